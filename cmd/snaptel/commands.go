@@ -41,6 +41,8 @@ var (
 						flTaskManifest,
 						flWorkfowManifest,
 						flTaskSchedInterval,
+						flTaskSchedTimezone,
+						flTaskSchedJitter,
 						flTaskSchedCount,
 						flTaskSchedStartDate,
 						flTaskSchedStartTime,
@@ -59,6 +61,7 @@ var (
 					Action: listTask,
 					Flags: []cli.Flag{
 						flVerbose,
+						flTaskLabels,
 					},
 				},
 				{
@@ -71,6 +74,16 @@ var (
 					Usage:  "stop <task_id>",
 					Action: stopTask,
 				},
+				{
+					Name:   "pause",
+					Usage:  "pause <task_id>",
+					Action: pauseTask,
+				},
+				{
+					Name:   "resume",
+					Usage:  "resume <task_id>",
+					Action: resumeTask,
+				},
 				{
 					Name:   "remove",
 					Usage:  "remove <task_id>",
@@ -87,6 +100,7 @@ var (
 					Action: watchTask,
 					Flags: []cli.Flag{
 						flVerbose,
+						flTaskWatchRuns,
 					},
 				},
 				{
@@ -101,10 +115,11 @@ var (
 			Subcommands: []cli.Command{
 				{
 					Name:   "load",
-					Usage:  "load <plugin_path> [--plugin-cert=<plugin_cert_path> --plugin-key=<plugin_key_path> --plugin-ca-certs=<ca_cert_paths>]",
+					Usage:  "load <plugin_path> [--plugin-cert=<plugin_cert_path> --plugin-key=<plugin_key_path> --plugin-ca-certs=<ca_cert_paths>] or load <plugin_url> --checksum=<sha256>",
 					Action: loadPlugin,
 					Flags: []cli.Flag{
 						flPluginAsc,
+						flPluginCheckSum,
 						flPluginCert,
 						flPluginKey,
 						flPluginCACerts,
@@ -196,6 +211,11 @@ var (
 					Action: showMember,
 					Flags:  []cli.Flag{flVerbose},
 				},
+				{
+					Name:   "metrics",
+					Usage:  "metrics [<agreement_name>]",
+					Action: listMemberMetrics,
+				},
 			},
 		},
 		{
@@ -211,6 +231,7 @@ var (
 					Name:   "create",
 					Usage:  "create <agreement_name>",
 					Action: createAgreement,
+					Flags:  []cli.Flag{flAgreementTaskLabels, flAgreementTaskStrategy, flAgreementTaskReplicas},
 				},
 				{
 					Name:   "delete",
@@ -232,6 +253,52 @@ var (
 					Usage:  "members <agreement_name>",
 					Action: agreementMembers,
 				},
+				{
+					Name:   "export",
+					Usage:  "export <agreement_name> [<file>]",
+					Action: exportAgreement,
+				},
+				{
+					Name:   "restore",
+					Usage:  "restore <file> [<agreement_name>]",
+					Action: restoreAgreement,
+				},
+				{
+					Name:   "override",
+					Usage:  "override <agreement_name> <task_id> <file>",
+					Action: overrideAgreementTask,
+				},
+			},
+		},
+		{
+			Name:  "tribe-key",
+			Usage: tribeWarning,
+			Subcommands: []cli.Command{
+				{
+					Name:   "list",
+					Usage:  "list",
+					Action: listTribeKeys,
+				},
+				{
+					Name:   "generate",
+					Usage:  "generate",
+					Action: generateTribeKey,
+				},
+				{
+					Name:   "install",
+					Usage:  "install <key>",
+					Action: installTribeKey,
+				},
+				{
+					Name:   "use",
+					Usage:  "use <key>",
+					Action: useTribeKey,
+				},
+				{
+					Name:   "remove",
+					Usage:  "remove <key>",
+					Action: removeTribeKey,
+				},
 			},
 		},
 	}
@@ -107,6 +107,10 @@ func getConfig(ctx *cli.Context) error {
 			printFields(w, false, 0, k, t.Value, t.Type())
 		case ctypes.ConfigValueStr:
 			printFields(w, false, 0, k, t.Value, t.Type())
+		case ctypes.ConfigValueDuration:
+			printFields(w, false, 0, k, t.String(), t.Type())
+		case ctypes.ConfigValueByteSize:
+			printFields(w, false, 0, k, t.String(), t.Type())
 		}
 	}
 
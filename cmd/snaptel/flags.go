@@ -30,7 +30,7 @@ var (
 	// Main flags
 	flURL = cli.StringFlag{
 		Name:   "url, u",
-		Usage:  "Sets the URL to use",
+		Usage:  "Sets the URL to use, unix:///<path> for the unix socket of snapteld",
 		EnvVar: "SNAP_URL",
 		Value:  "http://localhost:8181",
 	}
@@ -69,7 +69,11 @@ var (
 	// Plugin flags
 	flPluginAsc = cli.StringFlag{
 		Name:  "plugin-asc, a",
-		Usage: "The plugin asc",
+		Usage: "The plugin signature file (.asc or .sig)",
+	}
+	flPluginCheckSum = cli.StringFlag{
+		Name:  "checksum",
+		Usage: "The hex encoded SHA-256 checksum of a plugin loaded from a URL",
 	}
 	flPluginCert = cli.StringFlag{
 		Name:  "plugin-cert, c",
@@ -116,6 +120,10 @@ var (
 		Name:  "interval, i",
 		Usage: "Interval for the task schedule [ex (simple schedule): 250ms, 1s, 30m (cron schedule): \"0 * * * * *\"]",
 	}
+	flTaskSchedTimezone = cli.StringFlag{
+		Name:  "timezone",
+		Usage: "Timezone the cron schedule is evaluated in [ex: Europe/Paris, defaults to the local timezone of snapteld]",
+	}
 	flTaskSchedStartTime = cli.StringFlag{
 		Name:  "start-time",
 		Usage: "Start time for the task schedule [defaults to now]",
@@ -136,6 +144,10 @@ var (
 		Name:  "count",
 		Usage: "The count of runs for the task schedule [defaults to 0 what means no limit, e.g. set to 1 determines a single run task]",
 	}
+	flTaskSchedJitter = cli.StringFlag{
+		Name:  "jitter",
+		Usage: "The percentage of the interval the first run of a simple or windowed schedule is delayed by at most, derived from the hostname [defaults to 0]",
+	}
 	flTaskSchedDuration = cli.StringFlag{
 		Name:  "duration, d",
 		Usage: "The amount of time to run the task [appends to start or creates a start time before a stop]",
@@ -152,6 +164,29 @@ var (
 		Name:  "max-failures",
 		Usage: "The number of consecutive failures before Snap disables the task",
 	}
+	flTaskLabels = cli.StringFlag{
+		Name:  "labels",
+		Usage: "Only the tasks with all the labels, e.g. team=storage,env=prod",
+	}
+	flTaskWatchRuns = cli.BoolFlag{
+		Name:  "runs",
+		Usage: "Show the result of each step of every run of the task instead of the collected metrics",
+	}
+
+	// agreement
+	flAgreementTaskLabels = cli.StringFlag{
+		Name:  "task-labels",
+		Usage: "Only share the tasks with all the labels, e.g. team=storage,env=prod",
+	}
+	flAgreementTaskStrategy = cli.StringFlag{
+		Name:  "task-strategy",
+		Usage: "How the leader of the agreement assigns the tasks to its members: all, replicas or hash",
+	}
+	flAgreementTaskReplicas = cli.IntFlag{
+		Name:  "task-replicas",
+		Usage: "The number of members running each task with the replicas and hash strategies",
+		Value: 1,
+	}
 
 	// metric
 	flMetricVersion = cli.IntFlag{
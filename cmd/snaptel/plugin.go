@@ -29,6 +29,7 @@ import (
 	"text/tabwriter"
 	"time"
 
+	"github.com/intelsdi-x/snap/mgmt/rest/client"
 	"github.com/intelsdi-x/snap/mgmt/rest/v1"
 	"github.com/urfave/cli"
 )
@@ -39,17 +40,33 @@ func loadPlugin(ctx *cli.Context) error {
 	if len(ctx.Args()) != 1 {
 		return newUsageError("Incorrect usage:", ctx)
 	}
+	if arg := ctx.Args().First(); strings.HasPrefix(arg, "http://") || strings.HasPrefix(arg, "https://") {
+		return loadPluginFromURL(ctx, arg)
+	}
 	paths = append(paths, ctx.Args().First())
 	if pAsc != "" {
-		if !strings.Contains(pAsc, ".asc") {
-			return newUsageError("Must be a .asc file for the -a flag", ctx)
+		if !strings.Contains(pAsc, ".asc") && !strings.Contains(pAsc, ".sig") {
+			return newUsageError("Must be a .asc or .sig file for the -a flag", ctx)
 		}
 		paths = append(paths, pAsc)
 	}
 	if paths, err = storeTLSPaths(ctx, paths); err != nil {
 		return err
 	}
-	r := pClient.LoadPlugin(paths)
+	return printLoadedPlugins(pClient.LoadPlugin(paths))
+}
+
+// loadPluginFromURL has snapteld download the plugin from the HTTP(S) URL, the
+// checksum of the plugin is required since snapteld doesn't trust the server.
+func loadPluginFromURL(ctx *cli.Context, pluginURL string) error {
+	checkSum := ctx.String("checksum")
+	if checkSum == "" {
+		return newUsageError("Must provide the SHA-256 checksum (--checksum) of a plugin loaded from a URL", ctx)
+	}
+	return printLoadedPlugins(pClient.LoadPluginFromURL(pluginURL, checkSum))
+}
+
+func printLoadedPlugins(r *client.LoadPluginResult) error {
 	if r.Err != nil {
 		if r.Err.Fields()["error"] != nil {
 			return fmt.Errorf("Error loading plugin:\n%v\n%v\n", r.Err.Error(), r.Err.Fields()["error"])
@@ -113,8 +130,8 @@ func swapPlugins(ctx *cli.Context) error {
 	}
 	paths = append(paths, ctx.Args().First())
 	if pAsc != "" {
-		if !strings.Contains(pAsc, ".asc") {
-			return newUsageError("Must be a .asc file for the -a flag", ctx)
+		if !strings.Contains(pAsc, ".asc") && !strings.Contains(pAsc, ".sig") {
+			return newUsageError("Must be a .asc or .sig file for the -a flag", ctx)
 		}
 		paths = append(paths, pAsc)
 	}
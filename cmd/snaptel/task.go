@@ -264,6 +264,15 @@ func (t *task) setScheduleFromCliOptions(ctx *cli.Context) error {
 		t.Schedule.Count = count
 
 	}
+	jitterValStr := ctx.String("jitter")
+	if ctx.IsSet("jitter") || jitterValStr != "" {
+		jitter, err := stringValToUint(jitterValStr)
+		if err != nil || jitter > 100 {
+			return fmt.Errorf("Usage error (bad jitter value); the jitter must be a percentage between 0 and 100")
+		}
+		t.Schedule.Jitter = jitter
+	}
+	timezone := ctx.String("timezone")
 	// if a start, stop, or duration value was provided, or if the existing schedule for this task
 	// is 'windowed', then it's a 'windowed' schedule
 	isWindowed := (start != nil || stop != nil || duration != nil || t.Schedule.Type == "windowed")
@@ -298,14 +307,27 @@ func (t *task) setScheduleFromCliOptions(ctx *cli.Context) error {
 	}
 	// if it's not a 'windowed' schedule, then set the schedule type based on the 'isCron' flag,
 	// which was set above.
-	if isCron {
+	// (the existing 'cron' schedule is kept when no interval was passed in)
+	if isCron || (interval == "" && t.Schedule.Type == "cron") {
 		// make sure the current schedule type (if there is one) matches; if not it is an error
 		if t.Schedule.Type != "" && t.Schedule.Type != "cron" {
 			return fmt.Errorf("Usage error; cannot replace existing schedule of type '%v' with a new, 'cron' schedule", t.Schedule.Type)
 		}
+		if t.Schedule.Jitter != 0 {
+			return fmt.Errorf("Usage error; a jitter cannot be used with a cron entry as the interval")
+		}
 		t.Schedule.Type = "cron"
+		if timezone != "" {
+			if _, err := time.LoadLocation(timezone); err != nil {
+				return fmt.Errorf("Usage error (bad timezone value); %v", err)
+			}
+			t.Schedule.Timezone = timezone
+		}
 		return nil
 	}
+	if timezone != "" {
+		return fmt.Errorf("Usage error; a timezone can only be used with a cron entry as the interval")
+	}
 	// if it wasn't a 'windowed' schedule and it's not a 'cron' schedule, then it must be a 'simple'
 	// schedule, so first make sure the current schedule type (if there is one) matches; if not
 	// then it's an error
@@ -492,7 +514,7 @@ func mergeDateTime(tm, dt string) *time.Time {
 }
 
 func listTask(ctx *cli.Context) error {
-	tasks := pClient.GetTasks()
+	tasks := pClient.GetTasksByLabels(ctx.String("labels"))
 	termWidth, _, _ := terminal.GetSize(int(os.Stdout.Fd()))
 	verbose := ctx.Bool("verbose")
 	if tasks.Err != nil {
@@ -574,6 +596,9 @@ func watchTask(ctx *cli.Context) error {
 	}()
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', 0)
+	if ctx.Bool("runs") {
+		return watchTaskRuns(w, r)
+	}
 	fields := []interface{}{"NAMESPACE", "DATA", "TIMESTAMP"}
 	if verbose {
 		fields = append(fields, "TAGS")
@@ -634,6 +659,8 @@ func watchTask(ctx *cli.Context) error {
 				lines = len(e.Event) + extra
 				fmt.Fprintf(w, "\033[%dA\n", lines+1)
 				w.Flush()
+			case "task-run":
+				// the records of the runs are shown with --runs
 			default:
 				fmt.Printf("%s[%s]\n", strings.Repeat("\n", lines), e.EventType)
 			}
@@ -645,6 +672,36 @@ func watchTask(ctx *cli.Context) error {
 
 }
 
+// watchTaskRuns prints the result of each step of every run of the task
+func watchTaskRuns(w *tabwriter.Writer, r *client.WatchTasksResult) error {
+	printFields(w, false, 0, "TIMESTAMP", "DURATION", "STEP", "METRICS", "ERRORS")
+	w.Flush()
+	for {
+		select {
+		case e := <-r.EventChan:
+			switch e.EventType {
+			case "task-run":
+				if e.Run == nil {
+					continue
+				}
+				for i, s := range e.Run.Steps {
+					timestamp, duration := "", ""
+					if i == 0 {
+						timestamp, duration = e.Run.Timestamp.Format(timeFormat), e.Run.Duration
+					}
+					printFields(w, false, 0, timestamp, duration, s.Step, s.Metrics, strings.Join(s.Errors, "; "))
+				}
+				w.Flush()
+			case "metric-event":
+			default:
+				fmt.Printf("[%s]\n", e.EventType)
+			}
+		case <-r.DoneChan:
+			return nil
+		}
+	}
+}
+
 func startTask(ctx *cli.Context) error {
 	if len(ctx.Args()) != 1 {
 		return newUsageError("Incorrect usage", ctx)
@@ -687,6 +744,38 @@ func stopTask(ctx *cli.Context) error {
 	return nil
 }
 
+func pauseTask(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		return newUsageError("Incorrect usage", ctx)
+	}
+
+	id := ctx.Args().First()
+	r := pClient.PauseTask(id)
+	if r.Err != nil {
+		return fmt.Errorf("Error pausing task:\n%v\n", r.Err)
+	}
+	fmt.Println("Task paused:")
+	fmt.Printf("ID: %s\n", r.ID)
+
+	return nil
+}
+
+func resumeTask(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		return newUsageError("Incorrect usage", ctx)
+	}
+
+	id := ctx.Args().First()
+	r := pClient.ResumeTask(id)
+	if r.Err != nil {
+		return fmt.Errorf("Error resuming task:\n%v\n", r.Err)
+	}
+	fmt.Println("Task resumed:")
+	fmt.Printf("ID: %s\n", r.ID)
+
+	return nil
+}
+
 func removeTask(ctx *cli.Context) error {
 	if len(ctx.Args()) != 1 {
 		return newUsageError("Incorrect usage", ctx)
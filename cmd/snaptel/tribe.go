@@ -21,12 +21,18 @@ package main
 
 import (
 	"bytes"
+	"crypto/rand"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"sort"
+	"strings"
 	"text/tabwriter"
 
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/mgmt/rest/client"
 	"github.com/intelsdi-x/snap/mgmt/tribe/agreement"
 	"github.com/urfave/cli"
 )
@@ -53,6 +59,34 @@ func listMembers(ctx *cli.Context) error {
 	return nil
 }
 
+func listMemberMetrics(ctx *cli.Context) error {
+	if len(ctx.Args()) > 1 {
+		return newUsageError("Incorrect usage", ctx)
+	}
+
+	resp := pClient.GetTribeMetrics(ctx.Args().First())
+	if resp.Err != nil {
+		return fmt.Errorf("Error getting metrics:\n%v\n", resp.Err)
+	}
+
+	if len(resp.Metrics) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', 0)
+		printFields(w, false, 0,
+			"NAMESPACE", "VERSION", "MEMBERS",
+		)
+		for _, m := range resp.Metrics {
+			printFields(w, false, 0, m.Namespace, m.Version, strings.Join(m.Members, ","))
+		}
+		w.Flush()
+	} else {
+		fmt.Println("None")
+	}
+	for _, m := range resp.UnreachableMembers {
+		fmt.Printf("Unreachable member: %s\n", m)
+	}
+	return nil
+}
+
 func showMember(ctx *cli.Context) error {
 	if len(ctx.Args()) != 1 {
 		return newUsageError("Incorrect usage", ctx)
@@ -108,7 +142,11 @@ func createAgreement(ctx *cli.Context) error {
 		return newUsageError("Incorrect usage", ctx)
 	}
 
-	resp := pClient.AddAgreement(ctx.Args().First())
+	taskLabels, err := core.ParseLabelSelector(ctx.String("task-labels"))
+	if err != nil {
+		return newUsageError(err.Error(), ctx)
+	}
+	resp := pClient.AddAgreementWithTaskAssignment(ctx.Args().First(), taskLabels, ctx.String("task-strategy"), ctx.Int("task-replicas"))
 	if resp.Err != nil {
 		return fmt.Errorf("Error creating agreement: %v\n", resp.Err)
 	}
@@ -175,12 +213,93 @@ func agreementMembers(ctx *cli.Context) error {
 	return nil
 }
 
+func exportAgreement(ctx *cli.Context) error {
+	if len(ctx.Args()) < 1 || len(ctx.Args()) > 2 {
+		return newUsageError("Incorrect usage", ctx)
+	}
+
+	resp := pClient.ExportAgreement(ctx.Args().First())
+	if resp.Err != nil {
+		return fmt.Errorf("Error exporting agreement:\n%v\n", resp.Err)
+	}
+	b, err := json.MarshalIndent(resp.Snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Error exporting agreement:\n%v\n", err)
+	}
+	if len(ctx.Args()) == 1 {
+		fmt.Println(string(b))
+		return nil
+	}
+	if err := ioutil.WriteFile(ctx.Args().Get(1), b, 0600); err != nil {
+		return fmt.Errorf("Error exporting agreement:\n%v\n", err)
+	}
+	fmt.Printf("Agreement %s exported to %s\n", resp.Snapshot.Name, ctx.Args().Get(1))
+	return nil
+}
+
+func restoreAgreement(ctx *cli.Context) error {
+	if len(ctx.Args()) < 1 || len(ctx.Args()) > 2 {
+		return newUsageError("Incorrect usage", ctx)
+	}
+
+	b, err := ioutil.ReadFile(ctx.Args().First())
+	if err != nil {
+		return fmt.Errorf("Error restoring agreement:\n%v\n", err)
+	}
+	snapshot := &agreement.Snapshot{}
+	if err := json.Unmarshal(b, snapshot); err != nil {
+		return fmt.Errorf("Error restoring agreement:\n%v\n", err)
+	}
+	name := snapshot.Name
+	if len(ctx.Args()) == 2 {
+		name = ctx.Args().Get(1)
+	}
+
+	resp := pClient.RestoreAgreement(name, snapshot)
+	if resp.Err != nil {
+		return fmt.Errorf("Error restoring agreement:\n%v\n", resp.Err)
+	}
+	printAgreements(map[string]*agreement.Agreement{resp.Agreement.Name: resp.Agreement})
+	for _, m := range resp.MissingMembers {
+		fmt.Printf("Missing member: %s\n", m)
+	}
+	for _, p := range resp.MissingPlugins {
+		fmt.Printf("Missing plugin: %s %s %d\n", p.TypeName(), p.Name(), p.Version())
+	}
+	for _, id := range resp.MissingTasks {
+		fmt.Printf("Missing task: %s\n", id)
+	}
+	return nil
+}
+
+func overrideAgreementTask(ctx *cli.Context) error {
+	if len(ctx.Args()) != 3 {
+		return newUsageError("Incorrect usage", ctx)
+	}
+
+	b, err := ioutil.ReadFile(ctx.Args().Get(2))
+	if err != nil {
+		return fmt.Errorf("Error overriding task:\n%v\n", err)
+	}
+	overrides := map[string]agreement.TaskOverride{}
+	if err := json.Unmarshal(b, &overrides); err != nil {
+		return fmt.Errorf("Error overriding task:\n%v\n", err)
+	}
+
+	resp := pClient.SetTaskOverrides(ctx.Args().First(), ctx.Args().Get(1), overrides)
+	if resp.Err != nil {
+		return fmt.Errorf("Error overriding task:\n%v\n", resp.Err)
+	}
+	printAgreements(map[string]*agreement.Agreement{resp.Agreement.Name: resp.Agreement})
+	return nil
+}
+
 func printAgreements(agreements map[string]*agreement.Agreement) {
 	if len(agreements) > 0 {
 		w := tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', 0)
 		defer w.Flush()
 		printFields(w, false, 0,
-			"Name", "Number of Members", "plugins", "tasks",
+			"Name", "Number of Members", "plugins", "tasks", "leader",
 		)
 
 		var keys []string
@@ -193,15 +312,76 @@ func printAgreements(agreements map[string]*agreement.Agreement) {
 			v := agreements[k]
 			var plugins interface{}
 			var tasks interface{}
+			var leader string
 			if v.PluginAgreement != nil {
 				plugins = len(v.PluginAgreement.Plugins)
 			}
 			if v.TaskAgreement != nil {
 				tasks = len(v.TaskAgreement.Tasks)
+				leader = v.TaskAgreement.Leader
 			}
-			printFields(w, false, 0, v.Name, len(v.Members), plugins, tasks)
+			printFields(w, false, 0, v.Name, len(v.Members), plugins, tasks, leader)
 		}
 	} else {
 		fmt.Println("None")
 	}
 }
+
+func listTribeKeys(ctx *cli.Context) error {
+	resp := pClient.ListTribeKeys()
+	if resp.Err != nil {
+		return fmt.Errorf("Error getting keys:\n%v\n", resp.Err)
+	}
+	printTribeKeys(resp.Keys)
+	return nil
+}
+
+// generateTribeKey prints a random key to encrypt the tribe gossip with
+func generateTribeKey(ctx *cli.Context) error {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return fmt.Errorf("Error generating key: %v\n", err)
+	}
+	fmt.Println(base64.StdEncoding.EncodeToString(key))
+	return nil
+}
+
+func installTribeKey(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		return newUsageError("Incorrect usage", ctx)
+	}
+	return printTribeKeysResult(pClient.InstallTribeKey(ctx.Args().First()))
+}
+
+func useTribeKey(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		return newUsageError("Incorrect usage", ctx)
+	}
+	return printTribeKeysResult(pClient.UseTribeKey(ctx.Args().First()))
+}
+
+func removeTribeKey(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		return newUsageError("Incorrect usage", ctx)
+	}
+	return printTribeKeysResult(pClient.RemoveTribeKey(ctx.Args().First()))
+}
+
+func printTribeKeysResult(resp *client.TribeKeysResult) error {
+	if resp.Err != nil {
+		return fmt.Errorf("Error: %v\n", resp.Err)
+	}
+	printTribeKeys(resp.Keys)
+	return nil
+}
+
+// printTribeKeys prints the fingerprints of the keys, the first one is the
+// fingerprint of the primary key
+func printTribeKeys(keys []string) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', 0)
+	defer w.Flush()
+	printFields(w, false, 0, "Fingerprint", "Primary")
+	for i, k := range keys {
+		printFields(w, false, 0, k, i == 0)
+	}
+}
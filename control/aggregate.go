@@ -0,0 +1,305 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/ctypes"
+)
+
+const (
+	// AggregateProcessorName is the name of the built-in processor which
+	// aggregates the metrics of a task across its runs, it doesn't have to be
+	// loaded and accepts any version
+	AggregateProcessorName = "aggregate"
+
+	// AggregateWindowKey is the config item of the window the metrics are
+	// aggregated over (e.g. "5m")
+	AggregateWindowKey = "window"
+	// AggregateFunctionsKey is the config item of the comma separated
+	// aggregation functions, avg by default
+	AggregateFunctionsKey = "functions"
+
+	// DefaultAggregateWindow is the window of an aggregate step without one
+	DefaultAggregateWindow = time.Minute
+)
+
+// The aggregation functions of the aggregate processor
+const (
+	AggregateAvg   = "avg"
+	AggregateMin   = "min"
+	AggregateMax   = "max"
+	AggregateSum   = "sum"
+	AggregateCount = "count"
+)
+
+var (
+	// ErrInvalidAggregateWindow - error message when the window of an aggregate step isn't a positive duration
+	ErrInvalidAggregateWindow = errors.New("aggregate window must be a positive duration")
+	// ErrInvalidAggregateFunction - error message when an aggregation function of an aggregate step is unknown
+	ErrInvalidAggregateFunction = fmt.Errorf("aggregate functions must be a comma separated list of %s, %s, %s, %s or %s",
+		AggregateAvg, AggregateMin, AggregateMax, AggregateSum, AggregateCount)
+)
+
+// aggregateConfig holds the settings of an aggregate step
+type aggregateConfig struct {
+	window    time.Duration
+	functions []string
+}
+
+func newAggregateConfig(table map[string]ctypes.ConfigValue) (aggregateConfig, error) {
+	cfg := aggregateConfig{window: DefaultAggregateWindow, functions: []string{AggregateAvg}}
+	if v, ok := table[AggregateWindowKey]; ok {
+		s, _ := v.(ctypes.ConfigValueStr)
+		d, err := time.ParseDuration(s.Value)
+		if err != nil || d <= 0 {
+			return cfg, ErrInvalidAggregateWindow
+		}
+		cfg.window = d
+	}
+	if v, ok := table[AggregateFunctionsKey]; ok {
+		s, _ := v.(ctypes.ConfigValueStr)
+		cfg.functions = nil
+		for _, fn := range strings.Split(s.Value, ",") {
+			fn = strings.TrimSpace(fn)
+			switch fn {
+			case AggregateAvg, AggregateMin, AggregateMax, AggregateSum, AggregateCount:
+				cfg.functions = append(cfg.functions, fn)
+			default:
+				return cfg, ErrInvalidAggregateFunction
+			}
+		}
+	}
+	return cfg, nil
+}
+
+// aggregator holds the windows of the aggregate steps of the tasks: a step
+// returns no metric until its window elapsed, the run ending the window
+// returns the aggregates of the metrics passed to the step during the window
+type aggregator struct {
+	sync.Mutex
+	// the open windows by task id and step config
+	windows map[string]map[string]*aggregateWindow
+}
+
+// aggregateWindow holds the series aggregated by a step since its window opened
+type aggregateWindow struct {
+	start  time.Time
+	series map[string]*aggregateSeries
+	// the keys of the series in the order they were first seen
+	order []string
+}
+
+// aggregateSeries aggregates the values of a metric with the same namespace
+// and tags
+type aggregateSeries struct {
+	namespace core.Namespace
+	version   int
+	tags      map[string]string
+	unit      string
+	count     int
+	sum       float64
+	min       float64
+	max       float64
+}
+
+func newAggregator() *aggregator {
+	return &aggregator{windows: map[string]map[string]*aggregateWindow{}}
+}
+
+func (a *aggregator) validate(config map[string]ctypes.ConfigValue) error {
+	_, err := newAggregateConfig(config)
+	return err
+}
+
+// process adds the metrics to the window of the step and returns the
+// aggregates once the window elapsed
+func (a *aggregator) process(metrics []core.Metric, config map[string]ctypes.ConfigValue, taskID string, now time.Time) ([]core.Metric, []error) {
+	cfg, err := newAggregateConfig(config)
+	if err != nil {
+		return nil, []error{err}
+	}
+	step := aggregateStepKey(config)
+	if node, ok := config[core.WorkflowNodeConfigKey].(ctypes.ConfigValueStr); ok {
+		step = node.Value
+	}
+
+	a.Lock()
+	defer a.Unlock()
+	windows, ok := a.windows[taskID]
+	if !ok {
+		windows = map[string]*aggregateWindow{}
+		a.windows[taskID] = windows
+	}
+	w, ok := windows[step]
+	if !ok {
+		w = &aggregateWindow{start: now, series: map[string]*aggregateSeries{}}
+		windows[step] = w
+	}
+	for _, m := range metrics {
+		v, ok := numericValue(m.Data())
+		if !ok {
+			continue
+		}
+		w.add(m, v)
+	}
+	if now.Sub(w.start) < cfg.window {
+		return nil, nil
+	}
+	delete(windows, step)
+	return w.aggregates(cfg.functions, now), nil
+}
+
+// remove drops the windows of the task
+func (a *aggregator) remove(taskID string) {
+	a.Lock()
+	defer a.Unlock()
+	delete(a.windows, taskID)
+}
+
+func (w *aggregateWindow) add(m core.Metric, v float64) {
+	key := fmt.Sprintf("%s"+core.Separator+"%d"+core.Separator+"%s", m.Namespace().String(), m.Version(), tagsKey(m.Tags()))
+	s, ok := w.series[key]
+	if !ok {
+		s = &aggregateSeries{
+			namespace: m.Namespace(),
+			version:   m.Version(),
+			tags:      m.Tags(),
+			unit:      m.Unit(),
+			min:       v,
+			max:       v,
+		}
+		w.series[key] = s
+		w.order = append(w.order, key)
+	}
+	s.count++
+	s.sum += v
+	if v < s.min {
+		s.min = v
+	}
+	if v > s.max {
+		s.max = v
+	}
+}
+
+// aggregates returns a metric per series and function, its namespace is the
+// namespace of the series followed by the function
+func (w *aggregateWindow) aggregates(functions []string, now time.Time) []core.Metric {
+	mts := make([]core.Metric, 0, len(w.order)*len(functions))
+	for _, key := range w.order {
+		s := w.series[key]
+		for _, fn := range functions {
+			var data interface{}
+			unit := s.unit
+			switch fn {
+			case AggregateAvg:
+				data = s.sum / float64(s.count)
+			case AggregateMin:
+				data = s.min
+			case AggregateMax:
+				data = s.max
+			case AggregateSum:
+				data = s.sum
+			case AggregateCount:
+				data = s.count
+				unit = ""
+			}
+			ns := make(core.Namespace, len(s.namespace))
+			copy(ns, s.namespace)
+			mts = append(mts, plugin.MetricType{
+				Namespace_: ns.AddStaticElement(fn),
+				Version_:   s.version,
+				Tags_:      s.tags,
+				Unit_:      unit,
+				Data_:      data,
+				Timestamp_: now,
+			})
+		}
+	}
+	return mts
+}
+
+// aggregateStepKey identifies the aggregate steps of a task by their config
+// when the jobs don't tell the node of the workflow they come from
+func aggregateStepKey(config map[string]ctypes.ConfigValue) string {
+	keys := make([]string, 0, len(config))
+	for k := range config {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s=%v", k, config[k])
+	}
+	return strings.Join(parts, ",")
+}
+
+func tagsKey(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + "=" + tags[k]
+	}
+	return strings.Join(parts, ",")
+}
+
+// numericValue returns the value of a metric as a float, false when the
+// value isn't a number
+func numericValue(data interface{}) (float64, bool) {
+	switch v := data.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	}
+	return 0, false
+}
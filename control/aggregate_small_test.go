@@ -0,0 +1,126 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/ctypes"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAggregator(t *testing.T) {
+	Convey("newAggregateConfig", t, func() {
+		Convey("defaults the window and the functions", func() {
+			cfg, err := newAggregateConfig(map[string]ctypes.ConfigValue{})
+			So(err, ShouldBeNil)
+			So(cfg.window, ShouldEqual, DefaultAggregateWindow)
+			So(cfg.functions, ShouldResemble, []string{AggregateAvg})
+		})
+		Convey("returns an error for an invalid window or function", func() {
+			_, err := newAggregateConfig(map[string]ctypes.ConfigValue{AggregateWindowKey: ctypes.ConfigValueStr{Value: "-1m"}})
+			So(err, ShouldEqual, ErrInvalidAggregateWindow)
+			_, err = newAggregateConfig(map[string]ctypes.ConfigValue{AggregateFunctionsKey: ctypes.ConfigValueStr{Value: "avg,median"}})
+			So(err, ShouldEqual, ErrInvalidAggregateFunction)
+		})
+	})
+	Convey("aggregator", t, func() {
+		a := newAggregator()
+		config := map[string]ctypes.ConfigValue{
+			AggregateWindowKey:    ctypes.ConfigValueStr{Value: "1m"},
+			AggregateFunctionsKey: ctypes.ConfigValueStr{Value: "avg,min,max,sum,count"},
+		}
+		metric := func(v interface{}, tags map[string]string) core.Metric {
+			return plugin.MetricType{Namespace_: core.NewNamespace("intel", "load"), Data_: v, Tags_: tags}
+		}
+		now := time.Now()
+
+		Convey("returns the aggregates once the window elapsed", func() {
+			mts, errs := a.process([]core.Metric{metric(1, nil), metric("n/a", nil)}, config, "task", now)
+			So(errs, ShouldBeEmpty)
+			So(mts, ShouldBeEmpty)
+			mts, errs = a.process([]core.Metric{metric(3.0, nil)}, config, "task", now.Add(30*time.Second))
+			So(errs, ShouldBeEmpty)
+			So(mts, ShouldBeEmpty)
+			mts, errs = a.process([]core.Metric{metric(uint64(8), nil)}, config, "task", now.Add(time.Minute))
+			So(errs, ShouldBeEmpty)
+			So(mts, ShouldHaveLength, 5)
+			values := map[string]interface{}{}
+			for _, m := range mts {
+				values[m.Namespace().String()] = m.Data()
+			}
+			So(values["/intel/load/avg"], ShouldEqual, 4.0)
+			So(values["/intel/load/min"], ShouldEqual, 1.0)
+			So(values["/intel/load/max"], ShouldEqual, 8.0)
+			So(values["/intel/load/sum"], ShouldEqual, 12.0)
+			So(values["/intel/load/count"], ShouldEqual, 3)
+
+			Convey("and opens a new window", func() {
+				mts, _ := a.process([]core.Metric{metric(1, nil)}, config, "task", now.Add(time.Minute+time.Second))
+				So(mts, ShouldBeEmpty)
+			})
+		})
+		Convey("aggregates the metrics with different tags apart", func() {
+			a.process([]core.Metric{metric(1, map[string]string{"host": "a"}), metric(2, map[string]string{"host": "b"})}, config, "task", now)
+			mts, _ := a.process(nil, config, "task", now.Add(time.Minute))
+			So(mts, ShouldHaveLength, 10)
+		})
+		Convey("keeps the windows of the tasks and the steps apart", func() {
+			hourly := map[string]ctypes.ConfigValue{AggregateWindowKey: ctypes.ConfigValueStr{Value: "1h"}}
+			a.process([]core.Metric{metric(1, nil)}, config, "task", now)
+			a.process([]core.Metric{metric(5, nil)}, hourly, "task", now)
+			a.process([]core.Metric{metric(9, nil)}, config, "other", now)
+			mts, _ := a.process(nil, config, "task", now.Add(time.Minute))
+			So(mts, ShouldHaveLength, 5)
+			So(mts[0].Data(), ShouldEqual, 1.0)
+			mts, _ = a.process(nil, hourly, "task", now.Add(time.Minute))
+			So(mts, ShouldBeEmpty)
+		})
+		Convey("keeps the windows of the workflow nodes with the same config apart", func() {
+			node := func(position string) map[string]ctypes.ConfigValue {
+				c := map[string]ctypes.ConfigValue{core.WorkflowNodeConfigKey: ctypes.ConfigValueStr{Value: position}}
+				for k, v := range config {
+					c[k] = v
+				}
+				return c
+			}
+			a.process([]core.Metric{metric(1, nil)}, node("/0"), "task", now)
+			a.process([]core.Metric{metric(5, nil)}, node("/1"), "task", now)
+			mts, _ := a.process(nil, node("/0"), "task", now.Add(time.Minute))
+			So(mts, ShouldHaveLength, 5)
+			So(mts[0].Data(), ShouldEqual, 1.0)
+			mts, _ = a.process(nil, node("/1"), "task", now.Add(time.Minute))
+			So(mts, ShouldHaveLength, 5)
+			So(mts[0].Data(), ShouldEqual, 5.0)
+		})
+		Convey("forgets the windows of a removed task", func() {
+			a.process([]core.Metric{metric(1, nil)}, config, "task", now)
+			a.remove("task")
+			mts, _ := a.process(nil, config, "task", now.Add(time.Minute))
+			So(mts, ShouldBeEmpty)
+		})
+	})
+}
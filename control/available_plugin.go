@@ -24,9 +24,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	log "github.com/Sirupsen/logrus"
@@ -50,6 +52,12 @@ const (
 	DefaultHealthCheckFailureLimit = 3
 )
 
+var (
+	// HealthCheckFailureLimit is the number of consecutive failed health checks
+	// after which a plugin is considered unhealthy and restarted
+	HealthCheckFailureLimit = DefaultHealthCheckFailureLimit
+)
+
 var (
 	ErrPoolNotFound      = errors.New("plugin pool not found")
 	ErrBadKey            = errors.New("bad key")
@@ -70,7 +78,8 @@ type availablePlugin struct {
 	hitCount           int
 	lastHitTime        time.Time
 	emitter            gomit.Emitter
-	failedHealthChecks int
+	failedHealthChecks int32
+	memoryLimitHit     bool
 	healthChan         chan error
 	ePlugin            executablePlugin
 	execPath           string
@@ -115,13 +124,13 @@ func newAvailablePlugin(resp plugin.Response, emitter gomit.Emitter, ep executab
 				"_block":      "newAvailablePlugin",
 				"plugin_name": ap.name,
 			}).Warning("This plugin is using a deprecated RPC protocol. Find more information here: https://github.com/intelsdi-x/snap/issues/1289 ")
-			c, e := client.NewCollectorNativeClient(resp.ListenAddress, DefaultClientTimeout, resp.PublicKey, !resp.Meta.Unsecure)
+			c, e := client.NewCollectorNativeClient(resp.ListenAddress, DefaultClientTimeout, resp.PublicKey, !resp.Meta.Unsecure, plugin.MetricsContentType(resp.Meta.AcceptedContentTypes))
 			if e != nil {
 				return nil, errors.New("error while creating client connection: " + e.Error())
 			}
 			ap.client = c
 		case plugin.GRPC:
-			c, e := client.NewCollectorGrpcClient(resp.ListenAddress, DefaultClientTimeout, security)
+			c, e := client.NewCollectorGrpcClient(resp.ListenAddress, DefaultClientTimeout, security, resp.Meta.Capabilities)
 			if e != nil {
 				return nil, errors.New("error while creating client connection: " + e.Error())
 			}
@@ -130,47 +139,48 @@ func newAvailablePlugin(resp plugin.Response, emitter gomit.Emitter, ep executab
 			c, e := client.NewStreamCollectorGrpcClient(
 				resp.ListenAddress,
 				DefaultClientTimeout,
-				security)
+				security,
+				resp.Meta.Capabilities)
 			if e != nil {
 				return nil, errors.New("error while creating client connection: " + e.Error())
 			}
 			ap.client = c
 		default:
-			return nil, errors.New("Invalid RPCTYPE")
+			return nil, errInvalidRPCType(resp)
 		}
 	case plugin.PublisherPluginType:
 		switch resp.Meta.RPCType {
 		case plugin.NativeRPC:
-			c, e := client.NewPublisherNativeClient(resp.ListenAddress, DefaultClientTimeout, resp.PublicKey, !resp.Meta.Unsecure)
+			c, e := client.NewPublisherNativeClient(resp.ListenAddress, DefaultClientTimeout, resp.PublicKey, !resp.Meta.Unsecure, plugin.MetricsContentType(resp.Meta.AcceptedContentTypes))
 			if e != nil {
 				return nil, errors.New("error while creating client connection: " + e.Error())
 			}
 			ap.client = c
 		case plugin.GRPC:
-			c, e := client.NewPublisherGrpcClient(resp.ListenAddress, DefaultClientTimeout, security)
+			c, e := client.NewPublisherGrpcClient(resp.ListenAddress, DefaultClientTimeout, security, resp.Meta.Capabilities)
 			if e != nil {
 				return nil, errors.New("error while creating client connection: " + e.Error())
 			}
 			ap.client = c
 		default:
-			return nil, errors.New("Invalid RPCTYPE")
+			return nil, errInvalidRPCType(resp)
 		}
 	case plugin.ProcessorPluginType:
 		switch resp.Meta.RPCType {
 		case plugin.NativeRPC:
-			c, e := client.NewProcessorNativeClient(resp.ListenAddress, DefaultClientTimeout, resp.PublicKey, !resp.Meta.Unsecure)
+			c, e := client.NewProcessorNativeClient(resp.ListenAddress, DefaultClientTimeout, resp.PublicKey, !resp.Meta.Unsecure, plugin.MetricsContentType(resp.Meta.AcceptedContentTypes))
 			if e != nil {
 				return nil, errors.New("error while creating client connection: " + e.Error())
 			}
 			ap.client = c
 		case plugin.GRPC:
-			c, e := client.NewProcessorGrpcClient(resp.ListenAddress, DefaultClientTimeout, security)
+			c, e := client.NewProcessorGrpcClient(resp.ListenAddress, DefaultClientTimeout, security, resp.Meta.Capabilities)
 			if e != nil {
 				return nil, errors.New("error while creating client connection: " + e.Error())
 			}
 			ap.client = c
 		default:
-			return nil, errors.New("Invalid RPCTYPE")
+			return nil, errInvalidRPCType(resp)
 		}
 	case plugin.StreamCollectorPluginType:
 		switch resp.Meta.RPCType {
@@ -178,13 +188,14 @@ func newAvailablePlugin(resp plugin.Response, emitter gomit.Emitter, ep executab
 			c, e := client.NewStreamCollectorGrpcClient(
 				resp.ListenAddress,
 				DefaultClientTimeout,
-				security)
+				security,
+				resp.Meta.Capabilities)
 			if e != nil {
 				return nil, errors.New("error while creating client connection: " + e.Error())
 			}
 			ap.client = c
 		default:
-			return nil, errors.New("Invalid RPCTYPE")
+			return nil, errInvalidRPCType(resp)
 		}
 	default:
 		return nil, errors.New("Cannot create a client for a plugin of the type: " + resp.Type.String())
@@ -193,6 +204,12 @@ func newAvailablePlugin(resp plugin.Response, emitter gomit.Emitter, ep executab
 	return ap, nil
 }
 
+// errInvalidRPCType reports the RPC type a plugin negotiated in its handshake
+// which can't be used with its plugin type
+func errInvalidRPCType(resp plugin.Response) error {
+	return fmt.Errorf("Invalid RPCTYPE %d (%s) for a %s plugin", resp.Meta.RPCType, rpcTypeName(resp.Meta.RPCType), resp.Type.String())
+}
+
 func (a *availablePlugin) Port() string {
 	return a.pprofPort
 }
@@ -217,6 +234,15 @@ func (a *availablePlugin) RoutingStrategy() plugin.RoutingStrategyType {
 	return a.meta.RoutingStrategy
 }
 
+// Load returns the load reported by the plugin in its last reply to the
+// pings of the health checks, a zero load when it doesn't report it
+func (a *availablePlugin) Load() plugin.Load {
+	if r, ok := a.client.(client.PluginLoadReporter); ok {
+		return r.Load()
+	}
+	return plugin.Load{}
+}
+
 func (a *availablePlugin) ConcurrencyCount() int {
 	return a.meta.ConcurrencyCount
 }
@@ -249,6 +275,17 @@ func (a *availablePlugin) LastHit() time.Time {
 	return a.lastHitTime
 }
 
+// FailedHealthChecks returns the number of consecutive failed health checks
+func (a *availablePlugin) FailedHealthChecks() int {
+	return int(atomic.LoadInt32(&a.failedHealthChecks))
+}
+
+// Healthy returns false once the plugin failed HealthCheckFailureLimit
+// consecutive health checks
+func (a *availablePlugin) Healthy() bool {
+	return a.FailedHealthChecks() < HealthCheckFailureLimit
+}
+
 func (a *availablePlugin) IsRemote() bool {
 	return a.isRemote
 }
@@ -300,7 +337,8 @@ func (a *availablePlugin) Kill(r string) error {
 }
 
 // CheckHealth checks the health of a plugin and updates
-// a.failedHealthChecks
+// a.failedHealthChecks, which is accessed atomically as it is read while
+// the health monitor checks the plugin
 func (a *availablePlugin) CheckHealth() {
 	if a.IsRemote() {
 		runnerLog.WithFields(log.Fields{
@@ -309,21 +347,30 @@ func (a *availablePlugin) CheckHealth() {
 		}).Debug(fmt.Sprintf("bypassing check-health on standalone plugin"))
 		return
 	}
+	if a.memoryLimitExceeded() {
+		return
+	}
 	go func() {
 		a.healthChan <- a.client.Ping()
 	}()
 	select {
 	case err := <-a.healthChan:
 		if err == nil {
-			if a.failedHealthChecks > 0 {
+			if failed := atomic.SwapInt32(&a.failedHealthChecks, 0); failed > 0 {
 				// only log on first ok health check
 				log.WithFields(log.Fields{
 					"_module":     "control-aplugin",
 					"block":       "check-health",
 					"plugin_name": a,
 				}).Debug("health is ok")
+				defer a.emitter.Emit(&control_event.HealthCheckRecoveredEvent{
+					Name:         a.name,
+					Version:      a.version,
+					Type:         int(a.pluginType),
+					Id:           a.ID(),
+					FailedChecks: int(failed),
+				})
 			}
-			a.failedHealthChecks = 0
 		} else {
 			a.healthCheckFailed()
 		}
@@ -340,29 +387,61 @@ func (a *availablePlugin) healthCheckFailed() {
 		"block":       "check-health",
 		"plugin_name": a,
 	}).Warning("heartbeat missed")
-	a.failedHealthChecks++
-	if a.failedHealthChecks >= DefaultHealthCheckFailureLimit {
+	failed := int(atomic.AddInt32(&a.failedHealthChecks, 1))
+	if failed >= HealthCheckFailureLimit {
 		log.WithFields(log.Fields{
 			"_module":     "control-aplugin",
 			"block":       "check-health",
 			"plugin_name": a,
 		}).Warning("heartbeat failed")
-		pde := &control_event.DeadAvailablePluginEvent{
-			Name:    a.name,
-			Version: a.version,
-			Type:    int(a.pluginType),
-			Key:     a.key,
-			Id:      a.ID(),
-			String:  a.String(),
-		}
-		defer a.emitter.Emit(pde)
+		defer a.emitDead()
 	}
 	hcfe := &control_event.HealthCheckFailedEvent{
+		Name:         a.name,
+		Version:      a.version,
+		Type:         int(a.pluginType),
+		Id:           a.ID(),
+		FailedChecks: failed,
+	}
+	defer a.emitter.Emit(hcfe)
+}
+
+// memoryLimitExceeded returns true and emits a MemoryLimitExceededEvent and a
+// DeadAvailablePluginEvent, so that the plugin is killed and restarted, when
+// the plugin exceeded its memory limit
+func (a *availablePlugin) memoryLimitExceeded() bool {
+	ep, ok := a.ePlugin.(interface {
+		MemoryLimitExceeded() bool
+	})
+	if !ok || a.memoryLimitHit || !ep.MemoryLimitExceeded() {
+		return false
+	}
+	a.memoryLimitHit = true
+	log.WithFields(log.Fields{
+		"_module":     "control-aplugin",
+		"block":       "check-health",
+		"plugin_name": a,
+	}).Warning("memory limit exceeded")
+	a.emitter.Emit(&control_event.MemoryLimitExceededEvent{
 		Name:    a.name,
 		Version: a.version,
 		Type:    int(a.pluginType),
-	}
-	defer a.emitter.Emit(hcfe)
+		Id:      a.ID(),
+	})
+	a.emitDead()
+	return true
+}
+
+// emitDead emits a DeadAvailablePluginEvent for the plugin
+func (a *availablePlugin) emitDead() {
+	a.emitter.Emit(&control_event.DeadAvailablePluginEvent{
+		Name:    a.name,
+		Version: a.version,
+		Type:    int(a.pluginType),
+		Key:     a.key,
+		Id:      a.ID(),
+		String:  a.String(),
+	})
 }
 
 type availablePlugins struct {
@@ -372,13 +451,134 @@ type availablePlugins struct {
 	// The Pools' primary keys are equal to
 	// {plugin_type}:{plugin_name}:{plugin_version}
 	table map[string]strategy.Pool
+	// calls counts the calls made to the plugins, by pool key
+	calls      map[string]*callCounters
+	callsMutex *sync.Mutex
+}
+
+// callCounters counts the calls made to a plugin and the ones which failed
+type callCounters struct {
+	calls  uint64
+	errors uint64
+	// latency is the total time the calls took
+	latency time.Duration
 }
 
 func newAvailablePlugins() *availablePlugins {
 	return &availablePlugins{
-		RWMutex: &sync.RWMutex{},
-		table:   make(map[string]strategy.Pool),
+		RWMutex:    &sync.RWMutex{},
+		table:      make(map[string]strategy.Pool),
+		calls:      map[string]*callCounters{},
+		callsMutex: &sync.Mutex{},
+	}
+}
+
+// countCall records a call made to the plugin of the given pool key and the
+// time it took
+func (ap *availablePlugins) countCall(key string, failed bool, latency time.Duration) {
+	ap.callsMutex.Lock()
+	defer ap.callsMutex.Unlock()
+	c, ok := ap.calls[key]
+	if !ok {
+		c = &callCounters{}
+		ap.calls[key] = c
+	}
+	c.calls++
+	c.latency += latency
+	if failed {
+		c.errors++
+	}
+}
+
+// callCount returns the number of calls made to the plugin of the given pool
+// key and the number of the ones which failed
+func (ap *availablePlugins) callCount(key string) (uint64, uint64) {
+	ap.callsMutex.Lock()
+	defer ap.callsMutex.Unlock()
+	if c, ok := ap.calls[key]; ok {
+		return c.calls, c.errors
+	}
+	return 0, 0
+}
+
+// callStats returns a copy of the call counters of the plugins, by pool key
+func (ap *availablePlugins) callStats() map[string]callCounters {
+	ap.callsMutex.Lock()
+	defer ap.callsMutex.Unlock()
+	stats := make(map[string]callCounters, len(ap.calls))
+	for key, c := range ap.calls {
+		stats[key] = *c
 	}
+	return stats
+}
+
+// pids returns the process IDs of the running instances of the plugin of the
+// given pool key
+func (ap *availablePlugins) pids(key string) []int {
+	pids := []int{}
+	ap.RLock()
+	pool, ok := ap.table[key]
+	ap.RUnlock()
+	if !ok {
+		return pids
+	}
+	for _, p := range poolPlugins(pool) {
+		a, ok := p.(*availablePlugin)
+		if !ok {
+			continue
+		}
+		ep, ok := a.ePlugin.(interface {
+			Pid() int
+		})
+		if !ok {
+			continue
+		}
+		if pid := ep.Pid(); pid > 0 {
+			pids = append(pids, pid)
+		}
+	}
+	sort.Ints(pids)
+	return pids
+}
+
+// setConfig pushes the config to the running instances of the plugin with
+// the given key, it returns the number of instances which applied it.
+// Instances which don't support updating their config are skipped.
+func (ap *availablePlugins) setConfig(key string, config map[string]ctypes.ConfigValue) (int, []error) {
+	ap.RLock()
+	pool, ok := ap.table[key]
+	ap.RUnlock()
+	if !ok {
+		return 0, nil
+	}
+	var errs []error
+	updated := 0
+	// the instances are called without holding the lock of the pool
+	for _, p := range poolPlugins(pool) {
+		a, ok := p.(*availablePlugin)
+		if !ok {
+			continue
+		}
+		cli, ok := a.client.(client.PluginConfigClient)
+		if !ok {
+			continue
+		}
+		err := cli.SetConfig(config)
+		if err == client.ErrSetConfigUnsupported {
+			log.WithFields(log.Fields{
+				"_module":          "control-aplugin",
+				"_block":           "set-config",
+				"available-plugin": a.String(),
+			}).Debug(err)
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", a.String(), err))
+			continue
+		}
+		updated++
+	}
+	return updated, errs
 }
 
 func (ap *availablePlugins) insert(pl *availablePlugin) error {
@@ -443,6 +643,9 @@ func (ap *availablePlugins) collectMetrics(pluginKey string, metricTypes []core.
 	if pool == nil {
 		return nil, serror.New(ErrPoolNotFound, map[string]interface{}{"pool-key": pluginKey})
 	}
+	if serr := checkDraining(pool, pluginKey); serr != nil {
+		return nil, serr
+	}
 	// If the strategy is nil but the pool exists we likely are waiting on the pool to be fully initialized
 	// because of a plugin load/unload event that is currently being processed. Prevents panic from using nil
 	// RoutingAndCaching.
@@ -468,6 +671,7 @@ func (ap *availablePlugins) collectMetrics(pluginKey string, metricTypes []core.
 	if serr != nil {
 		return nil, serr
 	}
+	defer pool.Done(p)
 
 	// cast client to PluginCollectorClient
 	cli, ok := p.(*availablePlugin).client.(client.PluginCollectorClient)
@@ -476,7 +680,9 @@ func (ap *availablePlugins) collectMetrics(pluginKey string, metricTypes []core.
 	}
 
 	// collect metrics
+	start := time.Now()
 	metrics, err := cli.CollectMetrics(metricsToCollect)
+	ap.countCall(pluginKey, err != nil, time.Since(start))
 	if err != nil {
 		return nil, serror.New(err)
 	}
@@ -515,6 +721,9 @@ func (ap *availablePlugins) streamMetrics(
 	if pool == nil {
 		return nil, nil, serror.New(ErrPoolNotFound, map[string]interface{}{"pool-key": pluginKey})
 	}
+	if serr := checkDraining(pool, pluginKey); serr != nil {
+		return nil, nil, serr
+	}
 
 	if pool.Strategy() == nil {
 		return nil, nil, errors.New("Plugin strategy not set")
@@ -532,13 +741,16 @@ func (ap *availablePlugins) streamMetrics(
 	if serr != nil {
 		return nil, nil, serr
 	}
+	defer pool.Done(p)
 
 	cli, ok := p.(*availablePlugin).client.(client.PluginStreamCollectorClient)
 	if !ok {
 		return nil, nil, serror.New(errors.New("Invalid streaming client"))
 	}
 
+	start := time.Now()
 	metricChan, errChan, err := cli.StreamMetrics(metricTypes)
+	ap.countCall(pluginKey, err != nil, time.Since(start))
 	if err != nil {
 		return nil, nil, serror.New(err)
 	}
@@ -563,6 +775,9 @@ func (ap *availablePlugins) publishMetrics(metrics []core.Metric, pluginName str
 	if pool == nil {
 		return []error{serror.New(ErrPoolNotFound, map[string]interface{}{"pool-key": key})}
 	}
+	if serr := checkDraining(pool, key); serr != nil {
+		return []error{serr}
+	}
 
 	pool.RLock()
 	defer pool.RUnlock()
@@ -571,13 +786,16 @@ func (ap *availablePlugins) publishMetrics(metrics []core.Metric, pluginName str
 	if serr != nil {
 		return []error{serr}
 	}
+	defer pool.Done(p)
 
 	cli, ok := p.(*availablePlugin).client.(client.PluginPublisherClient)
 	if !ok {
 		return []error{errors.New("unable to cast client to PluginPublisherClient")}
 	}
 
+	start := time.Now()
 	err := cli.Publish(metrics, config)
+	ap.countCall(key, err != nil, time.Since(start))
 	if err != nil {
 		return []error{err}
 	}
@@ -597,6 +815,9 @@ func (ap *availablePlugins) processMetrics(metrics []core.Metric, pluginName str
 	if pool == nil {
 		return nil, []error{serror.New(ErrPoolNotFound, map[string]interface{}{"pool-key": key})}
 	}
+	if serr := checkDraining(pool, key); serr != nil {
+		return nil, []error{serr}
+	}
 
 	pool.RLock()
 	defer pool.RUnlock()
@@ -605,13 +826,16 @@ func (ap *availablePlugins) processMetrics(metrics []core.Metric, pluginName str
 		errs = append(errs, err)
 		return nil, errs
 	}
+	defer pool.Done(p)
 
 	cli, ok := p.(*availablePlugin).client.(client.PluginProcessorClient)
 	if !ok {
 		return nil, []error{errors.New("unable to cast client to PluginProcessorClient")}
 	}
 
+	start := time.Now()
 	mts, errp := cli.Process(metrics, config)
+	ap.countCall(key, errp != nil, time.Since(start))
 	if errp != nil {
 		return nil, []error{errp}
 	}
@@ -620,6 +844,23 @@ func (ap *availablePlugins) processMetrics(metrics []core.Metric, pluginName str
 	return mts, nil
 }
 
+// poolPlugins returns a snapshot of the available plugins of the pool taken
+// under its read lock
+func poolPlugins(pool strategy.Pool) []strategy.AvailablePlugin {
+	pool.RLock()
+	defer pool.RUnlock()
+	return pool.Plugins().Values()
+}
+
+// checkDraining returns an error when the pool is drained for its plugin to be
+// unloaded, without waiting for the read lock of the pool the drain holds
+func checkDraining(pool strategy.Pool, key string) serror.SnapError {
+	if pool.Draining() {
+		return serror.New(strategy.ErrPoolDraining, map[string]interface{}{"pool-key": key})
+	}
+	return nil
+}
+
 func (ap *availablePlugins) findLatestPool(pType, name string) (strategy.Pool, serror.SnapError) {
 	// see if there exists a pool at all which matches name version.
 	var latest strategy.Pool
@@ -659,6 +900,37 @@ func (ap *availablePlugins) getOrCreatePool(key string) (strategy.Pool, error) {
 	return pool, nil
 }
 
+// removePool removes the pool of the given key
+func (ap *availablePlugins) removePool(key string) {
+	ap.Lock()
+	defer ap.Unlock()
+	delete(ap.table, key)
+	ap.callsMutex.Lock()
+	delete(ap.calls, key)
+	ap.callsMutex.Unlock()
+}
+
+// killIdle kills the instances of the pools which have been idle for longer
+// than the idle timeout of their pool
+func (ap *availablePlugins) killIdle() {
+	ap.RLock()
+	pools := make(map[string]strategy.Pool, len(ap.table))
+	for key, pool := range ap.table {
+		pools[key] = pool
+	}
+	ap.RUnlock()
+	for key, pool := range pools {
+		if killed := pool.KillIdle("idle timeout"); killed > 0 {
+			log.WithFields(log.Fields{
+				"_module": "control-aplugin",
+				"_block":  "kill-idle",
+				"pool":    key,
+				"killed":  killed,
+			}).Info("idle plugins killed")
+		}
+	}
+}
+
 func (ap *availablePlugins) pools() map[string]strategy.Pool {
 	ap.RLock()
 	defer ap.RUnlock()
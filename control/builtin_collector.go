@@ -0,0 +1,64 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"time"
+
+	"github.com/intelsdi-x/snap/core"
+)
+
+// BuiltinState is the state of a cataloged plugin run by control itself, its
+// metrics aren't persisted with the metric catalog
+const BuiltinState pluginState = "builtin"
+
+// builtinCollector is a collector run by control itself instead of a plugin,
+// its metrics are cataloged when control starts
+type builtinCollector interface {
+	metricTypes() []*metricType
+	collect(metrics []core.Metric, now time.Time) ([]core.Metric, error)
+}
+
+func newBuiltinCollectors(p *pluginControl) map[string]builtinCollector {
+	collectors := map[string]builtinCollector{}
+	if p.Config != nil && p.Config.SelfMetrics {
+		collectors[SelfCollectorName] = newSelfCollector(p)
+	}
+	return collectors
+}
+
+// builtinCollector returns the built-in collector of the plugin, nil when the
+// plugin isn't a built-in collector
+func (p *pluginControl) builtinCollector(typeName, name string) builtinCollector {
+	if p == nil || typeName != core.CollectorPluginType.String() {
+		return nil
+	}
+	return p.builtinCollectors[name]
+}
+
+// catalogBuiltinCollectors adds the metrics of the built-in collectors to the
+// metric catalog
+func (p *pluginControl) catalogBuiltinCollectors() {
+	for _, bc := range p.builtinCollectors {
+		for _, mt := range bc.metricTypes() {
+			p.metricCatalog.Add(mt)
+		}
+	}
+}
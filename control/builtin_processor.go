@@ -0,0 +1,53 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"time"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/ctypes"
+)
+
+// builtinProcessor is a processor run by control itself instead of a plugin,
+// the workflows refer to it by its name with any version
+type builtinProcessor interface {
+	// validate returns an error when the config of a step is invalid
+	validate(config map[string]ctypes.ConfigValue) error
+	process(metrics []core.Metric, config map[string]ctypes.ConfigValue, taskID string, now time.Time) ([]core.Metric, []error)
+	// remove forgets the state kept for the steps of the task
+	remove(taskID string)
+}
+
+func newBuiltinProcessors() map[string]builtinProcessor {
+	return map[string]builtinProcessor{
+		AggregateProcessorName: newAggregator(),
+		RelabelProcessorName:   relabeler{},
+	}
+}
+
+// builtinProcessor returns the built-in processor of the plugin, nil when the
+// plugin isn't a built-in processor
+func (p *pluginControl) builtinProcessor(typeName, name string) builtinProcessor {
+	if p == nil || typeName != core.ProcessorPluginType.String() {
+		return nil
+	}
+	return p.builtinProcessors[name]
+}
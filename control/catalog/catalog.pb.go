@@ -0,0 +1,137 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by protoc-gen-go.
+// source: github.com/intelsdi-x/snap/control/catalog/catalog.proto
+// DO NOT EDIT!
+
+/*
+Package catalog is a generated protocol buffer package.
+
+It is generated from these files:
+	github.com/intelsdi-x/snap/control/catalog/catalog.proto
+
+It has these top-level messages:
+	Snapshot
+	Plugin
+*/
+package catalog
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import rpc "github.com/intelsdi-x/snap/control/plugin/rpc"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+// A snapshot of the metric catalog, exported or imported by snapteld
+type Snapshot struct {
+	Plugins []*Plugin `protobuf:"bytes,1,rep,name=Plugins" json:"Plugins,omitempty"`
+}
+
+func (m *Snapshot) Reset()                    { *m = Snapshot{} }
+func (m *Snapshot) String() string            { return proto.CompactTextString(m) }
+func (*Snapshot) ProtoMessage()               {}
+func (*Snapshot) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+func (m *Snapshot) GetPlugins() []*Plugin {
+	if m != nil {
+		return m.Plugins
+	}
+	return nil
+}
+
+// A plugin and the metrics it exposes
+type Plugin struct {
+	TypeName     string                    `protobuf:"bytes,1,opt,name=TypeName" json:"TypeName,omitempty"`
+	Name         string                    `protobuf:"bytes,2,opt,name=Name" json:"Name,omitempty"`
+	Version      int64                     `protobuf:"varint,3,opt,name=Version" json:"Version,omitempty"`
+	Signed       bool                      `protobuf:"varint,4,opt,name=Signed" json:"Signed,omitempty"`
+	Path         string                    `protobuf:"bytes,5,opt,name=Path" json:"Path,omitempty"`
+	LoadedTime   *rpc.Time                 `protobuf:"bytes,6,opt,name=LoadedTime" json:"LoadedTime,omitempty"`
+	ConfigPolicy *rpc.GetConfigPolicyReply `protobuf:"bytes,7,opt,name=ConfigPolicy" json:"ConfigPolicy,omitempty"`
+	Metrics      []*rpc.Metric             `protobuf:"bytes,8,rep,name=Metrics" json:"Metrics,omitempty"`
+}
+
+func (m *Plugin) Reset()                    { *m = Plugin{} }
+func (m *Plugin) String() string            { return proto.CompactTextString(m) }
+func (*Plugin) ProtoMessage()               {}
+func (*Plugin) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *Plugin) GetLoadedTime() *rpc.Time {
+	if m != nil {
+		return m.LoadedTime
+	}
+	return nil
+}
+
+func (m *Plugin) GetConfigPolicy() *rpc.GetConfigPolicyReply {
+	if m != nil {
+		return m.ConfigPolicy
+	}
+	return nil
+}
+
+func (m *Plugin) GetMetrics() []*rpc.Metric {
+	if m != nil {
+		return m.Metrics
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*Snapshot)(nil), "catalog.Snapshot")
+	proto.RegisterType((*Plugin)(nil), "catalog.Plugin")
+}
+
+func init() {
+	proto.RegisterFile("github.com/intelsdi-x/snap/control/catalog/catalog.proto", fileDescriptor0)
+}
+
+var fileDescriptor0 = []byte{
+	// 297 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x8c, 0x90, 0xc1, 0x4b, 0xfb, 0x30,
+	0x1c, 0xc5, 0xc9, 0xb6, 0x5f, 0xdb, 0x7d, 0xf7, 0x03, 0x21, 0x07, 0x89, 0x3b, 0x95, 0x81, 0xd0,
+	0x1d, 0x6c, 0x61, 0x22, 0x88, 0xe0, 0xc9, 0x83, 0x17, 0x95, 0x91, 0x0d, 0xef, 0x59, 0x1a, 0xbb,
+	0x40, 0x96, 0x84, 0x24, 0x03, 0xf7, 0x57, 0xf8, 0x2f, 0x4b, 0xd3, 0x56, 0xe6, 0xcd, 0x53, 0xdf,
+	0x7b, 0x9f, 0xd7, 0x17, 0xf8, 0xc2, 0x7d, 0x23, 0xc3, 0xfe, 0xb8, 0x2b, 0xb9, 0x39, 0x54, 0x52,
+	0x07, 0xa1, 0x7c, 0x2d, 0x6f, 0x3e, 0x2b, 0xaf, 0x99, 0xad, 0xb8, 0xd1, 0xc1, 0x19, 0x55, 0x71,
+	0x16, 0x98, 0x32, 0xcd, 0xf0, 0x2d, 0xad, 0x33, 0xc1, 0xe0, 0xb4, 0xb7, 0xf3, 0x87, 0x3f, 0x4c,
+	0x58, 0x75, 0x6c, 0xa4, 0xae, 0x9c, 0xe5, 0xbd, 0xec, 0x46, 0x16, 0x77, 0x90, 0x6d, 0x34, 0xb3,
+	0x7e, 0x6f, 0x02, 0x5e, 0x42, 0xba, 0x8e, 0xcc, 0x13, 0x94, 0x8f, 0x8b, 0xd9, 0xea, 0xa2, 0x1c,
+	0x5e, 0xec, 0x72, 0x3a, 0xf0, 0xc5, 0xd7, 0x08, 0x92, 0x4e, 0xe3, 0x39, 0x64, 0xdb, 0x93, 0x15,
+	0x6f, 0xec, 0x20, 0x08, 0xca, 0x51, 0x31, 0xa5, 0x3f, 0x1e, 0x63, 0x98, 0xc4, 0x7c, 0x14, 0xf3,
+	0xa8, 0x31, 0x81, 0xf4, 0x5d, 0x38, 0x2f, 0x8d, 0x26, 0xe3, 0x1c, 0x15, 0x63, 0x3a, 0x58, 0x7c,
+	0x09, 0xc9, 0x46, 0x36, 0x5a, 0xd4, 0x64, 0x92, 0xa3, 0x22, 0xa3, 0xbd, 0x6b, 0x57, 0xd6, 0x2c,
+	0xec, 0xc9, 0xbf, 0x6e, 0xa5, 0xd5, 0x78, 0x09, 0xf0, 0x62, 0x58, 0x2d, 0xea, 0xad, 0x3c, 0x08,
+	0x92, 0xe4, 0xa8, 0x98, 0xad, 0xa6, 0xa5, 0xb3, 0xbc, 0x6c, 0x03, 0x7a, 0x06, 0xf1, 0x23, 0xfc,
+	0x7f, 0x32, 0xfa, 0x43, 0x36, 0x6b, 0xa3, 0x24, 0x3f, 0x91, 0x34, 0x96, 0xaf, 0x62, 0xf9, 0x59,
+	0x84, 0x73, 0x46, 0x85, 0x55, 0x27, 0xfa, 0xab, 0x8e, 0xaf, 0x21, 0x7d, 0x15, 0xc1, 0x49, 0xee,
+	0x49, 0x16, 0xaf, 0x32, 0x8b, 0x7f, 0x76, 0x19, 0x1d, 0xd8, 0x2e, 0x89, 0xf7, 0xbc, 0xfd, 0x1e,
+	0x00, 0xe7, 0x42, 0xb7, 0x57, 0xd0, 0x01, 0x00, 0x00,
+}
@@ -0,0 +1,210 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/intelsdi-x/snap/control/catalog"
+	"github.com/intelsdi-x/snap/control/plugin/client"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/control/plugin/rpc"
+)
+
+const (
+	// CatalogFormatJSON exports the metric catalog as JSON, the same format
+	// is used for the persisted catalog (see catalog_path)
+	CatalogFormatJSON = "json"
+	// CatalogFormatProtobuf exports the metric catalog as a protobuf message
+	CatalogFormatProtobuf = "protobuf"
+)
+
+func errorUnsupportedCatalogFormat(format string) error {
+	return fmt.Errorf("Unsupported metric catalog format: %s (supported formats: %s, %s)", format, CatalogFormatJSON, CatalogFormatProtobuf)
+}
+
+// Export writes all metric types of the catalog to w in the given format
+// (CatalogFormatJSON or CatalogFormatProtobuf), an empty catalog results in an
+// empty snapshot
+func (mc *metricCatalog) Export(w io.Writer, format string) error {
+	var mts []*metricType
+	it := mc.Iterate()
+	for it.Next() {
+		_, versions := it.Item()
+		mts = append(mts, versions...)
+	}
+	sps := toStoredPlugins(mts)
+
+	var (
+		b   []byte
+		err error
+	)
+	switch format {
+	case CatalogFormatJSON:
+		b, err = json.MarshalIndent(sps, "", "  ")
+	case CatalogFormatProtobuf:
+		var snapshot *catalog.Snapshot
+		snapshot, err = toCatalogSnapshot(sps)
+		if err == nil {
+			b, err = proto.Marshal(snapshot)
+		}
+	default:
+		err = errorUnsupportedCatalogFormat(format)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// Import reads metric types exported in the given format from r and adds them
+// to the catalog.  As with the persisted catalog the imported metrics are
+// replaced by the ones advertised by plugins when they get loaded.  Metric types
+// already in the catalog are kept, the imported ones with the same namespace and
+// version are skipped.
+func (mc *metricCatalog) Import(r io.Reader, format string) error {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	var sps []storedPlugin
+	switch format {
+	case CatalogFormatJSON:
+		err = json.Unmarshal(b, &sps)
+	case CatalogFormatProtobuf:
+		snapshot := &catalog.Snapshot{}
+		if err = proto.Unmarshal(b, snapshot); err == nil {
+			sps = fromCatalogSnapshot(snapshot)
+		}
+	default:
+		err = errorUnsupportedCatalogFormat(format)
+	}
+	if err != nil {
+		return err
+	}
+	mts, err := fromStoredPlugins(sps)
+	if err != nil {
+		return err
+	}
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+	for _, mt := range mts {
+		if node, err := mc.tree.find(mt.Namespace().Strings()); err == nil {
+			if _, ok := node.mts[mt.Version()]; ok {
+				continue
+			}
+		}
+		mc.add(mt)
+	}
+	return nil
+}
+
+func toCatalogSnapshot(sps []storedPlugin) (*catalog.Snapshot, error) {
+	snapshot := &catalog.Snapshot{}
+	for _, sp := range sps {
+		if sp.ConfigPolicy == nil {
+			sp.ConfigPolicy = cpolicy.New()
+		}
+		policy, err := rpc.NewGetConfigPolicyReply(sp.ConfigPolicy)
+		if err != nil {
+			return nil, err
+		}
+		p := &catalog.Plugin{
+			TypeName:     sp.TypeName,
+			Name:         sp.Name,
+			Version:      int64(sp.Version),
+			Signed:       sp.Signed,
+			Path:         sp.Path,
+			LoadedTime:   toRPCTime(sp.LoadedTime),
+			ConfigPolicy: policy,
+		}
+		for _, sm := range sp.Metrics {
+			p.Metrics = append(p.Metrics, &rpc.Metric{
+				Namespace:          client.ToNamespace(sm.Namespace),
+				Version:            int64(sm.Version),
+				LastAdvertisedTime: toRPCTime(sm.LastAdvertisedTime),
+				Tags:               sm.Tags,
+				Description:        sm.Description,
+				Unit:               sm.Unit,
+				DataType:           sm.DataType,
+			})
+		}
+		snapshot.Plugins = append(snapshot.Plugins, p)
+	}
+	return snapshot, nil
+}
+
+func fromCatalogSnapshot(snapshot *catalog.Snapshot) []storedPlugin {
+	sps := make([]storedPlugin, 0, len(snapshot.Plugins))
+	for _, p := range snapshot.Plugins {
+		sp := storedPlugin{
+			TypeName:   p.TypeName,
+			Name:       p.Name,
+			Version:    int(p.Version),
+			Signed:     p.Signed,
+			Path:       p.Path,
+			LoadedTime: fromRPCTime(p.LoadedTime),
+		}
+		if p.ConfigPolicy != nil {
+			sp.ConfigPolicy = rpc.ToConfigPolicy(p.ConfigPolicy)
+		}
+		for _, m := range p.Metrics {
+			sp.Metrics = append(sp.Metrics, storedMetric{
+				Namespace:          client.ToCoreNamespace(m.Namespace),
+				Version:            int(m.Version),
+				LastAdvertisedTime: fromRPCTime(m.LastAdvertisedTime),
+				Tags:               m.Tags,
+				Description:        m.Description,
+				Unit:               m.Unit,
+				DataType:           m.DataType,
+			})
+		}
+		sps = append(sps, sp)
+	}
+	return sps
+}
+
+func toRPCTime(t time.Time) *rpc.Time {
+	return &rpc.Time{Sec: t.Unix(), Nsec: int64(t.Nanosecond())}
+}
+
+func fromRPCTime(t *rpc.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return time.Unix(t.Sec, t.Nsec)
+}
+
+// ExportMetricCatalog writes a snapshot of the metric catalog to w in the given format
+func (p *pluginControl) ExportMetricCatalog(w io.Writer, format string) error {
+	return p.metricCatalog.Export(w, format)
+}
+
+// ImportMetricCatalog preloads the metric catalog with a snapshot read from r
+func (p *pluginControl) ImportMetricCatalog(r io.Reader, format string) error {
+	return p.metricCatalog.Import(r, format)
+}
@@ -0,0 +1,114 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCatalogExportImport(t *testing.T) {
+	Convey("Given a metric catalog", t, func() {
+		node := cpolicy.NewPolicyNode()
+		rule, _ := cpolicy.NewIntegerRule("interval", true, 10)
+		node.Add(rule)
+		policy := cpolicy.New()
+		policy.Add([]string{"intel", "mock"}, node)
+		cp := &catalogedPlugin{
+			name:         "mock",
+			version:      1,
+			typeName:     plugin.CollectorPluginType,
+			state:        LoadedState,
+			configPolicy: policy,
+		}
+		ns := core.NewNamespace("intel", "mock").AddDynamicElement("host", "host id").AddStaticElement("baz")
+		mc := newMetricCatalog()
+		mc.Add(&metricType{
+			Plugin:             cp,
+			namespace:          ns,
+			version:            1,
+			lastAdvertisedTime: time.Unix(1480000000, 0),
+			tags:               map[string]string{"foo": "bar"},
+			description:        "mock metric",
+			unit:               "B",
+			dataType:           "uint64",
+		})
+
+		for _, format := range []string{CatalogFormatJSON, CatalogFormatProtobuf} {
+			Convey("exported as "+format, func() {
+				var buf bytes.Buffer
+				So(mc.Export(&buf, format), ShouldBeNil)
+
+				Convey("can be imported into another catalog", func() {
+					imported := newMetricCatalog()
+					So(imported.Import(&buf, format), ShouldBeNil)
+					mts, err := imported.Fetch(core.Namespace{})
+					So(err, ShouldBeNil)
+					So(len(mts), ShouldEqual, 1)
+					mt := mts[0]
+					So(mt.Namespace(), ShouldResemble, ns)
+					So(mt.Version(), ShouldEqual, 1)
+					So(mt.LastAdvertisedTime().Equal(time.Unix(1480000000, 0)), ShouldBeTrue)
+					So(mt.Tags(), ShouldResemble, map[string]string{"foo": "bar"})
+					So(mt.Description(), ShouldEqual, "mock metric")
+					So(mt.Unit(), ShouldEqual, "B")
+					So(mt.DataType(), ShouldEqual, "uint64")
+					So(mt.Plugin.Name(), ShouldEqual, "mock")
+					So(mt.Plugin.Status(), ShouldEqual, string(RestoredState))
+					So(mt.Policy().Defaults(), ShouldContainKey, "interval")
+				})
+				Convey("does not replace the metric types already in the catalog", func() {
+					So(mc.Import(&buf, format), ShouldBeNil)
+					mts, err := mc.Fetch(core.Namespace{})
+					So(err, ShouldBeNil)
+					So(len(mts), ShouldEqual, 1)
+					So(mts[0].Plugin.Status(), ShouldEqual, string(LoadedState))
+				})
+			})
+		}
+
+		Convey("exported while empty", func() {
+			for _, format := range []string{CatalogFormatJSON, CatalogFormatProtobuf} {
+				var buf bytes.Buffer
+				So(newMetricCatalog().Export(&buf, format), ShouldBeNil)
+				imported := newMetricCatalog()
+				So(imported.Import(&buf, format), ShouldBeNil)
+				So(imported.Keys(), ShouldBeEmpty)
+			}
+		})
+		Convey("exporting in an unsupported format fails", func() {
+			var buf bytes.Buffer
+			So(mc.Export(&buf, "xml"), ShouldNotBeNil)
+			So(buf.Len(), ShouldEqual, 0)
+		})
+		Convey("importing in an unsupported format fails", func() {
+			So(mc.Import(bytes.NewBufferString("{}"), "xml"), ShouldNotBeNil)
+		})
+	})
+}
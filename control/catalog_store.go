@@ -0,0 +1,241 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/core"
+)
+
+// RestoredState is the state of a cataloged plugin whose metrics were restored
+// from the persisted metric catalog and which has not been loaded yet
+const RestoredState pluginState = "restored"
+
+var catalogStoreLogger = log.WithField("_module", "control-catalog-store")
+
+// catalogStore persists the metric catalog in a file, so after snapteld
+// restart the catalog can be restored before the plugins are (auto)loaded again
+type catalogStore struct {
+	path  string
+	mutex *sync.Mutex
+}
+
+// storedPlugin is the persisted form of a plugin along with the metrics it exposes
+type storedPlugin struct {
+	TypeName     string                `json:"type"`
+	Name         string                `json:"name"`
+	Version      int                   `json:"version"`
+	Signed       bool                  `json:"signed"`
+	Path         string                `json:"path"`
+	LoadedTime   time.Time             `json:"loaded_timestamp"`
+	ConfigPolicy *cpolicy.ConfigPolicy `json:"config_policy"`
+	Metrics      []storedMetric        `json:"metrics"`
+}
+
+// storedMetric is the persisted form of a cataloged metric type
+type storedMetric struct {
+	Namespace          core.Namespace    `json:"namespace"`
+	Version            int               `json:"version"`
+	LastAdvertisedTime time.Time         `json:"last_advertised_timestamp"`
+	Tags               map[string]string `json:"tags,omitempty"`
+	Description        string            `json:"description,omitempty"`
+	Unit               string            `json:"unit,omitempty"`
+	DataType           string            `json:"data_type,omitempty"`
+}
+
+func newCatalogStore(path string) *catalogStore {
+	return &catalogStore{
+		path:  path,
+		mutex: &sync.Mutex{},
+	}
+}
+
+// save writes all metric types to the catalog file grouped by the plugin which exposes them
+func (s *catalogStore) save(mts []*metricType) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	b, err := json.Marshal(toStoredPlugins(mts))
+	if err != nil {
+		return err
+	}
+	// write to a temporary file first so the catalog file is never left half-written
+	tmp := s.path + ".tmp"
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// load reads the catalog file and returns the metric types it contains.
+// A missing catalog file results in an empty catalog.
+func (s *catalogStore) load() ([]*metricType, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	b, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var sps []storedPlugin
+	if err := json.Unmarshal(b, &sps); err != nil {
+		return nil, fmt.Errorf("Unable to parse metric catalog file %s: %v", s.path, err)
+	}
+	return fromStoredPlugins(sps)
+}
+
+func toStoredPlugins(mts []*metricType) []storedPlugin {
+	sps := []storedPlugin{}
+	index := map[string]int{}
+	for _, mt := range mts {
+		if mt.Plugin == nil || mt.Plugin.Status() == string(BuiltinState) {
+			continue
+		}
+		key := fmt.Sprintf("%s"+core.Separator+"%s"+core.Separator+"%d", mt.Plugin.TypeName(), mt.Plugin.Name(), mt.Plugin.Version())
+		i, ok := index[key]
+		if !ok {
+			sp := storedPlugin{
+				TypeName:     mt.Plugin.TypeName(),
+				Name:         mt.Plugin.Name(),
+				Version:      mt.Plugin.Version(),
+				Signed:       mt.Plugin.IsSigned(),
+				Path:         mt.Plugin.PluginPath(),
+				ConfigPolicy: mt.Plugin.Policy(),
+			}
+			if ts := mt.Plugin.LoadedTimestamp(); ts != nil {
+				sp.LoadedTime = *ts
+			}
+			sps = append(sps, sp)
+			i = len(sps) - 1
+			index[key] = i
+		}
+		sps[i].Metrics = append(sps[i].Metrics, storedMetric{
+			Namespace:          mt.Namespace(),
+			Version:            mt.Version(),
+			LastAdvertisedTime: mt.LastAdvertisedTime(),
+			Tags:               mt.Tags(),
+			Description:        mt.Description(),
+			Unit:               mt.Unit(),
+			DataType:           mt.DataType(),
+		})
+	}
+	return sps
+}
+
+func fromStoredPlugins(sps []storedPlugin) ([]*metricType, error) {
+	var mts []*metricType
+	for _, sp := range sps {
+		typ, err := core.ToPluginType(sp.TypeName)
+		if err != nil {
+			return nil, err
+		}
+		if sp.ConfigPolicy == nil {
+			sp.ConfigPolicy = cpolicy.New()
+		}
+		cp := &catalogedPlugin{
+			name:         sp.Name,
+			version:      sp.Version,
+			signed:       sp.Signed,
+			typeName:     plugin.PluginType(typ),
+			state:        RestoredState,
+			path:         sp.Path,
+			loadedTime:   sp.LoadedTime,
+			configPolicy: sp.ConfigPolicy,
+		}
+		for _, sm := range sp.Metrics {
+			if err := validateMetricNamespace(sm.Namespace); err != nil {
+				return nil, err
+			}
+			mts = append(mts, &metricType{
+				Plugin:             cp,
+				namespace:          sm.Namespace,
+				version:            sm.Version,
+				lastAdvertisedTime: sm.LastAdvertisedTime,
+				tags:               sm.Tags,
+				policy:             sp.ConfigPolicy.Get(sm.Namespace.Strings()),
+				description:        sm.Description,
+				unit:               sm.Unit,
+				dataType:           sm.DataType,
+			})
+		}
+	}
+	return mts, nil
+}
+
+// restoreCatalog adds the metric types found in the catalog file to the metric catalog.
+// Restored metrics are kept until a plugin is loaded from the same path, which replaces
+// them with the ones it advertises, or until their plugin is unloaded.
+func (p *pluginControl) restoreCatalog() {
+	if p.catalogStore == nil {
+		return
+	}
+	mts, err := p.catalogStore.load()
+	if err != nil {
+		catalogStoreLogger.WithFields(log.Fields{
+			"_block": "restore-catalog",
+			"path":   p.catalogStore.path,
+			"error":  err,
+		}).Error("unable to restore metric catalog")
+		return
+	}
+	p.metricCatalog.AddMany(mts)
+	catalogStoreLogger.WithFields(log.Fields{
+		"_block":  "restore-catalog",
+		"path":    p.catalogStore.path,
+		"metrics": len(mts),
+	}).Info("metric catalog restored")
+}
+
+// persistCatalog writes the current state of the metric catalog to the catalog file
+func (p *pluginControl) persistCatalog() {
+	if p.catalogStore == nil {
+		return
+	}
+	var mts []*metricType
+	it := p.metricCatalog.Iterate()
+	for it.Next() {
+		_, versions := it.Item()
+		mts = append(mts, versions...)
+	}
+	if err := p.catalogStore.save(mts); err != nil {
+		catalogStoreLogger.WithFields(log.Fields{
+			"_block": "persist-catalog",
+			"path":   p.catalogStore.path,
+			"error":  err,
+		}).Error("unable to persist metric catalog")
+	}
+}
@@ -0,0 +1,127 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCatalogStore(t *testing.T) {
+	Convey("catalogStore", t, func() {
+		dir, err := ioutil.TempDir("", "snap-catalog")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		store := newCatalogStore(filepath.Join(dir, "catalog.json"))
+
+		Convey("loads an empty catalog when the file does not exist", func() {
+			mts, err := store.load()
+			So(err, ShouldBeNil)
+			So(mts, ShouldBeEmpty)
+		})
+
+		Convey("restores saved metric types", func() {
+			node := cpolicy.NewPolicyNode()
+			rule, _ := cpolicy.NewStringRule("user", false, "root")
+			node.Add(rule)
+			policy := cpolicy.New()
+			policy.Add([]string{"intel", "mock"}, node)
+
+			cp := &catalogedPlugin{
+				name:         "mock",
+				version:      2,
+				typeName:     plugin.CollectorPluginType,
+				state:        LoadedState,
+				path:         "/opt/snap/plugins/snap-plugin-collector-mock",
+				configPolicy: policy,
+			}
+			ns := core.NewNamespace("intel", "mock").AddDynamicElement("host", "host id").AddStaticElement("baz")
+			mt := &metricType{
+				Plugin:             cp,
+				namespace:          ns,
+				version:            2,
+				lastAdvertisedTime: time.Now(),
+				unit:               "bytes",
+				dataType:           "uint64",
+				tags:               map[string]string{"foo": "bar"},
+			}
+			So(store.save([]*metricType{mt}), ShouldBeNil)
+
+			mts, err := store.load()
+			So(err, ShouldBeNil)
+			So(len(mts), ShouldEqual, 1)
+			So(mts[0].Namespace(), ShouldResemble, ns)
+			So(mts[0].Version(), ShouldEqual, 2)
+			So(mts[0].Unit(), ShouldEqual, "bytes")
+			So(mts[0].DataType(), ShouldEqual, "uint64")
+			So(mts[0].Tags(), ShouldResemble, mt.Tags())
+			So(mts[0].Plugin.Name(), ShouldEqual, "mock")
+			So(mts[0].Plugin.TypeName(), ShouldEqual, "collector")
+			So(mts[0].Plugin.Status(), ShouldEqual, string(RestoredState))
+			So(mts[0].Policy().Defaults(), ShouldContainKey, "user")
+
+			Convey("which can be added to the metric catalog", func() {
+				mc := newMetricCatalog()
+				mc.Add(mts[0])
+				m, err := mc.GetMetric(core.NewNamespace("intel", "mock", "host0", "baz"), -1)
+				So(err, ShouldBeNil)
+				So(m.Version(), ShouldEqual, 2)
+
+				Convey("and are kept for plugins loaded from another path", func() {
+					removed := mc.RmRestoredMetrics(func(cp core.CatalogedPlugin) bool {
+						return cp.PluginPath() == "/opt/snap/plugins/snap-plugin-collector-other"
+					})
+					So(removed, ShouldBeEmpty)
+					_, err := mc.GetMetric(core.NewNamespace("intel", "mock", "host0", "baz"), -1)
+					So(err, ShouldBeNil)
+				})
+
+				Convey("and are removed once the plugin of their path is loaded", func() {
+					lp := &loadedPlugin{Meta: plugin.PluginMeta{Name: "mock", Version: 3}, Type: plugin.CollectorPluginType, ConfigPolicy: policy, Details: &pluginDetails{Path: cp.path}}
+					mc.Add(newMetricType(core.NewNamespace("intel", "mock", "foo"), time.Now(), lp))
+					removed := mc.RmRestoredMetrics(func(cp core.CatalogedPlugin) bool {
+						return cp.PluginPath() == lp.PluginPath()
+					})
+					So(len(removed), ShouldEqual, 1)
+					_, err := mc.GetMetric(core.NewNamespace("intel", "mock", "host0", "baz"), -1)
+					So(err, ShouldNotBeNil)
+					So(mc.Keys(), ShouldResemble, []string{"/intel/mock/foo"})
+				})
+			})
+		})
+
+		Convey("fails on a corrupted catalog file", func() {
+			So(ioutil.WriteFile(store.path, []byte("{"), 0600), ShouldBeNil)
+			_, err := store.load()
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,174 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/cdata"
+)
+
+// collectionCache shares the metrics collected by the plugins between the
+// tasks: a metric requested with the same version and config again within its
+// cache TTL is taken from the cache instead of being collected by the plugin.
+// The TTL of a metric is given by the cache_ttl tag it is advertised with, or
+// by the default TTL otherwise. A TTL of zero disables the caching.
+type collectionCache struct {
+	sync.Mutex
+	ttl time.Duration
+	// the cached metrics by namespace, version and config hash of the
+	// requested metric
+	entries map[string]*collectionCacheEntry
+}
+
+type collectionCacheEntry struct {
+	expires time.Time
+	metrics []core.Metric
+}
+
+func newCollectionCache(ttl time.Duration) *collectionCache {
+	return &collectionCache{
+		ttl:     ttl,
+		entries: map[string]*collectionCacheEntry{},
+	}
+}
+
+// get returns the requested metrics which have to be collected and the
+// metrics collected for the other ones found in the cache at the given time
+func (c *collectionCache) get(mts []core.Metric, now time.Time) ([]core.Metric, []core.Metric) {
+	if c == nil {
+		return mts, nil
+	}
+	c.Lock()
+	defer c.Unlock()
+	if len(c.entries) == 0 {
+		return mts, nil
+	}
+	var toCollect, cached []core.Metric
+	for _, mt := range mts {
+		key := collectionCacheKey(mt)
+		if e, ok := c.entries[key]; ok {
+			if now.Before(e.expires) {
+				cached = append(cached, e.metrics...)
+				continue
+			}
+			delete(c.entries, key)
+		}
+		toCollect = append(toCollect, mt)
+	}
+	return toCollect, cached
+}
+
+// put caches the metrics collected at the given time for the requested ones,
+// each requested metric caches the collected metrics it matches
+func (c *collectionCache) put(requested, collected []core.Metric, now time.Time) {
+	if c == nil {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	for key, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, key)
+		}
+	}
+	for _, mt := range requested {
+		ttl := c.metricTTL(mt)
+		if ttl <= 0 {
+			continue
+		}
+		var metrics []core.Metric
+		for _, m := range collected {
+			if mt.Namespace().Matches(m.Namespace()) {
+				metrics = append(metrics, m)
+			}
+		}
+		// a metric the plugin returned nothing for is collected again next time
+		if len(metrics) == 0 {
+			continue
+		}
+		c.entries[collectionCacheKey(mt)] = &collectionCacheEntry{
+			expires: now.Add(ttl),
+			metrics: metrics,
+		}
+	}
+}
+
+// metricTTL returns the cache TTL of the metric
+func (c *collectionCache) metricTTL(mt core.Metric) time.Duration {
+	v, ok := mt.Tags()[core.CACHE_TTL_TAG]
+	if !ok {
+		return c.ttl
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		controlLogger.WithFields(log.Fields{
+			"_block": "collection-cache",
+			"value":  v,
+		}).Debug("invalid cache TTL")
+		return c.ttl
+	}
+	return d
+}
+
+func collectionCacheKey(mt core.Metric) string {
+	return fmt.Sprintf("%s"+core.Separator+"%d"+core.Separator+"%x", mt.Namespace().String(), mt.Version(), configHash(mt.Config()))
+}
+
+// configHash returns the hash of the items of the config, zero for a missing config
+func configHash(cfg *cdata.ConfigDataNode) uint64 {
+	if cfg == nil {
+		return 0
+	}
+	table := cfg.Table()
+	keys := make([]string, 0, len(table))
+	for k := range table {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	h := fnv.New64a()
+	for _, k := range keys {
+		fmt.Fprintf(h, "%s=%v;", k, table[k])
+	}
+	return h.Sum64()
+}
+
+// collectCached collects the metrics of the plugin which are not found in the
+// collection cache and caches them
+func (p *pluginControl) collectCached(pluginKey string, mts []core.Metric, taskID string) ([]core.Metric, error) {
+	now := time.Now()
+	toCollect, cached := p.collectionCache.get(mts, now)
+	if len(toCollect) == 0 {
+		return cached, nil
+	}
+	collected, err := p.pluginRunner.AvailablePlugins().collectMetrics(pluginKey, toCollect, taskID)
+	if err != nil {
+		return nil, err
+	}
+	p.collectionCache.put(toCollect, collected, now)
+	return append(cached, collected...), nil
+}
@@ -0,0 +1,104 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/cdata"
+	"github.com/intelsdi-x/snap/core/ctypes"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCollectionCache(t *testing.T) {
+	Convey("collectionCache", t, func() {
+		cfg := cdata.NewNode()
+		cfg.AddItem("password", ctypes.ConfigValueStr{Value: "secret"})
+		dynamic := plugin.MetricType{
+			Namespace_: core.NewNamespace("intel", "mock").AddDynamicElement("host", "host id").AddStaticElement("baz"),
+			Version_:   1,
+			Config_:    cfg,
+			Tags_:      map[string]string{core.CACHE_TTL_TAG: "30s"},
+		}
+		static := plugin.MetricType{
+			Namespace_: core.NewNamespace("intel", "mock", "foo"),
+			Version_:   1,
+			Config_:    cfg,
+		}
+		collected := []core.Metric{
+			plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "host0", "baz"), Version_: 1, Data_: 1},
+			plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "host1", "baz"), Version_: 1, Data_: 2},
+			plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "foo"), Version_: 1, Data_: 3},
+		}
+		requested := []core.Metric{dynamic, static}
+		now := time.Now()
+
+		Convey("caches the metrics advertised with a cache TTL", func() {
+			c := newCollectionCache(0)
+			c.put(requested, collected, now)
+			toCollect, cached := c.get(requested, now.Add(10*time.Second))
+			So(toCollect, ShouldHaveLength, 1)
+			So(toCollect[0].Namespace().String(), ShouldEqual, "/intel/mock/foo")
+			So(cached, ShouldHaveLength, 2)
+			So(cached[0].Data(), ShouldEqual, 1)
+			So(cached[1].Data(), ShouldEqual, 2)
+
+			Convey("until the TTL elapsed", func() {
+				toCollect, cached := c.get(requested, now.Add(30*time.Second))
+				So(toCollect, ShouldHaveLength, 2)
+				So(cached, ShouldBeEmpty)
+			})
+		})
+
+		Convey("caches the other metrics for the default TTL", func() {
+			c := newCollectionCache(time.Minute)
+			c.put(requested, collected, now)
+			toCollect, cached := c.get(requested, now.Add(45*time.Second))
+			So(toCollect, ShouldHaveLength, 1)
+			So(toCollect[0].Namespace().String(), ShouldEqual, dynamic.Namespace().String())
+			So(cached, ShouldHaveLength, 1)
+			So(cached[0].Data(), ShouldEqual, 3)
+		})
+
+		Convey("keeps the metrics requested with other configs apart", func() {
+			c := newCollectionCache(time.Minute)
+			c.put(requested, collected, now)
+			other := cdata.NewNode()
+			other.AddItem("password", ctypes.ConfigValueStr{Value: "other"})
+			static.Config_ = other
+			toCollect, cached := c.get([]core.Metric{static}, now)
+			So(toCollect, ShouldHaveLength, 1)
+			So(cached, ShouldBeEmpty)
+		})
+
+		Convey("does not cache anything without a TTL", func() {
+			c := newCollectionCache(0)
+			dynamic.Tags_ = nil
+			c.put([]core.Metric{dynamic, static}, collected, now)
+			So(c.entries, ShouldBeEmpty)
+		})
+	})
+}
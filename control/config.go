@@ -38,19 +38,32 @@ import (
 
 // default configuration values
 var (
-	defaultListenAddr        = "127.0.0.1"
-	defaultListenPort        = 8082
-	defaultMaxRunningPlugins = 3
-	defaultPluginLoadTimeout = 3
-	defaultPluginTrust       = 1
-	defaultAutoDiscoverPath  = ""
-	defaultKeyringPaths      = ""
-	defaultCacheExpiration   = 500 * time.Millisecond
-	defaultPprof             = false
-	defaultTempDirPath       = os.TempDir()
-	defaultTLSCertPath       = ""
-	defaultTLSKeyPath        = ""
-	defaultCACertPaths       = ""
+	defaultListenAddr             = "127.0.0.1"
+	defaultListenPort             = 8082
+	defaultMaxRunningPlugins      = 3
+	defaultPluginLoadTimeout      = 3
+	defaultPluginTrust            = 1
+	defaultAutoDiscoverPath       = ""
+	defaultKeyringPaths           = ""
+	defaultCacheExpiration        = 500 * time.Millisecond
+	defaultPprof                  = false
+	defaultTempDirPath            = os.TempDir()
+	defaultTLSCertPath            = ""
+	defaultTLSKeyPath             = ""
+	defaultCACertPaths            = ""
+	defaultCatalogPath            = ""
+	defaultPluginWatchPath        = ""
+	defaultPluginCachePath        = ""
+	defaultPluginConfigDir        = ""
+	defaultPluginAutoTLS          = false
+	defaultRestartBackoff         = time.Second
+	defaultPluginResourceLimits   = false
+	defaultPluginContainerImage   = ""
+	defaultPluginBlacklistCrashes = 0
+	defaultPluginBlacklistWindow  = 10 * time.Minute
+	defaultSelfMetrics            = false
+	defaultPermissiveConfigPolicy = false
+	defaultCollectionCacheTTL     = time.Duration(0)
 )
 
 type pluginConfig struct {
@@ -62,8 +75,9 @@ type pluginConfig struct {
 }
 
 type pluginTypeConfigItem struct {
-	Plugins map[string]*pluginConfigItem
-	All     *cdata.ConfigDataNode `json:"all"`
+	Plugins  map[string]*pluginConfigItem
+	All      *cdata.ConfigDataNode `json:"all"`
+	profiles map[string]*pluginConfigItem
 }
 
 type pluginConfigItem struct {
@@ -76,22 +90,40 @@ type pluginConfigItem struct {
 //         UnmarshalJSON method in this same file needs to be modified to
 //         match the field mapping that is defined here
 type Config struct {
-	MaxRunningPlugins int                          `json:"max_running_plugins"yaml:"max_running_plugins"`
-	PluginLoadTimeout int                          `json:"plugin_load_timeout"yaml:"plugin_load_timeout"`
-	PluginTrust       int                          `json:"plugin_trust_level"yaml:"plugin_trust_level"`
-	AutoDiscoverPath  string                       `json:"auto_discover_path"yaml:"auto_discover_path"`
-	KeyringPaths      string                       `json:"keyring_paths"yaml:"keyring_paths"`
-	CacheExpiration   jsonutil.Duration            `json:"cache_expiration"yaml:"cache_expiration"`
-	Plugins           *pluginConfig                `json:"plugins"yaml:"plugins"`
-	Tags              map[string]map[string]string `json:"tags,omitempty"yaml:"tags"`
-	ListenAddr        string                       `json:"listen_addr,omitempty"yaml:"listen_addr"`
-	ListenPort        int                          `json:"listen_port,omitempty"yaml:"listen_port"`
-	Pprof             bool                         `json:"pprof"yaml:"pprof"`
-	MaxPluginRestarts int                          `json:"max_plugin_restarts"yaml:"max_plugin_restarts"`
-	TempDirPath       string                       `json:"temp_dir_path"yaml:"temp_dir_path"`
-	TLSCertPath       string                       `json:"tls_cert_path"yaml:"tls_cert_path"`
-	TLSKeyPath        string                       `json:"tls_key_path"yaml:"tls_key_path"`
-	CACertPaths       string                       `json:"ca_cert_paths"yaml:"ca_cert_paths"`
+	MaxRunningPlugins       int                          `json:"max_running_plugins"yaml:"max_running_plugins"`
+	PluginLoadTimeout       int                          `json:"plugin_load_timeout"yaml:"plugin_load_timeout"`
+	PluginTrust             int                          `json:"plugin_trust_level"yaml:"plugin_trust_level"`
+	AutoDiscoverPath        string                       `json:"auto_discover_path"yaml:"auto_discover_path"`
+	KeyringPaths            string                       `json:"keyring_paths"yaml:"keyring_paths"`
+	CacheExpiration         jsonutil.Duration            `json:"cache_expiration"yaml:"cache_expiration"`
+	Plugins                 *pluginConfig                `json:"plugins"yaml:"plugins"`
+	Tags                    map[string]map[string]string `json:"tags,omitempty"yaml:"tags"`
+	ListenAddr              string                       `json:"listen_addr,omitempty"yaml:"listen_addr"`
+	ListenPort              int                          `json:"listen_port,omitempty"yaml:"listen_port"`
+	Pprof                   bool                         `json:"pprof"yaml:"pprof"`
+	MaxPluginRestarts       int                          `json:"max_plugin_restarts"yaml:"max_plugin_restarts"`
+	TempDirPath             string                       `json:"temp_dir_path"yaml:"temp_dir_path"`
+	TLSCertPath             string                       `json:"tls_cert_path"yaml:"tls_cert_path"`
+	TLSKeyPath              string                       `json:"tls_key_path"yaml:"tls_key_path"`
+	CACertPaths             string                       `json:"ca_cert_paths"yaml:"ca_cert_paths"`
+	CatalogPath             string                       `json:"catalog_path"yaml:"catalog_path"`
+	PluginWatchPath         string                       `json:"plugin_watch_path"yaml:"plugin_watch_path"`
+	PluginCachePath         string                       `json:"plugin_cache_path"yaml:"plugin_cache_path"`
+	PluginConfigDir         string                       `json:"plugin_config_dir"yaml:"plugin_config_dir"`
+	PluginAutoTLS           bool                         `json:"plugin_auto_tls"yaml:"plugin_auto_tls"`
+	HealthCheckInterval     jsonutil.Duration            `json:"health_check_interval"yaml:"health_check_interval"`
+	HealthCheckFailureLimit int                          `json:"health_check_failure_limit"yaml:"health_check_failure_limit"`
+	PluginRestartBackoff    jsonutil.Duration            `json:"plugin_restart_backoff"yaml:"plugin_restart_backoff"`
+	PluginResourceLimits    bool                         `json:"plugin_resource_limits"yaml:"plugin_resource_limits"`
+	PluginExecutor          string                       `json:"plugin_executor"yaml:"plugin_executor"`
+	PluginContainerImage    string                       `json:"plugin_container_image"yaml:"plugin_container_image"`
+	PluginBlacklistCrashes  int                          `json:"plugin_blacklist_crashes"yaml:"plugin_blacklist_crashes"`
+	PluginBlacklistWindow   jsonutil.Duration            `json:"plugin_blacklist_window"yaml:"plugin_blacklist_window"`
+	CollectorVersionRouting string                       `json:"collector_version_routing"yaml:"collector_version_routing"`
+	SelfMetrics             bool                         `json:"self_metrics"yaml:"self_metrics"`
+	InProcessPlugins        []string                     `json:"in_process_plugins"yaml:"in_process_plugins"`
+	PermissiveConfigPolicy  bool                         `json:"permissive_config_policy"yaml:"permissive_config_policy"`
+	CollectionCacheTTL      jsonutil.Duration            `json:"collection_cache_ttl"yaml:"collection_cache_ttl"`
 }
 
 const (
@@ -147,6 +179,45 @@ const (
 					"max_plugin_restarts": {
 						"type": "integer"
 					},
+					"health_check_interval": {
+						"type": "string"
+					},
+					"health_check_failure_limit": {
+						"type": "integer",
+						"minimum": 1
+					},
+					"plugin_restart_backoff": {
+						"type": "string"
+					},
+					"plugin_resource_limits": {
+						"type": "boolean"
+					},
+					"plugin_blacklist_crashes": {
+						"type": "integer",
+						"minimum": 0
+					},
+					"plugin_blacklist_window": {
+						"type": "string"
+					},
+					"collector_version_routing": {
+						"type": "string",
+						"enum": ["latest", "subscribed"]
+					},
+					"self_metrics": {
+						"type": "boolean"
+					},
+					"in_process_plugins": {
+						"type": "array",
+						"items": {
+							"type": "string"
+						}
+					},
+					"permissive_config_policy": {
+						"type": "boolean"
+					},
+					"collection_cache_ttl": {
+						"type": "string"
+					},
 					"tls_cert_path": {
 						"type": "string"
 					},
@@ -155,6 +226,28 @@ const (
 					},
 					"ca_cert_paths": {
 						"type": "string"
+					},
+					"catalog_path": {
+						"type": "string"
+					},
+					"plugin_watch_path": {
+						"type": "string"
+					},
+					"plugin_cache_path": {
+						"type": "string"
+					},
+					"plugin_config_dir": {
+						"type": "string"
+					},
+					"plugin_auto_tls": {
+						"type": "boolean"
+					},
+					"plugin_executor": {
+						"type": "string",
+						"enum": ["native", "container"]
+					},
+					"plugin_container_image": {
+						"type": "string"
 					}
 				},
 				"additionalProperties": false
@@ -165,22 +258,40 @@ const (
 // get the default snapteld configuration
 func GetDefaultConfig() *Config {
 	return &Config{
-		ListenAddr:        defaultListenAddr,
-		ListenPort:        defaultListenPort,
-		MaxRunningPlugins: defaultMaxRunningPlugins,
-		PluginLoadTimeout: defaultPluginLoadTimeout,
-		PluginTrust:       defaultPluginTrust,
-		AutoDiscoverPath:  defaultAutoDiscoverPath,
-		KeyringPaths:      defaultKeyringPaths,
-		CacheExpiration:   jsonutil.Duration{defaultCacheExpiration},
-		Plugins:           newPluginConfig(),
-		Tags:              newPluginTags(),
-		Pprof:             defaultPprof,
-		MaxPluginRestarts: MaxPluginRestartCount,
-		TempDirPath:       defaultTempDirPath,
-		TLSCertPath:       defaultTLSCertPath,
-		TLSKeyPath:        defaultTLSKeyPath,
-		CACertPaths:       defaultCACertPaths,
+		ListenAddr:              defaultListenAddr,
+		ListenPort:              defaultListenPort,
+		MaxRunningPlugins:       defaultMaxRunningPlugins,
+		PluginLoadTimeout:       defaultPluginLoadTimeout,
+		PluginTrust:             defaultPluginTrust,
+		AutoDiscoverPath:        defaultAutoDiscoverPath,
+		KeyringPaths:            defaultKeyringPaths,
+		CacheExpiration:         jsonutil.Duration{defaultCacheExpiration},
+		Plugins:                 newPluginConfig(),
+		Tags:                    newPluginTags(),
+		Pprof:                   defaultPprof,
+		MaxPluginRestarts:       MaxPluginRestartCount,
+		TempDirPath:             defaultTempDirPath,
+		TLSCertPath:             defaultTLSCertPath,
+		TLSKeyPath:              defaultTLSKeyPath,
+		CACertPaths:             defaultCACertPaths,
+		CatalogPath:             defaultCatalogPath,
+		PluginWatchPath:         defaultPluginWatchPath,
+		PluginCachePath:         defaultPluginCachePath,
+		PluginConfigDir:         defaultPluginConfigDir,
+		PluginAutoTLS:           defaultPluginAutoTLS,
+		HealthCheckInterval:     jsonutil.Duration{DefaultMonitorDuration},
+		HealthCheckFailureLimit: DefaultHealthCheckFailureLimit,
+		PluginRestartBackoff:    jsonutil.Duration{defaultRestartBackoff},
+		PluginResourceLimits:    defaultPluginResourceLimits,
+		PluginExecutor:          PluginExecutorNative,
+		PluginContainerImage:    defaultPluginContainerImage,
+		PluginBlacklistCrashes:  defaultPluginBlacklistCrashes,
+		PluginBlacklistWindow:   jsonutil.Duration{defaultPluginBlacklistWindow},
+		CollectorVersionRouting: CollectorVersionRoutingLatest,
+		SelfMetrics:             defaultSelfMetrics,
+		InProcessPlugins:        []string{},
+		PermissiveConfigPolicy:  defaultPermissiveConfigPolicy,
+		CollectionCacheTTL:      jsonutil.Duration{defaultCollectionCacheTTL},
 	}
 }
 
@@ -201,6 +312,7 @@ func newPluginTypeConfigItem() *pluginTypeConfigItem {
 	return &pluginTypeConfigItem{
 		make(map[string]*pluginConfigItem),
 		cdata.NewNode(),
+		make(map[string]*pluginConfigItem),
 	}
 }
 
@@ -219,12 +331,12 @@ func newPluginTags() map[string]map[string]string {
 }
 
 func (p *Config) GetPluginConfigDataNode(pluginType core.PluginType, name string, ver int) cdata.ConfigDataNode {
-	return *p.Plugins.getPluginConfigDataNode(pluginType, name, ver)
+	return *p.Plugins.pluginConfigDataNode(pluginType, name, ver)
 }
 
 func (p *Config) MergePluginConfigDataNode(pluginType core.PluginType, name string, ver int, cdn *cdata.ConfigDataNode) cdata.ConfigDataNode {
 	p.Plugins.mergePluginConfigDataNode(pluginType, name, ver, cdn)
-	return *p.Plugins.getPluginConfigDataNode(pluginType, name, ver)
+	return *p.Plugins.pluginConfigDataNode(pluginType, name, ver)
 }
 
 func (p *Config) MergePluginConfigDataNodeAll(cdn *cdata.ConfigDataNode) cdata.ConfigDataNode {
@@ -236,7 +348,7 @@ func (p *Config) DeletePluginConfigDataNodeField(pluginType core.PluginType, nam
 	for _, field := range fields {
 		p.Plugins.deletePluginConfigDataNodeField(pluginType, name, ver, field)
 	}
-	return *p.Plugins.getPluginConfigDataNode(pluginType, name, ver)
+	return *p.Plugins.pluginConfigDataNode(pluginType, name, ver)
 }
 
 func (p *Config) DeletePluginConfigDataNodeFieldAll(fields ...string) cdata.ConfigDataNode {
@@ -393,6 +505,8 @@ func (p *pluginConfig) deletePluginConfigDataNodeField(pluginType core.PluginTyp
 
 }
 
+// getPluginConfigDataNode returns the config passed to the plugin, that is the
+// plugin config without the pool items
 func (p *pluginConfig) getPluginConfigDataNode(pluginType core.PluginType, name string, ver int) *cdata.ConfigDataNode {
 	// check cache
 	key := fmt.Sprintf("%d"+core.Separator+"%s"+core.Separator+"%d", pluginType, name, ver)
@@ -402,21 +516,14 @@ func (p *pluginConfig) getPluginConfigDataNode(pluginType core.PluginType, name
 
 	//todo process/interpolate values
 
-	p.pluginCache[key] = cdata.NewNode()
-	p.pluginCache[key].Merge(p.All)
-
-	// check for plugin config
-	configItem := p.switchPluginConfigType(pluginType)
-	if configItem == nil {
+	cdn := p.pluginConfigDataNode(pluginType, name, ver)
+	if cdn == nil {
 		return nil
 	}
-	p.pluginCache[key].Merge(configItem.All)
-	if res, ok := configItem.Plugins[name]; ok {
-		p.pluginCache[key].Merge(res.ConfigDataNode)
-		if res2, ok2 := res.Versions[ver]; ok2 {
-			p.pluginCache[key].Merge(res2)
-		}
+	for _, k := range core.PoolConfigKeys {
+		cdn.DeleteItem(k)
 	}
+	p.pluginCache[key] = cdn
 
 	log.WithFields(log.Fields{
 		"_block_":            "getPluginConfigDataNode",
@@ -428,6 +535,39 @@ func (p *pluginConfig) getPluginConfigDataNode(pluginType core.PluginType, name
 	return p.pluginCache[key]
 }
 
+// pluginConfigDataNode merges the plugin config, pool items included
+func (p *pluginConfig) pluginConfigDataNode(pluginType core.PluginType, name string, ver int) *cdata.ConfigDataNode {
+	// check for plugin config
+	configItem := p.switchPluginConfigType(pluginType)
+	if configItem == nil {
+		return nil
+	}
+
+	cdn := cdata.NewNode()
+	// the profiles of the plugin config dir are overridden by any other config
+	if res, ok := configItem.profiles[name]; ok {
+		cdn.Merge(res.ConfigDataNode)
+		if res2, ok2 := res.Versions[ver]; ok2 {
+			cdn.Merge(res2)
+		}
+	}
+	cdn.Merge(p.All)
+	cdn.Merge(configItem.All)
+	if res, ok := configItem.Plugins[name]; ok {
+		cdn.Merge(res.ConfigDataNode)
+		if res2, ok2 := res.Versions[ver]; ok2 {
+			cdn.Merge(res2)
+		}
+	}
+	return cdn
+}
+
+// getPluginPoolConfig returns the settings of the pool of the plugin set by the
+// pool items of the plugin config
+func (p *pluginConfig) getPluginPoolConfig(pluginType core.PluginType, name string, ver int) (core.PluginPoolConfig, error) {
+	return core.NewPluginPoolConfig(p.pluginConfigDataNode(pluginType, name, ver))
+}
+
 func unmarshalPluginConfig(typ string, p *pluginConfig, t map[string]interface{}) error {
 	if v, ok := t[typ]; ok {
 		switch plugins := v.(type) {
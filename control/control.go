@@ -21,12 +21,13 @@ package control
 
 import (
 	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net"
 	"os"
-	"path"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -68,6 +69,16 @@ var (
 
 	// ErrControllerNotStarted - error message when the Controller was not started
 	ErrControllerNotStarted = errors.New("Must start Controller before use")
+
+	// ErrUpgradeNotCollector - error message when upgrading a plugin which is not a collector
+	ErrUpgradeNotCollector = errors.New("Only collector plugins can be upgraded")
+
+	// ErrUpgradeVersion - error message when the upgrading plugin is not a newer version of the upgraded plugin
+	ErrUpgradeVersion = errors.New("Plugin must be a newer version of the upgraded plugin")
+
+	// PluginDrainTimeout is the time the calls in flight to a plugin being
+	// unloaded are given to finish
+	PluginDrainTimeout = 10 * time.Second
 )
 
 type pluginControl struct {
@@ -83,6 +94,9 @@ type pluginControl struct {
 	pluginRunner   runsPlugins
 	signingManager managesSigning
 
+	// the trust level and the keyrings are reloaded while snapteld is
+	// running
+	trustMutex   sync.RWMutex
 	pluginTrust  int
 	keyringFiles []string
 	// used to cleanly shutdown the GRPC server
@@ -92,6 +106,36 @@ type pluginControl struct {
 
 	subscriptionGroups ManagesSubscriptionGroups
 	grpcSecurity       client.GRPCSecurity
+
+	// issues the certificates of the plugins when plugin_auto_tls is enabled
+	pluginCA *pluginCertAuthority
+
+	// persists the metric catalog when a catalog path is configured
+	catalogStore *catalogStore
+
+	// loads, unloads and reloads plugins of the watched plugin directory
+	pluginWatcher *pluginWatcher
+
+	// blacklists the plugin binaries crashing repeatedly
+	pluginBlacklist *pluginBlacklist
+
+	// down-samples the metrics declaring a minimum collection interval
+	collectionThrottle *collectionThrottle
+
+	// shares the collected metrics between the tasks within their cache TTL
+	collectionCache *collectionCache
+
+	// the processors run by control itself, by name
+	builtinProcessors map[string]builtinProcessor
+
+	// the collectors run by control itself, by name
+	builtinCollectors map[string]builtinCollector
+
+	// resolves the references to secrets of the config given to the plugins
+	secrets resolvesSecrets
+
+	// encrypts the sensitive values of the config of the tasks
+	cipher encryptsConfig
 }
 
 type subscribedPlugin struct {
@@ -138,13 +182,25 @@ type catalogsMetrics interface {
 	GetMetric(core.Namespace, int) (*metricType, error)
 	GetMetrics(core.Namespace, int) ([]*metricType, error)
 	Add(*metricType)
+	AddMany([]*metricType)
 	AddLoadedMetricType(*loadedPlugin, core.Metric) error
+	AddLoadedMetricTypes(*loadedPlugin, []core.Metric) error
 	RmUnloadedPluginMetrics(lp *loadedPlugin)
+	RmRestoredMetrics(func(core.CatalogedPlugin) bool) []metricType
 	GetVersions(core.Namespace) ([]*metricType, error)
 	Fetch(core.Namespace) ([]*metricType, error)
+	FetchPage([]string, int, int) ([]*metricType, int, error)
+	Export(io.Writer, string) error
+	Import(io.Reader, string) error
+	Query(map[string]string) ([]*metricType, error)
+	Match(core.Namespace) ([]*metricType, error)
+	Watch() (<-chan CatalogEvent, func())
+	Iterate() *catalogIterator
 	Keys() []string
-	Subscribe([]string, int) error
-	Unsubscribe([]string, int) error
+	Subscribe([]string, int, string) error
+	Unsubscribe([]string, int, string) error
+	UnsubscribeAll(string)
+	Resubscribe(string, []core.Metric) []error
 	GetPlugin(core.Namespace, int) (core.CatalogedPlugin, error)
 }
 
@@ -193,6 +249,17 @@ func MaxPluginRestarts(cfg *Config) PluginControlOpt {
 	}
 }
 
+// HealthCheck sets the number of failed health checks after which a plugin is
+// restarted and the backoff between the restarts
+func HealthCheck(cfg *Config) PluginControlOpt {
+	return func(*pluginControl) {
+		if cfg.HealthCheckFailureLimit > 0 {
+			HealthCheckFailureLimit = cfg.HealthCheckFailureLimit
+		}
+		PluginRestartBackoff = cfg.PluginRestartBackoff.Duration
+	}
+}
+
 // New returns a new pluginControl instance
 func New(cfg *Config) *pluginControl {
 	// construct a slice of options from the input configuration
@@ -202,6 +269,7 @@ func New(cfg *Config) *pluginControl {
 		OptSetConfig(cfg),
 		OptSetTags(cfg.Tags),
 		MaxPluginRestarts(cfg),
+		HealthCheck(cfg),
 	}
 	c := &pluginControl{}
 	c.Config = cfg
@@ -218,12 +286,40 @@ func New(cfg *Config) *pluginControl {
 	controlLogger.WithFields(log.Fields{
 		"_block": "new",
 	}).Debug("metric catalog created")
+	if cfg.CatalogPath != "" {
+		c.catalogStore = newCatalogStore(cfg.CatalogPath)
+	}
 
 	managerOpts := []pluginManagerOpt{
 		OptSetPprof(cfg.Pprof),
 		OptSetTempDirPath(cfg.TempDirPath),
+		OptSetPermissiveConfigPolicy(cfg.PermissiveConfigPolicy),
 	}
 	runnerOpts := []pluginRunnerOpt{}
+	if cfg.HealthCheckInterval.Duration > 0 {
+		runnerOpts = append(runnerOpts, OptSetHealthCheckInterval(cfg.HealthCheckInterval.Duration))
+	}
+	if cfg.PluginResourceLimits {
+		runnerOpts = append(runnerOpts, OptEnableResourceLimits())
+	}
+	c.pluginBlacklist = newPluginBlacklist(cfg.PluginBlacklistCrashes, cfg.PluginBlacklistWindow.Duration)
+	runnerOpts = append(runnerOpts, OptSetPluginBlacklist(c.pluginBlacklist))
+	c.collectionThrottle = newCollectionThrottle()
+	c.collectionCache = newCollectionCache(cfg.CollectionCacheTTL.Duration)
+	c.builtinProcessors = newBuiltinProcessors()
+	c.builtinCollectors = newBuiltinCollectors(c)
+	if cfg.PluginExecutor != PluginExecutorNative && cfg.PluginExecutor != PluginExecutorContainer {
+		controlLogger.WithFields(log.Fields{
+			"_block":          "new",
+			"plugin-executor": cfg.PluginExecutor,
+		}).Warning("unknown plugin executor, running plugins as subprocesses")
+	}
+	if cfg.CollectorVersionRouting != CollectorVersionRoutingLatest && cfg.CollectorVersionRouting != CollectorVersionRoutingSubscribed {
+		controlLogger.WithFields(log.Fields{
+			"_block":                    "new",
+			"collector-version-routing": cfg.CollectorVersionRouting,
+		}).Warning("unknown collector version routing, routing metrics to the latest collector versions")
+	}
 	if cfg.IsTLSEnabled() {
 		if cfg.CACertPaths != "" {
 			certPaths := filepath.SplitList(cfg.CACertPaths)
@@ -233,6 +329,17 @@ func New(cfg *Config) *pluginControl {
 		}
 		managerOpts = append(managerOpts, OptEnableManagerTLS(c.grpcSecurity))
 		runnerOpts = append(runnerOpts, OptEnableRunnerTLS(c.grpcSecurity))
+	} else if cfg.PluginAutoTLS {
+		ca, err := newPluginCertAuthority(cfg.TempDirPath)
+		if err != nil {
+			controlLogger.WithFields(log.Fields{
+				"_block": "new",
+			}).Fatal("unable to create the certificate authority of the plugins: ", err)
+		}
+		c.pluginCA = ca
+		c.grpcSecurity = pluginAutoTLSSecurity(ca, cfg)
+		managerOpts = append(managerOpts, OptEnableManagerTLS(c.grpcSecurity))
+		runnerOpts = append(runnerOpts, OptEnableRunnerTLS(c.grpcSecurity))
 	}
 	// Plugin Manager
 	c.pluginManager = newPluginManager(managerOpts...)
@@ -325,6 +432,12 @@ func (p *pluginControl) Start() error {
 		"_block": "start",
 	}).Info("control started")
 
+	// Restore the persisted metric catalog, its metrics are going to be
+	// replaced by those advertised by plugins as they get loaded
+	p.restoreCatalog()
+	p.catalogBuiltinCollectors()
+	p.loadInProcessPlugins()
+
 	//Autodiscover
 	if p.Config.AutoDiscoverPath != "" {
 		controlLogger.WithFields(log.Fields{
@@ -396,12 +509,12 @@ func (p *pluginControl) Start() error {
 					continue
 				}
 				// if the file is a plugin package (which would have a suffix of '.aci') or if the file
-				// is not a plugin signing file (which would have a suffix of '.asc'), then attempt to
-				// automatically load the file as a plugin
-				if strings.HasSuffix(fileName, ".aci") || !(strings.HasSuffix(fileName, ".asc")) {
+				// is not a plugin signing file (which would have a suffix of '.asc' or '.sig'), then attempt
+				// to automatically load the file as a plugin
+				if strings.HasSuffix(fileName, ".aci") || !psigning.IsSignatureFile(fileName) {
 					// check to makd sure the file is executable by someone (even if it isn't you); if no one
 					// can execute this file then skip it (and include a warning in the log output)
-					if (statCheck.Mode() & 0111) == 0 {
+					if !isExecutable(statCheck) {
 						controlLogger.WithFields(log.Fields{
 							"_block":           "start",
 							"autodiscoverpath": pa,
@@ -409,7 +522,7 @@ func (p *pluginControl) Start() error {
 						}).Warn("Auto-loading of plugin '", fileName, "' skipped (plugin not executable)")
 						continue
 					}
-					rp, err := core.NewRequestedPlugin(path.Join(fullPath, fileName), p.GetTempDir(), nil)
+					rp, err := core.NewRequestedPlugin(filepath.Join(fullPath, fileName), p.GetTempDir(), nil)
 					if err != nil {
 						controlLogger.WithFields(log.Fields{
 							"_block":           "start",
@@ -417,14 +530,13 @@ func (p *pluginControl) Start() error {
 							"plugin":           fileName,
 						}).Error(err)
 					}
-					signatureFile := fileName + ".asc"
-					if _, err := os.Stat(path.Join(fullPath, signatureFile)); err == nil {
-						err = rp.ReadSignatureFile(path.Join(fullPath, signatureFile))
+					if signatureFile := psigning.SignatureFile(filepath.Join(fullPath, fileName)); signatureFile != "" {
+						err = rp.ReadSignatureFile(signatureFile)
 						if err != nil {
 							controlLogger.WithFields(log.Fields{
 								"_block":           "start",
 								"autodiscoverpath": pa,
-								"plugin":           fileName + ".asc",
+								"plugin":           filepath.Base(signatureFile),
 							}).Error(err)
 						}
 					}
@@ -454,6 +566,18 @@ func (p *pluginControl) Start() error {
 		}).Info("auto discover path is disabled")
 	}
 
+	if p.Config.PluginWatchPath != "" {
+		p.pluginWatcher = newPluginWatcher(p, p.Config.PluginWatchPath)
+		if err := p.pluginWatcher.start(); err != nil {
+			controlLogger.WithFields(log.Fields{
+				"_block":          "start",
+				"pluginwatchpath": p.Config.PluginWatchPath,
+			}).Error(err)
+			p.pluginWatcher = nil
+			return err
+		}
+	}
+
 	lis, err := net.Listen("tcp", fmt.Sprintf("%v:%v", p.Config.ListenAddr, p.Config.ListenPort))
 	if err != nil {
 		controlLogger.WithField("error", err.Error()).Error("Failed to start control grpc listener")
@@ -490,6 +614,11 @@ func (p *pluginControl) Stop() {
 	// goroutine that is listening for connections)
 	p.closingChan <- true
 
+	// stop watching the plugin directory
+	if p.pluginWatcher != nil {
+		p.pluginWatcher.stop()
+	}
+
 	// stop GRPC server
 	p.grpcServer.Stop()
 	p.wg.Wait()
@@ -509,6 +638,11 @@ func (p *pluginControl) Stop() {
 	// unload plugins
 	p.pluginManager.teardown()
 
+	// remove the certificates issued to the plugins
+	if p.pluginCA != nil {
+		p.pluginCA.Close()
+	}
+
 	// log that we've stopped the control module
 	controlLogger.WithFields(log.Fields{
 		"_block": "stop",
@@ -539,7 +673,17 @@ func (p *pluginControl) Load(rp *core.RequestedPlugin) (core.CatalogedPlugin, se
 		controlLogger.WithFields(f).Error(se)
 		return nil, se
 	}
+	if p.pluginBlacklist != nil && p.pluginBlacklist.blacklisted(details.CheckSum) {
+		se := serror.New(ErrPluginBlacklisted)
+		se.SetFields(f)
+		se.SetFields(map[string]interface{}{
+			"checksum": hex.EncodeToString(details.CheckSum[:]),
+		})
+		controlLogger.WithFields(se.Fields()).Error(se)
+		return nil, se
+	}
 
+	p.loadPluginConfigProfiles()
 	pl, se := p.pluginManager.LoadPlugin(details, p.eventManager)
 	if se != nil {
 		return nil, se
@@ -551,6 +695,13 @@ func (p *pluginControl) Load(rp *core.RequestedPlugin) (core.CatalogedPlugin, se
 		pl.Details.ExecPath = ""
 	}
 
+	// the metrics restored for the plugin path are superseded by the ones
+	// the plugin advertised
+	p.metricCatalog.RmRestoredMetrics(func(cp core.CatalogedPlugin) bool {
+		return cp.PluginPath() == pl.PluginPath()
+	})
+	p.persistCatalog()
+
 	// defer sending event
 	event := &control_event.LoadPluginEvent{
 		Name:    pl.Meta.Name,
@@ -566,11 +717,12 @@ func (p *pluginControl) verifySignature(rp *core.RequestedPlugin) (bool, serror.
 	f := map[string]interface{}{
 		"_block": "verifySignature",
 	}
-	switch p.pluginTrust {
+	trust, keyringFiles := p.trust()
+	switch trust {
 	case PluginTrustDisabled:
 		return false, nil
 	case PluginTrustEnabled:
-		err := p.signingManager.ValidateSignature(p.keyringFiles, rp.Path(), rp.Signature())
+		err := p.signingManager.ValidateSignature(keyringFiles, rp.Path(), rp.Signature())
 		if err != nil {
 			return false, serror.New(err)
 		}
@@ -579,7 +731,7 @@ func (p *pluginControl) verifySignature(rp *core.RequestedPlugin) (bool, serror.
 			controlLogger.WithFields(f).Warn("Loading unsigned plugin ", rp.Path())
 			return false, nil
 		}
-		err := p.signingManager.ValidateSignature(p.keyringFiles, rp.Path(), rp.Signature())
+		err := p.signingManager.ValidateSignature(keyringFiles, rp.Path(), rp.Signature())
 		if err != nil {
 			return false, serror.New(err)
 		}
@@ -591,6 +743,12 @@ func (p *pluginControl) verifySignature(rp *core.RequestedPlugin) (bool, serror.
 func (p *pluginControl) returnPluginDetails(rp *core.RequestedPlugin) (*pluginDetails, serror.SnapError) {
 	details := &pluginDetails{}
 	var serr serror.SnapError
+	// Download the plugin first when it is loaded from a URL
+	if rp.DownloadUri() != nil {
+		if serr = p.downloadPlugin(rp); serr != nil {
+			return nil, serr
+		}
+	}
 	//Check plugin signing
 	details.Signed, serr = p.verifySignature(rp)
 	if serr != nil {
@@ -605,6 +763,11 @@ func (p *pluginControl) returnPluginDetails(rp *core.RequestedPlugin) (*pluginDe
 	details.CACertPaths = rp.CACertPaths()
 	details.TLSEnabled = rp.TLSEnabled()
 	details.Uri = rp.Uri()
+	if p.pluginCA != nil && rp.Uri() == nil {
+		if serr = p.securePlugin(details); serr != nil {
+			return nil, serr
+		}
+	}
 
 	if rp.Uri() != nil {
 		// Is a standalone plugin
@@ -621,7 +784,7 @@ func (p *pluginControl) returnPluginDetails(rp *core.RequestedPlugin) (*pluginDe
 		if err != nil {
 			return nil, serror.New(err)
 		}
-		details.ExecPath = path.Join(tempPath, "rootfs")
+		details.ExecPath = filepath.Join(tempPath, "rootfs")
 		if details.Manifest, err = aci.Manifest(f); err != nil {
 			return nil, serror.New(err)
 		}
@@ -633,21 +796,86 @@ func (p *pluginControl) returnPluginDetails(rp *core.RequestedPlugin) (*pluginDe
 		details.ExecPath = filepath.Dir(rp.Path())
 	}
 
+	if rp.Uri() == nil && p.Config.PluginExecutor == PluginExecutorContainer {
+		if details.ContainerImage, serr = p.containerImage(details); serr != nil {
+			return nil, serr
+		}
+	}
+
 	return details, nil
 }
 
+// Unload unloads the plugin.  No call is routed to the plugin anymore, the
+// tasks depending on the plugin are notified and the calls in flight are given
+// PluginDrainTimeout to finish before the metrics of the plugin are removed
+// from the catalog, the tasks are unsubscribed from the plugin and its running
+// instances are killed.
 func (p *pluginControl) Unload(pl core.Plugin) (core.CatalogedPlugin, serror.SnapError) {
-	up, err := p.pluginManager.UnloadPlugin(pl)
+	lp, err := p.pluginManager.get(key(pl))
 	if err != nil {
-		return nil, err
+		// a plugin which has not been loaded again since its metrics were
+		// restored is unloaded by removing them from the catalog
+		removed := p.metricCatalog.RmRestoredMetrics(func(cp core.CatalogedPlugin) bool {
+			return cp.TypeName() == pl.TypeName() && cp.Name() == pl.Name() && cp.Version() == pl.Version()
+		})
+		if len(removed) > 0 {
+			p.persistCatalog()
+			return removed[0].Plugin, nil
+		}
+		return nil, serror.New(ErrPluginNotFound, map[string]interface{}{
+			"plugin-name":    pl.Name(),
+			"plugin-version": pl.Version(),
+			"plugin-type":    pl.TypeName(),
+		})
+	}
+	drained := true
+	pool, _ := p.pluginRunner.AvailablePlugins().getPool(lp.Key())
+	if pool != nil {
+		p.eventManager.Emit(&control_event.UnloadingPluginEvent{
+			Name:    lp.Meta.Name,
+			Version: lp.Meta.Version,
+			Type:    int(lp.Meta.Type),
+			TaskIDs: p.subscriptionGroups.Dependents(lp),
+		})
+		if drained = pool.Drain(PluginDrainTimeout); !drained {
+			controlLogger.WithFields(log.Fields{
+				"_block":         "unload",
+				"plugin-name":    lp.Meta.Name,
+				"plugin-version": lp.Meta.Version,
+				"plugin-type":    lp.TypeName(),
+				"timeout":        PluginDrainTimeout,
+			}).Warning("calls in flight did not finish before the plugin is unloaded")
+		}
+	}
+
+	up, serr := p.pluginManager.UnloadPlugin(pl)
+	if serr != nil {
+		if pool != nil {
+			pool.Resume()
+		}
+		return nil, serr
 	}
+	p.persistCatalog()
 
-	event := &control_event.UnloadPluginEvent{
+	// the subscription groups are processed on the unload event, which
+	// unsubscribes the tasks from the plugin
+	p.eventManager.Emit(&control_event.UnloadPluginEvent{
 		Name:    up.Meta.Name,
 		Version: up.Meta.Version,
 		Type:    int(up.Meta.Type),
+	})
+	if pool != nil {
+		if drained {
+			pool.KillAll("plugin unloaded")
+		} else {
+			// the calls in flight still hold the pool, its instances are
+			// killed without waiting for them
+			for _, ap := range pool.Plugins() {
+				ap.Kill("plugin unloaded")
+			}
+		}
+		p.pluginRunner.AvailablePlugins().removePool(up.Key())
 	}
-	defer p.eventManager.Emit(event)
 	return up, nil
 }
 
@@ -700,6 +928,8 @@ func (p *pluginControl) SwapPlugins(in *core.RequestedPlugin, out core.Cataloged
 		return err
 	}
 
+	p.persistCatalog()
+
 	event := &control_event.SwapPluginsEvent{
 		LoadedPluginName:      lp.Meta.Name,
 		LoadedPluginVersion:   lp.Meta.Version,
@@ -712,6 +942,98 @@ func (p *pluginControl) SwapPlugins(in *core.RequestedPlugin, out core.Cataloged
 	return nil
 }
 
+// UpgradePlugin loads a new version of a collector plugin, moves the
+// subscriptions held on the metrics of the old version `out` to the new version
+// and unloads the old version, so running tasks don't have to be stopped.
+// The new version is unloaded again and the subscriptions are left in place if
+// it can't serve every subscription, e.g. a subscribed metric is
+// not exposed anymore or its config is not valid against the new config policy.
+func (p *pluginControl) UpgradePlugin(in *core.RequestedPlugin, out core.CatalogedPlugin) serror.SnapError {
+	if out.TypeName() != core.CollectorPluginType.String() {
+		return serror.New(ErrUpgradeNotCollector, map[string]interface{}{
+			"type": out.TypeName(),
+			"name": out.Name(),
+		})
+	}
+
+	details, serr := p.returnPluginDetails(in)
+	if serr != nil {
+		return serr
+	}
+	if details.IsPackage {
+		defer os.RemoveAll(filepath.Dir(details.ExecPath))
+	}
+
+	lp, serr := p.pluginManager.LoadPlugin(details, p.eventManager)
+	if serr != nil {
+		return serr
+	}
+
+	if lp.TypeName() != out.TypeName() || lp.Name() != out.Name() || lp.Version() <= out.Version() {
+		serr := serror.New(ErrUpgradeVersion, map[string]interface{}{
+			"in-type":     lp.TypeName(),
+			"out-type":    out.TypeName(),
+			"in-name":     lp.Name(),
+			"out-name":    out.Name(),
+			"in-version":  lp.Version(),
+			"out-version": out.Version(),
+		})
+		return p.rollbackLoad(lp, serr)
+	}
+
+	ids, serrs := p.subscriptionGroups.Upgrade(out, lp)
+	if ids == nil && serrs != nil {
+		for _, serr := range serrs {
+			controlLogger.WithFields(log.Fields{
+				"_block":  "upgrade-plugin",
+				"name":    out.Name(),
+				"version": out.Version(),
+			}).WithFields(serr.Fields()).Error(serr)
+		}
+		return p.rollbackLoad(lp, serrs[0])
+	}
+	// errors processing the moved subscription groups are kept by the groups
+	// and returned to the tasks, as for plugins being loaded or unloaded
+	for _, serr := range serrs {
+		controlLogger.WithFields(log.Fields{
+			"_block": "upgrade-plugin",
+		}).WithFields(serr.Fields()).Warn(serr)
+	}
+
+	// the subscriptions are held by the new version from now on, so failing
+	// to unload the old version doesn't affect the tasks
+	up, serr := p.pluginManager.UnloadPlugin(out)
+	if serr != nil {
+		return serr
+	}
+
+	p.persistCatalog()
+
+	event := &control_event.UpgradePluginEvent{
+		Name:            lp.Meta.Name,
+		PreviousVersion: up.Meta.Version,
+		Version:         lp.Meta.Version,
+		Type:            int(lp.Meta.Type),
+		TaskIds:         ids,
+	}
+	defer p.eventManager.Emit(event)
+
+	return nil
+}
+
+// rollbackLoad unloads a plugin which was loaded by an operation failing with serr
+func (p *pluginControl) rollbackLoad(lp *loadedPlugin, serr serror.SnapError) serror.SnapError {
+	if _, err := p.pluginManager.UnloadPlugin(lp); err != nil {
+		se := serror.New(errors.New("Failed to rollback after error"))
+		se.SetFields(map[string]interface{}{
+			"original-unload-error": serr.Error(),
+			"rollback-unload-error": err.Error(),
+		})
+		return se
+	}
+	return serr
+}
+
 func (p *pluginControl) ValidateDeps(requested []core.RequestedMetric, plugins []core.SubscribedPlugin, configTree *cdata.ConfigDataTree, asserts ...core.SubscribedPluginAssert) []serror.SnapError {
 	return p.subscriptionGroups.ValidateDeps(requested, plugins, configTree, asserts...)
 }
@@ -725,6 +1047,10 @@ func (p *pluginControl) SubscribeDeps(id string, requested []core.RequestedMetri
 
 // UnsubscribeDeps unsubscribes a group of dependencies provided the subscription group ID
 func (p *pluginControl) UnsubscribeDeps(id string) []serror.SnapError {
+	p.collectionThrottle.remove(id)
+	for _, bp := range p.builtinProcessors {
+		bp.remove(id)
+	}
 	// update view and unsubscribe to plugins
 	return p.subscriptionGroups.Remove(id)
 }
@@ -746,19 +1072,23 @@ func (p *pluginControl) verifyPlugin(lp *loadedPlugin) error {
 		return fmt.Errorf(fmt.Sprintf("Current plugin checksum (%x) does not match checksum when plugin was first loaded (%x).", cs, lp.Details.CheckSum))
 	}
 	if lp.Details.Signed {
-		return p.signingManager.ValidateSignature(p.keyringFiles, lp.Details.Path, lp.Details.Signature)
+		_, keyringFiles := p.trust()
+		return p.signingManager.ValidateSignature(keyringFiles, lp.Details.Path, lp.Details.Signature)
 	}
 	return nil
 }
 
-// getMetricsAndCollectors returns metrics to be collected grouped by plugin and collectors which are used to collect all of them
-func (p *pluginControl) getMetricsAndCollectors(requested []core.RequestedMetric, configTree *cdata.ConfigDataTree) (map[string]metricTypes, []core.SubscribedPlugin, []serror.SnapError) {
+// getMetricsAndCollectors returns metrics to be collected grouped by plugin and collectors which are used to collect all of them.
+// When pins is not nil the requested metrics without a version are collected by the pinned versions of the collectors
+// and pins is updated with the versions they were resolved to.
+func (p *pluginControl) getMetricsAndCollectors(requested []core.RequestedMetric, configTree *cdata.ConfigDataTree, pins versionPins) (map[string]metricTypes, []core.SubscribedPlugin, []serror.SnapError) {
 	newMetricsGroupedByPlugin := make(map[string]metricTypes)
 	newPlugins := []core.SubscribedPlugin{}
 	var serrs []serror.SnapError
 	for _, r := range requested {
-		// get all metric types available in metricCatalog which fulfill the requested namespace and version (if ver <=0 the latest version will be taken)
-		newMetrics, err := p.metricCatalog.GetMetrics(r.Namespace(), r.Version())
+		// get all metric types available in metricCatalog which fulfill the requested namespace and version (if ver <=0 the latest
+		// version, or the pinned one, will be taken)
+		newMetrics, err := p.resolveRequestedMetric(r, pins)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"_block": "control",
@@ -782,10 +1112,12 @@ func (p *pluginControl) getMetricsAndCollectors(requested []core.RequestedMetric
 
 		for _, mt := range newMetrics {
 			// in case config tree doesn't have any configuration for current namespace
-			// it's needed to initialize config, otherwise it will stay nil and panic later on
-			cfg := configTree.Get(mt.Namespace().Strings())
-			if cfg == nil {
-				cfg = cdata.NewNode()
+			// it's needed to initialize config, otherwise it will stay nil and panic later on.
+			// The node of the tree is copied since applying the global config to it
+			// would keep stale global values once the global config changes.
+			cfg := cdata.NewNode()
+			if n := configTree.Get(mt.Namespace().Strings()); n != nil {
+				cfg.ApplyDefaults(n.Table())
 			}
 			// set config to metric
 			mt.config = cfg
@@ -875,10 +1207,17 @@ func (p *pluginControl) AvailablePlugins() []core.AvailablePlugin {
 	return caps
 }
 
-// MetricCatalog returns the entire metric catalog
-// NOTE: The returned data from this function should be considered constant and read only
+// MetricCatalog returns a snapshot of the entire metric catalog
 func (p *pluginControl) MetricCatalog() ([]core.CatalogedMetric, error) {
-	return p.FetchMetrics(core.Namespace{}, 0)
+	cmt := []core.CatalogedMetric{}
+	it := p.metricCatalog.Iterate()
+	for it.Next() {
+		_, mts := it.Item()
+		for _, mt := range mts {
+			cmt = append(cmt, mt)
+		}
+	}
+	return cmt, nil
 }
 
 // FetchMetrics returns the metrics which fall under the given namespace
@@ -915,6 +1254,58 @@ func (p *pluginControl) FetchMetrics(ns core.Namespace, version int) ([]core.Cat
 	return cmt, nil
 }
 
+// FetchMetricsPage returns a page of metrics (in all versions) which fall under
+// the given namespace along with the total number of metrics under the namespace
+// NOTE: The returned data from this function should be considered constant and read only
+func (p *pluginControl) FetchMetricsPage(ns core.Namespace, offset, limit int) ([]core.CatalogedMetric, int, error) {
+	mts, total, err := p.metricCatalog.FetchPage(ns.Strings(), offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	cmt := make([]core.CatalogedMetric, len(mts))
+	for i, mt := range mts {
+		cmt[i] = mt
+	}
+	return cmt, total, nil
+}
+
+// MatchMetrics returns the metrics (in all versions) which the given concrete
+// namespace is an instance of, e.g. /intel/docker/1234/cpu matches the metric
+// exposed as /intel/docker/[container_id]/cpu
+// NOTE: The returned data from this function should be considered constant and read only
+func (p *pluginControl) MatchMetrics(ns core.Namespace) ([]core.CatalogedMetric, error) {
+	mts, err := p.metricCatalog.Match(ns)
+	if err != nil {
+		return nil, err
+	}
+	cmt := make([]core.CatalogedMetric, len(mts))
+	for i, mt := range mts {
+		cmt[i] = mt
+	}
+	return cmt, nil
+}
+
+// QueryMetrics returns the metrics which carry all of the given tags,
+// e.g. {"plugin": "psutil", "unit": "bytes"}
+// NOTE: The returned data from this function should be considered constant and read only
+func (p *pluginControl) QueryMetrics(tags map[string]string) ([]core.CatalogedMetric, error) {
+	mts, err := p.metricCatalog.Query(tags)
+	if err != nil {
+		return nil, err
+	}
+	rmts := make([]core.CatalogedMetric, len(mts))
+	for i, m := range mts {
+		rmts[i] = m
+	}
+	return rmts, nil
+}
+
+// WatchMetricCatalog returns a channel of events about changes of the metric catalog
+// and a function which has to be called to stop watching
+func (p *pluginControl) WatchMetricCatalog() (<-chan CatalogEvent, func()) {
+	return p.metricCatalog.Watch()
+}
+
 func (p *pluginControl) GetMetric(ns core.Namespace, ver int) (core.CatalogedMetric, error) {
 	return p.metricCatalog.GetMetric(ns, ver)
 }
@@ -997,24 +1388,43 @@ func (p *pluginControl) CollectMetrics(id string, allTags map[string]map[string]
 	var wg sync.WaitGroup
 
 	// For each available plugin call available plugin using RPC client and wait for response (goroutines)
+	now := time.Now()
 	for pluginKey, pmt := range pluginToMetricMap {
+		// skip the metrics collected by the task within their minimum interval
+		mts := p.collectionThrottle.due(id, pmt.metricTypes, now)
+		if len(mts) == 0 {
+			continue
+		}
 		// merge global plugin config into the config for the metric
-		for _, mt := range pmt.metricTypes {
+		for _, mt := range mts {
 			if mt.Config() != nil {
 				mt.Config().ReverseMergeInPlace(p.Config.Plugins.getPluginConfigDataNode(core.CollectorPluginType, pmt.plugin.Name(), pmt.plugin.Version()))
 			}
 		}
 
+		mts, err := p.resolveMetrics(mts)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
 		wg.Add(1)
 
+		bc := p.builtinCollector(pmt.plugin.TypeName(), pmt.plugin.Name())
 		go func(pluginKey string, mt []core.Metric) {
-			mts, err := p.pluginRunner.AvailablePlugins().collectMetrics(pluginKey, mt, id)
+			var mts []core.Metric
+			var err error
+			if bc != nil {
+				mts, err = bc.collect(mt, now)
+			} else {
+				mts, err = p.collectCached(pluginKey, mt, id)
+			}
 			if err != nil {
 				cError <- err
 			} else {
 				cMetrics <- mts
 			}
-		}(pluginKey, pmt.metricTypes)
+		}(pluginKey, mts)
 	}
 
 	go func() {
@@ -1025,7 +1435,9 @@ func (p *pluginControl) CollectMetrics(id string, allTags map[string]map[string]
 			for i := range m {
 				m[i] = p.pluginManager.AddStandardAndWorkflowTags(m[i], allTags)
 			}
-			metrics = append(metrics, m...)
+			// the metrics are passed as they are to the processors and
+			// publishers of the workflow
+			metrics = append(metrics, client.ShareMetrics(m)...)
 			wg.Done()
 		}
 	}()
@@ -1086,7 +1498,12 @@ func (p *pluginControl) StreamMetrics(
 						pmt.plugin.Version()))
 			}
 		}
-		metricChan, errChan, err = p.pluginRunner.AvailablePlugins().streamMetrics(pluginKey, pmt.metricTypes, id, maxCollectDuration, maxMetricsBuffer)
+		mts, err := p.resolveMetrics(pmt.metricTypes)
+		if err != nil {
+			errs = append(errs, err)
+			return nil, nil, errs
+		}
+		metricChan, errChan, err = p.pluginRunner.AvailablePlugins().streamMetrics(pluginKey, mts, id, maxCollectDuration, maxMetricsBuffer)
 		if err != nil {
 			errs = append(errs, err)
 			return nil, nil, errs
@@ -1112,6 +1529,10 @@ func (p *pluginControl) PublishMetrics(metrics []core.Metric, config map[string]
 	for k, v := range config {
 		merged[k] = v
 	}
+	merged, err := p.resolveConfig(merged)
+	if err != nil {
+		return []error{err}
+	}
 
 	return p.pluginRunner.AvailablePlugins().publishMetrics(metrics, pluginName, pluginVersion, merged, taskID)
 }
@@ -1133,8 +1554,21 @@ func (p *pluginControl) ProcessMetrics(metrics []core.Metric, config map[string]
 	for k, v := range config {
 		merged[k] = v
 	}
+	merged, err := p.resolveConfig(merged)
+	if err != nil {
+		return nil, []error{err}
+	}
 
-	return p.pluginRunner.AvailablePlugins().processMetrics(metrics, pluginName, pluginVersion, merged, taskID)
+	var mts []core.Metric
+	var errs []error
+	if bp := p.builtinProcessor(core.ProcessorPluginType.String(), pluginName); bp != nil {
+		mts, errs = bp.process(metrics, merged, taskID, time.Now())
+	} else {
+		// the position of the workflow node is for the built-in processors only
+		delete(merged, core.WorkflowNodeConfigKey)
+		mts, errs = p.pluginRunner.AvailablePlugins().processMetrics(metrics, pluginName, pluginVersion, merged, taskID)
+	}
+	return client.ShareMetrics(mts), errs
 }
 
 func (p *pluginControl) SetAutodiscoverPaths(paths []string) {
@@ -1150,13 +1584,33 @@ func (p *pluginControl) GetTempDir() string {
 }
 
 func (p *pluginControl) SetPluginTrustLevel(trust int) {
+	p.trustMutex.Lock()
+	defer p.trustMutex.Unlock()
 	p.pluginTrust = trust
 }
 
 func (p *pluginControl) SetKeyringFile(keyring string) {
+	p.trustMutex.Lock()
+	defer p.trustMutex.Unlock()
 	p.keyringFiles = append(p.keyringFiles, keyring)
 }
 
+// SetKeyringFiles replaces the keyring files the signatures of the plugins
+// are verified with
+func (p *pluginControl) SetKeyringFiles(keyrings []string) {
+	p.trustMutex.Lock()
+	defer p.trustMutex.Unlock()
+	p.keyringFiles = keyrings
+}
+
+// trust returns the trust level and the keyring files the plugins are
+// verified with
+func (p *pluginControl) trust() (int, []string) {
+	p.trustMutex.RLock()
+	defer p.trustMutex.RUnlock()
+	return p.pluginTrust, p.keyringFiles
+}
+
 type requestedPlugin struct {
 	name    string
 	version int
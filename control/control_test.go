@@ -24,6 +24,7 @@ package control
 import (
 	"errors"
 	"fmt"
+	"io"
 	"math/rand"
 	"net"
 	"os/exec"
@@ -767,6 +768,31 @@ func (m *mc) GetVersions(core.Namespace) ([]*metricType, error) {
 	return nil, nil
 }
 
+func (m *mc) Query(map[string]string) ([]*metricType, error) {
+	return nil, nil
+}
+
+func (m *mc) Match(core.Namespace) ([]*metricType, error) {
+	return nil, nil
+}
+
+func (m *mc) FetchPage([]string, int, int) ([]*metricType, int, error) {
+	return nil, 0, nil
+}
+
+func (m *mc) Export(io.Writer, string) error {
+	return nil
+}
+
+func (m *mc) Import(io.Reader, string) error {
+	return nil
+}
+
+func (m *mc) Watch() (<-chan CatalogEvent, func()) {
+	ch := make(chan CatalogEvent)
+	return ch, func() { close(ch) }
+}
+
 func (m *mc) GetMetric(ns core.Namespace, ver int) (*metricType, error) {
 	if m.e == 1 {
 		return &metricType{
@@ -788,26 +814,31 @@ func (m *mc) GetMetrics(ns core.Namespace, ver int) ([]*metricType, error) {
 	return nil, serror.New(errorMetricNotFound(ns.String(), ver))
 }
 
-func (m *mc) Subscribe(ns []string, ver int) error {
+func (m *mc) Subscribe(ns []string, ver int, id string) error {
 	if ns[0] == "nf" {
 		return serror.New(errorMetricNotFound("/"+strings.Join(ns, "/"), ver))
 	}
 	return nil
 }
 
-func (m *mc) Unsubscribe(ns []string, ver int) error {
+func (m *mc) Unsubscribe(ns []string, ver int, id string) error {
 	if ns[0] == "nf" {
 		return serror.New(errorMetricNotFound("/"+strings.Join(ns, "/"), ver))
 	}
-	if ns[0] == "neg" {
-		return errNegativeSubCount
-	}
+	return nil
+}
+
+func (m *mc) UnsubscribeAll(id string) {}
+
+func (m *mc) Resubscribe(id string, metrics []core.Metric) []error {
 	return nil
 }
 
 func (m *mc) Add(*metricType)                 {}
+func (m *mc) AddMany([]*metricType)           {}
 func (m *mc) Table() map[string][]*metricType { return map[string][]*metricType{} }
 func (m *mc) Item() (string, []*metricType)   { return "", []*metricType{} }
+func (m *mc) Iterate() *catalogIterator       { return &catalogIterator{current: -1} }
 func (m *mc) Keys() []string                  { return []string{} }
 
 func (m *mc) Next() bool {
@@ -820,10 +851,18 @@ func (m *mc) AddLoadedMetricType(*loadedPlugin, core.Metric) error {
 
 }
 
+func (m *mc) AddLoadedMetricTypes(*loadedPlugin, []core.Metric) error {
+	return nil
+}
+
 func (m *mc) RmUnloadedPluginMetrics(lp *loadedPlugin) {
 
 }
 
+func (m *mc) RmRestoredMetrics(func(core.CatalogedPlugin) bool) []metricType {
+	return nil
+}
+
 type mockCDProc struct {
 }
 
@@ -853,11 +892,11 @@ func TestExportedMetricCatalog(t *testing.T) {
 			So(len(t), ShouldEqual, 1)
 			So(t[0].Namespace(), ShouldResemble, mt.Namespace())
 		})
-		Convey("If metric catalog fetch fails", func() {
-			c.metricCatalog = &mc{e: 2}
+		Convey("it returns a snapshot of the catalog", func() {
 			mts, err := c.MetricCatalog()
-			So(mts, ShouldBeEmpty)
-			So(err, ShouldNotBeNil)
+			So(err, ShouldBeNil)
+			So(c.metricCatalog.Subscribe([]string{"foo", "bar"}, -1, "task"), ShouldBeNil)
+			So(mts[0].(*metricType).SubscriptionCount(), ShouldEqual, 0)
 		})
 	})
 }
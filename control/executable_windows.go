@@ -0,0 +1,42 @@
+// +build windows
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// executableExts are the extensions of the files Windows can run
+var executableExts = map[string]bool{
+	".exe": true,
+	".com": true,
+	".bat": true,
+	".cmd": true,
+}
+
+// isExecutable returns whether the file can be run as a plugin, Windows has no
+// execute permission and runs the files by their extension
+func isExecutable(fi os.FileInfo) bool {
+	return executableExts[strings.ToLower(filepath.Ext(fi.Name()))]
+}
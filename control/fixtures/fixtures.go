@@ -75,6 +75,10 @@ func (m MockMetricType) Unit() string {
 	return ""
 }
 
+func (m MockMetricType) DataType() string {
+	return ""
+}
+
 func (m MockMetricType) LastAdvertisedTime() time.Time {
 	return time.Now()
 }
@@ -89,5 +89,53 @@ var (
 		EnvVar: "SNAP_TEMP_DIR_PATH",
 	}
 
-	Flags = []cli.Flag{flNumberOfPLs, flPluginLoadTimeout, flAutoDiscover, flPluginTrust, flKeyringPaths, flCache, flControlRpcPort, flControlRpcAddr, flTempDirPath, flTLSCert, flTLSKey, flCACertPaths}
+	flCatalogPath = cli.StringFlag{
+		Name:   "catalog-path",
+		Usage:  "A path to the file where the metric catalog is persisted across restarts (disabled when empty)",
+		EnvVar: "SNAP_CATALOG_PATH",
+	}
+
+	flPluginWatchPath = cli.StringFlag{
+		Name:   "plugin-watch-path",
+		Usage:  "A path to the directory watched for plugins to load, unload and reload as they are added, removed and changed (disabled when empty)",
+		EnvVar: "SNAP_PLUGIN_WATCH_PATH",
+	}
+
+	flPluginCachePath = cli.StringFlag{
+		Name:   "plugin-cache-path",
+		Usage:  "A path to the directory where plugins loaded from HTTP(S) URLs are cached (default: <temp-dir-path>/snap-plugin-cache)",
+		EnvVar: "SNAP_PLUGIN_CACHE_PATH",
+	}
+
+	flPluginConfigDir = cli.StringFlag{
+		Name:   "plugin-config-dir",
+		Usage:  "A path to the directory of the default config profiles of the plugins, applied when they are loaded (disabled when empty)",
+		EnvVar: "SNAP_PLUGIN_CONFIG_DIR",
+	}
+
+	flPluginAutoTLS = cli.BoolFlag{
+		Name:   "plugin-auto-tls",
+		Usage:  "Secure the communication with the plugins with certificates generated for the session when --tls-cert and --tls-key aren't given",
+		EnvVar: "SNAP_PLUGIN_AUTO_TLS",
+	}
+
+	flPluginResourceLimits = cli.BoolFlag{
+		Name:   "plugin-resource-limits",
+		Usage:  "Run plugins with the CPU and memory limits declared in their config (cgroups on Linux, rlimits elsewhere)",
+		EnvVar: "SNAP_PLUGIN_RESOURCE_LIMITS",
+	}
+
+	flPluginExecutor = cli.StringFlag{
+		Name:   "plugin-executor",
+		Usage:  fmt.Sprintf("How plugins are run, as subprocesses (%s) or in containers (%s) (default: %s)", PluginExecutorNative, PluginExecutorContainer, PluginExecutorNative),
+		EnvVar: "SNAP_PLUGIN_EXECUTOR",
+	}
+
+	flPluginContainerImage = cli.StringFlag{
+		Name:   "plugin-container-image",
+		Usage:  "The container image plugins are run in when the plugin executor is container, unless their package names one",
+		EnvVar: "SNAP_PLUGIN_CONTAINER_IMAGE",
+	}
+
+	Flags = []cli.Flag{flNumberOfPLs, flPluginLoadTimeout, flAutoDiscover, flPluginTrust, flKeyringPaths, flCache, flControlRpcPort, flControlRpcAddr, flTempDirPath, flTLSCert, flTLSKey, flCACertPaths, flCatalogPath, flPluginWatchPath, flPluginCachePath, flPluginConfigDir, flPluginAutoTLS, flPluginResourceLimits, flPluginExecutor, flPluginContainerImage}
 )
@@ -0,0 +1,58 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/intelsdi-x/snap/core"
+)
+
+// Health reports control as ready once it's started, and degraded while
+// running plugins fail their health checks
+func (p *pluginControl) Health() core.SubsystemHealth {
+	h := core.SubsystemHealth{
+		Name:    "control",
+		Healthy: p.Started,
+		Ready:   p.Started,
+		Details: map[string]interface{}{},
+	}
+	if !p.Started {
+		h.Message = "control isn't started"
+		return h
+	}
+	unhealthy := []string{}
+	running := p.pluginRunner.AvailablePlugins().all()
+	for _, ap := range running {
+		if !ap.Healthy() {
+			unhealthy = append(unhealthy, ap.String())
+		}
+	}
+	sort.Strings(unhealthy)
+	h.Details["loaded_plugins"] = len(p.pluginManager.all())
+	h.Details["running_plugins"] = len(running)
+	h.Details["unhealthy_plugins"] = unhealthy
+	if len(unhealthy) > 0 {
+		h.Degraded = true
+		h.Message = fmt.Sprintf("%d running plugins failed their health checks", len(unhealthy))
+	}
+	return h
+}
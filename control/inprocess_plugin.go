@@ -0,0 +1,224 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/intelsdi-x/gomit"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/client"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/control_event"
+	"github.com/intelsdi-x/snap/core/serror"
+)
+
+var (
+	// ErrInProcessPluginNotRegistered is returned when loading an in-process
+	// plugin which isn't registered
+	ErrInProcessPluginNotRegistered = errors.New("in-process plugin not registered")
+
+	inProcessPlugins      = map[string]*inProcessPlugin{}
+	inProcessPluginsMutex = &sync.Mutex{}
+)
+
+// InProcessConstructor returns a new instance of a plugin compiled into
+// snapteld: a plugin.CollectorPlugin, plugin.ProcessorPlugin or
+// plugin.PublisherPlugin
+type InProcessConstructor func() plugin.Plugin
+
+// inProcessPlugin is a plugin compiled into snapteld, its instances are run in
+// the process of snapteld and called without RPC
+type inProcessPlugin struct {
+	meta        plugin.PluginMeta
+	constructor InProcessConstructor
+}
+
+func (ip *inProcessPlugin) key() string {
+	return fmt.Sprintf("%s"+core.Separator+"%s"+core.Separator+"%d", ip.meta.Type.String(), ip.meta.Name, ip.meta.Version)
+}
+
+// response returns the response of the handshake of the plugin
+func (ip *inProcessPlugin) response() plugin.Response {
+	meta := ip.meta
+	meta.RPCType = plugin.InProcessRPC
+	meta.Unsecure = true
+	return plugin.Response{
+		Meta:  meta,
+		Type:  meta.Type,
+		State: plugin.PluginSuccess,
+	}
+}
+
+// RegisterInProcessPlugin registers the constructor of a plugin compiled into
+// snapteld.  Once loaded the plugin is run in the process of snapteld instead
+// of a subprocess, and called without RPC.  The name, version and type of the
+// plugin are given by its meta.  It is meant to be called by the init
+// functions of the built-in plugins and by tests.
+func RegisterInProcessPlugin(meta plugin.PluginMeta, constructor InProcessConstructor) error {
+	switch meta.Type {
+	case plugin.CollectorPluginType, plugin.ProcessorPluginType, plugin.PublisherPluginType:
+	default:
+		return fmt.Errorf("in-process plugins can't be of type %s", meta.Type.String())
+	}
+	if meta.Name == "" {
+		return errors.New("in-process plugins must have a name")
+	}
+	if meta.Version < 1 {
+		return fmt.Errorf("in-process plugin %s must have a version greater than 0", meta.Name)
+	}
+	if constructor == nil {
+		return fmt.Errorf("in-process plugin %s must have a constructor", meta.Name)
+	}
+	ip := &inProcessPlugin{meta: meta, constructor: constructor}
+	inProcessPluginsMutex.Lock()
+	defer inProcessPluginsMutex.Unlock()
+	if _, ok := inProcessPlugins[ip.key()]; ok {
+		return fmt.Errorf("in-process plugin %s of version %d is already registered", meta.Name, meta.Version)
+	}
+	inProcessPlugins[ip.key()] = ip
+	return nil
+}
+
+// registeredInProcessPlugins returns the in-process plugins registered with the
+// name, of all types and versions
+func registeredInProcessPlugins(name string) []*inProcessPlugin {
+	inProcessPluginsMutex.Lock()
+	defer inProcessPluginsMutex.Unlock()
+	keys := []string{}
+	for k, ip := range inProcessPlugins {
+		if ip.meta.Name == name {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	ips := make([]*inProcessPlugin, len(keys))
+	for i, k := range keys {
+		ips[i] = inProcessPlugins[k]
+	}
+	return ips
+}
+
+// newInProcessAvailablePlugin returns an availablePlugin calling a new
+// instance of the in-process plugin
+func newInProcessAvailablePlugin(ip *inProcessPlugin, emitter gomit.Emitter) (*availablePlugin, error) {
+	resp := ip.response()
+	ap := &availablePlugin{
+		meta:        resp.Meta,
+		name:        resp.Meta.Name,
+		version:     resp.Meta.Version,
+		pluginType:  resp.Type,
+		emitter:     emitter,
+		healthChan:  make(chan error, 1),
+		lastHitTime: time.Now(),
+	}
+	ap.key = ip.key()
+
+	p := ip.constructor()
+	switch resp.Type {
+	case plugin.CollectorPluginType:
+		c, ok := p.(plugin.CollectorPlugin)
+		if !ok {
+			return nil, fmt.Errorf("in-process plugin %s is not a collector", ap.name)
+		}
+		ap.client = client.NewCollectorInProcessClient(c, plugin.MetricsContentType(resp.Meta.AcceptedContentTypes))
+	case plugin.ProcessorPluginType:
+		c, ok := p.(plugin.ProcessorPlugin)
+		if !ok {
+			return nil, fmt.Errorf("in-process plugin %s is not a processor", ap.name)
+		}
+		ap.client = client.NewProcessorInProcessClient(c, plugin.MetricsContentType(resp.Meta.AcceptedContentTypes))
+	case plugin.PublisherPluginType:
+		c, ok := p.(plugin.PublisherPlugin)
+		if !ok {
+			return nil, fmt.Errorf("in-process plugin %s is not a publisher", ap.name)
+		}
+		ap.client = client.NewPublisherInProcessClient(c, plugin.MetricsContentType(resp.Meta.AcceptedContentTypes))
+	default:
+		return nil, errors.New("Cannot create a client for a plugin of the type: " + resp.Type.String())
+	}
+	return ap, nil
+}
+
+// LoadInProcess loads the in-process plugins registered with the name, of all
+// types and versions
+func (p *pluginControl) LoadInProcess(name string) ([]core.CatalogedPlugin, serror.SnapError) {
+	f := map[string]interface{}{
+		"_block":      "load-in-process",
+		"plugin-name": name,
+	}
+	if !p.Started {
+		se := serror.New(ErrControllerNotStarted)
+		se.SetFields(f)
+		controlLogger.WithFields(f).Error(se)
+		return nil, se
+	}
+	ips := registeredInProcessPlugins(name)
+	if len(ips) == 0 {
+		se := serror.New(ErrInProcessPluginNotRegistered)
+		se.SetFields(f)
+		return nil, se
+	}
+
+	p.loadPluginConfigProfiles()
+	pls := []core.CatalogedPlugin{}
+	for _, ip := range ips {
+		pl, se := p.pluginManager.LoadPlugin(&pluginDetails{InProcess: ip}, p.eventManager)
+		if se != nil {
+			return pls, se
+		}
+		pls = append(pls, pl)
+		p.eventManager.Emit(&control_event.LoadPluginEvent{
+			Name:    pl.Meta.Name,
+			Version: pl.Meta.Version,
+			Type:    int(pl.Meta.Type),
+		})
+	}
+	p.persistCatalog()
+	return pls, nil
+}
+
+// loadInProcessPlugins loads the in-process plugins named by the config
+func (p *pluginControl) loadInProcessPlugins() {
+	for _, name := range p.Config.InProcessPlugins {
+		pls, se := p.LoadInProcess(name)
+		if se != nil {
+			controlLogger.WithFields(se.Fields()).WithFields(log.Fields{
+				"_block":      "start",
+				"plugin-name": name,
+			}).Error(se)
+			continue
+		}
+		for _, pl := range pls {
+			controlLogger.WithFields(log.Fields{
+				"_block":         "start",
+				"plugin-name":    pl.Name(),
+				"plugin-version": pl.Version(),
+				"plugin-type":    pl.TypeName(),
+			}).Info("Loading in-process plugin")
+		}
+	}
+}
@@ -0,0 +1,171 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+
+	"github.com/intelsdi-x/gomit"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/client"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/ctypes"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type inProcessTestCollector struct{}
+
+func (inProcessTestCollector) GetConfigPolicy() (*cpolicy.ConfigPolicy, error) {
+	return cpolicy.New(), nil
+}
+
+func (inProcessTestCollector) GetMetricTypes(plugin.ConfigType) ([]plugin.MetricType, error) {
+	return []plugin.MetricType{{Namespace_: core.NewNamespace("inprocess", "foo")}}, nil
+}
+
+func (inProcessTestCollector) CollectMetrics(mts []plugin.MetricType) ([]plugin.MetricType, error) {
+	for i := range mts {
+		mts[i].Data_ = 42
+	}
+	return mts, nil
+}
+
+type inProcessTestPublisher struct{}
+
+func (inProcessTestPublisher) GetConfigPolicy() (*cpolicy.ConfigPolicy, error) {
+	return cpolicy.New(), nil
+}
+
+func (inProcessTestPublisher) Publish(string, []byte, map[string]ctypes.ConfigValue) error {
+	panic("publish failed")
+}
+
+func inProcessTestMeta(name string, t plugin.PluginType) plugin.PluginMeta {
+	return plugin.PluginMeta{Name: name, Version: 1, Type: t}
+}
+
+func TestRegisterInProcessPlugin(t *testing.T) {
+	Convey("Given the constructor of a collector", t, func() {
+		constructor := func() plugin.Plugin { return inProcessTestCollector{} }
+		Convey("it is registered with a name, a version and a type", func() {
+			err := RegisterInProcessPlugin(inProcessTestMeta("register-test", plugin.CollectorPluginType), constructor)
+			So(err, ShouldBeNil)
+			ips := registeredInProcessPlugins("register-test")
+			So(ips, ShouldHaveLength, 1)
+			So(ips[0].meta.Version, ShouldEqual, 1)
+			Convey("but only once", func() {
+				err := RegisterInProcessPlugin(inProcessTestMeta("register-test", plugin.CollectorPluginType), constructor)
+				So(err, ShouldNotBeNil)
+			})
+		})
+		Convey("it isn't registered without a name", func() {
+			So(RegisterInProcessPlugin(inProcessTestMeta("", plugin.CollectorPluginType), constructor), ShouldNotBeNil)
+		})
+		Convey("it isn't registered without a version", func() {
+			meta := inProcessTestMeta("register-test-no-version", plugin.CollectorPluginType)
+			meta.Version = 0
+			So(RegisterInProcessPlugin(meta, constructor), ShouldNotBeNil)
+		})
+		Convey("it isn't registered as a streaming collector", func() {
+			So(RegisterInProcessPlugin(inProcessTestMeta("register-test-stream", plugin.StreamCollectorPluginType), constructor), ShouldNotBeNil)
+		})
+		Convey("it isn't registered without a constructor", func() {
+			So(RegisterInProcessPlugin(inProcessTestMeta("register-test-nil", plugin.CollectorPluginType), nil), ShouldNotBeNil)
+		})
+	})
+}
+
+func TestInProcessPlugin(t *testing.T) {
+	constructors := map[string]InProcessConstructor{
+		"inprocess-collector": func() plugin.Plugin { return inProcessTestCollector{} },
+		"inprocess-publisher": func() plugin.Plugin { return inProcessTestPublisher{} },
+		"inprocess-mistyped":  func() plugin.Plugin { return inProcessTestCollector{} },
+	}
+	types := map[string]plugin.PluginType{
+		"inprocess-collector": plugin.CollectorPluginType,
+		"inprocess-publisher": plugin.PublisherPluginType,
+		"inprocess-mistyped":  plugin.ProcessorPluginType,
+	}
+	for name, constructor := range constructors {
+		if err := RegisterInProcessPlugin(inProcessTestMeta(name, types[name]), constructor); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	Convey("Given a registered in-process collector", t, func() {
+		ip := registeredInProcessPlugins("inprocess-collector")[0]
+		Convey("its available plugins collect the metrics without RPC", func() {
+			ap, err := newInProcessAvailablePlugin(ip, gomit.NewEventController())
+			So(err, ShouldBeNil)
+			So(ap.meta.RPCType, ShouldEqual, plugin.InProcessRPC)
+			So(ap.client.Ping(), ShouldBeNil)
+			mts, err := ap.client.(client.PluginCollectorClient).CollectMetrics([]core.Metric{
+				plugin.MetricType{Namespace_: core.NewNamespace("inprocess", "foo")},
+			})
+			So(err, ShouldBeNil)
+			So(mts, ShouldHaveLength, 1)
+			So(mts[0].Data(), ShouldEqual, 42)
+		})
+		Convey("it is loaded and its metrics are cataloged", func() {
+			mc := newMetricCatalog()
+			pm := newPluginManager()
+			pm.SetMetricCatalog(mc)
+			lp, serr := pm.LoadPlugin(&pluginDetails{InProcess: ip}, gomit.NewEventController())
+			So(serr, ShouldBeNil)
+			So(lp.Name(), ShouldEqual, "inprocess-collector")
+			So(lp.State, ShouldEqual, LoadedState)
+			So(lp.Meta.RPCType, ShouldEqual, plugin.InProcessRPC)
+			mts, err := mc.Fetch(core.NewNamespace("inprocess"))
+			So(err, ShouldBeNil)
+			So(mts, ShouldHaveLength, 1)
+			Convey("and its instances are run in the pool of the plugin", func() {
+				r := newRunner()
+				r.SetEmitter(gomit.NewEventController())
+				So(r.runPlugin(lp.Name(), lp.Details), ShouldBeNil)
+				pool, serr := r.AvailablePlugins().getPool(lp.Key())
+				So(serr, ShouldBeNil)
+				So(pool, ShouldNotBeNil)
+				So(pool.Count(), ShouldEqual, 1)
+			})
+		})
+	})
+	Convey("Given a registered in-process publisher which panics", t, func() {
+		ip := registeredInProcessPlugins("inprocess-publisher")[0]
+		ap, err := newInProcessAvailablePlugin(ip, gomit.NewEventController())
+		So(err, ShouldBeNil)
+		Convey("the panic is returned as the error of the call", func() {
+			err := ap.client.(client.PluginPublisherClient).Publish([]core.Metric{}, nil)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "publish failed")
+		})
+	})
+	Convey("Given an in-process plugin registered with the wrong type", t, func() {
+		ip := registeredInProcessPlugins("inprocess-mistyped")[0]
+		Convey("it can't be run", func() {
+			_, err := newInProcessAvailablePlugin(ip, gomit.NewEventController())
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
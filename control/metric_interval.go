@@ -0,0 +1,122 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/intelsdi-x/snap/core"
+)
+
+// collectionThrottle down-samples the collection of the metrics declaring a
+// minimum collection interval: a collection of a task skips a metric until its
+// minimum interval elapsed since the task last collected it.
+type collectionThrottle struct {
+	sync.Mutex
+	// the time of the last collection of a metric by task id and metric key
+	last map[string]map[string]time.Time
+}
+
+func newCollectionThrottle() *collectionThrottle {
+	return &collectionThrottle{last: map[string]map[string]time.Time{}}
+}
+
+// due returns the metrics the task can collect at the given time, the
+// metrics returned are considered collected
+func (c *collectionThrottle) due(taskID string, mts []core.Metric, now time.Time) []core.Metric {
+	if c == nil {
+		return mts
+	}
+	c.Lock()
+	defer c.Unlock()
+	due := make([]core.Metric, 0, len(mts))
+	for _, mt := range mts {
+		interval := minCollectionInterval(mt.Tags())
+		if interval <= 0 {
+			due = append(due, mt)
+			continue
+		}
+		last, ok := c.last[taskID]
+		if !ok {
+			last = map[string]time.Time{}
+			c.last[taskID] = last
+		}
+		key := fmt.Sprintf("%s"+core.Separator+"%d", mt.Namespace().String(), mt.Version())
+		// the timer of a task firing slightly early must not skip a collection
+		// when the task interval matches the minimum interval
+		if t, ok := last[key]; ok && now.Sub(t) < interval-interval/20 {
+			continue
+		}
+		last[key] = now
+		due = append(due, mt)
+	}
+	return due
+}
+
+// remove forgets the collections of the task
+func (c *collectionThrottle) remove(taskID string) {
+	if c == nil {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	delete(c.last, taskID)
+}
+
+// MinCollectionIntervals returns the minimum collection interval of the
+// cataloged metrics matching the requested metrics, by namespace.  Metrics
+// which can be collected anytime are omitted.
+func (p *pluginControl) MinCollectionIntervals(requested []core.RequestedMetric) map[string]time.Duration {
+	intervals := map[string]time.Duration{}
+	for _, r := range requested {
+		mts, err := p.resolveRequestedMetric(r, nil)
+		if err != nil {
+			continue
+		}
+		for _, mt := range mts {
+			if interval := mt.MinCollectionInterval(); interval > intervals[mt.Namespace().String()] {
+				intervals[mt.Namespace().String()] = interval
+			}
+		}
+	}
+	return intervals
+}
+
+// minCollectionInterval parses the minimum collection interval tag, zero is
+// returned when it's missing or invalid
+func minCollectionInterval(tags map[string]string) time.Duration {
+	v, ok := tags[core.MIN_COLLECTION_INTERVAL_TAG]
+	if !ok {
+		return 0
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		controlLogger.WithFields(log.Fields{
+			"_block": "min-collection-interval",
+			"value":  v,
+		}).Debug("invalid minimum collection interval")
+		return 0
+	}
+	return d
+}
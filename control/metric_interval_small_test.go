@@ -0,0 +1,71 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCollectionThrottle(t *testing.T) {
+	Convey("collectionThrottle", t, func() {
+		lp := &loadedPlugin{Meta: plugin.PluginMeta{Name: "mock", Version: 1}, Type: plugin.CollectorPluginType}
+		limited := newMetricType(core.NewNamespace("intel", "cloud", "quota"), time.Now(), lp)
+		limited.tags = map[string]string{core.MIN_COLLECTION_INTERVAL_TAG: "1m"}
+		free := newMetricType(core.NewNamespace("intel", "cloud", "latency"), time.Now(), lp)
+		mts := []core.Metric{limited, free}
+		c := newCollectionThrottle()
+		now := time.Now()
+
+		Convey("collects the metrics once their minimum interval elapsed", func() {
+			So(c.due("task", mts, now), ShouldHaveLength, 2)
+			due := c.due("task", mts, now.Add(10*time.Second))
+			So(due, ShouldHaveLength, 1)
+			So(due[0].Namespace().String(), ShouldEqual, free.Namespace().String())
+			So(c.due("task", mts, now.Add(time.Minute)), ShouldHaveLength, 2)
+		})
+
+		Convey("tolerates a task timer firing slightly early", func() {
+			c.due("task", mts, now)
+			So(c.due("task", mts, now.Add(59*time.Second)), ShouldHaveLength, 2)
+		})
+
+		Convey("down-samples each task separately", func() {
+			c.due("task", mts, now)
+			So(c.due("other", mts, now.Add(time.Second)), ShouldHaveLength, 2)
+			c.remove("task")
+			So(c.due("task", mts, now.Add(time.Second)), ShouldHaveLength, 2)
+		})
+
+		Convey("ignores invalid intervals", func() {
+			limited.tags[core.MIN_COLLECTION_INTERVAL_TAG] = "often"
+			So(limited.MinCollectionInterval(), ShouldEqual, 0)
+			c.due("task", mts, now)
+			So(c.due("task", mts, now), ShouldHaveLength, 2)
+		})
+	})
+}
@@ -23,6 +23,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -34,13 +35,15 @@ import (
 	"github.com/intelsdi-x/snap/core"
 	"github.com/intelsdi-x/snap/core/cdata"
 	"github.com/intelsdi-x/snap/core/ctypes"
-	"github.com/intelsdi-x/snap/core/serror"
 )
 
 var (
-	errMetricNotFound   = errors.New("metric not found")
-	errNegativeSubCount = serror.New(errors.New("subscription count cannot be < 0"))
-	hostnameReader      hostnamer
+	// ErrNilConfigPolicy is returned when the metric types of a plugin which did
+	// not provide a config policy are added to the catalog
+	ErrNilConfigPolicy = errors.New("plugin did not provide a config policy")
+
+	errMetricNotFound = errors.New("metric not found")
+	hostnameReader    hostnamer
 )
 
 // hostnameReader, hostnamer created for mocking
@@ -117,6 +120,14 @@ func errorMetricElementHasTuple(value, ns string) error {
 	return fmt.Errorf("A element %s should not define tuple for namespace %s.", value, ns)
 }
 
+func errorMetricsNotFoundByTags(tags map[string]string) error {
+	return fmt.Errorf("No metric found with the given tags: %v", tags)
+}
+
+func errorInvalidPage(offset, limit int) error {
+	return fmt.Errorf("Invalid page (offset: %d, limit: %d), offset and limit cannot be negative", offset, limit)
+}
+
 func errorEmptyNamespace() error {
 	return fmt.Errorf("Incorrect format of requested metric, empty list of namespace elements")
 }
@@ -139,7 +150,7 @@ type metricType struct {
 	namespace          core.Namespace
 	version            int
 	lastAdvertisedTime time.Time
-	subscriptions      int
+	subscriptions      map[string]struct{}
 	policy             processesConfigData
 	config             *cdata.ConfigDataNode
 	data               interface{}
@@ -147,6 +158,7 @@ type metricType struct {
 	timestamp          time.Time
 	description        string
 	unit               string
+	dataType           string
 }
 
 type metric struct {
@@ -176,6 +188,9 @@ func (m *metric) Description() string {
 func (m *metric) Unit() string {
 	return ""
 }
+func (m *metric) DataType() string {
+	return ""
+}
 func (m *metric) Tags() map[string]string {
 	return nil
 }
@@ -216,20 +231,41 @@ func (m *metricType) LastAdvertisedTime() time.Time {
 	return m.lastAdvertisedTime
 }
 
-func (m *metricType) Subscribe() {
-	m.subscriptions++
+// Subscribe adds a subscription of the given subscriber (task) to the metric,
+// subscribing more than once by the same subscriber has no effect
+func (m *metricType) Subscribe(id string) {
+	if m.subscriptions == nil {
+		m.subscriptions = map[string]struct{}{}
+	}
+	m.subscriptions[id] = struct{}{}
 }
 
-func (m *metricType) Unsubscribe() serror.SnapError {
-	if m.subscriptions == 0 {
-		return errNegativeSubCount
-	}
-	m.subscriptions--
-	return nil
+// Unsubscribe removes the subscription of the given subscriber (task),
+// unsubscribing a subscriber which is not subscribed has no effect
+func (m *metricType) Unsubscribe(id string) {
+	delete(m.subscriptions, id)
 }
 
 func (m *metricType) SubscriptionCount() int {
-	return m.subscriptions
+	return len(m.subscriptions)
+}
+
+// Subscribers returns the sorted IDs of the subscribers (tasks) holding a subscription to the metric
+func (m *metricType) Subscribers() []string {
+	ids := make([]string, 0, len(m.subscriptions))
+	for id := range m.subscriptions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (m *metricType) copySubscriptions() map[string]struct{} {
+	subs := make(map[string]struct{}, len(m.subscriptions))
+	for id := range m.subscriptions {
+		subs[id] = struct{}{}
+	}
+	return subs
 }
 
 func (m *metricType) Version() int {
@@ -266,6 +302,55 @@ func (m *metricType) Unit() string {
 	return m.unit
 }
 
+func (m *metricType) DataType() string {
+	return m.dataType
+}
+
+// Deprecated returns true if the plugin marked this version of the metric as deprecated
+func (m *metricType) Deprecated() bool {
+	_, ok := m.tags[core.DEPRECATED_TAG]
+	return ok
+}
+
+// DeprecationMessage returns the migration hint the plugin gave along with the deprecation
+func (m *metricType) DeprecationMessage() string {
+	return m.tags[core.DEPRECATED_TAG]
+}
+
+// MinCollectionInterval returns the minimum interval the plugin allows between
+// two collections of the metric, zero when the metric can be collected anytime
+func (m *metricType) MinCollectionInterval() time.Duration {
+	return minCollectionInterval(m.tags)
+}
+
+// hasTags returns true when the metric type carries all of the given tags.
+// Keys `plugin` and `unit` which are not advertised as metric's tags
+// are matched against the name of the plugin exposing the metric and
+// the unit of the metric respectively.
+func (m *metricType) hasTags(tags map[string]string) bool {
+	for k, v := range tags {
+		if tv, ok := m.tags[k]; ok {
+			if tv != v {
+				return false
+			}
+			continue
+		}
+		switch k {
+		case "plugin":
+			if m.Plugin == nil || m.Plugin.Name() != v {
+				return false
+			}
+		case "unit":
+			if m.unit != v {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
 type catalogedPlugin struct {
 	name         string
 	version      int
@@ -338,56 +423,232 @@ func newCatalogedPlugin(lp *loadedPlugin) core.CatalogedPlugin {
 	}
 }
 
+// CatalogEventType describes the kind of change of a cataloged metric type
+type CatalogEventType int
+
+const (
+	// MetricTypeAdded is emitted when a new metric type is added to the catalog
+	MetricTypeAdded CatalogEventType = iota
+	// MetricTypeRemoved is emitted when a metric type is removed from the catalog
+	MetricTypeRemoved
+	// MetricTypeUpdated is emitted when a metric type already present in the catalog
+	// (the same namespace and version) is replaced
+	MetricTypeUpdated
+)
+
+func (t CatalogEventType) String() string {
+	switch t {
+	case MetricTypeAdded:
+		return "added"
+	case MetricTypeRemoved:
+		return "removed"
+	case MetricTypeUpdated:
+		return "updated"
+	}
+	return "unknown"
+}
+
+// CatalogEvent notifies a watcher about a change of a metric type in the catalog
+type CatalogEvent struct {
+	Type   CatalogEventType
+	Metric core.CatalogedMetric
+}
+
+// catalogWatchBufferSize is the number of events buffered for each watcher,
+// events which do not fit into the buffer of a slow watcher are dropped
+const catalogWatchBufferSize = 256
+
 type metricCatalog struct {
-	tree  *MTTrie
-	mutex *sync.Mutex
+	tree *MTTrie
+	// mutex serializes the changes of the catalog, the readers share it
+	mutex *sync.RWMutex
 	keys  []string
+	// keyIndex is the index of each of the keys in keys
+	keyIndex map[string]int
+	watchers map[int]chan CatalogEvent
+	watchID  int
 }
 
 func newMetricCatalog() *metricCatalog {
 	return &metricCatalog{
-		tree:  NewMTTrie(),
-		mutex: &sync.Mutex{},
-		keys:  []string{},
+		tree:     NewMTTrie(),
+		mutex:    &sync.RWMutex{},
+		keys:     []string{},
+		keyIndex: map[string]int{},
+		watchers: map[int]chan CatalogEvent{},
+	}
+}
+
+// Watch returns a channel of events about metric types being added to,
+// removed from or updated in the catalog along with a function which stops
+// the watch and closes the channel.
+func (mc *metricCatalog) Watch() (<-chan CatalogEvent, func()) {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	id := mc.watchID
+	mc.watchID++
+	ch := make(chan CatalogEvent, catalogWatchBufferSize)
+	mc.watchers[id] = ch
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			mc.mutex.Lock()
+			defer mc.mutex.Unlock()
+			delete(mc.watchers, id)
+			close(ch)
+		})
+	}
+	return ch, cancel
+}
+
+// notify sends the event to all watchers without blocking, it has to be
+// called with the catalog mutex held
+func (mc *metricCatalog) notify(typ CatalogEventType, mt *metricType) {
+	e := CatalogEvent{Type: typ, Metric: mt}
+	for id, ch := range mc.watchers {
+		select {
+		case ch <- e:
+		default:
+			log.WithFields(log.Fields{
+				"_module":   "control",
+				"_file":     "metrics.go,",
+				"_block":    "notify",
+				"watcher":   id,
+				"event":     typ.String(),
+				"namespace": mt.Namespace().String(),
+				"version":   mt.Version(),
+			}).Warn("catalog watcher is not keeping up, dropping event")
+		}
 	}
 }
 
+// Keys returns the namespaces of the cataloged metrics
 func (mc *metricCatalog) Keys() []string {
-	return mc.keys
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+	keys := make([]string, len(mc.keys))
+	copy(keys, mc.keys)
+	return keys
+}
+
+// catalogIterator iterates over a snapshot of the metric catalog, namespace by
+// namespace in the order they were added, each iterator has its own copy of
+// the metric types so that it can be used while the catalog changes
+type catalogIterator struct {
+	keys    []string
+	items   map[string][]*metricType
+	current int
+}
+
+// Iterate returns an iterator over a snapshot of the metric catalog
+func (mc *metricCatalog) Iterate() *catalogIterator {
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+
+	it := &catalogIterator{items: map[string][]*metricType{}, current: -1}
+	mts, _ := mc.tree.Fetch([]string{})
+	for _, mt := range mts {
+		c := *mt
+		c.subscriptions = mt.copySubscriptions()
+		key := mt.Namespace().String()
+		it.items[key] = append(it.items[key], &c)
+	}
+	for _, key := range mc.keys {
+		if versions, ok := it.items[key]; ok {
+			sort.Sort(metricTypesByNamespace(versions))
+			it.keys = append(it.keys, key)
+		}
+	}
+	return it
+}
+
+// Next moves to the next namespace, false when there's none left
+func (it *catalogIterator) Next() bool {
+	if it.current+1 >= len(it.keys) {
+		return false
+	}
+	it.current++
+	return true
+}
+
+// Item returns the key of the current namespace and its metric types ordered
+// by version
+func (it *catalogIterator) Item() (string, []*metricType) {
+	key := it.keys[it.current]
+	return key, it.items[key]
+}
+
+// addKey adds the key to the cataloged keys unless it is there already
+func (mc *metricCatalog) addKey(key string) {
+	if _, ok := mc.keyIndex[key]; ok {
+		return
+	}
+	mc.keyIndex[key] = len(mc.keys)
+	mc.keys = append(mc.keys, key)
+}
+
+// removeKey removes the key from the cataloged keys, keeping the order of the
+// others
+func (mc *metricCatalog) removeKey(key string) {
+	i, ok := mc.keyIndex[key]
+	if !ok {
+		return
+	}
+	delete(mc.keyIndex, key)
+	mc.keys = append(mc.keys[:i], mc.keys[i+1:]...)
+	for ; i < len(mc.keys); i++ {
+		mc.keyIndex[mc.keys[i]] = i
+	}
 }
 
 func (mc *metricCatalog) AddLoadedMetricType(lp *loadedPlugin, mt core.Metric) error {
-	if err := validateMetricNamespace(mt.Namespace()); err != nil {
-		log.WithFields(log.Fields{
-			"_module": "control",
-			"_file":   "metrics.go,",
-			"_block":  "add-loaded-metric-type",
-			"error":   fmt.Errorf("Metric namespace %s is invalid", mt.Namespace()),
-		}).Error("error adding loaded metric type")
-		return err
+	return mc.AddLoadedMetricTypes(lp, []core.Metric{mt})
+}
+
+// AddLoadedMetricTypes adds the metric types advertised by a loaded plugin at
+// once, none of them is added when one of them is invalid. ErrNilConfigPolicy
+// is returned when the plugin has no config policy.
+func (mc *metricCatalog) AddLoadedMetricTypes(lp *loadedPlugin, mts []core.Metric) error {
+	for _, mt := range mts {
+		if err := validateMetricNamespace(mt.Namespace()); err != nil {
+			log.WithFields(log.Fields{
+				"_module": "control",
+				"_file":   "metrics.go,",
+				"_block":  "add-loaded-metric-type",
+				"error":   fmt.Errorf("Metric namespace %s is invalid", mt.Namespace()),
+			}).Error("error adding loaded metric type")
+			return err
+		}
 	}
 	if lp.ConfigPolicy == nil {
-		err := errors.New("Config policy is nil")
 		log.WithFields(log.Fields{
 			"_module": "control",
 			"_file":   "metrics.go,",
 			"_block":  "add-loaded-metric-type",
-			"error":   err,
+			"error":   ErrNilConfigPolicy,
 		}).Error("error adding loaded metric type")
-		return err
+		return ErrNilConfigPolicy
 	}
 
-	newMt := metricType{
-		Plugin:             newCatalogedPlugin(lp),
-		namespace:          mt.Namespace(),
-		version:            mt.Version(),
-		lastAdvertisedTime: mt.LastAdvertisedTime(),
-		tags:               mt.Tags(),
-		policy:             lp.ConfigPolicy.Get(mt.Namespace().Strings()),
-		description:        mt.Description(),
-		unit:               mt.Unit(),
+	// the metric types share the copy of the plugin
+	cp := newCatalogedPlugin(lp)
+	newMts := make([]*metricType, len(mts))
+	for i, mt := range mts {
+		newMts[i] = &metricType{
+			Plugin:             cp,
+			namespace:          mt.Namespace(),
+			version:            mt.Version(),
+			lastAdvertisedTime: mt.LastAdvertisedTime(),
+			tags:               mt.Tags(),
+			policy:             lp.ConfigPolicy.Get(mt.Namespace().Strings()),
+			description:        mt.Description(),
+			unit:               mt.Unit(),
+			dataType:           mt.DataType(),
+		}
 	}
-	mc.Add(&newMt)
+	mc.AddMany(newMts)
 	return nil
 }
 
@@ -396,13 +657,42 @@ func (mc *metricCatalog) AddLoadedMetricType(lp *loadedPlugin, mt core.Metric) e
 func (mc *metricCatalog) RmUnloadedPluginMetrics(lp *loadedPlugin) {
 	mc.mutex.Lock()
 	defer mc.mutex.Unlock()
-	mc.tree.DeleteByPlugin(lp)
+	removed := mc.tree.DeleteByPlugin(lp)
+	for i := range removed {
+		mc.notify(MetricTypeRemoved, &removed[i])
+	}
+	mc.rebuildKeys()
+}
 
-	// Update metric catalog keys
+// RmRestoredMetrics removes the metrics restored from the persisted catalog
+// which were not replaced yet and whose plugin matches, it returns the removed
+// metric types
+func (mc *metricCatalog) RmRestoredMetrics(match func(core.CatalogedPlugin) bool) []metricType {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+	var removed []metricType
+	for _, mt := range mc.tree.gatherMetricTypes() {
+		if mt.Plugin == nil || mt.Plugin.Status() != string(RestoredState) || !match(mt.Plugin) {
+			continue
+		}
+		mc.tree.RemoveMetric(mt)
+		removed = append(removed, mt)
+	}
+	for i := range removed {
+		mc.notify(MetricTypeRemoved, &removed[i])
+	}
+	mc.rebuildKeys()
+	return removed
+}
+
+// rebuildKeys updates the metric catalog keys after metric types were
+// removed from the tree, it has to be called with the catalog mutex held
+func (mc *metricCatalog) rebuildKeys() {
 	mc.keys = []string{}
+	mc.keyIndex = map[string]int{}
 	mts := mc.tree.gatherMetricTypes()
 	for _, m := range mts {
-		mc.keys = append(mc.keys, m.Namespace().String())
+		mc.addKey(m.Namespace().String())
 	}
 }
 
@@ -410,19 +700,40 @@ func (mc *metricCatalog) RmUnloadedPluginMetrics(lp *loadedPlugin) {
 func (mc *metricCatalog) Add(m *metricType) {
 	mc.mutex.Lock()
 	defer mc.mutex.Unlock()
+	mc.add(m)
+}
+
+// AddMany adds the metric types at once
+func (mc *metricCatalog) AddMany(mts []*metricType) {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+	for _, m := range mts {
+		mc.add(m)
+	}
+}
 
+// add adds a metricType, it has to be called with the catalog mutex held
+func (mc *metricCatalog) add(m *metricType) {
 	key := m.Namespace().String()
 
+	event := MetricTypeAdded
+	if node, err := mc.tree.find(m.Namespace().Strings()); err == nil {
+		if _, ok := node.mts[m.Version()]; ok {
+			event = MetricTypeUpdated
+		}
+	}
+
 	// adding key as a cataloged keys (mc.keys)
-	mc.keys = appendIfMissing(mc.keys, key)
+	mc.addKey(key)
 	mc.tree.Add(m)
+	mc.notify(event, m)
 }
 
 // GetMetric retrieves a metric for a given requested namespace and version.
 // If provided a version of -1 the latest plugin will be returned.
 func (mc *metricCatalog) GetMetric(requested core.Namespace, version int) (*metricType, error) {
-	mc.mutex.Lock()
-	defer mc.mutex.Unlock()
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
 
 	var ns core.Namespace
 
@@ -457,8 +768,9 @@ func (mc *metricCatalog) GetMetric(requested core.Namespace, version int) (*metr
 		policy:             catalogedmt.Plugin.Policy().Get(catalogedmt.Namespace().Strings()),
 		config:             catalogedmt.Config(),
 		unit:               catalogedmt.Unit(),
+		dataType:           catalogedmt.DataType(),
 		description:        catalogedmt.Description(),
-		subscriptions:      catalogedmt.SubscriptionCount(),
+		subscriptions:      catalogedmt.copySubscriptions(),
 	}
 	return returnedmt, nil
 }
@@ -466,8 +778,8 @@ func (mc *metricCatalog) GetMetric(requested core.Namespace, version int) (*metr
 // GetMetrics retrieves all metrics which fulfill a given requested namespace and version.
 // If provided a version of -1 the latest plugin will be returned.
 func (mc *metricCatalog) GetMetrics(requested core.Namespace, version int) ([]*metricType, error) {
-	mc.mutex.Lock()
-	defer mc.mutex.Unlock()
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
 
 	returnedmts := []*metricType{}
 
@@ -505,8 +817,9 @@ func (mc *metricCatalog) GetMetrics(requested core.Namespace, version int) ([]*m
 				policy:             catalogedmt.Plugin.Policy().Get(catalogedmt.Namespace().Strings()),
 				config:             catalogedmt.Config(),
 				unit:               catalogedmt.Unit(),
+				dataType:           catalogedmt.DataType(),
 				description:        catalogedmt.Description(),
-				subscriptions:      catalogedmt.SubscriptionCount(),
+				subscriptions:      catalogedmt.copySubscriptions(),
 			}
 			returnedmts = append(returnedmts, returnedmt)
 		}
@@ -517,8 +830,8 @@ func (mc *metricCatalog) GetMetrics(requested core.Namespace, version int) ([]*m
 
 // GetVersions retrieves all versions of a given metric namespace.
 func (mc *metricCatalog) GetVersions(ns core.Namespace) ([]*metricType, error) {
-	mc.mutex.Lock()
-	defer mc.mutex.Unlock()
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
 
 	mts, err := mc.tree.GetVersions(ns.Strings())
 	if err != nil {
@@ -533,10 +846,11 @@ func (mc *metricCatalog) GetVersions(ns core.Namespace) ([]*metricType, error) {
 	return mts, nil
 }
 
-// Fetch transactionally retrieves all metrics which fall under namespace ns
+// Fetch transactionally retrieves all metrics which fall under namespace ns,
+// an asterisk in ns matches any single namespace element
 func (mc *metricCatalog) Fetch(ns core.Namespace) ([]*metricType, error) {
-	mc.mutex.Lock()
-	defer mc.mutex.Unlock()
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
 
 	mtsi, err := mc.tree.Fetch(ns.Strings())
 	if err != nil {
@@ -551,16 +865,120 @@ func (mc *metricCatalog) Fetch(ns core.Namespace) ([]*metricType, error) {
 	return mtsi, nil
 }
 
+// FetchPage retrieves a page of metrics which fall under the given namespace.
+// Metrics are ordered by namespace and version, so consecutive pages do not overlap.
+// A limit of 0 returns all metrics starting at the offset. The total number of
+// metrics falling under the namespace is returned along with the page.
+func (mc *metricCatalog) FetchPage(ns []string, offset, limit int) ([]*metricType, int, error) {
+	if offset < 0 || limit < 0 {
+		return nil, 0, errorInvalidPage(offset, limit)
+	}
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+
+	mts, total, err := mc.tree.FetchPage(ns, offset, limit)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"_module": "control",
+			"_file":   "metrics.go,",
+			"_block":  "fetch-page",
+			"error":   err,
+		}).Error("error fetching metrics")
+		return nil, 0, err
+	}
+	return mts, total, nil
+}
+
+// metricTypesByNamespace sorts metric types by namespace and version
+type metricTypesByNamespace []*metricType
+
+func (m metricTypesByNamespace) Len() int {
+	return len(m)
+}
+
+func (m metricTypesByNamespace) Less(i, j int) bool {
+	nsi, nsj := m[i].Namespace().String(), m[j].Namespace().String()
+	if nsi != nsj {
+		return nsi < nsj
+	}
+	return m[i].Version() < m[j].Version()
+}
+
+func (m metricTypesByNamespace) Swap(i, j int) {
+	m[i], m[j] = m[j], m[i]
+}
+
+// Match retrieves the metrics in all versions which the given concrete namespace is an
+// instance of, e.g. /intel/docker/1234/cpu matches /intel/docker/[container_id]/cpu
+func (mc *metricCatalog) Match(concrete core.Namespace) ([]*metricType, error) {
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+
+	var mts []*metricType
+	for _, node := range mc.tree.match(concrete.Strings()) {
+		for _, mt := range node.mts {
+			if mt.Namespace().Matches(concrete) {
+				mts = append(mts, mt)
+			}
+		}
+	}
+	if len(mts) == 0 {
+		return nil, errorMetricNotFound(concrete.String())
+	}
+	sort.Sort(metricTypesByNamespace(mts))
+	return mts, nil
+}
+
+// Query retrieves all metrics which carry all of the given tags
+func (mc *metricCatalog) Query(tags map[string]string) ([]*metricType, error) {
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+
+	mts, err := mc.tree.Fetch([]string{})
+	if err != nil {
+		log.WithFields(log.Fields{
+			"_module": "control",
+			"_file":   "metrics.go,",
+			"_block":  "query",
+			"error":   err,
+		}).Error("error fetching metrics")
+		return nil, err
+	}
+	var matched []*metricType
+	for _, mt := range mts {
+		if mt.hasTags(tags) {
+			matched = append(matched, mt)
+		}
+	}
+	if len(matched) == 0 {
+		return nil, errorMetricsNotFoundByTags(tags)
+	}
+	return matched, nil
+}
+
 // Remove removes a metricType from the catalog and from matching map
 func (mc *metricCatalog) Remove(ns core.Namespace) {
 	mc.mutex.Lock()
 	defer mc.mutex.Unlock()
 
-	mc.tree.Remove(ns.Strings())
+	node, err := mc.tree.find(ns.Strings())
+	if err != nil {
+		return
+	}
+	nodes := gatherDescendants([]*mttNode{node}, node)
+	if mc.tree.Remove(ns.Strings()) != nil {
+		return
+	}
+	for _, n := range nodes {
+		for _, mt := range n.mts {
+			mc.removeKey(mt.Namespace().String())
+			mc.notify(MetricTypeRemoved, mt)
+		}
+	}
 }
 
-// Subscribe atomically increments a metric's subscription count in the table.
-func (mc *metricCatalog) Subscribe(ns []string, version int) error {
+// Subscribe atomically adds a subscription of the given subscriber (task) to the metric in the table
+func (mc *metricCatalog) Subscribe(ns []string, version int, id string) error {
 	mc.mutex.Lock()
 	defer mc.mutex.Unlock()
 
@@ -575,12 +993,12 @@ func (mc *metricCatalog) Subscribe(ns []string, version int) error {
 		return err
 	}
 
-	m.Subscribe()
+	m.Subscribe(id)
 	return nil
 }
 
-// Unsubscribe atomically decrements a metric's count in the table
-func (mc *metricCatalog) Unsubscribe(ns []string, version int) error {
+// Unsubscribe atomically removes a subscription of the given subscriber (task) from the metric in the table
+func (mc *metricCatalog) Unsubscribe(ns []string, version int, id string) error {
 	mc.mutex.Lock()
 	defer mc.mutex.Unlock()
 
@@ -595,10 +1013,54 @@ func (mc *metricCatalog) Unsubscribe(ns []string, version int) error {
 		return err
 	}
 
-	return m.Unsubscribe()
+	m.Unsubscribe(id)
+	return nil
+}
+
+// UnsubscribeAll removes all subscriptions held by the given subscriber (task),
+// so subscriptions of a task which is gone are garbage-collected
+func (mc *metricCatalog) UnsubscribeAll(id string) {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	mts, _ := mc.tree.Fetch([]string{})
+	for _, m := range mts {
+		m.Unsubscribe(id)
+	}
+}
+
+// Resubscribe atomically replaces all subscriptions held by the given subscriber
+// (task) with subscriptions to the given metrics, so the subscriber is never seen
+// without its subscriptions. The metrics which are not in the catalog are skipped
+// and reported in the returned errors.
+func (mc *metricCatalog) Resubscribe(id string, metrics []core.Metric) []error {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	var errs []error
+	subscribed := make([]*metricType, 0, len(metrics))
+	for _, metric := range metrics {
+		m, err := mc.tree.GetMetric(metric.Namespace().Strings(), metric.Version())
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		subscribed = append(subscribed, m)
+	}
+	mts, _ := mc.tree.Fetch([]string{})
+	for _, m := range mts {
+		m.Unsubscribe(id)
+	}
+	for _, m := range subscribed {
+		m.Subscribe(id)
+	}
+	return errs
 }
 
 func (mc *metricCatalog) GetPlugin(mns core.Namespace, ver int) (core.CatalogedPlugin, error) {
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+
 	mt, err := mc.tree.GetMetric(mns.Strings(), ver)
 	if err != nil {
 		log.WithFields(log.Fields{
@@ -612,16 +1074,6 @@ func (mc *metricCatalog) GetPlugin(mns core.Namespace, ver int) (core.CatalogedP
 	return mt.Plugin, nil
 }
 
-func appendIfMissing(keys []string, ns string) []string {
-	for _, key := range keys {
-		if ns == key {
-			// do not append if the key was found in keys
-			return keys
-		}
-	}
-	return append(keys, ns)
-}
-
 // isTuple returns true when incoming namespace's element has been recognized as a tuple, otherwise returns false
 // notice, that the tuple is a string which starts with `core.TuplePrefix`, ends with `core.TupleSuffix`
 // and contains at least one `core.TupleSeparator`, e.g. (host0;host1)
@@ -22,7 +22,9 @@ limitations under the License.
 package control
 
 import (
+	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/intelsdi-x/snap/control/plugin"
 	"github.com/intelsdi-x/snap/core"
@@ -129,6 +131,277 @@ func TestContainsTupleNegative(t *testing.T) {
 	})
 }
 
+func TestQuery(t *testing.T) {
+	Convey("metricCatalog.Query()", t, func() {
+		mc := newMetricCatalog()
+		lp := &loadedPlugin{Meta: plugin.PluginMeta{Name: "psutil", Version: 1}}
+		load := newMetricType(core.NewNamespace("intel", "psutil", "load", "load1"), time.Now(), lp)
+		load.tags = map[string]string{"group": "load"}
+		mem := newMetricType(core.NewNamespace("intel", "psutil", "vm", "free"), time.Now(), lp)
+		mem.unit = "bytes"
+		mc.Add(load)
+		mc.Add(mem)
+
+		Convey("matches advertised tags", func() {
+			mts, err := mc.Query(map[string]string{"group": "load"})
+			So(err, ShouldBeNil)
+			So(len(mts), ShouldEqual, 1)
+			So(mts[0], ShouldEqual, load)
+		})
+		Convey("matches unit and plugin name", func() {
+			mts, err := mc.Query(map[string]string{"unit": "bytes", "plugin": "psutil"})
+			So(err, ShouldBeNil)
+			So(len(mts), ShouldEqual, 1)
+			So(mts[0], ShouldEqual, mem)
+		})
+		Convey("returns all metrics for empty tags", func() {
+			mts, err := mc.Query(map[string]string{})
+			So(err, ShouldBeNil)
+			So(len(mts), ShouldEqual, 2)
+		})
+		Convey("returns an error when nothing matches", func() {
+			mts, err := mc.Query(map[string]string{"plugin": "mock"})
+			So(err, ShouldNotBeNil)
+			So(mts, ShouldBeNil)
+		})
+	})
+}
+
+func TestFetchPage(t *testing.T) {
+	Convey("metricCatalog.FetchPage()", t, func() {
+		mc := newMetricCatalog()
+		lp := &loadedPlugin{Meta: plugin.PluginMeta{Name: "psutil", Version: 1}}
+		for _, n := range []string{"load5", "load1", "load15"} {
+			mc.Add(newMetricType(core.NewNamespace("intel", "psutil", "load", n), time.Now(), lp))
+		}
+		mc.Add(newMetricType(core.NewNamespace("intel", "psutil", "vm", "free"), time.Now(), lp))
+
+		Convey("returns metrics ordered by namespace", func() {
+			mts, total, err := mc.FetchPage([]string{"intel", "psutil", "load"}, 0, 2)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 3)
+			So(len(mts), ShouldEqual, 2)
+			So(mts[0].Namespace().String(), ShouldEqual, "/intel/psutil/load/load1")
+			So(mts[1].Namespace().String(), ShouldEqual, "/intel/psutil/load/load15")
+		})
+		Convey("returns the remaining metrics on the last page", func() {
+			mts, total, err := mc.FetchPage([]string{}, 3, 2)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 4)
+			So(len(mts), ShouldEqual, 1)
+			So(mts[0].Namespace().String(), ShouldEqual, "/intel/psutil/vm/free")
+		})
+		Convey("returns all metrics from offset when limit is 0", func() {
+			mts, _, err := mc.FetchPage([]string{}, 1, 0)
+			So(err, ShouldBeNil)
+			So(len(mts), ShouldEqual, 3)
+		})
+		Convey("returns an empty page when offset is out of range", func() {
+			mts, total, err := mc.FetchPage([]string{}, 10, 2)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 4)
+			So(mts, ShouldBeEmpty)
+		})
+		Convey("returns an error for negative offset or limit", func() {
+			_, _, err := mc.FetchPage([]string{}, -1, 2)
+			So(err, ShouldNotBeNil)
+			_, _, err = mc.FetchPage([]string{}, 0, -2)
+			So(err, ShouldNotBeNil)
+		})
+		Convey("returns an error when no metric falls under the namespace", func() {
+			_, _, err := mc.FetchPage([]string{"intel", "mock"}, 0, 2)
+			So(err, ShouldNotBeNil)
+		})
+		Convey("pages through the versions of a metric and wildcards", func() {
+			lp2 := &loadedPlugin{Meta: plugin.PluginMeta{Name: "psutil", Version: 2}}
+			mt := newMetricType(core.NewNamespace("intel", "psutil", "load", "load1"), time.Now(), lp2)
+			mt.version = 2
+			mc.Add(mt)
+			mts, total, err := mc.FetchPage([]string{"intel", "psutil", "*"}, 1, 2)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 5)
+			So(len(mts), ShouldEqual, 2)
+			So(mts[0].Namespace().String(), ShouldEqual, "/intel/psutil/load/load1")
+			So(mts[0].Version(), ShouldEqual, 2)
+			So(mts[1].Namespace().String(), ShouldEqual, "/intel/psutil/load/load15")
+		})
+		Convey("keeps the total in sync with removed metrics", func() {
+			mc.tree.RemoveMetric(*newMetricType(core.NewNamespace("intel", "psutil", "load", "load5"), time.Now(), lp))
+			_, total, err := mc.FetchPage([]string{}, 0, 0)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 3)
+			So(mc.tree.Remove([]string{"intel", "psutil", "load"}), ShouldBeNil)
+			mts, total, err := mc.FetchPage([]string{}, 0, 0)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 1)
+			So(mts[0].Namespace().String(), ShouldEqual, "/intel/psutil/vm/free")
+		})
+	})
+}
+
+func TestDeprecatedMetrics(t *testing.T) {
+	Convey("Given deprecated versions of a metric in the catalog", t, func() {
+		mc := newMetricCatalog()
+		ns := core.NewNamespace("intel", "psutil", "load", "load1")
+		var mts []*metricType
+		for v := 1; v <= 3; v++ {
+			lp := &loadedPlugin{Meta: plugin.PluginMeta{Name: "psutil", Version: v}}
+			mt := newMetricType(ns, time.Now(), lp)
+			mt.version = v
+			mts = append(mts, mt)
+			mc.Add(mt)
+		}
+		mts[2].tags = map[string]string{core.DEPRECATED_TAG: "use version 2"}
+
+		Convey("the latest version skips deprecated versions", func() {
+			mt, err := mc.tree.GetMetric(ns.Strings(), -1)
+			So(err, ShouldBeNil)
+			So(mt.Version(), ShouldEqual, 2)
+			So(mts[2].Deprecated(), ShouldBeTrue)
+			So(mts[2].DeprecationMessage(), ShouldEqual, "use version 2")
+		})
+		Convey("a deprecated version can still be requested explicitly", func() {
+			mt, err := mc.tree.GetMetric(ns.Strings(), 3)
+			So(err, ShouldBeNil)
+			So(mt.Version(), ShouldEqual, 3)
+		})
+		Convey("the latest deprecated version is used when all versions are deprecated", func() {
+			mts[0].tags = map[string]string{core.DEPRECATED_TAG: ""}
+			mts[1].tags = map[string]string{core.DEPRECATED_TAG: ""}
+			mt, err := mc.tree.GetMetric(ns.Strings(), -1)
+			So(err, ShouldBeNil)
+			So(mt.Version(), ShouldEqual, 3)
+		})
+		Convey("only newly subscribed deprecated metrics are reported", func() {
+			subscribed := map[string]metricTypes{"psutil": {metricTypes: []core.Metric{mts[2]}}}
+			So(newlyDeprecatedMetrics(subscribed, nil), ShouldResemble, []*metricType{mts[2]})
+			So(newlyDeprecatedMetrics(subscribed, subscribed), ShouldBeEmpty)
+			notDeprecated := map[string]metricTypes{"psutil": {metricTypes: []core.Metric{mts[1]}}}
+			So(newlyDeprecatedMetrics(notDeprecated, nil), ShouldBeEmpty)
+		})
+	})
+}
+
+func TestMatch(t *testing.T) {
+	Convey("metricCatalog.Match()", t, func() {
+		mc := newMetricCatalog()
+		lp := &loadedPlugin{Meta: plugin.PluginMeta{Name: "docker", Version: 1}}
+		dyn := newMetricType(core.ParseNamespace("/intel/docker/[container_id]/cpu"), time.Now(), lp)
+		static := newMetricType(core.NewNamespace("intel", "docker", "root", "cpu"), time.Now(), lp)
+		mc.Add(dyn)
+		mc.Add(static)
+
+		Convey("matches a concrete namespace against a dynamic entry", func() {
+			mts, err := mc.Match(core.NewNamespace("intel", "docker", "1234", "cpu"))
+			So(err, ShouldBeNil)
+			So(len(mts), ShouldEqual, 1)
+			So(mts[0], ShouldEqual, dyn)
+			So(mts[0].Namespace().DynamicElementNames(), ShouldResemble, []string{"container_id"})
+		})
+		Convey("matches both the static and the dynamic entry", func() {
+			mts, err := mc.Match(core.NewNamespace("intel", "docker", "root", "cpu"))
+			So(err, ShouldBeNil)
+			So(len(mts), ShouldEqual, 2)
+		})
+		Convey("returns an error when nothing matches", func() {
+			_, err := mc.Match(core.NewNamespace("intel", "docker", "1234", "mem"))
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestWatch(t *testing.T) {
+	Convey("metricCatalog.Watch()", t, func() {
+		mc := newMetricCatalog()
+		events, cancel := mc.Watch()
+		lp := &loadedPlugin{Meta: plugin.PluginMeta{Name: "psutil", Version: 1}}
+		load := newMetricType(core.NewNamespace("intel", "psutil", "load", "load1"), time.Now(), lp)
+		mc.Add(load)
+
+		Convey("emits an event when a metric type is added", func() {
+			e := <-events
+			So(e.Type, ShouldEqual, MetricTypeAdded)
+			So(e.Metric, ShouldEqual, load)
+
+			Convey("and when it is replaced", func() {
+				update := newMetricType(core.NewNamespace("intel", "psutil", "load", "load1"), time.Now(), lp)
+				mc.Add(update)
+				e := <-events
+				So(e.Type, ShouldEqual, MetricTypeUpdated)
+				So(e.Metric, ShouldEqual, update)
+			})
+			Convey("and when it is removed", func() {
+				mc.Remove(core.NewNamespace("intel", "psutil"))
+				e := <-events
+				So(e.Type, ShouldEqual, MetricTypeRemoved)
+				So(e.Metric.Namespace(), ShouldResemble, load.Namespace())
+			})
+			Convey("and when the plugin is unloaded", func() {
+				mc.RmUnloadedPluginMetrics(lp)
+				e := <-events
+				So(e.Type, ShouldEqual, MetricTypeRemoved)
+				So(e.Metric.Namespace(), ShouldResemble, load.Namespace())
+			})
+		})
+		Convey("closes the channel when canceled", func() {
+			cancel()
+			cancel()
+			<-events
+			_, ok := <-events
+			So(ok, ShouldBeFalse)
+			So(mc.watchers, ShouldBeEmpty)
+		})
+	})
+}
+
+func TestGetMetricWithoutPlugin(t *testing.T) {
+	Convey("Given a metric type without a plugin in the catalog", t, func() {
+		mc := newMetricCatalog()
+		mc.Add(&metricType{namespace: core.NewNamespace("intel", "orphan"), version: 1})
+		Convey("it is not found by its version", func() {
+			_, err := mc.GetMetric(core.NewNamespace("intel", "orphan"), 1)
+			So(err, ShouldNotBeNil)
+		})
+		Convey("it is not found as the latest version", func() {
+			_, err := mc.GetMetric(core.NewNamespace("intel", "orphan"), -1)
+			So(err, ShouldNotBeNil)
+			_, err = mc.GetMetrics(core.NewNamespace("intel", "*"), -1)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestCatalogQueryFuzz(t *testing.T) {
+	Convey("Given a catalog", t, func() {
+		mc := newMetricCatalog()
+		lp := &loadedPlugin{Meta: plugin.PluginMeta{Name: "mock", Version: 1}}
+		mc.Add(newMetricType(core.NewNamespace("intel", "mock", "foo"), time.Now(), lp))
+		mc.Add(newMetricType(core.NewNamespace("intel", "mock").AddDynamicElement("host", "host id").AddStaticElement("bar"), time.Now(), lp))
+		mc.Add(&metricType{namespace: core.NewNamespace("intel", "mock", "orphan"), version: 2})
+
+		Convey("random queries do not panic", func() {
+			elements := []string{"intel", "mock", "foo", "bar", "orphan", "host0", "*", "", "(foo;bar)", "a.b", "/"}
+			r := rand.New(rand.NewSource(1))
+			So(func() {
+				for i := 0; i < 1000; i++ {
+					ns := core.Namespace{}
+					for j := r.Intn(5); j > 0; j-- {
+						ns = ns.AddStaticElement(elements[r.Intn(len(elements))])
+					}
+					ver := r.Intn(4) - 1
+					mc.GetMetric(ns, ver)
+					mc.GetMetrics(ns, ver)
+					mc.GetVersions(ns)
+					mc.Fetch(ns)
+					mc.FetchPage(ns.Strings(), r.Intn(3)-1, r.Intn(3)-1)
+					mc.Match(ns)
+					mc.Query(map[string]string{"plugin_running_on": elements[r.Intn(len(elements))]})
+				}
+			}, ShouldNotPanic)
+		})
+	})
+}
+
 type mockHostnameReader struct{}
 
 func (m *mockHostnameReader) Hostname() string {
@@ -22,6 +22,8 @@ limitations under the License.
 package control
 
 import (
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -601,13 +603,21 @@ func TestSubscribe(t *testing.T) {
 	}
 	Convey("when the metric is not in the table", t, func() {
 		Convey("then it returns an error", func() {
-			err := mc.Subscribe([]string{"test4"}, -1)
+			err := mc.Subscribe([]string{"test4"}, -1, "task1")
 			So(err.Error(), ShouldContainSubstring, "Metric not found:")
 		})
 	})
 	Convey("when the metric is in the table", t, func() {
 		Convey("then it gets correctly increments the count", func() {
-			err := mc.Subscribe([]string{"test1"}, -1)
+			err := mc.Subscribe([]string{"test1"}, -1, "task1")
+			So(err, ShouldBeNil)
+			m, err2 := mc.GetMetric(core.NewNamespace("test1"), -1)
+			So(err2, ShouldBeNil)
+			So(m.SubscriptionCount(), ShouldEqual, 1)
+			So(m.Subscribers(), ShouldResemble, []string{"task1"})
+		})
+		Convey("then subscribing again by the same task does not change the count", func() {
+			err := mc.Subscribe([]string{"test1"}, -1, "task1")
 			So(err, ShouldBeNil)
 			m, err2 := mc.GetMetric(core.NewNamespace("test1"), -1)
 			So(err2, ShouldBeNil)
@@ -636,25 +646,58 @@ func TestUnsubscribe(t *testing.T) {
 	}
 	Convey("when the metric is in the table", t, func() {
 		Convey("then its subscription count is decremented", func() {
-			err := mc.Subscribe([]string{"test1"}, -1)
+			err := mc.Subscribe([]string{"test1"}, -1, "task1")
 			So(err, ShouldBeNil)
-			err1 := mc.Unsubscribe([]string{"test1"}, -1)
+			err1 := mc.Unsubscribe([]string{"test1"}, -1, "task1")
 			So(err1, ShouldBeNil)
 			m, err2 := mc.GetMetric(core.NewNamespace("test1"), -1)
 			So(err2, ShouldBeNil)
-			So(m.subscriptions, ShouldEqual, 0)
+			So(m.SubscriptionCount(), ShouldEqual, 0)
 		})
 	})
 	Convey("when the metric is not in the table", t, func() {
 		Convey("then it returns metric not found error", func() {
-			err := mc.Unsubscribe([]string{"test4"}, -1)
+			err := mc.Unsubscribe([]string{"test4"}, -1, "task1")
 			So(err.Error(), ShouldContainSubstring, "Metric not found:")
 		})
 	})
-	Convey("when the metric's count is already 0", t, func() {
-		Convey("then it returns negative subscription count error", func() {
-			err := mc.Unsubscribe([]string{"test1"}, -1)
-			So(err, ShouldResemble, errNegativeSubCount)
+	Convey("when the task is not subscribed to the metric", t, func() {
+		Convey("then unsubscribing has no effect", func() {
+			err := mc.Unsubscribe([]string{"test1"}, -1, "task1")
+			So(err, ShouldBeNil)
+			m, err2 := mc.GetMetric(core.NewNamespace("test1"), -1)
+			So(err2, ShouldBeNil)
+			So(m.SubscriptionCount(), ShouldEqual, 0)
+		})
+	})
+	Convey("when all subscriptions of a task are removed", t, func() {
+		Convey("then only the subscriptions of that task are removed", func() {
+			So(mc.Subscribe([]string{"test1"}, -1, "task1"), ShouldBeNil)
+			So(mc.Subscribe([]string{"test2"}, -1, "task1"), ShouldBeNil)
+			So(mc.Subscribe([]string{"test2"}, -1, "task2"), ShouldBeNil)
+			mc.UnsubscribeAll("task1")
+			m1, err := mc.GetMetric(core.NewNamespace("test1"), -1)
+			So(err, ShouldBeNil)
+			So(m1.SubscriptionCount(), ShouldEqual, 0)
+			m2, err := mc.GetMetric(core.NewNamespace("test2"), -1)
+			So(err, ShouldBeNil)
+			So(m2.Subscribers(), ShouldResemble, []string{"task2"})
+		})
+	})
+	Convey("when the subscriptions of a task are replaced", t, func() {
+		Convey("then the task only holds the new subscriptions", func() {
+			So(mc.Subscribe([]string{"test1"}, -1, "task3"), ShouldBeNil)
+			m2, err := mc.GetMetric(core.NewNamespace("test2"), -1)
+			So(err, ShouldBeNil)
+			missing := newMetricType(core.NewNamespace("test4"), time.Now(), &loadedPlugin{})
+			errs := mc.Resubscribe("task3", []core.Metric{m2, missing})
+			So(len(errs), ShouldEqual, 1)
+			m1, err := mc.GetMetric(core.NewNamespace("test1"), -1)
+			So(err, ShouldBeNil)
+			So(m1.Subscribers(), ShouldNotContain, "task3")
+			m2, err = mc.GetMetric(core.NewNamespace("test2"), -1)
+			So(err, ShouldBeNil)
+			So(m2.Subscribers(), ShouldResemble, []string{"task2", "task3"})
 		})
 	})
 }
@@ -662,12 +705,16 @@ func TestUnsubscribe(t *testing.T) {
 func TestSubscriptionCount(t *testing.T) {
 	m := newMetricType(core.NewNamespace("test"), time.Now(), &loadedPlugin{})
 	Convey("it returns the subscription count", t, func() {
-		m.Subscribe()
+		m.Subscribe("task1")
 		So(m.SubscriptionCount(), ShouldEqual, 1)
-		m.Subscribe()
-		m.Subscribe()
+		m.Subscribe("task2")
+		m.Subscribe("task3")
+		m.Subscribe("task3")
 		So(m.SubscriptionCount(), ShouldEqual, 3)
-		m.Unsubscribe()
+		m.Unsubscribe("task2")
+		So(m.SubscriptionCount(), ShouldEqual, 2)
+		So(m.Subscribers(), ShouldResemble, []string{"task1", "task3"})
+		m.Unsubscribe("task2")
 		So(m.SubscriptionCount(), ShouldEqual, 2)
 	})
 }
@@ -719,3 +766,196 @@ func TestMetricStaticDynamicNamespace(t *testing.T) {
 		})
 	})
 }
+
+func TestMetricCatalogConcurrency(t *testing.T) {
+	Convey("metricCatalog serves readers while metrics are added", t, func() {
+		mc := newMetricCatalog()
+		lp := new(loadedPlugin)
+		lp.ConfigPolicy = cpolicy.New()
+		mc.Add(newMetricType(core.NewNamespace("intel", "foo"), time.Now(), lp))
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					mc.GetMetric(core.NewNamespace("intel", "foo"), -1)
+					mc.Fetch(core.NewNamespace("intel"))
+					mc.Keys()
+				}
+			}()
+		}
+		for j := 0; j < 100; j++ {
+			mc.Add(newMetricType(core.NewNamespace("intel", fmt.Sprintf("bar%d", j)), time.Now(), lp))
+		}
+		wg.Wait()
+		So(mc.Keys(), ShouldHaveLength, 101)
+	})
+}
+
+func TestMetricCatalogAddLoadedMetricTypes(t *testing.T) {
+	Convey("metricCatalog.AddLoadedMetricTypes()", t, func() {
+		mc := newMetricCatalog()
+		lp := new(loadedPlugin)
+		lp.ConfigPolicy = cpolicy.New()
+		mts := []core.Metric{
+			plugin.MetricType{Namespace_: core.NewNamespace("intel", "foo"), Version_: 1},
+			plugin.MetricType{Namespace_: core.NewNamespace("intel", "foo"), Version_: 2},
+			plugin.MetricType{Namespace_: core.NewNamespace("intel", "bar"), Version_: 1},
+		}
+		Convey("adds the metric types at once", func() {
+			So(mc.AddLoadedMetricTypes(lp, mts), ShouldBeNil)
+			So(mc.Keys(), ShouldResemble, []string{"/intel/foo", "/intel/bar"})
+			versions, err := mc.GetVersions(core.NewNamespace("intel", "foo"))
+			So(err, ShouldBeNil)
+			So(versions, ShouldHaveLength, 2)
+			Convey("whose keys are removed with them", func() {
+				mc.Remove(core.NewNamespace("intel", "foo"))
+				So(mc.Keys(), ShouldResemble, []string{"/intel/bar"})
+			})
+		})
+		Convey("adds none of them when one is invalid", func() {
+			mts = append(mts, plugin.MetricType{Namespace_: core.NewNamespace("intel", "*"), Version_: 1})
+			So(mc.AddLoadedMetricTypes(lp, mts), ShouldNotBeNil)
+			So(mc.Keys(), ShouldBeEmpty)
+		})
+		Convey("adds none of them without a config policy", func() {
+			So(mc.AddLoadedMetricTypes(new(loadedPlugin), mts), ShouldEqual, ErrNilConfigPolicy)
+			So(mc.Keys(), ShouldBeEmpty)
+		})
+	})
+}
+
+func TestMetricCatalogIterate(t *testing.T) {
+	Convey("metricCatalog.Iterate()", t, func() {
+		mc := newMetricCatalog()
+		lp := new(loadedPlugin)
+		lp.ConfigPolicy = cpolicy.New()
+		lp.Meta.Version = 1
+		lp2 := new(loadedPlugin)
+		lp2.ConfigPolicy = cpolicy.New()
+		lp2.Meta.Version = 2
+		mc.Add(newMetricType(core.NewNamespace("intel", "foo"), time.Now(), lp2))
+		mc.Add(newMetricType(core.NewNamespace("intel", "foo"), time.Now(), lp))
+		mc.Add(newMetricType(core.NewNamespace("intel", "bar"), time.Now(), lp))
+		it := mc.Iterate()
+		Convey("iterates over the namespaces in the order they were added", func() {
+			So(it.Next(), ShouldBeTrue)
+			key, mts := it.Item()
+			So(key, ShouldEqual, "/intel/foo")
+			So(mts, ShouldHaveLength, 2)
+			So(mts[0].Version(), ShouldEqual, 1)
+			So(mts[1].Version(), ShouldEqual, 2)
+			So(it.Next(), ShouldBeTrue)
+			key, mts = it.Item()
+			So(key, ShouldEqual, "/intel/bar")
+			So(mts, ShouldHaveLength, 1)
+			So(it.Next(), ShouldBeFalse)
+		})
+		Convey("iterates over a snapshot of the catalog", func() {
+			mc.Add(newMetricType(core.NewNamespace("intel", "baz"), time.Now(), lp))
+			So(mc.Subscribe([]string{"intel", "bar"}, 1, "task"), ShouldBeNil)
+			var keys []string
+			for it.Next() {
+				key, mts := it.Item()
+				keys = append(keys, key)
+				for _, mt := range mts {
+					So(mt.SubscriptionCount(), ShouldEqual, 0)
+				}
+			}
+			So(keys, ShouldResemble, []string{"/intel/foo", "/intel/bar"})
+		})
+		Convey("iterates independently of the other iterators", func() {
+			So(it.Next(), ShouldBeTrue)
+			other := mc.Iterate()
+			So(other.Next(), ShouldBeTrue)
+			So(other.Next(), ShouldBeTrue)
+			key, _ := it.Item()
+			So(key, ShouldEqual, "/intel/foo")
+		})
+	})
+}
+
+// benchmarkCatalog returns a catalog of 50k metrics, 100 metrics of 500
+// hosts, with the namespaces of the metrics
+func benchmarkCatalog() (*metricCatalog, []core.Namespace) {
+	mc := newMetricCatalog()
+	lp := new(loadedPlugin)
+	lp.ConfigPolicy = cpolicy.New()
+	var nss []core.Namespace
+	for h := 0; h < 500; h++ {
+		for m := 0; m < 100; m++ {
+			ns := core.NewNamespace("intel", "bench", fmt.Sprintf("host%d", h), fmt.Sprintf("metric%d", m))
+			mc.Add(newMetricType(ns, time.Now(), lp))
+			nss = append(nss, ns)
+		}
+	}
+	return mc, nss
+}
+
+func BenchmarkMetricCatalogGetMetric(b *testing.B) {
+	mc, nss := benchmarkCatalog()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			if _, err := mc.GetMetric(nss[i%len(nss)], -1); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkMetricCatalogFetch(b *testing.B) {
+	mc, _ := benchmarkCatalog()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			if _, err := mc.Fetch(core.NewNamespace("intel", "bench", fmt.Sprintf("host%d", i%500))); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkMetricCatalogGetMetricWithAdds reads the catalog while a metric is
+// added every millisecond
+func BenchmarkMetricCatalogGetMetricWithAdds(b *testing.B) {
+	mc, nss := benchmarkCatalog()
+	lp := new(loadedPlugin)
+	lp.ConfigPolicy = cpolicy.New()
+	done := make(chan struct{})
+	go func() {
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			case <-time.After(time.Millisecond):
+				mc.Add(newMetricType(core.NewNamespace("intel", "added", fmt.Sprintf("metric%d", i)), time.Now(), lp))
+			}
+		}
+	}()
+	defer close(done)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			if _, err := mc.GetMetric(nss[i%len(nss)], -1); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkMetricCatalogAddLoadedMetricTypes(b *testing.B) {
+	lp := new(loadedPlugin)
+	lp.ConfigPolicy = cpolicy.New()
+	mts := make([]core.Metric, 50000)
+	for i := range mts {
+		mts[i] = plugin.MetricType{Namespace_: core.NewNamespace("intel", "bench", fmt.Sprintf("host%d", i/100), fmt.Sprintf("metric%d", i%100)), Version_: 1}
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := newMetricCatalog().AddLoadedMetricTypes(lp, mts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
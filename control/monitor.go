@@ -76,7 +76,8 @@ func newMonitor(opts ...monitorOption) *monitor {
 // Start starts the monitor
 func (m *monitor) Start(availablePlugins *availablePlugins) {
 	//start a routine that will be fired every X duration looping
-	//over available plugins and firing a health check routine
+	//over available plugins and firing a health check routine, then killing
+	//the plugins idle for longer than the idle timeout of their pool
 	ticker := time.NewTicker(m.duration)
 	m.quit = make(chan struct{})
 	go func() {
@@ -91,6 +92,7 @@ func (m *monitor) Start(availablePlugins *availablePlugins) {
 						}
 					}
 					availablePlugins.RUnlock()
+					availablePlugins.killIdle()
 				}()
 			case <-m.quit:
 				ticker.Stop()
@@ -55,6 +55,8 @@ FETCH /metric/root/foo -> trie.Fetch([]string{"root", "foo"}) ->
 type mttNode struct {
 	children map[string]*mttNode
 	mts      map[int]*metricType
+	// count is the number of metric types in the node and its descendants
+	count int
 }
 
 // MTTrie struct representing the root in the trie
@@ -95,25 +97,34 @@ func (m *MTTrie) gatherMetricTypes() []metricType {
 }
 
 // DeleteByPlugin removes all metrics from the catalog if they match a loadedPlugin
-func (m *MTTrie) DeleteByPlugin(cp core.CatalogedPlugin) {
+// and returns the removed metrics
+func (m *MTTrie) DeleteByPlugin(cp core.CatalogedPlugin) []metricType {
+	var removed []metricType
 	for _, mt := range m.gatherMetricTypes() {
 		mtPluginKey := fmt.Sprintf("%s"+core.Separator+"%s"+core.Separator+"%d", mt.Plugin.TypeName(), mt.Plugin.Name(), mt.Plugin.Version())
 		cpKey := fmt.Sprintf("%s"+core.Separator+"%s"+core.Separator+"%d", cp.TypeName(), cp.Name(), cp.Version())
 		if mtPluginKey == cpKey {
 			// remove this metric
 			m.RemoveMetric(mt)
+			removed = append(removed, mt)
 		}
 	}
+	return removed
 }
 
 // RemoveMetric removes a specific metric by namespace and version from the tree
 func (m *MTTrie) RemoveMetric(mt metricType) {
-	a, _ := m.find(mt.Namespace().Strings())
-	if a != nil {
-		for v, x := range a.mts {
-			if mt.Version() == x.Version() {
-				// delete this metric from the node
-				delete(a.mts, v)
+	path := m.path(mt.Namespace().Strings())
+	if path == nil {
+		return
+	}
+	a := path[len(path)-1]
+	for v, x := range a.mts {
+		if mt.Version() == x.Version() {
+			// delete this metric from the node
+			delete(a.mts, v)
+			for _, n := range path {
+				n.count--
 			}
 		}
 	}
@@ -121,30 +132,37 @@ func (m *MTTrie) RemoveMetric(mt metricType) {
 
 // Add adds a node with the given namespace with the given MetricType
 func (mtt *mttNode) Add(mt *metricType) {
-	ns := mt.Namespace()
-	node, index := mtt.walk(ns.Strings())
-	if index == len(ns) {
-		if node.mts == nil {
-			node.mts = make(map[int]*metricType)
-		}
-		node.mts[mt.Version()] = mt
-		return
-	}
-	// walk through the remaining namespace and build out the
-	// new branch in the trie.
-	for _, n := range ns[index:] {
+	path := []*mttNode{mtt}
+	node := mtt
+	// walk through the namespace and build out the missing
+	// branch in the trie.
+	for _, n := range mt.Namespace().Strings() {
 		if node.children == nil {
 			node.children = make(map[string]*mttNode)
 		}
-		node.children[n.Value] = &mttNode{}
-		node = node.children[n.Value]
+		child, ok := node.children[n]
+		if !ok {
+			child = &mttNode{}
+			node.children[n] = child
+		}
+		node = child
+		path = append(path, node)
+	}
+	if node.mts == nil {
+		node.mts = make(map[int]*metricType)
+	}
+	if _, ok := node.mts[mt.Version()]; !ok {
+		for _, n := range path {
+			n.count++
+		}
 	}
-	node.mts = make(map[int]*metricType)
 	node.mts[mt.Version()] = mt
 }
 
 // Fetch collects all children below a given namespace
-// and concatenates their metric types into a single slice
+// and concatenates their metric types into a single slice.
+// An asterisk used as an element of the namespace matches any element
+// on that level (e.g. /intel/psutil/*/load1)
 func (mtt *mttNode) Fetch(ns []string) ([]*metricType, error) {
 	children := mtt.fetch(ns)
 	var mts []*metricType
@@ -159,19 +177,86 @@ func (mtt *mttNode) Fetch(ns []string) ([]*metricType, error) {
 	return mts, nil
 }
 
+// FetchPage returns a page of the metric types below a given namespace ordered
+// by namespace and version, along with the number of all of them. The metric
+// types before the offset are skipped without being gathered, a limit of 0
+// returns all of them starting at the offset.
+func (mtt *mttNode) FetchPage(ns []string, offset, limit int) ([]*metricType, int, error) {
+	nodes := mtt.findAll(ns)
+	total := 0
+	for _, node := range nodes {
+		total += node.count
+	}
+	if total == 0 && len(ns) > 0 {
+		return nil, 0, errorMetricsNotFound("/" + strings.Join(ns, "/"))
+	}
+	mts := []*metricType{}
+	for _, node := range nodes {
+		if limit > 0 && len(mts) >= limit {
+			break
+		}
+		mts, offset = node.page(mts, offset, limit)
+	}
+	return mts, total, nil
+}
+
+// page appends the metric types of the node and its descendants ordered by
+// namespace and version to mts, skipping the first offset of them, until mts
+// holds limit metric types. It returns the offset left to skip.
+func (mtt *mttNode) page(mts []*metricType, offset, limit int) ([]*metricType, int) {
+	if offset >= mtt.count {
+		return mts, offset - mtt.count
+	}
+	if offset >= len(mtt.mts) {
+		offset -= len(mtt.mts)
+	} else {
+		versions := make([]int, 0, len(mtt.mts))
+		for v := range mtt.mts {
+			versions = append(versions, v)
+		}
+		sort.Ints(versions)
+		for _, v := range versions[offset:] {
+			if limit > 0 && len(mts) >= limit {
+				return mts, 0
+			}
+			mts = append(mts, mtt.mts[v])
+		}
+		offset = 0
+	}
+	for _, name := range mtt.childNames() {
+		if limit > 0 && len(mts) >= limit {
+			break
+		}
+		mts, offset = mtt.children[name].page(mts, offset, limit)
+	}
+	return mts, offset
+}
+
+// childNames returns the sorted names of the children of the node
+func (mtt *mttNode) childNames() []string {
+	names := make([]string, 0, len(mtt.children))
+	for name := range mtt.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // Remove removes all descendants nodes below a given namespace
 func (mtt *mttNode) Remove(ns []string) error {
-	_, err := mtt.find(ns)
-	if err != nil {
-		return err
+	if len(ns) == 0 {
+		return errorEmptyNamespace()
 	}
-
-	parent, err := mtt.find(ns[:len(ns)-1])
-	if err != nil {
-		return err
+	path := mtt.path(ns)
+	if path == nil {
+		return errorMetricNotFound("/" + strings.Join(ns, "/"))
 	}
+	node := path[len(path)-1]
 	// remove node from parent
-	delete(parent.children, ns[len(ns)-1:][0])
+	delete(path[len(path)-2].children, ns[len(ns)-1])
+	for _, n := range path[:len(path)-1] {
+		n.count -= node.count
+	}
 
 	return nil
 }
@@ -183,6 +268,9 @@ func (mtt *mttNode) GetMetric(ns []string, ver int) (*metricType, error) {
 	if err != nil {
 		return nil, err
 	}
+	if len(mts) == 0 {
+		return nil, errorMetricNotFound("/"+strings.Join(ns, "/"), ver)
+	}
 	// there is an expectation that only one metric should be fitted
 	if len(mts) > 1 {
 		return nil, fmt.Errorf("Incoming namespace `%s` is too ambiguous (version: %d)", "/"+strings.Join(ns, "/"), ver)
@@ -243,18 +331,15 @@ func (mtt *mttNode) GetVersions(ns []string) ([]*metricType, error) {
 
 // fetch collects all descendants nodes below the given namespace
 func (mtt *mttNode) fetch(ns []string) []*mttNode {
-	node, err := mtt.find(ns)
-	if err != nil {
-		return nil
-	}
-
 	var children []*mttNode
 
-	if node.mts != nil {
-		children = append(children, node)
-	}
-	if node.children != nil {
-		children = gatherDescendants(children, node)
+	for _, node := range mtt.findAll(ns) {
+		if node.mts != nil {
+			children = append(children, node)
+		}
+		if node.children != nil {
+			children = gatherDescendants(children, node)
+		}
 	}
 	return children
 }
@@ -308,6 +393,22 @@ func (mtt *mttNode) search(nodes []*mttNode, ns []string) []*mttNode {
 	return nodes
 }
 
+// path returns the nodes from the node down to the one at the given namespace,
+// nil is returned when the namespace is not in the trie
+func (mtt *mttNode) path(ns []string) []*mttNode {
+	path := []*mttNode{mtt}
+	node := mtt
+	for _, n := range ns {
+		child, ok := node.children[n]
+		if !ok {
+			return nil
+		}
+		node = child
+		path = append(path, node)
+	}
+	return path
+}
+
 func (mtt *mttNode) find(ns []string) (*mttNode, error) {
 	node, index := mtt.walk(ns)
 	if index != len(ns) {
@@ -316,6 +417,45 @@ func (mtt *mttNode) find(ns []string) (*mttNode, error) {
 	return node, nil
 }
 
+// match returns all nodes on the path of the given concrete namespace where
+// on each level both the child with the same name and the child representing
+// a dynamic element (an asterisk) are followed
+func (mtt *mttNode) match(ns []string) []*mttNode {
+	if len(ns) == 0 {
+		return []*mttNode{mtt}
+	}
+	var nodes []*mttNode
+	if child, ok := mtt.children[ns[0]]; ok {
+		nodes = append(nodes, child.match(ns[1:])...)
+	}
+	if ns[0] != "*" {
+		if child, ok := mtt.children["*"]; ok {
+			nodes = append(nodes, child.match(ns[1:])...)
+		}
+	}
+	return nodes
+}
+
+// findAll returns all nodes matching the given namespace ordered by namespace,
+// an asterisk matches any child on its level; all returned nodes are on the
+// same depth so none of them is a descendant of another one
+func (mtt *mttNode) findAll(ns []string) []*mttNode {
+	if len(ns) == 0 {
+		return []*mttNode{mtt}
+	}
+	var nodes []*mttNode
+	if ns[0] == "*" {
+		for _, name := range mtt.childNames() {
+			nodes = append(nodes, mtt.children[name].findAll(ns[1:])...)
+		}
+		return nodes
+	}
+	if child, ok := mtt.children[ns[0]]; ok {
+		nodes = child.findAll(ns[1:])
+	}
+	return nodes
+}
+
 // gatherChildren returns child or children by the 'name' of a given node (direct descendant(s))
 // and concatenates this direct descendant(s) into a single slice
 func (mtt *mttNode) gatherChildren(name string) []*mttNode {
@@ -358,18 +498,31 @@ func gatherDescendants(descendants []*mttNode, node *mttNode) []*mttNode {
 	return descendants
 }
 
-// getVersion returns the MT in the latest version
+// getLatest returns the MT in the latest version,
+// skipping deprecated versions unless all of the versions are deprecated.
+// Nil is returned when none of the MTs has a plugin.
 func getLatest(mts map[int]*metricType) *metricType {
 	versions := []int{}
 
 	// version is a key in mts map
-	for ver := range mts {
+	for ver, mt := range mts {
+		if mt == nil || mt.Plugin == nil {
+			continue
+		}
 		// concatenates all available versions to a single slice
 		versions = append(versions, ver)
 	}
+	if len(versions) == 0 {
+		return nil
+	}
 
 	// sort and take the last element (the latest version)
 	sort.Ints(versions)
+	for i := len(versions) - 1; i >= 0; i-- {
+		if !mts[versions[i]].Deprecated() {
+			return mts[versions[i]]
+		}
+	}
 	latestVersion := versions[len(versions)-1]
 
 	return mts[latestVersion]
@@ -382,11 +535,14 @@ func getVersion(mts map[int]*metricType, ver int) (*metricType, error) {
 	}
 	if ver > 0 {
 		// a version IS given
-		if mt, exist := mts[ver]; exist {
+		if mt, exist := mts[ver]; exist && mt != nil && mt.Plugin != nil {
 			return mt, nil
 		}
 		return nil, errMetricNotFound
 	}
 	// or get the latest
-	return getLatest(mts), nil
+	if mt := getLatest(mts); mt != nil {
+		return mt, nil
+	}
+	return nil, errMetricNotFound
 }
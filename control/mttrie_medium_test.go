@@ -102,6 +102,28 @@ func TestTrie(t *testing.T) {
 			So(err, ShouldBeNil)
 			So(len(n), ShouldEqual, 2)
 		})
+		Convey("Fetch with a wildcard in the middle of namespace", func() {
+			mt := newMetricType(core.NewNamespace("intel", "psutil", "cpu0", "load1"), time.Now(), new(loadedPlugin))
+			mt2 := newMetricType(core.NewNamespace("intel", "psutil", "cpu1", "load1"), time.Now(), new(loadedPlugin))
+			mt3 := newMetricType(core.NewNamespace("intel", "psutil", "cpu1", "load5"), time.Now(), new(loadedPlugin))
+			trie.Add(mt)
+			trie.Add(mt2)
+			trie.Add(mt3)
+
+			in, err := trie.Fetch([]string{"intel", "psutil", "*", "load1"})
+			So(err, ShouldBeNil)
+			So(len(in), ShouldEqual, 2)
+			So(in, ShouldContain, mt)
+			So(in, ShouldContain, mt2)
+
+			all, err := trie.Fetch([]string{"intel", "*"})
+			So(err, ShouldBeNil)
+			So(len(all), ShouldEqual, 3)
+
+			_, err = trie.Fetch([]string{"intel", "psutil", "*", "load15"})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "No metric found below the given namespace: /intel/psutil/*/load15")
+		})
 		Convey("Fetch with error: not found", func() {
 			_, err := trie.Fetch([]string{"not", "present"})
 			So(err, ShouldNotBeNil)
@@ -0,0 +1,94 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+const (
+	// CompressionSnappy is the snappy compression of the gRPC messages, in
+	// its block format
+	CompressionSnappy = "snappy"
+	// CompressionGzip is the gzip compression of the gRPC messages
+	CompressionGzip = "gzip"
+)
+
+// Capabilities are the optional features of the plugin protocol supported by
+// a plugin, advertised in the meta of its handshake response, or by snapteld,
+// passed in the arguments of the plugins.  Snapteld calls the plugins which
+// don't advertise their capabilities the way it always did, new features are
+// only used with the plugins advertising them.
+type Capabilities struct {
+	// Compression lists the compressions of the messages accepted
+	Compression []string
+	// MaxBatch is the maximum number of metrics of a call, 0 for no limit
+	MaxBatch int
+	// MaxPayload is the maximum size in bytes of the messages of a call, 0
+	// for no limit
+	MaxPayload int
+	// ConfigReload is whether the config can be pushed to the running plugin
+	ConfigReload bool
+	// StreamBackpressure is whether a streaming collector pauses and resumes
+	// sending metrics when asked to
+	StreamBackpressure bool
+}
+
+// SnapteldCapabilities are the capabilities of snapteld passed to the plugins
+var SnapteldCapabilities = Capabilities{
+	Compression:        []string{CompressionSnappy, CompressionGzip},
+	ConfigReload:       true,
+	StreamBackpressure: true,
+}
+
+// SupportsCompression returns whether the compression is accepted, never for
+// the plugins which don't advertise their capabilities
+func (c *Capabilities) SupportsCompression(compression string) bool {
+	if c == nil {
+		return false
+	}
+	for _, s := range c.Compression {
+		if s == compression {
+			return true
+		}
+	}
+	return false
+}
+
+// NegotiateCompression returns the first compression of snapteld, in order of
+// preference, which is accepted, "" when none is
+func (c *Capabilities) NegotiateCompression() string {
+	for _, s := range SnapteldCapabilities.Compression {
+		if c.SupportsCompression(s) {
+			return s
+		}
+	}
+	return ""
+}
+
+// SupportsConfigReload returns whether the config can be pushed to the
+// running plugin, assumed for the plugins which don't advertise their
+// capabilities
+func (c *Capabilities) SupportsConfigReload() bool {
+	return c == nil || c.ConfigReload
+}
+
+// SupportsStreamBackpressure returns whether a streaming collector can be asked
+// to pause and resume, never for the plugins which don't advertise their
+// capabilities
+func (c *Capabilities) SupportsStreamBackpressure() bool {
+	return c != nil && c.StreamBackpressure
+}
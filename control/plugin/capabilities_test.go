@@ -0,0 +1,58 @@
+// +build legacy
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCapabilities(t *testing.T) {
+	Convey("Capabilities", t, func() {
+		Convey("of the plugins which don't advertise them", func() {
+			var c *Capabilities
+			So(c.SupportsCompression(CompressionGzip), ShouldBeFalse)
+			So(c.NegotiateCompression(), ShouldEqual, "")
+			So(c.SupportsConfigReload(), ShouldBeTrue)
+			So(c.SupportsStreamBackpressure(), ShouldBeFalse)
+		})
+		Convey("advertised by the plugins", func() {
+			c := &Capabilities{Compression: []string{CompressionGzip}, StreamBackpressure: true}
+			So(c.SupportsCompression(CompressionGzip), ShouldBeTrue)
+			So(c.SupportsCompression(CompressionSnappy), ShouldBeFalse)
+			So(c.NegotiateCompression(), ShouldEqual, CompressionGzip)
+			So(c.SupportsConfigReload(), ShouldBeFalse)
+			So(c.SupportsStreamBackpressure(), ShouldBeTrue)
+		})
+		Convey("prefer snappy compression", func() {
+			c := &Capabilities{Compression: []string{CompressionGzip, CompressionSnappy}}
+			So(c.NegotiateCompression(), ShouldEqual, CompressionSnappy)
+			c = &Capabilities{Compression: []string{"lz4"}}
+			So(c.NegotiateCompression(), ShouldEqual, "")
+		})
+		Convey("of snapteld are passed to the plugins", func() {
+			arg := NewArg(0, false)
+			So(arg.Capabilities, ShouldResemble, &SnapteldCapabilities)
+		})
+	})
+}
@@ -0,0 +1,81 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"github.com/golang/protobuf/proto"
+
+	"github.com/intelsdi-x/snap/control/plugin/rpc"
+	"github.com/intelsdi-x/snap/core"
+)
+
+// batches splits the metrics of a call into the batches the plugin accepts,
+// sizing the messages of the batches with size
+func (g *grpcClient) batches(mts []core.Metric, size func([]core.Metric) int) [][]core.Metric {
+	if g.capabilities == nil {
+		return [][]core.Metric{mts}
+	}
+	return splitBatches(mts, g.capabilities.MaxBatch, g.capabilities.MaxPayload, size)
+}
+
+// splitBatches splits the metrics into batches of at most maxBatch metrics
+// whose messages are at most maxPayload bytes, a batch of a single metric is
+// sent whatever its size.  There's no limit when the max is 0.
+func splitBatches(mts []core.Metric, maxBatch, maxPayload int, size func([]core.Metric) int) [][]core.Metric {
+	if len(mts) == 0 || (maxBatch <= 0 && maxPayload <= 0) {
+		return [][]core.Metric{mts}
+	}
+	var batches [][]core.Metric
+	for len(mts) > 0 {
+		n := len(mts)
+		if maxBatch > 0 && n > maxBatch {
+			n = maxBatch
+		}
+		if maxPayload > 0 && n > 1 && size(mts[:n]) > maxPayload {
+			// search the largest batch fitting in the max payload
+			lo, hi := 1, n-1
+			for lo < hi {
+				mid := (lo + hi + 1) / 2
+				if size(mts[:mid]) <= maxPayload {
+					lo = mid
+				} else {
+					hi = mid - 1
+				}
+			}
+			n = lo
+		}
+		batches = append(batches, mts[:n])
+		mts = mts[n:]
+	}
+	return batches
+}
+
+// metricsArgSize returns the size of the message of a collect call
+func metricsArgSize(mts []core.Metric) int {
+	return proto.Size(&rpc.MetricsArg{Metrics: NewMetrics(mts)})
+}
+
+// pubProcArgSize returns a function sizing the message of a process or
+// publish call with the given config
+func pubProcArgSize(cfg *rpc.ConfigMap) func([]core.Metric) int {
+	return func(mts []core.Metric) int {
+		return proto.Size(&rpc.PubProcArg{Metrics: NewMetrics(mts), Config: cfg})
+	}
+}
@@ -0,0 +1,61 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	"github.com/intelsdi-x/snap/core"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSplitBatches(t *testing.T) {
+	Convey("splitBatches", t, func() {
+		mts := make([]core.Metric, 10)
+		// every metric takes 10 bytes
+		size := func(mts []core.Metric) int {
+			return 10 * len(mts)
+		}
+		lengths := func(batches [][]core.Metric) []int {
+			var ls []int
+			for _, b := range batches {
+				ls = append(ls, len(b))
+			}
+			return ls
+		}
+
+		Convey("keeps the metrics in a single call without limits", func() {
+			So(lengths(splitBatches(mts, 0, 0, size)), ShouldResemble, []int{10})
+			So(lengths(splitBatches(nil, 3, 0, size)), ShouldResemble, []int{0})
+		})
+		Convey("splits the metrics into batches of at most the max batch", func() {
+			So(lengths(splitBatches(mts, 4, 0, size)), ShouldResemble, []int{4, 4, 2})
+		})
+		Convey("splits the batches larger than the max payload", func() {
+			So(lengths(splitBatches(mts, 0, 45, size)), ShouldResemble, []int{4, 4, 2})
+			So(lengths(splitBatches(mts, 4, 35, size)), ShouldResemble, []int{3, 3, 3, 1})
+		})
+		Convey("sends a single metric whatever its size", func() {
+			So(lengths(splitBatches(mts[:3], 0, 5, size)), ShouldResemble, []int{1, 1, 1})
+		})
+	})
+}
@@ -66,3 +66,14 @@ type PluginPublisherClient interface {
 	PluginClient
 	Publish([]core.Metric, map[string]ctypes.ConfigValue) error
 }
+
+// PluginLoadReporter A client keeping the load reported by the plugin in its last reply to Ping.
+type PluginLoadReporter interface {
+	Load() plugin.Load
+}
+
+// PluginConfigClient A client able to update the config of a running plugin.
+type PluginConfigClient interface {
+	PluginClient
+	SetConfig(map[string]ctypes.ConfigValue) error
+}
@@ -0,0 +1,56 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"io"
+	"io/ioutil"
+
+	"github.com/golang/snappy"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+)
+
+// snappyCompressor compresses the gRPC messages sent to the plugins
+type snappyCompressor struct{}
+
+func (snappyCompressor) Do(w io.Writer, p []byte) error {
+	_, err := w.Write(snappy.Encode(nil, p))
+	return err
+}
+
+func (snappyCompressor) Type() string {
+	return plugin.CompressionSnappy
+}
+
+// snappyDecompressor decompresses the gRPC messages received from the plugins
+type snappyDecompressor struct{}
+
+func (snappyDecompressor) Do(r io.Reader) ([]byte, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return snappy.Decode(nil, b)
+}
+
+func (snappyDecompressor) Type() string {
+	return plugin.CompressionSnappy
+}
@@ -0,0 +1,44 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSnappyCompression(t *testing.T) {
+	Convey("A message compressed with snappy", t, func() {
+		msg := bytes.Repeat([]byte("/intel/mock/foo"), 1000)
+		var buf bytes.Buffer
+		err := snappyCompressor{}.Do(&buf, msg)
+		So(err, ShouldBeNil)
+		So(buf.Len(), ShouldBeLessThan, len(msg))
+		Convey("is decompressed", func() {
+			out, err := snappyDecompressor{}.Do(&buf)
+			So(err, ShouldBeNil)
+			So(out, ShouldResemble, msg)
+		})
+	})
+}
@@ -30,11 +30,13 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	log "github.com/Sirupsen/logrus"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 
 	"github.com/intelsdi-x/snap/control/plugin"
@@ -50,6 +52,9 @@ import (
 // SecureSide identifies security mode to apply in securing gRPC
 type SecureSide int
 
+// ErrSetConfigUnsupported - error message when a plugin doesn't implement the SetConfig rpc
+var ErrSetConfigUnsupported = errors.New("plugin does not support updating its config while running")
+
 // Define secuity modes available to apply to gRPC.
 const (
 	SecureClient = SecureSide(iota)
@@ -61,6 +66,7 @@ type pluginClient interface {
 	Ping(ctx context.Context, in *rpc.Empty, opts ...grpc.CallOption) (*rpc.ErrReply, error)
 	Kill(ctx context.Context, in *rpc.KillArg, opts ...grpc.CallOption) (*rpc.ErrReply, error)
 	GetConfigPolicy(ctx context.Context, in *rpc.Empty, opts ...grpc.CallOption) (*rpc.GetConfigPolicyReply, error)
+	SetConfig(ctx context.Context, in *rpc.ConfigMap, opts ...grpc.CallOption) (*rpc.ErrReply, error)
 }
 
 type grpcClient struct {
@@ -74,11 +80,19 @@ type grpcClient struct {
 	killChan chan struct{}
 	// stream connection to stream collector
 	stream rpc.StreamCollector_StreamMetricsClient
+	// serializes the requests sent on the stream
+	streamMutex sync.Mutex
 
 	pluginType plugin.PluginType
 	timeout    time.Duration
 	conn       *grpc.ClientConn
 	encrypter  *encrypter.Encrypter
+	// the capabilities advertised by the plugin, nil when it doesn't
+	// advertise them
+	capabilities *plugin.Capabilities
+	// the load reported by the plugin in its last reply to Ping
+	load      plugin.Load
+	loadMutex sync.Mutex
 }
 
 // GRPCSecurity contains data necessary to setup secure gRPC communication
@@ -122,8 +136,8 @@ func SecurityTLSOff() GRPCSecurity {
 }
 
 // NewCollectorGrpcClient returns a collector gRPC Client.
-func NewCollectorGrpcClient(address string, timeout time.Duration, security GRPCSecurity) (PluginCollectorClient, error) {
-	p, err := newPluginGrpcClient(address, timeout, security, plugin.CollectorPluginType)
+func NewCollectorGrpcClient(address string, timeout time.Duration, security GRPCSecurity, capabilities *plugin.Capabilities) (PluginCollectorClient, error) {
+	p, err := newPluginGrpcClient(address, timeout, security, plugin.CollectorPluginType, capabilities)
 	if err != nil {
 		return nil, err
 	}
@@ -131,8 +145,8 @@ func NewCollectorGrpcClient(address string, timeout time.Duration, security GRPC
 }
 
 // NewStreamCollectorGrpcClient returns a stream collector gRPC client
-func NewStreamCollectorGrpcClient(address string, timeout time.Duration, security GRPCSecurity) (PluginStreamCollectorClient, error) {
-	p, err := newPluginGrpcClient(address, timeout, security, plugin.StreamCollectorPluginType)
+func NewStreamCollectorGrpcClient(address string, timeout time.Duration, security GRPCSecurity, capabilities *plugin.Capabilities) (PluginStreamCollectorClient, error) {
+	p, err := newPluginGrpcClient(address, timeout, security, plugin.StreamCollectorPluginType, capabilities)
 	if err != nil {
 		return nil, err
 	}
@@ -140,8 +154,8 @@ func NewStreamCollectorGrpcClient(address string, timeout time.Duration, securit
 }
 
 // NewProcessorGrpcClient returns a processor gRPC Client.
-func NewProcessorGrpcClient(address string, timeout time.Duration, security GRPCSecurity) (PluginProcessorClient, error) {
-	p, err := newPluginGrpcClient(address, timeout, security, plugin.ProcessorPluginType)
+func NewProcessorGrpcClient(address string, timeout time.Duration, security GRPCSecurity, capabilities *plugin.Capabilities) (PluginProcessorClient, error) {
+	p, err := newPluginGrpcClient(address, timeout, security, plugin.ProcessorPluginType, capabilities)
 	if err != nil {
 		return nil, err
 	}
@@ -149,8 +163,8 @@ func NewProcessorGrpcClient(address string, timeout time.Duration, security GRPC
 }
 
 // NewPublisherGrpcClient returns a publisher gRPC Client.
-func NewPublisherGrpcClient(address string, timeout time.Duration, security GRPCSecurity) (PluginPublisherClient, error) {
-	p, err := newPluginGrpcClient(address, timeout, security, plugin.PublisherPluginType)
+func NewPublisherGrpcClient(address string, timeout time.Duration, security GRPCSecurity, capabilities *plugin.Capabilities) (PluginPublisherClient, error) {
+	p, err := newPluginGrpcClient(address, timeout, security, plugin.PublisherPluginType, capabilities)
 	if err != nil {
 		return nil, err
 	}
@@ -249,17 +263,13 @@ func buildCredentials(security GRPCSecurity) (creds credentials.TransportCredent
 }
 
 // newPluginGrpcClient returns a configured gRPC Client.
-func newPluginGrpcClient(address string, timeout time.Duration, security GRPCSecurity, typ plugin.PluginType) (interface{}, error) {
-	address, port, err := parseAddress(address)
-	if err != nil {
-		return nil, err
-	}
+func newPluginGrpcClient(address string, timeout time.Duration, security GRPCSecurity, typ plugin.PluginType, capabilities *plugin.Capabilities) (interface{}, error) {
 	var p *grpcClient
-	var creds credentials.TransportCredentials
-	if creds, err = buildCredentials(security); err != nil {
+	creds, err := buildCredentials(security)
+	if err != nil {
 		return nil, err
 	}
-	p, err = newGrpcClient(address, int(port), timeout, typ, creds)
+	p, err = newGrpcClient(address, timeout, typ, creds, capabilities)
 	if err != nil {
 		return nil, err
 	}
@@ -279,15 +289,35 @@ func parseAddress(address string) (string, int64, error) {
 	return address, port, nil
 }
 
-func newGrpcClient(addr string, port int, timeout time.Duration, typ plugin.PluginType, creds credentials.TransportCredentials) (*grpcClient, error) {
+// newGrpcClient connects to the plugin listening on the address, a host and a
+// port or the path of a named pipe on Windows
+func newGrpcClient(address string, timeout time.Duration, typ plugin.PluginType, creds credentials.TransportCredentials, capabilities *plugin.Capabilities) (*grpcClient, error) {
 	var conn *grpc.ClientConn
 	var err error
-	if conn, err = rpcutil.GetClientConnectionWithCreds(addr, port, creds); err != nil {
+	var opts []grpc.DialOption
+	switch capabilities.NegotiateCompression() {
+	case plugin.CompressionSnappy:
+		opts = append(opts, grpc.WithCompressor(snappyCompressor{}), grpc.WithDecompressor(snappyDecompressor{}))
+	case plugin.CompressionGzip:
+		opts = append(opts, grpc.WithCompressor(grpc.NewGZIPCompressor()), grpc.WithDecompressor(grpc.NewGZIPDecompressor()))
+	}
+	if rpcutil.IsPipeAddress(address) {
+		conn, err = rpcutil.GetPipeClientConnectionWithCreds(address, creds, opts...)
+	} else {
+		var addr string
+		var port int64
+		if addr, port, err = parseAddress(address); err != nil {
+			return nil, err
+		}
+		conn, err = rpcutil.GetClientConnectionWithCreds(addr, int(port), creds, opts...)
+	}
+	if err != nil {
 		return nil, err
 	}
 	p := &grpcClient{
-		timeout: timeout,
-		conn:    conn,
+		timeout:      timeout,
+		conn:         conn,
+		capabilities: capabilities,
 	}
 
 	switch typ {
@@ -317,13 +347,27 @@ func getContext(timeout time.Duration) context.Context {
 }
 
 func (g *grpcClient) Ping() error {
-	_, err := g.plugin.Ping(getContext(g.timeout), &rpc.Empty{})
+	reply, err := g.plugin.Ping(getContext(g.timeout), &rpc.Empty{})
 	if err != nil {
 		return err
 	}
+	g.loadMutex.Lock()
+	g.load = plugin.Load{
+		InFlight:    reply.InFlight,
+		LastLatency: time.Duration(reply.LastLatency),
+		Memory:      reply.Memory,
+	}
+	g.loadMutex.Unlock()
 	return nil
 }
 
+// Load returns the load reported by the plugin in its last reply to Ping
+func (g *grpcClient) Load() plugin.Load {
+	g.loadMutex.Lock()
+	defer g.loadMutex.Unlock()
+	return g.load
+}
+
 func (g *grpcClient) SetKey() error {
 	// Added to conform to interface but not needed by grpc
 	return nil
@@ -354,34 +398,41 @@ func (g *grpcClient) Close() error {
 }
 
 func (g *grpcClient) Publish(metrics []core.Metric, config map[string]ctypes.ConfigValue) error {
-	arg := &rpc.PubProcArg{
-		Metrics: NewMetrics(metrics),
-		Config:  ToConfigMap(config),
-	}
-	reply, err := g.publisher.Publish(getContext(g.timeout), arg)
-	if err != nil {
-		return err
-	}
-	if reply.Error != "" {
-		return errors.New(reply.Error)
+	cfg := ToConfigMap(config)
+	for _, batch := range g.batches(metrics, pubProcArgSize(cfg)) {
+		arg := &rpc.PubProcArg{
+			Metrics: NewMetrics(batch),
+			Config:  cfg,
+		}
+		reply, err := g.publisher.Publish(getContext(g.timeout), arg)
+		if err != nil {
+			return err
+		}
+		if reply.Error != "" {
+			return errors.New(reply.Error)
+		}
 	}
 	return nil
 }
 
 func (g *grpcClient) Process(metrics []core.Metric, config map[string]ctypes.ConfigValue) ([]core.Metric, error) {
-	arg := &rpc.PubProcArg{
-		Metrics: NewMetrics(metrics),
-		Config:  ToConfigMap(config),
-	}
-	reply, err := g.processor.Process(getContext(g.timeout), arg)
+	cfg := ToConfigMap(config)
+	var mts []core.Metric
+	for _, batch := range g.batches(metrics, pubProcArgSize(cfg)) {
+		arg := &rpc.PubProcArg{
+			Metrics: NewMetrics(batch),
+			Config:  cfg,
+		}
+		reply, err := g.processor.Process(getContext(g.timeout), arg)
 
-	if err != nil {
-		return nil, err
-	}
-	if reply.Error != "" {
-		return nil, errors.New(reply.Error)
+		if err != nil {
+			return nil, err
+		}
+		if reply.Error != "" {
+			return nil, errors.New(reply.Error)
+		}
+		mts = append(mts, ToCoreMetrics(reply.Metrics)...)
 	}
-	mts := ToCoreMetrics(reply.Metrics)
 	for _, mt := range mts {
 		log.Debug(mt.Namespace())
 	}
@@ -389,20 +440,23 @@ func (g *grpcClient) Process(metrics []core.Metric, config map[string]ctypes.Con
 }
 
 func (g *grpcClient) CollectMetrics(mts []core.Metric) ([]core.Metric, error) {
-	arg := &rpc.MetricsArg{
-		Metrics: NewMetrics(mts),
-	}
-	reply, err := g.collector.CollectMetrics(getContext(g.timeout), arg)
+	var metrics []core.Metric
+	for _, batch := range g.batches(mts, metricsArgSize) {
+		arg := &rpc.MetricsArg{
+			Metrics: NewMetrics(batch),
+		}
+		reply, err := g.collector.CollectMetrics(getContext(g.timeout), arg)
 
-	if err != nil {
-		return nil, err
-	}
+		if err != nil {
+			return nil, err
+		}
 
-	if reply.Error != "" {
-		return nil, errors.New(reply.Error)
-	}
+		if reply.Error != "" {
+			return nil, errors.New(reply.Error)
+		}
 
-	metrics := ToCoreMetrics(reply.Metrics)
+		metrics = append(metrics, ToCoreMetrics(reply.Metrics)...)
+	}
 	return metrics, nil
 }
 
@@ -411,7 +465,7 @@ func (g *grpcClient) UpdateCollectedMetrics(mts []core.Metric) error {
 		arg := &rpc.CollectArg{
 			Metrics_Arg: &rpc.MetricsArg{Metrics: NewMetrics(mts)},
 		}
-		err := g.stream.Send(arg)
+		err := g.send(arg)
 		if err != nil {
 			return err
 		}
@@ -423,7 +477,7 @@ func (g *grpcClient) UpdatePluginConfig(bytes []byte) error {
 		arg := &rpc.CollectArg{
 			Other: bytes,
 		}
-		err := g.stream.Send(arg)
+		err := g.send(arg)
 		if err != nil {
 			return err
 		}
@@ -435,7 +489,7 @@ func (g *grpcClient) UpdateCollectDuration(maxCollectDuration time.Duration) err
 		arg := &rpc.CollectArg{
 			MaxCollectDuration: maxCollectDuration.Nanoseconds(),
 		}
-		err := g.stream.Send(arg)
+		err := g.send(arg)
 		if err != nil {
 			return err
 		}
@@ -447,7 +501,7 @@ func (g *grpcClient) UpdateMetricsBuffer(maxMetricsBuffer int64) error {
 		arg := &rpc.CollectArg{
 			MaxMetricsBuffer: maxMetricsBuffer,
 		}
-		err := g.stream.Send(arg)
+		err := g.send(arg)
 		if err != nil {
 			return err
 		}
@@ -455,6 +509,28 @@ func (g *grpcClient) UpdateMetricsBuffer(maxMetricsBuffer int64) error {
 	return nil
 }
 
+// send sends a request on the stream, the stream doesn't support concurrent
+// sends
+func (g *grpcClient) send(arg *rpc.CollectArg) error {
+	g.streamMutex.Lock()
+	defer g.streamMutex.Unlock()
+	return g.stream.Send(arg)
+}
+
+// signalBackpressure asks the plugin to pause or resume streaming metrics,
+// the plugins which don't support it are only slowed down by the flow control
+// of the stream
+func (g *grpcClient) signalBackpressure(pause bool) error {
+	if !g.capabilities.SupportsStreamBackpressure() {
+		return nil
+	}
+	log.WithFields(log.Fields{
+		"_block": "signal-backpressure",
+		"pause":  pause,
+	}).Debug("signalling backpressure to the stream collector")
+	return g.send(&rpc.CollectArg{Pause: pause, Resume: !pause})
+}
+
 func (g *grpcClient) StreamMetrics(mts []core.Metric) (chan []core.Metric, chan error, error) {
 	arg := &rpc.CollectArg{
 		Metrics_Arg: &rpc.MetricsArg{Metrics: NewMetrics(mts)},
@@ -534,6 +610,10 @@ func (g *grpcClient) StreamMetrics(mts []core.Metric) (chan []core.Metric, chan
 func (g *grpcClient) handleInStream(
 	metricChan chan []core.Metric,
 	errChan chan error) {
+	// the metrics are queued so the plugin can be asked to pause before
+	// snapd stops reading the stream
+	buffer := newStreamBuffer(StreamBufferSize, g.signalBackpressure)
+	go buffer.forward(metricChan, errChan, g.killChan)
 	go func() {
 		for {
 			in, err := g.stream.Recv()
@@ -547,7 +627,9 @@ func (g *grpcClient) handleInStream(
 					// skip empty metrics
 					continue
 				}
-				metricChan <- mts
+				if err := buffer.push(mts, g.killChan); err != nil {
+					errChan <- err
+				}
 			} else if in.Error != nil {
 				e := errors.New(in.Error.Error)
 				errChan <- e
@@ -597,6 +679,26 @@ func (g *grpcClient) GetConfigPolicy() (*cpolicy.ConfigPolicy, error) {
 	return rpc.ToConfigPolicy(reply), nil
 }
 
+// SetConfig pushes the given global config to the running plugin, plugins
+// built before the rpc was added or advertising they don't support it return
+// ErrSetConfigUnsupported
+func (g *grpcClient) SetConfig(config map[string]ctypes.ConfigValue) error {
+	if !g.capabilities.SupportsConfigReload() {
+		return ErrSetConfigUnsupported
+	}
+	reply, err := g.plugin.SetConfig(getContext(g.timeout), ToConfigMap(config))
+	if err != nil {
+		if grpc.Code(err) == codes.Unimplemented {
+			return ErrSetConfigUnsupported
+		}
+		return err
+	}
+	if reply.Error != "" {
+		return errors.New(reply.Error)
+	}
+	return nil
+}
+
 type metric struct {
 	namespace          core.Namespace
 	version            int
@@ -607,6 +709,10 @@ type metric struct {
 	tags               map[string]string
 	description        string
 	unit               string
+	dataType           string
+	// msg is the message the metric was received in, sent as is to the next
+	// plugins since the metric isn't modified
+	msg *rpc.Metric
 }
 
 func (m *metric) Namespace() core.Namespace     { return m.namespace }
@@ -618,6 +724,7 @@ func (m *metric) LastAdvertisedTime() time.Time { return m.lastAdvertisedTime }
 func (m *metric) Timestamp() time.Time          { return m.timeStamp }
 func (m *metric) Description() string           { return m.description }
 func (m *metric) Unit() string                  { return m.unit }
+func (m *metric) DataType() string              { return m.dataType }
 
 func ToCoreMetrics(mts []*rpc.Metric) []core.Metric {
 	metrics := make([]core.Metric, len(mts))
@@ -645,6 +752,8 @@ func ToCoreMetric(mt *rpc.Metric) core.Metric {
 		config:             ConfigMapToConfig(mt.Config),
 		description:        mt.Description,
 		unit:               mt.Unit,
+		dataType:           mt.DataType,
+		msg:                mt,
 	}
 
 	switch mt.Data.(type) {
@@ -678,7 +787,21 @@ func NewMetrics(ms []core.Metric) []*rpc.Metric {
 	return metrics
 }
 
+// ToMetric returns the message of the metric, the one it was received in or
+// the one of its shared batch when it has one
 func ToMetric(co core.Metric) *rpc.Metric {
+	switch m := co.(type) {
+	case *metric:
+		if m.msg != nil {
+			return m.msg
+		}
+	case *sharedMetric:
+		return m.message()
+	}
+	return newMetricMessage(co)
+}
+
+func newMetricMessage(co core.Metric) *rpc.Metric {
 	cm := &rpc.Metric{
 		Namespace: ToNamespace(co.Namespace()),
 		Version:   int64(co.Version()),
@@ -691,7 +814,9 @@ func ToMetric(co core.Metric) *rpc.Metric {
 			Sec:  co.LastAdvertisedTime().Unix(),
 			Nsec: int64(co.Timestamp().Nanosecond()),
 		},
-		Unit: co.Unit(),
+		Unit:        co.Unit(),
+		Description: co.Description(),
+		DataType:    co.DataType(),
 	}
 	if co.Config() != nil {
 		cm.Config = ConfigToConfigMap(co.Config())
@@ -763,6 +888,9 @@ func ToConfigMap(cv map[string]ctypes.ConfigValue) *rpc.ConfigMap {
 			newConfig.StringMap[k] = v.(ctypes.ConfigValueStr).Value
 		case "bool":
 			newConfig.BoolMap[k] = v.(ctypes.ConfigValueBool).Value
+		case "duration", "bytesize":
+			// sent in their string form, e.g. "30s" or "512MB"
+			newConfig.StringMap[k] = v.(fmt.Stringer).String()
 		}
 	}
 	return newConfig
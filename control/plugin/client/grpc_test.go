@@ -38,6 +38,9 @@ func TestToCoreMetric(t *testing.T) {
 				cmt := ToCoreMetric(mt)
 				So(cmt.Timestamp(), ShouldNotBeNil)
 				So(cmt.LastAdvertisedTime(), ShouldNotBeNil)
+				So(cmt.Description(), ShouldEqual, c.Description())
+				So(cmt.Unit(), ShouldEqual, c.Unit())
+				So(cmt.DataType(), ShouldEqual, c.DataType())
 
 				if cmt.Version() == 2 {
 					So(cmt.Timestamp(), ShouldResemble, cmt.LastAdvertisedTime())
@@ -74,6 +77,13 @@ func testCases() []*metric {
 			lastAdvertisedTime: now,
 			description:        "Has both timestamp and lastAdvertisedTime defined",
 		},
+		&metric{
+			namespace:   core.NewNamespace("x", "y", "z"),
+			version:     1,
+			unit:        "B",
+			dataType:    "uint64",
+			description: "Has unit and data type defined",
+		},
 	}
 	return tc
 }
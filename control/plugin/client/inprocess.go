@@ -0,0 +1,170 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/ctypes"
+)
+
+// InProcessClient calls a plugin compiled into snapteld directly, without
+// running it in a subprocess nor going through RPC.
+type InProcessClient struct {
+	plugin     plugin.Plugin
+	pluginType plugin.PluginType
+	// contentType is the content type of the metrics passed to the plugin
+	contentType string
+}
+
+func NewCollectorInProcessClient(p plugin.CollectorPlugin, contentType string) PluginCollectorClient {
+	return &InProcessClient{plugin: p, pluginType: plugin.CollectorPluginType, contentType: contentType}
+}
+
+func NewProcessorInProcessClient(p plugin.ProcessorPlugin, contentType string) PluginProcessorClient {
+	return &InProcessClient{plugin: p, pluginType: plugin.ProcessorPluginType, contentType: contentType}
+}
+
+func NewPublisherInProcessClient(p plugin.PublisherPlugin, contentType string) PluginPublisherClient {
+	return &InProcessClient{plugin: p, pluginType: plugin.PublisherPluginType, contentType: contentType}
+}
+
+// Ping always succeeds, the plugin runs as long as snapteld does
+func (p *InProcessClient) Ping() error {
+	return nil
+}
+
+// SetKey does nothing, the calls don't leave the process
+func (p *InProcessClient) SetKey() error {
+	return nil
+}
+
+// Kill does nothing, the plugin has no process of its own to stop
+func (p *InProcessClient) Kill(reason string) error {
+	return nil
+}
+
+func (p *InProcessClient) Close() error {
+	return nil
+}
+
+func (p *InProcessClient) GetConfigPolicy() (cp *cpolicy.ConfigPolicy, err error) {
+	defer recoverPluginPanic(&err)
+	return p.plugin.GetConfigPolicy()
+}
+
+func (p *InProcessClient) CollectMetrics(mts []core.Metric) (results []core.Metric, err error) {
+	defer recoverPluginPanic(&err)
+	c, ok := p.plugin.(plugin.CollectorPlugin)
+	if !ok {
+		return nil, errors.New("plugin is not a collector")
+	}
+	if len(mts) == 0 {
+		return nil, errors.New("no metrics to collect")
+	}
+	metricsToCollect := make([]plugin.MetricType, len(mts))
+	for idx, mt := range mts {
+		metricsToCollect[idx] = plugin.MetricType{
+			Namespace_:          mt.Namespace(),
+			LastAdvertisedTime_: mt.LastAdvertisedTime(),
+			Version_:            mt.Version(),
+			Tags_:               mt.Tags(),
+			Config_:             mt.Config(),
+			Unit_:               mt.Unit(),
+		}
+	}
+	collected, err := c.CollectMetrics(metricsToCollect)
+	if err != nil {
+		return nil, err
+	}
+	results = make([]core.Metric, len(collected))
+	for i, mt := range collected {
+		results[i] = mt
+	}
+	return results, nil
+}
+
+func (p *InProcessClient) GetMetricTypes(config plugin.ConfigType) (retMetricTypes []core.Metric, err error) {
+	defer recoverPluginPanic(&err)
+	c, ok := p.plugin.(plugin.CollectorPlugin)
+	if !ok {
+		return nil, errors.New("plugin is not a collector")
+	}
+	mts, err := c.GetMetricTypes(config)
+	if err != nil {
+		return nil, err
+	}
+	retMetricTypes = make([]core.Metric, len(mts))
+	for i, mt := range mts {
+		// Set the advertised time
+		mt.LastAdvertisedTime_ = time.Now()
+		retMetricTypes[i] = mt
+	}
+	return retMetricTypes, nil
+}
+
+func (p *InProcessClient) Process(metrics []core.Metric, config map[string]ctypes.ConfigValue) (mts []core.Metric, err error) {
+	defer recoverPluginPanic(&err)
+	c, ok := p.plugin.(plugin.ProcessorPlugin)
+	if !ok {
+		return nil, errors.New("plugin is not a processor")
+	}
+	content, err := encodeMetrics(p.contentType, metrics)
+	if err != nil {
+		return nil, err
+	}
+	contentType, content, err := c.Process(p.contentType, content, config)
+	if err != nil {
+		return nil, err
+	}
+	return decodeMetrics(contentType, content)
+}
+
+func (p *InProcessClient) Publish(metrics []core.Metric, config map[string]ctypes.ConfigValue) (err error) {
+	defer recoverPluginPanic(&err)
+	c, ok := p.plugin.(plugin.PublisherPlugin)
+	if !ok {
+		return errors.New("plugin is not a publisher")
+	}
+	content, err := encodeMetrics(p.contentType, metrics)
+	if err != nil {
+		return err
+	}
+	return c.Publish(p.contentType, content, config)
+}
+
+// GetType returns the string type of the plugin
+// Note: the first letter of the type will be capitalized.
+func (p *InProcessClient) GetType() string {
+	return upcaseInitial(p.pluginType.String())
+}
+
+// recoverPluginPanic turns a panic of the plugin into the error of the call,
+// keeping snapteld running
+func recoverPluginPanic(err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("plugin panicked: %v", r)
+	}
+}
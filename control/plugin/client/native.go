@@ -39,6 +39,7 @@ import (
 	"github.com/intelsdi-x/snap/core"
 	"github.com/intelsdi-x/snap/core/cdata"
 	"github.com/intelsdi-x/snap/core/ctypes"
+	"github.com/intelsdi-x/snap/pkg/rpcutil"
 )
 
 // CallsRPC provides an interface for RPC clients
@@ -50,21 +51,23 @@ type CallsRPC interface {
 type PluginNativeClient struct {
 	connection CallsRPC
 	pluginType plugin.PluginType
-	encoder    encoding.Encoder
-	encrypter  *encrypter.Encrypter
-	timeout    time.Duration
+	// contentType is the content type of the metrics sent to the plugin
+	contentType string
+	encoder     encoding.Encoder
+	encrypter   *encrypter.Encrypter
+	timeout     time.Duration
 }
 
-func NewCollectorNativeClient(address string, timeout time.Duration, pub *rsa.PublicKey, secure bool) (PluginCollectorClient, error) {
-	return newNativeClient(address, timeout, plugin.CollectorPluginType, pub, secure)
+func NewCollectorNativeClient(address string, timeout time.Duration, pub *rsa.PublicKey, secure bool, contentType string) (PluginCollectorClient, error) {
+	return newNativeClient(address, timeout, plugin.CollectorPluginType, pub, secure, contentType)
 }
 
-func NewPublisherNativeClient(address string, timeout time.Duration, pub *rsa.PublicKey, secure bool) (PluginPublisherClient, error) {
-	return newNativeClient(address, timeout, plugin.PublisherPluginType, pub, secure)
+func NewPublisherNativeClient(address string, timeout time.Duration, pub *rsa.PublicKey, secure bool, contentType string) (PluginPublisherClient, error) {
+	return newNativeClient(address, timeout, plugin.PublisherPluginType, pub, secure, contentType)
 }
 
-func NewProcessorNativeClient(address string, timeout time.Duration, pub *rsa.PublicKey, secure bool) (PluginProcessorClient, error) {
-	return newNativeClient(address, timeout, plugin.ProcessorPluginType, pub, secure)
+func NewProcessorNativeClient(address string, timeout time.Duration, pub *rsa.PublicKey, secure bool, contentType string) (PluginProcessorClient, error) {
+	return newNativeClient(address, timeout, plugin.ProcessorPluginType, pub, secure, contentType)
 }
 
 func (p *PluginNativeClient) Ping() error {
@@ -110,7 +113,7 @@ func checkTime(in time.Time) time.Time {
 	return in
 }
 
-func encodeMetrics(metrics []core.Metric) []byte {
+func toMetricTypes(metrics []core.Metric) []plugin.MetricType {
 	mts := make([]plugin.MetricType, len(metrics))
 	for i, m := range metrics {
 		mts[i] = plugin.MetricType{
@@ -122,19 +125,36 @@ func encodeMetrics(metrics []core.Metric) []byte {
 			LastAdvertisedTime_: checkTime(m.LastAdvertisedTime()),
 			Unit_:               m.Unit(),
 			Description_:        m.Description(),
+			DataType_:           m.DataType(),
 			Data_:               m.Data(),
 		}
 	}
-	var buf bytes.Buffer
-	enc := gob.NewEncoder(&buf)
-	enc.Encode(mts)
-	return buf.Bytes()
+	return mts
 }
 
-func decodeMetrics(bts []byte) ([]core.Metric, error) {
-	var mts []plugin.MetricType
-	dec := gob.NewDecoder(bytes.NewBuffer(bts))
-	if err := dec.Decode(&mts); err != nil {
+func encodeMetrics(contentType string, metrics []core.Metric) ([]byte, error) {
+	switch {
+	case contentType == plugin.SnapGOBContentType:
+		var buf bytes.Buffer
+		enc := gob.NewEncoder(&buf)
+		err := enc.Encode(toMetricTypes(metrics))
+		return buf.Bytes(), err
+	case len(metrics) == 0:
+		// an empty batch is an empty protobuf message
+		return nil, nil
+	}
+	content, _, err := plugin.MarshalMetricTypes(contentType, toMetricTypes(metrics))
+	return content, err
+}
+
+// decodeMetrics decodes the metrics replied by a plugin in the content type
+// of the reply, gob when the plugin doesn't give it
+func decodeMetrics(contentType string, bts []byte) ([]core.Metric, error) {
+	if contentType == "" {
+		contentType = plugin.SnapGOBContentType
+	}
+	mts, err := plugin.UnmarshallMetricTypes(contentType, bts)
+	if err != nil {
 		return nil, fmt.Errorf("Error decoding metrics: %v", err)
 	}
 	var cmetrics []core.Metric
@@ -156,10 +176,13 @@ func enforceTimeout(p *PluginNativeClient, dl time.Duration, done chan int) {
 }
 
 func (p *PluginNativeClient) Publish(metrics []core.Metric, config map[string]ctypes.ConfigValue) error {
-
+	content, err := encodeMetrics(p.contentType, metrics)
+	if err != nil {
+		return err
+	}
 	args := plugin.PublishArgs{
-		ContentType: plugin.SnapGOBContentType,
-		Content:     encodeMetrics(metrics),
+		ContentType: p.contentType,
+		Content:     content,
 		Config:      config,
 	}
 
@@ -176,10 +199,13 @@ func (p *PluginNativeClient) Publish(metrics []core.Metric, config map[string]ct
 }
 
 func (p *PluginNativeClient) Process(metrics []core.Metric, config map[string]ctypes.ConfigValue) ([]core.Metric, error) {
-
+	content, err := encodeMetrics(p.contentType, metrics)
+	if err != nil {
+		return nil, err
+	}
 	args := plugin.ProcessorArgs{
-		ContentType: plugin.SnapGOBContentType,
-		Content:     encodeMetrics(metrics),
+		ContentType: p.contentType,
+		Content:     content,
 		Config:      config,
 	}
 
@@ -202,7 +228,7 @@ func (p *PluginNativeClient) Process(metrics []core.Metric, config map[string]ct
 	if err != nil {
 		return nil, err
 	}
-	mts, err := decodeMetrics(r.Content)
+	mts, err := decodeMetrics(r.ContentType, r.Content)
 	if err != nil {
 		return nil, err
 	}
@@ -314,18 +340,25 @@ func (p *PluginNativeClient) GetType() string {
 	return upcaseInitial(p.pluginType.String())
 }
 
-func newNativeClient(address string, timeout time.Duration, t plugin.PluginType, pub *rsa.PublicKey, secure bool) (*PluginNativeClient, error) {
+func newNativeClient(address string, timeout time.Duration, t plugin.PluginType, pub *rsa.PublicKey, secure bool, contentType string) (*PluginNativeClient, error) {
 	// Attempt to dial address error on timeout or problem
-	conn, err := net.DialTimeout("tcp", address, timeout)
+	var conn net.Conn
+	var err error
+	if rpcutil.IsPipeAddress(address) {
+		conn, err = rpcutil.DialPipe(address, timeout)
+	} else {
+		conn, err = net.DialTimeout("tcp", address, timeout)
+	}
 	// Return nil RPCClient and err if encoutered
 	if err != nil {
 		return nil, err
 	}
 	r := rpc.NewClient(conn)
 	p := &PluginNativeClient{
-		connection: r,
-		pluginType: t,
-		timeout:    timeout,
+		connection:  r,
+		pluginType:  t,
+		contentType: contentType,
+		timeout:     timeout,
 	}
 
 	p.encoder = encoding.NewGobEncoder()
@@ -349,6 +382,8 @@ func init() {
 	gob.RegisterName("conf_value_int", *(&ctypes.ConfigValueInt{}))
 	gob.RegisterName("conf_value_float", *(&ctypes.ConfigValueFloat{}))
 	gob.RegisterName("conf_value_bool", *(&ctypes.ConfigValueBool{}))
+	gob.RegisterName("conf_value_duration", *(&ctypes.ConfigValueDuration{}))
+	gob.RegisterName("conf_value_bytesize", *(&ctypes.ConfigValueByteSize{}))
 
 	gob.RegisterName("conf_policy_node", cpolicy.NewPolicyNode())
 	gob.RegisterName("conf_data_node", &cdata.ConfigDataNode{})
@@ -356,6 +391,8 @@ func init() {
 	gob.RegisterName("conf_policy_int", &cpolicy.IntRule{})
 	gob.RegisterName("conf_policy_float", &cpolicy.FloatRule{})
 	gob.RegisterName("conf_policy_bool", &cpolicy.BoolRule{})
+	gob.RegisterName("conf_policy_duration", &cpolicy.DurationRule{})
+	gob.RegisterName("conf_policy_bytesize", &cpolicy.ByteSizeRule{})
 }
 
 func upcaseInitial(str string) string {
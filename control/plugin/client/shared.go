@@ -0,0 +1,67 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"sync"
+
+	"github.com/intelsdi-x/snap/control/plugin/rpc"
+	"github.com/intelsdi-x/snap/core"
+)
+
+// sharedMetric is a metric of a batch shared by the steps of a workflow, its
+// message is built by the first plugin it is sent to and reused for the others
+type sharedMetric struct {
+	core.Metric
+	once sync.Once
+	msg  *rpc.Metric
+}
+
+func (m *sharedMetric) message() *rpc.Metric {
+	m.once.Do(func() {
+		m.msg = newMetricMessage(m.Metric)
+	})
+	return m.msg
+}
+
+// ShareMetrics returns the metrics as a batch shared by the processors and
+// publishers of a workflow, the metrics mustn't be modified anymore.  Their
+// messages are built once for all the plugins they're sent to, the metrics
+// received from a plugin already have theirs.  The slice is copied only when
+// a metric has to be replaced, it may be read by other steps.
+func ShareMetrics(mts []core.Metric) []core.Metric {
+	shared := mts
+	for i, m := range mts {
+		switch m := m.(type) {
+		case *sharedMetric:
+			continue
+		case *metric:
+			if m.msg != nil {
+				continue
+			}
+		}
+		if &shared[0] == &mts[0] {
+			shared = make([]core.Metric, len(mts))
+			copy(shared, mts)
+		}
+		shared[i] = &sharedMetric{Metric: m}
+	}
+	return shared
+}
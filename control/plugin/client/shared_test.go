@@ -0,0 +1,70 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestShareMetrics(t *testing.T) {
+	Convey("Given collected metrics", t, func() {
+		mts := []core.Metric{
+			plugin.MetricType{Namespace_: core.NewNamespace("a", "b"), Timestamp_: time.Now(), Data_: 1},
+			ToCoreMetric(ToMetric(plugin.MetricType{Namespace_: core.NewNamespace("a", "c"), Data_: 2})),
+		}
+		Convey("the shared metrics are built into messages once", func() {
+			shared := ShareMetrics(mts)
+			So(shared, ShouldHaveLength, 2)
+			So(shared[0].Namespace(), ShouldResemble, mts[0].Namespace())
+			So(shared[1], ShouldEqual, mts[1])
+			var wg sync.WaitGroup
+			msgs := make([][]interface{}, 4)
+			for i := range msgs {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					msgs[i] = []interface{}{ToMetric(shared[0]), ToMetric(shared[1])}
+				}(i)
+			}
+			wg.Wait()
+			for _, m := range msgs[1:] {
+				So(m[0], ShouldEqual, msgs[0][0])
+				So(m[1], ShouldEqual, msgs[0][1])
+			}
+			So(msgs[0][0], ShouldResemble, ToMetric(mts[0]))
+			Convey("and aren't shared again", func() {
+				So(ShareMetrics(shared)[0], ShouldEqual, shared[0])
+			})
+		})
+		Convey("the slice of the metrics isn't modified", func() {
+			ShareMetrics(mts)
+			_, ok := mts[0].(plugin.MetricType)
+			So(ok, ShouldBeTrue)
+		})
+	})
+}
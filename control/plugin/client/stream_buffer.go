@@ -0,0 +1,113 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"sync"
+
+	"github.com/intelsdi-x/snap/core"
+)
+
+// StreamBufferSize is the number of batches of metrics streamed by a plugin
+// snapd queues before the workflow of the task takes them.  The plugin is
+// asked to pause sending when the queue is three quarters full and to resume
+// when it is drained to a quarter.
+var StreamBufferSize = 100
+
+// streamBuffer queues the metrics streamed by a plugin and signals the plugin
+// to pause or resume sending as the queue fills up and drains
+type streamBuffer struct {
+	queue  chan []core.Metric
+	high   int
+	low    int
+	signal func(pause bool) error
+
+	mutex  *sync.Mutex
+	paused bool
+}
+
+func newStreamBuffer(size int, signal func(pause bool) error) *streamBuffer {
+	if size < 4 {
+		size = 4
+	}
+	return &streamBuffer{
+		queue:  make(chan []core.Metric, size),
+		high:   size * 3 / 4,
+		low:    size / 4,
+		signal: signal,
+		mutex:  &sync.Mutex{},
+	}
+}
+
+// push queues the metrics, blocking while the queue is full until done is
+// closed
+func (b *streamBuffer) push(mts []core.Metric, done chan struct{}) error {
+	select {
+	case b.queue <- mts:
+	case <-done:
+		return nil
+	}
+	return b.update()
+}
+
+// forward sends the queued metrics to out until done is closed
+func (b *streamBuffer) forward(out chan []core.Metric, errs chan error, done chan struct{}) {
+	for {
+		select {
+		case mts := <-b.queue:
+			if err := b.update(); err != nil {
+				select {
+				case errs <- err:
+				case <-done:
+					return
+				}
+			}
+			select {
+			case out <- mts:
+			case <-done:
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// isPaused returns whether the plugin was asked to pause sending
+func (b *streamBuffer) isPaused() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.paused
+}
+
+func (b *streamBuffer) update() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	n := len(b.queue)
+	switch {
+	case !b.paused && n >= b.high:
+		b.paused = true
+		return b.signal(true)
+	case b.paused && n <= b.low:
+		b.paused = false
+		return b.signal(false)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	"github.com/intelsdi-x/snap/core"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestStreamBuffer(t *testing.T) {
+	Convey("streamBuffer", t, func() {
+		var signals []bool
+		buffer := newStreamBuffer(8, func(pause bool) error {
+			signals = append(signals, pause)
+			return nil
+		})
+		done := make(chan struct{})
+		defer close(done)
+
+		Convey("asks the plugin to pause when the queue fills up", func() {
+			for i := 0; i < 5; i++ {
+				So(buffer.push([]core.Metric{}, done), ShouldBeNil)
+			}
+			So(buffer.isPaused(), ShouldBeFalse)
+			So(buffer.push([]core.Metric{}, done), ShouldBeNil)
+			So(buffer.isPaused(), ShouldBeTrue)
+			So(signals, ShouldResemble, []bool{true})
+
+			Convey("and to resume when the queue is drained", func() {
+				out := make(chan []core.Metric)
+				errs := make(chan error)
+				go buffer.forward(out, errs, done)
+				for i := 0; i < 4; i++ {
+					<-out
+				}
+				So(buffer.isPaused(), ShouldBeFalse)
+				So(signals, ShouldResemble, []bool{true, false})
+			})
+		})
+
+		Convey("stops blocking when done", func() {
+			d := make(chan struct{})
+			for i := 0; i < 8; i++ {
+				So(buffer.push([]core.Metric{}, d), ShouldBeNil)
+			}
+			close(d)
+			So(buffer.push([]core.Metric{}, d), ShouldBeNil)
+		})
+	})
+}
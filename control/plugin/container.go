@@ -0,0 +1,156 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// ContainerImageAnnotation is the annotation of the manifest of a plugin
+// package naming the container image the plugin is run in
+const ContainerImageAnnotation = "snap/container-image"
+
+// ContainerRuntime is the docker compatible CLI containerized plugins are run with
+var ContainerRuntime = "docker"
+
+// containerCommand runs a plugin in a container with the container runtime
+// CLI.  The plugin shares the network of the host so that snapteld reaches
+// it on the address it reports in its handshake.
+type containerCommand struct {
+	*commandWrapper
+	name   string
+	path   string
+	stdin  io.WriteCloser
+	limits ResourceLimits
+}
+
+func (cc *containerCommand) Path() string { return cc.path }
+
+func (cc *containerCommand) Start() error {
+	// the limits are enforced by the container runtime, they are set right
+	// after the "run" argument
+	var opts []string
+	if cc.limits.CPU > 0 {
+		opts = append(opts, "--cpus", strconv.FormatFloat(cc.limits.CPU, 'f', -1, 64))
+	}
+	if cc.limits.Memory > 0 {
+		opts = append(opts, "--memory", strconv.FormatUint(cc.limits.Memory, 10))
+	}
+	if len(opts) > 0 {
+		args := append([]string{}, cc.cmd.Args[:2]...)
+		args = append(args, opts...)
+		cc.cmd.Args = append(args, cc.cmd.Args[2:]...)
+	}
+	return cc.cmd.Start()
+}
+
+func (cc *containerCommand) Kill() error {
+	if cc.cmd.Process != nil {
+		// killing the CLI doesn't stop the container
+		if out, err := exec.Command(ContainerRuntime, "kill", cc.name).CombinedOutput(); err != nil {
+			execLogger.WithFields(log.Fields{
+				"_block":    "Kill",
+				"container": cc.name,
+				"output":    string(out),
+			}).Warn(err)
+		}
+	}
+	cc.stdin.Close()
+	return cc.commandWrapper.Kill()
+}
+
+// NewContainerizedPlugin returns a new ExecutablePlugin run in a container of
+// the given image.  The execPath directory holding the plugin and the TLS
+// files of the plugin are mounted read-only at the same paths in the
+// container.
+func NewContainerizedPlugin(a Arg, image, execPath string, commands ...string) (*ExecutablePlugin, error) {
+	jsonArgs, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	name, err := containerName(commands[0])
+	if err != nil {
+		return nil, err
+	}
+	args := []string{ContainerRuntime, "run", "--rm", "-i", "--network", "host", "--name", name}
+	mounts := []string{execPath}
+	for _, p := range append([]string{a.CertPath, a.KeyPath}, filepath.SplitList(a.CACertPaths)...) {
+		if p != "" {
+			mounts = append(mounts, p)
+		}
+	}
+	for _, m := range mounts {
+		args = append(args, "-v", m+":"+m+":ro")
+	}
+	args = append(args, image)
+	args = append(args, commands...)
+	args = append(args, string(jsonArgs))
+
+	path, err := exec.LookPath(ContainerRuntime)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find container runtime '%s': %v", ContainerRuntime, err)
+	}
+	cmd := &exec.Cmd{
+		Path: path,
+		Args: args,
+	}
+	// the handshake is read from the stream attached to the container, its
+	// stdin stays attached until the plugin is killed
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+	return &ExecutablePlugin{
+		cmd: &containerCommand{
+			commandWrapper: &commandWrapper{cmd: cmd},
+			name:           name,
+			path:           commands[0],
+			stdin:          stdin,
+		},
+		stdout: stdout,
+		stderr: stderr,
+	}, nil
+}
+
+// containerName returns a unique name for the container of the plugin
+func containerName(command string) (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s-%s", filepath.Base(command), hex.EncodeToString(b)), nil
+}
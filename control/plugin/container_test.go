@@ -0,0 +1,65 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNewContainerizedPlugin(t *testing.T) {
+	Convey("NewContainerizedPlugin", t, func() {
+		defaultRuntime := ContainerRuntime
+		// any executable stands in for the container runtime
+		ContainerRuntime = "sh"
+		defer func() { ContainerRuntime = defaultRuntime }()
+		a := Arg{}.SetCertPath("/etc/snap/plugin.crt").SetKeyPath("/etc/snap/plugin.key")
+
+		Convey("runs the plugin in a container of the image", func() {
+			ep, err := NewContainerizedPlugin(a, "snap/plugin-runtime", "/opt/snap/plugins", "/opt/snap/plugins/snap-plugin-collector-mock")
+			So(err, ShouldBeNil)
+			cc, ok := ep.cmd.(*containerCommand)
+			So(ok, ShouldBeTrue)
+			So(cc.Path(), ShouldEqual, "/opt/snap/plugins/snap-plugin-collector-mock")
+			So(cc.name, ShouldStartWith, "snap-plugin-collector-mock-")
+			args := strings.Join(cc.cmd.Args, " ")
+			So(args, ShouldStartWith, "sh run --rm -i --network host --name "+cc.name)
+			So(args, ShouldContainSubstring, "-v /opt/snap/plugins:/opt/snap/plugins:ro")
+			So(args, ShouldContainSubstring, "-v /etc/snap/plugin.crt:/etc/snap/plugin.crt:ro")
+			So(args, ShouldContainSubstring, "-v /etc/snap/plugin.key:/etc/snap/plugin.key:ro")
+			So(args, ShouldContainSubstring, "snap/plugin-runtime /opt/snap/plugins/snap-plugin-collector-mock {")
+
+			Convey("and passes its resource limits to the container runtime", func() {
+				So(ep.SetResourceLimits(ResourceLimits{CPU: 0.5, Memory: 1024}), ShouldBeNil)
+				So(cc.limits, ShouldResemble, ResourceLimits{CPU: 0.5, Memory: 1024})
+			})
+		})
+
+		Convey("fails when the container runtime is not found", func() {
+			ContainerRuntime = "snap-no-such-runtime"
+			_, err := NewContainerizedPlugin(a, "snap/plugin-runtime", "/opt/snap/plugins", "/opt/snap/plugins/snap-plugin-collector-mock")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
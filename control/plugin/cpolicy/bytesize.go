@@ -0,0 +1,215 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implieb.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpolicy
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+
+	"github.com/intelsdi-x/snap/core/ctypes"
+)
+
+const (
+	ByteSizeType = "bytesize"
+)
+
+// A rule validating byte sizes, given as strings with units e.g. "512MB"
+type ByteSizeRule struct {
+	rule
+
+	key      string
+	required bool
+	default_ *int64
+	minimum  *int64
+	maximum  *int64
+}
+
+// NewByteSizeRule returns a new byte size-typed rule. Arguments are key(string), required(bool), default(int64, bytes)
+func NewByteSizeRule(key string, req bool, opts ...int64) (*ByteSizeRule, error) {
+	// Return error if key is empty
+	if key == "" {
+		return nil, EmptyKeyError
+	}
+
+	b := &ByteSizeRule{
+		key:      key,
+		required: req,
+	}
+
+	if len(opts) > 0 {
+		b.default_ = &opts[0]
+	}
+	return b, nil
+}
+
+func (b *ByteSizeRule) Type() string {
+	return ByteSizeType
+}
+
+// MarshalJSON marshals a ByteSizeRule into JSON
+func (b *ByteSizeRule) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Key      string             `json:"key"`
+		Required bool               `json:"required"`
+		Default  ctypes.ConfigValue `json:"default,omitempty"`
+		Minimum  ctypes.ConfigValue `json:"minimum,omitempty"`
+		Maximum  ctypes.ConfigValue `json:"maximum,omitempty"`
+		Type     string             `json:"type"`
+	}{
+		Key:      b.key,
+		Required: b.required,
+		Default:  b.Default(),
+		Minimum:  b.Minimum(),
+		Maximum:  b.Maximum(),
+		Type:     ByteSizeType,
+	})
+}
+
+// GobEncode encodes a ByteSizeRule into a GOB
+func (b *ByteSizeRule) GobEncode() ([]byte, error) {
+	w := new(bytes.Buffer)
+	encoder := gob.NewEncoder(w)
+	if err := encoder.Encode(b.key); err != nil {
+		return nil, err
+	}
+	if err := encoder.Encode(b.required); err != nil {
+		return nil, err
+	}
+	for _, v := range []*int64{b.default_, b.minimum, b.maximum} {
+		if v == nil {
+			encoder.Encode(false)
+			continue
+		}
+		encoder.Encode(true)
+		if err := encoder.Encode(*v); err != nil {
+			return nil, err
+		}
+	}
+	return w.Bytes(), nil
+}
+
+// GobDecode decodes a GOB into a ByteSizeRule
+func (b *ByteSizeRule) GobDecode(buf []byte) error {
+	r := bytes.NewBuffer(buf)
+	decoder := gob.NewDecoder(r)
+	if err := decoder.Decode(&b.key); err != nil {
+		return err
+	}
+	if err := decoder.Decode(&b.required); err != nil {
+		return err
+	}
+	for _, v := range []**int64{&b.default_, &b.minimum, &b.maximum} {
+		var isSet bool
+		if err := decoder.Decode(&isSet); err != nil {
+			return err
+		}
+		if isSet {
+			*v = new(int64)
+			if err := decoder.Decode(*v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Key Returns the key
+func (b *ByteSizeRule) Key() string {
+	return b.key
+}
+
+// Validate Validates a config value against this rule.
+func (b *ByteSizeRule) Validate(cv ctypes.ConfigValue) error {
+	v, err := b.parse(cv)
+	if err != nil {
+		return err
+	}
+	value := v.(ctypes.ConfigValueByteSize).Value
+	// Check minimum.
+	if b.minimum != nil && value < *b.minimum {
+		return fmt.Errorf("value is under minimum (%s value %s < %s)", b.key, ctypes.FormatByteSize(value), ctypes.FormatByteSize(*b.minimum))
+	}
+	// Check maximum.
+	if b.maximum != nil && value > *b.maximum {
+		return fmt.Errorf("value is over maximum (%s value %s > %s)", b.key, ctypes.FormatByteSize(value), ctypes.FormatByteSize(*b.maximum))
+	}
+	return nil
+}
+
+// parse returns the byte size of the config value, given as a byte size or
+// as a string
+func (b *ByteSizeRule) parse(cv ctypes.ConfigValue) (ctypes.ConfigValue, error) {
+	switch v := cv.(type) {
+	case ctypes.ConfigValueByteSize:
+		return v, nil
+	case ctypes.ConfigValueInt:
+		// a number without unit is a number of bytes
+		if v.Value < 0 {
+			return nil, fmt.Errorf("invalid byte size (%s value %d is negative)", b.key, v.Value)
+		}
+		return ctypes.ConfigValueByteSize{Value: int64(v.Value)}, nil
+	case ctypes.ConfigValueStr:
+		value, err := ctypes.ParseByteSize(v.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid byte size (%s value %q, e.g. \"512MB\" or \"1GiB\")", b.key, v.Value)
+		}
+		return ctypes.ConfigValueByteSize{Value: value}, nil
+	}
+	return nil, wrongType(b.key, cv.Type(), ByteSizeType)
+}
+
+// Default return this rules default value
+func (b *ByteSizeRule) Default() ctypes.ConfigValue {
+	if b.default_ != nil {
+		return ctypes.ConfigValueByteSize{Value: *b.default_}
+	}
+	return nil
+}
+
+// Required returns a boolean indicating if this rule is required
+func (b *ByteSizeRule) Required() bool {
+	return b.required
+}
+
+// SetMinimum sets the minimum allowed value
+func (b *ByteSizeRule) SetMinimum(m int64) {
+	b.minimum = &m
+}
+
+// SetMaximum sets the maximum allowed value
+func (b *ByteSizeRule) SetMaximum(m int64) {
+	b.maximum = &m
+}
+
+func (b *ByteSizeRule) Minimum() ctypes.ConfigValue {
+	if b.minimum != nil {
+		return ctypes.ConfigValueByteSize{Value: *b.minimum}
+	}
+	return nil
+}
+
+func (b *ByteSizeRule) Maximum() ctypes.ConfigValue {
+	if b.maximum != nil {
+		return ctypes.ConfigValueByteSize{Value: *b.maximum}
+	}
+	return nil
+}
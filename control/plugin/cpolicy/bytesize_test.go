@@ -0,0 +1,115 @@
+// +build legacy
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpolicy
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"testing"
+
+	"github.com/intelsdi-x/snap/core/ctypes"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestConfigPolicyRuleByteSize(t *testing.T) {
+	Convey("NewByteSizeRule", t, func() {
+
+		Convey("empty key", func() {
+			r, e := NewByteSizeRule("", true)
+			So(r, ShouldBeNil)
+			So(e, ShouldResemble, EmptyKeyError)
+		})
+
+		Convey("default is set", func() {
+			r, e := NewByteSizeRule("buffer", false, 64<<20)
+			So(e, ShouldBeNil)
+			So(r.Type(), ShouldEqual, ByteSizeType)
+			So(r.Default(), ShouldResemble, ctypes.ConfigValueByteSize{Value: 64 << 20})
+		})
+
+		Convey("validating", func() {
+			r, _ := NewByteSizeRule("buffer", true)
+			r.SetMinimum(1 << 10)
+			r.SetMaximum(1 << 30)
+
+			So(r.Validate(ctypes.ConfigValueStr{Value: "512MB"}), ShouldBeNil)
+			So(r.Validate(ctypes.ConfigValueByteSize{Value: 1 << 20}), ShouldBeNil)
+			So(r.Validate(ctypes.ConfigValueInt{Value: 4096}), ShouldBeNil)
+
+			err := r.Validate(ctypes.ConfigValueBool{Value: true})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "type mismatch (buffer wanted type 'bytesize' but provided type 'bool')")
+
+			err = r.Validate(ctypes.ConfigValueStr{Value: "lots"})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, `invalid byte size (buffer value "lots", e.g. "512MB" or "1GiB")`)
+
+			err = r.Validate(ctypes.ConfigValueInt{Value: -1})
+			So(err, ShouldNotBeNil)
+
+			err = r.Validate(ctypes.ConfigValueStr{Value: "1000B"})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "value is under minimum (buffer value 1KB < 1KiB)")
+
+			err = r.Validate(ctypes.ConfigValueStr{Value: "2GB"})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "value is over maximum (buffer value 2GB > 1GiB)")
+		})
+
+		Convey("processing parses the strings", func() {
+			r, _ := NewByteSizeRule("buffer", true)
+			max, _ := NewByteSizeRule("max", false, 1<<30)
+			n := NewPolicyNode()
+			n.Add(r, max)
+			m, errs := n.Process(map[string]ctypes.ConfigValue{"buffer": ctypes.ConfigValueStr{Value: "512MB"}})
+			So(errs.HasErrors(), ShouldBeFalse)
+			So((*m)["buffer"], ShouldResemble, ctypes.ConfigValueByteSize{Value: 512000000})
+			So((*m)["max"], ShouldResemble, ctypes.ConfigValueByteSize{Value: 1 << 30})
+		})
+
+		Convey("marshalling", func() {
+			r, _ := NewByteSizeRule("buffer", true, 1<<20)
+			r.SetMaximum(1 << 30)
+			n := NewPolicyNode()
+			n.Add(r)
+
+			b, err := json.Marshal(n)
+			So(err, ShouldBeNil)
+			So(string(b), ShouldContainSubstring, `"default":"1MiB"`)
+			n2 := NewPolicyNode()
+			So(json.Unmarshal(b, n2), ShouldBeNil)
+			So(n2.rules["buffer"], ShouldResemble, r)
+
+			buf := new(bytes.Buffer)
+			So(gob.NewEncoder(buf).Encode(r), ShouldBeNil)
+			r2 := &ByteSizeRule{}
+			So(gob.NewDecoder(buf).Decode(r2), ShouldBeNil)
+			So(r2, ShouldResemble, r)
+
+			rules, err := n.CopyRules()
+			So(err, ShouldBeNil)
+			So(rules[0], ShouldResemble, r)
+			So(rules[0], ShouldNotPointTo, r)
+		})
+	})
+}
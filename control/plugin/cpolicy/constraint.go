@@ -0,0 +1,213 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpolicy
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/intelsdi-x/snap/core/ctypes"
+)
+
+var (
+	EmptyConstraintError = errors.New("constraint keys cannot be empty")
+)
+
+// A constraint between the items of a config policy node, checked by Process
+// once the defaults of the rules are added
+type Constraint struct {
+	keys    []string
+	ifKey   string
+	ifValue interface{}
+}
+
+// NewRequiredTogether returns a constraint requiring the keys together, e.g.
+// username and password: a config setting one of them sets all of them.
+func NewRequiredTogether(keys ...string) (*Constraint, error) {
+	if len(keys) < 2 {
+		return nil, EmptyConstraintError
+	}
+	return &Constraint{keys: keys}, nil
+}
+
+// NewRequiredIf returns a constraint requiring the keys when the item of the
+// given key holds the given value, e.g. tls_cert when tls is true. The value
+// is a bool, an int, a float64 or a string.
+func NewRequiredIf(key string, value interface{}, keys ...string) (*Constraint, error) {
+	if key == "" || len(keys) == 0 {
+		return nil, EmptyConstraintError
+	}
+	return &Constraint{keys: keys, ifKey: key, ifValue: normalize(value)}, nil
+}
+
+// Keys returns the keys required by the constraint
+func (c *Constraint) Keys() []string {
+	return c.keys
+}
+
+// IfKey returns the key of the item the keys are required upon, empty when
+// the keys are required together
+func (c *Constraint) IfKey() string {
+	return c.ifKey
+}
+
+// IfValue returns the value of the item of IfKey the keys are required upon,
+// a bool, a float64 or a string
+func (c *Constraint) IfValue() interface{} {
+	return c.ifValue
+}
+
+// equal returns true when both constraints require the same keys upon the
+// same condition
+func (c *Constraint) equal(o *Constraint) bool {
+	if c.ifKey != o.ifKey || c.ifValue != o.ifValue || len(c.keys) != len(o.keys) {
+		return false
+	}
+	for i := range c.keys {
+		if c.keys[i] != o.keys[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Check returns an error for each item missing in the map according to the
+// constraint
+func (c *Constraint) Check(m map[string]ctypes.ConfigValue) []error {
+	errs := []error{}
+	if c.ifKey != "" {
+		cv, ok := m[c.ifKey]
+		if !ok || valueOf(cv) != c.ifValue {
+			return errs
+		}
+		for _, k := range c.keys {
+			if _, ok := m[k]; !ok {
+				errs = append(errs, fmt.Errorf("required key missing (%s required when %s is %v)", k, c.ifKey, c.ifValue))
+			}
+		}
+		return errs
+	}
+	set := []string{}
+	missing := []string{}
+	for _, k := range c.keys {
+		if _, ok := m[k]; ok {
+			set = append(set, k)
+		} else {
+			missing = append(missing, k)
+		}
+	}
+	if len(set) == 0 {
+		return errs
+	}
+	for _, k := range missing {
+		errs = append(errs, fmt.Errorf("required key missing (%s required together with %s)", k, strings.Join(set, ", ")))
+	}
+	return errs
+}
+
+// MarshalJSON marshals a Constraint into JSON
+func (c *Constraint) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Keys    []string    `json:"keys"`
+		IfKey   string      `json:"if_key,omitempty"`
+		IfValue interface{} `json:"if_value,omitempty"`
+	}{
+		Keys:    c.keys,
+		IfKey:   c.ifKey,
+		IfValue: c.ifValue,
+	})
+}
+
+// UnmarshalJSON unmarshals JSON into a Constraint
+func (c *Constraint) UnmarshalJSON(data []byte) error {
+	t := struct {
+		Keys    []string    `json:"keys"`
+		IfKey   string      `json:"if_key"`
+		IfValue interface{} `json:"if_value"`
+	}{}
+	if err := json.Unmarshal(data, &t); err != nil {
+		return err
+	}
+	c.keys = t.Keys
+	c.ifKey = t.IfKey
+	c.ifValue = normalize(t.IfValue)
+	return nil
+}
+
+// GobEncode encodes a Constraint into a GOB
+func (c *Constraint) GobEncode() ([]byte, error) {
+	w := new(bytes.Buffer)
+	encoder := gob.NewEncoder(w)
+	if err := encoder.Encode(c.keys); err != nil {
+		return nil, err
+	}
+	if err := encoder.Encode(c.ifKey); err != nil {
+		return nil, err
+	}
+	if c.ifKey != "" {
+		if err := encoder.Encode(&c.ifValue); err != nil {
+			return nil, err
+		}
+	}
+	return w.Bytes(), nil
+}
+
+// GobDecode decodes a GOB into a Constraint
+func (c *Constraint) GobDecode(buf []byte) error {
+	r := bytes.NewBuffer(buf)
+	decoder := gob.NewDecoder(r)
+	if err := decoder.Decode(&c.keys); err != nil {
+		return err
+	}
+	if err := decoder.Decode(&c.ifKey); err != nil {
+		return err
+	}
+	if c.ifKey != "" {
+		return decoder.Decode(&c.ifValue)
+	}
+	return nil
+}
+
+// valueOf returns the value of a config value, the numbers as float64 so that
+// an integer and a float holding the same number are equal
+func valueOf(cv ctypes.ConfigValue) interface{} {
+	switch v := cv.(type) {
+	case ctypes.ConfigValueInt:
+		return float64(v.Value)
+	case ctypes.ConfigValueFloat:
+		return v.Value
+	case ctypes.ConfigValueStr:
+		return v.Value
+	case ctypes.ConfigValueBool:
+		return v.Value
+	}
+	return nil
+}
+
+func normalize(value interface{}) interface{} {
+	if i, ok := value.(int); ok {
+		return float64(i)
+	}
+	return value
+}
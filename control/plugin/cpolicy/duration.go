@@ -0,0 +1,210 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpolicy
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/intelsdi-x/snap/core/ctypes"
+)
+
+const (
+	DurationType = "duration"
+)
+
+// A rule validating durations, given as strings with units e.g. "30s"
+type DurationRule struct {
+	rule
+
+	key      string
+	required bool
+	default_ *time.Duration
+	minimum  *time.Duration
+	maximum  *time.Duration
+}
+
+// NewDurationRule returns a new duration-typed rule. Arguments are key(string), required(bool), default(time.Duration)
+func NewDurationRule(key string, req bool, opts ...time.Duration) (*DurationRule, error) {
+	// Return error if key is empty
+	if key == "" {
+		return nil, EmptyKeyError
+	}
+
+	d := &DurationRule{
+		key:      key,
+		required: req,
+	}
+
+	if len(opts) > 0 {
+		d.default_ = &opts[0]
+	}
+	return d, nil
+}
+
+func (d *DurationRule) Type() string {
+	return DurationType
+}
+
+// MarshalJSON marshals a DurationRule into JSON
+func (d *DurationRule) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Key      string             `json:"key"`
+		Required bool               `json:"required"`
+		Default  ctypes.ConfigValue `json:"default,omitempty"`
+		Minimum  ctypes.ConfigValue `json:"minimum,omitempty"`
+		Maximum  ctypes.ConfigValue `json:"maximum,omitempty"`
+		Type     string             `json:"type"`
+	}{
+		Key:      d.key,
+		Required: d.required,
+		Default:  d.Default(),
+		Minimum:  d.Minimum(),
+		Maximum:  d.Maximum(),
+		Type:     DurationType,
+	})
+}
+
+// GobEncode encodes a DurationRule into a GOB
+func (d *DurationRule) GobEncode() ([]byte, error) {
+	w := new(bytes.Buffer)
+	encoder := gob.NewEncoder(w)
+	if err := encoder.Encode(d.key); err != nil {
+		return nil, err
+	}
+	if err := encoder.Encode(d.required); err != nil {
+		return nil, err
+	}
+	for _, v := range []*time.Duration{d.default_, d.minimum, d.maximum} {
+		if v == nil {
+			encoder.Encode(false)
+			continue
+		}
+		encoder.Encode(true)
+		if err := encoder.Encode(*v); err != nil {
+			return nil, err
+		}
+	}
+	return w.Bytes(), nil
+}
+
+// GobDecode decodes a GOB into a DurationRule
+func (d *DurationRule) GobDecode(buf []byte) error {
+	r := bytes.NewBuffer(buf)
+	decoder := gob.NewDecoder(r)
+	if err := decoder.Decode(&d.key); err != nil {
+		return err
+	}
+	if err := decoder.Decode(&d.required); err != nil {
+		return err
+	}
+	for _, v := range []**time.Duration{&d.default_, &d.minimum, &d.maximum} {
+		var isSet bool
+		if err := decoder.Decode(&isSet); err != nil {
+			return err
+		}
+		if isSet {
+			*v = new(time.Duration)
+			if err := decoder.Decode(*v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Key Returns the key
+func (d *DurationRule) Key() string {
+	return d.key
+}
+
+// Validate Validates a config value against this rule.
+func (d *DurationRule) Validate(cv ctypes.ConfigValue) error {
+	v, err := d.parse(cv)
+	if err != nil {
+		return err
+	}
+	value := v.(ctypes.ConfigValueDuration).Value
+	// Check minimum.
+	if d.minimum != nil && value < *d.minimum {
+		return fmt.Errorf("value is under minimum (%s value %v < %v)", d.key, value, *d.minimum)
+	}
+	// Check maximum.
+	if d.maximum != nil && value > *d.maximum {
+		return fmt.Errorf("value is over maximum (%s value %v > %v)", d.key, value, *d.maximum)
+	}
+	return nil
+}
+
+// parse returns the duration of the config value, given as a duration or as
+// a string
+func (d *DurationRule) parse(cv ctypes.ConfigValue) (ctypes.ConfigValue, error) {
+	switch v := cv.(type) {
+	case ctypes.ConfigValueDuration:
+		return v, nil
+	case ctypes.ConfigValueStr:
+		value, err := time.ParseDuration(v.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration (%s value %q, e.g. \"30s\" or \"1m30s\")", d.key, v.Value)
+		}
+		return ctypes.ConfigValueDuration{Value: value}, nil
+	}
+	return nil, wrongType(d.key, cv.Type(), DurationType)
+}
+
+// Default return this rules default value
+func (d *DurationRule) Default() ctypes.ConfigValue {
+	if d.default_ != nil {
+		return ctypes.ConfigValueDuration{Value: *d.default_}
+	}
+	return nil
+}
+
+// Required returns a boolean indicating if this rule is required
+func (d *DurationRule) Required() bool {
+	return d.required
+}
+
+// SetMinimum sets the minimum allowed value
+func (d *DurationRule) SetMinimum(m time.Duration) {
+	d.minimum = &m
+}
+
+// SetMaximum sets the maximum allowed value
+func (d *DurationRule) SetMaximum(m time.Duration) {
+	d.maximum = &m
+}
+
+func (d *DurationRule) Minimum() ctypes.ConfigValue {
+	if d.minimum != nil {
+		return ctypes.ConfigValueDuration{Value: *d.minimum}
+	}
+	return nil
+}
+
+func (d *DurationRule) Maximum() ctypes.ConfigValue {
+	if d.maximum != nil {
+		return ctypes.ConfigValueDuration{Value: *d.maximum}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+// +build legacy
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpolicy
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/core/ctypes"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestConfigPolicyRuleDuration(t *testing.T) {
+	Convey("NewDurationRule", t, func() {
+
+		Convey("empty key", func() {
+			r, e := NewDurationRule("", true)
+			So(r, ShouldBeNil)
+			So(e, ShouldResemble, EmptyKeyError)
+		})
+
+		Convey("default is set", func() {
+			r, e := NewDurationRule("interval", false, 30*time.Second)
+			So(e, ShouldBeNil)
+			So(r.Type(), ShouldEqual, DurationType)
+			So(r.Default(), ShouldResemble, ctypes.ConfigValueDuration{Value: 30 * time.Second})
+		})
+
+		Convey("validating", func() {
+			r, _ := NewDurationRule("interval", true)
+			r.SetMinimum(time.Second)
+			r.SetMaximum(time.Hour)
+
+			So(r.Validate(ctypes.ConfigValueStr{Value: "1m30s"}), ShouldBeNil)
+			So(r.Validate(ctypes.ConfigValueDuration{Value: time.Minute}), ShouldBeNil)
+
+			err := r.Validate(ctypes.ConfigValueInt{Value: 30})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "type mismatch (interval wanted type 'duration' but provided type 'integer')")
+
+			err = r.Validate(ctypes.ConfigValueStr{Value: "30"})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, `invalid duration (interval value "30", e.g. "30s" or "1m30s")`)
+
+			err = r.Validate(ctypes.ConfigValueStr{Value: "500ms"})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "value is under minimum (interval value 500ms < 1s)")
+
+			err = r.Validate(ctypes.ConfigValueStr{Value: "2h"})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "value is over maximum (interval value 2h0m0s > 1h0m0s)")
+		})
+
+		Convey("processing parses the strings", func() {
+			r, _ := NewDurationRule("interval", true)
+			timeout, _ := NewDurationRule("timeout", false, 5*time.Second)
+			n := NewPolicyNode()
+			n.Add(r, timeout)
+			m, errs := n.Process(map[string]ctypes.ConfigValue{"interval": ctypes.ConfigValueStr{Value: "1m"}})
+			So(errs.HasErrors(), ShouldBeFalse)
+			So((*m)["interval"], ShouldResemble, ctypes.ConfigValueDuration{Value: time.Minute})
+			So((*m)["timeout"], ShouldResemble, ctypes.ConfigValueDuration{Value: 5 * time.Second})
+		})
+
+		Convey("marshalling", func() {
+			r, _ := NewDurationRule("interval", true, time.Minute)
+			r.SetMaximum(time.Hour)
+			n := NewPolicyNode()
+			n.Add(r)
+
+			b, err := json.Marshal(n)
+			So(err, ShouldBeNil)
+			So(string(b), ShouldContainSubstring, `"default":"1m0s"`)
+			n2 := NewPolicyNode()
+			So(json.Unmarshal(b, n2), ShouldBeNil)
+			So(n2.rules["interval"], ShouldResemble, r)
+
+			buf := new(bytes.Buffer)
+			So(gob.NewEncoder(buf).Encode(r), ShouldBeNil)
+			r2 := &DurationRule{}
+			So(gob.NewDecoder(buf).Decode(r2), ShouldBeNil)
+			So(r2, ShouldResemble, r)
+
+			rules, err := n.CopyRules()
+			So(err, ShouldBeNil)
+			So(rules[0], ShouldResemble, r)
+			So(rules[0], ShouldNotPointTo, r)
+		})
+	})
+}
@@ -42,6 +42,7 @@ type FloatRule struct {
 	default_ *float64
 	minimum  *float64
 	maximum  *float64
+	choices  []float64
 }
 
 // MarshalJSON marshals a FloatRule into JSON
@@ -53,6 +54,7 @@ func (f *FloatRule) MarshalJSON() ([]byte, error) {
 		Minimum  ctypes.ConfigValue `json:"minimum,omitempty"`
 		Maximum  ctypes.ConfigValue `json:"maximum,omitempty"`
 		Type     string             `json:"type"`
+		Choices  []float64          `json:"choices,omitempty"`
 	}{
 		Key:      f.key,
 		Required: f.required,
@@ -60,6 +62,7 @@ func (f *FloatRule) MarshalJSON() ([]byte, error) {
 		Minimum:  f.Minimum(),
 		Maximum:  f.Maximum(),
 		Type:     FloatType,
+		Choices:  f.choices,
 	})
 }
 
@@ -101,6 +104,9 @@ func (f *FloatRule) GobEncode() ([]byte, error) {
 			return nil, err
 		}
 	}
+	if err := encoder.Encode(f.choices); err != nil {
+		return nil, err
+	}
 	return w.Bytes(), nil
 }
 
@@ -117,7 +123,9 @@ func (f *FloatRule) GobDecode(buf []byte) error {
 	var is_default_set bool
 	decoder.Decode(&is_default_set)
 	if is_default_set {
-		return decoder.Decode(&f.default_)
+		if err := decoder.Decode(&f.default_); err != nil {
+			return err
+		}
 	}
 	var is_minimum_set bool
 	decoder.Decode(&is_minimum_set)
@@ -133,6 +141,8 @@ func (f *FloatRule) GobDecode(buf []byte) error {
 			return err
 		}
 	}
+	// the rules encoded before the choices were added end here
+	decoder.Decode(&f.choices)
 	return nil
 }
 
@@ -173,6 +183,15 @@ func (f *FloatRule) Validate(cv ctypes.ConfigValue) error {
 	if f.maximum != nil && cv.(ctypes.ConfigValueFloat).Value > *f.maximum {
 		return errors.New(fmt.Sprintf("value is over maximum (%s value %f > %f)", f.key, cv.(ctypes.ConfigValueFloat).Value, *f.maximum))
 	}
+	// Check the choices.
+	if len(f.choices) > 0 {
+		for _, c := range f.choices {
+			if cv.(ctypes.ConfigValueFloat).Value == c {
+				return nil
+			}
+		}
+		return errors.New(fmt.Sprintf("value isn't a choice (%s value %f not in %v)", f.key, cv.(ctypes.ConfigValueFloat).Value, f.choices))
+	}
 	return nil
 }
 
@@ -199,6 +218,24 @@ func (f *FloatRule) SetMaximum(m float64) {
 	f.maximum = &m
 }
 
+// SetChoices sets the values allowed
+func (f *FloatRule) SetChoices(choices ...float64) {
+	f.choices = choices
+}
+
+// Choices returns the values allowed
+func (f *FloatRule) Choices() []float64 {
+	return f.choices
+}
+
+func (f *FloatRule) choiceValues() []interface{} {
+	values := make([]interface{}, len(f.choices))
+	for i, c := range f.choices {
+		values[i] = c
+	}
+	return values
+}
+
 func (i *FloatRule) Minimum() ctypes.ConfigValue {
 	if i.minimum != nil {
 		return ctypes.ConfigValueFloat{Value: *i.minimum}
@@ -156,6 +156,24 @@ func TestConfigPolicyRuleFloat(t *testing.T) {
 				So(err2, ShouldBeNil)
 			})
 
+			Convey("error with value not a choice", func() {
+				r, e := NewFloatRule("thekey", true, 0.5)
+				r.SetMinimum(0.1)
+				r.SetChoices(0.5, 0.9)
+				So(e, ShouldBeNil)
+
+				So(r.Validate(ctypes.ConfigValueFloat{Value: 0.9}), ShouldBeNil)
+				e = r.Validate(ctypes.ConfigValueFloat{Value: 0.7})
+				So(e, ShouldResemble, errors.New("value isn't a choice (thekey value 0.700000 not in [0.5 0.9])"))
+
+				buf, err := r.GobEncode()
+				So(err, ShouldBeNil)
+				r2 := &FloatRule{}
+				So(r2.GobDecode(buf), ShouldBeNil)
+				So(r2.Minimum(), ShouldResemble, ctypes.ConfigValueFloat{Value: 0.1})
+				So(r2.Choices(), ShouldResemble, []float64{0.5, 0.9})
+			})
+
 		})
 
 	})
@@ -42,6 +42,7 @@ type IntRule struct {
 	default_ *int
 	minimum  *int
 	maximum  *int
+	choices  []int
 }
 
 // NewIntegerRule returns a new int-typed rule. Arguments are key(string), required(bool), default(int)
@@ -75,6 +76,7 @@ func (i *IntRule) MarshalJSON() ([]byte, error) {
 		Minimum  ctypes.ConfigValue `json:"minimum,omitempty"`
 		Maximum  ctypes.ConfigValue `json:"maximum,omitempty"`
 		Type     string             `json:"type"`
+		Choices  []int              `json:"choices,omitempty"`
 	}{
 		Key:      i.key,
 		Required: i.required,
@@ -82,6 +84,7 @@ func (i *IntRule) MarshalJSON() ([]byte, error) {
 		Minimum:  i.Minimum(),
 		Maximum:  i.Maximum(),
 		Type:     IntegerType,
+		Choices:  i.choices,
 	})
 }
 
@@ -119,6 +122,9 @@ func (i *IntRule) GobEncode() ([]byte, error) {
 			return nil, err
 		}
 	}
+	if err := encoder.Encode(i.choices); err != nil {
+		return nil, err
+	}
 	return w.Bytes(), nil
 }
 
@@ -135,7 +141,9 @@ func (i *IntRule) GobDecode(buf []byte) error {
 	var is_default_set bool
 	decoder.Decode(&is_default_set)
 	if is_default_set {
-		return decoder.Decode(&i.default_)
+		if err := decoder.Decode(&i.default_); err != nil {
+			return err
+		}
 	}
 	var is_minimum_set bool
 	decoder.Decode(&is_minimum_set)
@@ -151,6 +159,8 @@ func (i *IntRule) GobDecode(buf []byte) error {
 			return err
 		}
 	}
+	// the rules encoded before the choices were added end here
+	decoder.Decode(&i.choices)
 	return nil
 }
 
@@ -176,6 +186,19 @@ func (i *IntRule) Validate(cv ctypes.ConfigValue) error {
 	if i.maximum != nil && cv.(ctypes.ConfigValueInt).Value > *i.maximum {
 		return errors.New(fmt.Sprintf("value is over maximum (%s value %d > %d)", i.key, cv.(ctypes.ConfigValueInt).Value, *i.maximum))
 	}
+	// Check the choices.
+	if len(i.choices) > 0 {
+		v, ok := cv.(ctypes.ConfigValueInt)
+		if !ok {
+			return wrongType(i.key, cv.Type(), IntegerType)
+		}
+		for _, c := range i.choices {
+			if v.Value == c {
+				return nil
+			}
+		}
+		return errors.New(fmt.Sprintf("value isn't a choice (%s value %d not in %v)", i.key, v.Value, i.choices))
+	}
 	return nil
 }
 
@@ -202,6 +225,24 @@ func (i *IntRule) SetMaximum(m int) {
 	i.maximum = &m
 }
 
+// SetChoices sets the values allowed
+func (i *IntRule) SetChoices(choices ...int) {
+	i.choices = choices
+}
+
+// Choices returns the values allowed
+func (i *IntRule) Choices() []int {
+	return i.choices
+}
+
+func (i *IntRule) choiceValues() []interface{} {
+	values := make([]interface{}, len(i.choices))
+	for n, c := range i.choices {
+		values[n] = c
+	}
+	return values
+}
+
 func (i *IntRule) Minimum() ctypes.ConfigValue {
 	if i.minimum != nil {
 		return ctypes.ConfigValueInt{Value: *i.minimum}
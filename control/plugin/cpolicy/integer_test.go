@@ -156,6 +156,22 @@ func TestConfigPolicyRuleInteger(t *testing.T) {
 				So(err2, ShouldBeNil)
 			})
 
+			Convey("error with value not a choice", func() {
+				r, e := NewIntegerRule("thekey", true)
+				r.SetChoices(1, 2, 4)
+				So(e, ShouldBeNil)
+
+				So(r.Validate(ctypes.ConfigValueInt{Value: 2}), ShouldBeNil)
+				e = r.Validate(ctypes.ConfigValueInt{Value: 3})
+				So(e, ShouldResemble, errors.New("value isn't a choice (thekey value 3 not in [1 2 4])"))
+
+				buf, err := r.GobEncode()
+				So(err, ShouldBeNil)
+				r2 := &IntRule{}
+				So(r2.GobDecode(buf), ShouldBeNil)
+				So(r2.Choices(), ShouldResemble, []int{1, 2, 4})
+			})
+
 		})
 
 	})
@@ -26,6 +26,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/intelsdi-x/snap/core/ctypes"
 	"github.com/intelsdi-x/snap/pkg/ctree"
@@ -60,8 +61,9 @@ func (p *ProcessingErrors) AddError(e error) {
 }
 
 type ConfigPolicyNode struct {
-	rules map[string]Rule
-	mutex *sync.Mutex
+	rules       map[string]Rule
+	constraints []*Constraint
+	mutex       *sync.Mutex
 }
 
 func NewPolicyNode() *ConfigPolicyNode {
@@ -75,7 +77,7 @@ func (c *ConfigPolicyNode) CopyRules() ([]Rule, error) {
 	rules := []Rule{}
 	for _, rule := range c.rules {
 		var err error
-		switch rule.(type) {
+		switch r := rule.(type) {
 		case *BoolRule:
 			var newBoolRule *BoolRule
 			if rule.Default() != nil {
@@ -108,6 +110,12 @@ func (c *ConfigPolicyNode) CopyRules() ([]Rule, error) {
 				newIntRule, err = NewIntegerRule(rule.Key(), rule.Required())
 			}
 			rules = append(rules, newIntRule)
+		case *DurationRule:
+			newDurationRule := *r
+			rules = append(rules, &newDurationRule)
+		case *ByteSizeRule:
+			newByteSizeRule := *r
+			rules = append(rules, &newByteSizeRule)
 		default:
 			return []Rule{}, errors.New(fmt.Sprint("Unknown rule type"))
 		}
@@ -131,14 +139,19 @@ func (c *ConfigPolicyNode) UnmarshalJSON(data []byte) error {
 			addRulesToConfigPolicyNode(rules, c)
 		}
 	}
+	if cs, ok := m["constraints"]; ok {
+		return addConstraintsToConfigPolicyNode(cs, c)
+	}
 	return nil
 }
 
 func (c *ConfigPolicyNode) MarshalJSON() ([]byte, error) {
 	return json.Marshal(&struct {
-		Rules map[string]Rule `json:"rules"`
+		Rules       map[string]Rule `json:"rules"`
+		Constraints []*Constraint   `json:"constraints,omitempty"`
 	}{
-		Rules: c.rules,
+		Rules:       c.rules,
+		Constraints: c.constraints,
 	})
 }
 
@@ -148,6 +161,9 @@ func (c *ConfigPolicyNode) GobEncode() ([]byte, error) {
 	if err := encoder.Encode(&c.rules); err != nil {
 		return nil, err
 	}
+	if err := encoder.Encode(&c.constraints); err != nil {
+		return nil, err
+	}
 	return w.Bytes(), nil
 }
 
@@ -155,7 +171,12 @@ func (c *ConfigPolicyNode) GobDecode(buf []byte) error {
 	c.mutex = &sync.Mutex{}
 	r := bytes.NewBuffer(buf)
 	decoder := gob.NewDecoder(r)
-	return decoder.Decode(&c.rules)
+	if err := decoder.Decode(&c.rules); err != nil {
+		return err
+	}
+	// the nodes encoded before the constraints were added end here
+	decoder.Decode(&c.constraints)
+	return nil
 }
 
 // Adds a rule to this policy node
@@ -167,6 +188,20 @@ func (p *ConfigPolicyNode) Add(rules ...Rule) {
 	}
 }
 
+// AddConstraints adds constraints between the items of this policy node
+func (p *ConfigPolicyNode) AddConstraints(constraints ...*Constraint) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.constraints = append(p.constraints, constraints...)
+}
+
+// Constraints returns the constraints between the items of this policy node
+func (p *ConfigPolicyNode) Constraints() []*Constraint {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.constraints
+}
+
 type RuleTableSlice []RuleTable
 
 type RuleTable struct {
@@ -176,6 +211,21 @@ type RuleTable struct {
 	Required bool        `json:"required"`
 	Minimum  interface{} `json:"minimum,omitempty"`
 	Maximum  interface{} `json:"maximum,omitempty"`
+	// Sensitive is true for the rules whose values are sensitive, see StringRule
+	Sensitive bool `json:"sensitive,omitempty"`
+	// Pattern is the regular expression the values of a string rule match
+	Pattern string        `json:"pattern,omitempty"`
+	Choices []interface{} `json:"choices,omitempty"`
+}
+
+// sensitiveRule is implemented by the rules whose values can be sensitive
+type sensitiveRule interface {
+	Sensitive() bool
+}
+
+// choicesRule is implemented by the rules whose values can be enumerated
+type choicesRule interface {
+	choiceValues() []interface{}
 }
 
 func (p *ConfigPolicyNode) RulesAsTable() RuleTableSlice {
@@ -184,18 +234,41 @@ func (p *ConfigPolicyNode) RulesAsTable() RuleTableSlice {
 
 	rt := make([]RuleTable, 0, len(p.rules))
 	for _, r := range p.rules {
-		rt = append(rt, RuleTable{
-			Name:     r.Key(),
-			Type:     r.Type(),
-			Default:  r.Default(),
-			Required: r.Required(),
-			Minimum:  r.Minimum(),
-			Maximum:  r.Maximum(),
-		})
+		sr, ok := r.(sensitiveRule)
+		t := RuleTable{
+			Name:      r.Key(),
+			Type:      r.Type(),
+			Default:   r.Default(),
+			Required:  r.Required(),
+			Minimum:   r.Minimum(),
+			Maximum:   r.Maximum(),
+			Sensitive: ok && sr.Sensitive(),
+		}
+		if s, ok := r.(*StringRule); ok {
+			t.Pattern = s.Pattern()
+		}
+		if cr, ok := r.(choicesRule); ok && len(cr.choiceValues()) > 0 {
+			t.Choices = cr.choiceValues()
+		}
+		rt = append(rt, t)
 	}
 	return rt
 }
 
+// SensitiveKeys returns the keys of the rules whose values are sensitive
+func (p *ConfigPolicyNode) SensitiveKeys() []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	keys := []string{}
+	for k, r := range p.rules {
+		if sr, ok := r.(sensitiveRule); ok && sr.Sensitive() {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
 func (c *ConfigPolicyNode) HasRules() bool {
 	if len(c.rules) > 0 {
 		return true
@@ -228,6 +301,10 @@ func (c *ConfigPolicyNode) Process(m map[string]ctypes.ConfigValue) (*map[string
 			e := rule.Validate(cv)
 			if e != nil {
 				pErrors.AddError(e)
+			} else if pr, ok := rule.(parsingRule); ok {
+				if v, err := pr.parse(cv); err == nil {
+					m[key] = v
+				}
 			}
 		} else {
 			// If it was required add error
@@ -244,6 +321,12 @@ func (c *ConfigPolicyNode) Process(m map[string]ctypes.ConfigValue) (*map[string
 			}
 		}
 	}
+	// Check the constraints between the items, defaults included
+	for _, constraint := range c.constraints {
+		for _, e := range constraint.Check(m) {
+			pErrors.AddError(e)
+		}
+	}
 
 	if pErrors.HasErrors() {
 		return nil, pErrors
@@ -251,6 +334,23 @@ func (c *ConfigPolicyNode) Process(m map[string]ctypes.ConfigValue) (*map[string
 	return &m, pErrors
 }
 
+// Validate validates the items of the map which have a matching rule, unlike
+// Process missing required items aren't reported since they can be set later
+// (e.g. in the config of a task)
+func (c *ConfigPolicyNode) Validate(m map[string]ctypes.ConfigValue) *ProcessingErrors {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	pErrors := NewProcessingErrors()
+	for key, rule := range c.rules {
+		if cv, ok := m[key]; ok {
+			if e := rule.Validate(cv); e != nil {
+				pErrors.AddError(e)
+			}
+		}
+	}
+	return pErrors
+}
+
 // AddDefaults validates and returns a processed policy node or nil and error if validation has failed
 func (c *ConfigPolicyNode) AddDefaults(m map[string]ctypes.ConfigValue) (*map[string]ctypes.ConfigValue, *ProcessingErrors) {
 	c.mutex.Lock()
@@ -295,10 +395,38 @@ func (c ConfigPolicyNode) Merge(n ctree.Node) ctree.Node {
 
 		no.Add(r)
 	}
+	// the constraints held by both nodes are kept once
+	for _, cn := range append(append([]*Constraint{}, c.constraints...), cd.constraints...) {
+		dup := false
+		for _, existing := range no.constraints {
+			if existing.equal(cn) {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			no.constraints = append(no.constraints, cn)
+		}
+	}
 	// Return modified version of ConfigPolicyNode(as ctree.Node)
 	return no
 }
 
+// addConstraintsToConfigPolicyNode adds the constraints decoded from JSON to
+// the ConfigPolicyNode
+func addConstraintsToConfigPolicyNode(cs interface{}, cpn *ConfigPolicyNode) error {
+	b, err := json.Marshal(cs)
+	if err != nil {
+		return err
+	}
+	constraints := []*Constraint{}
+	if err := json.Unmarshal(b, &constraints); err != nil {
+		return err
+	}
+	cpn.AddConstraints(constraints...)
+	return nil
+}
+
 // addRulesToConfigPolicyNode accepts a map of empty interfaces that will be
 // marshalled into rules which will be added to the ConfigPolicyNode provided
 // as the second argument.  This function is called used by the UnmarshalJSON
@@ -326,6 +454,12 @@ func addRulesToConfigPolicyNode(rules map[string]interface{}, cpn *ConfigPolicyN
 					max := int(max_)
 					r.maximum = &max
 				}
+				if cs, ok := rule["choices"].([]interface{}); ok {
+					for _, c := range cs {
+						c_, _ := c.(float64)
+						r.choices = append(r.choices, int(c_))
+					}
+				}
 				cpn.Add(r)
 			case "string":
 				r, _ := NewStringRule(k, req)
@@ -335,6 +469,18 @@ func addRulesToConfigPolicyNode(rules map[string]interface{}, cpn *ConfigPolicyN
 						r.default_ = &def
 					}
 				}
+				r.sensitive, _ = rule["sensitive"].(bool)
+				if p, ok := rule["pattern"].(string); ok && p != "" {
+					if err := r.SetPattern(p); err != nil {
+						return err
+					}
+				}
+				if cs, ok := rule["choices"].([]interface{}); ok {
+					for _, c := range cs {
+						c_, _ := c.(string)
+						r.choices = append(r.choices, c_)
+					}
+				}
 
 				cpn.Add(r)
 			case "bool":
@@ -359,6 +505,36 @@ func addRulesToConfigPolicyNode(rules map[string]interface{}, cpn *ConfigPolicyN
 					max, _ := m.(float64)
 					r.maximum = &max
 				}
+				if cs, ok := rule["choices"].([]interface{}); ok {
+					for _, c := range cs {
+						c_, _ := c.(float64)
+						r.choices = append(r.choices, c_)
+					}
+				}
+				cpn.Add(r)
+			case DurationType:
+				r, _ := NewDurationRule(k, req)
+				for name, v := range map[string]**time.Duration{"default": &r.default_, "minimum": &r.minimum, "maximum": &r.maximum} {
+					if s, ok := rule[name].(string); ok {
+						d, err := time.ParseDuration(s)
+						if err != nil {
+							return fmt.Errorf("invalid %s of %s: %v", name, k, err)
+						}
+						*v = &d
+					}
+				}
+				cpn.Add(r)
+			case ByteSizeType:
+				r, _ := NewByteSizeRule(k, req)
+				for name, v := range map[string]**int64{"default": &r.default_, "minimum": &r.minimum, "maximum": &r.maximum} {
+					if s, ok := rule[name].(string); ok {
+						b, err := ctypes.ParseByteSize(s)
+						if err != nil {
+							return fmt.Errorf("invalid %s of %s: %v", name, k, err)
+						}
+						*v = &b
+					}
+				}
 				cpn.Add(r)
 			default:
 				return errors.New("unknown type")
@@ -22,6 +22,8 @@ limitations under the License.
 package cpolicy
 
 import (
+	"encoding/json"
+	"errors"
 	"testing"
 
 	"github.com/intelsdi-x/snap/core/ctypes"
@@ -243,5 +245,91 @@ func TestConfigPolicyNode(t *testing.T) {
 
 		So(len(pe.Errors()), ShouldEqual, 1)
 	})
+	Convey("Test validate ignores missing required items", t, func() {
+		n := NewPolicyNode()
+
+		m := map[string]ctypes.ConfigValue{}
+		m["port"] = ctypes.ConfigValueInt{Value: 5}
+
+		r1, _ := NewIntegerRule("port", false)
+		r1.SetMaximum(4)
+		r2, _ := NewStringRule("username", true)
+
+		n.Add(r1, r2)
+
+		pe := n.Validate(m)
+
+		So(len(pe.Errors()), ShouldEqual, 1)
+		So(m, ShouldNotContainKey, "username")
+	})
+
+	Convey("Test required together constraint", t, func() {
+		n := NewPolicyNode()
+		r1, _ := NewStringRule("username", false)
+		r2, _ := NewStringRule("password", false)
+		n.Add(r1, r2)
+		c, err := NewRequiredTogether("username", "password")
+		So(err, ShouldBeNil)
+		n.AddConstraints(c)
+
+		_, pe := n.Process(map[string]ctypes.ConfigValue{})
+		So(pe.HasErrors(), ShouldBeFalse)
+
+		_, pe = n.Process(map[string]ctypes.ConfigValue{"username": ctypes.ConfigValueStr{Value: "bob"}})
+		So(pe.Errors(), ShouldResemble, []error{errors.New("required key missing (password required together with username)")})
+
+		_, pe = n.Process(map[string]ctypes.ConfigValue{
+			"username": ctypes.ConfigValueStr{Value: "bob"},
+			"password": ctypes.ConfigValueStr{Value: "s3cr3t"},
+		})
+		So(pe.HasErrors(), ShouldBeFalse)
+	})
+	Convey("Test required if constraint", t, func() {
+		n := NewPolicyNode()
+		r1, _ := NewBoolRule("tls", false, true)
+		r2, _ := NewStringRule("tls_cert", false)
+		n.Add(r1, r2)
+		c, err := NewRequiredIf("tls", true, "tls_cert")
+		So(err, ShouldBeNil)
+		n.AddConstraints(c)
+
+		_, pe := n.Process(map[string]ctypes.ConfigValue{"tls": ctypes.ConfigValueBool{Value: false}})
+		So(pe.HasErrors(), ShouldBeFalse)
+
+		// the default of tls requires tls_cert
+		_, pe = n.Process(map[string]ctypes.ConfigValue{})
+		So(pe.Errors(), ShouldResemble, []error{errors.New("required key missing (tls_cert required when tls is true)")})
+
+		Convey("kept by GOB and JSON", func() {
+			b, err := c.GobEncode()
+			So(err, ShouldBeNil)
+			c2 := &Constraint{}
+			So(c2.GobDecode(b), ShouldBeNil)
+			So(c2.Check(map[string]ctypes.ConfigValue{"tls": ctypes.ConfigValueBool{Value: true}}), ShouldHaveLength, 1)
+
+			b, err = json.Marshal(n)
+			So(err, ShouldBeNil)
+			n3 := NewPolicyNode()
+			So(json.Unmarshal(b, n3), ShouldBeNil)
+			So(n3.Constraints(), ShouldHaveLength, 1)
+			_, pe = n3.Process(map[string]ctypes.ConfigValue{"tls": ctypes.ConfigValueBool{Value: true}})
+			So(pe.HasErrors(), ShouldBeTrue)
+		})
+		Convey("kept once by merging nodes holding them", func() {
+			c2, _ := NewRequiredIf("tls", true, "tls_cert")
+			c3, _ := NewRequiredTogether("tls_cert", "tls_key")
+			n2 := NewPolicyNode()
+			n2.AddConstraints(c2, c3)
+			merged := n.Merge(n2).(*ConfigPolicyNode)
+			So(merged.Constraints(), ShouldResemble, []*Constraint{c, c3})
+			So(merged.Merge(n).(*ConfigPolicyNode).Constraints(), ShouldHaveLength, 2)
+		})
+	})
+	Convey("Test constraints need keys", t, func() {
+		_, err := NewRequiredTogether("username")
+		So(err, ShouldEqual, EmptyConstraintError)
+		_, err = NewRequiredIf("tls", true)
+		So(err, ShouldEqual, EmptyConstraintError)
+	})
 
 }
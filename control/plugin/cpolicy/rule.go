@@ -43,6 +43,12 @@ type Rule interface {
 	Maximum() ctypes.ConfigValue
 }
 
+// parsingRule is implemented by the rules whose values are parsed from
+// strings, e.g. durations, Process replaces the values by the parsed ones
+type parsingRule interface {
+	parse(ctypes.ConfigValue) (ctypes.ConfigValue, error)
+}
+
 type rule struct {
 	Description string
 }
@@ -0,0 +1,164 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpolicy
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/intelsdi-x/snap/core/ctypes"
+)
+
+// JSONSchemaVersion is the draft of JSON Schema the policies are rendered in
+const JSONSchemaVersion = "http://json-schema.org/draft-04/schema#"
+
+// Schema is a JSON Schema document, limited to the keywords needed to
+// describe a config policy
+type Schema struct {
+	Schema       string              `json:"$schema,omitempty"`
+	Title        string              `json:"title,omitempty"`
+	Type         string              `json:"type,omitempty"`
+	Properties   map[string]*Schema  `json:"properties,omitempty"`
+	Required     []string            `json:"required,omitempty"`
+	Dependencies map[string][]string `json:"dependencies,omitempty"`
+	Default      interface{}         `json:"default,omitempty"`
+	Minimum      interface{}         `json:"minimum,omitempty"`
+	Maximum      interface{}         `json:"maximum,omitempty"`
+	Pattern      string              `json:"pattern,omitempty"`
+	Enum         []interface{}       `json:"enum,omitempty"`
+	AllOf        []*Schema           `json:"allOf,omitempty"`
+	AnyOf        []*Schema           `json:"anyOf,omitempty"`
+	Not          *Schema             `json:"not,omitempty"`
+}
+
+var schemaTypes = map[string]string{
+	"string":  "string",
+	"integer": "integer",
+	"float":   "number",
+	"bool":    "boolean",
+	// the durations and the byte sizes are given as strings with units
+	"duration": "string",
+	"bytesize": "string",
+}
+
+// JSONSchema renders the ConfigPolicy as the JSON Schema of the config of a
+// task workflow: an object keyed by the namespaces of the policy (e.g.
+// /intel/mock) whose values are the config items accepted at the namespace,
+// the rules inherited from the parent namespaces included.
+func (c *ConfigPolicy) JSONSchema() *Schema {
+	s := &Schema{
+		Schema:     JSONSchemaVersion,
+		Type:       "object",
+		Properties: map[string]*Schema{},
+	}
+	for _, node := range c.GetAll() {
+		ns := "/" + strings.Join(node.Key, "/")
+		s.Properties[ns] = c.Get(node.Key).jsonSchema()
+	}
+	return s
+}
+
+// JSONSchema renders the ConfigPolicyNode as the JSON Schema of the config
+// items it accepts
+func (p *ConfigPolicyNode) JSONSchema() *Schema {
+	s := p.jsonSchema()
+	s.Schema = JSONSchemaVersion
+	return s
+}
+
+func (p *ConfigPolicyNode) jsonSchema() *Schema {
+	s := &Schema{
+		Type:       "object",
+		Properties: map[string]*Schema{},
+	}
+	for _, r := range p.RulesAsTable() {
+		s.Properties[r.Name] = &Schema{
+			Type:    schemaTypes[r.Type],
+			Default: schemaValue(r.Default),
+			Enum:    r.Choices,
+		}
+		// the bounds of the durations and byte sizes can't be checked on strings
+		if r.Type != DurationType && r.Type != ByteSizeType {
+			s.Properties[r.Name].Minimum = schemaValue(r.Minimum)
+			s.Properties[r.Name].Maximum = schemaValue(r.Maximum)
+		}
+		// JSON Schema patterns aren't anchored, the rules match the whole value
+		if r.Pattern != "" {
+			s.Properties[r.Name].Pattern = "^(?:" + r.Pattern + ")$"
+		}
+		// the defaults are added to the config missing the item
+		if r.Required && r.Default == nil {
+			s.Required = append(s.Required, r.Name)
+		}
+	}
+	sort.Strings(s.Required)
+
+	for _, c := range p.Constraints() {
+		if c.ifKey == "" {
+			if s.Dependencies == nil {
+				s.Dependencies = map[string][]string{}
+			}
+			for _, k := range c.keys {
+				for _, other := range c.keys {
+					if other != k && !contains(s.Dependencies[k], other) {
+						s.Dependencies[k] = append(s.Dependencies[k], other)
+					}
+				}
+			}
+			continue
+		}
+		// either the item doesn't hold the value or the keys are set
+		s.AllOf = append(s.AllOf, &Schema{
+			AnyOf: []*Schema{
+				&Schema{Properties: map[string]*Schema{c.ifKey: &Schema{Not: &Schema{Enum: []interface{}{c.ifValue}}}}},
+				&Schema{Required: c.keys},
+			},
+		})
+	}
+	return s
+}
+
+// schemaValue returns the value of the ConfigValue, nil if it isn't set
+func schemaValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case ctypes.ConfigValueInt:
+		return t.Value
+	case ctypes.ConfigValueFloat:
+		return t.Value
+	case ctypes.ConfigValueStr:
+		return t.Value
+	case ctypes.ConfigValueBool:
+		return t.Value
+	case ctypes.ConfigValueDuration:
+		return t.String()
+	case ctypes.ConfigValueByteSize:
+		return t.String()
+	}
+	return nil
+}
+
+func contains(s []string, k string) bool {
+	for _, i := range s {
+		if i == k {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+// +build legacy
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpolicy
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestConfigPolicyJSONSchema(t *testing.T) {
+	Convey("JSON Schema of a config policy", t, func() {
+		user, _ := NewStringRule("user", true)
+		user.SetPattern("[a-z]+")
+		level, _ := NewStringRule("level", false, "info")
+		level.SetChoices("info", "debug")
+		port, _ := NewIntegerRule("port", true, 8080)
+		port.SetMinimum(1)
+		port.SetMaximum(65535)
+		tls, _ := NewBoolRule("tls", false)
+		cert, _ := NewStringRule("tls_cert", false)
+		password, _ := NewStringRule("password", false)
+		ratio, _ := NewFloatRule("ratio", false)
+
+		root := NewPolicyNode()
+		root.Add(user, level)
+		foo := NewPolicyNode()
+		foo.Add(port, tls, cert, password, ratio)
+		together, _ := NewRequiredTogether("user", "password")
+		requiredIf, _ := NewRequiredIf("tls", true, "tls_cert")
+		foo.AddConstraints(together, requiredIf)
+
+		Convey("renders the rules of a node", func() {
+			s := foo.JSONSchema()
+			So(s.Schema, ShouldEqual, JSONSchemaVersion)
+			So(s.Type, ShouldEqual, "object")
+			So(s.Properties["port"], ShouldResemble, &Schema{Type: "integer", Default: 8080, Minimum: 1, Maximum: 65535})
+			So(s.Properties["tls"].Type, ShouldEqual, "boolean")
+			So(s.Properties["ratio"].Type, ShouldEqual, "number")
+			So(s.Required, ShouldBeNil)
+			So(s.Dependencies, ShouldResemble, map[string][]string{
+				"user":     []string{"password"},
+				"password": []string{"user"},
+			})
+			So(len(s.AllOf), ShouldEqual, 1)
+			So(s.AllOf[0].AnyOf[0].Properties["tls"].Not.Enum, ShouldResemble, []interface{}{true})
+			So(s.AllOf[0].AnyOf[1].Required, ShouldResemble, []string{"tls_cert"})
+		})
+
+		Convey("renders the rules of a tree by namespace", func() {
+			c := New()
+			c.Add([]string{"intel", "mock"}, root)
+			c.Add([]string{"intel", "mock", "foo"}, foo)
+			s := c.JSONSchema()
+			So(s.Schema, ShouldEqual, JSONSchemaVersion)
+			So(len(s.Properties), ShouldEqual, 2)
+
+			mock := s.Properties["/intel/mock"]
+			So(mock.Schema, ShouldBeEmpty)
+			So(mock.Required, ShouldResemble, []string{"user"})
+			So(mock.Properties["user"].Pattern, ShouldEqual, "^(?:[a-z]+)$")
+			So(mock.Properties["level"].Enum, ShouldResemble, []interface{}{"info", "debug"})
+			So(mock.Properties["level"].Default, ShouldEqual, "info")
+
+			// the rules of /intel/mock are inherited
+			fooSchema := s.Properties["/intel/mock/foo"]
+			So(fooSchema.Required, ShouldResemble, []string{"user"})
+			So(len(fooSchema.Properties), ShouldEqual, 7)
+
+			b, err := json.Marshal(s)
+			So(err, ShouldBeNil)
+			So(string(b), ShouldContainSubstring, `"$schema":"http://json-schema.org/draft-04/schema#"`)
+			So(string(b), ShouldContainSubstring, `"port":{"type":"integer","default":8080,"minimum":1,"maximum":65535}`)
+		})
+	})
+}
@@ -23,21 +23,32 @@ import (
 	"bytes"
 	"encoding/gob"
 	"encoding/json"
+	"fmt"
+	"regexp"
 
 	"github.com/intelsdi-x/snap/core/ctypes"
+	"github.com/intelsdi-x/snap/pkg/cfgcrypt"
+	"github.com/intelsdi-x/snap/pkg/secrets"
 )
 
 const (
 	StringType = "string"
+
+	// redactedValue replaces the sensitive values in the validation errors
+	redactedValue = "********"
 )
 
 // A rule validating against string-typed config
 type StringRule struct {
 	rule
 
-	key      string
-	required bool
-	default_ *string
+	key       string
+	required  bool
+	default_  *string
+	sensitive bool
+	pattern   string
+	regexp    *regexp.Regexp
+	choices   []string
 }
 
 // Returns a new string-typed rule. Arguments are key(string), required(bool), default(string).
@@ -66,15 +77,21 @@ func (s *StringRule) Type() string {
 // MarshalJSON marshals a StringRule into JSON
 func (s *StringRule) MarshalJSON() ([]byte, error) {
 	return json.Marshal(&struct {
-		Key      string             `json:"key"`
-		Required bool               `json:"required"`
-		Default  ctypes.ConfigValue `json:"default"`
-		Type     string             `json:"type"`
+		Key       string             `json:"key"`
+		Required  bool               `json:"required"`
+		Default   ctypes.ConfigValue `json:"default"`
+		Type      string             `json:"type"`
+		Sensitive bool               `json:"sensitive,omitempty"`
+		Pattern   string             `json:"pattern,omitempty"`
+		Choices   []string           `json:"choices,omitempty"`
 	}{
-		Key:      s.key,
-		Required: s.required,
-		Default:  s.Default(),
-		Type:     StringType,
+		Key:       s.key,
+		Required:  s.required,
+		Default:   s.Default(),
+		Type:      StringType,
+		Sensitive: s.sensitive,
+		Pattern:   s.pattern,
+		Choices:   s.choices,
 	})
 }
 
@@ -96,6 +113,15 @@ func (s *StringRule) GobEncode() ([]byte, error) {
 			return nil, err
 		}
 	}
+	if err := encoder.Encode(s.sensitive); err != nil {
+		return nil, err
+	}
+	if err := encoder.Encode(s.pattern); err != nil {
+		return nil, err
+	}
+	if err := encoder.Encode(s.choices); err != nil {
+		return nil, err
+	}
 	return w.Bytes(), nil
 }
 
@@ -112,8 +138,21 @@ func (s *StringRule) GobDecode(buf []byte) error {
 	var is_default_set bool
 	decoder.Decode(&is_default_set)
 	if is_default_set {
-		return decoder.Decode(&s.default_)
+		if err := decoder.Decode(&s.default_); err != nil {
+			return err
+		}
 	}
+	// the rules encoded before sensitive was added end here
+	decoder.Decode(&s.sensitive)
+	// and the ones encoded before the pattern and the choices were added here
+	var pattern string
+	decoder.Decode(&pattern)
+	if pattern != "" {
+		if err := s.SetPattern(pattern); err != nil {
+			return err
+		}
+	}
+	decoder.Decode(&s.choices)
 	return nil
 }
 
@@ -128,6 +167,29 @@ func (s *StringRule) Validate(cv ctypes.ConfigValue) error {
 	if cv.Type() != StringType {
 		return wrongType(s.key, cv.Type(), StringType)
 	}
+	v := cv.(ctypes.ConfigValueStr).Value
+	// The encrypted values and the references to secrets aren't the values
+	// the plugin gets, they aren't checked against the pattern and the choices.
+	if cfgcrypt.IsEncrypted(v) || secrets.IsReference(v) {
+		return nil
+	}
+	shown := v
+	if s.sensitive {
+		shown = redactedValue
+	}
+	// Check the pattern. Type should be safe now because of the check above.
+	if s.regexp != nil && !s.regexp.MatchString(v) {
+		return fmt.Errorf("value doesn't match pattern (%s value '%s' !~ '%s')", s.key, shown, s.pattern)
+	}
+	// Check the choices.
+	if len(s.choices) > 0 {
+		for _, c := range s.choices {
+			if v == c {
+				return nil
+			}
+		}
+		return fmt.Errorf("value isn't a choice (%s value '%s' not in %v)", s.key, shown, s.choices)
+	}
 	return nil
 }
 
@@ -144,6 +206,52 @@ func (s *StringRule) Required() bool {
 	return s.required
 }
 
+// SetSensitive marks the values of this rule sensitive, e.g. passwords and
+// tokens. The sensitive values are encrypted at rest and redacted from the
+// API responses.
+func (s *StringRule) SetSensitive(sensitive bool) {
+	s.sensitive = sensitive
+}
+
+// Indicates the values of this rule are sensitive.
+func (s *StringRule) Sensitive() bool {
+	return s.sensitive
+}
+
+// SetPattern sets the regular expression the whole value has to match
+func (s *StringRule) SetPattern(pattern string) error {
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return err
+	}
+	s.pattern = pattern
+	s.regexp = re
+	return nil
+}
+
+// Pattern returns the regular expression the values have to match
+func (s *StringRule) Pattern() string {
+	return s.pattern
+}
+
+// SetChoices sets the values allowed
+func (s *StringRule) SetChoices(choices ...string) {
+	s.choices = choices
+}
+
+// Choices returns the values allowed
+func (s *StringRule) Choices() []string {
+	return s.choices
+}
+
+func (s *StringRule) choiceValues() []interface{} {
+	values := make([]interface{}, len(s.choices))
+	for i, c := range s.choices {
+		values[i] = c
+	}
+	return values
+}
+
 func (s *StringRule) Minimum() ctypes.ConfigValue {
 	return nil
 }
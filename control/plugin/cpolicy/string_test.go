@@ -22,6 +22,7 @@ limitations under the License.
 package cpolicy
 
 import (
+	"encoding/json"
 	"errors"
 	"testing"
 
@@ -64,6 +65,30 @@ func TestConfigPolicyRuleString(t *testing.T) {
 			So(e, ShouldBeNil)
 		})
 
+		Convey("sensitive is set", func() {
+			r, e := NewStringRule("password", true)
+			So(e, ShouldBeNil)
+			So(r.Sensitive(), ShouldBeFalse)
+			r.SetSensitive(true)
+			So(r.Sensitive(), ShouldBeTrue)
+
+			Convey("and kept by GOB", func() {
+				b, e := r.GobEncode()
+				So(e, ShouldBeNil)
+				r2 := &StringRule{}
+				So(r2.GobDecode(b), ShouldBeNil)
+				So(r2.Key(), ShouldEqual, "password")
+				So(r2.Sensitive(), ShouldBeTrue)
+			})
+
+			Convey("and listed by the node", func() {
+				n := NewPolicyNode()
+				n.Add(r)
+				So(n.SensitiveKeys(), ShouldResemble, []string{"password"})
+				So(n.RulesAsTable()[0].Sensitive, ShouldBeTrue)
+			})
+		})
+
 		Convey("processing", func() {
 
 			Convey("passes with string config value", func() {
@@ -90,6 +115,80 @@ func TestConfigPolicyRuleString(t *testing.T) {
 				So(e, ShouldResemble, errors.New("type mismatch (thekey wanted type 'string' but provided type 'integer')"))
 			})
 
+			Convey("errors with value not matching the pattern", func() {
+				r, e := NewStringRule("thekey", true)
+				So(e, ShouldBeNil)
+				So(r.SetPattern("[a-z]+-[0-9]+"), ShouldBeNil)
+
+				So(r.Validate(ctypes.ConfigValueStr{Value: "node-01"}), ShouldBeNil)
+				e = r.Validate(ctypes.ConfigValueStr{Value: "node-01.local"})
+				So(e, ShouldResemble, errors.New("value doesn't match pattern (thekey value 'node-01.local' !~ '[a-z]+-[0-9]+')"))
+
+				So(r.SetPattern("[a-z"), ShouldNotBeNil)
+				So(r.Pattern(), ShouldEqual, "[a-z]+-[0-9]+")
+			})
+
+			Convey("does not check encrypted values and references to secrets", func() {
+				r, e := NewStringRule("password", true)
+				So(e, ShouldBeNil)
+				So(r.SetPattern("[a-z]+"), ShouldBeNil)
+				r.SetChoices("secret")
+
+				So(r.Validate(ctypes.ConfigValueStr{Value: "enc:v1:bm9uY2VjaXBoZXJ0ZXh0"}), ShouldBeNil)
+				So(r.Validate(ctypes.ConfigValueStr{Value: "secret://env/SNAP_SECRET_PASSWORD"}), ShouldBeNil)
+			})
+
+			Convey("redacts sensitive values from the errors", func() {
+				r, e := NewStringRule("password", true)
+				So(e, ShouldBeNil)
+				r.SetSensitive(true)
+				So(r.SetPattern("[a-z]+"), ShouldBeNil)
+
+				e = r.Validate(ctypes.ConfigValueStr{Value: "hunter2"})
+				So(e, ShouldResemble, errors.New("value doesn't match pattern (password value '********' !~ '[a-z]+')"))
+				r.SetChoices("secret")
+				e = r.Validate(ctypes.ConfigValueStr{Value: "hunter"})
+				So(e, ShouldResemble, errors.New("value isn't a choice (password value '********' not in [secret])"))
+			})
+
+			Convey("errors with value not a choice", func() {
+				r, e := NewStringRule("thekey", true)
+				So(e, ShouldBeNil)
+				r.SetChoices("udp", "tcp")
+
+				So(r.Validate(ctypes.ConfigValueStr{Value: "tcp"}), ShouldBeNil)
+				e = r.Validate(ctypes.ConfigValueStr{Value: "http"})
+				So(e, ShouldResemble, errors.New("value isn't a choice (thekey value 'http' not in [udp tcp])"))
+
+				Convey("kept by GOB and listed by the node", func() {
+					So(r.SetPattern("[a-z]+"), ShouldBeNil)
+					b, e := r.GobEncode()
+					So(e, ShouldBeNil)
+					r2 := &StringRule{}
+					So(r2.GobDecode(b), ShouldBeNil)
+					So(r2.Pattern(), ShouldEqual, "[a-z]+")
+					So(r2.Choices(), ShouldResemble, []string{"udp", "tcp"})
+					So(r2.Validate(ctypes.ConfigValueStr{Value: "1"}), ShouldNotBeNil)
+
+					n := NewPolicyNode()
+					n.Add(r2)
+					So(n.RulesAsTable()[0].Pattern, ShouldEqual, "[a-z]+")
+					So(n.RulesAsTable()[0].Choices, ShouldResemble, []interface{}{"udp", "tcp"})
+				})
+
+				Convey("kept by JSON", func() {
+					So(r.SetPattern("[a-z]+"), ShouldBeNil)
+					n := NewPolicyNode()
+					n.Add(r)
+					b, e := json.Marshal(n)
+					So(e, ShouldBeNil)
+					n2 := NewPolicyNode()
+					So(json.Unmarshal(b, n2), ShouldBeNil)
+					So(n2.RulesAsTable()[0].Pattern, ShouldEqual, "[a-z]+")
+					So(n2.RulesAsTable()[0].Choices, ShouldResemble, []interface{}{"udp", "tcp"})
+				})
+			})
+
 		})
 
 	})
@@ -94,6 +94,11 @@ func unmarshalJSON(m map[string]interface{}, keys *[]string, config *ctree.Confi
 						return err
 					}
 				}
+				if cs, ok := node["constraints"]; ok {
+					if err := addConstraintsToConfigPolicyNode(cs, cpn); err != nil {
+						return err
+					}
+				}
 				config.Add(*keys, cpn)
 			}
 		}
@@ -25,7 +25,7 @@ import (
 	"fmt"
 	"io"
 	"os/exec"
-	"path"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -34,6 +34,10 @@ import (
 
 var execLogger = log.WithField("_module", "plugin-exec")
 
+// KillGracePeriod is the time given to the process of a plugin to exit once it
+// is asked to stop, before it is killed
+var KillGracePeriod = time.Second
+
 type ExecutablePlugin struct {
 	name   string
 	cmd    command
@@ -52,29 +56,80 @@ type command interface {
 // The implementation of command used here.
 type commandWrapper struct {
 	cmd *exec.Cmd
+	// path is the path of the plugin once the limiter wrapped the command
+	// (e.g. in a shell setting its rlimit)
+	path string
+	// limiter enforces the resource limits of the plugin, nil without limits
+	limiter resourceLimiter
 }
 
-func (cw *commandWrapper) Path() string { return cw.cmd.Path }
+func (cw *commandWrapper) Path() string {
+	if cw.path != "" {
+		return cw.path
+	}
+	return cw.cmd.Path
+}
 func (cw *commandWrapper) Kill() error {
-	// first, kill the process wrapped up in the commandWrapper
+	// first, make sure the process wrapped up in the commandWrapper is running
 	if cw.cmd.Process == nil {
-		err := fmt.Errorf("Process for plugin '%s' not started; cannot kill", path.Base(cw.Path()))
+		err := fmt.Errorf("Process for plugin '%s' not started; cannot kill", filepath.Base(cw.Path()))
 		log.WithFields(log.Fields{
 			"_block": "Kill",
 		}).Warn(err)
 		return err
-	} else if err := cw.cmd.Process.Kill(); err != nil {
+	}
+	// then ask it to stop and wait for it to exit (so that we don't have any
+	// zombie processes kicking around the system), killing it when it doesn't
+	// exit within the grace period
+	exited := make(chan error, 1)
+	go func() {
+		_, err := cw.cmd.Process.Wait()
+		exited <- err
+	}()
+	if err := terminate(cw.cmd.Process); err != nil {
 		log.WithFields(log.Fields{
 			"_block": "Kill",
-		}).Error(err)
-		return err
+		}).Debug(err)
+	}
+	var err error
+	select {
+	case err = <-exited:
+	case <-time.After(KillGracePeriod):
+		if kerr := cw.cmd.Process.Kill(); kerr != nil {
+			log.WithFields(log.Fields{
+				"_block": "Kill",
+			}).Error(kerr)
+		}
+		err = <-exited
+	}
+	if cw.limiter != nil {
+		if rerr := cw.limiter.release(); rerr != nil {
+			log.WithFields(log.Fields{
+				"_block": "Kill",
+			}).Warn(rerr)
+		}
 	}
-	// then wait for it to exit (so that we don't have any zombie processes kicking
-	// around the system)
-	_, err := cw.cmd.Process.Wait()
 	return err
 }
-func (cw *commandWrapper) Start() error { return cw.cmd.Start() }
+func (cw *commandWrapper) Start() error {
+	if cw.limiter == nil {
+		return cw.cmd.Start()
+	}
+	cw.path = cw.cmd.Path
+	if err := cw.limiter.prepare(cw.cmd); err != nil {
+		return err
+	}
+	if err := cw.cmd.Start(); err != nil {
+		return err
+	}
+	// don't let the plugin run without its limits
+	if err := cw.limiter.apply(cw.cmd.Process.Pid); err != nil {
+		cw.cmd.Process.Kill()
+		cw.cmd.Process.Wait()
+		return fmt.Errorf("unable to apply the resource limits of plugin '%s': %v", filepath.Base(cw.Path()), err)
+	}
+	return nil
+}
 
 // NewExecutablePlugin returns a new ExecutablePlugin.
 func NewExecutablePlugin(a Arg, commands ...string) (*ExecutablePlugin, error) {
@@ -95,7 +150,7 @@ func NewExecutablePlugin(a Arg, commands ...string) (*ExecutablePlugin, error) {
 		return nil, err
 	}
 	return &ExecutablePlugin{
-		cmd:    &commandWrapper{cmd},
+		cmd:    &commandWrapper{cmd: cmd},
 		stdout: stdout,
 		stderr: stderr,
 	}, nil
@@ -146,7 +201,7 @@ func (e *ExecutablePlugin) Run(timeout time.Duration) (Response, error) {
 				}
 
 				execLogger.
-					WithField("plugin", path.Base(e.cmd.Path())).
+					WithField("plugin", filepath.Base(e.cmd.Path())).
 					WithField("io", "stdout").
 					WithField("scanner_err", errScanner).
 					WithField("read_string_err", errRead).
@@ -166,7 +221,7 @@ func (e *ExecutablePlugin) Run(timeout time.Duration) (Response, error) {
 	case <-doneChan:
 	case <-time.After(timeout):
 		// We timed out waiting for the plugin's response.  Set err.
-		err = fmt.Errorf("timed out waiting for plugin %s", path.Base(e.cmd.Path()))
+		err = fmt.Errorf("timed out waiting for plugin %s", filepath.Base(e.cmd.Path()))
 	}
 	if err != nil {
 		execLogger.WithFields(log.Fields{
@@ -197,6 +252,51 @@ func (e *ExecutablePlugin) Kill() error {
 	return e.cmd.Kill()
 }
 
+// SetResourceLimits caps the CPU and memory the plugin may use, it has to be
+// called before the plugin is run.
+func (e *ExecutablePlugin) SetResourceLimits(limits ResourceLimits) error {
+	if cc, ok := e.cmd.(*containerCommand); ok {
+		cc.limits = limits
+		return nil
+	}
+	cw, ok := e.cmd.(*commandWrapper)
+	if !ok || limits.IsEmpty() {
+		return nil
+	}
+	limiter, err := newResourceLimiter(e.name, limits)
+	if err != nil {
+		return err
+	}
+	cw.limiter = limiter
+	return nil
+}
+
+// Pid returns the process ID of the plugin, 0 when the plugin isn't running
+func (e *ExecutablePlugin) Pid() int {
+	var cmd *exec.Cmd
+	switch c := e.cmd.(type) {
+	case *containerCommand:
+		if c.commandWrapper != nil {
+			cmd = c.cmd
+		}
+	case *commandWrapper:
+		cmd = c.cmd
+	}
+	if cmd == nil || cmd.Process == nil {
+		return 0
+	}
+	return cmd.Process.Pid
+}
+
+// MemoryLimitExceeded returns true once the plugin exceeded its memory limit
+func (e *ExecutablePlugin) MemoryLimitExceeded() bool {
+	cw, ok := e.cmd.(*commandWrapper)
+	if !ok || cw.limiter == nil {
+		return false
+	}
+	return cw.limiter.memoryLimitExceeded()
+}
+
 func (e *ExecutablePlugin) captureStderr() {
 	stdErrScanner := bufio.NewScanner(e.stderr)
 	go func() {
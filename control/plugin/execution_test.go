@@ -23,6 +23,8 @@ package plugin
 
 import (
 	"io"
+	"os/exec"
+	"runtime"
 	"testing"
 	"time"
 
@@ -81,3 +83,27 @@ func TestExecutablePlugin(t *testing.T) {
 		})
 	})
 }
+
+func TestCommandWrapperKill(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sleep isn't available on Windows")
+	}
+	Convey("Given the running process of a plugin", t, func() {
+		cw := &commandWrapper{cmd: exec.Command("sleep", "60")}
+		So(cw.Start(), ShouldBeNil)
+		Convey("it is asked to stop and exits before the grace period is over", func() {
+			grace := KillGracePeriod
+			KillGracePeriod = time.Minute
+			defer func() { KillGracePeriod = grace }()
+			start := time.Now()
+			So(cw.Kill(), ShouldBeNil)
+			So(time.Since(start), ShouldBeLessThan, KillGracePeriod)
+		})
+	})
+	Convey("Given a process which wasn't started", t, func() {
+		cw := &commandWrapper{cmd: exec.Command("sleep", "60")}
+		Convey("it can't be killed", func() {
+			So(cw.Kill(), ShouldNotBeNil)
+		})
+	})
+}
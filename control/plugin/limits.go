@@ -0,0 +1,107 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+
+	"github.com/intelsdi-x/snap/core/ctypes"
+)
+
+// The config items setting the resource limits of a plugin, declared with
+// defaults in the plugin config policy or set in the plugin config
+const (
+	// ResourceCPULimitKey is the number of CPUs a plugin may use (e.g. 0.5)
+	ResourceCPULimitKey = "resource_cpu_limit"
+	// ResourceMemoryLimitKey is the memory a plugin may use, a byte size (e.g.
+	// "512MB") or an integer number of megabytes
+	ResourceMemoryLimitKey = "resource_memory_limit"
+)
+
+var (
+	// ErrResourceLimitsUnsupported - error message when resource limits can't be enforced on the platform
+	ErrResourceLimitsUnsupported = errors.New("plugin resource limits are not supported on this platform")
+)
+
+// ResourceLimits holds the CPU and memory caps of a plugin, zero values mean
+// no limit
+type ResourceLimits struct {
+	// CPU is the number of CPUs the plugin may use
+	CPU float64
+	// Memory is the memory the plugin may use in bytes
+	Memory uint64
+}
+
+// IsEmpty returns true when no limit is set
+func (r ResourceLimits) IsEmpty() bool {
+	return r.CPU <= 0 && r.Memory == 0
+}
+
+// NewResourceLimits returns the resource limits set by the resource limit
+// items of the given config.  Invalid items are skipped and reported by the
+// returned error.
+func NewResourceLimits(table map[string]ctypes.ConfigValue) (ResourceLimits, error) {
+	limits := ResourceLimits{}
+	var err error
+	if v, ok := table[ResourceCPULimitKey]; ok {
+		switch cpu := v.(type) {
+		case ctypes.ConfigValueFloat:
+			limits.CPU = cpu.Value
+		case ctypes.ConfigValueInt:
+			limits.CPU = float64(cpu.Value)
+		}
+		if limits.CPU <= 0 {
+			limits.CPU = 0
+			err = fmt.Errorf("%s must be a positive number", ResourceCPULimitKey)
+		}
+	}
+	if v, ok := table[ResourceMemoryLimitKey]; ok {
+		var mem int64
+		switch t := v.(type) {
+		case ctypes.ConfigValueInt:
+			mem = int64(t.Value) * 1024 * 1024
+		case ctypes.ConfigValueByteSize:
+			mem = t.Value
+		case ctypes.ConfigValueStr:
+			mem, _ = ctypes.ParseByteSize(t.Value)
+		}
+		if mem > 0 {
+			limits.Memory = uint64(mem)
+		} else {
+			err = fmt.Errorf("%s must be a positive byte size (e.g. \"512MB\") or integer (megabytes)", ResourceMemoryLimitKey)
+		}
+	}
+	return limits, err
+}
+
+// resourceLimiter enforces the resource limits of a plugin process, the
+// implementation depends on the platform
+type resourceLimiter interface {
+	// prepare is called before the process is started
+	prepare(cmd *exec.Cmd) error
+	// apply is called once the process is started
+	apply(pid int) error
+	// memoryLimitExceeded returns true once the process exceeded its memory limit
+	memoryLimitExceeded() bool
+	// release is called once the process exited
+	release() error
+}
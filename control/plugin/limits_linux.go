@@ -0,0 +1,231 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// cgroupParent is the cgroup the cgroups of the plugins are created in
+	cgroupParent = "snap"
+	// cpuPeriod is the CFS period the CPU limit is enforced over (100ms)
+	cpuPeriod = 100000
+)
+
+// cgroupRoot is the mount point of the cgroup file system
+var cgroupRoot = "/sys/fs/cgroup"
+
+// cgroupLimiter runs a plugin in its own cgroup, the memory controller kills
+// the plugin (and not any other process of the host) when it exceeds its
+// memory limit.  Both the unified (v2) and the legacy (v1) hierarchies are
+// supported.
+type cgroupLimiter struct {
+	name   string
+	limits ResourceLimits
+	// the cgroups created for the plugin
+	dirs []string
+	// unified is true on the unified (v2) hierarchy
+	unified bool
+}
+
+func newResourceLimiter(name string, limits ResourceLimits) (resourceLimiter, error) {
+	if _, err := os.Stat(cgroupRoot); err != nil {
+		return nil, ErrResourceLimitsUnsupported
+	}
+	_, err := os.Stat(filepath.Join(cgroupRoot, "cgroup.controllers"))
+	return &cgroupLimiter{
+		name:    name,
+		limits:  limits,
+		unified: err == nil,
+	}, nil
+}
+
+func (c *cgroupLimiter) prepare(*exec.Cmd) error {
+	return nil
+}
+
+func (c *cgroupLimiter) apply(pid int) error {
+	name := fmt.Sprintf("%s-%d", sanitizeCgroupName(c.name), pid)
+	var err error
+	if c.unified {
+		err = c.applyUnified(name, pid)
+	} else {
+		err = c.applyLegacy(name, pid)
+	}
+	if err != nil {
+		c.release()
+	}
+	return err
+}
+
+func (c *cgroupLimiter) applyUnified(name string, pid int) error {
+	parent := filepath.Join(cgroupRoot, cgroupParent)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return err
+	}
+	// the controllers have to be enabled for the children of every level
+	controllers := c.controllers()
+	for _, dir := range []string{cgroupRoot, parent} {
+		if err := writeCgroupFile(dir, "cgroup.subtree_control", controllers); err != nil {
+			return err
+		}
+	}
+	dir := filepath.Join(parent, name)
+	if err := os.Mkdir(dir, 0755); err != nil {
+		return err
+	}
+	c.dirs = append(c.dirs, dir)
+	if c.limits.Memory > 0 {
+		if err := writeCgroupFile(dir, "memory.max", strconv.FormatUint(c.limits.Memory, 10)); err != nil {
+			return err
+		}
+		// kill all the processes of the plugin when one of them is killed
+		writeCgroupFile(dir, "memory.oom.group", "1")
+	}
+	if c.limits.CPU > 0 {
+		if err := writeCgroupFile(dir, "cpu.max", fmt.Sprintf("%d %d", cpuQuota(c.limits.CPU), cpuPeriod)); err != nil {
+			return err
+		}
+	}
+	return writeCgroupFile(dir, "cgroup.procs", strconv.Itoa(pid))
+}
+
+func (c *cgroupLimiter) applyLegacy(name string, pid int) error {
+	if c.limits.Memory > 0 {
+		dir := filepath.Join(cgroupRoot, "memory", cgroupParent, name)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		c.dirs = append(c.dirs, dir)
+		if err := writeCgroupFile(dir, "memory.limit_in_bytes", strconv.FormatUint(c.limits.Memory, 10)); err != nil {
+			return err
+		}
+		// don't let the plugin swap instead of hitting its limit
+		writeCgroupFile(dir, "memory.memsw.limit_in_bytes", strconv.FormatUint(c.limits.Memory, 10))
+		if err := writeCgroupFile(dir, "cgroup.procs", strconv.Itoa(pid)); err != nil {
+			return err
+		}
+	}
+	if c.limits.CPU > 0 {
+		dir := filepath.Join(cgroupRoot, "cpu", cgroupParent, name)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		c.dirs = append(c.dirs, dir)
+		if err := writeCgroupFile(dir, "cpu.cfs_period_us", strconv.Itoa(cpuPeriod)); err != nil {
+			return err
+		}
+		if err := writeCgroupFile(dir, "cpu.cfs_quota_us", strconv.Itoa(cpuQuota(c.limits.CPU))); err != nil {
+			return err
+		}
+		if err := writeCgroupFile(dir, "cgroup.procs", strconv.Itoa(pid)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// memoryLimitExceeded returns true once the memory controller had to kill a
+// process of the plugin.  Reaching the limit isn't enough, the kernel reclaims
+// memory (e.g. the page cache) before it kills a process.
+func (c *cgroupLimiter) memoryLimitExceeded() bool {
+	if c.limits.Memory == 0 || len(c.dirs) == 0 {
+		return false
+	}
+	if c.unified {
+		events := readCgroupKeys(filepath.Join(c.dirs[0], "memory.events"))
+		return events["oom_kill"] > 0
+	}
+	return readCgroupKeys(filepath.Join(c.dirs[0], "memory.oom_control"))["oom_kill"] > 0
+}
+
+// release removes the cgroups of the plugin, the plugin must have exited
+func (c *cgroupLimiter) release() error {
+	var err error
+	for _, dir := range c.dirs {
+		if e := os.Remove(dir); e != nil && !os.IsNotExist(e) {
+			err = e
+		}
+	}
+	c.dirs = nil
+	return err
+}
+
+func (c *cgroupLimiter) controllers() string {
+	var controllers []string
+	if c.limits.CPU > 0 {
+		controllers = append(controllers, "+cpu")
+	}
+	if c.limits.Memory > 0 {
+		controllers = append(controllers, "+memory")
+	}
+	return strings.Join(controllers, " ")
+}
+
+func cpuQuota(cpu float64) int {
+	quota := int(cpu * cpuPeriod)
+	// the kernel refuses quotas below 1ms
+	if quota < 1000 {
+		quota = 1000
+	}
+	return quota
+}
+
+func writeCgroupFile(dir, file, value string) error {
+	return ioutil.WriteFile(filepath.Join(dir, file), []byte(value), 0644)
+}
+
+// readCgroupKeys reads a flat keyed cgroup file ("<key> <value>" lines)
+func readCgroupKeys(path string) map[string]uint64 {
+	keys := map[string]uint64{}
+	f, err := os.Open(path)
+	if err != nil {
+		return keys
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		if v, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+			keys[fields[0]] = v
+		}
+	}
+	return keys
+}
+
+func sanitizeCgroupName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == ' ' || r == ':' {
+			return '-'
+		}
+		return r
+	}, name)
+}
@@ -0,0 +1,136 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/intelsdi-x/snap/core/ctypes"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNewResourceLimits(t *testing.T) {
+	Convey("NewResourceLimits", t, func() {
+		Convey("returns no limits when no resource item is set", func() {
+			limits, err := NewResourceLimits(map[string]ctypes.ConfigValue{
+				"user": ctypes.ConfigValueStr{Value: "jane"},
+			})
+			So(err, ShouldBeNil)
+			So(limits.IsEmpty(), ShouldBeTrue)
+		})
+
+		Convey("reads the resource items", func() {
+			limits, err := NewResourceLimits(map[string]ctypes.ConfigValue{
+				ResourceCPULimitKey:    ctypes.ConfigValueFloat{Value: 0.5},
+				ResourceMemoryLimitKey: ctypes.ConfigValueInt{Value: 64},
+			})
+			So(err, ShouldBeNil)
+			So(limits, ShouldResemble, ResourceLimits{CPU: 0.5, Memory: 64 * 1024 * 1024})
+		})
+
+		Convey("reads the memory limit as a byte size", func() {
+			limits, err := NewResourceLimits(map[string]ctypes.ConfigValue{
+				ResourceMemoryLimitKey: ctypes.ConfigValueStr{Value: "512MiB"},
+			})
+			So(err, ShouldBeNil)
+			So(limits, ShouldResemble, ResourceLimits{Memory: 512 * 1024 * 1024})
+			limits, err = NewResourceLimits(map[string]ctypes.ConfigValue{
+				ResourceMemoryLimitKey: ctypes.ConfigValueByteSize{Value: 1000000},
+			})
+			So(err, ShouldBeNil)
+			So(limits, ShouldResemble, ResourceLimits{Memory: 1000000})
+		})
+
+		Convey("skips invalid resource items", func() {
+			limits, err := NewResourceLimits(map[string]ctypes.ConfigValue{
+				ResourceCPULimitKey:    ctypes.ConfigValueInt{Value: 2},
+				ResourceMemoryLimitKey: ctypes.ConfigValueStr{Value: "lots"},
+			})
+			So(err, ShouldNotBeNil)
+			So(limits, ShouldResemble, ResourceLimits{CPU: 2})
+		})
+	})
+}
+
+func TestCgroupLimiter(t *testing.T) {
+	Convey("cgroupLimiter", t, func() {
+		root, err := ioutil.TempDir("", "snap-cgroup")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(root)
+		defaultRoot := cgroupRoot
+		cgroupRoot = root
+		defer func() { cgroupRoot = defaultRoot }()
+		limits := ResourceLimits{CPU: 0.5, Memory: 64 * 1024 * 1024}
+
+		Convey("creates a cgroup with the limits on the unified hierarchy", func() {
+			So(ioutil.WriteFile(filepath.Join(root, "cgroup.controllers"), []byte("cpu memory"), 0644), ShouldBeNil)
+			limiter, err := newResourceLimiter("snap-plugin-collector-mock", limits)
+			So(err, ShouldBeNil)
+			So(limiter.apply(42), ShouldBeNil)
+			dir := filepath.Join(root, cgroupParent, "snap-plugin-collector-mock-42")
+			So(readFile(filepath.Join(root, cgroupParent, "cgroup.subtree_control")), ShouldEqual, "+cpu +memory")
+			So(readFile(filepath.Join(dir, "memory.max")), ShouldEqual, "67108864")
+			So(readFile(filepath.Join(dir, "cpu.max")), ShouldEqual, "50000 100000")
+			So(readFile(filepath.Join(dir, "cgroup.procs")), ShouldEqual, "42")
+			So(limiter.memoryLimitExceeded(), ShouldBeFalse)
+
+			Convey("and reports the plugin exceeded its memory limit", func() {
+				So(ioutil.WriteFile(filepath.Join(dir, "memory.events"), []byte("low 0\nhigh 0\nmax 3\noom 0\noom_kill 0\n"), 0644), ShouldBeNil)
+				So(limiter.memoryLimitExceeded(), ShouldBeFalse)
+				So(ioutil.WriteFile(filepath.Join(dir, "memory.events"), []byte("low 0\nhigh 0\nmax 3\noom 1\noom_kill 1\n"), 0644), ShouldBeNil)
+				So(limiter.memoryLimitExceeded(), ShouldBeTrue)
+			})
+		})
+
+		Convey("creates a cgroup per controller on the legacy hierarchy", func() {
+			limiter, err := newResourceLimiter("snap-plugin-collector-mock", limits)
+			So(err, ShouldBeNil)
+			So(limiter.apply(42), ShouldBeNil)
+			memDir := filepath.Join(root, "memory", cgroupParent, "snap-plugin-collector-mock-42")
+			cpuDir := filepath.Join(root, "cpu", cgroupParent, "snap-plugin-collector-mock-42")
+			So(readFile(filepath.Join(memDir, "memory.limit_in_bytes")), ShouldEqual, "67108864")
+			So(readFile(filepath.Join(cpuDir, "cpu.cfs_quota_us")), ShouldEqual, "50000")
+			So(readFile(filepath.Join(cpuDir, "cgroup.procs")), ShouldEqual, "42")
+			So(limiter.memoryLimitExceeded(), ShouldBeFalse)
+
+			Convey("and reports the plugin exceeded its memory limit", func() {
+				So(ioutil.WriteFile(filepath.Join(memDir, "memory.max_usage_in_bytes"), []byte("67108864\n"), 0644), ShouldBeNil)
+				So(ioutil.WriteFile(filepath.Join(memDir, "memory.oom_control"), []byte("oom_kill_disable 0\nunder_oom 0\noom_kill 0\n"), 0644), ShouldBeNil)
+				So(limiter.memoryLimitExceeded(), ShouldBeFalse)
+				So(ioutil.WriteFile(filepath.Join(memDir, "memory.oom_control"), []byte("oom_kill_disable 0\nunder_oom 0\noom_kill 1\n"), 0644), ShouldBeNil)
+				So(limiter.memoryLimitExceeded(), ShouldBeTrue)
+			})
+		})
+	})
+}
+
+func readFile(path string) string {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
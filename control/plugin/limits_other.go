@@ -0,0 +1,71 @@
+// +build !linux,!windows
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"os/exec"
+	"strconv"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// rlimitLimiter caps the virtual memory of a plugin with an rlimit set by a
+// shell before it execs the plugin, the CPU limit can't be enforced this way.
+type rlimitLimiter struct {
+	name   string
+	limits ResourceLimits
+}
+
+func newResourceLimiter(name string, limits ResourceLimits) (resourceLimiter, error) {
+	if limits.CPU > 0 {
+		execLogger.WithFields(log.Fields{
+			"_block": "newResourceLimiter",
+			"plugin": name,
+		}).Warn("plugin CPU limit is not supported on this platform, ignoring it")
+	}
+	return &rlimitLimiter{name: name, limits: limits}, nil
+}
+
+func (r *rlimitLimiter) prepare(cmd *exec.Cmd) error {
+	if r.limits.Memory == 0 {
+		return nil
+	}
+	// ulimit -v takes kilobytes, "$0" and "$@" are the plugin and its arguments
+	script := "ulimit -v " + strconv.FormatUint(r.limits.Memory/1024, 10) + ` && exec "$0" "$@"`
+	cmd.Args = append([]string{"/bin/sh", "-c", script}, cmd.Args...)
+	cmd.Path = "/bin/sh"
+	return nil
+}
+
+func (r *rlimitLimiter) apply(int) error {
+	return nil
+}
+
+// memoryLimitExceeded always returns false, the allocations above the limit
+// fail in the plugin which exits and is reported by the health checks.
+func (r *rlimitLimiter) memoryLimitExceeded() bool {
+	return false
+}
+
+func (r *rlimitLimiter) release() error {
+	return nil
+}
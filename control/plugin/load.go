@@ -0,0 +1,46 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import "time"
+
+// Load is the load of a running instance of a plugin, reported by the plugin
+// in its replies to the pings of the health checks.  The plugins which don't
+// report their load report a zero load.
+type Load struct {
+	// InFlight is the number of calls the instance is serving
+	InFlight int64
+	// LastLatency is the time the instance took to serve its last call
+	LastLatency time.Duration
+	// Memory is the memory used by the instance in bytes
+	Memory int64
+}
+
+// Less returns whether the load is lower than the other: the instance serves
+// fewer calls, then served its last call faster, then uses less memory
+func (l Load) Less(o Load) bool {
+	if l.InFlight != o.InFlight {
+		return l.InFlight < o.InFlight
+	}
+	if l.LastLatency != o.LastLatency {
+		return l.LastLatency < o.LastLatency
+	}
+	return l.Memory < o.Memory
+}
@@ -38,8 +38,24 @@ const (
 	SnapGOBContentType = "snap.gob"
 	// SnapJSON snap metrics serialized into json
 	SnapJSONContentType = "snap.json"
+	// SnapProtobuf snap metrics serialized into protocol buffers, the
+	// messages of the gRPC protocol
+	SnapProtobufContentType = "snap.pb"
 )
 
+// MetricsContentType returns the content type the metrics are sent in to a
+// plugin accepting the content types: protocol buffers when it lists them, gob
+// otherwise, snap.* included since the plugins built before protocol buffers
+// were supported only decode gob
+func MetricsContentType(accepted []string) string {
+	for _, t := range accepted {
+		if t == SnapProtobufContentType {
+			return SnapProtobufContentType
+		}
+	}
+	return SnapGOBContentType
+}
+
 type ConfigType struct {
 	*cdata.ConfigDataNode
 }
@@ -106,6 +122,10 @@ type MetricType struct {
 	// metric catalog and not sent through  collect -> process -> publish.
 	Description_ string `json:"description"`
 
+	// DataType describes the type of the collected data (e.g. "uint64", "float64", "string").
+	// Like the description it is stored on the metric catalog.
+	DataType_ string `json:"data_type"`
+
 	// The timestamp from when the metric was created.
 	Timestamp_ time.Time `json:"timestamp"`
 }
@@ -155,6 +175,11 @@ func (p MetricType) Unit() string {
 	return p.Unit_
 }
 
+// returns the type of the metric data
+func (p MetricType) DataType() string {
+	return p.DataType_
+}
+
 func (p *MetricType) AddData(data interface{}) {
 	p.Data_ = data
 }
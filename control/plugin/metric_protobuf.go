@@ -0,0 +1,190 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/intelsdi-x/snap/control/plugin/rpc"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/cdata"
+	"github.com/intelsdi-x/snap/core/ctypes"
+)
+
+// marshalProtobuf serializes the metrics into the MetricsArg message of the
+// gRPC protocol
+func marshalProtobuf(metrics []MetricType) ([]byte, error) {
+	arg := &rpc.MetricsArg{Metrics: make([]*rpc.Metric, len(metrics))}
+	for i, m := range metrics {
+		mt, err := toProtobufMetric(m)
+		if err != nil {
+			return nil, err
+		}
+		arg.Metrics[i] = mt
+	}
+	return proto.Marshal(arg)
+}
+
+// unmarshalProtobuf deserializes metrics serialized by marshalProtobuf
+func unmarshalProtobuf(payload []byte) ([]MetricType, error) {
+	arg := &rpc.MetricsArg{}
+	if err := proto.Unmarshal(payload, arg); err != nil {
+		return nil, err
+	}
+	metrics := make([]MetricType, len(arg.Metrics))
+	for i, mt := range arg.Metrics {
+		metrics[i] = fromProtobufMetric(mt)
+	}
+	return metrics, nil
+}
+
+func toProtobufMetric(m MetricType) (*rpc.Metric, error) {
+	mt := &rpc.Metric{
+		Namespace:          make([]*rpc.NamespaceElement, len(m.Namespace_)),
+		Version:            int64(m.Version_),
+		Tags:               m.Tags_,
+		Timestamp:          &rpc.Time{Sec: m.Timestamp_.Unix(), Nsec: int64(m.Timestamp_.Nanosecond())},
+		LastAdvertisedTime: &rpc.Time{Sec: m.LastAdvertisedTime_.Unix(), Nsec: int64(m.LastAdvertisedTime_.Nanosecond())},
+		Unit:               m.Unit_,
+		Description:        m.Description_,
+		DataType:           m.DataType_,
+	}
+	for i, e := range m.Namespace_ {
+		mt.Namespace[i] = &rpc.NamespaceElement{Value: e.Value, Description: e.Description, Name: e.Name}
+	}
+	if m.Config_ != nil {
+		mt.Config = toProtobufConfig(m.Config_.Table())
+	}
+	switch d := m.Data_.(type) {
+	case string:
+		mt.Data = &rpc.Metric_StringData{StringData: d}
+	case float64:
+		mt.Data = &rpc.Metric_Float64Data{Float64Data: d}
+	case float32:
+		mt.Data = &rpc.Metric_Float32Data{Float32Data: d}
+	case int32:
+		mt.Data = &rpc.Metric_Int32Data{Int32Data: d}
+	case int:
+		mt.Data = &rpc.Metric_Int64Data{Int64Data: int64(d)}
+	case int64:
+		mt.Data = &rpc.Metric_Int64Data{Int64Data: d}
+	case uint32:
+		mt.Data = &rpc.Metric_Uint32Data{Uint32Data: d}
+	case uint64:
+		mt.Data = &rpc.Metric_Uint64Data{Uint64Data: d}
+	case []byte:
+		mt.Data = &rpc.Metric_BytesData{BytesData: d}
+	case bool:
+		mt.Data = &rpc.Metric_BoolData{BoolData: d}
+	case nil:
+	default:
+		return nil, fmt.Errorf("unsupported data type %T of metric %s", d, m.Namespace().String())
+	}
+	return mt, nil
+}
+
+func fromProtobufMetric(mt *rpc.Metric) MetricType {
+	m := MetricType{
+		Namespace_:   make(core.Namespace, len(mt.Namespace)),
+		Version_:     int(mt.Version),
+		Tags_:        mt.Tags,
+		Unit_:        mt.Unit,
+		Description_: mt.Description,
+		DataType_:    mt.DataType,
+	}
+	for i, e := range mt.Namespace {
+		m.Namespace_[i] = core.NamespaceElement{Value: e.Value, Description: e.Description, Name: e.Name}
+	}
+	if mt.Timestamp != nil {
+		m.Timestamp_ = time.Unix(mt.Timestamp.Sec, mt.Timestamp.Nsec)
+	}
+	if mt.LastAdvertisedTime != nil {
+		m.LastAdvertisedTime_ = time.Unix(mt.LastAdvertisedTime.Sec, mt.LastAdvertisedTime.Nsec)
+	}
+	if mt.Config != nil {
+		m.Config_ = cdata.FromTable(fromProtobufConfig(mt.Config))
+	}
+	switch d := mt.Data.(type) {
+	case *rpc.Metric_StringData:
+		m.Data_ = d.StringData
+	case *rpc.Metric_Float64Data:
+		m.Data_ = d.Float64Data
+	case *rpc.Metric_Float32Data:
+		m.Data_ = d.Float32Data
+	case *rpc.Metric_Int32Data:
+		m.Data_ = d.Int32Data
+	case *rpc.Metric_Int64Data:
+		m.Data_ = d.Int64Data
+	case *rpc.Metric_Uint32Data:
+		m.Data_ = d.Uint32Data
+	case *rpc.Metric_Uint64Data:
+		m.Data_ = d.Uint64Data
+	case *rpc.Metric_BytesData:
+		m.Data_ = d.BytesData
+	case *rpc.Metric_BoolData:
+		m.Data_ = d.BoolData
+	}
+	return m
+}
+
+func toProtobufConfig(table map[string]ctypes.ConfigValue) *rpc.ConfigMap {
+	cm := &rpc.ConfigMap{
+		IntMap:    map[string]int64{},
+		FloatMap:  map[string]float64{},
+		StringMap: map[string]string{},
+		BoolMap:   map[string]bool{},
+	}
+	for k, v := range table {
+		switch v := v.(type) {
+		case ctypes.ConfigValueInt:
+			cm.IntMap[k] = int64(v.Value)
+		case ctypes.ConfigValueFloat:
+			cm.FloatMap[k] = v.Value
+		case ctypes.ConfigValueStr:
+			cm.StringMap[k] = v.Value
+		case ctypes.ConfigValueBool:
+			cm.BoolMap[k] = v.Value
+		case fmt.Stringer:
+			// durations and byte sizes, in their string form like in the
+			// gRPC protocol
+			cm.StringMap[k] = v.String()
+		}
+	}
+	return cm
+}
+
+func fromProtobufConfig(cm *rpc.ConfigMap) map[string]ctypes.ConfigValue {
+	table := map[string]ctypes.ConfigValue{}
+	for k, v := range cm.IntMap {
+		table[k] = ctypes.ConfigValueInt{Value: int(v)}
+	}
+	for k, v := range cm.FloatMap {
+		table[k] = ctypes.ConfigValueFloat{Value: v}
+	}
+	for k, v := range cm.StringMap {
+		table[k] = ctypes.ConfigValueStr{Value: v}
+	}
+	for k, v := range cm.BoolMap {
+		table[k] = ctypes.ConfigValueBool{Value: v}
+	}
+	return table
+}
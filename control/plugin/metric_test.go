@@ -22,6 +22,7 @@ limitations under the License.
 package plugin
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -244,4 +245,89 @@ func TestMetric(t *testing.T) {
 		So(e.Error(), ShouldResemble, "invalid snap content type for unmarshalling: snap.wat")
 		So(b, ShouldBeNil)
 	})
+
+	Convey("marshall using snap.pb", t, func() {
+		now := time.Now()
+		m := []MetricType{
+			*NewMetricType(core.NewNamespace("foo", "bar"), now, map[string]string{"host": "a"}, "B", 1),
+			*NewMetricType(core.NewNamespace("foo", "baz"), now, nil, "", "2"),
+		}
+		configNewNode := cdata.NewNode()
+		configNewNode.AddItem("user", ctypes.ConfigValueStr{Value: "foo"})
+		m[0].Config_ = configNewNode
+		a, c, e := MarshalMetricTypes("snap.pb", m)
+		So(e, ShouldBeNil)
+		So(len(a), ShouldBeGreaterThan, 0)
+		So(c, ShouldEqual, "snap.pb")
+
+		Convey("unmarshal snap.pb", func() {
+			m, e = UnmarshallMetricTypes("snap.pb", a)
+			So(e, ShouldBeNil)
+			So(m[0].Namespace().String(), ShouldResemble, "/foo/bar")
+			So(m[0].Data(), ShouldResemble, int64(1))
+			So(m[0].Tags(), ShouldResemble, map[string]string{"host": "a"})
+			So(m[0].Unit_, ShouldEqual, "B")
+			So(m[0].Timestamp_.Equal(now), ShouldBeTrue)
+			So(m[0].Config().Table()["user"], ShouldResemble, ctypes.ConfigValueStr{Value: "foo"})
+			So(m[1].Namespace().String(), ShouldResemble, "/foo/baz")
+			So(m[1].Data(), ShouldResemble, "2")
+		})
+
+		Convey("error on unsupported data", func() {
+			m[1].Data_ = struct{}{}
+			a, c, e = MarshalMetricTypes("snap.pb", m)
+			So(e, ShouldNotBeNil)
+			So(a, ShouldBeNil)
+		})
+	})
+
+	Convey("metrics are sent in snap.pb to the plugins listing it", t, func() {
+		So(MetricsContentType([]string{SnapGOBContentType, SnapProtobufContentType}), ShouldEqual, SnapProtobufContentType)
+		So(MetricsContentType([]string{SnapAllContentType}), ShouldEqual, SnapGOBContentType)
+		So(MetricsContentType(nil), ShouldEqual, SnapGOBContentType)
+	})
+}
+
+// benchmarkMetrics returns a large batch of metrics like the ones of a
+// collection with high cardinality
+func benchmarkMetrics() []MetricType {
+	now := time.Now()
+	m := make([]MetricType, 10000)
+	for i := range m {
+		m[i] = *NewMetricType(core.NewNamespace("intel", "bench", fmt.Sprintf("host%d", i%100), "cpu", fmt.Sprintf("%d", i)), now, map[string]string{"plugin_running_on": "localhost"}, "ns", float64(i))
+	}
+	return m
 }
+
+func benchmarkMarshal(b *testing.B, contentType string) {
+	m := benchmarkMetrics()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		payload, _, err := MarshalMetricTypes(contentType, m)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.SetBytes(int64(len(payload)))
+	}
+}
+
+func benchmarkUnmarshal(b *testing.B, contentType string) {
+	payload, _, err := MarshalMetricTypes(contentType, benchmarkMetrics())
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(payload)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := UnmarshallMetricTypes(contentType, payload); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMarshalGOB(b *testing.B)        { benchmarkMarshal(b, SnapGOBContentType) }
+func BenchmarkMarshalJSON(b *testing.B)       { benchmarkMarshal(b, SnapJSONContentType) }
+func BenchmarkMarshalProtobuf(b *testing.B)   { benchmarkMarshal(b, SnapProtobufContentType) }
+func BenchmarkUnmarshalGOB(b *testing.B)      { benchmarkUnmarshal(b, SnapGOBContentType) }
+func BenchmarkUnmarshalJSON(b *testing.B)     { benchmarkUnmarshal(b, SnapJSONContentType) }
+func BenchmarkUnmarshalProtobuf(b *testing.B) { benchmarkUnmarshal(b, SnapProtobufContentType) }
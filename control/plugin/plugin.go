@@ -61,6 +61,9 @@ const (
 	// Using this strategy enables a running database plugin that has the same connection info between
 	// two tasks to be shared.
 	ConfigRouting
+	// LeastLoadedRouting is routing to the running instance reporting the
+	// lowest load in its replies to the pings.
+	LeastLoadedRouting
 )
 
 // Plugin response states
@@ -78,6 +81,9 @@ const (
 	NativeRPC  RPCType = 0
 	GRPC       RPCType = 2
 	STREAMGRPC RPCType = 3
+	// InProcessRPC is the RPC type of the plugins compiled into snapteld and
+	// called directly, it can't be negotiated by the plugins
+	InProcessRPC RPCType = -1
 )
 
 var (
@@ -98,6 +104,7 @@ var (
 		"least-recently-used",
 		"sticky",
 		"config",
+		"least-loaded",
 	}
 )
 
@@ -134,6 +141,9 @@ type PluginMeta struct {
 	RoutingStrategy RoutingStrategyType
 	// TLSEnabled identifies status of plugin security
 	TLSEnabled bool
+	// Capabilities are the optional features of the plugin protocol the
+	// plugin supports, nil when it doesn't advertise them
+	Capabilities *Capabilities
 }
 
 // Arg contains arguments passed to startup of Plugin
@@ -154,6 +164,14 @@ type Arg struct {
 	KeyPath     string `json:"KeyPath"`
 	CACertPaths string `json:"RootCertPaths"`
 	TLSEnabled  bool   `json:"TLSEnabled"`
+
+	// Capabilities are the optional features of the plugin protocol
+	// snapteld supports
+	Capabilities *Capabilities `json:"Capabilities,omitempty"`
+	// PipeName is the path of the named pipe the plugin listens on instead
+	// of a TCP port, snapteld only sets it on Windows. The plugins secured
+	// with TLS keep listening on a TCP port.
+	PipeName string `json:"PipeName,omitempty"`
 }
 
 // SetCertPath sets path to TLS certificate in plugin arguments
@@ -182,10 +200,12 @@ func (a Arg) SetCACertPaths(caCertPaths string) Arg {
 
 // NewArg returns new plugin arguments structure
 func NewArg(logLevel int, pprof bool) Arg {
+	capabilities := SnapteldCapabilities
 	return Arg{
 		LogLevel:            log.Level(logLevel),
 		PingTimeoutDuration: PingTimeoutDurationDefault,
 		Pprof:               pprof,
+		Capabilities:        &capabilities,
 	}
 }
 
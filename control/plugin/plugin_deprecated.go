@@ -33,6 +33,8 @@ import (
 	"time"
 
 	log "github.com/Sirupsen/logrus"
+
+	"github.com/intelsdi-x/snap/pkg/rpcutil"
 )
 
 var (
@@ -199,7 +201,13 @@ func Start(m *PluginMeta, c Plugin, requestString string) (error, int) {
 		}
 	}
 
-	l, err := net.Listen("tcp", "127.0.0.1:"+s.ListenPort())
+	var l net.Listener
+	var err error
+	if s.PipeName != "" && !s.TLSEnabled {
+		l, err = rpcutil.ListenPipe(s.PipeName)
+	} else {
+		l, err = net.Listen("tcp", "127.0.0.1:"+s.ListenPort())
+	}
 	if err != nil {
 		s.Logger().Error(err.Error())
 		panic(err)
@@ -1,6 +1,7 @@
 package rpc
 
 import (
+	"fmt"
 	"strings"
 
 	log "github.com/Sirupsen/logrus"
@@ -18,14 +19,15 @@ var (
 // NewGetConfigPolicyReply given a config *cpolicy.ConfigPolicy returns a GetConfigPolicyReply.
 func NewGetConfigPolicyReply(policy *cpolicy.ConfigPolicy) (*GetConfigPolicyReply, error) {
 	ret := &GetConfigPolicyReply{
-		BoolPolicy:    map[string]*BoolPolicy{},
-		FloatPolicy:   map[string]*FloatPolicy{},
-		IntegerPolicy: map[string]*IntegerPolicy{},
-		StringPolicy:  map[string]*StringPolicy{},
+		BoolPolicy:       map[string]*BoolPolicy{},
+		FloatPolicy:      map[string]*FloatPolicy{},
+		IntegerPolicy:    map[string]*IntegerPolicy{},
+		StringPolicy:     map[string]*StringPolicy{},
+		ConstraintPolicy: map[string]*ConstraintPolicy{},
 	}
 
 	for _, node := range policy.GetAll() {
-		key := strings.Join(node.Key, ".")
+		key := policyKey(node.Key)
 
 		for _, rule := range node.RulesAsTable() {
 			switch rule.Type {
@@ -45,11 +47,33 @@ func NewGetConfigPolicyReply(policy *cpolicy.ConfigPolicy) (*GetConfigPolicyRepl
 				ret.BoolPolicy[key].Rules[rule.Name] = r
 			case cpolicy.StringType:
 				r := &StringRule{
-					Required: rule.Required,
+					Required:  rule.Required,
+					Sensitive: rule.Sensitive,
+					Pattern:   rule.Pattern,
 				}
 				if rule.Default != nil {
 					r.Default = rule.Default.(ctypes.ConfigValueStr).Value
 				}
+				for _, c := range rule.Choices {
+					r.Choices = append(r.Choices, c.(string))
+				}
+				if ret.StringPolicy[key] == nil {
+					ret.StringPolicy[key] = &StringPolicy{
+						Rules: map[string]*StringRule{},
+						Key:   node.Key,
+					}
+				}
+				ret.StringPolicy[key].Rules[rule.Name] = r
+			case cpolicy.DurationType, cpolicy.ByteSizeType:
+				// the durations and the byte sizes are sent as string rules
+				// of their string form, e.g. "30s" or "512MB"
+				r := &StringRule{
+					Required: rule.Required,
+				}
+				if rule.Default != nil {
+					r.Default = rule.Default.(fmt.Stringer).String()
+					r.HasDefault = true
+				}
 				if ret.StringPolicy[key] == nil {
 					ret.StringPolicy[key] = &StringPolicy{
 						Rules: map[string]*StringRule{},
@@ -70,6 +94,9 @@ func NewGetConfigPolicyReply(policy *cpolicy.ConfigPolicy) (*GetConfigPolicyRepl
 				if rule.Minimum != nil {
 					r.Minimum = int64(rule.Minimum.(ctypes.ConfigValueInt).Value)
 				}
+				for _, c := range rule.Choices {
+					r.Choices = append(r.Choices, int64(c.(int)))
+				}
 				if ret.IntegerPolicy[key] == nil {
 					ret.IntegerPolicy[key] = &IntegerPolicy{
 						Rules: map[string]*IntegerRule{},
@@ -90,6 +117,9 @@ func NewGetConfigPolicyReply(policy *cpolicy.ConfigPolicy) (*GetConfigPolicyRepl
 				if rule.Minimum != nil {
 					r.Minimum = rule.Minimum.(ctypes.ConfigValueFloat).Value
 				}
+				for _, c := range rule.Choices {
+					r.Choices = append(r.Choices, c.(float64))
+				}
 				if ret.FloatPolicy[key] == nil {
 					ret.FloatPolicy[key] = &FloatPolicy{
 						Rules: map[string]*FloatRule{},
@@ -100,6 +130,30 @@ func NewGetConfigPolicyReply(policy *cpolicy.ConfigPolicy) (*GetConfigPolicyRepl
 			}
 
 		}
+
+		for _, c := range node.Constraints() {
+			r := &Constraint{
+				Keys:  c.Keys(),
+				IfKey: c.IfKey(),
+			}
+			switch v := c.IfValue().(type) {
+			case bool:
+				r.IfType = "bool"
+				r.IfBool = v
+			case float64:
+				r.IfType = "number"
+				r.IfNumber = v
+			case string:
+				r.IfType = "string"
+				r.IfString = v
+			}
+			if ret.ConstraintPolicy[key] == nil {
+				ret.ConstraintPolicy[key] = &ConstraintPolicy{
+					Key: node.Key,
+				}
+			}
+			ret.ConstraintPolicy[key].Constraints = append(ret.ConstraintPolicy[key].Constraints, r)
+		}
 	}
 	return ret, nil
 }
@@ -143,6 +197,15 @@ func ToConfigPolicy(reply *GetConfigPolicyReply) *cpolicy.ConfigPolicy {
 				rpcLogger.Warn("Empty key found with value %v", val)
 				continue
 			}
+			sr.SetSensitive(val.Sensitive)
+			if val.Pattern != "" {
+				if err := sr.SetPattern(val.Pattern); err != nil {
+					rpcLogger.Warnf("Invalid pattern of %v: %v", key, err)
+				}
+			}
+			if len(val.Choices) > 0 {
+				sr.SetChoices(val.Choices...)
+			}
 
 			nodes[k].Add(sr)
 		}
@@ -170,6 +233,13 @@ func ToConfigPolicy(reply *GetConfigPolicyReply) *cpolicy.ConfigPolicy {
 			if val.HasMax {
 				ir.SetMaximum(int(val.Maximum))
 			}
+			if len(val.Choices) > 0 {
+				choices := make([]int, len(val.Choices))
+				for i, c := range val.Choices {
+					choices[i] = int(c)
+				}
+				ir.SetChoices(choices...)
+			}
 
 			nodes[k].Add(ir)
 		}
@@ -199,15 +269,44 @@ func ToConfigPolicy(reply *GetConfigPolicyReply) *cpolicy.ConfigPolicy {
 			if val.HasMax {
 				fr.SetMaximum(val.Maximum)
 			}
+			if len(val.Choices) > 0 {
+				fr.SetChoices(val.Choices...)
+			}
 
 			nodes[k].Add(fr)
 		}
 	}
 
+	for k, v := range reply.ConstraintPolicy {
+		if _, ok := nodes[k]; !ok {
+			nodes[k] = cpolicy.NewPolicyNode()
+		}
+		for _, val := range v.Constraints {
+			var c *cpolicy.Constraint
+			var err error
+			switch val.IfType {
+			case "bool":
+				c, err = cpolicy.NewRequiredIf(val.IfKey, val.IfBool, val.Keys...)
+			case "number":
+				c, err = cpolicy.NewRequiredIf(val.IfKey, val.IfNumber, val.Keys...)
+			case "string":
+				c, err = cpolicy.NewRequiredIf(val.IfKey, val.IfString, val.Keys...)
+			default:
+				c, err = cpolicy.NewRequiredTogether(val.Keys...)
+			}
+			if err != nil {
+				rpcLogger.Warnf("Invalid constraint found with value %v", val)
+				continue
+			}
+			nodes[k].AddConstraints(c)
+		}
+	}
+
 	for key, node := range nodes {
 		var keys []string
 		// if the []string is present, use it.
 		// if not, fall back to dot separated key
+		// (see policyKey)
 		if val, ok := reply.BoolPolicy[key]; ok && val != nil && val.Key != nil {
 			keys = val.Key
 		} else if val, ok := reply.StringPolicy[key]; ok && val != nil && val.Key != nil {
@@ -216,11 +315,48 @@ func ToConfigPolicy(reply *GetConfigPolicyReply) *cpolicy.ConfigPolicy {
 			keys = val.Key
 		} else if val, ok := reply.IntegerPolicy[key]; ok && val != nil && val.Key != nil {
 			keys = val.Key
+		} else if val, ok := reply.ConstraintPolicy[key]; ok && val != nil && val.Key != nil {
+			keys = val.Key
 		} else {
-			keys = strings.Split(key, ".")
+			keys = splitPolicyKey(key)
 		}
 		result.Add(keys, node)
 	}
 
 	return result
 }
+
+// policyKey returns the key of the policies of the namespace ns. The elements
+// of ns are joined with "." after escaping the dots and the backslashes they
+// contain, so namespaces like ["a.b", "c"] and ["a", "b.c"] don't share a key.
+func policyKey(ns []string) string {
+	elems := make([]string, len(ns))
+	for i, e := range ns {
+		e = strings.Replace(e, `\`, `\\`, -1)
+		elems[i] = strings.Replace(e, ".", `\.`, -1)
+	}
+	return strings.Join(elems, ".")
+}
+
+// splitPolicyKey returns the namespace of a key built by policyKey. Keys sent by
+// older plugins, whose elements were joined with "." without being escaped, are
+// split the same way as long as they contain no backslash.
+func splitPolicyKey(key string) []string {
+	var ns []string
+	var elem []byte
+	for i := 0; i < len(key); i++ {
+		switch key[i] {
+		case '\\':
+			if i+1 < len(key) {
+				i++
+			}
+			elem = append(elem, key[i])
+		case '.':
+			ns = append(ns, string(elem))
+			elem = elem[:0]
+		default:
+			elem = append(elem, key[i])
+		}
+	}
+	return append(ns, string(elem))
+}
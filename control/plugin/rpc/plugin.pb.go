@@ -47,6 +47,8 @@ It has these top-level messages:
 	IntegerPolicy
 	StringRule
 	StringPolicy
+	Constraint
+	ConstraintPolicy
 	MetricsArg
 	MetricsReply
 	GetMetricTypesArg
@@ -84,6 +86,11 @@ type CollectArg struct {
 	MaxMetricsBuffer int64 `protobuf:"varint,3,opt,name=MaxMetricsBuffer,json=maxMetricsBuffer" json:"MaxMetricsBuffer,omitempty"`
 	// Blob of domain specific info
 	Other []byte `protobuf:"bytes,4,opt,name=Other,json=other,proto3" json:"Other,omitempty"`
+	// Ask the plugin to stop sending metrics, snapd can't keep up with them.
+	// The plugin buffers or drops the metrics it collects until resumed.
+	Pause bool `protobuf:"varint,5,opt,name=Pause,json=pause" json:"Pause,omitempty"`
+	// Ask the plugin to send metrics again after a pause
+	Resume bool `protobuf:"varint,6,opt,name=Resume,json=resume" json:"Resume,omitempty"`
 }
 
 func (m *CollectArg) Reset()                    { *m = CollectArg{} }
@@ -134,6 +141,13 @@ func (*Empty) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
 
 type ErrReply struct {
 	Error string `protobuf:"bytes,1,opt,name=error" json:"error,omitempty"`
+	// The load of the instance of the plugin, reported in the replies to Ping
+	// Number of calls the instance is serving
+	InFlight int64 `protobuf:"varint,2,opt,name=in_flight,json=inFlight" json:"in_flight,omitempty"`
+	// Time the instance took to serve its last call in ns
+	LastLatency int64 `protobuf:"varint,3,opt,name=last_latency,json=lastLatency" json:"last_latency,omitempty"`
+	// Memory used by the instance in bytes
+	Memory int64 `protobuf:"varint,4,opt,name=memory" json:"memory,omitempty"`
 }
 
 func (m *ErrReply) Reset()                    { *m = ErrReply{} }
@@ -196,6 +210,7 @@ type Metric struct {
 	Timestamp          *Time               `protobuf:"bytes,6,opt,name=Timestamp,json=timestamp" json:"Timestamp,omitempty"`
 	Unit               string              `protobuf:"bytes,7,opt,name=Unit,json=unit" json:"Unit,omitempty"`
 	Description        string              `protobuf:"bytes,8,opt,name=Description,json=description" json:"Description,omitempty"`
+	DataType           string              `protobuf:"bytes,18,opt,name=DataType,json=dataType" json:"DataType,omitempty"`
 	// Types that are valid to be assigned to Data:
 	//	*Metric_StringData
 	//	*Metric_Float32Data
@@ -580,11 +595,12 @@ func (*KillArg) ProtoMessage()               {}
 func (*KillArg) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
 
 type GetConfigPolicyReply struct {
-	Error         string                    `protobuf:"bytes,1,opt,name=error" json:"error,omitempty"`
-	BoolPolicy    map[string]*BoolPolicy    `protobuf:"bytes,2,rep,name=bool_policy,json=boolPolicy" json:"bool_policy,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	FloatPolicy   map[string]*FloatPolicy   `protobuf:"bytes,3,rep,name=float_policy,json=floatPolicy" json:"float_policy,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	IntegerPolicy map[string]*IntegerPolicy `protobuf:"bytes,4,rep,name=integer_policy,json=integerPolicy" json:"integer_policy,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	StringPolicy  map[string]*StringPolicy  `protobuf:"bytes,5,rep,name=string_policy,json=stringPolicy" json:"string_policy,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Error            string                       `protobuf:"bytes,1,opt,name=error" json:"error,omitempty"`
+	BoolPolicy       map[string]*BoolPolicy       `protobuf:"bytes,2,rep,name=bool_policy,json=boolPolicy" json:"bool_policy,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	FloatPolicy      map[string]*FloatPolicy      `protobuf:"bytes,3,rep,name=float_policy,json=floatPolicy" json:"float_policy,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	IntegerPolicy    map[string]*IntegerPolicy    `protobuf:"bytes,4,rep,name=integer_policy,json=integerPolicy" json:"integer_policy,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	StringPolicy     map[string]*StringPolicy     `protobuf:"bytes,5,rep,name=string_policy,json=stringPolicy" json:"string_policy,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	ConstraintPolicy map[string]*ConstraintPolicy `protobuf:"bytes,6,rep,name=constraint_policy,json=constraintPolicy" json:"constraint_policy,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 }
 
 func (m *GetConfigPolicyReply) Reset()                    { *m = GetConfigPolicyReply{} }
@@ -620,6 +636,13 @@ func (m *GetConfigPolicyReply) GetStringPolicy() map[string]*StringPolicy {
 	return nil
 }
 
+func (m *GetConfigPolicyReply) GetConstraintPolicy() map[string]*ConstraintPolicy {
+	if m != nil {
+		return m.ConstraintPolicy
+	}
+	return nil
+}
+
 type BoolRule struct {
 	Required   bool `protobuf:"varint,1,opt,name=required" json:"required,omitempty"`
 	Default    bool `protobuf:"varint,2,opt,name=default" json:"default,omitempty"`
@@ -649,13 +672,14 @@ func (m *BoolPolicy) GetRules() map[string]*BoolRule {
 }
 
 type FloatRule struct {
-	Required   bool    `protobuf:"varint,1,opt,name=required" json:"required,omitempty"`
-	Minimum    float64 `protobuf:"fixed64,2,opt,name=minimum" json:"minimum,omitempty"`
-	Maximum    float64 `protobuf:"fixed64,3,opt,name=maximum" json:"maximum,omitempty"`
-	Default    float64 `protobuf:"fixed64,4,opt,name=default" json:"default,omitempty"`
-	HasDefault bool    `protobuf:"varint,5,opt,name=has_default,json=hasDefault" json:"has_default,omitempty"`
-	HasMin     bool    `protobuf:"varint,6,opt,name=has_min,json=hasMin" json:"has_min,omitempty"`
-	HasMax     bool    `protobuf:"varint,7,opt,name=has_max,json=hasMax" json:"has_max,omitempty"`
+	Required   bool      `protobuf:"varint,1,opt,name=required" json:"required,omitempty"`
+	Minimum    float64   `protobuf:"fixed64,2,opt,name=minimum" json:"minimum,omitempty"`
+	Maximum    float64   `protobuf:"fixed64,3,opt,name=maximum" json:"maximum,omitempty"`
+	Default    float64   `protobuf:"fixed64,4,opt,name=default" json:"default,omitempty"`
+	HasDefault bool      `protobuf:"varint,5,opt,name=has_default,json=hasDefault" json:"has_default,omitempty"`
+	HasMin     bool      `protobuf:"varint,6,opt,name=has_min,json=hasMin" json:"has_min,omitempty"`
+	HasMax     bool      `protobuf:"varint,7,opt,name=has_max,json=hasMax" json:"has_max,omitempty"`
+	Choices    []float64 `protobuf:"fixed64,8,rep,packed,name=choices" json:"choices,omitempty"`
 }
 
 func (m *FloatRule) Reset()                    { *m = FloatRule{} }
@@ -681,13 +705,14 @@ func (m *FloatPolicy) GetRules() map[string]*FloatRule {
 }
 
 type IntegerRule struct {
-	Required   bool  `protobuf:"varint,1,opt,name=required" json:"required,omitempty"`
-	Minimum    int64 `protobuf:"varint,2,opt,name=minimum" json:"minimum,omitempty"`
-	Maximum    int64 `protobuf:"varint,3,opt,name=maximum" json:"maximum,omitempty"`
-	Default    int64 `protobuf:"varint,4,opt,name=default" json:"default,omitempty"`
-	HasDefault bool  `protobuf:"varint,5,opt,name=has_default,json=hasDefault" json:"has_default,omitempty"`
-	HasMin     bool  `protobuf:"varint,6,opt,name=has_min,json=hasMin" json:"has_min,omitempty"`
-	HasMax     bool  `protobuf:"varint,7,opt,name=has_max,json=hasMax" json:"has_max,omitempty"`
+	Required   bool    `protobuf:"varint,1,opt,name=required" json:"required,omitempty"`
+	Minimum    int64   `protobuf:"varint,2,opt,name=minimum" json:"minimum,omitempty"`
+	Maximum    int64   `protobuf:"varint,3,opt,name=maximum" json:"maximum,omitempty"`
+	Default    int64   `protobuf:"varint,4,opt,name=default" json:"default,omitempty"`
+	HasDefault bool    `protobuf:"varint,5,opt,name=has_default,json=hasDefault" json:"has_default,omitempty"`
+	HasMin     bool    `protobuf:"varint,6,opt,name=has_min,json=hasMin" json:"has_min,omitempty"`
+	HasMax     bool    `protobuf:"varint,7,opt,name=has_max,json=hasMax" json:"has_max,omitempty"`
+	Choices    []int64 `protobuf:"varint,8,rep,packed,name=choices" json:"choices,omitempty"`
 }
 
 func (m *IntegerRule) Reset()                    { *m = IntegerRule{} }
@@ -713,9 +738,12 @@ func (m *IntegerPolicy) GetRules() map[string]*IntegerRule {
 }
 
 type StringRule struct {
-	Required   bool   `protobuf:"varint,1,opt,name=required" json:"required,omitempty"`
-	Default    string `protobuf:"bytes,2,opt,name=default" json:"default,omitempty"`
-	HasDefault bool   `protobuf:"varint,3,opt,name=has_default,json=hasDefault" json:"has_default,omitempty"`
+	Required   bool     `protobuf:"varint,1,opt,name=required" json:"required,omitempty"`
+	Default    string   `protobuf:"bytes,2,opt,name=default" json:"default,omitempty"`
+	HasDefault bool     `protobuf:"varint,3,opt,name=has_default,json=hasDefault" json:"has_default,omitempty"`
+	Sensitive  bool     `protobuf:"varint,4,opt,name=sensitive" json:"sensitive,omitempty"`
+	Pattern    string   `protobuf:"bytes,5,opt,name=pattern" json:"pattern,omitempty"`
+	Choices    []string `protobuf:"bytes,6,rep,name=choices" json:"choices,omitempty"`
 }
 
 func (m *StringRule) Reset()                    { *m = StringRule{} }
@@ -740,6 +768,37 @@ func (m *StringPolicy) GetRules() map[string]*StringRule {
 	return nil
 }
 
+type Constraint struct {
+	Keys     []string `protobuf:"bytes,1,rep,name=keys" json:"keys,omitempty"`
+	IfKey    string   `protobuf:"bytes,2,opt,name=if_key,json=ifKey" json:"if_key,omitempty"`
+	IfType   string   `protobuf:"bytes,3,opt,name=if_type,json=ifType" json:"if_type,omitempty"`
+	IfBool   bool     `protobuf:"varint,4,opt,name=if_bool,json=ifBool" json:"if_bool,omitempty"`
+	IfNumber float64  `protobuf:"fixed64,5,opt,name=if_number,json=ifNumber" json:"if_number,omitempty"`
+	IfString string   `protobuf:"bytes,6,opt,name=if_string,json=ifString" json:"if_string,omitempty"`
+}
+
+func (m *Constraint) Reset()                    { *m = Constraint{} }
+func (m *Constraint) String() string            { return proto.CompactTextString(m) }
+func (*Constraint) ProtoMessage()               {}
+func (*Constraint) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+
+type ConstraintPolicy struct {
+	Constraints []*Constraint `protobuf:"bytes,1,rep,name=constraints" json:"constraints,omitempty"`
+	Key         []string      `protobuf:"bytes,2,rep,name=key" json:"key,omitempty"`
+}
+
+func (m *ConstraintPolicy) Reset()                    { *m = ConstraintPolicy{} }
+func (m *ConstraintPolicy) String() string            { return proto.CompactTextString(m) }
+func (*ConstraintPolicy) ProtoMessage()               {}
+func (*ConstraintPolicy) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+
+func (m *ConstraintPolicy) GetConstraints() []*Constraint {
+	if m != nil {
+		return m.Constraints
+	}
+	return nil
+}
+
 type MetricsArg struct {
 	Metrics []*Metric `protobuf:"bytes,1,rep,name=metrics" json:"metrics,omitempty"`
 }
@@ -747,7 +806,7 @@ type MetricsArg struct {
 func (m *MetricsArg) Reset()                    { *m = MetricsArg{} }
 func (m *MetricsArg) String() string            { return proto.CompactTextString(m) }
 func (*MetricsArg) ProtoMessage()               {}
-func (*MetricsArg) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+func (*MetricsArg) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
 
 func (m *MetricsArg) GetMetrics() []*Metric {
 	if m != nil {
@@ -764,7 +823,7 @@ type MetricsReply struct {
 func (m *MetricsReply) Reset()                    { *m = MetricsReply{} }
 func (m *MetricsReply) String() string            { return proto.CompactTextString(m) }
 func (*MetricsReply) ProtoMessage()               {}
-func (*MetricsReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+func (*MetricsReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
 
 func (m *MetricsReply) GetMetrics() []*Metric {
 	if m != nil {
@@ -780,7 +839,7 @@ type GetMetricTypesArg struct {
 func (m *GetMetricTypesArg) Reset()                    { *m = GetMetricTypesArg{} }
 func (m *GetMetricTypesArg) String() string            { return proto.CompactTextString(m) }
 func (*GetMetricTypesArg) ProtoMessage()               {}
-func (*GetMetricTypesArg) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
+func (*GetMetricTypesArg) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
 
 func (m *GetMetricTypesArg) GetConfig() *ConfigMap {
 	if m != nil {
@@ -809,6 +868,8 @@ func init() {
 	proto.RegisterType((*IntegerPolicy)(nil), "rpc.IntegerPolicy")
 	proto.RegisterType((*StringRule)(nil), "rpc.StringRule")
 	proto.RegisterType((*StringPolicy)(nil), "rpc.StringPolicy")
+	proto.RegisterType((*Constraint)(nil), "rpc.Constraint")
+	proto.RegisterType((*ConstraintPolicy)(nil), "rpc.ConstraintPolicy")
 	proto.RegisterType((*MetricsArg)(nil), "rpc.MetricsArg")
 	proto.RegisterType((*MetricsReply)(nil), "rpc.MetricsReply")
 	proto.RegisterType((*GetMetricTypesArg)(nil), "rpc.GetMetricTypesArg")
@@ -830,6 +891,7 @@ type CollectorClient interface {
 	Ping(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ErrReply, error)
 	Kill(ctx context.Context, in *KillArg, opts ...grpc.CallOption) (*ErrReply, error)
 	GetConfigPolicy(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*GetConfigPolicyReply, error)
+	SetConfig(ctx context.Context, in *ConfigMap, opts ...grpc.CallOption) (*ErrReply, error)
 }
 
 type collectorClient struct {
@@ -885,6 +947,15 @@ func (c *collectorClient) GetConfigPolicy(ctx context.Context, in *Empty, opts .
 	return out, nil
 }
 
+func (c *collectorClient) SetConfig(ctx context.Context, in *ConfigMap, opts ...grpc.CallOption) (*ErrReply, error) {
+	out := new(ErrReply)
+	err := grpc.Invoke(ctx, "/rpc.Collector/SetConfig", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Collector service
 
 type CollectorServer interface {
@@ -893,6 +964,7 @@ type CollectorServer interface {
 	Ping(context.Context, *Empty) (*ErrReply, error)
 	Kill(context.Context, *KillArg) (*ErrReply, error)
 	GetConfigPolicy(context.Context, *Empty) (*GetConfigPolicyReply, error)
+	SetConfig(context.Context, *ConfigMap) (*ErrReply, error)
 }
 
 func RegisterCollectorServer(s *grpc.Server, srv CollectorServer) {
@@ -989,6 +1061,24 @@ func _Collector_GetConfigPolicy_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Collector_SetConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfigMap)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollectorServer).SetConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.Collector/SetConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollectorServer).SetConfig(ctx, req.(*ConfigMap))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Collector_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "rpc.Collector",
 	HandlerType: (*CollectorServer)(nil),
@@ -1013,6 +1103,10 @@ var _Collector_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetConfigPolicy",
 			Handler:    _Collector_GetConfigPolicy_Handler,
 		},
+		{
+			MethodName: "SetConfig",
+			Handler:    _Collector_SetConfig_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: fileDescriptor0,
@@ -1025,6 +1119,7 @@ type ProcessorClient interface {
 	Ping(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ErrReply, error)
 	Kill(ctx context.Context, in *KillArg, opts ...grpc.CallOption) (*ErrReply, error)
 	GetConfigPolicy(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*GetConfigPolicyReply, error)
+	SetConfig(ctx context.Context, in *ConfigMap, opts ...grpc.CallOption) (*ErrReply, error)
 }
 
 type processorClient struct {
@@ -1071,6 +1166,15 @@ func (c *processorClient) GetConfigPolicy(ctx context.Context, in *Empty, opts .
 	return out, nil
 }
 
+func (c *processorClient) SetConfig(ctx context.Context, in *ConfigMap, opts ...grpc.CallOption) (*ErrReply, error) {
+	out := new(ErrReply)
+	err := grpc.Invoke(ctx, "/rpc.Processor/SetConfig", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Processor service
 
 type ProcessorServer interface {
@@ -1078,6 +1182,7 @@ type ProcessorServer interface {
 	Ping(context.Context, *Empty) (*ErrReply, error)
 	Kill(context.Context, *KillArg) (*ErrReply, error)
 	GetConfigPolicy(context.Context, *Empty) (*GetConfigPolicyReply, error)
+	SetConfig(context.Context, *ConfigMap) (*ErrReply, error)
 }
 
 func RegisterProcessorServer(s *grpc.Server, srv ProcessorServer) {
@@ -1156,6 +1261,24 @@ func _Processor_GetConfigPolicy_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Processor_SetConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfigMap)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProcessorServer).SetConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.Processor/SetConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProcessorServer).SetConfig(ctx, req.(*ConfigMap))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Processor_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "rpc.Processor",
 	HandlerType: (*ProcessorServer)(nil),
@@ -1176,6 +1299,10 @@ var _Processor_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetConfigPolicy",
 			Handler:    _Processor_GetConfigPolicy_Handler,
 		},
+		{
+			MethodName: "SetConfig",
+			Handler:    _Processor_SetConfig_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: fileDescriptor0,
@@ -1188,6 +1315,7 @@ type PublisherClient interface {
 	Ping(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ErrReply, error)
 	Kill(ctx context.Context, in *KillArg, opts ...grpc.CallOption) (*ErrReply, error)
 	GetConfigPolicy(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*GetConfigPolicyReply, error)
+	SetConfig(ctx context.Context, in *ConfigMap, opts ...grpc.CallOption) (*ErrReply, error)
 }
 
 type publisherClient struct {
@@ -1234,6 +1362,15 @@ func (c *publisherClient) GetConfigPolicy(ctx context.Context, in *Empty, opts .
 	return out, nil
 }
 
+func (c *publisherClient) SetConfig(ctx context.Context, in *ConfigMap, opts ...grpc.CallOption) (*ErrReply, error) {
+	out := new(ErrReply)
+	err := grpc.Invoke(ctx, "/rpc.Publisher/SetConfig", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Publisher service
 
 type PublisherServer interface {
@@ -1241,6 +1378,7 @@ type PublisherServer interface {
 	Ping(context.Context, *Empty) (*ErrReply, error)
 	Kill(context.Context, *KillArg) (*ErrReply, error)
 	GetConfigPolicy(context.Context, *Empty) (*GetConfigPolicyReply, error)
+	SetConfig(context.Context, *ConfigMap) (*ErrReply, error)
 }
 
 func RegisterPublisherServer(s *grpc.Server, srv PublisherServer) {
@@ -1319,6 +1457,24 @@ func _Publisher_GetConfigPolicy_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Publisher_SetConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfigMap)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PublisherServer).SetConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.Publisher/SetConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PublisherServer).SetConfig(ctx, req.(*ConfigMap))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Publisher_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "rpc.Publisher",
 	HandlerType: (*PublisherServer)(nil),
@@ -1339,6 +1495,10 @@ var _Publisher_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetConfigPolicy",
 			Handler:    _Publisher_GetConfigPolicy_Handler,
 		},
+		{
+			MethodName: "SetConfig",
+			Handler:    _Publisher_SetConfig_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: fileDescriptor0,
@@ -1352,6 +1512,7 @@ type StreamCollectorClient interface {
 	Ping(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ErrReply, error)
 	Kill(ctx context.Context, in *KillArg, opts ...grpc.CallOption) (*ErrReply, error)
 	GetConfigPolicy(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*GetConfigPolicyReply, error)
+	SetConfig(ctx context.Context, in *ConfigMap, opts ...grpc.CallOption) (*ErrReply, error)
 }
 
 type streamCollectorClient struct {
@@ -1429,6 +1590,15 @@ func (c *streamCollectorClient) GetConfigPolicy(ctx context.Context, in *Empty,
 	return out, nil
 }
 
+func (c *streamCollectorClient) SetConfig(ctx context.Context, in *ConfigMap, opts ...grpc.CallOption) (*ErrReply, error) {
+	out := new(ErrReply)
+	err := grpc.Invoke(ctx, "/rpc.StreamCollector/SetConfig", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for StreamCollector service
 
 type StreamCollectorServer interface {
@@ -1437,6 +1607,7 @@ type StreamCollectorServer interface {
 	Ping(context.Context, *Empty) (*ErrReply, error)
 	Kill(context.Context, *KillArg) (*ErrReply, error)
 	GetConfigPolicy(context.Context, *Empty) (*GetConfigPolicyReply, error)
+	SetConfig(context.Context, *ConfigMap) (*ErrReply, error)
 }
 
 func RegisterStreamCollectorServer(s *grpc.Server, srv StreamCollectorServer) {
@@ -1541,6 +1712,24 @@ func _StreamCollector_GetConfigPolicy_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _StreamCollector_SetConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfigMap)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StreamCollectorServer).SetConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.StreamCollector/SetConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StreamCollectorServer).SetConfig(ctx, req.(*ConfigMap))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _StreamCollector_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "rpc.StreamCollector",
 	HandlerType: (*StreamCollectorServer)(nil),
@@ -1561,6 +1750,10 @@ var _StreamCollector_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetConfigPolicy",
 			Handler:    _StreamCollector_GetConfigPolicy_Handler,
 		},
+		{
+			MethodName: "SetConfig",
+			Handler:    _StreamCollector_SetConfig_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -1578,101 +1771,120 @@ func init() {
 }
 
 var fileDescriptor0 = []byte{
-	// 1532 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xdc, 0x58, 0x4b, 0x6f, 0xdb, 0xc6,
-	0x16, 0x16, 0x4d, 0xbd, 0x78, 0x28, 0xf9, 0x31, 0xc8, 0xcd, 0xd5, 0x55, 0x12, 0x44, 0xa1, 0x6f,
-	0x12, 0xe5, 0x71, 0xe5, 0x5c, 0x39, 0x75, 0x13, 0xa7, 0x5d, 0x38, 0xb1, 0x1b, 0x27, 0xa9, 0x53,
-	0x83, 0x71, 0xb3, 0x29, 0xd0, 0x60, 0x24, 0x8f, 0x65, 0x22, 0x7c, 0x75, 0x48, 0x06, 0xf6, 0x5f,
-	0xe8, 0xb6, 0xab, 0x02, 0x05, 0x0a, 0xf4, 0x17, 0x74, 0xdd, 0x55, 0x17, 0x5d, 0x14, 0xfd, 0x13,
-	0xfd, 0x2b, 0xc5, 0x3c, 0x28, 0x0e, 0x29, 0x29, 0xb2, 0x17, 0x05, 0x82, 0xee, 0x78, 0x5e, 0x9f,
-	0xce, 0xf9, 0xce, 0x99, 0x21, 0x8f, 0x60, 0x73, 0xe4, 0xc4, 0xc7, 0xc9, 0xa0, 0x37, 0x0c, 0xbc,
-	0x35, 0xc7, 0x8f, 0x89, 0x1b, 0x1d, 0x3a, 0xff, 0x3b, 0x59, 0x8b, 0x7c, 0x1c, 0xae, 0x0d, 0x03,
-	0x3f, 0xa6, 0x81, 0xbb, 0x16, 0xba, 0xc9, 0xc8, 0xf1, 0xd7, 0x68, 0x38, 0x94, 0x8f, 0xbd, 0x90,
-	0x06, 0x71, 0x80, 0x74, 0x1a, 0x0e, 0xad, 0x9f, 0x35, 0x80, 0x27, 0x81, 0xeb, 0x92, 0x61, 0xbc,
-	0x45, 0x47, 0xe8, 0x1e, 0x98, 0x7b, 0x24, 0xa6, 0xce, 0x30, 0x7a, 0xb3, 0x45, 0x47, 0x2d, 0xad,
-	0xa3, 0x75, 0xcd, 0xfe, 0x52, 0x8f, 0x86, 0xc3, 0x9e, 0xd4, 0x6f, 0xd1, 0x91, 0x0d, 0xde, 0xf8,
-	0x19, 0xf5, 0x00, 0xed, 0xe1, 0x13, 0x09, 0xb1, 0x9d, 0x50, 0x1c, 0x3b, 0x81, 0xdf, 0x5a, 0xe8,
-	0x68, 0x5d, 0xdd, 0x46, 0xde, 0x84, 0x05, 0xdd, 0x86, 0xe5, 0x3d, 0x7c, 0x22, 0xc1, 0x1e, 0x27,
-	0x47, 0x47, 0x84, 0xb6, 0x74, 0xee, 0xbd, 0xec, 0x15, 0xf4, 0xe8, 0x02, 0x54, 0xbe, 0x88, 0x8f,
-	0x09, 0x6d, 0x95, 0x3b, 0x5a, 0xb7, 0x61, 0x57, 0x02, 0x26, 0x58, 0x6f, 0xa1, 0x21, 0x41, 0x6d,
-	0x12, 0xba, 0xa7, 0x68, 0x03, 0x9a, 0x69, 0xce, 0x5c, 0x21, 0xb3, 0x5e, 0x51, 0xb3, 0xe6, 0x06,
-	0xbb, 0xe1, 0x29, 0x12, 0x5a, 0x85, 0xca, 0x0e, 0xa5, 0x01, 0xe5, 0xc9, 0x9a, 0xfd, 0x26, 0xf7,
-	0xdf, 0xa1, 0x54, 0xf8, 0x56, 0x08, 0xb3, 0x59, 0x35, 0xa8, 0xec, 0x78, 0x61, 0x7c, 0x6a, 0x75,
-	0xa0, 0x9e, 0xda, 0x58, 0x5e, 0xdc, 0xca, 0x7f, 0xc9, 0x48, 0x5d, 0xef, 0x42, 0xf9, 0xc0, 0xf1,
-	0x08, 0x5a, 0x06, 0x3d, 0x22, 0x43, 0x6e, 0xd3, 0x6d, 0xf6, 0x88, 0x10, 0x94, 0x7d, 0xa6, 0x12,
-	0xac, 0xf0, 0x67, 0xeb, 0x6b, 0x58, 0x7e, 0x89, 0x3d, 0x12, 0x85, 0x78, 0x48, 0x76, 0x5c, 0xe2,
-	0x11, 0x3f, 0x66, 0xb8, 0xaf, 0xb1, 0x9b, 0x90, 0x14, 0xf7, 0x1d, 0x13, 0x50, 0x07, 0xcc, 0x6d,
-	0x12, 0x0d, 0xa9, 0x13, 0x8e, 0xa9, 0x35, 0x6c, 0xf3, 0x30, 0x53, 0x31, 0x7c, 0x86, 0xc5, 0x79,
-	0x34, 0xec, 0xb2, 0x8f, 0x3d, 0x62, 0x7d, 0x05, 0xb0, 0x9f, 0x0c, 0xf6, 0x69, 0x30, 0x64, 0x5d,
-	0xba, 0x0e, 0x35, 0xc9, 0x44, 0x4b, 0xeb, 0xe8, 0x5d, 0xb3, 0x6f, 0x2a, 0xec, 0xd8, 0x35, 0xc9,
-	0x0b, 0xba, 0x01, 0xd5, 0x27, 0x81, 0x7f, 0xe4, 0x8c, 0x24, 0x27, 0x8b, 0xdc, 0x4b, 0xa8, 0xf6,
-	0x70, 0x68, 0x57, 0x87, 0xfc, 0xd1, 0xfa, 0xa5, 0x02, 0x55, 0x11, 0x8b, 0xd6, 0xc1, 0x18, 0xd7,
-	0x21, 0xb1, 0xff, 0xc5, 0xa3, 0x8a, 0xd5, 0xd9, 0x86, 0x9f, 0x6a, 0x50, 0x0b, 0x6a, 0xaf, 0x09,
-	0x8d, 0xb2, 0x49, 0xa9, 0xbd, 0x13, 0xa2, 0x92, 0x81, 0xfe, 0xbe, 0x0c, 0xd0, 0x43, 0x40, 0x9f,
-	0xe3, 0x28, 0xde, 0x3a, 0x7c, 0x47, 0x68, 0xec, 0x44, 0xe4, 0x90, 0x51, 0xcf, 0xe7, 0xc4, 0xec,
-	0x1b, 0x3c, 0x86, 0x29, 0x6c, 0xe4, 0x4e, 0x38, 0xa1, 0x5b, 0x50, 0x3e, 0xc0, 0xa3, 0xa8, 0x55,
-	0x51, 0x92, 0x15, 0xc5, 0xf4, 0x98, 0x7e, 0xc7, 0x8f, 0xe9, 0xa9, 0x5d, 0x8e, 0xf1, 0x28, 0x42,
-	0x37, 0xc1, 0x60, 0x21, 0x51, 0x8c, 0xbd, 0xb0, 0x55, 0x2d, 0x82, 0x1b, 0x71, 0x6a, 0x63, 0x1d,
-	0xf8, 0xd2, 0x77, 0xe2, 0x56, 0x4d, 0x74, 0x20, 0xf1, 0x9d, 0xb8, 0xd8, 0xb7, 0xfa, 0x64, 0xdf,
-	0xae, 0x81, 0x19, 0xc5, 0xd4, 0xf1, 0x47, 0x6f, 0x0e, 0x71, 0x8c, 0x5b, 0x06, 0xf3, 0xd8, 0x2d,
-	0xd9, 0x20, 0x94, 0xdb, 0x38, 0xc6, 0x68, 0x15, 0x1a, 0x47, 0x6e, 0x80, 0xe3, 0xf5, 0xbe, 0xf0,
-	0x81, 0x8e, 0xd6, 0x5d, 0xd8, 0x2d, 0xd9, 0xa6, 0xd4, 0xe6, 0x9c, 0x36, 0xee, 0x0b, 0x27, 0xb3,
-	0xa3, 0x75, 0xb5, 0xb1, 0xd3, 0xc6, 0x7d, 0xee, 0x74, 0x15, 0xc0, 0xf1, 0xc7, 0x38, 0x8d, 0x8e,
-	0xd6, 0xad, 0xec, 0x96, 0x6c, 0x83, 0xeb, 0x14, 0x87, 0x14, 0xa3, 0xc9, 0xfa, 0x22, 0x1d, 0x32,
-	0x84, 0xc1, 0x69, 0x4c, 0x22, 0xe1, 0xb0, 0xc8, 0xce, 0x24, 0x73, 0xe0, 0x3a, 0xee, 0x70, 0x05,
-	0x8c, 0x41, 0x10, 0xb8, 0xc2, 0xbe, 0xd4, 0xd1, 0xba, 0xf5, 0xdd, 0x92, 0x5d, 0x67, 0x2a, 0x6e,
-	0xbe, 0x06, 0x66, 0xa2, 0xa4, 0xb0, 0xdc, 0xd1, 0xba, 0x4d, 0x56, 0x6e, 0x92, 0xe5, 0x20, 0x5d,
-	0xd2, 0x24, 0x56, 0x3a, 0x5a, 0xb7, 0x9c, 0xba, 0x88, 0x2c, 0xda, 0x1f, 0x83, 0x31, 0x6e, 0x13,
-	0x3b, 0x6b, 0x6f, 0xc9, 0xa9, 0x3c, 0x2f, 0xec, 0x91, 0x9d, 0x21, 0x7e, 0x6c, 0xe4, 0x39, 0x11,
-	0xc2, 0xe6, 0xc2, 0x03, 0xed, 0x71, 0x15, 0xca, 0x0c, 0xd4, 0xfa, 0x53, 0x07, 0x63, 0x3c, 0x50,
-	0xa8, 0x0f, 0xd5, 0x67, 0x7e, 0xbc, 0x87, 0x43, 0x39, 0xbc, 0xed, 0xfc, 0xc0, 0xf5, 0x84, 0x51,
-	0x0c, 0x45, 0xd5, 0xe1, 0x02, 0x7a, 0x04, 0xc6, 0x2b, 0xde, 0x22, 0x16, 0xb6, 0xc0, 0xc3, 0xae,
-	0x14, 0xc2, 0xc6, 0x76, 0x11, 0x69, 0x44, 0xa9, 0x8c, 0x1e, 0x40, 0xfd, 0x33, 0xd6, 0x16, 0x16,
-	0xab, 0xf3, 0xd8, 0xcb, 0x85, 0xd8, 0xd4, 0x2c, 0x42, 0xeb, 0x47, 0x52, 0x44, 0x1f, 0x41, 0xed,
-	0x71, 0x10, 0xb8, 0x2c, 0xb0, 0xcc, 0x03, 0x2f, 0x15, 0x02, 0xa5, 0x55, 0xc4, 0xd5, 0x06, 0x42,
-	0x6a, 0x3f, 0x04, 0x53, 0x29, 0x62, 0x1e, 0x65, 0xba, 0x42, 0x59, 0xfb, 0x13, 0x58, 0xcc, 0x17,
-	0x72, 0x1e, 0xc2, 0xdb, 0x8f, 0xa0, 0x99, 0x2b, 0x65, 0x5e, 0xb0, 0xa6, 0x06, 0x6f, 0x42, 0x43,
-	0x2d, 0x67, 0x5e, 0x6c, 0x5d, 0x89, 0xb5, 0xae, 0x41, 0xed, 0x85, 0xe3, 0xba, 0xec, 0xe2, 0xbb,
-	0x08, 0x55, 0x9b, 0xe0, 0x28, 0xf0, 0x65, 0x64, 0x95, 0x72, 0x89, 0xdd, 0x60, 0x17, 0x9e, 0x92,
-	0x58, 0x70, 0xb7, 0x1f, 0xb8, 0xce, 0xf0, 0xf4, 0x3d, 0x77, 0x3b, 0x7a, 0x0e, 0x26, 0x9f, 0xec,
-	0x90, 0x7b, 0xca, 0x9e, 0xdf, 0xe2, 0xf4, 0x4f, 0x43, 0xe1, 0x9d, 0x10, 0xb2, 0x68, 0x06, 0x0c,
-	0xc6, 0x0a, 0xb4, 0x27, 0x4f, 0x6b, 0x0a, 0x26, 0x86, 0xe0, 0xf6, 0x6c, 0x30, 0x4e, 0xa2, 0x8a,
-	0x26, 0xce, 0xb5, 0x84, 0x7b, 0x05, 0x8b, 0xec, 0xcd, 0x3f, 0x22, 0x34, 0x05, 0x14, 0xc3, 0x71,
-	0x77, 0x36, 0xe0, 0x33, 0xe1, 0xaf, 0x42, 0x36, 0x1d, 0x55, 0x87, 0xf6, 0xa1, 0x29, 0x6f, 0x26,
-	0x89, 0x29, 0x2e, 0xcb, 0x3b, 0xb3, 0x31, 0xc5, 0x9c, 0xa8, 0x90, 0x8d, 0x48, 0x51, 0xb5, 0x5f,
-	0xc2, 0x52, 0x81, 0x94, 0x29, 0x2d, 0xbd, 0xae, 0xb6, 0x34, 0xfd, 0xf0, 0xc8, 0xc2, 0xd4, 0xf9,
-	0xd8, 0x87, 0xe5, 0x22, 0x2f, 0x53, 0x00, 0x6f, 0xe4, 0x01, 0x97, 0x39, 0xa0, 0x12, 0xa7, 0x22,
-	0x1e, 0x00, 0x9a, 0x24, 0x66, 0x0a, 0x66, 0x37, 0x8f, 0x89, 0x38, 0x66, 0x2e, 0x52, 0x45, 0xb5,
-	0x61, 0x65, 0x82, 0x9a, 0x29, 0xa0, 0x37, 0xf3, 0xa0, 0xe2, 0xe3, 0x45, 0x0d, 0x54, 0xe7, 0x1b,
-	0x43, 0x9d, 0x91, 0x62, 0x27, 0x2e, 0x41, 0x6d, 0xa8, 0x53, 0xf2, 0x4d, 0xe2, 0x50, 0x72, 0xc8,
-	0xf1, 0xea, 0xf6, 0x58, 0x66, 0xaf, 0xd9, 0x43, 0x72, 0x84, 0x13, 0x37, 0x96, 0x67, 0x24, 0x15,
-	0xd1, 0x55, 0x30, 0x8f, 0x71, 0xf4, 0x26, 0xb5, 0xea, 0xdc, 0x0a, 0xc7, 0x38, 0xda, 0x16, 0x1a,
-	0xeb, 0x7b, 0x0d, 0x20, 0x23, 0x1e, 0xdd, 0x83, 0x0a, 0x4d, 0x5c, 0x12, 0xe5, 0x2e, 0xc9, 0xcc,
-	0xde, 0x63, 0xa9, 0xc8, 0x37, 0xa7, 0x70, 0x4c, 0x4b, 0x64, 0x27, 0x45, 0x94, 0xd8, 0x7e, 0x0a,
-	0x90, 0xb9, 0x4d, 0xa1, 0x60, 0x35, 0x4f, 0x41, 0x73, 0xfc, 0x1b, 0x2c, 0x4a, 0x2d, 0xff, 0x77,
-	0x0d, 0x0c, 0xde, 0xc3, 0xb3, 0x10, 0xe0, 0x39, 0xbe, 0xe3, 0x25, 0x9e, 0xbc, 0x60, 0x52, 0x91,
-	0x5b, 0xf0, 0x09, 0xb7, 0xe8, 0xd2, 0x22, 0x44, 0x95, 0xb4, 0xb2, 0xb0, 0xcc, 0x20, 0xad, 0x52,
-	0x24, 0x0d, 0xfd, 0x1b, 0x6a, 0xcc, 0xc1, 0x73, 0x7c, 0xfe, 0xb1, 0x50, 0xb7, 0xab, 0xc7, 0x38,
-	0xda, 0x73, 0xfc, 0xb1, 0x01, 0x9f, 0xf0, 0x2f, 0x04, 0x69, 0xc0, 0x27, 0xd6, 0x0f, 0x1a, 0x98,
-	0xca, 0x38, 0xa2, 0xff, 0xe7, 0x79, 0xbe, 0x54, 0x9c, 0xd7, 0x33, 0x11, 0xbd, 0x3b, 0x87, 0xe8,
-	0xff, 0xe6, 0x89, 0x5e, 0xcc, 0x7e, 0xa4, 0xc8, 0xf4, 0x1f, 0x1a, 0x7f, 0x77, 0xb0, 0xc9, 0x3e,
-	0x2f, 0xd7, 0xfa, 0x4c, 0xae, 0xf5, 0x99, 0x5c, 0xeb, 0x7f, 0x2b, 0xd7, 0x3f, 0x69, 0xd0, 0xcc,
-	0x1d, 0x53, 0xb4, 0x9e, 0x67, 0xfb, 0xca, 0xe4, 0x49, 0x3e, 0x13, 0xdf, 0xcf, 0xe7, 0xf0, 0x3d,
-	0xf5, 0x12, 0x52, 0x68, 0x55, 0x19, 0x1f, 0x02, 0x88, 0x53, 0x7f, 0xde, 0xc3, 0x6d, 0x9c, 0xe3,
-	0x70, 0xff, 0xa8, 0x41, 0x43, 0xbd, 0x5b, 0x50, 0x3f, 0x4f, 0xc4, 0xe5, 0x89, 0xdb, 0xe7, 0x4c,
-	0x3c, 0x3c, 0x9b, 0xc3, 0xc3, 0xd4, 0xdb, 0x3d, 0xab, 0x56, 0xa5, 0x61, 0x1d, 0x20, 0xdb, 0x37,
-	0xd9, 0xf6, 0xe2, 0xcd, 0xdf, 0x5e, 0xac, 0x17, 0xd0, 0x50, 0xd7, 0xbd, 0x33, 0x86, 0x65, 0x6f,
-	0xfc, 0x05, 0x75, 0x9b, 0x7b, 0x04, 0x2b, 0x4f, 0x49, 0x2c, 0x7c, 0x0f, 0x4e, 0x43, 0xc2, 0x13,
-	0xb9, 0x01, 0x72, 0xff, 0x90, 0x3b, 0xe6, 0x8c, 0xed, 0xa4, 0xff, 0xed, 0x02, 0xfb, 0xc4, 0xe4,
-	0x3b, 0x6a, 0x40, 0xd1, 0x06, 0x2c, 0x4a, 0x41, 0xa6, 0x87, 0x8a, 0x1b, 0x75, 0x7b, 0x72, 0x59,
-	0xb5, 0x4a, 0xe8, 0x53, 0x58, 0xcc, 0xa7, 0x80, 0x2e, 0xa6, 0xef, 0xdf, 0x7c, 0x5e, 0xd3, 0xc3,
-	0x57, 0xa1, 0xbc, 0xef, 0xf8, 0x23, 0x04, 0x62, 0xb1, 0x65, 0x5b, 0x6c, 0x3b, 0xbf, 0xe4, 0x5a,
-	0x25, 0x74, 0x1d, 0xca, 0xec, 0x53, 0x09, 0x35, 0xb8, 0x41, 0x7e, 0x35, 0x4d, 0xba, 0x6d, 0xc2,
-	0x52, 0xe1, 0xad, 0x9f, 0x83, 0xfd, 0xcf, 0xcc, 0xef, 0x02, 0xab, 0xd4, 0xff, 0x4d, 0x03, 0x83,
-	0xed, 0xa1, 0x24, 0x8a, 0x02, 0x8a, 0xd6, 0xa0, 0x26, 0x05, 0xc9, 0x42, 0xb6, 0xa5, 0x7e, 0xd8,
-	0x65, 0xfc, 0xca, 0xca, 0x48, 0x06, 0xae, 0x13, 0x1d, 0x13, 0x8a, 0xee, 0x40, 0x4d, 0x0a, 0x93,
-	0x65, 0x4c, 0xfc, 0xec, 0x87, 0x52, 0xc2, 0x77, 0x0b, 0xb0, 0xf4, 0x2a, 0xa6, 0x04, 0x7b, 0xd9,
-	0x70, 0x3e, 0x84, 0xa6, 0x50, 0xe5, 0x67, 0x33, 0xfb, 0x4f, 0x48, 0x76, 0x45, 0xfd, 0xcb, 0xc5,
-	0x2a, 0x75, 0xb5, 0x7b, 0xda, 0x3f, 0x64, 0x3e, 0x07, 0x55, 0xfe, 0x77, 0xd8, 0xfa, 0x5f, 0x01,
-	0x00, 0x00, 0xff, 0xff, 0x04, 0x49, 0xf2, 0x93, 0x4c, 0x13, 0x00, 0x00,
+	// 1835 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xe4, 0x58, 0xdd, 0x6e, 0xdb, 0xc8,
+	0x15, 0x36, 0x4d, 0x89, 0x12, 0x0f, 0x25, 0x5b, 0x1e, 0x6c, 0xb6, 0xaa, 0x92, 0x60, 0x15, 0xa6,
+	0xc9, 0x6a, 0x37, 0xa9, 0x9d, 0x95, 0xb7, 0xe9, 0x26, 0x69, 0x2f, 0xf2, 0xe3, 0x8d, 0xb3, 0x59,
+	0xa7, 0x06, 0x93, 0x6e, 0x81, 0xb6, 0xa8, 0x41, 0xd1, 0x23, 0x79, 0x10, 0xfe, 0x75, 0x38, 0x34,
+	0xac, 0x47, 0x29, 0x50, 0xa0, 0x40, 0xfb, 0x0c, 0x05, 0x7a, 0xdb, 0x97, 0xe8, 0x65, 0x0b, 0x14,
+	0xbd, 0xee, 0x45, 0xd1, 0x07, 0x28, 0xe6, 0x87, 0xe2, 0x90, 0x92, 0x63, 0xe7, 0xa6, 0x40, 0xbb,
+	0x77, 0x3c, 0x7f, 0x1f, 0xcf, 0xf9, 0xce, 0xcc, 0xe1, 0x70, 0xe0, 0xe1, 0x8c, 0xb0, 0x93, 0x7c,
+	0xb2, 0x1d, 0x24, 0xd1, 0x0e, 0x89, 0x19, 0x0e, 0xb3, 0x63, 0xf2, 0xfd, 0xb3, 0x9d, 0x2c, 0xf6,
+	0xd3, 0x9d, 0x20, 0x89, 0x19, 0x4d, 0xc2, 0x9d, 0x34, 0xcc, 0x67, 0x24, 0xde, 0xa1, 0x69, 0xa0,
+	0x1e, 0xb7, 0x53, 0x9a, 0xb0, 0x04, 0x99, 0x34, 0x0d, 0xdc, 0xbf, 0x1a, 0x00, 0x4f, 0x93, 0x30,
+	0xc4, 0x01, 0x7b, 0x4c, 0x67, 0xe8, 0x1e, 0x38, 0x07, 0x98, 0x51, 0x12, 0x64, 0x47, 0x8f, 0xe9,
+	0xac, 0x6f, 0x0c, 0x8d, 0x91, 0x33, 0xde, 0xdc, 0xa6, 0x69, 0xb0, 0xad, 0xf4, 0x8f, 0xe9, 0xcc,
+	0x83, 0x68, 0xf1, 0x8c, 0xb6, 0x01, 0x1d, 0xf8, 0x67, 0x0a, 0xe2, 0x59, 0x4e, 0x7d, 0x46, 0x92,
+	0xb8, 0xbf, 0x3e, 0x34, 0x46, 0xa6, 0x87, 0xa2, 0x25, 0x0b, 0xfa, 0x14, 0x7a, 0x07, 0xfe, 0x99,
+	0x02, 0x7b, 0x92, 0x4f, 0xa7, 0x98, 0xf6, 0x4d, 0xe1, 0xdd, 0x8b, 0x6a, 0x7a, 0xf4, 0x01, 0x34,
+	0x7f, 0xc2, 0x4e, 0x30, 0xed, 0x37, 0x86, 0xc6, 0xa8, 0xe3, 0x35, 0x13, 0x76, 0x22, 0xb5, 0x87,
+	0x7e, 0x9e, 0xe1, 0x7e, 0x73, 0x68, 0x8c, 0xda, 0x5e, 0x33, 0xe5, 0x02, 0xfa, 0x10, 0x2c, 0x0f,
+	0x67, 0x79, 0x84, 0xfb, 0x96, 0x50, 0x5b, 0x54, 0x48, 0xee, 0x5b, 0xe8, 0xa8, 0x14, 0x3c, 0x9c,
+	0x86, 0x73, 0x74, 0x1f, 0xba, 0x45, 0x85, 0x42, 0xa1, 0x6a, 0xdc, 0xd2, 0x6b, 0x14, 0x06, 0xaf,
+	0x13, 0x69, 0x12, 0xba, 0x09, 0xcd, 0x3d, 0x4a, 0x13, 0x2a, 0x4a, 0x73, 0xc6, 0x5d, 0xe1, 0xbf,
+	0x47, 0xa9, 0xf4, 0x6d, 0x62, 0x6e, 0x73, 0x5b, 0xd0, 0xdc, 0x8b, 0x52, 0x36, 0x77, 0xcf, 0xa0,
+	0x5d, 0xd8, 0x78, 0xbe, 0xc2, 0x2a, 0xde, 0x64, 0x2b, 0x57, 0x74, 0x15, 0x6c, 0x12, 0x1f, 0x4d,
+	0x43, 0x32, 0x3b, 0x61, 0x8a, 0xae, 0x36, 0x89, 0xbf, 0x14, 0x32, 0xba, 0x01, 0x9d, 0xd0, 0xcf,
+	0xd8, 0x51, 0xe8, 0x33, 0x1c, 0x07, 0x73, 0x45, 0x90, 0xc3, 0x75, 0x5f, 0x4b, 0x15, 0xaf, 0x37,
+	0xc2, 0x51, 0x42, 0xe7, 0x82, 0x1c, 0xd3, 0x53, 0x92, 0x7b, 0x17, 0x1a, 0x6f, 0x48, 0x84, 0x51,
+	0x0f, 0xcc, 0x0c, 0x07, 0xe2, 0x9d, 0xa6, 0xc7, 0x1f, 0x11, 0x82, 0x46, 0xcc, 0x55, 0xf2, 0x65,
+	0xe2, 0xd9, 0xfd, 0x15, 0xf4, 0x5e, 0xf9, 0x11, 0xce, 0x52, 0x3f, 0xc0, 0x7b, 0x21, 0x8e, 0x70,
+	0xcc, 0x78, 0xbe, 0xdf, 0xf8, 0x61, 0x8e, 0x8b, 0x7c, 0x4f, 0xb9, 0x80, 0x86, 0xe0, 0x3c, 0xc3,
+	0x59, 0x40, 0x49, 0xba, 0x68, 0xb0, 0xed, 0x39, 0xc7, 0xa5, 0x8a, 0xe3, 0x73, 0x2c, 0x91, 0xac,
+	0xed, 0x35, 0x62, 0x3f, 0xc2, 0xee, 0x2f, 0x00, 0x0e, 0xf3, 0xc9, 0x21, 0x4d, 0x02, 0xbe, 0x56,
+	0x6e, 0x41, 0x4b, 0x31, 0xdc, 0x37, 0x86, 0xe6, 0xc8, 0x19, 0x3b, 0x1a, 0xeb, 0x5e, 0x4b, 0xf1,
+	0x8d, 0x6e, 0x83, 0xf5, 0x34, 0x89, 0xa7, 0x64, 0xa6, 0xb8, 0xde, 0x10, 0x5e, 0x52, 0x75, 0xe0,
+	0xa7, 0x9e, 0x15, 0x88, 0x47, 0xf7, 0x2f, 0x4d, 0xb0, 0x64, 0x2c, 0xda, 0x05, 0x7b, 0x51, 0x87,
+	0xc2, 0xbe, 0x22, 0xa2, 0xea, 0xd5, 0x79, 0x76, 0x5c, 0x68, 0x50, 0x1f, 0x5a, 0xdf, 0x60, 0x9a,
+	0x95, 0xeb, 0xb5, 0x75, 0x2a, 0x45, 0x2d, 0x03, 0xf3, 0x5d, 0x19, 0xa0, 0x07, 0x80, 0xbe, 0xf6,
+	0x33, 0xf6, 0xf8, 0xf8, 0x14, 0x53, 0x46, 0x32, 0x7c, 0xcc, 0xa9, 0x17, 0x0d, 0x71, 0xc6, 0xb6,
+	0x88, 0xe1, 0x0a, 0x0f, 0x85, 0x4b, 0x4e, 0xe8, 0x13, 0x68, 0xbc, 0xf1, 0x67, 0x59, 0xbf, 0xa9,
+	0x25, 0x2b, 0x8b, 0xd9, 0xe6, 0xfa, 0xbd, 0x98, 0xd1, 0xb9, 0xd7, 0x60, 0xfe, 0x2c, 0x43, 0x1f,
+	0x83, 0xcd, 0x43, 0x32, 0xe6, 0x47, 0x69, 0xdf, 0xaa, 0x83, 0xdb, 0xac, 0xb0, 0xf1, 0x0e, 0xfc,
+	0x34, 0x26, 0xac, 0xdf, 0x92, 0x1d, 0xc8, 0x63, 0xc2, 0xea, 0x7d, 0x6b, 0x2f, 0xf7, 0x6d, 0x00,
+	0xed, 0x67, 0x3e, 0xf3, 0xdf, 0xcc, 0x53, 0xdc, 0x47, 0xc2, 0xdc, 0x3e, 0x56, 0x32, 0xba, 0x01,
+	0x4e, 0xc6, 0x28, 0x89, 0x67, 0x47, 0x5c, 0xd5, 0xb7, 0xb9, 0x79, 0x7f, 0xcd, 0x03, 0xa9, 0xe4,
+	0x61, 0xe8, 0x26, 0x74, 0xa6, 0x61, 0xe2, 0xb3, 0xdd, 0xb1, 0xf4, 0x81, 0xa1, 0x31, 0x5a, 0xdf,
+	0x5f, 0xf3, 0x1c, 0xa5, 0xad, 0x38, 0xdd, 0xff, 0x5c, 0x3a, 0x39, 0x43, 0x63, 0x64, 0x2c, 0x9c,
+	0xee, 0x7f, 0x2e, 0x9c, 0x3e, 0x02, 0x20, 0xf1, 0x02, 0xa7, 0x33, 0x34, 0x46, 0xcd, 0xfd, 0x35,
+	0xcf, 0x16, 0x3a, 0xcd, 0xa1, 0xc0, 0xe8, 0xf2, 0x9e, 0x29, 0x87, 0x12, 0x61, 0x32, 0x67, 0x38,
+	0x93, 0x0e, 0x1b, 0x7c, 0x6a, 0x70, 0x07, 0xa1, 0x13, 0x0e, 0xd7, 0xc1, 0x9e, 0x24, 0x49, 0x28,
+	0xed, 0x9b, 0x7c, 0x50, 0xec, 0xaf, 0x79, 0x6d, 0xae, 0x12, 0xe6, 0x1b, 0xe0, 0xe4, 0x5a, 0x0a,
+	0xbd, 0xa1, 0x31, 0xea, 0xf2, 0x72, 0xf3, 0x32, 0x07, 0xe5, 0x52, 0x24, 0xb1, 0x35, 0x34, 0x46,
+	0x8d, 0xc2, 0x45, 0x66, 0x31, 0xf8, 0x21, 0xd8, 0x8b, 0x16, 0xf2, 0x7d, 0xf8, 0x16, 0xcf, 0xd5,
+	0x5e, 0xe2, 0x8f, 0x7c, 0x7f, 0x89, 0x2d, 0xa5, 0xf6, 0x90, 0x14, 0x1e, 0xae, 0x7f, 0x61, 0x3c,
+	0xb1, 0xa0, 0xc1, 0x41, 0xdd, 0xbf, 0x99, 0x60, 0x2f, 0x16, 0x1b, 0x1a, 0x83, 0xf5, 0x22, 0x66,
+	0x07, 0x7e, 0xaa, 0x16, 0xf6, 0xa0, 0xba, 0x18, 0xb7, 0xa5, 0x51, 0x2e, 0x18, 0x8b, 0x08, 0x01,
+	0x3d, 0x02, 0xfb, 0xb5, 0x68, 0x11, 0x0f, 0x5b, 0x17, 0x61, 0xd7, 0x6b, 0x61, 0x0b, 0xbb, 0x8c,
+	0xb4, 0xb3, 0x42, 0x46, 0x5f, 0x40, 0xfb, 0x4b, 0xde, 0x16, 0x1e, 0x6b, 0x8a, 0xd8, 0x6b, 0xb5,
+	0xd8, 0xc2, 0x2c, 0x43, 0xdb, 0x53, 0x25, 0xa2, 0x1f, 0x40, 0xeb, 0x49, 0x92, 0x84, 0x3c, 0xb0,
+	0x21, 0x02, 0xaf, 0xd6, 0x02, 0x95, 0x55, 0xc6, 0xb5, 0x26, 0x52, 0x1a, 0x3c, 0x00, 0x47, 0x2b,
+	0xe2, 0x22, 0xca, 0x4c, 0x8d, 0xb2, 0xc1, 0x8f, 0x60, 0xa3, 0x5a, 0xc8, 0xfb, 0x10, 0x3e, 0x78,
+	0x04, 0xdd, 0x4a, 0x29, 0x17, 0x05, 0x1b, 0x7a, 0xf0, 0x43, 0xe8, 0xe8, 0xe5, 0x5c, 0x14, 0xdb,
+	0xd6, 0x62, 0xdd, 0x1b, 0xd0, 0x7a, 0x49, 0xc2, 0x90, 0x0f, 0x45, 0xf1, 0xe1, 0xf2, 0xb3, 0x24,
+	0x56, 0x91, 0x16, 0x15, 0x92, 0xfb, 0x6f, 0x0b, 0x3e, 0x78, 0x8e, 0x99, 0xe4, 0xee, 0x30, 0x09,
+	0x49, 0x30, 0x7f, 0xd7, 0xf7, 0xe4, 0x2b, 0x70, 0xc4, 0xca, 0x4e, 0x85, 0xa7, 0xea, 0xf9, 0x27,
+	0x82, 0xfe, 0x55, 0x28, 0xa2, 0x13, 0x52, 0x96, 0xcd, 0x80, 0xc9, 0x42, 0x81, 0x0e, 0xd4, 0x6e,
+	0x2d, 0xc0, 0xe4, 0x22, 0xf8, 0xf4, 0x7c, 0x30, 0x41, 0xa2, 0x8e, 0xe6, 0x4c, 0x4b, 0x0d, 0x7a,
+	0x0d, 0x1b, 0xfc, 0x6c, 0x32, 0xc3, 0xb4, 0x00, 0x94, 0x8b, 0xe3, 0xee, 0xf9, 0x80, 0x2f, 0xa4,
+	0xbf, 0x0e, 0xd9, 0x25, 0xba, 0x0e, 0x1d, 0x42, 0x57, 0x4d, 0x26, 0x85, 0x29, 0x07, 0xe9, 0x9d,
+	0xf3, 0x31, 0xe5, 0x3a, 0xd1, 0x21, 0x3b, 0x99, 0xa6, 0x42, 0xbf, 0x84, 0xad, 0x20, 0x89, 0x33,
+	0x46, 0x7d, 0x12, 0x2f, 0x4a, 0xb7, 0x04, 0xea, 0xce, 0xf9, 0xa8, 0x4f, 0x17, 0x21, 0x3a, 0x72,
+	0x2f, 0xa8, 0xa9, 0x07, 0xaf, 0x60, 0xb3, 0x46, 0xf9, 0x8a, 0x05, 0x73, 0x4b, 0x5f, 0x30, 0xc5,
+	0xc1, 0xab, 0x0c, 0xd3, 0x57, 0xdf, 0x21, 0xf4, 0xea, 0xac, 0xaf, 0x00, 0xbc, 0x5d, 0x05, 0xec,
+	0x09, 0x40, 0x2d, 0x4e, 0x47, 0x7c, 0x03, 0x68, 0x99, 0xf6, 0x15, 0x98, 0xa3, 0x2a, 0x26, 0x12,
+	0x98, 0x95, 0x48, 0x1d, 0xd5, 0x83, 0xad, 0x25, 0xe2, 0x57, 0x80, 0x7e, 0x5c, 0x05, 0x95, 0xc7,
+	0x31, 0x3d, 0x50, 0xc7, 0xfc, 0x39, 0x5c, 0x59, 0x49, 0xfb, 0x0a, 0xdc, 0x3b, 0x55, 0xdc, 0x2b,
+	0xc5, 0x3c, 0xaa, 0x04, 0xeb, 0x3b, 0xd3, 0x87, 0x36, 0x27, 0xdc, 0xcb, 0x43, 0xcc, 0xbf, 0x8c,
+	0x14, 0xff, 0x3a, 0x27, 0x14, 0x1f, 0x0b, 0xcc, 0xb6, 0xb7, 0x90, 0xf9, 0xe1, 0xe1, 0x18, 0x4f,
+	0xfd, 0x3c, 0x64, 0x6a, 0x77, 0x17, 0x22, 0xfa, 0x08, 0x9c, 0x13, 0x3f, 0x3b, 0x2a, 0xac, 0xa6,
+	0xb0, 0xc2, 0x89, 0x9f, 0x3d, 0x93, 0x1a, 0xf7, 0x37, 0x06, 0x40, 0xd9, 0x54, 0x74, 0x0f, 0x9a,
+	0x34, 0x0f, 0x71, 0x56, 0x19, 0xef, 0xa5, 0x7d, 0x9b, 0xa7, 0xa2, 0xce, 0x03, 0xd2, 0xb1, 0x28,
+	0x93, 0xef, 0x71, 0x59, 0xe6, 0xe0, 0x39, 0x40, 0xe9, 0xb6, 0x82, 0x86, 0x9b, 0x55, 0x1a, 0xba,
+	0x8b, 0x77, 0xf0, 0x28, 0xbd, 0xfc, 0xbf, 0x1b, 0x60, 0x8b, 0xf5, 0x71, 0x19, 0x02, 0x22, 0x12,
+	0x93, 0x28, 0x8f, 0xd4, 0x68, 0x2c, 0x44, 0x61, 0xf1, 0xcf, 0x84, 0xc5, 0x54, 0x16, 0xff, 0xac,
+	0xb0, 0x14, 0xb4, 0x34, 0xa4, 0xe5, 0x1c, 0xd2, 0x9a, 0x75, 0xd2, 0xd0, 0x77, 0xa0, 0xc5, 0x1d,
+	0x22, 0x12, 0x17, 0x07, 0xfc, 0x13, 0x3f, 0x3b, 0x20, 0xf1, 0xc2, 0xe0, 0x9f, 0xf5, 0x5b, 0xa5,
+	0xc1, 0x3f, 0xe3, 0x2f, 0x0b, 0x4e, 0x12, 0x12, 0xe0, 0xac, 0xdf, 0x1e, 0x9a, 0xfc, 0x65, 0x4a,
+	0x74, 0x7f, 0x6b, 0x80, 0xa3, 0x6d, 0x02, 0xf4, 0x59, 0xb5, 0x03, 0x57, 0xeb, 0xbb, 0xe4, 0x52,
+	0x2d, 0xd8, 0xbf, 0xa0, 0x05, 0xdf, 0xab, 0xb6, 0x60, 0xa3, 0x7c, 0x49, 0xbd, 0x07, 0xff, 0x30,
+	0xc0, 0x51, 0xfb, 0xe9, 0x7d, 0xbb, 0x60, 0x9e, 0xdb, 0x05, 0xf3, 0xdc, 0x2e, 0x98, 0xff, 0xcd,
+	0x2e, 0x98, 0x65, 0x17, 0x7e, 0x6f, 0x40, 0xb7, 0x32, 0x36, 0xd0, 0x6e, 0xb5, 0x0f, 0xd7, 0x97,
+	0x27, 0xcb, 0xa5, 0x3a, 0xf1, 0xd5, 0x05, 0x9d, 0x58, 0x39, 0x14, 0x35, 0xc2, 0xf5, 0x5e, 0xfc,
+	0xd1, 0x00, 0x90, 0x63, 0xe8, 0x7d, 0x27, 0x82, 0x7d, 0xf9, 0x89, 0x80, 0xae, 0x81, 0x9d, 0xe1,
+	0x38, 0x23, 0x8c, 0x9c, 0xca, 0xdf, 0x87, 0xb6, 0x57, 0x2a, 0x38, 0x70, 0xea, 0x33, 0x86, 0x69,
+	0x2c, 0x3a, 0x62, 0x7b, 0x85, 0xa8, 0x93, 0x6b, 0x89, 0xfa, 0x17, 0xe4, 0xfe, 0xce, 0x80, 0x8e,
+	0x3e, 0x3e, 0xd1, 0xb8, 0xca, 0xed, 0xb5, 0xa5, 0x01, 0x7b, 0x29, 0x6a, 0x5f, 0x5c, 0x40, 0xed,
+	0xca, 0x0f, 0x58, 0xc9, 0x9f, 0xce, 0xec, 0x1f, 0xc4, 0xcd, 0x43, 0x31, 0x88, 0xf9, 0xbf, 0xcb,
+	0x5b, 0x3c, 0x97, 0xe9, 0xd9, 0x9e, 0x78, 0x46, 0x57, 0xc0, 0x22, 0xd3, 0x23, 0x99, 0x82, 0x38,
+	0xea, 0x90, 0xe9, 0x4b, 0x3c, 0xe7, 0x6b, 0x8d, 0x4c, 0x8f, 0x18, 0xff, 0x5f, 0x91, 0xff, 0x9a,
+	0x16, 0x99, 0x8a, 0xbf, 0x15, 0x69, 0xe0, 0x07, 0x19, 0x45, 0xa2, 0x45, 0xa6, 0x7c, 0xc8, 0x89,
+	0x9f, 0xed, 0xe9, 0x51, 0x9c, 0x47, 0x13, 0x4c, 0x05, 0x87, 0x86, 0xd7, 0x26, 0xd3, 0x57, 0x42,
+	0x56, 0x46, 0x79, 0x14, 0x10, 0xab, 0xda, 0xe6, 0x46, 0x99, 0xb4, 0xfb, 0x33, 0xe8, 0xd5, 0xbf,
+	0x16, 0xe8, 0x33, 0x70, 0xca, 0xcf, 0x7b, 0x41, 0xe8, 0x66, 0xed, 0xcb, 0xe2, 0xe9, 0x3e, 0xcb,
+	0x4c, 0xba, 0xbb, 0x00, 0xe5, 0x8d, 0x0a, 0xff, 0x33, 0x8e, 0x2e, 0xfe, 0x33, 0x76, 0x5f, 0x42,
+	0x47, 0xbf, 0xa2, 0xb8, 0x64, 0x58, 0x79, 0x62, 0x5c, 0xd7, 0x4e, 0x8c, 0xee, 0x23, 0xd8, 0x7a,
+	0x8e, 0x99, 0xf4, 0xe5, 0xf4, 0x89, 0x44, 0x6e, 0x83, 0xfa, 0xb7, 0xed, 0x1b, 0xda, 0x98, 0x5a,
+	0xfa, 0xf3, 0x1d, 0xff, 0x69, 0x1d, 0x6c, 0x75, 0xaf, 0x92, 0x50, 0x74, 0x1f, 0x36, 0x94, 0xa0,
+	0xd2, 0x43, 0xf5, 0x3b, 0xa3, 0xc1, 0xf2, 0x05, 0x8b, 0xbb, 0x86, 0x7e, 0x0c, 0x1b, 0xd5, 0x14,
+	0xd0, 0x87, 0xc5, 0x49, 0xab, 0x9a, 0xd7, 0xea, 0xf0, 0x9b, 0xd0, 0x38, 0x24, 0xf1, 0x0c, 0x81,
+	0x30, 0x8a, 0x9b, 0x97, 0x41, 0xf5, 0x62, 0xc6, 0x5d, 0x43, 0xb7, 0xa0, 0xc1, 0x8f, 0xda, 0xa8,
+	0x23, 0x0c, 0xea, 0xd4, 0xbd, 0xec, 0xf6, 0x10, 0x36, 0x6b, 0xe7, 0xbb, 0x0a, 0xec, 0x77, 0xcf,
+	0x3d, 0x01, 0xba, 0x6b, 0xe8, 0x2e, 0xd8, 0xaf, 0x0b, 0x0b, 0xaa, 0x31, 0xb6, 0xf4, 0xa6, 0xf1,
+	0xbf, 0x0c, 0xb0, 0xf9, 0x8d, 0x08, 0xce, 0xb2, 0x84, 0xa2, 0x1d, 0x68, 0x29, 0x41, 0x71, 0x56,
+	0xde, 0x97, 0xfc, 0x3f, 0x15, 0xfd, 0x4f, 0x5e, 0x74, 0x3e, 0x09, 0x49, 0xc6, 0xaf, 0xf0, 0xee,
+	0x40, 0x4b, 0x09, 0xcb, 0x45, 0x2f, 0x25, 0xf9, 0xbf, 0x59, 0xf0, 0x9f, 0xd7, 0x61, 0xf3, 0x35,
+	0xa3, 0xd8, 0x8f, 0xca, 0x6d, 0xf2, 0x00, 0xba, 0x52, 0x55, 0xdd, 0x25, 0xe5, 0xfd, 0xeb, 0x60,
+	0x4b, 0x57, 0x28, 0xa8, 0x91, 0x71, 0xcf, 0xf8, 0x56, 0xee, 0x94, 0x89, 0x25, 0x2e, 0xaa, 0x77,
+	0xff, 0x33, 0x00, 0xa3, 0x76, 0x18, 0x4d, 0xe6, 0x16, 0x00, 0x00,
 }
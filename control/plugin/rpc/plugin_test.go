@@ -0,0 +1,92 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rpc
+
+import (
+	"testing"
+
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPolicyKey(t *testing.T) {
+	Convey("Policy keys", t, func() {
+		Convey("are unique for namespaces with dots in their elements", func() {
+			So(policyKey([]string{"a.b", "c"}), ShouldNotEqual, policyKey([]string{"a", "b.c"}))
+		})
+		Convey("are split back into the namespace", func() {
+			for _, ns := range [][]string{
+				{"intel", "mock"},
+				{"host.example.com", "cpu"},
+				{`a\`, ".b", `c\.d`},
+			} {
+				So(splitPolicyKey(policyKey(ns)), ShouldResemble, ns)
+			}
+		})
+		Convey("of older plugins are split on dots", func() {
+			So(splitPolicyKey("intel.mock.foo"), ShouldResemble, []string{"intel", "mock", "foo"})
+		})
+	})
+}
+
+func TestConfigPolicyReply(t *testing.T) {
+	Convey("Given policies of namespaces with dots in their elements", t, func() {
+		policy := cpolicy.New()
+		for _, ns := range [][]string{{"intel", "a.b", "c"}, {"intel", "a", "b.c"}} {
+			node := cpolicy.NewPolicyNode()
+			r, err := cpolicy.NewStringRule(ns[2], true)
+			So(err, ShouldBeNil)
+			node.Add(r)
+			policy.Add(ns, node)
+		}
+		reply, err := NewGetConfigPolicyReply(policy)
+		So(err, ShouldBeNil)
+		So(reply.StringPolicy, ShouldHaveLength, 2)
+		Convey("they are kept apart when the key lists are missing", func() {
+			for _, p := range reply.StringPolicy {
+				p.Key = nil
+			}
+			result := ToConfigPolicy(reply)
+			So(result.Get([]string{"intel", "a.b", "c"}).RulesAsTable(), ShouldHaveLength, 1)
+			So(result.Get([]string{"intel", "a", "b.c"}).RulesAsTable(), ShouldHaveLength, 1)
+		})
+	})
+	Convey("Given a policy with constraints", t, func() {
+		policy := cpolicy.New()
+		node := cpolicy.NewPolicyNode()
+		c1, err := cpolicy.NewRequiredTogether("username", "password")
+		So(err, ShouldBeNil)
+		c2, err := cpolicy.NewRequiredIf("tls", true, "tls_cert", "tls_key")
+		So(err, ShouldBeNil)
+		c3, err := cpolicy.NewRequiredIf("port", 443, "tls_cert")
+		So(err, ShouldBeNil)
+		node.AddConstraints(c1, c2, c3)
+		policy.Add([]string{"intel", "mock"}, node)
+		reply, err := NewGetConfigPolicyReply(policy)
+		So(err, ShouldBeNil)
+		So(reply.ConstraintPolicy, ShouldHaveLength, 1)
+		Convey("they are carried over to the config policy", func() {
+			result := ToConfigPolicy(reply)
+			So(result.Get([]string{"intel", "mock"}).Constraints(), ShouldResemble, []*cpolicy.Constraint{c1, c2, c3})
+		})
+	})
+}
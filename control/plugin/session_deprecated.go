@@ -293,6 +293,8 @@ func init() {
 	gob.RegisterName("conf_value_int", *(&ctypes.ConfigValueInt{}))
 	gob.RegisterName("conf_value_float", *(&ctypes.ConfigValueFloat{}))
 	gob.RegisterName("conf_value_bool", *(&ctypes.ConfigValueBool{}))
+	gob.RegisterName("conf_value_duration", *(&ctypes.ConfigValueDuration{}))
+	gob.RegisterName("conf_value_bytesize", *(&ctypes.ConfigValueByteSize{}))
 
 	gob.RegisterName("conf_policy_node", cpolicy.NewPolicyNode())
 	gob.RegisterName("conf_data_node", &cdata.ConfigDataNode{})
@@ -300,6 +302,8 @@ func init() {
 	gob.RegisterName("conf_policy_int", &cpolicy.IntRule{})
 	gob.RegisterName("conf_policy_float", &cpolicy.FloatRule{})
 	gob.RegisterName("conf_policy_bool", &cpolicy.BoolRule{})
+	gob.RegisterName("conf_policy_duration", &cpolicy.DurationRule{})
+	gob.RegisterName("conf_policy_bytesize", &cpolicy.ByteSizeRule{})
 }
 
 // simpleFormatter is a logrus formatter that includes only the message.
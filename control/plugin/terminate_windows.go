@@ -0,0 +1,31 @@
+// +build windows
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import "os"
+
+// terminate does nothing on Windows, which can't signal a process to stop.
+// The plugin was asked to stop by its Kill RPC and is given
+// KillGracePeriod to exit before its process is terminated.
+func terminate(p *os.Process) error {
+	return nil
+}
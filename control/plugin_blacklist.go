@@ -0,0 +1,181 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/serror"
+)
+
+var (
+	// ErrPluginBlacklisted - error message when a blacklisted plugin is loaded or started
+	ErrPluginBlacklisted = errors.New("Plugin is blacklisted after crashing repeatedly")
+
+	// ErrPluginNotBlacklisted - error message when clearing a plugin which is not blacklisted
+	ErrPluginNotBlacklisted = errors.New("Plugin is not blacklisted")
+)
+
+// pluginBlacklist counts the crashes of the plugin binaries and blacklists a
+// binary crashing `crashes` times within `window`.  Binaries are identified by
+// their checksum so that a blacklisted binary stays blacklisted when it is
+// loaded again.
+type pluginBlacklist struct {
+	*sync.Mutex
+	crashes int
+	window  time.Duration
+	// the time of the recent crashes of the binaries
+	crashTimes map[string][]time.Time
+	entries    map[string]core.BlacklistedPlugin
+}
+
+// newPluginBlacklist returns a plugin blacklist, blacklisting is disabled when
+// crashes is 0
+func newPluginBlacklist(crashes int, window time.Duration) *pluginBlacklist {
+	return &pluginBlacklist{
+		Mutex:      &sync.Mutex{},
+		crashes:    crashes,
+		window:     window,
+		crashTimes: map[string][]time.Time{},
+		entries:    map[string]core.BlacklistedPlugin{},
+	}
+}
+
+// crashed records a crash of the plugin and returns true when the plugin got
+// blacklisted by this crash, false when it was already blacklisted
+func (b *pluginBlacklist) crashed(lp *loadedPlugin) bool {
+	if b.crashes < 1 || lp.Details == nil || lp.Details.Uri != nil {
+		return false
+	}
+	checkSum := hex.EncodeToString(lp.Details.CheckSum[:])
+	b.Lock()
+	defer b.Unlock()
+	if _, ok := b.entries[checkSum]; ok {
+		return false
+	}
+	now := time.Now()
+	recent := []time.Time{}
+	for _, t := range b.crashTimes[checkSum] {
+		if now.Sub(t) < b.window {
+			recent = append(recent, t)
+		}
+	}
+	recent = append(recent, now)
+	if len(recent) < b.crashes {
+		b.crashTimes[checkSum] = recent
+		return false
+	}
+	delete(b.crashTimes, checkSum)
+	b.entries[checkSum] = core.BlacklistedPlugin{
+		Name:     lp.Name(),
+		Version:  lp.Version(),
+		Type:     core.PluginType(lp.Type),
+		Path:     lp.Details.Path,
+		CheckSum: checkSum,
+		Crashes:  len(recent),
+		Since:    now,
+	}
+	return true
+}
+
+// blacklisted returns true when the binary of the given checksum is blacklisted
+func (b *pluginBlacklist) blacklisted(checkSum [sha256.Size]byte) bool {
+	b.Lock()
+	defer b.Unlock()
+	_, ok := b.entries[hex.EncodeToString(checkSum[:])]
+	return ok
+}
+
+// all returns the blacklisted plugins, the most recently blacklisted first
+func (b *pluginBlacklist) all() []core.BlacklistedPlugin {
+	b.Lock()
+	defer b.Unlock()
+	entries := make([]core.BlacklistedPlugin, 0, len(b.entries))
+	for _, e := range b.entries {
+		entries = append(entries, e)
+	}
+	sort.Sort(blacklistedPluginsBySince(entries))
+	return entries
+}
+
+// blacklistedPluginsBySince sorts the blacklisted plugins, the most recently
+// blacklisted first
+type blacklistedPluginsBySince []core.BlacklistedPlugin
+
+func (b blacklistedPluginsBySince) Len() int           { return len(b) }
+func (b blacklistedPluginsBySince) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b blacklistedPluginsBySince) Less(i, j int) bool { return b[i].Since.After(b[j].Since) }
+
+// clear removes the binary of the given checksum from the blacklist, or all
+// the binaries when checkSum is empty
+func (b *pluginBlacklist) clear(checkSum string) error {
+	b.Lock()
+	defer b.Unlock()
+	if checkSum == "" {
+		b.entries = map[string]core.BlacklistedPlugin{}
+		b.crashTimes = map[string][]time.Time{}
+		return nil
+	}
+	if _, ok := b.entries[checkSum]; !ok {
+		return ErrPluginNotBlacklisted
+	}
+	delete(b.entries, checkSum)
+	return nil
+}
+
+// PluginBlacklist returns the plugin binaries blacklisted after crashing
+// repeatedly
+func (p *pluginControl) PluginBlacklist() []core.BlacklistedPlugin {
+	if p.pluginBlacklist == nil {
+		return []core.BlacklistedPlugin{}
+	}
+	return p.pluginBlacklist.all()
+}
+
+// ClearPluginBlacklist removes the binary of the given checksum from the
+// blacklist, all the binaries are removed when checkSum is empty.  A cleared
+// binary is restarted again when it's loaded after being unloaded.
+func (p *pluginControl) ClearPluginBlacklist(checkSum string) serror.SnapError {
+	f := map[string]interface{}{
+		"_block":   "clear-plugin-blacklist",
+		"checksum": checkSum,
+	}
+	if p.pluginBlacklist == nil {
+		if checkSum == "" {
+			return nil
+		}
+		se := serror.New(ErrPluginNotBlacklisted)
+		se.SetFields(f)
+		return se
+	}
+	if err := p.pluginBlacklist.clear(checkSum); err != nil {
+		se := serror.New(err)
+		se.SetFields(f)
+		return se
+	}
+	controlLogger.WithFields(f).Info("plugin blacklist cleared")
+	return nil
+}
@@ -0,0 +1,116 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func blacklistTestPlugin(binary string) *loadedPlugin {
+	return &loadedPlugin{
+		Meta: plugin.PluginMeta{Name: "mock", Version: 1, Type: plugin.CollectorPluginType},
+		Details: &pluginDetails{
+			Path:     "/opt/snap/plugins/" + binary,
+			CheckSum: sha256.Sum256([]byte(binary)),
+		},
+	}
+}
+
+func TestPluginBlacklist(t *testing.T) {
+	Convey("pluginBlacklist", t, func() {
+		lp := blacklistTestPlugin("snap-plugin-collector-mock")
+		checkSum := hex.EncodeToString(lp.Details.CheckSum[:])
+
+		Convey("blacklists a plugin crashing repeatedly within the window", func() {
+			b := newPluginBlacklist(3, time.Minute)
+			So(b.crashed(lp), ShouldBeFalse)
+			So(b.crashed(lp), ShouldBeFalse)
+			So(b.blacklisted(lp.Details.CheckSum), ShouldBeFalse)
+			So(b.crashed(lp), ShouldBeTrue)
+			So(b.blacklisted(lp.Details.CheckSum), ShouldBeTrue)
+
+			entries := b.all()
+			So(entries, ShouldHaveLength, 1)
+			So(entries[0].Name, ShouldEqual, "mock")
+			So(entries[0].Type, ShouldEqual, core.CollectorPluginType)
+			So(entries[0].CheckSum, ShouldEqual, checkSum)
+			So(entries[0].Crashes, ShouldEqual, 3)
+
+			Convey("and reports it only once", func() {
+				So(b.crashed(lp), ShouldBeFalse)
+				So(b.blacklisted(lp.Details.CheckSum), ShouldBeTrue)
+				So(b.all(), ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("forgets the crashes outside of the window", func() {
+			b := newPluginBlacklist(2, 50*time.Millisecond)
+			So(b.crashed(lp), ShouldBeFalse)
+			time.Sleep(100 * time.Millisecond)
+			So(b.crashed(lp), ShouldBeFalse)
+			So(b.blacklisted(lp.Details.CheckSum), ShouldBeFalse)
+		})
+
+		Convey("counts the crashes of each binary separately", func() {
+			b := newPluginBlacklist(2, time.Minute)
+			other := blacklistTestPlugin("snap-plugin-collector-other")
+			So(b.crashed(lp), ShouldBeFalse)
+			So(b.crashed(other), ShouldBeFalse)
+			So(b.blacklisted(lp.Details.CheckSum), ShouldBeFalse)
+			So(b.blacklisted(other.Details.CheckSum), ShouldBeFalse)
+		})
+
+		Convey("does not blacklist when disabled", func() {
+			b := newPluginBlacklist(0, time.Minute)
+			for i := 0; i < 5; i++ {
+				So(b.crashed(lp), ShouldBeFalse)
+			}
+			So(b.all(), ShouldBeEmpty)
+		})
+
+		Convey("clears a blacklisted plugin", func() {
+			b := newPluginBlacklist(1, time.Minute)
+			So(b.crashed(lp), ShouldBeTrue)
+			So(b.clear("unknown"), ShouldEqual, ErrPluginNotBlacklisted)
+			So(b.clear(checkSum), ShouldBeNil)
+			So(b.blacklisted(lp.Details.CheckSum), ShouldBeFalse)
+			So(b.clear(checkSum), ShouldEqual, ErrPluginNotBlacklisted)
+		})
+
+		Convey("clears the whole blacklist", func() {
+			b := newPluginBlacklist(1, time.Minute)
+			So(b.crashed(lp), ShouldBeTrue)
+			So(b.crashed(blacklistTestPlugin("snap-plugin-collector-other")), ShouldBeTrue)
+			So(b.all(), ShouldHaveLength, 2)
+			So(b.clear(""), ShouldBeNil)
+			So(b.all(), ShouldBeEmpty)
+		})
+	})
+}
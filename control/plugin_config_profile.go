@@ -0,0 +1,156 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/cdata"
+	"github.com/intelsdi-x/snap/pkg/cfgfile"
+)
+
+const (
+	// PLUGIN_CONFIG_PROFILE_CONSTRAINTS is the JSON schema of the files of the
+	// plugin config directory
+	PLUGIN_CONFIG_PROFILE_CONSTRAINTS = `{
+		"$schema": "http://json-schema.org/draft-04/schema#",
+		"title": "snapteld plugin config profile",
+		"type": "object",
+		"properties": {
+			"type": {
+				"type": "string",
+				"enum": ["collector", "processor", "publisher", "streaming-collector"]
+			},
+			"name": {
+				"type": "string",
+				"minLength": 1
+			},
+			"version": {
+				"type": "integer",
+				"minimum": 0
+			},
+			"config": {
+				"type": "object",
+				"properties": {},
+				"additionalProperties": true
+			}
+		},
+		"required": ["type", "name", "config"],
+		"additionalProperties": false
+	}`
+)
+
+// pluginConfigProfile is a file of the plugin config directory holding the
+// default config of a plugin, of all its versions when the version is 0
+type pluginConfigProfile struct {
+	Type    string                `json:"type"`
+	Name    string                `json:"name"`
+	Version int                   `json:"version"`
+	Config  *cdata.ConfigDataNode `json:"config"`
+}
+
+// readPluginConfigProfiles reads the profiles of the JSON and YAML files of
+// the directory in the order of their names.  The files which can't be read
+// are reported and skipped.
+func readPluginConfigProfiles(dir string) ([]*pluginConfigProfile, []error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, []error{err}
+	}
+	var profiles []*pluginConfigProfile
+	var errs []error
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(f.Name())) {
+		case ".json", ".yaml", ".yml":
+		default:
+			continue
+		}
+		path := filepath.Join(dir, f.Name())
+		profile := &pluginConfigProfile{}
+		if serrs := cfgfile.Read(path, profile, PLUGIN_CONFIG_PROFILE_CONSTRAINTS); serrs != nil {
+			for _, serr := range serrs {
+				errs = append(errs, fmt.Errorf("%s: %v %v", path, serr.Error(), serr.Fields()))
+			}
+			continue
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, errs
+}
+
+// setProfiles replaces the profiles of the plugins.  The profiles are the
+// defaults of the global config: they are overridden by the config of the
+// snapteld config file which is itself overridden by the config of the tasks.
+func (p *pluginConfig) setProfiles(profiles []*pluginConfigProfile) {
+	// clear cache
+	p.pluginCache = make(map[string]*cdata.ConfigDataNode)
+	for _, item := range []*pluginTypeConfigItem{p.Collector, p.Processor, p.Publisher} {
+		item.profiles = map[string]*pluginConfigItem{}
+	}
+	for _, profile := range profiles {
+		pluginType, err := core.ToPluginType(profile.Type)
+		if err != nil {
+			continue
+		}
+		configItem := p.switchPluginConfigType(pluginType)
+		res, ok := configItem.profiles[profile.Name]
+		if !ok {
+			res = newPluginConfigItem()
+			configItem.profiles[profile.Name] = res
+		}
+		if profile.Version < 1 {
+			res.Merge(profile.Config)
+			continue
+		}
+		if _, ok := res.Versions[profile.Version]; !ok {
+			res.Versions[profile.Version] = cdata.NewNode()
+		}
+		res.Versions[profile.Version].Merge(profile.Config)
+	}
+}
+
+// loadPluginConfigProfiles reads the plugin config directory again so the
+// plugins being loaded or reloaded pick up the profiles added or changed since
+func (p *pluginControl) loadPluginConfigProfiles() {
+	if p.Config == nil || p.Config.PluginConfigDir == "" {
+		return
+	}
+	cfg := p.pluginManager.GetPluginConfig()
+	if cfg == nil {
+		return
+	}
+	profiles, errs := readPluginConfigProfiles(p.Config.PluginConfigDir)
+	for _, err := range errs {
+		controlLogger.WithFields(log.Fields{
+			"_block":            "load-plugin-config-profiles",
+			"plugin-config-dir": p.Config.PluginConfigDir,
+		}).Error(err)
+	}
+	cfg.setProfiles(profiles)
+}
@@ -0,0 +1,88 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/cdata"
+	"github.com/intelsdi-x/snap/core/ctypes"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPluginConfigProfiles(t *testing.T) {
+	Convey("plugin config profiles", t, func() {
+		dir, err := ioutil.TempDir("", "snap-plugin-config-dir")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		files := map[string]string{
+			"10-mock.json":   `{"type": "collector", "name": "mock", "config": {"host": "localhost", "port": 8080}}`,
+			"20-mock-2.yaml": "type: collector\nname: mock\nversion: 2\nconfig:\n  port: 9090\n",
+			"30-bad.yml":     "type: collector\nconfig:\n  port: 1\n",
+			"README":         "not a profile",
+		}
+		for name, content := range files {
+			So(ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644), ShouldBeNil)
+		}
+
+		Convey("are read from the JSON and YAML files of the directory", func() {
+			profiles, errs := readPluginConfigProfiles(dir)
+			So(errs, ShouldNotBeEmpty)
+			So(profiles, ShouldHaveLength, 2)
+			So(profiles[0].Name, ShouldEqual, "mock")
+			So(profiles[0].Version, ShouldEqual, 0)
+			So(profiles[1].Version, ShouldEqual, 2)
+		})
+
+		Convey("are overridden by the global config", func() {
+			profiles, _ := readPluginConfigProfiles(dir)
+			cfg := newPluginConfig()
+			cfg.setProfiles(profiles)
+
+			table := cfg.getPluginConfigDataNode(core.CollectorPluginType, "mock", 1).Table()
+			So(table["host"], ShouldResemble, ctypes.ConfigValueStr{Value: "localhost"})
+			So(table["port"], ShouldResemble, ctypes.ConfigValueInt{Value: 8080})
+			table = cfg.getPluginConfigDataNode(core.CollectorPluginType, "mock", 2).Table()
+			So(table["port"], ShouldResemble, ctypes.ConfigValueInt{Value: 9090})
+			So(cfg.getPluginConfigDataNode(core.PublisherPluginType, "mock", 2).Table(), ShouldBeEmpty)
+
+			cdn := cdata.NewNode()
+			cdn.AddItem("host", ctypes.ConfigValueStr{Value: "db.example.com"})
+			cfg.mergePluginConfigDataNodeAll(cdn)
+			table = cfg.getPluginConfigDataNode(core.CollectorPluginType, "mock", 2).Table()
+			So(table["host"], ShouldResemble, ctypes.ConfigValueStr{Value: "db.example.com"})
+			So(table["port"], ShouldResemble, ctypes.ConfigValueInt{Value: 9090})
+
+			Convey("and replaced when they are read again", func() {
+				cfg.setProfiles(nil)
+				table = cfg.getPluginConfigDataNode(core.CollectorPluginType, "mock", 2).Table()
+				So(table["host"], ShouldResemble, ctypes.ConfigValueStr{Value: "db.example.com"})
+				So(table, ShouldNotContainKey, "port")
+			})
+		})
+	})
+}
@@ -0,0 +1,140 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/control_event"
+	"github.com/intelsdi-x/snap/core/ctypes"
+	"github.com/intelsdi-x/snap/core/serror"
+)
+
+// ReloadPluginConfig applies the global config of the loaded plugins matching
+// the type, name and version (all the plugins when the name is empty, all the
+// versions when the version is lower than 1) without reloading them.  The
+// profiles of the plugin config dir are read again.  The config is validated
+// against the config policy of each plugin, pushed to its running instances
+// and the subscriptions of the tasks are processed again so the metrics they
+// collect pick up the new config.  The pool items of the config are applied
+// to the pool of the running instances.
+func (p *pluginControl) ReloadPluginConfig(pluginType core.PluginType, name string, ver int) []serror.SnapError {
+	p.loadPluginConfigProfiles()
+	var serrs []serror.SnapError
+	for _, lp := range p.pluginManager.all() {
+		if name != "" && (core.PluginType(lp.Type) != pluginType || lp.Name() != name || (ver > 0 && lp.Version() != ver)) {
+			continue
+		}
+		fields := map[string]interface{}{
+			"plugin-name":    lp.Name(),
+			"plugin-version": lp.Version(),
+			"plugin-type":    lp.TypeName(),
+		}
+		table, errs := p.pluginConfigTable(lp)
+		if len(errs) > 0 {
+			for _, err := range errs {
+				serrs = append(serrs, serror.New(err, fields))
+			}
+			continue
+		}
+		instances := 0
+		if p.pluginRunner != nil {
+			instances, errs = p.pluginRunner.AvailablePlugins().setConfig(lp.Key(), table)
+			for _, err := range errs {
+				serrs = append(serrs, serror.New(err, fields))
+			}
+			if err := p.configurePool(lp); err != nil {
+				serrs = append(serrs, serror.New(err, fields))
+			}
+		}
+		controlLogger.WithFields(log.Fields{
+			"_block":         "reload-plugin-config",
+			"plugin-name":    lp.Name(),
+			"plugin-version": lp.Version(),
+			"plugin-type":    lp.TypeName(),
+			"instances":      instances,
+		}).Info("plugin config reloaded")
+		p.eventManager.Emit(&control_event.PluginConfigReloadedEvent{
+			Name:      lp.Name(),
+			Version:   lp.Version(),
+			Type:      int(lp.Type),
+			Key:       lp.Key(),
+			Instances: instances,
+		})
+	}
+	serrs = append(serrs, p.subscriptionGroups.Process()...)
+	return serrs
+}
+
+// configurePool applies the pool items of the plugin config to the pool of the
+// running instances of the plugin, the pool is configured when its first
+// instance starts if there is none yet
+func (p *pluginControl) configurePool(lp *loadedPlugin) error {
+	cfg := p.pluginManager.GetPluginConfig()
+	if cfg == nil {
+		return nil
+	}
+	// invalid items are skipped, the valid ones are still applied
+	poolCfg, err := cfg.getPluginPoolConfig(core.PluginType(lp.Type), lp.Name(), lp.Version())
+	pool, serr := p.pluginRunner.AvailablePlugins().getPool(lp.Key())
+	if serr != nil || pool == nil {
+		return err
+	}
+	if cerr := pool.Configure(poolCfg); cerr != nil {
+		return cerr
+	}
+	return err
+}
+
+// pluginConfigTable returns the global config of the plugin with the defaults
+// of its config policy applied.  Only the items set are validated, required
+// items may be set in the config of the tasks.
+func (p *pluginControl) pluginConfigTable(lp *loadedPlugin) (map[string]ctypes.ConfigValue, []error) {
+	table := map[string]ctypes.ConfigValue{}
+	if cfg := p.pluginManager.GetPluginConfig(); cfg != nil {
+		if cdn := cfg.getPluginConfigDataNode(core.PluginType(lp.Type), lp.Name(), lp.Version()); cdn != nil {
+			for k, v := range cdn.Table() {
+				table[k] = v
+			}
+		}
+	}
+	if lp.ConfigPolicy == nil {
+		return table, nil
+	}
+	var errs []error
+	nodes := lp.ConfigPolicy.GetAll()
+	for _, node := range nodes {
+		if perrs := node.Validate(table); perrs.HasErrors() {
+			errs = append(errs, perrs.Errors()...)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	for _, node := range nodes {
+		for k, v := range node.Defaults() {
+			if _, ok := table[k]; !ok {
+				table[k] = v
+			}
+		}
+	}
+	return table, nil
+}
@@ -0,0 +1,110 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+
+	"github.com/intelsdi-x/gomit"
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/cdata"
+	"github.com/intelsdi-x/snap/core/ctypes"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestReloadPluginConfig(t *testing.T) {
+	Convey("pluginControl.ReloadPluginConfig()", t, func() {
+		policy := cpolicy.New()
+		node := cpolicy.NewPolicyNode()
+		user, _ := cpolicy.NewStringRule("user", true)
+		host, _ := cpolicy.NewStringRule("host", false, "localhost")
+		port, _ := cpolicy.NewIntegerRule("port", false, 8080)
+		port.SetMaximum(65535)
+		node.Add(user, host, port)
+		policy.Add([]string{"intel", "mock"}, node)
+
+		lp := &loadedPlugin{
+			Meta:         plugin.PluginMeta{Name: "mock", Version: 1, Type: plugin.CollectorPluginType},
+			Type:         plugin.CollectorPluginType,
+			ConfigPolicy: policy,
+		}
+		cfg := newPluginConfig()
+		pm := newPluginManager(OptSetPluginConfig(cfg))
+		So(pm.loadedPlugins.add(lp), ShouldBeNil)
+		c := &pluginControl{
+			pluginManager: pm,
+			pluginRunner:  newRunner(),
+			eventManager:  gomit.NewEventController(),
+		}
+		c.subscriptionGroups = newSubscriptionGroups(c)
+
+		Convey("applies the defaults of the config policy to the global config", func() {
+			cdn := cdata.NewNode()
+			cdn.AddItem("host", ctypes.ConfigValueStr{Value: "db.example.com"})
+			cfg.mergePluginConfigDataNode(core.CollectorPluginType, "mock", 1, cdn)
+			table, errs := c.pluginConfigTable(lp)
+			So(errs, ShouldBeEmpty)
+			So(table["host"], ShouldResemble, ctypes.ConfigValueStr{Value: "db.example.com"})
+			So(table["port"], ShouldResemble, ctypes.ConfigValueInt{Value: 8080})
+			So(table, ShouldNotContainKey, "user")
+			So(c.ReloadPluginConfig(core.CollectorPluginType, "mock", 1), ShouldBeEmpty)
+		})
+
+		Convey("reports the items which don't match the config policy", func() {
+			cdn := cdata.NewNode()
+			cdn.AddItem("port", ctypes.ConfigValueInt{Value: 70000})
+			cfg.mergePluginConfigDataNode(core.CollectorPluginType, "mock", 1, cdn)
+			_, errs := c.pluginConfigTable(lp)
+			So(errs, ShouldHaveLength, 1)
+			serrs := c.ReloadPluginConfig(core.CollectorPluginType, "mock", 0)
+			So(serrs, ShouldHaveLength, 1)
+			So(serrs[0].Fields()["plugin-name"], ShouldEqual, "mock")
+		})
+
+		Convey("applies the pool items to the pool instead of passing them to the plugin", func() {
+			pool, err := c.pluginRunner.AvailablePlugins().getOrCreatePool(lp.Key())
+			So(err, ShouldBeNil)
+			cdn := cdata.NewNode()
+			cdn.AddItem(core.PoolMaxInstancesKey, ctypes.ConfigValueInt{Value: 3})
+			cdn.AddItem(core.PoolRoutingKey, ctypes.ConfigValueStr{Value: core.PoolRoutingSticky})
+			cfg.mergePluginConfigDataNode(core.CollectorPluginType, "mock", 1, cdn)
+			table, errs := c.pluginConfigTable(lp)
+			So(errs, ShouldBeEmpty)
+			So(table, ShouldNotContainKey, core.PoolMaxInstancesKey)
+			So(table, ShouldNotContainKey, core.PoolRoutingKey)
+			So(c.ReloadPluginConfig(core.CollectorPluginType, "mock", 1), ShouldBeEmpty)
+			So(pool.PoolConfig(), ShouldResemble, core.PluginPoolConfig{MaxInstances: 3, Routing: core.PoolRoutingSticky})
+		})
+
+		Convey("skips the plugins which don't match", func() {
+			cdn := cdata.NewNode()
+			cdn.AddItem("port", ctypes.ConfigValueInt{Value: 70000})
+			cfg.mergePluginConfigDataNode(core.CollectorPluginType, "mock", 1, cdn)
+			So(c.ReloadPluginConfig(core.CollectorPluginType, "other", 1), ShouldBeEmpty)
+			So(c.ReloadPluginConfig(core.PublisherPluginType, "mock", 1), ShouldBeEmpty)
+			So(c.ReloadPluginConfig(core.CollectorPluginType, "mock", 2), ShouldBeEmpty)
+		})
+	})
+}
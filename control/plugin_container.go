@@ -0,0 +1,65 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"errors"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core/serror"
+)
+
+// The executors plugins can be run with
+const (
+	// PluginExecutorNative runs plugins as subprocesses of snapteld
+	PluginExecutorNative = "native"
+	// PluginExecutorContainer runs plugins in containers
+	PluginExecutorContainer = "container"
+)
+
+var (
+	// ErrContainerImageRequired - error message when a plugin has to be run in a container but no image is set
+	ErrContainerImageRequired = errors.New("A container image is required to run the plugin in a container")
+)
+
+// containerImage returns the image the plugin is run in, the image declared by
+// the manifest of a plugin package overrides the configured image
+func (p *pluginControl) containerImage(details *pluginDetails) (string, serror.SnapError) {
+	if details.Manifest != nil {
+		if image, ok := details.Manifest.Annotations.Get(plugin.ContainerImageAnnotation); ok && image != "" {
+			return image, nil
+		}
+	}
+	if p.Config.PluginContainerImage != "" {
+		return p.Config.PluginContainerImage, nil
+	}
+	return "", serror.New(ErrContainerImageRequired, map[string]interface{}{
+		"plugin-path": details.Path,
+	})
+}
+
+// newExecutablePlugin returns the executable plugin running the commands of
+// the plugin, in a container when the plugin has a container image
+func newExecutablePlugin(args plugin.Arg, details *pluginDetails, commands []string) (*plugin.ExecutablePlugin, error) {
+	if details.ContainerImage != "" {
+		return plugin.NewContainerizedPlugin(args, details.ContainerImage, details.ExecPath, commands...)
+	}
+	return plugin.NewExecutablePlugin(args, commands...)
+}
@@ -0,0 +1,189 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/serror"
+	"github.com/intelsdi-x/snap/pkg/psigning"
+)
+
+const pluginCacheDirName = "snap-plugin-cache"
+
+var (
+	// ErrDownloadCheckSum - error message when the downloaded plugin doesn't match the expected checksum
+	ErrDownloadCheckSum = errors.New("Downloaded plugin does not match the checksum")
+
+	// ErrDownloadCheckSumRequired - error message when there is no checksum to verify a downloaded plugin with
+	ErrDownloadCheckSumRequired = errors.New("A SHA-256 checksum is required to load a plugin from a URL")
+
+	// pluginDownloadClient is the http client used to download plugins
+	pluginDownloadClient = &http.Client{Timeout: 5 * time.Minute}
+)
+
+// pluginCacheDir returns the directory plugins downloaded from URLs are cached in
+func (p *pluginControl) pluginCacheDir() string {
+	if p.Config.PluginCachePath != "" {
+		return p.Config.PluginCachePath
+	}
+	return filepath.Join(p.GetTempDir(), pluginCacheDirName)
+}
+
+// downloadPlugin downloads the requested plugin from its download URL into the
+// plugin cache, unless it is cached already, and points the requested plugin to
+// the cached file.  A detached signature published next to the plugin
+// (<url>.asc or <url>.sig) is downloaded too when no signature was provided.
+func (p *pluginControl) downloadPlugin(rp *core.RequestedPlugin) serror.SnapError {
+	uri := rp.DownloadUri()
+	f := map[string]interface{}{
+		"_block": "download-plugin",
+		"url":    uri.String(),
+	}
+
+	checkSum, err := p.downloadCheckSum(rp)
+	if err != nil {
+		return serror.New(err, f)
+	}
+
+	name := path.Base(uri.Path)
+	if name == "/" || name == "." {
+		return serror.New(fmt.Errorf("invalid plugin download URL: %s", uri), f)
+	}
+	dir := filepath.Join(p.pluginCacheDir(), hex.EncodeToString(checkSum[:]))
+	cached := filepath.Join(dir, name)
+	if sum, err := fileCheckSum(cached); err == nil && sum == checkSum {
+		controlLogger.WithFields(f).WithField("path", cached).Debug("plugin found in cache")
+	} else {
+		controlLogger.WithFields(f).WithField("path", cached).Info("downloading plugin")
+		if err := downloadFile(uri.String(), dir, cached, checkSum); err != nil {
+			return serror.New(err, f)
+		}
+	}
+	rp.SetPath(cached)
+	rp.SetCheckSum(checkSum)
+
+	if rp.Signature() == nil {
+		for _, ext := range psigning.SignatureFileExtensions {
+			if signature, err := httpGet(uri.String() + ext); err == nil {
+				rp.SetSignature(signature)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// downloadCheckSum returns the checksum provided with the requested plugin.  A
+// checksum published next to the plugin isn't used since it comes from the same
+// server as the plugin, it wouldn't detect a tampered plugin.
+func (p *pluginControl) downloadCheckSum(rp *core.RequestedPlugin) ([sha256.Size]byte, error) {
+	var checkSum [sha256.Size]byte
+	s := strings.TrimSpace(rp.DownloadCheckSum())
+	if s == "" {
+		return checkSum, ErrDownloadCheckSumRequired
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != sha256.Size {
+		return checkSum, fmt.Errorf("invalid SHA-256 checksum: %s", s)
+	}
+	copy(checkSum[:], b)
+	return checkSum, nil
+}
+
+// downloadFile downloads the file at url to path verifying its checksum; the
+// file is written to a temporary file first so path is never left half-written
+func downloadFile(url, dir, path string, checkSum [sha256.Size]byte) error {
+	resp, err := pluginDownloadClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unable to download %s: %s", url, resp.Status)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(dir, filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, h), resp.Body)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	if sum != checkSum {
+		controlLogger.WithFields(log.Fields{
+			"_block":   "download-plugin",
+			"url":      url,
+			"expected": hex.EncodeToString(checkSum[:]),
+			"actual":   hex.EncodeToString(sum[:]),
+		}).Error(ErrDownloadCheckSum)
+		return ErrDownloadCheckSum
+	}
+	if err := os.Chmod(tmp.Name(), 0755); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func httpGet(url string) ([]byte, error) {
+	resp, err := pluginDownloadClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to download %s: %s", url, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+func fileCheckSum(path string) ([sha256.Size]byte, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(b), nil
+}
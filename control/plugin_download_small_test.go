@@ -0,0 +1,118 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDownloadPlugin(t *testing.T) {
+	Convey("downloadPlugin", t, func() {
+		content := []byte("#!/bin/sh\necho plugin\n")
+		sum := sha256.Sum256(content)
+		checkSum := hex.EncodeToString(sum[:])
+		downloads := 0
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/snap-plugin-collector-mock", func(w http.ResponseWriter, r *http.Request) {
+			downloads++
+			w.Write(content)
+		})
+		mux.HandleFunc("/snap-plugin-collector-mock.sha256", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(checkSum + "  snap-plugin-collector-mock\n"))
+		})
+		mux.HandleFunc("/snap-plugin-collector-mock.asc", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("signature"))
+		})
+		mux.HandleFunc("/snap-plugin-collector-other", func(w http.ResponseWriter, r *http.Request) {
+			w.Write(content)
+		})
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		dir, err := ioutil.TempDir("", "snap-plugin-cache")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		config := GetDefaultConfig()
+		config.PluginCachePath = dir
+		c := &pluginControl{Config: config}
+
+		Convey("downloads the plugin into the cache", func() {
+			rp, err := core.NewDownloadRequestedPlugin(server.URL+"/snap-plugin-collector-mock", checkSum)
+			So(err, ShouldBeNil)
+			So(c.downloadPlugin(rp), ShouldBeNil)
+			So(rp.Path(), ShouldEqual, filepath.Join(dir, checkSum, "snap-plugin-collector-mock"))
+			So(rp.CheckSum(), ShouldEqual, sum)
+			So(string(rp.Signature()), ShouldEqual, "signature")
+			b, err := ioutil.ReadFile(rp.Path())
+			So(err, ShouldBeNil)
+			So(b, ShouldResemble, content)
+			fi, err := os.Stat(rp.Path())
+			So(err, ShouldBeNil)
+			So(fi.Mode()&0111, ShouldNotEqual, 0)
+
+			Convey("and loads it from the cache afterwards", func() {
+				rp, err := core.NewDownloadRequestedPlugin(server.URL+"/snap-plugin-collector-mock", checkSum)
+				So(err, ShouldBeNil)
+				So(c.downloadPlugin(rp), ShouldBeNil)
+				So(downloads, ShouldEqual, 1)
+			})
+		})
+
+		Convey("does not trust the checksum published next to the plugin", func() {
+			rp, err := core.NewDownloadRequestedPlugin(server.URL+"/snap-plugin-collector-mock", "")
+			So(err, ShouldBeNil)
+			serr := c.downloadPlugin(rp)
+			So(serr, ShouldNotBeNil)
+			So(serr.Error(), ShouldEqual, ErrDownloadCheckSumRequired.Error())
+			So(downloads, ShouldEqual, 0)
+		})
+
+		Convey("fails when the plugin does not match the checksum", func() {
+			other := sha256.Sum256([]byte("other"))
+			rp, err := core.NewDownloadRequestedPlugin(server.URL+"/snap-plugin-collector-mock", hex.EncodeToString(other[:]))
+			So(err, ShouldBeNil)
+			serr := c.downloadPlugin(rp)
+			So(serr, ShouldNotBeNil)
+			So(serr.Error(), ShouldEqual, ErrDownloadCheckSum.Error())
+			_, err = os.Stat(filepath.Join(dir, hex.EncodeToString(other[:]), "snap-plugin-collector-mock"))
+			So(os.IsNotExist(err), ShouldBeTrue)
+		})
+
+		Convey("fails when the checksum is invalid", func() {
+			rp, err := core.NewDownloadRequestedPlugin(server.URL+"/snap-plugin-collector-other", "not-a-checksum")
+			So(err, ShouldBeNil)
+			So(c.downloadPlugin(rp), ShouldNotBeNil)
+		})
+	})
+}
@@ -46,7 +46,9 @@ import (
 	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
 	"github.com/intelsdi-x/snap/core"
 	"github.com/intelsdi-x/snap/core/cdata"
+	"github.com/intelsdi-x/snap/core/ctypes"
 	"github.com/intelsdi-x/snap/core/serror"
+	"github.com/intelsdi-x/snap/pkg/rpcutil"
 )
 
 const (
@@ -175,6 +177,14 @@ type pluginDetails struct {
 	CACertPaths string
 	TLSEnabled  bool
 	Uri         *url.URL
+	// ResourceLimits are the CPU and memory caps the plugin is run with
+	ResourceLimits plugin.ResourceLimits
+	// ContainerImage is the image the plugin is run in, empty to run the
+	// plugin as a subprocess
+	ContainerImage string
+	// InProcess is the plugin compiled into snapteld, nil for the plugins
+	// run as subprocesses
+	InProcess *inProcessPlugin
 }
 
 type loadedPlugin struct {
@@ -249,6 +259,9 @@ type pluginManager struct {
 	pprof             bool
 	tempDirPath       string
 	grpcSecurity      client.GRPCSecurity
+	// permissiveConfigPolicy makes the plugins which do not provide a config
+	// policy get an empty one instead of failing to load
+	permissiveConfigPolicy bool
 }
 
 func newPluginManager(opts ...pluginManagerOpt) *pluginManager {
@@ -302,6 +315,14 @@ func OptSetPluginConfig(cf *pluginConfig) pluginManagerOpt {
 	}
 }
 
+// OptSetPermissiveConfigPolicy sets whether the plugins which do not provide a
+// config policy are loaded with an empty one
+func OptSetPermissiveConfigPolicy(permissive bool) pluginManagerOpt {
+	return func(p *pluginManager) {
+		p.permissiveConfigPolicy = permissive
+	}
+}
+
 // OptSetPluginTags sets the tags on the plugin manager
 func OptSetPluginTags(tags map[string]map[string]string) pluginManagerOpt {
 	return func(p *pluginManager) {
@@ -359,7 +380,13 @@ func (p *pluginManager) LoadPlugin(details *pluginDetails, emitter gomit.Emitter
 			err     error
 		)
 
-		if lPlugin.Details.Uri == nil {
+		if lPlugin.Details.InProcess != nil {
+			pmLogger.WithFields(log.Fields{
+				"_block":      "load-plugin",
+				"plugin-name": lPlugin.Details.InProcess.meta.Name,
+			}).Info("in-process plugin load called")
+			resp = lPlugin.Details.InProcess.response()
+		} else if lPlugin.Details.Uri == nil {
 			pmLogger.WithFields(log.Fields{
 				"_block": "load-plugin",
 				"path":   filepath.Base(lPlugin.Details.Exec[0]),
@@ -372,13 +399,14 @@ func (p *pluginManager) LoadPlugin(details *pluginDetails, emitter gomit.Emitter
 				commands[i] = filepath.Join(lPlugin.Details.ExecPath, e)
 			}
 
-			ePlugin, err = plugin.NewExecutablePlugin(
+			ePlugin, err = newExecutablePlugin(
 				p.GenerateArgs(int(log.GetLevel())).
 					SetCertPath(details.CertPath).
 					SetKeyPath(details.KeyPath).
 					SetCACertPaths(details.CACertPaths).
 					SetTLSEnabled(details.TLSEnabled),
-				commands...)
+				details,
+				commands)
 			if err != nil {
 				pmLogger.WithFields(log.Fields{
 					"_block": "load-plugin",
@@ -435,7 +463,12 @@ func (p *pluginManager) LoadPlugin(details *pluginDetails, emitter gomit.Emitter
 				}).Error("error during json unmarshal")
 			}
 		}
-		ap, err := newAvailablePlugin(resp, emitter, ePlugin, p.grpcSecurity)
+		var ap *availablePlugin
+		if lPlugin.Details.InProcess != nil {
+			ap, err = newInProcessAvailablePlugin(lPlugin.Details.InProcess, emitter)
+		} else {
+			ap, err = newAvailablePlugin(resp, emitter, ePlugin, p.grpcSecurity)
+		}
 		if err != nil {
 			pmLogger.WithFields(log.Fields{
 				"_block": "load-plugin",
@@ -483,6 +516,15 @@ func (p *pluginManager) LoadPlugin(details *pluginDetails, emitter gomit.Emitter
 			resultChan <- result{nil, serror.New(err)}
 			return
 		}
+		if cp == nil && p.permissiveConfigPolicy {
+			pmLogger.WithFields(log.Fields{
+				"_block":         "load-plugin",
+				"plugin-name":    ap.Name(),
+				"plugin-version": ap.Version(),
+				"plugin-id":      ap.ID(),
+			}).Warning("plugin did not provide a config policy, using an empty one")
+			cp = cpolicy.New()
+		}
 
 		lPlugin.ConfigPolicy = cp
 		lPlugin.Meta = resp.Meta
@@ -490,6 +532,7 @@ func (p *pluginManager) LoadPlugin(details *pluginDetails, emitter gomit.Emitter
 		lPlugin.Token = resp.Token
 		lPlugin.LoadedTime = time.Now()
 		lPlugin.State = LoadedState
+		lPlugin.Details.ResourceLimits = p.resourceLimits(lPlugin)
 
 		if resp.Type == plugin.CollectorPluginType || resp.Type == plugin.StreamCollectorPluginType {
 			cfgNode := p.pluginConfig.getPluginConfigDataNode(core.PluginType(resp.Type), resp.Meta.Name, resp.Meta.Version)
@@ -553,7 +596,8 @@ func (p *pluginManager) LoadPlugin(details *pluginDetails, emitter gomit.Emitter
 				return
 			}
 
-			// Add metric types to metric catalog
+			// Add metric types to metric catalog, all at once
+			nmts := make([]core.Metric, 0, len(metricTypes))
 			for _, nmt := range metricTypes {
 				// If the version is 0 default it to the plugin version
 				// This honors the plugins explicit version but falls back
@@ -569,6 +613,7 @@ func (p *pluginManager) LoadPlugin(details *pluginDetails, emitter gomit.Emitter
 						tags:               nmt.Tags(),
 						description:        nmt.Description(),
 						unit:               nmt.Unit(),
+						dataType:           nmt.DataType(),
 					}
 				}
 				// We quit and throw an error on bad metric versions (<1)
@@ -590,26 +635,27 @@ func (p *pluginManager) LoadPlugin(details *pluginDetails, emitter gomit.Emitter
 				}
 
 				//Add standard tags
-				nmt = p.AddStandardAndWorkflowTags(nmt, nil)
-
-				if err := p.metricCatalog.AddLoadedMetricType(lPlugin, nmt); err != nil {
-					pmLogger.WithFields(log.Fields{
-						"_block":           "load-plugin",
-						"plugin-name":      resp.Meta.Name,
-						"plugin-version":   resp.Meta.Version,
-						"plugin-type":      resp.Meta.Type.String(),
-						"plugin-path":      filepath.Base(lPlugin.Details.ExecPath),
-						"metric-namespace": nmt.Namespace(),
-						"metric-version":   nmt.Version(),
-						"error":            err.Error(),
-					}).Error("error adding loaded metric type")
-					resultChan <- result{nil, serror.New(err)}
-					return
+				nmts = append(nmts, p.AddStandardAndWorkflowTags(nmt, nil))
+			}
+			if err := p.metricCatalog.AddLoadedMetricTypes(lPlugin, nmts); err != nil {
+				pmLogger.WithFields(log.Fields{
+					"_block":         "load-plugin",
+					"plugin-name":    resp.Meta.Name,
+					"plugin-version": resp.Meta.Version,
+					"plugin-type":    resp.Meta.Type.String(),
+					"plugin-path":    filepath.Base(lPlugin.Details.ExecPath),
+					"error":          err.Error(),
+				}).Error("error adding loaded metric types")
+				if err == ErrNilConfigPolicy {
+					err = fmt.Errorf("unable to load plugin %s (version %d): it did not provide a config policy, "+
+						"enable permissive_config_policy to load it with an empty one", resp.Meta.Name, resp.Meta.Version)
 				}
+				resultChan <- result{nil, serror.New(err)}
+				return
 			}
 		}
 
-		if lPlugin.Details.Uri == nil {
+		if lPlugin.Details.Uri == nil && lPlugin.Details.InProcess == nil {
 			// Added so clients can adequately clean up connections
 			ap.client.Kill("Retrieved necessary plugin info")
 			err = ePlugin.Kill()
@@ -655,6 +701,37 @@ func (p *pluginManager) LoadPlugin(details *pluginDetails, emitter gomit.Emitter
 	}
 }
 
+// resourceLimits returns the resource limits of the plugin, the resource limit
+// items of the plugin config override the defaults of its config policy
+func (p *pluginManager) resourceLimits(lp *loadedPlugin) plugin.ResourceLimits {
+	table := map[string]ctypes.ConfigValue{}
+	if lp.ConfigPolicy != nil {
+		for _, node := range lp.ConfigPolicy.GetAll() {
+			for k, v := range node.Defaults() {
+				table[k] = v
+			}
+		}
+	}
+	if p.pluginConfig != nil {
+		if cdn := p.pluginConfig.getPluginConfigDataNode(core.PluginType(lp.Type), lp.Meta.Name, lp.Meta.Version); cdn != nil {
+			for k, v := range cdn.Table() {
+				table[k] = v
+			}
+		}
+	}
+	limits, err := plugin.NewResourceLimits(table)
+	if err != nil {
+		pmLogger.WithFields(log.Fields{
+			"_block":         "resource-limits",
+			"plugin-name":    lp.Meta.Name,
+			"plugin-version": lp.Meta.Version,
+			"plugin-type":    lp.Type.String(),
+			"error":          err,
+		}).Warning("invalid plugin resource limits")
+	}
+	return limits
+}
+
 // UnloadPlugin unloads a plugin from the LoadedPlugins table
 func (p *pluginManager) UnloadPlugin(pl core.Plugin) (*loadedPlugin, serror.SnapError) {
 	plugin, err := p.loadedPlugins.get(fmt.Sprintf("%s"+core.Separator+"%s"+core.Separator+"%d", pl.TypeName(), pl.Name(), pl.Version()))
@@ -725,7 +802,10 @@ func (p *pluginManager) UnloadPlugin(pl core.Plugin) (*loadedPlugin, serror.Snap
 
 // GenerateArgs generates the cli args to send when stating a plugin
 func (p *pluginManager) GenerateArgs(logLevel int) plugin.Arg {
-	return plugin.NewArg(logLevel, p.pprof)
+	args := plugin.NewArg(logLevel, p.pprof)
+	// the plugins are asked to listen on a named pipe of their own on Windows
+	args.PipeName = rpcutil.NewPipePath("snap-plugin")
+	return args
 }
 
 func (p *pluginManager) teardown() {
@@ -776,9 +856,11 @@ func split(ns string) []string {
 func (p *pluginManager) AddStandardAndWorkflowTags(m core.Metric, allTags map[string]map[string]string) core.Metric {
 	hostname := hostnameReader.Hostname()
 
-	tags := m.Tags()
-	if tags == nil {
-		tags = map[string]string{}
+	// the tags of the metric are copied, the metric may be shared with other
+	// tasks by the cache of the collector
+	tags := make(map[string]string, len(m.Tags())+1)
+	for k, v := range m.Tags() {
+		tags[k] = v
 	}
 	// apply standard tag
 	tags[core.STD_TAG_PLUGIN_RUNNING_ON] = hostname
@@ -809,6 +891,7 @@ func (p *pluginManager) AddStandardAndWorkflowTags(m core.Metric, allTags map[st
 		Tags_:               tags,
 		Description_:        m.Description(),
 		Unit_:               m.Unit(),
+		DataType_:           m.DataType(),
 		Timestamp_:          m.Timestamp(),
 	}
 	return metric
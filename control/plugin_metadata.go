@@ -0,0 +1,108 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"encoding/hex"
+	"sort"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/serror"
+)
+
+// PluginMetadata returns the details of a loaded plugin: its binary, the
+// metrics it exposes, its config policy, its running instances and the calls
+// made to it.  The latest version is taken when the version is lower than 1.
+func (p *pluginControl) PluginMetadata(pl core.Plugin) (*core.PluginMetadata, serror.SnapError) {
+	lp, err := p.pluginManager.get(key(pl))
+	if err != nil {
+		return nil, serror.New(ErrPluginNotFound, map[string]interface{}{
+			"plugin-name":    pl.Name(),
+			"plugin-version": pl.Version(),
+			"plugin-type":    pl.TypeName(),
+		})
+	}
+	md := &core.PluginMetadata{
+		Name:           lp.Name(),
+		Version:        lp.Version(),
+		Type:           core.PluginType(lp.Type),
+		LoadedTime:     lp.LoadedTime,
+		RPCType:        rpcTypeName(lp.Meta.RPCType),
+		RequiredConfig: []string{},
+		PIDs:           []int{},
+	}
+	if lp.Details != nil {
+		md.Path = lp.Details.Path
+		if lp.Details.Uri == nil {
+			md.CheckSum = hex.EncodeToString(lp.Details.CheckSum[:])
+		}
+	}
+	if mts, err := p.metricCatalog.Fetch(core.Namespace{}); err == nil {
+		for _, mt := range mts {
+			if mt.Plugin != nil && key(mt.Plugin) == lp.Key() {
+				md.MetricCount++
+			}
+		}
+	}
+	md.ConfigPolicyRules, md.RequiredConfig = summarizeConfigPolicy(lp.ConfigPolicy)
+	if p.pluginRunner != nil {
+		md.PIDs = p.pluginRunner.AvailablePlugins().pids(lp.Key())
+		md.Calls, md.Errors = p.pluginRunner.AvailablePlugins().callCount(lp.Key())
+	}
+	return md, nil
+}
+
+// summarizeConfigPolicy returns the number of rules of the config policy and
+// the sorted names of the required items without a default
+func summarizeConfigPolicy(policy *cpolicy.ConfigPolicy) (int, []string) {
+	required := []string{}
+	if policy == nil {
+		return 0, required
+	}
+	rules := 0
+	seen := map[string]bool{}
+	for _, node := range policy.GetAll() {
+		for _, rule := range node.RulesAsTable() {
+			rules++
+			if rule.Required && rule.Default == nil && !seen[rule.Name] {
+				seen[rule.Name] = true
+				required = append(required, rule.Name)
+			}
+		}
+	}
+	sort.Strings(required)
+	return rules, required
+}
+
+func rpcTypeName(t plugin.RPCType) string {
+	switch t {
+	case plugin.NativeRPC:
+		return "native"
+	case plugin.GRPC:
+		return "grpc"
+	case plugin.STREAMGRPC:
+		return "stream-grpc"
+	case plugin.InProcessRPC:
+		return "in-process"
+	}
+	return "unknown"
+}
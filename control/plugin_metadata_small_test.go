@@ -0,0 +1,102 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPluginMetadata(t *testing.T) {
+	Convey("pluginControl.PluginMetadata()", t, func() {
+		policy := cpolicy.New()
+		node := cpolicy.NewPolicyNode()
+		user, _ := cpolicy.NewStringRule("user", true)
+		host, _ := cpolicy.NewStringRule("host", true, "localhost")
+		port, _ := cpolicy.NewIntegerRule("port", false, 8080)
+		node.Add(user, host, port)
+		policy.Add([]string{"intel", "mock"}, node)
+
+		loadedTime := time.Now()
+		lp := &loadedPlugin{
+			Meta:         plugin.PluginMeta{Name: "mock", Version: 2, Type: plugin.CollectorPluginType, RPCType: plugin.GRPC},
+			Type:         plugin.CollectorPluginType,
+			LoadedTime:   loadedTime,
+			ConfigPolicy: policy,
+			Details: &pluginDetails{
+				Path:     "/opt/snap/plugins/snap-plugin-collector-mock",
+				CheckSum: sha256.Sum256([]byte("mock")),
+			},
+		}
+		pm := newPluginManager()
+		So(pm.loadedPlugins.add(lp), ShouldBeNil)
+		mc := newMetricCatalog()
+		for _, n := range []string{"foo", "bar"} {
+			mc.Add(newMetricType(core.NewNamespace("intel", "mock", n), time.Now(), lp))
+		}
+		r := newRunner()
+		c := &pluginControl{pluginManager: pm, metricCatalog: mc, pluginRunner: r}
+
+		Convey("returns the details of a loaded plugin", func() {
+			r.AvailablePlugins().countCall(lp.Key(), false, time.Millisecond)
+			r.AvailablePlugins().countCall(lp.Key(), true, time.Millisecond)
+			md, se := c.PluginMetadata(lp)
+			So(se, ShouldBeNil)
+			So(md.Name, ShouldEqual, "mock")
+			So(md.Version, ShouldEqual, 2)
+			So(md.Type, ShouldEqual, core.CollectorPluginType)
+			So(md.Path, ShouldEqual, lp.Details.Path)
+			So(md.CheckSum, ShouldEqual, hex.EncodeToString(lp.Details.CheckSum[:]))
+			So(md.LoadedTime, ShouldResemble, loadedTime)
+			So(md.RPCType, ShouldEqual, "grpc")
+			So(md.MetricCount, ShouldEqual, 2)
+			So(md.ConfigPolicyRules, ShouldEqual, 3)
+			So(md.RequiredConfig, ShouldResemble, []string{"user"})
+			So(md.PIDs, ShouldBeEmpty)
+			So(md.Calls, ShouldEqual, 2)
+			So(md.Errors, ShouldEqual, 1)
+		})
+
+		Convey("resets the call counters when the pool is removed", func() {
+			r.AvailablePlugins().countCall(lp.Key(), false, time.Millisecond)
+			r.AvailablePlugins().removePool(lp.Key())
+			md, se := c.PluginMetadata(lp)
+			So(se, ShouldBeNil)
+			So(md.Calls, ShouldEqual, 0)
+		})
+
+		Convey("returns an error for a plugin which is not loaded", func() {
+			md, se := c.PluginMetadata(&loadedPlugin{Meta: plugin.PluginMeta{Name: "other", Version: 1}, Type: plugin.CollectorPluginType})
+			So(se, ShouldNotBeNil)
+			So(se.Error(), ShouldEqual, ErrPluginNotFound.Error())
+			So(md, ShouldBeNil)
+		})
+	})
+}
@@ -0,0 +1,203 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin/client"
+	"github.com/intelsdi-x/snap/core/serror"
+)
+
+const (
+	// pluginTLSKeyBits is the size of the RSA keys generated for the session,
+	// the plugins only accept the RSA cipher suites
+	pluginTLSKeyBits = 2048
+	// pluginTLSValidity is how long the certificates of the session are valid
+	pluginTLSValidity = 10 * 365 * 24 * time.Hour
+)
+
+// pluginCertAuthority issues the certificates securing the communication
+// with the plugins when they aren't given any.  The authority only lives as
+// long as the snapteld session: its key is kept in memory and the
+// certificates it issues are written to a directory only the user running
+// snapteld can read, removed when control stops.
+type pluginCertAuthority struct {
+	dir  string
+	cert *x509.Certificate
+	key  *rsa.PrivateKey
+
+	// CACertPath is the certificate of the authority the plugins and
+	// snapteld verify each other with
+	CACertPath string
+	// ClientCertPath and ClientKeyPath are the certificate and key snapteld
+	// authenticates with to the plugins
+	ClientCertPath string
+	ClientKeyPath  string
+
+	mutex  *sync.Mutex
+	serial int64
+}
+
+// newPluginCertAuthority generates the authority of a session and the client
+// certificate of snapteld in a new directory of tempDir
+func newPluginCertAuthority(tempDir string) (*pluginCertAuthority, error) {
+	dir, err := ioutil.TempDir(tempDir, "snap-plugin-tls-")
+	if err != nil {
+		return nil, err
+	}
+	ca := &pluginCertAuthority{
+		dir:   dir,
+		mutex: &sync.Mutex{},
+	}
+	if err := ca.init(); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	return ca, nil
+}
+
+func (ca *pluginCertAuthority) init() error {
+	key, err := rsa.GenerateKey(rand.Reader, pluginTLSKeyBits)
+	if err != nil {
+		return err
+	}
+	tmpl := ca.template("snapteld session CA")
+	tmpl.IsCA = true
+	tmpl.BasicConstraintsValid = true
+	tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return err
+	}
+	if ca.cert, err = x509.ParseCertificate(der); err != nil {
+		return err
+	}
+	ca.key = key
+	ca.CACertPath = filepath.Join(ca.dir, "ca.crt")
+	if err := writePEM(ca.CACertPath, "CERTIFICATE", der); err != nil {
+		return err
+	}
+	ca.ClientCertPath, ca.ClientKeyPath, err = ca.issue("snapteld", x509.ExtKeyUsageClientAuth)
+	return err
+}
+
+// IssuePluginCert issues the server certificate of a plugin, returning the
+// paths of the certificate and of its key
+func (ca *pluginCertAuthority) IssuePluginCert(name string) (string, string, error) {
+	return ca.issue(name, x509.ExtKeyUsageServerAuth)
+}
+
+// Close removes the certificates issued in the session
+func (ca *pluginCertAuthority) Close() error {
+	return os.RemoveAll(ca.dir)
+}
+
+func (ca *pluginCertAuthority) template(commonName string) *x509.Certificate {
+	ca.mutex.Lock()
+	ca.serial++
+	serial := ca.serial
+	ca.mutex.Unlock()
+	now := time.Now()
+	return &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject: pkix.Name{
+			Organization: []string{"snap"},
+			CommonName:   commonName,
+		},
+		NotBefore: now.Add(-time.Minute),
+		NotAfter:  now.Add(pluginTLSValidity),
+	}
+}
+
+func (ca *pluginCertAuthority) issue(name string, usage x509.ExtKeyUsage) (string, string, error) {
+	key, err := rsa.GenerateKey(rand.Reader, pluginTLSKeyBits)
+	if err != nil {
+		return "", "", err
+	}
+	tmpl := ca.template(name)
+	tmpl.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+	tmpl.ExtKeyUsage = []x509.ExtKeyUsage{usage}
+	// the plugins listen on the loopback interface
+	tmpl.DNSNames = []string{"localhost"}
+	tmpl.IPAddresses = []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		return "", "", err
+	}
+	base := filepath.Join(ca.dir, fmt.Sprintf("%s-%d", name, tmpl.SerialNumber.Int64()))
+	certPath, keyPath := base+".crt", base+".key"
+	if err := writePEM(certPath, "CERTIFICATE", der); err != nil {
+		return "", "", err
+	}
+	if err := writePEM(keyPath, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key)); err != nil {
+		return "", "", err
+	}
+	return certPath, keyPath, nil
+}
+
+// pluginAutoTLSSecurity returns the security of the communication with the
+// plugins secured by the authority of the session.  Snapteld also trusts the
+// CA certificates configured for the plugins loaded with their own
+// certificates.
+func pluginAutoTLSSecurity(ca *pluginCertAuthority, cfg *Config) client.GRPCSecurity {
+	caCertPaths := []string{ca.CACertPath}
+	if cfg.CACertPaths != "" {
+		caCertPaths = append(caCertPaths, filepath.SplitList(cfg.CACertPaths)...)
+	}
+	return client.SecurityTLSExtended(ca.ClientCertPath, ca.ClientKeyPath, client.SecureClient, caCertPaths)
+}
+
+// securePlugin issues a certificate to a plugin loaded without one.  A plugin
+// loaded with its own certificate is given the authority of the session to
+// verify the client certificate of snapteld.
+func (p *pluginControl) securePlugin(details *pluginDetails) serror.SnapError {
+	if details.TLSEnabled {
+		caCertPaths := append(filepath.SplitList(details.CACertPaths), p.pluginCA.CACertPath)
+		details.CACertPaths = strings.Join(caCertPaths, string(filepath.ListSeparator))
+		return nil
+	}
+	certPath, keyPath, err := p.pluginCA.IssuePluginCert(filepath.Base(details.Path))
+	if err != nil {
+		return serror.New(fmt.Errorf("unable to issue the TLS certificate of the plugin: %v", err))
+	}
+	details.CertPath = certPath
+	details.KeyPath = keyPath
+	details.CACertPaths = p.pluginCA.CACertPath
+	details.TLSEnabled = true
+	return nil
+}
+
+func writePEM(path, typ string, der []byte) error {
+	return ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0600)
+}
@@ -0,0 +1,115 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPluginCertAuthority(t *testing.T) {
+	Convey("pluginCertAuthority", t, func() {
+		tempDir, err := ioutil.TempDir("", "snap-plugin-tls-test")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(tempDir)
+		ca, err := newPluginCertAuthority(tempDir)
+		So(err, ShouldBeNil)
+
+		Convey("issues the certificates in a private directory", func() {
+			fi, err := os.Stat(ca.dir)
+			So(err, ShouldBeNil)
+			So(fi.Mode().Perm(), ShouldEqual, os.FileMode(0700))
+			fi, err = os.Stat(ca.ClientKeyPath)
+			So(err, ShouldBeNil)
+			So(fi.Mode().Perm(), ShouldEqual, os.FileMode(0600))
+		})
+
+		Convey("secures the connections between snapteld and the plugins", func() {
+			certPath, keyPath, err := ca.IssuePluginCert("snap-plugin-collector-mock")
+			So(err, ShouldBeNil)
+			roots, err := loadCertPool(ca.CACertPath)
+			So(err, ShouldBeNil)
+
+			serverCert, err := tls.LoadX509KeyPair(certPath, keyPath)
+			So(err, ShouldBeNil)
+			lis, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+				Certificates: []tls.Certificate{serverCert},
+				CipherSuites: []uint16{tls.TLS_RSA_WITH_AES_128_GCM_SHA256},
+				ClientAuth:   tls.RequireAndVerifyClientCert,
+				ClientCAs:    roots,
+			})
+			So(err, ShouldBeNil)
+			defer lis.Close()
+			go func() {
+				for {
+					conn, err := lis.Accept()
+					if err != nil {
+						return
+					}
+					conn.(*tls.Conn).Handshake()
+					conn.Close()
+				}
+			}()
+
+			clientCert, err := tls.LoadX509KeyPair(ca.ClientCertPath, ca.ClientKeyPath)
+			So(err, ShouldBeNil)
+			conn, err := tls.Dial("tcp", lis.Addr().String(), &tls.Config{
+				Certificates: []tls.Certificate{clientCert},
+				RootCAs:      roots,
+			})
+			So(err, ShouldBeNil)
+			conn.Close()
+
+			Convey("and rejects the clients without a certificate of the session", func() {
+				conn, err := tls.Dial("tcp", lis.Addr().String(), &tls.Config{RootCAs: roots})
+				if err == nil {
+					// the server reports the missing certificate after the
+					// handshake of the client
+					_, err = conn.Read(make([]byte, 1))
+					conn.Close()
+				}
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("removes the certificates when closed", func() {
+			So(ca.Close(), ShouldBeNil)
+			_, err := os.Stat(ca.dir)
+			So(os.IsNotExist(err), ShouldBeTrue)
+		})
+	})
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(b)
+	return pool, nil
+}
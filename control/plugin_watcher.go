@@ -0,0 +1,288 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/control_event"
+	"github.com/intelsdi-x/snap/pkg/psigning"
+)
+
+// pluginWatchDelay is the time a plugin file has to stay unchanged before it
+// is loaded, so a plugin being copied into the watched directory isn't loaded
+// until the copy is complete
+var pluginWatchDelay = time.Second
+
+var watcherLogger = log.WithField("_module", "control-plugin-watcher")
+
+// watchedPlugin is a plugin loaded from a file of the watched directory
+type watchedPlugin struct {
+	plugin  core.CatalogedPlugin
+	modTime time.Time
+	size    int64
+}
+
+// pluginWatcher watches a directory and loads new plugin files, unloads the
+// plugins whose files are removed and reloads the plugins whose files change
+type pluginWatcher struct {
+	path    string
+	control *pluginControl
+	watcher *fsnotify.Watcher
+
+	mutex   *sync.Mutex
+	plugins map[string]*watchedPlugin
+	pending map[string]*time.Timer
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+func newPluginWatcher(c *pluginControl, path string) *pluginWatcher {
+	return &pluginWatcher{
+		path:    path,
+		control: c,
+		mutex:   &sync.Mutex{},
+		plugins: map[string]*watchedPlugin{},
+		pending: map[string]*time.Timer{},
+		done:    make(chan struct{}),
+	}
+}
+
+// start loads the plugins found in the watched directory and starts watching it
+func (w *pluginWatcher) start() error {
+	fullPath, err := filepath.Abs(w.path)
+	if err != nil {
+		return err
+	}
+	w.path = fullPath
+	w.watcher, err = fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	if err := w.watcher.Add(w.path); err != nil {
+		w.watcher.Close()
+		return err
+	}
+	watcherLogger.WithFields(log.Fields{
+		"_block": "start",
+		"path":   w.path,
+	}).Info("watching plugin directory")
+
+	files, err := ioutil.ReadDir(w.path)
+	if err != nil {
+		w.watcher.Close()
+		return err
+	}
+	for _, file := range files {
+		w.sync(filepath.Join(w.path, file.Name()))
+	}
+
+	w.wg.Add(1)
+	go w.watch()
+	return nil
+}
+
+// stop stops watching the directory, the loaded plugins are left loaded
+func (w *pluginWatcher) stop() {
+	close(w.done)
+	w.watcher.Close()
+	w.wg.Wait()
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	for path, t := range w.pending {
+		t.Stop()
+		delete(w.pending, path)
+	}
+}
+
+func (w *pluginWatcher) watch() {
+	defer w.wg.Done()
+	for {
+		select {
+		case <-w.done:
+			return
+		case e, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			w.schedule(e.Name)
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			watcherLogger.WithFields(log.Fields{
+				"_block": "watch",
+				"path":   w.path,
+				"error":  err,
+			}).Error("error watching plugin directory")
+		}
+	}
+}
+
+// schedule syncs the plugin file once it hasn't changed for pluginWatchDelay
+func (w *pluginWatcher) schedule(path string) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if t, ok := w.pending[path]; ok {
+		t.Reset(pluginWatchDelay)
+		return
+	}
+	w.pending[path] = time.AfterFunc(pluginWatchDelay, func() {
+		w.mutex.Lock()
+		delete(w.pending, path)
+		w.mutex.Unlock()
+		select {
+		case <-w.done:
+		default:
+			w.sync(path)
+		}
+	})
+}
+
+// sync loads, unloads or reloads the plugin of the file depending on
+// whether the file was added, removed or changed
+func (w *pluginWatcher) sync(path string) {
+	f := log.Fields{
+		"_block": "sync",
+		"path":   path,
+	}
+	fi, ok := pluginFileInfo(path)
+
+	w.mutex.Lock()
+	wp, loaded := w.plugins[path]
+	w.mutex.Unlock()
+
+	switch {
+	case !ok && loaded:
+		if _, err := w.control.Unload(wp.plugin); err != nil {
+			watcherLogger.WithFields(f).Error(err)
+			return
+		}
+		w.forget(path)
+		w.control.eventManager.Emit(&control_event.WatchedPluginUnloadEvent{
+			Path:    path,
+			Name:    wp.plugin.Name(),
+			Version: wp.plugin.Version(),
+			Type:    pluginTypeOf(wp.plugin),
+		})
+		watcherLogger.WithFields(f).Info("watched plugin unloaded")
+	case ok && !loaded:
+		pl, err := w.load(path)
+		if err != nil {
+			watcherLogger.WithFields(f).Error(err)
+			return
+		}
+		w.remember(path, pl, fi)
+		w.control.eventManager.Emit(&control_event.WatchedPluginLoadEvent{
+			Path:    path,
+			Name:    pl.Name(),
+			Version: pl.Version(),
+			Type:    pluginTypeOf(pl),
+		})
+		watcherLogger.WithFields(f).Info("watched plugin loaded")
+	case ok && loaded:
+		if fi.ModTime().Equal(wp.modTime) && fi.Size() == wp.size {
+			return
+		}
+		if _, err := w.control.Unload(wp.plugin); err != nil {
+			watcherLogger.WithFields(f).Error(err)
+			return
+		}
+		w.forget(path)
+		pl, err := w.load(path)
+		if err != nil {
+			watcherLogger.WithFields(f).Error(err)
+			return
+		}
+		w.remember(path, pl, fi)
+		w.control.eventManager.Emit(&control_event.WatchedPluginReloadEvent{
+			Path:            path,
+			Name:            pl.Name(),
+			PreviousVersion: wp.plugin.Version(),
+			Version:         pl.Version(),
+			Type:            pluginTypeOf(pl),
+		})
+		watcherLogger.WithFields(f).Info("watched plugin reloaded")
+	}
+}
+
+func (w *pluginWatcher) load(path string) (core.CatalogedPlugin, error) {
+	rp, err := core.NewRequestedPlugin(path, w.control.GetTempDir(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if signatureFile := psigning.SignatureFile(path); signatureFile != "" {
+		if err := rp.ReadSignatureFile(signatureFile); err != nil {
+			return nil, err
+		}
+	}
+	pl, serr := w.control.Load(rp)
+	if serr != nil {
+		return nil, serr
+	}
+	return pl, nil
+}
+
+func (w *pluginWatcher) remember(path string, pl core.CatalogedPlugin, fi os.FileInfo) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.plugins[path] = &watchedPlugin{plugin: pl, modTime: fi.ModTime(), size: fi.Size()}
+}
+
+func (w *pluginWatcher) forget(path string) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	delete(w.plugins, path)
+}
+
+// pluginFileInfo returns the file info of path (following symlinks) if the
+// file is a plugin, skipping the same files as the auto discovery does
+func pluginFileInfo(path string) (os.FileInfo, bool) {
+	fi, err := os.Stat(path)
+	if err != nil || fi.IsDir() {
+		return nil, false
+	}
+	fname := strings.ToLower(filepath.Base(path))
+	if strings.HasSuffix(fname, ".json") || strings.HasSuffix(fname, ".yaml") || strings.HasSuffix(fname, ".yml") || psigning.IsSignatureFile(fname) {
+		return nil, false
+	}
+	if !isExecutable(fi) {
+		return nil, false
+	}
+	return fi, true
+}
+
+func pluginTypeOf(pl core.Plugin) int {
+	typ, err := core.ToPluginType(pl.TypeName())
+	if err != nil {
+		return -1
+	}
+	return int(typ)
+}
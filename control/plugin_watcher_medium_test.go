@@ -0,0 +1,106 @@
+// +build medium
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/gomit"
+	"github.com/intelsdi-x/snap/core/control_event"
+	"github.com/intelsdi-x/snap/plugin/helper"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type lstnToWatchedPluginEvents struct {
+	load   chan *control_event.WatchedPluginLoadEvent
+	unload chan *control_event.WatchedPluginUnloadEvent
+}
+
+func (l *lstnToWatchedPluginEvents) HandleGomitEvent(e gomit.Event) {
+	switch v := e.Body.(type) {
+	case *control_event.WatchedPluginLoadEvent:
+		l.load <- v
+	case *control_event.WatchedPluginUnloadEvent:
+		l.unload <- v
+	}
+}
+
+func copyPluginFile(src, dst string) error {
+	b, err := ioutil.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(dst, b, 0755)
+}
+
+func TestPluginWatcher(t *testing.T) {
+	pluginWatchDelay = 100 * time.Millisecond
+
+	Convey("Given a watched plugin directory", t, func() {
+		dir, err := ioutil.TempDir("", "snap-plugin-watch")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+
+		config := getTestSGConfig()
+		config.PluginWatchPath = dir
+		c := New(config)
+		lpe := &lstnToWatchedPluginEvents{
+			load:   make(chan *control_event.WatchedPluginLoadEvent, 1),
+			unload: make(chan *control_event.WatchedPluginUnloadEvent, 1),
+		}
+		c.eventManager.RegisterHandler("TestPluginWatcher", lpe)
+		So(c.Start(), ShouldBeNil)
+		defer c.Stop()
+
+		Convey("A plugin copied into the directory is loaded and unloaded when removed", func() {
+			path := filepath.Join(dir, "snap-plugin-collector-mock1")
+			So(copyPluginFile(helper.PluginFilePath("snap-plugin-collector-mock1"), path), ShouldBeNil)
+
+			var le *control_event.WatchedPluginLoadEvent
+			select {
+			case le = <-lpe.load:
+			case <-time.After(30 * time.Second):
+			}
+			So(le, ShouldNotBeNil)
+			So(le.Path, ShouldEqual, path)
+			So(le.Name, ShouldEqual, "mock")
+			So(le.Version, ShouldEqual, 1)
+			So(len(c.PluginCatalog()), ShouldEqual, 1)
+
+			So(os.Remove(path), ShouldBeNil)
+			var ue *control_event.WatchedPluginUnloadEvent
+			select {
+			case ue = <-lpe.unload:
+			case <-time.After(30 * time.Second):
+			}
+			So(ue, ShouldNotBeNil)
+			So(ue.Path, ShouldEqual, path)
+			So(ue.Name, ShouldEqual, "mock")
+			So(len(c.PluginCatalog()), ShouldEqual, 0)
+		})
+	})
+}
@@ -0,0 +1,182 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"errors"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/ctypes"
+)
+
+const (
+	// RelabelProcessorName is the name of the built-in processor which drops
+	// metrics, renames namespace elements and adds or removes tags, it doesn't
+	// have to be loaded and accepts any version
+	RelabelProcessorName = "relabel"
+
+	// RelabelDropKey is the config item of the comma separated namespace
+	// patterns of the metrics dropped (e.g. "/intel/psutil/cpu/*")
+	RelabelDropKey = "drop"
+	// RelabelRenameKey is the config item of the comma separated renames of
+	// namespace elements (e.g. "psutil=os")
+	RelabelRenameKey = "rename"
+	// RelabelAddTagsKey is the config item of the comma separated tags added
+	// to the metrics (e.g. "env=prod")
+	RelabelAddTagsKey = "add_tags"
+	// RelabelRemoveTagsKey is the config item of the comma separated keys of
+	// the tags removed from the metrics
+	RelabelRemoveTagsKey = "remove_tags"
+)
+
+var (
+	// ErrInvalidRelabelPattern - error message when a drop pattern of a relabel step isn't a valid pattern
+	ErrInvalidRelabelPattern = errors.New("relabel drop patterns must be valid namespace patterns")
+	// ErrInvalidRelabelPair - error message when a rename or a tag of a relabel step isn't in the form key=value
+	ErrInvalidRelabelPair = errors.New("relabel renames and tags must be comma separated key=value pairs")
+)
+
+// relabelRules holds the rules of a relabel step, applied in the order drop,
+// rename, add tags and remove tags
+type relabelRules struct {
+	drop       []string
+	rename     map[string]string
+	addTags    map[string]string
+	removeTags []string
+}
+
+func newRelabelRules(table map[string]ctypes.ConfigValue) (relabelRules, error) {
+	rules := relabelRules{}
+	for _, pattern := range configList(table, RelabelDropKey) {
+		// matching the pattern against itself reaches any malformed part of it
+		if _, err := path.Match(pattern, pattern); err != nil || !strings.HasPrefix(pattern, "/") {
+			return rules, ErrInvalidRelabelPattern
+		}
+		rules.drop = append(rules.drop, pattern)
+	}
+	var err error
+	if rules.rename, err = configPairs(table, RelabelRenameKey); err != nil {
+		return rules, err
+	}
+	if rules.addTags, err = configPairs(table, RelabelAddTagsKey); err != nil {
+		return rules, err
+	}
+	rules.removeTags = configList(table, RelabelRemoveTagsKey)
+	return rules, nil
+}
+
+// configList returns the comma separated values of a string config item
+func configList(table map[string]ctypes.ConfigValue, key string) []string {
+	s, _ := table[key].(ctypes.ConfigValueStr)
+	var values []string
+	for _, v := range strings.Split(s.Value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// configPairs returns the comma separated key=value pairs of a string config item
+func configPairs(table map[string]ctypes.ConfigValue, key string) (map[string]string, error) {
+	pairs := map[string]string{}
+	for _, pair := range configList(table, key) {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, ErrInvalidRelabelPair
+		}
+		pairs[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return pairs, nil
+}
+
+// dropped returns true when the namespace matches a drop pattern, a "*"
+// matches an element of the namespace
+func (r relabelRules) dropped(ns core.Namespace) bool {
+	s := "/" + strings.Join(ns.Strings(), "/")
+	for _, pattern := range r.drop {
+		if ok, _ := path.Match(pattern, s); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// apply returns a copy of the metric relabeled by the rules, the metric
+// passed to the step is left as it is
+func (r relabelRules) apply(m core.Metric) core.Metric {
+	ns := make(core.Namespace, len(m.Namespace()))
+	copy(ns, m.Namespace())
+	for i, e := range ns {
+		if v, ok := r.rename[e.Value]; ok {
+			ns[i].Value = v
+		}
+	}
+	tags := make(map[string]string, len(m.Tags())+len(r.addTags))
+	for k, v := range m.Tags() {
+		tags[k] = v
+	}
+	for k, v := range r.addTags {
+		tags[k] = v
+	}
+	for _, k := range r.removeTags {
+		delete(tags, k)
+	}
+	return plugin.MetricType{
+		Namespace_:          ns,
+		LastAdvertisedTime_: m.LastAdvertisedTime(),
+		Version_:            m.Version(),
+		Data_:               m.Data(),
+		Tags_:               tags,
+		Unit_:               m.Unit(),
+		Description_:        m.Description(),
+		DataType_:           m.DataType(),
+		Timestamp_:          m.Timestamp(),
+	}
+}
+
+// relabeler runs the relabel steps, which keep no state across runs
+type relabeler struct{}
+
+func (relabeler) validate(config map[string]ctypes.ConfigValue) error {
+	_, err := newRelabelRules(config)
+	return err
+}
+
+func (relabeler) process(metrics []core.Metric, config map[string]ctypes.ConfigValue, taskID string, now time.Time) ([]core.Metric, []error) {
+	rules, err := newRelabelRules(config)
+	if err != nil {
+		return nil, []error{err}
+	}
+	mts := make([]core.Metric, 0, len(metrics))
+	for _, m := range metrics {
+		if rules.dropped(m.Namespace()) {
+			continue
+		}
+		mts = append(mts, rules.apply(m))
+	}
+	return mts, nil
+}
+
+func (relabeler) remove(string) {}
@@ -0,0 +1,83 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/ctypes"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRelabeler(t *testing.T) {
+	Convey("newRelabelRules", t, func() {
+		Convey("returns an error for an invalid pattern or pair", func() {
+			_, err := newRelabelRules(map[string]ctypes.ConfigValue{RelabelDropKey: ctypes.ConfigValueStr{Value: "/intel/[cpu"}})
+			So(err, ShouldEqual, ErrInvalidRelabelPattern)
+			_, err = newRelabelRules(map[string]ctypes.ConfigValue{RelabelRenameKey: ctypes.ConfigValueStr{Value: "psutil"}})
+			So(err, ShouldEqual, ErrInvalidRelabelPair)
+			_, err = newRelabelRules(map[string]ctypes.ConfigValue{RelabelAddTagsKey: ctypes.ConfigValueStr{Value: "=prod"}})
+			So(err, ShouldEqual, ErrInvalidRelabelPair)
+		})
+	})
+	Convey("relabeler", t, func() {
+		config := map[string]ctypes.ConfigValue{
+			RelabelDropKey:       ctypes.ConfigValueStr{Value: "/intel/psutil/cpu/*, /intel/mock/*/bar"},
+			RelabelRenameKey:     ctypes.ConfigValueStr{Value: "psutil=os"},
+			RelabelAddTagsKey:    ctypes.ConfigValueStr{Value: "env=prod,team=storage"},
+			RelabelRemoveTagsKey: ctypes.ConfigValueStr{Value: "plugin_running_on"},
+		}
+		load := plugin.MetricType{
+			Namespace_: core.NewNamespace("intel", "psutil", "load", "load1"),
+			Data_:      1.5,
+			Tags_:      map[string]string{"plugin_running_on": "host", "env": "dev"},
+		}
+		metrics := []core.Metric{
+			load,
+			plugin.MetricType{Namespace_: core.NewNamespace("intel", "psutil", "cpu", "idle")},
+			plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "host0", "bar")},
+		}
+
+		Convey("drops, renames and retags the metrics", func() {
+			mts, errs := relabeler{}.process(metrics, config, "task", time.Now())
+			So(errs, ShouldBeEmpty)
+			So(mts, ShouldHaveLength, 1)
+			So(mts[0].Namespace().String(), ShouldEqual, "/intel/os/load/load1")
+			So(mts[0].Data(), ShouldEqual, 1.5)
+			So(mts[0].Tags(), ShouldResemble, map[string]string{"env": "prod", "team": "storage"})
+		})
+		Convey("leaves the metrics passed to it as they are", func() {
+			relabeler{}.process(metrics, config, "task", time.Now())
+			So(load.Namespace().String(), ShouldEqual, "/intel/psutil/load/load1")
+			So(load.Tags()["env"], ShouldEqual, "dev")
+		})
+		Convey("passes the metrics through without rules", func() {
+			mts, errs := relabeler{}.process(metrics, map[string]ctypes.ConfigValue{}, "task", time.Now())
+			So(errs, ShouldBeEmpty)
+			So(mts, ShouldHaveLength, 3)
+		})
+	})
+}
@@ -20,10 +20,12 @@ limitations under the License.
 package control
 
 import (
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"os"
-	"path"
+	"path/filepath"
+	"sync"
 	"time"
 
 	log "github.com/Sirupsen/logrus"
@@ -31,6 +33,7 @@ import (
 
 	"github.com/intelsdi-x/snap/control/plugin"
 	"github.com/intelsdi-x/snap/control/plugin/client"
+	"github.com/intelsdi-x/snap/control/strategy"
 	"github.com/intelsdi-x/snap/core"
 	"github.com/intelsdi-x/snap/core/control_event"
 	"github.com/intelsdi-x/snap/pkg/aci"
@@ -59,6 +62,14 @@ var (
 	// after the event of control_event.DeadAvailablePluginEvent
 	MaxPluginRestartCount = 3
 
+	// PluginRestartBackoff is the delay before the second restart of a dead
+	// plugin, the delay doubles with every following restart (the first restart
+	// happens immediately)
+	PluginRestartBackoff = time.Second
+
+	// maxPluginRestartBackoff caps the delay between restarts of a dead plugin
+	maxPluginRestartBackoff = 5 * time.Minute
+
 	defaultRunnerOpts = []pluginRunnerOpt{optDefaultRunnerSecurity()}
 )
 
@@ -77,6 +88,11 @@ type runner struct {
 	pluginManager     managesPlugins
 	grpcSecurity      client.GRPCSecurity
 	pluginLoadTimeout int
+	resourceLimits    bool
+	blacklist         *pluginBlacklist
+
+	restartMutex    *sync.Mutex
+	pendingRestarts map[uint32]*time.Timer
 }
 
 func newRunner(opts ...pluginRunnerOpt) *runner {
@@ -84,6 +100,8 @@ func newRunner(opts ...pluginRunnerOpt) *runner {
 		pluginLoadTimeout: defaultPluginLoadTimeout,
 		monitor:           newMonitor(),
 		availablePlugins:  newAvailablePlugins(),
+		restartMutex:      &sync.Mutex{},
+		pendingRestarts:   map[uint32]*time.Timer{},
 	}
 	mergedOpts := append([]pluginRunnerOpt{}, defaultRunnerOpts...)
 	mergedOpts = append(mergedOpts, opts...)
@@ -102,6 +120,30 @@ func OptEnableRunnerTLS(grpcSecurity client.GRPCSecurity) pluginRunnerOpt {
 	}
 }
 
+// OptSetHealthCheckInterval sets the interval the runner's monitor checks the
+// health of the running plugins at
+func OptSetHealthCheckInterval(d time.Duration) pluginRunnerOpt {
+	return func(r *runner) {
+		r.monitor.Option(MonitorDurationOption(d))
+	}
+}
+
+// OptEnableResourceLimits runs the plugins with the resource limits declared
+// in their config
+func OptEnableResourceLimits() pluginRunnerOpt {
+	return func(r *runner) {
+		r.resourceLimits = true
+	}
+}
+
+// OptSetPluginBlacklist sets the blacklist the crashes of the plugins are
+// recorded in, blacklisted plugins aren't restarted
+func OptSetPluginBlacklist(b *pluginBlacklist) pluginRunnerOpt {
+	return func(r *runner) {
+		r.blacklist = b
+	}
+}
+
 func optDefaultRunnerSecurity() pluginRunnerOpt {
 	return func(r *runner) {
 		r.grpcSecurity = client.SecurityTLSOff()
@@ -171,6 +213,14 @@ func (r *runner) Stop() []error {
 	// Stop the monitor
 	r.monitor.Stop()
 
+	// Cancel the restarts waiting for their backoff to elapse
+	r.restartMutex.Lock()
+	for id, t := range r.pendingRestarts {
+		t.Stop()
+		delete(r.pendingRestarts, id)
+	}
+	r.restartMutex.Unlock()
+
 	// TODO: Actually stop the plugins
 
 	// For each delegate unregister needed handlers
@@ -231,22 +281,9 @@ func (r *runner) startPlugin(p executablePlugin) (*availablePlugin, error) {
 			resultChan <- result{nil, err}
 			return
 		}
-		r.availablePlugins.insert(ap)
-
-		runnerLog.WithFields(log.Fields{
-			"_block":                "start-plugin",
-			"available-plugin":      ap.String(),
-			"available-plugin-type": ap.TypeName(),
-		}).Info("available plugin started")
+		r.insertStartedPlugin(ap)
 		resultChan <- result{ap, nil}
-
-		defer r.emitter.Emit(&control_event.StartPluginEvent{
-			Name:    ap.Name(),
-			Version: ap.Version(),
-			Type:    int(ap.Type()),
-			Key:     ap.key,
-			Id:      ap.ID(),
-		})
+		r.emitStartPluginEvent(ap)
 	}()
 
 	select {
@@ -293,31 +330,31 @@ func (r *runner) HandleGomitEvent(e gomit.Event) {
 			pool.Kill(v.Id, "plugin dead")
 		}
 
+		if r.blacklistCrashed(v) {
+			return
+		}
+
 		if pool.Eligible() {
 			if pool.RestartCount() < MaxPluginRestartCount || MaxPluginRestartCount == -1 {
-				e := r.restartPlugin(v.Key)
-				if e != nil {
-					runnerLog.WithFields(log.Fields{
-						"_block":  "handle-events",
-						"aplugin": v.String,
-					}).Error(e.Error())
+				backoff := restartBackoff(pool.RestartCount())
+				if backoff == 0 {
+					r.restartDeadPlugin(v, pool)
 					return
 				}
-				pool.IncRestartCount()
-
 				runnerLog.WithFields(log.Fields{
 					"_block":        "handle-events",
 					"aplugin":       v.String,
 					"restart-count": pool.RestartCount(),
-				}).Warning("plugin restarted")
-
-				r.emitter.Emit(&control_event.RestartedAvailablePluginEvent{
-					Id:      v.Id,
-					Name:    v.Name,
-					Version: v.Version,
-					Key:     v.Key,
-					Type:    v.Type,
+					"backoff":       backoff.String(),
+				}).Warning("scheduling plugin restart")
+				r.restartMutex.Lock()
+				r.pendingRestarts[v.Id] = time.AfterFunc(backoff, func() {
+					r.restartMutex.Lock()
+					delete(r.pendingRestarts, v.Id)
+					r.restartMutex.Unlock()
+					r.restartDeadPlugin(v, pool)
 				})
+				r.restartMutex.Unlock()
 			} else {
 				runnerLog.WithFields(log.Fields{
 					"_block":  "handle-events",
@@ -354,7 +391,111 @@ func (r *runner) HandleGomitEvent(e gomit.Event) {
 	}
 }
 
+// blacklistCrashed records the crash of the dead plugin and returns true when
+// its binary is blacklisted, the PluginBlacklistedEvent is emitted only when
+// this crash got it blacklisted
+func (r *runner) blacklistCrashed(v *control_event.DeadAvailablePluginEvent) bool {
+	if r.blacklist == nil || r.pluginManager == nil {
+		return false
+	}
+	lp, err := r.pluginManager.get(v.Key)
+	if err != nil {
+		return false
+	}
+	if !r.blacklist.crashed(lp) {
+		// the other instances of a blacklisted binary aren't restarted either
+		return lp.Details != nil && r.blacklist.blacklisted(lp.Details.CheckSum)
+	}
+	checkSum := hex.EncodeToString(lp.Details.CheckSum[:])
+	runnerLog.WithFields(log.Fields{
+		"_block":   "handle-events",
+		"aplugin":  v.String,
+		"checksum": checkSum,
+	}).Warning("plugin blacklisted after crashing repeatedly, not restarting it")
+	r.emitter.Emit(&control_event.PluginBlacklistedEvent{
+		Name:     v.Name,
+		Version:  v.Version,
+		Type:     v.Type,
+		Key:      v.Key,
+		CheckSum: checkSum,
+		Crashes:  r.blacklist.crashes,
+	})
+	return true
+}
+
+// configurePool applies the pool items of the plugin config to the pool the
+// available plugin is about to be added to
+func (r *runner) configurePool(ap *availablePlugin) {
+	if r.pluginManager == nil || r.pluginManager.GetPluginConfig() == nil {
+		return
+	}
+	cfg, err := r.pluginManager.GetPluginConfig().getPluginPoolConfig(core.PluginType(ap.pluginType), ap.name, ap.version)
+	if err != nil {
+		runnerLog.WithFields(log.Fields{
+			"_block":           "configure-pool",
+			"available-plugin": ap.String(),
+			"error":            err,
+		}).Warning("invalid plugin pool config")
+	}
+	pool, err := r.availablePlugins.getOrCreatePool(ap.key)
+	if err != nil {
+		return
+	}
+	if err := pool.Configure(cfg); err != nil {
+		runnerLog.WithFields(log.Fields{
+			"_block":           "configure-pool",
+			"available-plugin": ap.String(),
+			"error":            err,
+		}).Error("error configuring plugin pool")
+	}
+}
+
+// insertStartedPlugin adds the started plugin to its pool
+func (r *runner) insertStartedPlugin(ap *availablePlugin) {
+	r.configurePool(ap)
+	r.availablePlugins.insert(ap)
+
+	runnerLog.WithFields(log.Fields{
+		"_block":                "start-plugin",
+		"available-plugin":      ap.String(),
+		"available-plugin-type": ap.TypeName(),
+	}).Info("available plugin started")
+}
+
+func (r *runner) emitStartPluginEvent(ap *availablePlugin) {
+	r.emitter.Emit(&control_event.StartPluginEvent{
+		Name:    ap.Name(),
+		Version: ap.Version(),
+		Type:    int(ap.Type()),
+		Key:     ap.key,
+		Id:      ap.ID(),
+	})
+}
+
+// runInProcessPlugin starts a new instance of the in-process plugin in the
+// process of snapteld
+func (r *runner) runInProcessPlugin(ip *inProcessPlugin) error {
+	ap, err := newInProcessAvailablePlugin(ip, r.emitter)
+	if err != nil {
+		runnerLog.WithFields(log.Fields{
+			"_block":      "run-plugin",
+			"plugin-name": ip.meta.Name,
+			"error":       err,
+		}).Error("error starting new in-process plugin")
+		return err
+	}
+	r.insertStartedPlugin(ap)
+	r.emitStartPluginEvent(ap)
+	return nil
+}
+
 func (r *runner) runPlugin(name string, details *pluginDetails) error {
+	if details.InProcess != nil {
+		return r.runInProcessPlugin(details.InProcess)
+	}
+	if r.blacklist != nil && r.blacklist.blacklisted(details.CheckSum) {
+		return ErrPluginBlacklisted
+	}
 	if details.IsPackage {
 		f, err := os.Open(details.Path)
 		if err != nil {
@@ -365,17 +506,17 @@ func (r *runner) runPlugin(name string, details *pluginDetails) error {
 		if err != nil {
 			return err
 		}
-		details.ExecPath = path.Join(tempPath, "rootfs")
+		details.ExecPath = filepath.Join(tempPath, "rootfs")
 	}
 	commands := make([]string, len(details.Exec))
 	for i, e := range details.Exec {
-		commands[i] = path.Join(details.ExecPath, e)
+		commands[i] = filepath.Join(details.ExecPath, e)
 	}
-	ePlugin, err := plugin.NewExecutablePlugin(r.pluginManager.GenerateArgs(int(log.GetLevel())).
+	ePlugin, err := newExecutablePlugin(r.pluginManager.GenerateArgs(int(log.GetLevel())).
 		SetCertPath(details.CertPath).
 		SetKeyPath(details.KeyPath).
 		SetCACertPaths(details.CACertPaths).
-		SetTLSEnabled(details.TLSEnabled), commands...)
+		SetTLSEnabled(details.TLSEnabled), details, commands)
 	if err != nil {
 		runnerLog.WithFields(log.Fields{
 			"_block": "run-plugin",
@@ -385,6 +526,16 @@ func (r *runner) runPlugin(name string, details *pluginDetails) error {
 		return err
 	}
 	ePlugin.SetName(name)
+	if r.resourceLimits && !details.ResourceLimits.IsEmpty() {
+		if err := ePlugin.SetResourceLimits(details.ResourceLimits); err != nil {
+			runnerLog.WithFields(log.Fields{
+				"_block": "run-plugin",
+				"path":   commands,
+				"error":  err,
+			}).Error("error setting plugin resource limits")
+			return err
+		}
+	}
 	ap, err := r.startPlugin(ePlugin)
 	if err != nil {
 		runnerLog.WithFields(log.Fields{
@@ -439,6 +590,51 @@ func (r *runner) handleUnsubscription(pType, pName string, pVersion int, taskID
 	return nil
 }
 
+// restartDeadPlugin restarts the dead plugin of the event in its pool and
+// emits a RestartedAvailablePluginEvent
+func (r *runner) restartDeadPlugin(v *control_event.DeadAvailablePluginEvent, pool strategy.Pool) {
+	e := r.restartPlugin(v.Key)
+	if e != nil {
+		runnerLog.WithFields(log.Fields{
+			"_block":  "handle-events",
+			"aplugin": v.String,
+		}).Error(e.Error())
+		return
+	}
+	pool.IncRestartCount()
+
+	runnerLog.WithFields(log.Fields{
+		"_block":        "handle-events",
+		"aplugin":       v.String,
+		"restart-count": pool.RestartCount(),
+	}).Warning("plugin restarted")
+
+	r.emitter.Emit(&control_event.RestartedAvailablePluginEvent{
+		Id:      v.Id,
+		Name:    v.Name,
+		Version: v.Version,
+		Key:     v.Key,
+		Type:    v.Type,
+	})
+}
+
+// restartBackoff returns the delay before restarting a plugin which has been
+// restarted restartCount times already: no delay for the first restart, then
+// PluginRestartBackoff doubled with every restart up to maxPluginRestartBackoff
+func restartBackoff(restartCount int) time.Duration {
+	if restartCount <= 0 || PluginRestartBackoff <= 0 {
+		return 0
+	}
+	backoff := PluginRestartBackoff
+	for i := 1; i < restartCount; i++ {
+		backoff *= 2
+		if backoff >= maxPluginRestartBackoff {
+			return maxPluginRestartBackoff
+		}
+	}
+	return backoff
+}
+
 func (r *runner) restartPlugin(key string) error {
 	lp, err := r.pluginManager.get(key)
 	if err != nil {
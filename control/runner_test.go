@@ -332,6 +332,29 @@ func TestRunnerState(t *testing.T) {
 	})
 }
 
+func TestRestartBackoff(t *testing.T) {
+	Convey("restartBackoff", t, func() {
+		defer func(backoff time.Duration) { PluginRestartBackoff = backoff }(PluginRestartBackoff)
+		PluginRestartBackoff = time.Second
+
+		Convey("does not delay the first restart", func() {
+			So(restartBackoff(0), ShouldEqual, 0)
+		})
+		Convey("doubles the delay with every restart", func() {
+			So(restartBackoff(1), ShouldEqual, time.Second)
+			So(restartBackoff(2), ShouldEqual, 2*time.Second)
+			So(restartBackoff(4), ShouldEqual, 8*time.Second)
+		})
+		Convey("caps the delay", func() {
+			So(restartBackoff(100), ShouldEqual, maxPluginRestartBackoff)
+		})
+		Convey("is disabled by a zero backoff", func() {
+			PluginRestartBackoff = 0
+			So(restartBackoff(3), ShouldEqual, 0)
+		})
+	})
+}
+
 func TestRunnerPluginRunning(t *testing.T) {
 	Convey("snap/control", t, func() {
 		Convey("Runner", func() {
@@ -391,7 +414,7 @@ func TestRunnerPluginRunning(t *testing.T) {
 						So(e, ShouldBeNil)
 						ap.client = new(MockHealthyPluginCollectorClient)
 						ap.CheckHealth()
-						So(ap.failedHealthChecks, ShouldEqual, 0)
+						So(ap.FailedHealthChecks(), ShouldEqual, 0)
 					})
 
 					Convey("healthcheck on unhealthy plugin increments failedHealthChecks", func() {
@@ -408,7 +431,7 @@ func TestRunnerPluginRunning(t *testing.T) {
 						So(e, ShouldBeNil)
 						ap.client = new(MockUnhealthyPluginCollectorClient)
 						ap.CheckHealth()
-						So(ap.failedHealthChecks, ShouldEqual, 1)
+						So(ap.FailedHealthChecks(), ShouldEqual, 1)
 					})
 
 					Convey("successful healthcheck resets failedHealthChecks", func() {
@@ -426,10 +449,11 @@ func TestRunnerPluginRunning(t *testing.T) {
 						ap.client = new(MockUnhealthyPluginCollectorClient)
 						ap.CheckHealth()
 						ap.CheckHealth()
-						So(ap.failedHealthChecks, ShouldEqual, 2)
+						So(ap.FailedHealthChecks(), ShouldEqual, 2)
+						So(ap.Healthy(), ShouldBeTrue)
 						ap.client = new(MockHealthyPluginCollectorClient)
 						ap.CheckHealth()
-						So(ap.failedHealthChecks, ShouldEqual, 0)
+						So(ap.FailedHealthChecks(), ShouldEqual, 0)
 					})
 
 					Convey("three consecutive failedHealthChecks disables the plugin", func() {
@@ -448,7 +472,8 @@ func TestRunnerPluginRunning(t *testing.T) {
 						ap.CheckHealth()
 						ap.CheckHealth()
 						ap.CheckHealth()
-						So(ap.failedHealthChecks, ShouldEqual, 3)
+						So(ap.FailedHealthChecks(), ShouldEqual, 3)
+						So(ap.Healthy(), ShouldBeFalse)
 					})
 
 					Convey("should return error for Run error", func() {
@@ -0,0 +1,135 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/cdata"
+	"github.com/intelsdi-x/snap/core/ctypes"
+	"github.com/intelsdi-x/snap/pkg/cfgcrypt"
+	"github.com/intelsdi-x/snap/pkg/secrets"
+)
+
+// resolvesSecrets resolves the references to secrets of the config of the
+// plugins, e.g. secret://env/SNAP_SECRET_INFLUX_PASSWORD
+type resolvesSecrets interface {
+	Resolve(value string) (string, error)
+}
+
+// SetSecretResolver sets the resolver of the references to secrets of the
+// config given to the plugins. The references are resolved each time the
+// config is given to a plugin, the config of the tasks and the global config
+// keep the references
+func (p *pluginControl) SetSecretResolver(r resolvesSecrets) {
+	p.secrets = r
+}
+
+// resolveConfig returns the config with its references to environment
+// variables and to secrets resolved and its encrypted values decrypted, the
+// config is returned as it is when it has none
+func (p *pluginControl) resolveConfig(config map[string]ctypes.ConfigValue) (map[string]ctypes.ConfigValue, error) {
+	resolved, _, err := p.resolveTable(config)
+	return resolved, err
+}
+
+// resolveTable returns a copy of the config with its references to
+// environment variables and to secrets resolved, its encrypted values
+// decrypted and its typed values as strings and true, or the config and
+// false when it has none
+func (p *pluginControl) resolveTable(config map[string]ctypes.ConfigValue) (map[string]ctypes.ConfigValue, bool, error) {
+	// the environment variables are interpolated first, their values can be
+	// references to secrets
+	config, interpolated, err := cdata.InterpolateTable(config, os.LookupEnv)
+	if err != nil {
+		return nil, false, err
+	}
+	var resolved map[string]ctypes.ConfigValue
+	for k, v := range config {
+		var value string
+		var err error
+		switch t := v.(type) {
+		case ctypes.ConfigValueDuration, ctypes.ConfigValueByteSize:
+			// the plugins are given the string form of the durations and
+			// the byte sizes, e.g. "30s" or "512MB"
+			value = t.(fmt.Stringer).String()
+		case ctypes.ConfigValueStr:
+			switch {
+			case p.cipher != nil && cfgcrypt.IsEncrypted(t.Value):
+				value, err = p.cipher.Decrypt(t.Value)
+			case p.secrets != nil && secrets.IsReference(t.Value):
+				value, err = p.secrets.Resolve(t.Value)
+			default:
+				continue
+			}
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		if resolved == nil {
+			resolved = make(map[string]ctypes.ConfigValue, len(config))
+			for k, v := range config {
+				resolved[k] = v
+			}
+		}
+		resolved[k] = ctypes.ConfigValueStr{Value: value}
+	}
+	if resolved == nil {
+		return config, interpolated, nil
+	}
+	return resolved, true, nil
+}
+
+// secretMetric is a metric given to a collector with the references to
+// secrets of its config resolved and its encrypted values decrypted, the
+// metric of the subscription keeps them
+type secretMetric struct {
+	core.Metric
+	config *cdata.ConfigDataNode
+}
+
+func (m secretMetric) Config() *cdata.ConfigDataNode {
+	return m.config
+}
+
+// resolveMetrics returns the metrics with the references to environment
+// variables and to secrets of their config resolved and their encrypted
+// values decrypted
+func (p *pluginControl) resolveMetrics(mts []core.Metric) ([]core.Metric, error) {
+	resolved := make([]core.Metric, len(mts))
+	for i, mt := range mts {
+		resolved[i] = mt
+		if mt.Config() == nil {
+			continue
+		}
+		config, ok, err := p.resolveTable(mt.Config().Table())
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			resolved[i] = secretMetric{Metric: mt, config: cdata.FromTable(config)}
+		}
+	}
+	return resolved, nil
+}
@@ -0,0 +1,126 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/cdata"
+	"github.com/intelsdi-x/snap/core/ctypes"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type mockSecrets map[string]string
+
+func (m mockSecrets) Resolve(value string) (string, error) {
+	if !strings.HasPrefix(value, "secret://") {
+		return value, nil
+	}
+	if secret, ok := m[value]; ok {
+		return secret, nil
+	}
+	return "", errors.New("no such secret")
+}
+
+func TestResolveSecrets(t *testing.T) {
+	Convey("Given a control resolving secrets", t, func() {
+		c := &pluginControl{}
+		c.SetSecretResolver(mockSecrets{"secret://env/SNAP_SECRET_DB": "p@ssw0rd"})
+		config := map[string]ctypes.ConfigValue{
+			"password": ctypes.ConfigValueStr{Value: "secret://env/SNAP_SECRET_DB"},
+			"user":     ctypes.ConfigValueStr{Value: "snap"},
+			"port":     ctypes.ConfigValueInt{Value: 5432},
+		}
+
+		Convey("the references of a config are resolved in a copy", func() {
+			resolved, err := c.resolveConfig(config)
+			So(err, ShouldBeNil)
+			So(resolved["password"], ShouldResemble, ctypes.ConfigValueStr{Value: "p@ssw0rd"})
+			So(resolved["user"], ShouldResemble, ctypes.ConfigValueStr{Value: "snap"})
+			So(resolved["port"], ShouldResemble, ctypes.ConfigValueInt{Value: 5432})
+			So(config["password"], ShouldResemble, ctypes.ConfigValueStr{Value: "secret://env/SNAP_SECRET_DB"})
+		})
+		Convey("the references of the config of the metrics are resolved", func() {
+			mt := plugin.MetricType{
+				Namespace_: core.NewNamespace("intel", "postgres", "connections"),
+				Config_:    cdata.FromTable(config),
+			}
+			plain := plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "foo")}
+			mts, err := c.resolveMetrics([]core.Metric{mt, plain})
+			So(err, ShouldBeNil)
+			So(mts[0].Namespace(), ShouldResemble, mt.Namespace())
+			So(mts[0].Config().Table()["password"], ShouldResemble, ctypes.ConfigValueStr{Value: "p@ssw0rd"})
+			So(mt.Config().Table()["password"], ShouldResemble, ctypes.ConfigValueStr{Value: "secret://env/SNAP_SECRET_DB"})
+			So(mts[1], ShouldResemble, plain)
+		})
+		Convey("an unknown reference is an error", func() {
+			config["password"] = ctypes.ConfigValueStr{Value: "secret://env/SNAP_SECRET_NONE"}
+			_, err := c.resolveConfig(config)
+			So(err, ShouldNotBeNil)
+		})
+	})
+
+	Convey("Given a config referencing environment variables", t, func() {
+		os.Setenv("SNAP_TEST_DB_HOST", "db.example.com")
+		os.Setenv("SNAP_TEST_DB_PASSWORD", "secret://env/SNAP_SECRET_DB")
+		defer os.Unsetenv("SNAP_TEST_DB_HOST")
+		defer os.Unsetenv("SNAP_TEST_DB_PASSWORD")
+		config := map[string]ctypes.ConfigValue{
+			"host":     ctypes.ConfigValueStr{Value: "${SNAP_TEST_DB_HOST}:${SNAP_TEST_DB_PORT:-5432}"},
+			"password": ctypes.ConfigValueStr{Value: "${SNAP_TEST_DB_PASSWORD}"},
+		}
+
+		Convey("the variables are interpolated without secrets resolver", func() {
+			c := &pluginControl{}
+			resolved, err := c.resolveConfig(config)
+			So(err, ShouldBeNil)
+			So(resolved["host"], ShouldResemble, ctypes.ConfigValueStr{Value: "db.example.com:5432"})
+			So(config["host"], ShouldResemble, ctypes.ConfigValueStr{Value: "${SNAP_TEST_DB_HOST}:${SNAP_TEST_DB_PORT:-5432}"})
+		})
+		Convey("the variables can reference secrets", func() {
+			c := &pluginControl{}
+			c.SetSecretResolver(mockSecrets{"secret://env/SNAP_SECRET_DB": "p@ssw0rd"})
+			resolved, err := c.resolveConfig(config)
+			So(err, ShouldBeNil)
+			So(resolved["password"], ShouldResemble, ctypes.ConfigValueStr{Value: "p@ssw0rd"})
+		})
+		Convey("an unset variable is an error", func() {
+			c := &pluginControl{}
+			config["user"] = ctypes.ConfigValueStr{Value: "${SNAP_TEST_DB_USER}"}
+			_, err := c.resolveConfig(config)
+			So(err, ShouldNotBeNil)
+		})
+	})
+
+	Convey("Given a control without secrets resolver", t, func() {
+		c := &pluginControl{}
+		config := map[string]ctypes.ConfigValue{"password": ctypes.ConfigValueStr{Value: "secret://env/SNAP_SECRET_DB"}}
+		resolved, err := c.resolveConfig(config)
+		So(err, ShouldBeNil)
+		So(resolved, ShouldResemble, config)
+	})
+}
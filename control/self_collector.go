@@ -0,0 +1,284 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/core"
+)
+
+const (
+	// SelfCollectorName is the name of the built-in collector of the
+	// statistics of snapteld, exposed under the /snap namespace
+	SelfCollectorName    = "snap"
+	selfCollectorVersion = 1
+)
+
+// managesTasks is the source of the statistics of the worker pools and the
+// tasks of the scheduler
+type managesTasks interface {
+	GetTasks() map[string]core.Task
+	WorkerPoolStats() []core.WorkerPoolStats
+}
+
+// selfMetric describes a metric of the self collector
+type selfMetric struct {
+	namespace   core.Namespace
+	description string
+	unit        string
+	dataType    string
+}
+
+var selfMetrics = []selfMetric{
+	{core.NewNamespace("snap", "runtime", "goroutines"), "number of goroutines", "", "int"},
+	{core.NewNamespace("snap", "runtime", "heap_alloc"), "bytes of allocated heap objects", "B", "uint64"},
+	{core.NewNamespace("snap", "runtime", "heap_sys"), "bytes of heap memory obtained from the OS", "B", "uint64"},
+	{core.NewNamespace("snap", "runtime", "heap_objects"), "number of allocated heap objects", "", "uint64"},
+	{core.NewNamespace("snap", "runtime", "gc_count"), "number of completed GC cycles", "", "uint64"},
+	{core.NewNamespace("snap", "runtime", "gc_pause_total"), "total time the GC paused the program", "ns", "uint64"},
+	{core.NewNamespace("snap", "runtime", "gc_pause_last"), "time the last GC paused the program", "ns", "uint64"},
+	{core.NewNamespace("snap", "scheduler").AddDynamicElement("pool", "worker pool of the scheduler: collect, process or publish").AddStaticElement("queue_depth"), "number of jobs waiting in the queue of the pool", "", "uint64"},
+	{core.NewNamespace("snap", "scheduler").AddDynamicElement("pool", "worker pool of the scheduler: collect, process or publish").AddStaticElement("queue_latency"), "average time the jobs waited for a worker", "ns", "int64"},
+	{core.NewNamespace("snap", "scheduler").AddDynamicElement("pool", "worker pool of the scheduler: collect, process or publish").AddStaticElement("workers"), "number of workers of the pool", "", "uint64"},
+	{core.NewNamespace("snap", "plugins").AddDynamicElement("plugin_type", "type of the plugin").AddDynamicElement("plugin_name", "name of the plugin").AddDynamicElement("plugin_version", "version of the plugin").AddStaticElement("calls"), "number of calls made to the plugin", "", "uint64"},
+	{core.NewNamespace("snap", "plugins").AddDynamicElement("plugin_type", "type of the plugin").AddDynamicElement("plugin_name", "name of the plugin").AddDynamicElement("plugin_version", "version of the plugin").AddStaticElement("errors"), "number of calls made to the plugin which failed", "", "uint64"},
+	{core.NewNamespace("snap", "plugins").AddDynamicElement("plugin_type", "type of the plugin").AddDynamicElement("plugin_name", "name of the plugin").AddDynamicElement("plugin_version", "version of the plugin").AddStaticElement("latency"), "average time the calls made to the plugin took", "ns", "int64"},
+	{core.NewNamespace("snap", "tasks").AddDynamicElement("task_id", "ID of the task").AddStaticElement("hit_count"), "number of runs of the task", "", "uint64"},
+	{core.NewNamespace("snap", "tasks").AddDynamicElement("task_id", "ID of the task").AddStaticElement("miss_count"), "number of runs of the task missed", "", "uint64"},
+	{core.NewNamespace("snap", "tasks").AddDynamicElement("task_id", "ID of the task").AddStaticElement("failed_count"), "number of runs of the task which failed", "", "uint64"},
+}
+
+// selfCollector collects the statistics of snapteld: the runtime, the worker
+// pools of the scheduler, the calls made to the plugins and the tasks
+type selfCollector struct {
+	plugin  *catalogedPlugin
+	control *pluginControl
+
+	mutex sync.RWMutex
+	tasks managesTasks
+}
+
+func newSelfCollector(p *pluginControl) *selfCollector {
+	return &selfCollector{
+		plugin: &catalogedPlugin{
+			name:         SelfCollectorName,
+			version:      selfCollectorVersion,
+			typeName:     plugin.CollectorPluginType,
+			state:        BuiltinState,
+			loadedTime:   time.Now(),
+			configPolicy: cpolicy.New(),
+		},
+		control: p,
+	}
+}
+
+// SetTaskManager sets the scheduler whose worker pools and tasks are
+// collected by the self collector
+func (p *pluginControl) SetTaskManager(tm managesTasks) {
+	if sc, ok := p.builtinCollectors[SelfCollectorName].(*selfCollector); ok {
+		sc.mutex.Lock()
+		sc.tasks = tm
+		sc.mutex.Unlock()
+	}
+}
+
+func (s *selfCollector) metricTypes() []*metricType {
+	now := time.Now()
+	mts := make([]*metricType, len(selfMetrics))
+	for i, sm := range selfMetrics {
+		mts[i] = &metricType{
+			Plugin:             s.plugin,
+			namespace:          append(core.Namespace{}, sm.namespace...),
+			version:            selfCollectorVersion,
+			lastAdvertisedTime: now,
+			policy:             cpolicy.NewPolicyNode(),
+			description:        sm.description,
+			unit:               sm.unit,
+			dataType:           sm.dataType,
+		}
+	}
+	return mts
+}
+
+// collect returns the values of the requested metrics, the dynamic elements
+// requested with * are expanded to all the pools, plugins or tasks
+func (s *selfCollector) collect(metrics []core.Metric, now time.Time) ([]core.Metric, error) {
+	var memStats *runtime.MemStats
+	s.mutex.RLock()
+	tasks := s.tasks
+	s.mutex.RUnlock()
+
+	mts := []core.Metric{}
+	for _, m := range metrics {
+		ns := m.Namespace()
+		if len(ns) < 3 {
+			continue
+		}
+		last := ns[len(ns)-1].Value
+		switch ns[1].Value {
+		case "runtime":
+			if memStats == nil {
+				memStats = &runtime.MemStats{}
+				runtime.ReadMemStats(memStats)
+			}
+			if v, ok := runtimeValue(memStats, last); ok {
+				mts = append(mts, selfMetricValue(m, ns, nil, v, now))
+			}
+		case "scheduler":
+			if tasks == nil {
+				continue
+			}
+			for _, ps := range tasks.WorkerPoolStats() {
+				if !matchesElement(ns[2], ps.Pool) {
+					continue
+				}
+				var v interface{}
+				switch last {
+				case "queue_depth":
+					v = uint64(ps.QueueDepth)
+				case "queue_latency":
+					v = int64(ps.QueueLatency)
+				case "workers":
+					v = uint64(ps.Workers)
+				default:
+					continue
+				}
+				mts = append(mts, selfMetricValue(m, ns, []string{ps.Pool}, v, now))
+			}
+		case "plugins":
+			if len(ns) != 6 {
+				continue
+			}
+			for key, c := range s.control.pluginRunner.AvailablePlugins().callStats() {
+				tnv := strings.Split(key, core.Separator)
+				if len(tnv) != 3 || !matchesElement(ns[2], tnv[0]) || !matchesElement(ns[3], tnv[1]) || !matchesElement(ns[4], tnv[2]) {
+					continue
+				}
+				var v interface{}
+				switch last {
+				case "calls":
+					v = c.calls
+				case "errors":
+					v = c.errors
+				case "latency":
+					var avg int64
+					if c.calls > 0 {
+						avg = int64(c.latency) / int64(c.calls)
+					}
+					v = avg
+				default:
+					continue
+				}
+				mts = append(mts, selfMetricValue(m, ns, tnv, v, now))
+			}
+		case "tasks":
+			if tasks == nil {
+				continue
+			}
+			for id, t := range tasks.GetTasks() {
+				if !matchesElement(ns[2], id) {
+					continue
+				}
+				var v interface{}
+				switch last {
+				case "hit_count":
+					v = uint64(t.HitCount())
+				case "miss_count":
+					v = uint64(t.MissedCount())
+				case "failed_count":
+					v = uint64(t.FailedCount())
+				default:
+					continue
+				}
+				mt := selfMetricValue(m, ns, []string{id}, v, now)
+				mt.Tags_ = map[string]string{"task_name": t.GetName()}
+				mts = append(mts, mt)
+			}
+		}
+	}
+	return mts, nil
+}
+
+func runtimeValue(ms *runtime.MemStats, name string) (interface{}, bool) {
+	switch name {
+	case "goroutines":
+		return runtime.NumGoroutine(), true
+	case "heap_alloc":
+		return ms.HeapAlloc, true
+	case "heap_sys":
+		return ms.HeapSys, true
+	case "heap_objects":
+		return ms.HeapObjects, true
+	case "gc_count":
+		return uint64(ms.NumGC), true
+	case "gc_pause_total":
+		return ms.PauseTotalNs, true
+	case "gc_pause_last":
+		if ms.NumGC == 0 {
+			return uint64(0), true
+		}
+		return ms.PauseNs[(ms.NumGC+255)%256], true
+	}
+	return nil, false
+}
+
+// matchesElement returns true when the requested element of a namespace
+// matches the value, * matches any value
+func matchesElement(e core.NamespaceElement, value string) bool {
+	return e.Value == "*" || e.Value == value
+}
+
+// selfMetricValue returns the collected metric of a requested one, the
+// dynamic elements of its namespace are set to the given values in order
+func selfMetricValue(requested core.Metric, ns core.Namespace, values []string, data interface{}, now time.Time) plugin.MetricType {
+	collected := make(core.Namespace, len(ns))
+	copy(collected, ns)
+	i := 0
+	for j := range collected {
+		if collected[j].IsDynamic() && i < len(values) {
+			collected[j].Value = values[i]
+			i++
+		}
+	}
+	return plugin.MetricType{
+		Namespace_: collected,
+		Version_:   selfCollectorVersion,
+		Config_:    requested.Config(),
+		Unit_:      unitOf(ns),
+		Data_:      data,
+		Timestamp_: now,
+	}
+}
+
+// unitOf returns the unit of the self metric of the namespace
+func unitOf(ns core.Namespace) string {
+	for _, sm := range selfMetrics {
+		if len(sm.namespace) == len(ns) && sm.namespace[1].Value == ns[1].Value && sm.namespace[len(ns)-1].Value == ns[len(ns)-1].Value {
+			return sm.unit
+		}
+	}
+	return ""
+}
@@ -0,0 +1,143 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type selfTestTask struct {
+	core.Task
+	id     string
+	name   string
+	hits   uint
+	misses uint
+}
+
+func (t *selfTestTask) ID() string        { return t.id }
+func (t *selfTestTask) GetName() string   { return t.name }
+func (t *selfTestTask) HitCount() uint    { return t.hits }
+func (t *selfTestTask) MissedCount() uint { return t.misses }
+func (t *selfTestTask) FailedCount() uint { return 0 }
+
+type selfTestTaskManager struct {
+	tasks map[string]core.Task
+}
+
+func (m *selfTestTaskManager) GetTasks() map[string]core.Task {
+	return m.tasks
+}
+
+func (m *selfTestTaskManager) WorkerPoolStats() []core.WorkerPoolStats {
+	return []core.WorkerPoolStats{
+		{Pool: "collect", Workers: 4, QueueDepth: 2, QueueLatency: time.Millisecond},
+		{Pool: "publish", Workers: 1},
+	}
+}
+
+func selfRequest(ns core.Namespace) core.Metric {
+	return &metricType{namespace: ns, version: selfCollectorVersion}
+}
+
+func TestSelfCollector(t *testing.T) {
+	Convey("Given control with the self metrics enabled", t, func() {
+		cfg := GetDefaultConfig()
+		cfg.SelfMetrics = true
+		c := &pluginControl{Config: cfg, metricCatalog: newMetricCatalog(), pluginRunner: newRunner()}
+		c.builtinCollectors = newBuiltinCollectors(c)
+		c.SetTaskManager(&selfTestTaskManager{tasks: map[string]core.Task{
+			"1": &selfTestTask{id: "1", name: "task-one", hits: 5, misses: 1},
+			"2": &selfTestTask{id: "2", name: "task-two", hits: 2},
+		}})
+		bc := c.builtinCollector(core.CollectorPluginType.String(), SelfCollectorName)
+		So(bc, ShouldNotBeNil)
+
+		Convey("its metrics are cataloged under /snap", func() {
+			c.catalogBuiltinCollectors()
+			So(c.metricCatalog.Keys(), ShouldContain, "/snap/runtime/goroutines")
+			mts, err := c.metricCatalog.Fetch(core.NewNamespace("snap"))
+			So(err, ShouldBeNil)
+			So(mts, ShouldHaveLength, len(selfMetrics))
+			Convey("but not persisted", func() {
+				So(toStoredPlugins(mts), ShouldBeEmpty)
+			})
+		})
+		Convey("the runtime statistics are collected", func() {
+			mts, err := bc.collect([]core.Metric{
+				selfRequest(core.NewNamespace("snap", "runtime", "goroutines")),
+				selfRequest(core.NewNamespace("snap", "runtime", "heap_alloc")),
+			}, time.Now())
+			So(err, ShouldBeNil)
+			So(mts, ShouldHaveLength, 2)
+			So(mts[0].Data(), ShouldBeGreaterThan, 0)
+			So(mts[1].Unit(), ShouldEqual, "B")
+		})
+		Convey("the statistics of every worker pool are collected", func() {
+			ns := core.NewNamespace("snap", "scheduler").AddDynamicElement("pool", "").AddStaticElement("queue_depth")
+			mts, err := bc.collect([]core.Metric{selfRequest(ns)}, time.Now())
+			So(err, ShouldBeNil)
+			So(mts, ShouldHaveLength, 2)
+			So(mts[0].Namespace().String(), ShouldEqual, "/snap/scheduler/collect/queue_depth")
+			So(mts[0].Data(), ShouldEqual, uint64(2))
+		})
+		Convey("the statistics of the requested task are collected", func() {
+			ns := core.NewNamespace("snap", "tasks").AddDynamicElement("task_id", "").AddStaticElement("hit_count")
+			ns[2].Value = "1"
+			mts, err := bc.collect([]core.Metric{selfRequest(ns)}, time.Now())
+			So(err, ShouldBeNil)
+			So(mts, ShouldHaveLength, 1)
+			So(mts[0].Data(), ShouldEqual, uint64(5))
+			So(mts[0].Tags()["task_name"], ShouldEqual, "task-one")
+		})
+		Convey("the calls made to the plugins are collected", func() {
+			key := "collector" + core.Separator + "mock" + core.Separator + "2"
+			c.pluginRunner.AvailablePlugins().countCall(key, false, 2*time.Millisecond)
+			c.pluginRunner.AvailablePlugins().countCall(key, true, 4*time.Millisecond)
+			requested := []core.Metric{}
+			for _, name := range []string{"calls", "errors", "latency"} {
+				ns := core.NewNamespace("snap", "plugins").
+					AddDynamicElement("plugin_type", "").
+					AddDynamicElement("plugin_name", "").
+					AddDynamicElement("plugin_version", "").
+					AddStaticElement(name)
+				requested = append(requested, selfRequest(ns))
+			}
+			mts, err := bc.collect(requested, time.Now())
+			So(err, ShouldBeNil)
+			So(mts, ShouldHaveLength, 3)
+			So(mts[0].Namespace().String(), ShouldEqual, "/snap/plugins/collector/mock/2/calls")
+			So(mts[0].Data(), ShouldEqual, uint64(2))
+			So(mts[1].Data(), ShouldEqual, uint64(1))
+			So(mts[2].Data(), ShouldEqual, int64(3*time.Millisecond))
+		})
+	})
+	Convey("Given control with the self metrics disabled", t, func() {
+		c := &pluginControl{Config: GetDefaultConfig()}
+		c.builtinCollectors = newBuiltinCollectors(c)
+		So(c.builtinCollector(core.CollectorPluginType.String(), SelfCollectorName), ShouldBeNil)
+	})
+}
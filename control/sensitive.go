@@ -0,0 +1,100 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"github.com/intelsdi-x/snap/core/cdata"
+	"github.com/intelsdi-x/snap/core/ctypes"
+	"github.com/intelsdi-x/snap/pkg/cfgcrypt"
+	"github.com/intelsdi-x/snap/pkg/secrets"
+)
+
+// redacted replaces the sensitive values of the config returned by the API
+const redacted = "********"
+
+// encryptsConfig encrypts the sensitive values of the config of the tasks,
+// e.g. with a cfgcrypt.Cipher
+type encryptsConfig interface {
+	Encrypt(value string) (string, error)
+	Decrypt(value string) (string, error)
+}
+
+// SetConfigCipher sets the cipher the sensitive values of the config of the
+// tasks are encrypted with. The values are decrypted each time the config is
+// given to a plugin.
+func (p *pluginControl) SetConfigCipher(c encryptsConfig) {
+	p.cipher = c
+}
+
+// sensitiveKeys returns the keys the config policies of the loaded plugins
+// mark sensitive
+func (p *pluginControl) sensitiveKeys() map[string]bool {
+	keys := map[string]bool{}
+	for _, lp := range p.pluginManager.all() {
+		if lp.ConfigPolicy == nil {
+			continue
+		}
+		for _, node := range lp.ConfigPolicy.GetAll() {
+			for _, k := range node.SensitiveKeys() {
+				keys[k] = true
+			}
+		}
+	}
+	return keys
+}
+
+// SealConfig encrypts in place the sensitive values of the config of a task,
+// the values of the keys marked sensitive by the config policy of a loaded
+// plugin. The values already encrypted and the references to secrets are
+// kept as they are.
+func (p *pluginControl) SealConfig(config map[string]interface{}) error {
+	if p.cipher == nil || len(config) == 0 {
+		return nil
+	}
+	keys := p.sensitiveKeys()
+	for k, v := range config {
+		s, ok := v.(string)
+		if !ok || !keys[k] || cfgcrypt.IsEncrypted(s) || secrets.IsReference(s) {
+			continue
+		}
+		sealed, err := p.cipher.Encrypt(s)
+		if err != nil {
+			return err
+		}
+		config[k] = sealed
+	}
+	return nil
+}
+
+// RedactConfig returns a copy of the config with the values of the keys
+// marked sensitive by the config policy of a loaded plugin redacted, the
+// references to secrets are kept
+func (p *pluginControl) RedactConfig(cdn cdata.ConfigDataNode) cdata.ConfigDataNode {
+	keys := p.sensitiveKeys()
+	table := cdn.Table()
+	out := make(map[string]ctypes.ConfigValue, len(table))
+	for k, v := range table {
+		if s, ok := v.(ctypes.ConfigValueStr); ok && keys[k] && !secrets.IsReference(s.Value) {
+			v = ctypes.ConfigValueStr{Value: redacted}
+		}
+		out[k] = v
+	}
+	return *cdata.FromTable(out)
+}
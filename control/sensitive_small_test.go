@@ -0,0 +1,97 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/core/cdata"
+	"github.com/intelsdi-x/snap/core/ctypes"
+	"github.com/intelsdi-x/snap/pkg/cfgcrypt"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSensitiveConfig(t *testing.T) {
+	Convey("Given a control with a plugin whose password is sensitive", t, func() {
+		policy := cpolicy.New()
+		node := cpolicy.NewPolicyNode()
+		user, _ := cpolicy.NewStringRule("user", false)
+		password, _ := cpolicy.NewStringRule("password", false)
+		password.SetSensitive(true)
+		node.Add(user, password)
+		policy.Add([]string{"intel", "mock"}, node)
+		pm := newPluginManager()
+		So(pm.loadedPlugins.add(&loadedPlugin{
+			Meta:         plugin.PluginMeta{Name: "mock", Version: 1, Type: plugin.CollectorPluginType},
+			Type:         plugin.CollectorPluginType,
+			ConfigPolicy: policy,
+		}), ShouldBeNil)
+		cipher, err := cfgcrypt.NewCipher(make([]byte, 32))
+		So(err, ShouldBeNil)
+		c := &pluginControl{pluginManager: pm}
+		c.SetConfigCipher(cipher)
+
+		Convey("the sensitive values of the config of a task are encrypted", func() {
+			config := map[string]interface{}{
+				"user":     "snap",
+				"password": "p@ssw0rd",
+				"port":     5432,
+			}
+			So(c.SealConfig(config), ShouldBeNil)
+			So(config["user"], ShouldEqual, "snap")
+			So(config["port"], ShouldEqual, 5432)
+			sealed := config["password"].(string)
+			So(cfgcrypt.IsEncrypted(sealed), ShouldBeTrue)
+
+			Convey("once", func() {
+				So(c.SealConfig(config), ShouldBeNil)
+				So(config["password"], ShouldEqual, sealed)
+			})
+
+			Convey("and decrypted when they are given to the plugins", func() {
+				resolved, err := c.resolveConfig(map[string]ctypes.ConfigValue{
+					"password": ctypes.ConfigValueStr{Value: sealed},
+				})
+				So(err, ShouldBeNil)
+				So(resolved["password"], ShouldResemble, ctypes.ConfigValueStr{Value: "p@ssw0rd"})
+			})
+		})
+
+		Convey("the references to secrets aren't encrypted", func() {
+			config := map[string]interface{}{"password": "secret://env/SNAP_SECRET_DB"}
+			So(c.SealConfig(config), ShouldBeNil)
+			So(config["password"], ShouldEqual, "secret://env/SNAP_SECRET_DB")
+		})
+
+		Convey("the sensitive values of the global config are redacted", func() {
+			cdn := cdata.NewNode()
+			cdn.AddItem("user", ctypes.ConfigValueStr{Value: "snap"})
+			cdn.AddItem("password", ctypes.ConfigValueStr{Value: "p@ssw0rd"})
+			out := c.RedactConfig(*cdn)
+			So(out.Table()["user"], ShouldResemble, ctypes.ConfigValueStr{Value: "snap"})
+			So(out.Table()["password"], ShouldResemble, ctypes.ConfigValueStr{Value: "********"})
+			So(cdn.Table()["password"], ShouldResemble, ctypes.ConfigValueStr{Value: "p@ssw0rd"})
+		})
+	})
+}
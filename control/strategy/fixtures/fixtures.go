@@ -61,6 +61,7 @@ type MockAvailablePlugin struct {
 	version    int
 	port       string
 	isRemote   bool
+	load       plugin.Load
 }
 
 func NewMockAvailablePlugin() *MockAvailablePlugin {
@@ -131,6 +132,11 @@ func (m *MockAvailablePlugin) WithVersion(ver int) *MockAvailablePlugin {
 	return m
 }
 
+func (m *MockAvailablePlugin) WithLoad(load plugin.Load) *MockAvailablePlugin {
+	m.load = load
+	return m
+}
+
 func (m MockAvailablePlugin) HitCount() int {
 	return m.hitCount
 }
@@ -173,6 +179,10 @@ func (m MockAvailablePlugin) RoutingStrategy() plugin.RoutingStrategyType {
 	return m.strategy
 }
 
+func (m MockAvailablePlugin) Load() plugin.Load {
+	return m.load
+}
+
 func (m MockAvailablePlugin) SetID(id uint32) {
 	m.id = id
 }
@@ -197,6 +207,14 @@ func (m MockAvailablePlugin) Port() string {
 	return m.port
 }
 
+func (m MockAvailablePlugin) FailedHealthChecks() int {
+	return 0
+}
+
+func (m MockAvailablePlugin) Healthy() bool {
+	return true
+}
+
 func (m MockAvailablePlugin) IsRemote() bool {
 	return m.isRemote
 }
@@ -0,0 +1,159 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package strategy
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+)
+
+// leastLoaded provides a strategy that selects the available plugin with the
+// lowest load, the least recently used among those with the same load.  The
+// load reported by a plugin is only refreshed by the pings of the health
+// checks, so the calls the strategy dispatched to the plugin and which haven't
+// ended yet are added to the calls in flight it reported.
+type leastLoaded struct {
+	*cache
+	logger   *log.Entry
+	mutex    *sync.Mutex
+	inFlight map[AvailablePlugin]int64
+}
+
+func NewLeastLoaded(cacheTTL time.Duration) *leastLoaded {
+	return &leastLoaded{
+		NewCache(cacheTTL),
+		log.WithFields(log.Fields{
+			"_module": "control-routing",
+		}),
+		&sync.Mutex{},
+		map[AvailablePlugin]int64{},
+	}
+}
+
+// String returns the strategy name.
+func (l *leastLoaded) String() string {
+	return "least-loaded"
+}
+
+// CacheTTL returns the TTL for the cache.
+func (l *leastLoaded) CacheTTL(taskID string) (time.Duration, error) {
+	return l.ttl, nil
+}
+
+// Select selects an available plugin using the least-loaded strategy and
+// counts the call dispatched to it in its calls in flight until Done.
+func (l *leastLoaded) Select(aps []AvailablePlugin, _ string) (AvailablePlugin, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	ap, err := l.selectPlugin(aps)
+	if err != nil {
+		return nil, err
+	}
+	l.inFlight[ap]++
+	return ap, nil
+}
+
+// Remove selects a plugin
+// Since there is no call dispatched to the plugin it isn't counted in its calls in flight
+func (l *leastLoaded) Remove(aps []AvailablePlugin, taskID string) (AvailablePlugin, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.selectPlugin(aps)
+}
+
+// Done ends a call dispatched to the plugin ap by Select.
+func (l *leastLoaded) Done(ap AvailablePlugin) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.inFlight[ap] > 1 {
+		l.inFlight[ap]--
+		return
+	}
+	delete(l.inFlight, ap)
+}
+
+// load returns the load reported by the plugin ap with the calls dispatched to
+// it which haven't ended yet added to its calls in flight.
+func (l *leastLoaded) load(ap AvailablePlugin) plugin.Load {
+	load := ap.Load()
+	load.InFlight += l.inFlight[ap]
+	return load
+}
+
+func (l *leastLoaded) selectPlugin(aps []AvailablePlugin) (AvailablePlugin, error) {
+	index := -1
+	var selected plugin.Load
+	for i, ap := range aps {
+		load := l.load(ap)
+		if index == -1 || load.Less(selected) || (load == selected && ap.LastHit().Before(aps[index].LastHit())) {
+			index, selected = i, load
+		}
+	}
+	if index > -1 {
+		l.logger.WithFields(log.Fields{
+			"block":     "select",
+			"strategy":  l.String(),
+			"pool size": len(aps),
+			"index":     aps[index].String(),
+			"in-flight": selected.InFlight,
+		}).Debug("plugin selected")
+		return aps[index], nil
+	}
+	l.logger.WithFields(log.Fields{
+		"block":    "select",
+		"strategy": l.String(),
+		"error":    ErrCouldNotSelect,
+	}).Error("error selecting")
+	return nil, ErrCouldNotSelect
+}
+
+// CheckCache checks the cache for metric types.
+func (l *leastLoaded) CheckCache(mts []core.Metric, _ string) ([]core.Metric, []core.Metric) {
+	return l.checkCache(mts)
+}
+
+// UpdateCache updates the cache with the given array of metrics.
+func (l *leastLoaded) UpdateCache(mts []core.Metric, _ string) {
+	l.updateCache(mts)
+}
+
+// AllCacheHits returns cache hits across all metrics.
+func (l *leastLoaded) AllCacheHits() uint64 {
+	return l.allCacheHits()
+}
+
+// AllCacheMisses returns cache misses across all metrics.
+func (l *leastLoaded) AllCacheMisses() uint64 {
+	return l.allCacheMisses()
+}
+
+// CacheHits returns the cache hits for a given metric namespace and version.
+func (l *leastLoaded) CacheHits(ns string, version int, _ string) (uint64, error) {
+	return l.cacheHits(ns, version)
+}
+
+// CacheMisses returns the cache misses for a given metric namespace and version.
+func (l *leastLoaded) CacheMisses(ns string, version int, _ string) (uint64, error) {
+	return l.cacheMisses(ns, version)
+}
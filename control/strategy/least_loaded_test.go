@@ -0,0 +1,107 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package strategy
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	. "github.com/intelsdi-x/snap/control/strategy/fixtures"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLeastLoadedRouter(t *testing.T) {
+	Convey("Given a least-loaded router", t, func() {
+		router := NewLeastLoaded(100 * time.Millisecond)
+		So(router, ShouldNotBeNil)
+		So(router.String(), ShouldResemble, "least-loaded")
+		Convey("Select the plugin with the fewest calls in flight", func() {
+			p1 := NewMockAvailablePlugin().WithName("p1").WithLoad(plugin.Load{InFlight: 3})
+			p2 := NewMockAvailablePlugin().WithName("p2").WithLoad(plugin.Load{InFlight: 1, LastLatency: time.Second})
+			sp, err := router.Select([]AvailablePlugin{p1, p2}, "task1")
+			So(err, ShouldBeNil)
+			So(sp, ShouldEqual, p2)
+		})
+		Convey("Select the plugin with the lowest latency when the calls in flight are equal", func() {
+			p1 := NewMockAvailablePlugin().WithName("p1").WithLoad(plugin.Load{InFlight: 1, LastLatency: time.Second})
+			p2 := NewMockAvailablePlugin().WithName("p2").WithLoad(plugin.Load{InFlight: 1, LastLatency: time.Millisecond})
+			sp, err := router.Select([]AvailablePlugin{p1, p2}, "task1")
+			So(err, ShouldBeNil)
+			So(sp, ShouldEqual, p2)
+		})
+		Convey("Select the least recently used plugin when the loads are equal", func() {
+			p1 := NewMockAvailablePlugin().WithName("p1").WithLastHit(time.Now())
+			p2 := NewMockAvailablePlugin().WithName("p2").WithLastHit(time.Now().Add(-time.Minute))
+			sp, err := router.Select([]AvailablePlugin{p1, p2}, "task1")
+			So(err, ShouldBeNil)
+			So(sp, ShouldEqual, p2)
+		})
+		Convey("Spread the concurrent calls over the plugins reporting the same load", func() {
+			p1 := NewMockAvailablePlugin().WithName("p1").WithLoad(plugin.Load{InFlight: 1})
+			p2 := NewMockAvailablePlugin().WithName("p2").WithLoad(plugin.Load{InFlight: 1})
+			p3 := NewMockAvailablePlugin().WithName("p3").WithLoad(plugin.Load{InFlight: 1})
+			aps := []AvailablePlugin{p1, p2, p3}
+			selected := make(chan AvailablePlugin, 6)
+			var wg sync.WaitGroup
+			for i := 0; i < 6; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					sp, err := router.Select(aps, "task1")
+					if err == nil {
+						selected <- sp
+					}
+				}()
+			}
+			wg.Wait()
+			close(selected)
+			calls := map[AvailablePlugin]int{}
+			for sp := range selected {
+				calls[sp]++
+			}
+			So(calls, ShouldResemble, map[AvailablePlugin]int{p1: 2, p2: 2, p3: 2})
+			Convey("and select the plugin whose calls ended", func() {
+				router.Done(p2)
+				router.Done(p2)
+				sp, err := router.Select(aps, "task1")
+				So(err, ShouldBeNil)
+				So(sp, ShouldEqual, p2)
+			})
+			Convey("but not count the plugin selected to be removed", func() {
+				router.Done(p3)
+				sp, err := router.Remove(aps, "task1")
+				So(err, ShouldBeNil)
+				So(sp, ShouldEqual, p3)
+				sp, err = router.Select(aps, "task1")
+				So(err, ShouldBeNil)
+				So(sp, ShouldEqual, p3)
+			})
+		})
+		Convey("Fail to select a plugin when there are none available", func() {
+			sp, err := router.Select([]AvailablePlugin{}, "task1")
+			So(sp, ShouldBeNil)
+			So(err, ShouldEqual, ErrCouldNotSelect)
+		})
+	})
+}
@@ -48,6 +48,8 @@ var (
 	ErrBadType     = errors.New("bad plugin type")
 	ErrBadStrategy = errors.New("bad strategy")
 	ErrPoolEmpty   = errors.New("plugin pool is empty")
+	// ErrPoolDraining - error message when a call is routed to a plugin being unloaded
+	ErrPoolDraining = errors.New("plugin is being unloaded")
 )
 
 type Pool interface {
@@ -61,6 +63,7 @@ type Pool interface {
 	RUnlock()
 	SelectAndKill(taskID, reason string)
 	SelectAP(taskID string, configID map[string]ctypes.ConfigValue) (AvailablePlugin, serror.SnapError)
+	Done(ap AvailablePlugin)
 	Strategy() RoutingAndCaching
 	Subscribe(taskID string)
 	SubscriptionCount() int
@@ -69,6 +72,12 @@ type Pool interface {
 	RestartCount() int
 	IncRestartCount()
 	KillAll(string)
+	Configure(core.PluginPoolConfig) error
+	PoolConfig() core.PluginPoolConfig
+	KillIdle(string) int
+	Drain(timeout time.Duration) bool
+	Draining() bool
+	Resume()
 }
 
 type AvailablePlugin interface {
@@ -79,6 +88,7 @@ type AvailablePlugin interface {
 	Exclusive() bool
 	Kill(r string) error
 	RoutingStrategy() plugin.RoutingStrategyType
+	Load() plugin.Load
 	SetID(id uint32)
 	String() string
 	Type() plugin.PluginType
@@ -125,6 +135,19 @@ type pool struct {
 	// restartCount the restart count of available plugins
 	// when the DeadAvailablePluginEvent occurs
 	restartCount int
+
+	// The settings declared by the plugin (applied on the first insert).
+	exclusive   bool
+	cacheTTL    time.Duration
+	concurrency int
+	routing     plugin.RoutingStrategyType
+
+	// The settings of the pool overriding the ones declared by the plugin.
+	config core.PluginPoolConfig
+
+	// draining is set (1) while the plugin is unloaded, no call is routed to
+	// the plugins of the pool anymore
+	draining int32
 }
 
 func NewPool(key string, plugins ...AvailablePlugin) (Pool, error) {
@@ -202,29 +225,64 @@ func (p *pool) Insert(a AvailablePlugin) error {
 func (p *pool) applyPluginMeta(a AvailablePlugin) error {
 	// Checking if plugin is exclusive
 	// (only one instance should be running).
-	if a.Exclusive() {
-		p.max = 1
-	}
+	p.exclusive = a.Exclusive()
 
 	// Set the cache TTL
-	cacheTTL := GlobalCacheExpiration
+	p.cacheTTL = GlobalCacheExpiration
 	// if the plugin exposes a default TTL that is greater the the global default use it
 	if a.CacheTTL() != 0 && a.CacheTTL() > GlobalCacheExpiration {
-		cacheTTL = a.CacheTTL()
+		p.cacheTTL = a.CacheTTL()
+	}
+
+	p.concurrency = a.ConcurrencyCount()
+	p.routing = a.RoutingStrategy()
+	p.RoutingAndCaching = nil
+	return p.applyConfig()
+}
+
+// applyConfig sets the max size, concurrency count and routing strategy of
+// the pool from the plugin meta overridden by the pool config.  The routing
+// strategy (and its cache) is only replaced when it changes.
+func (p *pool) applyConfig() error {
+	p.max = MaximumRunningPlugins
+	if p.config.MaxInstances > 0 {
+		p.max = p.config.MaxInstances
+	}
+	if p.exclusive {
+		p.max = 1
+	}
+
+	routing := p.routing
+	switch p.config.Routing {
+	case core.PoolRoutingLRU:
+		routing = plugin.DefaultRouting
+	case core.PoolRoutingSticky:
+		routing = plugin.StickyRouting
+	case core.PoolRoutingConfig:
+		routing = plugin.ConfigRouting
+	case core.PoolRoutingLeastLoaded:
+		routing = plugin.LeastLoadedRouting
 	}
 
 	// Set the concurrency count
-	p.concurrencyCount = a.ConcurrencyCount()
+	p.concurrencyCount = p.concurrency
+	if routing == plugin.StickyRouting {
+		p.concurrencyCount = 1
+	}
+	if p.RoutingAndCaching != nil && p.routingType() == routing {
+		return nil
+	}
 
 	// Set the routing and caching strategy
-	switch a.RoutingStrategy() {
+	switch routing {
 	case plugin.DefaultRouting:
-		p.RoutingAndCaching = NewLRU(cacheTTL)
+		p.RoutingAndCaching = NewLRU(p.cacheTTL)
 	case plugin.StickyRouting:
-		p.RoutingAndCaching = NewSticky(cacheTTL)
-		p.concurrencyCount = 1
+		p.RoutingAndCaching = NewSticky(p.cacheTTL)
 	case plugin.ConfigRouting:
-		p.RoutingAndCaching = NewConfigBased(cacheTTL)
+		p.RoutingAndCaching = NewConfigBased(p.cacheTTL)
+	case plugin.LeastLoadedRouting:
+		p.RoutingAndCaching = NewLeastLoaded(p.cacheTTL)
 	default:
 		return ErrBadStrategy
 	}
@@ -232,6 +290,77 @@ func (p *pool) applyPluginMeta(a AvailablePlugin) error {
 	return nil
 }
 
+// routingType returns the type of the current routing strategy
+func (p *pool) routingType() plugin.RoutingStrategyType {
+	switch p.RoutingAndCaching.(type) {
+	case *sticky:
+		return plugin.StickyRouting
+	case *configBased:
+		return plugin.ConfigRouting
+	case *leastLoaded:
+		return plugin.LeastLoadedRouting
+	}
+	return plugin.DefaultRouting
+}
+
+// Configure overrides the settings declared by the plugins of the pool,
+// the config is applied right away if the pool isn't empty
+func (p *pool) Configure(cfg core.PluginPoolConfig) error {
+	p.Lock()
+	defer p.Unlock()
+	if cfg == p.config {
+		return nil
+	}
+	p.config = cfg
+	if len(p.plugins) == 0 {
+		return nil
+	}
+	return p.applyConfig()
+}
+
+// PoolConfig returns the config overriding the settings declared by the
+// plugins of the pool
+func (p *pool) PoolConfig() core.PluginPoolConfig {
+	p.RLock()
+	defer p.RUnlock()
+	return p.config
+}
+
+// KillIdle kills the instances which haven't been hit for the idle timeout
+// of the pool and returns the number of instances killed.  At least one
+// instance is kept running, instances of pools routing with the sticky or
+// config strategy are bound to tasks and are never killed.
+func (p *pool) KillIdle(reason string) int {
+	p.Lock()
+	defer p.Unlock()
+	if p.config.IdleTimeout <= 0 || p.RoutingAndCaching == nil || p.routingType() != plugin.DefaultRouting {
+		return 0
+	}
+	killed := 0
+	for id, ap := range p.plugins {
+		if len(p.plugins) <= 1 {
+			break
+		}
+		if time.Since(ap.LastHit()) < p.config.IdleTimeout {
+			continue
+		}
+		log.WithFields(log.Fields{
+			"_block": "KillIdle",
+			"reason": reason,
+		}).Debug(fmt.Sprintf("killing idle plugin '%v:%v' of pool '%v'", ap.Name(), ap.Version(), p.key))
+		if err := ap.Stop(reason); err != nil {
+			log.WithFields(log.Fields{
+				"_block": "KillIdle",
+				"reason": reason,
+			}).Error(err)
+		}
+		ap.Kill(reason)
+		delete(p.plugins, id)
+		killed++
+	}
+	return killed
+}
+
 // subscribe adds a subscription to the pool.
 // Using subscribe is idempotent.
 func (p *pool) Subscribe(taskID string) {
@@ -305,6 +434,37 @@ func (p *pool) KillAll(reason string) {
 	}
 }
 
+// Drain stops routing calls to the plugins of the pool and waits for the calls
+// in flight to finish.  It returns false when they did not finish within the
+// timeout.
+func (p *pool) Drain(timeout time.Duration) bool {
+	atomic.StoreInt32(&p.draining, 1)
+	done := make(chan struct{})
+	go func() {
+		// the calls in flight hold a read lock of the pool
+		p.Lock()
+		p.Unlock()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// Draining returns true while the pool is drained, callers check it before
+// they take the read lock of the pool so they don't queue behind the drain
+func (p *pool) Draining() bool {
+	return atomic.LoadInt32(&p.draining) == 1
+}
+
+// Resume routes calls to the plugins of a drained pool again
+func (p *pool) Resume() {
+	atomic.StoreInt32(&p.draining, 0)
+}
+
 // SelectAndKill selects, kills and removes the available plugin from the pool
 func (p *pool) SelectAndKill(id, reason string) {
 	rp, err := p.Remove(p.plugins.Values(), id)
@@ -365,11 +525,14 @@ func (p *pool) SubscriptionCount() int {
 // SelectAP selects an available plugin from the pool
 // the method is not thread safe, it should be protected outside of the body
 func (p *pool) SelectAP(taskID string, config map[string]ctypes.ConfigValue) (AvailablePlugin, serror.SnapError) {
+	if p.Draining() {
+		return nil, serror.New(ErrPoolDraining, map[string]interface{}{"pool-key": p.key})
+	}
 	aps := p.plugins.Values()
 
 	var id string
 	switch p.Strategy().String() {
-	case "least-recently-used":
+	case "least-recently-used", "least-loaded":
 		id = ""
 	case "sticky":
 		id = taskID
@@ -386,6 +549,14 @@ func (p *pool) SelectAP(taskID string, config map[string]ctypes.ConfigValue) (Av
 	return ap, nil
 }
 
+// Done ends the call dispatched to the plugin ap selected by SelectAP, for the
+// strategies counting the calls in flight of the plugins
+func (p *pool) Done(ap AvailablePlugin) {
+	if d, ok := p.Strategy().(dispatchTracker); ok {
+		d.Done(ap)
+	}
+}
+
 func idFromCfg(cfg map[string]ctypes.ConfigValue) string {
 	//TODO: check for nil map
 	var buff bytes.Buffer
@@ -216,6 +216,89 @@ func TestPoolEligibility(t *testing.T) {
 	})
 }
 
+func TestPoolConfigure(t *testing.T) {
+	Convey("Given a pool of a plugin using the default strategy", t, func() {
+		plg := NewMockAvailablePlugin().WithID(1)
+		pool, err := NewPool(plg.String(), plg)
+		So(err, ShouldBeNil)
+		pool.Subscribe("task1")
+		pool.Subscribe("task2")
+		So(pool.Eligible(), ShouldBeTrue)
+
+		Convey("When the max number of instances is configured", func() {
+			So(pool.Configure(core.PluginPoolConfig{MaxInstances: 1}), ShouldBeNil)
+			Convey("Then the pool can't grow beyond it", func() {
+				So(pool.Eligible(), ShouldBeFalse)
+				So(pool.PoolConfig().MaxInstances, ShouldEqual, 1)
+			})
+		})
+		Convey("When the routing strategy is configured", func() {
+			So(pool.Configure(core.PluginPoolConfig{Routing: core.PoolRoutingSticky}), ShouldBeNil)
+			Convey("Then the pool uses the configured strategy", func() {
+				So(pool.Strategy().String(), ShouldEqual, "sticky")
+			})
+		})
+		Convey("When an idle timeout is configured", func() {
+			pool.Insert(NewMockAvailablePlugin().WithID(2))
+			pool.Insert(NewMockAvailablePlugin().WithID(3).WithLastHit(time.Now()))
+			So(pool.Configure(core.PluginPoolConfig{IdleTimeout: time.Minute}), ShouldBeNil)
+			Convey("Then the idle instances are killed", func() {
+				So(pool.KillIdle("idle timeout"), ShouldEqual, 2)
+				So(pool.Count(), ShouldEqual, 1)
+				So(pool.Plugins(), ShouldContainKey, uint32(3))
+			})
+		})
+		Convey("When an idle timeout is configured for a pool using the sticky strategy", func() {
+			pool.Insert(NewMockAvailablePlugin().WithID(2))
+			So(pool.Configure(core.PluginPoolConfig{IdleTimeout: time.Minute, Routing: core.PoolRoutingSticky}), ShouldBeNil)
+			Convey("Then no instance is killed", func() {
+				So(pool.KillIdle("idle timeout"), ShouldEqual, 0)
+				So(pool.Count(), ShouldEqual, 2)
+			})
+		})
+	})
+}
+
+func TestPoolDrain(t *testing.T) {
+	Convey("Given a pool of a plugin", t, func() {
+		plg := NewMockAvailablePlugin().WithID(1)
+		pool, err := NewPool(plg.String(), plg)
+		So(err, ShouldBeNil)
+
+		Convey("When it is drained without calls in flight", func() {
+			So(pool.Drain(time.Second), ShouldBeTrue)
+			Convey("Then no call is routed to its plugins", func() {
+				_, serr := pool.SelectAP("task1", nil)
+				So(serr, ShouldNotBeNil)
+				So(serr.Error(), ShouldEqual, ErrPoolDraining.Error())
+			})
+			Convey("Then calls are routed again once it is resumed", func() {
+				pool.Resume()
+				_, serr := pool.SelectAP("task1", nil)
+				So(serr, ShouldBeNil)
+			})
+		})
+		Convey("When it is drained while a call is in flight", func() {
+			pool.RLock()
+			Convey("Then the drain times out", func() {
+				So(pool.Drain(10*time.Millisecond), ShouldBeFalse)
+				pool.RUnlock()
+			})
+			Convey("Then it reports it is draining while the drain waits for the call", func() {
+				So(pool.Draining(), ShouldBeFalse)
+				go pool.Drain(time.Second)
+				So(func() bool {
+					for i := 0; i < 100 && !pool.Draining(); i++ {
+						time.Sleep(time.Millisecond)
+					}
+					return pool.Draining()
+				}(), ShouldBeTrue)
+				pool.RUnlock()
+			})
+		})
+	})
+}
+
 func TestPoolSelectAPDefaultRouter(t *testing.T) {
 	Convey("For plugin defined with default strategy", t, func() {
 		plugin := NewMockAvailablePlugin().WithStrategy(plugin.DefaultRouting)
@@ -47,6 +47,12 @@ type RoutingAndCaching interface {
 	String() string
 }
 
+// dispatchTracker is implemented by the strategies counting the calls
+// dispatched to the plugins they select, which are told when each call ends.
+type dispatchTracker interface {
+	Done(ap AvailablePlugin)
+}
+
 // Values returns slice of map values
 func (sm MapAvailablePlugin) Values() []AvailablePlugin {
 	values := []AvailablePlugin{}
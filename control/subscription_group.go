@@ -25,6 +25,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"os"
+	"sort"
 	"sync"
 
 	"github.com/intelsdi-x/snap/control/plugin"
@@ -46,6 +48,10 @@ var (
 	ErrSubscriptionGroupDoesNotExist = core.ErrSubscriptionGroupDoesNotExist
 
 	ErrConfigRequiredForMetric = errors.New("config required")
+
+	// ErrMetricNotExposedByUpgrade - error message when a subscribed metric is
+	// not exposed by the new version of an upgraded plugin
+	ErrMetricNotExposedByUpgrade = errors.New("subscribed metric not exposed by the new plugin version")
 )
 
 // ManagesSubscriptionGroups is the interface implemented by an object that can
@@ -57,6 +63,8 @@ type ManagesSubscriptionGroups interface {
 		plugins []core.SubscribedPlugin) []serror.SnapError
 	Get(id string) (map[string]metricTypes, []serror.SnapError, error)
 	Remove(id string) []serror.SnapError
+	Upgrade(out, in core.Plugin) ([]string, []serror.SnapError)
+	Dependents(plugin core.Plugin) []string
 	ValidateDeps(requested []core.RequestedMetric,
 		plugins []core.SubscribedPlugin,
 		configTree *cdata.ConfigDataTree, asserts ...core.SubscribedPluginAssert) (serrs []serror.SnapError)
@@ -65,7 +73,7 @@ type ManagesSubscriptionGroups interface {
 
 type subscriptionGroup struct {
 	*pluginControl
-	// requested metrics - never updated
+	// requested metrics - only updated when the plugin exposing them is upgraded
 	requestedMetrics []core.RequestedMetric
 	// requested plugins - contains only processors and publishers;
 	// never updated
@@ -80,6 +88,9 @@ type subscriptionGroup struct {
 	// subscription groups are processed when the subscription group is added
 	// and when plugins are loaded/unloaded
 	errors []serror.SnapError
+	// versions of the collectors the requested metrics without a version are
+	// routed to - only used with the subscribed collector version routing
+	pins versionPins
 }
 
 type subscriptionMap map[string]*subscriptionGroup
@@ -152,10 +163,28 @@ func (s subscriptionGroups) remove(id string) []serror.SnapError {
 		return []serror.SnapError{serror.New(ErrSubscriptionGroupDoesNotExist)}
 	}
 	serrs := subscriptionGroup.unsubscribePlugins(id, s.subscriptionMap[id].plugins)
+	s.metricCatalog.UnsubscribeAll(id)
 	delete(s.subscriptionMap, id)
 	return serrs
 }
 
+// Dependents returns the IDs of the subscription groups which depend on the
+// given plugin.
+func (s subscriptionGroups) Dependents(plugin core.Plugin) []string {
+	s.Lock()
+	defer s.Unlock()
+	ids := []string{}
+	for id, group := range s.subscriptionMap {
+		for _, plg := range group.plugins {
+			if key(plg) == key(plugin) {
+				ids = append(ids, id)
+				break
+			}
+		}
+	}
+	return ids
+}
+
 // Get returns the metrics (core.Metric) and an array of serror.SnapError when
 // provided a subscription ID. The array of serror.SnapError returned was
 // produced the last time `process` was run which is important since
@@ -201,12 +230,94 @@ func (s *subscriptionGroups) Process() (errs []serror.SnapError) {
 	return errs
 }
 
+// Upgrade moves the subscription groups holding metrics of the collector `out`
+// to `in`, a newer version of the same plugin.  Requested metrics pinned to the
+// version of `out` are pinned to the version of `in`, the others resolve to the
+// latest version anyway (or are routed to `in` with the subscribed collector
+// version routing).  Nothing is moved unless each of the affected
+// subscription groups can be served by `in` with its current config.
+// Returns the IDs of the subscription groups which were moved.
+func (s *subscriptionGroups) Upgrade(out, in core.Plugin) ([]string, []serror.SnapError) {
+	s.Lock()
+	defer s.Unlock()
+
+	upgraded := map[string][]core.RequestedMetric{}
+	upgradedPins := map[string]versionPins{}
+	var serrs []serror.SnapError
+	for id, group := range s.subscriptionMap {
+		if _, ok := group.metrics[key(out)]; !ok {
+			continue
+		}
+		requested := s.upgradeRequestedMetrics(group.requestedMetrics, out, in)
+		pins := group.pins.upgrade(out, in)
+		if errs := s.validateUpgrade(requested, group.configTree, pins.copy(), out); errs != nil {
+			serrs = append(serrs, errs...)
+			continue
+		}
+		upgraded[id] = requested
+		upgradedPins[id] = pins
+	}
+	if serrs != nil {
+		return nil, serrs
+	}
+
+	ids := make([]string, 0, len(upgraded))
+	for id, requested := range upgraded {
+		group := s.subscriptionMap[id]
+		group.requestedMetrics = requested
+		group.pins = upgradedPins[id]
+		if errs := group.process(id); errs != nil {
+			serrs = append(serrs, errs...)
+		}
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, serrs
+}
+
+// validateUpgrade checks that none of the requested metrics would still be
+// served by the collector `out` and that the config of the metrics is valid
+// against the policy of the plugin serving them
+func (s *subscriptionGroups) validateUpgrade(requested []core.RequestedMetric,
+	configTree *cdata.ConfigDataTree, pins versionPins, out core.Plugin) (serrs []serror.SnapError) {
+	pluginToMetricMap, _, serrs := s.getMetricsAndCollectors(requested, configTree, pins)
+	if serrs != nil {
+		return serrs
+	}
+	if pmt, ok := pluginToMetricMap[key(out)]; ok {
+		for _, mt := range pmt.Metrics() {
+			serrs = append(serrs, serror.New(ErrMetricNotExposedByUpgrade, map[string]interface{}{
+				"name":    mt.Namespace().String(),
+				"version": mt.Version(),
+			}))
+		}
+		return serrs
+	}
+	for _, pmt := range pluginToMetricMap {
+		for _, m := range pmt.Metrics() {
+			mt, ok := m.(*metricType)
+			if !ok || !mt.policy.HasRules() {
+				continue
+			}
+			if _, errs := mt.policy.Process(mt.config.Table()); errs != nil && errs.HasErrors() {
+				for _, e := range errs.Errors() {
+					serrs = append(serrs, serror.New(e, map[string]interface{}{
+						"name":    mt.Namespace().String(),
+						"version": mt.Version(),
+					}))
+				}
+			}
+		}
+	}
+	return serrs
+}
+
 func (s *subscriptionGroups) ValidateDeps(requested []core.RequestedMetric,
 	plugins []core.SubscribedPlugin,
 	configTree *cdata.ConfigDataTree, asserts ...core.SubscribedPluginAssert) (serrs []serror.SnapError) {
 
 	// resolve requested metrics and map to collectors
-	pluginToMetricMap, collectors, errs := s.getMetricsAndCollectors(requested, configTree)
+	pluginToMetricMap, collectors, errs := s.getMetricsAndCollectors(requested, configTree, nil)
 	if errs != nil {
 		serrs = append(serrs, errs...)
 	}
@@ -241,9 +352,23 @@ func (s *subscriptionGroups) ValidateDeps(requested []core.RequestedMetric,
 		if err != nil {
 			return []serror.SnapError{serror.New(err)}
 		}
-		mergedConfig := plg.Config().ReverseMerge(
+		mergedConfig, err := plg.Config().ReverseMerge(
 			s.Config.Plugins.getPluginConfigDataNode(
-				typ, plg.Name(), plg.Version()))
+				typ, plg.Name(), plg.Version())).Interpolate(os.LookupEnv)
+		if err != nil {
+			serrs = append(serrs, serror.New(err, map[string]interface{}{"name": plg.Name(), "version": plg.Version()}))
+			return serrs
+		}
+		if s.builtinCollector(plg.TypeName(), plg.Name()) != nil {
+			continue
+		}
+		if bp := s.builtinProcessor(plg.TypeName(), plg.Name()); bp != nil {
+			if err := bp.validate(mergedConfig.Table()); err != nil {
+				serrs = append(serrs, serror.New(err, map[string]interface{}{"name": plg.Name(), "version": plg.Version()}))
+				return serrs
+			}
+			continue
+		}
 		errs := s.validatePluginSubscription(plg, mergedConfig)
 		if len(errs) > 0 {
 			serrs = append(serrs, errs...)
@@ -318,6 +443,19 @@ func (s *subscriptionGroups) validateMetric(
 				m.Plugin.Name(), m.Plugin.Version())
 		}
 
+		// bind the references to environment variables of the config
+		if m.config != nil {
+			config, err := m.config.Interpolate(os.LookupEnv)
+			if err != nil {
+				serrs = append(serrs, serror.New(err, map[string]interface{}{
+					"metric":  m.Namespace().String(),
+					"version": m.Version(),
+				}))
+				continue
+			}
+			m.config = config
+		}
+
 		// When a metric is added to the MetricCatalog, the policy of rules defined by the plugin is added to the metric's policy.
 		// If no rules are defined for a metric, we set the metric's policy to an empty ConfigPolicyNode.
 		// Checking m.policy for nil will not work, we need to check if rules are nil.
@@ -347,13 +485,30 @@ func (s *subscriptionGroups) validateMetric(
 
 func (s *subscriptionGroup) process(id string) (serrs []serror.SnapError) {
 	// gathers collectors based on requested metrics
-	pluginToMetricMap, plugins, serrs := s.getMetricsAndCollectors(s.requestedMetrics, s.configTree)
+	var pins versionPins
+	if s.Config != nil && s.Config.CollectorVersionRouting == CollectorVersionRoutingSubscribed {
+		pins = s.pins.copy()
+	}
+	pluginToMetricMap, plugins, serrs := s.getMetricsAndCollectors(s.requestedMetrics, s.configTree, pins)
 	controlLogger.WithFields(log.Fields{
 		"collectors": fmt.Sprintf("%+v", plugins),
 		"metrics":    fmt.Sprintf("%+v", s.requestedMetrics),
 	}).Debug("gathered collectors")
 
+	// the built-in collectors aren't run by plugins
+	collectors := plugins[:0]
+	for _, plugin := range plugins {
+		if s.builtinCollector(plugin.TypeName(), plugin.Name()) == nil {
+			collectors = append(collectors, plugin)
+		}
+	}
+	plugins = collectors
+
 	for _, plugin := range s.requestedPlugins {
+		// the built-in processors aren't run by plugins
+		if s.builtinProcessor(plugin.TypeName(), plugin.Name()) != nil {
+			continue
+		}
 		//add processors and publishers to collectors just gathered
 		if plugin.TypeName() != core.CollectorPluginType.String() {
 			plugins = append(plugins, plugin)
@@ -387,15 +542,40 @@ func (s *subscriptionGroup) process(id string) (serrs []serror.SnapError) {
 		}
 	}
 
+	// warns about deprecated metrics which were not subscribed before
+	for _, mt := range newlyDeprecatedMetrics(pluginToMetricMap, s.metrics) {
+		if serr := s.sendDeprecatedMetricSubscriptionEvent(id, mt); serr != nil {
+			serrs = append(serrs, serr)
+		}
+	}
+
+	s.subscribeMetrics(id, pluginToMetricMap)
+
 	//updating view
 	// metrics are grouped by plugin
 	s.metrics = pluginToMetricMap
 	s.plugins = plugins
 	s.errors = serrs
+	s.pins = pins
 
 	return serrs
 }
 
+// subscribeMetrics records in the metric catalog that the metrics are held by
+// the subscription group, replacing the metrics it held before at once
+func (s *subscriptionGroup) subscribeMetrics(id string, metrics map[string]metricTypes) {
+	var mts []core.Metric
+	for _, pmt := range metrics {
+		mts = append(mts, pmt.Metrics()...)
+	}
+	for _, err := range s.metricCatalog.Resubscribe(id, mts) {
+		controlLogger.WithFields(log.Fields{
+			"_block": "subscriptionGroup.subscribeMetrics",
+			"error":  err,
+		}).Warn("unable to subscribe metric")
+	}
+}
+
 func (s *subscriptionGroup) subscribePlugins(id string,
 	plugins []core.SubscribedPlugin) (serrs []serror.SnapError) {
 	plgs := make([]*loadedPlugin, len(plugins))
@@ -547,6 +727,52 @@ func (p *subscriptionGroup) sendPluginUnsubscriptionEvent(taskID string,
 	return nil
 }
 
+func (p *subscriptionGroup) sendDeprecatedMetricSubscriptionEvent(taskID string,
+	mt *metricType) serror.SnapError {
+	controlLogger.WithFields(log.Fields{
+		"_block":    "subscriptionGroup.sendDeprecatedMetricSubscriptionEvent",
+		"task-id":   taskID,
+		"namespace": mt.Namespace().String(),
+		"version":   mt.Version(),
+		"message":   mt.DeprecationMessage(),
+	}).Warn("task subscribed to a deprecated metric")
+	e := &control_event.DeprecatedMetricSubscriptionEvent{
+		TaskId:          taskID,
+		MetricNamespace: mt.Namespace().String(),
+		MetricVersion:   mt.Version(),
+		Message:         mt.DeprecationMessage(),
+	}
+	if _, err := p.eventManager.Emit(e); err != nil {
+		return serror.New(err)
+	}
+	return nil
+}
+
+// newlyDeprecatedMetrics returns the deprecated metrics from the new state of
+// metrics which were not present in the previous state.
+func newlyDeprecatedMetrics(newMetrics,
+	oldMetrics map[string]metricTypes) []*metricType {
+	old := map[string]struct{}{}
+	for _, pmt := range oldMetrics {
+		for _, m := range pmt.Metrics() {
+			old[fmt.Sprintf("%s:%d", m.Namespace().String(), m.Version())] = struct{}{}
+		}
+	}
+	var deprecated []*metricType
+	for _, pmt := range newMetrics {
+		for _, m := range pmt.Metrics() {
+			mt, ok := m.(*metricType)
+			if !ok || !mt.Deprecated() {
+				continue
+			}
+			if _, ok := old[fmt.Sprintf("%s:%d", mt.Namespace().String(), mt.Version())]; !ok {
+				deprecated = append(deprecated, mt)
+			}
+		}
+	}
+	return deprecated
+}
+
 // comparePlugins compares the new state of plugins with the previous state.
 // It returns an array of plugins that need to be subscribed and an array of
 // plugins that need to be unsubscribed.
@@ -592,6 +818,35 @@ func pluginNotFoundError(pl core.SubscribedPlugin) serror.SnapError {
 	return se
 }
 
-func key(p core.SubscribedPlugin) string {
+// upgradeRequestedMetrics returns the requested metrics with the ones pinned to
+// the version of the collector `out` pinned to the version of `in` instead.
+// Requested metrics pinned to the same version of another plugin are kept.
+func (s *subscriptionGroups) upgradeRequestedMetrics(requested []core.RequestedMetric, out, in core.Plugin) []core.RequestedMetric {
+	upgraded := make([]core.RequestedMetric, len(requested))
+	for i, r := range requested {
+		upgraded[i] = r
+		if r.Version() == out.Version() && s.servedBy(r, out) {
+			upgraded[i] = &metric{namespace: r.Namespace(), version: in.Version()}
+		}
+	}
+	return upgraded
+}
+
+// servedBy returns true if the requested metric resolves to metrics exposed by
+// the plugin pl
+func (s *subscriptionGroups) servedBy(r core.RequestedMetric, pl core.Plugin) bool {
+	mts, err := s.metricCatalog.GetMetrics(r.Namespace(), r.Version())
+	if err != nil {
+		return false
+	}
+	for _, mt := range mts {
+		if mt.Plugin.TypeName() == pl.TypeName() && mt.Plugin.Name() == pl.Name() && mt.Plugin.Version() == pl.Version() {
+			return true
+		}
+	}
+	return false
+}
+
+func key(p core.Plugin) string {
 	return fmt.Sprintf("%v"+core.Separator+"%v"+core.Separator+"%v", p.TypeName(), p.Name(), p.Version())
 }
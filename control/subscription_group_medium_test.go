@@ -887,6 +887,79 @@ func TestSubscriptionGroups_GetSpecifiedDynamic(t *testing.T) {
 	})
 }
 
+func TestSubscriptionGroups_Upgrade(t *testing.T) {
+	c := New(getTestSGConfig())
+
+	lpe := newLstnToPluginEvents()
+	c.eventManager.RegisterHandler("TestSubscriptionGroups_Upgrade", lpe)
+	c.Start()
+
+	Convey("Loading a mock collector plugin", t, func() {
+		_, err := loadPlg(c, helper.PluginFilePath("snap-plugin-collector-mock1"))
+		So(err, ShouldBeNil)
+		<-lpe.load
+
+		Convey("Subscription group created for requested metric pinned to version 1", func() {
+			requested := mockRequestedMetric{namespace: core.NewNamespace("intel", "mock", "foo"), version: 1}
+			mock1 := mockSubscribedPlugin{
+				typeName: core.CollectorPluginType,
+				name:     "mock",
+				version:  1,
+				config:   cdata.NewNode(),
+			}
+			mock2 := mockSubscribedPlugin{
+				typeName: core.CollectorPluginType,
+				name:     "mock",
+				version:  2,
+				config:   cdata.NewNode(),
+			}
+
+			sg := newSubscriptionGroups(c)
+			So(sg, ShouldNotBeNil)
+			sg.Add("task-id", []core.RequestedMetric{requested}, cdata.NewTree(), []core.SubscribedPlugin{mock1})
+			<-lpe.sub
+
+			Convey("loading another mock does not move the pinned subscription", func() {
+				_, err := loadPlg(c, helper.PluginFilePath("snap-plugin-collector-mock2"))
+				So(err, ShouldBeNil)
+				<-lpe.load
+				serrs := sg.Process()
+				So(len(serrs), ShouldEqual, 0)
+				group := sg.subscriptionMap["task-id"]
+				So(subscribedPluginsContain(group.plugins, mock1), ShouldBeTrue)
+
+				Convey("upgrading the mock to a version which is not loaded moves nothing", func() {
+					mock3 := mockSubscribedPlugin{
+						typeName: core.CollectorPluginType,
+						name:     "mock",
+						version:  3,
+						config:   cdata.NewNode(),
+					}
+					ids, serrs := sg.Upgrade(mock1, mock3)
+					So(serrs, ShouldNotBeEmpty)
+					So(ids, ShouldBeEmpty)
+					group := sg.subscriptionMap["task-id"]
+					So(subscribedPluginsContain(group.plugins, mock1), ShouldBeTrue)
+					So(group.requestedMetrics[0].Version(), ShouldEqual, 1)
+
+					Convey("upgrading the mock moves the subscription to the new version", func() {
+						ids, serrs := sg.Upgrade(mock1, mock2)
+						So(serrs, ShouldBeEmpty)
+						So(ids, ShouldResemble, []string{"task-id"})
+						group := sg.subscriptionMap["task-id"]
+						So(subscribedPluginsContain(group.plugins, mock1), ShouldBeFalse)
+						So(subscribedPluginsContain(group.plugins, mock2), ShouldBeTrue)
+						So(group.metrics, ShouldContainKey, key(mock2))
+						So(group.metrics, ShouldNotContainKey, key(mock1))
+						So(group.requestedMetrics[0].Version(), ShouldEqual, 2)
+						So(group.requestedMetrics[0].Namespace(), ShouldResemble, requested.Namespace())
+					})
+				})
+			})
+		})
+	})
+}
+
 type lstnToPluginEvents struct {
 	load    chan struct{}
 	sub     chan struct{}
@@ -0,0 +1,107 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"github.com/intelsdi-x/snap/core"
+)
+
+// The routing of the requested metrics without a version to the versions of
+// their collectors
+const (
+	// CollectorVersionRoutingLatest routes the metrics to the latest loaded
+	// version of their collector, moving them when a newer version is loaded
+	CollectorVersionRoutingLatest = "latest"
+	// CollectorVersionRoutingSubscribed keeps routing the metrics to the
+	// version of their collector they were subscribed to, a newer version is
+	// only used by the tasks subscribing to it until the tasks are upgraded
+	CollectorVersionRoutingSubscribed = "subscribed"
+)
+
+// versionPins maps the names of the collectors to the versions the requested
+// metrics without a version are routed to
+type versionPins map[string]int
+
+func (v versionPins) copy() versionPins {
+	pins := versionPins{}
+	for name, version := range v {
+		pins[name] = version
+	}
+	return pins
+}
+
+// upgrade returns the pins with the collector `out` pinned to `in` instead
+func (v versionPins) upgrade(out, in core.Plugin) versionPins {
+	if v == nil {
+		return nil
+	}
+	pins := v.copy()
+	if pins[out.Name()] == out.Version() {
+		pins[in.Name()] = in.Version()
+	}
+	return pins
+}
+
+// resolveRequestedMetric returns the metric types matching the requested
+// metric.  The metric types of a collector pinned to another version than the
+// latest one are taken in the pinned version, as long as it's loaded.  The
+// versions the metric types are resolved to are recorded in pins.
+func (p *pluginControl) resolveRequestedMetric(r core.RequestedMetric, pins versionPins) ([]*metricType, error) {
+	mts, err := p.metricCatalog.GetMetrics(r.Namespace(), r.Version())
+	if err != nil || pins == nil || r.Version() > 0 {
+		return mts, err
+	}
+	resolved := make([]*metricType, 0, len(mts))
+	pinned := map[string][]*metricType{}
+	names := []string{}
+	for _, mt := range mts {
+		name := mt.Plugin.Name()
+		version, ok := pins[name]
+		if !ok || version == mt.Version() {
+			resolved = append(resolved, mt)
+			continue
+		}
+		if _, ok := pinned[name]; ok {
+			continue
+		}
+		names = append(names, name)
+		pinned[name] = []*metricType{}
+		if pmts, err := p.metricCatalog.GetMetrics(r.Namespace(), version); err == nil {
+			for _, pmt := range pmts {
+				if pmt.Plugin.Name() == name {
+					pinned[name] = append(pinned[name], pmt)
+				}
+			}
+		}
+	}
+	for _, mt := range mts {
+		// the pinned version isn't loaded anymore, fall back to the latest one
+		if pmts, ok := pinned[mt.Plugin.Name()]; ok && len(pmts) == 0 {
+			resolved = append(resolved, mt)
+		}
+	}
+	for _, name := range names {
+		resolved = append(resolved, pinned[name]...)
+	}
+	for _, mt := range resolved {
+		pins[mt.Plugin.Name()] = mt.Version()
+	}
+	return resolved, nil
+}
@@ -0,0 +1,144 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func addVersionRoutingMetrics(mc *metricCatalog, name string, version int) *loadedPlugin {
+	lp := &loadedPlugin{
+		Meta:         plugin.PluginMeta{Name: name, Version: version, Type: plugin.CollectorPluginType},
+		ConfigPolicy: cpolicy.New(),
+	}
+	for _, n := range []string{"foo", "bar"} {
+		mc.Add(newMetricType(core.NewNamespace("intel", name, n), time.Now(), lp))
+	}
+	return lp
+}
+
+func TestResolveRequestedMetric(t *testing.T) {
+	Convey("pluginControl.resolveRequestedMetric()", t, func() {
+		mc := newMetricCatalog()
+		c := &pluginControl{metricCatalog: mc}
+		addVersionRoutingMetrics(mc, "mock", 1)
+		requested := &metric{namespace: core.NewNamespace("intel", "mock", "*"), version: -1}
+
+		Convey("resolves to the latest version without pins", func() {
+			addVersionRoutingMetrics(mc, "mock", 2)
+			mts, err := c.resolveRequestedMetric(requested, nil)
+			So(err, ShouldBeNil)
+			So(mts, ShouldHaveLength, 2)
+			for _, mt := range mts {
+				So(mt.Version(), ShouldEqual, 2)
+			}
+		})
+
+		Convey("records the version the metrics are resolved to", func() {
+			pins := versionPins{}
+			mts, err := c.resolveRequestedMetric(requested, pins)
+			So(err, ShouldBeNil)
+			So(mts, ShouldHaveLength, 2)
+			So(pins, ShouldResemble, versionPins{"mock": 1})
+		})
+
+		Convey("keeps routing to the pinned version once a newer one is loaded", func() {
+			pins := versionPins{"mock": 1}
+			addVersionRoutingMetrics(mc, "mock", 2)
+			mts, err := c.resolveRequestedMetric(requested, pins)
+			So(err, ShouldBeNil)
+			So(mts, ShouldHaveLength, 2)
+			for _, mt := range mts {
+				So(mt.Version(), ShouldEqual, 1)
+			}
+			So(pins, ShouldResemble, versionPins{"mock": 1})
+
+			Convey("unless a version is requested", func() {
+				mts, err := c.resolveRequestedMetric(&metric{namespace: requested.Namespace(), version: 2}, pins)
+				So(err, ShouldBeNil)
+				So(mts, ShouldHaveLength, 2)
+				for _, mt := range mts {
+					So(mt.Version(), ShouldEqual, 2)
+				}
+			})
+		})
+
+		Convey("falls back to the latest version when the pinned one is not loaded", func() {
+			pins := versionPins{"mock": 3}
+			mts, err := c.resolveRequestedMetric(requested, pins)
+			So(err, ShouldBeNil)
+			So(mts, ShouldHaveLength, 2)
+			So(pins, ShouldResemble, versionPins{"mock": 1})
+		})
+	})
+}
+
+func TestVersionPinsUpgrade(t *testing.T) {
+	Convey("versionPins.upgrade()", t, func() {
+		v1 := &loadedPlugin{Meta: plugin.PluginMeta{Name: "mock", Version: 1}}
+		v2 := &loadedPlugin{Meta: plugin.PluginMeta{Name: "mock", Version: 2}}
+		Convey("moves the pins of the upgraded version", func() {
+			pins := versionPins{"mock": 1, "other": 4}
+			So(pins.upgrade(v1, v2), ShouldResemble, versionPins{"mock": 2, "other": 4})
+			So(pins, ShouldResemble, versionPins{"mock": 1, "other": 4})
+		})
+		Convey("keeps the pins of other versions", func() {
+			pins := versionPins{"mock": 2}
+			So(pins.upgrade(v1, v2), ShouldResemble, versionPins{"mock": 2})
+		})
+		Convey("returns no pins without pins", func() {
+			var pins versionPins
+			So(pins.upgrade(v1, v2), ShouldBeNil)
+		})
+	})
+}
+
+func TestUpgradeRequestedMetrics(t *testing.T) {
+	Convey("subscriptionGroups.upgradeRequestedMetrics()", t, func() {
+		mc := newMetricCatalog()
+		s := &subscriptionGroups{pluginControl: &pluginControl{metricCatalog: mc}}
+		out := addVersionRoutingMetrics(mc, "mock", 1)
+		in := addVersionRoutingMetrics(mc, "mock", 2)
+		addVersionRoutingMetrics(mc, "anothermock", 1)
+		requested := []core.RequestedMetric{
+			&metric{namespace: core.NewNamespace("intel", "mock", "foo"), version: 1},
+			&metric{namespace: core.NewNamespace("intel", "anothermock", "foo"), version: 1},
+			&metric{namespace: core.NewNamespace("intel", "mock", "bar"), version: -1},
+		}
+
+		Convey("only pins the metrics served by the upgraded plugin to the new version", func() {
+			upgraded := s.upgradeRequestedMetrics(requested, out, in)
+			So(upgraded, ShouldHaveLength, 3)
+			So(upgraded[0].Namespace(), ShouldResemble, requested[0].Namespace())
+			So(upgraded[0].Version(), ShouldEqual, 2)
+			So(upgraded[1], ShouldEqual, requested[1])
+			So(upgraded[2], ShouldEqual, requested[2])
+		})
+	})
+}
@@ -0,0 +1,106 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cdata
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/intelsdi-x/snap/core/ctypes"
+)
+
+// LookupFunc returns the value of a variable and whether it is set, e.g.
+// os.LookupEnv
+type LookupFunc func(string) (string, bool)
+
+// Interpolate returns a copy of the ConfigDataNode with the references to
+// variables of its string values replaced, see InterpolateString. The node
+// is returned as it is when it has no references.
+func (c *ConfigDataNode) Interpolate(lookup LookupFunc) (*ConfigDataNode, error) {
+	table, ok, err := InterpolateTable(c.Table(), lookup)
+	if err != nil || !ok {
+		return c, err
+	}
+	return FromTable(table), nil
+}
+
+// InterpolateTable returns a copy of the table with the references to
+// variables of its string values replaced and true, or the table and false
+// when it has none.
+func InterpolateTable(table map[string]ctypes.ConfigValue, lookup LookupFunc) (map[string]ctypes.ConfigValue, bool, error) {
+	var interpolated map[string]ctypes.ConfigValue
+	for k, v := range table {
+		s, ok := v.(ctypes.ConfigValueStr)
+		if !ok || !strings.Contains(s.Value, "${") {
+			continue
+		}
+		value, err := InterpolateString(s.Value, lookup)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: %v", k, err)
+		}
+		if interpolated == nil {
+			interpolated = make(map[string]ctypes.ConfigValue, len(table))
+			for k, v := range table {
+				interpolated[k] = v
+			}
+		}
+		interpolated[k] = ctypes.ConfigValueStr{Value: value}
+	}
+	if interpolated == nil {
+		return table, false, nil
+	}
+	return interpolated, true, nil
+}
+
+// InterpolateString replaces the references to variables of the string,
+// ${VAR} by the value of VAR and ${VAR:-default} by the value of VAR or by
+// default when VAR is unset or empty. A variable unset without default is an
+// error, a $ not followed by { is kept as it is.
+func InterpolateString(s string, lookup LookupFunc) (string, error) {
+	var b []byte
+	for {
+		i := strings.Index(s, "${")
+		if i < 0 {
+			break
+		}
+		end := strings.IndexByte(s[i:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated variable reference in %q", s)
+		}
+		ref := s[i+2 : i+end]
+		name, def, hasDefault := ref, "", false
+		if j := strings.Index(ref, ":-"); j >= 0 {
+			name, def, hasDefault = ref[:j], ref[j+2:], true
+		}
+		if name == "" {
+			return "", fmt.Errorf("empty variable reference in %q", s)
+		}
+		value, ok := lookup(name)
+		if !ok || value == "" {
+			if !hasDefault && !ok {
+				return "", fmt.Errorf("variable %s is not set", name)
+			}
+			value = def
+		}
+		b = append(append(b, s[:i]...), value...)
+		s = s[i+end+1:]
+	}
+	return string(append(b, s...)), nil
+}
@@ -0,0 +1,102 @@
+// +build legacy
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cdata
+
+import (
+	"testing"
+
+	"github.com/intelsdi-x/snap/core/ctypes"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestInterpolate(t *testing.T) {
+	env := map[string]string{"HOST": "db.example.com", "PORT": "5432", "EMPTY": ""}
+	lookup := func(k string) (string, bool) {
+		v, ok := env[k]
+		return v, ok
+	}
+
+	Convey("InterpolateString", t, func() {
+		Convey("replaces the variables", func() {
+			s, err := InterpolateString("${HOST}:${PORT}", lookup)
+			So(err, ShouldBeNil)
+			So(s, ShouldEqual, "db.example.com:5432")
+		})
+		Convey("uses the default of a variable unset or empty", func() {
+			s, err := InterpolateString("${USER:-snap}/${EMPTY:-none}/${HOST:-localhost}", lookup)
+			So(err, ShouldBeNil)
+			So(s, ShouldEqual, "snap/none/db.example.com")
+			s, err = InterpolateString("[${USER:-}]", lookup)
+			So(err, ShouldBeNil)
+			So(s, ShouldEqual, "[]")
+		})
+		Convey("keeps the values without references", func() {
+			s, err := InterpolateString("pa$$word $HOST", lookup)
+			So(err, ShouldBeNil)
+			So(s, ShouldEqual, "pa$$word $HOST")
+		})
+		Convey("keeps a variable set but empty", func() {
+			s, err := InterpolateString("[${EMPTY}]", lookup)
+			So(err, ShouldBeNil)
+			So(s, ShouldEqual, "[]")
+		})
+		Convey("fails on an unset variable without default", func() {
+			_, err := InterpolateString("${USER}", lookup)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "variable USER is not set")
+		})
+		Convey("fails on an invalid reference", func() {
+			_, err := InterpolateString("${HOST", lookup)
+			So(err, ShouldNotBeNil)
+			_, err = InterpolateString("${:-x}", lookup)
+			So(err, ShouldNotBeNil)
+		})
+	})
+
+	Convey("ConfigDataNode.Interpolate", t, func() {
+		n := NewNode()
+		n.AddItem("host", ctypes.ConfigValueStr{Value: "${HOST}"})
+		n.AddItem("user", ctypes.ConfigValueStr{Value: "snap"})
+		n.AddItem("port", ctypes.ConfigValueInt{Value: 5432})
+
+		Convey("returns a copy with the variables replaced", func() {
+			i, err := n.Interpolate(lookup)
+			So(err, ShouldBeNil)
+			So(i.Table()["host"], ShouldResemble, ctypes.ConfigValueStr{Value: "db.example.com"})
+			So(i.Table()["user"], ShouldResemble, ctypes.ConfigValueStr{Value: "snap"})
+			So(i.Table()["port"], ShouldResemble, ctypes.ConfigValueInt{Value: 5432})
+			So(n.Table()["host"], ShouldResemble, ctypes.ConfigValueStr{Value: "${HOST}"})
+		})
+		Convey("returns the node without references", func() {
+			n.DeleteItem("host")
+			i, err := n.Interpolate(lookup)
+			So(err, ShouldBeNil)
+			So(i, ShouldEqual, n)
+		})
+		Convey("reports the key of an unset variable", func() {
+			n.AddItem("password", ctypes.ConfigValueStr{Value: "${PASSWORD}"})
+			_, err := n.Interpolate(lookup)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "password: variable PASSWORD is not set")
+		})
+	})
+}
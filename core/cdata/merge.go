@@ -0,0 +1,180 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cdata
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/intelsdi-x/snap/core/ctypes"
+)
+
+// Precedence decides which side of a merge wins when both sides hold a value
+// for the same key.
+type Precedence int
+
+const (
+	// PrecedenceOther lets the values of the merged in config override the
+	// values already present (the behavior of Merge).
+	PrecedenceOther Precedence = iota
+	// PrecedenceSelf keeps the values already present and only adds the
+	// missing keys of the merged in config (the behavior of ReverseMerge).
+	PrecedenceSelf
+)
+
+// ErrRootMismatch is returned when merging trees with different root namespaces.
+var ErrRootMismatch = errors.New("config trees have different root namespaces")
+
+// Override describes a key whose value was decided by the precedence of a merge.
+type Override struct {
+	// Namespace of the node holding the key (empty when merging nodes).
+	Namespace []string `json:"namespace,omitempty"`
+	Key       string   `json:"key"`
+	// Value is the value kept by the merge.
+	Value ctypes.ConfigValue `json:"value"`
+	// Overridden is the conflicting value that was discarded.
+	Overridden ctypes.ConfigValue `json:"overridden"`
+}
+
+func (o Override) String() string {
+	key := o.Key
+	if len(o.Namespace) > 0 {
+		key = "/" + strings.Join(o.Namespace, "/") + ":" + o.Key
+	}
+	return fmt.Sprintf("%s: %v overrides %v", key, o.Value, o.Overridden)
+}
+
+// MergeReport lists the conflicting keys of a merge.
+type MergeReport struct {
+	Overrides []Override `json:"overrides"`
+}
+
+// HasOverrides returns true if any key had conflicting values.
+func (r *MergeReport) HasOverrides() bool {
+	return len(r.Overrides) > 0
+}
+
+func (r *MergeReport) sort() {
+	sort.Sort(byNamespaceAndKey(r.Overrides))
+}
+
+type byNamespaceAndKey []Override
+
+func (b byNamespaceAndKey) Len() int      { return len(b) }
+func (b byNamespaceAndKey) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b byNamespaceAndKey) Less(i, j int) bool {
+	ni, nj := strings.Join(b[i].Namespace, "/"), strings.Join(b[j].Namespace, "/")
+	if ni != nj {
+		return ni < nj
+	}
+	return b[i].Key < b[j].Key
+}
+
+// MergeWithReport merges n with a copy of this ConfigDataNode using the given
+// precedence and returns the copy along with the keys holding conflicting
+// values. Neither node is modified.
+func (c *ConfigDataNode) MergeWithReport(n *ConfigDataNode, p Precedence) (*ConfigDataNode, *MergeReport) {
+	report := &MergeReport{}
+	merged := c.mergeWithReport(nil, n, p, report)
+	report.sort()
+	return merged, report
+}
+
+func (c *ConfigDataNode) mergeWithReport(ns []string, n *ConfigDataNode, p Precedence, report *MergeReport) *ConfigDataNode {
+	merged := c.clone()
+	for k, v := range n.Table() {
+		old, ok := merged.table[k]
+		if !ok {
+			merged.table[k] = v
+			continue
+		}
+		if old == v {
+			continue
+		}
+		o := Override{Namespace: ns, Key: k, Value: v, Overridden: old}
+		if p == PrecedenceSelf {
+			o.Value, o.Overridden = old, v
+		} else {
+			merged.table[k] = v
+		}
+		report.Overrides = append(report.Overrides, o)
+	}
+	return merged
+}
+
+func (c *ConfigDataNode) clone() *ConfigDataNode {
+	n := NewNode()
+	for k, v := range c.Table() {
+		n.table[k] = v
+	}
+	return n
+}
+
+// MergeWithReport deep merges o with a copy of this ConfigDataTree using the
+// given precedence. The nodes added at the same namespace in both trees are
+// merged key by key and the conflicting keys are returned in the report.
+// Neither tree is modified.
+func (c *ConfigDataTree) MergeWithReport(o *ConfigDataTree, p Precedence) (*ConfigDataTree, *MergeReport, error) {
+	mine, other := c.cTree.GetAll(), o.cTree.GetAll()
+	if len(mine) > 0 && len(other) > 0 && mine[0].Key[0] != other[0].Key[0] {
+		return nil, nil, ErrRootMismatch
+	}
+
+	nodes := map[string]*ConfigDataNode{}
+	namespaces := map[string][]string{}
+	var order []string
+	for _, kn := range mine {
+		k := strings.Join(kn.Key, "/")
+		nodes[k] = asConfigDataNode(kn.Node).clone()
+		namespaces[k] = kn.Key
+		order = append(order, k)
+	}
+
+	report := &MergeReport{}
+	for _, kn := range other {
+		k := strings.Join(kn.Key, "/")
+		n := asConfigDataNode(kn.Node)
+		if existing, ok := nodes[k]; ok {
+			nodes[k] = existing.mergeWithReport(kn.Key, n, p, report)
+			continue
+		}
+		nodes[k] = n.clone()
+		namespaces[k] = kn.Key
+		order = append(order, k)
+	}
+	report.sort()
+
+	merged := NewTree()
+	for _, k := range order {
+		merged.Add(namespaces[k], nodes[k])
+	}
+	return merged, report, nil
+}
+
+func asConfigDataNode(n interface{}) *ConfigDataNode {
+	switch t := n.(type) {
+	case ConfigDataNode:
+		return &t
+	default:
+		return t.(*ConfigDataNode)
+	}
+}
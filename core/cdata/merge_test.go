@@ -0,0 +1,145 @@
+// +build legacy
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cdata
+
+import (
+	"testing"
+
+	"github.com/intelsdi-x/snap/core/ctypes"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestConfigDataNodeMergeWithReport(t *testing.T) {
+	Convey("ConfigDataNode.MergeWithReport", t, func() {
+		a := NewNode()
+		a.AddItem("user", ctypes.ConfigValueStr{Value: "root"})
+		a.AddItem("port", ctypes.ConfigValueInt{Value: 80})
+		a.AddItem("debug", ctypes.ConfigValueBool{Value: true})
+		b := NewNode()
+		b.AddItem("port", ctypes.ConfigValueInt{Value: 8080})
+		b.AddItem("debug", ctypes.ConfigValueBool{Value: true})
+		b.AddItem("timeout", ctypes.ConfigValueFloat{Value: 1.5})
+
+		Convey("the other node takes precedence", func() {
+			m, r := a.MergeWithReport(b, PrecedenceOther)
+			So(m.Table()["port"], ShouldResemble, ctypes.ConfigValueInt{Value: 8080})
+			So(m.Table()["user"], ShouldResemble, ctypes.ConfigValueStr{Value: "root"})
+			So(m.Table()["timeout"], ShouldResemble, ctypes.ConfigValueFloat{Value: 1.5})
+			So(r.HasOverrides(), ShouldBeTrue)
+			So(r.Overrides, ShouldResemble, []Override{
+				{Key: "port", Value: ctypes.ConfigValueInt{Value: 8080}, Overridden: ctypes.ConfigValueInt{Value: 80}},
+			})
+			So(r.Overrides[0].String(), ShouldEqual, "port: {8080} overrides {80}")
+		})
+
+		Convey("this node takes precedence", func() {
+			m, r := a.MergeWithReport(b, PrecedenceSelf)
+			So(m.Table()["port"], ShouldResemble, ctypes.ConfigValueInt{Value: 80})
+			So(m.Table()["timeout"], ShouldResemble, ctypes.ConfigValueFloat{Value: 1.5})
+			So(r.Overrides, ShouldResemble, []Override{
+				{Key: "port", Value: ctypes.ConfigValueInt{Value: 80}, Overridden: ctypes.ConfigValueInt{Value: 8080}},
+			})
+		})
+
+		Convey("neither node is modified", func() {
+			a.MergeWithReport(b, PrecedenceOther)
+			So(len(a.Table()), ShouldEqual, 3)
+			So(a.Table()["port"], ShouldResemble, ctypes.ConfigValueInt{Value: 80})
+			So(len(b.Table()), ShouldEqual, 3)
+		})
+
+		Convey("equal values are not reported", func() {
+			_, r := a.MergeWithReport(a, PrecedenceOther)
+			So(r.HasOverrides(), ShouldBeFalse)
+		})
+	})
+}
+
+func TestConfigDataTreeMergeWithReport(t *testing.T) {
+	Convey("ConfigDataTree.MergeWithReport", t, func() {
+		global := NewNode()
+		global.AddItem("user", ctypes.ConfigValueStr{Value: "root"})
+		global.AddItem("port", ctypes.ConfigValueInt{Value: 80})
+		plugin := NewNode()
+		plugin.AddItem("port", ctypes.ConfigValueInt{Value: 81})
+		a := NewTree()
+		a.Add([]string{"intel"}, global)
+		a.Add([]string{"intel", "mock", "foo"}, plugin)
+
+		task := NewNode()
+		task.AddItem("port", ctypes.ConfigValueInt{Value: 8080})
+		task.AddItem("user", ctypes.ConfigValueStr{Value: "snap"})
+		metric := NewNode()
+		metric.AddItem("password", ctypes.ConfigValueStr{Value: "secret"})
+		b := NewTree()
+		b.Add([]string{"intel", "mock", "foo"}, task)
+		b.Add([]string{"intel", "mock", "bar"}, metric)
+
+		Convey("merges the nodes at the same namespace", func() {
+			m, r, err := a.MergeWithReport(b, PrecedenceOther)
+			So(err, ShouldBeNil)
+			bar := m.Get([]string{"intel", "mock", "bar"}).Table()
+			So(bar["password"], ShouldResemble, ctypes.ConfigValueStr{Value: "secret"})
+			So(bar["port"], ShouldResemble, ctypes.ConfigValueInt{Value: 80})
+			foo := m.Get([]string{"intel", "mock", "foo"}).Table()
+			So(foo["port"], ShouldResemble, ctypes.ConfigValueInt{Value: 8080})
+			So(foo["user"], ShouldResemble, ctypes.ConfigValueStr{Value: "snap"})
+			So(r.Overrides, ShouldResemble, []Override{
+				{
+					Namespace:  []string{"intel", "mock", "foo"},
+					Key:        "port",
+					Value:      ctypes.ConfigValueInt{Value: 8080},
+					Overridden: ctypes.ConfigValueInt{Value: 81},
+				},
+			})
+			So(r.Overrides[0].String(), ShouldEqual, "/intel/mock/foo:port: {8080} overrides {81}")
+		})
+
+		Convey("keeps its own values", func() {
+			m, r, err := a.MergeWithReport(b, PrecedenceSelf)
+			So(err, ShouldBeNil)
+			So(m.Get([]string{"intel", "mock", "foo"}).Table()["port"], ShouldResemble, ctypes.ConfigValueInt{Value: 81})
+			So(len(r.Overrides), ShouldEqual, 1)
+			So(r.Overrides[0].Value, ShouldResemble, ctypes.ConfigValueInt{Value: 81})
+		})
+
+		Convey("does not modify the trees", func() {
+			a.MergeWithReport(b, PrecedenceOther)
+			So(a.Get([]string{"intel", "mock", "foo"}).Table()["port"], ShouldResemble, ctypes.ConfigValueInt{Value: 81})
+			So(len(plugin.Table()), ShouldEqual, 1)
+		})
+
+		Convey("merges with an empty tree", func() {
+			m, r, err := NewTree().MergeWithReport(b, PrecedenceOther)
+			So(err, ShouldBeNil)
+			So(r.HasOverrides(), ShouldBeFalse)
+			So(m.Get([]string{"intel", "mock", "foo"}).Table()["port"], ShouldResemble, ctypes.ConfigValueInt{Value: 8080})
+		})
+
+		Convey("fails on different roots", func() {
+			c := NewTree()
+			c.Add([]string{"acme"}, NewNode())
+			_, _, err := a.MergeWithReport(c, PrecedenceOther)
+			So(err, ShouldEqual, ErrRootMismatch)
+		})
+	})
+}
@@ -65,11 +65,5 @@ func (c *ConfigDataTree) Get(ns []string) *ConfigDataNode {
 	if n == nil {
 		return nil
 	}
-	switch t := n.(type) {
-	case ConfigDataNode:
-		return &t
-	default:
-		return t.(*ConfigDataNode)
-
-	}
+	return asConfigDataNode(n)
 }
@@ -20,21 +20,31 @@ limitations under the License.
 package control_event
 
 const (
-	AvailablePluginDead      = "Control.AvailablePluginDead"
-	AvailablePluginRestarted = "Control.RestartedAvailablePlugin"
-	PluginRestartsExceeded   = "Control.PluginRestartsExceeded"
-	PluginStarted            = "Control.PluginStarted"
-	PluginLoaded             = "Control.PluginLoaded"
-	PluginUnloaded           = "Control.PluginUnloaded"
-	PluginsSwapped           = "Control.PluginsSwapped"
-	PluginSubscribed         = "Control.PluginSubscribed"
-	PluginUnsubscribed       = "Control.PluginUnsubscribed"
-	ProcessorSubscribed      = "Control.ProcessorSubscribed"
-	ProcessorUnsubscribed    = "Control.ProcessorUnsubscribed"
-	MetricSubscribed         = "Control.MetricSubscribed"
-	MetricUnsubscribed       = "Control.MetricUnsubscribed"
-	HealthCheckFailed        = "Control.PluginHealthCheckFailed"
-	MoveSubscription         = "Control.PluginSubscriptionMoved"
+	AvailablePluginDead        = "Control.AvailablePluginDead"
+	AvailablePluginRestarted   = "Control.RestartedAvailablePlugin"
+	PluginRestartsExceeded     = "Control.PluginRestartsExceeded"
+	PluginStarted              = "Control.PluginStarted"
+	PluginLoaded               = "Control.PluginLoaded"
+	PluginUnloaded             = "Control.PluginUnloaded"
+	PluginUnloading            = "Control.PluginUnloading"
+	PluginsSwapped             = "Control.PluginsSwapped"
+	PluginUpgraded             = "Control.PluginUpgraded"
+	WatchedPluginLoaded        = "Control.WatchedPluginLoaded"
+	WatchedPluginUnloaded      = "Control.WatchedPluginUnloaded"
+	WatchedPluginReloaded      = "Control.WatchedPluginReloaded"
+	PluginSubscribed           = "Control.PluginSubscribed"
+	PluginUnsubscribed         = "Control.PluginUnsubscribed"
+	ProcessorSubscribed        = "Control.ProcessorSubscribed"
+	ProcessorUnsubscribed      = "Control.ProcessorUnsubscribed"
+	MetricSubscribed           = "Control.MetricSubscribed"
+	MetricUnsubscribed         = "Control.MetricUnsubscribed"
+	HealthCheckFailed          = "Control.PluginHealthCheckFailed"
+	HealthCheckRecovered       = "Control.PluginHealthCheckRecovered"
+	MemoryLimitExceeded        = "Control.PluginMemoryLimitExceeded"
+	PluginBlacklisted          = "Control.PluginBlacklisted"
+	PluginConfigReloaded       = "Control.PluginConfigReloaded"
+	MoveSubscription           = "Control.PluginSubscriptionMoved"
+	DeprecatedMetricSubscribed = "Control.DeprecatedMetricSubscribed"
 )
 
 type StartPluginEvent struct {
@@ -70,6 +80,17 @@ func (e UnloadPluginEvent) Namespace() string {
 	return PluginUnloaded
 }
 
+type UnloadingPluginEvent struct {
+	Name    string
+	Version int
+	Type    int
+	TaskIDs []string
+}
+
+func (e UnloadingPluginEvent) Namespace() string {
+	return PluginUnloading
+}
+
 type DeadAvailablePluginEvent struct {
 	Name    string
 	Version int
@@ -119,6 +140,52 @@ func (s SwapPluginsEvent) Namespace() string {
 	return PluginsSwapped
 }
 
+type UpgradePluginEvent struct {
+	Name            string
+	PreviousVersion int
+	Version         int
+	Type            int
+	TaskIds         []string
+}
+
+func (e UpgradePluginEvent) Namespace() string {
+	return PluginUpgraded
+}
+
+type WatchedPluginLoadEvent struct {
+	Path    string
+	Name    string
+	Version int
+	Type    int
+}
+
+func (e WatchedPluginLoadEvent) Namespace() string {
+	return WatchedPluginLoaded
+}
+
+type WatchedPluginUnloadEvent struct {
+	Path    string
+	Name    string
+	Version int
+	Type    int
+}
+
+func (e WatchedPluginUnloadEvent) Namespace() string {
+	return WatchedPluginUnloaded
+}
+
+type WatchedPluginReloadEvent struct {
+	Path            string
+	Name            string
+	PreviousVersion int
+	Version         int
+	Type            int
+}
+
+func (e WatchedPluginReloadEvent) Namespace() string {
+	return WatchedPluginReloaded
+}
+
 type PluginSubscriptionEvent struct {
 	PluginName    string
 	PluginVersion int
@@ -141,12 +208,74 @@ func (pu PluginUnsubscriptionEvent) Namespace() string {
 	return PluginUnsubscribed
 }
 
+type DeprecatedMetricSubscriptionEvent struct {
+	TaskId          string
+	MetricNamespace string
+	MetricVersion   int
+	Message         string
+}
+
+func (de DeprecatedMetricSubscriptionEvent) Namespace() string {
+	return DeprecatedMetricSubscribed
+}
+
 type HealthCheckFailedEvent struct {
+	Name         string
+	Version      int
+	Type         int
+	Id           uint32
+	FailedChecks int
+}
+
+func (hfe HealthCheckFailedEvent) Namespace() string {
+	return HealthCheckFailed
+}
+
+type HealthCheckRecoveredEvent struct {
+	Name         string
+	Version      int
+	Type         int
+	Id           uint32
+	FailedChecks int
+}
+
+func (hre HealthCheckRecoveredEvent) Namespace() string {
+	return HealthCheckRecovered
+}
+
+type MemoryLimitExceededEvent struct {
 	Name    string
 	Version int
 	Type    int
+	Id      uint32
 }
 
-func (hfe HealthCheckFailedEvent) Namespace() string {
-	return HealthCheckFailed
+func (mle MemoryLimitExceededEvent) Namespace() string {
+	return MemoryLimitExceeded
+}
+
+type PluginBlacklistedEvent struct {
+	Name     string
+	Version  int
+	Type     int
+	Key      string
+	CheckSum string
+	Crashes  int
+}
+
+func (pbe PluginBlacklistedEvent) Namespace() string {
+	return PluginBlacklisted
+}
+
+type PluginConfigReloadedEvent struct {
+	Name    string
+	Version int
+	Type    int
+	Key     string
+	// Instances is the number of running instances the config was pushed to
+	Instances int
+}
+
+func (pce PluginConfigReloadedEvent) Namespace() string {
+	return PluginConfigReloaded
 }
@@ -0,0 +1,98 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ctypes
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// the units of the byte sizes, largest first, a binary unit before the
+// decimal unit of the same order
+var byteUnits = []struct {
+	name string
+	size int64
+}{
+	{"TiB", 1 << 40},
+	{"TB", 1000 * 1000 * 1000 * 1000},
+	{"GiB", 1 << 30},
+	{"GB", 1000 * 1000 * 1000},
+	{"MiB", 1 << 20},
+	{"MB", 1000 * 1000},
+	{"KiB", 1 << 10},
+	{"KB", 1000},
+	{"B", 1},
+}
+
+// ParseByteSize parses a number of bytes with a unit, e.g. "512MB" or
+// "1.5GiB". KB, MB, GB and TB are powers of 1000 and KiB, MiB, GiB and TiB
+// powers of 1024, the units are case insensitive and a number without unit is
+// a number of bytes.
+func ParseByteSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+	num, unit := s[:i], strings.TrimSpace(s[i:])
+	v, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+	size := int64(1)
+	if unit != "" {
+		size = 0
+		for _, u := range byteUnits {
+			if strings.EqualFold(unit, u.name) {
+				size = u.size
+				break
+			}
+		}
+		if size == 0 {
+			return 0, fmt.Errorf("unknown unit %q in byte size %q", unit, s)
+		}
+	}
+	bytes := v * float64(size)
+	if bytes >= math.MaxInt64 {
+		return 0, fmt.Errorf("byte size %q is too large", s)
+	}
+	if bytes != math.Trunc(bytes) {
+		return 0, fmt.Errorf("byte size %q isn't a whole number of bytes", s)
+	}
+	return int64(bytes), nil
+}
+
+// FormatByteSize formats a number of bytes with the largest unit it is a
+// whole number of, e.g. 536870912 as "512MiB" and 512000000 as "512MB"
+func FormatByteSize(n int64) string {
+	if n == 0 {
+		return "0B"
+	}
+	for _, u := range byteUnits {
+		if n%u.size == 0 {
+			return fmt.Sprintf("%d%s", n/u.size, u.name)
+		}
+	}
+	return fmt.Sprintf("%dB", n)
+}
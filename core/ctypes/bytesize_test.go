@@ -0,0 +1,70 @@
+// +build legacy
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ctypes
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestByteSize(t *testing.T) {
+	Convey("ParseByteSize", t, func() {
+		for s, n := range map[string]int64{
+			"512":     512,
+			"512B":    512,
+			"1KB":     1000,
+			"1kib":    1024,
+			"512MB":   512000000,
+			"512 MiB": 512 << 20,
+			"1.5GiB":  3 << 29,
+			"2TB":     2000000000000,
+		} {
+			v, err := ParseByteSize(s)
+			So(err, ShouldBeNil)
+			So(v, ShouldEqual, n)
+		}
+		for _, s := range []string{"", "MB", "-1MB", "12XB", "0.5B", "1.5.1KB", "99999999TiB"} {
+			_, err := ParseByteSize(s)
+			So(err, ShouldNotBeNil)
+		}
+	})
+
+	Convey("FormatByteSize", t, func() {
+		So(FormatByteSize(0), ShouldEqual, "0B")
+		So(FormatByteSize(512<<20), ShouldEqual, "512MiB")
+		So(FormatByteSize(512000000), ShouldEqual, "512MB")
+		So(FormatByteSize(1500), ShouldEqual, "1500B")
+		So(FormatByteSize(1<<40), ShouldEqual, "1TiB")
+	})
+
+	Convey("The typed values are marshalled as strings", t, func() {
+		b, err := json.Marshal(map[string]ConfigValue{
+			"interval": ConfigValueDuration{Value: 90 * time.Second},
+			"buffer":   ConfigValueByteSize{Value: 64 << 20},
+		})
+		So(err, ShouldBeNil)
+		So(string(b), ShouldEqual, `{"buffer":"64MiB","interval":"1m30s"}`)
+	})
+}
@@ -19,7 +19,10 @@ limitations under the License.
 
 package ctypes
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"time"
+)
 
 // TODO constructors for each that have typing for value (and optionally validate)
 
@@ -75,6 +78,42 @@ func (c ConfigValueBool) MarshalJSON() ([]byte, error) {
 	return json.Marshal(c.Value)
 }
 
+// ConfigValueDuration is a duration given as a string with units, e.g. "30s"
+// or "1m30s", see time.ParseDuration
+type ConfigValueDuration struct {
+	Value time.Duration
+}
+
+func (c ConfigValueDuration) Type() string {
+	return "duration"
+}
+
+func (c ConfigValueDuration) String() string {
+	return c.Value.String()
+}
+
+func (c ConfigValueDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.String())
+}
+
+// ConfigValueByteSize is a number of bytes given as a string with a unit,
+// e.g. "512MB" or "1GiB", see ParseByteSize
+type ConfigValueByteSize struct {
+	Value int64
+}
+
+func (c ConfigValueByteSize) Type() string {
+	return "bytesize"
+}
+
+func (c ConfigValueByteSize) String() string {
+	return FormatByteSize(c.Value)
+}
+
+func (c ConfigValueByteSize) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.String())
+}
+
 // Returns a slice of string keywords for the types supported by ConfigValue.
 func SupportedTypes() []string {
 	// This is kind of a hack but keeps the definition of types here in
@@ -90,6 +129,10 @@ func SupportedTypes() []string {
 		ConfigValueFloat{}.Type(),
 		// Bool
 		ConfigValueBool{}.Type(),
+		// Duration
+		ConfigValueDuration{}.Type(),
+		// Byte size
+		ConfigValueByteSize{}.Type(),
 	}
 	return t
 }
@@ -0,0 +1,54 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"time"
+
+	"github.com/intelsdi-x/snap/core/serror"
+)
+
+// DeadLetter is a batch of metrics a publisher of a task failed to publish,
+// spooled so it can be published again once the publisher recovered
+type DeadLetter struct {
+	ID            string
+	TaskID        string
+	TaskName      string
+	PluginName    string
+	PluginVersion int
+	// Errors are the errors of the failed publish
+	Errors []string
+	// Timestamp is the time the batch was spooled at
+	Timestamp time.Time
+	// MetricCount is the number of metrics of the batch
+	MetricCount int
+	// Metrics are the metrics of the batch, only set when a single batch is
+	// returned
+	Metrics []Metric
+}
+
+// DeadLetterResult holds the result of replaying or purging a batch of the
+// dead letter queue
+type DeadLetterResult struct {
+	ID string
+	// Error is set when the batch couldn't be replayed or purged, the batches
+	// which failed to be replayed are kept in the queue
+	Error serror.SnapError
+}
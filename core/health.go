@@ -0,0 +1,37 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+// SubsystemHealth holds the health of a subsystem of snapteld, as reported by
+// the health and readiness endpoints of the REST API
+type SubsystemHealth struct {
+	Name string
+	// Healthy is false when the subsystem isn't running
+	Healthy bool
+	// Ready is false while the subsystem can't serve the requests yet
+	Ready bool
+	// Degraded is true when the subsystem runs with failures, e.g. plugins
+	// failing their health checks
+	Degraded bool
+	// Message explains why the subsystem is unhealthy, not ready or degraded
+	Message string
+	// Details holds the state the health of the subsystem is decided on
+	Details map[string]interface{}
+}
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
 	"github.com/intelsdi-x/snap/core/cdata"
@@ -32,7 +33,16 @@ var (
 	// Standard Tags are in added to the metric by the framework on plugin load.
 	// STD_TAG_PLUGIN_RUNNING_ON describes where the plugin is running (hostname).
 	STD_TAG_PLUGIN_RUNNING_ON = "plugin_running_on"
-	nsPriorityList            = []string{"/", "|", "%", ":", "-", ";", "_", "^", ">", "<", "+", "=", "&", "㊽", "Ä", "大", "小", "ᵹ", "☍", "ヒ"}
+	// DEPRECATED_TAG is the tag a plugin advertises on a metric version which is deprecated,
+	// its value may describe how to migrate (e.g. which metric or version replaces it).
+	DEPRECATED_TAG = "deprecated"
+	// MIN_COLLECTION_INTERVAL_TAG is the tag a plugin advertises on a metric which must not be
+	// collected more often than the given duration (e.g. "1m" for a metric backed by a rate limited API).
+	MIN_COLLECTION_INTERVAL_TAG = "min_collection_interval"
+	// CACHE_TTL_TAG is the tag a plugin advertises on a metric whose collected values can be shared
+	// between the tasks requesting it for the given duration (e.g. "30s"), "0s" disables the sharing.
+	CACHE_TTL_TAG  = "cache_ttl"
+	nsPriorityList = []string{"/", "|", "%", ":", "-", ";", "_", "^", ">", "<", "+", "=", "&", "㊽", "Ä", "大", "小", "ᵹ", "☍", "ヒ"}
 )
 
 // Metric represents a snap metric collected or to be collected
@@ -45,6 +55,7 @@ type Metric interface {
 	Timestamp() time.Time
 	Description() string
 	Unit() string
+	DataType() string
 }
 
 type Namespace []NamespaceElement
@@ -152,6 +163,57 @@ func (n Namespace) AddStaticElements(values ...string) Namespace {
 	return n
 }
 
+// DynamicElementNames returns the names of the dynamic elements of the namespace
+// in the order they appear in the namespace.
+func (n Namespace) DynamicElementNames() []string {
+	var names []string
+	for i := range n {
+		if n[i].IsDynamic() {
+			names = append(names, n[i].Name)
+		}
+	}
+	return names
+}
+
+// Matches returns true if the given concrete namespace is an instance of the
+// namespace, i.e. both have the same length, all static elements are equal
+// and dynamic elements match any value of the concrete namespace.
+func (n Namespace) Matches(concrete Namespace) bool {
+	if len(n) != len(concrete) {
+		return false
+	}
+	for i := range n {
+		if n[i].IsDynamic() || n[i].Value == "*" {
+			continue
+		}
+		if n[i].Value != concrete[i].Value {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseNamespace takes a string representation of a namespace where the first
+// character is the separator (e.g. "/intel/docker/[container_id]/cpu") and
+// returns a Namespace.  An element enclosed in square brackets is a placeholder
+// which results in a dynamic element named by the enclosed text.
+func ParseNamespace(ns string) Namespace {
+	if ns == "" {
+		return Namespace{}
+	}
+	r, size := utf8.DecodeRuneInString(ns)
+	sep := string(r)
+	n := Namespace{}
+	for _, e := range strings.Split(ns[size:], sep) {
+		if len(e) > 2 && strings.HasPrefix(e, "[") && strings.HasSuffix(e, "]") {
+			n = n.AddDynamicElement(e[1:len(e)-1], "")
+			continue
+		}
+		n = n.AddStaticElement(e)
+	}
+	return n
+}
+
 func (n Namespace) Element(idx int) NamespaceElement {
 	if idx >= 0 && idx < len(n) {
 		return n[idx]
@@ -197,4 +259,5 @@ type CatalogedMetric interface {
 	Policy() *cpolicy.ConfigPolicyNode
 	Description() string
 	Unit() string
+	DataType() string
 }
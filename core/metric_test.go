@@ -21,6 +21,39 @@ func TestMetricSeparator(t *testing.T) {
 	})
 }
 
+func TestParseNamespace(t *testing.T) {
+	Convey("Test ParseNamespace", t, func() {
+		Convey("static namespace", func() {
+			ns := ParseNamespace("/intel/docker/cpu")
+			So(ns, ShouldResemble, NewNamespace("intel", "docker", "cpu"))
+			So(ns.DynamicElementNames(), ShouldBeEmpty)
+		})
+		Convey("namespace with a placeholder", func() {
+			ns := ParseNamespace("/intel/docker/[container_id]/cpu")
+			So(ns, ShouldResemble, NewNamespace("intel", "docker").AddDynamicElement("container_id", "").AddStaticElement("cpu"))
+			So(ns.String(), ShouldEqual, "/intel/docker/*/cpu")
+			So(ns.DynamicElementNames(), ShouldResemble, []string{"container_id"})
+		})
+		Convey("namespace with a different separator", func() {
+			ns := ParseNamespace("|intel|[host]|[container_id]|cpu")
+			So(ns.DynamicElementNames(), ShouldResemble, []string{"host", "container_id"})
+		})
+		Convey("empty namespace", func() {
+			So(ParseNamespace(""), ShouldBeEmpty)
+		})
+	})
+}
+
+func TestNamespaceMatches(t *testing.T) {
+	Convey("Test Namespace.Matches", t, func() {
+		ns := ParseNamespace("/intel/docker/[container_id]/cpu")
+		So(ns.Matches(NewNamespace("intel", "docker", "1234", "cpu")), ShouldBeTrue)
+		So(ns.Matches(NewNamespace("intel", "docker", "1234", "mem")), ShouldBeFalse)
+		So(ns.Matches(NewNamespace("intel", "docker", "1234")), ShouldBeFalse)
+		So(NewNamespace("intel", "docker").Matches(NewNamespace("intel", "docker")), ShouldBeTrue)
+	})
+}
+
 type testCase struct {
 	input    Namespace
 	expected string
@@ -107,6 +107,8 @@ type AvailablePlugin interface {
 	LastHit() time.Time
 	ID() uint32
 	Port() string
+	FailedHealthChecks() int
+	Healthy() bool
 }
 
 // the public interface for a plugin
@@ -125,6 +127,48 @@ type CatalogedPlugin interface {
 // by mgmt modules
 type PluginCatalog []CatalogedPlugin
 
+// BlacklistedPlugin is a plugin binary which is refused to be run after
+// crashing repeatedly
+type BlacklistedPlugin struct {
+	Name    string
+	Version int
+	Type    PluginType
+	Path    string
+	// CheckSum is the hex encoded SHA-256 checksum of the plugin binary
+	CheckSum string
+	// Crashes is the number of crashes which got the plugin blacklisted
+	Crashes int
+	// Since is the time the plugin was blacklisted at
+	Since time.Time
+}
+
+// PluginMetadata holds the details of a loaded plugin and of its running
+// instances
+type PluginMetadata struct {
+	Name    string
+	Version int
+	Type    PluginType
+	Path    string
+	// CheckSum is the hex encoded SHA-256 checksum of the plugin binary
+	CheckSum   string
+	LoadedTime time.Time
+	// RPCType is the RPC protocol the plugin is called with
+	RPCType string
+	// MetricCount is the number of metrics the plugin exposes in the catalog
+	MetricCount int
+	// ConfigPolicyRules is the number of rules of the config policy of the plugin
+	ConfigPolicyRules int
+	// RequiredConfig holds the config items the plugin requires which have
+	// no default
+	RequiredConfig []string
+	// PIDs holds the process IDs of the running instances of the plugin
+	PIDs []int
+	// Calls and Errors count the calls made to the plugin since it was loaded
+	// and the ones which failed
+	Calls  uint64
+	Errors uint64
+}
+
 type SubscribedPlugin interface {
 	Plugin
 	Config() *cdata.ConfigDataNode
@@ -142,6 +186,9 @@ type RequestedPlugin struct {
 	tlsEnabled  bool
 	autoLoaded  bool
 	uri         *url.URL
+	downloadUri *url.URL
+	// hex encoded SHA-256 checksum the downloaded plugin has to match
+	downloadCheckSum string
 }
 
 // NewRequestedPlugin returns a Requested Plugin which represents the plugin path and signature
@@ -204,6 +251,20 @@ func NewRequestedPlugin(path, fileName string, b []byte) (*RequestedPlugin, erro
 	return rp, nil
 }
 
+// NewDownloadRequestedPlugin returns a Requested Plugin which is downloaded from
+// the HTTP(S) URL (rawurl) before it gets loaded. The downloaded plugin has to match
+// the hex encoded SHA-256 checksum, a plugin without checksum isn't loaded.
+func NewDownloadRequestedPlugin(rawurl, checkSum string) (*RequestedPlugin, error) {
+	if !IsUri(rawurl) {
+		return nil, fmt.Errorf("invalid plugin download URL: %s", rawurl)
+	}
+	uri, err := url.ParseRequestURI(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	return &RequestedPlugin{downloadUri: uri, downloadCheckSum: checkSum}, nil
+}
+
 // Checks if string is URL
 func IsUri(url string) bool {
 	if !govalidator.IsURL(url) || !strings.HasPrefix(url, "http") {
@@ -248,6 +309,16 @@ func (p *RequestedPlugin) Uri() *url.URL {
 	return p.uri
 }
 
+// DownloadUri returns the URL the requested plugin is downloaded from before it is loaded
+func (p *RequestedPlugin) DownloadUri() *url.URL {
+	return p.downloadUri
+}
+
+// DownloadCheckSum returns the hex encoded SHA-256 checksum the downloaded plugin has to match
+func (p *RequestedPlugin) DownloadCheckSum() string {
+	return p.downloadCheckSum
+}
+
 func (p *RequestedPlugin) SetPath(path string) {
 	p.path = path
 }
@@ -276,6 +347,11 @@ func (p *RequestedPlugin) SetSignature(data []byte) {
 	p.signature = data
 }
 
+// SetCheckSum sets the checksum of the requested plugin file
+func (p *RequestedPlugin) SetCheckSum(checkSum [sha256.Size]byte) {
+	p.checkSum = checkSum
+}
+
 func (p *RequestedPlugin) SetUri(uri *url.URL) {
 	p.uri = uri
 }
@@ -0,0 +1,99 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/intelsdi-x/snap/core/cdata"
+	"github.com/intelsdi-x/snap/core/ctypes"
+)
+
+// The plugin config items which configure the pool of running instances of a
+// plugin instead of being passed to the plugin
+const (
+	PoolMaxInstancesKey = "pool_max_instances"
+	PoolIdleTimeoutKey  = "pool_idle_timeout"
+	PoolRoutingKey      = "pool_routing"
+)
+
+// PoolConfigKeys lists the pool items of the plugin config
+var PoolConfigKeys = []string{PoolMaxInstancesKey, PoolIdleTimeoutKey, PoolRoutingKey}
+
+// The routing strategies a pool can be configured with
+const (
+	PoolRoutingLRU         = "least-recently-used"
+	PoolRoutingSticky      = "sticky"
+	PoolRoutingConfig      = "config"
+	PoolRoutingLeastLoaded = "least-loaded"
+)
+
+// PluginPoolConfig holds the settings of the pool of running instances of a
+// plugin.  Zero values keep the settings declared by the plugin.
+type PluginPoolConfig struct {
+	// MaxInstances is the maximum number of running instances
+	MaxInstances int
+	// IdleTimeout is the time an instance can stay unused before it's killed
+	IdleTimeout time.Duration
+	// Routing is the strategy used to route requests to the instances
+	Routing string
+}
+
+// IsEmpty returns true when the pool config doesn't override any setting
+func (p PluginPoolConfig) IsEmpty() bool {
+	return p.MaxInstances == 0 && p.IdleTimeout == 0 && p.Routing == ""
+}
+
+// NewPluginPoolConfig returns the pool config set by the pool items of the
+// plugin config.  Invalid items are skipped and reported by the returned error.
+func NewPluginPoolConfig(cdn *cdata.ConfigDataNode) (PluginPoolConfig, error) {
+	cfg := PluginPoolConfig{}
+	if cdn == nil {
+		return cfg, nil
+	}
+	var err error
+	table := cdn.Table()
+	if v, ok := table[PoolMaxInstancesKey]; ok {
+		if i, ok := v.(ctypes.ConfigValueInt); ok && i.Value > 0 {
+			cfg.MaxInstances = i.Value
+		} else {
+			err = fmt.Errorf("%s must be a positive integer", PoolMaxInstancesKey)
+		}
+	}
+	if v, ok := table[PoolIdleTimeoutKey]; ok {
+		s, _ := v.(ctypes.ConfigValueStr)
+		if d, perr := time.ParseDuration(s.Value); perr == nil && d >= 0 {
+			cfg.IdleTimeout = d
+		} else {
+			err = fmt.Errorf("%s must be a positive duration", PoolIdleTimeoutKey)
+		}
+	}
+	if v, ok := table[PoolRoutingKey]; ok {
+		s, _ := v.(ctypes.ConfigValueStr)
+		switch s.Value {
+		case PoolRoutingLRU, PoolRoutingSticky, PoolRoutingConfig, PoolRoutingLeastLoaded:
+			cfg.Routing = s.Value
+		default:
+			err = fmt.Errorf("%s must be one of %s, %s, %s or %s", PoolRoutingKey, PoolRoutingLRU, PoolRoutingSticky, PoolRoutingConfig, PoolRoutingLeastLoaded)
+		}
+	}
+	return cfg, err
+}
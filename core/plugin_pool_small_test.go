@@ -0,0 +1,67 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/core/cdata"
+	"github.com/intelsdi-x/snap/core/ctypes"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNewPluginPoolConfig(t *testing.T) {
+	Convey("NewPluginPoolConfig", t, func() {
+		cdn := cdata.NewNode()
+		cdn.AddItem("user", ctypes.ConfigValueStr{Value: "jane"})
+
+		Convey("returns an empty config when no pool item is set", func() {
+			cfg, err := NewPluginPoolConfig(cdn)
+			So(err, ShouldBeNil)
+			So(cfg.IsEmpty(), ShouldBeTrue)
+		})
+
+		Convey("reads the pool items", func() {
+			cdn.AddItem(PoolMaxInstancesKey, ctypes.ConfigValueInt{Value: 5})
+			cdn.AddItem(PoolIdleTimeoutKey, ctypes.ConfigValueStr{Value: "10m"})
+			cdn.AddItem(PoolRoutingKey, ctypes.ConfigValueStr{Value: PoolRoutingSticky})
+			cfg, err := NewPluginPoolConfig(cdn)
+			So(err, ShouldBeNil)
+			So(cfg, ShouldResemble, PluginPoolConfig{
+				MaxInstances: 5,
+				IdleTimeout:  10 * time.Minute,
+				Routing:      PoolRoutingSticky,
+			})
+		})
+
+		Convey("skips invalid pool items", func() {
+			cdn.AddItem(PoolMaxInstancesKey, ctypes.ConfigValueInt{Value: 5})
+			cdn.AddItem(PoolIdleTimeoutKey, ctypes.ConfigValueStr{Value: "soon"})
+			cdn.AddItem(PoolRoutingKey, ctypes.ConfigValueStr{Value: "round-robin"})
+			cfg, err := NewPluginPoolConfig(cdn)
+			So(err, ShouldNotBeNil)
+			So(cfg, ShouldResemble, PluginPoolConfig{MaxInstances: 5})
+		})
+	})
+}
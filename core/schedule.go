@@ -39,13 +39,54 @@ type Schedule struct {
 	StartTimestamp *time.Time `json:"start_timestamp,omitempty"`
 	StopTimestamp  *time.Time `json:"stop_timestamp,omitempty"`
 	Count          uint       `json:"count,omitempty"`
+	// Timezone the cron entry or the recurring window is evaluated in (e.g.
+	// Europe/Paris), defaults to the local timezone of snapteld
+	Timezone string `json:"timezone,omitempty"`
+	// Jitter is the percentage of the interval (0-100) the first run of a simple or
+	// windowed schedule is delayed by at most, the delay is derived from the hostname
+	Jitter uint `json:"jitter,omitempty"`
+	// Recurrence makes the window of a windowed schedule recur every day or on
+	// some days of the week
+	Recurrence *ScheduleRecurrence `json:"recurrence,omitempty"`
+}
+
+// ScheduleRecurrence defines a window recurring between two times of the day.
+//
+// swagger:model ScheduleRecurrence
+type ScheduleRecurrence struct {
+	// Start is the time of the day the window opens at (e.g. 09:00)
+	// required: true
+	Start string `json:"start"`
+	// Stop is the time of the day the window closes at (e.g. 17:00)
+	// required: true
+	Stop string `json:"stop"`
+	// Days are the days of the week the window opens on (e.g. monday, mon,
+	// weekdays or weekend), every day when empty
+	Days []string `json:"days,omitempty"`
 }
 
 var (
 	ErrMissingScheduleInterval = errors.New("missing `interval` in configuration of schedule")
+	ErrTimezoneNotSupported    = errors.New("`timezone` is only supported by cron schedules and recurring windows")
+	ErrJitterNotSupported      = errors.New("`jitter` is only supported by simple and windowed schedules")
+	ErrRecurrenceNotSupported  = errors.New("`recurrence` is only supported by windowed schedules")
 )
 
+// MakeSchedule returns the schedule defined by s
+func MakeSchedule(s Schedule) (schedule.Schedule, error) {
+	return makeSchedule(s)
+}
+
 func makeSchedule(s Schedule) (schedule.Schedule, error) {
+	if s.Timezone != "" && s.Type != "cron" && s.Recurrence == nil {
+		return nil, ErrTimezoneNotSupported
+	}
+	if s.Recurrence != nil && s.Type != "windowed" {
+		return nil, ErrRecurrenceNotSupported
+	}
+	if s.Jitter != 0 && s.Type != "simple" && s.Type != "windowed" {
+		return nil, ErrJitterNotSupported
+	}
 	switch s.Type {
 	case "simple", "windowed":
 		if s.Interval == "" {
@@ -63,6 +104,13 @@ func makeSchedule(s Schedule) (schedule.Schedule, error) {
 			s.StopTimestamp,
 			s.Count,
 		)
+		sch.Jitter = s.Jitter
+		if s.Recurrence != nil {
+			sch.Recurrence, err = schedule.NewRecurrence(s.Recurrence.Start, s.Recurrence.Stop, s.Recurrence.Days, s.Timezone)
+			if err != nil {
+				return nil, err
+			}
+		}
 
 		err = sch.Validate()
 		if err != nil {
@@ -73,7 +121,7 @@ func makeSchedule(s Schedule) (schedule.Schedule, error) {
 		if s.Interval == "" {
 			return nil, ErrMissingScheduleInterval
 		}
-		sch := schedule.NewCronSchedule(s.Interval)
+		sch := schedule.NewCronScheduleWithTimezone(s.Interval, s.Timezone)
 
 		err := sch.Validate()
 		if err != nil {
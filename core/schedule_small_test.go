@@ -26,6 +26,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/intelsdi-x/snap/pkg/schedule"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
@@ -219,4 +220,48 @@ func TestMakeSchedule(t *testing.T) {
 		So(err, ShouldNotBeNil)
 		So(err.Error(), ShouldStartWith, "Expected 5 or 6 fields, found ")
 	})
+
+	Convey("Cron schedule with timezone", t, func() {
+		sched1 := &Schedule{Type: "cron", Interval: "0 0 9 * * *", Timezone: "Europe/Paris"}
+		rsched, err := makeSchedule(*sched1)
+		So(err, ShouldBeNil)
+		So(rsched, ShouldNotBeNil)
+		So(rsched.(*schedule.CronSchedule).Timezone(), ShouldEqual, "Europe/Paris")
+	})
+
+	Convey("Cron schedule with invalid timezone", t, func() {
+		sched1 := &Schedule{Type: "cron", Interval: "0 0 9 * * *", Timezone: "Nowhere"}
+		rsched, err := makeSchedule(*sched1)
+		So(rsched, ShouldBeNil)
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldStartWith, "invalid timezone `Nowhere`")
+	})
+
+	Convey("Simple schedule with timezone", t, func() {
+		sched1 := &Schedule{Type: "simple", Interval: "1s", Timezone: "Europe/Paris"}
+		rsched, err := makeSchedule(*sched1)
+		So(rsched, ShouldBeNil)
+		So(err, ShouldEqual, ErrTimezoneNotSupported)
+	})
+
+	Convey("Simple schedule with jitter", t, func() {
+		sched1 := &Schedule{Type: "simple", Interval: "1m", Jitter: 50}
+		rsched, err := makeSchedule(*sched1)
+		So(err, ShouldBeNil)
+		So(rsched.(*schedule.WindowedSchedule).Jitter, ShouldEqual, 50)
+	})
+
+	Convey("Simple schedule with invalid jitter", t, func() {
+		sched1 := &Schedule{Type: "simple", Interval: "1m", Jitter: 120}
+		rsched, err := makeSchedule(*sched1)
+		So(rsched, ShouldBeNil)
+		So(err, ShouldEqual, schedule.ErrInvalidJitter)
+	})
+
+	Convey("Cron schedule with jitter", t, func() {
+		sched1 := &Schedule{Type: "cron", Interval: "0 * * * * *", Jitter: 10}
+		rsched, err := makeSchedule(*sched1)
+		So(rsched, ShouldBeNil)
+		So(err, ShouldEqual, ErrJitterNotSupported)
+	})
 }
@@ -29,6 +29,11 @@ const (
 	TaskDeleted            = "Scheduler.TaskDeleted"
 	TaskStarted            = "Scheduler.TaskStarted"
 	TaskStopped            = "Scheduler.TaskStopped"
+	TaskPaused             = "Scheduler.TaskPaused"
+	TaskResumed            = "Scheduler.TaskResumed"
+	TaskOverrun            = "Scheduler.TaskOverrun"
+	TaskRun                = "Scheduler.TaskRun"
+	CircuitBreaker         = "Scheduler.CircuitBreaker"
 	TaskEnded              = "Scheduler.TaskEnded"
 	TaskDisabled           = "Scheduler.TaskDisabled"
 	MetricCollected        = "Scheduler.MetricsCollected"
@@ -57,6 +62,7 @@ type TaskCreatedEvent struct {
 	TaskID        string
 	StartOnCreate bool
 	Source        string
+	Labels        map[string]string
 }
 
 func (e TaskCreatedEvent) Namespace() string {
@@ -81,6 +87,54 @@ func (e TaskStoppedEvent) Namespace() string {
 	return TaskStopped
 }
 
+type TaskPausedEvent struct {
+	TaskID string
+	Source string
+}
+
+func (e TaskPausedEvent) Namespace() string {
+	return TaskPaused
+}
+
+type TaskResumedEvent struct {
+	TaskID string
+	Source string
+}
+
+func (e TaskResumedEvent) Namespace() string {
+	return TaskResumed
+}
+
+type TaskOverrunEvent struct {
+	TaskID       string
+	Policy       string
+	OverrunCount uint
+}
+
+func (e TaskOverrunEvent) Namespace() string {
+	return TaskOverrun
+}
+
+type TaskRunEvent struct {
+	TaskID string
+	Record core.TaskRunRecord
+}
+
+func (e TaskRunEvent) Namespace() string {
+	return TaskRun
+}
+
+// CircuitBreakerEvent is emitted when the circuit breaker of a workflow step
+// of a task changes state
+type CircuitBreakerEvent struct {
+	TaskID  string
+	Breaker core.TaskStepCircuitBreaker
+}
+
+func (e CircuitBreakerEvent) Namespace() string {
+	return CircuitBreaker
+}
+
 type TaskEndedEvent struct {
 	TaskID string
 	Source string
@@ -44,6 +44,7 @@ const (
 	TaskFiring
 	TaskEnded
 	TaskStopping
+	TaskPaused
 )
 
 var (
@@ -54,9 +55,66 @@ var (
 		TaskFiring:   "Running",  // running (firing can happen so briefly we don't want to try and render it as a string state)
 		TaskEnded:    "Ended",    // ended, but resumable if the schedule is still valid and might fire again
 		TaskStopping: "Stopping", // channel has been closed, wait for TaskStopped state
+		TaskPaused:   "Paused",   // paused, keeps its subscriptions and schedule until resumed
 	}
 )
 
+// TaskDependencyState is the state of a task another task depends on
+type TaskDependencyState int
+
+const (
+	TaskDependencyMissing TaskDependencyState = iota - 1
+	TaskDependencyWaiting
+	TaskDependencySatisfied
+	TaskDependencyFailed
+)
+
+var (
+	TaskDependencyStateLookup = map[TaskDependencyState]string{
+		TaskDependencyMissing:   "Missing",   // the task doesn't exist
+		TaskDependencyWaiting:   "Waiting",   // the task didn't complete a run yet
+		TaskDependencySatisfied: "Satisfied", // the most recent run of the task succeeded
+		TaskDependencyFailed:    "Failed",    // the most recent run of the task failed
+	}
+)
+
+func (t TaskDependencyState) String() string {
+	return TaskDependencyStateLookup[t]
+}
+
+// The policies applied to a task run lasting longer than the run deadline of
+// its task
+const (
+	// TaskOverrunQueue waits for the run to end, the next run starts right after
+	TaskOverrunQueue = "queue"
+	// TaskOverrunSkip stops waiting for the run, the runs due while it's in
+	// flight are skipped
+	TaskOverrunSkip = "skip"
+	// TaskOverrunKill abandons the run, its jobs not started yet are dropped
+	// and the run is recorded as failed.  The task doesn't fire again until
+	// the jobs already started ended.
+	TaskOverrunKill = "kill"
+)
+
+// The priorities of a task, the jobs of the tasks with a higher priority are
+// dispatched first to the workers when all of them are busy
+const (
+	TaskPriorityLow    = "low"
+	TaskPriorityNormal = "normal"
+	TaskPriorityHigh   = "high"
+)
+
+var (
+	// ErrInvalidTaskOverrunPolicy - error message when the overrun policy of a task is unknown
+	ErrInvalidTaskOverrunPolicy = fmt.Errorf("overrun policy must be one of %s, %s or %s", TaskOverrunQueue, TaskOverrunSkip, TaskOverrunKill)
+	// ErrInvalidTaskPriority - error message when the priority of a task is unknown
+	ErrInvalidTaskPriority = fmt.Errorf("priority must be one of %s, %s or %s", TaskPriorityLow, TaskPriorityNormal, TaskPriorityHigh)
+	// ErrInvalidTaskBufferSize - error message when the max size of the buffer of a task isn't positive
+	ErrInvalidTaskBufferSize = errors.New("buffer max-size must be a positive number of megabytes")
+	// ErrInvalidTaskBufferAge - error message when the max age of the buffer of a task isn't a positive duration
+	ErrInvalidTaskBufferAge = errors.New("buffer max-age must be a positive duration")
+)
+
 type TaskWatcherCloser interface {
 	Close() error
 }
@@ -69,6 +127,25 @@ type TaskWatcherHandler interface {
 	CatchTaskDisabled(string)
 }
 
+// TaskOverrunWatcherHandler is implemented by the task watcher handlers which
+// are notified of the task runs exceeding their deadline
+type TaskOverrunWatcherHandler interface {
+	// CatchTaskOverrun is passed the number of overruns of the task so far
+	CatchTaskOverrun(uint)
+}
+
+// TaskRunWatcherHandler is implemented by the task watcher handlers which are
+// passed the record of each run of the task
+type TaskRunWatcherHandler interface {
+	CatchTaskRun(TaskRunRecord)
+}
+
+// TaskCircuitBreakerWatcherHandler is implemented by the task watcher handlers
+// which are notified of the state changes of the circuit breakers of the task
+type TaskCircuitBreakerWatcherHandler interface {
+	CatchCircuitBreaker(TaskStepCircuitBreaker)
+}
+
 func (t TaskState) String() string {
 	return TaskStateLookup[t]
 }
@@ -98,6 +175,23 @@ type Task interface {
 	Option(...TaskOption) TaskOption
 	WMap() *wmap.WorkflowMap
 	Schedule() schedule.Schedule
+	Dependencies() []string
+	SetDependencies([]string)
+	DependencyStates() map[string]TaskDependencyState
+	RunDeadline() time.Duration
+	SetRunDeadline(time.Duration)
+	OverrunPolicy() string
+	SetOverrunPolicy(string)
+	OverrunCount() uint
+	RetryStats() []TaskStepRetryStats
+	CircuitBreakers() []TaskStepCircuitBreaker
+	BatchStats() []TaskStepBatchStats
+	Priority() string
+	SetPriority(string)
+	Labels() map[string]string
+	SetLabels(map[string]string)
+	Buffer() *TaskBuffer
+	SetBuffer(*TaskBuffer)
 }
 
 type TaskOption func(Task) TaskOption
@@ -174,10 +268,201 @@ func SetMaxCollectDuration(d time.Duration) TaskOption {
 	}
 }
 
+// SetDependencies sets the ids of the tasks the task depends on, the task
+// only fires once the most recent run of each of them succeeded.
+func SetDependencies(ids []string) TaskOption {
+	return func(t Task) TaskOption {
+		previous := t.Dependencies()
+		t.SetDependencies(ids)
+		return SetDependencies(previous)
+	}
+}
+
+// SetRunDeadline sets the time a run of the task may take before its overrun
+// policy is applied, zero means no deadline.
+func SetRunDeadline(d time.Duration) TaskOption {
+	return func(t Task) TaskOption {
+		previous := t.RunDeadline()
+		t.SetRunDeadline(d)
+		return SetRunDeadline(previous)
+	}
+}
+
+// SetOverrunPolicy sets the policy applied to the runs of the task exceeding
+// its run deadline (queue, skip or kill).
+func SetOverrunPolicy(p string) TaskOption {
+	return func(t Task) TaskOption {
+		previous := t.OverrunPolicy()
+		t.SetOverrunPolicy(p)
+		return SetOverrunPolicy(previous)
+	}
+}
+
+// SetPriority sets the priority the jobs of the task are dispatched with
+// (low, normal or high).
+func SetPriority(p string) TaskOption {
+	return func(t Task) TaskOption {
+		previous := t.Priority()
+		t.SetPriority(p)
+		return SetPriority(previous)
+	}
+}
+
+// SetLabels sets the labels of the task, the bulk operations select the
+// tasks by their labels.
+func SetLabels(labels map[string]string) TaskOption {
+	return func(t Task) TaskOption {
+		previous := t.Labels()
+		t.SetLabels(labels)
+		return SetLabels(previous)
+	}
+}
+
+// SetBuffer enables the disk-backed buffer of the task, nil disables it.
+func SetBuffer(b *TaskBuffer) TaskOption {
+	return func(t Task) TaskOption {
+		previous := t.Buffer()
+		t.SetBuffer(b)
+		return SetBuffer(previous)
+	}
+}
+
 type TaskErrors interface {
 	Errors() []serror.SnapError
 }
 
+// TaskStepRetryStats holds the retries of the failed jobs of a workflow step
+type TaskStepRetryStats struct {
+	// Step is the plugin of the step, "<type>:<name>:<version>"
+	Step string
+	// Retries is the number of retries of the jobs of the step
+	Retries uint
+	// Recovered is the number of jobs which succeeded after a retry
+	Recovered uint
+	// Exhausted is the number of jobs which failed after their last retry
+	Exhausted uint
+}
+
+// TaskStepBatchStats holds the batches of the metrics published by a workflow step
+type TaskStepBatchStats struct {
+	// Step is the plugin of the step, "<type>:<name>:<version>"
+	Step string
+	// Pending is the number of metrics waiting in the current batch
+	Pending uint
+	// Published is the number of batches published
+	Published uint
+	// Failed is the number of batches which failed to publish
+	Failed uint
+	// Metrics is the number of metrics of the published batches
+	Metrics uint
+}
+
+// The states of the circuit breaker of a workflow step
+const (
+	// CircuitBreakerClosed - the jobs of the step are run
+	CircuitBreakerClosed = "closed"
+	// CircuitBreakerOpen - the step is skipped until its cooldown passed
+	CircuitBreakerOpen = "open"
+	// CircuitBreakerHalfOpen - a single job of the step is tried again
+	CircuitBreakerHalfOpen = "half-open"
+)
+
+// TaskStepCircuitBreaker holds the state of the circuit breaker of a workflow step
+type TaskStepCircuitBreaker struct {
+	// Step is the plugin of the step, "<type>:<name>:<version>"
+	Step string
+	// State is closed, open or half-open
+	State string
+	// Failures is the number of consecutive failed jobs of the step
+	Failures uint
+	// Trips is the number of times the breaker opened
+	Trips uint
+	// OpenedAt is the time the breaker last opened, zero if it never opened
+	OpenedAt time.Time
+}
+
+// TaskBuffer holds the caps and the backlog of the disk-backed buffer of a
+// task, which stores the metrics its publishers fail to publish and forwards
+// them, oldest first, once the publishers recover
+type TaskBuffer struct {
+	// MaxSize is the size the buffered batches may take in bytes, zero for the default
+	MaxSize int64
+	// MaxAge is the time a batch stays in the buffer, zero for the default
+	MaxAge time.Duration
+	// Batches is the number of batches buffered
+	Batches int
+	// Size is the size the buffered batches take in bytes
+	Size int64
+}
+
+// NewTaskBuffer returns the buffer of a task holding up to maxSize megabytes
+// of batches for up to maxAge, zero values keep the defaults
+func NewTaskBuffer(maxSize int64, maxAge string) (*TaskBuffer, error) {
+	if maxSize < 0 {
+		return nil, ErrInvalidTaskBufferSize
+	}
+	b := &TaskBuffer{MaxSize: maxSize * 1024 * 1024}
+	if maxAge != "" {
+		d, err := time.ParseDuration(maxAge)
+		if err != nil || d <= 0 {
+			return nil, ErrInvalidTaskBufferAge
+		}
+		b.MaxAge = d
+	}
+	return b, nil
+}
+
+// TaskBufferRequest is the buffer section of a task manifest
+type TaskBufferRequest struct {
+	// MaxSize is in megabytes
+	MaxSize int64  `json:"max-size,omitempty"`
+	MaxAge  string `json:"max-age,omitempty"`
+}
+
+// TaskRunRecord holds the results of a scheduled run of a task workflow
+type TaskRunRecord struct {
+	// Start is the time the run started at
+	Start time.Time
+	// Duration is the time the run took
+	Duration time.Duration
+	// Steps holds the results of the collect step and of each process and
+	// publish step of the run, in the order they ended
+	Steps []TaskRunStep
+}
+
+// TaskRunStep holds the result of a step of a task run
+type TaskRunStep struct {
+	// Step is "collector" or the plugin of the step, "<type>:<name>:<version>"
+	Step string
+	// Metrics is the number of metrics collected, returned by a processor or
+	// passed to a publisher
+	Metrics int
+	// Errors are the errors of the step
+	Errors []string
+}
+
+// TaskRun holds the metrics of a workflow run a single time
+type TaskRun struct {
+	// Collected holds the metrics collected by the workflow
+	Collected []Metric
+	// Published holds the metrics passed to each publisher of the workflow
+	Published []TaskRunPublisher
+}
+
+// TaskRunPublisher holds the metrics passed to a publisher in a task run
+type TaskRunPublisher struct {
+	Name    string
+	Version int
+	Metrics []Metric
+}
+
+// TaskRunner runs a workflow any number of times without creating a task, the
+// plugins of the workflow stay subscribed until the runner is closed
+type TaskRunner interface {
+	Run() (*TaskRun, []serror.SnapError)
+	Close()
+}
+
 type TaskCreationRequest struct {
 	Name               string            `json:"name"`
 	Version            int               `json:"version"`
@@ -188,6 +473,13 @@ type TaskCreationRequest struct {
 	MaxFailures        int               `json:"max-failures"`
 	MaxCollectDuration string            `json:"max-collect-duration"`
 	MaxMetricsBuffer   int64             `json:"max-metrics-buffer"`
+	DependsOn          []string          `json:"depends-on,omitempty"`
+	RunDeadline        string            `json:"run-deadline,omitempty"`
+	OverrunPolicy      string            `json:"overrun-policy,omitempty"`
+	Priority           string            `json:"priority,omitempty"`
+	Labels             map[string]string `json:"labels,omitempty"`
+	// Buffer enables the disk-backed buffer of the task
+	Buffer *TaskBufferRequest `json:"buffer,omitempty"`
 }
 
 func (tr *TaskCreationRequest) UnmarshalJSON(data []byte) error {
@@ -233,6 +525,30 @@ func (tr *TaskCreationRequest) UnmarshalJSON(data []byte) error {
 			if err := json.Unmarshal(v, &(tr.MaxMetricsBuffer)); err != nil {
 				return fmt.Errorf("%v (while parsing 'max-metrics-buffer')", err)
 			}
+		case "depends-on":
+			if err := json.Unmarshal(v, &(tr.DependsOn)); err != nil {
+				return fmt.Errorf("%v (while parsing 'depends-on')", err)
+			}
+		case "run-deadline":
+			if err := json.Unmarshal(v, &(tr.RunDeadline)); err != nil {
+				return fmt.Errorf("%v (while parsing 'run-deadline')", err)
+			}
+		case "overrun-policy":
+			if err := json.Unmarshal(v, &(tr.OverrunPolicy)); err != nil {
+				return fmt.Errorf("%v (while parsing 'overrun-policy')", err)
+			}
+		case "priority":
+			if err := json.Unmarshal(v, &(tr.Priority)); err != nil {
+				return fmt.Errorf("%v (while parsing 'priority')", err)
+			}
+		case "labels":
+			if err := json.Unmarshal(v, &(tr.Labels)); err != nil {
+				return fmt.Errorf("%v (while parsing 'labels')", err)
+			}
+		case "buffer":
+			if err := json.Unmarshal(v, &(tr.Buffer)); err != nil {
+				return fmt.Errorf("%v (while parsing 'buffer')", err)
+			}
 		default:
 			return fmt.Errorf("Unrecognized key '%v' in task creation request", k)
 		}
@@ -300,6 +616,49 @@ func CreateTaskFromContent(body io.ReadCloser,
 		opts = append(opts, SetMaxCollectDuration(dl))
 	}
 
+	if len(tr.DependsOn) > 0 {
+		opts = append(opts, SetDependencies(tr.DependsOn))
+	}
+
+	if tr.RunDeadline != "" {
+		dl, err := time.ParseDuration(tr.RunDeadline)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, SetRunDeadline(dl))
+	}
+
+	switch tr.OverrunPolicy {
+	case "":
+	case TaskOverrunQueue, TaskOverrunSkip, TaskOverrunKill:
+		opts = append(opts, SetOverrunPolicy(tr.OverrunPolicy))
+	default:
+		return nil, ErrInvalidTaskOverrunPolicy
+	}
+
+	switch tr.Priority {
+	case "":
+	case TaskPriorityLow, TaskPriorityNormal, TaskPriorityHigh:
+		opts = append(opts, SetPriority(tr.Priority))
+	default:
+		return nil, ErrInvalidTaskPriority
+	}
+
+	if len(tr.Labels) > 0 {
+		if err := ValidateLabels(tr.Labels); err != nil {
+			return nil, err
+		}
+		opts = append(opts, SetLabels(tr.Labels))
+	}
+
+	if tr.Buffer != nil {
+		b, err := NewTaskBuffer(tr.Buffer.MaxSize, tr.Buffer.MaxAge)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, SetBuffer(b))
+	}
+
 	if fp == nil {
 		return nil, errors.New("Missing workflow creation routine")
 	}
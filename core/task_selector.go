@@ -0,0 +1,132 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/intelsdi-x/snap/core/serror"
+)
+
+// The actions applied to the tasks selected by a bulk operation
+const (
+	TaskBulkStart  = "start"
+	TaskBulkStop   = "stop"
+	TaskBulkRemove = "remove"
+)
+
+var (
+	// ErrEmptyTaskSelector - error message when a bulk operation doesn't select the tasks by name or label
+	ErrEmptyTaskSelector = errors.New("tasks must be selected by a name pattern or labels")
+	// ErrInvalidTaskBulkAction - error message when the action of a bulk operation is unknown
+	ErrInvalidTaskBulkAction = fmt.Errorf("bulk action must be one of %s, %s or %s", TaskBulkStart, TaskBulkStop, TaskBulkRemove)
+	// ErrInvalidLabelSelector - error message when a label selector isn't a list of key=value pairs
+	ErrInvalidLabelSelector = errors.New("labels must be a comma separated list of key=value pairs")
+	// ErrTaskBulkAborted - error message when a bulk operation is not applied to any task because it can't be applied to some of them
+	ErrTaskBulkAborted = errors.New("bulk operation aborted, it can't be applied to all the selected tasks")
+	// ErrTaskBulkRolledBack - error message for a task whose change was undone because the bulk operation failed on another task
+	ErrTaskBulkRolledBack = errors.New("rolled back, the bulk operation failed on another task")
+	// ErrInvalidTaskLabel - error message when a label of a task can't be selected
+	ErrInvalidTaskLabel = errors.New("label keys must not be empty and labels must not contain ',' or '='")
+)
+
+// TaskSelector selects the tasks whose name matches a pattern and which have
+// all the given labels
+type TaskSelector struct {
+	// Name is a shell pattern (e.g. web-*) the name of the tasks match, any
+	// name when empty
+	Name string
+	// Labels are the labels the tasks have, any labels when empty
+	Labels map[string]string
+}
+
+// Validate returns an error when the selector would select all the tasks or
+// its name pattern is malformed
+func (s TaskSelector) Validate() error {
+	if s.Name == "" && len(s.Labels) == 0 {
+		return ErrEmptyTaskSelector
+	}
+	if _, err := path.Match(s.Name, ""); err != nil {
+		return fmt.Errorf("invalid name pattern `%s`: %v", s.Name, err)
+	}
+	return nil
+}
+
+// Matches returns true when the task is selected
+func (s TaskSelector) Matches(t Task) bool {
+	if s.Name != "" {
+		if ok, _ := path.Match(s.Name, t.GetName()); !ok {
+			return false
+		}
+	}
+	return MatchLabels(s.Labels, t.Labels())
+}
+
+// MatchLabels returns true when the labels hold all the labels of the
+// selector
+func MatchLabels(selector, labels map[string]string) bool {
+	for k, v := range selector {
+		if lv, ok := labels[k]; !ok || lv != v {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateLabels returns an error when a label couldn't be selected by a
+// label selector
+func ValidateLabels(labels map[string]string) error {
+	for k, v := range labels {
+		if strings.TrimSpace(k) == "" || strings.ContainsAny(k, ",=") || strings.ContainsAny(v, ",=") {
+			return ErrInvalidTaskLabel
+		}
+	}
+	return nil
+}
+
+// ParseLabelSelector returns the labels of a selector in the form key=value[,key=value]
+func ParseLabelSelector(s string) (map[string]string, error) {
+	labels := map[string]string{}
+	if strings.TrimSpace(s) == "" {
+		return labels, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, ErrInvalidLabelSelector
+		}
+		labels[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return labels, nil
+}
+
+// TaskBulkResult holds the result of a bulk operation for one of the tasks it
+// selected
+type TaskBulkResult struct {
+	ID   string
+	Name string
+	// State is the state of the task once the operation was applied
+	State TaskState
+	// Error is set when the operation can't be or wasn't applied to the task
+	Error serror.SnapError
+}
@@ -22,7 +22,12 @@ package tribe_event
 import "github.com/intelsdi-x/snap/core"
 
 const (
-	PluginAdded = "Tribe.PluginAdded"
+	PluginAdded  = "Tribe.PluginAdded"
+	MemberJoined = "Tribe.MemberJoined"
+	MemberLeft   = "Tribe.MemberLeft"
+	// IntentReceived is emitted the first time the local member handles an
+	// intent of the tribe
+	IntentReceived = "Tribe.IntentReceived"
 )
 
 type AddPluginEvent struct {
@@ -39,3 +44,41 @@ type AddPluginEvent struct {
 func (e AddPluginEvent) Namespace() string {
 	return PluginAdded
 }
+
+// MemberJoinedEvent is emitted when a member joins the tribe
+type MemberJoinedEvent struct {
+	Name string
+	Addr string
+}
+
+func (e MemberJoinedEvent) Namespace() string {
+	return MemberJoined
+}
+
+// MemberLeftEvent is emitted when a member leaves the tribe
+type MemberLeftEvent struct {
+	Name string
+	Addr string
+}
+
+func (e MemberLeftEvent) Namespace() string {
+	return MemberLeft
+}
+
+// IntentReceivedEvent is emitted when the local member handles an intent
+// gossiped to the tribe or created locally, the first time it's received
+type IntentReceivedEvent struct {
+	// Type is the type of the intent, e.g. "Add plugin"
+	Type  string
+	UUID  string
+	LTime uint64
+	// Agreement is the agreement the intent changes, empty for the intents
+	// of the whole tribe
+	Agreement string
+	// Subject is the plugin, task, member or key the intent is about
+	Subject string
+}
+
+func (e IntentReceivedEvent) Namespace() string {
+	return IntentReceived
+}
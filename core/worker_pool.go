@@ -0,0 +1,42 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import "time"
+
+// WorkerPoolStats holds the state of a worker pool of the scheduler
+type WorkerPoolStats struct {
+	// Pool is the type of the jobs run by the pool: collect, process or publish
+	Pool string
+	// Workers is the number of workers of the pool
+	Workers uint
+	// MinWorkers and MaxWorkers bound the number of workers of an autoscaled
+	// pool, they're equal when the pool isn't autoscaled
+	MinWorkers uint
+	MaxWorkers uint
+	Autoscale  bool
+	// QueueDepth is the number of jobs waiting in the queue of the pool
+	QueueDepth uint
+	// QueueLength is the maximum number of jobs of the queue, 0 means no limit
+	QueueLength uint
+	// QueueLatency is the average time the jobs recently dispatched waited
+	// for a worker
+	QueueLatency time.Duration
+}
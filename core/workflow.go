@@ -21,6 +21,12 @@ package core
 
 type WorkflowState int
 
+// WorkflowNodeConfigKey is the config item of the process jobs identifying
+// the process node of the task workflow they come from, e.g. "/0/1" for the
+// second node under the first one. It's only passed to the built-in
+// processors which keep state per node, never to the processor plugins.
+const WorkflowNodeConfigKey = "_workflow_node"
+
 const (
 	WorkflowStopped WorkflowState = iota
 	WorkflowStarted
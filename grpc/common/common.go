@@ -46,6 +46,9 @@ func ToMetric(co core.Metric) *Metric {
 			Sec:  time.Now().Unix(),
 			Nsec: int64(time.Now().Nanosecond()),
 		},
+		Unit:        co.Unit(),
+		Description: co.Description(),
+		DataType:    co.DataType(),
 	}
 	if co.Config() != nil {
 		cm.Config = ConfigToConfigMap(co.Config())
@@ -119,6 +122,7 @@ type metric struct {
 	tags               map[string]string
 	description        string
 	unit               string
+	dataType           string
 }
 
 func (m *metric) Namespace() core.Namespace     { return m.namespace }
@@ -130,6 +134,7 @@ func (m *metric) LastAdvertisedTime() time.Time { return m.lastAdvertisedTime }
 func (m *metric) Timestamp() time.Time          { return m.timeStamp }
 func (m *metric) Description() string           { return m.description }
 func (m *metric) Unit() string                  { return m.unit }
+func (m *metric) DataType() string              { return m.dataType }
 
 // Convert common.Metric to core.Metric
 func ToCoreMetric(mt *Metric) core.Metric {
@@ -150,6 +155,7 @@ func ToCoreMetric(mt *Metric) core.Metric {
 		config:             ConfigMapToConfig(mt.Config),
 		description:        mt.Description,
 		unit:               mt.Unit,
+		dataType:           mt.DataType,
 	}
 
 	switch mt.Data.(type) {
@@ -411,6 +417,9 @@ func ToConfigMap(cv map[string]ctypes.ConfigValue) *ConfigMap {
 			newConfig.StringMap[k] = v.(ctypes.ConfigValueStr).Value
 		case "bool":
 			newConfig.BoolMap[k] = v.(ctypes.ConfigValueBool).Value
+		case "duration", "bytesize":
+			// sent in their string form, e.g. "30s" or "512MB"
+			newConfig.StringMap[k] = v.(fmt.Stringer).String()
 		}
 	}
 	return newConfig
@@ -110,6 +110,7 @@ type Metric struct {
 	Timestamp          *Time               `protobuf:"bytes,6,opt,name=Timestamp" json:"Timestamp,omitempty"`
 	Unit               string              `protobuf:"bytes,7,opt,name=Unit" json:"Unit,omitempty"`
 	Description        string              `protobuf:"bytes,8,opt,name=Description" json:"Description,omitempty"`
+	DataType           string              `protobuf:"bytes,18,opt,name=DataType" json:"DataType,omitempty"`
 	// Types that are valid to be assigned to Data:
 	//	*Metric_StringData
 	//	*Metric_Float32Data
@@ -544,54 +545,54 @@ func init() {
 }
 
 var fileDescriptor0 = []byte{
-	// 774 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xac, 0x95, 0xd1, 0x4e, 0xdb, 0x30,
-	0x14, 0x86, 0x9b, 0x26, 0x4d, 0x9b, 0x93, 0xc2, 0x8a, 0xb5, 0x8b, 0xa8, 0x12, 0x23, 0x64, 0x37,
+	// 780 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xac, 0x95, 0xdf, 0x4e, 0xdb, 0x30,
+	0x14, 0xc6, 0x9b, 0x26, 0x4d, 0x9b, 0x93, 0xc2, 0x8a, 0xb5, 0x8b, 0xa8, 0x12, 0x23, 0x64, 0x37,
 	0xd9, 0xb4, 0xb5, 0x1a, 0x30, 0xc6, 0x00, 0x21, 0x8d, 0x51, 0xd4, 0x49, 0x30, 0x4d, 0x81, 0x71,
-	0x39, 0xe4, 0xb6, 0xa6, 0x8b, 0x96, 0x38, 0x51, 0xe2, 0x22, 0xfa, 0x04, 0x7b, 0xa2, 0x3d, 0xc4,
-	0x9e, 0x65, 0x2f, 0x31, 0xd9, 0x4e, 0xd2, 0x94, 0x32, 0x55, 0x48, 0xbb, 0x81, 0xe3, 0xdf, 0xdf,
-	0x7f, 0x72, 0x4e, 0x7c, 0x9c, 0xc2, 0xf6, 0xd8, 0x67, 0xdf, 0x27, 0x83, 0xce, 0x30, 0x0a, 0xbb,
-	0x3e, 0x65, 0x24, 0x48, 0x47, 0xfe, 0xeb, 0xbb, 0x6e, 0x4a, 0x71, 0xdc, 0x1d, 0x27, 0xf1, 0xb0,
-	0x3b, 0x8c, 0xc2, 0x30, 0xa2, 0xd9, 0xbf, 0x4e, 0x9c, 0x44, 0x2c, 0x42, 0xba, 0x5c, 0x39, 0xaf,
-	0x40, 0xbb, 0xf4, 0x43, 0x82, 0x5a, 0xa0, 0xa6, 0x64, 0x68, 0x29, 0xb6, 0xe2, 0xaa, 0x1e, 0x0f,
-	0x11, 0x02, 0x8d, 0x72, 0xa9, 0x2a, 0x24, 0x11, 0x3b, 0x75, 0xa8, 0xf5, 0xc2, 0x98, 0x4d, 0x9d,
-	0x5f, 0x0a, 0x18, 0x17, 0x14, 0xc7, 0xbd, 0x24, 0x89, 0x12, 0xb4, 0x09, 0x4d, 0xc2, 0x83, 0xeb,
-	0x94, 0x25, 0x3e, 0x1d, 0x8b, 0x2c, 0x86, 0x67, 0x0a, 0xed, 0x42, 0x48, 0xa8, 0x97, 0x23, 0x37,
-	0x3e, 0x09, 0x46, 0xa9, 0x55, 0xb5, 0x55, 0xd7, 0xdc, 0x72, 0x3a, 0x59, 0x51, 0x45, 0xae, 0x8e,
-	0xf8, 0x7b, 0x2a, 0xa0, 0x1e, 0x65, 0xc9, 0x34, 0x4b, 0x23, 0x95, 0xf6, 0x11, 0xb4, 0xee, 0x03,
-	0xbc, 0xf4, 0x1f, 0x64, 0x9a, 0x3d, 0x94, 0x87, 0xe8, 0x29, 0xd4, 0x6e, 0x71, 0x30, 0x21, 0xa2,
-	0x76, 0xc3, 0x93, 0x8b, 0xfd, 0xea, 0x9e, 0xe2, 0xbc, 0x81, 0xda, 0x19, 0x1e, 0x90, 0x80, 0x23,
-	0x3e, 0x1d, 0x91, 0x3b, 0x61, 0xd3, 0x3c, 0xb9, 0x10, 0x3d, 0xe3, 0x30, 0xf7, 0x89, 0xd8, 0xf9,
-	0x5d, 0x03, 0xfd, 0x9c, 0xb0, 0xc4, 0x1f, 0xa2, 0x5d, 0x30, 0x3e, 0xe3, 0x90, 0xa4, 0x31, 0x1e,
-	0x12, 0x4b, 0x11, 0x1d, 0x58, 0x79, 0x07, 0xc5, 0x46, 0x2f, 0x20, 0x21, 0xa1, 0xcc, 0x9b, 0xa1,
-	0xc8, 0x82, 0xfa, 0x15, 0x49, 0x52, 0x3f, 0xa2, 0xd9, 0xdb, 0xcc, 0x97, 0xe8, 0x05, 0xe8, 0x1f,
-	0x23, 0x7a, 0xe3, 0x8f, 0x2d, 0xd5, 0x56, 0x5c, 0x73, 0x6b, 0x2d, 0x4f, 0x27, 0xd5, 0x73, 0x1c,
-	0x7b, 0x19, 0x80, 0x0e, 0x01, 0x9d, 0xe1, 0x94, 0x7d, 0x18, 0xdd, 0x92, 0x84, 0xf9, 0x29, 0x19,
-	0xf1, 0x73, 0xb3, 0x34, 0x61, 0x6b, 0xe6, 0x36, 0xae, 0x79, 0x0f, 0x70, 0x88, 0x9f, 0x33, 0x1e,
-	0xa7, 0x56, 0x6d, 0xbe, 0x6a, 0xd9, 0x58, 0x87, 0x6f, 0xc9, 0xb7, 0x2d, 0x28, 0xf4, 0x12, 0x0c,
-	0xee, 0x4a, 0x19, 0x0e, 0x63, 0x4b, 0x7f, 0xe0, 0x11, 0xb3, 0x6d, 0xfe, 0xce, 0xbe, 0x52, 0x9f,
-	0x59, 0x75, 0xf9, 0xce, 0x78, 0x8c, 0x6c, 0x30, 0x4f, 0x48, 0x3a, 0x4c, 0xfc, 0x98, 0xf1, 0xa6,
-	0x1b, 0x72, 0x1e, 0x4a, 0x12, 0xda, 0x04, 0x53, 0x0e, 0xcb, 0xf5, 0x08, 0x33, 0x6c, 0x19, 0x9c,
-	0xe8, 0x57, 0x3c, 0x90, 0xe2, 0x09, 0x66, 0x18, 0x3d, 0x87, 0xe6, 0x4d, 0x10, 0x61, 0xb6, 0xbd,
-	0x25, 0x19, 0xb0, 0x15, 0xb7, 0xda, 0xaf, 0x78, 0x66, 0xa6, 0xce, 0x41, 0xbb, 0x3b, 0x12, 0x32,
-	0x6d, 0xc5, 0x55, 0x0a, 0x68, 0x77, 0x47, 0x40, 0x1b, 0x00, 0x3e, 0x2d, 0xf2, 0x34, 0x6d, 0xc5,
-	0xad, 0xf5, 0x2b, 0x9e, 0x21, 0xb4, 0x12, 0x90, 0xe7, 0x58, 0xe1, 0x67, 0x94, 0x01, 0xb3, 0x0c,
-	0x83, 0x29, 0x23, 0xa9, 0x04, 0x56, 0x6d, 0xc5, 0x6d, 0x72, 0x40, 0x68, 0x02, 0x58, 0x07, 0x63,
-	0x10, 0x45, 0x81, 0xdc, 0x7f, 0x62, 0x2b, 0x6e, 0xa3, 0x5f, 0xf1, 0x1a, 0x5c, 0x12, 0xdb, 0x9b,
-	0x60, 0x4e, 0x4a, 0x25, 0xb4, 0x6c, 0xc5, 0x5d, 0xe1, 0xed, 0x4e, 0x66, 0x35, 0x64, 0x48, 0x5e,
-	0xc4, 0x1a, 0x9f, 0xcb, 0x1c, 0x91, 0x55, 0xb4, 0xdf, 0x81, 0x51, 0x9c, 0xd4, 0x63, 0xc6, 0xfe,
-	0x58, 0x07, 0x8d, 0x27, 0x75, 0xbe, 0x41, 0xeb, 0xfe, 0x9c, 0x72, 0xd7, 0x95, 0x70, 0xc9, 0x4c,
-	0x72, 0x71, 0xff, 0x04, 0xab, 0x8b, 0x27, 0x88, 0x40, 0xe3, 0xb9, 0xc4, 0xe0, 0x1a, 0x9e, 0x88,
-	0x9d, 0x9f, 0x0a, 0xb4, 0x2e, 0x26, 0x03, 0x0e, 0x0d, 0xc8, 0xe8, 0x4b, 0x30, 0x19, 0xfb, 0x14,
-	0xb5, 0xa1, 0x71, 0x39, 0x8d, 0x89, 0x80, 0xe5, 0x33, 0x8a, 0x75, 0x91, 0xa4, 0x3a, 0x4b, 0x52,
-	0xbe, 0x2d, 0xea, 0xbf, 0x6e, 0x8b, 0xb6, 0xe4, 0xb6, 0x38, 0x7f, 0x54, 0x30, 0x0a, 0x15, 0xbd,
-	0x05, 0xfd, 0x13, 0x65, 0xe7, 0x38, 0xce, 0x6e, 0xed, 0xfa, 0x82, 0xb1, 0x23, 0xf7, 0xe5, 0x25,
-	0xc8, 0x60, 0x74, 0x04, 0x86, 0xfc, 0x7c, 0x71, 0xa7, 0xfc, 0x62, 0xd9, 0x8b, 0xce, 0x02, 0x91,
-	0xe6, 0x99, 0x05, 0x1d, 0x40, 0xe3, 0x94, 0x8f, 0x21, 0xb7, 0xab, 0xc2, 0xbe, 0xb1, 0x68, 0xcf,
-	0x09, 0xe9, 0x2e, 0x0c, 0x68, 0x0f, 0xea, 0xc7, 0x51, 0x14, 0x70, 0xaf, 0x26, 0xbc, 0xcf, 0x16,
-	0xbd, 0x19, 0x20, 0xad, 0x39, 0xde, 0x7e, 0x0f, 0x66, 0xa9, 0x9b, 0x65, 0x83, 0xa2, 0x96, 0x06,
-	0xa5, 0x7d, 0x08, 0xab, 0xf3, 0xed, 0x3c, 0x66, 0xcc, 0xda, 0x07, 0xb0, 0x32, 0xd7, 0xcd, 0x32,
-	0xb3, 0x52, 0x36, 0xef, 0x43, 0xb3, 0xdc, 0xce, 0x32, 0x6f, 0xa3, 0xfc, 0x59, 0xf7, 0x40, 0xff,
-	0xdf, 0xc3, 0x36, 0xd0, 0xc5, 0x0f, 0xe5, 0xf6, 0xdf, 0x00, 0x00, 0x00, 0xff, 0xff, 0xd1, 0xc9,
-	0x60, 0x28, 0x5f, 0x07, 0x00, 0x00,
+	0x39, 0xe4, 0xb6, 0xa6, 0xb3, 0x96, 0x7f, 0x4a, 0x5c, 0x44, 0x9f, 0x60, 0x4f, 0xb4, 0x47, 0xda,
+	0xd5, 0x5e, 0x62, 0xb2, 0x9d, 0xa4, 0x29, 0x65, 0xaa, 0x90, 0x76, 0x03, 0xf6, 0x77, 0x7e, 0xdf,
+	0xc9, 0x39, 0xf1, 0x71, 0x0a, 0xdb, 0x63, 0xca, 0xbe, 0x4f, 0x06, 0x9d, 0x61, 0x14, 0x74, 0x69,
+	0xc8, 0x88, 0x9f, 0x8e, 0xe8, 0xeb, 0xbb, 0x6e, 0x1a, 0xe2, 0xb8, 0x3b, 0x4e, 0xe2, 0x61, 0x77,
+	0x18, 0x05, 0x41, 0x14, 0x66, 0xff, 0x3a, 0x71, 0x12, 0xb1, 0x08, 0xe9, 0x72, 0xe7, 0xbc, 0x02,
+	0xed, 0x92, 0x06, 0x04, 0xb5, 0x40, 0x4d, 0xc9, 0xd0, 0x52, 0x6c, 0xc5, 0x55, 0x3d, 0xbe, 0x44,
+	0x08, 0xb4, 0x90, 0x4b, 0x55, 0x21, 0x89, 0xb5, 0x53, 0x87, 0x5a, 0x2f, 0x88, 0xd9, 0xd4, 0xf9,
+	0xa5, 0x80, 0x71, 0x11, 0xe2, 0xb8, 0x97, 0x24, 0x51, 0x82, 0x36, 0xa1, 0x49, 0xf8, 0xe2, 0x3a,
+	0x65, 0x09, 0x0d, 0xc7, 0x22, 0x8b, 0xe1, 0x99, 0x42, 0xbb, 0x10, 0x12, 0xea, 0xe5, 0xc8, 0x0d,
+	0x25, 0xfe, 0x28, 0xb5, 0xaa, 0xb6, 0xea, 0x9a, 0x5b, 0x4e, 0x27, 0x2b, 0xaa, 0xc8, 0xd5, 0x11,
+	0x7f, 0x4f, 0x05, 0xd4, 0x0b, 0x59, 0x32, 0xcd, 0xd2, 0x48, 0xa5, 0x7d, 0x04, 0xad, 0xfb, 0x00,
+	0x2f, 0xfd, 0x07, 0x99, 0x66, 0x0f, 0xe5, 0x4b, 0xf4, 0x14, 0x6a, 0xb7, 0xd8, 0x9f, 0x10, 0x51,
+	0xbb, 0xe1, 0xc9, 0xcd, 0x7e, 0x75, 0x4f, 0x71, 0xde, 0x40, 0xed, 0x0c, 0x0f, 0x88, 0xcf, 0x11,
+	0x1a, 0x8e, 0xc8, 0x9d, 0xb0, 0x69, 0x9e, 0xdc, 0x88, 0x9e, 0x71, 0x90, 0xfb, 0xc4, 0xda, 0xf9,
+	0x5d, 0x03, 0xfd, 0x9c, 0xb0, 0x84, 0x0e, 0xd1, 0x2e, 0x18, 0x9f, 0x71, 0x40, 0xd2, 0x18, 0x0f,
+	0x89, 0xa5, 0x88, 0x0e, 0xac, 0xbc, 0x83, 0x22, 0xd0, 0xf3, 0x49, 0x40, 0x42, 0xe6, 0xcd, 0x50,
+	0x64, 0x41, 0xfd, 0x8a, 0x24, 0x29, 0x8d, 0xc2, 0xec, 0x6d, 0xe6, 0x5b, 0xf4, 0x02, 0xf4, 0x8f,
+	0x51, 0x78, 0x43, 0xc7, 0x96, 0x6a, 0x2b, 0xae, 0xb9, 0xb5, 0x96, 0xa7, 0x93, 0xea, 0x39, 0x8e,
+	0xbd, 0x0c, 0x40, 0x87, 0x80, 0xce, 0x70, 0xca, 0x3e, 0x8c, 0x6e, 0x49, 0xc2, 0x68, 0x4a, 0x46,
+	0xfc, 0xdc, 0x2c, 0x4d, 0xd8, 0x9a, 0xb9, 0x8d, 0x6b, 0xde, 0x03, 0x1c, 0xe2, 0xe7, 0x8c, 0xc7,
+	0xa9, 0x55, 0x9b, 0xaf, 0x5a, 0x36, 0xd6, 0xe1, 0x21, 0xf9, 0xb6, 0x05, 0x85, 0x5e, 0x82, 0xc1,
+	0x5d, 0x29, 0xc3, 0x41, 0x6c, 0xe9, 0x0f, 0x3c, 0x62, 0x16, 0xe6, 0xef, 0xec, 0x6b, 0x48, 0x99,
+	0x55, 0x97, 0xef, 0x8c, 0xaf, 0x91, 0x0d, 0xe6, 0x09, 0x49, 0x87, 0x09, 0x8d, 0x19, 0x6f, 0xba,
+	0x21, 0xe7, 0xa1, 0x24, 0xa1, 0x36, 0x34, 0x4e, 0x30, 0xc3, 0x97, 0xd3, 0x98, 0x58, 0x48, 0x84,
+	0x8b, 0x3d, 0xda, 0x04, 0x53, 0x0e, 0xd2, 0xf5, 0x08, 0x33, 0x6c, 0x19, 0x3c, 0xdc, 0xaf, 0x78,
+	0x20, 0x45, 0x8e, 0xa1, 0xe7, 0xd0, 0xbc, 0xf1, 0x23, 0xcc, 0xb6, 0xb7, 0x24, 0x03, 0xb6, 0xe2,
+	0x56, 0xfb, 0x15, 0xcf, 0xcc, 0xd4, 0x39, 0x68, 0x77, 0x47, 0x42, 0xa6, 0xad, 0xb8, 0x4a, 0x01,
+	0xed, 0xee, 0x08, 0x68, 0x03, 0x80, 0x86, 0x45, 0x9e, 0xa6, 0xad, 0xb8, 0xb5, 0x7e, 0xc5, 0x33,
+	0x84, 0x56, 0x02, 0xf2, 0x1c, 0x2b, 0xfc, 0xfc, 0x32, 0x60, 0x96, 0x61, 0x30, 0x65, 0x24, 0x95,
+	0xc0, 0xaa, 0xad, 0xb8, 0x4d, 0x0e, 0x08, 0x4d, 0x00, 0xeb, 0x60, 0x0c, 0xa2, 0xc8, 0x97, 0xf1,
+	0x27, 0xb6, 0xe2, 0x36, 0xfa, 0x15, 0xaf, 0xc1, 0x25, 0x11, 0xde, 0x04, 0x73, 0x52, 0x2a, 0xa1,
+	0x65, 0x2b, 0xee, 0x0a, 0x6f, 0x77, 0x32, 0xab, 0x21, 0x43, 0xf2, 0x22, 0xd6, 0xf8, 0xcc, 0xe6,
+	0x88, 0xac, 0xa2, 0xfd, 0x0e, 0x8c, 0xe2, 0x14, 0x1f, 0x73, 0x25, 0x8e, 0x75, 0xd0, 0x78, 0x52,
+	0xe7, 0x1b, 0xb4, 0xee, 0xcf, 0x30, 0x77, 0x5d, 0x09, 0x97, 0xcc, 0x24, 0x37, 0xf7, 0x4f, 0xb7,
+	0xba, 0x78, 0xba, 0x08, 0x34, 0x9e, 0x4b, 0x0c, 0xb5, 0xe1, 0x89, 0xb5, 0xf3, 0x53, 0x81, 0xd6,
+	0xc5, 0x64, 0xc0, 0xa1, 0x01, 0x19, 0x7d, 0xf1, 0x27, 0x63, 0x2a, 0xc6, 0x80, 0x1f, 0xb9, 0x80,
+	0xe5, 0x33, 0x8a, 0x7d, 0x91, 0xa4, 0x3a, 0x4b, 0x52, 0xbe, 0x49, 0xea, 0xbf, 0x6e, 0x92, 0xb6,
+	0xe4, 0x26, 0x39, 0x7f, 0x54, 0x30, 0x0a, 0x15, 0xbd, 0x05, 0xfd, 0x53, 0xc8, 0xce, 0x71, 0x9c,
+	0xdd, 0xe8, 0xf5, 0x05, 0x63, 0x47, 0xc6, 0xe5, 0x05, 0xc9, 0x60, 0x74, 0x04, 0x86, 0xfc, 0xb4,
+	0x71, 0xa7, 0xfc, 0x9a, 0xd9, 0x8b, 0xce, 0x02, 0x91, 0xe6, 0x99, 0x05, 0x1d, 0x40, 0xe3, 0x94,
+	0x8f, 0x21, 0xb7, 0xab, 0xc2, 0xbe, 0xb1, 0x68, 0xcf, 0x09, 0xe9, 0x2e, 0x0c, 0x68, 0x0f, 0xea,
+	0xc7, 0x51, 0xe4, 0x73, 0xaf, 0x26, 0xbc, 0xcf, 0x16, 0xbd, 0x19, 0x20, 0xad, 0x39, 0xde, 0x7e,
+	0x0f, 0x66, 0xa9, 0x9b, 0x65, 0x83, 0xa2, 0x96, 0x06, 0xa5, 0x7d, 0x08, 0xab, 0xf3, 0xed, 0x3c,
+	0x66, 0xcc, 0xda, 0x07, 0xb0, 0x32, 0xd7, 0xcd, 0x32, 0xb3, 0x52, 0x36, 0xef, 0x43, 0xb3, 0xdc,
+	0xce, 0x32, 0x6f, 0xa3, 0xfc, 0xc9, 0xf7, 0x40, 0xff, 0xdf, 0xc3, 0x36, 0xd0, 0xc5, 0x8f, 0xe8,
+	0xf6, 0xdf, 0x01, 0x00, 0x60, 0x97, 0x4f, 0x5d, 0x7b, 0x07, 0x00, 0x00,
 }
@@ -0,0 +1,261 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli"
+
+	"github.com/intelsdi-x/snap/pkg/cfgremote"
+)
+
+// the layers of the configuration of snapteld, the settings of a layer
+// override the ones of the previous layers
+const (
+	layerDefault = "default"
+	layerFile    = "file"
+	layerEnv     = "env"
+	layerFlag    = "flag"
+	layerRemote  = "remote"
+	// layerRuntime is the layer of the settings changed through
+	// PUT /v1/config, they're overridden when the config file is reloaded
+	layerRuntime = "runtime"
+)
+
+// configFlags are the command line flags of snapteld with the names of the
+// ones set by environment variables and of the ones given on the command line
+type configFlags struct {
+	ctx runtimeFlagsContext
+	env map[string]bool
+	cmd map[string]bool
+}
+
+func newConfigFlags(ctx runtimeFlagsContext, flags []cli.Flag, args []string) configFlags {
+	given := map[string]bool{}
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		name := strings.TrimLeft(arg, "-")
+		if i := strings.Index(name, "="); i >= 0 {
+			name = name[:i]
+		}
+		given[name] = true
+	}
+	f := configFlags{ctx: ctx, env: map[string]bool{}, cmd: map[string]bool{}}
+	for _, flag := range flags {
+		var names, envVars string
+		switch fl := flag.(type) {
+		case cli.StringFlag:
+			names, envVars = fl.Name, fl.EnvVar
+		case cli.BoolFlag:
+			names, envVars = fl.Name, fl.EnvVar
+		case cli.BoolTFlag:
+			names, envVars = fl.Name, fl.EnvVar
+		default:
+			continue
+		}
+		// the settings are applied by the first name of the flags
+		var name string
+		for i, n := range strings.Split(names, ",") {
+			n = strings.TrimSpace(n)
+			if i == 0 {
+				name = n
+			}
+			if given[n] {
+				f.cmd[name] = true
+			}
+		}
+		if f.cmd[name] {
+			continue
+		}
+		for _, env := range strings.Split(envVars, ",") {
+			if env = strings.TrimSpace(env); env != "" && os.Getenv(env) != "" {
+				f.env[name] = true
+			}
+		}
+	}
+	return f
+}
+
+// flagLayer is the context of the flags set in a layer of the configuration,
+// the other flags are unset
+type flagLayer struct {
+	ctx   runtimeFlagsContext
+	names map[string]bool
+}
+
+func (l flagLayer) String(key string) string {
+	if !l.names[key] {
+		return ""
+	}
+	return l.ctx.String(key)
+}
+
+func (l flagLayer) Int(key string) int {
+	if !l.names[key] {
+		return 0
+	}
+	return l.ctx.Int(key)
+}
+
+func (l flagLayer) Bool(key string) bool {
+	if !l.names[key] {
+		return false
+	}
+	return l.ctx.Bool(key)
+}
+
+func (l flagLayer) IsSet(key string) bool {
+	return l.names[key]
+}
+
+// layeredConfig builds the configuration of snapteld from its layers: the
+// defaults, the config file, the environment variables, the command line
+// flags and the settings of the key-value store, each one overriding the
+// previous ones. It returns the configuration with the layer each of its
+// settings comes from, by the names of the settings, e.g. restapi.port
+func layeredConfig(fpath string, flags configFlags) (*Config, map[string]string, error) {
+	cfg := getDefaultConfig()
+	if err := loadConfig(cfg, fpath); err != nil {
+		return nil, nil, err
+	}
+	file := copyConfig(cfg)
+	applyCmdLineFlags(cfg, flags.ctx)
+
+	// the settings of each layer are compared with the ones of the previous
+	// layer to find the settings it sets, the layers of the flags are applied
+	// to copies of the configuration since applying them joins the address
+	// of the REST API with its port
+	layers := []map[string]interface{}{
+		flagSettings(getDefaultConfig(), flagLayer{ctx: flags.ctx}),
+		flagSettings(file, flagLayer{ctx: flags.ctx}),
+		flagSettings(file, flagLayer{ctx: flags.ctx, names: flags.env}),
+		settingsOf(cfg),
+	}
+	remote, err := cfgremote.Fetch(cfg.RemoteConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(remote) > 0 {
+		if err := applyRemoteConfig(cfg, remote); err != nil {
+			return nil, nil, err
+		}
+	}
+	layers = append(layers, settingsOf(cfg))
+
+	names := []string{layerDefault, layerFile, layerEnv, layerFlag, layerRemote}
+	sources := map[string]string{}
+	var previous map[string]interface{}
+	for i, settings := range layers {
+		for name, v := range settings {
+			if pv, ok := previous[name]; !ok || !reflect.DeepEqual(pv, v) {
+				sources[name] = names[i]
+			}
+		}
+		previous = settings
+	}
+	for name := range sources {
+		if _, ok := previous[name]; !ok {
+			delete(sources, name)
+		}
+	}
+	return cfg, sources, nil
+}
+
+// applyRemoteConfig applies the settings of the key-value store to the
+// configuration, the address of the REST API is joined with its port again
+// when one of them is set
+func applyRemoteConfig(cfg *Config, settings map[string]interface{}) error {
+	// the key-value store is configured by the other layers only
+	delete(settings, "remote_config")
+	restapi, _ := settings["restapi"].(map[string]interface{})
+	_, addrSet := restapi["addr"]
+	_, portSet := restapi["port"]
+	host := cfg.RestAPI.Address
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	jb, _ := json.Marshal(settings)
+	if err := json.Unmarshal(jb, cfg); err != nil {
+		return fmt.Errorf("%v (while applying the remote settings)", err)
+	}
+	if addrSet {
+		host = cfg.RestAPI.Address
+		if _, port, err := net.SplitHostPort(host); err == nil {
+			// the port of the address overrides the port of the REST API
+			if cfg.RestAPI.Port, err = strconv.Atoi(port); err != nil {
+				return fmt.Errorf("%v (while parsing the port of 'restapi.addr')", err)
+			}
+			return nil
+		}
+	}
+	if addrSet || portSet {
+		cfg.RestAPI.Address = fmt.Sprintf("%v:%v", host, cfg.RestAPI.Port)
+	}
+	return nil
+}
+
+// copyConfig returns a copy of the configuration the command line flags can
+// be applied to without changing it
+func copyConfig(cfg *Config) *Config {
+	c := *cfg
+	control, scheduler, restapi, tribe, remote := *cfg.Control, *cfg.Scheduler, *cfg.RestAPI, *cfg.Tribe, *cfg.RemoteConfig
+	c.Control, c.Scheduler, c.RestAPI, c.Tribe, c.RemoteConfig = &control, &scheduler, &restapi, &tribe, &remote
+	return &c
+}
+
+// flagSettings returns the settings of a copy of the configuration the flags
+// are applied to
+func flagSettings(cfg *Config, ctx runtimeFlagsContext) map[string]interface{} {
+	c := copyConfig(cfg)
+	applyCmdLineFlags(c, ctx)
+	return settingsOf(c)
+}
+
+// settingsOf returns the settings of the configuration by their names, the
+// settings of the sections are named after them, e.g. restapi.port
+func settingsOf(cfg *Config) map[string]interface{} {
+	jb, _ := json.Marshal(cfg)
+	config := map[string]interface{}{}
+	json.Unmarshal(jb, &config)
+	settings := map[string]interface{}{}
+	for name, v := range config {
+		section, ok := v.(map[string]interface{})
+		if !ok {
+			settings[name] = v
+			continue
+		}
+		for key, sv := range section {
+			settings[name+"."+key] = sv
+		}
+	}
+	return settings
+}
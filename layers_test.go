@@ -0,0 +1,101 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/urfave/cli"
+
+	"github.com/intelsdi-x/snap/control"
+	"github.com/intelsdi-x/snap/mgmt/rest"
+)
+
+func TestConfigFlags(t *testing.T) {
+	Convey("Given flags set on the command line and by environment variables", t, func() {
+		os.Setenv("SNAP_LOG_LEVEL", "1")
+		os.Setenv("SNAP_PORT", "9000")
+		defer os.Unsetenv("SNAP_LOG_LEVEL")
+		defer os.Unsetenv("SNAP_PORT")
+		flags := []cli.Flag{flLogLevel, flLogPath, flLogColors}
+		flags = append(flags, rest.Flags...)
+		flags = append(flags, control.Flags...)
+		args := []string{"-p", "9001", "--log-colors=false", "-t", "0", "--", "--log-path", "/tmp"}
+
+		Convey("the flags are told apart by their layers", func() {
+			f := newConfigFlags(mockFlags{}, flags, args)
+			So(f.env, ShouldResemble, map[string]bool{"log-level": true})
+			So(f.cmd, ShouldResemble, map[string]bool{"api-port": true, "log-colors": true, "plugin-trust": true})
+		})
+	})
+}
+
+func TestLayeredConfig(t *testing.T) {
+	Convey("Given the layers of the configuration", t, func() {
+		ctx := mockFlags{"log-level": "1", "max-running-plugins": "5", "remote-config-type": "consul"}
+		flags := configFlags{
+			ctx: ctx,
+			env: map[string]bool{"log-level": true},
+			cmd: map[string]bool{"max-running-plugins": true, "remote-config-type": true},
+		}
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"Key": "snap/config/restapi/port", "Value": base64.StdEncoding.EncodeToString([]byte("9999"))},
+			})
+		}))
+		defer srv.Close()
+		ctx["remote-config-endpoint"] = srv.URL
+		flags.cmd["remote-config-endpoint"] = true
+
+		Convey("each layer overrides the previous ones", func() {
+			cfg, sources, err := layeredConfig("", flags)
+			So(err, ShouldBeNil)
+			So(cfg.LogLevel, ShouldEqual, 1)
+			So(cfg.Control.MaxRunningPlugins, ShouldEqual, 5)
+			So(cfg.RestAPI.Port, ShouldEqual, 9999)
+			So(cfg.RestAPI.Address, ShouldEqual, ":9999")
+			So(sources["log_level"], ShouldEqual, layerEnv)
+			So(sources["control.max_running_plugins"], ShouldEqual, layerFlag)
+			So(sources["remote_config.type"], ShouldEqual, layerFlag)
+			So(sources["restapi.port"], ShouldEqual, layerRemote)
+			So(sources["restapi.addr"], ShouldEqual, layerRemote)
+			So(sources["scheduler.work_manager_queue_size"], ShouldEqual, layerDefault)
+		})
+		Convey("the key-value store can't be configured by itself", func() {
+			cfg := getDefaultConfig()
+			err := applyRemoteConfig(cfg, map[string]interface{}{
+				"remote_config": map[string]interface{}{"type": "etcd"},
+				"restapi":       map[string]interface{}{"addr": "127.0.0.1:8282"},
+			})
+			So(err, ShouldBeNil)
+			So(cfg.RemoteConfig.Type, ShouldEqual, "")
+			So(cfg.RestAPI.Address, ShouldEqual, "127.0.0.1:8282")
+			So(cfg.RestAPI.Port, ShouldEqual, 8282)
+		})
+	})
+}
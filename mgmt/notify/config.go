@@ -0,0 +1,119 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import (
+	"time"
+
+	"github.com/vrischmann/jsonutil"
+)
+
+// default configuration values
+const (
+	defaultStorePath  = ""
+	defaultRetries    = 5
+	defaultRetryDelay = time.Second
+	defaultTimeout    = 10 * time.Second
+	defaultQueueSize  = 1000
+)
+
+// holds the configuration passed in through the SNAP config file
+type Config struct {
+	// Webhooks are the webhooks notified from the start of snapteld, along
+	// with the ones registered through the REST API
+	Webhooks []Webhook `json:"webhooks"yaml:"webhooks"`
+	// StorePath is the file the webhooks registered through the REST API are
+	// persisted in, they're forgotten on restart when it's empty
+	StorePath  string            `json:"store_path"yaml:"store_path"`
+	Retries    int               `json:"retries"yaml:"retries"`
+	RetryDelay jsonutil.Duration `json:"retry_delay"yaml:"retry_delay"`
+	Timeout    jsonutil.Duration `json:"timeout"yaml:"timeout"`
+	QueueSize  int               `json:"queue_size"yaml:"queue_size"`
+}
+
+const (
+	CONFIG_CONSTRAINTS = `
+			"notify": {
+				"type": ["object", "null"],
+				"properties" : {
+					"webhooks": {
+						"type": ["array", "null"],
+						"items": {
+							"type": "object",
+							"properties": {
+								"id": {
+									"type": "string"
+								},
+								"url": {
+									"type": "string"
+								},
+								"events": {
+									"type": ["array", "null"],
+									"items": {
+										"type": "string"
+									}
+								},
+								"task_ids": {
+									"type": ["array", "null"],
+									"items": {
+										"type": "string"
+									}
+								},
+								"secret": {
+									"type": "string"
+								}
+							},
+							"required": ["url"],
+							"additionalProperties": false
+						}
+					},
+					"store_path": {
+						"type": "string"
+					},
+					"retries": {
+						"type": "integer",
+						"minimum": 0
+					},
+					"retry_delay": {
+						"type": "string"
+					},
+					"timeout": {
+						"type": "string"
+					},
+					"queue_size": {
+						"type": "integer",
+						"minimum": 1
+					}
+				},
+				"additionalProperties": false
+			}
+	`
+)
+
+// GetDefaultConfig gets the default snapteld configuration
+func GetDefaultConfig() *Config {
+	return &Config{
+		StorePath:  defaultStorePath,
+		Retries:    defaultRetries,
+		RetryDelay: jsonutil.Duration{defaultRetryDelay},
+		Timeout:    jsonutil.Duration{defaultTimeout},
+		QueueSize:  defaultQueueSize,
+	}
+}
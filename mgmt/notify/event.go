@@ -0,0 +1,111 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import (
+	"time"
+
+	"github.com/intelsdi-x/gomit"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/control_event"
+	"github.com/intelsdi-x/snap/core/scheduler_event"
+	"github.com/intelsdi-x/snap/core/tribe_event"
+)
+
+// Notification is the JSON body POSTed to the webhooks
+type Notification struct {
+	ID string `json:"id"`
+	// Event is the namespace of the event, e.g. Scheduler.TaskDisabled
+	Event     string    `json:"event"`
+	Timestamp time.Time `json:"timestamp"`
+	// Host is the host name of snapteld
+	Host   string                 `json:"host"`
+	Fields map[string]interface{} `json:"fields"`
+}
+
+// eventFields returns the fields of the notification of an event, false for
+// the events that aren't notified
+func eventFields(e gomit.EventBody) (map[string]interface{}, bool) {
+	switch v := e.(type) {
+	case *control_event.LoadPluginEvent:
+		return pluginFields(v.Name, v.Version, v.Type), true
+	case *control_event.UnloadPluginEvent:
+		return pluginFields(v.Name, v.Version, v.Type), true
+	case *control_event.DeadAvailablePluginEvent:
+		return pluginFields(v.Name, v.Version, v.Type), true
+	case *control_event.RestartedAvailablePluginEvent:
+		return pluginFields(v.Name, v.Version, v.Type), true
+	case *control_event.MaxPluginRestartsExceededEvent:
+		return pluginFields(v.Name, v.Version, v.Type), true
+	case *control_event.PluginBlacklistedEvent:
+		f := pluginFields(v.Name, v.Version, v.Type)
+		f["checksum"] = v.CheckSum
+		f["crashes"] = v.Crashes
+		return f, true
+	case *control_event.HealthCheckFailedEvent:
+		f := pluginFields(v.Name, v.Version, v.Type)
+		f["failed_checks"] = v.FailedChecks
+		return f, true
+	case *scheduler_event.TaskCreatedEvent:
+		return taskFields(v.TaskID, v.Source), true
+	case *scheduler_event.TaskStartedEvent:
+		return taskFields(v.TaskID, v.Source), true
+	case *scheduler_event.TaskStoppedEvent:
+		return taskFields(v.TaskID, v.Source), true
+	case *scheduler_event.TaskEndedEvent:
+		return taskFields(v.TaskID, v.Source), true
+	case *scheduler_event.TaskDeletedEvent:
+		return taskFields(v.TaskID, v.Source), true
+	case *scheduler_event.TaskDisabledEvent:
+		f := taskFields(v.TaskID, "")
+		f["why"] = v.Why
+		return f, true
+	case *scheduler_event.MetricCollectionFailedEvent:
+		f := taskFields(v.TaskID, "")
+		errs := make([]string, len(v.Errors))
+		for i, err := range v.Errors {
+			errs[i] = err.Error()
+		}
+		f["errors"] = errs
+		return f, true
+	case *tribe_event.MemberJoinedEvent:
+		return map[string]interface{}{"member": v.Name, "addr": v.Addr}, true
+	case *tribe_event.MemberLeftEvent:
+		return map[string]interface{}{"member": v.Name, "addr": v.Addr}, true
+	}
+	return nil, false
+}
+
+func pluginFields(name string, version, typ int) map[string]interface{} {
+	return map[string]interface{}{
+		"plugin_name":    name,
+		"plugin_version": version,
+		"plugin_type":    core.PluginType(typ).String(),
+	}
+}
+
+func taskFields(id, source string) map[string]interface{} {
+	f := map[string]interface{}{"task_id": id}
+	if source != "" {
+		f["source"] = source
+	}
+	return f
+}
@@ -0,0 +1,312 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package notify POSTs the events of control, the scheduler and tribe to the
+// webhooks registered by the users.
+package notify
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/intelsdi-x/gomit"
+	"github.com/pborman/uuid"
+)
+
+// workers is the number of notifications delivered concurrently
+const workers = 4
+
+var (
+	notifyLogger = log.WithField("_module", "notify")
+
+	ErrWebhookNotFound   = errors.New("Webhook not found")
+	ErrWebhookExists     = errors.New("Webhook already exists")
+	ErrInvalidWebhookURL = errors.New("The URL of a webhook must be an absolute http or https URL")
+)
+
+// Webhook is a URL the events are POSTed to
+type Webhook struct {
+	ID  string `json:"id"yaml:"id"`
+	URL string `json:"url"yaml:"url"`
+	// Events are the namespaces of the events notified, e.g.
+	// Control.PluginLoaded, a trailing * matches the namespaces with the
+	// prefix, e.g. Scheduler.*; every event is notified when it's empty
+	Events []string `json:"events,omitempty"yaml:"events"`
+	// TaskIDs restrict the events of the tasks to the events of these tasks,
+	// the events not related to a task are still notified
+	TaskIDs []string `json:"task_ids,omitempty"yaml:"task_ids"`
+	// Secret signs the notifications with HMAC-SHA256 when it's set
+	Secret string `json:"secret,omitempty"yaml:"secret"`
+
+	// configured is true for the webhooks of the config, they aren't
+	// persisted in the store
+	configured bool
+}
+
+// Validate checks the URL of the webhook
+func (w Webhook) Validate() error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidWebhookURL
+	}
+	return nil
+}
+
+// matches returns true when the notification passes the filters of the
+// webhook
+func (w Webhook) matches(n Notification) bool {
+	if len(w.Events) > 0 {
+		matched := false
+		for _, pattern := range w.Events {
+			if pattern == n.Event || (strings.HasSuffix(pattern, "*") && strings.HasPrefix(n.Event, strings.TrimSuffix(pattern, "*"))) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if taskID, ok := n.Fields["task_id"].(string); ok && len(w.TaskIDs) > 0 {
+		for _, id := range w.TaskIDs {
+			if id == taskID {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Notifier delivers the events it handles to the webhooks they match
+type Notifier struct {
+	sender *sender
+	host   string
+
+	mutex    sync.RWMutex
+	webhooks map[string]Webhook
+	store    *webhookStore
+
+	queue    chan delivery
+	killChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+// New returns a notifier of the webhooks of the config
+func New(cfg *Config) (*Notifier, error) {
+	n := &Notifier{
+		sender: &sender{
+			client:     &http.Client{Timeout: cfg.Timeout.Duration},
+			retries:    cfg.Retries,
+			retryDelay: cfg.RetryDelay.Duration,
+		},
+		webhooks: map[string]Webhook{},
+		queue:    make(chan delivery, cfg.QueueSize),
+		killChan: make(chan struct{}),
+	}
+	n.host, _ = os.Hostname()
+	for _, w := range cfg.Webhooks {
+		if err := w.Validate(); err != nil {
+			return nil, err
+		}
+		if w.ID == "" {
+			w.ID = uuid.New()
+		}
+		if _, ok := n.webhooks[w.ID]; ok {
+			return nil, ErrWebhookExists
+		}
+		w.configured = true
+		n.webhooks[w.ID] = w
+	}
+	if cfg.StorePath != "" {
+		n.store = newWebhookStore(cfg.StorePath)
+	}
+	return n, nil
+}
+
+func (n *Notifier) Name() string {
+	return "notify"
+}
+
+// Start loads the webhooks persisted in the store and starts delivering the
+// notifications
+func (n *Notifier) Start() error {
+	if n.store != nil {
+		webhooks, err := n.store.load()
+		if err != nil {
+			return err
+		}
+		n.mutex.Lock()
+		for _, w := range webhooks {
+			if _, ok := n.webhooks[w.ID]; !ok {
+				n.webhooks[w.ID] = w
+			}
+		}
+		n.mutex.Unlock()
+	}
+	for i := 0; i < workers; i++ {
+		n.wg.Add(1)
+		go n.deliver()
+	}
+	notifyLogger.WithFields(log.Fields{
+		"_block":   "start",
+		"webhooks": len(n.webhooks),
+	}).Info("notify started")
+	return nil
+}
+
+// Stop stops delivering the notifications, the notifications still queued
+// are dropped
+func (n *Notifier) Stop() {
+	close(n.killChan)
+	n.wg.Wait()
+	notifyLogger.WithFields(log.Fields{
+		"_block": "stop",
+	}).Info("notify stopped")
+}
+
+// HandleGomitEvent queues the notifications of an event for the webhooks it
+// matches, it never blocks the emitter of the event
+func (n *Notifier) HandleGomitEvent(e gomit.Event) {
+	fields, ok := eventFields(e.Body)
+	if !ok {
+		return
+	}
+	notif := Notification{
+		ID:        uuid.New(),
+		Event:     e.Namespace(),
+		Timestamp: time.Now().UTC(),
+		Host:      n.host,
+		Fields:    fields,
+	}
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+	for _, w := range n.webhooks {
+		if !w.matches(notif) {
+			continue
+		}
+		select {
+		case n.queue <- delivery{webhook: w, notification: notif}:
+		default:
+			notifyLogger.WithFields(log.Fields{
+				"_block":  "handle-gomit-event",
+				"event":   notif.Event,
+				"webhook": w.ID,
+			}).Warn("notification dropped, the queue is full")
+		}
+	}
+}
+
+func (n *Notifier) deliver() {
+	defer n.wg.Done()
+	for {
+		select {
+		case d := <-n.queue:
+			n.sender.send(d, n.killChan)
+		case <-n.killChan:
+			return
+		}
+	}
+}
+
+// Webhooks returns the webhooks sorted by ID
+func (n *Notifier) Webhooks() []Webhook {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+	webhooks := make([]Webhook, 0, len(n.webhooks))
+	for _, w := range n.webhooks {
+		webhooks = append(webhooks, w)
+	}
+	sort.Sort(byID(webhooks))
+	return webhooks
+}
+
+func (n *Notifier) Webhook(id string) (Webhook, error) {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+	w, ok := n.webhooks[id]
+	if !ok {
+		return Webhook{}, ErrWebhookNotFound
+	}
+	return w, nil
+}
+
+// AddWebhook registers a webhook, it's given an ID and persisted in the
+// store
+func (n *Notifier) AddWebhook(w Webhook) (Webhook, error) {
+	if err := w.Validate(); err != nil {
+		return Webhook{}, err
+	}
+	w.ID = uuid.New()
+	w.configured = false
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.webhooks[w.ID] = w
+	if err := n.save(); err != nil {
+		delete(n.webhooks, w.ID)
+		return Webhook{}, err
+	}
+	return w, nil
+}
+
+// RemoveWebhook removes a webhook, the webhooks of the config are notified
+// again after a restart
+func (n *Notifier) RemoveWebhook(id string) error {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	w, ok := n.webhooks[id]
+	if !ok {
+		return ErrWebhookNotFound
+	}
+	delete(n.webhooks, id)
+	if err := n.save(); err != nil {
+		n.webhooks[id] = w
+		return err
+	}
+	return nil
+}
+
+// save persists the webhooks registered through the REST API, it's called
+// with the mutex locked
+func (n *Notifier) save() error {
+	if n.store == nil {
+		return nil
+	}
+	webhooks := []Webhook{}
+	for _, w := range n.webhooks {
+		if !w.configured {
+			webhooks = append(webhooks, w)
+		}
+	}
+	sort.Sort(byID(webhooks))
+	return n.store.save(webhooks)
+}
+
+type byID []Webhook
+
+func (b byID) Len() int           { return len(b) }
+func (b byID) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byID) Less(i, j int) bool { return b[i].ID < b[j].ID }
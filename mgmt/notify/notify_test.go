@@ -0,0 +1,246 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/vrischmann/jsonutil"
+
+	"github.com/intelsdi-x/gomit"
+	"github.com/intelsdi-x/snap/core/control_event"
+	"github.com/intelsdi-x/snap/core/scheduler_event"
+)
+
+type received struct {
+	header       http.Header
+	body         []byte
+	notification Notification
+}
+
+// webhookServer records the notifications it receives, the first failures
+// requests fail with a 503
+type webhookServer struct {
+	*httptest.Server
+	mutex    sync.Mutex
+	failures int
+	received []received
+	ch       chan struct{}
+}
+
+func newWebhookServer(failures int) *webhookServer {
+	s := &webhookServer{failures: failures, ch: make(chan struct{}, 100)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		if s.failures > 0 {
+			s.failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		rcv := received{header: r.Header, body: b}
+		json.Unmarshal(b, &rcv.notification)
+		s.received = append(s.received, rcv)
+		s.ch <- struct{}{}
+	}))
+	return s
+}
+
+// wait waits for n notifications to be received
+func (s *webhookServer) wait(n int) bool {
+	for i := 0; i < n; i++ {
+		select {
+		case <-s.ch:
+		case <-time.After(time.Second):
+			return false
+		}
+	}
+	return true
+}
+
+func testConfig() *Config {
+	cfg := GetDefaultConfig()
+	cfg.RetryDelay = jsonutil.Duration{time.Millisecond}
+	cfg.Timeout = jsonutil.Duration{time.Second}
+	return cfg
+}
+
+func TestWebhookMatches(t *testing.T) {
+	Convey("Given a notification of a task event", t, func() {
+		n := Notification{Event: scheduler_event.TaskDisabled, Fields: map[string]interface{}{"task_id": "1"}}
+		Convey("a webhook without filters matches it", func() {
+			So(Webhook{}.matches(n), ShouldBeTrue)
+		})
+		Convey("a webhook of the event matches it", func() {
+			So(Webhook{Events: []string{scheduler_event.TaskDisabled}}.matches(n), ShouldBeTrue)
+		})
+		Convey("a webhook of a prefix of the event matches it", func() {
+			So(Webhook{Events: []string{"Scheduler.*"}}.matches(n), ShouldBeTrue)
+		})
+		Convey("a webhook of other events doesn't match it", func() {
+			So(Webhook{Events: []string{"Control.*", scheduler_event.TaskStarted}}.matches(n), ShouldBeFalse)
+		})
+		Convey("a webhook of the task matches it", func() {
+			So(Webhook{TaskIDs: []string{"2", "1"}}.matches(n), ShouldBeTrue)
+		})
+		Convey("a webhook of other tasks doesn't match it", func() {
+			So(Webhook{TaskIDs: []string{"2"}}.matches(n), ShouldBeFalse)
+		})
+	})
+	Convey("A webhook of tasks matches the notifications of the events not related to a task", t, func() {
+		n := Notification{Event: control_event.PluginLoaded, Fields: map[string]interface{}{"plugin_name": "mock"}}
+		So(Webhook{TaskIDs: []string{"1"}}.matches(n), ShouldBeTrue)
+	})
+}
+
+func TestWebhookValidate(t *testing.T) {
+	Convey("The URL of a webhook must be an absolute http or https URL", t, func() {
+		So(Webhook{URL: "http://localhost:8080/hook"}.Validate(), ShouldBeNil)
+		So(Webhook{URL: "https://example.com/hook"}.Validate(), ShouldBeNil)
+		So(Webhook{URL: "ftp://example.com/hook"}.Validate(), ShouldEqual, ErrInvalidWebhookURL)
+		So(Webhook{URL: "/hook"}.Validate(), ShouldEqual, ErrInvalidWebhookURL)
+		So(Webhook{URL: ""}.Validate(), ShouldEqual, ErrInvalidWebhookURL)
+	})
+}
+
+func TestNotifier(t *testing.T) {
+	Convey("Given a notifier of a webhook", t, func() {
+		srv := newWebhookServer(0)
+		defer srv.Close()
+		cfg := testConfig()
+		cfg.Webhooks = []Webhook{{URL: srv.URL, Events: []string{"Control.*"}, Secret: "s3cr3t"}}
+		n, err := New(cfg)
+		So(err, ShouldBeNil)
+		So(n.Start(), ShouldBeNil)
+		defer n.Stop()
+
+		Convey("the events it matches are POSTed to it", func() {
+			n.HandleGomitEvent(gomit.Event{Body: &control_event.LoadPluginEvent{Name: "mock", Version: 2, Type: 0}})
+			So(srv.wait(1), ShouldBeTrue)
+			rcv := srv.received[0]
+			So(rcv.notification.Event, ShouldEqual, control_event.PluginLoaded)
+			So(rcv.notification.Fields["plugin_name"], ShouldEqual, "mock")
+			So(rcv.notification.Fields["plugin_version"], ShouldEqual, 2)
+			So(rcv.notification.Fields["plugin_type"], ShouldEqual, "collector")
+			So(rcv.header.Get(EventHeader), ShouldEqual, control_event.PluginLoaded)
+			So(rcv.header.Get(DeliveryHeader), ShouldEqual, rcv.notification.ID)
+			Convey("signed with its secret", func() {
+				So(rcv.header.Get(SignatureHeader), ShouldEqual, Sign("s3cr3t", rcv.body))
+				So(rcv.header.Get(SignatureHeader), ShouldNotEqual, Sign("other", rcv.body))
+			})
+		})
+		Convey("the events it doesn't match aren't POSTed to it", func() {
+			n.HandleGomitEvent(gomit.Event{Body: &scheduler_event.TaskDisabledEvent{TaskID: "1", Why: "too many failures"}})
+			So(srv.wait(1), ShouldBeFalse)
+		})
+	})
+	Convey("Given a webhook failing the first attempts", t, func() {
+		srv := newWebhookServer(2)
+		defer srv.Close()
+		cfg := testConfig()
+		cfg.Webhooks = []Webhook{{URL: srv.URL}}
+		Convey("the notifications are retried until they're delivered", func() {
+			n, err := New(cfg)
+			So(err, ShouldBeNil)
+			So(n.Start(), ShouldBeNil)
+			defer n.Stop()
+			n.HandleGomitEvent(gomit.Event{Body: &scheduler_event.TaskDisabledEvent{TaskID: "1", Why: "too many failures"}})
+			So(srv.wait(1), ShouldBeTrue)
+			So(srv.received[0].notification.Fields["why"], ShouldEqual, "too many failures")
+			So(srv.received[0].header.Get(SignatureHeader), ShouldBeEmpty)
+		})
+		Convey("the notifications are dropped once the retries are exhausted", func() {
+			cfg.Retries = 1
+			n, err := New(cfg)
+			So(err, ShouldBeNil)
+			So(n.Start(), ShouldBeNil)
+			defer n.Stop()
+			n.HandleGomitEvent(gomit.Event{Body: &scheduler_event.TaskDisabledEvent{TaskID: "1"}})
+			So(srv.wait(1), ShouldBeFalse)
+		})
+	})
+	Convey("The webhooks of the config must be valid", t, func() {
+		cfg := testConfig()
+		cfg.Webhooks = []Webhook{{URL: "localhost"}}
+		_, err := New(cfg)
+		So(err, ShouldEqual, ErrInvalidWebhookURL)
+	})
+}
+
+func TestWebhookStore(t *testing.T) {
+	Convey("Given a notifier persisting its webhooks", t, func() {
+		dir, err := ioutil.TempDir("", "snap-notify-")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		cfg := testConfig()
+		cfg.StorePath = filepath.Join(dir, "webhooks.json")
+		cfg.Webhooks = []Webhook{{ID: "configured", URL: "http://localhost:8080/config"}}
+		n, err := New(cfg)
+		So(err, ShouldBeNil)
+		So(n.Start(), ShouldBeNil)
+		defer n.Stop()
+
+		w, err := n.AddWebhook(Webhook{URL: "http://localhost:8080/api", Secret: "s3cr3t"})
+		So(err, ShouldBeNil)
+		So(w.ID, ShouldNotBeEmpty)
+		So(n.Webhooks(), ShouldHaveLength, 2)
+
+		Convey("the webhooks added are restored by a new notifier", func() {
+			n2, err := New(cfg)
+			So(err, ShouldBeNil)
+			So(n2.Start(), ShouldBeNil)
+			defer n2.Stop()
+			So(n2.Webhooks(), ShouldHaveLength, 2)
+			w2, err := n2.Webhook(w.ID)
+			So(err, ShouldBeNil)
+			So(w2.URL, ShouldEqual, "http://localhost:8080/api")
+			So(w2.Secret, ShouldEqual, "s3cr3t")
+		})
+		Convey("the webhooks of the config aren't persisted", func() {
+			webhooks, err := newWebhookStore(cfg.StorePath).load()
+			So(err, ShouldBeNil)
+			So(webhooks, ShouldHaveLength, 1)
+			So(webhooks[0].ID, ShouldEqual, w.ID)
+		})
+		Convey("the webhooks removed are no longer restored", func() {
+			So(n.RemoveWebhook(w.ID), ShouldBeNil)
+			So(n.RemoveWebhook(w.ID), ShouldEqual, ErrWebhookNotFound)
+			webhooks, err := newWebhookStore(cfg.StorePath).load()
+			So(err, ShouldBeNil)
+			So(webhooks, ShouldBeEmpty)
+		})
+		Convey("an invalid webhook can't be added", func() {
+			_, err := n.AddWebhook(Webhook{URL: "localhost"})
+			So(err, ShouldEqual, ErrInvalidWebhookURL)
+		})
+	})
+}
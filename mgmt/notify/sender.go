@@ -0,0 +1,134 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// The headers of the notifications POSTed to the webhooks
+const (
+	// EventHeader is the namespace of the event
+	EventHeader = "X-Snap-Event"
+	// DeliveryHeader is the ID of the notification, the same for all the
+	// attempts to deliver it
+	DeliveryHeader = "X-Snap-Delivery"
+	// SignatureHeader is the HMAC-SHA256 of the body keyed with the secret of
+	// the webhook, e.g. sha256=8f0b...
+	SignatureHeader = "X-Snap-Signature"
+)
+
+type delivery struct {
+	webhook      Webhook
+	notification Notification
+}
+
+// sender POSTs the notifications, retrying the failed attempts
+type sender struct {
+	client     *http.Client
+	retries    int
+	retryDelay time.Duration
+}
+
+// Sign returns the signature of a body, the value of SignatureHeader
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// send delivers a notification, the retries are waited for twice as long as
+// the previous one, until the notifier is stopped
+func (s *sender) send(d delivery, killChan <-chan struct{}) {
+	logger := notifyLogger.WithFields(log.Fields{
+		"_block":   "send",
+		"event":    d.notification.Event,
+		"delivery": d.notification.ID,
+		"webhook":  d.webhook.ID,
+	})
+	body, err := json.Marshal(d.notification)
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+	wait := s.retryDelay
+	for attempt := 0; ; attempt++ {
+		retry, err := s.post(d, body)
+		if err == nil {
+			logger.Debug("notification delivered")
+			return
+		}
+		if !retry || attempt >= s.retries {
+			logger.WithField("error", err).Error("notification not delivered")
+			return
+		}
+		logger.WithFields(log.Fields{
+			"error": err,
+			"wait":  wait,
+		}).Warn("notification not delivered, retrying")
+		select {
+		case <-time.After(wait):
+		case <-killChan:
+			return
+		}
+		wait *= 2
+	}
+}
+
+// post makes an attempt to deliver a notification, retry is true when the
+// attempt failed and can be made again
+func (s *sender) post(d delivery, body []byte) (retry bool, err error) {
+	req, err := http.NewRequest("POST", d.webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "snapteld")
+	req.Header.Set(EventHeader, d.notification.Event)
+	req.Header.Set(DeliveryHeader, d.notification.ID)
+	if d.webhook.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(d.webhook.Secret, body))
+	}
+	rsp, err := s.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	io.Copy(ioutil.Discard, rsp.Body)
+	rsp.Body.Close()
+	switch {
+	case rsp.StatusCode >= 200 && rsp.StatusCode < 300:
+		return false, nil
+	case rsp.StatusCode >= 500 || rsp.StatusCode == http.StatusTooManyRequests:
+		return true, fmt.Errorf("webhook responded with %s", rsp.Status)
+	}
+	// the other client errors won't be fixed by sending the notification again
+	return false, fmt.Errorf("webhook responded with %s", rsp.Status)
+}
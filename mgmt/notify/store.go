@@ -0,0 +1,73 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// webhookStore persists the webhooks registered through the REST API in a
+// file, so they're still notified after snapteld restart.  The file holds
+// the secrets of the webhooks, it's only readable by snapteld.
+type webhookStore struct {
+	path string
+}
+
+func newWebhookStore(path string) *webhookStore {
+	return &webhookStore{path: path}
+}
+
+// save writes the webhooks to the store file
+func (s *webhookStore) save(webhooks []Webhook) error {
+	b, err := json.Marshal(webhooks)
+	if err != nil {
+		return err
+	}
+	// write to a temporary file first so the store file is never left half-written
+	tmp := s.path + ".tmp"
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// load reads the store file and returns the webhooks it contains.  A missing
+// store file results in no webhooks.
+func (s *webhookStore) load() ([]Webhook, error) {
+	b, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var webhooks []Webhook
+	if err := json.Unmarshal(b, &webhooks); err != nil {
+		return nil, fmt.Errorf("Unable to parse webhook file %s: %v", s.path, err)
+	}
+	return webhooks, nil
+}
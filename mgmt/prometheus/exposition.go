@@ -0,0 +1,222 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ContentType is the content type of the text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// the types of the metric families
+const (
+	counterType = "counter"
+	gaugeType   = "gauge"
+	untypedType = "untyped"
+)
+
+type sample struct {
+	labels    map[string]string
+	value     float64
+	timestamp time.Time
+}
+
+type family struct {
+	name    string
+	help    string
+	typ     string
+	samples []sample
+}
+
+// families gathers the samples of the metric families by name
+type families map[string]*family
+
+// add adds a sample to the family of the given name, the family is created
+// with the given help and type by its first sample
+func (fs families) add(name, help, typ string, labels map[string]string, value float64, timestamp time.Time) {
+	f, ok := fs[name]
+	if !ok {
+		f = &family{name: name, help: help, typ: typ}
+		fs[name] = f
+	}
+	f.samples = append(f.samples, sample{labels: labels, value: value, timestamp: timestamp})
+}
+
+// write writes the families sorted by name in the text exposition format
+func (fs families) write(w io.Writer) error {
+	names := make([]string, 0, len(fs))
+	for name := range fs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var buf bytes.Buffer
+	for _, name := range names {
+		f := fs[name]
+		if f.help != "" {
+			fmt.Fprintf(&buf, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+		}
+		fmt.Fprintf(&buf, "# TYPE %s %s\n", f.name, f.typ)
+		// the samples are sorted by their labels for a stable output
+		lines := make([]string, len(f.samples))
+		for i, s := range f.samples {
+			var line bytes.Buffer
+			line.WriteString(f.name)
+			writeLabels(&line, s.labels)
+			line.WriteByte(' ')
+			line.WriteString(formatValue(s.value))
+			if !s.timestamp.IsZero() {
+				line.WriteByte(' ')
+				line.WriteString(strconv.FormatInt(s.timestamp.UnixNano()/int64(time.Millisecond), 10))
+			}
+			lines[i] = line.String()
+		}
+		sort.Strings(lines)
+		for _, line := range lines {
+			buf.WriteString(line)
+			buf.WriteByte('\n')
+		}
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func writeLabels(buf *bytes.Buffer, labels map[string]string) {
+	if len(labels) == 0 {
+		return
+	}
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	buf.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(name)
+		buf.WriteString(`="`)
+		buf.WriteString(escapeLabelValue(labels[name]))
+		buf.WriteByte('"')
+	}
+	buf.WriteByte('}')
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return labelValueEscaper.Replace(s)
+}
+
+// metricName returns a valid metric name of the elements, the characters not
+// allowed in a metric name are replaced with underscores
+func metricName(elements ...string) string {
+	return sanitize(strings.Join(elements, "_"), true)
+}
+
+// labelName returns a valid label name, the characters not allowed in a label
+// name are replaced with underscores
+func labelName(name string) string {
+	name = sanitize(name, false)
+	// the names starting with __ are reserved for Prometheus
+	if strings.HasPrefix(name, "__") {
+		name = "_" + strings.TrimLeft(name, "_")
+	}
+	return name
+}
+
+func sanitize(s string, colons bool) string {
+	if s == "" {
+		return "_"
+	}
+	b := []byte(s)
+	for i, c := range b {
+		if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
+			(i > 0 && c >= '0' && c <= '9') || (colons && c == ':') {
+			continue
+		}
+		b[i] = '_'
+	}
+	return string(b)
+}
+
+// toFloat returns the value of the numeric data of a metric, false when the
+// data isn't numeric
+func toFloat(data interface{}) (float64, bool) {
+	switch v := data.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
@@ -0,0 +1,217 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package prometheus exposes the statistics of snapteld, and optionally the
+// values last collected by the tasks, in the Prometheus text exposition format
+package prometheus
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/intelsdi-x/gomit"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/scheduler_event"
+	"github.com/intelsdi-x/snap/core/serror"
+)
+
+var promLogger = log.WithField("_module", "mgmt-prometheus")
+
+// managesMetrics is the source of the statistics of the plugins
+type managesMetrics interface {
+	PluginCatalog() core.PluginCatalog
+	AvailablePlugins() []core.AvailablePlugin
+	PluginMetadata(core.Plugin) (*core.PluginMetadata, serror.SnapError)
+}
+
+// managesTasks is the source of the statistics of the scheduler
+type managesTasks interface {
+	GetTasks() map[string]core.Task
+	WorkerPoolStats() []core.WorkerPoolStats
+}
+
+// Exporter serves the statistics of snapteld to Prometheus.  When it exposes
+// the collected metrics it has to be registered as a handler of the events of
+// the scheduler, the values it exposes are the last ones collected by the
+// running tasks.
+type Exporter struct {
+	metrics   managesMetrics
+	tasks     managesTasks
+	collected bool
+
+	mutex         sync.RWMutex
+	lastCollected map[string][]core.Metric
+}
+
+// New returns an exporter of the statistics of control and the scheduler,
+// along with the values last collected by the tasks when collected is true
+func New(metrics managesMetrics, tasks managesTasks, collected bool) *Exporter {
+	return &Exporter{
+		metrics:       metrics,
+		tasks:         tasks,
+		collected:     collected,
+		lastCollected: map[string][]core.Metric{},
+	}
+}
+
+// HandleGomitEvent keeps the metrics last collected by each task, they're
+// forgotten once the task stops
+func (e *Exporter) HandleGomitEvent(ev gomit.Event) {
+	if !e.collected {
+		return
+	}
+	switch v := ev.Body.(type) {
+	case *scheduler_event.MetricCollectedEvent:
+		e.mutex.Lock()
+		e.lastCollected[v.TaskID] = v.Metrics
+		e.mutex.Unlock()
+	case *scheduler_event.TaskStoppedEvent:
+		e.forget(v.TaskID)
+	case *scheduler_event.TaskEndedEvent:
+		e.forget(v.TaskID)
+	case *scheduler_event.TaskDeletedEvent:
+		e.forget(v.TaskID)
+	case *scheduler_event.TaskDisabledEvent:
+		e.forget(v.TaskID)
+	}
+}
+
+func (e *Exporter) forget(taskID string) {
+	e.mutex.Lock()
+	delete(e.lastCollected, taskID)
+	e.mutex.Unlock()
+}
+
+// ServeHTTP writes the metrics in the text exposition format
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	if err := e.gather().write(w); err != nil {
+		promLogger.WithFields(log.Fields{
+			"_block": "serve-http",
+		}).Error(err)
+	}
+}
+
+func (e *Exporter) gather() families {
+	fs := families{}
+	gatherRuntime(fs)
+	if e.tasks != nil {
+		e.gatherScheduler(fs)
+	}
+	if e.metrics != nil {
+		e.gatherPlugins(fs)
+	}
+	if e.collected {
+		e.gatherCollected(fs)
+	}
+	return fs
+}
+
+func gatherRuntime(fs families) {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	fs.add("snap_goroutines", "Number of goroutines of snapteld.", gaugeType, nil, float64(runtime.NumGoroutine()), time.Time{})
+	fs.add("snap_memory_heap_alloc_bytes", "Bytes of allocated heap objects.", gaugeType, nil, float64(ms.HeapAlloc), time.Time{})
+	fs.add("snap_memory_heap_sys_bytes", "Bytes of heap memory obtained from the OS.", gaugeType, nil, float64(ms.HeapSys), time.Time{})
+	fs.add("snap_memory_heap_objects", "Number of allocated heap objects.", gaugeType, nil, float64(ms.HeapObjects), time.Time{})
+	fs.add("snap_gc_runs_total", "Number of completed GC cycles.", counterType, nil, float64(ms.NumGC), time.Time{})
+	fs.add("snap_gc_pause_seconds_total", "Total time the GC paused snapteld.", counterType, nil, float64(ms.PauseTotalNs)/float64(time.Second), time.Time{})
+}
+
+func (e *Exporter) gatherScheduler(fs families) {
+	for _, ps := range e.tasks.WorkerPoolStats() {
+		labels := map[string]string{"pool": ps.Pool}
+		fs.add("snap_scheduler_workers", "Number of workers of the worker pool.", gaugeType, labels, float64(ps.Workers), time.Time{})
+		fs.add("snap_scheduler_queue_depth", "Number of jobs waiting in the queue of the worker pool.", gaugeType, labels, float64(ps.QueueDepth), time.Time{})
+		fs.add("snap_scheduler_queue_latency_seconds", "Average time the jobs waited for a worker of the pool.", gaugeType, labels, ps.QueueLatency.Seconds(), time.Time{})
+	}
+	states := map[string]int{}
+	for id, t := range e.tasks.GetTasks() {
+		states[t.State().String()]++
+		labels := map[string]string{"task_id": id, "task_name": t.GetName()}
+		fs.add("snap_task_hits_total", "Number of runs of the task.", counterType, labels, float64(t.HitCount()), time.Time{})
+		fs.add("snap_task_misses_total", "Number of runs of the task missed.", counterType, labels, float64(t.MissedCount()), time.Time{})
+		fs.add("snap_task_failures_total", "Number of runs of the task which failed.", counterType, labels, float64(t.FailedCount()), time.Time{})
+	}
+	for state, n := range states {
+		fs.add("snap_tasks", "Number of tasks by state.", gaugeType, map[string]string{"state": state}, float64(n), time.Time{})
+	}
+}
+
+func (e *Exporter) gatherPlugins(fs families) {
+	instances := map[string]int{}
+	for _, ap := range e.metrics.AvailablePlugins() {
+		instances[pluginKey(ap)]++
+	}
+	for _, p := range e.metrics.PluginCatalog() {
+		labels := map[string]string{
+			"plugin_type":    p.TypeName(),
+			"plugin_name":    p.Name(),
+			"plugin_version": strconv.Itoa(p.Version()),
+		}
+		fs.add("snap_plugin_instances", "Number of running instances of the plugin.", gaugeType, labels, float64(instances[pluginKey(p)]), time.Time{})
+		md, serr := e.metrics.PluginMetadata(p)
+		if serr != nil {
+			continue
+		}
+		fs.add("snap_plugin_calls_total", "Number of calls made to the plugin.", counterType, labels, float64(md.Calls), time.Time{})
+		fs.add("snap_plugin_errors_total", "Number of calls made to the plugin which failed.", counterType, labels, float64(md.Errors), time.Time{})
+	}
+}
+
+// gatherCollected adds the numeric values last collected by the tasks, the
+// static elements of their namespaces name them and their dynamic elements and
+// tags label them
+func (e *Exporter) gatherCollected(fs families) {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	for taskID, mts := range e.lastCollected {
+		for _, m := range mts {
+			value, ok := toFloat(m.Data())
+			if !ok {
+				continue
+			}
+			var elements []string
+			labels := map[string]string{}
+			for k, v := range m.Tags() {
+				labels[labelName(k)] = v
+			}
+			for _, el := range m.Namespace() {
+				if el.IsDynamic() {
+					labels[labelName(el.Name)] = el.Value
+					continue
+				}
+				elements = append(elements, el.Value)
+			}
+			labels["task_id"] = taskID
+			fs.add(metricName(elements...), "Last value collected of /"+strings.Join(elements, "/")+".", untypedType, labels, value, m.Timestamp())
+		}
+	}
+}
+
+func pluginKey(p core.Plugin) string {
+	return fmt.Sprintf("%s"+core.Separator+"%s"+core.Separator+"%d", p.TypeName(), p.Name(), p.Version())
+}
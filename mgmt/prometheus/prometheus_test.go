@@ -0,0 +1,200 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prometheus
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/intelsdi-x/gomit"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/scheduler_event"
+	"github.com/intelsdi-x/snap/core/serror"
+)
+
+type mockPlugin struct {
+	core.CatalogedPlugin
+	typeName string
+	name     string
+	version  int
+}
+
+func (p *mockPlugin) TypeName() string { return p.typeName }
+func (p *mockPlugin) Name() string     { return p.name }
+func (p *mockPlugin) Version() int     { return p.version }
+
+type mockAvailablePlugin struct {
+	core.AvailablePlugin
+	*mockPlugin
+}
+
+func (p mockAvailablePlugin) TypeName() string { return p.mockPlugin.TypeName() }
+func (p mockAvailablePlugin) Name() string     { return p.mockPlugin.Name() }
+func (p mockAvailablePlugin) Version() int     { return p.mockPlugin.Version() }
+
+type mockMetricManager struct {
+	plugin *mockPlugin
+}
+
+func (m *mockMetricManager) PluginCatalog() core.PluginCatalog {
+	return core.PluginCatalog{m.plugin}
+}
+
+func (m *mockMetricManager) AvailablePlugins() []core.AvailablePlugin {
+	return []core.AvailablePlugin{mockAvailablePlugin{mockPlugin: m.plugin}, mockAvailablePlugin{mockPlugin: m.plugin}}
+}
+
+func (m *mockMetricManager) PluginMetadata(core.Plugin) (*core.PluginMetadata, serror.SnapError) {
+	return &core.PluginMetadata{Calls: 10, Errors: 2}, nil
+}
+
+type mockTask struct {
+	core.Task
+	name string
+}
+
+func (t *mockTask) GetName() string       { return t.name }
+func (t *mockTask) State() core.TaskState { return core.TaskSpinning }
+func (t *mockTask) HitCount() uint        { return 7 }
+func (t *mockTask) MissedCount() uint     { return 1 }
+func (t *mockTask) FailedCount() uint     { return 0 }
+
+type mockTaskManager struct{}
+
+func (m *mockTaskManager) GetTasks() map[string]core.Task {
+	return map[string]core.Task{"1": &mockTask{name: "task-one"}}
+}
+
+func (m *mockTaskManager) WorkerPoolStats() []core.WorkerPoolStats {
+	return []core.WorkerPoolStats{{Pool: "collect", Workers: 4, QueueDepth: 3, QueueLatency: 500 * time.Millisecond}}
+}
+
+type mockMetric struct {
+	core.Metric
+	namespace core.Namespace
+	tags      map[string]string
+	data      interface{}
+}
+
+func (m *mockMetric) Namespace() core.Namespace { return m.namespace }
+func (m *mockMetric) Tags() map[string]string   { return m.tags }
+func (m *mockMetric) Data() interface{}         { return m.data }
+func (m *mockMetric) Timestamp() time.Time      { return time.Unix(1, 0) }
+
+func scrape(e *Exporter) string {
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	So(w.Header().Get("Content-Type"), ShouldEqual, ContentType)
+	return w.Body.String()
+}
+
+func TestExposition(t *testing.T) {
+	Convey("The families are written sorted in the text exposition format", t, func() {
+		fs := families{}
+		fs.add("b_total", "B\\help\nline", counterType, map[string]string{"z": "1", "a": "say \"hi\"\n"}, 2, time.Time{})
+		fs.add("a", "", untypedType, nil, 0.5, time.Unix(10, 0))
+		var buf bytes.Buffer
+		So(fs.write(&buf), ShouldBeNil)
+		So(buf.String(), ShouldEqual, "# TYPE a untyped\n"+
+			"a 0.5 10000\n"+
+			"# HELP b_total B\\\\help\\nline\n"+
+			"# TYPE b_total counter\n"+
+			"b_total{a=\"say \\\"hi\\\"\\n\",z=\"1\"} 2\n")
+	})
+	Convey("The names are sanitized", t, func() {
+		So(metricName("intel", "cpu-0", "usage.percent"), ShouldEqual, "intel_cpu_0_usage_percent")
+		So(metricName("1st"), ShouldEqual, "_st")
+		So(labelName("plugin-running-on"), ShouldEqual, "plugin_running_on")
+		So(labelName("__name__"), ShouldEqual, "_name__")
+	})
+	Convey("Only the numeric data is exposed", t, func() {
+		_, ok := toFloat("42")
+		So(ok, ShouldBeFalse)
+		v, ok := toFloat(uint32(42))
+		So(ok, ShouldBeTrue)
+		So(v, ShouldEqual, 42)
+	})
+}
+
+func TestExporter(t *testing.T) {
+	Convey("Given an exporter of the statistics of snapteld", t, func() {
+		mp := &mockPlugin{typeName: "collector", name: "mock", version: 2}
+		e := New(&mockMetricManager{plugin: mp}, &mockTaskManager{}, false)
+		out := scrape(e)
+		Convey("the runtime statistics are exposed", func() {
+			So(out, ShouldContainSubstring, "# TYPE snap_goroutines gauge\nsnap_goroutines ")
+		})
+		Convey("the statistics of the scheduler are exposed", func() {
+			So(out, ShouldContainSubstring, "snap_scheduler_queue_depth{pool=\"collect\"} 3\n")
+			So(out, ShouldContainSubstring, "snap_scheduler_queue_latency_seconds{pool=\"collect\"} 0.5\n")
+			So(out, ShouldContainSubstring, "snap_task_hits_total{task_id=\"1\",task_name=\"task-one\"} 7\n")
+			So(out, ShouldContainSubstring, "snap_tasks{state=\"Running\"} 1\n")
+		})
+		Convey("the statistics of the plugins are exposed", func() {
+			labels := "{plugin_name=\"mock\",plugin_type=\"collector\",plugin_version=\"2\"}"
+			So(out, ShouldContainSubstring, "snap_plugin_instances"+labels+" 2\n")
+			So(out, ShouldContainSubstring, "snap_plugin_calls_total"+labels+" 10\n")
+			So(out, ShouldContainSubstring, "snap_plugin_errors_total"+labels+" 2\n")
+		})
+		Convey("the collected values aren't exposed", func() {
+			e.HandleGomitEvent(gomit.Event{Body: collectedEvent("1")})
+			So(scrape(e), ShouldNotContainSubstring, "intel_mock_foo")
+		})
+	})
+	Convey("Given an exporter of the collected values", t, func() {
+		e := New(nil, nil, true)
+		e.HandleGomitEvent(gomit.Event{Body: collectedEvent("1")})
+		Convey("the numeric values last collected are exposed", func() {
+			out := scrape(e)
+			So(out, ShouldContainSubstring, "# TYPE intel_mock_foo untyped\n")
+			So(out, ShouldContainSubstring, "intel_mock_foo{host=\"host1\",plugin_running_on=\"node\",task_id=\"1\"} 3 1000\n")
+			So(out, ShouldNotContainSubstring, "intel_mock_bar")
+		})
+		Convey("the values of a stopped task are forgotten", func() {
+			e.HandleGomitEvent(gomit.Event{Body: &scheduler_event.TaskStoppedEvent{TaskID: "1"}})
+			So(scrape(e), ShouldNotContainSubstring, "intel_mock_foo")
+		})
+	})
+}
+
+func collectedEvent(taskID string) *scheduler_event.MetricCollectedEvent {
+	foo := core.NewNamespace("intel", "mock").AddDynamicElement("host", "host name").AddStaticElement("foo")
+	foo[2].Value = "host1"
+	return &scheduler_event.MetricCollectedEvent{
+		TaskID: taskID,
+		Metrics: []core.Metric{
+			&mockMetric{
+				namespace: foo,
+				tags:      map[string]string{"plugin_running_on": "node"},
+				data:      3,
+			},
+			&mockMetric{
+				namespace: core.NewNamespace("intel", "mock", "bar"),
+				data:      "not a number",
+			},
+		},
+	}
+}
@@ -10,9 +10,15 @@ type API interface {
 	BindTaskManager(Tasks)
 	BindTribeManager(Tribe)
 	BindConfigManager(Config)
+	BindNotifier(Notifier)
+	BindLogging(Logging)
+	BindConfigReloader(ConfigReloader)
 }
 
 type Route struct {
 	Method, Path string
 	Handle       httprouter.Handle
+	// Body and Response are samples of the bodies of the request and of the
+	// response of the route, describing it in the API spec (GET /v1/spec)
+	Body, Response interface{}
 }
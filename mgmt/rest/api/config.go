@@ -13,3 +13,20 @@ type Config interface {
 	DeletePluginConfigDataNodeField(pluginType core.PluginType, name string, ver int, fields ...string) cdata.ConfigDataNode
 	DeletePluginConfigDataNodeFieldAll(fields ...string) cdata.ConfigDataNode
 }
+
+// ConfigRedactor is implemented by the metric managers redacting the values
+// of the config of the plugins marked sensitive by their config policies
+type ConfigRedactor interface {
+	RedactConfig(cdata.ConfigDataNode) cdata.ConfigDataNode
+}
+
+// ConfigReloader applies the settings of snapteld changed while it's running
+type ConfigReloader interface {
+	// ReloadConfig applies the settings, in the format of the config file, and
+	// returns the names of the settings changed
+	ReloadConfig(settings []byte) ([]string, error)
+	// EffectiveConfig returns the settings snapteld runs with, in the format
+	// of the config file, and the layers of the configuration they come from
+	// by their names, e.g. restapi.port
+	EffectiveConfig() (map[string]interface{}, map[string]string)
+}
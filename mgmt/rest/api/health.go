@@ -0,0 +1,10 @@
+package api
+
+import (
+	"github.com/intelsdi-x/snap/core"
+)
+
+// HealthReporter is a subsystem of snapteld reporting its health
+type HealthReporter interface {
+	Health() core.SubsystemHealth
+}
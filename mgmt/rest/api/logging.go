@@ -0,0 +1,8 @@
+package api
+
+type Logging interface {
+	Level() string
+	ModuleLevels() map[string]string
+	SetLevel(module, level string) error
+	ResetLevel(module string) error
+}
@@ -8,6 +8,7 @@ import (
 type Metrics interface {
 	MetricCatalog() ([]core.CatalogedMetric, error)
 	FetchMetrics(core.Namespace, int) ([]core.CatalogedMetric, error)
+	FetchMetricsPage(core.Namespace, int, int) ([]core.CatalogedMetric, int, error)
 	GetMetricVersions(core.Namespace) ([]core.CatalogedMetric, error)
 	GetMetric(core.Namespace, int) (core.CatalogedMetric, error)
 	Load(*core.RequestedPlugin) (core.CatalogedPlugin, serror.SnapError)
@@ -16,4 +17,8 @@ type Metrics interface {
 	AvailablePlugins() []core.AvailablePlugin
 	GetAutodiscoverPaths() []string
 	GetTempDir() string
+	PluginBlacklist() []core.BlacklistedPlugin
+	ClearPluginBlacklist(string) serror.SnapError
+	PluginMetadata(core.Plugin) (*core.PluginMetadata, serror.SnapError)
+	ReloadPluginConfig(core.PluginType, string, int) []serror.SnapError
 }
@@ -0,0 +1,12 @@
+package api
+
+import (
+	"github.com/intelsdi-x/snap/mgmt/notify"
+)
+
+type Notifier interface {
+	Webhooks() []notify.Webhook
+	Webhook(id string) (notify.Webhook, error)
+	AddWebhook(notify.Webhook) (notify.Webhook, error)
+	RemoveWebhook(id string) error
+}
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"time"
+
 	"github.com/intelsdi-x/snap/core"
 	"github.com/intelsdi-x/snap/core/serror"
 	"github.com/intelsdi-x/snap/pkg/schedule"
@@ -13,7 +15,17 @@ type Tasks interface {
 	GetTask(string) (core.Task, error)
 	StartTask(string) []serror.SnapError
 	StopTask(string) []serror.SnapError
+	PauseTask(string) []serror.SnapError
+	ResumeTask(string) []serror.SnapError
 	RemoveTask(string) error
 	WatchTask(string, core.TaskWatcherHandler) (core.TaskWatcherCloser, error)
 	EnableTask(string) (core.Task, error)
+	RunTask(*wmap.WorkflowMap, time.Duration, bool, ...core.TaskOption) (*core.TaskRun, []serror.SnapError)
+	NewTaskRunner(*wmap.WorkflowMap, time.Duration, bool, ...core.TaskOption) (core.TaskRunner, []serror.SnapError)
+	WorkerPoolStats() []core.WorkerPoolStats
+	BulkTaskOperation(string, core.TaskSelector) ([]core.TaskBulkResult, serror.SnapError)
+	DeadLetters() ([]core.DeadLetter, serror.SnapError)
+	GetDeadLetter(string) (core.DeadLetter, serror.SnapError)
+	ReplayDeadLetters([]string) ([]core.DeadLetterResult, serror.SnapError)
+	PurgeDeadLetters([]string) ([]core.DeadLetterResult, serror.SnapError)
 }
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"time"
+
 	"github.com/intelsdi-x/snap/core/serror"
 	"github.com/intelsdi-x/snap/mgmt/tribe/agreement"
 )
@@ -8,10 +10,20 @@ import (
 type Tribe interface {
 	GetAgreement(name string) (*agreement.Agreement, serror.SnapError)
 	GetAgreements() map[string]*agreement.Agreement
-	AddAgreement(name string) serror.SnapError
+	AddAgreement(name string, taskLabels map[string]string) serror.SnapError
+	SetTaskAssignment(agreementName, strategy string, replicas int) serror.SnapError
 	RemoveAgreement(name string) serror.SnapError
 	JoinAgreement(agreementName, memberName string) serror.SnapError
 	LeaveAgreement(agreementName, memberName string) serror.SnapError
 	GetMembers() []string
 	GetMember(name string) *agreement.Member
+	ExportAgreement(name string) (*agreement.Snapshot, serror.SnapError)
+	RestoreAgreement(s *agreement.Snapshot) (*agreement.RestoreReport, serror.SnapError)
+	SetTaskOverrides(agreementName, taskID string, overrides map[string]agreement.TaskOverride) serror.SnapError
+	MetricCatalog(agreementName string) (*agreement.Catalog, serror.SnapError)
+	Sync(taskID string, timeout time.Duration) serror.SnapError
+	ListKeys() ([]string, serror.SnapError)
+	InstallKey(key string) serror.SnapError
+	UseKey(key string) serror.SnapError
+	RemoveKey(key string) serror.SnapError
 }
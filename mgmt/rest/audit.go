@@ -0,0 +1,306 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/intelsdi-x/gomit"
+	"github.com/julienschmidt/httprouter"
+	"github.com/urfave/negroni"
+
+	"github.com/intelsdi-x/snap/core/tribe_event"
+	"github.com/intelsdi-x/snap/mgmt/rest/api"
+	"github.com/intelsdi-x/snap/mgmt/rest/v2"
+)
+
+const (
+	auditPath = "/v2/audit"
+	// defaultAuditLimit is the number of records returned by a query without
+	// a limit
+	defaultAuditLimit = 100
+
+	AuditSuccess = "success"
+	AuditFailure = "failure"
+)
+
+var ErrInvalidAuditQuery = errors.New("invalid audit query, since and until are RFC3339 timestamps and limit a positive integer")
+
+// AuditRecord is a mutating request of the REST API or an intent of the
+// tribe, the records are appended to the audit log as JSON, one per line
+type AuditRecord struct {
+	Time time.Time `json:"time"`
+	// Identity is the common name of the client certificate or the name of
+	// the token of the request, Admin is true for the password of snapteld
+	Identity   string `json:"identity,omitempty"`
+	Admin      bool   `json:"admin,omitempty"`
+	RemoteAddr string `json:"remote_addr,omitempty"`
+	Method     string `json:"method,omitempty"`
+	Path       string `json:"path,omitempty"`
+	Route      string `json:"route,omitempty"`
+	// BodySHA256 is the hash of the body of the request as received
+	BodySHA256 string `json:"body_sha256,omitempty"`
+	Status     int    `json:"status,omitempty"`
+	Result     string `json:"result"`
+	// Intent is set instead of the request for the intents of the tribe
+	Intent *AuditIntent `json:"intent,omitempty"`
+}
+
+// AuditIntent is an intent of the tribe handled by the local member, gossiped
+// by another member or created by a request to the local REST API
+type AuditIntent struct {
+	Type      string `json:"type"`
+	UUID      string `json:"uuid"`
+	LTime     uint64 `json:"ltime"`
+	Agreement string `json:"agreement,omitempty"`
+	Subject   string `json:"subject,omitempty"`
+}
+
+// AuditResponse returns the records of the audit log matching a query,
+// oldest first
+type AuditResponse struct {
+	Records []AuditRecord `json:"records"`
+}
+
+// auditLog is the append-only log of the mutating requests
+type auditLog struct {
+	path string
+	mu   sync.Mutex
+	f    *os.File
+}
+
+func newAuditLog(path string) (*auditLog, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &auditLog{path: path, f: f}, nil
+}
+
+// record appends a record to the log, it's synced to the disk before the
+// request completes
+func (a *auditLog) record(rec AuditRecord) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, err := a.f.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	return a.f.Sync()
+}
+
+func (a *auditLog) close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.f.Close()
+}
+
+// auditQuery selects the records of the log, the zero values match every
+// record
+type auditQuery struct {
+	identity string
+	method   string
+	result   string
+	since    time.Time
+	until    time.Time
+	limit    int
+}
+
+func parseAuditQuery(r *http.Request) (auditQuery, error) {
+	q := r.URL.Query()
+	aq := auditQuery{
+		identity: q.Get("identity"),
+		method:   q.Get("method"),
+		result:   q.Get("result"),
+		limit:    defaultAuditLimit,
+	}
+	var err error
+	if v := q.Get("since"); v != "" {
+		if aq.since, err = time.Parse(time.RFC3339, v); err != nil {
+			return aq, ErrInvalidAuditQuery
+		}
+	}
+	if v := q.Get("until"); v != "" {
+		if aq.until, err = time.Parse(time.RFC3339, v); err != nil {
+			return aq, ErrInvalidAuditQuery
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if aq.limit, err = strconv.Atoi(v); err != nil || aq.limit < 1 {
+			return aq, ErrInvalidAuditQuery
+		}
+	}
+	return aq, nil
+}
+
+func (aq auditQuery) matches(rec AuditRecord) bool {
+	return (aq.identity == "" || aq.identity == rec.Identity) &&
+		(aq.method == "" || aq.method == rec.Method) &&
+		(aq.result == "" || aq.result == rec.Result) &&
+		(aq.since.IsZero() || !rec.Time.Before(aq.since)) &&
+		(aq.until.IsZero() || rec.Time.Before(aq.until))
+}
+
+// query returns the latest records matching the query, oldest first
+func (a *auditLog) query(aq auditQuery) ([]AuditRecord, error) {
+	f, err := os.Open(a.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	records := []AuditRecord{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			// a record torn by a crash doesn't hide the following ones
+			continue
+		}
+		if !aq.matches(rec) {
+			continue
+		}
+		records = append(records, rec)
+		if len(records) > aq.limit {
+			records = records[1:]
+		}
+	}
+	return records, scanner.Err()
+}
+
+// audited returns the handle of a route recording its requests to the audit
+// log, the requests only reading the resources aren't recorded
+func (s *Server) audited(route api.Route, h httprouter.Handle) httprouter.Handle {
+	if s.audit == nil || route.Method == "GET" || route.Method == "HEAD" {
+		return h
+	}
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		start := time.Now().UTC()
+		id, _ := r.Context().Value(identityKey{}).(identity)
+		body := &hashingBody{ReadCloser: r.Body, hash: sha256.New()}
+		r.Body = body
+		h(w, r, p)
+		// the part of the body the handle didn't read is hashed as well
+		io.Copy(ioutil.Discard, body)
+
+		status := w.(negroni.ResponseWriter).Status()
+		if status == 0 {
+			// nothing was written, the response is completed with 200
+			status = http.StatusOK
+		}
+		result := AuditSuccess
+		if status >= 400 {
+			result = AuditFailure
+		}
+		err := s.audit.record(AuditRecord{
+			Time:       start,
+			Identity:   id.name,
+			Admin:      id.admin,
+			RemoteAddr: r.RemoteAddr,
+			Method:     r.Method,
+			Path:       r.URL.Path,
+			Route:      route.Path,
+			BodySHA256: hex.EncodeToString(body.hash.Sum(nil)),
+			Status:     status,
+			Result:     result,
+		})
+		if err != nil {
+			restLogger.WithFields(log.Fields{
+				"_block": "audited",
+				"method": r.Method,
+				"path":   r.URL.Path,
+				"error":  err,
+			}).Error("failed to record the request to the audit log")
+		}
+	}
+}
+
+// HandleGomitEvent records the intents handled by the tribe to the audit log
+func (s *Server) HandleGomitEvent(e gomit.Event) {
+	v, ok := e.Body.(*tribe_event.IntentReceivedEvent)
+	if !ok || s.audit == nil {
+		return
+	}
+	err := s.audit.record(AuditRecord{
+		Time:   time.Now().UTC(),
+		Result: AuditSuccess,
+		Intent: &AuditIntent{
+			Type:      v.Type,
+			UUID:      v.UUID,
+			LTime:     v.LTime,
+			Agreement: v.Agreement,
+			Subject:   v.Subject,
+		},
+	})
+	if err != nil {
+		restLogger.WithFields(log.Fields{
+			"_block": "handle-gomit-event",
+			"intent": v.Type,
+			"uuid":   v.UUID,
+			"error":  err,
+		}).Error("failed to record the intent to the audit log")
+	}
+}
+
+// hashingBody hashes a body as it's read
+type hashingBody struct {
+	io.ReadCloser
+	hash hash.Hash
+}
+
+func (b *hashingBody) Read(p []byte) (int, error) {
+	if b.ReadCloser == nil {
+		return 0, io.EOF
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.hash.Write(p[:n])
+	return n, err
+}
+
+// getAudit returns the records of the audit log matching the query of the
+// request
+func (s *Server) getAudit(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	aq, err := parseAuditQuery(r)
+	if err != nil {
+		v2.Write(400, v2.FromError(err), w)
+		return
+	}
+	records, err := s.audit.query(aq)
+	if err != nil {
+		v2.Write(500, v2.FromError(err), w)
+		return
+	}
+	v2.Write(200, AuditResponse{Records: records}, w)
+}
@@ -0,0 +1,152 @@
+// +build medium
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/intelsdi-x/gomit"
+	"github.com/julienschmidt/httprouter"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/urfave/negroni"
+
+	"github.com/intelsdi-x/snap/core/tribe_event"
+	"github.com/intelsdi-x/snap/mgmt/rest/api"
+)
+
+func TestAudit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snap-rest-audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	const task = `{"name": "task-1", "schedule": {"type": "simple", "interval": "1s"}}`
+	// created reads the start of the body only
+	created := func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		r.Body.Read(make([]byte, 8))
+		w.WriteHeader(201)
+	}
+	notFound := func(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) { w.WriteHeader(404) }
+
+	serve := func(s *Server, route api.Route, id identity, body string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(route.Method, "http://localhost"+route.Path, strings.NewReader(body))
+		r.RemoteAddr = "10.1.2.3:50000"
+		rec := httptest.NewRecorder()
+		s.audited(route, route.Handle)(negroni.NewResponseWriter(rec), withIdentity(r, id), nil)
+		return rec
+	}
+	query := func(s *Server, rawQuery string) (int, AuditResponse) {
+		r, _ := http.NewRequest("GET", "http://localhost"+auditPath+"?"+rawQuery, nil)
+		rec := httptest.NewRecorder()
+		s.getAudit(negroni.NewResponseWriter(rec), r, nil)
+		var resp AuditResponse
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec.Code, resp
+	}
+
+	Convey("Auditing the REST API", t, func() {
+		audit, err := newAuditLog(filepath.Join(dir, "audit.log"))
+		So(err, ShouldBeNil)
+		s := &Server{audit: audit}
+
+		serve(s, api.Route{Method: "POST", Path: "/v2/tasks", Handle: created}, identity{name: "ci"}, task)
+		serve(s, api.Route{Method: "GET", Path: "/v2/tasks", Handle: created}, identity{name: "ci"}, "")
+		serve(s, api.Route{Method: "DELETE", Path: "/v2/plugins/:type", Handle: notFound}, identity{admin: true}, "")
+		audit.close()
+		defer os.Remove(audit.path)
+
+		Convey("records the mutating requests only", func() {
+			code, resp := query(s, "")
+			So(code, ShouldEqual, 200)
+			So(resp.Records, ShouldHaveLength, 2)
+		})
+		Convey("records who did what, when and its result", func() {
+			_, resp := query(s, "")
+			rec := resp.Records[0]
+			sum := sha256.Sum256([]byte(task))
+			So(rec.Identity, ShouldEqual, "ci")
+			So(rec.RemoteAddr, ShouldEqual, "10.1.2.3:50000")
+			So(rec.Method, ShouldEqual, "POST")
+			So(rec.Route, ShouldEqual, "/v2/tasks")
+			So(rec.BodySHA256, ShouldEqual, hex.EncodeToString(sum[:]))
+			So(rec.Status, ShouldEqual, 201)
+			So(rec.Result, ShouldEqual, AuditSuccess)
+			So(rec.Time.IsZero(), ShouldBeFalse)
+
+			rec = resp.Records[1]
+			So(rec.Admin, ShouldBeTrue)
+			So(rec.Path, ShouldEqual, "/v2/plugins/:type")
+			So(rec.Result, ShouldEqual, AuditFailure)
+		})
+		Convey("queries the records", func() {
+			_, resp := query(s, "identity=ci")
+			So(resp.Records, ShouldHaveLength, 1)
+			_, resp = query(s, "result=failure&method=DELETE")
+			So(resp.Records, ShouldHaveLength, 1)
+			So(resp.Records[0].Admin, ShouldBeTrue)
+			_, resp = query(s, "limit=1")
+			So(resp.Records, ShouldHaveLength, 1)
+			So(resp.Records[0].Method, ShouldEqual, "DELETE")
+			_, resp = query(s, "until=2017-01-01T00:00:00Z")
+			So(resp.Records, ShouldBeEmpty)
+		})
+		Convey("records the intents of the tribe", func() {
+			audit, err := newAuditLog(filepath.Join(dir, "intents.log"))
+			So(err, ShouldBeNil)
+			defer os.Remove(audit.path)
+			s := &Server{audit: audit}
+			s.HandleGomitEvent(gomit.Event{Body: &tribe_event.IntentReceivedEvent{
+				Type:      "Add plugin",
+				UUID:      "c0a8",
+				LTime:     12,
+				Agreement: "prod",
+				Subject:   "collector:psutil:9",
+			}})
+			// the other events aren't recorded
+			s.HandleGomitEvent(gomit.Event{Body: &tribe_event.MemberJoinedEvent{Name: "member-2"}})
+			audit.close()
+
+			_, resp := query(s, "")
+			So(resp.Records, ShouldHaveLength, 1)
+			rec := resp.Records[0]
+			So(rec.Result, ShouldEqual, AuditSuccess)
+			So(rec.Method, ShouldBeEmpty)
+			So(rec.Intent, ShouldResemble, &AuditIntent{Type: "Add plugin", UUID: "c0a8", LTime: 12, Agreement: "prod", Subject: "collector:psutil:9"})
+		})
+		Convey("rejects the invalid queries", func() {
+			code, _ := query(s, "since=yesterday")
+			So(code, ShouldEqual, 400)
+			code, _ = query(s, "limit=0")
+			So(code, ShouldEqual, 400)
+		})
+	})
+}
@@ -0,0 +1,202 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/subtle"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+const bearerPrefix = "Bearer "
+
+var (
+	ErrBadClientCA          = errors.New("Invalid client CA certificate given")
+	ErrClientCAWithoutHTTPS = errors.New("Client certificates can only be verified when HTTPS is enabled")
+)
+
+// authenticated returns the identity of a request presenting a verified
+// client certificate, a valid bearer token or the password of the REST API
+func (s *Server) authenticated(r *http.Request) (identity, bool) {
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+		return identity{name: r.TLS.VerifiedChains[0][0].Subject.CommonName}, true
+	}
+	s.authMutex.RLock()
+	tokens, tokenFile, authpwd := s.authTokens, s.authTokenFile, s.authpwd
+	s.authMutex.RUnlock()
+	if h := r.Header.Get("Authorization"); strings.HasPrefix(h, bearerPrefix) {
+		token := strings.TrimSpace(h[len(bearerPrefix):])
+		if name, ok := matchToken(token, tokens); ok {
+			return identity{name: name}, true
+		}
+		if tokenFile != nil {
+			if name, ok := matchToken(token, tokenFile.tokens()); ok {
+				return identity{name: name}, true
+			}
+		}
+		return identity{}, false
+	}
+	_, password, ok := r.BasicAuth()
+	if ok && authpwd != "" && subtle.ConstantTimeCompare([]byte(password), []byte(authpwd)) == 1 {
+		return identity{admin: true}, true
+	}
+	return identity{}, false
+}
+
+// ReloadAuth applies the authentication settings and the roles of the
+// configuration while the API is served. The client CA certificates are
+// only loaded when the server is created, they're verified by its listener
+func (s *Server) ReloadAuth(cfg *Config) error {
+	var tf *tokenFile
+	if cfg.RestAuthTokenFile != "" {
+		var err error
+		if tf, err = newTokenFile(cfg.RestAuthTokenFile); err != nil {
+			return err
+		}
+	}
+	roles, err := newRoles(cfg.RestAuthRoles)
+	if err != nil {
+		return err
+	}
+	s.authMutex.Lock()
+	defer s.authMutex.Unlock()
+	s.authTokens = cfg.RestAuthTokens
+	s.authTokenFile = tf
+	s.authpwd = cfg.RestAuthPassword
+	s.roles = roles
+	s.auth = cfg.RestAuth || len(s.authTokens) > 0 || s.authTokenFile != nil || s.clientCAs != nil
+	restLogger.WithFields(log.Fields{
+		"_block": "reload-auth",
+		"auth":   s.auth,
+		"tokens": len(s.authTokens),
+		"roles":  len(s.roles),
+	}).Info("REST API authentication reloaded")
+	return nil
+}
+
+// matchToken returns the name of the token among the tokens, written as
+// "name:token" or "token" when the token has no name. All the tokens are
+// compared so the time taken doesn't tell which one matched
+func matchToken(token string, tokens []string) (string, bool) {
+	var name string
+	valid := 0
+	for _, t := range tokens {
+		n, tok := "", t
+		if i := strings.Index(t, ":"); i >= 0 {
+			n, tok = t[:i], t[i+1:]
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(tok)) == 1 {
+			name, valid = n, 1
+		}
+	}
+	return name, token != "" && valid == 1
+}
+
+// tokenFile holds the bearer tokens read from a file, one per line, the file
+// is read again when it's modified so the tokens can be rotated without
+// restarting snapteld
+type tokenFile struct {
+	path    string
+	mutex   sync.Mutex
+	modTime time.Time
+	size    int64
+	toks    []string
+}
+
+func newTokenFile(path string) (*tokenFile, error) {
+	f := &tokenFile{path: path}
+	if err := f.load(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// tokens returns the tokens of the file, read again when the file has changed
+// since it was last read
+func (f *tokenFile) tokens() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if err := f.load(); err != nil {
+		// the tokens last read are kept until the file can be read again
+		restLogger.WithFields(log.Fields{
+			"_block": "tokens",
+			"path":   f.path,
+		}).Warn(err)
+	}
+	return f.toks
+}
+
+func (f *tokenFile) load() error {
+	fi, err := os.Stat(f.path)
+	if err != nil {
+		return err
+	}
+	if fi.ModTime().Equal(f.modTime) && fi.Size() == f.size {
+		return nil
+	}
+	b, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return err
+	}
+	toks := []string{}
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		// blank lines and comments are skipped
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		toks = append(toks, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	f.toks, f.modTime, f.size = toks, fi.ModTime(), fi.Size()
+	restLogger.WithFields(log.Fields{
+		"_block": "tokens",
+		"path":   f.path,
+		"tokens": len(toks),
+	}).Info("REST API tokens loaded")
+	return nil
+}
+
+// loadClientCAs reads the CA certificates the client certificates are
+// verified with
+func loadClientCAs(path string) (*x509.CertPool, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(b) {
+		return nil, ErrBadClientCA
+	}
+	return pool, nil
+}
@@ -0,0 +1,157 @@
+// +build medium
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAuthenticated(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snap-rest-auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "tokens")
+	if err := ioutil.WriteFile(path, []byte("# rotated daily\nfile-token\n\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tf, err := newTokenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{auth: true, authpwd: "changeme", authTokens: []string{"static-token", "grafana:named-token"}, authTokenFile: tf}
+
+	accepted := func(s *Server, r *http.Request) bool {
+		_, ok := s.authenticated(r)
+		return ok
+	}
+	request := func(header, value string) *http.Request {
+		r, _ := http.NewRequest("GET", "http://localhost/v1/plugins", nil)
+		if header != "" {
+			r.Header.Set(header, value)
+		}
+		return r
+	}
+
+	Convey("Authenticating REST API requests", t, func() {
+		Convey("accepts the static tokens", func() {
+			So(accepted(s, request("Authorization", "Bearer static-token")), ShouldBeTrue)
+		})
+		Convey("identifies the named tokens", func() {
+			id, ok := s.authenticated(request("Authorization", "Bearer named-token"))
+			So(ok, ShouldBeTrue)
+			So(id.name, ShouldEqual, "grafana")
+			So(accepted(s, request("Authorization", "Bearer grafana:named-token")), ShouldBeFalse)
+		})
+		Convey("accepts the tokens of the token file", func() {
+			So(accepted(s, request("Authorization", "Bearer file-token")), ShouldBeTrue)
+		})
+		Convey("rejects the other tokens", func() {
+			So(accepted(s, request("Authorization", "Bearer # rotated daily")), ShouldBeFalse)
+			So(accepted(s, request("Authorization", "Bearer ")), ShouldBeFalse)
+			So(accepted(s, request("Authorization", "Bearer changeme")), ShouldBeFalse)
+		})
+		Convey("accepts the password", func() {
+			r := request("", "")
+			r.SetBasicAuth("snap", "changeme")
+			id, ok := s.authenticated(r)
+			So(ok, ShouldBeTrue)
+			So(id.admin, ShouldBeTrue)
+			r.SetBasicAuth("snap", "noway")
+			So(accepted(s, r), ShouldBeFalse)
+		})
+		Convey("rejects an empty password when none is set", func() {
+			r := request("", "")
+			r.SetBasicAuth("snap", "")
+			So(accepted(&Server{auth: true, authTokens: []string{"static-token"}}, r), ShouldBeFalse)
+		})
+		Convey("accepts the verified client certificates", func() {
+			r := request("", "")
+			cert := &x509.Certificate{Subject: pkix.Name{CommonName: "ops.example.com"}}
+			r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+			id, ok := s.authenticated(r)
+			So(ok, ShouldBeTrue)
+			So(id.name, ShouldEqual, "ops.example.com")
+			r.TLS = &tls.ConnectionState{}
+			So(accepted(s, r), ShouldBeFalse)
+		})
+		Convey("reads the token file again when it's rotated", func() {
+			So(ioutil.WriteFile(path, []byte("rotated-token\n"), 0600), ShouldBeNil)
+			// the modification time is moved for the filesystems with a
+			// coarse resolution
+			later := time.Now().Add(time.Minute)
+			So(os.Chtimes(path, later, later), ShouldBeNil)
+			So(accepted(s, request("Authorization", "Bearer rotated-token")), ShouldBeTrue)
+			So(accepted(s, request("Authorization", "Bearer file-token")), ShouldBeFalse)
+		})
+	})
+}
+
+func TestReloadAuth(t *testing.T) {
+	s := &Server{authpwd: "changeme"}
+	request := func(token string) *http.Request {
+		r, _ := http.NewRequest("GET", "http://localhost/v1/plugins", nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		return r
+	}
+
+	Convey("Reloading the REST API authentication", t, func() {
+		Convey("enables it with the new tokens and roles", func() {
+			err := s.ReloadAuth(&Config{
+				RestAuthTokens: []string{"grafana:new-token"},
+				RestAuthRoles:  map[string][]string{"grafana": {RoleReadOnly}},
+			})
+			So(err, ShouldBeNil)
+			So(s.auth, ShouldBeTrue)
+			id, ok := s.authenticated(request("new-token"))
+			So(ok, ShouldBeTrue)
+			So(s.allowed(id, RoleReadOnly), ShouldBeTrue)
+			So(s.allowed(id, RoleTaskOperator), ShouldBeFalse)
+		})
+		Convey("disables it without credentials", func() {
+			So(s.ReloadAuth(&Config{}), ShouldBeNil)
+			So(s.auth, ShouldBeFalse)
+			So(s.allowed(identity{}, RolePluginAdmin), ShouldBeTrue)
+		})
+		Convey("keeps the settings when the new ones are invalid", func() {
+			So(s.ReloadAuth(&Config{RestAuthTokens: []string{"static-token"}}), ShouldBeNil)
+			err := s.ReloadAuth(&Config{
+				RestAuthTokens: []string{"other-token"},
+				RestAuthRoles:  map[string][]string{"grafana": {"admin"}},
+			})
+			So(err, ShouldNotBeNil)
+			_, ok := s.authenticated(request("static-token"))
+			So(ok, ShouldBeTrue)
+		})
+	})
+}
@@ -30,6 +30,7 @@ import (
 	"io"
 	"io/ioutil"
 	"mime/multipart"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -81,10 +82,20 @@ type Client struct {
 	Password string
 }
 
+// unixScheme prefixes the URLs of the unix sockets the API is served on,
+// e.g. unix:///var/run/snap/snapteld.sock
+const unixScheme = "unix://"
+
 // Checks validity of URL
 func parseURL(url string) error {
+	if strings.HasPrefix(url, unixScheme) {
+		if len(url) == len(unixScheme) {
+			return fmt.Errorf("URL %s is not in the format of unix://<path>", url)
+		}
+		return nil
+	}
 	if !govalidator.IsURL(url) || !strings.HasPrefix(url, "http") {
-		return fmt.Errorf("URL %s is not in the format of http(s)://<ip>:<port>", url)
+		return fmt.Errorf("URL %s is not in the format of http(s)://<ip>:<port> or unix://<path>", url)
 	}
 	return nil
 }
@@ -138,6 +149,10 @@ func New(url, ver string, insecure bool, opts ...metaOp) (*Client, error) {
 	} else {
 		t = secureTransport
 	}
+	prefix := url
+	if strings.HasPrefix(url, unixScheme) {
+		t, prefix = unixTransport(strings.TrimPrefix(url, unixScheme)), "http://unix"
+	}
 	c := &Client{
 		URL:     url,
 		Version: ver,
@@ -149,17 +164,28 @@ func New(url, ver string, insecure bool, opts ...metaOp) (*Client, error) {
 	for _, opt := range opts {
 		opt(c)
 	}
-	c.prefix = url + "/" + ver
+	c.prefix = prefix + "/" + ver
 	return c, nil
 }
 
+// unixTransport returns a transport connecting to the unix socket at path
+// whatever the host of the requests
+func unixTransport(path string) *http.Transport {
+	return &http.Transport{
+		Dial: func(_, _ string) (net.Conn, error) {
+			return net.Dial("unix", path)
+		},
+		IdleConnTimeout: time.Second,
+	}
+}
+
 // String returns the string representation of the content type given a content number.
 func (t contentType) String() string {
 	return contentTypes[t]
 }
 
 /*
-   Add's auth info to request if password is set.
+Add's auth info to request if password is set.
 */
 func addAuth(req *http.Request, username, password string) {
 	if password != "" {
@@ -419,10 +445,23 @@ func writePluginToWriter(pw io.WriteCloser, bufin []*bufio.Reader, writer *multi
 
 // Passthrough for tribe request to allow use of client auth.
 func (c *Client) TribeRequest() (*http.Response, error) {
+	return c.tribeRequest(nil)
+}
+
+// TribeRangeRequest is a tribe request for the bytes from start to end
+// inclusive of the resource, e.g. a chunk of a plugin binary.
+func (c *Client) TribeRangeRequest(start, end int64) (*http.Response, error) {
+	return c.tribeRequest(http.Header{"Range": {fmt.Sprintf("bytes=%d-%d", start, end)}})
+}
+
+func (c *Client) tribeRequest(header http.Header) (*http.Response, error) {
 	req, err := http.NewRequest("GET", c.URL, nil)
 	if err != nil {
 		return nil, err
 	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
 	addAuth(req, "snap", c.Password)
 	rsp, err := c.http.Do(req)
 	if err != nil {
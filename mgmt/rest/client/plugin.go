@@ -20,6 +20,7 @@ limitations under the License.
 package client
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
@@ -57,6 +58,33 @@ func (c *Client) LoadPlugin(p []string) *LoadPluginResult {
 	return r
 }
 
+// LoadPluginFromURL loads the plugin snapteld downloads from the HTTP(S) URL through an HTTP POST request.
+// The downloaded plugin has to match the hex encoded SHA-256 checksum.
+func (c *Client) LoadPluginFromURL(pluginURL, checkSum string) *LoadPluginResult {
+	r := new(LoadPluginResult)
+	b, err := json.Marshal(map[string]string{"url": pluginURL, "checksum": checkSum})
+	if err != nil {
+		r.Err = serror.New(err)
+		return r
+	}
+	resp, err := c.do("POST", "/plugins", ContentTypeJSON, b)
+	if err != nil {
+		r.Err = serror.New(err)
+		return r
+	}
+
+	switch resp.Meta.Type {
+	case rbody.PluginsLoadedType:
+		pl := resp.Body.(*rbody.PluginsLoaded)
+		r.LoadedPlugins = convertLoadedPlugins(pl.LoadedPlugins)
+	case rbody.ErrorType:
+		r.Err = serror.New(resp.Body.(*rbody.Error))
+	default:
+		r.Err = serror.New(ErrAPIResponseMetaType)
+	}
+	return r
+}
+
 // UnloadPlugin unloads a plugin given plugin type, name, and version through an HTTP DELETE request.
 // The unloaded plugin returns if succeeded. Otherwise, an error is returned.
 func (c *Client) UnloadPlugin(pluginType, name string, version int) *UnloadPluginResult {
@@ -25,6 +25,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
@@ -45,6 +46,13 @@ type Schedule struct {
 	// Count specifies the number of expected runs (defaults to 0 what means no limit, set to 1 means single run task).
 	// Count is supported by "simple" and "windowed" schedules
 	Count uint `json:"count,omitempty"`
+	// Timezone specifies the timezone the cron entry is evaluated in (e.g. "Europe/Paris").
+	// Timezone is supported by "cron" schedules
+	Timezone string `json:"timezone,omitempty"`
+	// Jitter specifies the percentage of the interval the first run is delayed by at most
+	// (the delay is derived from the hostname of snapteld).
+	// Jitter is supported by "simple" and "windowed" schedules
+	Jitter uint `json:"jitter,omitempty"`
 }
 
 // CreateTask creates a task given the schedule, workflow, task name, and task state.
@@ -59,6 +67,8 @@ func (c *Client) CreateTask(s *Schedule, wf *wmap.WorkflowMap, name string, dead
 			StartTimestamp: s.StartTimestamp,
 			StopTimestamp:  s.StopTimestamp,
 			Count:          s.Count,
+			Timezone:       s.Timezone,
+			Jitter:         s.Jitter,
 		},
 		Workflow:    wf,
 		Start:       startTask,
@@ -166,7 +176,7 @@ func (c *Client) WatchTask(id string) *WatchTasksResult {
 				case rbody.TaskWatchTaskDisabled:
 					r.EventChan <- ste
 					r.Close()
-				case rbody.TaskWatchTaskStopped, rbody.TaskWatchTaskEnded, rbody.TaskWatchTaskStarted, rbody.TaskWatchMetricEvent:
+				case rbody.TaskWatchTaskStopped, rbody.TaskWatchTaskEnded, rbody.TaskWatchTaskStarted, rbody.TaskWatchMetricEvent, rbody.TaskWatchTaskOverrun, rbody.TaskWatchTaskRun, rbody.TaskWatchCircuitBreaker:
 					r.EventChan <- ste
 				}
 			}
@@ -179,7 +189,17 @@ func (c *Client) WatchTask(id string) *WatchTasksResult {
 // A list of scheduled tasks returns if it succeeds.
 // Otherwise. an error is returned.
 func (c *Client) GetTasks() *GetTasksResult {
-	resp, err := c.do("GET", "/tasks", ContentTypeJSON, nil)
+	return c.GetTasksByLabels("")
+}
+
+// GetTasksByLabels retrieves the tasks with all the labels of a selector in
+// the form key=value[,key=value] through an HTTP GET call.
+func (c *Client) GetTasksByLabels(selector string) *GetTasksResult {
+	path := "/tasks"
+	if selector != "" {
+		path += "?labels=" + url.QueryEscape(selector)
+	}
+	resp, err := c.do("GET", path, ContentTypeJSON, nil)
 	if err != nil {
 		return &GetTasksResult{Err: err}
 	}
@@ -255,6 +275,51 @@ func (c *Client) StopTask(id string) *StopTasksResult {
 	}
 }
 
+// PauseTask pauses a running task given a task id, keeping its counters and
+// schedule position. It uses an HTTP PUT call.
+// The paused task id returns if it succeeds. Otherwise, an error is returned.
+func (c *Client) PauseTask(id string) *PauseTasksResult {
+	resp, err := c.do("PUT", fmt.Sprintf("/tasks/%v/pause", id), ContentTypeJSON)
+	if err != nil {
+		return &PauseTasksResult{Err: err}
+	}
+
+	if resp == nil {
+		return nil
+	}
+	switch resp.Meta.Type {
+	case rbody.ScheduledTaskPausedType:
+		// Success
+		return &PauseTasksResult{resp.Body.(*rbody.ScheduledTaskPaused), nil}
+	case rbody.ErrorType:
+		return &PauseTasksResult{Err: resp.Body.(*rbody.Error)}
+	default:
+		return &PauseTasksResult{Err: ErrAPIResponseMetaType}
+	}
+}
+
+// ResumeTask resumes a paused task given a task id. It uses an HTTP PUT call.
+// The resumed task id returns if it succeeds. Otherwise, an error is returned.
+func (c *Client) ResumeTask(id string) *ResumeTasksResult {
+	resp, err := c.do("PUT", fmt.Sprintf("/tasks/%v/resume", id), ContentTypeJSON)
+	if err != nil {
+		return &ResumeTasksResult{Err: err}
+	}
+
+	if resp == nil {
+		return nil
+	}
+	switch resp.Meta.Type {
+	case rbody.ScheduledTaskResumedType:
+		// Success
+		return &ResumeTasksResult{resp.Body.(*rbody.ScheduledTaskResumed), nil}
+	case rbody.ErrorType:
+		return &ResumeTasksResult{Err: resp.Body.(*rbody.Error)}
+	default:
+		return &ResumeTasksResult{Err: ErrAPIResponseMetaType}
+	}
+}
+
 // RemoveTask removes a task from the schedule tasks given a task id. It's through an HTTP DELETE call.
 // The removed task id returns if it succeeds. Otherwise, an error is returned.
 func (c *Client) RemoveTask(id string) *RemoveTasksResult {
@@ -334,6 +399,18 @@ type StopTasksResult struct {
 	Err error
 }
 
+// PauseTasksResult is the response from snap/client on a PauseTask call.
+type PauseTasksResult struct {
+	*rbody.ScheduledTaskPaused
+	Err error
+}
+
+// ResumeTasksResult is the response from snap/client on a ResumeTask call.
+type ResumeTasksResult struct {
+	*rbody.ScheduledTaskResumed
+	Err error
+}
+
 // RemoveTasksResult is the response from snap/client on a RemoveTask call.
 type RemoveTasksResult struct {
 	*rbody.ScheduledTaskRemoved
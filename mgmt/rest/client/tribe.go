@@ -22,8 +22,10 @@ package client
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 
 	"github.com/intelsdi-x/snap/mgmt/rest/v1/rbody"
+	"github.com/intelsdi-x/snap/mgmt/tribe/agreement"
 )
 
 // ListMembers retrieves a list of tribe members through an HTTP GET call.
@@ -85,9 +87,25 @@ func (c *Client) ListAgreements() *ListAgreementResult {
 // returns if it succeeds. Otherwise, an error is returned. Note that the newly added agreement
 // has no effect unless members join the agreement.
 func (c *Client) AddAgreement(name string) *AddAgreementResult {
+	return c.AddAgreementWithTaskLabels(name, nil)
+}
+
+// AddAgreementWithTaskLabels adds a tribe agreement which only shares the tasks
+// with all the given labels with its members.
+func (c *Client) AddAgreementWithTaskLabels(name string, taskLabels map[string]string) *AddAgreementResult {
+	return c.AddAgreementWithTaskAssignment(name, taskLabels, "", 0)
+}
+
+// AddAgreementWithTaskAssignment adds a tribe agreement whose leader assigns
+// each task to the given number of members with the given strategy: all,
+// replicas or hash.
+func (c *Client) AddAgreementWithTaskAssignment(name string, taskLabels map[string]string, strategy string, replicas int) *AddAgreementResult {
 	b, err := json.Marshal(struct {
-		Name string `json:"name"`
-	}{Name: name})
+		Name         string            `json:"name"`
+		TaskLabels   map[string]string `json:"task_labels,omitempty"`
+		TaskStrategy string            `json:"task_strategy,omitempty"`
+		TaskReplicas int               `json:"task_replicas,omitempty"`
+	}{Name: name, TaskLabels: taskLabels, TaskStrategy: strategy, TaskReplicas: replicas})
 	if err != nil {
 		return &AddAgreementResult{Err: err}
 	}
@@ -237,3 +255,183 @@ type LeaveAgreementResult struct {
 	*rbody.TribeLeaveAgreement
 	Err error
 }
+
+// ListTribeKeys retrieves the fingerprints of the keys the tribe gossip is encrypted with through
+// an HTTP GET call, the first one is the fingerprint of the primary key.
+func (c *Client) ListTribeKeys() *TribeKeysResult {
+	resp, err := c.do("GET", "/tribe/keys", ContentTypeJSON, nil)
+	if err != nil {
+		return &TribeKeysResult{Err: err}
+	}
+	switch resp.Meta.Type {
+	case rbody.TribeKeyListType:
+		return &TribeKeysResult{Keys: resp.Body.(*rbody.TribeKeyList).Keys}
+	case rbody.ErrorType:
+		return &TribeKeysResult{Err: resp.Body.(*rbody.Error)}
+	default:
+		return &TribeKeysResult{Err: ErrAPIResponseMetaType}
+	}
+}
+
+// InstallTribeKey installs the base64 encoded key on every tribe member through an HTTP POST call,
+// the gossip encrypted with it is decrypted once it's installed.
+func (c *Client) InstallTribeKey(key string) *TribeKeysResult {
+	return c.rotateTribeKey("POST", key, rbody.TribeKeyInstalledType)
+}
+
+// UseTribeKey makes the installed key the key encrypting the gossip of every tribe member through
+// an HTTP PUT call.
+func (c *Client) UseTribeKey(key string) *TribeKeysResult {
+	return c.rotateTribeKey("PUT", key, rbody.TribeKeyUsedType)
+}
+
+// RemoveTribeKey removes the key from every tribe member through an HTTP DELETE call, the primary key
+// can't be removed.
+func (c *Client) RemoveTribeKey(key string) *TribeKeysResult {
+	return c.rotateTribeKey("DELETE", key, rbody.TribeKeyRemovedType)
+}
+
+func (c *Client) rotateTribeKey(method, key, respType string) *TribeKeysResult {
+	b, err := json.Marshal(struct {
+		Key string `json:"key"`
+	}{Key: key})
+	if err != nil {
+		return &TribeKeysResult{Err: err}
+	}
+	resp, err := c.do(method, "/tribe/keys", ContentTypeJSON, b)
+	if err != nil {
+		return &TribeKeysResult{Err: err}
+	}
+	switch resp.Meta.Type {
+	case respType:
+		switch body := resp.Body.(type) {
+		case *rbody.TribeKeyInstalled:
+			return &TribeKeysResult{Keys: body.Keys}
+		case *rbody.TribeKeyUsed:
+			return &TribeKeysResult{Keys: body.Keys}
+		case *rbody.TribeKeyRemoved:
+			return &TribeKeysResult{Keys: body.Keys}
+		}
+		return &TribeKeysResult{Err: ErrAPIResponseMetaType}
+	case rbody.ErrorType:
+		return &TribeKeysResult{Err: resp.Body.(*rbody.Error)}
+	default:
+		return &TribeKeysResult{Err: ErrAPIResponseMetaType}
+	}
+}
+
+// TribeKeysResult is the response from snap/client on the calls rotating the tribe keys, the
+// fingerprints of the keys.
+type TribeKeysResult struct {
+	Keys []string
+	Err  error
+}
+
+// ExportAgreement retrieves the snapshot of an agreement given its name through an HTTP GET call,
+// its settings, members, plugins and tasks along with the definitions of the tasks.
+func (c *Client) ExportAgreement(name string) *ExportAgreementResult {
+	resp, err := c.do("GET", fmt.Sprintf("/tribe/agreements/%s/snapshot", name), ContentTypeJSON, nil)
+	if err != nil {
+		return &ExportAgreementResult{Err: err}
+	}
+	switch resp.Meta.Type {
+	case rbody.TribeAgreementSnapshotType:
+		return &ExportAgreementResult{resp.Body.(*rbody.TribeAgreementSnapshot), nil}
+	case rbody.ErrorType:
+		return &ExportAgreementResult{Err: resp.Body.(*rbody.Error)}
+	default:
+		return &ExportAgreementResult{Err: ErrAPIResponseMetaType}
+	}
+}
+
+// RestoreAgreement restores a snapshot as the agreement of the given name through an HTTP POST call.
+// The agreement returns along with the members, plugins and tasks of the snapshot missing on the tribe
+// if it succeeds. Otherwise, an error is returned.
+func (c *Client) RestoreAgreement(name string, snapshot *agreement.Snapshot) *RestoreAgreementResult {
+	b, err := json.Marshal(snapshot)
+	if err != nil {
+		return &RestoreAgreementResult{Err: err}
+	}
+	resp, err := c.do("POST", fmt.Sprintf("/tribe/agreements/%s/snapshot", name), ContentTypeJSON, b)
+	if err != nil {
+		return &RestoreAgreementResult{Err: err}
+	}
+	switch resp.Meta.Type {
+	case rbody.TribeAgreementRestoredType:
+		return &RestoreAgreementResult{resp.Body.(*rbody.TribeAgreementRestored), nil}
+	case rbody.ErrorType:
+		return &RestoreAgreementResult{Err: resp.Body.(*rbody.Error)}
+	default:
+		return &RestoreAgreementResult{Err: ErrAPIResponseMetaType}
+	}
+}
+
+// ExportAgreementResult is the response from snap/client on an ExportAgreement call.
+type ExportAgreementResult struct {
+	*rbody.TribeAgreementSnapshot
+	Err error
+}
+
+// RestoreAgreementResult is the response from snap/client on a RestoreAgreement call.
+type RestoreAgreementResult struct {
+	*rbody.TribeAgreementRestored
+	Err error
+}
+
+// SetTaskOverrides sets the config of a task of an agreement overridden on some members, by member name,
+// through an HTTP PUT call. The members create the task with the config of its manifest overridden by
+// theirs. The agreement returns if it succeeds. Otherwise, an error is returned.
+func (c *Client) SetTaskOverrides(agreementName, taskID string, overrides map[string]agreement.TaskOverride) *SetTaskOverridesResult {
+	b, err := json.Marshal(struct {
+		Overrides map[string]agreement.TaskOverride `json:"overrides"`
+	}{Overrides: overrides})
+	if err != nil {
+		return &SetTaskOverridesResult{Err: err}
+	}
+	resp, err := c.do("PUT", fmt.Sprintf("/tribe/agreements/%s/tasks/%s/overrides", agreementName, taskID), ContentTypeJSON, b)
+	if err != nil {
+		return &SetTaskOverridesResult{Err: err}
+	}
+	switch resp.Meta.Type {
+	case rbody.TribeTaskOverridesSetType:
+		return &SetTaskOverridesResult{resp.Body.(*rbody.TribeTaskOverridesSet), nil}
+	case rbody.ErrorType:
+		return &SetTaskOverridesResult{Err: resp.Body.(*rbody.Error)}
+	default:
+		return &SetTaskOverridesResult{Err: ErrAPIResponseMetaType}
+	}
+}
+
+// SetTaskOverridesResult is the response from snap/client on a SetTaskOverrides call.
+type SetTaskOverridesResult struct {
+	*rbody.TribeTaskOverridesSet
+	Err error
+}
+
+// GetTribeMetrics retrieves the metric catalog merged over the members of an agreement, or of the whole tribe
+// when the agreement name is empty, through an HTTP GET call. Each metric is listed with the members exposing it.
+// The catalog returns if it succeeds. Otherwise, an error is returned.
+func (c *Client) GetTribeMetrics(agreementName string) *GetTribeMetricsResult {
+	path := "/tribe/metrics"
+	if agreementName != "" {
+		path = fmt.Sprintf("%s?agreement=%s", path, url.QueryEscape(agreementName))
+	}
+	resp, err := c.do("GET", path, ContentTypeJSON)
+	if err != nil {
+		return &GetTribeMetricsResult{Err: err}
+	}
+	switch resp.Meta.Type {
+	case rbody.TribeMetricCatalogType:
+		return &GetTribeMetricsResult{resp.Body.(*rbody.TribeMetricCatalog), nil}
+	case rbody.ErrorType:
+		return &GetTribeMetricsResult{Err: resp.Body.(*rbody.Error)}
+	default:
+		return &GetTribeMetricsResult{Err: ErrAPIResponseMetaType}
+	}
+}
+
+// GetTribeMetricsResult is the response from snap/client on a GetTribeMetrics call.
+type GetTribeMetricsResult struct {
+	*rbody.TribeMetricCatalog
+	Err error
+}
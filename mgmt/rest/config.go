@@ -10,9 +10,17 @@ const (
 	defaultRestKey         string = ""
 	defaultAuth            bool   = false
 	defaultAuthPassword    string = ""
+	defaultAuthTokenFile   string = ""
+	defaultClientCA        string = ""
 	defaultPortSetByConfig bool   = false
 	defaultPprof           bool   = false
 	defaultCorsd           string = ""
+	defaultUnixSocket      string = ""
+	defaultUnixSocketMode  string = "0660"
+	defaultUnixSocketOnly  bool   = false
+	defaultAuditLog        string = ""
+	defaultPrometheus      bool   = false
+	defaultPromCollected   bool   = false
 )
 
 // holds the configuration passed in through the SNAP config file
@@ -20,17 +28,29 @@ const (
 //         UnmarshalJSON method in this same file needs to be modified to
 //         match the field mapping that is defined here
 type Config struct {
-	Enable           bool   `json:"enable"yaml:"enable"`
-	Port             int    `json:"port"yaml:"port"`
-	Address          string `json:"addr"yaml:"addr"`
-	HTTPS            bool   `json:"https"yaml:"https"`
-	RestCertificate  string `json:"rest_certificate"yaml:"rest_certificate"`
-	RestKey          string `json:"rest_key"yaml:"rest_key"`
-	RestAuth         bool   `json:"rest_auth"yaml:"rest_auth"`
-	RestAuthPassword string `json:"rest_auth_password"yaml:"rest_auth_password"`
-	portSetByConfig  bool   ``
-	Pprof            bool   `json:"pprof"yaml:"pprof"`
-	Corsd            string `json:"allowed_origins"yaml:"allowed_origins"`
+	Enable            bool                `json:"enable"yaml:"enable"`
+	Port              int                 `json:"port"yaml:"port"`
+	Address           string              `json:"addr"yaml:"addr"`
+	HTTPS             bool                `json:"https"yaml:"https"`
+	RestCertificate   string              `json:"rest_certificate"yaml:"rest_certificate"`
+	RestKey           string              `json:"rest_key"yaml:"rest_key"`
+	RestAuth          bool                `json:"rest_auth"yaml:"rest_auth"`
+	RestAuthPassword  string              `json:"rest_auth_password"yaml:"rest_auth_password"`
+	RestAuthTokens    []string            `json:"rest_auth_tokens"yaml:"rest_auth_tokens"`
+	RestAuthTokenFile string              `json:"rest_auth_token_file"yaml:"rest_auth_token_file"`
+	RestClientCA      string              `json:"rest_client_ca"yaml:"rest_client_ca"`
+	RestAuthRoles     map[string][]string `json:"rest_auth_roles"yaml:"rest_auth_roles"`
+	portSetByConfig   bool                ``
+	Pprof             bool                `json:"pprof"yaml:"pprof"`
+	Corsd             string              `json:"allowed_origins"yaml:"allowed_origins"`
+	UnixSocket        string              `json:"unix_socket"yaml:"unix_socket"`
+	UnixSocketMode    string              `json:"unix_socket_mode"yaml:"unix_socket_mode"`
+	UnixSocketOnly    bool                `json:"unix_socket_only"yaml:"unix_socket_only"`
+	AuditLog          string              `json:"audit_log"yaml:"audit_log"`
+	// Prometheus serves the statistics of snapteld on GET /metrics and
+	// PrometheusCollected adds the values last collected by the tasks
+	Prometheus          bool `json:"prometheus"yaml:"prometheus"`
+	PrometheusCollected bool `json:"prometheus_collected"yaml:"prometheus_collected"`
 }
 
 const (
@@ -50,6 +70,28 @@ const (
 					"rest_auth_password": {
 						"type": "string"
 					},
+					"rest_auth_tokens": {
+						"type": ["array", "null"],
+						"items": {
+							"type": "string"
+						}
+					},
+					"rest_auth_token_file": {
+						"type": "string"
+					},
+					"rest_client_ca": {
+						"type": "string"
+					},
+					"rest_auth_roles": {
+						"type": ["object", "null"],
+						"additionalProperties": {
+							"type": "array",
+							"items": {
+								"type": "string",
+								"enum": ["read-only", "task-operator", "plugin-admin", "tribe-admin"]
+							}
+						}
+					},
 					"rest_certificate": {
 						"type": "string"
 					},
@@ -69,6 +111,25 @@ const (
 					},
 					"allowed_origins" : {
 						"type": "string"
+					},
+					"unix_socket": {
+						"type": "string"
+					},
+					"unix_socket_mode": {
+						"type": "string",
+						"pattern": "^0?[0-7]{3}$"
+					},
+					"unix_socket_only": {
+						"type": "boolean"
+					},
+					"audit_log": {
+						"type": "string"
+					},
+					"prometheus": {
+						"type": "boolean"
+					},
+					"prometheus_collected": {
+						"type": "boolean"
 					}
 				},
 				"additionalProperties": false
@@ -79,17 +140,26 @@ const (
 // GetDefaultConfig gets the default snapteld configuration
 func GetDefaultConfig() *Config {
 	return &Config{
-		Enable:           defaultEnable,
-		Port:             defaultPort,
-		Address:          defaultAddress,
-		HTTPS:            defaultHTTPS,
-		RestCertificate:  defaultRestCertificate,
-		RestKey:          defaultRestKey,
-		RestAuth:         defaultAuth,
-		RestAuthPassword: defaultAuthPassword,
-		portSetByConfig:  defaultPortSetByConfig,
-		Pprof:            defaultPprof,
-		Corsd:            defaultCorsd,
+		Enable:            defaultEnable,
+		Port:              defaultPort,
+		Address:           defaultAddress,
+		HTTPS:             defaultHTTPS,
+		RestCertificate:   defaultRestCertificate,
+		RestKey:           defaultRestKey,
+		RestAuth:          defaultAuth,
+		RestAuthPassword:  defaultAuthPassword,
+		RestAuthTokenFile: defaultAuthTokenFile,
+		RestClientCA:      defaultClientCA,
+		portSetByConfig:   defaultPortSetByConfig,
+		Pprof:             defaultPprof,
+		Corsd:             defaultCorsd,
+		UnixSocket:        defaultUnixSocket,
+		UnixSocketMode:    defaultUnixSocketMode,
+		UnixSocketOnly:    defaultUnixSocketOnly,
+		AuditLog:          defaultAuditLog,
+
+		Prometheus:          defaultPrometheus,
+		PrometheusCollected: defaultPromCollected,
 	}
 }
 
@@ -56,6 +56,30 @@ var (
 		Name:  "rest-auth",
 		Usage: "Enables Snap's REST API authentication",
 	}
+	flRestAuthTokenFile = cli.StringFlag{
+		Name:  "rest-auth-token-file",
+		Usage: "A path to a file of bearer tokens, one per line, accepted by Snap's REST API",
+	}
+	flRestClientCA = cli.StringFlag{
+		Name:  "rest-client-ca",
+		Usage: "A path to the CA certificates the client certificates of Snap's REST API are verified with",
+	}
+	flRestUnixSocket = cli.StringFlag{
+		Name:  "rest-unix-socket",
+		Usage: "A path to a unix socket Snap's REST API is also served on",
+	}
+	flRestUnixSocketMode = cli.StringFlag{
+		Name:  "rest-unix-socket-mode",
+		Usage: fmt.Sprintf("The permissions of the unix socket of Snap's REST API (default: %s)", defaultUnixSocketMode),
+	}
+	flRestUnixSocketOnly = cli.BoolFlag{
+		Name:  "rest-unix-socket-only",
+		Usage: "Serve Snap's REST API only on its unix socket, without listening on a TCP port",
+	}
+	flRestAuditLog = cli.StringFlag{
+		Name:  "rest-audit-log",
+		Usage: "A path to the file the mutating requests of Snap's REST API are recorded to",
+	}
 	flPProf = cli.BoolFlag{
 		Name:  "pprof",
 		Usage: "Enables profiling tools",
@@ -66,5 +90,5 @@ var (
 	}
 
 	// Flags consumed by snapteld
-	Flags = []cli.Flag{flAPIDisabled, flAPIAddr, flAPIPort, flRestHTTPS, flRestCert, flRestKey, flRestAuth, flPProf, flCorsd}
+	Flags = []cli.Flag{flAPIDisabled, flAPIAddr, flAPIPort, flRestHTTPS, flRestCert, flRestKey, flRestAuth, flRestAuthTokenFile, flRestClientCA, flRestUnixSocket, flRestUnixSocketMode, flRestUnixSocketOnly, flRestAuditLog, flPProf, flCorsd}
 )
@@ -0,0 +1,115 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/urfave/negroni"
+)
+
+// gzipped returns the handle of a route compressing its response when the
+// request accepts gzip
+func gzipped(h httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		if !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+			h(w, r, p)
+			return
+		}
+		gw := &gzipWriter{ResponseWriter: w.(negroni.ResponseWriter)}
+		defer gw.close()
+		h(gw, r, p)
+	}
+}
+
+func acceptsGzip(acceptEncoding string) bool {
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		params := strings.Split(part, ";")
+		if strings.TrimSpace(params[0]) != "gzip" {
+			continue
+		}
+		for _, param := range params[1:] {
+			if strings.Replace(param, " ", "", -1) == "q=0" {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// gzipWriter compresses a response, unless the response is already encoded
+// (e.g. the plugins downloaded) or is a stream of events
+type gzipWriter struct {
+	negroni.ResponseWriter
+	gz *gzip.Writer
+	// decided is true once the headers are written, and the response
+	// compressed or not
+	decided bool
+}
+
+func (g *gzipWriter) WriteHeader(code int) {
+	if !g.decided {
+		g.decided = true
+		h := g.Header()
+		if h.Get("Content-Encoding") == "" && !strings.HasPrefix(h.Get("Content-Type"), "text/event-stream") &&
+			code != http.StatusNoContent && code != http.StatusNotModified {
+			h.Set("Content-Encoding", "gzip")
+			h.Add("Vary", "Accept-Encoding")
+			h.Del("Content-Length")
+			g.gz = gzip.NewWriter(g.ResponseWriter)
+		}
+	}
+	g.ResponseWriter.WriteHeader(code)
+}
+
+func (g *gzipWriter) Write(b []byte) (int, error) {
+	if !g.decided {
+		// the content type is detected on the uncompressed response
+		if g.Header().Get("Content-Type") == "" {
+			g.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		g.WriteHeader(http.StatusOK)
+	}
+	if g.gz == nil {
+		return g.ResponseWriter.Write(b)
+	}
+	return g.gz.Write(b)
+}
+
+func (g *gzipWriter) Flush() {
+	if g.gz != nil {
+		g.gz.Flush()
+	}
+	g.ResponseWriter.Flush()
+}
+
+func (g *gzipWriter) CloseNotify() <-chan bool {
+	return g.ResponseWriter.(http.CloseNotifier).CloseNotify()
+}
+
+func (g *gzipWriter) close() {
+	if g.gz != nil {
+		g.gz.Close()
+	}
+}
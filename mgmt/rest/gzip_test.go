@@ -0,0 +1,87 @@
+// +build medium
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/urfave/negroni"
+)
+
+func TestGzipped(t *testing.T) {
+	const catalog = `{"plugins": []}`
+	serve := func(acceptEncoding string, h httprouter.Handle) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("GET", "http://localhost/v2/plugins", nil)
+		r.Header.Set("Accept-Encoding", acceptEncoding)
+		rec := httptest.NewRecorder()
+		gzipped(h)(negroni.NewResponseWriter(rec), r, nil)
+		return rec
+	}
+	json := func(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(200)
+		w.Write([]byte(catalog))
+	}
+
+	Convey("Compressing the responses", t, func() {
+		Convey("compresses the responses when the request accepts gzip", func() {
+			rec := serve("deflate, gzip", json)
+			So(rec.Header().Get("Content-Encoding"), ShouldEqual, "gzip")
+			So(rec.Header().Get("Content-Type"), ShouldEqual, "application/json")
+			gz, err := gzip.NewReader(rec.Body)
+			So(err, ShouldBeNil)
+			b, err := ioutil.ReadAll(gz)
+			So(err, ShouldBeNil)
+			So(string(b), ShouldEqual, catalog)
+		})
+		Convey("doesn't compress the responses when the request doesn't accept gzip", func() {
+			for _, acceptEncoding := range []string{"", "deflate", "gzip;q=0"} {
+				rec := serve(acceptEncoding, json)
+				So(rec.Header().Get("Content-Encoding"), ShouldEqual, "")
+				So(rec.Body.String(), ShouldEqual, catalog)
+			}
+		})
+		Convey("doesn't compress the event streams", func() {
+			rec := serve("gzip", func(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				w.Write([]byte("data: {}\n\n"))
+				w.(http.Flusher).Flush()
+			})
+			So(rec.Header().Get("Content-Encoding"), ShouldEqual, "")
+			So(rec.Body.String(), ShouldEqual, "data: {}\n\n")
+		})
+		Convey("doesn't compress the responses already encoded", func() {
+			rec := serve("gzip", func(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+				w.Header().Set("Content-Encoding", "identity")
+				w.Write([]byte(catalog))
+			})
+			So(rec.Header().Get("Content-Encoding"), ShouldEqual, "identity")
+			So(rec.Body.String(), ShouldEqual, catalog)
+		})
+	})
+}
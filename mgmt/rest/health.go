@@ -0,0 +1,127 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/mgmt/rest/api"
+)
+
+// the paths of the probes of the health and of the readiness of snapteld,
+// they're served without authentication
+const (
+	healthzPath = "/healthz"
+	readyzPath  = "/readyz"
+)
+
+// the statuses of snapteld and of its subsystems
+const (
+	HealthOK          = "ok"
+	HealthDegraded    = "degraded"
+	HealthNotReady    = "not_ready"
+	HealthUnavailable = "unavailable"
+)
+
+// HealthResponse is the body of the responses of /healthz and /readyz
+type HealthResponse struct {
+	Status     string            `json:"status"`
+	Subsystems []SubsystemHealth `json:"subsystems"`
+}
+
+// SubsystemHealth is the health of a subsystem of snapteld
+type SubsystemHealth struct {
+	Name    string                 `json:"name"`
+	Status  string                 `json:"status"`
+	Message string                 `json:"message,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// BindHealthReporters adds the subsystems reported by /healthz and /readyz
+func (s *Server) BindHealthReporters(reporters ...api.HealthReporter) {
+	s.healthReporters = append(s.healthReporters, reporters...)
+}
+
+func (s *Server) addHealthRoutes() {
+	s.r.GET(healthzPath, s.healthz)
+	s.r.GET(readyzPath, s.readyz)
+}
+
+// isProbe returns true for the requests of the probes
+func isProbe(r *http.Request) bool {
+	return r.Method == "GET" && (r.URL.Path == healthzPath || r.URL.Path == readyzPath)
+}
+
+// healthz responds with 503 when a subsystem isn't running
+func (s *Server) healthz(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	s.writeHealth(w, false)
+}
+
+// readyz responds with 503 when a subsystem isn't running or isn't ready yet
+func (s *Server) readyz(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	s.writeHealth(w, true)
+}
+
+func (s *Server) writeHealth(w http.ResponseWriter, readiness bool) {
+	resp := HealthResponse{Status: HealthOK, Subsystems: []SubsystemHealth{}}
+	code := http.StatusOK
+	for _, hr := range s.healthReporters {
+		h := hr.Health()
+		status := subsystemStatus(h)
+		if !h.Healthy || (readiness && !h.Ready) {
+			code = http.StatusServiceUnavailable
+			resp.Status = HealthUnavailable
+		} else if h.Degraded && resp.Status == HealthOK {
+			resp.Status = HealthDegraded
+		}
+		resp.Subsystems = append(resp.Subsystems, SubsystemHealth{
+			Name:    h.Name,
+			Status:  status,
+			Message: h.Message,
+			Details: h.Details,
+		})
+	}
+	b, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		restLogger.WithField("_block", "write-health").Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(code)
+	w.Write(b)
+}
+
+func subsystemStatus(h core.SubsystemHealth) string {
+	switch {
+	case !h.Healthy:
+		return HealthUnavailable
+	case !h.Ready:
+		return HealthNotReady
+	case h.Degraded:
+		return HealthDegraded
+	}
+	return HealthOK
+}
@@ -0,0 +1,102 @@
+// +build medium
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/intelsdi-x/snap/core"
+)
+
+type mockHealthReporter core.SubsystemHealth
+
+func (m mockHealthReporter) Health() core.SubsystemHealth {
+	return core.SubsystemHealth(m)
+}
+
+func TestHealth(t *testing.T) {
+	probe := func(s *Server, readiness bool) (int, HealthResponse) {
+		w := httptest.NewRecorder()
+		if readiness {
+			s.readyz(w, httptest.NewRequest("GET", readyzPath, nil), nil)
+		} else {
+			s.healthz(w, httptest.NewRequest("GET", healthzPath, nil), nil)
+		}
+		So(w.Header().Get("Content-Type"), ShouldEqual, "application/json")
+		var resp HealthResponse
+		So(json.Unmarshal(w.Body.Bytes(), &resp), ShouldBeNil)
+		return w.Code, resp
+	}
+
+	Convey("Given the subsystems of snapteld", t, func() {
+		s := &Server{}
+		s.BindHealthReporters(
+			mockHealthReporter{Name: "control", Healthy: true, Ready: true},
+			mockHealthReporter{Name: "scheduler", Healthy: true, Ready: true, Details: map[string]interface{}{"queued_jobs": 0}},
+		)
+		Convey("snapteld is healthy and ready when all of them are", func() {
+			code, resp := probe(s, false)
+			So(code, ShouldEqual, 200)
+			So(resp.Status, ShouldEqual, HealthOK)
+			So(resp.Subsystems, ShouldHaveLength, 2)
+			So(resp.Subsystems[1].Details["queued_jobs"], ShouldEqual, 0)
+			code, _ = probe(s, true)
+			So(code, ShouldEqual, 200)
+		})
+		Convey("a degraded subsystem doesn't fail the probes", func() {
+			s.BindHealthReporters(mockHealthReporter{Name: "plugins", Healthy: true, Ready: true, Degraded: true, Message: "1 plugin is unhealthy"})
+			code, resp := probe(s, true)
+			So(code, ShouldEqual, 200)
+			So(resp.Status, ShouldEqual, HealthDegraded)
+			So(resp.Subsystems[2].Status, ShouldEqual, HealthDegraded)
+			So(resp.Subsystems[2].Message, ShouldEqual, "1 plugin is unhealthy")
+		})
+		Convey("a subsystem not ready only fails the readiness probe", func() {
+			s.BindHealthReporters(mockHealthReporter{Name: "tribe", Healthy: true})
+			code, resp := probe(s, false)
+			So(code, ShouldEqual, 200)
+			So(resp.Status, ShouldEqual, HealthOK)
+			So(resp.Subsystems[2].Status, ShouldEqual, HealthNotReady)
+			code, resp = probe(s, true)
+			So(code, ShouldEqual, 503)
+			So(resp.Status, ShouldEqual, HealthUnavailable)
+		})
+		Convey("a subsystem down fails both probes", func() {
+			s.BindHealthReporters(mockHealthReporter{Name: "control"})
+			code, resp := probe(s, false)
+			So(code, ShouldEqual, 503)
+			So(resp.Subsystems[2].Status, ShouldEqual, HealthUnavailable)
+			code, _ = probe(s, true)
+			So(code, ShouldEqual, 503)
+		})
+	})
+	Convey("The probes don't authenticate", t, func() {
+		So(isProbe(httptest.NewRequest("GET", "/healthz", nil)), ShouldBeTrue)
+		So(isProbe(httptest.NewRequest("GET", "/readyz", nil)), ShouldBeTrue)
+		So(isProbe(httptest.NewRequest("POST", "/readyz", nil)), ShouldBeFalse)
+		So(isProbe(httptest.NewRequest("GET", "/v2/plugins", nil)), ShouldBeFalse)
+	})
+}
@@ -0,0 +1,36 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"net/http"
+)
+
+// BindExporter sets the handler of GET /metrics, which serves the statistics
+// of snapteld in the Prometheus text exposition format
+func (s *Server) BindExporter(h http.Handler) {
+	s.exporter = h
+}
+
+func (s *Server) addPrometheusRoute() {
+	if s.exporter != nil {
+		s.r.Handler("GET", "/metrics", s.exporter)
+	}
+}
@@ -0,0 +1,146 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/intelsdi-x/snap/mgmt/rest/api"
+	"github.com/intelsdi-x/snap/mgmt/rest/v2"
+)
+
+// The roles granted to the identities of the REST API, every role can read
+// all the resources
+const (
+	RoleReadOnly     = "read-only"
+	RoleTaskOperator = "task-operator"
+	RolePluginAdmin  = "plugin-admin"
+	RoleTribeAdmin   = "tribe-admin"
+)
+
+var roles = map[string]bool{
+	RoleReadOnly:     true,
+	RoleTaskOperator: true,
+	RolePluginAdmin:  true,
+	RoleTribeAdmin:   true,
+}
+
+// identity is who a request is authenticated as
+type identity struct {
+	// name is the common name of the client certificate or the name of the
+	// token, it's empty for the tokens without a name
+	name string
+	// admin is true for the password of snapteld, it's granted every role
+	admin bool
+}
+
+type identityKey struct{}
+
+// withIdentity returns the request with its identity
+func withIdentity(r *http.Request, id identity) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), identityKey{}, id))
+}
+
+// newRoles validates the roles of the identities
+func newRoles(cfg map[string][]string) (map[string]map[string]bool, error) {
+	if len(cfg) == 0 {
+		return nil, nil
+	}
+	granted := map[string]map[string]bool{}
+	for name, names := range cfg {
+		granted[name] = map[string]bool{}
+		for _, role := range names {
+			if !roles[role] {
+				return nil, fmt.Errorf("Unknown role '%s' of '%s' in the REST API roles", role, name)
+			}
+			granted[name][role] = true
+		}
+	}
+	return granted, nil
+}
+
+// routeRole returns the role required by a route, the routes reading the
+// resources only require any role
+func routeRole(route api.Route) string {
+	// the audit log is only readable with the password
+	if route.Path == auditPath {
+		return ""
+	}
+	if route.Method == "GET" || route.Method == "HEAD" {
+		return RoleReadOnly
+	}
+	// the resource is the segment following the version of the API, e.g.
+	// tasks for /v2/tasks/:id
+	segments := strings.Split(strings.Trim(route.Path, "/"), "/")
+	if len(segments) < 2 {
+		return ""
+	}
+	switch segments[1] {
+	case "tasks", "deadletters":
+		return RoleTaskOperator
+	case "plugins", "blacklist":
+		return RolePluginAdmin
+	case "tribe":
+		return RoleTribeAdmin
+	}
+	// the other routes are only allowed with the password
+	return ""
+}
+
+// authorize returns the handle of a route allowing only the identities
+// granted the role of the route, every identity is allowed when no roles
+// are configured
+func (s *Server) authorize(route api.Route) httprouter.Handle {
+	role := routeRole(route)
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		id, _ := r.Context().Value(identityKey{}).(identity)
+		if !s.allowed(id, role) {
+			restLogger.WithFields(log.Fields{
+				"_block":   "authorize",
+				"identity": id.name,
+				"role":     role,
+				"method":   r.Method,
+				"path":     r.URL.Path,
+			}).Warn("REST API request forbidden")
+			v2.Write(403, v2.FromError(v2.ErrForbidden), w)
+			return
+		}
+		route.Handle(w, r, p)
+	}
+}
+
+func (s *Server) allowed(id identity, role string) bool {
+	s.authMutex.RLock()
+	defer s.authMutex.RUnlock()
+	if !s.auth || s.roles == nil || id.admin {
+		return true
+	}
+	granted := s.roles[id.name]
+	if id.name == "" || len(granted) == 0 {
+		return false
+	}
+	return role == RoleReadOnly || (role != "" && granted[role])
+}
@@ -0,0 +1,101 @@
+// +build medium
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/urfave/negroni"
+
+	"github.com/intelsdi-x/snap/mgmt/rest/api"
+)
+
+func TestRouteRole(t *testing.T) {
+	Convey("The roles required by the routes", t, func() {
+		So(routeRole(api.Route{Method: "GET", Path: "/v2/tasks/:id"}), ShouldEqual, RoleReadOnly)
+		So(routeRole(api.Route{Method: "GET", Path: "/v1/plugins"}), ShouldEqual, RoleReadOnly)
+		So(routeRole(api.Route{Method: "PUT", Path: "/v2/tasks/:id"}), ShouldEqual, RoleTaskOperator)
+		So(routeRole(api.Route{Method: "DELETE", Path: "/v2/deadletters"}), ShouldEqual, RoleTaskOperator)
+		So(routeRole(api.Route{Method: "POST", Path: "/v1/plugins"}), ShouldEqual, RolePluginAdmin)
+		So(routeRole(api.Route{Method: "DELETE", Path: "/v2/blacklist/:checksum"}), ShouldEqual, RolePluginAdmin)
+		So(routeRole(api.Route{Method: "POST", Path: "/v1/tribe/agreements"}), ShouldEqual, RoleTribeAdmin)
+		So(routeRole(api.Route{Method: "GET", Path: "/v2/audit"}), ShouldEqual, "")
+		So(routeRole(api.Route{Method: "PUT", Path: "/v1/config"}), ShouldEqual, "")
+	})
+}
+
+func TestAuthorize(t *testing.T) {
+	granted, err := newRoles(map[string][]string{
+		"grafana":         {RoleReadOnly},
+		"ci":              {RoleTaskOperator},
+		"ops.example.com": {RolePluginAdmin, RoleTribeAdmin},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{auth: true, roles: granted}
+	ok := func(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) { w.WriteHeader(200) }
+
+	status := func(s *Server, id identity, method, path string) int {
+		h := s.authorize(api.Route{Method: method, Path: path, Handle: ok})
+		r, _ := http.NewRequest(method, "http://localhost"+path, nil)
+		w := negroni.NewResponseWriter(httptest.NewRecorder())
+		h(w, withIdentity(r, id), nil)
+		return w.Status()
+	}
+
+	Convey("Authorizing REST API requests", t, func() {
+		Convey("allows every identity with a role to read", func() {
+			So(status(s, identity{name: "grafana"}, "GET", "/v2/plugins"), ShouldEqual, 200)
+			So(status(s, identity{name: "ci"}, "GET", "/v2/tasks"), ShouldEqual, 200)
+		})
+		Convey("allows the routes of the roles", func() {
+			So(status(s, identity{name: "ci"}, "PUT", "/v2/tasks/:id"), ShouldEqual, 200)
+			So(status(s, identity{name: "ops.example.com"}, "POST", "/v2/plugins"), ShouldEqual, 200)
+			So(status(s, identity{name: "ops.example.com"}, "POST", "/v1/tribe/agreements"), ShouldEqual, 200)
+		})
+		Convey("forbids the routes of the other roles", func() {
+			So(status(s, identity{name: "grafana"}, "POST", "/v2/plugins"), ShouldEqual, 403)
+			So(status(s, identity{name: "ci"}, "POST", "/v2/plugins"), ShouldEqual, 403)
+			So(status(s, identity{name: "ops.example.com"}, "DELETE", "/v2/tasks/:id"), ShouldEqual, 403)
+		})
+		Convey("forbids the identities without a role", func() {
+			So(status(s, identity{name: "unknown"}, "GET", "/v2/plugins"), ShouldEqual, 403)
+			So(status(s, identity{}, "GET", "/v2/plugins"), ShouldEqual, 403)
+		})
+		Convey("allows the password everywhere", func() {
+			So(status(s, identity{admin: true}, "POST", "/v2/plugins"), ShouldEqual, 200)
+		})
+		Convey("allows everything when no roles are configured", func() {
+			So(status(&Server{auth: true}, identity{name: "grafana"}, "POST", "/v2/plugins"), ShouldEqual, 200)
+		})
+	})
+
+	Convey("Unknown roles are rejected", t, func() {
+		_, err := newRoles(map[string][]string{"grafana": {"admin"}})
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -37,6 +37,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -603,9 +604,15 @@ func startV1API(cfg *mockConfig, testType string) *restAPIInstance {
 	case "metric":
 		mockMetricManager := &fixtures.MockManagesMetrics{}
 		r.BindMetricManager(mockMetricManager)
+		// the metrics are streamed by running workflows
+		mockTaskManager := &fixtures.MockTaskManager{}
+		r.BindTaskManager(mockTaskManager)
 	case "task":
 		mockTaskManager := &fixtures.MockTaskManager{}
 		r.BindTaskManager(mockTaskManager)
+	case "config":
+		mockConfigReloader := &fixtures.MockConfigReloader{}
+		r.BindConfigReloader(mockConfigReloader)
 	}
 	go func(ch <-chan error) {
 		// Block on the error channel. Will return exit status 1 for an error or
@@ -838,6 +845,43 @@ func TestV1Metric(t *testing.T) {
 				ShouldResemble,
 				resp1)
 		})
+
+		Convey("Stream metrics - v1/metrics/stream", func() {
+			resp, err := http.Get(
+				fmt.Sprintf("http://localhost:%d/v1/metrics/stream", r.port))
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, 400)
+
+			resp, err = http.Get(
+				fmt.Sprintf("http://localhost:%d/v1/metrics/stream?ns=/intel/mock/*&interval=10ms", r.port))
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, 400)
+
+			atomic.StoreInt32(&fixtures.TaskRunnersCreated, 0)
+			resp, err = http.Get(
+				fmt.Sprintf("http://localhost:%d/v1/metrics/stream?ns=/intel/mock/*&interval=1s", r.port))
+			So(err, ShouldBeNil)
+			defer resp.Body.Close()
+			So(resp.StatusCode, ShouldEqual, 200)
+			So(resp.Header.Get("Content-Type"), ShouldEqual, "text/event-stream")
+			reader := bufio.NewReader(resp.Body)
+			var events []rbody.StreamedTaskEvent
+			for len(events) < 3 {
+				line, err := reader.ReadString('\n')
+				So(err, ShouldBeNil)
+				if !strings.HasPrefix(line, "data: ") {
+					continue
+				}
+				e := rbody.StreamedTaskEvent{}
+				So(json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &e), ShouldBeNil)
+				events = append(events, e)
+			}
+			So(events[0].EventType, ShouldEqual, rbody.TaskWatchStreamOpen)
+			So(events[1].EventType, ShouldEqual, rbody.TaskWatchMetricEvent)
+			So(events[2].EventType, ShouldEqual, rbody.TaskWatchMetricEvent)
+			// the plugins are subscribed once for the whole stream
+			So(atomic.LoadInt32(&fixtures.TaskRunnersCreated), ShouldEqual, 1)
+		})
 	})
 }
 
@@ -954,6 +998,27 @@ func TestV1Task(t *testing.T) {
 			)
 		})
 
+		Convey("Pause and resume tasks - v1/tasks/:id/pause and v1/tasks/:id/resume", func() {
+			c := &http.Client{}
+			taskID := "MockTask1234"
+			for action, expected := range map[string]string{
+				"pause":  fixtures.PAUSE_TASK_RESPONSE_ID_PAUSE,
+				"resume": fixtures.RESUME_TASK_RESPONSE_ID_RESUME,
+			} {
+				req, err := http.NewRequest(
+					"PUT",
+					fmt.Sprintf("http://localhost:%d/v1/tasks/%s/%s", r.port, taskID, action),
+					nil)
+				So(err, ShouldBeNil)
+				resp, err := c.Do(req)
+				So(err, ShouldBeNil)
+				So(resp.StatusCode, ShouldEqual, http.StatusOK)
+				body, err := ioutil.ReadAll(resp.Body)
+				So(err, ShouldBeNil)
+				So(string(body), ShouldResemble, expected)
+			}
+		})
+
 		Convey("Enable tasks - v1/tasks/:id/enable", func() {
 			c := &http.Client{}
 			taskID := "MockTask1234"
@@ -1006,6 +1071,40 @@ func TestV1Task(t *testing.T) {
 	})
 }
 
+func TestV1Config(t *testing.T) {
+	r := startV1API(getDefaultMockConfig(), "config")
+	Convey("Test Config REST API V1", t, func() {
+		Convey("Reload config - v1/config", func() {
+			put := func(body string) *http.Response {
+				req, err := http.NewRequest("PUT",
+					fmt.Sprintf("http://localhost:%d/v1/config", r.port),
+					strings.NewReader(body))
+				So(err, ShouldBeNil)
+				resp, err := http.DefaultClient.Do(req)
+				So(err, ShouldBeNil)
+				return resp
+			}
+			resp := put(`{"log_level": 1}`)
+			So(resp.StatusCode, ShouldEqual, 200)
+			reloaded := getAPIResponse(resp).Body.(*rbody.ConfigReloaded)
+			So(reloaded.Changed, ShouldResemble, []string{"log_level"})
+
+			resp = put(`{"restapi": {"port": 8282}}`)
+			So(resp.StatusCode, ShouldEqual, 400)
+			So(getAPIResponse(resp).Meta.Type, ShouldEqual, rbody.ErrorType)
+		})
+		Convey("Get effective config - v1/config/effective", func() {
+			resp, err := http.Get(
+				fmt.Sprintf("http://localhost:%d/v1/config/effective", r.port))
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, 200)
+			effective := getAPIResponse(resp).Body.(*rbody.EffectiveConfig)
+			So(effective.Settings, ShouldResemble, map[string]interface{}{"log_level": 3.0})
+			So(effective.Sources, ShouldResemble, map[string]string{"log_level": "default"})
+		})
+	})
+}
+
 func TestV1Tribe(t *testing.T) {
 	r := startV1API(getDefaultMockConfig(), "tribe")
 	Convey("Test Tribe REST API V1", t, func() {
@@ -1069,6 +1168,20 @@ func TestV1Tribe(t *testing.T) {
 			)
 		})
 
+		Convey("Get tribe keys - v1/tribe/keys", func() {
+			resp, err := http.Get(
+				fmt.Sprintf("http://localhost:%d/v1/tribe/keys", r.port))
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, 200)
+			body, err := ioutil.ReadAll(resp.Body)
+			So(err, ShouldBeNil)
+			So(
+				string(body),
+				ShouldResemble,
+				fmt.Sprintf(fixtures.GET_TRIBE_KEYS_RESPONSE),
+			)
+		})
+
 		Convey("Get tribe member - v1/tribe/member/:name", func() {
 			tribeName := "Imma_Mock"
 			resp, err := http.Get(
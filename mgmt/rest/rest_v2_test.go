@@ -120,6 +120,46 @@ func TestV2Plugin(t *testing.T) {
 				fmt.Sprintf(mock.GET_PLUGINS_RESPONSE, r.port, r.port,
 					r.port, r.port, r.port, r.port))
 		})
+		Convey("Get plugins by pages - v2/plugins?limit=4", func() {
+			var page struct {
+				Plugins []struct {
+					Name    string `json:"name"`
+					Type    string `json:"type"`
+					Version int    `json:"version"`
+				} `json:"plugins"`
+				NextCursor string `json:"next_cursor"`
+			}
+			resp, err := http.Get(
+				fmt.Sprintf("http://localhost:%d/v2/plugins?limit=4", r.port))
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, 200)
+			So(json.NewDecoder(resp.Body).Decode(&page), ShouldBeNil)
+			So(len(page.Plugins), ShouldEqual, 4)
+			So(page.Plugins[0].Type, ShouldEqual, "collector")
+			So(page.Plugins[3].Name, ShouldEqual, "foobar")
+			So(page.NextCursor, ShouldNotBeEmpty)
+
+			cursor := page.NextCursor
+			page.Plugins, page.NextCursor = nil, ""
+			resp, err = http.Get(
+				fmt.Sprintf("http://localhost:%d/v2/plugins?limit=4&cursor=%s", r.port, cursor))
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, 200)
+			So(json.NewDecoder(resp.Body).Decode(&page), ShouldBeNil)
+			So(len(page.Plugins), ShouldEqual, 2)
+			So(page.Plugins[0].Name, ShouldEqual, "bar")
+			So(page.Plugins[1].Name, ShouldEqual, "baz")
+			So(page.NextCursor, ShouldBeEmpty)
+		})
+		Convey("Get plugins with an invalid cursor - v2/plugins?cursor=!", func() {
+			resp, err := http.Get(
+				fmt.Sprintf("http://localhost:%d/v2/plugins?cursor=!", r.port))
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, 400)
+			body, err := ioutil.ReadAll(resp.Body)
+			So(err, ShouldBeNil)
+			So(string(body), ShouldContainSubstring, `"code": 400`)
+		})
 		Convey("Get plugins - v2/plugins/:type", func() {
 			c := &http.Client{}
 			req, err := http.NewRequest("GET",
@@ -210,6 +250,22 @@ func TestV2Plugin(t *testing.T) {
 				fmt.Sprintf(mock.GET_PLUGIN_CONFIG_ITEM))
 		})
 
+		Convey("Get plugin config schema - v2/plugins/:type/:name/:version/config/schema", func() {
+			resp, err := http.Get(
+				fmt.Sprintf("http://localhost:%d/v2/plugins/publisher/bar/3/config/schema", r.port))
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, 200)
+			body, err := ioutil.ReadAll(resp.Body)
+			So(err, ShouldBeNil)
+			So(string(body), ShouldContainSubstring, `"$schema": "http://json-schema.org/draft-04/schema#"`)
+			So(string(body), ShouldContainSubstring, `"type": "object"`)
+
+			resp, err = http.Get(
+				fmt.Sprintf("http://localhost:%d/v2/plugins/publisher/bar/9/config/schema", r.port))
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, 404)
+		})
+
 		Convey("Set plugin config item- v2/plugins/:type/:name/:version/config", func() {
 			c := &http.Client{}
 			pluginName := "foo"
@@ -294,6 +350,44 @@ func TestV2Task(t *testing.T) {
 			)
 		})
 
+		Convey("Run task - v2/tasks/run", func() {
+			resp, err := http.Post(
+				fmt.Sprintf("http://localhost:%d/v2/tasks/run?timeout=5s", r.port),
+				http.DetectContentType([]byte(mock.TASK)),
+				strings.NewReader(mock.TASK))
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, 200)
+			body, err := ioutil.ReadAll(resp.Body)
+			So(err, ShouldBeNil)
+			So(string(body), ShouldResemble, "{\n  \"collected\": [],\n  \"published\": []\n}\n")
+		})
+
+		Convey("Run task with an invalid timeout - v2/tasks/run", func() {
+			resp, err := http.Post(
+				fmt.Sprintf("http://localhost:%d/v2/tasks/run?timeout=-1s", r.port),
+				http.DetectContentType([]byte(mock.TASK)),
+				strings.NewReader(mock.TASK))
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, 400)
+		})
+
+		Convey("Get tasks with an invalid label selector - v2/tasks", func() {
+			resp, err := http.Get(
+				fmt.Sprintf("http://localhost:%d/v2/tasks?labels=env", r.port))
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, 400)
+		})
+
+		Convey("Get worker pools - v2/scheduler/pools", func() {
+			resp, err := http.Get(
+				fmt.Sprintf("http://localhost:%d/v2/scheduler/pools", r.port))
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, 200)
+			body, err := ioutil.ReadAll(resp.Body)
+			So(err, ShouldBeNil)
+			So(string(body), ShouldResemble, mock.GET_WORKER_POOLS_RESPONSE)
+		})
+
 		Convey("Get tasks - v2/tasks", func() {
 			resp, err := http.Get(
 				fmt.Sprintf("http://localhost:%d/v2/tasks", r.port))
@@ -441,6 +535,116 @@ func TestV2Task(t *testing.T) {
 				ShouldResemble,
 				fmt.Sprintf(mock.REMOVE_TASK_RESPONSE_ID))
 		})
+
+		Convey("Bulk start tasks - v2/tasks", func() {
+			c := &http.Client{}
+			req, err := http.NewRequest(
+				"PUT",
+				fmt.Sprintf("http://localhost:%d/v2/tasks?action=start&name=TASK*&labels=team=storage", r.port),
+				bytes.NewReader([]byte{}))
+			So(err, ShouldBeNil)
+			resp, err := c.Do(req)
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, http.StatusOK)
+			body, err := ioutil.ReadAll(resp.Body)
+			So(err, ShouldBeNil)
+			So(string(body), ShouldResemble, mock.BULK_START_TASKS_RESPONSE)
+		})
+
+		Convey("Bulk start tasks without a selector - v2/tasks", func() {
+			c := &http.Client{}
+			req, err := http.NewRequest(
+				"PUT",
+				fmt.Sprintf("http://localhost:%d/v2/tasks?action=start", r.port),
+				bytes.NewReader([]byte{}))
+			So(err, ShouldBeNil)
+			resp, err := c.Do(req)
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, http.StatusBadRequest)
+		})
+
+		Convey("Bulk pause tasks - v2/tasks", func() {
+			c := &http.Client{}
+			req, err := http.NewRequest(
+				"PUT",
+				fmt.Sprintf("http://localhost:%d/v2/tasks?action=pause&name=TASK*", r.port),
+				bytes.NewReader([]byte{}))
+			So(err, ShouldBeNil)
+			resp, err := c.Do(req)
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, http.StatusBadRequest)
+		})
+
+		Convey("Bulk remove tasks - v2/tasks", func() {
+			c := &http.Client{}
+			req, err := http.NewRequest(
+				"DELETE",
+				fmt.Sprintf("http://localhost:%d/v2/tasks?name=TASK*", r.port),
+				bytes.NewReader([]byte{}))
+			So(err, ShouldBeNil)
+			resp, err := c.Do(req)
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, http.StatusConflict)
+			body, err := ioutil.ReadAll(resp.Body)
+			So(err, ShouldBeNil)
+			So(string(body), ShouldResemble, mock.BULK_REMOVE_TASKS_RESPONSE)
+		})
+
+		Convey("Get dead letters - v2/deadletters", func() {
+			resp, err := http.Get(
+				fmt.Sprintf("http://localhost:%d/v2/deadletters", r.port))
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, 200)
+			body, err := ioutil.ReadAll(resp.Body)
+			So(err, ShouldBeNil)
+			So(string(body), ShouldResemble, mock.GET_DEAD_LETTERS_RESPONSE)
+		})
+
+		Convey("Get dead letter - v2/deadletters/:id", func() {
+			resp, err := http.Get(
+				fmt.Sprintf("http://localhost:%d/v2/deadletters/unknown", r.port))
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, http.StatusNotFound)
+		})
+
+		Convey("Replay dead letters - v2/deadletters", func() {
+			c := &http.Client{}
+			req, err := http.NewRequest(
+				"PUT",
+				fmt.Sprintf("http://localhost:%d/v2/deadletters?action=replay&id=a&id=b", r.port),
+				bytes.NewReader([]byte{}))
+			So(err, ShouldBeNil)
+			resp, err := c.Do(req)
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, 200)
+			body, err := ioutil.ReadAll(resp.Body)
+			So(err, ShouldBeNil)
+			So(string(body), ShouldResemble, mock.REPLAY_DEAD_LETTERS_RESPONSE)
+		})
+
+		Convey("Replay dead letters without action - v2/deadletters", func() {
+			c := &http.Client{}
+			req, err := http.NewRequest(
+				"PUT",
+				fmt.Sprintf("http://localhost:%d/v2/deadletters", r.port),
+				bytes.NewReader([]byte{}))
+			So(err, ShouldBeNil)
+			resp, err := c.Do(req)
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, http.StatusBadRequest)
+		})
+
+		Convey("Purge dead letters - v2/deadletters", func() {
+			c := &http.Client{}
+			req, err := http.NewRequest(
+				"DELETE",
+				fmt.Sprintf("http://localhost:%d/v2/deadletters", r.port),
+				bytes.NewReader([]byte{}))
+			So(err, ShouldBeNil)
+			resp, err := c.Do(req)
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, http.StatusNotFound)
+		})
 	})
 }
 
@@ -462,5 +666,34 @@ func TestV2Metric(t *testing.T) {
 				ShouldResemble,
 				fmt.Sprintf(mock.GET_METRICS_RESPONSE, r.port))
 		})
+
+		Convey("Get metrics page - v2/metrics?offset=0&limit=10", func() {
+			resp, err := http.Get(
+				fmt.Sprintf("http://localhost:%d/v2/metrics?offset=0&limit=10", r.port))
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, 200)
+			body, err := ioutil.ReadAll(resp.Body)
+			So(err, ShouldBeNil)
+			resp1, err := url.QueryUnescape(string(body))
+			So(err, ShouldBeNil)
+			So(
+				resp1,
+				ShouldResemble,
+				fmt.Sprintf(mock.GET_METRICS_PAGE_RESPONSE, r.port))
+		})
+
+		Convey("Get metrics page with invalid limit - v2/metrics?limit=-1", func() {
+			resp, err := http.Get(
+				fmt.Sprintf("http://localhost:%d/v2/metrics?limit=-1", r.port))
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, 400)
+		})
+
+		Convey("Get metrics page with version - v2/metrics?limit=1&ver=1", func() {
+			resp, err := http.Get(
+				fmt.Sprintf("http://localhost:%d/v2/metrics?limit=1&ver=1", r.port))
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, 400)
+		})
 	})
 }
@@ -21,11 +21,13 @@ package rest
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"net"
 	"net/http"
 	"net/url"
+	"os"
 	"strconv"
 	"sync"
 	"time"
@@ -49,28 +51,56 @@ const (
 )
 
 var (
-	ErrBadCert = errors.New("Invalid certificate given")
+	ErrBadCert                = errors.New("Invalid certificate given")
+	ErrBadUnixSocketMode      = errors.New("Invalid unix socket mode given, expected octal permissions such as 0660")
+	ErrUnixSocketOnlyNoSocket = errors.New("The REST API can't be served only on a unix socket without a unix socket path")
+	ErrUnixSocketInUse        = errors.New("The unix socket is already in use")
 
 	restLogger     = log.WithField("_module", "_mgmt-rest")
 	protocolPrefix = "http"
 )
 
 type Server struct {
-	apis           []api.API
-	n              *negroni.Negroni
-	r              *httprouter.Router
-	snapTLS        *snapTLS
+	apis    []api.API
+	n       *negroni.Negroni
+	r       *httprouter.Router
+	snapTLS *snapTLS
+	pprof   bool
+	// authMutex guards the authentication and the roles, they're reloaded
+	// while the API is served
+	authMutex      sync.RWMutex
 	auth           bool
-	pprof          bool
 	authpwd        string
+	authTokens     []string
+	authTokenFile  *tokenFile
+	clientCAs      *x509.CertPool
 	addrString     string
 	addr           net.Addr
 	wg             sync.WaitGroup
 	killChan       chan struct{}
 	err            chan error
 	allowedOrigins map[string]bool
+	// roles are the roles granted to the identities, every identity is
+	// granted every role when it's nil
+	roles map[string]map[string]bool
+	// audit is the log of the mutating requests, nil when auditing is
+	// disabled
+	audit *auditLog
+	// exporter serves GET /metrics to Prometheus, nil when the statistics
+	// aren't exported
+	exporter http.Handler
+	// healthReporters are the subsystems reported by /healthz and /readyz
+	healthReporters []api.HealthReporter
+	// spec is the OpenAPI description of the routes, served by GET /v1/spec
+	spec []byte
+	// unixSocket is the path of the unix socket the API is also served on,
+	// or only on when unixSocketOnly is set
+	unixSocket     string
+	unixSocketMode os.FileMode
+	unixSocketOnly bool
 	// the following instance variables are used to cleanly shutdown the server
 	serverListener net.Listener
+	unixListener   net.Listener
 	closingChan    chan bool
 }
 
@@ -93,6 +123,52 @@ func New(cfg *Config) (*Server, error) {
 	}
 	restLogger.Info(fmt.Sprintf("Configuring REST API with HTTPS set to: %v", cfg.HTTPS))
 
+	if cfg.UnixSocketOnly && cfg.UnixSocket == "" {
+		return nil, ErrUnixSocketOnlyNoSocket
+	}
+	if cfg.UnixSocket != "" {
+		mode, err := strconv.ParseUint(cfg.UnixSocketMode, 8, 32)
+		if err != nil || mode > 0777 {
+			return nil, ErrBadUnixSocketMode
+		}
+		s.unixSocket = cfg.UnixSocket
+		s.unixSocketMode = os.FileMode(mode)
+		s.unixSocketOnly = cfg.UnixSocketOnly
+	}
+
+	// the tokens and the client certificates enable the authentication,
+	// along with the password set by snapteld
+	s.authTokens = cfg.RestAuthTokens
+	if cfg.RestAuthTokenFile != "" {
+		var err error
+		s.authTokenFile, err = newTokenFile(cfg.RestAuthTokenFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if cfg.RestClientCA != "" {
+		if !cfg.HTTPS {
+			return nil, ErrClientCAWithoutHTTPS
+		}
+		var err error
+		s.clientCAs, err = loadClientCAs(cfg.RestClientCA)
+		if err != nil {
+			return nil, err
+		}
+	}
+	s.auth = len(s.authTokens) > 0 || s.authTokenFile != nil || s.clientCAs != nil
+	roles, err := newRoles(cfg.RestAuthRoles)
+	if err != nil {
+		return nil, err
+	}
+	s.roles = roles
+	if cfg.AuditLog != "" {
+		if s.audit, err = newAuditLog(cfg.AuditLog); err != nil {
+			return nil, err
+		}
+		restLogger.Info("Recording the mutating REST API requests to ", cfg.AuditLog)
+	}
+
 	s.apis = []api.API{
 		v1.New(&s.wg, s.killChan, protocolPrefix),
 		v2.New(&s.wg, s.killChan, protocolPrefix),
@@ -150,13 +226,36 @@ func (s *Server) BindConfigManager(c api.Config) {
 	}
 }
 
-// SetAPIAuth sets API authentication to enabled or disabled
+func (s *Server) BindNotifier(n api.Notifier) {
+	for _, apiInstance := range s.apis {
+		apiInstance.BindNotifier(n)
+	}
+}
+
+func (s *Server) BindLogging(l api.Logging) {
+	for _, apiInstance := range s.apis {
+		apiInstance.BindLogging(l)
+	}
+}
+
+func (s *Server) BindConfigReloader(c api.ConfigReloader) {
+	for _, apiInstance := range s.apis {
+		apiInstance.BindConfigReloader(c)
+	}
+}
+
+// SetAPIAuth sets API authentication to enabled or disabled, it is always
+// enabled when tokens or client certificates are configured
 func (s *Server) SetAPIAuth(auth bool) {
-	s.auth = auth
+	s.authMutex.Lock()
+	defer s.authMutex.Unlock()
+	s.auth = auth || len(s.authTokens) > 0 || s.authTokenFile != nil || s.clientCAs != nil
 }
 
 // SetAPIAuthPwd sets the API authentication password from snapteld
 func (s *Server) SetAPIAuthPwd(pwd string) {
+	s.authMutex.Lock()
+	defer s.authMutex.Unlock()
 	s.authpwd = pwd
 }
 
@@ -166,15 +265,17 @@ func (s *Server) authMiddleware(rw http.ResponseWriter, r *http.Request, next ht
 	s.setAllowedOrigins(rw, reqOrigin)
 
 	defer r.Body.Close()
-	if s.auth {
-		_, password, ok := r.BasicAuth()
-		// If we have valid password or going to tribe/agreements endpoint
-		// go to next. tribe/agreements endpoint used for populating
-		// snaptel help page when tribe mode is turned on.
-		if ok && password == s.authpwd {
-			next(rw, r)
+	s.authMutex.RLock()
+	auth := s.auth
+	s.authMutex.RUnlock()
+	// the probes of load balancers and orchestrators don't authenticate
+	if auth && !isProbe(r) {
+		// The request is authenticated by a client certificate, a bearer
+		// token or the password
+		if id, ok := s.authenticated(r); ok {
+			next(rw, withIdentity(r, id))
 		} else {
-			v2.Write(401, v2.UnauthError{Code: 401, Message: "Not authorized. Please specify the same password that used to start snapteld. E.g: [snaptel -p plugin list] or [curl http://localhost:8181/v2/plugins -u snap]"}, rw)
+			v2.Write(401, v2.FromError(v2.ErrNotAuthorized), rw)
 		}
 	} else {
 		next(rw, r)
@@ -208,8 +309,10 @@ func (s *Server) Name() string {
 }
 
 func (s *Server) Start() error {
-	s.closingChan = make(chan bool, 1)
-	s.addRoutes()
+	s.closingChan = make(chan bool)
+	if err := s.addRoutes(); err != nil {
+		return err
+	}
 	s.run(s.addrString)
 	restLogger.WithFields(log.Fields{
 		"_block": "start",
@@ -218,13 +321,22 @@ func (s *Server) Start() error {
 }
 
 func (s *Server) Stop() {
-	// add a boolean to the s.closingChan (used for error handling in the
-	// goroutine that is listening for connections)
-	s.closingChan <- true
+	// close the s.closingChan (used for error handling in the goroutines
+	// that are listening for connections)
+	close(s.closingChan)
 	// then close the server
 	close(s.killChan)
-	// close the server listener
-	s.serverListener.Close()
+	// close the server listeners, closing the unix listener removes its
+	// socket
+	if s.serverListener != nil {
+		s.serverListener.Close()
+	}
+	if s.unixListener != nil {
+		s.unixListener.Close()
+	}
+	if s.audit != nil {
+		s.audit.close()
+	}
 	// wait for the server goroutines to complete (serve and watch)
 	s.wg.Wait()
 	// finally log the result
@@ -237,11 +349,29 @@ func (s *Server) Err() <-chan error {
 	return s.err
 }
 
+// Port returns the TCP port the API is served on, 0 when it's only served on
+// a unix socket
 func (s *Server) Port() int {
-	return s.addr.(*net.TCPAddr).Port
+	if addr, ok := s.addr.(*net.TCPAddr); ok {
+		return addr.Port
+	}
+	return 0
 }
 
 func (s *Server) run(addrString string) {
+	if s.unixSocket != "" {
+		restLogger.Info("Starting REST API on unix socket ", s.unixSocket)
+		ln, err := listenUnix(s.unixSocket, s.unixSocketMode)
+		if err != nil {
+			log.Fatal(err)
+		}
+		s.unixListener = ln
+		s.wg.Add(1)
+		go s.serve(ln)
+		if s.unixSocketOnly {
+			return
+		}
+	}
 	restLogger.Info("Starting REST API on ", addrString)
 	if s.snapTLS != nil {
 		cer, err := tls.LoadX509KeyPair(s.snapTLS.cert, s.snapTLS.key)
@@ -250,13 +380,19 @@ func (s *Server) run(addrString string) {
 			return
 		}
 		config := &tls.Config{Certificates: []tls.Certificate{cer}}
+		if s.clientCAs != nil {
+			// the clients without a certificate can still authenticate
+			// with a token or the password
+			config.ClientCAs = s.clientCAs
+			config.ClientAuth = tls.VerifyClientCertIfGiven
+		}
 		ln, err := tls.Listen("tcp", addrString, config)
 		if err != nil {
 			log.Fatal(err)
 		}
 		s.serverListener = ln
 		s.wg.Add(1)
-		go s.serveTLS(ln)
+		go s.serve(ln)
 	} else {
 		ln, err := net.Listen("tcp", addrString)
 		if err != nil {
@@ -265,33 +401,44 @@ func (s *Server) run(addrString string) {
 		s.serverListener = ln
 		s.addr = ln.Addr()
 		s.wg.Add(1)
-		go s.serve(ln)
+		go s.serve(tcpKeepAliveListener{ln.(*net.TCPListener)})
 	}
 }
 
-func (s *Server) serveTLS(ln net.Listener) {
-	defer s.wg.Done()
-	err := http.Serve(ln, s.n)
-	if err != nil {
-		select {
-		case <-s.closingChan:
-		// If we called Stop() then there will be a value in s.closingChan, so
-		// we'll get here and we can exit without showing the error.
-		default:
-			restLogger.Error(err)
-			s.err <- err
+// listenUnix listens on the unix socket at path with the given permissions,
+// a socket left behind by a previous snapteld is removed
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s is not a unix socket", path)
+		}
+		if c, err := net.Dial("unix", path); err == nil {
+			c.Close()
+			return nil, ErrUnixSocketInUse
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
 		}
 	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
 }
 
 func (s *Server) serve(ln net.Listener) {
 	defer s.wg.Done()
-	err := http.Serve(tcpKeepAliveListener{ln.(*net.TCPListener)}, s.n)
+	err := http.Serve(ln, s.n)
 	if err != nil {
 		select {
 		case <-s.closingChan:
-		// If we called Stop() then there will be a value in s.closingChan, so
-		// we'll get here and we can exit without showing the error.
+		// If we called Stop() then s.closingChan is closed, so we'll get
+		// here and we can exit without showing the error.
 		default:
 			restLogger.Error(err)
 			s.err <- err
@@ -299,13 +446,18 @@ func (s *Server) serve(ln net.Listener) {
 	}
 }
 
-func (s *Server) addRoutes() {
-	for _, apiInstance := range s.apis {
-		for _, route := range apiInstance.GetRoutes() {
-			s.r.Handle(route.Method, route.Path, route.Handle)
-		}
+func (s *Server) addRoutes() error {
+	routes := s.specRoutes()
+	if err := s.buildSpec(routes); err != nil {
+		return err
+	}
+	for _, route := range routes {
+		s.r.Handle(route.Method, route.Path, gzipped(s.audited(route, s.authorize(route))))
 	}
 	s.addPprofRoutes()
+	s.addPrometheusRoute()
+	s.addHealthRoutes()
+	return nil
 }
 
 func (s *Server) getAllowedOrigins(corsd string) ([]string, error) {
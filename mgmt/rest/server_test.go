@@ -86,6 +86,9 @@ func TestRestAPIConfigJSON(t *testing.T) {
 		Convey("RestAuthPassword should equal changeme", func() {
 			So(cfg.RestAuthPassword, ShouldEqual, "changeme")
 		})
+		Convey("RestAuthTokens should equal d2a1f6f0 and 7c9e03b4", func() {
+			So(cfg.RestAuthTokens, ShouldResemble, []string{"d2a1f6f0", "7c9e03b4"})
+		})
 		Convey("RestCertificate should equal /etc/snap/cert.pem", func() {
 			So(cfg.RestCertificate, ShouldEqual, "/etc/snap/cert.pem")
 		})
@@ -128,6 +131,9 @@ func TestRestAPIConfigYaml(t *testing.T) {
 		Convey("RestAuthPassword should equal changeme", func() {
 			So(cfg.RestAuthPassword, ShouldEqual, "changeme")
 		})
+		Convey("RestAuthTokens should equal d2a1f6f0 and 7c9e03b4", func() {
+			So(cfg.RestAuthTokens, ShouldResemble, []string{"d2a1f6f0", "7c9e03b4"})
+		})
 		Convey("RestCertificate should equal /etc/snap/cert.pem", func() {
 			So(cfg.RestCertificate, ShouldEqual, "/etc/snap/cert.pem")
 		})
@@ -158,6 +164,15 @@ func TestRestAPIDefaultConfig(t *testing.T) {
 		Convey("RestAuthPassword should be empty", func() {
 			So(cfg.RestAuthPassword, ShouldEqual, "")
 		})
+		Convey("RestAuthTokens should be empty", func() {
+			So(cfg.RestAuthTokens, ShouldBeEmpty)
+		})
+		Convey("RestAuthTokenFile should be empty", func() {
+			So(cfg.RestAuthTokenFile, ShouldEqual, "")
+		})
+		Convey("RestClientCA should be empty", func() {
+			So(cfg.RestClientCA, ShouldEqual, "")
+		})
 		Convey("RestCertificate should be empty", func() {
 			So(cfg.RestCertificate, ShouldEqual, "")
 		})
@@ -0,0 +1,290 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/intelsdi-x/snap/mgmt/rest/api"
+)
+
+const specPath = "/v1/spec"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// spec is the OpenAPI (swagger 2.0) description of the REST API
+type spec struct {
+	Swagger     string                        `json:"swagger"`
+	Info        specInfo                      `json:"info"`
+	Schemes     []string                      `json:"schemes"`
+	Consumes    []string                      `json:"consumes"`
+	Produces    []string                      `json:"produces"`
+	Paths       map[string]map[string]*specOp `json:"paths"`
+	Definitions map[string]*specSchema        `json:"definitions,omitempty"`
+}
+
+type specInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type specOp struct {
+	Tags       []string                `json:"tags,omitempty"`
+	Parameters []specParameter         `json:"parameters,omitempty"`
+	Responses  map[string]specResponse `json:"responses"`
+}
+
+type specParameter struct {
+	Name     string      `json:"name"`
+	In       string      `json:"in"`
+	Required bool        `json:"required"`
+	Type     string      `json:"type,omitempty"`
+	Schema   *specSchema `json:"schema,omitempty"`
+}
+
+type specResponse struct {
+	Description string      `json:"description"`
+	Schema      *specSchema `json:"schema,omitempty"`
+}
+
+type specSchema struct {
+	Ref                  string                 `json:"$ref,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Items                *specSchema            `json:"items,omitempty"`
+	Properties           map[string]*specSchema `json:"properties,omitempty"`
+	AdditionalProperties *specSchema            `json:"additionalProperties,omitempty"`
+}
+
+// newSpec describes the routes, the schemas of their bodies are derived from
+// the samples of the routes
+func newSpec(routes []api.Route) *spec {
+	sp := &spec{
+		Swagger:     "2.0",
+		Info:        specInfo{Title: "Snap REST API", Version: "1"},
+		Schemes:     []string{protocolPrefix},
+		Consumes:    []string{"application/json"},
+		Produces:    []string{"application/json"},
+		Paths:       map[string]map[string]*specOp{},
+		Definitions: map[string]*specSchema{},
+	}
+	for _, route := range routes {
+		p, params := specRoutePath(route.Path)
+		op := &specOp{
+			Parameters: params,
+			Responses:  map[string]specResponse{},
+		}
+		// the routes are tagged with their resource, e.g. /v1/plugins/:type
+		// is tagged plugins
+		if segments := strings.Split(strings.Trim(route.Path, "/"), "/"); len(segments) > 1 {
+			op.Tags = []string{segments[1]}
+		}
+		if route.Body != nil {
+			op.Parameters = append(op.Parameters, specParameter{
+				Name:     "body",
+				In:       "body",
+				Required: true,
+				Schema:   sp.schemaOfValue(reflect.ValueOf(route.Body)),
+			})
+		}
+		resp := specResponse{Description: "The response of the route"}
+		if route.Response != nil {
+			resp.Schema = sp.schemaOfValue(reflect.ValueOf(route.Response))
+		}
+		op.Responses["default"] = resp
+		if sp.Paths[p] == nil {
+			sp.Paths[p] = map[string]*specOp{}
+		}
+		sp.Paths[p][strings.ToLower(route.Method)] = op
+	}
+	return sp
+}
+
+// specRoutePath returns the path of a route in the spec and its parameters,
+// e.g. /v1/tasks/:id is /v1/tasks/{id}
+func specRoutePath(route string) (string, []specParameter) {
+	var params []specParameter
+	segments := strings.Split(route, "/")
+	for i, seg := range segments {
+		if len(seg) > 1 && (seg[0] == ':' || seg[0] == '*') {
+			params = append(params, specParameter{
+				Name:     seg[1:],
+				In:       "path",
+				Required: true,
+				Type:     "string",
+			})
+			segments[i] = "{" + seg[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// schemaOfValue returns the schema of a sample, the interfaces holding a value
+// in the sample are described by the type of their value
+func (sp *spec) schemaOfValue(v reflect.Value) *specSchema {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			return sp.schemaOfType(v.Type())
+		}
+		return sp.schemaOfValue(v.Elem())
+	case reflect.Struct:
+		if !hasInterfaceValues(v) {
+			return sp.schemaOfType(v.Type())
+		}
+		// the struct is specific to the sample, it's described inline
+		s := &specSchema{Type: "object", Properties: map[string]*specSchema{}}
+		sp.addProperties(s, v.Type(), func(i int) *specSchema {
+			return sp.schemaOfValue(v.Field(i))
+		})
+		return s
+	}
+	return sp.schemaOfType(v.Type())
+}
+
+func hasInterfaceValues(v reflect.Value) bool {
+	for i := 0; i < v.NumField(); i++ {
+		if f := v.Field(i); f.Kind() == reflect.Interface && !f.IsNil() && v.Type().Field(i).PkgPath == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaOfType returns the schema of a type, the named structs are described
+// once in the definitions of the spec
+func (sp *spec) schemaOfType(t reflect.Type) *specSchema {
+	if t == timeType {
+		return &specSchema{Type: "string", Format: "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return sp.schemaOfType(t.Elem())
+	case reflect.Bool:
+		return &specSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &specSchema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &specSchema{Type: "integer", Format: "int32"}
+	case reflect.Float32:
+		return &specSchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &specSchema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &specSchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &specSchema{Type: "string", Format: "byte"}
+		}
+		return &specSchema{Type: "array", Items: sp.schemaOfType(t.Elem())}
+	case reflect.Map:
+		return &specSchema{Type: "object", AdditionalProperties: sp.schemaOfType(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			s := &specSchema{Type: "object", Properties: map[string]*specSchema{}}
+			sp.addProperties(s, t, nil)
+			return s
+		}
+		name := path.Base(t.PkgPath()) + "." + t.Name()
+		if _, ok := sp.Definitions[name]; !ok {
+			// the definition is added before its properties, ending the
+			// recursion of types referring to themselves
+			s := &specSchema{Type: "object", Properties: map[string]*specSchema{}}
+			sp.Definitions[name] = s
+			sp.addProperties(s, t, nil)
+		}
+		return &specSchema{Ref: "#/definitions/" + name}
+	}
+	// interfaces, and any other kind, can hold any value
+	return &specSchema{}
+}
+
+// addProperties adds the JSON fields of a struct to its schema, the fields
+// of the embedded structs are added to the schema of the struct
+func (sp *spec) addProperties(s *specSchema, t reflect.Type, field func(int) *specSchema) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+		name := f.Name
+		if tag := f.Tag.Get("json"); tag != "" {
+			if tag == "-" {
+				continue
+			}
+			if n := strings.Split(tag, ",")[0]; n != "" {
+				name = n
+			}
+		}
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && f.Tag.Get("json") == "" && ft.Kind() == reflect.Struct {
+			sp.addProperties(s, ft, nil)
+			continue
+		}
+		if f.PkgPath != "" || ft.Kind() == reflect.Func || ft.Kind() == reflect.Chan {
+			continue
+		}
+		if field != nil {
+			s.Properties[name] = field(i)
+		} else {
+			s.Properties[name] = sp.schemaOfType(f.Type)
+		}
+	}
+}
+
+// getSpec writes the spec of the REST API
+func (s *Server) getSpec(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(s.spec)
+}
+
+// specRoutes returns the routes of the APIs and of the spec
+func (s *Server) specRoutes() []api.Route {
+	var routes []api.Route
+	for _, apiInstance := range s.apis {
+		routes = append(routes, apiInstance.GetRoutes()...)
+	}
+	routes = append(routes, api.Route{Method: "GET", Path: specPath, Handle: s.getSpec, Response: spec{}})
+	if s.audit != nil {
+		routes = append(routes, api.Route{Method: "GET", Path: auditPath, Handle: s.getAudit, Response: AuditResponse{}})
+	}
+	return routes
+}
+
+// buildSpec marshals the spec of the routes, served by GET /v1/spec
+func (s *Server) buildSpec(routes []api.Route) error {
+	b, err := json.MarshalIndent(newSpec(routes), "", "  ")
+	if err != nil {
+		return err
+	}
+	s.spec = b
+	return nil
+}
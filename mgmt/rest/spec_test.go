@@ -0,0 +1,83 @@
+// +build medium
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/mgmt/rest/api"
+	"github.com/intelsdi-x/snap/mgmt/rest/v1/rbody"
+)
+
+func TestSpec(t *testing.T) {
+	Convey("newSpec", t, func() {
+		sp := newSpec([]api.Route{
+			{Method: "GET", Path: "/v1/tasks/:id", Response: &rbody.APIResponse{Body: &rbody.ScheduledTaskReturned{}}},
+			{Method: "POST", Path: "/v1/tasks", Body: &core.TaskCreationRequest{}},
+			{Method: "GET", Path: "/v1/metrics/*namespace"},
+		})
+		Convey("describes the paths and their parameters", func() {
+			So(sp.Paths, ShouldContainKey, "/v1/tasks/{id}")
+			So(sp.Paths, ShouldContainKey, "/v1/metrics/{namespace}")
+			op := sp.Paths["/v1/tasks/{id}"]["get"]
+			So(op.Tags, ShouldResemble, []string{"tasks"})
+			So(op.Parameters, ShouldHaveLength, 1)
+			So(op.Parameters[0].Name, ShouldEqual, "id")
+			So(op.Parameters[0].In, ShouldEqual, "path")
+		})
+		Convey("describes the bodies of the requests", func() {
+			op := sp.Paths["/v1/tasks"]["post"]
+			So(op.Parameters, ShouldHaveLength, 1)
+			So(op.Parameters[0].In, ShouldEqual, "body")
+			So(op.Parameters[0].Schema.Ref, ShouldEqual, "#/definitions/core.TaskCreationRequest")
+			So(sp.Definitions, ShouldContainKey, "core.TaskCreationRequest")
+		})
+		Convey("describes the bodies of the responses", func() {
+			schema := sp.Paths["/v1/tasks/{id}"]["get"].Responses["default"].Schema
+			So(schema.Properties["meta"].Ref, ShouldEqual, "#/definitions/rbody.APIResponseMeta")
+			So(schema.Properties["body"].Ref, ShouldEqual, "#/definitions/rbody.ScheduledTaskReturned")
+			So(sp.Definitions["rbody.APIResponseMeta"].Properties["code"].Type, ShouldEqual, "integer")
+		})
+	})
+}
+
+func TestSpecRoute(t *testing.T) {
+	r := startV1API(getDefaultMockConfig(), "task")
+	Convey("GET /v1/spec returns the spec of the routes", t, func() {
+		resp, err := http.Get(fmt.Sprintf("http://localhost:%d/v1/spec", r.port))
+		So(err, ShouldBeNil)
+		defer resp.Body.Close()
+		So(resp.StatusCode, ShouldEqual, 200)
+		sp := spec{}
+		So(json.NewDecoder(resp.Body).Decode(&sp), ShouldBeNil)
+		So(sp.Swagger, ShouldEqual, "2.0")
+		So(sp.Paths, ShouldContainKey, "/v1/spec")
+		So(sp.Paths, ShouldContainKey, "/v1/tasks/{id}")
+		So(sp.Paths, ShouldContainKey, "/v2/tasks")
+	})
+}
@@ -0,0 +1,108 @@
+// +build medium
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/intelsdi-x/snap/mgmt/rest/v2/mock"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUnixSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snap-rest-unix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "snapteld.sock")
+
+	get := func(path string) (*http.Response, error) {
+		c := &http.Client{Transport: &http.Transport{
+			Dial: func(_, _ string) (net.Conn, error) { return net.Dial("unix", socket) },
+		}}
+		return c.Get("http://unix" + path)
+	}
+
+	Convey("Serving the REST API on a unix socket", t, func() {
+		cfg := GetDefaultConfig()
+		cfg.UnixSocket = socket
+		cfg.UnixSocketMode = "0600"
+		cfg.UnixSocketOnly = true
+		r, err := New(cfg)
+		So(err, ShouldBeNil)
+		r.BindMetricManager(&mock.MockManagesMetrics{})
+		r.BindConfigManager(&mock.MockConfigManager{})
+		So(r.Start(), ShouldBeNil)
+
+		Convey("serves the requests with the permissions of the socket", func() {
+			fi, err := os.Stat(socket)
+			So(err, ShouldBeNil)
+			So(fi.Mode()&os.ModePerm, ShouldEqual, os.FileMode(0600))
+			rsp, err := get("/v2/plugins")
+			So(err, ShouldBeNil)
+			rsp.Body.Close()
+			So(rsp.StatusCode, ShouldEqual, 200)
+		})
+		Convey("doesn't listen on a TCP port", func() {
+			So(r.Port(), ShouldEqual, 0)
+		})
+		Convey("refuses to take over the socket of a running server", func() {
+			_, err := listenUnix(socket, 0600)
+			So(err, ShouldEqual, ErrUnixSocketInUse)
+		})
+
+		r.Stop()
+		_, err = os.Stat(socket)
+		So(os.IsNotExist(err), ShouldBeTrue)
+	})
+
+	Convey("Listening on a unix socket left behind", t, func() {
+		// a crashed server leaves its socket
+		fd, err := syscall.Socket(syscall.AF_UNIX, syscall.SOCK_STREAM, 0)
+		So(err, ShouldBeNil)
+		So(syscall.Bind(fd, &syscall.SockaddrUnix{Name: socket}), ShouldBeNil)
+		syscall.Close(fd)
+		ln, err := listenUnix(socket, 0660)
+		So(err, ShouldBeNil)
+		ln.Close()
+	})
+
+	Convey("Invalid unix socket configurations are rejected", t, func() {
+		cfg := GetDefaultConfig()
+		cfg.UnixSocket = socket
+		cfg.UnixSocketMode = "rw-rw----"
+		_, err := New(cfg)
+		So(err, ShouldEqual, ErrBadUnixSocketMode)
+
+		cfg = GetDefaultConfig()
+		cfg.UnixSocketOnly = true
+		_, err = New(cfg)
+		So(err, ShouldEqual, ErrUnixSocketOnlyNoSocket)
+	})
+}
@@ -4,7 +4,10 @@ import (
 	"sync"
 
 	log "github.com/Sirupsen/logrus"
+	"github.com/intelsdi-x/snap/core"
 	"github.com/intelsdi-x/snap/mgmt/rest/api"
+	"github.com/intelsdi-x/snap/mgmt/rest/v1/rbody"
+	"github.com/intelsdi-x/snap/mgmt/tribe/agreement"
 )
 
 const (
@@ -25,10 +28,11 @@ var (
 )
 
 type apiV1 struct {
-	metricManager api.Metrics
-	taskManager   api.Tasks
-	tribeManager  api.Tribe
-	configManager api.Config
+	metricManager  api.Metrics
+	taskManager    api.Tasks
+	tribeManager   api.Tribe
+	configManager  api.Config
+	configReloader api.ConfigReloader
 
 	wg       *sync.WaitGroup
 	killChan chan struct{}
@@ -42,46 +46,69 @@ func New(wg *sync.WaitGroup, killChan chan struct{}, protocol string) *apiV1 {
 func (s *apiV1) GetRoutes() []api.Route {
 	routes := []api.Route{
 		// plugin routes
-		api.Route{Method: "GET", Path: prefix + "/plugins", Handle: s.getPlugins},
-		api.Route{Method: "GET", Path: prefix + "/plugins/:type", Handle: s.getPlugins},
-		api.Route{Method: "GET", Path: prefix + "/plugins/:type/:name", Handle: s.getPlugins},
-		api.Route{Method: "GET", Path: prefix + "/plugins/:type/:name/:version", Handle: s.getPlugin},
-		api.Route{Method: "POST", Path: prefix + "/plugins", Handle: s.loadPlugin},
-		api.Route{Method: "DELETE", Path: prefix + "/plugins/:type/:name/:version", Handle: s.unloadPlugin},
-		api.Route{Method: "GET", Path: prefix + "/plugins/:type/:name/:version/config", Handle: s.getPluginConfigItem},
-		api.Route{Method: "PUT", Path: prefix + "/plugins/:type/:name/:version/config", Handle: s.setPluginConfigItem},
-		api.Route{Method: "DELETE", Path: prefix + "/plugins/:type/:name/:version/config", Handle: s.deletePluginConfigItem},
+		api.Route{Method: "GET", Path: prefix + "/plugins", Handle: s.getPlugins, Response: response(&rbody.PluginList{})},
+		api.Route{Method: "GET", Path: prefix + "/plugins/:type", Handle: s.getPlugins, Response: response(&rbody.PluginList{})},
+		api.Route{Method: "GET", Path: prefix + "/plugins/:type/:name", Handle: s.getPlugins, Response: response(&rbody.PluginList{})},
+		api.Route{Method: "GET", Path: prefix + "/plugins/:type/:name/:version", Handle: s.getPlugin, Response: response(&rbody.PluginReturned{})},
+		api.Route{Method: "POST", Path: prefix + "/plugins", Handle: s.loadPlugin, Response: response(&rbody.PluginsLoaded{})},
+		api.Route{Method: "DELETE", Path: prefix + "/plugins/:type/:name/:version", Handle: s.unloadPlugin, Response: response(&rbody.PluginUnloaded{})},
+		api.Route{Method: "GET", Path: prefix + "/plugins/:type/:name/:version/config", Handle: s.getPluginConfigItem, Response: response(&rbody.PluginConfigItem{})},
+		api.Route{Method: "PUT", Path: prefix + "/plugins/:type/:name/:version/config", Handle: s.setPluginConfigItem, Body: map[string]interface{}{}, Response: response(&rbody.SetPluginConfigItem{})},
+		api.Route{Method: "DELETE", Path: prefix + "/plugins/:type/:name/:version/config", Handle: s.deletePluginConfigItem, Body: []string{}, Response: response(&rbody.DeletePluginConfigItem{})},
 
 		// metric routes
-		api.Route{Method: "GET", Path: prefix + "/metrics", Handle: s.getMetrics},
-		api.Route{Method: "GET", Path: prefix + "/metrics/*namespace", Handle: s.getMetricsFromTree},
+		api.Route{Method: "GET", Path: prefix + "/metrics", Handle: s.getMetrics, Response: response(&rbody.MetricsReturned{})},
+		api.Route{Method: "GET", Path: prefix + "/metrics/*namespace", Handle: s.getMetricsFromTree, Response: response(&rbody.MetricsReturned{})},
 
 		// task routes
-		api.Route{Method: "GET", Path: prefix + "/tasks", Handle: s.getTasks},
-		api.Route{Method: "GET", Path: prefix + "/tasks/:id", Handle: s.getTask},
+		api.Route{Method: "GET", Path: prefix + "/tasks", Handle: s.getTasks, Response: response(&rbody.ScheduledTaskListReturned{})},
+		api.Route{Method: "GET", Path: prefix + "/tasks/:id", Handle: s.getTask, Response: response(&rbody.ScheduledTaskReturned{})},
 		api.Route{Method: "GET", Path: prefix + "/tasks/:id/watch", Handle: s.watchTask},
-		api.Route{Method: "POST", Path: prefix + "/tasks", Handle: s.addTask},
-		api.Route{Method: "PUT", Path: prefix + "/tasks/:id/start", Handle: s.startTask},
-		api.Route{Method: "PUT", Path: prefix + "/tasks/:id/stop", Handle: s.stopTask},
-		api.Route{Method: "DELETE", Path: prefix + "/tasks/:id", Handle: s.removeTask},
-		api.Route{Method: "PUT", Path: prefix + "/tasks/:id/enable", Handle: s.enableTask},
+		api.Route{Method: "POST", Path: prefix + "/tasks", Handle: s.addTask, Body: &core.TaskCreationRequest{}, Response: response(&rbody.AddScheduledTask{})},
+		api.Route{Method: "PUT", Path: prefix + "/tasks/:id/start", Handle: s.startTask, Response: response(&rbody.ScheduledTaskStarted{})},
+		api.Route{Method: "PUT", Path: prefix + "/tasks/:id/stop", Handle: s.stopTask, Response: response(&rbody.ScheduledTaskStopped{})},
+		api.Route{Method: "PUT", Path: prefix + "/tasks/:id/pause", Handle: s.pauseTask, Response: response(&rbody.ScheduledTaskPaused{})},
+		api.Route{Method: "PUT", Path: prefix + "/tasks/:id/resume", Handle: s.resumeTask, Response: response(&rbody.ScheduledTaskResumed{})},
+		api.Route{Method: "DELETE", Path: prefix + "/tasks/:id", Handle: s.removeTask, Response: response(&rbody.ScheduledTaskRemoved{})},
+		api.Route{Method: "PUT", Path: prefix + "/tasks/:id/enable", Handle: s.enableTask, Response: response(&rbody.ScheduledTaskEnabled{})},
+	}
+	// config routes
+	if s.configReloader != nil {
+		routes = append(routes, []api.Route{
+			api.Route{Method: "GET", Path: prefix + "/config/effective", Handle: s.getEffectiveConfig, Response: response(&rbody.EffectiveConfig{})},
+			api.Route{Method: "PUT", Path: prefix + "/config", Handle: s.reloadConfig, Body: map[string]interface{}{}, Response: response(&rbody.ConfigReloaded{})},
+		}...)
 	}
 	// tribe routes
 	if s.tribeManager != nil {
 		routes = append(routes, []api.Route{
-			api.Route{Method: "GET", Path: prefix + "/tribe/agreements", Handle: s.getAgreements},
-			api.Route{Method: "POST", Path: prefix + "/tribe/agreements", Handle: s.addAgreement},
-			api.Route{Method: "GET", Path: prefix + "/tribe/agreements/:name", Handle: s.getAgreement},
-			api.Route{Method: "DELETE", Path: prefix + "/tribe/agreements/:name", Handle: s.deleteAgreement},
-			api.Route{Method: "PUT", Path: prefix + "/tribe/agreements/:name/join", Handle: s.joinAgreement},
-			api.Route{Method: "DELETE", Path: prefix + "/tribe/agreements/:name/leave", Handle: s.leaveAgreement},
-			api.Route{Method: "GET", Path: prefix + "/tribe/members", Handle: s.getMembers},
-			api.Route{Method: "GET", Path: prefix + "/tribe/member/:name", Handle: s.getMember},
+			api.Route{Method: "GET", Path: prefix + "/tribe/agreements", Handle: s.getAgreements, Response: response(&rbody.TribeListAgreement{})},
+			api.Route{Method: "POST", Path: prefix + "/tribe/agreements", Handle: s.addAgreement, Body: &agreementRequest{}, Response: response(&rbody.TribeAddAgreement{})},
+			api.Route{Method: "GET", Path: prefix + "/tribe/agreements/:name", Handle: s.getAgreement, Response: response(&rbody.TribeGetAgreement{})},
+			api.Route{Method: "DELETE", Path: prefix + "/tribe/agreements/:name", Handle: s.deleteAgreement, Response: response(&rbody.TribeDeleteAgreement{})},
+			api.Route{Method: "PUT", Path: prefix + "/tribe/agreements/:name/join", Handle: s.joinAgreement, Body: &agreementMember{}, Response: response(&rbody.TribeJoinAgreement{})},
+			api.Route{Method: "DELETE", Path: prefix + "/tribe/agreements/:name/leave", Handle: s.leaveAgreement, Body: &agreementMember{}, Response: response(&rbody.TribeLeaveAgreement{})},
+			api.Route{Method: "GET", Path: prefix + "/tribe/agreements/:name/snapshot", Handle: s.exportAgreement, Response: response(&rbody.TribeAgreementSnapshot{})},
+			api.Route{Method: "POST", Path: prefix + "/tribe/agreements/:name/snapshot", Handle: s.restoreAgreement, Body: &agreement.Snapshot{}, Response: response(&rbody.TribeAgreementRestored{})},
+			api.Route{Method: "PUT", Path: prefix + "/tribe/agreements/:name/tasks/:id/overrides", Handle: s.setTaskOverrides, Body: &taskOverrides{}, Response: response(&rbody.TribeTaskOverridesSet{})},
+			api.Route{Method: "GET", Path: prefix + "/tribe/members", Handle: s.getMembers, Response: response(&rbody.TribeMemberList{})},
+			api.Route{Method: "GET", Path: prefix + "/tribe/member/:name", Handle: s.getMember, Response: response(&rbody.TribeMemberShow{})},
+			api.Route{Method: "GET", Path: prefix + "/tribe/metrics", Handle: s.getTribeMetrics, Response: response(&rbody.TribeMetricCatalog{})},
+			api.Route{Method: "GET", Path: prefix + "/tribe/keys", Handle: s.getKeys, Response: response(&rbody.TribeKeyList{})},
+			api.Route{Method: "POST", Path: prefix + "/tribe/keys", Handle: s.installKey, Body: &tribeKey{}, Response: response(&rbody.TribeKeyInstalled{})},
+			api.Route{Method: "PUT", Path: prefix + "/tribe/keys", Handle: s.useKey, Body: &tribeKey{}, Response: response(&rbody.TribeKeyUsed{})},
+			api.Route{Method: "DELETE", Path: prefix + "/tribe/keys", Handle: s.removeKey, Body: &tribeKey{}, Response: response(&rbody.TribeKeyRemoved{})},
 		}...)
 	}
 	return routes
 }
 
+// response is a sample of the responses of a route, the body wrapped as
+// written by rbody.Write
+func response(b rbody.Body) *rbody.APIResponse {
+	return &rbody.APIResponse{Body: b}
+}
+
 func (s *apiV1) BindMetricManager(metricManager api.Metrics) {
 	s.metricManager = metricManager
 }
@@ -97,3 +124,11 @@ func (s *apiV1) BindTribeManager(tribeManager api.Tribe) {
 func (s *apiV1) BindConfigManager(configManager api.Config) {
 	s.configManager = configManager
 }
+
+func (s *apiV1) BindNotifier(notifier api.Notifier) {}
+
+func (s *apiV1) BindLogging(logging api.Logging) {}
+
+func (s *apiV1) BindConfigReloader(configReloader api.ConfigReloader) {
+	s.configReloader = configReloader
+}
@@ -20,11 +20,13 @@ limitations under the License.
 package v1
 
 import (
+	"io/ioutil"
 	"net/http"
 	"strconv"
 
 	"github.com/intelsdi-x/snap/core"
 	"github.com/intelsdi-x/snap/core/cdata"
+	"github.com/intelsdi-x/snap/mgmt/rest/api"
 	"github.com/intelsdi-x/snap/mgmt/rest/v1/rbody"
 	"github.com/julienschmidt/httprouter"
 )
@@ -34,7 +36,7 @@ func (s *apiV1) getPluginConfigItem(w http.ResponseWriter, r *http.Request, p ht
 	styp := p.ByName("type")
 	if styp == "" {
 		cdn := s.configManager.GetPluginConfigDataNodeAll()
-		item := &rbody.PluginConfigItem{ConfigDataNode: cdn}
+		item := &rbody.PluginConfigItem{ConfigDataNode: s.redactConfig(cdn)}
 		rbody.Write(200, item, w)
 		return
 	}
@@ -58,7 +60,7 @@ func (s *apiV1) getPluginConfigItem(w http.ResponseWriter, r *http.Request, p ht
 	}
 
 	cdn := s.configManager.GetPluginConfigDataNode(typ, name, iver)
-	item := &rbody.PluginConfigItem{ConfigDataNode: cdn}
+	item := &rbody.PluginConfigItem{ConfigDataNode: s.redactConfig(cdn)}
 	rbody.Write(200, item, w)
 }
 
@@ -99,8 +101,9 @@ func (s *apiV1) deletePluginConfigItem(w http.ResponseWriter, r *http.Request, p
 	} else {
 		res = s.configManager.DeletePluginConfigDataNodeField(typ, name, iver, src...)
 	}
+	s.reloadPluginConfig(typ, name, iver)
 
-	item := &rbody.DeletePluginConfigItem{ConfigDataNode: res}
+	item := &rbody.DeletePluginConfigItem{ConfigDataNode: s.redactConfig(res)}
 	rbody.Write(200, item, w)
 }
 
@@ -134,6 +137,10 @@ func (s *apiV1) setPluginConfigItem(w http.ResponseWriter, r *http.Request, p ht
 		rbody.Write(400, rbody.FromError(err), w)
 		return
 	}
+	if _, err := core.NewPluginPoolConfig(src); err != nil {
+		rbody.Write(400, rbody.FromError(err), w)
+		return
+	}
 
 	var res cdata.ConfigDataNode
 	if styp == "" {
@@ -141,7 +148,48 @@ func (s *apiV1) setPluginConfigItem(w http.ResponseWriter, r *http.Request, p ht
 	} else {
 		res = s.configManager.MergePluginConfigDataNode(typ, name, iver, src)
 	}
+	s.reloadPluginConfig(typ, name, iver)
 
-	item := &rbody.SetPluginConfigItem{ConfigDataNode: res}
+	item := &rbody.SetPluginConfigItem{ConfigDataNode: s.redactConfig(res)}
 	rbody.Write(200, item, w)
 }
+
+// redactConfig redacts the sensitive values of the config returned when the
+// metric manager knows them
+func (s *apiV1) redactConfig(cdn cdata.ConfigDataNode) cdata.ConfigDataNode {
+	if rc, ok := s.metricManager.(api.ConfigRedactor); ok {
+		return rc.RedactConfig(cdn)
+	}
+	return cdn
+}
+
+// reloadPluginConfig applies the updated global config to the loaded plugins
+// it belongs to, errors are logged since the config has already been stored
+func (s *apiV1) reloadPluginConfig(typ core.PluginType, name string, ver int) {
+	for _, err := range s.metricManager.ReloadPluginConfig(typ, name, ver) {
+		restLogger.WithFields(err.Fields()).Warning(err)
+	}
+}
+
+// reloadConfig applies the settings of snapteld given in the format of the
+// config file, without restarting it
+func (s *apiV1) reloadConfig(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		rbody.Write(500, rbody.FromError(err), w)
+		return
+	}
+	changed, err := s.configReloader.ReloadConfig(b)
+	if err != nil {
+		rbody.Write(400, rbody.FromError(err), w)
+		return
+	}
+	rbody.Write(200, &rbody.ConfigReloaded{Changed: changed}, w)
+}
+
+// getEffectiveConfig returns the settings snapteld runs with and the layers of
+// the configuration they come from
+func (s *apiV1) getEffectiveConfig(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	settings, sources := s.configReloader.EffectiveConfig()
+	rbody.Write(200, &rbody.EffectiveConfig{Settings: settings, Sources: sources}, w)
+}
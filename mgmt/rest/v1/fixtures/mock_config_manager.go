@@ -21,6 +21,9 @@ limitations under the License.
 package fixtures
 
 import (
+	"encoding/json"
+	"fmt"
+
 	"github.com/intelsdi-x/snap/core"
 	"github.com/intelsdi-x/snap/core/cdata"
 	"github.com/intelsdi-x/snap/core/ctypes"
@@ -107,3 +110,26 @@ const (
   }
 }`
 )
+
+// MockConfigReloader only reloads the log level, its effective configuration
+// is the default log level
+type MockConfigReloader struct{}
+
+func (MockConfigReloader) ReloadConfig(b []byte) ([]string, error) {
+	settings := map[string]interface{}{}
+	if err := json.Unmarshal(b, &settings); err != nil {
+		return nil, err
+	}
+	changed := []string{}
+	for k := range settings {
+		if k != "log_level" {
+			return nil, fmt.Errorf("'%s' can't be changed while snapteld is running", k)
+		}
+		changed = append(changed, k)
+	}
+	return changed, nil
+}
+
+func (MockConfigReloader) EffectiveConfig() (map[string]interface{}, map[string]string) {
+	return map[string]interface{}{"log_level": 3}, map[string]string{"log_level": "default"}
+}
@@ -66,6 +66,8 @@ func (m MockLoadedPlugin) Policy() *cpolicy.ConfigPolicy { return cpolicy.New()
 func (m MockLoadedPlugin) HitCount() int                 { return 0 }
 func (m MockLoadedPlugin) LastHit() time.Time            { return time.Now() }
 func (m MockLoadedPlugin) ID() uint32                    { return 0 }
+func (m MockLoadedPlugin) FailedHealthChecks() int       { return 0 }
+func (m MockLoadedPlugin) Healthy() bool                 { return true }
 
 //////MockCatalogedMetric/////
 
@@ -79,6 +81,7 @@ func (m MockCatalogedMetric) LastAdvertisedTime() time.Time     { return time.Ti
 func (m MockCatalogedMetric) Policy() *cpolicy.ConfigPolicyNode { return cpolicy.NewPolicyNode() }
 func (m MockCatalogedMetric) Description() string               { return "This Is A Description" }
 func (m MockCatalogedMetric) Unit() string                      { return "" }
+func (m MockCatalogedMetric) DataType() string                  { return "" }
 
 //////MockManagesMetrics/////
 
@@ -90,6 +93,9 @@ func (m MockManagesMetrics) MetricCatalog() ([]core.CatalogedMetric, error) {
 func (m MockManagesMetrics) FetchMetrics(core.Namespace, int) ([]core.CatalogedMetric, error) {
 	return metricCatalog, nil
 }
+func (m MockManagesMetrics) FetchMetricsPage(core.Namespace, int, int) ([]core.CatalogedMetric, int, error) {
+	return metricCatalog, len(metricCatalog), nil
+}
 func (m MockManagesMetrics) GetMetricVersions(core.Namespace) ([]core.CatalogedMetric, error) {
 	return metricCatalog, nil
 }
@@ -131,6 +137,22 @@ func (m MockManagesMetrics) GetTempDir() string {
 	return ""
 }
 
+func (m MockManagesMetrics) PluginBlacklist() []core.BlacklistedPlugin {
+	return []core.BlacklistedPlugin{}
+}
+
+func (m MockManagesMetrics) ClearPluginBlacklist(checkSum string) serror.SnapError {
+	return nil
+}
+
+func (m MockManagesMetrics) PluginMetadata(plugin core.Plugin) (*core.PluginMetadata, serror.SnapError) {
+	return nil, serror.New(errors.New("plugin not found"))
+}
+
+func (m MockManagesMetrics) ReloadPluginConfig(pluginType core.PluginType, name string, ver int) []serror.SnapError {
+	return nil
+}
+
 // These constants are the expected plugin responses from running
 // rest_v1_test.go on the plugin routes found in mgmt/rest/server.go
 const (
@@ -21,6 +21,7 @@ limitations under the License.
 package fixtures
 
 import (
+	"sync/atomic"
 	"time"
 
 	"github.com/intelsdi-x/snap/core"
@@ -67,26 +68,43 @@ type mockTask struct {
 	MyHref               string            `json:"href"`
 }
 
-func (t *mockTask) ID() string                          { return t.MyID }
-func (t *mockTask) State() core.TaskState               { return core.TaskSpinning }
-func (t *mockTask) HitCount() uint                      { return 0 }
-func (t *mockTask) GetName() string                     { return t.MyName }
-func (t *mockTask) SetName(string)                      { return }
-func (t *mockTask) SetID(string)                        { return }
-func (t *mockTask) MissedCount() uint                   { return 0 }
-func (t *mockTask) FailedCount() uint                   { return 0 }
-func (t *mockTask) LastFailureMessage() string          { return "" }
-func (t *mockTask) LastRunTime() *time.Time             { return &time.Time{} }
-func (t *mockTask) CreationTime() *time.Time            { return &time.Time{} }
-func (t *mockTask) DeadlineDuration() time.Duration     { return 4 }
-func (t *mockTask) SetDeadlineDuration(time.Duration)   { return }
-func (t *mockTask) SetTaskID(id string)                 { return }
-func (t *mockTask) SetStopOnFailure(int)                { return }
-func (t *mockTask) GetStopOnFailure() int               { return 0 }
-func (t *mockTask) MaxMetricsBuffer() int64             { return 0 }
-func (t *mockTask) SetMaxMetricsBuffer(int64)           {}
-func (t *mockTask) MaxCollectDuration() time.Duration   { return time.Second }
-func (t *mockTask) SetMaxCollectDuration(time.Duration) {}
+func (t *mockTask) ID() string                                            { return t.MyID }
+func (t *mockTask) State() core.TaskState                                 { return core.TaskSpinning }
+func (t *mockTask) HitCount() uint                                        { return 0 }
+func (t *mockTask) GetName() string                                       { return t.MyName }
+func (t *mockTask) SetName(string)                                        { return }
+func (t *mockTask) SetID(string)                                          { return }
+func (t *mockTask) MissedCount() uint                                     { return 0 }
+func (t *mockTask) FailedCount() uint                                     { return 0 }
+func (t *mockTask) LastFailureMessage() string                            { return "" }
+func (t *mockTask) LastRunTime() *time.Time                               { return &time.Time{} }
+func (t *mockTask) CreationTime() *time.Time                              { return &time.Time{} }
+func (t *mockTask) DeadlineDuration() time.Duration                       { return 4 }
+func (t *mockTask) SetDeadlineDuration(time.Duration)                     { return }
+func (t *mockTask) SetTaskID(id string)                                   { return }
+func (t *mockTask) SetStopOnFailure(int)                                  { return }
+func (t *mockTask) GetStopOnFailure() int                                 { return 0 }
+func (t *mockTask) MaxMetricsBuffer() int64                               { return 0 }
+func (t *mockTask) SetMaxMetricsBuffer(int64)                             {}
+func (t *mockTask) Dependencies() []string                                { return nil }
+func (t *mockTask) SetDependencies([]string)                              {}
+func (t *mockTask) DependencyStates() map[string]core.TaskDependencyState { return nil }
+func (t *mockTask) RunDeadline() time.Duration                            { return 0 }
+func (t *mockTask) SetRunDeadline(time.Duration)                          {}
+func (t *mockTask) OverrunPolicy() string                                 { return "" }
+func (t *mockTask) SetOverrunPolicy(string)                               {}
+func (t *mockTask) Priority() string                                      { return "" }
+func (t *mockTask) SetPriority(string)                                    {}
+func (t *mockTask) Labels() map[string]string                             { return nil }
+func (t *mockTask) SetLabels(map[string]string)                           {}
+func (t *mockTask) OverrunCount() uint                                    { return 0 }
+func (t *mockTask) RetryStats() []core.TaskStepRetryStats                 { return nil }
+func (t *mockTask) CircuitBreakers() []core.TaskStepCircuitBreaker        { return nil }
+func (t *mockTask) BatchStats() []core.TaskStepBatchStats                 { return nil }
+func (t *mockTask) Buffer() *core.TaskBuffer                              { return nil }
+func (t *mockTask) SetBuffer(*core.TaskBuffer)                            {}
+func (t *mockTask) MaxCollectDuration() time.Duration                     { return time.Second }
+func (t *mockTask) SetMaxCollectDuration(time.Duration)                   {}
 func (t *mockTask) Option(...core.TaskOption) core.TaskOption {
 	return core.TaskDeadlineDuration(0)
 }
@@ -132,9 +150,11 @@ func (m *MockTaskManager) CreateTask(
 func (m *MockTaskManager) GetTasks() map[string]core.Task {
 	return taskCatalog
 }
-func (m *MockTaskManager) StartTask(id string) []serror.SnapError { return nil }
-func (m *MockTaskManager) StopTask(id string) []serror.SnapError  { return nil }
-func (m *MockTaskManager) RemoveTask(id string) error             { return nil }
+func (m *MockTaskManager) StartTask(id string) []serror.SnapError  { return nil }
+func (m *MockTaskManager) StopTask(id string) []serror.SnapError   { return nil }
+func (m *MockTaskManager) PauseTask(id string) []serror.SnapError  { return nil }
+func (m *MockTaskManager) ResumeTask(id string) []serror.SnapError { return nil }
+func (m *MockTaskManager) RemoveTask(id string) error              { return nil }
 func (m *MockTaskManager) WatchTask(id string, handler core.TaskWatcherHandler) (core.TaskWatcherCloser, error) {
 	return nil, nil
 }
@@ -149,6 +169,35 @@ func (m *MockTaskManager) EnableTask(id string) (core.Task, error) {
 		MyState:             "failed",
 		MyHref:              "http://localhost:8181/v2/tasks/alskdjf"}, nil
 }
+func (m *MockTaskManager) RunTask(wfMap *wmap.WorkflowMap, timeout time.Duration, publish bool, opts ...core.TaskOption) (*core.TaskRun, []serror.SnapError) {
+	return &core.TaskRun{}, nil
+}
+func (m *MockTaskManager) NewTaskRunner(wfMap *wmap.WorkflowMap, timeout time.Duration, publish bool, opts ...core.TaskOption) (core.TaskRunner, []serror.SnapError) {
+	atomic.AddInt32(&TaskRunnersCreated, 1)
+	return &mockTaskRunner{}, nil
+}
+func (m *MockTaskManager) WorkerPoolStats() []core.WorkerPoolStats { return nil }
+func (m *MockTaskManager) BulkTaskOperation(string, core.TaskSelector) ([]core.TaskBulkResult, serror.SnapError) {
+	return nil, nil
+}
+func (m *MockTaskManager) DeadLetters() ([]core.DeadLetter, serror.SnapError) { return nil, nil }
+func (m *MockTaskManager) GetDeadLetter(string) (core.DeadLetter, serror.SnapError) {
+	return core.DeadLetter{}, nil
+}
+func (m *MockTaskManager) ReplayDeadLetters([]string) ([]core.DeadLetterResult, serror.SnapError) {
+	return nil, nil
+}
+func (m *MockTaskManager) PurgeDeadLetters([]string) ([]core.DeadLetterResult, serror.SnapError) {
+	return nil, nil
+}
+
+// TaskRunnersCreated counts the task runners created by the mock task managers
+var TaskRunnersCreated int32
+
+type mockTaskRunner struct{}
+
+func (r *mockTaskRunner) Run() (*core.TaskRun, []serror.SnapError) { return &core.TaskRun{}, nil }
+func (r *mockTaskRunner) Close()                                   {}
 
 // Mock task used in the 'Add tasks' test in rest_v1_test.go
 const TASK = `{
@@ -311,6 +360,30 @@ const (
   }
 }`
 
+	PAUSE_TASK_RESPONSE_ID_PAUSE = `{
+  "meta": {
+    "code": 200,
+    "message": "Scheduled task (MockTask1234) paused",
+    "type": "scheduled_task_paused",
+    "version": 1
+  },
+  "body": {
+    "id": "MockTask1234"
+  }
+}`
+
+	RESUME_TASK_RESPONSE_ID_RESUME = `{
+  "meta": {
+    "code": 200,
+    "message": "Scheduled task (MockTask1234) resumed",
+    "type": "scheduled_task_resumed",
+    "version": 1
+  },
+  "body": {
+    "id": "MockTask1234"
+  }
+}`
+
 	ENABLE_TASK_RESPONSE_ID_ENABLE = `{
   "meta": {
     "code": 200,
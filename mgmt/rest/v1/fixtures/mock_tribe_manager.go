@@ -22,6 +22,7 @@ package fixtures
 
 import (
 	"net"
+	"time"
 
 	"github.com/hashicorp/memberlist"
 	"github.com/intelsdi-x/snap/core"
@@ -77,7 +78,10 @@ func (m *MockTribeManager) GetAgreements() map[string]*agreement.Agreement {
 		"Agree1": mockTribeAgreement,
 		"Agree2": mockTribeAgreement}
 }
-func (m *MockTribeManager) AddAgreement(name string) serror.SnapError {
+func (m *MockTribeManager) AddAgreement(name string, taskLabels map[string]string) serror.SnapError {
+	return nil
+}
+func (m *MockTribeManager) SetTaskAssignment(agreementName, strategy string, replicas int) serror.SnapError {
 	return nil
 }
 func (m *MockTribeManager) RemoveAgreement(name string) serror.SnapError {
@@ -95,6 +99,38 @@ func (m *MockTribeManager) GetMembers() []string {
 func (m *MockTribeManager) GetMember(name string) *agreement.Member {
 	return mockTribeMember
 }
+func (m *MockTribeManager) ExportAgreement(name string) (*agreement.Snapshot, serror.SnapError) {
+	return &agreement.Snapshot{
+		Name:    name,
+		Members: []string{"mockName"},
+		Plugins: []agreement.Plugin{{Name_: "mockVersion", Version_: 1, Type_: core.CollectorPluginType}},
+		Tasks:   []agreement.SnapshotTask{{ID: "mockTask"}},
+	}, nil
+}
+func (m *MockTribeManager) RestoreAgreement(s *agreement.Snapshot) (*agreement.RestoreReport, serror.SnapError) {
+	return &agreement.RestoreReport{}, nil
+}
+func (m *MockTribeManager) SetTaskOverrides(agreementName, taskID string, overrides map[string]agreement.TaskOverride) serror.SnapError {
+	return nil
+}
+func (m *MockTribeManager) MetricCatalog(agreementName string) (*agreement.Catalog, serror.SnapError) {
+	return &agreement.Catalog{Metrics: []agreement.CatalogMetric{}}, nil
+}
+func (m *MockTribeManager) Sync(taskID string, timeout time.Duration) serror.SnapError {
+	return nil
+}
+func (m *MockTribeManager) ListKeys() ([]string, serror.SnapError) {
+	return []string{"5c1f3a9e2b7d4c60"}, nil
+}
+func (m *MockTribeManager) InstallKey(key string) serror.SnapError {
+	return nil
+}
+func (m *MockTribeManager) UseKey(key string) serror.SnapError {
+	return nil
+}
+func (m *MockTribeManager) RemoveKey(key string) serror.SnapError {
+	return nil
+}
 
 // These constants are the expected tribe responses from running
 // rest_v1_test.go on the tribe routes found in mgmt/rest/server.go
@@ -214,6 +250,20 @@ const (
   }
 }`
 
+	GET_TRIBE_KEYS_RESPONSE = `{
+  "meta": {
+    "code": 200,
+    "message": "Tribe keys retrieved",
+    "type": "tribe_key_list_returned",
+    "version": 1
+  },
+  "body": {
+    "keys": [
+      "5c1f3a9e2b7d4c60"
+    ]
+  }
+}`
+
 	GET_TRIBE_MEMBER_NAME = `{
   "meta": {
     "code": 200,
@@ -20,19 +20,33 @@ limitations under the License.
 package v1
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
+	log "github.com/Sirupsen/logrus"
 	"github.com/intelsdi-x/snap/core"
 	"github.com/intelsdi-x/snap/mgmt/rest/v1/rbody"
 	"github.com/intelsdi-x/snap/pkg/stringutils"
+	"github.com/intelsdi-x/snap/scheduler/wmap"
 	"github.com/julienschmidt/httprouter"
 )
 
+var (
+	// The interval the metrics of a stream are collected at, unless given
+	DefaultMetricStreamInterval = time.Second
+	// The shortest interval the metrics of a stream can be collected at
+	MinMetricStreamInterval = time.Second
+
+	ErrNoStreamNamespace     = errors.New("No metric namespace (ns) was specified in the request")
+	ErrInvalidStreamInterval = fmt.Errorf("Stream interval must be a duration of at least %s", MinMetricStreamInterval)
+)
+
 func (s *apiV1) getMetrics(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 	ver := 0 // 0: get all metrics
 
@@ -86,6 +100,12 @@ func (s *apiV1) getMetricsFromTree(w http.ResponseWriter, r *http.Request, param
 		s.getMetrics(w, r, params)
 		return
 	}
+	// GET /v1/metrics/stream can't be routed on its own, it would conflict
+	// with the lookup URL
+	if namespace == "/stream" {
+		s.streamMetrics(w, r, params)
+		return
+	}
 
 	ns := parseNamespace(namespace)
 
@@ -152,6 +172,7 @@ func (s *apiV1) getMetricsFromTree(w http.ResponseWriter, r *http.Request, param
 		DynamicElements: dynamicElements,
 		Description:     mt.Description(),
 		Unit:            mt.Unit(),
+		DataType:        mt.DataType(),
 		LastAdvertisedTimestamp: mt.LastAdvertisedTime().Unix(),
 		Href: catalogedMetricURI(r.Host, version, mt),
 	}
@@ -161,6 +182,109 @@ func (s *apiV1) getMetricsFromTree(w http.ResponseWriter, r *http.Request, param
 	rbody.Write(200, b, w)
 }
 
+// streamMetrics collects the metrics matching the namespace given by the 'ns'
+// parameter on an interval and streams them to the client as Server Sent
+// Events, without creating a task.
+func (s *apiV1) streamMetrics(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	s.wg.Add(1)
+	defer s.wg.Done()
+	logger := log.WithFields(log.Fields{
+		"_module": "api",
+		"_block":  "stream-metrics",
+		"client":  r.RemoteAddr,
+	})
+
+	q := r.URL.Query()
+	ns := q.Get("ns")
+	if ns == "" {
+		rbody.Write(400, rbody.FromError(ErrNoStreamNamespace), w)
+		return
+	}
+	ver := 0 // 0: the latest version
+	if v := q.Get("ver"); v != "" {
+		var err error
+		ver, err = strconv.Atoi(v)
+		if err != nil {
+			rbody.Write(400, rbody.FromError(err), w)
+			return
+		}
+	}
+	interval := DefaultMetricStreamInterval
+	if i := q.Get("interval"); i != "" {
+		d, err := time.ParseDuration(i)
+		if err != nil || d < MinMetricStreamInterval {
+			rbody.Write(400, rbody.FromError(ErrInvalidStreamInterval), w)
+			return
+		}
+		interval = d
+	}
+	wf := wmap.NewWorkflowMap()
+	wf.Collect.AddMetric(ns, ver)
+	// The plugins are subscribed once for the whole stream and each
+	// collection is bounded by the interval so that a slow collector doesn't
+	// pile up runs
+	runner, errs := s.taskManager.NewTaskRunner(wf, interval, false)
+	if len(errs) > 0 {
+		rbody.Write(400, rbody.FromSnapErrors(errs), w)
+		return
+	}
+	defer runner.Close()
+
+	// Make this Server Sent Events compatible
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		// This only works on ResponseWriters that support streaming
+		rbody.Write(500, rbody.FromError(ErrStreamingUnsupported), w)
+		return
+	}
+	so := rbody.StreamedTaskEvent{
+		EventType: rbody.TaskWatchStreamOpen,
+		Message:   "Stream opened",
+	}
+	fmt.Fprintf(w, "data: %s\n\n", so.ToJSON())
+	flusher.Flush()
+
+	logger.WithFields(log.Fields{
+		"namespace": ns,
+		"interval":  interval,
+	}).Debug("streaming metrics")
+	n := w.(http.CloseNotifier).CloseNotify()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		e := rbody.StreamedTaskEvent{EventType: rbody.TaskWatchMetricEvent}
+		run, errs := runner.Run()
+		if run != nil {
+			e.Event = streamedMetrics(run.Collected)
+		}
+		if len(errs) > 0 {
+			e.EventType = rbody.MetricStreamCollectError
+			e.Message = errs[0].Error()
+		}
+		fmt.Fprintf(w, "data: %s\n\n", e.ToJSON())
+		flusher.Flush()
+
+		select {
+		case <-ticker.C:
+		case <-n:
+			logger.WithFields(log.Fields{
+				"namespace": ns,
+			}).Debug("client disconnecting")
+			return
+		case <-s.killChan:
+			logger.WithFields(log.Fields{
+				"namespace": ns,
+			}).Debug("snapteld exiting; disconnecting client")
+			return
+		}
+	}
+}
+
 func respondWithMetrics(host string, mts []core.CatalogedMetric, w http.ResponseWriter) {
 	b := rbody.NewMetricsReturned()
 	for _, m := range mts {
@@ -178,6 +302,7 @@ func respondWithMetrics(host string, mts []core.CatalogedMetric, w http.Response
 			Dynamic:                 dyn,
 			DynamicElements:         dynamicElements,
 			Unit:                    m.Unit(),
+			DataType:                m.DataType(),
 			Policy:                  policies,
 			Href:                    catalogedMetricURI(host, version, m),
 		})
@@ -20,8 +20,10 @@ limitations under the License.
 package v1
 
 import (
+	"bytes"
 	"compress/gzip"
 	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -34,11 +36,13 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/intelsdi-x/snap/core"
 	"github.com/intelsdi-x/snap/core/serror"
 	"github.com/intelsdi-x/snap/mgmt/rest/api"
 	"github.com/intelsdi-x/snap/mgmt/rest/v1/rbody"
+	"github.com/intelsdi-x/snap/pkg/psigning"
 	"github.com/julienschmidt/httprouter"
 )
 
@@ -119,14 +123,14 @@ func (s *apiV1) loadPlugin(w http.ResponseWriter, r *http.Request, _ httprouter.
 			// First file passed in should be the plugin. If the first file is a signature
 			// file, an error is returned. The signature file should be the second
 			// file passed to the API server. If the second file does not have the ".asc"
-			// extension, an error is returned.
+			// or ".sig" extension, an error is returned.
 			// If we loop around more than twice before receiving io.EOF, then
 			// an error is returned.
 			// Reception of TLS security file paths (ceritificate file, private key file, CA certificate files)
 			// is also taking place here. Paths are extracted and used to set up a RequestedPlugin object.
 			switch {
 			case i == 0:
-				if filepath.Ext(p.FileName()) == ".asc" {
+				if psigning.IsSignatureFile(p.FileName()) {
 					e := errors.New("Error: first file passed to load plugin api can not be signature file")
 					rbody.Write(500, rbody.FromError(e), w)
 					return
@@ -137,7 +141,7 @@ func (s *apiV1) loadPlugin(w http.ResponseWriter, r *http.Request, _ httprouter.
 				}
 				checkSum = sha256.Sum256(b)
 			case i < 5:
-				if filepath.Ext(p.FileName()) == ".asc" {
+				if psigning.IsSignatureFile(p.FileName()) {
 					signature = b
 				} else if strings.HasPrefix(p.FileName(), TLSCertPrefix) {
 					certPath = string(b)
@@ -219,7 +223,13 @@ func (s *apiV1) loadPlugin(w http.ResponseWriter, r *http.Request, _ httprouter.
 		if err != nil {
 			rbody.Write(500, rbody.FromError(err), w)
 		}
-		rp, err := core.NewRequestedPlugin(resp["uri"], "", nil)
+		// a plugin binary to download (url) or a standalone plugin (uri)
+		var rp *core.RequestedPlugin
+		if resp["url"] != "" {
+			rp, err = core.NewDownloadRequestedPlugin(resp["url"], resp["checksum"])
+		} else {
+			rp, err = core.NewRequestedPlugin(resp["uri"], "", nil)
+		}
 		if err != nil {
 			rbody.Write(500, rbody.FromError(err), w)
 			return
@@ -310,14 +320,16 @@ func getPlugins(mm api.Metrics, detail bool, h string, plName string, plType str
 		plugins.AvailablePlugins = make([]rbody.AvailablePlugin, len(aPlugins))
 		for i, p := range aPlugins {
 			plugins.AvailablePlugins[i] = rbody.AvailablePlugin{
-				Name:             p.Name(),
-				Version:          p.Version(),
-				Type:             p.TypeName(),
-				HitCount:         p.HitCount(),
-				LastHitTimestamp: p.LastHit().Unix(),
-				ID:               p.ID(),
-				Href:             pluginURI(h, version, p),
-				PprofPort:        p.Port(),
+				Name:               p.Name(),
+				Version:            p.Version(),
+				Type:               p.TypeName(),
+				HitCount:           p.HitCount(),
+				LastHitTimestamp:   p.LastHit().Unix(),
+				ID:                 p.ID(),
+				Href:               pluginURI(h, version, p),
+				PprofPort:          p.Port(),
+				Health:             pluginHealth(p),
+				FailedHealthChecks: p.FailedHealthChecks(),
 			}
 		}
 	}
@@ -446,6 +458,16 @@ func (s *apiV1) getPlugin(w http.ResponseWriter, r *http.Request, p httprouter.P
 			rbody.Write(500, rbody.FromSnapError(se), w)
 			return
 		}
+		sum := sha256.Sum256(b)
+		w.Header().Set(rbody.PluginCheckSumHeader, hex.EncodeToString(sum[:]))
+
+		// the ranges of the binary requested by the members of a tribe
+		// transferring it in chunks are served as is
+		if r.Header.Get("Range") != "" {
+			w.Header().Set("Content-Type", "application/octet-stream")
+			http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(b))
+			return
+		}
 
 		w.Header().Set("Content-Encoding", "gzip")
 		gz := gzip.NewWriter(w)
@@ -476,3 +498,10 @@ func (s *apiV1) getPlugin(w http.ResponseWriter, r *http.Request, p httprouter.P
 func pluginURI(host, version string, c core.Plugin) string {
 	return fmt.Sprintf("%s://%s/%s/plugins/%s/%s/%d", protocolPrefix, host, version, c.TypeName(), c.Name(), c.Version())
 }
+
+func pluginHealth(p core.AvailablePlugin) string {
+	if p.Healthy() {
+		return "healthy"
+	}
+	return "unhealthy"
+}
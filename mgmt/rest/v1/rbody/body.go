@@ -125,6 +125,10 @@ func UnmarshalBody(t string, b []byte) (Body, error) {
 		return unmarshalAndHandleError(b, &ScheduledTaskStarted{})
 	case ScheduledTaskStoppedType:
 		return unmarshalAndHandleError(b, &ScheduledTaskStopped{})
+	case ScheduledTaskPausedType:
+		return unmarshalAndHandleError(b, &ScheduledTaskPaused{})
+	case ScheduledTaskResumedType:
+		return unmarshalAndHandleError(b, &ScheduledTaskResumed{})
 	case ScheduledTaskRemovedType:
 		return unmarshalAndHandleError(b, &ScheduledTaskRemoved{})
 	case ScheduledTaskEnabledType:
@@ -145,6 +149,22 @@ func UnmarshalBody(t string, b []byte) (Body, error) {
 		return unmarshalAndHandleError(b, &TribeDeleteAgreement{})
 	case TribeMemberShowType:
 		return unmarshalAndHandleError(b, &TribeMemberShow{})
+	case TribeKeyListType:
+		return unmarshalAndHandleError(b, &TribeKeyList{})
+	case TribeKeyInstalledType:
+		return unmarshalAndHandleError(b, &TribeKeyInstalled{})
+	case TribeKeyUsedType:
+		return unmarshalAndHandleError(b, &TribeKeyUsed{})
+	case TribeKeyRemovedType:
+		return unmarshalAndHandleError(b, &TribeKeyRemoved{})
+	case TribeAgreementSnapshotType:
+		return unmarshalAndHandleError(b, &TribeAgreementSnapshot{})
+	case TribeAgreementRestoredType:
+		return unmarshalAndHandleError(b, &TribeAgreementRestored{})
+	case TribeTaskOverridesSetType:
+		return unmarshalAndHandleError(b, &TribeTaskOverridesSet{})
+	case TribeMetricCatalogType:
+		return unmarshalAndHandleError(b, &TribeMetricCatalog{})
 	case TribeJoinAgreementType:
 		return unmarshalAndHandleError(b, &TribeJoinAgreement{})
 	case TribeLeaveAgreementType:
@@ -157,6 +177,10 @@ func UnmarshalBody(t string, b []byte) (Body, error) {
 		return unmarshalAndHandleError(b, &SetPluginConfigItem{*cdata.NewNode()})
 	case DeletePluginConfigItemType:
 		return unmarshalAndHandleError(b, &DeletePluginConfigItem{*cdata.NewNode()})
+	case ConfigReloadedType:
+		return unmarshalAndHandleError(b, &ConfigReloaded{})
+	case EffectiveConfigType:
+		return unmarshalAndHandleError(b, &EffectiveConfig{})
 	case ErrorType:
 		return unmarshalAndHandleError(b, &Error{})
 	default:
@@ -25,6 +25,8 @@ const (
 	PluginConfigItemType       = "config_plugin_item_returned"
 	SetPluginConfigItemType    = "config_plugin_item_created"
 	DeletePluginConfigItemType = "config_plugin_item_deleted"
+	ConfigReloadedType         = "config_reloaded"
+	EffectiveConfigType        = "config_effective_returned"
 )
 
 type DeletePluginConfigItem PluginConfigItem
@@ -58,3 +60,31 @@ func (t *PluginConfigItem) ResponseBodyMessage() string {
 func (t *PluginConfigItem) ResponseBodyType() string {
 	return PluginConfigItemType
 }
+
+// ConfigReloaded lists the settings of snapteld changed by a reload
+type ConfigReloaded struct {
+	Changed []string `json:"changed"`
+}
+
+func (t *ConfigReloaded) ResponseBodyMessage() string {
+	return "Configuration reloaded"
+}
+
+func (t *ConfigReloaded) ResponseBodyType() string {
+	return ConfigReloadedType
+}
+
+// EffectiveConfig is the configuration snapteld runs with, its settings in the
+// format of the config file and the layers they come from by their names
+type EffectiveConfig struct {
+	Settings map[string]interface{} `json:"settings"`
+	Sources  map[string]string      `json:"sources"`
+}
+
+func (t *EffectiveConfig) ResponseBodyMessage() string {
+	return "Effective configuration returned"
+}
+
+func (t *EffectiveConfig) ResponseBodyType() string {
+	return EffectiveConfigType
+}
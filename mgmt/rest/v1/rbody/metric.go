@@ -28,6 +28,9 @@ import (
 const (
 	MetricsReturnedType = "metrics_returned"
 	MetricReturnedType  = "metric_returned"
+
+	// Event type for the failed collections of metric streaming
+	MetricStreamCollectError = "collect-error"
 )
 
 type PolicyTable cpolicy.RuleTable
@@ -42,6 +45,7 @@ type Metric struct {
 	DynamicElements         []DynamicElement `json:"dynamic_elements,omitempty"`
 	Description             string           `json:"description,omitempty"`
 	Unit                    string           `json:"unit,omitempty"`
+	DataType                string           `json:"data_type,omitempty"`
 	Policy                  PolicyTableSlice `json:"policy,omitempty"`
 	Href                    string           `json:"href"`
 }
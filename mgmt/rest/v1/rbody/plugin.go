@@ -31,6 +31,10 @@ const (
 	PluginReturnedType = "plugin_returned"
 )
 
+// PluginCheckSumHeader is the header of a downloaded plugin binary holding
+// its hex encoded SHA-256 checksum
+const PluginCheckSumHeader = "Snap-Plugin-Checksum"
+
 // Successful response to the loading of a plugins
 type PluginsLoaded struct {
 	LoadedPlugins []LoadedPlugin `json:"loaded_plugins"`
@@ -100,12 +104,14 @@ type LoadedPlugin struct {
 }
 
 type AvailablePlugin struct {
-	Name             string `json:"name"`
-	Version          int    `json:"version"`
-	Type             string `json:"type"`
-	HitCount         int    `json:"hitcount"`
-	LastHitTimestamp int64  `json:"last_hit_timestamp"`
-	ID               uint32 `json:"id"`
-	Href             string `json:"href"`
-	PprofPort        string `json:"pprof_port"`
+	Name               string `json:"name"`
+	Version            int    `json:"version"`
+	Type               string `json:"type"`
+	HitCount           int    `json:"hitcount"`
+	LastHitTimestamp   int64  `json:"last_hit_timestamp"`
+	ID                 uint32 `json:"id"`
+	Href               string `json:"href"`
+	PprofPort          string `json:"pprof_port"`
+	Health             string `json:"health"`
+	FailedHealthChecks int    `json:"failed_health_checks"`
 }
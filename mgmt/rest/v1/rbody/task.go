@@ -36,18 +36,23 @@ const (
 	ScheduledTaskType              = "scheduled_task"
 	ScheduledTaskStartedType       = "scheduled_task_started"
 	ScheduledTaskStoppedType       = "scheduled_task_stopped"
+	ScheduledTaskPausedType        = "scheduled_task_paused"
+	ScheduledTaskResumedType       = "scheduled_task_resumed"
 	ScheduledTaskEndedType         = "scheduled_task_ended"
 	ScheduledTaskRemovedType       = "scheduled_task_removed"
 	ScheduledTaskWatchingEndedType = "schedule_task_watch_ended"
 	ScheduledTaskEnabledType       = "scheduled_task_enabled"
 
 	// Event types for task watcher streaming
-	TaskWatchStreamOpen   = "stream-open"
-	TaskWatchMetricEvent  = "metric-event"
-	TaskWatchTaskDisabled = "task-disabled"
-	TaskWatchTaskStarted  = "task-started"
-	TaskWatchTaskStopped  = "task-stopped"
-	TaskWatchTaskEnded    = "task-ended"
+	TaskWatchStreamOpen     = "stream-open"
+	TaskWatchMetricEvent    = "metric-event"
+	TaskWatchTaskDisabled   = "task-disabled"
+	TaskWatchTaskStarted    = "task-started"
+	TaskWatchTaskStopped    = "task-stopped"
+	TaskWatchTaskEnded      = "task-ended"
+	TaskWatchTaskOverrun    = "task-overrun"
+	TaskWatchTaskRun        = "task-run"
+	TaskWatchCircuitBreaker = "circuit-breaker"
 )
 
 type ScheduledTaskListReturned struct {
@@ -118,19 +123,97 @@ func AddSchedulerTaskFromTask(t core.Task) *AddScheduledTask {
 }
 
 type ScheduledTask struct {
-	ID                 string            `json:"id"`
-	Name               string            `json:"name"`
-	Deadline           string            `json:"deadline"`
-	Workflow           *wmap.WorkflowMap `json:"workflow,omitempty"`
-	Schedule           *core.Schedule    `json:"schedule,omitempty"`
-	CreationTimestamp  int64             `json:"creation_timestamp,omitempty"`
-	LastRunTimestamp   int64             `json:"last_run_timestamp,omitempty"`
-	HitCount           int               `json:"hit_count,omitempty"`
-	MissCount          int               `json:"miss_count,omitempty"`
-	FailedCount        int               `json:"failed_count,omitempty"`
-	LastFailureMessage string            `json:"last_failure_message,omitempty"`
-	State              string            `json:"task_state"`
-	Href               string            `json:"href"`
+	ID                 string               `json:"id"`
+	Name               string               `json:"name"`
+	Deadline           string               `json:"deadline"`
+	Workflow           *wmap.WorkflowMap    `json:"workflow,omitempty"`
+	Schedule           *core.Schedule       `json:"schedule,omitempty"`
+	CreationTimestamp  int64                `json:"creation_timestamp,omitempty"`
+	LastRunTimestamp   int64                `json:"last_run_timestamp,omitempty"`
+	HitCount           int                  `json:"hit_count,omitempty"`
+	MissCount          int                  `json:"miss_count,omitempty"`
+	FailedCount        int                  `json:"failed_count,omitempty"`
+	LastFailureMessage string               `json:"last_failure_message,omitempty"`
+	State              string               `json:"task_state"`
+	Href               string               `json:"href"`
+	MaxFailures        int                  `json:"max-failures,omitempty"`
+	DependsOn          []string             `json:"depends-on,omitempty"`
+	DependencyStates   map[string]string    `json:"dependency_states,omitempty"`
+	RunDeadline        string               `json:"run-deadline,omitempty"`
+	OverrunPolicy      string               `json:"overrun-policy,omitempty"`
+	Priority           string               `json:"priority,omitempty"`
+	Labels             map[string]string    `json:"labels,omitempty"`
+	OverrunCount       int                  `json:"overrun_count,omitempty"`
+	RetryStats         []TaskStepRetryStats `json:"retry_stats,omitempty"`
+	// NextWindowStartTimestamp is the time the next recurring window of a windowed schedule opens at
+	NextWindowStartTimestamp int64 `json:"next_window_start_timestamp,omitempty"`
+	// CircuitBreakers the state of the circuit breakers of the workflow steps
+	CircuitBreakers []TaskStepCircuitBreaker `json:"circuit_breakers,omitempty"`
+	// Buffer holds the caps and the backlog of the buffer of the task
+	Buffer *TaskBuffer `json:"buffer,omitempty"`
+	// BatchStats the batches of the metrics of the batched publishers
+	BatchStats []TaskStepBatchStats `json:"batch_stats,omitempty"`
+}
+
+// TaskBuffer holds the caps and the backlog of the buffer of a task.
+type TaskBuffer struct {
+	// MaxSize is in megabytes
+	MaxSize int64  `json:"max-size"`
+	MaxAge  string `json:"max-age"`
+	Batches int    `json:"batches"`
+	// Size is the size the buffered batches take in bytes
+	Size int64 `json:"size"`
+}
+
+// NewTaskBuffer returns the caps and the backlog of the buffer of a task
+func NewTaskBuffer(b *core.TaskBuffer) *TaskBuffer {
+	return &TaskBuffer{
+		MaxSize: b.MaxSize / (1024 * 1024),
+		MaxAge:  b.MaxAge.String(),
+		Batches: b.Batches,
+		Size:    b.Size,
+	}
+}
+
+// TaskStepBatchStats holds the batches of the metrics published by a workflow step.
+type TaskStepBatchStats struct {
+	Step      string `json:"step"`
+	Pending   uint   `json:"pending"`
+	Published uint   `json:"published"`
+	Failed    uint   `json:"failed"`
+	Metrics   uint   `json:"metrics"`
+}
+
+// TaskStepRetryStats holds the retries of the failed jobs of a workflow step.
+type TaskStepRetryStats struct {
+	Step      string `json:"step"`
+	Retries   uint   `json:"retries"`
+	Recovered uint   `json:"recovered"`
+	Exhausted uint   `json:"exhausted"`
+}
+
+// TaskStepCircuitBreaker holds the state of the circuit breaker of a workflow step.
+type TaskStepCircuitBreaker struct {
+	Step     string `json:"step"`
+	State    string `json:"state"`
+	Failures uint   `json:"failures"`
+	Trips    uint   `json:"trips"`
+	// OpenedTimestamp is the time the breaker last opened at
+	OpenedTimestamp int64 `json:"opened_timestamp,omitempty"`
+}
+
+// NewTaskStepCircuitBreaker returns the state of the circuit breaker of a workflow step
+func NewTaskStepCircuitBreaker(b core.TaskStepCircuitBreaker) *TaskStepCircuitBreaker {
+	s := &TaskStepCircuitBreaker{
+		Step:     b.Step,
+		State:    b.State,
+		Failures: b.Failures,
+		Trips:    b.Trips,
+	}
+	if !b.OpenedAt.IsZero() {
+		s.OpenedTimestamp = b.OpenedAt.Unix()
+	}
+	return s
 }
 
 func (s *ScheduledTask) CreationTime() time.Time {
@@ -157,10 +240,62 @@ func SchedulerTaskFromTask(t core.Task) *ScheduledTask {
 		FailedCount:        int(t.FailedCount()),
 		LastFailureMessage: t.LastFailureMessage(),
 		State:              t.State().String(),
+		MaxFailures:        t.GetStopOnFailure(),
+		DependsOn:          t.Dependencies(),
+		OverrunPolicy:      t.OverrunPolicy(),
+		Priority:           t.Priority(),
+		Labels:             t.Labels(),
+		OverrunCount:       int(t.OverrunCount()),
+	}
+	if t.RunDeadline() > 0 {
+		st.RunDeadline = t.RunDeadline().String()
+	}
+	if w, ok := t.Schedule().(*schedule.WindowedSchedule); ok {
+		if next := w.NextWindowStart(); next != nil {
+			st.NextWindowStartTimestamp = next.Unix()
+		}
+	}
+	if stats := t.RetryStats(); len(stats) > 0 {
+		st.RetryStats = make([]TaskStepRetryStats, len(stats))
+		for i, s := range stats {
+			st.RetryStats[i] = TaskStepRetryStats{
+				Step:      s.Step,
+				Retries:   s.Retries,
+				Recovered: s.Recovered,
+				Exhausted: s.Exhausted,
+			}
+		}
+	}
+	if breakers := t.CircuitBreakers(); len(breakers) > 0 {
+		st.CircuitBreakers = make([]TaskStepCircuitBreaker, len(breakers))
+		for i, b := range breakers {
+			st.CircuitBreakers[i] = *NewTaskStepCircuitBreaker(b)
+		}
+	}
+	if b := t.Buffer(); b != nil {
+		st.Buffer = NewTaskBuffer(b)
+	}
+	if stats := t.BatchStats(); len(stats) > 0 {
+		st.BatchStats = make([]TaskStepBatchStats, len(stats))
+		for i, s := range stats {
+			st.BatchStats[i] = TaskStepBatchStats{
+				Step:      s.Step,
+				Pending:   s.Pending,
+				Published: s.Published,
+				Failed:    s.Failed,
+				Metrics:   s.Metrics,
+			}
+		}
 	}
 	if st.LastRunTimestamp < 0 {
 		st.LastRunTimestamp = -1
 	}
+	if len(st.DependsOn) > 0 {
+		st.DependencyStates = make(map[string]string, len(st.DependsOn))
+		for id, state := range t.DependencyStates() {
+			st.DependencyStates[id] = state.String()
+		}
+	}
 	return st
 }
 
@@ -190,6 +325,30 @@ func (s *ScheduledTaskStopped) ResponseBodyType() string {
 	return ScheduledTaskStoppedType
 }
 
+type ScheduledTaskPaused struct {
+	ID string `json:"id"`
+}
+
+func (s *ScheduledTaskPaused) ResponseBodyMessage() string {
+	return fmt.Sprintf("Scheduled task (%s) paused", s.ID)
+}
+
+func (s *ScheduledTaskPaused) ResponseBodyType() string {
+	return ScheduledTaskPausedType
+}
+
+type ScheduledTaskResumed struct {
+	ID string `json:"id"`
+}
+
+func (s *ScheduledTaskResumed) ResponseBodyMessage() string {
+	return fmt.Sprintf("Scheduled task (%s) resumed", s.ID)
+}
+
+func (s *ScheduledTaskResumed) ResponseBodyType() string {
+	return ScheduledTaskResumedType
+}
+
 type ScheduledTaskRemoved struct {
 	// TODO return resource
 	ID string `json:"id"`
@@ -223,12 +382,22 @@ func assertSchedule(s schedule.Schedule, t *AddScheduledTask) {
 			Interval:       v.Interval.String(),
 			StartTimestamp: v.StartTime,
 			StopTimestamp:  v.StopTime,
+			Jitter:         v.Jitter,
+		}
+		if r := v.Recurrence; r != nil {
+			t.Schedule.Timezone = r.Timezone
+			t.Schedule.Recurrence = &core.ScheduleRecurrence{
+				Start: r.Start,
+				Stop:  r.Stop,
+				Days:  r.Days,
+			}
 		}
 		return
 	case *schedule.CronSchedule:
 		t.Schedule = &core.Schedule{
 			Type:     "cron",
 			Interval: v.Entry(),
+			Timezone: v.Timezone(),
 		}
 		return
 	}
@@ -250,6 +419,12 @@ type StreamedTaskEvent struct {
 	EventType string          `json:"type"`
 	Message   string          `json:"message"`
 	Event     StreamedMetrics `json:"event,omitempty"`
+	// The number of task runs which exceeded the run deadline, set on overruns
+	OverrunCount uint `json:"overrun_count,omitempty"`
+	// The record of the run, set on the task-run events
+	Run *StreamedTaskRun `json:"run,omitempty"`
+	// The state of the circuit breaker, set on the circuit-breaker events
+	CircuitBreaker *TaskStepCircuitBreaker `json:"circuit_breaker,omitempty"`
 }
 
 func (s *StreamedTaskEvent) ToJSON() string {
@@ -257,6 +432,38 @@ func (s *StreamedTaskEvent) ToJSON() string {
 	return string(j)
 }
 
+// StreamedTaskRun is the record of a scheduled run of a task
+type StreamedTaskRun struct {
+	Timestamp time.Time             `json:"timestamp"`
+	Duration  string                `json:"duration"`
+	Steps     []StreamedTaskRunStep `json:"steps"`
+}
+
+// StreamedTaskRunStep is the result of a step of a task run
+type StreamedTaskRunStep struct {
+	// Step is "collector" or the plugin of the step, "<type>:<name>:<version>"
+	Step    string   `json:"step"`
+	Metrics int      `json:"metrics"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+// NewStreamedTaskRun returns the streamed record of a task run
+func NewStreamedTaskRun(r core.TaskRunRecord) *StreamedTaskRun {
+	steps := make([]StreamedTaskRunStep, len(r.Steps))
+	for i, s := range r.Steps {
+		steps[i] = StreamedTaskRunStep{
+			Step:    s.Step,
+			Metrics: s.Metrics,
+			Errors:  s.Errors,
+		}
+	}
+	return &StreamedTaskRun{
+		Timestamp: r.Start,
+		Duration:  r.Duration.String(),
+		Steps:     steps,
+	}
+}
+
 type StreamedMetric struct {
 	Namespace string            `json:"namespace"`
 	Data      interface{}       `json:"data"`
@@ -22,15 +22,23 @@ package rbody
 import "github.com/intelsdi-x/snap/mgmt/tribe/agreement"
 
 const (
-	TribeListAgreementType   = "tribe_agreement_list_returned"
-	TribeGetAgreementType    = "tribe_agreement_returned"
-	TribeAddAgreementType    = "tribe_agreement_created"
-	TribeDeleteAgreementType = "tribe_agreement_deleted"
-	TribeAddMemberType       = "tribe_member_added"
-	TribeJoinAgreementType   = "tribe_agreement_joined"
-	TribeLeaveAgreementType  = "tribe_agreement_left"
-	TribeMemberListType      = "tribe_member_list_returned"
-	TribeMemberShowType      = "tribe_member_details_returned"
+	TribeListAgreementType     = "tribe_agreement_list_returned"
+	TribeGetAgreementType      = "tribe_agreement_returned"
+	TribeAddAgreementType      = "tribe_agreement_created"
+	TribeDeleteAgreementType   = "tribe_agreement_deleted"
+	TribeAddMemberType         = "tribe_member_added"
+	TribeJoinAgreementType     = "tribe_agreement_joined"
+	TribeLeaveAgreementType    = "tribe_agreement_left"
+	TribeMemberListType        = "tribe_member_list_returned"
+	TribeMemberShowType        = "tribe_member_details_returned"
+	TribeKeyListType           = "tribe_key_list_returned"
+	TribeKeyInstalledType      = "tribe_key_installed"
+	TribeKeyUsedType           = "tribe_key_used"
+	TribeKeyRemovedType        = "tribe_key_removed"
+	TribeAgreementSnapshotType = "tribe_agreement_snapshot_returned"
+	TribeAgreementRestoredType = "tribe_agreement_restored"
+	TribeTaskOverridesSetType  = "tribe_task_overrides_set"
+	TribeMetricCatalogType     = "tribe_metric_catalog_returned"
 )
 
 type TribeAddAgreement struct {
@@ -131,3 +139,106 @@ func (t *TribeMemberShow) ResponseBodyMessage() string {
 func (t *TribeMemberShow) ResponseBodyType() string {
 	return TribeMemberShowType
 }
+
+// TribeKeyList is the fingerprints of the keys the gossip is encrypted with,
+// the first one is the fingerprint of the primary key
+type TribeKeyList struct {
+	Keys []string `json:"keys"`
+}
+
+func (t *TribeKeyList) ResponseBodyMessage() string {
+	return "Tribe keys retrieved"
+}
+
+func (t *TribeKeyList) ResponseBodyType() string {
+	return TribeKeyListType
+}
+
+type TribeKeyInstalled struct {
+	Keys []string `json:"keys"`
+}
+
+func (t *TribeKeyInstalled) ResponseBodyMessage() string {
+	return "Tribe key installed"
+}
+
+func (t *TribeKeyInstalled) ResponseBodyType() string {
+	return TribeKeyInstalledType
+}
+
+type TribeKeyUsed struct {
+	Keys []string `json:"keys"`
+}
+
+func (t *TribeKeyUsed) ResponseBodyMessage() string {
+	return "Tribe key used"
+}
+
+func (t *TribeKeyUsed) ResponseBodyType() string {
+	return TribeKeyUsedType
+}
+
+type TribeKeyRemoved struct {
+	Keys []string `json:"keys"`
+}
+
+func (t *TribeKeyRemoved) ResponseBodyMessage() string {
+	return "Tribe key removed"
+}
+
+func (t *TribeKeyRemoved) ResponseBodyType() string {
+	return TribeKeyRemovedType
+}
+
+// TribeAgreementSnapshot is the snapshot of an agreement along with the
+// definitions of its tasks
+type TribeAgreementSnapshot struct {
+	Snapshot *agreement.Snapshot `json:"snapshot"`
+}
+
+func (t *TribeAgreementSnapshot) ResponseBodyMessage() string {
+	return "Tribe agreement snapshot returned"
+}
+
+func (t *TribeAgreementSnapshot) ResponseBodyType() string {
+	return TribeAgreementSnapshotType
+}
+
+// TribeAgreementRestored is the agreement restored from a snapshot and what
+// restoring it left out
+type TribeAgreementRestored struct {
+	Agreement *agreement.Agreement `json:"agreement"`
+	agreement.RestoreReport
+}
+
+func (t *TribeAgreementRestored) ResponseBodyMessage() string {
+	return "Tribe agreement restored"
+}
+
+func (t *TribeAgreementRestored) ResponseBodyType() string {
+	return TribeAgreementRestoredType
+}
+
+type TribeTaskOverridesSet struct {
+	Agreement *agreement.Agreement `json:"agreement"`
+}
+
+func (t *TribeTaskOverridesSet) ResponseBodyMessage() string {
+	return "Tribe task overrides set"
+}
+
+func (t *TribeTaskOverridesSet) ResponseBodyType() string {
+	return TribeTaskOverridesSetType
+}
+
+type TribeMetricCatalog struct {
+	agreement.Catalog
+}
+
+func (t *TribeMetricCatalog) ResponseBodyMessage() string {
+	return "Tribe metric catalog returned"
+}
+
+func (t *TribeMetricCatalog) ResponseBodyType() string {
+	return TribeMetricCatalogType
+}
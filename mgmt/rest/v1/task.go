@@ -40,32 +40,50 @@ var (
 	ErrStreamingUnsupported    = errors.New("Streaming unsupported")
 	ErrTaskNotFound            = errors.New("Task not found")
 	ErrTaskDisabledNotRunnable = errors.New("Task is disabled. Cannot be started")
+	ErrTaskPausedNotRunnable   = errors.New("Task is paused")
+	ErrTaskNotPausable         = errors.New("can't be paused")
+	ErrTaskNotPaused           = errors.New("Task is not paused")
 	ErrNoActionSpecified       = errors.New("No action was specified in the request")
 	ErrWrongAction             = errors.New("Wrong action requested")
 )
 
 func (s *apiV1) addTask(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	wait, err := tribeWait(r)
+	if err != nil {
+		rbody.Write(400, rbody.FromError(err), w)
+		return
+	}
 	task, err := core.CreateTaskFromContent(r.Body, nil, s.taskManager.CreateTask)
 	if err != nil {
 		rbody.Write(500, rbody.FromError(err), w)
 		return
 	}
+	if !s.syncTribe(w, task.ID(), wait) {
+		return
+	}
 	taskB := rbody.AddSchedulerTaskFromTask(task)
 	taskB.Href = taskURI(r.Host, version, task)
 	rbody.Write(201, taskB, w)
 }
 
 func (s *apiV1) getTasks(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	labels, err := core.ParseLabelSelector(r.URL.Query().Get("labels"))
+	if err != nil {
+		rbody.Write(400, rbody.FromError(err), w)
+		return
+	}
 	sts := s.taskManager.GetTasks()
 
 	tasks := &rbody.ScheduledTaskListReturned{}
-	tasks.ScheduledTasks = make([]rbody.ScheduledTask, len(sts))
+	tasks.ScheduledTasks = make([]rbody.ScheduledTask, 0, len(sts))
 
-	i := 0
 	for _, t := range sts {
-		tasks.ScheduledTasks[i] = *rbody.SchedulerTaskFromTask(t)
-		tasks.ScheduledTasks[i].Href = taskURI(r.Host, version, t)
-		i++
+		if !core.MatchLabels(labels, t.Labels()) {
+			continue
+		}
+		st := rbody.SchedulerTaskFromTask(t)
+		st.Href = taskURI(r.Host, version, t)
+		tasks.ScheduledTasks = append(tasks.ScheduledTasks, *st)
 	}
 	sort.Sort(tasks)
 	rbody.Write(200, tasks, w)
@@ -145,7 +163,7 @@ func (s *apiV1) watchTask(w http.ResponseWriter, r *http.Request, p httprouter.P
 				"task-watcher-event": e.EventType,
 			}).Debug("new event")
 			switch e.EventType {
-			case rbody.TaskWatchMetricEvent, rbody.TaskWatchTaskStarted:
+			case rbody.TaskWatchMetricEvent, rbody.TaskWatchTaskStarted, rbody.TaskWatchTaskOverrun, rbody.TaskWatchTaskRun, rbody.TaskWatchCircuitBreaker:
 				// The client can decide to stop receiving on the stream on Task Stopped.
 				// We write the event to the buffer
 				fmt.Fprintf(w, "data: %s\n\n", e.ToJSON())
@@ -191,6 +209,11 @@ func (s *apiV1) watchTask(w http.ResponseWriter, r *http.Request, p httprouter.P
 }
 
 func (s *apiV1) startTask(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	wait, err := tribeWait(r)
+	if err != nil {
+		rbody.Write(400, rbody.FromError(err), w)
+		return
+	}
 	id := p.ByName("id")
 	errs := s.taskManager.StartTask(id)
 	if errs != nil {
@@ -198,18 +221,27 @@ func (s *apiV1) startTask(w http.ResponseWriter, r *http.Request, p httprouter.P
 			rbody.Write(404, rbody.FromSnapErrors(errs), w)
 			return
 		}
-		if strings.Contains(errs[0].Error(), ErrTaskDisabledNotRunnable.Error()) {
+		if strings.Contains(errs[0].Error(), ErrTaskDisabledNotRunnable.Error()) ||
+			strings.Contains(errs[0].Error(), ErrTaskPausedNotRunnable.Error()) {
 			rbody.Write(409, rbody.FromSnapErrors(errs), w)
 			return
 		}
 		rbody.Write(500, rbody.FromSnapErrors(errs), w)
 		return
 	}
+	if !s.syncTribe(w, id, wait) {
+		return
+	}
 	// TODO should return resource
 	rbody.Write(200, &rbody.ScheduledTaskStarted{ID: id}, w)
 }
 
 func (s *apiV1) stopTask(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	wait, err := tribeWait(r)
+	if err != nil {
+		rbody.Write(400, rbody.FromError(err), w)
+		return
+	}
 	id := p.ByName("id")
 	errs := s.taskManager.StopTask(id)
 	if errs != nil {
@@ -220,12 +252,56 @@ func (s *apiV1) stopTask(w http.ResponseWriter, r *http.Request, p httprouter.Pa
 		rbody.Write(500, rbody.FromSnapErrors(errs), w)
 		return
 	}
+	if !s.syncTribe(w, id, wait) {
+		return
+	}
 	rbody.Write(200, &rbody.ScheduledTaskStopped{ID: id}, w)
 }
 
+func (s *apiV1) pauseTask(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	id := p.ByName("id")
+	errs := s.taskManager.PauseTask(id)
+	if errs != nil {
+		if strings.Contains(errs[0].Error(), ErrTaskNotFound.Error()) {
+			rbody.Write(404, rbody.FromSnapErrors(errs), w)
+			return
+		}
+		if strings.Contains(errs[0].Error(), ErrTaskNotPausable.Error()) {
+			rbody.Write(409, rbody.FromSnapErrors(errs), w)
+			return
+		}
+		rbody.Write(500, rbody.FromSnapErrors(errs), w)
+		return
+	}
+	rbody.Write(200, &rbody.ScheduledTaskPaused{ID: id}, w)
+}
+
+func (s *apiV1) resumeTask(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	id := p.ByName("id")
+	errs := s.taskManager.ResumeTask(id)
+	if errs != nil {
+		if strings.Contains(errs[0].Error(), ErrTaskNotFound.Error()) {
+			rbody.Write(404, rbody.FromSnapErrors(errs), w)
+			return
+		}
+		if strings.Contains(errs[0].Error(), ErrTaskNotPaused.Error()) {
+			rbody.Write(409, rbody.FromSnapErrors(errs), w)
+			return
+		}
+		rbody.Write(500, rbody.FromSnapErrors(errs), w)
+		return
+	}
+	rbody.Write(200, &rbody.ScheduledTaskResumed{ID: id}, w)
+}
+
 func (s *apiV1) removeTask(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	wait, err := tribeWait(r)
+	if err != nil {
+		rbody.Write(400, rbody.FromError(err), w)
+		return
+	}
 	id := p.ByName("id")
-	err := s.taskManager.RemoveTask(id)
+	err = s.taskManager.RemoveTask(id)
 	if err != nil {
 		if strings.Contains(err.Error(), ErrTaskNotFound.Error()) {
 			rbody.Write(404, rbody.FromError(err), w)
@@ -234,6 +310,9 @@ func (s *apiV1) removeTask(w http.ResponseWriter, r *http.Request, p httprouter.
 		rbody.Write(500, rbody.FromError(err), w)
 		return
 	}
+	if !s.syncTribe(w, id, wait) {
+		return
+	}
 	rbody.Write(200, &rbody.ScheduledTaskRemoved{ID: id}, w)
 }
 
@@ -261,6 +340,14 @@ type TaskWatchHandler struct {
 }
 
 func (t *TaskWatchHandler) CatchCollection(m []core.Metric) {
+	t.mChan <- rbody.StreamedTaskEvent{
+		EventType: rbody.TaskWatchMetricEvent,
+		Message:   "",
+		Event:     streamedMetrics(m),
+	}
+}
+
+func streamedMetrics(m []core.Metric) rbody.StreamedMetrics {
 	sm := make([]rbody.StreamedMetric, len(m))
 	for i := range m {
 		sm[i] = rbody.StreamedMetric{
@@ -270,11 +357,7 @@ func (t *TaskWatchHandler) CatchCollection(m []core.Metric) {
 			Tags:      m[i].Tags(),
 		}
 	}
-	t.mChan <- rbody.StreamedTaskEvent{
-		EventType: rbody.TaskWatchMetricEvent,
-		Message:   "",
-		Event:     sm,
-	}
+	return sm
 }
 
 func (t *TaskWatchHandler) CatchTaskStarted() {
@@ -302,6 +385,29 @@ func (t *TaskWatchHandler) CatchTaskDisabled(why string) {
 	}
 }
 
+func (t *TaskWatchHandler) CatchTaskOverrun(count uint) {
+	t.mChan <- rbody.StreamedTaskEvent{
+		EventType:    rbody.TaskWatchTaskOverrun,
+		Message:      "Task run exceeded its deadline",
+		OverrunCount: count,
+	}
+}
+
+func (t *TaskWatchHandler) CatchTaskRun(r core.TaskRunRecord) {
+	t.mChan <- rbody.StreamedTaskEvent{
+		EventType: rbody.TaskWatchTaskRun,
+		Run:       rbody.NewStreamedTaskRun(r),
+	}
+}
+
+func (t *TaskWatchHandler) CatchCircuitBreaker(b core.TaskStepCircuitBreaker) {
+	t.mChan <- rbody.StreamedTaskEvent{
+		EventType:      rbody.TaskWatchCircuitBreaker,
+		Message:        fmt.Sprintf("Circuit breaker of %s is %s", b.Step, b.State),
+		CircuitBreaker: rbody.NewTaskStepCircuitBreaker(b),
+	}
+}
+
 func taskURI(host, version string, t core.Task) string {
 	return fmt.Sprintf("%s://%s/%s/tasks/%s", protocolPrefix, host, version, t.ID())
 }
@@ -20,15 +20,22 @@ limitations under the License.
 package v1
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
+	"time"
 
 	log "github.com/Sirupsen/logrus"
 
+	"github.com/intelsdi-x/snap/core"
 	"github.com/intelsdi-x/snap/core/serror"
 	"github.com/intelsdi-x/snap/mgmt/rest/v1/rbody"
+	"github.com/intelsdi-x/snap/mgmt/tribe/agreement"
+	"github.com/intelsdi-x/snap/pkg/schedule"
+	"github.com/intelsdi-x/snap/scheduler/wmap"
 	"github.com/julienschmidt/httprouter"
 )
 
@@ -37,11 +44,27 @@ var (
 		"_module": "rest-tribe",
 	})
 
-	ErrInvalidJSON           = errors.New("Invalid JSON")
-	ErrAgreementDoesNotExist = errors.New("Agreement not found")
-	ErrMemberNotFound        = errors.New("Member not found")
+	ErrInvalidJSON            = errors.New("Invalid JSON")
+	ErrAgreementDoesNotExist  = errors.New("Agreement not found")
+	ErrMemberNotFound         = errors.New("Member not found")
+	ErrAgreementAlreadyExists = errors.New("Agreement already exists")
 )
 
+// agreementRequest is the body of the requests adding an agreement
+type agreementRequest struct {
+	Name       string
+	TaskLabels map[string]string `json:"task_labels"`
+	// TaskStrategy and TaskReplicas set how the tasks of the agreement are
+	// assigned to its members, every member runs every task by default
+	TaskStrategy string `json:"task_strategy"`
+	TaskReplicas int    `json:"task_replicas"`
+}
+
+// agreementMember is the body of the requests joining or leaving an agreement
+type agreementMember struct {
+	MemberName string `json:"member_name"`
+}
+
 func (s *apiV1) getAgreements(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 	res := &rbody.TribeListAgreement{}
 	res.Agreements = s.tribeManager.GetAgreements()
@@ -72,6 +95,12 @@ func (s *apiV1) getAgreement(w http.ResponseWriter, r *http.Request, p httproute
 
 func (s *apiV1) deleteAgreement(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
 	tribeLogger = tribeLogger.WithField("_block", "deleteAgreement")
+	wait, err := tribeWait(r)
+	if err != nil {
+		tribeLogger.Error(err)
+		rbody.Write(400, rbody.FromError(err), w)
+		return
+	}
 	name := p.ByName("name")
 	if _, ok := s.tribeManager.GetAgreements()[name]; !ok {
 		fields := map[string]interface{}{
@@ -90,6 +119,9 @@ func (s *apiV1) deleteAgreement(w http.ResponseWriter, r *http.Request, p httpro
 		return
 	}
 
+	if !s.syncTribe(w, "", wait) {
+		return
+	}
 	a := &rbody.TribeDeleteAgreement{}
 	a.Agreements = s.tribeManager.GetAgreements()
 	rbody.Write(200, a, w)
@@ -97,6 +129,12 @@ func (s *apiV1) deleteAgreement(w http.ResponseWriter, r *http.Request, p httpro
 
 func (s *apiV1) joinAgreement(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
 	tribeLogger = tribeLogger.WithField("_block", "joinAgreement")
+	wait, err := tribeWait(r)
+	if err != nil {
+		tribeLogger.Error(err)
+		rbody.Write(400, rbody.FromError(err), w)
+		return
+	}
 	name := p.ByName("name")
 	if _, ok := s.tribeManager.GetAgreements()[name]; !ok {
 		fields := map[string]interface{}{
@@ -114,9 +152,7 @@ func (s *apiV1) joinAgreement(w http.ResponseWriter, r *http.Request, p httprout
 		return
 	}
 
-	m := struct {
-		MemberName string `json:"member_name"`
-	}{}
+	m := agreementMember{}
 	err = json.Unmarshal(b, &m)
 	if err != nil {
 		fields := map[string]interface{}{
@@ -135,6 +171,9 @@ func (s *apiV1) joinAgreement(w http.ResponseWriter, r *http.Request, p httprout
 		rbody.Write(400, rbody.FromSnapError(serr), w)
 		return
 	}
+	if !s.syncTribe(w, "", wait) {
+		return
+	}
 	agreement, _ := s.tribeManager.GetAgreement(name)
 	rbody.Write(200, &rbody.TribeJoinAgreement{Agreement: agreement}, w)
 
@@ -142,6 +181,12 @@ func (s *apiV1) joinAgreement(w http.ResponseWriter, r *http.Request, p httprout
 
 func (s *apiV1) leaveAgreement(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
 	tribeLogger = tribeLogger.WithField("_block", "leaveAgreement")
+	wait, err := tribeWait(r)
+	if err != nil {
+		tribeLogger.Error(err)
+		rbody.Write(400, rbody.FromError(err), w)
+		return
+	}
 	name := p.ByName("name")
 	if _, ok := s.tribeManager.GetAgreements()[name]; !ok {
 		fields := map[string]interface{}{
@@ -159,9 +204,7 @@ func (s *apiV1) leaveAgreement(w http.ResponseWriter, r *http.Request, p httprou
 		return
 	}
 
-	m := struct {
-		MemberName string `json:"member_name"`
-	}{}
+	m := agreementMember{}
 	err = json.Unmarshal(b, &m)
 	if err != nil {
 		fields := map[string]interface{}{
@@ -180,6 +223,9 @@ func (s *apiV1) leaveAgreement(w http.ResponseWriter, r *http.Request, p httprou
 		rbody.Write(400, rbody.FromSnapError(serr), w)
 		return
 	}
+	if !s.syncTribe(w, "", wait) {
+		return
+	}
 	agreement, _ := s.tribeManager.GetAgreement(name)
 	rbody.Write(200, &rbody.TribeLeaveAgreement{Agreement: agreement}, w)
 }
@@ -189,6 +235,17 @@ func (s *apiV1) getMembers(w http.ResponseWriter, r *http.Request, p httprouter.
 	rbody.Write(200, &rbody.TribeMemberList{Members: members}, w)
 }
 
+func (s *apiV1) getTribeMetrics(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	tribeLogger = tribeLogger.WithField("_block", "getTribeMetrics")
+	catalog, err := s.tribeManager.MetricCatalog(r.URL.Query().Get("agreement"))
+	if err != nil {
+		tribeLogger.Error(err)
+		rbody.Write(400, rbody.FromSnapError(err), w)
+		return
+	}
+	rbody.Write(200, &rbody.TribeMetricCatalog{Catalog: *catalog}, w)
+}
+
 func (s *apiV1) getMember(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
 	tribeLogger = tribeLogger.WithField("_block", "getMember")
 	name := p.ByName("name")
@@ -218,6 +275,12 @@ func (s *apiV1) getMember(w http.ResponseWriter, r *http.Request, p httprouter.P
 
 func (s *apiV1) addAgreement(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
 	tribeLogger = tribeLogger.WithField("_block", "addAgreement")
+	wait, err := tribeWait(r)
+	if err != nil {
+		tribeLogger.Error(err)
+		rbody.Write(400, rbody.FromError(err), w)
+		return
+	}
 	b, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		tribeLogger.Error(err)
@@ -225,7 +288,7 @@ func (s *apiV1) addAgreement(w http.ResponseWriter, r *http.Request, p httproute
 		return
 	}
 
-	a := struct{ Name string }{}
+	a := agreementRequest{}
 	err = json.Unmarshal(b, &a)
 	if err != nil {
 		fields := map[string]interface{}{
@@ -248,15 +311,307 @@ func (s *apiV1) addAgreement(w http.ResponseWriter, r *http.Request, p httproute
 		return
 	}
 
-	err = s.tribeManager.AddAgreement(a.Name)
+	if err := core.ValidateLabels(a.TaskLabels); err != nil {
+		tribeLogger.WithField("agreement-name", a.Name).Error(err)
+		rbody.Write(400, rbody.FromError(err), w)
+		return
+	}
+
+	if _, err := agreement.GetStrategy(a.TaskStrategy); err != nil {
+		tribeLogger.WithField("agreement-name", a.Name).Error(err)
+		rbody.Write(400, rbody.FromError(err), w)
+		return
+	}
+
+	err = s.tribeManager.AddAgreement(a.Name, a.TaskLabels)
 	if err != nil {
 		tribeLogger.WithField("agreement-name", a.Name).Error(err)
 		rbody.Write(400, rbody.FromError(err), w)
 		return
 	}
 
+	if a.TaskStrategy != "" {
+		if serr := s.tribeManager.SetTaskAssignment(a.Name, a.TaskStrategy, a.TaskReplicas); serr != nil {
+			tribeLogger.WithField("agreement-name", a.Name).Error(serr)
+			rbody.Write(400, rbody.FromSnapError(serr), w)
+			return
+		}
+	}
+
+	if !s.syncTribe(w, "", wait) {
+		return
+	}
 	res := &rbody.TribeAddAgreement{}
 	res.Agreements = s.tribeManager.GetAgreements()
 
 	rbody.Write(200, res, w)
 }
+
+// tribeKey is the body of the requests rotating the keys of the gossip
+type tribeKey struct {
+	Key string `json:"key"`
+}
+
+func (s *apiV1) getKeys(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	keys, serr := s.tribeManager.ListKeys()
+	if serr != nil {
+		tribeLogger.Error(serr)
+		rbody.Write(400, rbody.FromSnapError(serr), w)
+		return
+	}
+	rbody.Write(200, &rbody.TribeKeyList{Keys: keys}, w)
+}
+
+func (s *apiV1) installKey(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	s.rotateKey(w, r, s.tribeManager.InstallKey, func(keys []string) rbody.Body {
+		return &rbody.TribeKeyInstalled{Keys: keys}
+	})
+}
+
+func (s *apiV1) useKey(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	s.rotateKey(w, r, s.tribeManager.UseKey, func(keys []string) rbody.Body {
+		return &rbody.TribeKeyUsed{Keys: keys}
+	})
+}
+
+func (s *apiV1) removeKey(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	s.rotateKey(w, r, s.tribeManager.RemoveKey, func(keys []string) rbody.Body {
+		return &rbody.TribeKeyRemoved{Keys: keys}
+	})
+}
+
+// rotateKey rotates the key of the body of the request and responds with the
+// fingerprints of the keys
+func (s *apiV1) rotateKey(w http.ResponseWriter, r *http.Request, rotate func(string) serror.SnapError, body func([]string) rbody.Body) {
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		tribeLogger.Error(err)
+		rbody.Write(500, rbody.FromError(err), w)
+		return
+	}
+
+	k := tribeKey{}
+	if err := json.Unmarshal(b, &k); err != nil || k.Key == "" {
+		fields := map[string]interface{}{
+			"hint": `The body of the request should be of the form '{"key": "base64_encoded_key"}'`,
+		}
+		se := serror.New(ErrInvalidJSON, fields)
+		tribeLogger.WithFields(fields).Error(ErrInvalidJSON)
+		rbody.Write(400, rbody.FromSnapError(se), w)
+		return
+	}
+
+	if serr := rotate(k.Key); serr != nil {
+		tribeLogger.Error(serr)
+		rbody.Write(400, rbody.FromSnapError(serr), w)
+		return
+	}
+	keys, _ := s.tribeManager.ListKeys()
+	rbody.Write(200, body(keys), w)
+}
+
+func (s *apiV1) exportAgreement(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	tribeLogger = tribeLogger.WithField("_block", "exportAgreement")
+	snapshot, serr := s.tribeManager.ExportAgreement(p.ByName("name"))
+	if serr != nil {
+		tribeLogger.Error(serr)
+		rbody.Write(400, rbody.FromSnapError(serr), w)
+		return
+	}
+	for i, st := range snapshot.Tasks {
+		if t, err := s.taskManager.GetTask(st.ID); err == nil {
+			snapshot.Tasks[i].Definition = taskDefinition(t)
+		}
+	}
+	rbody.Write(200, &rbody.TribeAgreementSnapshot{Snapshot: snapshot}, w)
+}
+
+// restoreAgreement restores the snapshot of the body of the request as the
+// agreement of the given name, the tasks of the snapshot missing on this
+// member are created from their definitions first
+func (s *apiV1) restoreAgreement(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	tribeLogger = tribeLogger.WithField("_block", "restoreAgreement")
+	wait, err := tribeWait(r)
+	if err != nil {
+		tribeLogger.Error(err)
+		rbody.Write(400, rbody.FromError(err), w)
+		return
+	}
+	name := p.ByName("name")
+	if _, ok := s.tribeManager.GetAgreements()[name]; ok {
+		fields := map[string]interface{}{
+			"agreement_name": name,
+		}
+		tribeLogger.WithFields(fields).Error(ErrAgreementAlreadyExists)
+		rbody.Write(400, rbody.FromSnapError(serror.New(ErrAgreementAlreadyExists, fields)), w)
+		return
+	}
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		tribeLogger.Error(err)
+		rbody.Write(500, rbody.FromError(err), w)
+		return
+	}
+
+	snapshot := agreement.Snapshot{}
+	if err := json.Unmarshal(b, &snapshot); err != nil {
+		fields := map[string]interface{}{
+			"error": err,
+			"hint":  "The body of the request should be a snapshot exported from GET /v1/tribe/agreements/:name/snapshot",
+		}
+		se := serror.New(ErrInvalidJSON, fields)
+		tribeLogger.WithFields(fields).Error(ErrInvalidJSON)
+		rbody.Write(400, rbody.FromSnapError(se), w)
+		return
+	}
+	snapshot.Name = name
+
+	for _, st := range snapshot.Tasks {
+		if err := s.createSnapshotTask(st); err != nil {
+			tribeLogger.WithFields(log.Fields{
+				"agreement-name": name,
+				"task-id":        st.ID,
+			}).Warn(err)
+		}
+	}
+
+	report, serr := s.tribeManager.RestoreAgreement(&snapshot)
+	if serr != nil {
+		tribeLogger.Error(serr)
+		rbody.Write(400, rbody.FromSnapError(serr), w)
+		return
+	}
+	if !s.syncTribe(w, "", wait) {
+		return
+	}
+	a, _ := s.tribeManager.GetAgreement(name)
+	rbody.Write(200, &rbody.TribeAgreementRestored{Agreement: a, RestoreReport: *report}, w)
+}
+
+// createSnapshotTask creates a task of a snapshot from its definition unless
+// it exists on this member
+func (s *apiV1) createSnapshotTask(st agreement.SnapshotTask) error {
+	if _, err := s.taskManager.GetTask(st.ID); err == nil || st.Definition == nil {
+		return nil
+	}
+	b, err := json.Marshal(st.Definition)
+	if err != nil {
+		return err
+	}
+	start := !st.Stopped
+	_, err = core.CreateTaskFromContent(ioutil.NopCloser(bytes.NewReader(b)), &start,
+		func(sch schedule.Schedule, wfMap *wmap.WorkflowMap, startOnCreate bool, opts ...core.TaskOption) (core.Task, core.TaskErrors) {
+			return s.taskManager.CreateTask(sch, wfMap, startOnCreate, append(opts, core.SetTaskID(st.ID))...)
+		})
+	return err
+}
+
+// taskDefinition returns the request creating a task like the given one
+func taskDefinition(t core.Task) *core.TaskCreationRequest {
+	st := rbody.AddSchedulerTaskFromTask(t)
+	def := &core.TaskCreationRequest{
+		Name:             t.GetName(),
+		Version:          1,
+		Deadline:         st.Deadline,
+		Workflow:         t.WMap(),
+		Schedule:         st.Schedule,
+		MaxFailures:      t.GetStopOnFailure(),
+		MaxMetricsBuffer: t.MaxMetricsBuffer(),
+		DependsOn:        t.Dependencies(),
+		OverrunPolicy:    t.OverrunPolicy(),
+		Priority:         t.Priority(),
+		Labels:           t.Labels(),
+	}
+	if d := t.MaxCollectDuration(); d > 0 {
+		def.MaxCollectDuration = d.String()
+	}
+	if d := t.RunDeadline(); d > 0 {
+		def.RunDeadline = d.String()
+	}
+	if b := t.Buffer(); b != nil {
+		def.Buffer = &core.TaskBufferRequest{MaxSize: b.MaxSize / (1024 * 1024)}
+		if b.MaxAge > 0 {
+			def.Buffer.MaxAge = b.MaxAge.String()
+		}
+	}
+	return def
+}
+
+// taskOverrides is the body of the requests setting the config of a task
+// overridden on some members
+type taskOverrides struct {
+	Overrides map[string]agreement.TaskOverride `json:"overrides"`
+}
+
+func (s *apiV1) setTaskOverrides(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	tribeLogger = tribeLogger.WithField("_block", "setTaskOverrides")
+	wait, err := tribeWait(r)
+	if err != nil {
+		tribeLogger.Error(err)
+		rbody.Write(400, rbody.FromError(err), w)
+		return
+	}
+	name := p.ByName("name")
+	id := p.ByName("id")
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		tribeLogger.Error(err)
+		rbody.Write(500, rbody.FromError(err), w)
+		return
+	}
+
+	o := taskOverrides{}
+	if err := json.Unmarshal(b, &o); err != nil {
+		fields := map[string]interface{}{
+			"error": err,
+			"hint":  `The body of the request should be of the form '{"overrides": {"member_name": {"plugin_name": {"key": "value"}}}}'`,
+		}
+		se := serror.New(ErrInvalidJSON, fields)
+		tribeLogger.WithFields(fields).Error(ErrInvalidJSON)
+		rbody.Write(400, rbody.FromSnapError(se), w)
+		return
+	}
+
+	if serr := s.tribeManager.SetTaskOverrides(name, id, o.Overrides); serr != nil {
+		tribeLogger.Error(serr)
+		rbody.Write(400, rbody.FromSnapError(serr), w)
+		return
+	}
+	if !s.syncTribe(w, id, wait) {
+		return
+	}
+	a, _ := s.tribeManager.GetAgreement(name)
+	rbody.Write(200, &rbody.TribeTaskOverridesSet{Agreement: a}, w)
+}
+
+// tribeWait returns the time a request waits for the local member to apply
+// the intents of the tribe resulting from it, given by its wait parameter
+func tribeWait(r *http.Request) (time.Duration, error) {
+	wait := r.URL.Query().Get("wait")
+	if wait == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(wait)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid wait `%s`", wait)
+	}
+	return d, nil
+}
+
+// syncTribe waits for the local member to apply the intents of the tribe
+// about the task, or about every task when the ID is empty, so that the
+// response of a request is followed by reads of its writes. It responds with
+// an error when the wait expires first.
+func (s *apiV1) syncTribe(w http.ResponseWriter, taskID string, wait time.Duration) bool {
+	if wait == 0 || s.tribeManager == nil {
+		return true
+	}
+	if serr := s.tribeManager.Sync(taskID, wait); serr != nil {
+		tribeLogger.Warn(serr)
+		rbody.Write(504, rbody.FromSnapError(serr), w)
+		return false
+	}
+	return true
+}
@@ -20,12 +20,14 @@ limitations under the License.
 package v2
 
 import (
-	"encoding/json"
 	"sync"
+	"time"
 
 	"net/http"
 
 	log "github.com/Sirupsen/logrus"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/core"
 	"github.com/intelsdi-x/snap/mgmt/rest/api"
 	"github.com/urfave/negroni"
 )
@@ -44,6 +46,8 @@ type apiV2 struct {
 	metricManager api.Metrics
 	taskManager   api.Tasks
 	configManager api.Config
+	notifier      api.Notifier
+	logging       api.Logging
 
 	wg       *sync.WaitGroup
 	killChan chan struct{}
@@ -70,7 +74,7 @@ func (s *apiV2) GetRoutes() []api.Route {
 		// Responses:
 		// 200: PluginsResponse
 		// 401: UnauthResponse
-		api.Route{Method: "GET", Path: prefix + "/plugins", Handle: s.getPlugins},
+		api.Route{Method: "GET", Path: prefix + "/plugins", Handle: s.getPlugins, Response: &PluginsResponse{}},
 		// swagger:route GET /plugins/{ptype}/{pname}/{pversion} plugins getPlugin
 		//
 		// Get
@@ -88,7 +92,7 @@ func (s *apiV2) GetRoutes() []api.Route {
 		// 404: ErrorResponse
 		// 500: ErrorResponse
 		// 401: UnauthResponse
-		api.Route{Method: "GET", Path: prefix + "/plugins/:type/:name/:version", Handle: s.getPlugin},
+		api.Route{Method: "GET", Path: prefix + "/plugins/:type/:name/:version", Handle: s.getPlugin, Response: &Plugin{}},
 		// swagger:route POST /plugins plugins loadPlugin
 		//
 		// Load
@@ -110,7 +114,7 @@ func (s *apiV2) GetRoutes() []api.Route {
 		// 415: ErrorResponse
 		// 500: ErrorResponse
 		// 401: UnauthResponse
-		api.Route{Method: "POST", Path: prefix + "/plugins", Handle: s.loadPlugin},
+		api.Route{Method: "POST", Path: prefix + "/plugins", Handle: s.loadPlugin, Response: &Plugin{}},
 		// swagger:route DELETE /plugins/{ptype}/{pname}/{pversion} plugins unloadPlugin
 		//
 		// Unload
@@ -145,7 +149,7 @@ func (s *apiV2) GetRoutes() []api.Route {
 		// 200: PluginConfigResponse
 		// 400: ErrorResponse
 		// 401: UnauthResponse
-		api.Route{Method: "GET", Path: prefix + "/plugins/:type/:name/:version/config", Handle: s.getPluginConfigItem},
+		api.Route{Method: "GET", Path: prefix + "/plugins/:type/:name/:version/config", Handle: s.getPluginConfigItem, Response: &PluginConfigItem{}},
 		// swagger:route PUT /plugins/{ptype}/{pname}/{pversion}/config plugins setPluginConfigItem
 		//
 		// Set Config
@@ -164,7 +168,7 @@ func (s *apiV2) GetRoutes() []api.Route {
 		// 200: PluginConfigResponse
 		// 400: ErrorResponse
 		// 401: UnauthResponse
-		api.Route{Method: "PUT", Path: prefix + "/plugins/:type/:name/:version/config", Handle: s.setPluginConfigItem},
+		api.Route{Method: "PUT", Path: prefix + "/plugins/:type/:name/:version/config", Handle: s.setPluginConfigItem, Body: map[string]interface{}{}, Response: &PluginConfigItem{}},
 		// swagger:route DELETE /plugins/{ptype}/{pname}/{pversion}/config plugins deletePluginConfigItem
 		//
 		// Delete Config
@@ -183,12 +187,93 @@ func (s *apiV2) GetRoutes() []api.Route {
 		// 200: PluginConfigResponse
 		// 400: ErrorResponse
 		// 401: UnauthResponse
-		api.Route{Method: "DELETE", Path: prefix + "/plugins/:type/:name/:version/config", Handle: s.deletePluginConfigItem},
+		api.Route{Method: "DELETE", Path: prefix + "/plugins/:type/:name/:version/config", Handle: s.deletePluginConfigItem, Body: []string{}, Response: &PluginConfigItem{}},
+		// swagger:route GET /plugins/{ptype}/{pname}/{pversion}/config/schema plugins getPluginConfigSchema
+		//
+		// Get Config Schema
+		//
+		// The config policy of the plugin as JSON Schema, to validate the config of a task before it's created.
+		//
+		// Produces:
+		// application/json
+		//
+		// Schemes: http, https
+		//
+		// Responses:
+		// 200: PluginConfigSchemaResponse
+		// 400: ErrorResponse
+		// 404: ErrorResponse
+		// 401: UnauthResponse
+		api.Route{Method: "GET", Path: prefix + "/plugins/:type/:name/:version/config/schema", Handle: s.getPluginConfigSchema, Response: &cpolicy.Schema{}},
+		// swagger:route GET /blacklist plugins getPluginBlacklist
+		//
+		// Get Blacklist
+		//
+		// An empty list is returned if no plugin is blacklisted.
+		//
+		// Produces:
+		// application/json
+		//
+		// Schemes: http, https
+		//
+		// Responses:
+		// 200: BlacklistResponse
+		// 401: UnauthResponse
+		api.Route{Method: "GET", Path: prefix + "/blacklist", Handle: s.getPluginBlacklist, Response: &BlacklistResponse{}},
+		// swagger:route DELETE /blacklist plugins clearPluginBlacklist
+		//
+		// Clear Blacklist
+		//
+		// All the plugins are removed from the blacklist.
+		//
+		// Produces:
+		// application/json
+		//
+		// Schemes: http, https
+		//
+		// Responses:
+		// 204: BlacklistResponse
+		// 500: ErrorResponse
+		// 401: UnauthResponse
+		api.Route{Method: "DELETE", Path: prefix + "/blacklist", Handle: s.clearPluginBlacklist},
+		// swagger:route DELETE /blacklist/{checksum} plugins clearPluginBlacklistItem
+		//
+		// Remove From Blacklist
+		//
+		// The checksum of the plugin binary is required.
+		//
+		// Produces:
+		// application/json
+		//
+		// Schemes: http, https
+		//
+		// Responses:
+		// 204: BlacklistResponse
+		// 404: ErrorResponse
+		// 500: ErrorResponse
+		// 401: UnauthResponse
+		api.Route{Method: "DELETE", Path: prefix + "/blacklist/:checksum", Handle: s.clearPluginBlacklistItem},
+		// swagger:route GET /scheduler/pools tasks getWorkerPools
+		//
+		// Get Worker Pools
+		//
+		// The collect, process and publish worker pools of the scheduler are returned with the state of their queue.
+		//
+		// Produces:
+		// application/json
+		//
+		// Schemes: http, https
+		//
+		// Responses:
+		// 200: WorkerPoolsResponse
+		// 401: UnauthResponse
+		api.Route{Method: "GET", Path: prefix + "/scheduler/pools", Handle: s.getWorkerPools, Response: &WorkerPoolsResponse{}},
 		// swagger:route GET /metrics plugins getMetrics
 		//
 		// Get Metrics
 		//
 		// An empty list returns if there is no loaded metrics.
+		// The list can be paginated with the offset and limit parameters.
 		//
 		// Produces:
 		// application/json
@@ -197,10 +282,11 @@ func (s *apiV2) GetRoutes() []api.Route {
 		//
 		// Responses:
 		// 200: MetricsResponse
+		// 400: ErrorResponse
 		// 404: ErrorResponse
 		// 500: ErrorResponse
 		// 401: UnauthResponse
-		api.Route{Method: "GET", Path: prefix + "/metrics", Handle: s.getMetrics},
+		api.Route{Method: "GET", Path: prefix + "/metrics", Handle: s.getMetrics, Response: &MetricsResonse{}},
 		// swagger:route GET /tasks tasks getTasks
 		//
 		// Get All
@@ -214,8 +300,9 @@ func (s *apiV2) GetRoutes() []api.Route {
 		//
 		// Responses:
 		// 200: TasksResponse
+		// 400: ErrorResponse
 		// 401: UnauthResponse
-		api.Route{Method: "GET", Path: prefix + "/tasks", Handle: s.getTasks},
+		api.Route{Method: "GET", Path: prefix + "/tasks", Handle: s.getTasks, Response: &TasksResponse{}},
 		// swagger:route GET /tasks/{id} tasks getTask
 		//
 		// Get
@@ -231,7 +318,7 @@ func (s *apiV2) GetRoutes() []api.Route {
 		// 200: TaskResponse
 		// 404: ErrorResponse
 		// 401: UnauthResponse
-		api.Route{Method: "GET", Path: prefix + "/tasks/:id", Handle: s.getTask},
+		api.Route{Method: "GET", Path: prefix + "/tasks/:id", Handle: s.getTask, Response: &Task{}},
 		// swagger:route GET /tasks/{id}/watch tasks watchTask
 		//
 		// Watch
@@ -267,7 +354,28 @@ func (s *apiV2) GetRoutes() []api.Route {
 		// 201: TaskResponse
 		// 500: ErrorResponse
 		// 401: UnauthResponse
-		api.Route{Method: "POST", Path: prefix + "/tasks", Handle: s.addTask},
+		api.Route{Method: "POST", Path: prefix + "/tasks", Handle: s.addTask, Body: &core.TaskCreationRequest{}, Response: &Task{}},
+		// swagger:route POST /tasks/run tasks runTask
+		//
+		// Run Once
+		//
+		// The workflow of a Snap task manifest is run a single time, without creating a task, and the metrics it collected and passed to its publishers are returned.
+		// The metrics are only published when the publish parameter is true.
+		//
+		// Consumes:
+		// application/json
+		//
+		// Produces:
+		// application/json
+		//
+		// Schemes: http, https
+		//
+		// Responses:
+		// 200: TaskRunResponse
+		// 400: ErrorResponse
+		// 500: ErrorResponse
+		// 401: UnauthResponse
+		api.Route{Method: "POST", Path: prefix + "/tasks/run", Handle: s.runTask, Body: &core.TaskCreationRequest{}, Response: &TaskRun{}},
 		// swagger:route PUT /tasks/{id} tasks updateTaskState
 		//
 		// Enable/Start/Stop
@@ -306,6 +414,238 @@ func (s *apiV2) GetRoutes() []api.Route {
 		// 500: TaskErrorResponse
 		// 401: UnauthResponse
 		api.Route{Method: "DELETE", Path: prefix + "/tasks/:id", Handle: s.removeTask},
+		// swagger:route PUT /tasks tasks bulkUpdateTaskState
+		//
+		// Bulk Start/Stop
+		//
+		// The tasks are selected by a name pattern, by labels or by both, and are all started or stopped, or none of them when the action can't be applied to one of them.
+		// The tasks already in the requested state are left as they are.
+		//
+		// Produces:
+		// application/json
+		//
+		// Schemes: http, https
+		//
+		// Responses:
+		// 200: BulkTasksResponse
+		// 400: ErrorResponse
+		// 409: BulkTasksResponse
+		// 500: ErrorResponse
+		// 401: UnauthResponse
+		api.Route{Method: "PUT", Path: prefix + "/tasks", Handle: s.bulkUpdateTaskState, Response: &BulkTasksResponse{}},
+		// swagger:route DELETE /tasks tasks bulkRemoveTasks
+		//
+		// Bulk Remove
+		//
+		// The tasks are selected by a name pattern, by labels or by both, and are all removed, or none of them when one of them isn't stopped.
+		//
+		// Produces:
+		// application/json
+		//
+		// Schemes: http, https
+		//
+		// Responses:
+		// 200: BulkTasksResponse
+		// 400: ErrorResponse
+		// 409: BulkTasksResponse
+		// 500: ErrorResponse
+		// 401: UnauthResponse
+		api.Route{Method: "DELETE", Path: prefix + "/tasks", Handle: s.bulkRemoveTasks, Response: &BulkTasksResponse{}},
+		// swagger:route GET /deadletters tasks getDeadLetters
+		//
+		// Get Dead Letters
+		//
+		// The batches of metrics the publishers failed to publish are returned oldest first, without their metrics.
+		//
+		// Produces:
+		// application/json
+		//
+		// Schemes: http, https
+		//
+		// Responses:
+		// 200: DeadLettersResponse
+		// 404: ErrorResponse
+		// 500: ErrorResponse
+		// 401: UnauthResponse
+		api.Route{Method: "GET", Path: prefix + "/deadletters", Handle: s.getDeadLetters, Response: &DeadLettersResponse{}},
+		// swagger:route GET /deadletters/{id} tasks getDeadLetter
+		//
+		// Get Dead Letter
+		//
+		// A batch of metrics a publisher failed to publish is returned with its metrics.
+		//
+		// Produces:
+		// application/json
+		//
+		// Schemes: http, https
+		//
+		// Responses:
+		// 200: DeadLetterResponse
+		// 404: ErrorResponse
+		// 500: ErrorResponse
+		// 401: UnauthResponse
+		api.Route{Method: "GET", Path: prefix + "/deadletters/:id", Handle: s.getDeadLetter, Response: &DeadLetter{}},
+		// swagger:route PUT /deadletters tasks replayDeadLetters
+		//
+		// Replay Dead Letters
+		//
+		// The selected batches, or all of them, are published again with the current config of their publisher and removed from the queue once published.
+		//
+		// Produces:
+		// application/json
+		//
+		// Schemes: http, https
+		//
+		// Responses:
+		// 200: DeadLetterResultsResponse
+		// 400: ErrorResponse
+		// 404: ErrorResponse
+		// 500: ErrorResponse
+		// 401: UnauthResponse
+		api.Route{Method: "PUT", Path: prefix + "/deadletters", Handle: s.replayDeadLetters, Response: &DeadLetterResultsResponse{}},
+		// swagger:route DELETE /deadletters tasks purgeDeadLetters
+		//
+		// Purge Dead Letters
+		//
+		// The selected batches, or all of them, are removed from the queue.
+		//
+		// Produces:
+		// application/json
+		//
+		// Schemes: http, https
+		//
+		// Responses:
+		// 200: DeadLetterResultsResponse
+		// 404: ErrorResponse
+		// 500: ErrorResponse
+		// 401: UnauthResponse
+		api.Route{Method: "DELETE", Path: prefix + "/deadletters", Handle: s.purgeDeadLetters, Response: &DeadLetterResultsResponse{}},
+		// swagger:route GET /webhooks webhooks getWebhooks
+		//
+		// Get Webhooks
+		//
+		// The webhooks the events of snapteld are notified to are returned, without their secrets.
+		//
+		// Produces:
+		// application/json
+		//
+		// Schemes: http, https
+		//
+		// Responses:
+		// 200: WebhooksResponse
+		// 404: ErrorResponse
+		// 401: UnauthResponse
+		api.Route{Method: "GET", Path: prefix + "/webhooks", Handle: s.getWebhooks, Response: &WebhooksResponse{}},
+		// swagger:route GET /webhooks/{id} webhooks getWebhook
+		//
+		// Get Webhook
+		//
+		// An error will be returned if the webhook does not exist.
+		//
+		// Produces:
+		// application/json
+		//
+		// Schemes: http, https
+		//
+		// Responses:
+		// 200: WebhookResponse
+		// 404: ErrorResponse
+		// 401: UnauthResponse
+		api.Route{Method: "GET", Path: prefix + "/webhooks/:id", Handle: s.getWebhook, Response: &Webhook{}},
+		// swagger:route POST /webhooks webhooks addWebhook
+		//
+		// Add Webhook
+		//
+		// The events matching the filters of the webhook are POSTed to its URL from then on.
+		//
+		// Consumes:
+		// application/json
+		//
+		// Produces:
+		// application/json
+		//
+		// Schemes: http, https
+		//
+		// Responses:
+		// 201: WebhookResponse
+		// 400: ErrorResponse
+		// 404: ErrorResponse
+		// 500: ErrorResponse
+		// 401: UnauthResponse
+		api.Route{Method: "POST", Path: prefix + "/webhooks", Handle: s.addWebhook, Body: &WebhookRequest{}, Response: &Webhook{}},
+		// swagger:route DELETE /webhooks/{id} webhooks removeWebhook
+		//
+		// Remove Webhook
+		//
+		// The events are no longer notified to the webhook.
+		//
+		// Produces:
+		// application/json
+		//
+		// Schemes: http, https
+		//
+		// Responses:
+		// 204: WebhookResponse
+		// 404: ErrorResponse
+		// 500: ErrorResponse
+		// 401: UnauthResponse
+		api.Route{Method: "DELETE", Path: prefix + "/webhooks/:id", Handle: s.removeWebhook},
+		// swagger:route GET /log/levels log getLogLevels
+		//
+		// Get Log Levels
+		//
+		// The log level of snapteld is returned along with the log levels set for its modules.
+		//
+		// Produces:
+		// application/json
+		//
+		// Schemes: http, https
+		//
+		// Responses:
+		// 200: LogLevelsResponse
+		// 404: ErrorResponse
+		// 401: UnauthResponse
+		api.Route{Method: "GET", Path: prefix + "/log/levels", Handle: s.getLogLevels, Response: &LogLevels{}},
+		// swagger:route PUT /log/levels log setLogLevel
+		//
+		// Set Log Level
+		//
+		// The log level of a module, and of its submodules without a level, is set, or the log level of snapteld when no module is given.
+		//
+		// Consumes:
+		// application/json
+		//
+		// Produces:
+		// application/json
+		//
+		// Schemes: http, https
+		//
+		// Responses:
+		// 200: LogLevelsResponse
+		// 400: ErrorResponse
+		// 404: ErrorResponse
+		// 401: UnauthResponse
+		api.Route{Method: "PUT", Path: prefix + "/log/levels", Handle: s.setLogLevel, Body: &LogLevelRequest{}, Response: &LogLevels{}},
+		// swagger:route DELETE /log/levels/{module} log resetLogLevel
+		//
+		// Reset Log Level
+		//
+		// The log level of the module is removed, its entries are logged with the log level of its parent module or of snapteld from then on.
+		//
+		// Produces:
+		// application/json
+		//
+		// Schemes: http, https
+		//
+		// Responses:
+		// 200: LogLevelsResponse
+		// 404: ErrorResponse
+		// 401: UnauthResponse
+		api.Route{Method: "DELETE", Path: prefix + "/log/levels/:module", Handle: s.resetLogLevel, Response: &LogLevels{}},
+	}
+	// the responses are encoded in the media type accepted by the requests
+	for i := range routes {
+		routes[i].Handle = negotiate(routes[i].Handle)
 	}
 	return routes
 }
@@ -324,21 +664,44 @@ func (s *apiV2) BindConfigManager(configManager api.Config) {
 	s.configManager = configManager
 }
 
+func (s *apiV2) BindNotifier(notifier api.Notifier) {
+	s.notifier = notifier
+}
+
+func (s *apiV2) BindLogging(logging api.Logging) {
+	s.logging = logging
+}
+
+func (s *apiV2) BindConfigReloader(configReloader api.ConfigReloader) {}
+
 func Write(code int, body interface{}, w http.ResponseWriter) {
-	w.Header().Set("Content-Type", "application/json; version=2; charset=utf-8")
+	mediaType := MediaTypeJSON
+	if e, ok := w.(*encodingWriter); ok {
+		mediaType = e.mediaType
+	}
+	w.Header().Set("Content-Type", contentTypes[mediaType])
 	w.Header().Set("Version", "beta")
 
 	if !w.(negroni.ResponseWriter).Written() {
 		w.WriteHeader(code)
 	}
+	// the errors carry the status code of the response
+	if e, ok := body.(*Error); ok && e.Code == 0 {
+		e.Code = code
+	}
 
 	if body != nil {
-		e := json.NewEncoder(w)
-		e.SetIndent("", "  ")
-		e.SetEscapeHTML(false)
-		err := e.Encode(body)
+		err := encoders[mediaType](w, body)
 		if err != nil {
 			restLogger.Fatalln(err)
 		}
 	}
 }
+
+// timestamp formats a time of a response as RFC3339, the zero time is empty
+func timestamp(t time.Time) string {
+	if t.IsZero() || t.Unix() <= 0 {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
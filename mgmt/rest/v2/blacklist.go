@@ -0,0 +1,105 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"net/http"
+
+	"github.com/intelsdi-x/snap/control"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/julienschmidt/httprouter"
+)
+
+// BlacklistResp represents the response from blacklist operations.
+//
+// swagger:response BlacklistResponse
+type BlacklistResp struct {
+	// List of blacklisted plugins
+	//
+	// in: body
+	Body struct {
+		Plugins []BlacklistedPlugin `json:"plugins"`
+	}
+}
+
+type BlacklistResponse struct {
+	Plugins []BlacklistedPlugin `json:"plugins"`
+}
+
+// BlacklistedPlugin represents a plugin binary blacklisted after crashing repeatedly.
+type BlacklistedPlugin struct {
+	Name                 string `json:"name"`
+	Version              int    `json:"version"`
+	Type                 string `json:"type"`
+	Path                 string `json:"path"`
+	CheckSum             string `json:"checksum"`
+	Crashes              int    `json:"crashes"`
+	BlacklistedTimestamp string `json:"blacklisted_timestamp"`
+}
+
+// BlacklistedPluginParams defines the checksum of the plugin binary to remove from the blacklist.
+//
+// swagger:parameters clearPluginBlacklistItem
+type BlacklistedPluginParams struct {
+	// in: path
+	// required: true
+	CheckSum string `json:"checksum"`
+}
+
+func (s *apiV2) getPluginBlacklist(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	Write(200, BlacklistResponse{Plugins: blacklistBody(s.metricManager.PluginBlacklist())}, w)
+}
+
+func (s *apiV2) clearPluginBlacklist(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	if se := s.metricManager.ClearPluginBlacklist(""); se != nil {
+		Write(500, FromSnapError(se), w)
+		return
+	}
+	Write(204, nil, w)
+}
+
+func (s *apiV2) clearPluginBlacklistItem(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	se := s.metricManager.ClearPluginBlacklist(p.ByName("checksum"))
+	if se != nil {
+		statusCode := 500
+		if se.Error() == control.ErrPluginNotBlacklisted.Error() {
+			statusCode = 404
+		}
+		Write(statusCode, FromSnapError(se), w)
+		return
+	}
+	Write(204, nil, w)
+}
+
+func blacklistBody(blacklist []core.BlacklistedPlugin) []BlacklistedPlugin {
+	plugins := make([]BlacklistedPlugin, len(blacklist))
+	for i, b := range blacklist {
+		plugins[i] = BlacklistedPlugin{
+			Name:                 b.Name,
+			Version:              b.Version,
+			Type:                 b.Type.String(),
+			Path:                 b.Path,
+			CheckSum:             b.CheckSum,
+			Crashes:              b.Crashes,
+			BlacklistedTimestamp: timestamp(b.Since),
+		}
+	}
+	return plugins
+}
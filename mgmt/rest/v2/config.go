@@ -26,6 +26,8 @@ import (
 	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
 	"github.com/intelsdi-x/snap/core"
 	"github.com/intelsdi-x/snap/core/cdata"
+	"github.com/intelsdi-x/snap/core/serror"
+	"github.com/intelsdi-x/snap/mgmt/rest/api"
 	"github.com/julienschmidt/httprouter"
 )
 
@@ -45,6 +47,14 @@ type PluginConfigResponse struct {
 	Body cdata.ConfigDataNode
 }
 
+// PluginConfigSchemaResponse represents the JSON Schema of the config of a plugin.
+//
+// swagger:response PluginConfigSchemaResponse
+type PluginConfigSchemaResponse struct {
+	// in: body
+	Body cpolicy.Schema
+}
+
 // PluginConfigParam defines the string representation of a config.
 //
 //swagger:parameters setPluginConfigItem
@@ -77,7 +87,7 @@ func (s *apiV2) getPluginConfigItem(w http.ResponseWriter, r *http.Request, p ht
 	styp := p.ByName("type")
 	if styp == "" {
 		cfg := s.configManager.GetPluginConfigDataNodeAll()
-		item := &PluginConfigItem{cfg}
+		item := &PluginConfigItem{s.redactConfig(cfg)}
 		Write(200, item, w)
 		return
 	}
@@ -99,7 +109,7 @@ func (s *apiV2) getPluginConfigItem(w http.ResponseWriter, r *http.Request, p ht
 	}
 
 	cfg := s.configManager.GetPluginConfigDataNode(typ, name, iver)
-	item := &PluginConfigItem{cfg}
+	item := &PluginConfigItem{s.redactConfig(cfg)}
 	Write(200, item, w)
 }
 
@@ -138,8 +148,9 @@ func (s *apiV2) deletePluginConfigItem(w http.ResponseWriter, r *http.Request, p
 	} else {
 		res = s.configManager.DeletePluginConfigDataNodeField(typ, name, iver, src...)
 	}
+	s.reloadPluginConfig(typ, name, iver)
 
-	item := &PluginConfigItem{res}
+	item := &PluginConfigItem{s.redactConfig(res)}
 	Write(200, item, w)
 }
 
@@ -171,6 +182,10 @@ func (s *apiV2) setPluginConfigItem(w http.ResponseWriter, r *http.Request, p ht
 		Write(400, FromError(err), w)
 		return
 	}
+	if _, err := core.NewPluginPoolConfig(src); err != nil {
+		Write(400, FromError(err), w)
+		return
+	}
 
 	var res cdata.ConfigDataNode
 	if styp == "" {
@@ -178,7 +193,56 @@ func (s *apiV2) setPluginConfigItem(w http.ResponseWriter, r *http.Request, p ht
 	} else {
 		res = s.configManager.MergePluginConfigDataNode(typ, name, iver, src)
 	}
+	s.reloadPluginConfig(typ, name, iver)
 
-	item := &PluginConfigItem{res}
+	item := &PluginConfigItem{s.redactConfig(res)}
 	Write(200, item, w)
 }
+
+// getPluginConfigSchema returns the config policy of the plugin as JSON
+// Schema, keyed by namespace for the collectors
+func (s *apiV2) getPluginConfigSchema(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	plType, plName, plVersion, f, se := pluginParameters(p)
+	if se != nil {
+		Write(400, FromSnapError(se), w)
+		return
+	}
+
+	var plugin core.CatalogedPlugin
+	for _, item := range s.metricManager.PluginCatalog() {
+		if item.Name() == plName &&
+			item.Version() == plVersion &&
+			item.TypeName() == plType {
+			plugin = item
+			break
+		}
+	}
+	if plugin == nil {
+		Write(404, FromSnapError(serror.New(ErrPluginNotFound, f)), w)
+		return
+	}
+
+	// the config of the processors and publishers isn't given by namespace
+	if plugin.TypeName() == "processor" || plugin.TypeName() == "publisher" {
+		Write(200, plugin.Policy().Get([]string{""}).JSONSchema(), w)
+		return
+	}
+	Write(200, plugin.Policy().JSONSchema(), w)
+}
+
+// redactConfig redacts the sensitive values of the config returned when the
+// metric manager knows them
+func (s *apiV2) redactConfig(cdn cdata.ConfigDataNode) cdata.ConfigDataNode {
+	if rc, ok := s.metricManager.(api.ConfigRedactor); ok {
+		return rc.RedactConfig(cdn)
+	}
+	return cdn
+}
+
+// reloadPluginConfig applies the updated global config to the loaded plugins
+// it belongs to, errors are logged since the config has already been stored
+func (s *apiV2) reloadPluginConfig(typ core.PluginType, name string, ver int) {
+	for _, err := range s.metricManager.ReloadPluginConfig(typ, name, ver) {
+		restLogger.WithFields(err.Fields()).Warning(err)
+	}
+}
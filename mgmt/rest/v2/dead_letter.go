@@ -0,0 +1,195 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/serror"
+	"github.com/julienschmidt/httprouter"
+)
+
+// DeadLettersResponse returns the batches of the dead letter queue.
+//
+// swagger:response DeadLettersResponse
+type DeadLettersResp struct {
+	// in: body
+	Body struct {
+		DeadLetters []DeadLetter `json:"dead_letters"`
+	}
+}
+
+type DeadLettersResponse struct {
+	DeadLetters []DeadLetter `json:"dead_letters"`
+}
+
+// DeadLetterResponse returns a batch of the dead letter queue with its metrics.
+//
+// swagger:response DeadLetterResponse
+type DeadLetterResp struct {
+	// in: body
+	Body DeadLetter
+}
+
+// DeadLetter represents a batch of metrics a publisher of a task failed to publish.
+type DeadLetter struct {
+	ID            string   `json:"id"`
+	TaskID        string   `json:"task_id"`
+	TaskName      string   `json:"task_name"`
+	PluginName    string   `json:"plugin_name"`
+	PluginVersion int      `json:"plugin_version"`
+	Errors        []string `json:"errors,omitempty"`
+	Timestamp     string   `json:"timestamp"`
+	MetricCount   int      `json:"metric_count"`
+	// Metrics are only returned with a single batch
+	Metrics StreamedMetrics `json:"metrics,omitempty"`
+}
+
+// DeadLetterResultsResponse returns the result of a replay or a purge for each batch.
+//
+// swagger:response DeadLetterResultsResponse
+type DeadLetterResultsResp struct {
+	// in: body
+	Body struct {
+		DeadLetters []DeadLetterResult `json:"dead_letters"`
+	}
+}
+
+type DeadLetterResultsResponse struct {
+	DeadLetters []DeadLetterResult `json:"dead_letters"`
+}
+
+// DeadLetterResult represents the result of a replay or a purge for a batch.
+type DeadLetterResult struct {
+	ID    string `json:"id"`
+	Error *Error `json:"error,omitempty"`
+}
+
+// DeadLetterParams defines the batch of the dead letter queue.
+//
+// swagger:parameters getDeadLetter
+type DeadLetterParams struct {
+	// in: path
+	//
+	// required: true
+	ID string `json:"id"`
+}
+
+// DeadLetterIDsParams selects the batches of a replay or a purge, all of them when none is given.
+//
+// swagger:parameters replayDeadLetters purgeDeadLetters
+type DeadLetterIDsParams struct {
+	// The IDs of the batches
+	//
+	// in: query
+	ID []string `json:"id"`
+}
+
+// DeadLetterPutParams defines the operation applied to the batches.
+//
+// swagger:parameters replayDeadLetters
+type DeadLetterPutParams struct {
+	// Replay the batches
+	//
+	// in: query
+	//
+	// required: true
+	Action string `json:"action"`
+}
+
+func (s *apiV2) getDeadLetters(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	dls, serr := s.taskManager.DeadLetters()
+	if serr != nil {
+		writeDeadLetterError(serr, w)
+		return
+	}
+	resp := DeadLettersResponse{DeadLetters: make([]DeadLetter, len(dls))}
+	for i, dl := range dls {
+		resp.DeadLetters[i] = deadLetter(dl)
+	}
+	Write(200, resp, w)
+}
+
+func (s *apiV2) getDeadLetter(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	dl, serr := s.taskManager.GetDeadLetter(p.ByName("id"))
+	if serr != nil {
+		writeDeadLetterError(serr, w)
+		return
+	}
+	Write(200, deadLetter(dl), w)
+}
+
+func (s *apiV2) replayDeadLetters(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	switch action := r.URL.Query().Get("action"); action {
+	case "":
+		Write(400, FromError(ErrNoActionSpecified), w)
+	case "replay":
+		s.deadLetterOperation(s.taskManager.ReplayDeadLetters, w, r)
+	default:
+		Write(400, FromError(ErrWrongAction), w)
+	}
+}
+
+func (s *apiV2) purgeDeadLetters(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	s.deadLetterOperation(s.taskManager.PurgeDeadLetters, w, r)
+}
+
+func (s *apiV2) deadLetterOperation(op func([]string) ([]core.DeadLetterResult, serror.SnapError), w http.ResponseWriter, r *http.Request) {
+	results, serr := op(r.URL.Query()["id"])
+	if serr != nil {
+		writeDeadLetterError(serr, w)
+		return
+	}
+	resp := DeadLetterResultsResponse{DeadLetters: make([]DeadLetterResult, len(results))}
+	for i, res := range results {
+		resp.DeadLetters[i] = DeadLetterResult{ID: res.ID}
+		if res.Error != nil {
+			resp.DeadLetters[i].Error = FromSnapError(res.Error)
+		}
+	}
+	Write(200, resp, w)
+}
+
+func writeDeadLetterError(serr serror.SnapError, w http.ResponseWriter) {
+	if strings.Contains(serr.Error(), ErrDeadLetterQueueDisabled) || strings.Contains(serr.Error(), ErrDeadLetterNotFound) {
+		Write(404, FromSnapError(serr), w)
+		return
+	}
+	Write(500, FromSnapError(serr), w)
+}
+
+func deadLetter(dl core.DeadLetter) DeadLetter {
+	d := DeadLetter{
+		ID:            dl.ID,
+		TaskID:        dl.TaskID,
+		TaskName:      dl.TaskName,
+		PluginName:    dl.PluginName,
+		PluginVersion: dl.PluginVersion,
+		Errors:        dl.Errors,
+		Timestamp:     timestamp(dl.Timestamp),
+		MetricCount:   dl.MetricCount,
+	}
+	if dl.Metrics != nil {
+		d.Metrics = streamedMetrics(dl.Metrics)
+	}
+	return d
+}
@@ -0,0 +1,183 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	"github.com/hashicorp/go-msgpack/codec"
+	"github.com/julienschmidt/httprouter"
+	"github.com/urfave/negroni"
+)
+
+// The media types the responses are encoded in, negotiated with the Accept
+// header of the requests
+const (
+	MediaTypeJSON     = "application/json"
+	MediaTypeMsgpack  = "application/msgpack"
+	MediaTypeProtobuf = "application/x-protobuf"
+)
+
+// mediaTypes maps the media types accepted by the clients to the media types
+// of the responses
+var mediaTypes = map[string]string{
+	"application/json":       MediaTypeJSON,
+	"application/*":          MediaTypeJSON,
+	"*/*":                    MediaTypeJSON,
+	"application/msgpack":    MediaTypeMsgpack,
+	"application/x-msgpack":  MediaTypeMsgpack,
+	"application/x-protobuf": MediaTypeProtobuf,
+	"application/protobuf":   MediaTypeProtobuf,
+}
+
+// contentTypes are the content types of the responses of the media types,
+// the protobuf responses are google.protobuf.Value messages
+var contentTypes = map[string]string{
+	MediaTypeJSON:     "application/json; version=2; charset=utf-8",
+	MediaTypeMsgpack:  "application/msgpack; version=2",
+	MediaTypeProtobuf: "application/x-protobuf; version=2; messageType=google.protobuf.Value",
+}
+
+var encoders = map[string]func(io.Writer, interface{}) error{
+	MediaTypeJSON:     encodeJSON,
+	MediaTypeMsgpack:  encodeMsgpack,
+	MediaTypeProtobuf: encodeProtobuf,
+}
+
+// encodingWriter carries the media type negotiated for the response to Write
+type encodingWriter struct {
+	negroni.ResponseWriter
+	mediaType string
+}
+
+func (e *encodingWriter) CloseNotify() <-chan bool {
+	return e.ResponseWriter.(http.CloseNotifier).CloseNotify()
+}
+
+// negotiate returns the handle of a route writing its response in the media
+// type accepted by the request, JSON when none of the accepted media types
+// is supported
+func negotiate(h httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		mediaType := acceptedMediaType(r.Header.Get("Accept"))
+		if mediaType == MediaTypeJSON {
+			h(w, r, p)
+			return
+		}
+		h(&encodingWriter{ResponseWriter: w.(negroni.ResponseWriter), mediaType: mediaType}, r, p)
+	}
+}
+
+// acceptedMediaType returns the supported media type of the Accept header
+// with the highest quality
+func acceptedMediaType(accept string) string {
+	best, quality := MediaTypeJSON, 0.0
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		mediaType, ok := mediaTypes[strings.ToLower(strings.TrimSpace(params[0]))]
+		if !ok {
+			continue
+		}
+		q := 1.0
+		for _, param := range params[1:] {
+			if kv := strings.SplitN(strings.TrimSpace(param), "=", 2); len(kv) == 2 && kv[0] == "q" {
+				if v, err := strconv.ParseFloat(kv[1], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q > quality {
+			best, quality = mediaType, q
+		}
+	}
+	return best
+}
+
+func encodeJSON(w io.Writer, body interface{}) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	e.SetEscapeHTML(false)
+	return e.Encode(body)
+}
+
+// genericBody returns the body as decoded from its JSON, the other encodings
+// share the field names of the JSON responses
+func genericBody(body interface{}) (interface{}, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	err = json.Unmarshal(b, &v)
+	return v, err
+}
+
+func encodeMsgpack(w io.Writer, body interface{}) error {
+	v, err := genericBody(body)
+	if err != nil {
+		return err
+	}
+	return codec.NewEncoder(w, &codec.MsgpackHandle{}).Encode(v)
+}
+
+func encodeProtobuf(w io.Writer, body interface{}) error {
+	v, err := genericBody(body)
+	if err != nil {
+		return err
+	}
+	b, err := proto.Marshal(protoValue(v))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// protoValue returns the google.protobuf.Value message of a value decoded
+// from JSON
+func protoValue(v interface{}) *structpb.Value {
+	switch v := v.(type) {
+	case float64:
+		return &structpb.Value{Kind: &structpb.Value_NumberValue{NumberValue: v}}
+	case string:
+		return &structpb.Value{Kind: &structpb.Value_StringValue{StringValue: v}}
+	case bool:
+		return &structpb.Value{Kind: &structpb.Value_BoolValue{BoolValue: v}}
+	case map[string]interface{}:
+		fields := make(map[string]*structpb.Value, len(v))
+		for k, e := range v {
+			fields[k] = protoValue(e)
+		}
+		return &structpb.Value{Kind: &structpb.Value_StructValue{StructValue: &structpb.Struct{Fields: fields}}}
+	case []interface{}:
+		values := make([]*structpb.Value, len(v))
+		for i, e := range v {
+			values[i] = protoValue(e)
+		}
+		return &structpb.Value{Kind: &structpb.Value_ListValue{ListValue: &structpb.ListValue{Values: values}}}
+	}
+	return &structpb.Value{Kind: &structpb.Value_NullValue{NullValue: structpb.NullValue_NULL_VALUE}}
+}
@@ -0,0 +1,72 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	"github.com/hashicorp/go-msgpack/codec"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAcceptedMediaType(t *testing.T) {
+	Convey("Negotiating the media type of the responses", t, func() {
+		So(acceptedMediaType(""), ShouldEqual, MediaTypeJSON)
+		So(acceptedMediaType("text/html"), ShouldEqual, MediaTypeJSON)
+		So(acceptedMediaType("application/msgpack"), ShouldEqual, MediaTypeMsgpack)
+		So(acceptedMediaType("application/x-msgpack"), ShouldEqual, MediaTypeMsgpack)
+		So(acceptedMediaType("application/x-protobuf"), ShouldEqual, MediaTypeProtobuf)
+		So(acceptedMediaType("application/json;q=0.5, application/msgpack"), ShouldEqual, MediaTypeMsgpack)
+		So(acceptedMediaType("application/msgpack;q=0.5, */*"), ShouldEqual, MediaTypeJSON)
+	})
+}
+
+func TestEncoders(t *testing.T) {
+	body := PluginsResponse{Plugins: []Plugin{{Name: "mock", Version: 1, Type: "collector"}}}
+
+	Convey("Encoding the responses in msgpack", t, func() {
+		var buf bytes.Buffer
+		So(encodeMsgpack(&buf, body), ShouldBeNil)
+		var v map[string]interface{}
+		So(codec.NewDecoder(&buf, &codec.MsgpackHandle{}).Decode(&v), ShouldBeNil)
+		So(v, ShouldContainKey, "plugins")
+	})
+
+	Convey("Encoding the responses in protobuf", t, func() {
+		So(protoValue(nil).GetKind(), ShouldHaveSameTypeAs, &structpb.Value_NullValue{})
+		So(protoValue(true).GetBoolValue(), ShouldBeTrue)
+		So(protoValue("a").GetStringValue(), ShouldEqual, "a")
+		So(protoValue([]interface{}{"a"}).GetListValue().GetValues()[0].GetStringValue(), ShouldEqual, "a")
+		So(protoValue(map[string]interface{}{"a": 1.0}).GetStructValue().GetFields()["a"].GetNumberValue(), ShouldEqual, 1.0)
+
+		var buf bytes.Buffer
+		So(encodeProtobuf(&buf, body), ShouldBeNil)
+		v := &structpb.Value{}
+		So(proto.Unmarshal(buf.Bytes(), v), ShouldBeNil)
+		plugins := v.GetStructValue().GetFields()["plugins"].GetListValue().GetValues()
+		So(plugins, ShouldHaveLength, 1)
+		So(plugins[0].GetStructValue().GetFields()["name"].GetStringValue(), ShouldEqual, "mock")
+	})
+}
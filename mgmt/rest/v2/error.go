@@ -31,13 +31,24 @@ const (
 	ErrPluginAlreadyLoaded     = "plugin is already loaded"
 	ErrTaskNotFound            = "task not found"
 	ErrTaskDisabledNotRunnable = "task is disabled"
+	ErrTaskPausedNotRunnable   = "Task is paused"
+	ErrTaskNotPausable         = "can't be paused"
+	ErrTaskNotPaused           = "Task is not paused"
+	ErrDeadLetterQueueDisabled = "Dead letter queue is not enabled"
+	ErrDeadLetterNotFound      = "Dead letter not found"
 )
 
 var (
-	ErrPluginNotFound       = errors.New("plugin not found")
-	ErrStreamingUnsupported = errors.New("streaming unsupported")
-	ErrNoActionSpecified    = errors.New("no action was specified in the request")
-	ErrWrongAction          = errors.New("wrong action requested")
+	ErrPluginNotFound        = errors.New("plugin not found")
+	ErrStreamingUnsupported  = errors.New("streaming unsupported")
+	ErrNoActionSpecified     = errors.New("no action was specified in the request")
+	ErrWrongAction           = errors.New("wrong action requested")
+	ErrNoWorkflowSpecified   = errors.New("no workflow was specified in the request")
+	ErrInvalidCursor         = errors.New("invalid cursor")
+	ErrNotificationsDisabled = errors.New("Notifications are not enabled")
+	ErrLoggingUnavailable    = errors.New("The log levels can't be changed")
+	ErrForbidden             = errors.New("Forbidden. None of the roles granted to the identity of the request allows it.")
+	ErrNotAuthorized         = errors.New("Not authorized. Please specify the same password that used to start snapteld. E.g: [snaptel -p plugin list] or [curl http://localhost:8181/v2/plugins -u snap]")
 )
 
 // ErrorResponse represents the Snap error response type.
@@ -54,17 +65,14 @@ type ErrorResponse struct {
 // swagger:response UnauthResponse
 type UnauthResponse struct {
 	// in:body
-	Unauth UnauthError `json:"unauth"`
+	Unauth Error `json:"unauth"`
 }
 
-// UnauthError defines the error type of an unauthorized response.
-type UnauthError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-}
-
-// Unsuccessful generic response to a failed API call
+// Unsuccessful generic response to a failed API call, every error of the API
+// is returned in this form
 type Error struct {
+	// Code is the HTTP status code of the response, set by Write
+	Code         int               `json:"code,omitempty"`
 	ErrorMessage string            `json:"message"`
 	Fields       map[string]string `json:"fields"`
 }
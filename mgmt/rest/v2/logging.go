@@ -0,0 +1,122 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/intelsdi-x/snap/pkg/logging"
+)
+
+// LogLevelsResponse returns the log levels.
+//
+// swagger:response LogLevelsResponse
+type LogLevelsResp struct {
+	// in: body
+	Body LogLevels
+}
+
+// LogLevels represents the log level of snapteld and the log levels set for its modules.
+type LogLevels struct {
+	Level   string            `json:"level"`
+	Modules map[string]string `json:"modules"`
+}
+
+// LogLevelRequest sets a log level.
+type LogLevelRequest struct {
+	// Module is the module the level is set for, e.g. control, the level of snapteld is set when it's empty
+	Module string `json:"module,omitempty"`
+	// Level is debug, info, warning, error, fatal or panic
+	Level string `json:"level"`
+}
+
+// LogLevelParams defines the module.
+//
+// swagger:parameters resetLogLevel
+type LogLevelParams struct {
+	// in: path
+	//
+	// required: true
+	Module string `json:"module"`
+}
+
+// LogLevelPutParams defines the log level set.
+//
+// swagger:parameters setLogLevel
+type LogLevelPutParams struct {
+	// in: body
+	//
+	// required: true
+	Level LogLevelRequest `json:"level"`
+}
+
+func (s *apiV2) getLogLevels(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	if s.logging == nil {
+		Write(404, FromError(ErrLoggingUnavailable), w)
+		return
+	}
+	Write(200, s.logLevels(), w)
+}
+
+func (s *apiV2) setLogLevel(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	if s.logging == nil {
+		Write(404, FromError(ErrLoggingUnavailable), w)
+		return
+	}
+	var req LogLevelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Write(400, FromError(err), w)
+		return
+	}
+	if err := s.logging.SetLevel(req.Module, req.Level); err != nil {
+		Write(400, FromError(err), w)
+		return
+	}
+	restLogger.WithFields(log.Fields{
+		"_block": "set-log-level",
+		"module": req.Module,
+		"level":  req.Level,
+	}).Info("log level set")
+	Write(200, s.logLevels(), w)
+}
+
+func (s *apiV2) resetLogLevel(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	if s.logging == nil {
+		Write(404, FromError(ErrLoggingUnavailable), w)
+		return
+	}
+	if err := s.logging.ResetLevel(p.ByName("module")); err != nil {
+		if err == logging.ErrModuleNotFound {
+			Write(404, FromError(err), w)
+			return
+		}
+		Write(500, FromError(err), w)
+		return
+	}
+	Write(200, s.logLevels(), w)
+}
+
+func (s *apiV2) logLevels() LogLevels {
+	return LogLevels{Level: s.logging.Level(), Modules: s.logging.ModuleLevels()}
+}
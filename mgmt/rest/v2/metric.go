@@ -20,6 +20,7 @@ limitations under the License.
 package v2
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"sort"
@@ -40,6 +41,10 @@ type MetricsResp struct {
 	// in: body
 	Body struct {
 		Metrics []Metric `json:"metrics,omitempty"`
+		// Total number of metrics matching the query, returned for requests paginated by offset.
+		Total int `json:"total,omitempty"`
+		// Cursor of the next page of metrics, returned for requests paginated by cursor.
+		NextCursor string `json:"next_cursor,omitempty"`
 	}
 }
 
@@ -51,17 +56,31 @@ type MetricParams struct {
 	Ns string `json:"ns"`
 	// in: query
 	Ver int `json:"ver"`
+	// Offset of the first metric returned, metrics are ordered by namespace and version.
+	// It cannot be combined with ver.
+	// in: query
+	Offset int `json:"offset"`
+	// Maximum number of metrics returned, 0 means no limit.
+	// It cannot be combined with ver.
+	// in: query
+	Limit int `json:"limit"`
+	// Position after which the metrics are returned, the next_cursor of the previous page.
+	// It cannot be combined with ver and offset.
+	// in: query
+	Cursor string `json:"cursor"`
 }
 
 type MetricsResonse struct {
-	Metrics Metrics `json:"metrics,omitempty"`
+	Metrics    Metrics `json:"metrics,omitempty"`
+	Total      int     `json:"total,omitempty"`
+	NextCursor string  `json:"next_cursor,omitempty"`
 }
 
 type Metrics []Metric
 
 // Metric represents the metric type.
 type Metric struct {
-	LastAdvertisedTimestamp int64 `json:"last_advertised_timestamp,omitempty"`
+	LastAdvertisedTimestamp string `json:"last_advertised_timestamp,omitempty"`
 	//required: true
 	Namespace string `json:"namespace"`
 	Version   int    `json:"version,omitempty"`
@@ -71,6 +90,8 @@ type Metric struct {
 	DynamicElements []DynamicElement `json:"dynamic_elements,omitempty"`
 	Description     string           `json:"description,omitempty"`
 	Unit            string           `json:"unit,omitempty"`
+	// DataType describes the type of the collected data (e.g. "uint64", "float64", "string").
+	DataType string `json:"data_type,omitempty"`
 	// Policy a slice of metric rules.
 	Policy PolicyTableSlice `json:"policy,omitempty"`
 	Href   string           `json:"href"`
@@ -103,6 +124,22 @@ func (s *apiV2) getMetrics(w http.ResponseWriter, r *http.Request, _ httprouter.
 	q := r.URL.Query()
 	v := q.Get("ver")
 	ns_query := q.Get("ns")
+	if q.Get("offset") != "" || q.Get("limit") != "" || q.Get("cursor") != "" {
+		if v != "" {
+			Write(400, FromError(errors.New("ver cannot be combined with offset, limit and cursor")), w)
+			return
+		}
+		if q.Get("offset") == "" {
+			s.getMetricsAfterCursor(w, r)
+			return
+		}
+		if q.Get("cursor") != "" {
+			Write(400, FromError(errors.New("offset cannot be combined with cursor")), w)
+			return
+		}
+		s.getMetricsPage(w, r)
+		return
+	}
 	if ns_query != "" {
 		ver := 0 // 0: get all versions
 		if v != "" {
@@ -113,13 +150,7 @@ func (s *apiV2) getMetrics(w http.ResponseWriter, r *http.Request, _ httprouter.
 				return
 			}
 		}
-		// strip the leading char and split on the remaining.
-		fc := stringutils.GetFirstChar(ns_query)
-		ns := strings.Split(strings.TrimLeft(ns_query, fc), fc)
-		if ns[len(ns)-1] == "*" {
-			ns = ns[:len(ns)-1]
-		}
-
+		ns := parseNamespaceQuery(ns_query)
 		mts, err := s.metricManager.FetchMetrics(core.NewNamespace(ns...), ver)
 		if err != nil {
 			Write(404, FromError(err), w)
@@ -137,25 +168,115 @@ func (s *apiV2) getMetrics(w http.ResponseWriter, r *http.Request, _ httprouter.
 	respondWithMetrics(r.Host, mts, w)
 }
 
+// getMetricsPage responds with a page of metrics selected by the offset and limit
+// query parameters, optionally narrowed to the metrics under the ns query parameter
+func (s *apiV2) getMetricsPage(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	offset, limit := 0, 0
+	var err error
+	if o := q.Get("offset"); o != "" {
+		offset, err = strconv.Atoi(o)
+		if err != nil {
+			Write(400, FromError(err), w)
+			return
+		}
+	}
+	if l := q.Get("limit"); l != "" {
+		limit, err = strconv.Atoi(l)
+		if err != nil {
+			Write(400, FromError(err), w)
+			return
+		}
+	}
+	if offset < 0 || limit < 0 {
+		Write(400, FromError(fmt.Errorf("offset (%d) and limit (%d) cannot be negative", offset, limit)), w)
+		return
+	}
+
+	ns := []string{}
+	if ns_query := q.Get("ns"); ns_query != "" {
+		ns = parseNamespaceQuery(ns_query)
+	}
+	mts, total, err := s.metricManager.FetchMetricsPage(core.NewNamespace(ns...), offset, limit)
+	if err != nil {
+		Write(404, FromError(err), w)
+		return
+	}
+	b := MetricsResonse{Metrics: toMetrics(r.Host, mts), Total: total}
+	Write(200, b, w)
+}
+
+// getMetricsAfterCursor responds with a page of metrics selected by the cursor and
+// limit query parameters, optionally narrowed to the metrics under the ns query parameter
+func (s *apiV2) getMetricsAfterCursor(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	pg, _, err := parsePage(q)
+	if err != nil {
+		Write(400, FromError(err), w)
+		return
+	}
+
+	var mts []core.CatalogedMetric
+	if ns_query := q.Get("ns"); ns_query != "" {
+		mts, err = s.metricManager.FetchMetrics(core.NewNamespace(parseNamespaceQuery(ns_query)...), 0)
+		if err != nil {
+			Write(404, FromError(err), w)
+			return
+		}
+	} else {
+		mts, err = s.metricManager.MetricCatalog()
+		if err != nil {
+			Write(500, FromError(err), w)
+			return
+		}
+	}
+
+	metrics := toMetrics(r.Host, mts)
+	keys := make([]string, len(metrics))
+	for i, m := range metrics {
+		keys[i] = fmt.Sprintf("%s\x00%010d", m.Namespace, m.Version)
+	}
+	start, end, next := pg.bounds(keys, metrics.Swap)
+	Write(200, MetricsResonse{Metrics: metrics[start:end], NextCursor: next}, w)
+}
+
+// parseNamespaceQuery strips the leading char and splits the namespace on the remaining,
+// a trailing asterisk is dropped as all metrics under the namespace are fetched anyway
+func parseNamespaceQuery(ns_query string) []string {
+	fc := stringutils.GetFirstChar(ns_query)
+	ns := strings.Split(strings.TrimLeft(ns_query, fc), fc)
+	if ns[len(ns)-1] == "*" {
+		ns = ns[:len(ns)-1]
+	}
+	return ns
+}
+
 func respondWithMetrics(host string, mts []core.CatalogedMetric, w http.ResponseWriter) {
-	b := MetricsResonse{Metrics: make(Metrics, 0)}
+	b := MetricsResonse{Metrics: toMetrics(host, mts)}
+	Write(200, b, w)
+}
+
+// toMetrics converts cataloged metrics into their sorted REST representation
+func toMetrics(host string, mts []core.CatalogedMetric) Metrics {
+	metrics := make(Metrics, 0)
 	for _, m := range mts {
 		policies := PolicyTableSlice(m.Policy().RulesAsTable())
 		dyn, indexes := m.Namespace().IsDynamic()
-		b.Metrics = append(b.Metrics, Metric{
+		metrics = append(metrics, Metric{
 			Namespace:               m.Namespace().String(),
 			Version:                 m.Version(),
-			LastAdvertisedTimestamp: m.LastAdvertisedTime().Unix(),
+			LastAdvertisedTimestamp: timestamp(m.LastAdvertisedTime()),
 			Description:             m.Description(),
 			Dynamic:                 dyn,
 			DynamicElements:         getDynamicElements(m.Namespace(), indexes),
 			Unit:                    m.Unit(),
+			DataType:                m.DataType(),
 			Policy:                  policies,
 			Href:                    catalogedMetricURI(host, m),
 		})
 	}
-	sort.Sort(b.Metrics)
-	Write(200, b, w)
+	sort.Sort(metrics)
+	return metrics
 }
 
 func catalogedMetricURI(host string, mt core.CatalogedMetric) string {
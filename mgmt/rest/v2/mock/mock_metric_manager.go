@@ -66,6 +66,8 @@ func (m MockLoadedPlugin) Policy() *cpolicy.ConfigPolicy { return cpolicy.New()
 func (m MockLoadedPlugin) HitCount() int                 { return 0 }
 func (m MockLoadedPlugin) LastHit() time.Time            { return time.Now() }
 func (m MockLoadedPlugin) ID() uint32                    { return 0 }
+func (m MockLoadedPlugin) FailedHealthChecks() int       { return 0 }
+func (m MockLoadedPlugin) Healthy() bool                 { return true }
 
 //////MockCatalogedMetric/////
 
@@ -79,6 +81,7 @@ func (m MockCatalogedMetric) LastAdvertisedTime() time.Time     { return time.Ti
 func (m MockCatalogedMetric) Policy() *cpolicy.ConfigPolicyNode { return cpolicy.NewPolicyNode() }
 func (m MockCatalogedMetric) Description() string               { return "This Is A Description" }
 func (m MockCatalogedMetric) Unit() string                      { return "" }
+func (m MockCatalogedMetric) DataType() string                  { return "" }
 
 //////MockManagesMetrics/////
 
@@ -90,6 +93,9 @@ func (m MockManagesMetrics) MetricCatalog() ([]core.CatalogedMetric, error) {
 func (m MockManagesMetrics) FetchMetrics(core.Namespace, int) ([]core.CatalogedMetric, error) {
 	return metricCatalog, nil
 }
+func (m MockManagesMetrics) FetchMetricsPage(core.Namespace, int, int) ([]core.CatalogedMetric, int, error) {
+	return metricCatalog, len(metricCatalog), nil
+}
 func (m MockManagesMetrics) GetMetricVersions(core.Namespace) ([]core.CatalogedMetric, error) {
 	return metricCatalog, nil
 }
@@ -131,6 +137,22 @@ func (m MockManagesMetrics) GetTempDir() string {
 	return ""
 }
 
+func (m MockManagesMetrics) PluginBlacklist() []core.BlacklistedPlugin {
+	return []core.BlacklistedPlugin{}
+}
+
+func (m MockManagesMetrics) ClearPluginBlacklist(checkSum string) serror.SnapError {
+	return nil
+}
+
+func (m MockManagesMetrics) PluginMetadata(plugin core.Plugin) (*core.PluginMetadata, serror.SnapError) {
+	return nil, serror.New(errors.New("plugin not found"))
+}
+
+func (m MockManagesMetrics) ReloadPluginConfig(pluginType core.PluginType, name string, ver int) []serror.SnapError {
+	return nil
+}
+
 // These constants are the expected plugin responses from running
 // rest_v2_test.go on the plugin routes found in mgmt/rest/server.go
 const (
@@ -142,7 +164,7 @@ const (
       "type": "collector",
       "signed": false,
       "status": "",
-      "loaded_timestamp": 1473120000,
+      "loaded_timestamp": "2016-09-06T00:00:00Z",
       "href": "http://localhost:%d/v2/plugins/collector/foo/2"
     },
     {
@@ -151,7 +173,7 @@ const (
       "type": "publisher",
       "signed": false,
       "status": "",
-      "loaded_timestamp": 1473120000,
+      "loaded_timestamp": "2016-09-06T00:00:00Z",
       "href": "http://localhost:%d/v2/plugins/publisher/bar/3"
     },
     {
@@ -160,7 +182,7 @@ const (
       "type": "collector",
       "signed": false,
       "status": "",
-      "loaded_timestamp": 1473120000,
+      "loaded_timestamp": "2016-09-06T00:00:00Z",
       "href": "http://localhost:%d/v2/plugins/collector/foo/4"
     },
     {
@@ -169,7 +191,7 @@ const (
       "type": "publisher",
       "signed": false,
       "status": "",
-      "loaded_timestamp": 1473120000,
+      "loaded_timestamp": "2016-09-06T00:00:00Z",
       "href": "http://localhost:%d/v2/plugins/publisher/baz/5"
     },
     {
@@ -178,7 +200,7 @@ const (
       "type": "processor",
       "signed": false,
       "status": "",
-      "loaded_timestamp": 1473120000,
+      "loaded_timestamp": "2016-09-06T00:00:00Z",
       "href": "http://localhost:%d/v2/plugins/processor/foo/6"
     },
     {
@@ -187,7 +209,7 @@ const (
       "type": "processor",
       "signed": false,
       "status": "",
-      "loaded_timestamp": 1473120000,
+      "loaded_timestamp": "2016-09-06T00:00:00Z",
       "href": "http://localhost:%d/v2/plugins/processor/foobar/1"
     }
   ]
@@ -202,7 +224,7 @@ const (
       "type": "collector",
       "signed": false,
       "status": "",
-      "loaded_timestamp": 1473120000,
+      "loaded_timestamp": "2016-09-06T00:00:00Z",
       "href": "http://localhost:%d/v2/plugins/collector/foo/2"
     },
     {
@@ -211,7 +233,7 @@ const (
       "type": "collector",
       "signed": false,
       "status": "",
-      "loaded_timestamp": 1473120000,
+      "loaded_timestamp": "2016-09-06T00:00:00Z",
       "href": "http://localhost:%d/v2/plugins/collector/foo/4"
     }
   ]
@@ -226,7 +248,7 @@ const (
       "type": "publisher",
       "signed": false,
       "status": "",
-      "loaded_timestamp": 1473120000,
+      "loaded_timestamp": "2016-09-06T00:00:00Z",
       "href": "http://localhost:%d/v2/plugins/publisher/bar/3"
     }
   ]
@@ -239,7 +261,7 @@ const (
   "type": "publisher",
   "signed": false,
   "status": "",
-  "loaded_timestamp": 1473120000,
+  "loaded_timestamp": "2016-09-06T00:00:00Z",
   "href": "http://localhost:%d/v2/plugins/publisher/bar/3"
 }
 `
@@ -247,7 +269,6 @@ const (
 	GET_METRICS_RESPONSE = `{
   "metrics": [
     {
-      "last_advertised_timestamp": -62135596800,
       "namespace": "/one/two/three",
       "version": 5,
       "dynamic": false,
@@ -256,6 +277,20 @@ const (
     }
   ]
 }
+`
+
+	GET_METRICS_PAGE_RESPONSE = `{
+  "metrics": [
+    {
+      "namespace": "/one/two/three",
+      "version": 5,
+      "dynamic": false,
+      "description": "This Is A Description",
+      "href": "http://localhost:%d/v2/metrics?ns=/one/two/three&ver=5"
+    }
+  ],
+  "total": 1
+}
 `
 
 	UNLOAD_PLUGIN_RESPONSE = ``
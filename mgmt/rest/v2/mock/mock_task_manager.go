@@ -21,6 +21,7 @@ limitations under the License.
 package mock
 
 import (
+	"errors"
 	"time"
 
 	"github.com/intelsdi-x/snap/core"
@@ -67,26 +68,43 @@ type mockTask struct {
 	MyHref               string            `json:"href"`
 }
 
-func (t *mockTask) ID() string                          { return t.MyID }
-func (t *mockTask) State() core.TaskState               { return core.TaskSpinning }
-func (t *mockTask) HitCount() uint                      { return 0 }
-func (t *mockTask) GetName() string                     { return t.MyName }
-func (t *mockTask) SetName(string)                      { return }
-func (t *mockTask) SetID(string)                        { return }
-func (t *mockTask) MissedCount() uint                   { return 0 }
-func (t *mockTask) FailedCount() uint                   { return 0 }
-func (t *mockTask) LastFailureMessage() string          { return "" }
-func (t *mockTask) LastRunTime() *time.Time             { return &time.Time{} }
-func (t *mockTask) CreationTime() *time.Time            { return &time.Time{} }
-func (t *mockTask) DeadlineDuration() time.Duration     { return 4 }
-func (t *mockTask) SetDeadlineDuration(time.Duration)   { return }
-func (t *mockTask) SetTaskID(id string)                 { return }
-func (t *mockTask) SetStopOnFailure(int)                { return }
-func (t *mockTask) GetStopOnFailure() int               { return 0 }
-func (t *mockTask) MaxCollectDuration() time.Duration   { return time.Second }
-func (t *mockTask) SetMaxCollectDuration(time.Duration) {}
-func (t *mockTask) MaxMetricsBuffer() int64             { return 0 }
-func (t *mockTask) SetMaxMetricsBuffer(int64)           {}
+func (t *mockTask) ID() string                                            { return t.MyID }
+func (t *mockTask) State() core.TaskState                                 { return core.TaskSpinning }
+func (t *mockTask) HitCount() uint                                        { return 0 }
+func (t *mockTask) GetName() string                                       { return t.MyName }
+func (t *mockTask) SetName(string)                                        { return }
+func (t *mockTask) SetID(string)                                          { return }
+func (t *mockTask) MissedCount() uint                                     { return 0 }
+func (t *mockTask) FailedCount() uint                                     { return 0 }
+func (t *mockTask) LastFailureMessage() string                            { return "" }
+func (t *mockTask) LastRunTime() *time.Time                               { return &time.Time{} }
+func (t *mockTask) CreationTime() *time.Time                              { return &time.Time{} }
+func (t *mockTask) DeadlineDuration() time.Duration                       { return 4 }
+func (t *mockTask) SetDeadlineDuration(time.Duration)                     { return }
+func (t *mockTask) SetTaskID(id string)                                   { return }
+func (t *mockTask) SetStopOnFailure(int)                                  { return }
+func (t *mockTask) GetStopOnFailure() int                                 { return 0 }
+func (t *mockTask) MaxCollectDuration() time.Duration                     { return time.Second }
+func (t *mockTask) SetMaxCollectDuration(time.Duration)                   {}
+func (t *mockTask) MaxMetricsBuffer() int64                               { return 0 }
+func (t *mockTask) SetMaxMetricsBuffer(int64)                             {}
+func (t *mockTask) Dependencies() []string                                { return nil }
+func (t *mockTask) SetDependencies([]string)                              {}
+func (t *mockTask) DependencyStates() map[string]core.TaskDependencyState { return nil }
+func (t *mockTask) RunDeadline() time.Duration                            { return 0 }
+func (t *mockTask) SetRunDeadline(time.Duration)                          {}
+func (t *mockTask) OverrunPolicy() string                                 { return "" }
+func (t *mockTask) SetOverrunPolicy(string)                               {}
+func (t *mockTask) Priority() string                                      { return "" }
+func (t *mockTask) SetPriority(string)                                    {}
+func (t *mockTask) Labels() map[string]string                             { return nil }
+func (t *mockTask) SetLabels(map[string]string)                           {}
+func (t *mockTask) OverrunCount() uint                                    { return 0 }
+func (t *mockTask) RetryStats() []core.TaskStepRetryStats                 { return nil }
+func (t *mockTask) CircuitBreakers() []core.TaskStepCircuitBreaker        { return nil }
+func (t *mockTask) BatchStats() []core.TaskStepBatchStats                 { return nil }
+func (t *mockTask) Buffer() *core.TaskBuffer                              { return nil }
+func (t *mockTask) SetBuffer(*core.TaskBuffer)                            {}
 func (t *mockTask) Option(...core.TaskOption) core.TaskOption {
 	return core.TaskDeadlineDuration(0)
 }
@@ -132,9 +150,11 @@ func (m *MockTaskManager) CreateTask(
 func (m *MockTaskManager) GetTasks() map[string]core.Task {
 	return taskCatalog
 }
-func (m *MockTaskManager) StartTask(id string) []serror.SnapError { return nil }
-func (m *MockTaskManager) StopTask(id string) []serror.SnapError  { return nil }
-func (m *MockTaskManager) RemoveTask(id string) error             { return nil }
+func (m *MockTaskManager) StartTask(id string) []serror.SnapError  { return nil }
+func (m *MockTaskManager) StopTask(id string) []serror.SnapError   { return nil }
+func (m *MockTaskManager) PauseTask(id string) []serror.SnapError  { return nil }
+func (m *MockTaskManager) ResumeTask(id string) []serror.SnapError { return nil }
+func (m *MockTaskManager) RemoveTask(id string) error              { return nil }
 func (m *MockTaskManager) WatchTask(id string, handler core.TaskWatcherHandler) (core.TaskWatcherCloser, error) {
 	return nil, nil
 }
@@ -149,6 +169,69 @@ func (m *MockTaskManager) EnableTask(id string) (core.Task, error) {
 		MyState:             "failed",
 		MyHref:              "http://localhost:8181/v2/tasks/alskdjf"}, nil
 }
+func (m *MockTaskManager) RunTask(wfMap *wmap.WorkflowMap, timeout time.Duration, publish bool, opts ...core.TaskOption) (*core.TaskRun, []serror.SnapError) {
+	return &core.TaskRun{}, nil
+}
+func (m *MockTaskManager) NewTaskRunner(wfMap *wmap.WorkflowMap, timeout time.Duration, publish bool, opts ...core.TaskOption) (core.TaskRunner, []serror.SnapError) {
+	return &mockTaskRunner{}, nil
+}
+func (m *MockTaskManager) WorkerPoolStats() []core.WorkerPoolStats {
+	return []core.WorkerPoolStats{
+		{
+			Pool:         "collect",
+			Workers:      6,
+			MinWorkers:   4,
+			MaxWorkers:   16,
+			Autoscale:    true,
+			QueueDepth:   2,
+			QueueLength:  25,
+			QueueLatency: 150 * time.Millisecond,
+		},
+	}
+}
+func (m *MockTaskManager) BulkTaskOperation(action string, sel core.TaskSelector) ([]core.TaskBulkResult, serror.SnapError) {
+	results := []core.TaskBulkResult{
+		{ID: "qwertyuiop", Name: "TASK1.0", State: core.TaskSpinning},
+		{ID: "asdfghjkl", Name: "TASK2.0", State: core.TaskStopped},
+	}
+	if action == core.TaskBulkRemove {
+		results[0].Error = serror.New(errors.New("Task must be stopped"))
+		return results, serror.New(core.ErrTaskBulkAborted)
+	}
+	return results, nil
+}
+func (m *MockTaskManager) DeadLetters() ([]core.DeadLetter, serror.SnapError) {
+	return []core.DeadLetter{
+		{
+			ID:            "00000001447997141000-batch",
+			TaskID:        "qwertyuiop",
+			TaskName:      "TASK1.0",
+			PluginName:    "influxdb",
+			PluginVersion: 1,
+			Errors:        []string{"connection refused"},
+			Timestamp:     time.Unix(1447997141, 0),
+			MetricCount:   2,
+		},
+	}, nil
+}
+func (m *MockTaskManager) GetDeadLetter(id string) (core.DeadLetter, serror.SnapError) {
+	return core.DeadLetter{}, serror.New(errors.New("Dead letter not found"))
+}
+func (m *MockTaskManager) ReplayDeadLetters(ids []string) ([]core.DeadLetterResult, serror.SnapError) {
+	results := make([]core.DeadLetterResult, len(ids))
+	for i, id := range ids {
+		results[i].ID = id
+	}
+	return results, nil
+}
+func (m *MockTaskManager) PurgeDeadLetters(ids []string) ([]core.DeadLetterResult, serror.SnapError) {
+	return nil, serror.New(errors.New("Dead letter queue is not enabled"))
+}
+
+type mockTaskRunner struct{}
+
+func (r *mockTaskRunner) Run() (*core.TaskRun, []serror.SnapError) { return &core.TaskRun{}, nil }
+func (r *mockTaskRunner) Close()                                   {}
 
 // Mock task used in the 'Add tasks' test in rest_v2_test.go
 const TASK = `{
@@ -171,14 +254,94 @@ const TASK = `{
 // These constants are the expected responses from running the task tests in
 // rest_v2_test.go on the task routes found in mgmt/rest/server.go
 const (
+	GET_WORKER_POOLS_RESPONSE = `{
+  "pools": [
+    {
+      "pool": "collect",
+      "workers": 6,
+      "min_workers": 4,
+      "max_workers": 16,
+      "autoscale": true,
+      "queue_depth": 2,
+      "queue_length": 25,
+      "queue_latency": "150ms"
+    }
+  ]
+}
+`
+
+	BULK_START_TASKS_RESPONSE = `{
+  "tasks": [
+    {
+      "id": "qwertyuiop",
+      "name": "TASK1.0",
+      "task_state": "Running"
+    },
+    {
+      "id": "asdfghjkl",
+      "name": "TASK2.0",
+      "task_state": "Stopped"
+    }
+  ]
+}
+`
+
+	BULK_REMOVE_TASKS_RESPONSE = `{
+  "tasks": [
+    {
+      "id": "qwertyuiop",
+      "name": "TASK1.0",
+      "task_state": "Running",
+      "error": {
+        "message": "Task must be stopped",
+        "fields": {}
+      }
+    },
+    {
+      "id": "asdfghjkl",
+      "name": "TASK2.0",
+      "task_state": "Stopped"
+    }
+  ]
+}
+`
+
+	GET_DEAD_LETTERS_RESPONSE = `{
+  "dead_letters": [
+    {
+      "id": "00000001447997141000-batch",
+      "task_id": "qwertyuiop",
+      "task_name": "TASK1.0",
+      "plugin_name": "influxdb",
+      "plugin_version": 1,
+      "errors": [
+        "connection refused"
+      ],
+      "timestamp": "2015-11-20T05:25:41Z",
+      "metric_count": 2
+    }
+  ]
+}
+`
+
+	REPLAY_DEAD_LETTERS_RESPONSE = `{
+  "dead_letters": [
+    {
+      "id": "a"
+    },
+    {
+      "id": "b"
+    }
+  ]
+}
+`
+
 	GET_TASKS_RESPONSE = `{
   "tasks": [
     {
       "id": "qwertyuiop",
       "name": "TASK1.0",
       "deadline": "4ns",
-      "creation_timestamp": -62135596800,
-      "last_run_timestamp": -1,
       "task_state": "Running",
       "href": "http://localhost:%d/v2/tasks/qwertyuiop"
     },
@@ -186,8 +349,6 @@ const (
       "id": "asdfghjkl",
       "name": "TASK2.0",
       "deadline": "4ns",
-      "creation_timestamp": -62135596800,
-      "last_run_timestamp": -1,
       "task_state": "Running",
       "href": "http://localhost:%d/v2/tasks/asdfghjkl"
     }
@@ -201,8 +362,6 @@ const (
       "id": "asdfghjkl",
       "name": "TASK2.0",
       "deadline": "4ns",
-      "creation_timestamp": -62135596800,
-      "last_run_timestamp": -1,
       "task_state": "Running",
       "href": "http://localhost:%d/v2/tasks/asdfghjkl"
     },
@@ -210,8 +369,6 @@ const (
       "id": "qwertyuiop",
       "name": "TASK1.0",
       "deadline": "4ns",
-      "creation_timestamp": -62135596800,
-      "last_run_timestamp": -1,
       "task_state": "Running",
       "href": "http://localhost:%d/v2/tasks/qwertyuiop"
     }
@@ -232,8 +389,6 @@ const (
     "type": "windowed",
     "interval": "1s"
   },
-  "creation_timestamp": -62135596800,
-  "last_run_timestamp": -1,
   "task_state": "Running",
   "href": "http://localhost:%d/v2/tasks/:1234"
 }
@@ -252,8 +407,6 @@ const (
     "type": "windowed",
     "interval": "1s"
   },
-  "creation_timestamp": -62135596800,
-  "last_run_timestamp": -1,
   "task_state": "Running",
   "href": "http://localhost:%d/v2/tasks/MyTaskID"
 }
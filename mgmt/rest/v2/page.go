@@ -0,0 +1,113 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+)
+
+// PageParams defines the page of a list of plugins or tasks.
+//
+// swagger:parameters getPlugins getTasks
+type PageParams struct {
+	// Maximum number of items returned, 0 means no limit.
+	// in: query
+	Limit int `json:"limit"`
+	// Position after which the items are returned, the next_cursor of the previous page.
+	// in: query
+	Cursor string `json:"cursor"`
+}
+
+// page selects the items of a list following the item of its cursor, the
+// items are ordered by their keys
+type page struct {
+	// after is the key of the last item of the previous page, empty for the
+	// first page
+	after string
+	// limit is zero when all the items following the cursor are returned
+	limit int
+}
+
+// parsePage returns the page selected by the limit and cursor query
+// parameters, false when neither is given and the whole list is returned
+func parsePage(q url.Values) (page, bool, error) {
+	p := page{}
+	l, c := q.Get("limit"), q.Get("cursor")
+	if l == "" && c == "" {
+		return p, false, nil
+	}
+	if l != "" {
+		var err error
+		if p.limit, err = strconv.Atoi(l); err != nil {
+			return p, false, err
+		}
+		if p.limit < 0 {
+			return p, false, fmt.Errorf("limit (%d) cannot be negative", p.limit)
+		}
+	}
+	if c != "" {
+		after, err := base64.RawURLEncoding.DecodeString(c)
+		if err != nil || len(after) == 0 {
+			return p, false, ErrInvalidCursor
+		}
+		p.after = string(after)
+	}
+	return p, true, nil
+}
+
+// bounds sorts the list by the keys of its items and returns the bounds of
+// the page in the list, along with the cursor of the next page which is empty
+// on the last page
+func (p page) bounds(keys []string, swap func(i, j int)) (int, int, string) {
+	sort.Sort(byKeys{keys: keys, swap: swap})
+	start := sort.Search(len(keys), func(i int) bool { return keys[i] > p.after })
+	end := len(keys)
+	if p.limit > 0 && start+p.limit < end {
+		end = start + p.limit
+	}
+	var next string
+	if end < len(keys) {
+		next = base64.RawURLEncoding.EncodeToString([]byte(keys[end-1]))
+	}
+	return start, end, next
+}
+
+// byKeys sorts a list along with the keys of its items
+type byKeys struct {
+	keys []string
+	swap func(i, j int)
+}
+
+func (b byKeys) Len() int {
+	return len(b.keys)
+}
+
+func (b byKeys) Less(i, j int) bool {
+	return b.keys[i] < b.keys[j]
+}
+
+func (b byKeys) Swap(i, j int) {
+	b.keys[i], b.keys[j] = b.keys[j], b.keys[i]
+	b.swap(i, j)
+}
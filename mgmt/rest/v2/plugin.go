@@ -23,6 +23,7 @@ import (
 	"bytes"
 	"compress/gzip"
 	"crypto/sha256"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -38,6 +39,7 @@ import (
 	"github.com/intelsdi-x/snap/control"
 	"github.com/intelsdi-x/snap/core"
 	"github.com/intelsdi-x/snap/core/serror"
+	"github.com/intelsdi-x/snap/pkg/psigning"
 	"github.com/julienschmidt/httprouter"
 )
 
@@ -60,27 +62,56 @@ type PluginsResp struct {
 	// in: body
 	Body struct {
 		Plugins []Plugin `json:"plugins,omitempty"`
+		// Cursor of the next page of plugins, returned for paginated requests.
+		NextCursor string `json:"next_cursor,omitempty"`
 	}
 }
 
 type PluginsResponse struct {
-	Plugins []Plugin `json:"plugins,omitempty"`
+	Plugins    []Plugin `json:"plugins,omitempty"`
+	NextCursor string   `json:"next_cursor,omitempty"`
 }
 
 // Plugin represents a plugin type definition.
 type Plugin struct {
-	Name             string        `json:"name"`
-	Version          int           `json:"version"`
-	Type             string        `json:"type"`
-	Signed           bool          `json:"signed"`
-	Status           string        `json:"status"`
-	LoadedTimestamp  int64         `json:"loaded_timestamp,omitempty"`
-	Href             string        `json:"href,omitempty"`
-	ConfigPolicy     []PolicyTable `json:"config_policy,omitempty"`
-	HitCount         int           `json:"hitcount,omitempty"`
-	LastHitTimestamp int64         `json:"last_hit_timestamp,omitempty"`
-	ID               uint32        `json:"id,omitempty"`
-	PprofPort        string        `json:"pprof_port,omitempty"`
+	Name               string        `json:"name"`
+	Version            int           `json:"version"`
+	Type               string        `json:"type"`
+	Signed             bool          `json:"signed"`
+	Status             string        `json:"status"`
+	LoadedTimestamp    string        `json:"loaded_timestamp,omitempty"`
+	Href               string        `json:"href,omitempty"`
+	ConfigPolicy       []PolicyTable `json:"config_policy,omitempty"`
+	HitCount           int           `json:"hitcount,omitempty"`
+	LastHitTimestamp   string        `json:"last_hit_timestamp,omitempty"`
+	ID                 uint32        `json:"id,omitempty"`
+	PprofPort          string        `json:"pprof_port,omitempty"`
+	Health             string        `json:"health,omitempty"`
+	FailedHealthChecks int           `json:"failed_health_checks,omitempty"`
+	Pool               *PluginPool   `json:"pool,omitempty"`
+	Details            *PluginDetail `json:"details,omitempty"`
+}
+
+// PluginDetail represents the details of a loaded plugin served by the plugin
+// detail view.
+type PluginDetail struct {
+	Path              string   `json:"path"`
+	CheckSum          string   `json:"checksum,omitempty"`
+	RPCType           string   `json:"rpc_type"`
+	MetricCount       int      `json:"metric_count"`
+	ConfigPolicyRules int      `json:"config_policy_rules"`
+	RequiredConfig    []string `json:"required_config"`
+	PIDs              []int    `json:"pids"`
+	Calls             uint64   `json:"calls"`
+	Errors            uint64   `json:"errors"`
+}
+
+// PluginPool represents the settings of the pool of running instances of a
+// plugin set through the plugin config.
+type PluginPool struct {
+	MaxInstances int    `json:"max_instances,omitempty"`
+	IdleTimeout  string `json:"idle_timeout,omitempty"`
+	Routing      string `json:"routing,omitempty"`
 }
 
 // PluginParams represents the request path plugin name, version and type.
@@ -187,13 +218,13 @@ func (s *apiV2) loadPlugin(w http.ResponseWriter, r *http.Request, _ httprouter.
 			// First file passed in should be the plugin. If the first file is a signature
 			// file, an error is returned. The signature file should be the second
 			// file passed to the API server. If the second file does not have the ".asc"
-			// extension, an error is returned.
+			// or ".sig" extension, an error is returned.
 			// If we loop around more than twice before receiving io.EOF, then
 			// an error is returned.
 
 			switch {
 			case i == 0:
-				if filepath.Ext(p.FileName()) == ".asc" {
+				if psigning.IsSignatureFile(p.FileName()) {
 					e := errors.New("Error: first file passed to load plugin api can not be signature file")
 					Write(400, FromError(e), w)
 					return
@@ -204,7 +235,7 @@ func (s *apiV2) loadPlugin(w http.ResponseWriter, r *http.Request, _ httprouter.
 				}
 				checkSum = sha256.Sum256(b)
 			case i == 1:
-				if filepath.Ext(p.FileName()) == ".asc" {
+				if psigning.IsSignatureFile(p.FileName()) {
 					signature = b
 				} else {
 					e := errors.New("Error: second file passed was not a signature file")
@@ -239,8 +270,44 @@ func (s *apiV2) loadPlugin(w http.ResponseWriter, r *http.Request, _ httprouter.
 			}
 			rb := FromError(err)
 			switch rb.ErrorMessage {
-			case ErrPluginAlreadyLoaded:
+			case ErrPluginAlreadyLoaded, control.ErrPluginBlacklisted.Error():
+				ec = 409
+			default:
+				ec = 500
+			}
+			Write(ec, rb, w)
+			return
+		}
+		Write(201, catalogedPluginBody(r.Host, pl), w)
+	} else if strings.HasSuffix(mediaType, "json") {
+		var req struct {
+			URL      string `json:"url"`
+			CheckSum string `json:"checksum"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			Write(400, FromError(err), w)
+			return
+		}
+		if req.URL == "" {
+			Write(400, FromError(errors.New("missing plugin url")), w)
+			return
+		}
+		rp, err := core.NewDownloadRequestedPlugin(req.URL, req.CheckSum)
+		if err != nil {
+			Write(400, FromError(err), w)
+			return
+		}
+		restLogger.Info("Loading plugin: ", req.URL)
+		pl, err := s.metricManager.Load(rp)
+		if err != nil {
+			var ec int
+			restLogger.Error(err)
+			rb := FromError(err)
+			switch rb.ErrorMessage {
+			case ErrPluginAlreadyLoaded, control.ErrPluginBlacklisted.Error():
 				ec = 409
+			case control.ErrDownloadCheckSumRequired.Error():
+				ec = 400
 			default:
 				ec = 500
 			}
@@ -248,6 +315,8 @@ func (s *apiV2) loadPlugin(w http.ResponseWriter, r *http.Request, _ httprouter.
 			return
 		}
 		Write(201, catalogedPluginBody(r.Host, pl), w)
+	} else {
+		Write(415, FromError(fmt.Errorf("unsupported media type: %s", mediaType)), w)
 	}
 }
 
@@ -304,6 +373,11 @@ func (s *apiV2) getPlugins(w http.ResponseWriter, r *http.Request, params httpro
 
 	// filter by plugin name or plugin type
 	q := r.URL.Query()
+	pg, paginated, err := parsePage(q)
+	if err != nil {
+		Write(400, FromError(err), w)
+		return
+	}
 	plName := q.Get("name")
 	plType := q.Get("type")
 	nbFilter := Btoi(plName != "") + Btoi(plType != "")
@@ -327,7 +401,19 @@ func (s *apiV2) getPlugins(w http.ResponseWriter, r *http.Request, params httpro
 	} else {
 		filteredPlugins = plugins
 	}
-	Write(200, PluginsResponse{Plugins: filteredPlugins}, w)
+	if !paginated {
+		Write(200, PluginsResponse{Plugins: filteredPlugins}, w)
+		return
+	}
+
+	keys := make([]string, len(filteredPlugins))
+	for i, p := range filteredPlugins {
+		keys[i] = fmt.Sprintf("%s:%s:%010d", p.Type, p.Name, p.Version)
+	}
+	start, end, next := pg.bounds(keys, func(i, j int) {
+		filteredPlugins[i], filteredPlugins[j] = filteredPlugins[j], filteredPlugins[i]
+	})
+	Write(200, PluginsResponse{Plugins: filteredPlugins[start:end], NextCursor: next}, w)
 }
 
 func Btoi(b bool) int {
@@ -352,7 +438,7 @@ func catalogedPluginBody(host string, c core.CatalogedPlugin) Plugin {
 		Type:            c.TypeName(),
 		Signed:          c.IsSigned(),
 		Status:          c.Status(),
-		LoadedTimestamp: c.LoadedTimestamp().Unix(),
+		LoadedTimestamp: timestamp(*c.LoadedTimestamp()),
 		Href:            pluginURI(host, c),
 	}
 }
@@ -361,19 +447,69 @@ func runningPluginsBody(host string, c []core.AvailablePlugin) []Plugin {
 	plugins := make([]Plugin, len(c))
 	for i, p := range c {
 		plugins[i] = Plugin{
-			Name:             p.Name(),
-			Version:          p.Version(),
-			Type:             p.TypeName(),
-			HitCount:         p.HitCount(),
-			LastHitTimestamp: p.LastHit().Unix(),
-			ID:               p.ID(),
-			Href:             pluginURI(host, p),
-			PprofPort:        p.Port(),
+			Name:               p.Name(),
+			Version:            p.Version(),
+			Type:               p.TypeName(),
+			HitCount:           p.HitCount(),
+			LastHitTimestamp:   timestamp(p.LastHit()),
+			ID:                 p.ID(),
+			Href:               pluginURI(host, p),
+			PprofPort:          p.Port(),
+			Health:             pluginHealth(p),
+			FailedHealthChecks: p.FailedHealthChecks(),
 		}
 	}
 	return plugins
 }
 
+func pluginHealth(p core.AvailablePlugin) string {
+	if p.Healthy() {
+		return "healthy"
+	}
+	return "unhealthy"
+}
+
+// pluginPool returns the pool settings set in the plugin config, nil if none is set
+func (s *apiV2) pluginPool(p core.Plugin) *PluginPool {
+	typ, err := core.ToPluginType(p.TypeName())
+	if err != nil || s.configManager == nil {
+		return nil
+	}
+	cdn := s.configManager.GetPluginConfigDataNode(typ, p.Name(), p.Version())
+	cfg, _ := core.NewPluginPoolConfig(&cdn)
+	if cfg.IsEmpty() {
+		return nil
+	}
+	pool := &PluginPool{
+		MaxInstances: cfg.MaxInstances,
+		Routing:      cfg.Routing,
+	}
+	if cfg.IdleTimeout > 0 {
+		pool.IdleTimeout = cfg.IdleTimeout.String()
+	}
+	return pool
+}
+
+// pluginDetail returns the details of the loaded plugin, nil when they are
+// not available
+func (s *apiV2) pluginDetail(p core.Plugin) *PluginDetail {
+	md, se := s.metricManager.PluginMetadata(p)
+	if se != nil || md == nil {
+		return nil
+	}
+	return &PluginDetail{
+		Path:              md.Path,
+		CheckSum:          md.CheckSum,
+		RPCType:           md.RPCType,
+		MetricCount:       md.MetricCount,
+		ConfigPolicyRules: md.ConfigPolicyRules,
+		RequiredConfig:    md.RequiredConfig,
+		PIDs:              md.PIDs,
+		Calls:             md.Calls,
+		Errors:            md.Errors,
+	}
+}
+
 func pluginURI(host string, c core.Plugin) string {
 	return fmt.Sprintf("%s://%s/%s/plugins/%s/%s/%d", protocolPrefix, host, version, c.TypeName(), c.Name(), c.Version())
 }
@@ -449,9 +585,11 @@ func (s *apiV2) getPlugin(w http.ResponseWriter, r *http.Request, p httprouter.P
 			Type:            plugin.TypeName(),
 			Signed:          plugin.IsSigned(),
 			Status:          plugin.Status(),
-			LoadedTimestamp: plugin.LoadedTimestamp().Unix(),
+			LoadedTimestamp: timestamp(*plugin.LoadedTimestamp()),
 			Href:            pluginURI(r.Host, plugin),
 			ConfigPolicy:    configPolicy,
+			Pool:            s.pluginPool(plugin),
+			Details:         s.pluginDetail(plugin),
 		}
 		Write(200, pluginRet, w)
 	}
@@ -0,0 +1,74 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"net/http"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/julienschmidt/httprouter"
+)
+
+// WorkerPoolsResp represents the response from the worker pools operation.
+//
+// swagger:response WorkerPoolsResponse
+type WorkerPoolsResp struct {
+	// in: body
+	Body struct {
+		Pools []WorkerPool `json:"pools"`
+	}
+}
+
+type WorkerPoolsResponse struct {
+	Pools []WorkerPool `json:"pools"`
+}
+
+// WorkerPool represents a worker pool of the scheduler.
+type WorkerPool struct {
+	Pool         string `json:"pool"`
+	Workers      uint   `json:"workers"`
+	MinWorkers   uint   `json:"min_workers"`
+	MaxWorkers   uint   `json:"max_workers"`
+	Autoscale    bool   `json:"autoscale"`
+	QueueDepth   uint   `json:"queue_depth"`
+	QueueLength  uint   `json:"queue_length"`
+	QueueLatency string `json:"queue_latency"`
+}
+
+func (s *apiV2) getWorkerPools(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	Write(200, WorkerPoolsResponse{Pools: workerPoolsBody(s.taskManager.WorkerPoolStats())}, w)
+}
+
+func workerPoolsBody(stats []core.WorkerPoolStats) []WorkerPool {
+	pools := make([]WorkerPool, len(stats))
+	for i, ps := range stats {
+		pools[i] = WorkerPool{
+			Pool:         ps.Pool,
+			Workers:      ps.Workers,
+			MinWorkers:   ps.MinWorkers,
+			MaxWorkers:   ps.MaxWorkers,
+			Autoscale:    ps.Autoscale,
+			QueueDepth:   ps.QueueDepth,
+			QueueLength:  ps.QueueLength,
+			QueueLatency: ps.QueueLatency.String(),
+		}
+	}
+	return pools
+}
@@ -33,6 +33,10 @@ import (
 	"github.com/julienschmidt/httprouter"
 )
 
+// taskKeyFormat formats the creation time of the tasks in their page keys,
+// the keys of the tasks sort in the order of creation
+const taskKeyFormat = "2006-01-02T15:04:05.000000000"
+
 // TasksResponse returns a list of created tasks.
 //
 // swagger:response TasksResponse
@@ -40,6 +44,8 @@ type TasksResp struct {
 	// in: body
 	Body struct {
 		Tasks Tasks `json:"tasks"`
+		// Cursor of the next page of tasks, returned for paginated requests.
+		NextCursor string `json:"next_cursor,omitempty"`
 	}
 }
 
@@ -60,7 +66,8 @@ type RemoveTaskError struct {
 }
 
 type TasksResponse struct {
-	Tasks Tasks `json:"tasks"`
+	Tasks      Tasks  `json:"tasks"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // TaskParam defines the API path task id.
@@ -72,6 +79,16 @@ type TaskParam struct {
 	ID string `json:"id"`
 }
 
+// TaskListParams selects the listed tasks.
+//
+// swagger:parameters getTasks
+type TaskListParams struct {
+	// Only the tasks with all the labels, e.g. "team=storage,env=prod".
+	//
+	// in: query
+	Labels string `json:"labels"`
+}
+
 // TaskPostParams defines task POST and PUT string representation content.
 //
 // swagger:parameters addTask
@@ -88,7 +105,7 @@ type TaskPostParams struct {
 //
 // swagger:parameters updateTaskState
 type TaskPutParams struct {
-	// Update the state of a task
+	// Update the state of a task: enable, start, stop, pause or resume
 	//
 	// in: query
 	//
@@ -96,24 +113,144 @@ type TaskPutParams struct {
 	Action string `json:"action"`
 }
 
+// TaskRunParams defines the parameters of a task run.
+//
+// swagger:parameters runTask
+type TaskRunParams struct {
+	// The task manifest holding the workflow to run, its schedule is ignored.
+	//
+	// in: body
+	//
+	// required: true
+	Task Task `json:"task"`
+	// The time the run may take, 30s by default.
+	//
+	// in: query
+	Timeout string `json:"timeout"`
+	// Publish the metrics with the publishers of the workflow.
+	//
+	// in: query
+	Publish bool `json:"publish"`
+}
+
+// TaskRunResponse returns the metrics of a task run.
+//
+// swagger:response TaskRunResponse
+type TaskRunResp struct {
+	// in: body
+	TaskRun TaskRun `json:"task_run"`
+}
+
+// TaskRun represents the metrics of a workflow run a single time.
+type TaskRun struct {
+	Collected StreamedMetrics    `json:"collected"`
+	Published []TaskRunPublisher `json:"published"`
+	Errors    []*Error           `json:"errors,omitempty"`
+}
+
+// TaskRunPublisher represents the metrics passed to a publisher in a task run.
+type TaskRunPublisher struct {
+	Name    string          `json:"name"`
+	Version int             `json:"version"`
+	Metrics StreamedMetrics `json:"metrics"`
+}
+
+// DefaultTaskRunTimeout is the time a task run may take when no timeout is requested.
+var DefaultTaskRunTimeout = 30 * time.Second
+
 // Task represents Snap task definition.
 type Task struct {
-	ID                 string            `json:"id,omitempty"`
-	Name               string            `json:"name,omitempty"`
-	Version            int               `json:"version,omitempty"`
-	Deadline           string            `json:"deadline,omitempty"`
-	Workflow           *wmap.WorkflowMap `json:"workflow,omitempty"`
-	Schedule           *core.Schedule    `json:"schedule,omitempty"`
-	CreationTimestamp  int64             `json:"creation_timestamp,omitempty"`
-	LastRunTimestamp   int64             `json:"last_run_timestamp,omitempty"`
-	HitCount           int               `json:"hit_count,omitempty"`
-	MissCount          int               `json:"miss_count,omitempty"`
-	FailedCount        int               `json:"failed_count,omitempty"`
-	LastFailureMessage string            `json:"last_failure_message,omitempty"`
-	TaskState          string            `json:"task_state,omitempty"`
-	Href               string            `json:"href,omitempty"`
-	Start              bool              `json:"start,omitempty"`
-	MaxFailures        int               `json:"max-failures,omitempty"`
+	ID                 string               `json:"id,omitempty"`
+	Name               string               `json:"name,omitempty"`
+	Version            int                  `json:"version,omitempty"`
+	Deadline           string               `json:"deadline,omitempty"`
+	Workflow           *wmap.WorkflowMap    `json:"workflow,omitempty"`
+	Schedule           *core.Schedule       `json:"schedule,omitempty"`
+	CreationTimestamp  string               `json:"creation_timestamp,omitempty"`
+	LastRunTimestamp   string               `json:"last_run_timestamp,omitempty"`
+	HitCount           int                  `json:"hit_count,omitempty"`
+	MissCount          int                  `json:"miss_count,omitempty"`
+	FailedCount        int                  `json:"failed_count,omitempty"`
+	LastFailureMessage string               `json:"last_failure_message,omitempty"`
+	TaskState          string               `json:"task_state,omitempty"`
+	Href               string               `json:"href,omitempty"`
+	Start              bool                 `json:"start,omitempty"`
+	MaxFailures        int                  `json:"max-failures,omitempty"`
+	DependsOn          []string             `json:"depends-on,omitempty"`
+	DependencyStates   map[string]string    `json:"dependency_states,omitempty"`
+	RunDeadline        string               `json:"run-deadline,omitempty"`
+	OverrunPolicy      string               `json:"overrun-policy,omitempty"`
+	Priority           string               `json:"priority,omitempty"`
+	Labels             map[string]string    `json:"labels,omitempty"`
+	OverrunCount       int                  `json:"overrun_count,omitempty"`
+	RetryStats         []TaskStepRetryStats `json:"retry_stats,omitempty"`
+	// NextWindowStartTimestamp is the time the next recurring window of a windowed schedule opens at
+	NextWindowStartTimestamp string `json:"next_window_start_timestamp,omitempty"`
+	// CircuitBreakers the state of the circuit breakers of the workflow steps
+	CircuitBreakers []TaskStepCircuitBreaker `json:"circuit_breakers,omitempty"`
+	// Buffer holds the caps and the backlog of the buffer of the task
+	Buffer *TaskBuffer `json:"buffer,omitempty"`
+	// BatchStats the batches of the metrics of the batched publishers
+	BatchStats []TaskStepBatchStats `json:"batch_stats,omitempty"`
+}
+
+// TaskBuffer holds the caps and the backlog of the buffer of a task.
+type TaskBuffer struct {
+	// MaxSize is in megabytes
+	MaxSize int64  `json:"max-size"`
+	MaxAge  string `json:"max-age"`
+	Batches int    `json:"batches"`
+	// Size is the size the buffered batches take in bytes
+	Size int64 `json:"size"`
+}
+
+// taskBuffer returns the caps and the backlog of the buffer of a task
+func taskBuffer(b *core.TaskBuffer) *TaskBuffer {
+	return &TaskBuffer{
+		MaxSize: b.MaxSize / (1024 * 1024),
+		MaxAge:  b.MaxAge.String(),
+		Batches: b.Batches,
+		Size:    b.Size,
+	}
+}
+
+// TaskStepBatchStats holds the batches of the metrics published by a workflow step.
+type TaskStepBatchStats struct {
+	Step      string `json:"step"`
+	Pending   uint   `json:"pending"`
+	Published uint   `json:"published"`
+	Failed    uint   `json:"failed"`
+	Metrics   uint   `json:"metrics"`
+}
+
+// TaskStepRetryStats holds the retries of the failed jobs of a workflow step.
+type TaskStepRetryStats struct {
+	Step      string `json:"step"`
+	Retries   uint   `json:"retries"`
+	Recovered uint   `json:"recovered"`
+	Exhausted uint   `json:"exhausted"`
+}
+
+// TaskStepCircuitBreaker holds the state of the circuit breaker of a workflow step.
+type TaskStepCircuitBreaker struct {
+	Step     string `json:"step"`
+	State    string `json:"state"`
+	Failures uint   `json:"failures"`
+	Trips    uint   `json:"trips"`
+	// OpenedTimestamp is the time the breaker last opened at
+	OpenedTimestamp string `json:"opened_timestamp,omitempty"`
+}
+
+// taskStepCircuitBreaker returns the state of the circuit breaker of a workflow step
+func taskStepCircuitBreaker(b core.TaskStepCircuitBreaker) *TaskStepCircuitBreaker {
+	s := &TaskStepCircuitBreaker{
+		Step:     b.Step,
+		State:    b.State,
+		Failures: b.Failures,
+		Trips:    b.Trips,
+	}
+	s.OpenedTimestamp = timestamp(b.OpenedAt)
+	return s
 }
 
 type Tasks []Task
@@ -131,7 +268,8 @@ func (s Tasks) Swap(i, j int) {
 }
 
 func (s *Task) CreationTime() time.Time {
-	return time.Unix(s.CreationTimestamp, 0)
+	t, _ := time.Parse(time.RFC3339, s.CreationTimestamp)
+	return t
 }
 
 func (s *apiV2) addTask(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
@@ -146,20 +284,43 @@ func (s *apiV2) addTask(w http.ResponseWriter, r *http.Request, _ httprouter.Par
 }
 
 func (s *apiV2) getTasks(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	// only the tasks with all the labels of the selector are listed
+	labels, err := core.ParseLabelSelector(r.URL.Query().Get("labels"))
+	if err != nil {
+		Write(400, FromError(err), w)
+		return
+	}
+	pg, paginated, err := parsePage(r.URL.Query())
+	if err != nil {
+		Write(400, FromError(err), w)
+		return
+	}
+
 	// get tasks from the task manager
 	sts := s.taskManager.GetTasks()
 
 	// create the task list response
-	tasks := make(Tasks, len(sts))
-	i := 0
+	tasks := make(Tasks, 0, len(sts))
+	keys := make([]string, 0, len(sts))
 	for _, t := range sts {
-		tasks[i] = SchedulerTaskFromTask(t)
-		tasks[i].Href = taskURI(r.Host, t)
-		i++
+		if !core.MatchLabels(labels, t.Labels()) {
+			continue
+		}
+		task := SchedulerTaskFromTask(t)
+		task.Href = taskURI(r.Host, t)
+		tasks = append(tasks, task)
+		// the tasks are paged by creation time, the keys keep the precision
+		// lost by the timestamps of the tasks
+		keys = append(keys, t.CreationTime().UTC().Format(taskKeyFormat)+t.ID())
+	}
+	if !paginated {
+		sort.Sort(tasks)
+		Write(200, TasksResponse{Tasks: tasks}, w)
+		return
 	}
-	sort.Sort(tasks)
 
-	Write(200, TasksResponse{Tasks: tasks}, w)
+	start, end, next := pg.bounds(keys, tasks.Swap)
+	Write(200, TasksResponse{Tasks: tasks[start:end], NextCursor: next}, w)
 }
 
 func (s *apiV2) getTask(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
@@ -191,6 +352,10 @@ func (s *apiV2) updateTaskState(w http.ResponseWriter, r *http.Request, p httpro
 			errs = s.taskManager.StartTask(id)
 		case "stop":
 			errs = s.taskManager.StopTask(id)
+		case "pause":
+			errs = s.taskManager.PauseTask(id)
+		case "resume":
+			errs = s.taskManager.ResumeTask(id)
 		default:
 			errs = append(errs, serror.New(ErrWrongAction))
 		}
@@ -208,6 +373,11 @@ func (s *apiV2) updateTaskState(w http.ResponseWriter, r *http.Request, p httpro
 		case ErrTaskDisabledNotRunnable:
 			statusCode = 409
 		}
+		for _, conflict := range []string{ErrTaskPausedNotRunnable, ErrTaskNotPausable, ErrTaskNotPaused} {
+			if strings.Contains(errs[0].Error(), conflict) {
+				statusCode = 409
+			}
+		}
 		Write(statusCode, FromSnapErrors(errs), w)
 		return
 	}
@@ -228,6 +398,53 @@ func (s *apiV2) removeTask(w http.ResponseWriter, r *http.Request, p httprouter.
 	Write(204, nil, w)
 }
 
+func (s *apiV2) runTask(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	tr := core.TaskCreationRequest{}
+	if code, err := core.UnmarshalBody(&tr, r.Body); code != 0 && err != nil {
+		Write(code, FromError(err), w)
+		return
+	}
+	if tr.Workflow == nil || *tr.Workflow == (wmap.WorkflowMap{}) {
+		Write(400, FromError(ErrNoWorkflowSpecified), w)
+		return
+	}
+	timeout := DefaultTaskRunTimeout
+	if t := r.URL.Query().Get("timeout"); t != "" {
+		d, err := time.ParseDuration(t)
+		if err != nil || d <= 0 {
+			Write(400, FromError(fmt.Errorf("invalid timeout `%s`", t)), w)
+			return
+		}
+		timeout = d
+	}
+	publish := r.URL.Query().Get("publish") == "true"
+
+	var opts []core.TaskOption
+	if tr.Name != "" {
+		opts = append(opts, core.SetTaskName(tr.Name))
+	}
+	run, errs := s.taskManager.RunTask(tr.Workflow, timeout, publish, opts...)
+	if run == nil {
+		Write(500, FromSnapErrors(errs), w)
+		return
+	}
+	resp := TaskRun{
+		Collected: streamedMetrics(run.Collected),
+		Published: make([]TaskRunPublisher, len(run.Published)),
+	}
+	for i, p := range run.Published {
+		resp.Published[i] = TaskRunPublisher{
+			Name:    p.Name,
+			Version: p.Version,
+			Metrics: streamedMetrics(p.Metrics),
+		}
+	}
+	for _, err := range errs {
+		resp.Errors = append(resp.Errors, FromSnapError(err))
+	}
+	Write(200, resp, w)
+}
+
 func taskURI(host string, t core.Task) string {
 	return fmt.Sprintf("%s://%s/%s/tasks/%s", protocolPrefix, host, version, t.ID())
 }
@@ -245,16 +462,65 @@ func SchedulerTaskFromTask(t core.Task) Task {
 		ID:                 t.ID(),
 		Name:               t.GetName(),
 		Deadline:           t.DeadlineDuration().String(),
-		CreationTimestamp:  t.CreationTime().Unix(),
-		LastRunTimestamp:   t.LastRunTime().Unix(),
+		CreationTimestamp:  timestamp(*t.CreationTime()),
+		LastRunTimestamp:   timestamp(*t.LastRunTime()),
 		HitCount:           int(t.HitCount()),
 		MissCount:          int(t.MissedCount()),
 		FailedCount:        int(t.FailedCount()),
 		LastFailureMessage: t.LastFailureMessage(),
 		TaskState:          t.State().String(),
+		MaxFailures:        t.GetStopOnFailure(),
+		DependsOn:          t.Dependencies(),
+		OverrunPolicy:      t.OverrunPolicy(),
+		Priority:           t.Priority(),
+		Labels:             t.Labels(),
+		OverrunCount:       int(t.OverrunCount()),
 	}
-	if st.LastRunTimestamp < 0 {
-		st.LastRunTimestamp = -1
+	if t.RunDeadline() > 0 {
+		st.RunDeadline = t.RunDeadline().String()
+	}
+	if w, ok := t.Schedule().(*schedule.WindowedSchedule); ok {
+		if next := w.NextWindowStart(); next != nil {
+			st.NextWindowStartTimestamp = timestamp(*next)
+		}
+	}
+	if stats := t.RetryStats(); len(stats) > 0 {
+		st.RetryStats = make([]TaskStepRetryStats, len(stats))
+		for i, s := range stats {
+			st.RetryStats[i] = TaskStepRetryStats{
+				Step:      s.Step,
+				Retries:   s.Retries,
+				Recovered: s.Recovered,
+				Exhausted: s.Exhausted,
+			}
+		}
+	}
+	if breakers := t.CircuitBreakers(); len(breakers) > 0 {
+		st.CircuitBreakers = make([]TaskStepCircuitBreaker, len(breakers))
+		for i, b := range breakers {
+			st.CircuitBreakers[i] = *taskStepCircuitBreaker(b)
+		}
+	}
+	if b := t.Buffer(); b != nil {
+		st.Buffer = taskBuffer(b)
+	}
+	if stats := t.BatchStats(); len(stats) > 0 {
+		st.BatchStats = make([]TaskStepBatchStats, len(stats))
+		for i, s := range stats {
+			st.BatchStats[i] = TaskStepBatchStats{
+				Step:      s.Step,
+				Pending:   s.Pending,
+				Published: s.Published,
+				Failed:    s.Failed,
+				Metrics:   s.Metrics,
+			}
+		}
+	}
+	if len(st.DependsOn) > 0 {
+		st.DependencyStates = make(map[string]string, len(st.DependsOn))
+		for id, state := range t.DependencyStates() {
+			st.DependencyStates[id] = state.String()
+		}
 	}
 	return st
 }
@@ -267,12 +533,22 @@ func (t *Task) assertSchedule(s schedule.Schedule) {
 			Interval:       v.Interval.String(),
 			StartTimestamp: v.StartTime,
 			StopTimestamp:  v.StopTime,
+			Jitter:         v.Jitter,
+		}
+		if r := v.Recurrence; r != nil {
+			t.Schedule.Timezone = r.Timezone
+			t.Schedule.Recurrence = &core.ScheduleRecurrence{
+				Start: r.Start,
+				Stop:  r.Stop,
+				Days:  r.Days,
+			}
 		}
 		return
 	case *schedule.CronSchedule:
 		t.Schedule = &core.Schedule{
 			Type:     "cron",
 			Interval: v.Entry(),
+			Timezone: v.Timezone(),
 		}
 		return
 	}
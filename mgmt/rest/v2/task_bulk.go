@@ -0,0 +1,126 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"net/http"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/julienschmidt/httprouter"
+)
+
+// BulkTasksResponse returns the result of a bulk operation for each selected task.
+//
+// swagger:response BulkTasksResponse
+type BulkTasksResp struct {
+	// in: body
+	Body struct {
+		Tasks []TaskBulkResult `json:"tasks"`
+	}
+}
+
+type BulkTasksResponse struct {
+	Tasks []TaskBulkResult `json:"tasks"`
+}
+
+// TaskBulkResult represents the result of a bulk operation for a task.
+type TaskBulkResult struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	TaskState string `json:"task_state"`
+	Error     *Error `json:"error,omitempty"`
+}
+
+// TaskSelectorParams selects the tasks of a bulk operation.
+//
+// swagger:parameters bulkUpdateTaskState bulkRemoveTasks
+type TaskSelectorParams struct {
+	// The pattern the names of the tasks match, e.g. "collect-*".
+	//
+	// in: query
+	Name string `json:"name"`
+	// The labels of the tasks, e.g. "team=storage,env=prod".
+	//
+	// in: query
+	Labels string `json:"labels"`
+}
+
+// TaskBulkPutParams defines the state the selected tasks are brought to.
+//
+// swagger:parameters bulkUpdateTaskState
+type TaskBulkPutParams struct {
+	// Update the state of the tasks: start or stop
+	//
+	// in: query
+	//
+	// required: true
+	Action string `json:"action"`
+}
+
+func (s *apiV2) bulkUpdateTaskState(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	switch action := r.URL.Query().Get("action"); action {
+	case "":
+		Write(400, FromError(ErrNoActionSpecified), w)
+	case core.TaskBulkStart, core.TaskBulkStop:
+		s.bulkTaskOperation(action, w, r)
+	default:
+		Write(400, FromError(ErrWrongAction), w)
+	}
+}
+
+func (s *apiV2) bulkRemoveTasks(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	s.bulkTaskOperation(core.TaskBulkRemove, w, r)
+}
+
+func (s *apiV2) bulkTaskOperation(action string, w http.ResponseWriter, r *http.Request) {
+	sel := core.TaskSelector{Name: r.URL.Query().Get("name")}
+	if labels := r.URL.Query().Get("labels"); labels != "" {
+		var err error
+		if sel.Labels, err = core.ParseLabelSelector(labels); err != nil {
+			Write(400, FromError(err), w)
+			return
+		}
+	}
+	if err := sel.Validate(); err != nil {
+		Write(400, FromError(err), w)
+		return
+	}
+	results, serr := s.taskManager.BulkTaskOperation(action, sel)
+	if serr != nil && results == nil {
+		Write(500, FromSnapError(serr), w)
+		return
+	}
+	resp := BulkTasksResponse{Tasks: make([]TaskBulkResult, len(results))}
+	for i, res := range results {
+		resp.Tasks[i] = TaskBulkResult{
+			ID:        res.ID,
+			Name:      res.Name,
+			TaskState: res.State.String(),
+		}
+		if res.Error != nil {
+			resp.Tasks[i].Error = FromSnapError(res.Error)
+		}
+	}
+	if serr != nil {
+		Write(409, resp, w)
+		return
+	}
+	Write(200, resp, w)
+}
@@ -32,12 +32,15 @@ import (
 
 const (
 	// Event types for task watcher streaming
-	TaskWatchStreamOpen   = "stream-open"
-	TaskWatchMetricEvent  = "metric-event"
-	TaskWatchTaskDisabled = "task-disabled"
-	TaskWatchTaskStarted  = "task-started"
-	TaskWatchTaskStopped  = "task-stopped"
-	TaskWatchTaskEnded    = "task-ended"
+	TaskWatchStreamOpen     = "stream-open"
+	TaskWatchMetricEvent    = "metric-event"
+	TaskWatchTaskDisabled   = "task-disabled"
+	TaskWatchTaskStarted    = "task-started"
+	TaskWatchTaskStopped    = "task-stopped"
+	TaskWatchTaskEnded      = "task-ended"
+	TaskWatchTaskOverrun    = "task-overrun"
+	TaskWatchTaskRun        = "task-run"
+	TaskWatchCircuitBreaker = "circuit-breaker"
 )
 
 // The amount of time to buffer streaming events before flushing in seconds
@@ -92,7 +95,7 @@ func (s *apiV2) watchTask(w http.ResponseWriter, r *http.Request, p httprouter.P
 		select {
 		case e := <-tw.mChan:
 			switch e.EventType {
-			case TaskWatchMetricEvent, TaskWatchTaskStarted:
+			case TaskWatchMetricEvent, TaskWatchTaskStarted, TaskWatchTaskOverrun, TaskWatchTaskRun, TaskWatchCircuitBreaker:
 				// The client can decide to stop receiving on the stream on Task Stopped.
 				// We write the event to the buffer
 				fmt.Fprintf(w, "data: %s\n\n", e.ToJSON())
@@ -138,19 +141,10 @@ type TaskWatchHandler struct {
 }
 
 func (t *TaskWatchHandler) CatchCollection(m []core.Metric) {
-	sm := make([]StreamedMetric, len(m))
-	for i := range m {
-		sm[i] = StreamedMetric{
-			Namespace: m[i].Namespace().String(),
-			Data:      m[i].Data(),
-			Timestamp: m[i].Timestamp(),
-			Tags:      m[i].Tags(),
-		}
-	}
 	t.mChan <- StreamedTaskEvent{
 		EventType: TaskWatchMetricEvent,
 		Message:   "",
-		Event:     sm,
+		Event:     streamedMetrics(m),
 	}
 }
 
@@ -179,6 +173,29 @@ func (t *TaskWatchHandler) CatchTaskDisabled(why string) {
 	}
 }
 
+func (t *TaskWatchHandler) CatchTaskOverrun(count uint) {
+	t.mChan <- StreamedTaskEvent{
+		EventType:    TaskWatchTaskOverrun,
+		Message:      "task run exceeded its deadline",
+		OverrunCount: count,
+	}
+}
+
+func (t *TaskWatchHandler) CatchTaskRun(r core.TaskRunRecord) {
+	t.mChan <- StreamedTaskEvent{
+		EventType: TaskWatchTaskRun,
+		Run:       streamedTaskRun(r),
+	}
+}
+
+func (t *TaskWatchHandler) CatchCircuitBreaker(b core.TaskStepCircuitBreaker) {
+	t.mChan <- StreamedTaskEvent{
+		EventType:      TaskWatchCircuitBreaker,
+		Message:        fmt.Sprintf("circuit breaker of %s is %s", b.Step, b.State),
+		CircuitBreaker: taskStepCircuitBreaker(b),
+	}
+}
+
 // TaskWatchResponse defines the response of the task watching stream.
 //
 // swagger:response TaskWatchResponse
@@ -194,6 +211,12 @@ type StreamedTaskEvent struct {
 	EventType string          `json:"type"`
 	Message   string          `json:"message"`
 	Event     StreamedMetrics `json:"event,omitempty"`
+	// The number of task runs which exceeded the run deadline, set on overruns
+	OverrunCount uint `json:"overrun_count,omitempty"`
+	// The record of the run, set on the task-run events
+	Run *StreamedTaskRun `json:"run,omitempty"`
+	// The state of the circuit breaker, set on the circuit-breaker events
+	CircuitBreaker *TaskStepCircuitBreaker `json:"circuit_breaker,omitempty"`
 }
 
 func (s *StreamedTaskEvent) ToJSON() string {
@@ -201,6 +224,37 @@ func (s *StreamedTaskEvent) ToJSON() string {
 	return string(j)
 }
 
+// StreamedTaskRun is the record of a scheduled run of a task
+type StreamedTaskRun struct {
+	Timestamp time.Time             `json:"timestamp"`
+	Duration  string                `json:"duration"`
+	Steps     []StreamedTaskRunStep `json:"steps"`
+}
+
+// StreamedTaskRunStep is the result of a step of a task run
+type StreamedTaskRunStep struct {
+	// Step is "collector" or the plugin of the step, "<type>:<name>:<version>"
+	Step    string   `json:"step"`
+	Metrics int      `json:"metrics"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+func streamedTaskRun(r core.TaskRunRecord) *StreamedTaskRun {
+	steps := make([]StreamedTaskRunStep, len(r.Steps))
+	for i, s := range r.Steps {
+		steps[i] = StreamedTaskRunStep{
+			Step:    s.Step,
+			Metrics: s.Metrics,
+			Errors:  s.Errors,
+		}
+	}
+	return &StreamedTaskRun{
+		Timestamp: r.Start,
+		Duration:  r.Duration.String(),
+		Steps:     steps,
+	}
+}
+
 type StreamedMetric struct {
 	Namespace string            `json:"namespace"`
 	Data      interface{}       `json:"data"`
@@ -211,6 +265,19 @@ type StreamedMetric struct {
 // StreamedMetrics defines a slice of streamed metrics.
 type StreamedMetrics []StreamedMetric
 
+func streamedMetrics(m []core.Metric) StreamedMetrics {
+	sm := make(StreamedMetrics, len(m))
+	for i := range m {
+		sm[i] = StreamedMetric{
+			Namespace: m[i].Namespace().String(),
+			Data:      m[i].Data(),
+			Timestamp: m[i].Timestamp(),
+			Tags:      m[i].Tags(),
+		}
+	}
+	return sm
+}
+
 func (s StreamedMetrics) Len() int {
 	return len(s)
 }
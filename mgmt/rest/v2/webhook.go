@@ -0,0 +1,171 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/intelsdi-x/snap/mgmt/notify"
+)
+
+// WebhooksResponse returns the webhooks the events are notified to.
+//
+// swagger:response WebhooksResponse
+type WebhooksResp struct {
+	// in: body
+	Body struct {
+		Webhooks []Webhook `json:"webhooks"`
+	}
+}
+
+type WebhooksResponse struct {
+	Webhooks []Webhook `json:"webhooks"`
+}
+
+// WebhookResponse returns a webhook.
+//
+// swagger:response WebhookResponse
+type WebhookResp struct {
+	// in: body
+	Body Webhook
+}
+
+// Webhook represents a URL the events of snapteld are POSTed to, its secret is never returned.
+type Webhook struct {
+	ID      string   `json:"id"`
+	URL     string   `json:"url"`
+	Events  []string `json:"events,omitempty"`
+	TaskIDs []string `json:"task_ids,omitempty"`
+	// Signed is true when the notifications are signed with the secret of the webhook
+	Signed bool   `json:"signed"`
+	Href   string `json:"href"`
+}
+
+// WebhookRequest registers a webhook.
+type WebhookRequest struct {
+	// URL is the http or https URL the events are POSTed to
+	URL string `json:"url"`
+	// Events are the namespaces of the events notified, e.g. Control.PluginLoaded, a trailing * matches a prefix
+	Events []string `json:"events,omitempty"`
+	// TaskIDs restrict the events of the tasks to the events of these tasks
+	TaskIDs []string `json:"task_ids,omitempty"`
+	// Secret signs the notifications with HMAC-SHA256 in the X-Snap-Signature header
+	Secret string `json:"secret,omitempty"`
+}
+
+// WebhookParams defines the webhook.
+//
+// swagger:parameters getWebhook removeWebhook
+type WebhookParams struct {
+	// in: path
+	//
+	// required: true
+	ID string `json:"id"`
+}
+
+// WebhookPostParams defines the webhook registered.
+//
+// swagger:parameters addWebhook
+type WebhookPostParams struct {
+	// in: body
+	//
+	// required: true
+	Webhook WebhookRequest `json:"webhook"`
+}
+
+func (s *apiV2) getWebhooks(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	if s.notifier == nil {
+		Write(404, FromError(ErrNotificationsDisabled), w)
+		return
+	}
+	webhooks := s.notifier.Webhooks()
+	resp := WebhooksResponse{Webhooks: make([]Webhook, len(webhooks))}
+	for i, wh := range webhooks {
+		resp.Webhooks[i] = webhook(r.Host, wh)
+	}
+	Write(200, resp, w)
+}
+
+func (s *apiV2) getWebhook(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	if s.notifier == nil {
+		Write(404, FromError(ErrNotificationsDisabled), w)
+		return
+	}
+	wh, err := s.notifier.Webhook(p.ByName("id"))
+	if err != nil {
+		Write(404, FromError(err), w)
+		return
+	}
+	Write(200, webhook(r.Host, wh), w)
+}
+
+func (s *apiV2) addWebhook(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	if s.notifier == nil {
+		Write(404, FromError(ErrNotificationsDisabled), w)
+		return
+	}
+	var req WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Write(400, FromError(err), w)
+		return
+	}
+	wh := notify.Webhook{URL: req.URL, Events: req.Events, TaskIDs: req.TaskIDs, Secret: req.Secret}
+	if err := wh.Validate(); err != nil {
+		Write(400, FromError(err), w)
+		return
+	}
+	wh, err := s.notifier.AddWebhook(wh)
+	if err != nil {
+		Write(500, FromError(err), w)
+		return
+	}
+	Write(201, webhook(r.Host, wh), w)
+}
+
+func (s *apiV2) removeWebhook(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	if s.notifier == nil {
+		Write(404, FromError(ErrNotificationsDisabled), w)
+		return
+	}
+	if err := s.notifier.RemoveWebhook(p.ByName("id")); err != nil {
+		if err == notify.ErrWebhookNotFound {
+			Write(404, FromError(err), w)
+			return
+		}
+		Write(500, FromError(err), w)
+		return
+	}
+	Write(204, nil, w)
+}
+
+func webhook(host string, wh notify.Webhook) Webhook {
+	return Webhook{
+		ID:      wh.ID,
+		URL:     wh.URL,
+		Events:  wh.Events,
+		TaskIDs: wh.TaskIDs,
+		Signed:  wh.Secret != "",
+		Href:    fmt.Sprintf("%s://%s/%s/webhooks/%s", protocolPrefix, host, version, wh.ID),
+	}
+}
@@ -32,6 +32,7 @@ const (
 	RestPort               = "rest_api_port"
 	RestProtocol           = "rest_proto"
 	RestInsecureSkipVerify = "rest_insecure"
+	Zone                   = "zone"
 )
 
 var logger = log.WithFields(log.Fields{
@@ -57,11 +58,31 @@ type tasks []Task
 type taskAgreement struct {
 	Name  string `json:"-"`
 	Tasks tasks  `json:"tasks,omitempty"`
+	// Labels select the tasks shared with the members of the agreement, all
+	// the tasks are shared when empty
+	Labels map[string]string `json:"labels,omitempty"`
+	// Strategy assigns the tasks to the members of the agreement, every
+	// member runs every task when it's empty
+	Strategy string `json:"strategy,omitempty"`
+	// Replicas is the number of members running each task
+	Replicas int `json:"replicas,omitempty"`
+	// Leader is the member assigning the tasks to the members
+	Leader string `json:"leader,omitempty"`
+	// Assignment is the names of the members running each task by task ID,
+	// as assigned by the leader at AssignmentTime
+	Assignment     map[string][]string `json:"assignment,omitempty"`
+	AssignmentTime uint64              `json:"-"`
 }
 
 type Task struct {
 	ID            string `json:"id"`
 	StartOnCreate bool   `json:"start_on_create"`
+	// Stopped is true when the task was stopped in the agreement, it isn't
+	// started on the members it's assigned to
+	Stopped bool `json:"stopped,omitempty"`
+	// Overrides is the config of the task overridden on some members, by
+	// member name, applied when the members create the task
+	Overrides map[string]TaskOverride `json:"overrides,omitempty"`
 }
 
 func New(name string) *Agreement {
@@ -192,6 +213,12 @@ func (a *pluginAgreement) Add(plugin Plugin) bool {
 	return true
 }
 
+// Selects returns true when a task with the given labels is shared with the
+// members of the agreement
+func (a *taskAgreement) Selects(labels map[string]string) bool {
+	return core.MatchLabels(a.Labels, labels)
+}
+
 func (a *taskAgreement) Add(task Task) bool {
 	logger.WithFields(log.Fields{
 		"agreement": a.Name,
@@ -217,3 +244,33 @@ func (a *taskAgreement) Remove(task Task) bool {
 	}
 	return false
 }
+
+// Assigns returns true when the tasks are assigned to the members by a
+// strategy, every member runs every task otherwise
+func (a *taskAgreement) Assigns() bool {
+	return a.Strategy != ""
+}
+
+// Assigned returns true when the task is assigned to the member
+func (a *taskAgreement) Assigned(taskID, member string) bool {
+	for _, m := range a.Assignment[taskID] {
+		if m == member {
+			return true
+		}
+	}
+	return false
+}
+
+// SetStopped records whether the task was stopped in the agreement
+func (a *taskAgreement) SetStopped(taskID string, stopped bool) {
+	if ok, idx := a.Tasks.Contains(Task{ID: taskID}); ok {
+		a.Tasks[idx].Stopped = stopped
+	}
+}
+
+// SetOverrides sets the config of the task overridden on the members
+func (a *taskAgreement) SetOverrides(taskID string, overrides map[string]TaskOverride) {
+	if ok, idx := a.Tasks.Contains(Task{ID: taskID}); ok {
+		a.Tasks[idx].Overrides = overrides
+	}
+}
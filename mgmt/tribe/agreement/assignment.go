@@ -0,0 +1,196 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agreement
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"sort"
+	"sync"
+)
+
+// The strategies assigning the tasks of an agreement to its members
+const (
+	// StrategyAll assigns every task to every member
+	StrategyAll = "all"
+	// StrategyReplicas assigns each task to a number of members, spreading
+	// the tasks evenly between the members
+	StrategyReplicas = "replicas"
+	// StrategyHash assigns each task to a number of members by consistent
+	// hashing of its ID, few tasks move when the members change
+	StrategyHash = "hash"
+)
+
+// hashVirtualNodes is the number of points of each member on the hash ring
+const hashVirtualNodes = 64
+
+var (
+	// ErrUnknownStrategy is returned for the strategies which aren't registered
+	ErrUnknownStrategy = errors.New("unknown task assignment strategy")
+
+	strategiesMutex sync.RWMutex
+	strategies      = map[string]Strategy{
+		StrategyAll:      allStrategy{},
+		StrategyReplicas: replicasStrategy{},
+		StrategyHash:     hashStrategy{},
+	}
+)
+
+// Strategy assigns the tasks of an agreement to its members
+type Strategy interface {
+	// Assign returns the names of the members running each task by task ID,
+	// each task runs on the given number of replicas at most
+	Assign(tasks []string, members []string, replicas int) map[string][]string
+}
+
+// RegisterStrategy registers a strategy under a name, replacing the strategy
+// registered under the same name. The strategy must return the same
+// assignment on every member of the tribe for the same tasks and members.
+func RegisterStrategy(name string, s Strategy) {
+	strategiesMutex.Lock()
+	defer strategiesMutex.Unlock()
+	strategies[name] = s
+}
+
+// GetStrategy returns the strategy registered under a name, every task is
+// assigned to every member when the name is empty
+func GetStrategy(name string) (Strategy, error) {
+	if name == "" {
+		name = StrategyAll
+	}
+	strategiesMutex.RLock()
+	defer strategiesMutex.RUnlock()
+	s, ok := strategies[name]
+	if !ok {
+		return nil, fmt.Errorf("%v: %v", ErrUnknownStrategy, name)
+	}
+	return s, nil
+}
+
+// ElectLeader returns the leader of the members of an agreement, the member
+// with the smallest name. Every member elects the same leader once the
+// membership of the tribe has converged.
+func ElectLeader(members []string) string {
+	leader := ""
+	for _, m := range members {
+		if leader == "" || m < leader {
+			leader = m
+		}
+	}
+	return leader
+}
+
+type allStrategy struct{}
+
+func (allStrategy) Assign(tasks []string, members []string, _ int) map[string][]string {
+	sorted := sortedCopy(members)
+	assignment := make(map[string][]string, len(tasks))
+	for _, t := range tasks {
+		assignment[t] = sorted
+	}
+	return assignment
+}
+
+type replicasStrategy struct{}
+
+func (replicasStrategy) Assign(tasks []string, members []string, replicas int) map[string][]string {
+	assignment := make(map[string][]string, len(tasks))
+	if len(members) == 0 {
+		return assignment
+	}
+	sortedMembers := sortedCopy(members)
+	n := replicaCount(replicas, len(members))
+	for i, t := range sortedCopy(tasks) {
+		assigned := make([]string, 0, n)
+		for r := 0; r < n; r++ {
+			assigned = append(assigned, sortedMembers[(i*n+r)%len(sortedMembers)])
+		}
+		assignment[t] = assigned
+	}
+	return assignment
+}
+
+type hashStrategy struct{}
+
+type ringPoint struct {
+	hash   uint32
+	member string
+}
+
+func (hashStrategy) Assign(tasks []string, members []string, replicas int) map[string][]string {
+	assignment := make(map[string][]string, len(tasks))
+	if len(members) == 0 {
+		return assignment
+	}
+	ring := make([]ringPoint, 0, len(members)*hashVirtualNodes)
+	for _, m := range members {
+		for i := 0; i < hashVirtualNodes; i++ {
+			ring = append(ring, ringPoint{hash: crc32.ChecksumIEEE([]byte(fmt.Sprintf("%s#%d", m, i))), member: m})
+		}
+	}
+	sort.Sort(byHash(ring))
+	n := replicaCount(replicas, len(members))
+	for _, t := range tasks {
+		h := crc32.ChecksumIEEE([]byte(t))
+		start := sort.Search(len(ring), func(i int) bool { return ring[i].hash >= h })
+		assigned := make([]string, 0, n)
+		seen := map[string]bool{}
+		for i := 0; i < len(ring) && len(assigned) < n; i++ {
+			p := ring[(start+i)%len(ring)]
+			if !seen[p.member] {
+				seen[p.member] = true
+				assigned = append(assigned, p.member)
+			}
+		}
+		assignment[t] = assigned
+	}
+	return assignment
+}
+
+type byHash []ringPoint
+
+func (r byHash) Len() int      { return len(r) }
+func (r byHash) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+func (r byHash) Less(i, j int) bool {
+	if r[i].hash == r[j].hash {
+		return r[i].member < r[j].member
+	}
+	return r[i].hash < r[j].hash
+}
+
+// replicaCount returns the number of members running each task, one by
+// default and at most every member
+func replicaCount(replicas, members int) int {
+	if replicas < 1 {
+		replicas = 1
+	}
+	if replicas > members {
+		replicas = members
+	}
+	return replicas
+}
+
+func sortedCopy(s []string) []string {
+	sorted := make([]string, len(s))
+	copy(sorted, s)
+	sort.Strings(sorted)
+	return sorted
+}
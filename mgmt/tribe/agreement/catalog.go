@@ -0,0 +1,35 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agreement
+
+// CatalogMetric is a metric of the catalog of a tribe along with the members
+// exposing it
+type CatalogMetric struct {
+	Namespace string   `json:"namespace"`
+	Version   int      `json:"version"`
+	Members   []string `json:"members"`
+}
+
+// Catalog is the metric catalog of the members of a tribe or an agreement,
+// the members whose catalog couldn't be fetched are unreachable
+type Catalog struct {
+	Metrics            []CatalogMetric `json:"metrics"`
+	UnreachableMembers []string        `json:"unreachable_members,omitempty"`
+}
@@ -0,0 +1,105 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agreement
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/intelsdi-x/snap/scheduler/wmap"
+)
+
+// TaskOverride is the config of the plugins of a task on a member overriding
+// the config of the task manifest: the config of the processors and the
+// publishers by plugin name, and the config of the collectors by namespace for
+// the keys starting with a "/"
+type TaskOverride map[string]map[string]interface{}
+
+// MarshalBinary encodes the override in JSON for the gossip, so that its
+// values are decoded with the types of the values of a task manifest
+func (o TaskOverride) MarshalBinary() ([]byte, error) {
+	return json.Marshal(map[string]map[string]interface{}(o))
+}
+
+func (o *TaskOverride) UnmarshalBinary(b []byte) error {
+	m := map[string]map[string]interface{}{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+	*o = m
+	return nil
+}
+
+// Apply overrides the config of the nodes of the workflow
+func (o TaskOverride) Apply(wf *wmap.WorkflowMap) {
+	if wf == nil || wf.Collect == nil {
+		return
+	}
+	o.applyCollect(wf.Collect)
+}
+
+func (o TaskOverride) applyCollect(c *wmap.CollectWorkflowMapNode) {
+	for key, config := range o {
+		if !strings.HasPrefix(key, "/") {
+			continue
+		}
+		if c.Config == nil {
+			c.Config = map[string]map[string]interface{}{}
+		}
+		if c.Config[key] == nil {
+			c.Config[key] = map[string]interface{}{}
+		}
+		for k, v := range config {
+			c.Config[key][k] = v
+		}
+	}
+	for i := range c.Join {
+		o.applyCollect(&c.Join[i])
+	}
+	o.applyProcess(c.Process)
+	o.applyPublish(c.Publish)
+}
+
+func (o TaskOverride) applyProcess(nodes []wmap.ProcessWorkflowMapNode) {
+	for i := range nodes {
+		nodes[i].Config = o.override(nodes[i].PluginName, nodes[i].Config)
+		o.applyProcess(nodes[i].Process)
+		o.applyPublish(nodes[i].Publish)
+	}
+}
+
+func (o TaskOverride) applyPublish(nodes []wmap.PublishWorkflowMapNode) {
+	for i := range nodes {
+		nodes[i].Config = o.override(nodes[i].PluginName, nodes[i].Config)
+	}
+}
+
+func (o TaskOverride) override(pluginName string, config map[string]interface{}) map[string]interface{} {
+	if len(o[pluginName]) == 0 {
+		return config
+	}
+	if config == nil {
+		config = map[string]interface{}{}
+	}
+	for k, v := range o[pluginName] {
+		config[k] = v
+	}
+	return config
+}
@@ -0,0 +1,53 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agreement
+
+import "github.com/intelsdi-x/snap/core"
+
+// Snapshot is the state of an agreement exported to restore it into
+// another tribe: its settings, members, plugins and tasks
+type Snapshot struct {
+	Name         string            `json:"name"`
+	TaskLabels   map[string]string `json:"task_labels,omitempty"`
+	TaskStrategy string            `json:"task_strategy,omitempty"`
+	TaskReplicas int               `json:"task_replicas,omitempty"`
+	Members      []string          `json:"members"`
+	Plugins      []Plugin          `json:"plugins"`
+	Tasks        []SnapshotTask    `json:"tasks"`
+}
+
+type SnapshotTask struct {
+	ID      string `json:"id"`
+	Stopped bool   `json:"stopped,omitempty"`
+	// Overrides is the config of the task overridden on some members
+	Overrides map[string]TaskOverride `json:"overrides,omitempty"`
+	// Definition creates the task on a member lacking it when the snapshot
+	// is restored
+	Definition *core.TaskCreationRequest `json:"definition,omitempty"`
+}
+
+// RestoreReport lists what restoring a snapshot left out: the members which
+// aren't in the tribe, the plugins which aren't loaded and the tasks which
+// don't exist on the member restoring it
+type RestoreReport struct {
+	MissingMembers []string `json:"missing_members,omitempty"`
+	MissingPlugins []Plugin `json:"missing_plugins,omitempty"`
+	MissingTasks   []string `json:"missing_tasks,omitempty"`
+}
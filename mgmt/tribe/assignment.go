@@ -0,0 +1,241 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tribe
+
+import (
+	"errors"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/intelsdi-x/snap/core/serror"
+	"github.com/intelsdi-x/snap/mgmt/tribe/agreement"
+	"github.com/intelsdi-x/snap/mgmt/tribe/worker"
+	"github.com/pborman/uuid"
+)
+
+var errInvalidReplicas = errors.New("The number of replicas can't be negative")
+
+// SetTaskAssignment sets the strategy assigning the tasks of an agreement to
+// its members and the number of members running each task. The leader of the
+// agreement assigns the tasks again, and again each time a member joins,
+// leaves or fails.
+func (t *tribe) SetTaskAssignment(agreementName, strategy string, replicas int) serror.SnapError {
+	fields := log.Fields{
+		"agreement": agreementName,
+		"strategy":  strategy,
+		"replicas":  replicas,
+	}
+	if _, ok := t.agreements[agreementName]; !ok {
+		return serror.New(errAgreementDoesNotExist, fields)
+	}
+	if _, err := agreement.GetStrategy(strategy); err != nil {
+		return serror.New(err, fields)
+	}
+	if replicas < 0 {
+		return serror.New(errInvalidReplicas, fields)
+	}
+	if strategy == "" {
+		strategy = agreement.StrategyAll
+	}
+	msg := &agreementMsg{
+		LTime:         t.clock.Increment(),
+		UUID:          uuid.New(),
+		AgreementName: agreementName,
+		Type:          setTaskStrategyMsgType,
+		TaskStrategy:  strategy,
+		TaskReplicas:  replicas,
+	}
+	if t.handleSetTaskStrategy(msg) {
+		t.broadcast(setTaskStrategyMsgType, msg, nil)
+	}
+	return nil
+}
+
+func (t *tribe) handleSetTaskStrategy(msg *agreementMsg) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	// update the clock if newer
+	t.clock.Update(msg.LTime)
+
+	if t.isDuplicate(msg) {
+		return false
+	}
+
+	t.msgBuffer[msg.LTime%LTime(len(t.msgBuffer))] = msg
+	t.emitIntent(msg.GetType(), msg.UUID, msg.LTime, msg.AgreementName, "")
+
+	if a, ok := t.agreements[msg.AgreementName]; ok {
+		t.setTaskStrategy(a, msg)
+		return true
+	}
+
+	t.addAgreementIntent(msg)
+	return true
+}
+
+func (t *tribe) processSetTaskStrategyIntents() bool {
+	for idx, v := range t.intentBuffer {
+		if v.GetType() == setTaskStrategyMsgType {
+			intent := v.(*agreementMsg)
+			if a, ok := t.agreements[intent.AgreementName]; ok {
+				t.intentBuffer = append(t.intentBuffer[:idx], t.intentBuffer[idx+1:]...)
+				t.setTaskStrategy(a, intent)
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (t *tribe) setTaskStrategy(a *agreement.Agreement, msg *agreementMsg) {
+	a.TaskAgreement.Strategy = msg.TaskStrategy
+	a.TaskAgreement.Replicas = msg.TaskReplicas
+	t.rebalance(a.Name)
+}
+
+// rebalance elects the leader of an agreement, the leader assigns the tasks
+// of the agreement to its members with the strategy of the agreement and
+// broadcasts the assignment. It's called with the mutex locked.
+func (t *tribe) rebalance(agreementName string) {
+	a, ok := t.agreements[agreementName]
+	if !ok {
+		return
+	}
+	members := make([]string, 0, len(a.Members))
+	for name := range a.Members {
+		members = append(members, name)
+	}
+	a.TaskAgreement.Leader = agreement.ElectLeader(members)
+	if !a.TaskAgreement.Assigns() || a.TaskAgreement.Leader != t.memberlist.LocalNode().Name {
+		return
+	}
+	strategy, err := agreement.GetStrategy(a.TaskAgreement.Strategy)
+	if err != nil {
+		t.logger.WithFields(log.Fields{
+			"_block":    "rebalance",
+			"agreement": agreementName,
+		}).Error(err)
+		return
+	}
+	tasks := make([]string, 0, len(a.TaskAgreement.Tasks))
+	for _, task := range a.TaskAgreement.Tasks {
+		tasks = append(tasks, task.ID)
+	}
+	msg := &assignmentMsg{
+		LTime:         t.clock.Increment(),
+		UUID:          uuid.New(),
+		AgreementName: agreementName,
+		Leader:        a.TaskAgreement.Leader,
+		Assignment:    strategy.Assign(tasks, members, a.TaskAgreement.Replicas),
+		Type:          assignTasksMsgType,
+	}
+	t.logger.WithFields(log.Fields{
+		"_block":     "rebalance",
+		"agreement":  agreementName,
+		"strategy":   a.TaskAgreement.Strategy,
+		"members":    len(members),
+		"tasks":      len(tasks),
+		"event-uuid": msg.UUID,
+	}).Debugln("assigning the tasks")
+	t.msgBuffer[msg.LTime%LTime(len(t.msgBuffer))] = msg
+	t.assignTasks(a, msg)
+	t.broadcast(assignTasksMsgType, msg, nil)
+}
+
+func (t *tribe) handleAssignTasks(msg *assignmentMsg) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	// update the clock if newer
+	t.clock.Update(msg.LTime)
+
+	if t.isDuplicate(msg) {
+		return false
+	}
+
+	t.msgBuffer[msg.LTime%LTime(len(t.msgBuffer))] = msg
+
+	if a, ok := t.agreements[msg.AgreementName]; ok {
+		// an assignment older than the one applied is from a former leader
+		if uint64(msg.LTime) < a.TaskAgreement.AssignmentTime {
+			return false
+		}
+		t.assignTasks(a, msg)
+	}
+	return true
+}
+
+// assignTasks applies the assignment of the tasks of an agreement, the local
+// member starts the tasks newly assigned to it and stops the ones which no
+// longer are. It's called with the mutex locked.
+func (t *tribe) assignTasks(a *agreement.Agreement, msg *assignmentMsg) {
+	ta := a.TaskAgreement
+	previous := ta.Assignment
+	ta.Leader = msg.Leader
+	ta.Assignment = msg.Assignment
+	ta.AssignmentTime = uint64(msg.LTime)
+
+	local := t.memberlist.LocalNode().Name
+	if _, ok := a.Members[local]; !ok {
+		return
+	}
+	for _, task := range ta.Tasks {
+		members, known := previous[task.ID]
+		assigned := ta.Assigned(task.ID, local)
+		if known && containsMember(members, local) == assigned {
+			continue
+		}
+		t.runAssignedTask(task, assigned)
+	}
+}
+
+// runAssignedTask starts a task assigned to the local member unless it was
+// stopped in the agreement, and stops a task which isn't
+func (t *tribe) runAssignedTask(task agreement.Task, assigned bool) {
+	if assigned {
+		if !task.Stopped {
+			t.taskWorkQueue <- worker.TaskRequest{
+				Task:        worker.Task{ID: task.ID},
+				RequestType: worker.TaskStartedType,
+			}
+		}
+		return
+	}
+	// the copies of the tasks are kept by the members they aren't assigned
+	// to, so that they can be assigned to them when a member fails
+	if t.taskManager == nil {
+		return
+	}
+	if _, err := t.taskManager.GetTask(task.ID); err == nil {
+		t.taskWorkQueue <- worker.TaskRequest{
+			Task:        worker.Task{ID: task.ID},
+			RequestType: worker.TaskStoppedType,
+		}
+	}
+}
+
+func containsMember(members []string, name string) bool {
+	for _, m := range members {
+		if m == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tribe
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/intelsdi-x/snap/core/serror"
+	"github.com/intelsdi-x/snap/mgmt/rest/client"
+	"github.com/intelsdi-x/snap/mgmt/rest/v1/rbody"
+	"github.com/intelsdi-x/snap/mgmt/tribe/agreement"
+)
+
+// catalogTimeout is how long the metric catalog of a member is waited for
+const catalogTimeout = 5 * time.Second
+
+// MetricCatalog merges the metric catalogs of the members of the agreement,
+// or of every member of the tribe when the agreement name is empty. Each
+// metric is listed with the members exposing it.
+func (t *tribe) MetricCatalog(agreementName string) (*agreement.Catalog, serror.SnapError) {
+	t.mutex.RLock()
+	members := []*agreement.Member{}
+	if agreementName == "" {
+		for _, m := range t.members {
+			members = append(members, m)
+		}
+	} else {
+		a, ok := t.agreements[agreementName]
+		if !ok {
+			t.mutex.RUnlock()
+			return nil, serror.New(errAgreementDoesNotExist, map[string]interface{}{"agreement_name": agreementName})
+		}
+		for _, m := range a.Members {
+			members = append(members, m)
+		}
+	}
+	t.mutex.RUnlock()
+
+	type memberCatalog struct {
+		name    string
+		metrics []*rbody.Metric
+		err     error
+	}
+	catalogs := make(chan memberCatalog, len(members))
+	for _, m := range members {
+		go func(m *agreement.Member) {
+			uri := fmt.Sprintf("%s://%s:%s", m.GetRestProto(), m.GetAddr(), m.GetRestPort())
+			c, err := client.New(uri, "v1", m.GetRestInsecureSkipVerify(), client.Password(t.GetRequestPassword()), client.Timeout(catalogTimeout))
+			if err != nil {
+				catalogs <- memberCatalog{name: m.Name, err: err}
+				return
+			}
+			r := c.GetMetricCatalog()
+			catalogs <- memberCatalog{name: m.Name, metrics: r.Catalog, err: r.Err}
+		}(m)
+	}
+
+	catalog := &agreement.Catalog{Metrics: []agreement.CatalogMetric{}}
+	metrics := map[string]*agreement.CatalogMetric{}
+	for range members {
+		mc := <-catalogs
+		if mc.err != nil {
+			t.logger.WithFields(log.Fields{
+				"_block": "metric-catalog",
+				"member": mc.name,
+			}).Warn(mc.err)
+			catalog.UnreachableMembers = append(catalog.UnreachableMembers, mc.name)
+			continue
+		}
+		for _, m := range mc.metrics {
+			key := fmt.Sprintf("%s:%d", m.Namespace, m.Version)
+			if _, ok := metrics[key]; !ok {
+				metrics[key] = &agreement.CatalogMetric{Namespace: m.Namespace, Version: m.Version}
+			}
+			metrics[key].Members = append(metrics[key].Members, mc.name)
+		}
+	}
+	for _, m := range metrics {
+		sort.Strings(m.Members)
+		catalog.Metrics = append(catalog.Metrics, *m)
+	}
+	sort.Sort(catalogMetrics(catalog.Metrics))
+	sort.Strings(catalog.UnreachableMembers)
+	return catalog, nil
+}
+
+type catalogMetrics []agreement.CatalogMetric
+
+func (c catalogMetrics) Len() int      { return len(c) }
+func (c catalogMetrics) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
+func (c catalogMetrics) Less(i, j int) bool {
+	if c[i].Namespace == c[j].Namespace {
+		return c[i].Version < c[j].Version
+	}
+	return c[i].Namespace < c[j].Namespace
+}
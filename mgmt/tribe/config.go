@@ -28,6 +28,7 @@ import (
 	"github.com/hashicorp/memberlist"
 	"github.com/intelsdi-x/snap/pkg/netutil"
 	"github.com/pborman/uuid"
+	"github.com/vrischmann/jsonutil"
 )
 
 // default configuration values
@@ -40,6 +41,8 @@ const (
 	defaultRestAPIPassword           string        = ""
 	defaultRestAPIPort               int           = 8181
 	defaultRestAPIInsecureSkipVerify string        = "true"
+	defaultWAN                       bool          = false
+	defaultZoneSuspicionTimeout      time.Duration = 60 * time.Second
 )
 
 // holds the configuration passed in through the SNAP config file
@@ -52,6 +55,12 @@ type Config struct {
 	BindAddr                  string             `json:"bind_addr"yaml:"bind_addr"`
 	BindPort                  int                `json:"bind_port"yaml:"bind_port"`
 	Seed                      string             `json:"seed"yaml:"seed"`
+	EncryptKeys               []string           `json:"encrypt_keys"yaml:"encrypt_keys"`
+	KeyringFile               string             `json:"keyring_file"yaml:"keyring_file"`
+	WAN                       bool               `json:"wan"yaml:"wan"`
+	Zone                      string             `json:"zone"yaml:"zone"`
+	ZoneSuspicionTimeout      jsonutil.Duration  `json:"zone_suspicion_timeout"yaml:"zone_suspicion_timeout"`
+	Relays                    []string           `json:"relays"yaml:"relays"`
 	MemberlistConfig          *memberlist.Config `json:"-"yaml:"-"`
 	RestAPIProto              string             `json:"-"yaml:"-"`
 	RestAPIPassword           string             `json:"-"yaml:"-"`
@@ -80,6 +89,30 @@ const (
 					},
 					"seed": {
 						"type" : "string"
+					},
+					"encrypt_keys": {
+						"type": ["array", "null"],
+						"items": {
+							"type": "string"
+						}
+					},
+					"keyring_file": {
+						"type": "string"
+					},
+					"wan": {
+						"type": "boolean"
+					},
+					"zone": {
+						"type": "string"
+					},
+					"zone_suspicion_timeout": {
+						"type": "string"
+					},
+					"relays": {
+						"type": ["array", "null"],
+						"items": {
+							"type": "string"
+						}
 					}
 				},
 				"additionalProperties": false
@@ -98,6 +131,8 @@ func GetDefaultConfig() *Config {
 		BindAddr:                  netutil.GetIP(),
 		BindPort:                  defaultBindPort,
 		Seed:                      defaultSeed,
+		WAN:                       defaultWAN,
+		ZoneSuspicionTimeout:      jsonutil.Duration{defaultZoneSuspicionTimeout},
 		MemberlistConfig:          mlCfg,
 		RestAPIProto:              defaultRestAPIProto,
 		RestAPIPassword:           defaultRestAPIPassword,
@@ -140,6 +175,30 @@ func (c *Config) UnmarshalJSON(data []byte) error {
 			if err := json.Unmarshal(v, &(c.Seed)); err != nil {
 				return fmt.Errorf("%v (while parsing 'tribe::seed')", err)
 			}
+		case "encrypt_keys":
+			if err := json.Unmarshal(v, &(c.EncryptKeys)); err != nil {
+				return fmt.Errorf("%v (while parsing 'tribe::encrypt_keys')", err)
+			}
+		case "keyring_file":
+			if err := json.Unmarshal(v, &(c.KeyringFile)); err != nil {
+				return fmt.Errorf("%v (while parsing 'tribe::keyring_file')", err)
+			}
+		case "wan":
+			if err := json.Unmarshal(v, &(c.WAN)); err != nil {
+				return fmt.Errorf("%v (while parsing 'tribe::wan')", err)
+			}
+		case "zone":
+			if err := json.Unmarshal(v, &(c.Zone)); err != nil {
+				return fmt.Errorf("%v (while parsing 'tribe::zone')", err)
+			}
+		case "zone_suspicion_timeout":
+			if err := json.Unmarshal(v, &(c.ZoneSuspicionTimeout)); err != nil {
+				return fmt.Errorf("%v (while parsing 'tribe::zone_suspicion_timeout')", err)
+			}
+		case "relays":
+			if err := json.Unmarshal(v, &(c.Relays)); err != nil {
+				return fmt.Errorf("%v (while parsing 'tribe::relays')", err)
+			}
 		default:
 			return fmt.Errorf("Unrecognized key '%v' in global config file while parsing 'tribe'", k)
 		}
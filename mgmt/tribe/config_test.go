@@ -81,6 +81,21 @@ func TestTribeConfigJSON(t *testing.T) {
 		Convey("Seed should be 1.1.1.1:16000", func() {
 			So(cfg.Seed, ShouldEqual, "1.1.1.1:16000")
 		})
+		Convey("EncryptKeys should hold a key", func() {
+			So(cfg.EncryptKeys, ShouldResemble, []string{"8mW0q2Nq3v3H7F5n3TqkY3QyC1v4w3sQ8m0b1Vq6jXg="})
+		})
+		Convey("WAN should be true", func() {
+			So(cfg.WAN, ShouldEqual, true)
+		})
+		Convey("Zone should be us-east", func() {
+			So(cfg.Zone, ShouldEqual, "us-east")
+		})
+		Convey("ZoneSuspicionTimeout should be 2m", func() {
+			So(cfg.ZoneSuspicionTimeout.Duration, ShouldEqual, 2*time.Minute)
+		})
+		Convey("Relays should hold a relay", func() {
+			So(cfg.Relays, ShouldResemble, []string{"2.2.2.2:16000"})
+		})
 	})
 
 }
@@ -114,6 +129,21 @@ func TestTribeConfigYaml(t *testing.T) {
 		Convey("Seed should be 1.1.1.1:16000", func() {
 			So(cfg.Seed, ShouldEqual, "1.1.1.1:16000")
 		})
+		Convey("EncryptKeys should hold a key", func() {
+			So(cfg.EncryptKeys, ShouldResemble, []string{"8mW0q2Nq3v3H7F5n3TqkY3QyC1v4w3sQ8m0b1Vq6jXg="})
+		})
+		Convey("WAN should be true", func() {
+			So(cfg.WAN, ShouldEqual, true)
+		})
+		Convey("Zone should be us-east", func() {
+			So(cfg.Zone, ShouldEqual, "us-east")
+		})
+		Convey("ZoneSuspicionTimeout should be 2m", func() {
+			So(cfg.ZoneSuspicionTimeout.Duration, ShouldEqual, 2*time.Minute)
+		})
+		Convey("Relays should hold a relay", func() {
+			So(cfg.Relays, ShouldResemble, []string{"2.2.2.2:16000"})
+		})
 	})
 
 }
@@ -136,6 +166,12 @@ func TestTribeDefaultConfig(t *testing.T) {
 		Convey("Seed should be empty", func() {
 			So(cfg.Seed, ShouldEqual, "")
 		})
+		Convey("WAN should be false", func() {
+			So(cfg.WAN, ShouldEqual, false)
+		})
+		Convey("ZoneSuspicionTimeout should be 60s", func() {
+			So(cfg.ZoneSuspicionTimeout.Duration, ShouldEqual, 60*time.Second)
+		})
 		Convey("MemberlistConfig.PushPullInterval should be 300s", func() {
 			So(cfg.MemberlistConfig.PushPullInterval, ShouldEqual, 300*time.Second)
 		})
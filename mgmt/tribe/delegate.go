@@ -82,6 +82,30 @@ func (t *delegate) NotifyMsg(buf []byte) {
 			panic(err)
 		}
 		rebroadcast = t.tribe.handleLeaveAgreement(msg)
+	case setTaskStrategyMsgType:
+		msg := &agreementMsg{}
+		if err := decodeMessage(buf[1:], msg); err != nil {
+			panic(err)
+		}
+		rebroadcast = t.tribe.handleSetTaskStrategy(msg)
+	case assignTasksMsgType:
+		msg := &assignmentMsg{}
+		if err := decodeMessage(buf[1:], msg); err != nil {
+			panic(err)
+		}
+		rebroadcast = t.tribe.handleAssignTasks(msg)
+	case setTaskOverridesMsgType:
+		msg := &taskMsg{}
+		if err := decodeMessage(buf[1:], msg); err != nil {
+			panic(err)
+		}
+		rebroadcast = t.tribe.handleSetTaskOverrides(msg)
+	case installKeyMsgType, useKeyMsgType, removeKeyMsgType:
+		msg := &keyMsg{}
+		if err := decodeMessage(buf[1:], msg); err != nil {
+			panic(err)
+		}
+		rebroadcast = t.tribe.handleKey(msg)
 	case addTaskMsgType:
 		msg := &taskMsg{}
 		if err := decodeMessage(buf[1:], msg); err != nil {
@@ -186,6 +210,8 @@ func (t *delegate) LocalState(join bool) []byte {
 			agreementMsgs[idx] = msg.(*agreementMsg)
 		case leaveAgreementMsgType:
 			agreementMsgs[idx] = msg.(*agreementMsg)
+		case setTaskStrategyMsgType:
+			agreementMsgs[idx] = msg.(*agreementMsg)
 		case addTaskMsgType:
 			taskMsgs[idx] = msg.(*taskMsg)
 		case removeTaskMsgType:
@@ -194,6 +220,8 @@ func (t *delegate) LocalState(join bool) []byte {
 			taskMsgs[idx] = msg.(*taskMsg)
 		case startTaskMsgType:
 			taskMsgs[idx] = msg.(*taskMsg)
+		case setTaskOverridesMsgType:
+			taskMsgs[idx] = msg.(*taskMsg)
 		}
 	}
 
@@ -214,6 +242,8 @@ func (t *delegate) LocalState(join bool) []byte {
 			agreementIntentMsgs[idx] = msg.(*agreementMsg)
 		case leaveAgreementMsgType:
 			agreementIntentMsgs[idx] = msg.(*agreementMsg)
+		case setTaskStrategyMsgType:
+			agreementIntentMsgs[idx] = msg.(*agreementMsg)
 		case addTaskMsgType:
 			taskIntentMsgs[idx] = msg.(*taskMsg)
 		case removeTaskMsgType:
@@ -222,6 +252,8 @@ func (t *delegate) LocalState(join bool) []byte {
 			taskIntentMsgs[idx] = msg.(*taskMsg)
 		case startTaskMsgType:
 			taskIntentMsgs[idx] = msg.(*taskMsg)
+		case setTaskOverridesMsgType:
+			taskIntentMsgs[idx] = msg.(*taskMsg)
 		}
 	}
 
@@ -335,6 +367,9 @@ func (t *delegate) MergeRemoteState(buf []byte, join bool) {
 			if m.GetType() == leaveAgreementMsgType {
 				t.tribe.handleLeaveAgreement(m)
 			}
+			if m.GetType() == setTaskStrategyMsgType {
+				t.tribe.handleSetTaskStrategy(m)
+			}
 		}
 		for _, m := range fs.TaskMsgs {
 			if m == nil {
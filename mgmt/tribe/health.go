@@ -0,0 +1,46 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tribe
+
+import (
+	"github.com/intelsdi-x/snap/core"
+)
+
+// Health reports the tribe as ready once it joined the members of its seed,
+// a tribe without a seed is ready on its own
+func (t *tribe) Health() core.SubsystemHealth {
+	members := t.memberlist.NumMembers()
+	t.mutex.RLock()
+	agreements := len(t.agreements)
+	t.mutex.RUnlock()
+	h := core.SubsystemHealth{
+		Name:    "tribe",
+		Healthy: true,
+		Ready:   t.config.Seed == "" || members > 1,
+		Details: map[string]interface{}{
+			"members":    members,
+			"agreements": agreements,
+		},
+	}
+	if !h.Ready {
+		h.Message = "tribe hasn't joined the members of its seed"
+	}
+	return h
+}
@@ -0,0 +1,228 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tribe
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/hashicorp/memberlist"
+	"github.com/intelsdi-x/snap/core/serror"
+	"github.com/pborman/uuid"
+)
+
+var (
+	errEncryptionDisabled = errors.New("The gossip isn't encrypted, there are no keys to rotate")
+	errInvalidKey         = errors.New("A key must be a base64 encoded AES key of 16, 24 or 32 bytes")
+)
+
+// newKeyring returns the keyring encrypting the gossip with the keys of the
+// keyring file, or else with the configured keys. It's nil when there are no
+// keys.
+func newKeyring(cfg *Config) (*memberlist.Keyring, error) {
+	keys := cfg.EncryptKeys
+	if cfg.KeyringFile != "" {
+		b, err := ioutil.ReadFile(cfg.KeyringFile)
+		switch {
+		case err == nil:
+			keys = nil
+			if err := json.Unmarshal(b, &keys); err != nil {
+				return nil, err
+			}
+		case !os.IsNotExist(err):
+			return nil, err
+		}
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	decoded := make([][]byte, 0, len(keys))
+	for _, k := range keys {
+		key, err := decodeKey(k)
+		if err != nil {
+			return nil, err
+		}
+		decoded = append(decoded, key)
+	}
+	return memberlist.NewKeyring(decoded, decoded[0])
+}
+
+func decodeKey(key string) ([]byte, error) {
+	b, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, errInvalidKey
+	}
+	switch len(b) {
+	case 16, 24, 32:
+		return b, nil
+	}
+	return nil, errInvalidKey
+}
+
+// keyFingerprint identifies a key without disclosing it
+func keyFingerprint(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
+
+// ListKeys returns the fingerprints of the keys the gossip is encrypted with,
+// the first one is the fingerprint of the primary key
+func (t *tribe) ListKeys() ([]string, serror.SnapError) {
+	keyring := t.config.MemberlistConfig.Keyring
+	if keyring == nil {
+		return nil, serror.New(errEncryptionDisabled)
+	}
+	keys := keyring.GetKeys()
+	fingerprints := make([]string, 0, len(keys))
+	for _, k := range keys {
+		fingerprints = append(fingerprints, keyFingerprint(base64.StdEncoding.EncodeToString(k)))
+	}
+	return fingerprints, nil
+}
+
+// InstallKey adds a key decrypting the gossip to every member, it has to be
+// installed on every member before it's used to encrypt the gossip
+func (t *tribe) InstallKey(key string) serror.SnapError {
+	return t.rotateKey(installKeyMsgType, key)
+}
+
+// UseKey makes an installed key the key encrypting the gossip of every member
+func (t *tribe) UseKey(key string) serror.SnapError {
+	return t.rotateKey(useKeyMsgType, key)
+}
+
+// RemoveKey removes a key from every member, the primary key can't be removed
+func (t *tribe) RemoveKey(key string) serror.SnapError {
+	return t.rotateKey(removeKeyMsgType, key)
+}
+
+func (t *tribe) rotateKey(mt msgType, key string) serror.SnapError {
+	fields := log.Fields{
+		"key": keyFingerprint(key),
+	}
+	if t.config.MemberlistConfig.Keyring == nil {
+		return serror.New(errEncryptionDisabled, fields)
+	}
+	if _, err := decodeKey(key); err != nil {
+		return serror.New(err, fields)
+	}
+	msg := &keyMsg{
+		LTime: t.clock.Increment(),
+		UUID:  uuid.New(),
+		Key:   key,
+		Type:  mt,
+	}
+	// the key is rotated locally first so that an error is returned
+	if err := t.applyKey(msg); err != nil {
+		return serror.New(err, fields)
+	}
+	t.mutex.Lock()
+	t.msgBuffer[msg.LTime%LTime(len(t.msgBuffer))] = msg
+	t.mutex.Unlock()
+	t.broadcast(mt, msg, nil)
+	return nil
+}
+
+func (t *tribe) handleKey(msg *keyMsg) bool {
+	t.mutex.Lock()
+	// update the clock if newer
+	t.clock.Update(msg.LTime)
+	if t.isDuplicate(msg) {
+		t.mutex.Unlock()
+		return false
+	}
+	t.msgBuffer[msg.LTime%LTime(len(t.msgBuffer))] = msg
+	t.mutex.Unlock()
+	t.emitIntent(msg.GetType(), msg.UUID, msg.LTime, "", keyFingerprint(msg.Key))
+
+	if err := t.applyKey(msg); err != nil {
+		t.logger.WithFields(log.Fields{
+			"_block": "handle-key",
+			"key":    keyFingerprint(msg.Key),
+			"type":   msg.GetType(),
+		}).Error(err)
+	}
+	return true
+}
+
+// applyKey rotates the keys of the local member and saves them to the keyring
+// file
+func (t *tribe) applyKey(msg *keyMsg) error {
+	keyring := t.config.MemberlistConfig.Keyring
+	if keyring == nil {
+		return errEncryptionDisabled
+	}
+	key, err := decodeKey(msg.Key)
+	if err != nil {
+		return err
+	}
+	switch msg.GetType() {
+	case installKeyMsgType:
+		err = keyring.AddKey(key)
+	case useKeyMsgType:
+		err = keyring.UseKey(key)
+	case removeKeyMsgType:
+		err = keyring.RemoveKey(key)
+	}
+	if err != nil {
+		return err
+	}
+	t.logger.WithFields(log.Fields{
+		"_block": "apply-key",
+		"key":    keyFingerprint(msg.Key),
+		"type":   msg.GetType(),
+	}).Info("gossip keys rotated")
+	return t.saveKeyring(keyring)
+}
+
+// saveKeyring writes the keys to the keyring file, the primary key first
+func (t *tribe) saveKeyring(keyring *memberlist.Keyring) error {
+	if t.config.KeyringFile == "" {
+		return nil
+	}
+	keys := []string{}
+	for _, k := range keyring.GetKeys() {
+		keys = append(keys, base64.StdEncoding.EncodeToString(k))
+	}
+	b, err := json.Marshal(keys)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(t.config.KeyringFile), filepath.Base(t.config.KeyringFile))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(b)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), t.config.KeyringFile)
+}
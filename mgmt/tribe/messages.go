@@ -45,6 +45,12 @@ const (
 	startTaskMsgType
 	getTaskStateMsgType
 	taskStateQueryResponseMsgType
+	setTaskStrategyMsgType
+	assignTasksMsgType
+	installKeyMsgType
+	useKeyMsgType
+	removeKeyMsgType
+	setTaskOverridesMsgType
 )
 
 var msgTypes = []string{
@@ -61,6 +67,12 @@ var msgTypes = []string{
 	"Start task",
 	"Get task state",
 	"Get task state response",
+	"Set task strategy",
+	"Assign tasks",
+	"Install key",
+	"Use key",
+	"Remove key",
+	"Set task overrides",
 }
 
 func (m msgType) String() string {
@@ -111,6 +123,12 @@ type agreementMsg struct {
 	MemberName    string
 	APIPort       int
 	Type          msgType
+	// TaskLabels select the tasks of an added agreement
+	TaskLabels map[string]string
+	// TaskStrategy and TaskReplicas set how the tasks of the agreement are
+	// assigned to its members
+	TaskStrategy string
+	TaskReplicas int
 }
 
 func (a *agreementMsg) ID() string {
@@ -141,6 +159,8 @@ type taskMsg struct {
 	StartOnCreate bool
 	AgreementName string
 	Type          msgType
+	// Overrides is the config of the task overridden on some members
+	Overrides map[string]agreement.TaskOverride
 }
 
 func (t *taskMsg) ID() string {
@@ -164,6 +184,68 @@ func (t *taskMsg) String() string {
 		t.GetType(), t.Agreement(), t.ID(), t.TaskID)
 }
 
+// assignmentMsg is broadcasted by the leader of an agreement when it assigns
+// the tasks of the agreement to its members
+type assignmentMsg struct {
+	LTime         LTime
+	UUID          string
+	AgreementName string
+	Leader        string
+	Assignment    map[string][]string
+	Type          msgType
+}
+
+func (a *assignmentMsg) ID() string {
+	return a.UUID
+}
+
+func (a *assignmentMsg) Time() LTime {
+	return a.LTime
+}
+
+func (a *assignmentMsg) GetType() msgType {
+	return a.Type
+}
+
+func (a *assignmentMsg) Agreement() string {
+	return a.AgreementName
+}
+
+func (a *assignmentMsg) String() string {
+	return fmt.Sprintf("msg type='%v' agreementName='%v' uuid='%v' leader='%v'",
+		a.GetType(), a.Agreement(), a.ID(), a.Leader)
+}
+
+// keyMsg rotates the keys the gossip is encrypted with, it's only readable by
+// the members with the primary key
+type keyMsg struct {
+	LTime LTime
+	UUID  string
+	Key   string
+	Type  msgType
+}
+
+func (k *keyMsg) ID() string {
+	return k.UUID
+}
+
+func (k *keyMsg) Time() LTime {
+	return k.LTime
+}
+
+func (k *keyMsg) GetType() msgType {
+	return k.Type
+}
+
+func (k *keyMsg) Agreement() string {
+	return ""
+}
+
+func (k *keyMsg) String() string {
+	return fmt.Sprintf("msg type='%v' uuid='%v' key='%v'",
+		k.GetType(), k.ID(), keyFingerprint(k.Key))
+}
+
 type taskStateQueryMsg struct {
 	LTime         LTime
 	UUID          string
@@ -0,0 +1,130 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tribe
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/intelsdi-x/snap/core/serror"
+	"github.com/intelsdi-x/snap/mgmt/tribe/agreement"
+	"github.com/intelsdi-x/snap/mgmt/tribe/worker"
+	"github.com/pborman/uuid"
+)
+
+// SetTaskOverrides sets the config of a task of an agreement overridden on
+// some members by member name. The members create the task with their
+// override, the ones which created it already create it again.
+func (t *tribe) SetTaskOverrides(agreementName, taskID string, overrides map[string]agreement.TaskOverride) serror.SnapError {
+	if err := t.canStartStopRemoveTask(agreement.Task{ID: taskID}, agreementName); err != nil {
+		return err
+	}
+	msg := &taskMsg{
+		LTime:         t.clock.Increment(),
+		TaskID:        taskID,
+		AgreementName: agreementName,
+		UUID:          uuid.New(),
+		Type:          setTaskOverridesMsgType,
+		Overrides:     overrides,
+	}
+	if t.handleSetTaskOverrides(msg) {
+		t.broadcast(setTaskOverridesMsgType, msg, nil)
+	}
+	return nil
+}
+
+func (t *tribe) handleSetTaskOverrides(msg *taskMsg) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	// update the clock if newer
+	t.clock.Update(msg.LTime)
+
+	if t.isDuplicate(msg) {
+		return false
+	}
+
+	t.msgBuffer[msg.LTime%LTime(len(t.msgBuffer))] = msg
+	t.emitIntent(msg.GetType(), msg.UUID, msg.LTime, msg.AgreementName, msg.TaskID)
+
+	if a, ok := t.agreements[msg.AgreementName]; ok {
+		if ok, _ := a.TaskAgreement.Tasks.Contains(agreement.Task{ID: msg.TaskID}); ok {
+			t.setTaskOverrides(a, msg)
+			return true
+		}
+	}
+
+	t.addTaskIntent(msg)
+	return true
+}
+
+func (t *tribe) processSetTaskOverridesIntents() bool {
+	for idx, v := range t.intentBuffer {
+		if v.GetType() == setTaskOverridesMsgType {
+			intent := v.(*taskMsg)
+			if a, ok := t.agreements[intent.AgreementName]; ok {
+				if ok, _ := a.TaskAgreement.Tasks.Contains(agreement.Task{ID: intent.TaskID}); ok {
+					t.intentBuffer = append(t.intentBuffer[:idx], t.intentBuffer[idx+1:]...)
+					t.setTaskOverrides(a, intent)
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// setTaskOverrides sets the overrides of the task, the local member creates
+// the task again when its override changed. It's called with the mutex
+// locked.
+func (t *tribe) setTaskOverrides(a *agreement.Agreement, msg *taskMsg) {
+	_, idx := a.TaskAgreement.Tasks.Contains(agreement.Task{ID: msg.TaskID})
+	local := t.memberlist.LocalNode().Name
+	previous := a.TaskAgreement.Tasks[idx].Overrides[local]
+	a.TaskAgreement.SetOverrides(msg.TaskID, msg.Overrides)
+
+	if _, ok := a.Members[local]; !ok || reflect.DeepEqual(previous, msg.Overrides[local]) {
+		return
+	}
+	task := a.TaskAgreement.Tasks[idx]
+	start := !task.Stopped
+	if a.TaskAgreement.Assigns() {
+		start = start && a.TaskAgreement.Assigned(task.ID, local)
+	}
+	t.taskWorkQueue <- worker.TaskRequest{
+		Task:        t.workerTask(task, start),
+		RequestType: worker.TaskUpdatedType,
+	}
+}
+
+// workerTask returns the task of an agreement created on the local member
+// with its override
+func (t *tribe) workerTask(task agreement.Task, startOnCreate bool) worker.Task {
+	wt := worker.Task{
+		ID:            task.ID,
+		StartOnCreate: startOnCreate,
+		Override:      task.Overrides[t.memberlist.LocalNode().Name],
+	}
+	for name := range task.Overrides {
+		wt.Overridden = append(wt.Overridden, name)
+	}
+	sort.Strings(wt.Overridden)
+	return wt
+}
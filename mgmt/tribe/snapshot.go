@@ -0,0 +1,121 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tribe
+
+import (
+	"sort"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/intelsdi-x/snap/core/serror"
+	"github.com/intelsdi-x/snap/mgmt/tribe/agreement"
+)
+
+// ExportAgreement returns the snapshot of an agreement: its settings, its
+// members, its plugins and its tasks
+func (t *tribe) ExportAgreement(name string) (*agreement.Snapshot, serror.SnapError) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	a, ok := t.agreements[name]
+	if !ok {
+		return nil, serror.New(errAgreementDoesNotExist, map[string]interface{}{"agreement_name": name})
+	}
+	s := &agreement.Snapshot{
+		Name:         a.Name,
+		TaskLabels:   a.TaskAgreement.Labels,
+		TaskStrategy: a.TaskAgreement.Strategy,
+		TaskReplicas: a.TaskAgreement.Replicas,
+		Members:      []string{},
+		Plugins:      []agreement.Plugin{},
+		Tasks:        []agreement.SnapshotTask{},
+	}
+	for m := range a.Members {
+		s.Members = append(s.Members, m)
+	}
+	sort.Strings(s.Members)
+	s.Plugins = append(s.Plugins, a.PluginAgreement.Plugins...)
+	for _, task := range a.TaskAgreement.Tasks {
+		s.Tasks = append(s.Tasks, agreement.SnapshotTask{
+			ID:        task.ID,
+			Stopped:   task.Stopped,
+			Overrides: task.Overrides,
+		})
+	}
+	return s, nil
+}
+
+// RestoreAgreement adds the agreement of a snapshot to the tribe. The members
+// of the snapshot in the tribe join it, the plugins of the snapshot loaded on
+// this member and the tasks of the snapshot existing on this member are
+// shared with them; the others are reported missing.
+func (t *tribe) RestoreAgreement(s *agreement.Snapshot) (*agreement.RestoreReport, serror.SnapError) {
+	fields := log.Fields{
+		"agreement": s.Name,
+	}
+	if err := t.AddAgreement(s.Name, s.TaskLabels); err != nil {
+		return nil, err
+	}
+	if s.TaskStrategy != "" {
+		if err := t.SetTaskAssignment(s.Name, s.TaskStrategy, s.TaskReplicas); err != nil {
+			return nil, err
+		}
+	}
+	report := &agreement.RestoreReport{}
+	for _, name := range s.Members {
+		if err := t.JoinAgreement(s.Name, name); err != nil {
+			t.logger.WithFields(fields).WithField("member-name", name).Warn(err.Error())
+			report.MissingMembers = append(report.MissingMembers, name)
+		}
+	}
+	for _, p := range s.Plugins {
+		if !t.isPluginLoaded(p) {
+			report.MissingPlugins = append(report.MissingPlugins, p)
+			continue
+		}
+		if err := t.AddPlugin(s.Name, p); err != nil {
+			return nil, serror.New(err, fields)
+		}
+	}
+	for _, task := range s.Tasks {
+		if _, err := t.taskManager.GetTask(task.ID); err != nil {
+			report.MissingTasks = append(report.MissingTasks, task.ID)
+			continue
+		}
+		// the task may already be shared with the agreement when it was
+		// created on this member with the labels of the agreement
+		err := t.AddTask(s.Name, agreement.Task{
+			ID:            task.ID,
+			StartOnCreate: !task.Stopped,
+			Overrides:     task.Overrides,
+		})
+		if err != nil && err.Error() != errTaskAlreadyExists.Error() {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+func (t *tribe) isPluginLoaded(p agreement.Plugin) bool {
+	for _, lp := range t.pluginCatalog.PluginCatalog() {
+		if lp.Name() == p.Name() && lp.Version() == p.Version() && lp.TypeName() == p.TypeName() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tribe
+
+import (
+	"errors"
+	"time"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/serror"
+	"github.com/intelsdi-x/snap/mgmt/tribe/agreement"
+)
+
+var errSyncTimeout = errors.New("Timed out waiting for the local member to apply the tribe intents")
+
+// syncInterval is the interval the state of the local member is checked at
+// while waiting for it to apply the intents of the tribe
+var syncInterval = 50 * time.Millisecond
+
+// Sync blocks until the local member applied the intents of the tribe: until
+// it created the tasks and loaded the plugins of its agreements and, when the
+// task ID isn't empty, until its agreements selecting the task share it as it
+// is on the local member. It returns an error when the timeout expires first.
+func (t *tribe) Sync(taskID string, timeout time.Duration) serror.SnapError {
+	deadline := time.After(timeout)
+	ticker := time.NewTicker(syncInterval)
+	defer ticker.Stop()
+	for !t.synced(taskID) {
+		select {
+		case <-ticker.C:
+		case <-deadline:
+			return serror.New(errSyncTimeout, map[string]interface{}{
+				"task-id": taskID,
+				"timeout": timeout.String(),
+			})
+		}
+	}
+	return nil
+}
+
+func (t *tribe) synced(taskID string) bool {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	m, ok := t.members[t.memberlist.LocalNode().Name]
+	if !ok {
+		return true
+	}
+	if m.PluginAgreement != nil {
+		for _, p := range m.PluginAgreement.Plugins {
+			if !t.isPluginLoaded(p) {
+				return false
+			}
+		}
+	}
+	for _, a := range m.TaskAgreements {
+		for _, task := range a.Tasks {
+			if _, err := t.taskManager.GetTask(task.ID); err != nil {
+				return false
+			}
+		}
+	}
+	if taskID == "" {
+		return true
+	}
+	task, err := t.taskManager.GetTask(taskID)
+	if err != nil {
+		// the task was removed, it's removed from every agreement as
+		// checked above
+		return true
+	}
+	for _, a := range m.TaskAgreements {
+		if !a.Selects(task.Labels()) {
+			continue
+		}
+		ok, idx := a.Tasks.Contains(agreement.Task{ID: taskID})
+		if !ok {
+			return false
+		}
+		switch task.State() {
+		case core.TaskSpinning, core.TaskFiring:
+			if a.Tasks[idx].Stopped {
+				return false
+			}
+		case core.TaskStopped, core.TaskStopping:
+			// the tasks of an agreement assigning them are stopped on
+			// the members they aren't assigned to
+			if !a.Tasks[idx].Stopped && !a.Assigns() {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -78,6 +78,7 @@ type tribe struct {
 	taskStartStopCache *cache
 	taskStateResponses map[string]*taskStateQueryResponse
 	members            map[string]*agreement.Member
+	suspects           map[string]*time.Timer
 	tags               map[string]string
 	EventManager       *gomit.EventController
 	config             *Config
@@ -95,6 +96,9 @@ func New(cfg *Config) (*tribe, error) {
 	cfg.MemberlistConfig.Name = cfg.Name
 	cfg.MemberlistConfig.BindAddr = cfg.BindAddr
 	cfg.MemberlistConfig.BindPort = cfg.BindPort
+	if cfg.WAN {
+		setWANProfile(cfg.MemberlistConfig)
+	}
 	logger := logger.WithFields(log.Fields{
 		"port": cfg.MemberlistConfig.BindPort,
 		"addr": cfg.MemberlistConfig.BindAddr,
@@ -104,6 +108,7 @@ func New(cfg *Config) (*tribe, error) {
 	tribe := &tribe{
 		agreements:         map[string]*agreement.Agreement{},
 		members:            map[string]*agreement.Member{},
+		suspects:           map[string]*time.Timer{},
 		taskStateResponses: map[string]*taskStateQueryResponse{},
 		taskStartStopCache: newCache(),
 		msgBuffer:          make([]msg, 512),
@@ -121,18 +126,32 @@ func New(cfg *Config) (*tribe, error) {
 		config:          cfg,
 		EventManager:    gomit.NewEventController(),
 	}
+	if cfg.Zone != "" {
+		tribe.tags[agreement.Zone] = cfg.Zone
+	}
 
 	tribe.broadcasts = &memberlist.TransmitLimitedQueue{
 		NumNodes: func() int {
 			return len(tribe.memberlist.Members())
 		},
-		RetransmitMult: memberlist.DefaultLANConfig().RetransmitMult,
+		RetransmitMult: cfg.MemberlistConfig.RetransmitMult,
 	}
 
 	//configure delegates
 	cfg.MemberlistConfig.Delegate = &delegate{tribe: tribe}
 	cfg.MemberlistConfig.Events = &memberDelegate{tribe: tribe}
 
+	// the gossip is encrypted and authenticated with the keys, the members
+	// without them can't read nor send messages
+	keyring, err := newKeyring(cfg)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	if keyring != nil {
+		cfg.MemberlistConfig.Keyring = keyring
+	}
+
 	ml, err := memberlist.Create(cfg.MemberlistConfig)
 	if err != nil {
 		logger.Error(err)
@@ -210,6 +229,12 @@ func (t *tribe) SetTaskManager(m worker.ManagesTasks) {
 	t.taskManager = m
 }
 
+// RegisterEventHandler registers a handler of the events of the tribe, e.g.
+// the members joining and leaving
+func (t *tribe) RegisterEventHandler(name string, h gomit.Handler) error {
+	return t.EventManager.RegisterHandler(name, h)
+}
+
 func (t *tribe) Name() string {
 	return "tribe"
 }
@@ -230,6 +255,10 @@ func (t *tribe) Start() error {
 		t.pluginCatalog,
 		t.taskManager,
 		t)
+	if len(t.config.Relays) > 0 {
+		t.workerWaitGroup.Add(1)
+		go t.relay()
+	}
 	return nil
 }
 
@@ -245,6 +274,12 @@ func (t *tribe) Stop() {
 	if err != nil {
 		logger.Error(err)
 	}
+	t.mutex.Lock()
+	for name, timer := range t.suspects {
+		timer.Stop()
+		delete(t.suspects, name)
+	}
+	t.mutex.Unlock()
 	close(t.workerQuitChan)
 	t.workerWaitGroup.Wait()
 }
@@ -354,6 +389,7 @@ func (t *tribe) HandleGomitEvent(e gomit.Event) {
 				"event":                e.Namespace(),
 				"task-id":              v.TaskID,
 				"task-start-on-create": v.StartOnCreate,
+				"task-labels":          v.Labels,
 			}).Debugf("handling task create event")
 			task := agreement.Task{
 				ID:            v.TaskID,
@@ -362,7 +398,7 @@ func (t *tribe) HandleGomitEvent(e gomit.Event) {
 			if m, ok := t.members[t.memberlist.LocalNode().Name]; ok {
 				if m.TaskAgreements != nil {
 					for n, a := range m.TaskAgreements {
-						if ok, _ := a.Tasks.Contains(task); !ok {
+						if ok, _ := a.Tasks.Contains(task); !ok && a.Selects(v.Labels) {
 							t.AddTask(n, task)
 						}
 					}
@@ -561,6 +597,7 @@ func (t *tribe) AddTask(agreementName string, task agreement.Task) serror.SnapEr
 		AgreementName: agreementName,
 		UUID:          uuid.New(),
 		Type:          addTaskMsgType,
+		Overrides:     task.Overrides,
 	}
 	if t.handleAddTask(msg) {
 		t.broadcast(addTaskMsgType, msg, nil)
@@ -620,7 +657,9 @@ func (t *tribe) StartTask(agreementName string, task agreement.Task) serror.Snap
 	return nil
 }
 
-func (t *tribe) AddAgreement(name string) serror.SnapError {
+// AddAgreement adds an agreement, only the tasks with all the given labels are
+// shared with its members
+func (t *tribe) AddAgreement(name string, taskLabels map[string]string) serror.SnapError {
 	if _, ok := t.agreements[name]; ok {
 		fields := log.Fields{
 			"agreement": name,
@@ -632,6 +671,7 @@ func (t *tribe) AddAgreement(name string) serror.SnapError {
 		AgreementName: name,
 		UUID:          uuid.New(),
 		Type:          addAgreementMsgType,
+		TaskLabels:    taskLabels,
 	}
 	if t.handleAddAgreement(msg) {
 		t.broadcast(addAgreementMsgType, msg, nil)
@@ -698,7 +738,9 @@ func (t *tribe) processIntents() {
 			t.processJoinAgreementIntents() &&
 			t.processLeaveAgreementIntents() &&
 			t.processAddTaskIntents() &&
-			t.processRemoveTaskIntents() {
+			t.processRemoveTaskIntents() &&
+			t.processSetTaskStrategyIntents() &&
+			t.processSetTaskOverridesIntents() {
 			return
 		}
 	}
@@ -754,17 +796,16 @@ func (t *tribe) processAddTaskIntents() bool {
 			intent := v.(*taskMsg)
 			if a, ok := t.agreements[intent.AgreementName]; ok {
 				if ok, _ := a.TaskAgreement.Tasks.Contains(agreement.Task{ID: intent.TaskID}); !ok {
-					a.TaskAgreement.Tasks = append(a.TaskAgreement.Tasks, agreement.Task{ID: intent.TaskID})
+					task := agreement.Task{ID: intent.TaskID, Stopped: !intent.StartOnCreate, Overrides: intent.Overrides}
+					a.TaskAgreement.Tasks = append(a.TaskAgreement.Tasks, task)
 					t.intentBuffer = append(t.intentBuffer[:idx], t.intentBuffer[idx+1:]...)
 
 					work := worker.TaskRequest{
-						Task: worker.Task{
-							ID:            intent.TaskID,
-							StartOnCreate: intent.StartOnCreate,
-						},
+						Task:        t.workerTask(task, intent.StartOnCreate && !a.TaskAgreement.Assigns()),
 						RequestType: worker.TaskCreatedType,
 					}
 					t.taskWorkQueue <- work
+					t.rebalance(intent.AgreementName)
 
 					return false
 				}
@@ -782,6 +823,7 @@ func (t *tribe) processRemoveTaskIntents() bool {
 				if ok, idx := t.agreements[intent.AgreementName].TaskAgreement.Tasks.Contains(agreement.Task{ID: intent.TaskID}); ok {
 					t.agreements[intent.AgreementName].TaskAgreement.Tasks = append(t.agreements[intent.AgreementName].TaskAgreement.Tasks[:idx], t.agreements[intent.AgreementName].TaskAgreement.Tasks[idx+1:]...)
 					t.intentBuffer = append(t.intentBuffer[:k], t.intentBuffer[k+1:]...)
+					t.rebalance(intent.AgreementName)
 					return false
 				}
 			}
@@ -868,6 +910,7 @@ func (t *tribe) handleRemovePlugin(msg *pluginMsg) bool {
 	}
 
 	t.msgBuffer[msg.LTime%LTime(len(t.msgBuffer))] = msg
+	t.emitIntent(msg.GetType(), msg.UUID, msg.LTime, msg.AgreementName, pluginSubject(msg.Plugin))
 
 	if _, ok := t.agreements[msg.Agreement()]; ok {
 		if t.agreements[msg.AgreementName].PluginAgreement.Remove(msg.Plugin) {
@@ -903,6 +946,7 @@ func (t *tribe) handleAddPlugin(msg *pluginMsg) bool {
 	}
 
 	t.msgBuffer[msg.LTime%LTime(len(t.msgBuffer))] = msg
+	t.emitIntent(msg.GetType(), msg.UUID, msg.LTime, msg.AgreementName, pluginSubject(msg.Plugin))
 
 	if _, ok := t.agreements[msg.AgreementName]; ok {
 		if t.agreements[msg.AgreementName].PluginAgreement.Add(msg.Plugin) {
@@ -939,18 +983,20 @@ func (t *tribe) handleAddTask(msg *taskMsg) bool {
 	}
 
 	t.msgBuffer[msg.LTime%LTime(len(t.msgBuffer))] = msg
+	t.emitIntent(msg.GetType(), msg.UUID, msg.LTime, msg.AgreementName, msg.TaskID)
 
-	if _, ok := t.agreements[msg.AgreementName]; ok {
-		if t.agreements[msg.AgreementName].TaskAgreement.Add(agreement.Task{ID: msg.TaskID}) {
+	if a, ok := t.agreements[msg.AgreementName]; ok {
+		task := agreement.Task{ID: msg.TaskID, Stopped: !msg.StartOnCreate, Overrides: msg.Overrides}
+		if a.TaskAgreement.Add(task) {
 
+			// the tasks of an agreement assigning them are started once
+			// they're assigned to the local member
 			work := worker.TaskRequest{
-				Task: worker.Task{
-					ID:            msg.TaskID,
-					StartOnCreate: msg.StartOnCreate,
-				},
+				Task:        t.workerTask(task, msg.StartOnCreate && !a.TaskAgreement.Assigns()),
 				RequestType: worker.TaskCreatedType,
 			}
 			t.taskWorkQueue <- work
+			t.rebalance(msg.AgreementName)
 
 			t.processIntents()
 			return true
@@ -973,6 +1019,7 @@ func (t *tribe) handleRemoveTask(msg *taskMsg) bool {
 	}
 
 	t.msgBuffer[msg.LTime%LTime(len(t.msgBuffer))] = msg
+	t.emitIntent(msg.GetType(), msg.UUID, msg.LTime, msg.AgreementName, msg.TaskID)
 
 	if _, ok := t.agreements[msg.Agreement()]; ok {
 		if t.agreements[msg.AgreementName].TaskAgreement.Remove(agreement.Task{ID: msg.TaskID}) {
@@ -984,6 +1031,7 @@ func (t *tribe) handleRemoveTask(msg *taskMsg) bool {
 				RequestType: worker.TaskRemovedType,
 			}
 			t.taskWorkQueue <- work
+			t.rebalance(msg.AgreementName)
 
 			t.processIntents()
 			return true
@@ -1006,14 +1054,23 @@ func (t *tribe) handleStartTask(msg *taskMsg) bool {
 	}
 
 	t.msgBuffer[msg.LTime%LTime(len(t.msgBuffer))] = msg
+	t.emitIntent(msg.GetType(), msg.UUID, msg.LTime, msg.AgreementName, msg.TaskID)
 
-	if _, ok := t.agreements[msg.Agreement()]; ok {
+	if a, ok := t.agreements[msg.Agreement()]; ok {
+		a.TaskAgreement.SetStopped(msg.TaskID, false)
 
 		if ok := t.taskStartStopCache.put(msg, t.getTimeout()); !ok {
 			// A cache entry exists; return and do not broadcast event again
 			return false
 		}
 
+		if a.TaskAgreement.Assigns() {
+			if _, ok := a.Members[t.memberlist.LocalNode().Name]; ok {
+				t.runAssignedTask(agreement.Task{ID: msg.TaskID}, a.TaskAgreement.Assigned(msg.TaskID, t.memberlist.LocalNode().Name))
+			}
+			return true
+		}
+
 		work := worker.TaskRequest{
 			Task: worker.Task{
 				ID: msg.TaskID,
@@ -1040,8 +1097,10 @@ func (t *tribe) handleStopTask(msg *taskMsg) bool {
 	}
 
 	t.msgBuffer[msg.LTime%LTime(len(t.msgBuffer))] = msg
+	t.emitIntent(msg.GetType(), msg.UUID, msg.LTime, msg.AgreementName, msg.TaskID)
 
-	if _, ok := t.agreements[msg.Agreement()]; ok {
+	if a, ok := t.agreements[msg.Agreement()]; ok {
+		a.TaskAgreement.SetStopped(msg.TaskID, true)
 
 		if ok := t.taskStartStopCache.put(msg, t.getTimeout()); !ok {
 			// A cache entry exists; return and do not broadcast event again
@@ -1062,13 +1121,40 @@ func (t *tribe) handleStopTask(msg *taskMsg) bool {
 	return true
 }
 
+// emitIntent emits the event of an intent handled for the first time, the
+// REST API records them to its audit log
+func (t *tribe) emitIntent(typ msgType, uuid string, ltime LTime, agreementName, subject string) {
+	t.EventManager.Emit(&tribe_event.IntentReceivedEvent{
+		Type:      typ.String(),
+		UUID:      uuid,
+		LTime:     uint64(ltime),
+		Agreement: agreementName,
+		Subject:   subject,
+	})
+}
+
+// pluginSubject identifies the plugin of an intent, "<type>:<name>:<version>"
+func pluginSubject(p agreement.Plugin) string {
+	return fmt.Sprintf("%s:%s:%d", p.TypeName(), p.Name(), p.Version())
+}
+
 func (t *tribe) handleMemberJoin(n *memberlist.Node) {
 	t.mutex.Lock()
 	defer t.mutex.Unlock()
+	if timer, ok := t.suspects[n.Name]; ok {
+		// the member of the remote zone is back before it's removed
+		timer.Stop()
+		delete(t.suspects, n.Name)
+		t.logger.WithFields(log.Fields{
+			"_block": "handle-member-join",
+			"member": n.Name,
+		}).Infoln("suspected member is back")
+	}
 	if _, ok := t.members[n.Name]; !ok {
 		t.members[n.Name] = agreement.NewMember(n)
 		t.members[n.Name].Tags = t.decodeTags(n.Meta)
 		t.members[n.Name].Tags["host"] = n.Addr.String()
+		defer t.EventManager.Emit(&tribe_event.MemberJoinedEvent{Name: n.Name, Addr: n.Addr.String()})
 	}
 	t.processIntents()
 }
@@ -1077,14 +1163,34 @@ func (t *tribe) handleMemberLeave(n *memberlist.Node) {
 	t.mutex.Lock()
 	defer t.mutex.Unlock()
 	if m, ok := t.members[n.Name]; ok {
-		if m.PluginAgreement != nil {
-			delete(t.agreements[m.PluginAgreement.Name].Members, n.Name)
+		if t.isRemoteZone(m) {
+			t.suspect(n)
+			return
 		}
-		for k := range m.TaskAgreements {
-			delete(t.agreements[k].Members, n.Name)
+		t.removeMember(n, m)
+	}
+}
+
+// removeMember removes the member which left from its agreements. It's called
+// with the mutex locked.
+func (t *tribe) removeMember(n *memberlist.Node, m *agreement.Member) {
+	// the agreements may have been removed while the member was suspected
+	if m.PluginAgreement != nil {
+		if a, ok := t.agreements[m.PluginAgreement.Name]; ok {
+			delete(a.Members, n.Name)
 		}
-		delete(t.members, n.Name)
 	}
+	for k := range m.TaskAgreements {
+		if a, ok := t.agreements[k]; ok {
+			delete(a.Members, n.Name)
+		}
+	}
+	delete(t.members, n.Name)
+	// the tasks of the failed member are assigned to the others
+	for k := range m.TaskAgreements {
+		t.rebalance(k)
+	}
+	t.EventManager.Emit(&tribe_event.MemberLeftEvent{Name: n.Name, Addr: n.Addr.String()})
 }
 
 func (t *tribe) handleMemberUpdate(n *memberlist.Node) {
@@ -1108,10 +1214,13 @@ func (t *tribe) handleAddAgreement(msg *agreementMsg) bool {
 
 	// add msg to seen buffer
 	t.msgBuffer[msg.LTime%LTime(len(t.msgBuffer))] = msg
+	t.emitIntent(msg.GetType(), msg.UUID, msg.LTime, msg.AgreementName, "")
 
 	// add agreement
 	if _, ok := t.agreements[msg.AgreementName]; !ok {
-		t.agreements[msg.AgreementName] = agreement.New(msg.AgreementName)
+		a := agreement.New(msg.AgreementName)
+		a.TaskAgreement.Labels = msg.TaskLabels
+		t.agreements[msg.AgreementName] = a
 		t.processIntents()
 		return true
 	}
@@ -1132,6 +1241,7 @@ func (t *tribe) handleRemoveAgreement(msg *agreementMsg) bool {
 
 	// add msg to seen buffer
 	t.msgBuffer[msg.LTime%LTime(len(t.msgBuffer))] = msg
+	t.emitIntent(msg.GetType(), msg.UUID, msg.LTime, msg.AgreementName, "")
 
 	if _, ok := t.agreements[msg.AgreementName]; ok {
 		delete(t.agreements, msg.AgreementName)
@@ -1155,6 +1265,7 @@ func (t *tribe) handleJoinAgreement(msg *agreementMsg) bool {
 	}
 
 	t.msgBuffer[msg.LTime%LTime(len(t.msgBuffer))] = msg
+	t.emitIntent(msg.GetType(), msg.UUID, msg.LTime, msg.AgreementName, msg.MemberName)
 
 	if err := t.joinAgreement(msg); err == nil {
 		t.processIntents()
@@ -1177,6 +1288,7 @@ func (t *tribe) handleLeaveAgreement(msg *agreementMsg) bool {
 	}
 
 	t.msgBuffer[msg.LTime%LTime(len(t.msgBuffer))] = msg
+	t.emitIntent(msg.GetType(), msg.UUID, msg.LTime, msg.AgreementName, msg.MemberName)
 
 	if err := t.leaveAgreement(msg); err == nil {
 		t.processIntents()
@@ -1298,9 +1410,11 @@ func (t *tribe) joinAgreement(msg *agreementMsg) serror.SnapError {
 
 	// update the agreements membership
 	t.agreements[msg.Agreement()].Members[msg.MemberName] = t.members[msg.MemberName]
+	t.rebalance(msg.Agreement())
 
 	// get plugins and tasks if this is the node joining
 	if msg.MemberName == t.memberlist.LocalNode().Name {
+		assigns := t.agreements[msg.Agreement()].TaskAgreement.Assigns()
 		go func(a *agreement.Agreement) {
 			for _, p := range a.PluginAgreement.Plugins {
 				ptype, _ := core.ToPluginType(p.TypeName())
@@ -1318,14 +1432,13 @@ func (t *tribe) joinAgreement(msg *agreementMsg) serror.SnapError {
 			for _, tsk := range a.TaskAgreement.Tasks {
 				state := t.TaskStateQuery(msg.Agreement(), tsk.ID)
 				startOnCreate := false
-				if state == core.TaskSpinning || state == core.TaskFiring {
+				// the tasks of an agreement assigning them are started once
+				// they're assigned to the local member
+				if !assigns && (state == core.TaskSpinning || state == core.TaskFiring) {
 					startOnCreate = true
 				}
 				work := worker.TaskRequest{
-					Task: worker.Task{
-						ID:            tsk.ID,
-						StartOnCreate: startOnCreate,
-					},
+					Task:        t.workerTask(tsk, startOnCreate),
 					RequestType: worker.TaskCreatedType,
 				}
 				t.taskWorkQueue <- work
@@ -1345,6 +1458,7 @@ func (t *tribe) leaveAgreement(msg *agreementMsg) serror.SnapError {
 	if _, ok := t.members[msg.MemberName].TaskAgreements[msg.Agreement()]; ok {
 		delete(t.members[msg.MemberName].TaskAgreements, msg.Agreement())
 	}
+	t.rebalance(msg.Agreement())
 
 	return nil
 }
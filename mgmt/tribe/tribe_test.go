@@ -22,17 +22,30 @@ limitations under the License.
 package tribe
 
 import (
+	"bytes"
+	"encoding/base64"
 	"fmt"
+	"io/ioutil"
 	"math/rand"
 	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	log "github.com/Sirupsen/logrus"
+	"github.com/hashicorp/memberlist"
+	"github.com/intelsdi-x/gomit"
 	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/scheduler_event"
 	"github.com/intelsdi-x/snap/core/serror"
+	"github.com/intelsdi-x/snap/mgmt/rest/v1/rbody"
 	"github.com/intelsdi-x/snap/mgmt/tribe/agreement"
 	"github.com/intelsdi-x/snap/pkg/schedule"
 	"github.com/intelsdi-x/snap/scheduler/wmap"
@@ -53,30 +66,47 @@ func (m *mockTaskManager) RemoveTaskTribe(id string) error             { return
 
 type mockTask struct{}
 
-func (t *mockTask) ID() string                                { return "" }
-func (t *mockTask) State() core.TaskState                     { return core.TaskSpinning }
-func (t *mockTask) HitCount() uint                            { return 0 }
-func (t *mockTask) GetName() string                           { return "" }
-func (t *mockTask) SetName(string)                            { return }
-func (t *mockTask) SetID(string)                              { return }
-func (t *mockTask) MissedCount() uint                         { return 0 }
-func (t *mockTask) FailedCount() uint                         { return 0 }
-func (t *mockTask) LastFailureMessage() string                { return "" }
-func (t *mockTask) LastRunTime() *time.Time                   { return nil }
-func (t *mockTask) CreationTime() *time.Time                  { return nil }
-func (t *mockTask) DeadlineDuration() time.Duration           { return 0 }
-func (t *mockTask) SetDeadlineDuration(time.Duration)         { return }
-func (t *mockTask) SetTaskID(id string)                       { return }
-func (t *mockTask) SetStopOnFailure(int)                      { return }
-func (t *mockTask) GetStopOnFailure() int                     { return 0 }
-func (t *mockTask) Option(...core.TaskOption) core.TaskOption { return core.TaskDeadlineDuration(0) }
-func (t *mockTask) WMap() *wmap.WorkflowMap                   { return nil }
-func (t *mockTask) Schedule() schedule.Schedule               { return nil }
-func (t *mockTask) MaxFailures() int                          { return 10 }
-func (t *mockTask) MaxMetricsBuffer() int64                   { return 0 }
-func (t *mockTask) SetMaxMetricsBuffer(int64)                 {}
-func (t *mockTask) MaxCollectDuration() time.Duration         { return time.Second }
-func (t *mockTask) SetMaxCollectDuration(time.Duration)       {}
+func (t *mockTask) ID() string                                            { return "" }
+func (t *mockTask) State() core.TaskState                                 { return core.TaskSpinning }
+func (t *mockTask) HitCount() uint                                        { return 0 }
+func (t *mockTask) GetName() string                                       { return "" }
+func (t *mockTask) SetName(string)                                        { return }
+func (t *mockTask) SetID(string)                                          { return }
+func (t *mockTask) MissedCount() uint                                     { return 0 }
+func (t *mockTask) FailedCount() uint                                     { return 0 }
+func (t *mockTask) LastFailureMessage() string                            { return "" }
+func (t *mockTask) LastRunTime() *time.Time                               { return nil }
+func (t *mockTask) CreationTime() *time.Time                              { return nil }
+func (t *mockTask) DeadlineDuration() time.Duration                       { return 0 }
+func (t *mockTask) SetDeadlineDuration(time.Duration)                     { return }
+func (t *mockTask) SetTaskID(id string)                                   { return }
+func (t *mockTask) SetStopOnFailure(int)                                  { return }
+func (t *mockTask) GetStopOnFailure() int                                 { return 0 }
+func (t *mockTask) Option(...core.TaskOption) core.TaskOption             { return core.TaskDeadlineDuration(0) }
+func (t *mockTask) WMap() *wmap.WorkflowMap                               { return nil }
+func (t *mockTask) Schedule() schedule.Schedule                           { return nil }
+func (t *mockTask) MaxFailures() int                                      { return 10 }
+func (t *mockTask) MaxMetricsBuffer() int64                               { return 0 }
+func (t *mockTask) SetMaxMetricsBuffer(int64)                             {}
+func (t *mockTask) MaxCollectDuration() time.Duration                     { return time.Second }
+func (t *mockTask) SetMaxCollectDuration(time.Duration)                   {}
+func (t *mockTask) Dependencies() []string                                { return nil }
+func (t *mockTask) SetDependencies([]string)                              {}
+func (t *mockTask) DependencyStates() map[string]core.TaskDependencyState { return nil }
+func (t *mockTask) RunDeadline() time.Duration                            { return 0 }
+func (t *mockTask) SetRunDeadline(time.Duration)                          {}
+func (t *mockTask) OverrunPolicy() string                                 { return "" }
+func (t *mockTask) SetOverrunPolicy(string)                               {}
+func (t *mockTask) Priority() string                                      { return "" }
+func (t *mockTask) SetPriority(string)                                    {}
+func (t *mockTask) Labels() map[string]string                             { return nil }
+func (t *mockTask) SetLabels(map[string]string)                           {}
+func (t *mockTask) OverrunCount() uint                                    { return 0 }
+func (t *mockTask) RetryStats() []core.TaskStepRetryStats                 { return nil }
+func (t *mockTask) CircuitBreakers() []core.TaskStepCircuitBreaker        { return nil }
+func (t *mockTask) BatchStats() []core.TaskStepBatchStats                 { return nil }
+func (t *mockTask) Buffer() *core.TaskBuffer                              { return nil }
+func (t *mockTask) SetBuffer(*core.TaskBuffer)                            {}
 
 func getTestConfig() *Config {
 	cfg := GetDefaultConfig()
@@ -139,7 +169,7 @@ func TestTribeFullStateSync(t *testing.T) {
 		wg.Wait()
 		Convey("agreements are added", func() {
 			t := tribes[rand.Intn(len(tribes))]
-			serr := t.AddAgreement(agreement1)
+			serr := t.AddAgreement(agreement1, nil)
 			So(serr, ShouldBeNil)
 			err := t.AddPlugin(agreement1, plugin1)
 			So(err, ShouldBeNil)
@@ -206,7 +236,7 @@ func TestTribeFullStateSyncOnJoin(t *testing.T) {
 		seed.SetTaskManager(taskManager)
 		tribes = append(tribes, seed)
 		Convey("agreements are added", func() {
-			seed.AddAgreement(agreement1)
+			seed.AddAgreement(agreement1, nil)
 			seed.JoinAgreement(agreement1, seed.memberlist.LocalNode().Name)
 			seed.AddPlugin(agreement1, plugin1)
 			seed.AddPlugin(agreement1, plugin2)
@@ -348,7 +378,7 @@ func TestTribeTaskAgreements(t *testing.T) {
 					So(len(t.intentBuffer), ShouldEqual, 1)
 					err := t.AddTask(agreementName, task1)
 					So(err.Error(), ShouldResemble, errAgreementDoesNotExist.Error())
-					err = t.AddAgreement(agreementName)
+					err = t.AddAgreement(agreementName, nil)
 					So(err, ShouldBeNil)
 					So(len(t.intentBuffer), ShouldEqual, 0)
 					So(len(t.agreements[agreementName].TaskAgreement.Tasks), ShouldEqual, 1)
@@ -363,7 +393,7 @@ func TestTribeTaskAgreements(t *testing.T) {
 							err = t.RemoveTask("doesn't exist", task1)
 							So(err.Error(), ShouldResemble, errAgreementDoesNotExist.Error())
 							Convey("joining an agreement with tasks", func() {
-								err := t.AddAgreement(agreementName2)
+								err := t.AddAgreement(agreementName2, nil)
 								So(err, ShouldBeNil)
 								err = t.AddTask(agreementName2, task2)
 								So(err, ShouldBeNil)
@@ -533,9 +563,9 @@ func TestTribeAgreements(t *testing.T) {
 						t.broadcast(addPluginMsgType, msg, nil)
 
 						Convey("an add agreement", func() {
-							err := t.AddAgreement(agreementName)
+							err := t.AddAgreement(agreementName, nil)
 							So(err, ShouldBeNil)
-							err = t.AddAgreement(agreementName)
+							err = t.AddAgreement(agreementName, nil)
 							So(err.Error(), ShouldResemble, errAgreementAlreadyExists.Error())
 							var wg sync.WaitGroup
 							for _, t := range tribes {
@@ -655,7 +685,7 @@ func TestTribeMembership(t *testing.T) {
 			Convey("Adds an agreement", func(c C) {
 				a := "agreement1"
 				t := tribes[numOfTribes-1]
-				t.AddAgreement("agreement1")
+				t.AddAgreement("agreement1", nil)
 				var wg sync.WaitGroup
 				for _, t := range tribes {
 					wg.Add(1)
@@ -805,7 +835,7 @@ func TestTribePluginAgreement(t *testing.T) {
 					So(len(tribes[rand.Intn(len(tribes))].members), ShouldEqual, len(tribes))
 
 					Convey("Handles a 'add agreement' message broadcasted across the cluster", func(c C) {
-						tribes[0].AddAgreement("clan1")
+						tribes[0].AddAgreement("clan1", nil)
 						var wg sync.WaitGroup
 						for _, t := range tribes {
 							wg.Add(1)
@@ -1159,3 +1189,386 @@ func getAvailablePort() int {
 	defer l.Close()
 	return l.Addr().(*net.TCPAddr).Port
 }
+
+func TestTribeTaskAgreementLabels(t *testing.T) {
+	log.SetLevel(log.WarnLevel)
+	Convey("A tribe with an agreement sharing the tasks with some labels", t, func() {
+		tr, err := New(getTestConfig())
+		So(err, ShouldBeNil)
+		tr.SetTaskManager(&mockTaskManager{})
+		So(tr.AddAgreement("prod", map[string]string{"env": "prod"}), ShouldBeNil)
+		So(tr.JoinAgreement("prod", tr.memberlist.LocalNode().Name), ShouldBeNil)
+		So(tr.agreements["prod"].TaskAgreement.Labels, ShouldResemble, map[string]string{"env": "prod"})
+
+		Convey("shares the created tasks with the labels", func() {
+			id := uuid.New()
+			tr.HandleGomitEvent(gomit.Event{Body: &scheduler_event.TaskCreatedEvent{
+				TaskID: id,
+				Source: "user",
+				Labels: map[string]string{"env": "prod", "team": "web"},
+			}})
+			ok, _ := tr.agreements["prod"].TaskAgreement.Tasks.Contains(agreement.Task{ID: id})
+			So(ok, ShouldBeTrue)
+		})
+		Convey("doesn't share the created tasks without the labels", func() {
+			id := uuid.New()
+			tr.HandleGomitEvent(gomit.Event{Body: &scheduler_event.TaskCreatedEvent{
+				TaskID: id,
+				Source: "user",
+				Labels: map[string]string{"env": "dev"},
+			}})
+			ok, _ := tr.agreements["prod"].TaskAgreement.Tasks.Contains(agreement.Task{ID: id})
+			So(ok, ShouldBeFalse)
+		})
+	})
+}
+
+func TestTribeTaskAssignment(t *testing.T) {
+	log.SetLevel(log.WarnLevel)
+	Convey("The task assignment strategies", t, func() {
+		tasks := []string{"t1", "t2", "t3", "t4"}
+		members := []string{"m3", "m1", "m2"}
+		Convey("all assigns every task to every member", func() {
+			s, err := agreement.GetStrategy(agreement.StrategyAll)
+			So(err, ShouldBeNil)
+			for _, m := range s.Assign(tasks, members, 1) {
+				So(m, ShouldResemble, []string{"m1", "m2", "m3"})
+			}
+		})
+		Convey("replicas and hash assign each task to distinct members", func() {
+			for _, name := range []string{agreement.StrategyReplicas, agreement.StrategyHash} {
+				s, err := agreement.GetStrategy(name)
+				So(err, ShouldBeNil)
+				assignment := s.Assign(tasks, members, 2)
+				So(len(assignment), ShouldEqual, len(tasks))
+				for _, m := range assignment {
+					So(len(m), ShouldEqual, 2)
+					So(m[0], ShouldNotEqual, m[1])
+				}
+				So(s.Assign(tasks, members, 2), ShouldResemble, assignment)
+			}
+		})
+		Convey("hash keeps the tasks of the remaining members when one fails", func() {
+			s, err := agreement.GetStrategy(agreement.StrategyHash)
+			So(err, ShouldBeNil)
+			before := s.Assign(tasks, members, 1)
+			after := s.Assign(tasks, []string{"m1", "m2"}, 1)
+			for _, id := range tasks {
+				if before[id][0] != "m3" {
+					So(after[id], ShouldResemble, before[id])
+				}
+			}
+		})
+		Convey("an unknown strategy is an error", func() {
+			_, err := agreement.GetStrategy("random")
+			So(err, ShouldNotBeNil)
+		})
+		Convey("the leader is the member with the smallest name", func() {
+			So(agreement.ElectLeader(members), ShouldEqual, "m1")
+			So(agreement.ElectLeader(nil), ShouldEqual, "")
+		})
+	})
+	Convey("A tribe with an agreement assigning its tasks", t, func() {
+		tr, err := New(getTestConfig())
+		So(err, ShouldBeNil)
+		tr.SetTaskManager(&mockTaskManager{})
+		local := tr.memberlist.LocalNode().Name
+		So(tr.AddAgreement("prod", nil), ShouldBeNil)
+		So(tr.JoinAgreement("prod", local), ShouldBeNil)
+		So(tr.SetTaskAssignment("prod", agreement.StrategyReplicas, 1), ShouldBeNil)
+		ta := tr.agreements["prod"].TaskAgreement
+		So(ta.Strategy, ShouldEqual, agreement.StrategyReplicas)
+		So(ta.Leader, ShouldEqual, local)
+
+		Convey("assigns the added tasks to the local member", func() {
+			id := uuid.New()
+			So(tr.AddTask("prod", agreement.Task{ID: id, StartOnCreate: true}), ShouldBeNil)
+			So(ta.Assignment[id], ShouldResemble, []string{local})
+			So(ta.Assigned(id, local), ShouldBeTrue)
+		})
+		Convey("records the tasks stopped in the agreement", func() {
+			id := uuid.New()
+			So(tr.AddTask("prod", agreement.Task{ID: id, StartOnCreate: true}), ShouldBeNil)
+			So(tr.StopTask("prod", agreement.Task{ID: id}), ShouldBeNil)
+			_, idx := ta.Tasks.Contains(agreement.Task{ID: id})
+			So(ta.Tasks[idx].Stopped, ShouldBeTrue)
+		})
+		Convey("rejects an unknown strategy", func() {
+			So(tr.SetTaskAssignment("prod", "random", 1), ShouldNotBeNil)
+		})
+		Convey("rejects an agreement which doesn't exist", func() {
+			So(tr.SetTaskAssignment("dev", agreement.StrategyHash, 1), ShouldNotBeNil)
+		})
+	})
+}
+
+func TestTribeKeyRotation(t *testing.T) {
+	log.SetLevel(log.WarnLevel)
+	key1 := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+	key2 := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32))
+	Convey("A tribe without keys", t, func() {
+		tr, err := New(getTestConfig())
+		So(err, ShouldBeNil)
+		Convey("doesn't rotate keys", func() {
+			_, serr := tr.ListKeys()
+			So(serr, ShouldNotBeNil)
+			So(tr.InstallKey(key2), ShouldNotBeNil)
+		})
+	})
+	Convey("A tribe encrypting the gossip with a key", t, func() {
+		dir, err := ioutil.TempDir("", "tribe-keyring")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		cfg := getTestConfig()
+		cfg.EncryptKeys = []string{key1}
+		cfg.KeyringFile = filepath.Join(dir, "keyring")
+		tr, err := New(cfg)
+		So(err, ShouldBeNil)
+		keys, serr := tr.ListKeys()
+		So(serr, ShouldBeNil)
+		So(keys, ShouldResemble, []string{keyFingerprint(key1)})
+
+		Convey("rotates its keys and saves them", func() {
+			So(tr.InstallKey(key2), ShouldBeNil)
+			So(tr.UseKey(key2), ShouldBeNil)
+			So(tr.RemoveKey(key1), ShouldBeNil)
+			keys, serr := tr.ListKeys()
+			So(serr, ShouldBeNil)
+			So(keys, ShouldResemble, []string{keyFingerprint(key2)})
+
+			keyring, err := newKeyring(&Config{KeyringFile: cfg.KeyringFile})
+			So(err, ShouldBeNil)
+			So(keyring.GetPrimaryKey(), ShouldResemble, bytes.Repeat([]byte{2}, 32))
+		})
+		Convey("doesn't remove its primary key", func() {
+			So(tr.RemoveKey(key1), ShouldNotBeNil)
+		})
+		Convey("rejects an invalid key", func() {
+			So(tr.InstallKey("not a key"), ShouldNotBeNil)
+		})
+	})
+}
+
+type mockPluginCatalog struct{}
+
+func (m *mockPluginCatalog) Load(*core.RequestedPlugin) (core.CatalogedPlugin, serror.SnapError) {
+	return nil, nil
+}
+func (m *mockPluginCatalog) Unload(core.Plugin) (core.CatalogedPlugin, serror.SnapError) {
+	return nil, nil
+}
+func (m *mockPluginCatalog) PluginCatalog() core.PluginCatalog { return nil }
+
+func TestTribeAgreementSnapshot(t *testing.T) {
+	log.SetLevel(log.WarnLevel)
+	Convey("A tribe with an agreement", t, func() {
+		tr, err := New(getTestConfig())
+		So(err, ShouldBeNil)
+		tr.SetTaskManager(&mockTaskManager{})
+		tr.SetPluginCatalog(&mockPluginCatalog{})
+		local := tr.memberlist.LocalNode().Name
+		id := uuid.New()
+		So(tr.AddAgreement("prod", map[string]string{"env": "prod"}), ShouldBeNil)
+		So(tr.JoinAgreement("prod", local), ShouldBeNil)
+		So(tr.SetTaskAssignment("prod", agreement.StrategyHash, 1), ShouldBeNil)
+		So(tr.AddPlugin("prod", agreement.Plugin{Name_: "mock", Version_: 1, Type_: core.CollectorPluginType}), ShouldBeNil)
+		So(tr.AddTask("prod", agreement.Task{ID: id, StartOnCreate: true}), ShouldBeNil)
+		So(tr.StopTask("prod", agreement.Task{ID: id}), ShouldBeNil)
+
+		Convey("exports its snapshot", func() {
+			s, serr := tr.ExportAgreement("prod")
+			So(serr, ShouldBeNil)
+			So(s.TaskLabels, ShouldResemble, map[string]string{"env": "prod"})
+			So(s.TaskStrategy, ShouldEqual, agreement.StrategyHash)
+			So(s.Members, ShouldResemble, []string{local})
+			So(len(s.Plugins), ShouldEqual, 1)
+			So(s.Tasks, ShouldResemble, []agreement.SnapshotTask{{ID: id, Stopped: true}})
+
+			Convey("which is restored into a fresh tribe", func() {
+				fresh, err := New(getTestConfig())
+				So(err, ShouldBeNil)
+				fresh.SetTaskManager(&mockTaskManager{})
+				fresh.SetPluginCatalog(&mockPluginCatalog{})
+				report, serr := fresh.RestoreAgreement(s)
+				So(serr, ShouldBeNil)
+				So(report.MissingMembers, ShouldBeEmpty)
+				So(report.MissingPlugins, ShouldResemble, s.Plugins)
+				a := fresh.agreements["prod"]
+				So(a, ShouldNotBeNil)
+				So(a.TaskAgreement.Strategy, ShouldEqual, agreement.StrategyHash)
+				So(a.Members, ShouldContainKey, local)
+				So(a.TaskAgreement.Tasks, ShouldResemble, []agreement.Task{{ID: id, Stopped: true}})
+			})
+			Convey("which isn't restored over an existing agreement", func() {
+				_, serr := tr.RestoreAgreement(s)
+				So(serr, ShouldNotBeNil)
+			})
+		})
+		Convey("doesn't export an agreement which doesn't exist", func() {
+			_, serr := tr.ExportAgreement("dev")
+			So(serr, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestTribeSync(t *testing.T) {
+	log.SetLevel(log.WarnLevel)
+	Convey("A tribe with an agreement", t, func() {
+		tr, err := New(getTestConfig())
+		So(err, ShouldBeNil)
+		tr.SetTaskManager(&mockTaskManager{})
+		tr.SetPluginCatalog(&mockPluginCatalog{})
+		So(tr.AddAgreement("prod", nil), ShouldBeNil)
+		So(tr.JoinAgreement("prod", tr.memberlist.LocalNode().Name), ShouldBeNil)
+		id := uuid.New()
+		So(tr.AddTask("prod", agreement.Task{ID: id, StartOnCreate: true}), ShouldBeNil)
+
+		Convey("is synced once it shares the running task", func() {
+			So(tr.Sync(id, time.Second), ShouldBeNil)
+		})
+		Convey("isn't synced while the running task is stopped in the agreement", func() {
+			So(tr.StopTask("prod", agreement.Task{ID: id}), ShouldBeNil)
+			So(tr.Sync(id, 200*time.Millisecond), ShouldNotBeNil)
+		})
+		Convey("isn't synced while a plugin of the agreement isn't loaded", func() {
+			So(tr.AddPlugin("prod", agreement.Plugin{Name_: "mock", Version_: 1, Type_: core.CollectorPluginType}), ShouldBeNil)
+			So(tr.Sync("", 200*time.Millisecond), ShouldNotBeNil)
+		})
+	})
+}
+
+func TestTribeTaskOverrides(t *testing.T) {
+	log.SetLevel(log.WarnLevel)
+	Convey("A tribe with a task agreement", t, func() {
+		tr, err := New(getTestConfig())
+		So(err, ShouldBeNil)
+		tr.SetTaskManager(&mockTaskManager{})
+		So(tr.AddAgreement("prod", nil), ShouldBeNil)
+		local := tr.memberlist.LocalNode().Name
+		So(tr.JoinAgreement("prod", local), ShouldBeNil)
+		id := uuid.New()
+		So(tr.AddTask("prod", agreement.Task{ID: id}), ShouldBeNil)
+
+		Convey("stores the overrides of a task", func() {
+			overrides := map[string]agreement.TaskOverride{
+				local: {"file": {"file": "/tmp/local.log"}},
+			}
+			So(tr.SetTaskOverrides("prod", id, overrides), ShouldBeNil)
+			task := tr.agreements["prod"].TaskAgreement.Tasks[0]
+			So(task.Overrides, ShouldResemble, overrides)
+			So(tr.workerTask(task, false).Override, ShouldResemble, overrides[local])
+		})
+		Convey("fails to override an unknown task", func() {
+			So(tr.SetTaskOverrides("prod", uuid.New(), nil), ShouldNotBeNil)
+		})
+	})
+	Convey("A task override", t, func() {
+		wf := wmap.NewWorkflowMap()
+		wf.Collect.AddMetric("/intel/mock/foo", 1)
+		wf.Collect.AddConfigItem("/intel/mock", "password", "secret")
+		pr := wmap.NewProcessNode("passthru", 1)
+		pu := wmap.NewPublishNode("file", 1)
+		pu.AddConfigItem("file", "/tmp/snap.log")
+		pr.Add(pu)
+		wf.Collect.Add(pr)
+
+		Convey("overrides the config of the collect node and of the plugins", func() {
+			agreement.TaskOverride{
+				"/intel/mock": {"password": "maui"},
+				"passthru":    {"debug": true},
+				"file":        {"file": "/tmp/maui.log"},
+			}.Apply(wf)
+			So(wf.Collect.Config["/intel/mock"]["password"], ShouldEqual, "maui")
+			So(wf.Collect.Process[0].Config["debug"], ShouldEqual, true)
+			So(wf.Collect.Process[0].Publish[0].Config["file"], ShouldEqual, "/tmp/maui.log")
+		})
+	})
+}
+
+func TestTribeZoneSuspicion(t *testing.T) {
+	log.SetLevel(log.WarnLevel)
+	Convey("A tribe in a zone", t, func() {
+		cfg := getTestConfig()
+		cfg.Zone = "us-east"
+		cfg.ZoneSuspicionTimeout.Duration = 200 * time.Millisecond
+		tr, err := New(cfg)
+		So(err, ShouldBeNil)
+		So(tr.AddAgreement("prod", nil), ShouldBeNil)
+		n := &memberlist.Node{
+			Name: "remote",
+			Addr: net.ParseIP("127.0.0.1"),
+			Meta: tr.encodeTags(map[string]string{agreement.Zone: "eu-west"}),
+		}
+		tr.handleMemberJoin(n)
+		So(tr.JoinAgreement("prod", "remote"), ShouldBeNil)
+
+		Convey("keeps a member of a remote zone which left until it's back", func() {
+			tr.handleMemberLeave(n)
+			tr.handleMemberJoin(n)
+			time.Sleep(400 * time.Millisecond)
+			tr.mutex.RLock()
+			defer tr.mutex.RUnlock()
+			So(tr.agreements["prod"].Members, ShouldContainKey, "remote")
+		})
+		Convey("removes a member of a remote zone which left after the timeout", func() {
+			tr.handleMemberLeave(n)
+			tr.mutex.RLock()
+			So(tr.agreements["prod"].Members, ShouldContainKey, "remote")
+			tr.mutex.RUnlock()
+			time.Sleep(400 * time.Millisecond)
+			tr.mutex.RLock()
+			defer tr.mutex.RUnlock()
+			So(tr.agreements["prod"].Members, ShouldNotContainKey, "remote")
+		})
+	})
+}
+
+func TestTribeMetricCatalog(t *testing.T) {
+	log.SetLevel(log.WarnLevel)
+	Convey("A tribe whose members expose their metrics", t, func() {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rbody.Write(200, rbody.MetricsReturned{
+				{Namespace: "/intel/mock/foo", Version: 2},
+				{Namespace: "/intel/mock/bar", Version: 1},
+			}, w)
+		}))
+		defer ts.Close()
+		_, port, err := net.SplitHostPort(strings.TrimPrefix(ts.URL, "http://"))
+		So(err, ShouldBeNil)
+
+		cfg := getTestConfig()
+		cfg.RestAPIPort, _ = strconv.Atoi(port)
+		tr, err := New(cfg)
+		So(err, ShouldBeNil)
+		So(tr.AddAgreement("prod", nil), ShouldBeNil)
+		So(tr.JoinAgreement("prod", tr.memberlist.LocalNode().Name), ShouldBeNil)
+		tr.handleMemberJoin(&memberlist.Node{
+			Name: "unreachable",
+			Addr: net.ParseIP("127.0.0.1"),
+			Meta: tr.encodeTags(map[string]string{
+				agreement.RestPort:     strconv.Itoa(getAvailablePort()),
+				agreement.RestProtocol: "http",
+			}),
+		})
+
+		Convey("merges the catalogs of the members of the tribe", func() {
+			catalog, err := tr.MetricCatalog("")
+			So(err, ShouldBeNil)
+			So(catalog.Metrics, ShouldHaveLength, 2)
+			So(catalog.Metrics[0].Namespace, ShouldEqual, "/intel/mock/bar")
+			So(catalog.Metrics[1].Version, ShouldEqual, 2)
+			So(catalog.Metrics[1].Members, ShouldResemble, []string{tr.memberlist.LocalNode().Name})
+			So(catalog.UnreachableMembers, ShouldResemble, []string{"unreachable"})
+		})
+		Convey("merges the catalogs of the members of an agreement", func() {
+			catalog, err := tr.MetricCatalog("prod")
+			So(err, ShouldBeNil)
+			So(catalog.Metrics, ShouldHaveLength, 2)
+			So(catalog.UnreachableMembers, ShouldBeEmpty)
+		})
+		Convey("fails for an unknown agreement", func() {
+			_, err := tr.MetricCatalog("dev")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,103 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tribe
+
+import (
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/hashicorp/memberlist"
+	"github.com/intelsdi-x/snap/mgmt/tribe/agreement"
+)
+
+// setWANProfile tunes the gossip for members spread over datacenters: the
+// probes and the suspicion of the failed members last longer and the state is
+// pushed and pulled more often, over TCP when the UDP probes are lost
+func setWANProfile(c *memberlist.Config) {
+	wan := memberlist.DefaultWANConfig()
+	c.TCPTimeout = wan.TCPTimeout
+	c.SuspicionMult = wan.SuspicionMult
+	c.PushPullInterval = wan.PushPullInterval
+	c.ProbeTimeout = wan.ProbeTimeout
+	c.ProbeInterval = wan.ProbeInterval
+	c.GossipInterval = wan.GossipInterval
+	c.DisableTcpPings = false
+}
+
+// isRemoteZone returns true if the member is in another zone than the local
+// member and its failure is suspected before it's removed
+func (t *tribe) isRemoteZone(m *agreement.Member) bool {
+	zone := m.Tags[agreement.Zone]
+	return t.config.Zone != "" && zone != "" && zone != t.config.Zone &&
+		t.config.ZoneSuspicionTimeout.Duration > 0
+}
+
+// suspect keeps the member of a remote zone which left in its agreements for
+// the zone suspicion timeout, the member is removed unless it joins again
+// before. It's called with the mutex locked.
+func (t *tribe) suspect(n *memberlist.Node) {
+	if _, ok := t.suspects[n.Name]; ok {
+		return
+	}
+	t.logger.WithFields(log.Fields{
+		"_block":  "suspect",
+		"member":  n.Name,
+		"timeout": t.config.ZoneSuspicionTimeout.Duration,
+	}).Warnln("member of a remote zone left, suspecting it")
+	t.suspects[n.Name] = time.AfterFunc(t.config.ZoneSuspicionTimeout.Duration, func() {
+		t.mutex.Lock()
+		defer t.mutex.Unlock()
+		if _, ok := t.suspects[n.Name]; !ok {
+			return
+		}
+		delete(t.suspects, n.Name)
+		if m, ok := t.members[n.Name]; ok {
+			t.removeMember(n, m)
+		}
+	})
+}
+
+// relay pushes and pulls the state of the tribe with the relays over TCP every
+// push-pull interval, so that the zones converge when the gossip between them
+// is lost
+func (t *tribe) relay() {
+	defer t.workerWaitGroup.Done()
+	logger := t.logger.WithFields(log.Fields{
+		"_block": "relay",
+		"relays": t.config.Relays,
+	})
+	interval := t.config.MemberlistConfig.PushPullInterval
+	if interval <= 0 {
+		interval = defaultPushPullInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			n, err := t.memberlist.Join(t.config.Relays)
+			if err != nil {
+				logger.WithField("reached", n).Warn(err)
+			}
+		case <-t.workerQuitChan:
+			return
+		}
+	}
+}
@@ -0,0 +1,98 @@
+// +build legacy
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package worker
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/mgmt/rest/client"
+	"github.com/intelsdi-x/snap/mgmt/rest/v1/rbody"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDownloadChunks(t *testing.T) {
+	Convey("A plugin binary transferred from a member", t, func() {
+		chunkSize := PluginChunkSize
+		PluginChunkSize = 1000
+		defer func() { PluginChunkSize = chunkSize }()
+
+		b := make([]byte, 4500)
+		rand.Read(b)
+		sum := sha256.Sum256(b)
+		requests := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.Header().Set(rbody.PluginCheckSumHeader, hex.EncodeToString(sum[:]))
+			http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(b))
+		}))
+		defer ts.Close()
+		c, err := client.New(ts.URL, "v1", true)
+		So(err, ShouldBeNil)
+
+		Convey("is transferred in chunks with its checksum", func() {
+			buf := &bytes.Buffer{}
+			checkSum, err := downloadChunks(c, buf)
+			So(err, ShouldBeNil)
+			So(checkSum, ShouldEqual, hex.EncodeToString(sum[:]))
+			So(buf.Bytes(), ShouldResemble, b)
+			So(requests, ShouldEqual, 5)
+		})
+	})
+	Convey("A plugin binary transferred at once by a member of a former version", t, func() {
+		b := []byte("plugin binary")
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Encoding", "gzip")
+			gz := gzip.NewWriter(w)
+			defer gz.Close()
+			gz.Write(b)
+		}))
+		defer ts.Close()
+		c, err := client.New(ts.URL, "v1", true)
+		So(err, ShouldBeNil)
+
+		Convey("is transferred without a checksum", func() {
+			buf := &bytes.Buffer{}
+			checkSum, err := downloadChunks(c, buf)
+			So(err, ShouldBeNil)
+			So(checkSum, ShouldBeEmpty)
+			So(buf.Bytes(), ShouldResemble, b)
+		})
+	})
+	Convey("The size of a Content-Range header", t, func() {
+		size, err := contentRangeSize("bytes 0-999/4500")
+		So(err, ShouldBeNil)
+		So(size, ShouldEqual, 4500)
+		_, err = contentRangeSize("bytes 0-999/*")
+		So(err, ShouldNotBeNil)
+		_, err = contentRangeSize("")
+		So(err, ShouldNotBeNil)
+	})
+}
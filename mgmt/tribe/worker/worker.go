@@ -20,14 +20,20 @@ limitations under the License.
 package worker
 
 import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"math/rand"
 	"net"
+	"net/http"
 	"os"
-	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -35,6 +41,8 @@ import (
 	"github.com/intelsdi-x/snap/core"
 	"github.com/intelsdi-x/snap/core/serror"
 	"github.com/intelsdi-x/snap/mgmt/rest/client"
+	"github.com/intelsdi-x/snap/mgmt/rest/v1/rbody"
+	"github.com/intelsdi-x/snap/mgmt/tribe/agreement"
 	"github.com/intelsdi-x/snap/pkg/schedule"
 	"github.com/intelsdi-x/snap/scheduler"
 	"github.com/intelsdi-x/snap/scheduler/wmap"
@@ -43,8 +51,19 @@ import (
 const (
 	retryDelay = 500 * time.Millisecond
 	retryLimit = 20
+	// chunkRetryLimit is the number of times a chunk of a plugin binary
+	// is requested again before trying another member
+	chunkRetryLimit = 3
 )
 
+// PluginChunkSize is the number of bytes of a plugin binary transferred
+// between the members of a tribe at once
+var PluginChunkSize int64 = 1 << 20
+
+// ErrPluginCheckSum is the error of a plugin binary transferred from a member
+// not matching the checksum sent by the member
+var ErrPluginCheckSum = errors.New("Plugin does not match the checksum")
+
 const (
 	PluginLoadedType = iota
 	PluginUnloadedType
@@ -55,6 +74,7 @@ const (
 	TaskStoppedType
 	TaskStartedType
 	TaskRemovedType
+	TaskUpdatedType
 )
 
 var (
@@ -68,6 +88,7 @@ var (
 		TaskStoppedType: "Stopped",
 		TaskStartedType: "Started",
 		TaskRemovedType: "Removed",
+		TaskUpdatedType: "Updated",
 	}
 )
 
@@ -100,6 +121,11 @@ type TaskRequest struct {
 type Task struct {
 	ID            string
 	StartOnCreate bool
+	// Override is the config of the task overridden on the local member
+	Override agreement.TaskOverride
+	// Overridden is the names of the members the config of the task is
+	// overridden on, the task is copied from the other members if any
+	Overridden []string
 }
 
 type ManagesPlugins interface {
@@ -221,7 +247,10 @@ func (w worker) start() {
 					}
 				}
 				if work.RequestType == TaskCreatedType {
-					w.createTask(work.Task.ID, work.Task.StartOnCreate)
+					w.createTask(work.Task)
+				}
+				if work.RequestType == TaskUpdatedType {
+					w.updateTask(work.Task)
 				}
 				if work.RequestType == TaskRemovedType {
 					if err := w.removeTask(work.Task.ID); err != nil {
@@ -350,6 +379,9 @@ func (w worker) loadPlugin(plugin core.Plugin) error {
 	return errors.New("failed to find a member with the plugin")
 }
 
+// downloadPlugin transfers the plugin binary from a member in chunks of
+// PluginChunkSize bytes, a chunk failing is requested again, and verifies the
+// checksum of the binary sent by the member.
 func (w worker) downloadPlugin(c *client.Client, plugin core.Plugin) (*os.File, error) {
 	logger := w.logger.WithFields(log.Fields{
 		"plugin-name":    plugin.Name(),
@@ -358,36 +390,120 @@ func (w worker) downloadPlugin(c *client.Client, plugin core.Plugin) (*os.File,
 		"url":            c.URL,
 		"_block":         "download-plugin",
 	})
-	resp, err := c.TribeRequest()
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	fpath := filepath.Join(dir, fmt.Sprintf("%s-%s-%d", plugin.TypeName(), plugin.Name(), plugin.Version()))
+	f, err := os.OpenFile(fpath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0700)
 	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	checkSum, err := downloadChunks(c, io.MultiWriter(f, h))
+	if err != nil {
+		logger.WithField("err", err).Info("plugin not downloaded")
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("Plugin not downloaded from %s: %s", c.URL, err.Error())
+	}
+	if checkSum == "" {
+		// members of a former version don't send the checksum
+		logger.Warn("the checksum of the plugin wasn't sent, the plugin isn't verified")
+		return f, nil
+	}
+	if sum := hex.EncodeToString(h.Sum(nil)); sum != checkSum {
 		logger.WithFields(log.Fields{
-			"err": err,
-		}).Info("plugin not found")
-		return nil, fmt.Errorf("Plugin not found at %s: %s", c.URL, err.Error())
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode == 200 {
-		if resp.Header.Get("Content-Type") != "application/x-gzip" {
-			logger.WithField("content-type", resp.Header.Get("Content-Type")).Error("Expected application/x-gzip")
-		}
-		dir, err := ioutil.TempDir("", "")
+			"expected": checkSum,
+			"actual":   sum,
+		}).Error(ErrPluginCheckSum)
+		os.RemoveAll(dir)
+		return nil, ErrPluginCheckSum
+	}
+	return f, nil
+}
+
+// downloadChunks writes the plugin binary to w chunk by chunk and returns the
+// checksum sent by the member. A member serving the whole binary at once is
+// supported too.
+func downloadChunks(c *client.Client, w io.Writer) (string, error) {
+	var offset int64
+	var checkSum string
+	for retries := 0; ; {
+		resp, err := c.TribeRangeRequest(offset, offset+PluginChunkSize-1)
 		if err != nil {
-			logger.Error(err)
-			return nil, err
+			if retries < chunkRetryLimit {
+				retries++
+				time.Sleep(retryDelay)
+				continue
+			}
+			return "", err
 		}
-		fpath := path.Join(dir, fmt.Sprintf("%s-%s-%d", plugin.TypeName(), plugin.Name(), plugin.Version()))
-		f, err := os.OpenFile(fpath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0700)
-		if err != nil {
-			logger.Error(err)
-			return nil, err
+		checkSum = resp.Header.Get(rbody.PluginCheckSumHeader)
+		switch resp.StatusCode {
+		case http.StatusOK:
+			defer resp.Body.Close()
+			var body io.Reader = resp.Body
+			if resp.Header.Get("Content-Encoding") == "gzip" && !resp.Uncompressed {
+				if body, err = gzip.NewReader(resp.Body); err != nil {
+					return "", err
+				}
+			}
+			_, err = io.Copy(w, body)
+			return checkSum, err
+		case http.StatusPartialContent:
+			size, err := contentRangeSize(resp.Header.Get("Content-Range"))
+			if err != nil {
+				resp.Body.Close()
+				return "", err
+			}
+			n, err := io.Copy(w, resp.Body)
+			resp.Body.Close()
+			offset += n
+			if err != nil {
+				// the part of the chunk written is kept, the rest of it
+				// is requested again
+				if retries < chunkRetryLimit {
+					retries++
+					time.Sleep(retryDelay)
+					continue
+				}
+				return "", err
+			}
+			retries = 0
+			if offset >= size {
+				return checkSum, nil
+			}
+		case http.StatusRequestedRangeNotSatisfiable:
+			// an empty binary has no range
+			resp.Body.Close()
+			return checkSum, nil
+		default:
+			resp.Body.Close()
+			return "", fmt.Errorf("Status code not 200 was %v: %s", resp.StatusCode, c.URL)
 		}
-		io.Copy(f, resp.Body)
-		f.Close()
-		return f, nil
 	}
-	return nil, fmt.Errorf("Status code not 200 was %v: %s", resp.StatusCode, c.URL)
 }
-func (w worker) createTask(taskID string, startOnCreate bool) {
+
+// contentRangeSize returns the complete length of a Content-Range header, e.g.
+// 4096 for "bytes 0-1023/4096"
+func contentRangeSize(contentRange string) (int64, error) {
+	idx := strings.LastIndex(contentRange, "/")
+	if !strings.HasPrefix(contentRange, "bytes ") || idx < 0 {
+		return 0, fmt.Errorf("invalid Content-Range: %q", contentRange)
+	}
+	size, err := strconv.ParseInt(contentRange[idx+1:], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid Content-Range: %q", contentRange)
+	}
+	return size, nil
+}
+
+func (w worker) createTask(task Task) {
+	taskID, startOnCreate := task.ID, task.StartOnCreate
 	logger := w.logger.WithFields(log.Fields{
 		"task-id": taskID,
 		"_block":  "create-task",
@@ -403,7 +519,7 @@ func (w worker) createTask(taskID string, startOnCreate bool) {
 			logger.Error(err)
 			continue
 		}
-		for _, member := range shuffle(members) {
+		for _, member := range notOverriddenFirst(shuffle(members), task.Overridden) {
 			uri := fmt.Sprintf("%s://%s:%s", member.GetRestProto(), member.GetAddr(), member.GetRestPort())
 			logger.Debugf("getting task %v from %v", taskID, uri)
 
@@ -436,12 +552,35 @@ func (w worker) createTask(taskID string, startOnCreate bool) {
 				}
 			}
 			logger.Debug("creating task")
-			opt := core.SetTaskID(taskID)
+			opts := []core.TaskOption{core.SetTaskID(taskID)}
+			if len(taskResult.DependsOn) > 0 {
+				opts = append(opts, core.SetDependencies(taskResult.DependsOn))
+			}
+			if taskResult.RunDeadline != "" {
+				if dl, err := time.ParseDuration(taskResult.RunDeadline); err == nil {
+					opts = append(opts, core.SetRunDeadline(dl))
+				}
+			}
+			if taskResult.OverrunPolicy != "" {
+				opts = append(opts, core.SetOverrunPolicy(taskResult.OverrunPolicy))
+			}
+			if taskResult.Priority != "" {
+				opts = append(opts, core.SetPriority(taskResult.Priority))
+			}
+			if len(taskResult.Labels) > 0 {
+				opts = append(opts, core.SetLabels(taskResult.Labels))
+			}
+			if taskResult.Buffer != nil {
+				if b, err := core.NewTaskBuffer(taskResult.Buffer.MaxSize, taskResult.Buffer.MaxAge); err == nil {
+					opts = append(opts, core.SetBuffer(b))
+				}
+			}
+			task.Override.Apply(taskResult.Workflow)
 			_, errs := w.taskManager.CreateTaskTribe(
 				getSchedule(taskResult.ScheduledTaskReturned.Schedule),
 				taskResult.Workflow,
 				startOnCreate,
-				opt)
+				opts...)
 			if errs != nil && len(errs.Errors()) > 0 {
 				fields := log.Fields{}
 				for idx, e := range errs.Errors() {
@@ -517,6 +656,107 @@ func (w worker) removeTask(taskID string) error {
 	return err
 }
 
+// updateTask creates again a task whose config was overridden on the local
+// member from its local copy, once it's stopped and removed
+func (w worker) updateTask(task Task) {
+	logger := w.logger.WithFields(log.Fields{
+		"task-id": task.ID,
+		"_block":  "update-task",
+	})
+	t, err := w.taskManager.GetTask(task.ID)
+	if err != nil {
+		w.createTask(task)
+		return
+	}
+	// the workflow of the local copy is copied before it's overridden
+	b, err := t.WMap().ToJson()
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+	wf, err := wmap.FromJson(b)
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+	task.Override.Apply(wf)
+	w.stopTask(task.ID)
+	for i := 0; i <= retryLimit; i++ {
+		// the task may still be stopping
+		if err = w.removeTask(task.ID); err == nil {
+			break
+		}
+		time.Sleep(retryDelay)
+	}
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+	logger.Debug("creating the task with its override")
+	_, errs := w.taskManager.CreateTaskTribe(t.Schedule(), wf, task.StartOnCreate, taskOptions(t)...)
+	if errs != nil && len(errs.Errors()) > 0 {
+		logger.WithField("err", errs.Errors()[0].Error()).Error("error creating task")
+		// copied from another member instead
+		w.createTask(task)
+	}
+}
+
+// taskOptions returns the options creating a task like the given one
+func taskOptions(t core.Task) []core.TaskOption {
+	opts := []core.TaskOption{
+		core.SetTaskID(t.ID()),
+		core.SetTaskName(t.GetName()),
+		core.TaskDeadlineDuration(t.DeadlineDuration()),
+		core.OptionStopOnFailure(t.GetStopOnFailure()),
+		core.SetMaxMetricsBuffer(t.MaxMetricsBuffer()),
+		core.SetMaxCollectDuration(t.MaxCollectDuration()),
+	}
+	if len(t.Dependencies()) > 0 {
+		opts = append(opts, core.SetDependencies(t.Dependencies()))
+	}
+	if t.RunDeadline() > 0 {
+		opts = append(opts, core.SetRunDeadline(t.RunDeadline()))
+	}
+	if t.OverrunPolicy() != "" {
+		opts = append(opts, core.SetOverrunPolicy(t.OverrunPolicy()))
+	}
+	if t.Priority() != "" {
+		opts = append(opts, core.SetPriority(t.Priority()))
+	}
+	if len(t.Labels()) > 0 {
+		opts = append(opts, core.SetLabels(t.Labels()))
+	}
+	if t.Buffer() != nil {
+		opts = append(opts, core.SetBuffer(t.Buffer()))
+	}
+	return opts
+}
+
+// notOverriddenFirst orders the members the config of the task isn't
+// overridden on first, so that the task is copied from them
+func notOverriddenFirst(m []Member, overridden []string) []Member {
+	if len(overridden) == 0 {
+		return m
+	}
+	result := make([]Member, 0, len(m))
+	var last []Member
+	for _, member := range m {
+		isOverridden := false
+		for _, name := range overridden {
+			if member.GetName() == name {
+				isOverridden = true
+				break
+			}
+		}
+		if isOverridden {
+			last = append(last, member)
+			continue
+		}
+		result = append(result, member)
+	}
+	return append(result, last...)
+}
+
 func shuffle(m []Member) []Member {
 	result := make([]Member, len(m))
 	perm := rand.Perm(len(m))
@@ -566,6 +806,13 @@ func getSchedule(s *core.Schedule) schedule.Schedule {
 			s.StopTimestamp,
 			s.Count,
 		)
+		sch.Jitter = s.Jitter
+		if s.Recurrence != nil {
+			if sch.Recurrence, err = schedule.NewRecurrence(s.Recurrence.Start, s.Recurrence.Stop, s.Recurrence.Days, s.Timezone); err != nil {
+				logger.Error(err)
+				return nil
+			}
+		}
 		if err = sch.Validate(); err != nil {
 			logger.Error(err)
 			return nil
@@ -576,7 +823,7 @@ func getSchedule(s *core.Schedule) schedule.Schedule {
 			logger.Error(core.ErrMissingScheduleInterval)
 			return nil
 		}
-		sch := schedule.NewCronSchedule(s.Interval)
+		sch := schedule.NewCronScheduleWithTimezone(s.Interval, s.Timezone)
 		if err := sch.Validate(); err != nil {
 			logger.Error(err)
 			return nil
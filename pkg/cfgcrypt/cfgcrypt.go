@@ -0,0 +1,176 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cfgcrypt encrypts the sensitive values of the config of the plugins
+// and of the tasks, e.g. passwords and tokens, so that they're only known in
+// clear by snapteld when it gives them to the plugins
+package cfgcrypt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// Prefix is the prefix of the encrypted values, it's followed by the nonce
+// and the ciphertext encoded in base64
+const Prefix = "enc:v1:"
+
+// keySize is the size of the AES-256 keys
+const keySize = 32
+
+var (
+	// ErrInvalidKey is returned for the keys which aren't 256 bits keys
+	// encoded in base64 or in hex
+	ErrInvalidKey = errors.New("invalid encryption key, it must be a 256 bits key encoded in base64 or in hex")
+	// ErrNotEncrypted is returned when a value which isn't encrypted is decrypted
+	ErrNotEncrypted = errors.New("value isn't encrypted")
+	// ErrInvalidValue is returned for the encrypted values which can't be decrypted
+	ErrInvalidValue = errors.New("encrypted value can't be decrypted")
+
+	cryptLogger = log.WithField("_module", "cfgcrypt")
+)
+
+// Cipher encrypts and decrypts the sensitive values with AES-GCM
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// New returns the cipher of the key of the configuration, or nil when no key
+// file is configured. The key is wrapped by the transit secrets engine of
+// Vault when its address is configured. A key is generated into the key file
+// when it doesn't exist.
+func New(cfg *Config) (*Cipher, error) {
+	if cfg == nil || cfg.KeyFile == "" {
+		return nil, nil
+	}
+	var key []byte
+	var err error
+	if cfg.KMSAddr != "" {
+		key, err = newVaultTransit(cfg).key(cfg.KeyFile)
+	} else {
+		key, err = readKey(cfg.KeyFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewCipher(key)
+}
+
+// NewCipher returns the cipher of a 256 bits key
+func NewCipher(key []byte) (*Cipher, error) {
+	if len(key) != keySize {
+		return nil, ErrInvalidKey
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// IsEncrypted returns true when the value is encrypted
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, Prefix)
+}
+
+// Encrypt returns the value encrypted, with a random nonce
+func (c *Cipher) Encrypt(value string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(value), nil)
+	return Prefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt returns the value an encrypted value was encrypted from
+func (c *Cipher) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return "", ErrNotEncrypted
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, Prefix))
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return "", ErrInvalidValue
+	}
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	b, err := c.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", ErrInvalidValue
+	}
+	return string(b), nil
+}
+
+// readKey reads the key of the key file, it's generated when the file doesn't
+// exist
+func readKey(path string) ([]byte, error) {
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		key := make([]byte, keySize)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+		if err := createKeyFile(path, base64.StdEncoding.EncodeToString(key)); err != nil {
+			return nil, err
+		}
+		cryptLogger.WithField("key_file", path).Info("generated the key of the sensitive values")
+		return key, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeKey(string(bytes.TrimSpace(b)))
+}
+
+// decodeKey decodes a key encoded in base64 or in hex
+func decodeKey(s string) ([]byte, error) {
+	if key, err := base64.StdEncoding.DecodeString(s); err == nil && len(key) == keySize {
+		return key, nil
+	}
+	if key, err := hex.DecodeString(s); err == nil && len(key) == keySize {
+		return key, nil
+	}
+	return nil, ErrInvalidKey
+}
+
+// createKeyFile writes a key into a new file only readable by snapteld
+func createKeyFile(path, key string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(key + "\n"); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
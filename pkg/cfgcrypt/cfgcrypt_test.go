@@ -0,0 +1,188 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cfgcrypt
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCipher(t *testing.T) {
+	Convey("Given a cipher", t, func() {
+		c, err := NewCipher(make([]byte, keySize))
+		So(err, ShouldBeNil)
+
+		Convey("the values are encrypted with a random nonce", func() {
+			v1, err := c.Encrypt("p@ssw0rd")
+			So(err, ShouldBeNil)
+			v2, err := c.Encrypt("p@ssw0rd")
+			So(err, ShouldBeNil)
+			So(IsEncrypted(v1), ShouldBeTrue)
+			So(v1, ShouldNotEqual, v2)
+			So(v1, ShouldNotContainSubstring, "p@ssw0rd")
+
+			Convey("and decrypted", func() {
+				v, err := c.Decrypt(v1)
+				So(err, ShouldBeNil)
+				So(v, ShouldEqual, "p@ssw0rd")
+			})
+		})
+
+		Convey("the values which aren't encrypted aren't decrypted", func() {
+			_, err := c.Decrypt("p@ssw0rd")
+			So(err, ShouldEqual, ErrNotEncrypted)
+		})
+
+		Convey("the values encrypted with another key aren't decrypted", func() {
+			key := make([]byte, keySize)
+			key[0] = 1
+			other, err := NewCipher(key)
+			So(err, ShouldBeNil)
+			v, err := other.Encrypt("p@ssw0rd")
+			So(err, ShouldBeNil)
+			_, err = c.Decrypt(v)
+			So(err, ShouldEqual, ErrInvalidValue)
+		})
+
+		Convey("the keys which aren't 256 bits keys are invalid", func() {
+			_, err := NewCipher([]byte("short"))
+			So(err, ShouldEqual, ErrInvalidKey)
+		})
+	})
+}
+
+func TestNew(t *testing.T) {
+	Convey("Given a key file", t, func() {
+		dir, err := ioutil.TempDir("", "cfgcrypt")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		cfg := GetDefaultConfig()
+
+		Convey("no cipher is returned when it's not configured", func() {
+			c, err := New(cfg)
+			So(err, ShouldBeNil)
+			So(c, ShouldBeNil)
+		})
+
+		Convey("a key is generated when it doesn't exist", func() {
+			cfg.KeyFile = filepath.Join(dir, "key")
+			c1, err := New(cfg)
+			So(err, ShouldBeNil)
+			fi, err := os.Stat(cfg.KeyFile)
+			So(err, ShouldBeNil)
+			So(fi.Mode().Perm(), ShouldEqual, os.FileMode(0600))
+
+			Convey("and read when it exists", func() {
+				c2, err := New(cfg)
+				So(err, ShouldBeNil)
+				v, err := c1.Encrypt("p@ssw0rd")
+				So(err, ShouldBeNil)
+				v, err = c2.Decrypt(v)
+				So(err, ShouldBeNil)
+				So(v, ShouldEqual, "p@ssw0rd")
+			})
+		})
+
+		Convey("a key encoded in hex is read", func() {
+			cfg.KeyFile = filepath.Join(dir, "key")
+			So(ioutil.WriteFile(cfg.KeyFile, []byte("000102030405060708090a0b0c0d0e0f000102030405060708090a0b0c0d0e0f\n"), 0600), ShouldBeNil)
+			_, err := New(cfg)
+			So(err, ShouldBeNil)
+		})
+
+		Convey("an invalid key is an error", func() {
+			cfg.KeyFile = filepath.Join(dir, "key")
+			So(ioutil.WriteFile(cfg.KeyFile, []byte("changeme"), 0600), ShouldBeNil)
+			_, err := New(cfg)
+			So(err, ShouldEqual, ErrInvalidKey)
+		})
+	})
+}
+
+func TestVaultTransit(t *testing.T) {
+	Convey("Given the transit secrets engine of Vault", t, func() {
+		key := base64.StdEncoding.EncodeToString(make([]byte, keySize))
+		var paths []string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			paths = append(paths, r.URL.Path)
+			if r.Header.Get("X-Vault-Token") != "t0k3n" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			var in map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&in)
+			data := map[string]interface{}{"plaintext": key}
+			switch r.URL.Path {
+			case "/v1/transit/datakey/plaintext/snap":
+				data["ciphertext"] = "vault:v1:wrapped"
+			case "/v1/transit/decrypt/snap":
+				if in["ciphertext"] != "vault:v1:wrapped" {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+		}))
+		defer ts.Close()
+		dir, err := ioutil.TempDir("", "cfgcrypt")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+
+		cfg := GetDefaultConfig()
+		cfg.KeyFile = filepath.Join(dir, "key")
+		cfg.KMSAddr = ts.URL
+		cfg.KMSToken = "t0k3n"
+
+		Convey("the wrapped key is written when the key file doesn't exist", func() {
+			_, err := New(cfg)
+			So(err, ShouldBeNil)
+			b, err := ioutil.ReadFile(cfg.KeyFile)
+			So(err, ShouldBeNil)
+			So(string(b), ShouldEqual, "vault:v1:wrapped\n")
+
+			Convey("and unwrapped when it exists", func() {
+				_, err := New(cfg)
+				So(err, ShouldBeNil)
+				So(paths, ShouldResemble, []string{"/v1/transit/datakey/plaintext/snap", "/v1/transit/decrypt/snap"})
+			})
+		})
+
+		Convey("the errors of Vault are returned", func() {
+			cfg.KMSToken = "wrong"
+			_, err := New(cfg)
+			So(err, ShouldNotBeNil)
+			_, err = os.Stat(cfg.KeyFile)
+			So(os.IsNotExist(err), ShouldBeTrue)
+		})
+	})
+}
@@ -0,0 +1,89 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cfgcrypt
+
+import (
+	"time"
+
+	"github.com/vrischmann/jsonutil"
+)
+
+// default configuration values
+const (
+	defaultKeyFile    = ""
+	defaultKMSAddr    = ""
+	defaultKMSKey     = "snap"
+	defaultKMSToken   = ""
+	defaultKMSTimeout = 10 * time.Second
+)
+
+// holds the configuration passed in through the SNAP config file
+type Config struct {
+	// KeyFile is the path to the file of the key the sensitive values are
+	// encrypted with, a key is generated into it when it doesn't exist. The
+	// values aren't encrypted when it's empty
+	KeyFile string `json:"key_file"yaml:"key_file"`
+	// KMSAddr is the address of the HashiCorp Vault server whose transit
+	// secrets engine wraps the key, the key file then holds the wrapped key
+	KMSAddr string `json:"kms_addr"yaml:"kms_addr"`
+	// KMSKey is the name of the transit key wrapping the key
+	KMSKey string `json:"kms_key"yaml:"kms_key"`
+	// KMSToken is the token of snapteld in Vault, the environment variable
+	// VAULT_TOKEN is read when it's empty
+	KMSToken   string            `json:"kms_token"yaml:"kms_token"`
+	KMSTimeout jsonutil.Duration `json:"kms_timeout"yaml:"kms_timeout"`
+}
+
+const (
+	CONFIG_CONSTRAINTS = `
+			"encryption": {
+				"type": ["object", "null"],
+				"properties" : {
+					"key_file": {
+						"type": "string"
+					},
+					"kms_addr": {
+						"type": "string"
+					},
+					"kms_key": {
+						"type": "string"
+					},
+					"kms_token": {
+						"type": "string"
+					},
+					"kms_timeout": {
+						"type": "string"
+					}
+				},
+				"additionalProperties": false
+			}
+	`
+)
+
+// GetDefaultConfig gets the default snapteld configuration
+func GetDefaultConfig() *Config {
+	return &Config{
+		KeyFile:    defaultKeyFile,
+		KMSAddr:    defaultKMSAddr,
+		KMSKey:     defaultKMSKey,
+		KMSToken:   defaultKMSToken,
+		KMSTimeout: jsonutil.Duration{defaultKMSTimeout},
+	}
+}
@@ -0,0 +1,110 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cfgcrypt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// vaultTransit wraps the key with a key of the transit secrets engine of
+// HashiCorp Vault, the key file holds the wrapped key so that the key is never
+// written in clear
+type vaultTransit struct {
+	addr   string
+	name   string
+	token  string
+	client *http.Client
+}
+
+func newVaultTransit(cfg *Config) *vaultTransit {
+	token := cfg.KMSToken
+	if token == "" {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+	return &vaultTransit{
+		addr:   strings.TrimSuffix(cfg.KMSAddr, "/"),
+		name:   cfg.KMSKey,
+		token:  token,
+		client: &http.Client{Timeout: cfg.KMSTimeout.Duration},
+	}
+}
+
+// key unwraps the key of the key file, a key is generated by Vault and
+// written wrapped into the file when it doesn't exist
+func (v *vaultTransit) key(path string) ([]byte, error) {
+	var data struct {
+		Plaintext  string `json:"plaintext"`
+		Ciphertext string `json:"ciphertext"`
+	}
+	b, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		if err := v.post("datakey/plaintext", map[string]interface{}{"bits": keySize * 8}, &data); err != nil {
+			return nil, err
+		}
+		if err := createKeyFile(path, data.Ciphertext); err != nil {
+			return nil, err
+		}
+		cryptLogger.WithField("key_file", path).Info("generated the key of the sensitive values with Vault")
+	case err != nil:
+		return nil, err
+	default:
+		if err := v.post("decrypt", map[string]interface{}{"ciphertext": string(bytes.TrimSpace(b))}, &data); err != nil {
+			return nil, err
+		}
+	}
+	key, err := base64.StdEncoding.DecodeString(data.Plaintext)
+	if err != nil || len(key) != keySize {
+		return nil, ErrInvalidKey
+	}
+	return key, nil
+}
+
+// post posts a request to an endpoint of the transit key and decodes the data
+// of the response into out
+func (v *vaultTransit) post(endpoint string, in interface{}, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s/v1/transit/%s/%s", v.addr, endpoint, v.name), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("vault answered %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(&struct {
+		Data interface{} `json:"data"`
+	}{Data: out})
+}
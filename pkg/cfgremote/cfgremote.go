@@ -0,0 +1,201 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cfgremote reads the settings overriding the configuration of
+// snapteld from a key-value store, etcd or consul
+package cfgremote
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+const (
+	// TypeEtcd reads the settings through the JSON gateway of etcd v3
+	TypeEtcd = "etcd"
+	// TypeConsul reads the settings through the KV API of consul
+	TypeConsul = "consul"
+)
+
+// kv is a key of the key-value store, relative to the prefix, and its value
+type kv struct {
+	key   string
+	value []byte
+}
+
+// Fetch reads the settings under the prefix from the key-value store, in the
+// format of the config file: the key restapi/port is the setting port of the
+// section restapi. The values are parsed as JSON, the values which aren't
+// valid JSON are strings. No settings are read when no store is configured
+func Fetch(cfg *Config) (map[string]interface{}, error) {
+	var kvs []kv
+	var err error
+	client := &http.Client{Timeout: cfg.Timeout.Duration}
+	endpoint := strings.TrimSuffix(cfg.Endpoint, "/")
+	switch cfg.Type {
+	case "":
+		return nil, nil
+	case TypeEtcd:
+		kvs, err = fetchEtcd(client, endpoint, cfg.Prefix)
+	case TypeConsul:
+		kvs, err = fetchConsul(client, endpoint, cfg.Prefix)
+	default:
+		return nil, fmt.Errorf("unknown key-value store '%s'", cfg.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%v (while reading the settings from %s)", err, cfg.Type)
+	}
+	settings := map[string]interface{}{}
+	for _, kv := range kvs {
+		path := strings.Split(strings.Trim(kv.key, "/"), "/")
+		if path[0] == "" {
+			continue
+		}
+		if err := setValue(settings, path, kv.value); err != nil {
+			return nil, err
+		}
+	}
+	return settings, nil
+}
+
+// setValue sets the value at the path of the settings, creating the
+// sections of the path
+func setValue(settings map[string]interface{}, path []string, value []byte) error {
+	for i, name := range path[:len(path)-1] {
+		section, ok := settings[name].(map[string]interface{})
+		if !ok {
+			if _, set := settings[name]; set {
+				return fmt.Errorf("'%s' is both a setting and a section", strings.Join(path[:i+1], "/"))
+			}
+			section = map[string]interface{}{}
+			settings[name] = section
+		}
+		settings = section
+	}
+	name := path[len(path)-1]
+	if _, set := settings[name].(map[string]interface{}); set {
+		return fmt.Errorf("'%s' is both a setting and a section", strings.Join(path, "/"))
+	}
+	var v interface{}
+	if err := json.Unmarshal(value, &v); err != nil {
+		v = string(value)
+	}
+	settings[name] = v
+	return nil
+}
+
+// fetchEtcd reads the keys under the prefix through the range API of the
+// JSON gateway of etcd v3, the keys and values are encoded in base64
+func fetchEtcd(client *http.Client, endpoint, prefix string) ([]kv, error) {
+	req := map[string]string{
+		"key":       base64.StdEncoding.EncodeToString([]byte(prefix)),
+		"range_end": base64.StdEncoding.EncodeToString(prefixEnd(prefix)),
+	}
+	b, _ := json.Marshal(req)
+	resp, err := client.Post(endpoint+"/v3/kv/range", "application/json", bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, unexpectedStatus(resp)
+	}
+	var body struct {
+		Kvs []struct {
+			Key   string `json:"key"`
+			Value string `json:"value"`
+		} `json:"kvs"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	kvs := make([]kv, 0, len(body.Kvs))
+	for _, e := range body.Kvs {
+		key, err := base64.StdEncoding.DecodeString(e.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := base64.StdEncoding.DecodeString(e.Value)
+		if err != nil {
+			return nil, err
+		}
+		kvs = append(kvs, kv{key: strings.TrimPrefix(string(key), prefix), value: value})
+	}
+	return kvs, nil
+}
+
+// fetchConsul reads the keys under the prefix through the KV API of consul,
+// the values are encoded in base64 and are null for the folders
+func fetchConsul(client *http.Client, endpoint, prefix string) ([]kv, error) {
+	resp, err := client.Get(endpoint + "/v1/kv/" + strings.TrimPrefix(prefix, "/") + "?recurse")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	// consul answers 404 when no key has the prefix
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, unexpectedStatus(resp)
+	}
+	var body []struct {
+		Key   string  `json:"Key"`
+		Value *string `json:"Value"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	kvs := make([]kv, 0, len(body))
+	for _, e := range body {
+		if e.Value == nil {
+			continue
+		}
+		value, err := base64.StdEncoding.DecodeString(*e.Value)
+		if err != nil {
+			return nil, err
+		}
+		kvs = append(kvs, kv{key: strings.TrimPrefix(e.Key, strings.TrimPrefix(prefix, "/")), value: value})
+	}
+	return kvs, nil
+}
+
+// prefixEnd returns the end of the range of the keys with the prefix, the
+// keys of the range are lower than it
+func prefixEnd(prefix string) []byte {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	// every key is in the range of an empty prefix
+	return []byte{0}
+}
+
+func unexpectedStatus(resp *http.Response) error {
+	b, _ := ioutil.ReadAll(resp.Body)
+	return fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(b)))
+}
@@ -0,0 +1,128 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cfgremote
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func b64(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+func TestFetch(t *testing.T) {
+	Convey("Given settings in etcd", t, func() {
+		var req map[string]string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/v3/kv/range" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"kvs": []map[string]string{
+					{"key": b64("snap/config/log_level"), "value": b64("1")},
+					{"key": b64("snap/config/restapi/port"), "value": b64("8282")},
+					{"key": b64("snap/config/restapi/rest_auth"), "value": b64("true")},
+					{"key": b64("snap/config/logging/modules/control"), "value": b64("debug")},
+				},
+			})
+		}))
+		defer srv.Close()
+		cfg := GetDefaultConfig()
+		cfg.Type = TypeEtcd
+		cfg.Endpoint = srv.URL
+
+		Convey("the keys under the prefix are read as settings", func() {
+			settings, err := Fetch(cfg)
+			So(err, ShouldBeNil)
+			So(req["key"], ShouldEqual, b64("snap/config/"))
+			So(req["range_end"], ShouldEqual, b64("snap/config0"))
+			So(settings["log_level"], ShouldEqual, 1.0)
+			So(settings["restapi"], ShouldResemble, map[string]interface{}{"port": 8282.0, "rest_auth": true})
+			So(settings["logging"], ShouldResemble, map[string]interface{}{
+				"modules": map[string]interface{}{"control": "debug"},
+			})
+		})
+	})
+
+	Convey("Given settings in consul", t, func() {
+		status := http.StatusOK
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := r.URL.Query()["recurse"]; r.URL.Path != "/v1/kv/snap/config/" || !ok {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"Key": "snap/config/restapi/", "Value": nil},
+				{"Key": "snap/config/restapi/addr", "Value": b64("127.0.0.1")},
+			})
+		}))
+		defer srv.Close()
+		cfg := GetDefaultConfig()
+		cfg.Type = TypeConsul
+		cfg.Endpoint = srv.URL + "/"
+
+		Convey("the keys under the prefix are read as settings", func() {
+			settings, err := Fetch(cfg)
+			So(err, ShouldBeNil)
+			So(settings, ShouldResemble, map[string]interface{}{
+				"restapi": map[string]interface{}{"addr": "127.0.0.1"},
+			})
+		})
+		Convey("no settings are read when no key has the prefix", func() {
+			status = http.StatusNotFound
+			settings, err := Fetch(cfg)
+			So(err, ShouldBeNil)
+			So(settings, ShouldBeEmpty)
+		})
+		Convey("an error is returned when the store fails", func() {
+			status = http.StatusInternalServerError
+			_, err := Fetch(cfg)
+			So(err, ShouldNotBeNil)
+		})
+	})
+
+	Convey("Given no key-value store", t, func() {
+		Convey("no settings are read", func() {
+			settings, err := Fetch(GetDefaultConfig())
+			So(err, ShouldBeNil)
+			So(settings, ShouldBeNil)
+		})
+	})
+
+	Convey("Given a setting which is a section", t, func() {
+		Convey("an error is returned", func() {
+			settings := map[string]interface{}{}
+			So(setValue(settings, []string{"restapi", "port"}, []byte("8181")), ShouldBeNil)
+			So(setValue(settings, []string{"restapi"}, []byte("1")), ShouldNotBeNil)
+			So(setValue(settings, []string{"restapi", "port", "x"}, []byte("1")), ShouldNotBeNil)
+		})
+	})
+}
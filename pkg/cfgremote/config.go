@@ -0,0 +1,80 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cfgremote
+
+import (
+	"time"
+
+	"github.com/vrischmann/jsonutil"
+)
+
+// default configuration values
+const (
+	defaultType     = ""
+	defaultEndpoint = ""
+	defaultPrefix   = "snap/config/"
+	defaultTimeout  = 5 * time.Second
+)
+
+// holds the configuration passed in through the SNAP config file
+type Config struct {
+	// Type is the key-value store the settings are read from, etcd or
+	// consul, no settings are read when it's empty
+	Type     string `json:"type"yaml:"type"`
+	Endpoint string `json:"endpoint"yaml:"endpoint"`
+	// Prefix is the prefix of the keys of the settings, the key
+	// snap/config/restapi/port sets the port of the REST API
+	Prefix  string            `json:"prefix"yaml:"prefix"`
+	Timeout jsonutil.Duration `json:"timeout"yaml:"timeout"`
+}
+
+const (
+	CONFIG_CONSTRAINTS = `
+			"remote_config": {
+				"type": ["object", "null"],
+				"properties" : {
+					"type": {
+						"type": "string",
+						"enum": ["", "etcd", "consul"]
+					},
+					"endpoint": {
+						"type": "string"
+					},
+					"prefix": {
+						"type": "string"
+					},
+					"timeout": {
+						"type": "string"
+					}
+				},
+				"additionalProperties": false
+			}
+	`
+)
+
+// GetDefaultConfig gets the default snapteld configuration
+func GetDefaultConfig() *Config {
+	return &Config{
+		Type:     defaultType,
+		Endpoint: defaultEndpoint,
+		Prefix:   defaultPrefix,
+		Timeout:  jsonutil.Duration{defaultTimeout},
+	}
+}
@@ -0,0 +1,45 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cfgremote
+
+import (
+	"github.com/urfave/cli"
+)
+
+var (
+	flRemoteConfigType = cli.StringFlag{
+		Name:   "remote-config-type",
+		Usage:  "The key-value store the settings overriding the configuration are read from, etcd or consul",
+		EnvVar: "SNAP_REMOTE_CONFIG_TYPE",
+	}
+	flRemoteConfigEndpoint = cli.StringFlag{
+		Name:   "remote-config-endpoint",
+		Usage:  "The URL of the key-value store the settings overriding the configuration are read from",
+		EnvVar: "SNAP_REMOTE_CONFIG_ENDPOINT",
+	}
+	flRemoteConfigPrefix = cli.StringFlag{
+		Name:   "remote-config-prefix",
+		Usage:  "The prefix of the keys of the settings in the key-value store (default: " + defaultPrefix + ")",
+		EnvVar: "SNAP_REMOTE_CONFIG_PREFIX",
+	}
+
+	// Flags consumed by snapteld
+	Flags = []cli.Flag{flRemoteConfigType, flRemoteConfigEndpoint, flRemoteConfigPrefix}
+)
@@ -131,7 +131,8 @@ func (c *ConfigTree) GetAll() []keyNode {
 func (c *ConfigTree) getAll(node *node, key []string, res *[]keyNode) []keyNode {
 	if len(node.keys) > 0 {
 		if key != nil {
-			key = append(key, node.keys[0])
+			// copy on append so that siblings don't share the backing array
+			key = append(key[:len(key):len(key)], node.keys[0])
 		} else {
 			key = []string{node.keys[0]}
 		}
@@ -0,0 +1,149 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+// the formats of the log entries
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// the types of the sinks
+const (
+	SinkStderr = "stderr"
+	SinkStdout = "stdout"
+	SinkFile   = "file"
+	SinkSyslog = "syslog"
+)
+
+// default configuration values
+const (
+	defaultFormat     = FormatText
+	defaultMaxBackups = 5
+	defaultSyslogTag  = "snapteld"
+)
+
+// holds the configuration passed in through the SNAP config file
+type Config struct {
+	// Format is the format of the log entries, text or json
+	Format string `json:"format"yaml:"format"`
+	// Modules are the log levels of the modules overriding log_level, the
+	// level of a module applies to its submodules, e.g. control applies to
+	// control-runner
+	Modules map[string]string `json:"modules"yaml:"modules"`
+	// Sinks are where the log entries are written, the entries are written
+	// to log_path, or to stderr, when there are none
+	Sinks []SinkConfig `json:"sinks"yaml:"sinks"`
+}
+
+// SinkConfig is the configuration of a sink of the log entries
+type SinkConfig struct {
+	// Type is stderr, stdout, file or syslog
+	Type string `json:"type"yaml:"type"`
+	// Level is the least severe level written to the sink, every entry
+	// logged is written when it's empty
+	Level string `json:"level"yaml:"level"`
+	// Path is the file of a file sink
+	Path string `json:"path"yaml:"path"`
+	// Truncate truncates the file of a file sink when snapteld starts
+	Truncate bool `json:"truncate"yaml:"truncate"`
+	// MaxSize is the size in megabytes a file is rotated at, it's never
+	// rotated when it's 0
+	MaxSize int `json:"max_size"yaml:"max_size"`
+	// MaxBackups is the number of rotated files kept
+	MaxBackups int `json:"max_backups"yaml:"max_backups"`
+	// Network and Address are the syslog daemon of a syslog sink, the local
+	// one when they're empty
+	Network string `json:"network"yaml:"network"`
+	Address string `json:"address"yaml:"address"`
+	// Tag is the tag of the syslog messages
+	Tag string `json:"tag"yaml:"tag"`
+}
+
+const (
+	CONFIG_CONSTRAINTS = `
+			"logging": {
+				"type": ["object", "null"],
+				"properties" : {
+					"format": {
+						"type": "string",
+						"enum": ["text", "json"]
+					},
+					"modules": {
+						"type": ["object", "null"],
+						"additionalProperties": {
+							"type": "string",
+							"enum": ["debug", "info", "warning", "error", "fatal", "panic"]
+						}
+					},
+					"sinks": {
+						"type": ["array", "null"],
+						"items": {
+							"type": "object",
+							"properties": {
+								"type": {
+									"type": "string",
+									"enum": ["stderr", "stdout", "file", "syslog"]
+								},
+								"level": {
+									"type": "string",
+									"enum": ["debug", "info", "warning", "error", "fatal", "panic"]
+								},
+								"path": {
+									"type": "string"
+								},
+								"truncate": {
+									"type": "boolean"
+								},
+								"max_size": {
+									"type": "integer",
+									"minimum": 0
+								},
+								"max_backups": {
+									"type": "integer",
+									"minimum": 0
+								},
+								"network": {
+									"type": "string"
+								},
+								"address": {
+									"type": "string"
+								},
+								"tag": {
+									"type": "string"
+								}
+							},
+							"required": ["type"],
+							"additionalProperties": false
+						}
+					}
+				},
+				"additionalProperties": false
+			}
+	`
+)
+
+// GetDefaultConfig gets the default snapteld configuration
+func GetDefaultConfig() *Config {
+	return &Config{
+		Format:  defaultFormat,
+		Modules: map[string]string{},
+	}
+}
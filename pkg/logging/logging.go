@@ -0,0 +1,227 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package logging writes the entries of the standard logger of logrus to
+// configurable sinks, filtering them with log levels set per module which can
+// be changed while snapteld is running
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// the field of the log entries naming the module logging them
+const moduleField = "_module"
+
+var (
+	ErrUnknownSinkType = errors.New("Unknown log sink type")
+	ErrNoSinkPath      = errors.New("A file log sink requires a path")
+	ErrModuleNotFound  = errors.New("No log level is set for the module")
+)
+
+// Logging filters the log entries by the levels of their module and writes
+// them to its sinks
+type Logging struct {
+	mutex sync.RWMutex
+	// level is the level of the entries of the modules without a level
+	level   log.Level
+	modules map[string]log.Level
+	sinks   []sink
+	// installed is true once the standard logger writes to the sinks, its
+	// level then follows the levels of the modules
+	installed bool
+}
+
+// New returns the logging of the entries of the given level, or of the level
+// of their module, to the sinks of the configuration
+func New(cfg *Config, level log.Level) (*Logging, error) {
+	l := &Logging{
+		level:   level,
+		modules: map[string]log.Level{},
+	}
+	for module, name := range cfg.Modules {
+		lvl, err := log.ParseLevel(name)
+		if err != nil {
+			return nil, err
+		}
+		l.modules[module] = lvl
+	}
+	for _, sc := range cfg.Sinks {
+		s, err := newSink(sc)
+		if err != nil {
+			l.Close()
+			return nil, err
+		}
+		l.sinks = append(l.sinks, s)
+	}
+	return l, nil
+}
+
+// Install writes the entries of the standard logger to the sinks instead of
+// its output
+func (l *Logging) Install() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	log.SetOutput(ioutil.Discard)
+	log.AddHook(l)
+	l.installed = true
+	l.applyLevel()
+}
+
+// Close closes the sinks
+func (l *Logging) Close() error {
+	var errs []string
+	for _, s := range l.sinks {
+		if err := s.Close(); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Levels returns the levels of the entries the hook is fired for, all of them
+func (l *Logging) Levels() []log.Level {
+	return log.AllLevels
+}
+
+// Fire writes an entry to the sinks when its level is enabled for its module
+func (l *Logging) Fire(entry *log.Entry) error {
+	l.mutex.RLock()
+	enabled := entry.Level <= l.moduleLevel(entryModule(entry))
+	l.mutex.RUnlock()
+	if !enabled {
+		return nil
+	}
+	b, err := entry.Logger.Formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	var errs []string
+	for _, s := range l.sinks {
+		if entry.Level > s.Level() {
+			continue
+		}
+		if err := s.Write(entry.Level, b); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("writing the log entry failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Level returns the level of the modules without a level
+func (l *Logging) Level() string {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	return l.level.String()
+}
+
+// ModuleLevels returns the levels set for the modules
+func (l *Logging) ModuleLevels() map[string]string {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	levels := make(map[string]string, len(l.modules))
+	for module, lvl := range l.modules {
+		levels[module] = lvl.String()
+	}
+	return levels
+}
+
+// SetLevel sets the level of a module, or the level of the modules without a
+// level when module is empty
+func (l *Logging) SetLevel(module, level string) error {
+	lvl, err := log.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if module == "" {
+		l.level = lvl
+	} else {
+		l.modules[module] = lvl
+	}
+	l.applyLevel()
+	return nil
+}
+
+// ResetLevel removes the level of a module, its entries are logged with the
+// level of its parent module or of the modules without a level from then on
+func (l *Logging) ResetLevel(module string) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if _, ok := l.modules[module]; !ok {
+		return ErrModuleNotFound
+	}
+	delete(l.modules, module)
+	l.applyLevel()
+	return nil
+}
+
+// applyLevel sets the level of the standard logger to the most verbose level
+// so that no entry enabled for a module is dropped before the hook is fired
+func (l *Logging) applyLevel() {
+	if !l.installed {
+		return
+	}
+	lvl := l.level
+	for _, ml := range l.modules {
+		if ml > lvl {
+			lvl = ml
+		}
+	}
+	log.SetLevel(lvl)
+}
+
+// moduleLevel returns the level of the module, or of its closest parent
+// module, e.g. control for control-runner
+func (l *Logging) moduleLevel(module string) log.Level {
+	if len(l.modules) == 0 {
+		return l.level
+	}
+	for module != "" {
+		if lvl, ok := l.modules[module]; ok {
+			return lvl
+		}
+		i := strings.LastIndex(module, "-")
+		if i < 0 {
+			break
+		}
+		module = module[:i]
+	}
+	return l.level
+}
+
+// entryModule returns the module of an entry, without the leading underscores
+// of the modules of the REST API
+func entryModule(entry *log.Entry) string {
+	module, _ := entry.Data[moduleField].(string)
+	return strings.TrimLeft(module, "_")
+}
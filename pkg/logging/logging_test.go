@@ -0,0 +1,152 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	log "github.com/Sirupsen/logrus"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// bufferSink keeps the entries written to it
+type bufferSink struct {
+	level log.Level
+	bytes.Buffer
+}
+
+func (s *bufferSink) Level() log.Level { return s.level }
+func (s *bufferSink) Close() error     { return nil }
+
+func (s *bufferSink) Write(_ log.Level, entry []byte) error {
+	_, err := s.Buffer.Write(entry)
+	return err
+}
+
+func entry(module string, level log.Level, msg string) *log.Entry {
+	logger := log.New()
+	logger.Formatter = &log.JSONFormatter{}
+	e := log.NewEntry(logger)
+	if module != "" {
+		e = e.WithField(moduleField, module)
+	}
+	e.Level = level
+	e.Message = msg
+	return e
+}
+
+func TestLogging(t *testing.T) {
+	Convey("Given the logging of warnings with levels per module", t, func() {
+		l, err := New(&Config{Modules: map[string]string{"control": "debug", "mgmt-rest": "error"}}, log.WarnLevel)
+		So(err, ShouldBeNil)
+		all := &bufferSink{level: log.DebugLevel}
+		errs := &bufferSink{level: log.ErrorLevel}
+		l.sinks = []sink{all, errs}
+
+		Convey("the entries are filtered by the level of their module", func() {
+			So(l.Fire(entry("control", log.DebugLevel, "control debug")), ShouldBeNil)
+			So(l.Fire(entry("control-runner", log.DebugLevel, "runner debug")), ShouldBeNil)
+			So(l.Fire(entry("_mgmt-rest-v2", log.WarnLevel, "rest warning")), ShouldBeNil)
+			So(l.Fire(entry("scheduler", log.InfoLevel, "scheduler info")), ShouldBeNil)
+			So(l.Fire(entry("", log.WarnLevel, "snapteld warning")), ShouldBeNil)
+			So(all.String(), ShouldContainSubstring, `"msg":"control debug"`)
+			So(all.String(), ShouldContainSubstring, `"msg":"runner debug"`)
+			So(all.String(), ShouldNotContainSubstring, "rest warning")
+			So(all.String(), ShouldNotContainSubstring, "scheduler info")
+			So(all.String(), ShouldContainSubstring, `"msg":"snapteld warning"`)
+		})
+		Convey("the entries are filtered by the level of the sinks", func() {
+			So(l.Fire(entry("scheduler", log.ErrorLevel, "scheduler error")), ShouldBeNil)
+			So(l.Fire(entry("control", log.InfoLevel, "control info")), ShouldBeNil)
+			So(errs.String(), ShouldContainSubstring, "scheduler error")
+			So(errs.String(), ShouldNotContainSubstring, "control info")
+		})
+		Convey("the levels can be changed", func() {
+			So(l.SetLevel("scheduler", "info"), ShouldBeNil)
+			So(l.SetLevel("", "error"), ShouldBeNil)
+			So(l.ResetLevel("control"), ShouldBeNil)
+			So(l.Level(), ShouldEqual, "error")
+			So(l.ModuleLevels(), ShouldResemble, map[string]string{"scheduler": "info", "mgmt-rest": "error"})
+			So(l.Fire(entry("scheduler-job", log.InfoLevel, "job info")), ShouldBeNil)
+			So(l.Fire(entry("control", log.WarnLevel, "control warning")), ShouldBeNil)
+			So(all.String(), ShouldContainSubstring, "job info")
+			So(all.String(), ShouldNotContainSubstring, "control warning")
+		})
+		Convey("the levels are validated", func() {
+			So(l.SetLevel("control", "verbose"), ShouldNotBeNil)
+			So(l.ResetLevel("tribe"), ShouldEqual, ErrModuleNotFound)
+		})
+	})
+	Convey("The sinks are validated", t, func() {
+		_, err := New(&Config{Sinks: []SinkConfig{{Type: "kafka"}}}, log.WarnLevel)
+		So(err, ShouldNotBeNil)
+		_, err = New(&Config{Sinks: []SinkConfig{{Type: SinkFile}}}, log.WarnLevel)
+		So(err, ShouldEqual, ErrNoSinkPath)
+		_, err = New(&Config{Modules: map[string]string{"control": "verbose"}}, log.WarnLevel)
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestFileSink(t *testing.T) {
+	Convey("Given a file sink rotated at its maximum size", t, func() {
+		dir, err := ioutil.TempDir("", "snap-logging")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "snapteld.log")
+		s, err := newFileSink(log.DebugLevel, SinkConfig{Path: path, MaxBackups: 2})
+		So(err, ShouldBeNil)
+		// a megabyte is too large to test the rotation
+		s.maxSize = 10
+		line := []byte("12345678\n")
+		for i := 0; i < 4; i++ {
+			So(s.Write(log.InfoLevel, line), ShouldBeNil)
+		}
+		So(s.Close(), ShouldBeNil)
+		Convey("the rotated files are kept up to the maximum number of backups", func() {
+			for _, name := range []string{path, path + ".1", path + ".2"} {
+				b, err := ioutil.ReadFile(name)
+				So(err, ShouldBeNil)
+				So(string(b), ShouldEqual, string(line))
+			}
+			_, err := os.Stat(path + ".3")
+			So(os.IsNotExist(err), ShouldBeTrue)
+		})
+		Convey("the file is appended to unless it's truncated", func() {
+			s, err := newFileSink(log.DebugLevel, SinkConfig{Path: path})
+			So(err, ShouldBeNil)
+			So(s.Write(log.InfoLevel, []byte("appended\n")), ShouldBeNil)
+			So(s.Close(), ShouldBeNil)
+			b, _ := ioutil.ReadFile(path)
+			So(strings.Count(string(b), "\n"), ShouldEqual, 2)
+			s, err = newFileSink(log.DebugLevel, SinkConfig{Path: path, Truncate: true})
+			So(err, ShouldBeNil)
+			So(s.Close(), ShouldBeNil)
+			b, _ = ioutil.ReadFile(path)
+			So(b, ShouldBeEmpty)
+		})
+	})
+}
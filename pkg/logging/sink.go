@@ -0,0 +1,173 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// sink is where the formatted log entries are written
+type sink interface {
+	// Level is the least severe level of the entries written to the sink
+	Level() log.Level
+	Write(level log.Level, entry []byte) error
+	Close() error
+}
+
+func newSink(cfg SinkConfig) (sink, error) {
+	level := log.DebugLevel
+	if cfg.Level != "" {
+		var err error
+		if level, err = log.ParseLevel(cfg.Level); err != nil {
+			return nil, err
+		}
+	}
+	switch cfg.Type {
+	case SinkStderr:
+		return &writerSink{level: level, w: os.Stderr}, nil
+	case SinkStdout:
+		return &writerSink{level: level, w: os.Stdout}, nil
+	case SinkFile:
+		return newFileSink(level, cfg)
+	case SinkSyslog:
+		return newSyslogSink(level, cfg)
+	}
+	return nil, fmt.Errorf("%v: %s", ErrUnknownSinkType, cfg.Type)
+}
+
+// writerSink writes the entries to stderr or stdout
+type writerSink struct {
+	mutex sync.Mutex
+	level log.Level
+	w     io.Writer
+}
+
+func (s *writerSink) Level() log.Level {
+	return s.level
+}
+
+func (s *writerSink) Write(_ log.Level, entry []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, err := s.w.Write(entry)
+	return err
+}
+
+func (s *writerSink) Close() error {
+	return nil
+}
+
+// fileSink writes the entries to a file, rotated once it reaches its maximum
+// size: the file is renamed path.1, the former path.1 path.2 and so on up to
+// the maximum number of backups
+type fileSink struct {
+	mutex      sync.Mutex
+	level      log.Level
+	path       string
+	file       *os.File
+	size       int64
+	maxSize    int64
+	maxBackups int
+}
+
+func newFileSink(level log.Level, cfg SinkConfig) (*fileSink, error) {
+	if cfg.Path == "" {
+		return nil, ErrNoSinkPath
+	}
+	maxBackups := cfg.MaxBackups
+	if maxBackups == 0 {
+		maxBackups = defaultMaxBackups
+	}
+	s := &fileSink{
+		level:      level,
+		path:       cfg.Path,
+		maxSize:    int64(cfg.MaxSize) * 1024 * 1024,
+		maxBackups: maxBackups,
+	}
+	mode := os.O_APPEND
+	if cfg.Truncate {
+		mode = os.O_TRUNC
+	}
+	if err := s.open(mode); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *fileSink) open(mode int) error {
+	f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_CREATE|mode, 0666)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	s.file = f
+	s.size = fi.Size()
+	return nil
+}
+
+func (s *fileSink) Level() log.Level {
+	return s.level
+}
+
+func (s *fileSink) Write(_ log.Level, entry []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.maxSize > 0 && s.size > 0 && s.size+int64(len(entry)) > s.maxSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := s.file.Write(entry)
+	s.size += int64(n)
+	return err
+}
+
+func (s *fileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	for i := s.maxBackups - 1; i > 0; i-- {
+		backup := fmt.Sprintf("%s.%d", s.path, i)
+		if _, err := os.Stat(backup); err == nil {
+			if err := os.Rename(backup, fmt.Sprintf("%s.%d", s.path, i+1)); err != nil {
+				return err
+			}
+		}
+	}
+	if err := os.Rename(s.path, s.path+".1"); err != nil {
+		return err
+	}
+	return s.open(os.O_TRUNC)
+}
+
+func (s *fileSink) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.file.Close()
+}
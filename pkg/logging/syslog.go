@@ -0,0 +1,71 @@
+// +build !windows
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"log/syslog"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// syslogSink writes the entries to a syslog daemon with the priority of their
+// level
+type syslogSink struct {
+	level log.Level
+	w     *syslog.Writer
+}
+
+func newSyslogSink(level log.Level, cfg SinkConfig) (sink, error) {
+	tag := cfg.Tag
+	if tag == "" {
+		tag = defaultSyslogTag
+	}
+	w, err := syslog.Dial(cfg.Network, cfg.Address, syslog.LOG_INFO|syslog.LOG_DAEMON, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &syslogSink{level: level, w: w}, nil
+}
+
+func (s *syslogSink) Level() log.Level {
+	return s.level
+}
+
+func (s *syslogSink) Write(level log.Level, entry []byte) error {
+	msg := strings.TrimSuffix(string(entry), "\n")
+	switch level {
+	case log.PanicLevel, log.FatalLevel:
+		return s.w.Crit(msg)
+	case log.ErrorLevel:
+		return s.w.Err(msg)
+	case log.WarnLevel:
+		return s.w.Warning(msg)
+	case log.InfoLevel:
+		return s.w.Info(msg)
+	}
+	return s.w.Debug(msg)
+}
+
+func (s *syslogSink) Close() error {
+	return s.w.Close()
+}
@@ -0,0 +1,34 @@
+// +build windows
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"errors"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+var ErrSyslogUnsupported = errors.New("The syslog log sink is not supported on windows")
+
+func newSyslogSink(log.Level, SinkConfig) (sink, error) {
+	return nil, ErrSyslogUnsupported
+}
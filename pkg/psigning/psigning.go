@@ -21,11 +21,16 @@ package psigning
 
 import (
 	"bytes"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
+	"golang.org/x/crypto/ed25519"
 	"golang.org/x/crypto/openpgp"
 )
 
@@ -43,10 +48,42 @@ var (
 	ErrSignedFileNotFound = errors.New("Signed file not found")
 	// ErrCheckSignature - Error message for error checking signature
 	ErrCheckSignature = errors.New("Error checking signature")
+	// ErrNoEd25519Keyring - Error message for an ed25519 signature without an ed25519 keyring
+	ErrNoEd25519Keyring = errors.New("No ed25519 keyring (.pub) found")
 )
 
+// SignatureFileExtensions are the extensions of detached signature files:
+// armored GPG signatures (.asc) and ed25519 signatures (.sig)
+var SignatureFileExtensions = []string{".asc", ".sig"}
+
+// IsSignatureFile returns true when the file is a detached signature file
+func IsSignatureFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, e := range SignatureFileExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// SignatureFile returns the path of the detached signature file next to the
+// signed file or an empty string when there is none
+func SignatureFile(signedFile string) string {
+	for _, e := range SignatureFileExtensions {
+		if _, err := os.Stat(signedFile + e); err == nil {
+			return signedFile + e
+		}
+	}
+	return ""
+}
+
 //ValidateSignature is exported for plugin authoring
 func (s *SigningManager) ValidateSignature(keyringFiles []string, signedFile string, signature []byte) error {
+	if sig, ok := ed25519Signature(signature); ok {
+		return validateEd25519Signature(keyringFiles, signedFile, sig)
+	}
+
 	var signedby string
 	var e error
 	var checked *openpgp.Entity
@@ -59,6 +96,10 @@ func (s *SigningManager) ValidateSignature(keyringFiles []string, signedFile str
 
 	//Go through all the keyrings til either signature is valid or end of keyrings
 	for _, keyringFile := range keyringFiles {
+		// ed25519 keyrings can't check GPG signatures
+		if _, err := readEd25519Keyring(keyringFile); err == nil {
+			continue
+		}
 		keyringf, err := os.Open(keyringFile)
 		if err != nil {
 			return fmt.Errorf("%v: %v\n%v", ErrKeyringFileNotFound, keyringFile, err)
@@ -88,3 +129,73 @@ func (s *SigningManager) ValidateSignature(keyringFiles []string, signedFile str
 	}
 	return fmt.Errorf("%v\n%v", ErrCheckSignature, e)
 }
+
+// validateEd25519Signature checks the ed25519 signature of the signed file
+// against the public keys of the ed25519 keyrings
+func validateEd25519Signature(keyringFiles []string, signedFile string, signature []byte) error {
+	signed, err := ioutil.ReadFile(signedFile)
+	if err != nil {
+		return fmt.Errorf("%v: %v\n%v", ErrSignedFileNotFound, signedFile, err)
+	}
+
+	found := false
+	for _, keyringFile := range keyringFiles {
+		if _, err := os.Stat(keyringFile); err != nil {
+			return fmt.Errorf("%v: %v\n%v", ErrKeyringFileNotFound, keyringFile, err)
+		}
+		// GPG keyrings can't check ed25519 signatures
+		keys, err := readEd25519Keyring(keyringFile)
+		if err != nil {
+			continue
+		}
+		found = true
+		for _, key := range keys {
+			if ed25519.Verify(key, signed, signature) {
+				fmt.Printf("Signature made %v using ed25519 key %v\nGood signature from %v\n", time.Now().Format(time.RFC1123), base64.StdEncoding.EncodeToString(key), keyringFile)
+				return nil
+			}
+		}
+	}
+	if !found {
+		return fmt.Errorf("%v\n%v", ErrCheckSignature, ErrNoEd25519Keyring)
+	}
+	return fmt.Errorf("%v\n%v", ErrCheckSignature, errors.New("ed25519: invalid signature"))
+}
+
+// readEd25519Keyring reads an ed25519 keyring; a file with one base64 encoded
+// public key per line, empty lines and lines starting with '#' are ignored
+func readEd25519Keyring(keyringFile string) ([]ed25519.PublicKey, error) {
+	b, err := ioutil.ReadFile(keyringFile)
+	if err != nil {
+		return nil, err
+	}
+	var keys []ed25519.PublicKey
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, err := base64.StdEncoding.DecodeString(line)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("%v: %v", ErrUnableToReadKeyring, keyringFile)
+		}
+		keys = append(keys, ed25519.PublicKey(key))
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%v: %v", ErrUnableToReadKeyring, keyringFile)
+	}
+	return keys, nil
+}
+
+// ed25519Signature returns the ed25519 signature, either raw or base64 encoded;
+// false is returned for any other signature (e.g. an armored GPG signature)
+func ed25519Signature(signature []byte) ([]byte, bool) {
+	if len(signature) == ed25519.SignatureSize {
+		return signature, true
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return nil, false
+	}
+	return sig, true
+}
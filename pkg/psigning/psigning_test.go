@@ -22,11 +22,16 @@ limitations under the License.
 package psigning
 
 import (
+	"crypto/rand"
+	"encoding/base64"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/intelsdi-x/snap/plugin/helper"
 	. "github.com/smartystreets/goconvey/convey"
+	"golang.org/x/crypto/ed25519"
 )
 
 func TestValidateSignature(t *testing.T) {
@@ -72,3 +77,76 @@ func TestValidateSignature(t *testing.T) {
 		So(err.Error(), ShouldContainSubstring, "Error checking signature")
 	})
 }
+
+func TestValidateEd25519Signature(t *testing.T) {
+	signedFile := "snap-plugin-collector-mock1"
+	unsignedFile := helper.PluginFilePath("snap-plugin-collector-mock2")
+	s := SigningManager{}
+
+	dir, err := ioutil.TempDir("", "psigning")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyringFile := filepath.Join(dir, "keys.pub")
+	ioutil.WriteFile(keyringFile, []byte("# snap plugin keys\n"+
+		base64.StdEncoding.EncodeToString(otherPub)+"\n"+
+		base64.StdEncoding.EncodeToString(pub)+"\n"), 0644)
+	b, _ := ioutil.ReadFile(signedFile)
+	signature := ed25519.Sign(priv, b)
+
+	Convey("Valid files and good raw signature", t, func() {
+		err := s.ValidateSignature([]string{keyringFile}, signedFile, signature)
+		So(err, ShouldBeNil)
+	})
+
+	Convey("Valid files and good base64 encoded signature", t, func() {
+		encoded := []byte(base64.StdEncoding.EncodeToString(signature) + "\n")
+		err := s.ValidateSignature([]string{keyringFile}, signedFile, encoded)
+		So(err, ShouldBeNil)
+	})
+
+	Convey("Valid files and good signatures. GPG and ed25519 keyrings", t, func() {
+		keyringFiles := []string{"pubring.gpg", keyringFile}
+		err := s.ValidateSignature(keyringFiles, signedFile, signature)
+		So(err, ShouldBeNil)
+		gpgSignature, _ := ioutil.ReadFile(signedFile + ".asc")
+		err = s.ValidateSignature(keyringFiles, signedFile, gpgSignature)
+		So(err, ShouldBeNil)
+	})
+
+	Convey("Validate unsigned file with signature", t, func() {
+		err := s.ValidateSignature([]string{keyringFile}, unsignedFile, signature)
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, "Error checking signature")
+	})
+
+	Convey("Validate signature without ed25519 keyring", t, func() {
+		err := s.ValidateSignature([]string{"pubring.gpg"}, signedFile, signature)
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, "No ed25519 keyring (.pub) found")
+	})
+}
+
+func TestSignatureFile(t *testing.T) {
+	Convey("Signature files are recognized by their extension", t, func() {
+		So(IsSignatureFile("plugin.asc"), ShouldBeTrue)
+		So(IsSignatureFile("plugin.sig"), ShouldBeTrue)
+		So(IsSignatureFile("plugin"), ShouldBeFalse)
+		So(IsSignatureFile("plugin.aci"), ShouldBeFalse)
+	})
+
+	Convey("The signature file next to the signed file is found", t, func() {
+		So(SignatureFile("snap-plugin-collector-mock1"), ShouldEqual, "snap-plugin-collector-mock1.asc")
+		So(SignatureFile("pubring.gpg"), ShouldEqual, "")
+	})
+}
@@ -0,0 +1,48 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rpcutil
+
+import (
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// PipePrefix is the prefix of the paths of the Windows named pipes, the
+// plugins listening on a named pipe return its path as their address
+const PipePrefix = `\\.\pipe\`
+
+// ErrPipeUnsupported is returned when listening on or dialing a named pipe
+// anywhere but on Windows
+var ErrPipeUnsupported = errors.New("named pipes are only supported on Windows")
+
+// IsPipeAddress returns true when the address is the path of a named pipe
+func IsPipeAddress(addr string) bool {
+	return strings.HasPrefix(addr, PipePrefix)
+}
+
+// GetPipeClientConnectionWithCreds returns a grpc.ClientConn over the named
+// pipe at the path with optional TLS security (if creds != nil) and the given
+// additional dial options
+func GetPipeClientConnectionWithCreds(path string, creds credentials.TransportCredentials, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	return dial(path, creds, append(opts, grpc.WithDialer(DialPipe))...)
+}
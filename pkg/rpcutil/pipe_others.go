@@ -0,0 +1,42 @@
+// +build !windows
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rpcutil
+
+import (
+	"net"
+	"time"
+)
+
+// NewPipePath returns "" as there are no named pipes to listen on
+func NewPipePath(name string) string {
+	return ""
+}
+
+// ListenPipe returns ErrPipeUnsupported
+func ListenPipe(path string) (net.Listener, error) {
+	return nil, ErrPipeUnsupported
+}
+
+// DialPipe returns ErrPipeUnsupported
+func DialPipe(path string, timeout time.Duration) (net.Conn, error) {
+	return nil, ErrPipeUnsupported
+}
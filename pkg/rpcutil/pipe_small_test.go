@@ -0,0 +1,87 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rpcutil
+
+import (
+	"io"
+	"runtime"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPipe(t *testing.T) {
+	Convey("Named pipes", t, func() {
+		So(IsPipeAddress(`\\.\pipe\snap-plugin-1`), ShouldBeTrue)
+		So(IsPipeAddress("127.0.0.1:8183"), ShouldBeFalse)
+
+		if runtime.GOOS != "windows" {
+			Convey("aren't supported anywhere but on Windows", func() {
+				So(NewPipePath("snap-plugin"), ShouldBeEmpty)
+				_, err := ListenPipe(`\\.\pipe\snap-plugin-1`)
+				So(err, ShouldEqual, ErrPipeUnsupported)
+				_, err = DialPipe(`\\.\pipe\snap-plugin-1`, time.Second)
+				So(err, ShouldEqual, ErrPipeUnsupported)
+			})
+			return
+		}
+		Convey("carry the data both ways", func() {
+			path := NewPipePath("snap-plugin")
+			l, err := ListenPipe(path)
+			So(err, ShouldBeNil)
+			defer l.Close()
+			// the path of a pipe is listened on once
+			_, err = ListenPipe(path)
+			So(err, ShouldNotBeNil)
+
+			go func() {
+				conn, err := l.Accept()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+			conn, err := DialPipe(path, time.Second)
+			So(err, ShouldBeNil)
+			defer conn.Close()
+			So(conn.RemoteAddr().String(), ShouldEqual, path)
+			_, err = conn.Write([]byte("ping"))
+			So(err, ShouldBeNil)
+			b := make([]byte, 4)
+			_, err = io.ReadFull(conn, b)
+			So(err, ShouldBeNil)
+			So(string(b), ShouldEqual, "ping")
+
+			Convey("until a read times out", func() {
+				conn.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+				_, err := conn.Read(b)
+				So(err, ShouldNotBeNil)
+			})
+		})
+		Convey("fail to be dialed when nobody listens on them", func() {
+			_, err := DialPipe(NewPipePath("snap-plugin"), 0)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
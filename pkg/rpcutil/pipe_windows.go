@@ -0,0 +1,50 @@
+// +build windows
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rpcutil
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"time"
+
+	winio "github.com/Microsoft/go-winio"
+)
+
+// NewPipePath returns the path of a new named pipe, unique to the process
+func NewPipePath(name string) string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return PipePrefix + name + "-" + hex.EncodeToString(b)
+}
+
+// ListenPipe listens on the named pipe at the path, only the clients of the
+// local host can connect to it
+func ListenPipe(path string) (net.Listener, error) {
+	return winio.ListenPipe(path, nil)
+}
+
+// DialPipe connects to the named pipe at the path, it waits for an instance
+// of the pipe to be available until the timeout elapses
+func DialPipe(path string, timeout time.Duration) (net.Conn, error) {
+	return winio.DialPipe(path, &timeout)
+}
@@ -36,17 +36,22 @@ func GetClientConnection(addr string, port int) (*grpc.ClientConn, error) {
 }
 
 // GetClientConnectionWithCreds returns a grcp.ClientConn with optional TLS
-// security (if creds != nil)
-func GetClientConnectionWithCreds(addr string, port int, creds credentials.TransportCredentials) (*grpc.ClientConn, error) {
+// security (if creds != nil) and the given additional dial options
+func GetClientConnectionWithCreds(addr string, port int, creds credentials.TransportCredentials, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	return dial(fmt.Sprintf("%v:%v", addr, port), creds, opts...)
+}
+
+func dial(target string, creds credentials.TransportCredentials, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
 	grpcDialOpts := []grpc.DialOption{
 		grpc.WithTimeout(grpcDialDefaultTimeout),
 	}
+	grpcDialOpts = append(grpcDialOpts, opts...)
 	if creds != nil {
 		grpcDialOpts = append(grpcDialOpts, grpc.WithTransportCredentials(creds))
 	} else {
 		grpcDialOpts = append(grpcDialOpts, grpc.WithInsecure())
 	}
-	conn, err := grpc.Dial(fmt.Sprintf("%v:%v", addr, port), grpcDialOpts...)
+	conn, err := grpc.Dial(target, grpcDialOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -20,6 +20,7 @@ package schedule
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/robfig/cron"
@@ -31,6 +32,8 @@ var ErrMissingCronEntry = errors.New("Cron entry is missing")
 // CronSchedule is a schedule that waits as long as specified in cron entry
 type CronSchedule struct {
 	entry    string
+	timezone string
+	location *time.Location
 	enabled  bool
 	state    ScheduleState
 	schedule *cron.Cron
@@ -46,11 +49,26 @@ func NewCronSchedule(entry string) *CronSchedule {
 	}
 }
 
+// NewCronScheduleWithTimezone returns a cron schedule whose entry is evaluated
+// in the given timezone (an IANA name e.g. "Europe/Paris"), the local timezone
+// is used when it's empty
+func NewCronScheduleWithTimezone(entry, timezone string) *CronSchedule {
+	c := NewCronSchedule(entry)
+	c.timezone = timezone
+	return c
+}
+
 // Entry returns the cron schedule entry
 func (c *CronSchedule) Entry() string {
 	return c.entry
 }
 
+// Timezone returns the timezone the cron entry is evaluated in, empty for the
+// local timezone
+func (c *CronSchedule) Timezone() string {
+	return c.timezone
+}
+
 // GetState returns state of CronSchedule
 func (c *CronSchedule) GetState() ScheduleState {
 	return c.state
@@ -65,9 +83,28 @@ func (c *CronSchedule) Validate() error {
 	if err != nil {
 		return err
 	}
+	if _, err := c.loadLocation(); err != nil {
+		return err
+	}
 	return nil
 }
 
+// loadLocation returns the location of the timezone of the schedule
+func (c *CronSchedule) loadLocation() (*time.Location, error) {
+	if c.location != nil {
+		return c.location, nil
+	}
+	if c.timezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(c.timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone `%s`: %v", c.timezone, err)
+	}
+	c.location = loc
+	return loc, nil
+}
+
 // Wait waits as long as specified in cron entry
 func (c *CronSchedule) Wait(last time.Time) Response {
 	var err error
@@ -79,7 +116,10 @@ func (c *CronSchedule) Wait(last time.Time) Response {
 	}
 	// schedule not enabled, either due to first run or invalid cron entry
 	if !c.enabled {
-		err = c.schedule.AddFunc(c.entry, func() {})
+		_, err = c.loadLocation()
+		if err == nil {
+			err = c.schedule.AddFunc(c.entry, func() {})
+		}
 		if err != nil {
 			c.state = Error
 		} else {
@@ -90,9 +130,10 @@ func (c *CronSchedule) Wait(last time.Time) Response {
 	var misses uint
 	if c.enabled {
 		s := c.schedule.Entries()[0].Schedule
+		loc, _ := c.loadLocation()
 
-		// calculate misses
-		for next := last; next.Before(now); {
+		// calculate misses, the entry is evaluated in the timezone of the schedule
+		for next := last.In(loc); next.Before(now); {
 			next = s.Next(next)
 			if next.After(now) {
 				break
@@ -101,7 +142,7 @@ func (c *CronSchedule) Wait(last time.Time) Response {
 		}
 
 		// wait
-		waitTime := s.Next(now)
+		waitTime := s.Next(now.In(loc))
 		time.Sleep(waitTime.Sub(now))
 	}
 
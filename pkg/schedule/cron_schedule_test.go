@@ -47,6 +47,20 @@ func TestCronSchedule(t *testing.T) {
 			e := c.Validate()
 			So(e, ShouldNotBeNil)
 		})
+		Convey("valid timezone", func() {
+			c := NewCronScheduleWithTimezone("0 0 9 * * *", "Europe/Paris")
+			So(c.Validate(), ShouldBeNil)
+			So(c.Timezone(), ShouldEqual, "Europe/Paris")
+		})
+		Convey("invalid timezone", func() {
+			c := NewCronScheduleWithTimezone("0 0 9 * * *", "Mars/Olympus_Mons")
+			e := c.Validate()
+			So(e, ShouldNotBeNil)
+			So(e.Error(), ShouldStartWith, "invalid timezone `Mars/Olympus_Mons`")
+			r := c.Wait(time.Now())
+			So(r.State(), ShouldEqual, Error)
+			So(r.Error(), ShouldNotBeNil)
+		})
 		Convey("wait on valid cron entry", func() {
 			i := "@every 1s"
 			c := NewCronSchedule(i)
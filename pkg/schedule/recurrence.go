@@ -0,0 +1,155 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidRecurrenceTime - Error message for the times of the day of a recurring window must be "15:04"
+	ErrInvalidRecurrenceTime = errors.New("Recurring window start and stop must be times of the day (e.g. 09:00)")
+	// ErrRecurrenceWithCount - Error message for the count of runs can't end a recurring window
+	ErrRecurrenceWithCount = errors.New("Recurring window cannot be combined with a count of runs")
+
+	weekdays = map[string][]time.Weekday{
+		"weekdays": {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+		"weekend":  {time.Saturday, time.Sunday},
+	}
+)
+
+func init() {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		weekdays[name] = []time.Weekday{d}
+		weekdays[name[:3]] = []time.Weekday{d}
+	}
+}
+
+// Recurrence is the window of a windowed schedule recurring every day, or on
+// some days of the week, between two times of the day.  A window whose stop
+// is before its start ends the next day (e.g. 22:00-06:00).
+//
+// The bounds of the windows are computed from the dates in the location of
+// the recurrence, not by adding 24 hours, so a window keeps its times of the
+// day across the DST transitions.  A bound falling in the hour skipped by a
+// transition is moved forward by that hour.
+type Recurrence struct {
+	// Start and Stop are the times of the day ("15:04") the window opens and closes at
+	Start string
+	Stop  string
+	// Days are the days of the week the window opens on, every day when empty
+	Days []string
+	// Timezone the times of the day are evaluated in, the local timezone when empty
+	Timezone string
+
+	// start and stop are the minutes since midnight
+	start, stop int
+	days        map[time.Weekday]bool
+	location    *time.Location
+}
+
+// NewRecurrence returns the recurrence of a window opening and closing at the
+// given times of the day ("15:04") on the given days of the week (e.g.
+// "monday", "mon", "weekdays" or "weekend"), every day when no days are given.
+// The times are evaluated in the given timezone (an IANA name e.g.
+// "Europe/Paris"), the local timezone when empty.
+func NewRecurrence(start, stop string, days []string, timezone string) (*Recurrence, error) {
+	r := &Recurrence{
+		Start:    start,
+		Stop:     stop,
+		Days:     days,
+		Timezone: timezone,
+		location: time.Local,
+	}
+	var err error
+	if r.start, err = parseTimeOfDay(start); err != nil {
+		return nil, err
+	}
+	if r.stop, err = parseTimeOfDay(stop); err != nil {
+		return nil, err
+	}
+	if len(days) > 0 {
+		r.days = map[time.Weekday]bool{}
+		for _, d := range days {
+			wd, ok := weekdays[strings.ToLower(d)]
+			if !ok {
+				return nil, fmt.Errorf("Unknown day of the week `%s` in recurring window", d)
+			}
+			for _, w := range wd {
+				r.days[w] = true
+			}
+		}
+	}
+	if timezone != "" {
+		if r.location, err = time.LoadLocation(timezone); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func parseTimeOfDay(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, ErrInvalidRecurrenceTime
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// window returns the bounds of the window starting on the day of t, ok is
+// false when the window doesn't recur on that day
+func (r *Recurrence) window(t time.Time, dayOffset int) (start, stop time.Time, ok bool) {
+	y, m, d := t.In(r.location).Date()
+	start = time.Date(y, m, d+dayOffset, r.start/60, r.start%60, 0, 0, r.location)
+	if r.days != nil && !r.days[start.Weekday()] {
+		return start, stop, false
+	}
+	stopDay := d + dayOffset
+	if r.stop <= r.start {
+		stopDay++
+	}
+	stop = time.Date(y, m, stopDay, r.stop/60, r.stop%60, 0, 0, r.location)
+	return start, stop, true
+}
+
+// Contains returns true when t is within a window
+func (r *Recurrence) Contains(t time.Time) bool {
+	// a window starting the day before may not be closed yet
+	for i := -1; i <= 0; i++ {
+		if start, stop, ok := r.window(t, i); ok && !t.Before(start) && t.Before(stop) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextStart returns the start of the first window opening after t
+func (r *Recurrence) NextStart(t time.Time) time.Time {
+	// the window opens on one of the days of the week at least
+	for i := 0; ; i++ {
+		if start, _, ok := r.window(t, i); ok && start.After(t) {
+			return start
+		}
+	}
+}
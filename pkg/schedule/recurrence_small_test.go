@@ -0,0 +1,104 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRecurrence(t *testing.T) {
+	Convey("Given a window recurring between 09:00 and 17:00 on weekdays", t, func() {
+		r, err := NewRecurrence("09:00", "17:00", []string{"weekdays"}, "UTC")
+		So(err, ShouldBeNil)
+		Convey("it contains the times of the day within the window", func() {
+			// 2017-03-08 is a Wednesday
+			So(r.Contains(time.Date(2017, 3, 8, 9, 0, 0, 0, time.UTC)), ShouldBeTrue)
+			So(r.Contains(time.Date(2017, 3, 8, 16, 59, 0, 0, time.UTC)), ShouldBeTrue)
+			So(r.Contains(time.Date(2017, 3, 8, 8, 59, 0, 0, time.UTC)), ShouldBeFalse)
+			So(r.Contains(time.Date(2017, 3, 8, 17, 0, 0, 0, time.UTC)), ShouldBeFalse)
+		})
+		Convey("it doesn't contain the weekend", func() {
+			So(r.Contains(time.Date(2017, 3, 11, 12, 0, 0, 0, time.UTC)), ShouldBeFalse)
+		})
+		Convey("the next window opens the same day before 09:00", func() {
+			next := r.NextStart(time.Date(2017, 3, 8, 7, 0, 0, 0, time.UTC))
+			So(next, ShouldResemble, time.Date(2017, 3, 8, 9, 0, 0, 0, time.UTC))
+		})
+		Convey("the next window opens on monday after the window of friday", func() {
+			next := r.NextStart(time.Date(2017, 3, 10, 18, 0, 0, 0, time.UTC))
+			So(next, ShouldResemble, time.Date(2017, 3, 13, 9, 0, 0, 0, time.UTC))
+		})
+	})
+	Convey("Given a window recurring overnight", t, func() {
+		r, err := NewRecurrence("22:00", "06:00", []string{"fri"}, "UTC")
+		So(err, ShouldBeNil)
+		Convey("it contains the morning after", func() {
+			// 2017-03-11 is a Saturday
+			So(r.Contains(time.Date(2017, 3, 11, 5, 0, 0, 0, time.UTC)), ShouldBeTrue)
+			So(r.Contains(time.Date(2017, 3, 11, 6, 0, 0, 0, time.UTC)), ShouldBeFalse)
+			So(r.Contains(time.Date(2017, 3, 10, 21, 0, 0, 0, time.UTC)), ShouldBeFalse)
+			So(r.Contains(time.Date(2017, 3, 10, 23, 0, 0, 0, time.UTC)), ShouldBeTrue)
+		})
+	})
+	Convey("Given a window recurring every day across the DST transitions", t, func() {
+		r, err := NewRecurrence("09:00", "17:00", nil, "America/New_York")
+		if err != nil {
+			// the timezone database isn't available
+			return
+		}
+		loc := r.location
+		Convey("the window keeps opening at 09:00 after the spring transition", func() {
+			// the clocks move forward on 2017-03-12
+			next := r.NextStart(time.Date(2017, 3, 11, 18, 0, 0, 0, loc))
+			So(next.Equal(time.Date(2017, 3, 12, 9, 0, 0, 0, loc)), ShouldBeTrue)
+			So(next.In(loc).Hour(), ShouldEqual, 9)
+			So(next.Sub(time.Date(2017, 3, 11, 9, 0, 0, 0, loc)), ShouldEqual, 23*time.Hour)
+		})
+		Convey("the window keeps opening at 09:00 after the fall transition", func() {
+			// the clocks move back on 2017-11-05
+			next := r.NextStart(time.Date(2017, 11, 4, 18, 0, 0, 0, loc))
+			So(next.In(loc).Hour(), ShouldEqual, 9)
+			So(next.Sub(time.Date(2017, 11, 4, 9, 0, 0, 0, loc)), ShouldEqual, 25*time.Hour)
+		})
+	})
+	Convey("Given invalid recurrences", t, func() {
+		Convey("an invalid time of the day is rejected", func() {
+			_, err := NewRecurrence("9am", "17:00", nil, "")
+			So(err, ShouldEqual, ErrInvalidRecurrenceTime)
+		})
+		Convey("an unknown day is rejected", func() {
+			_, err := NewRecurrence("09:00", "17:00", []string{"someday"}, "")
+			So(err, ShouldNotBeNil)
+		})
+		Convey("a count of runs is rejected", func() {
+			r, err := NewRecurrence("09:00", "17:00", nil, "")
+			So(err, ShouldBeNil)
+			count := uint(2)
+			w := NewWindowedSchedule(time.Second, nil, nil, count)
+			w.Recurrence = r
+			So(w.Validate(), ShouldEqual, ErrRecurrenceWithCount)
+		})
+	})
+}
@@ -31,6 +31,8 @@ var (
 	ErrInvalidStopTime = errors.New("Stop time is in the past")
 	// ErrStopBeforeStart - Error message for the stop time cannot occur before start time
 	ErrStopBeforeStart = errors.New("Stop time cannot occur before start time")
+	// ErrInvalidJitter - Error message for the jitter must be a percentage of the interval
+	ErrInvalidJitter = errors.New("Jitter must be between 0 and 100 percent")
 )
 
 // ScheduleState int type
@@ -55,6 +57,15 @@ type Schedule interface {
 	Wait(time.Time) Response
 }
 
+// Pausable is implemented by the schedules keeping their position while the
+// task they schedule is paused
+type Pausable interface {
+	// Pause is called when the task is paused
+	Pause()
+	// Resume is called when the task is resumed
+	Resume()
+}
+
 // Response interface defines the behavior of schedule response
 type Response interface {
 	// Contains any errors captured during a schedule.Wait()
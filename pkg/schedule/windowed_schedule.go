@@ -20,6 +20,8 @@ limitations under the License.
 package schedule
 
 import (
+	"hash/fnv"
+	"os"
 	"time"
 
 	log "github.com/Sirupsen/logrus"
@@ -27,16 +29,27 @@ import (
 
 var (
 	logger = log.WithField("_module", "schedule")
+	// hostname returns the name the jitter of the schedules is derived from
+	hostname = os.Hostname
 )
 
-// WindowedSchedule is a schedule that waits on an interval within a specific time window
+// WindowedSchedule is a schedule that waits on an interval within a specific time window.
+// The first run is delayed by at most `Jitter` percent of the interval, the delay is derived
+// from the hostname so the hosts running the same task don't collect at the same instant.
+// With a `Recurrence` the schedule only fires within the recurring windows.
 type WindowedSchedule struct {
 	Interval   time.Duration
 	StartTime  *time.Time
 	StopTime   *time.Time
 	Count      uint
+	Jitter     uint
+	Recurrence *Recurrence
 	state      ScheduleState
 	stopOnTime *time.Time
+	pausedAt   time.Time
+	// restored is true until the first wait after the stop of a window
+	// determined by the count of runs was restored
+	restored bool
 }
 
 // NewWindowedSchedule returns an instance of WindowedSchedule with given interval, start and stop timestamp
@@ -84,6 +97,44 @@ func (w *WindowedSchedule) setStopOnTime() {
 	w.stopOnTime = w.StopTime
 }
 
+// StopOnTime returns the end of the window determined by the count of runs,
+// nil when the window ends at its stop time or never ends
+func (w *WindowedSchedule) StopOnTime() *time.Time {
+	if w.StopTime != nil {
+		return nil
+	}
+	return w.stopOnTime
+}
+
+// RestoreStopOnTime sets the end of the window determined by the count of
+// runs, so the schedule restored from a persisted task keeps the count of runs
+// it had left
+func (w *WindowedSchedule) RestoreStopOnTime(stop time.Time) {
+	if w.StopTime != nil || w.Count == 0 {
+		return
+	}
+	w.stopOnTime = &stop
+	w.restored = true
+}
+
+// Pause records the time the task of the schedule is paused at
+func (w *WindowedSchedule) Pause() {
+	w.pausedAt = time.Now()
+}
+
+// Resume moves the end of a window determined by the count of runs by the
+// time the task was paused, keeping the count of runs left
+func (w *WindowedSchedule) Resume() {
+	if w.pausedAt.IsZero() {
+		return
+	}
+	if w.StopTime == nil && w.stopOnTime != nil {
+		stop := w.stopOnTime.Add(time.Since(w.pausedAt))
+		w.stopOnTime = &stop
+	}
+	w.pausedAt = time.Time{}
+}
+
 // GetState returns ScheduleState of WindowedSchedule
 func (w *WindowedSchedule) GetState() ScheduleState {
 	return w.state
@@ -105,6 +156,12 @@ func (w *WindowedSchedule) Validate() error {
 	if w.Interval <= 0 {
 		return ErrInvalidInterval
 	}
+	if w.Jitter > 100 {
+		return ErrInvalidJitter
+	}
+	if w.Recurrence != nil && w.Count != 0 {
+		return ErrRecurrenceWithCount
+	}
 
 	// the schedule passed validation, set as active
 	w.state = Active
@@ -121,7 +178,12 @@ func (w *WindowedSchedule) Wait(last time.Time) Response {
 	if (last == time.Time{}) {
 		// the first waiting in cycles, so
 		// set the `stopOnTime` determining the right-window boundary
-		w.setStopOnTime()
+		// unless it was restored
+		if w.restored {
+			w.restored = false
+		} else {
+			w.setStopOnTime()
+		}
 	}
 
 	// Do we even have a specific start time?
@@ -137,6 +199,22 @@ func (w *WindowedSchedule) Wait(last time.Time) Response {
 		}
 	}
 
+	// Delay the first run by the jitter of the host
+	if (last == time.Time{}) && w.Jitter > 0 {
+		name, _ := hostname()
+		wait := jitterOffset(name, w.Interval, w.Jitter)
+		logger.WithFields(log.Fields{
+			"_block":         "windowed-wait",
+			"sleep-duration": wait,
+		}).Debug("Waiting for the jitter of the host")
+		time.Sleep(wait)
+	}
+
+	// Wait for the recurring window to open
+	if w.Recurrence != nil && !w.Recurrence.Contains(time.Now()) {
+		return w.waitRecurrence()
+	}
+
 	// Do we even have a stop time?
 	if w.stopOnTime != nil {
 		if time.Now().Before(*w.stopOnTime) {
@@ -166,6 +244,10 @@ func (w *WindowedSchedule) Wait(last time.Time) Response {
 		m, _ = waitOnInterval(last, w.Interval)
 
 	}
+	// the recurring window may have closed while waiting on the interval
+	if w.Recurrence != nil && w.state == Active && !w.Recurrence.Contains(time.Now()) {
+		return w.waitRecurrence()
+	}
 	return &WindowedScheduleResponse{
 		state:    w.GetState(),
 		missed:   m,
@@ -173,6 +255,52 @@ func (w *WindowedSchedule) Wait(last time.Time) Response {
 	}
 }
 
+// waitRecurrence waits for the next recurring window to open, the intervals
+// outside of the windows aren't missed.  The schedule ends when the window
+// would open after its stop time.
+func (w *WindowedSchedule) waitRecurrence() Response {
+	next := w.Recurrence.NextStart(time.Now())
+	if w.stopOnTime != nil && !next.Before(*w.stopOnTime) {
+		logger.WithFields(log.Fields{
+			"_block": "windowed-wait",
+		}).Debug("schedule has ended")
+		w.state = Ended
+	} else {
+		wait := next.Sub(time.Now())
+		logger.WithFields(log.Fields{
+			"_block":         "windowed-wait",
+			"sleep-duration": wait,
+		}).Debug("Waiting for the recurring window to open")
+		time.Sleep(wait)
+	}
+	return &WindowedScheduleResponse{
+		state:    w.GetState(),
+		lastTime: time.Now(),
+	}
+}
+
+// NextWindowStart returns the time the next recurring window opens at, nil
+// without a recurrence
+func (w *WindowedSchedule) NextWindowStart() *time.Time {
+	if w.Recurrence == nil {
+		return nil
+	}
+	next := w.Recurrence.NextStart(time.Now())
+	return &next
+}
+
+// jitterOffset returns the delay of the first run on the host, between zero
+// and the given percentage of the interval
+func jitterOffset(host string, interval time.Duration, percent uint) time.Duration {
+	span := int64(interval) / 100 * int64(percent)
+	if span <= 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	h.Write([]byte(host))
+	return time.Duration(h.Sum64() % uint64(span))
+}
+
 // WindowedScheduleResponse is the response from SimpleSchedule
 // conforming to ScheduleResponse interface
 type WindowedScheduleResponse struct {
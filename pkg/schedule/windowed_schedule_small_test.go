@@ -91,3 +91,103 @@ func TestWindowedScheduleValidation(t *testing.T) {
 		So(afterMS, ShouldBeLessThan, shouldWait+10)
 	})
 }
+
+func TestWindowedScheduleJitter(t *testing.T) {
+	Convey("invalid jitter", t, func() {
+		w := NewWindowedSchedule(time.Second, nil, nil, 0)
+		w.Jitter = 101
+		So(w.Validate(), ShouldEqual, ErrInvalidJitter)
+	})
+	Convey("jitter offset", t, func() {
+		Convey("is within the percentage of the interval", func() {
+			for _, host := range []string{"host-0", "host-1", "host-2", "host-3"} {
+				offset := jitterOffset(host, time.Minute, 10)
+				So(offset, ShouldBeGreaterThanOrEqualTo, 0)
+				So(offset, ShouldBeLessThan, 6*time.Second)
+			}
+		})
+		Convey("is deterministic per host", func() {
+			So(jitterOffset("host-0", time.Minute, 10), ShouldEqual, jitterOffset("host-0", time.Minute, 10))
+			So(jitterOffset("host-0", time.Minute, 10), ShouldNotEqual, jitterOffset("host-1", time.Minute, 10))
+		})
+		Convey("is zero without jitter", func() {
+			So(jitterOffset("host-0", time.Minute, 0), ShouldEqual, 0)
+		})
+	})
+	Convey("test Wait() delays the first run", t, func() {
+		name := hostname
+		hostname = func() (string, error) { return "host-0", nil }
+		defer func() { hostname = name }()
+
+		s := NewWindowedSchedule(time.Millisecond*100, nil, nil, 0)
+		s.Jitter = 50
+		So(s.Validate(), ShouldBeNil)
+		wait := jitterOffset("host-0", s.Interval, s.Jitter)
+
+		before := time.Now()
+		r := s.Wait(time.Time{})
+		after := time.Since(before)
+
+		So(r.State(), ShouldEqual, Active)
+		So(after, ShouldBeGreaterThanOrEqualTo, wait)
+		So(after, ShouldBeLessThan, wait+10*time.Millisecond)
+	})
+}
+
+func TestWindowedSchedulePause(t *testing.T) {
+	Convey("count based window", t, func() {
+		s := NewWindowedSchedule(time.Millisecond*10, nil, nil, 5)
+		So(s.Validate(), ShouldBeNil)
+		// the window stop is set on the first wait
+		s.setStopOnTime()
+		stop := *s.stopOnTime
+
+		s.Pause()
+		time.Sleep(time.Millisecond * 20)
+		s.Resume()
+		Convey("is moved by the time paused", func() {
+			So(s.stopOnTime.Sub(stop), ShouldBeGreaterThanOrEqualTo, time.Millisecond*20)
+		})
+	})
+	Convey("window with a stop time", t, func() {
+		stopTime := time.Now().Add(time.Second)
+		s := NewWindowedSchedule(time.Millisecond*10, nil, &stopTime, 0)
+		So(s.Validate(), ShouldBeNil)
+		s.setStopOnTime()
+
+		s.Pause()
+		time.Sleep(time.Millisecond * 20)
+		s.Resume()
+		Convey("is not moved", func() {
+			So(*s.stopOnTime, ShouldResemble, stopTime)
+		})
+	})
+}
+
+func TestWindowedScheduleRestoreStopOnTime(t *testing.T) {
+	Convey("count based window", t, func() {
+		s := NewWindowedSchedule(time.Millisecond*10, nil, nil, 5)
+		So(s.Validate(), ShouldBeNil)
+		stop := time.Now().Add(time.Millisecond * 20)
+		s.RestoreStopOnTime(stop)
+		Convey("keeps the restored stop on the first wait", func() {
+			s.Wait(time.Time{})
+			So(*s.StopOnTime(), ShouldResemble, stop)
+			Convey("and computes it again on the next start", func() {
+				s.Wait(time.Time{})
+				So(s.StopOnTime().After(stop), ShouldBeTrue)
+			})
+		})
+	})
+	Convey("window with a stop time", t, func() {
+		stopTime := time.Now().Add(time.Second)
+		s := NewWindowedSchedule(time.Millisecond*10, nil, &stopTime, 0)
+		So(s.Validate(), ShouldBeNil)
+		s.RestoreStopOnTime(time.Now())
+		Convey("is not restored", func() {
+			So(s.StopOnTime(), ShouldBeNil)
+			s.Wait(time.Time{})
+			So(*s.stopOnTime, ShouldResemble, stopTime)
+		})
+	})
+}
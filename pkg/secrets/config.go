@@ -0,0 +1,95 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secrets
+
+import (
+	"time"
+
+	"github.com/vrischmann/jsonutil"
+)
+
+// default configuration values
+const (
+	defaultEnvPrefix    = "SNAP_SECRET_"
+	defaultFileDir      = "/etc/snap/secrets"
+	defaultVaultAddr    = ""
+	defaultVaultToken   = ""
+	defaultVaultTimeout = 10 * time.Second
+	defaultCacheTTL     = time.Minute
+)
+
+// holds the configuration passed in through the SNAP config file
+type Config struct {
+	// EnvPrefix is the prefix of the environment variables the env provider
+	// reads, the other variables of snapteld can't be referenced
+	EnvPrefix string `json:"env_prefix"yaml:"env_prefix"`
+	// FileDir is the directory the file provider reads the secrets from
+	FileDir string `json:"file_dir"yaml:"file_dir"`
+	// VaultAddr is the address of the HashiCorp Vault server, the vault
+	// provider is disabled when it's empty
+	VaultAddr string `json:"vault_addr"yaml:"vault_addr"`
+	// VaultToken is the token of snapteld in Vault, the environment variable
+	// VAULT_TOKEN is read when it's empty
+	VaultToken   string            `json:"vault_token"yaml:"vault_token"`
+	VaultTimeout jsonutil.Duration `json:"vault_timeout"yaml:"vault_timeout"`
+	// CacheTTL is how long a resolved secret is kept before it's read again
+	CacheTTL jsonutil.Duration `json:"cache_ttl"yaml:"cache_ttl"`
+}
+
+const (
+	CONFIG_CONSTRAINTS = `
+			"secrets": {
+				"type": ["object", "null"],
+				"properties" : {
+					"env_prefix": {
+						"type": "string"
+					},
+					"file_dir": {
+						"type": "string"
+					},
+					"vault_addr": {
+						"type": "string"
+					},
+					"vault_token": {
+						"type": "string"
+					},
+					"vault_timeout": {
+						"type": "string"
+					},
+					"cache_ttl": {
+						"type": "string"
+					}
+				},
+				"additionalProperties": false
+			}
+	`
+)
+
+// GetDefaultConfig gets the default snapteld configuration
+func GetDefaultConfig() *Config {
+	return &Config{
+		EnvPrefix:    defaultEnvPrefix,
+		FileDir:      defaultFileDir,
+		VaultAddr:    defaultVaultAddr,
+		VaultToken:   defaultVaultToken,
+		VaultTimeout: jsonutil.Duration{defaultVaultTimeout},
+		CacheTTL:     jsonutil.Duration{defaultCacheTTL},
+	}
+}
@@ -0,0 +1,129 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secrets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// envProvider reads the secrets from the environment variables of snapteld
+// with its prefix: secret://env/SNAP_SECRET_INFLUX_PASSWORD
+type envProvider struct {
+	prefix string
+}
+
+func (p *envProvider) Secret(name string) (string, error) {
+	if !strings.HasPrefix(name, p.prefix) {
+		return "", fmt.Errorf("environment variable '%s' doesn't have the prefix '%s'", name, p.prefix)
+	}
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable '%s' isn't set", name)
+	}
+	return value, nil
+}
+
+// fileProvider reads the secrets from the files of its directory, without
+// their trailing newlines: secret://file/influx/password
+type fileProvider struct {
+	dir string
+}
+
+func (p *fileProvider) Secret(path string) (string, error) {
+	// the path is cleaned as an absolute path so that it can't escape the
+	// directory
+	b, err := ioutil.ReadFile(filepath.Join(p.dir, filepath.Clean("/"+path)))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
+
+// vaultProvider reads the secrets of the KV secrets engine of HashiCorp Vault,
+// the path is followed by the key of the secret:
+// secret://vault/secret/data/influx#password
+type vaultProvider struct {
+	addr   string
+	token  string
+	client *http.Client
+}
+
+func newVaultProvider(cfg *Config) *vaultProvider {
+	token := cfg.VaultToken
+	if token == "" {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+	return &vaultProvider{
+		addr:   strings.TrimSuffix(cfg.VaultAddr, "/"),
+		token:  token,
+		client: &http.Client{Timeout: cfg.VaultTimeout.Duration},
+	}
+}
+
+func (p *vaultProvider) Secret(ref string) (string, error) {
+	i := strings.LastIndex(ref, "#")
+	if i < 0 || i == len(ref)-1 {
+		return "", errors.New("the key of the secret is missing, e.g. secret://vault/secret/data/influx#password")
+	}
+	path, key := ref[:i], ref[i+1:]
+	req, err := http.NewRequest("GET", p.addr+"/v1/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault answered %s", resp.Status)
+	}
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	data := body.Data
+	// the version 2 of the KV secrets engine nests the secrets in data with
+	// their metadata
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = nested
+		}
+	}
+	v, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("secret '%s' has no key '%s'", path, key)
+	}
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	b, _ := json.Marshal(v)
+	return string(b), nil
+}
@@ -0,0 +1,132 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package secrets resolves the references to secrets given in the config of
+// the plugins and of the tasks, e.g. secret://vault/secret/data/influx#password,
+// so that the secrets are only known by snapteld and the plugins
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// Scheme is the prefix of the references to secrets, it's followed by the
+// name of the provider and the path of the secret in the provider
+const Scheme = "secret://"
+
+var (
+	// ErrUnknownProvider is returned for the references to a provider which
+	// isn't configured
+	ErrUnknownProvider = errors.New("unknown secrets provider")
+	// ErrInvalidReference is returned for the references without a path
+	ErrInvalidReference = errors.New("invalid secret reference")
+
+	secretsLogger = log.WithField("_module", "secrets")
+)
+
+// Provider reads the secrets of a store
+type Provider interface {
+	// Secret returns the secret at the path
+	Secret(path string) (string, error)
+}
+
+type cachedSecret struct {
+	value   string
+	expires time.Time
+}
+
+// Resolver resolves the references to secrets with their providers, the
+// secrets are cached for the TTL of the configuration
+type Resolver struct {
+	providers map[string]Provider
+	ttl       time.Duration
+
+	// mutex guards the cache
+	mutex sync.Mutex
+	cache map[string]cachedSecret
+}
+
+// New returns a resolver with the providers env and file, and vault when the
+// address of a Vault server is configured
+func New(cfg *Config) *Resolver {
+	r := &Resolver{
+		providers: map[string]Provider{
+			"env":  &envProvider{prefix: cfg.EnvPrefix},
+			"file": &fileProvider{dir: cfg.FileDir},
+		},
+		ttl:   cfg.CacheTTL.Duration,
+		cache: map[string]cachedSecret{},
+	}
+	if cfg.VaultAddr != "" {
+		r.providers["vault"] = newVaultProvider(cfg)
+	}
+	return r
+}
+
+// IsReference returns true when the value is a reference to a secret
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, Scheme)
+}
+
+// Resolve returns the secret referenced by the value, the values which aren't
+// references are returned as they are
+func (r *Resolver) Resolve(value string) (string, error) {
+	if !IsReference(value) {
+		return value, nil
+	}
+	r.mutex.Lock()
+	cached, ok := r.cache[value]
+	r.mutex.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.value, nil
+	}
+
+	ref := strings.TrimPrefix(value, Scheme)
+	i := strings.Index(ref, "/")
+	if i <= 0 || i == len(ref)-1 {
+		return "", fmt.Errorf("%v: '%s'", ErrInvalidReference, value)
+	}
+	name, path := ref[:i], ref[i+1:]
+	p, ok := r.providers[name]
+	if !ok {
+		return "", fmt.Errorf("%v: '%s'", ErrUnknownProvider, name)
+	}
+	secret, err := p.Secret(path)
+	if err != nil {
+		// the references are logged, never the secrets
+		secretsLogger.WithFields(log.Fields{
+			"_block":   "resolve",
+			"provider": name,
+			"path":     path,
+		}).Error(err)
+		return "", fmt.Errorf("%v (while resolving '%s')", err, value)
+	}
+	if r.ttl > 0 {
+		r.mutex.Lock()
+		r.cache[value] = cachedSecret{value: secret, expires: time.Now().Add(r.ttl)}
+		r.mutex.Unlock()
+	}
+	return secret, nil
+}
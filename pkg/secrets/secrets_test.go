@@ -0,0 +1,149 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secrets
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/vrischmann/jsonutil"
+)
+
+type countingProvider struct {
+	calls int
+}
+
+func (p *countingProvider) Secret(path string) (string, error) {
+	p.calls++
+	if path == "missing" {
+		return "", errors.New("no such secret")
+	}
+	return "s3cr3t-" + path, nil
+}
+
+func TestResolver(t *testing.T) {
+	Convey("Given a resolver", t, func() {
+		dir, err := ioutil.TempDir("", "secrets")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		So(ioutil.WriteFile(filepath.Join(dir, "influx"), []byte("p@ssw0rd\n"), 0600), ShouldBeNil)
+		os.Setenv("SNAP_SECRET_TEST_PASSWORD", "hunter2")
+		defer os.Unsetenv("SNAP_SECRET_TEST_PASSWORD")
+
+		cfg := GetDefaultConfig()
+		cfg.FileDir = dir
+		r := New(cfg)
+		counting := &countingProvider{}
+		r.providers["test"] = counting
+
+		Convey("the values which aren't references are returned as they are", func() {
+			v, err := r.Resolve("password")
+			So(err, ShouldBeNil)
+			So(v, ShouldEqual, "password")
+		})
+		Convey("the references are resolved by their providers", func() {
+			v, err := r.Resolve("secret://env/SNAP_SECRET_TEST_PASSWORD")
+			So(err, ShouldBeNil)
+			So(v, ShouldEqual, "hunter2")
+			v, err = r.Resolve("secret://file/influx")
+			So(err, ShouldBeNil)
+			So(v, ShouldEqual, "p@ssw0rd")
+		})
+		Convey("the secrets are cached", func() {
+			for i := 0; i < 3; i++ {
+				v, err := r.Resolve("secret://test/db")
+				So(err, ShouldBeNil)
+				So(v, ShouldEqual, "s3cr3t-db")
+			}
+			So(counting.calls, ShouldEqual, 1)
+			r.ttl = 0
+			r.cache = map[string]cachedSecret{}
+			r.Resolve("secret://test/db")
+			r.Resolve("secret://test/db")
+			So(counting.calls, ShouldEqual, 3)
+		})
+		Convey("the invalid references are rejected", func() {
+			_, err := r.Resolve("secret://test/missing")
+			So(err, ShouldNotBeNil)
+			_, err = r.Resolve("secret://vault/secret/data/influx#password")
+			So(err, ShouldNotBeNil)
+			_, err = r.Resolve("secret://env/")
+			So(err, ShouldNotBeNil)
+			_, err = r.Resolve("secret://env/HOME")
+			So(err, ShouldNotBeNil)
+			_, err = r.Resolve("secret://file/../../etc/passwd")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestVaultProvider(t *testing.T) {
+	Convey("Given a Vault server", t, func() {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Vault-Token") != "t0k3n" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			switch r.URL.Path {
+			case "/v1/secret/data/influx":
+				w.Write([]byte(`{"data": {"data": {"password": "v2-p@ss", "port": 8086}, "metadata": {"version": 1}}}`))
+			case "/v1/kv/influx":
+				w.Write([]byte(`{"data": {"password": "v1-p@ss"}}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer srv.Close()
+		cfg := GetDefaultConfig()
+		cfg.VaultAddr = srv.URL
+		cfg.VaultToken = "t0k3n"
+		cfg.VaultTimeout = jsonutil.Duration{time.Second}
+		r := New(cfg)
+
+		Convey("the secrets of both versions of the KV engine are resolved", func() {
+			v, err := r.Resolve("secret://vault/secret/data/influx#password")
+			So(err, ShouldBeNil)
+			So(v, ShouldEqual, "v2-p@ss")
+			v, err = r.Resolve("secret://vault/secret/data/influx#port")
+			So(err, ShouldBeNil)
+			So(v, ShouldEqual, "8086")
+			v, err = r.Resolve("secret://vault/kv/influx#password")
+			So(err, ShouldBeNil)
+			So(v, ShouldEqual, "v1-p@ss")
+		})
+		Convey("the missing secrets and keys are errors", func() {
+			_, err := r.Resolve("secret://vault/kv/influx")
+			So(err, ShouldNotBeNil)
+			_, err = r.Resolve("secret://vault/kv/influx#user")
+			So(err, ShouldNotBeNil)
+			_, err = r.Resolve("secret://vault/kv/postgres#password")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,108 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"time"
+
+	"github.com/vrischmann/jsonutil"
+)
+
+// default configuration values
+const (
+	defaultEnable        = false
+	defaultEndpoint      = "http://localhost:9411/api/v1/spans"
+	defaultServiceName   = "snapteld"
+	defaultSampleRate    = 1.0
+	defaultBatchSize     = 100
+	defaultFlushInterval = 5 * time.Second
+	defaultQueueSize     = 1000
+	defaultTimeout       = 10 * time.Second
+)
+
+// holds the configuration passed in through the SNAP config file
+type Config struct {
+	Enable bool `json:"enable"yaml:"enable"`
+	// Endpoint is the URL of the Zipkin HTTP collector the spans are POSTed
+	// to, served by Zipkin and Jaeger
+	Endpoint    string `json:"endpoint"yaml:"endpoint"`
+	ServiceName string `json:"service_name"yaml:"service_name"`
+	// SampleRate is the fraction of the runs of the tasks traced, from 0 to 1
+	SampleRate float64 `json:"sample_rate"yaml:"sample_rate"`
+	// BatchSize, FlushInterval, QueueSize and Timeout configure the HTTP
+	// collector of zipkin-go-opentracing
+	BatchSize     int               `json:"batch_size"yaml:"batch_size"`
+	FlushInterval jsonutil.Duration `json:"flush_interval"yaml:"flush_interval"`
+	QueueSize     int               `json:"queue_size"yaml:"queue_size"`
+	Timeout       jsonutil.Duration `json:"timeout"yaml:"timeout"`
+}
+
+const (
+	CONFIG_CONSTRAINTS = `
+			"tracing": {
+				"type": ["object", "null"],
+				"properties" : {
+					"enable": {
+						"type": "boolean"
+					},
+					"endpoint": {
+						"type": "string"
+					},
+					"service_name": {
+						"type": "string"
+					},
+					"sample_rate": {
+						"type": "number",
+						"minimum": 0,
+						"maximum": 1
+					},
+					"batch_size": {
+						"type": "integer",
+						"minimum": 1
+					},
+					"flush_interval": {
+						"type": "string"
+					},
+					"queue_size": {
+						"type": "integer",
+						"minimum": 1
+					},
+					"timeout": {
+						"type": "string"
+					}
+				},
+				"additionalProperties": false
+			}
+	`
+)
+
+// GetDefaultConfig gets the default snapteld configuration
+func GetDefaultConfig() *Config {
+	return &Config{
+		Enable:        defaultEnable,
+		Endpoint:      defaultEndpoint,
+		ServiceName:   defaultServiceName,
+		SampleRate:    defaultSampleRate,
+		BatchSize:     defaultBatchSize,
+		FlushInterval: jsonutil.Duration{defaultFlushInterval},
+		QueueSize:     defaultQueueSize,
+		Timeout:       jsonutil.Duration{defaultTimeout},
+	}
+}
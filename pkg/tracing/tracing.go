@@ -0,0 +1,155 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tracing traces the runs of the workflows of the tasks with the
+// OpenTracing API, the spans of the runs and of their steps are reported to
+// Zipkin by the HTTP collector of zipkin-go-opentracing
+package tracing
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	otlog "github.com/opentracing/opentracing-go/log"
+	zipkin "github.com/openzipkin/zipkin-go-opentracing"
+)
+
+var tracingLogger = log.WithField("_module", "tracing")
+
+// Tracer starts the spans with an OpenTracing tracer, which reports them to
+// Zipkin once it's started.  A nil tracer traces nothing, its spans are nil.
+type Tracer struct {
+	cfg       *Config
+	tracer    opentracing.Tracer
+	collector zipkin.Collector
+}
+
+// New returns a tracer reporting the spans to the endpoint of the config, the
+// spans started before the tracer is started aren't reported
+func New(cfg *Config) *Tracer {
+	return &Tracer{
+		cfg:    cfg,
+		tracer: opentracing.NoopTracer{},
+	}
+}
+
+func (t *Tracer) Name() string {
+	return "tracing"
+}
+
+// Start starts reporting the finished spans
+func (t *Tracer) Start() error {
+	collector, err := zipkin.NewHTTPCollector(
+		t.cfg.Endpoint,
+		zipkin.HTTPBatchSize(t.cfg.BatchSize),
+		zipkin.HTTPBatchInterval(t.cfg.FlushInterval.Duration),
+		zipkin.HTTPMaxBacklog(t.cfg.QueueSize),
+		zipkin.HTTPTimeout(t.cfg.Timeout.Duration),
+		zipkin.HTTPLogger(zipkin.LoggerFunc(func(keyvals ...interface{}) error {
+			tracingLogger.WithField("_block", "collect").Warn(keyvals...)
+			return nil
+		})),
+	)
+	if err != nil {
+		return err
+	}
+	// the traces are sampled at their root
+	tracer, err := zipkin.NewTracer(
+		zipkin.NewRecorder(collector, false, "", t.cfg.ServiceName),
+		zipkin.WithSampler(zipkin.NewBoundarySampler(t.cfg.SampleRate, time.Now().UnixNano())),
+		zipkin.TraceID128Bit(true),
+	)
+	if err != nil {
+		collector.Close()
+		return err
+	}
+	t.collector = collector
+	t.tracer = tracer
+	tracingLogger.WithFields(log.Fields{
+		"_block":      "start",
+		"endpoint":    t.cfg.Endpoint,
+		"sample-rate": t.cfg.SampleRate,
+	}).Info("tracing started")
+	return nil
+}
+
+// Stop reports the spans already finished and stops reporting them
+func (t *Tracer) Stop() {
+	if t.collector != nil {
+		t.collector.Close()
+	}
+	tracingLogger.WithFields(log.Fields{
+		"_block": "stop",
+	}).Info("tracing stopped")
+}
+
+// StartSpan starts the root span of a new trace, nil when the tracer is nil
+func (t *Tracer) StartSpan(name string) *Span {
+	if t == nil {
+		return nil
+	}
+	return &Span{span: t.tracer.StartSpan(name)}
+}
+
+// Span is a timed operation of a trace, the run of a workflow or one of its
+// steps.  The methods of a nil span do nothing.
+type Span struct {
+	span   opentracing.Span
+	finish sync.Once
+}
+
+// StartChild starts a child span of the span, nil when the span is nil
+func (s *Span) StartChild(name string) *Span {
+	if s == nil {
+		return nil
+	}
+	return &Span{span: s.span.Tracer().StartSpan(name, opentracing.ChildOf(s.span.Context()))}
+}
+
+// SetTag tags the span with a key and a value
+func (s *Span) SetTag(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.span.SetTag(key, value)
+}
+
+// SetError tags the span as failed and logs the errors of its operation
+func (s *Span) SetError(errs []error) {
+	if s == nil || len(errs) == 0 {
+		return
+	}
+	ext.Error.Set(s.span, true)
+	s.span.SetTag("errors", len(errs))
+	for _, err := range errs {
+		s.span.LogFields(otlog.Error(err))
+	}
+}
+
+// Finish ends the span, the span is reported once whatever the number of
+// times it's finished
+func (s *Span) Finish() {
+	if s == nil {
+		return
+	}
+	s.finish.Do(s.span.Finish)
+}
@@ -0,0 +1,94 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/opentracing/opentracing-go/mocktracer"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTracer(t *testing.T) {
+	Convey("Given a tracer", t, func() {
+		mt := mocktracer.New()
+		tr := &Tracer{cfg: GetDefaultConfig(), tracer: mt}
+
+		Convey("the spans of a trace are the children of its root", func() {
+			root := tr.StartSpan("workflow")
+			root.SetTag("task_id", "1")
+			child := root.StartChild("publish")
+			child.SetError([]error{errors.New("publisher unavailable")})
+			child.Finish()
+			// a span is only reported once
+			child.Finish()
+			root.Finish()
+			spans := mt.FinishedSpans()
+			So(spans, ShouldHaveLength, 2)
+			So(spans[0].OperationName, ShouldEqual, "publish")
+			So(spans[0].SpanContext.TraceID, ShouldEqual, spans[1].SpanContext.TraceID)
+			So(spans[0].ParentID, ShouldEqual, spans[1].SpanContext.SpanID)
+			So(spans[0].Tag("error"), ShouldEqual, true)
+			So(spans[0].Tag("errors"), ShouldEqual, 1)
+			So(spans[0].Logs(), ShouldHaveLength, 1)
+			So(spans[1].OperationName, ShouldEqual, "workflow")
+			So(spans[1].ParentID, ShouldEqual, 0)
+			So(spans[1].Tags(), ShouldResemble, map[string]interface{}{"task_id": "1"})
+		})
+	})
+	Convey("A started tracer reports the spans to its endpoint", t, func() {
+		received := make(chan string, 10)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received <- r.Method
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer srv.Close()
+		cfg := GetDefaultConfig()
+		cfg.Endpoint = srv.URL
+		tr := New(cfg)
+		So(tr.Start(), ShouldBeNil)
+		tr.StartSpan("workflow").Finish()
+		// the spans reported are sent when the tracer stops
+		tr.Stop()
+		var method string
+		select {
+		case method = <-received:
+		case <-time.After(5 * time.Second):
+		}
+		So(method, ShouldEqual, "POST")
+	})
+	Convey("A nil tracer traces nothing", t, func() {
+		var tr *Tracer
+		s := tr.StartSpan("workflow")
+		So(s, ShouldBeNil)
+		So(func() {
+			So(s.StartChild("collect"), ShouldBeNil)
+			s.SetTag("task_id", "1")
+			s.SetError([]error{errors.New("failed")})
+			s.Finish()
+		}, ShouldNotPanic)
+	})
+}
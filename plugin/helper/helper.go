@@ -22,7 +22,7 @@ package helper
 import (
 	"fmt"
 	"os"
-	"path"
+	"path/filepath"
 	"runtime"
 )
 
@@ -51,11 +51,15 @@ func PluginPath() string {
 		arch = runtime.GOARCH
 	}
 
-	fpath := path.Join(BuildPath, runtime.GOOS, arch, "plugins")
+	fpath := filepath.Join(BuildPath, runtime.GOOS, arch, "plugins")
 	return fpath
 }
 
 func PluginFilePath(name string) string {
-	fpath := path.Join(PluginPath(), name)
+	// plugins are built as executables ending with .exe on Windows
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	fpath := filepath.Join(PluginPath(), name)
 	return fpath
 }
@@ -0,0 +1,261 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package plugintest drives plugins the way snapteld does, so that plugin
+// authors can test their collectors, processors and publishers without
+// running snapteld.  A Harness plays the part of control: it runs the binary
+// of a plugin with the argument snapteld passes to it, reads its handshake and
+// calls it over the RPC protocol it negotiated, or calls an in-process
+// implementation directly with a canned handshake.
+package plugintest
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/client"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/cdata"
+	"github.com/intelsdi-x/snap/core/ctypes"
+)
+
+// DefaultTimeout is the time given to the plugins to answer the handshake and
+// the calls
+const DefaultTimeout = 10 * time.Second
+
+var (
+	// ErrNotCollector is returned when collecting the metrics of a plugin which
+	// isn't a collector
+	ErrNotCollector = errors.New("plugin is not a collector")
+	// ErrNotProcessor is returned when processing metrics with a plugin which
+	// isn't a processor
+	ErrNotProcessor = errors.New("plugin is not a processor")
+	// ErrNotPublisher is returned when publishing metrics with a plugin which
+	// isn't a publisher
+	ErrNotPublisher = errors.New("plugin is not a publisher")
+)
+
+// Harness is a fake control endpoint driving a single instance of a plugin
+type Harness struct {
+	// Response is the handshake of the plugin
+	Response plugin.Response
+	client   client.PluginClient
+	ePlugin  *plugin.ExecutablePlugin
+}
+
+// Handshake returns the canned handshake of a plugin with the meta, the
+// response a plugin sends to snapteld once it started successfully
+func Handshake(meta plugin.PluginMeta) plugin.Response {
+	meta.Unsecure = true
+	return plugin.Response{
+		Meta:  meta,
+		Type:  meta.Type,
+		State: plugin.PluginSuccess,
+	}
+}
+
+// Run runs the plugin binary at the path with the argument snapteld passes to
+// the plugins, waits for its handshake and connects to it.  The plugin is
+// killed by Stop.
+func Run(path string, timeout time.Duration) (*Harness, error) {
+	ep, err := plugin.NewExecutablePlugin(plugin.NewArg(int(log.GetLevel()), false), path)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := ep.Run(timeout)
+	if err != nil {
+		return nil, err
+	}
+	if resp.State != plugin.PluginSuccess {
+		ep.Kill()
+		return nil, fmt.Errorf("plugin could not start: %s", resp.ErrorMessage)
+	}
+	h, err := Connect(resp, timeout)
+	if err != nil {
+		ep.Kill()
+		return nil, err
+	}
+	h.ePlugin = ep
+	return h, nil
+}
+
+// Connect connects to a running plugin with its handshake
+func Connect(resp plugin.Response, timeout time.Duration) (*Harness, error) {
+	var (
+		c   client.PluginClient
+		err error
+	)
+	switch {
+	case resp.Type == plugin.CollectorPluginType && resp.Meta.RPCType == plugin.NativeRPC:
+		c, err = client.NewCollectorNativeClient(resp.ListenAddress, timeout, resp.PublicKey, !resp.Meta.Unsecure, plugin.MetricsContentType(resp.Meta.AcceptedContentTypes))
+	case resp.Type == plugin.CollectorPluginType && resp.Meta.RPCType == plugin.GRPC:
+		c, err = client.NewCollectorGrpcClient(resp.ListenAddress, timeout, client.GRPCSecurity{}, resp.Meta.Capabilities)
+	case resp.Type == plugin.ProcessorPluginType && resp.Meta.RPCType == plugin.NativeRPC:
+		c, err = client.NewProcessorNativeClient(resp.ListenAddress, timeout, resp.PublicKey, !resp.Meta.Unsecure, plugin.MetricsContentType(resp.Meta.AcceptedContentTypes))
+	case resp.Type == plugin.ProcessorPluginType && resp.Meta.RPCType == plugin.GRPC:
+		c, err = client.NewProcessorGrpcClient(resp.ListenAddress, timeout, client.GRPCSecurity{}, resp.Meta.Capabilities)
+	case resp.Type == plugin.PublisherPluginType && resp.Meta.RPCType == plugin.NativeRPC:
+		c, err = client.NewPublisherNativeClient(resp.ListenAddress, timeout, resp.PublicKey, !resp.Meta.Unsecure, plugin.MetricsContentType(resp.Meta.AcceptedContentTypes))
+	case resp.Type == plugin.PublisherPluginType && resp.Meta.RPCType == plugin.GRPC:
+		c, err = client.NewPublisherGrpcClient(resp.ListenAddress, timeout, client.GRPCSecurity{}, resp.Meta.Capabilities)
+	default:
+		return nil, fmt.Errorf("can't drive a %s plugin over RPC type %d", resp.Type.String(), resp.Meta.RPCType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while creating client connection: %v", err)
+	}
+	if resp.Meta.Unsecure {
+		err = c.Ping()
+	} else {
+		err = c.SetKey()
+	}
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	return &Harness{Response: resp, client: c}, nil
+}
+
+// InProcess drives an in-process implementation of a plugin, a
+// plugin.CollectorPlugin, plugin.ProcessorPlugin or plugin.PublisherPlugin
+// given by the type of the meta
+func InProcess(meta plugin.PluginMeta, p plugin.Plugin) (*Harness, error) {
+	var c client.PluginClient
+	switch meta.Type {
+	case plugin.CollectorPluginType:
+		cp, ok := p.(plugin.CollectorPlugin)
+		if !ok {
+			return nil, ErrNotCollector
+		}
+		c = client.NewCollectorInProcessClient(cp, plugin.MetricsContentType(meta.AcceptedContentTypes))
+	case plugin.ProcessorPluginType:
+		pp, ok := p.(plugin.ProcessorPlugin)
+		if !ok {
+			return nil, ErrNotProcessor
+		}
+		c = client.NewProcessorInProcessClient(pp, plugin.MetricsContentType(meta.AcceptedContentTypes))
+	case plugin.PublisherPluginType:
+		pp, ok := p.(plugin.PublisherPlugin)
+		if !ok {
+			return nil, ErrNotPublisher
+		}
+		c = client.NewPublisherInProcessClient(pp, plugin.MetricsContentType(meta.AcceptedContentTypes))
+	default:
+		return nil, fmt.Errorf("can't drive a %s plugin in-process", meta.Type.String())
+	}
+	meta.RPCType = plugin.InProcessRPC
+	return &Harness{Response: Handshake(meta), client: c}, nil
+}
+
+// Meta returns the meta of the plugin given in its handshake
+func (h *Harness) Meta() plugin.PluginMeta {
+	return h.Response.Meta
+}
+
+// Ping checks the plugin is alive
+func (h *Harness) Ping() error {
+	return h.client.Ping()
+}
+
+// GetConfigPolicy returns the config policy of the plugin
+func (h *Harness) GetConfigPolicy() (*cpolicy.ConfigPolicy, error) {
+	return h.client.GetConfigPolicy()
+}
+
+// GetMetricTypes returns the metrics the collector advertises with the config
+func (h *Harness) GetMetricTypes(config map[string]ctypes.ConfigValue) ([]core.Metric, error) {
+	c, ok := h.client.(client.PluginCollectorClient)
+	if !ok {
+		return nil, ErrNotCollector
+	}
+	return c.GetMetricTypes(plugin.ConfigType{ConfigDataNode: cdata.FromTable(config)})
+}
+
+// CollectMetrics collects the requested metrics
+func (h *Harness) CollectMetrics(mts []core.Metric) ([]core.Metric, error) {
+	c, ok := h.client.(client.PluginCollectorClient)
+	if !ok {
+		return nil, ErrNotCollector
+	}
+	return c.CollectMetrics(mts)
+}
+
+// Process processes the metrics with the config
+func (h *Harness) Process(mts []core.Metric, config map[string]ctypes.ConfigValue) ([]core.Metric, error) {
+	c, ok := h.client.(client.PluginProcessorClient)
+	if !ok {
+		return nil, ErrNotProcessor
+	}
+	return c.Process(mts, config)
+}
+
+// Publish publishes the metrics with the config
+func (h *Harness) Publish(mts []core.Metric, config map[string]ctypes.ConfigValue) error {
+	c, ok := h.client.(client.PluginPublisherClient)
+	if !ok {
+		return ErrNotPublisher
+	}
+	return c.Publish(mts, config)
+}
+
+// Stop asks the plugin to stop and kills the binary run by the harness
+func (h *Harness) Stop() error {
+	err := h.client.Kill("test harness stopped")
+	h.client.Close()
+	if h.ePlugin != nil {
+		if kerr := h.ePlugin.Kill(); kerr != nil && err == nil {
+			err = kerr
+		}
+	}
+	return err
+}
+
+// Metric returns a metric requested with the namespace
+func Metric(ns ...string) plugin.MetricType {
+	return plugin.MetricType{
+		Namespace_: core.NewNamespace(ns...),
+		Timestamp_: time.Now(),
+	}
+}
+
+// Config returns the config of a call with the values, which are strings,
+// ints, float64s or bools
+func Config(values map[string]interface{}) (map[string]ctypes.ConfigValue, error) {
+	config := map[string]ctypes.ConfigValue{}
+	for k, v := range values {
+		switch t := v.(type) {
+		case string:
+			config[k] = ctypes.ConfigValueStr{Value: t}
+		case int:
+			config[k] = ctypes.ConfigValueInt{Value: t}
+		case float64:
+			config[k] = ctypes.ConfigValueFloat{Value: t}
+		case bool:
+			config[k] = ctypes.ConfigValueBool{Value: t}
+		default:
+			return nil, fmt.Errorf("unsupported config value %v of %s", v, k)
+		}
+	}
+	return config, nil
+}
@@ -0,0 +1,147 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugintest
+
+import (
+	"testing"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/ctypes"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type testCollector struct{}
+
+func (testCollector) GetConfigPolicy() (*cpolicy.ConfigPolicy, error) {
+	return cpolicy.New(), nil
+}
+
+func (testCollector) GetMetricTypes(cfg plugin.ConfigType) ([]plugin.MetricType, error) {
+	name := "foo"
+	if v, ok := cfg.Table()["name"]; ok {
+		name = v.(ctypes.ConfigValueStr).Value
+	}
+	return []plugin.MetricType{Metric("test", name)}, nil
+}
+
+func (testCollector) CollectMetrics(mts []plugin.MetricType) ([]plugin.MetricType, error) {
+	for i := range mts {
+		mts[i].Data_ = 1
+	}
+	return mts, nil
+}
+
+type testProcessor struct{}
+
+func (testProcessor) GetConfigPolicy() (*cpolicy.ConfigPolicy, error) {
+	return cpolicy.New(), nil
+}
+
+func (testProcessor) Process(contentType string, content []byte, config map[string]ctypes.ConfigValue) (string, []byte, error) {
+	return contentType, content, nil
+}
+
+type testPublisher struct {
+	published *bool
+}
+
+func (testPublisher) GetConfigPolicy() (*cpolicy.ConfigPolicy, error) {
+	return cpolicy.New(), nil
+}
+
+func (p testPublisher) Publish(contentType string, content []byte, config map[string]ctypes.ConfigValue) error {
+	*p.published = true
+	return nil
+}
+
+func TestInProcessHarness(t *testing.T) {
+	Convey("Given a harness driving an in-process collector", t, func() {
+		h, err := InProcess(plugin.PluginMeta{Name: "test", Version: 1, Type: plugin.CollectorPluginType}, testCollector{})
+		So(err, ShouldBeNil)
+		So(h.Meta().Name, ShouldEqual, "test")
+		So(h.Meta().RPCType, ShouldEqual, plugin.InProcessRPC)
+		So(h.Ping(), ShouldBeNil)
+		Convey("the metric types are advertised with the config", func() {
+			cfg, err := Config(map[string]interface{}{"name": "bar"})
+			So(err, ShouldBeNil)
+			mts, err := h.GetMetricTypes(cfg)
+			So(err, ShouldBeNil)
+			So(mts, ShouldHaveLength, 1)
+			So(mts[0].Namespace().String(), ShouldEqual, "/test/bar")
+		})
+		Convey("the metrics are collected", func() {
+			mts, err := h.CollectMetrics([]core.Metric{Metric("test", "foo")})
+			So(err, ShouldBeNil)
+			So(mts, ShouldHaveLength, 1)
+			So(mts[0].Data(), ShouldEqual, 1)
+		})
+		Convey("it doesn't process nor publish metrics", func() {
+			_, err := h.Process([]core.Metric{}, nil)
+			So(err, ShouldEqual, ErrNotProcessor)
+			So(h.Publish([]core.Metric{}, nil), ShouldEqual, ErrNotPublisher)
+		})
+		So(h.Stop(), ShouldBeNil)
+	})
+	Convey("Given a harness driving an in-process processor", t, func() {
+		h, err := InProcess(plugin.PluginMeta{Name: "test", Version: 1, Type: plugin.ProcessorPluginType}, testProcessor{})
+		So(err, ShouldBeNil)
+		Convey("the metrics are processed", func() {
+			mts, err := h.Process([]core.Metric{Metric("test", "foo")}, nil)
+			So(err, ShouldBeNil)
+			So(mts, ShouldHaveLength, 1)
+			So(mts[0].Namespace().String(), ShouldEqual, "/test/foo")
+		})
+	})
+	Convey("Given a harness driving an in-process publisher", t, func() {
+		published := false
+		h, err := InProcess(plugin.PluginMeta{Name: "test", Version: 1, Type: plugin.PublisherPluginType}, testPublisher{&published})
+		So(err, ShouldBeNil)
+		Convey("the metrics are published", func() {
+			So(h.Publish([]core.Metric{Metric("test", "foo")}, nil), ShouldBeNil)
+			So(published, ShouldBeTrue)
+		})
+	})
+	Convey("Given an implementation which doesn't match the type of the plugin", t, func() {
+		_, err := InProcess(plugin.PluginMeta{Name: "test", Version: 1, Type: plugin.PublisherPluginType}, testCollector{})
+		So(err, ShouldEqual, ErrNotPublisher)
+	})
+}
+
+func TestConfig(t *testing.T) {
+	Convey("Given config values", t, func() {
+		Convey("the supported values are converted", func() {
+			cfg, err := Config(map[string]interface{}{"s": "a", "i": 1, "f": 1.5, "b": true})
+			So(err, ShouldBeNil)
+			So(cfg["s"], ShouldResemble, ctypes.ConfigValueStr{Value: "a"})
+			So(cfg["i"], ShouldResemble, ctypes.ConfigValueInt{Value: 1})
+			So(cfg["f"], ShouldResemble, ctypes.ConfigValueFloat{Value: 1.5})
+			So(cfg["b"], ShouldResemble, ctypes.ConfigValueBool{Value: true})
+		})
+		Convey("the unsupported values are reported", func() {
+			_, err := Config(map[string]interface{}{"l": []string{}})
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
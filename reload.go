@@ -0,0 +1,419 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/intelsdi-x/snap/mgmt/rest"
+	"github.com/intelsdi-x/snap/pkg/cfgfile"
+	"github.com/intelsdi-x/snap/pkg/logging"
+	"github.com/intelsdi-x/snap/scheduler"
+)
+
+// redacted replaces the values of the secret settings
+const redacted = "********"
+
+// reloadableSettings are the settings applied while snapteld is running, by
+// section of the configuration, the settings of the section "" are at its
+// top level. The other settings are applied by restarting snapteld
+var reloadableSettings = map[string][]string{
+	"":        {"log_level"},
+	"logging": {"modules"},
+	"control": {"plugin_trust_level", "keyring_paths"},
+	"scheduler": {
+		"work_manager_pool_size",
+		"work_manager_collect_pool_size",
+		"work_manager_process_pool_size",
+		"work_manager_publish_pool_size",
+		"work_manager_autoscale",
+		"work_manager_max_pool_size",
+		"work_manager_max_queue_latency",
+	},
+	"restapi": {
+		"rest_auth",
+		"rest_auth_password",
+		"rest_auth_tokens",
+		"rest_auth_token_file",
+		"rest_auth_roles",
+	},
+}
+
+type managesTrust interface {
+	SetPluginTrustLevel(trust int)
+	SetKeyringFiles(keyrings []string)
+}
+
+type managesWorkerPools interface {
+	ResizeWorkerPools(cfg *scheduler.Config)
+}
+
+// reloader applies the changes of the configuration of snapteld without
+// restarting it, the config file is read again on SIGHUP and the changes are
+// given to PUT /v1/config
+type reloader struct {
+	mutex sync.Mutex
+	// settings is the configuration snapteld runs with, in the format of the
+	// config file
+	settings map[string]interface{}
+	// sources are the layers the settings come from, by their names
+	sources map[string]string
+	// password is the password of the REST API, it's kept when the settings
+	// don't set it since it may have been prompted for
+	password string
+	file     string
+	flags    configFlags
+
+	control   managesTrust
+	scheduler managesWorkerPools
+	logging   *logging.Logging
+	// rest is nil when the REST API is disabled
+	rest *rest.Server
+}
+
+func newReloader(settings []byte, sources map[string]string, password, file string, flags configFlags) (*reloader, error) {
+	r := &reloader{sources: sources, password: password, file: file, flags: flags}
+	if err := json.Unmarshal(settings, &r.settings); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ReloadFile applies the changes of the layers of the configuration, the config
+// file and the key-value store. It returns false when settings which can't be
+// applied while snapteld is running were changed, none is applied then
+func (r *reloader) ReloadFile() (bool, []string, error) {
+	cfg, sources, err := layeredConfig(r.file, r.flags)
+	if err != nil {
+		return true, nil, err
+	}
+	jb, _ := json.Marshal(cfg)
+	if err := validateSettings(jb); err != nil {
+		return true, nil, err
+	}
+	settings := map[string]interface{}{}
+	if err := json.Unmarshal(jb, &settings); err != nil {
+		return true, nil, err
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if !reflect.DeepEqual(fixedSettings(r.settings), fixedSettings(settings)) {
+		return false, nil, nil
+	}
+	changed, err := r.apply(cfg, settings)
+	if err == nil {
+		r.sources = sources
+	}
+	return true, changed, err
+}
+
+// ReloadConfig applies the settings given in the format of the config file,
+// the settings not given are left unchanged
+func (r *reloader) ReloadConfig(b []byte) ([]string, error) {
+	patch := map[string]interface{}{}
+	if err := json.Unmarshal(b, &patch); err != nil {
+		return nil, err
+	}
+	if err := checkReloadable(patch); err != nil {
+		return nil, err
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	settings := mergeSettings(r.settings, patch)
+	jb, _ := json.Marshal(settings)
+	if err := validateSettings(jb); err != nil {
+		return nil, err
+	}
+	cfg := getDefaultConfig()
+	if err := json.Unmarshal(jb, cfg); err != nil {
+		return nil, err
+	}
+	changed, err := r.apply(cfg, settings)
+	if err != nil {
+		return nil, err
+	}
+	sources := make(map[string]string, len(r.sources))
+	for name, layer := range r.sources {
+		sources[name] = layer
+	}
+	for _, name := range changed {
+		sources[name] = layerRuntime
+	}
+	r.sources = sources
+	return changed, nil
+}
+
+// EffectiveConfig returns the settings snapteld runs with, in the format of
+// the config file, and the layers they come from by their names, e.g.
+// restapi.port. The passwords, secrets and tokens are redacted
+func (r *reloader) EffectiveConfig() (map[string]interface{}, map[string]string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	settings, _ := redactSettings(r.settings).(map[string]interface{})
+	sources := make(map[string]string, len(r.sources))
+	for name, layer := range r.sources {
+		sources[name] = layer
+	}
+	return settings, sources
+}
+
+// apply applies the reloadable settings changed, the caller must hold the
+// mutex
+func (r *reloader) apply(cfg *Config, settings map[string]interface{}) ([]string, error) {
+	changed := reloadableChanges(r.settings, settings)
+	sections := map[string]bool{}
+	for _, name := range changed {
+		if i := strings.Index(name, "."); i >= 0 {
+			sections[name[:i]] = true
+		} else {
+			sections[""] = true
+		}
+	}
+
+	// the settings which can be invalid are checked first so that none is
+	// applied when one of them is
+	var keyrings []string
+	if sections["control"] && cfg.Control.PluginTrust > 0 {
+		var err error
+		if keyrings, err = keyringFiles(cfg.Control.KeyringPaths); err != nil {
+			return nil, err
+		}
+	}
+	for module, level := range cfg.Logging.Modules {
+		if _, err := log.ParseLevel(level); err != nil {
+			return nil, fmt.Errorf("%v (while parsing the log level of '%s')", err, module)
+		}
+	}
+	if sections["restapi"] && r.rest != nil {
+		restCfg := *cfg.RestAPI
+		if restCfg.RestAuthPassword == "" {
+			restCfg.RestAuthPassword = r.password
+		}
+		if err := r.rest.ReloadAuth(&restCfg); err != nil {
+			return nil, err
+		}
+		r.password = restCfg.RestAuthPassword
+	}
+
+	if sections["control"] {
+		r.control.SetKeyringFiles(keyrings)
+		r.control.SetPluginTrustLevel(cfg.Control.PluginTrust)
+		log.Info("setting plugin trust level to: ", t[cfg.Control.PluginTrust])
+	}
+	if sections[""] {
+		r.logging.SetLevel("", getLevel(cfg.LogLevel).String())
+		log.Info("setting log level to: ", l[cfg.LogLevel])
+	}
+	if sections["logging"] {
+		// the levels of the modules removed from the settings are reset,
+		// they may have been reset through the REST API already
+		previous, _ := r.settings["logging"].(map[string]interface{})
+		modules, _ := previous["modules"].(map[string]interface{})
+		for module := range modules {
+			if _, ok := cfg.Logging.Modules[module]; !ok {
+				r.logging.ResetLevel(module)
+			}
+		}
+		for module, level := range cfg.Logging.Modules {
+			r.logging.SetLevel(module, level)
+		}
+	}
+	if sections["scheduler"] {
+		r.scheduler.ResizeWorkerPools(cfg.Scheduler)
+	}
+	r.settings = settings
+	return changed, nil
+}
+
+// checkReloadable returns an error when settings which can't be applied while
+// snapteld is running are given
+func checkReloadable(settings map[string]interface{}) error {
+	for name, v := range settings {
+		if isReloadable("", name) {
+			continue
+		}
+		keys, ok := reloadableSettings[name]
+		if !ok || name == "" {
+			return fmt.Errorf("'%s' can't be changed while snapteld is running", name)
+		}
+		section, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("'%s' must be an object of the settings %s", name, strings.Join(keys, ", "))
+		}
+		for key := range section {
+			if !isReloadable(name, key) {
+				return fmt.Errorf("'%s.%s' can't be changed while snapteld is running", name, key)
+			}
+		}
+	}
+	return nil
+}
+
+func isReloadable(section, key string) bool {
+	for _, k := range reloadableSettings[section] {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeSettings returns the settings with the ones of the patch, the settings
+// of a section not in the patch are kept
+func mergeSettings(settings, patch map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(settings))
+	for name, v := range settings {
+		merged[name] = v
+	}
+	for name, v := range patch {
+		section, ok := v.(map[string]interface{})
+		if !ok {
+			merged[name] = v
+			continue
+		}
+		previous, _ := merged[name].(map[string]interface{})
+		m := make(map[string]interface{}, len(previous)+len(section))
+		for key, sv := range previous {
+			m[key] = sv
+		}
+		for key, sv := range section {
+			m[key] = sv
+		}
+		merged[name] = m
+	}
+	return merged
+}
+
+// fixedSettings returns the settings without the reloadable ones
+func fixedSettings(settings map[string]interface{}) map[string]interface{} {
+	fixed := map[string]interface{}{}
+	for name, v := range settings {
+		if isReloadable("", name) {
+			continue
+		}
+		section, ok := v.(map[string]interface{})
+		if !ok {
+			fixed[name] = v
+			continue
+		}
+		m := map[string]interface{}{}
+		for key, sv := range section {
+			if !isReloadable(name, key) {
+				m[key] = sv
+			}
+		}
+		fixed[name] = m
+	}
+	return fixed
+}
+
+// reloadableChanges returns the names of the reloadable settings changed,
+// e.g. log_level or scheduler.work_manager_pool_size
+func reloadableChanges(previous, settings map[string]interface{}) []string {
+	value := func(settings map[string]interface{}, section, key string) interface{} {
+		if section == "" {
+			return settings[key]
+		}
+		s, _ := settings[section].(map[string]interface{})
+		return s[key]
+	}
+	changed := []string{}
+	for section, keys := range reloadableSettings {
+		for _, key := range keys {
+			if reflect.DeepEqual(value(previous, section, key), value(settings, section, key)) {
+				continue
+			}
+			if section == "" {
+				changed = append(changed, key)
+			} else {
+				changed = append(changed, section+"."+key)
+			}
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// validateSettings validates the settings against the constraints of the
+// configuration
+func validateSettings(settings []byte) error {
+	serrs := cfgfile.ValidateSchema(CONFIG_CONSTRAINTS, string(settings))
+	if serrs == nil {
+		return nil
+	}
+	msgs := make([]string, len(serrs))
+	for i, serr := range serrs {
+		log.WithFields(serr.Fields()).Error(serr.Error())
+		msgs[i] = serr.Error()
+	}
+	return errors.New(strings.Join(msgs, "; "))
+}
+
+// redactSettings returns a copy of the settings where the values of the
+// passwords, secrets and tokens are redacted
+func redactSettings(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, sv := range v {
+			m[key] = redactSettings(sv)
+			if isSecret(key) && redactable(sv) {
+				m[key] = redacted
+			}
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, sv := range v {
+			l[i] = redactSettings(sv)
+		}
+		return l
+	}
+	return v
+}
+
+// isSecret returns true for the names of the settings holding passwords,
+// secrets or tokens, e.g. rest_auth_password, rest_auth_tokens or vault_token
+func isSecret(key string) bool {
+	key = strings.ToLower(key)
+	return strings.Contains(key, "password") || strings.Contains(key, "secret") || strings.HasSuffix(key, "token") || strings.HasSuffix(key, "tokens")
+}
+
+// redactable returns true for the values of the secrets which are set, the
+// sections named after secrets, e.g. secrets, are redacted by setting
+func redactable(v interface{}) bool {
+	switch v := v.(type) {
+	case nil, map[string]interface{}:
+		return false
+	case string:
+		return v != ""
+	case []interface{}:
+		return len(v) > 0
+	}
+	return true
+}
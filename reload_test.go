@@ -0,0 +1,153 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	log "github.com/Sirupsen/logrus"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/intelsdi-x/snap/pkg/logging"
+	"github.com/intelsdi-x/snap/scheduler"
+)
+
+type mockTrust struct {
+	trust    int
+	keyrings []string
+}
+
+func (m *mockTrust) SetPluginTrustLevel(trust int)     { m.trust = trust }
+func (m *mockTrust) SetKeyringFiles(keyrings []string) { m.keyrings = keyrings }
+
+type mockWorkerPools struct {
+	cfg *scheduler.Config
+}
+
+func (m *mockWorkerPools) ResizeWorkerPools(cfg *scheduler.Config) { m.cfg = cfg }
+
+func TestReloader(t *testing.T) {
+	newTestReloader := func() (*reloader, *mockTrust, *mockWorkerPools, *logging.Logging) {
+		jb, _ := json.Marshal(getDefaultConfig())
+		rl, err := newReloader(jb, map[string]string{"log_level": layerDefault}, "", "", configFlags{ctx: mockFlags{}})
+		So(err, ShouldBeNil)
+		lg, err := logging.New(&logging.Config{}, log.WarnLevel)
+		So(err, ShouldBeNil)
+		trust, pools := &mockTrust{trust: -1}, &mockWorkerPools{}
+		rl.control, rl.scheduler, rl.logging = trust, pools, lg
+		return rl, trust, pools, lg
+	}
+
+	Convey("Reloading the configuration", t, func() {
+		Convey("applies the settings changed", func() {
+			rl, trust, pools, lg := newTestReloader()
+			changed, err := rl.ReloadConfig([]byte(`{
+				"log_level": 1,
+				"logging": {"modules": {"control": "error"}},
+				"scheduler": {"work_manager_pool_size": 8}
+			}`))
+			So(err, ShouldBeNil)
+			So(changed, ShouldResemble, []string{"log_level", "logging.modules", "scheduler.work_manager_pool_size"})
+			So(lg.Level(), ShouldEqual, "debug")
+			So(lg.ModuleLevels(), ShouldResemble, map[string]string{"control": "error"})
+			So(pools.cfg.WorkManagerPoolSize, ShouldEqual, 8)
+			So(trust.trust, ShouldEqual, -1)
+			_, sources := rl.EffectiveConfig()
+			So(sources["log_level"], ShouldEqual, layerRuntime)
+			So(sources["scheduler.work_manager_pool_size"], ShouldEqual, layerRuntime)
+
+			Convey("and only those", func() {
+				pools.cfg = nil
+				changed, err := rl.ReloadConfig([]byte(`{"log_level": 1, "logging": {"modules": {}}}`))
+				So(err, ShouldBeNil)
+				So(changed, ShouldResemble, []string{"logging.modules"})
+				So(lg.ModuleLevels(), ShouldBeEmpty)
+				So(pools.cfg, ShouldBeNil)
+			})
+		})
+		Convey("disables the plugin trust", func() {
+			rl, trust, _, _ := newTestReloader()
+			changed, err := rl.ReloadConfig([]byte(`{"control": {"plugin_trust_level": 0}}`))
+			So(err, ShouldBeNil)
+			So(changed, ShouldResemble, []string{"control.plugin_trust_level"})
+			So(trust.trust, ShouldEqual, 0)
+		})
+		Convey("rejects the settings which can't be applied while snapteld is running", func() {
+			rl, _, _, _ := newTestReloader()
+			_, err := rl.ReloadConfig([]byte(`{"restapi": {"port": 8282}}`))
+			So(err, ShouldNotBeNil)
+			_, err = rl.ReloadConfig([]byte(`{"gomaxprocs": 2}`))
+			So(err, ShouldNotBeNil)
+		})
+		Convey("rejects the invalid settings without applying any", func() {
+			rl, _, pools, lg := newTestReloader()
+			_, err := rl.ReloadConfig([]byte(`{"log_level": 9, "scheduler": {"work_manager_pool_size": 8}}`))
+			So(err, ShouldNotBeNil)
+			_, err = rl.ReloadConfig([]byte(`{"control": {"plugin_trust_level": 1, "keyring_paths": "/no/keyrings/here"}}`))
+			So(err, ShouldNotBeNil)
+			So(lg.Level(), ShouldEqual, "warning")
+			So(pools.cfg, ShouldBeNil)
+		})
+	})
+
+	Convey("The effective settings", t, func() {
+		jb := []byte(`{
+			"log_level": 2,
+			"restapi": {"rest_auth_password": "changeme", "rest_auth_tokens": [], "rest_auth_token_file": "/etc/snap/tokens"},
+			"notify": {"webhooks": [{"url": "http://127.0.0.1:9000", "secret": "s3cr3t"}]},
+			"secrets": {"file_dir": "/etc/snap/secrets", "vault_token": "t0k3n"}
+		}`)
+		rl, err := newReloader(jb, map[string]string{"log_level": layerFile}, "", "", configFlags{ctx: mockFlags{}})
+		So(err, ShouldBeNil)
+		settings, sources := rl.EffectiveConfig()
+		So(sources, ShouldResemble, map[string]string{"log_level": layerFile})
+		Convey("redact the passwords, secrets and tokens", func() {
+			So(settings["log_level"], ShouldEqual, 2.0)
+			So(settings["restapi"], ShouldResemble, map[string]interface{}{
+				"rest_auth_password":   redacted,
+				"rest_auth_tokens":     []interface{}{},
+				"rest_auth_token_file": "/etc/snap/tokens",
+			})
+			So(settings["notify"], ShouldResemble, map[string]interface{}{
+				"webhooks": []interface{}{map[string]interface{}{"url": "http://127.0.0.1:9000", "secret": redacted}},
+			})
+			So(settings["secrets"], ShouldResemble, map[string]interface{}{
+				"file_dir":    "/etc/snap/secrets",
+				"vault_token": redacted,
+			})
+			So(rl.settings["restapi"].(map[string]interface{})["rest_auth_password"], ShouldEqual, "changeme")
+		})
+	})
+
+	Convey("The settings which can't be reloaded", t, func() {
+		settings := map[string]interface{}{
+			"log_level": 1.0,
+			"restapi":   map[string]interface{}{"port": 8181.0, "rest_auth": false},
+		}
+		fixed := fixedSettings(settings)
+		So(fixed, ShouldResemble, map[string]interface{}{"restapi": map[string]interface{}{"port": 8181.0}})
+		merged := mergeSettings(settings, map[string]interface{}{"restapi": map[string]interface{}{"rest_auth": true}})
+		So(fixedSettings(merged), ShouldResemble, fixed)
+		So(reloadableChanges(settings, merged), ShouldResemble, []string{"restapi.rest_auth"})
+	})
+}
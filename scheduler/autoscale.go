@@ -0,0 +1,169 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/intelsdi-x/snap/core"
+)
+
+var (
+	// poolSampleInterval is the interval the latency of the queues is
+	// sampled at, and the worker pools are resized at when autoscaled
+	poolSampleInterval = time.Second
+	// poolIdleSamples is the number of consecutive samples a worker pool has
+	// to be idle for before it's shrunk
+	poolIdleSamples = 10
+
+	poolJobTypes = []jobType{collectJobType, processJobType, publishJobType}
+	poolNames    = map[jobType]string{
+		collectJobType: "collect",
+		processJobType: "process",
+		publishJobType: "publish",
+	}
+)
+
+// poolScale holds the autoscaling state of a worker pool
+type poolScale struct {
+	// min is the initial size of the pool, it's never shrunk below it
+	min uint
+	// latency is the average time the jobs waited for a worker during the
+	// last sample
+	latency time.Duration
+	// idle is the number of consecutive samples the pool was idle for
+	idle int
+}
+
+// AutoscaleOption makes the work manager grow the worker pools, up to the
+// given size, while the jobs wait longer than the given latency for a worker,
+// and shrink them back to their initial size once idle.  It returns the
+// previous autoscale option state.
+func AutoscaleOption(enabled bool, maxSize uint, maxLatency time.Duration) workManagerOption {
+	return func(w *workManager) workManagerOption {
+		previous := AutoscaleOption(w.autoscale, w.maxWkrSize, w.maxQLatency)
+		w.autoscale = enabled
+		w.maxWkrSize = maxSize
+		w.maxQLatency = maxLatency
+		return previous
+	}
+}
+
+// resize sets the sizes of the worker pools and their autoscaling while the
+// jobs are running, the workers removed from a shrunk pool finish their
+// current job first
+func (w *workManager) resize(sizes map[jobType]uint, autoscale bool, maxSize uint, maxLatency time.Duration) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.autoscale = autoscale
+	w.maxWkrSize = maxSize
+	w.maxQLatency = maxLatency
+	for t, min := range sizes {
+		_, _, size, _ := w.pool(t)
+		sc := w.scales[t]
+		sc.min, sc.idle = min, 0
+		max := min
+		if autoscale && maxSize > min {
+			max = maxSize
+		}
+		for *size < min {
+			w.addWorker(t)
+		}
+		for *size > max {
+			w.removeWorker(t)
+		}
+		schedulerLogger.WithFields(log.Fields{
+			"_block":    "resize",
+			"pool":      poolNames[t],
+			"workers":   *size,
+			"autoscale": autoscale,
+		}).Info("worker pool resized")
+	}
+}
+
+// sample takes the latency of the queues and resizes the worker pools when
+// they're autoscaled
+func (w *workManager) sample() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	for _, t := range poolJobTypes {
+		q, _, size, _ := w.pool(t)
+		sc := w.scales[t]
+		sc.latency = q.takeLatency()
+		if !w.autoscale {
+			continue
+		}
+		depth := uint(q.depth())
+		max := w.maxWkrSize
+		if max < sc.min {
+			max = sc.min
+		}
+		switch {
+		case (sc.latency > w.maxQLatency || depth > *size) && *size < max:
+			sc.idle = 0
+			w.addWorker(t)
+		case depth == 0 && sc.latency <= w.maxQLatency/2:
+			sc.idle++
+			if sc.idle < poolIdleSamples || *size <= sc.min {
+				continue
+			}
+			sc.idle = 0
+			w.removeWorker(t)
+		default:
+			sc.idle = 0
+			continue
+		}
+		schedulerLogger.WithFields(log.Fields{
+			"_block":        "autoscale",
+			"pool":          poolNames[t],
+			"workers":       *size,
+			"queue-depth":   depth,
+			"queue-latency": sc.latency.String(),
+		}).Debug("worker pool resized")
+	}
+}
+
+// stats returns the state of the worker pools
+func (w *workManager) stats() []core.WorkerPoolStats {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	stats := make([]core.WorkerPoolStats, 0, len(poolJobTypes))
+	for _, t := range poolJobTypes {
+		q, _, size, _ := w.pool(t)
+		sc := w.scales[t]
+		ps := core.WorkerPoolStats{
+			Pool:         poolNames[t],
+			Workers:      *size,
+			MinWorkers:   sc.min,
+			MaxWorkers:   sc.min,
+			Autoscale:    w.autoscale,
+			QueueDepth:   uint(q.depth()),
+			QueueLength:  q.limit,
+			QueueLatency: sc.latency,
+		}
+		if w.autoscale && w.maxWkrSize > sc.min {
+			ps.MaxWorkers = w.maxWkrSize
+		}
+		stats = append(stats, ps)
+	}
+	return stats
+}
@@ -0,0 +1,144 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/scheduler/wmap"
+)
+
+var (
+	// ErrInvalidBatch - The error message for when the batch of a workflow step has neither a max size nor a max age
+	ErrInvalidBatch = errors.New("Batch of a workflow step needs a max size or a max age.")
+	// ErrInvalidBatchSize - The error message for when the max size of the batch of a workflow step is negative
+	ErrInvalidBatchSize = errors.New("Batch max size of a workflow step can't be negative.")
+	// ErrInvalidBatchAge - The error message for when the max age of the batch of a workflow step isn't a positive duration
+	ErrInvalidBatchAge = errors.New("Batch max age of a workflow step must be a positive duration.")
+)
+
+// publishBatch coalesces the metrics of the runs of a task into a single
+// publish job, published once it holds maxSize metrics or its oldest metrics
+// were added maxAge ago.  The age of a batch is checked on the runs of the
+// task.
+type publishBatch struct {
+	sync.Mutex
+	maxSize int
+	maxAge  time.Duration
+
+	metrics []core.Metric
+	started time.Time
+
+	published uint
+	failed    uint
+	total     uint
+}
+
+// newPublishBatch returns the batch of a workflow step, nil when the metrics
+// of every run are published
+func newPublishBatch(b *wmap.BatchWorkflowMapNode) (*publishBatch, error) {
+	if b == nil {
+		return nil, nil
+	}
+	if b.MaxSize < 0 {
+		return nil, ErrInvalidBatchSize
+	}
+	p := &publishBatch{maxSize: b.MaxSize}
+	if b.MaxAge != "" {
+		var err error
+		if p.maxAge, err = time.ParseDuration(b.MaxAge); err != nil || p.maxAge <= 0 {
+			return nil, ErrInvalidBatchAge
+		}
+	}
+	if p.maxSize == 0 && p.maxAge == 0 {
+		return nil, ErrInvalidBatch
+	}
+	return p, nil
+}
+
+// add adds the metrics of a run to the batch, it returns the metrics of the
+// batch once it's full or old enough to be published and nil otherwise
+func (b *publishBatch) add(metrics []core.Metric, now time.Time) []core.Metric {
+	b.Lock()
+	defer b.Unlock()
+	if len(b.metrics) == 0 {
+		b.started = now
+	}
+	b.metrics = append(b.metrics, metrics...)
+	if len(b.metrics) == 0 {
+		return nil
+	}
+	if (b.maxSize > 0 && len(b.metrics) >= b.maxSize) || (b.maxAge > 0 && now.Sub(b.started) >= b.maxAge) {
+		batch := b.metrics
+		b.metrics = nil
+		return batch
+	}
+	return nil
+}
+
+// record records the result of the publish job of a batch
+func (b *publishBatch) record(metrics int, ok bool) {
+	if b == nil {
+		return
+	}
+	b.Lock()
+	defer b.Unlock()
+	if ok {
+		b.published++
+		b.total += uint(metrics)
+	} else {
+		b.failed++
+	}
+}
+
+func (b *publishBatch) stats(typ core.PluginType, name string, version int) core.TaskStepBatchStats {
+	b.Lock()
+	defer b.Unlock()
+	return core.TaskStepBatchStats{
+		Step:      fmt.Sprintf("%s:%s:%d", typ, name, version),
+		Pending:   uint(len(b.metrics)),
+		Published: b.published,
+		Failed:    b.failed,
+		Metrics:   b.total,
+	}
+}
+
+// BatchStats returns the batches of the workflow steps of the task which are
+// batched, in the order of the workflow
+func (t *task) BatchStats() []core.TaskStepBatchStats {
+	var stats []core.TaskStepBatchStats
+	var walk func(prs []*processNode, pus []*publishNode)
+	walk = func(prs []*processNode, pus []*publishNode) {
+		for _, pr := range prs {
+			walk(pr.ProcessNodes, pr.PublishNodes)
+		}
+		for _, pu := range pus {
+			if pu.batch != nil {
+				stats = append(stats, pu.batch.stats(core.PublisherPluginType, pu.name, pu.version))
+			}
+		}
+	}
+	walk(t.workflow.processNodes, t.workflow.publishNodes)
+	return stats
+}
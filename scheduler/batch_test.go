@@ -0,0 +1,90 @@
+// +build legacy
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/scheduler/wmap"
+)
+
+func TestPublishBatch(t *testing.T) {
+	Convey("newPublishBatch", t, func() {
+		Convey("returns no batch without a batch node", func() {
+			b, err := newPublishBatch(nil)
+			So(err, ShouldBeNil)
+			So(b, ShouldBeNil)
+		})
+		Convey("returns an error for an invalid size or age", func() {
+			_, err := newPublishBatch(&wmap.BatchWorkflowMapNode{})
+			So(err, ShouldEqual, ErrInvalidBatch)
+			_, err = newPublishBatch(&wmap.BatchWorkflowMapNode{MaxSize: -1})
+			So(err, ShouldEqual, ErrInvalidBatchSize)
+			_, err = newPublishBatch(&wmap.BatchWorkflowMapNode{MaxAge: "soon"})
+			So(err, ShouldEqual, ErrInvalidBatchAge)
+			_, err = newPublishBatch(&wmap.BatchWorkflowMapNode{MaxAge: "-1s"})
+			So(err, ShouldEqual, ErrInvalidBatchAge)
+		})
+	})
+	Convey("A batch", t, func() {
+		mts := []core.Metric{
+			plugin.MetricType{Namespace_: core.NewNamespace("foo", "a"), Data_: 1},
+			plugin.MetricType{Namespace_: core.NewNamespace("foo", "b"), Data_: 2},
+		}
+		now := time.Now()
+		Convey("is published once full", func() {
+			b, err := newPublishBatch(&wmap.BatchWorkflowMapNode{MaxSize: 3})
+			So(err, ShouldBeNil)
+			So(b.add(mts, now), ShouldBeNil)
+			So(b.stats(core.PublisherPluginType, "file", 1).Pending, ShouldEqual, 2)
+			So(b.add(mts, now), ShouldHaveLength, 4)
+			So(b.stats(core.PublisherPluginType, "file", 1).Pending, ShouldEqual, 0)
+		})
+		Convey("is published once old enough", func() {
+			b, err := newPublishBatch(&wmap.BatchWorkflowMapNode{MaxAge: "30s"})
+			So(err, ShouldBeNil)
+			So(b.add(mts, now), ShouldBeNil)
+			So(b.add(mts, now.Add(10*time.Second)), ShouldBeNil)
+			So(b.add(mts, now.Add(30*time.Second)), ShouldHaveLength, 6)
+			Convey("and its age restarts with the next metrics", func() {
+				So(b.add(mts, now.Add(40*time.Second)), ShouldBeNil)
+			})
+		})
+		Convey("records the published and failed batches", func() {
+			b, err := newPublishBatch(&wmap.BatchWorkflowMapNode{MaxSize: 1})
+			So(err, ShouldBeNil)
+			b.record(2, true)
+			b.record(3, true)
+			b.record(2, false)
+			s := b.stats(core.PublisherPluginType, "file", 1)
+			So(s.Step, ShouldEqual, "publisher:file:1")
+			So(s.Published, ShouldEqual, 2)
+			So(s.Failed, ShouldEqual, 1)
+			So(s.Metrics, ShouldEqual, 5)
+		})
+	})
+}
@@ -0,0 +1,168 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"errors"
+	"path"
+	"strings"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/pkg/tracing"
+	"github.com/intelsdi-x/snap/scheduler/wmap"
+)
+
+var (
+	// ErrInvalidBranchNamespace - The error message for when the namespace of a workflow branch condition is not a valid pattern
+	ErrInvalidBranchNamespace = errors.New("Namespace of a workflow branch condition is not a valid pattern.")
+	// ErrInvalidBranchThresholds - The error message for when no value is within the thresholds of a workflow branch condition
+	ErrInvalidBranchThresholds = errors.New("Value above threshold of a workflow branch condition must be lower than its value below threshold.")
+)
+
+// condition selects the metrics passed to a branch of a workflow
+type condition struct {
+	namespace string
+	tags      map[string]string
+	above     *float64
+	below     *float64
+}
+
+// newCondition returns the condition of a workflow branch, nil when all the
+// metrics are passed to the branch
+func newCondition(c *wmap.ConditionWorkflowMapNode) (*condition, error) {
+	if c == nil {
+		return nil, nil
+	}
+	if c.Namespace != "" {
+		if _, err := path.Match(c.Namespace, ""); err != nil {
+			return nil, ErrInvalidBranchNamespace
+		}
+	}
+	if c.ValueAbove != nil && c.ValueBelow != nil && *c.ValueAbove >= *c.ValueBelow {
+		return nil, ErrInvalidBranchThresholds
+	}
+	return &condition{
+		namespace: c.Namespace,
+		tags:      c.Tags,
+		above:     c.ValueAbove,
+		below:     c.ValueBelow,
+	}, nil
+}
+
+// matches returns true when the metric is passed to the branch, the metrics
+// without a numeric value don't match the value thresholds
+func (c *condition) matches(m core.Metric) bool {
+	if c.namespace != "" {
+		ns := "/" + strings.Join(m.Namespace().Strings(), "/")
+		if ok, _ := path.Match(c.namespace, ns); !ok {
+			return false
+		}
+	}
+	if !core.MatchLabels(c.tags, m.Tags()) {
+		return false
+	}
+	if c.above == nil && c.below == nil {
+		return true
+	}
+	v, ok := metricValue(m.Data())
+	if !ok {
+		return false
+	}
+	return (c.above == nil || v > *c.above) && (c.below == nil || v < *c.below)
+}
+
+// filter returns the metrics passed to the branch
+func (c *condition) filter(mts []core.Metric) []core.Metric {
+	var filtered []core.Metric
+	for _, m := range mts {
+		if c.matches(m) {
+			filtered = append(filtered, m)
+		}
+	}
+	return filtered
+}
+
+// branch returns the job whose metrics are passed to a node of a workflow,
+// false when no metric of the parent job is passed to the node
+func branch(pj job, c *condition) (job, bool) {
+	if c == nil {
+		return pj, true
+	}
+	mts := c.filter(pj.Metrics())
+	if len(mts) == 0 {
+		return nil, false
+	}
+	return &branchJob{job: pj, metrics: mts}, true
+}
+
+// branchJob holds the metrics of a job passed to a branch of a workflow
+type branchJob struct {
+	job
+	metrics []core.Metric
+}
+
+func (b *branchJob) Metrics() []core.Metric {
+	return b.metrics
+}
+
+func (b *branchJob) Priority() int {
+	if p, ok := b.job.(prioritizedJob); ok {
+		return p.Priority()
+	}
+	return normalPriority
+}
+
+func (b *branchJob) recorder() *runRecorder {
+	if r, ok := b.job.(recordedJob); ok {
+		return r.recorder()
+	}
+	return nil
+}
+
+func (b *branchJob) span() *tracing.Span {
+	if tj, ok := b.job.(tracedJob); ok {
+		return tj.span()
+	}
+	return nil
+}
+
+// metricValue returns the value of a metric as a float, false when the value
+// isn't a number
+func metricValue(data interface{}) (float64, bool) {
+	switch v := data.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	}
+	return 0, false
+}
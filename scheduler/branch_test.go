@@ -0,0 +1,95 @@
+// +build legacy
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/scheduler/wmap"
+)
+
+func TestBranchCondition(t *testing.T) {
+	above, below := 10.0, 100.0
+	mts := []core.Metric{
+		plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "foo"), Data_: 5, Tags_: map[string]string{"env": "prod"}},
+		plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "bar"), Data_: 50.5},
+		plugin.MetricType{Namespace_: core.NewNamespace("intel", "other", "baz"), Data_: int64(500), Tags_: map[string]string{"env": "prod"}},
+		plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "qux"), Data_: "text"},
+	}
+	Convey("newCondition", t, func() {
+		Convey("returns no condition without a when node", func() {
+			c, err := newCondition(nil)
+			So(err, ShouldBeNil)
+			So(c, ShouldBeNil)
+		})
+		Convey("returns an error for a malformed namespace pattern", func() {
+			_, err := newCondition(&wmap.ConditionWorkflowMapNode{Namespace: "/intel/["})
+			So(err, ShouldEqual, ErrInvalidBranchNamespace)
+		})
+		Convey("returns an error when no value is within the thresholds", func() {
+			_, err := newCondition(&wmap.ConditionWorkflowMapNode{ValueAbove: &below, ValueBelow: &above})
+			So(err, ShouldEqual, ErrInvalidBranchThresholds)
+		})
+	})
+	Convey("A branch condition", t, func() {
+		names := func(c *condition) []string {
+			var ns []string
+			for _, m := range c.filter(mts) {
+				ns = append(ns, m.Namespace().Element(2).Value)
+			}
+			return ns
+		}
+		Convey("selects the metrics by namespace", func() {
+			c, err := newCondition(&wmap.ConditionWorkflowMapNode{Namespace: "/intel/mock/*"})
+			So(err, ShouldBeNil)
+			So(names(c), ShouldResemble, []string{"foo", "bar", "qux"})
+		})
+		Convey("selects the metrics by tags", func() {
+			c, err := newCondition(&wmap.ConditionWorkflowMapNode{Tags: map[string]string{"env": "prod"}})
+			So(err, ShouldBeNil)
+			So(names(c), ShouldResemble, []string{"foo", "baz"})
+		})
+		Convey("selects the metrics with a numeric value within the thresholds", func() {
+			c, err := newCondition(&wmap.ConditionWorkflowMapNode{ValueAbove: &above, ValueBelow: &below})
+			So(err, ShouldBeNil)
+			So(names(c), ShouldResemble, []string{"bar"})
+			c, err = newCondition(&wmap.ConditionWorkflowMapNode{ValueAbove: &above})
+			So(err, ShouldBeNil)
+			So(names(c), ShouldResemble, []string{"bar", "baz"})
+		})
+		Convey("skips the branch when no metric matches", func() {
+			c, err := newCondition(&wmap.ConditionWorkflowMapNode{Namespace: "/intel/none/*"})
+			So(err, ShouldBeNil)
+			pj := &collectorJob{metrics: mts, coreJob: newCoreJob(collectJobType, time.Now(), "", "", 0)}
+			_, ok := branch(pj, c)
+			So(ok, ShouldBeFalse)
+			j, ok := branch(pj, nil)
+			So(ok, ShouldBeTrue)
+			So(j.Metrics(), ShouldHaveLength, 4)
+		})
+	})
+}
@@ -0,0 +1,190 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/scheduler_event"
+	"github.com/intelsdi-x/snap/scheduler/wmap"
+)
+
+var (
+	// DefaultCircuitBreakerCooldown - The default time a publisher is skipped once its circuit breaker opened
+	DefaultCircuitBreakerCooldown = time.Second * 30
+
+	// ErrInvalidCircuitBreakerFailures - The error message for when the failures of a circuit breaker aren't positive
+	ErrInvalidCircuitBreakerFailures = errors.New("Circuit breaker failures of a workflow step must be positive.")
+	// ErrInvalidCircuitBreakerCooldown - The error message for when the cooldown of a circuit breaker isn't a positive duration
+	ErrInvalidCircuitBreakerCooldown = errors.New("Circuit breaker cooldown of a workflow step must be a positive duration.")
+	// ErrCircuitBreakerOpen - The error message recorded for a step skipped by its open circuit breaker
+	ErrCircuitBreakerOpen = errors.New("Workflow step skipped, its circuit breaker is open.")
+)
+
+// circuitBreaker skips a workflow step once its jobs failed a number of
+// consecutive times, until its cooldown passed.  A single job is then tried
+// again, closing the breaker when it succeeds and opening it again when it
+// fails.
+type circuitBreaker struct {
+	sync.Mutex
+	failures uint
+	cooldown time.Duration
+
+	state       string
+	consecutive uint
+	trips       uint
+	openedAt    time.Time
+}
+
+// newCircuitBreaker returns the circuit breaker of a workflow step, nil when
+// the step has none
+func newCircuitBreaker(c *wmap.CircuitBreakerWorkflowMapNode) (*circuitBreaker, error) {
+	if c == nil {
+		return nil, nil
+	}
+	if c.Failures < 1 {
+		return nil, ErrInvalidCircuitBreakerFailures
+	}
+	b := &circuitBreaker{
+		failures: uint(c.Failures),
+		cooldown: DefaultCircuitBreakerCooldown,
+		state:    core.CircuitBreakerClosed,
+	}
+	if c.Cooldown != "" {
+		var err error
+		if b.cooldown, err = time.ParseDuration(c.Cooldown); err != nil || b.cooldown <= 0 {
+			return nil, ErrInvalidCircuitBreakerCooldown
+		}
+	}
+	return b, nil
+}
+
+// allow returns true when the job of the step can be run, the breaker is
+// half-open while the single job tried after the cooldown runs
+func (b *circuitBreaker) allow() (bool, bool) {
+	if b == nil {
+		return true, false
+	}
+	b.Lock()
+	defer b.Unlock()
+	switch b.state {
+	case core.CircuitBreakerOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false, false
+		}
+		b.state = core.CircuitBreakerHalfOpen
+		return true, true
+	case core.CircuitBreakerHalfOpen:
+		return false, false
+	}
+	return true, false
+}
+
+// record records the result of a job of the step, it returns true when the
+// breaker changed state
+func (b *circuitBreaker) record(ok bool) bool {
+	if b == nil {
+		return false
+	}
+	b.Lock()
+	defer b.Unlock()
+	previous := b.state
+	if ok {
+		b.consecutive = 0
+		b.state = core.CircuitBreakerClosed
+		return previous != b.state
+	}
+	b.consecutive++
+	if b.state == core.CircuitBreakerHalfOpen || b.consecutive >= b.failures {
+		b.state = core.CircuitBreakerOpen
+		b.openedAt = time.Now()
+		b.trips++
+	}
+	return previous != b.state
+}
+
+func (b *circuitBreaker) snapshot(typ core.PluginType, name string, version int) core.TaskStepCircuitBreaker {
+	b.Lock()
+	defer b.Unlock()
+	return core.TaskStepCircuitBreaker{
+		Step:     fmt.Sprintf("%s:%s:%d", typ, name, version),
+		State:    b.state,
+		Failures: b.consecutive,
+		Trips:    b.trips,
+		OpenedAt: b.openedAt,
+	}
+}
+
+// guard runs the job of the publish node through its circuit breaker, the
+// returned errors are nil and skipped is true when the breaker is open.  The
+// state changes of the breaker are logged and emitted.
+func (pu *publishNode) guard(t *task, run func() []error) (errs []error, skipped bool) {
+	ok, halfOpen := pu.breaker.allow()
+	if !ok {
+		return nil, true
+	}
+	if halfOpen {
+		t.emitCircuitBreaker(pu)
+	}
+	errs = run()
+	if pu.breaker.record(len(errs) == 0) {
+		t.emitCircuitBreaker(pu)
+	}
+	return errs, false
+}
+
+func (t *task) emitCircuitBreaker(pu *publishNode) {
+	state := pu.breaker.snapshot(core.PublisherPluginType, pu.name, pu.version)
+	workflowLogger.WithFields(log.Fields{
+		"_block":          "circuit-breaker",
+		"task-id":         t.id,
+		"task-name":       t.name,
+		"publish-name":    pu.name,
+		"publish-version": pu.version,
+		"state":           state.State,
+		"failures":        state.Failures,
+	}).Warn("Circuit breaker changed state")
+	t.eventEmitter.Emit(&scheduler_event.CircuitBreakerEvent{TaskID: t.id, Breaker: state})
+}
+
+// CircuitBreakers returns the state of the circuit breakers of the workflow
+// steps of the task, in the order of the workflow
+func (t *task) CircuitBreakers() []core.TaskStepCircuitBreaker {
+	var breakers []core.TaskStepCircuitBreaker
+	var walk func(prs []*processNode, pus []*publishNode)
+	walk = func(prs []*processNode, pus []*publishNode) {
+		for _, pr := range prs {
+			walk(pr.ProcessNodes, pr.PublishNodes)
+		}
+		for _, pu := range pus {
+			if pu.breaker != nil {
+				breakers = append(breakers, pu.breaker.snapshot(core.PublisherPluginType, pu.name, pu.version))
+			}
+		}
+	}
+	walk(t.workflow.processNodes, t.workflow.publishNodes)
+	return breakers
+}
@@ -0,0 +1,123 @@
+// +build legacy
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/scheduler/wmap"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	Convey("newCircuitBreaker", t, func() {
+		Convey("returns no breaker without a circuit breaker node", func() {
+			b, err := newCircuitBreaker(nil)
+			So(err, ShouldBeNil)
+			So(b, ShouldBeNil)
+		})
+		Convey("defaults the cooldown", func() {
+			b, err := newCircuitBreaker(&wmap.CircuitBreakerWorkflowMapNode{Failures: 3})
+			So(err, ShouldBeNil)
+			So(b.cooldown, ShouldEqual, DefaultCircuitBreakerCooldown)
+			So(b.state, ShouldEqual, core.CircuitBreakerClosed)
+		})
+		Convey("returns an error for invalid failures or cooldown", func() {
+			_, err := newCircuitBreaker(&wmap.CircuitBreakerWorkflowMapNode{})
+			So(err, ShouldEqual, ErrInvalidCircuitBreakerFailures)
+			_, err = newCircuitBreaker(&wmap.CircuitBreakerWorkflowMapNode{Failures: 1, Cooldown: "later"})
+			So(err, ShouldEqual, ErrInvalidCircuitBreakerCooldown)
+		})
+	})
+	Convey("A circuit breaker", t, func() {
+		b, err := newCircuitBreaker(&wmap.CircuitBreakerWorkflowMapNode{Failures: 2, Cooldown: "50ms"})
+		So(err, ShouldBeNil)
+		Convey("opens after consecutive failures", func() {
+			So(b.record(false), ShouldBeFalse)
+			So(b.record(true), ShouldBeFalse)
+			So(b.record(false), ShouldBeFalse)
+			So(b.record(false), ShouldBeTrue)
+			ok, _ := b.allow()
+			So(ok, ShouldBeFalse)
+			s := b.snapshot(core.PublisherPluginType, "file", 1)
+			So(s.Step, ShouldEqual, "publisher:file:1")
+			So(s.State, ShouldEqual, core.CircuitBreakerOpen)
+			So(s.Trips, ShouldEqual, 1)
+			So(s.OpenedAt.IsZero(), ShouldBeFalse)
+		})
+		Convey("tries a single job once its cooldown passed", func() {
+			b.record(false)
+			b.record(false)
+			time.Sleep(60 * time.Millisecond)
+			ok, halfOpen := b.allow()
+			So(ok, ShouldBeTrue)
+			So(halfOpen, ShouldBeTrue)
+			ok, _ = b.allow()
+			So(ok, ShouldBeFalse)
+			Convey("and closes when it succeeds", func() {
+				So(b.record(true), ShouldBeTrue)
+				So(b.state, ShouldEqual, core.CircuitBreakerClosed)
+				ok, _ := b.allow()
+				So(ok, ShouldBeTrue)
+			})
+			Convey("and opens again when it fails", func() {
+				So(b.record(false), ShouldBeTrue)
+				So(b.state, ShouldEqual, core.CircuitBreakerOpen)
+				So(b.trips, ShouldEqual, 2)
+			})
+		})
+	})
+	Convey("A nil circuit breaker always allows the jobs", t, func() {
+		var b *circuitBreaker
+		ok, _ := b.allow()
+		So(ok, ShouldBeTrue)
+		So(b.record(false), ShouldBeFalse)
+	})
+}
+
+func TestStepTimeout(t *testing.T) {
+	Convey("newStepTimeout", t, func() {
+		d, err := newStepTimeout("")
+		So(err, ShouldBeNil)
+		So(d, ShouldEqual, 0)
+		d, err = newStepTimeout("2s")
+		So(err, ShouldBeNil)
+		So(d, ShouldEqual, 2*time.Second)
+		_, err = newStepTimeout("-1s")
+		So(err, ShouldEqual, ErrInvalidStepTimeout)
+	})
+	Convey("awaitJob", t, func() {
+		qj := newQueuedJob(nil)
+		Convey("fails a job not done within the timeout", func() {
+			So(awaitJob(qj, 10*time.Millisecond), ShouldResemble, []error{ErrStepTimeout})
+		})
+		Convey("returns the errors of a job done within the timeout", func() {
+			e := errors.New("publish failed")
+			qj.Promise().Complete([]error{e})
+			So(awaitJob(qj, time.Second), ShouldResemble, []error{e})
+		})
+	})
+}
@@ -0,0 +1,499 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+)
+
+const (
+	// DefaultBufferMaxSize is the size the buffered batches of a task may take (100MB)
+	DefaultBufferMaxSize = 100 * 1024 * 1024
+	// DefaultBufferMaxAge is the time a batch stays in the buffer of a task
+	DefaultBufferMaxAge = 24 * time.Hour
+
+	// bufferExt is the extension of the files of the buffered batches, the
+	// batches are gob encoded so the data of the metrics keeps its type
+	bufferExt = ".gob"
+)
+
+var (
+	bufferLogger = schedulerLogger.WithField("_module", "scheduler-buffer")
+
+	// ErrBufferBatchExpired - The error message for when a buffered batch is dropped once it reached the max age of the buffer
+	ErrBufferBatchExpired = errors.New("Buffered batch expired before its publisher recovered")
+	// ErrBufferFull - The error message for when a buffered batch is dropped to keep the buffer within its max size
+	ErrBufferFull = errors.New("Buffered batch dropped, buffer full")
+)
+
+// metricBuffer stores the batches of metrics the publishers of a task failed
+// to publish in a directory, one file per batch, and hands them back oldest
+// first once the publishers recover.  The batches older than the max age
+// and, while the buffer is larger than its max size, the oldest batches are
+// dropped.
+type metricBuffer struct {
+	dir     string
+	maxSize int64
+	maxAge  time.Duration
+	mutex   *sync.Mutex
+	// the batches, oldest first
+	batches []bufferedBatch
+	size    int64
+	// the publish nodes whose backlog is being forwarded
+	flushing map[int]bool
+}
+
+// bufferedBatch is a batch of the buffer, its file is named after the time it
+// was buffered at and the index of its publish node in the workflow
+type bufferedBatch struct {
+	id        string
+	node      int
+	size      int64
+	timestamp time.Time
+}
+
+// storedBatch is the persisted form of a buffered batch
+type storedBatch struct {
+	Metrics []plugin.MetricType
+}
+
+// droppedBatch is a batch dropped from the buffer before it was forwarded
+type droppedBatch struct {
+	node    int
+	metrics []core.Metric
+	err     error
+}
+
+func newMetricBuffer(dir string, cfg *core.TaskBuffer) *metricBuffer {
+	b := &metricBuffer{
+		dir:      dir,
+		maxSize:  cfg.MaxSize,
+		maxAge:   cfg.MaxAge,
+		mutex:    &sync.Mutex{},
+		flushing: map[int]bool{},
+	}
+	if b.maxSize <= 0 {
+		b.maxSize = DefaultBufferMaxSize
+	}
+	if b.maxAge <= 0 {
+		b.maxAge = DefaultBufferMaxAge
+	}
+	return b
+}
+
+// open creates the directory of the buffer and reads the batches left in it,
+// e.g. by a task restored on the start of snapteld
+func (b *metricBuffer) open() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if err := os.MkdirAll(b.dir, 0755); err != nil {
+		return err
+	}
+	files, err := ioutil.ReadDir(b.dir)
+	if err != nil {
+		return err
+	}
+	b.batches = nil
+	b.size = 0
+	for _, f := range files {
+		if f.IsDir() || strings.HasPrefix(f.Name(), ".") || filepath.Ext(f.Name()) != bufferExt {
+			continue
+		}
+		batch, ok := parseBatchID(strings.TrimSuffix(f.Name(), bufferExt))
+		if !ok {
+			continue
+		}
+		batch.size = f.Size()
+		b.batches = append(b.batches, batch)
+		b.size += batch.size
+	}
+	sort.Sort(batchesByID(b.batches))
+	return nil
+}
+
+func batchID(ts time.Time, node int) string {
+	return fmt.Sprintf("%020d-%d", ts.UnixNano(), node)
+}
+
+func parseBatchID(id string) (bufferedBatch, bool) {
+	parts := strings.Split(id, "-")
+	if len(parts) != 2 {
+		return bufferedBatch{}, false
+	}
+	ts, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return bufferedBatch{}, false
+	}
+	node, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return bufferedBatch{}, false
+	}
+	return bufferedBatch{id: id, node: node, timestamp: time.Unix(0, ts)}, true
+}
+
+type batchesByID []bufferedBatch
+
+func (b batchesByID) Len() int           { return len(b) }
+func (b batchesByID) Less(i, j int) bool { return b[i].id < b[j].id }
+func (b batchesByID) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+
+func (b *metricBuffer) path(id string) string {
+	return filepath.Join(b.dir, id+bufferExt)
+}
+
+// push buffers the metrics of the publish node and returns the batches
+// dropped to keep the buffer within its caps
+func (b *metricBuffer) push(node int, metrics []core.Metric) ([]droppedBatch, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(storedBatch{Metrics: storedMetrics(metrics)}); err != nil {
+		return nil, err
+	}
+	data := buf.Bytes()
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	now := time.Now()
+	batch := bufferedBatch{id: batchID(now, node), node: node, size: int64(len(data)), timestamp: now}
+	// write to a temporary file first so a batch is never left half-written
+	tmp := filepath.Join(b.dir, "."+batch.id+".tmp")
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp, b.path(batch.id)); err != nil {
+		return nil, err
+	}
+	b.batches = append(b.batches, batch)
+	b.size += batch.size
+	return b.evict(now), nil
+}
+
+// evict drops the expired batches and the oldest batches while the buffer is
+// larger than its max size, the buffer must be locked
+func (b *metricBuffer) evict(now time.Time) []droppedBatch {
+	var dropped []droppedBatch
+	for len(b.batches) > 0 {
+		oldest := b.batches[0]
+		var reason error
+		switch {
+		case now.Sub(oldest.timestamp) > b.maxAge:
+			reason = ErrBufferBatchExpired
+		case b.size > b.maxSize:
+			reason = ErrBufferFull
+		default:
+			return dropped
+		}
+		metrics, _ := b.load(oldest.id)
+		b.drop(0)
+		dropped = append(dropped, droppedBatch{node: oldest.node, metrics: metrics, err: reason})
+	}
+	return dropped
+}
+
+// expire drops the batches which reached the max age of the buffer
+func (b *metricBuffer) expire() []droppedBatch {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.evict(time.Now())
+}
+
+// drop removes the i-th batch, the buffer must be locked
+func (b *metricBuffer) drop(i int) {
+	batch := b.batches[i]
+	if err := os.Remove(b.path(batch.id)); err != nil && !os.IsNotExist(err) {
+		bufferLogger.WithFields(log.Fields{
+			"_block":   "drop",
+			"batch-id": batch.id,
+			"error":    err,
+		}).Error("Unable to remove a buffered batch")
+	}
+	b.batches = append(b.batches[:i], b.batches[i+1:]...)
+	b.size -= batch.size
+}
+
+func (b *metricBuffer) load(id string) ([]core.Metric, error) {
+	data, err := ioutil.ReadFile(b.path(id))
+	if err != nil {
+		return nil, err
+	}
+	var sb storedBatch
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&sb); err != nil {
+		return nil, fmt.Errorf("Unable to parse buffered batch %s: %v", id, err)
+	}
+	return coreMetrics(sb.Metrics), nil
+}
+
+// pending returns true while metrics of the publish node are buffered
+func (b *metricBuffer) pending(node int) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for _, batch := range b.batches {
+		if batch.node == node {
+			return true
+		}
+	}
+	return false
+}
+
+// next returns the oldest batch of the publish node, false when none is
+// left.  The batches which can't be read are dropped.
+func (b *metricBuffer) next(node int) (string, []core.Metric, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for i := 0; i < len(b.batches); i++ {
+		batch := b.batches[i]
+		if batch.node != node {
+			continue
+		}
+		metrics, err := b.load(batch.id)
+		if err != nil {
+			bufferLogger.WithFields(log.Fields{
+				"_block":   "next",
+				"batch-id": batch.id,
+				"error":    err,
+			}).Error("Dropping unreadable buffered batch")
+			b.drop(i)
+			i--
+			continue
+		}
+		return batch.id, metrics, true
+	}
+	return "", nil, false
+}
+
+// remove removes a batch once it's been forwarded
+func (b *metricBuffer) remove(id string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for i, batch := range b.batches {
+		if batch.id == id {
+			b.drop(i)
+			return
+		}
+	}
+}
+
+// startFlush returns false when the backlog of the publish node is already
+// being forwarded
+func (b *metricBuffer) startFlush(node int) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.flushing[node] {
+		return false
+	}
+	b.flushing[node] = true
+	return true
+}
+
+func (b *metricBuffer) endFlush(node int) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	delete(b.flushing, node)
+}
+
+// stats returns the number of buffered batches and their size
+func (b *metricBuffer) stats() (int, int64) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return len(b.batches), b.size
+}
+
+// destroy removes the buffer and its batches
+func (b *metricBuffer) destroy() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.batches = nil
+	b.size = 0
+	return os.RemoveAll(b.dir)
+}
+
+// Buffer returns the caps and the backlog of the buffer of the task, nil when
+// the task isn't buffered
+func (t *task) Buffer() *core.TaskBuffer {
+	if t.bufferConfig == nil {
+		return nil
+	}
+	b := &core.TaskBuffer{
+		MaxSize: t.bufferConfig.MaxSize,
+		MaxAge:  t.bufferConfig.MaxAge,
+	}
+	if b.MaxSize <= 0 {
+		b.MaxSize = DefaultBufferMaxSize
+	}
+	if b.MaxAge <= 0 {
+		b.MaxAge = DefaultBufferMaxAge
+	}
+	if t.buffer != nil {
+		b.Batches, b.Size = t.buffer.stats()
+	}
+	return b
+}
+
+// SetBuffer sets the caps of the buffer of the task, the buffer is opened
+// when the task is created
+func (t *task) SetBuffer(b *core.TaskBuffer) {
+	t.bufferConfig = b
+}
+
+// openBuffer opens the buffer of the task in the given directory
+func (t *task) openBuffer(dir string) error {
+	b := newMetricBuffer(dir, t.bufferConfig)
+	if err := b.open(); err != nil {
+		return err
+	}
+	t.buffer = b
+	t.bufferNodes = workflowPublishNodes(t.workflow.processNodes, t.workflow.publishNodes)
+	return nil
+}
+
+// workflowPublishNodes returns the publish nodes of the workflow, in the
+// order of the workflow
+func workflowPublishNodes(prs []*processNode, pus []*publishNode) []*publishNode {
+	var nodes []*publishNode
+	for _, pr := range prs {
+		nodes = append(nodes, workflowPublishNodes(pr.ProcessNodes, pr.PublishNodes)...)
+	}
+	return append(nodes, pus...)
+}
+
+func (t *task) bufferNode(pu *publishNode) int {
+	for i, n := range t.bufferNodes {
+		if n == pu {
+			return i
+		}
+	}
+	return -1
+}
+
+// bufferMetrics buffers the metrics the publish node failed to publish,
+// false when the task isn't buffered or the metrics couldn't be buffered
+func (t *task) bufferMetrics(pu *publishNode, metrics []core.Metric) bool {
+	if t.buffer == nil || len(metrics) == 0 {
+		return false
+	}
+	logger := bufferLogger.WithFields(log.Fields{
+		"_block":          "buffer-metrics",
+		"task-id":         t.id,
+		"task-name":       t.name,
+		"publish-name":    pu.name,
+		"publish-version": pu.version,
+		"metric-count":    len(metrics),
+	})
+	dropped, err := t.buffer.push(t.bufferNode(pu), metrics)
+	if err != nil {
+		logger.WithField("error", err).Error("Unable to buffer metrics")
+		return false
+	}
+	logger.Debug("Metrics buffered until the publisher recovers")
+	t.dropBuffered(dropped)
+	return true
+}
+
+// dropBuffered spools the batches dropped from the buffer to the dead letter
+// queue, when it's enabled
+func (t *task) dropBuffered(dropped []droppedBatch) {
+	for _, d := range dropped {
+		if d.node < 0 || d.node >= len(t.bufferNodes) {
+			continue
+		}
+		pu := t.bufferNodes[d.node]
+		bufferLogger.WithFields(log.Fields{
+			"_block":          "drop-buffered",
+			"task-id":         t.id,
+			"task-name":       t.name,
+			"publish-name":    pu.name,
+			"publish-version": pu.version,
+			"metric-count":    len(d.metrics),
+			"reason":          d.err,
+		}).Warn("Buffered batch dropped")
+		t.spoolDeadLetter(pu, d.metrics, []error{d.err})
+	}
+}
+
+// forward publishes the backlog of the publish node, oldest first, until a
+// batch fails.  The batches stay buffered until they are published, dropped
+// by the caps of the buffer or the task is removed.
+func (t *task) forward(pj job, pu *publishNode, mgr publishesMetrics) {
+	node := t.bufferNode(pu)
+	if !t.buffer.startFlush(node) {
+		// the batch is forwarded by the run flushing the backlog
+		return
+	}
+	defer t.buffer.endFlush(node)
+
+	published := 0
+	var errs []error
+	for {
+		t.dropBuffered(t.buffer.expire())
+		id, metrics, ok := t.buffer.next(node)
+		if !ok {
+			break
+		}
+		bj := &branchJob{job: pj, metrics: metrics}
+		var skipped bool
+		errs, skipped = pu.guard(t, func() []error {
+			_, errs := pu.retry.work(t, pu.timeout, func() job {
+				return newPublishJob(bj, pu.Name(), pu.Version(), pu.InboundContentType, pu.config.Table(), mgr, t.id)
+			})
+			return errs
+		})
+		if skipped {
+			errs = []error{ErrCircuitBreakerOpen}
+		}
+		if len(errs) > 0 {
+			break
+		}
+		t.buffer.remove(id)
+		published += len(metrics)
+	}
+	recordStep(pj, pu, published, errs)
+	batches, _ := t.buffer.stats()
+	logger := bufferLogger.WithFields(log.Fields{
+		"_block":           "forward",
+		"task-id":          t.id,
+		"task-name":        t.name,
+		"publish-name":     pu.name,
+		"publish-version":  pu.version,
+		"metric-count":     published,
+		"buffered-batches": batches,
+	})
+	if len(errs) > 0 {
+		logger.WithField("error", errs[0]).Debug("Publisher not recovered, backlog kept")
+		return
+	}
+	logger.Info("Buffered metrics forwarded")
+}
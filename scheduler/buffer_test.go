@@ -0,0 +1,144 @@
+// +build legacy
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+)
+
+func TestMetricBuffer(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+	Convey("metricBuffer", t, func() {
+		dir, err := ioutil.TempDir("", "snap-buffer")
+		So(err, ShouldBeNil)
+		Reset(func() { os.RemoveAll(dir) })
+		b := newMetricBuffer(dir, &core.TaskBuffer{})
+		So(b.open(), ShouldBeNil)
+		metrics := []core.Metric{plugin.MetricType{Namespace_: core.NewNamespace("foo", "bar"), Data_: 1}}
+
+		Convey("defaults its caps", func() {
+			So(b.maxSize, ShouldEqual, DefaultBufferMaxSize)
+			So(b.maxAge, ShouldEqual, DefaultBufferMaxAge)
+		})
+		Convey("hands back the batches of a node oldest first", func() {
+			_, err := b.push(0, metrics)
+			So(err, ShouldBeNil)
+			_, err = b.push(1, metrics)
+			So(err, ShouldBeNil)
+			_, err = b.push(0, append(metrics, metrics...))
+			So(err, ShouldBeNil)
+			So(b.pending(0), ShouldBeTrue)
+			So(b.pending(2), ShouldBeFalse)
+
+			id, mts, ok := b.next(0)
+			So(ok, ShouldBeTrue)
+			So(mts, ShouldHaveLength, 1)
+			So(mts[0].Namespace().String(), ShouldEqual, "/foo/bar")
+			b.remove(id)
+			_, mts, ok = b.next(0)
+			So(ok, ShouldBeTrue)
+			So(mts, ShouldHaveLength, 2)
+			batches, size := b.stats()
+			So(batches, ShouldEqual, 2)
+			So(size, ShouldBeGreaterThan, 0)
+		})
+		Convey("reads the batches left in its directory", func() {
+			_, err := b.push(3, metrics)
+			So(err, ShouldBeNil)
+			restored := newMetricBuffer(dir, &core.TaskBuffer{})
+			So(restored.open(), ShouldBeNil)
+			So(restored.pending(3), ShouldBeTrue)
+			_, mts, ok := restored.next(3)
+			So(ok, ShouldBeTrue)
+			So(mts, ShouldHaveLength, 1)
+		})
+		Convey("keeps the type of the data of the metrics", func() {
+			_, err := b.push(0, []core.Metric{
+				plugin.MetricType{Namespace_: core.NewNamespace("foo", "int64"), Data_: int64(1) << 60},
+				plugin.MetricType{Namespace_: core.NewNamespace("foo", "uint32"), Data_: uint32(7)},
+				plugin.MetricType{Namespace_: core.NewNamespace("foo", "float32"), Data_: float32(1.5)},
+				plugin.MetricType{Namespace_: core.NewNamespace("foo", "bytes"), Data_: []byte("raw")},
+			})
+			So(err, ShouldBeNil)
+			_, mts, ok := b.next(0)
+			So(ok, ShouldBeTrue)
+			So(mts[0].Data(), ShouldEqual, int64(1)<<60)
+			So(mts[1].Data(), ShouldEqual, uint32(7))
+			So(mts[2].Data(), ShouldEqual, float32(1.5))
+			So(mts[3].Data(), ShouldResemble, []byte("raw"))
+		})
+		Convey("drops the oldest batches beyond its max size", func() {
+			_, err := b.push(0, metrics)
+			So(err, ShouldBeNil)
+			_, size := b.stats()
+			b.maxSize = size + 1
+			dropped, err := b.push(0, metrics)
+			So(err, ShouldBeNil)
+			So(dropped, ShouldHaveLength, 1)
+			So(dropped[0].err, ShouldEqual, ErrBufferFull)
+			So(dropped[0].metrics, ShouldHaveLength, 1)
+			batches, _ := b.stats()
+			So(batches, ShouldEqual, 1)
+		})
+		Convey("drops the batches older than its max age", func() {
+			b.maxAge = 10 * time.Millisecond
+			_, err := b.push(0, metrics)
+			So(err, ShouldBeNil)
+			time.Sleep(20 * time.Millisecond)
+			dropped := b.expire()
+			So(dropped, ShouldHaveLength, 1)
+			So(dropped[0].err, ShouldEqual, ErrBufferBatchExpired)
+			So(b.pending(0), ShouldBeFalse)
+		})
+		Convey("forwards the backlog of a node from a single run at a time", func() {
+			So(b.startFlush(0), ShouldBeTrue)
+			So(b.startFlush(0), ShouldBeFalse)
+			So(b.startFlush(1), ShouldBeTrue)
+			b.endFlush(0)
+			So(b.startFlush(0), ShouldBeTrue)
+		})
+		Convey("removes its directory once destroyed", func() {
+			So(b.destroy(), ShouldBeNil)
+			_, err := os.Stat(dir)
+			So(os.IsNotExist(err), ShouldBeTrue)
+		})
+	})
+	Convey("core.NewTaskBuffer", t, func() {
+		cb, err := core.NewTaskBuffer(10, "1h")
+		So(err, ShouldBeNil)
+		So(cb.MaxSize, ShouldEqual, 10*1024*1024)
+		So(cb.MaxAge, ShouldEqual, time.Hour)
+		_, err = core.NewTaskBuffer(-1, "")
+		So(err, ShouldEqual, core.ErrInvalidTaskBufferSize)
+		_, err = core.NewTaskBuffer(0, "soon")
+		So(err, ShouldEqual, core.ErrInvalidTaskBufferAge)
+	})
+}
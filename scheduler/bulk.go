@@ -0,0 +1,224 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"sort"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/serror"
+	"github.com/intelsdi-x/snap/pkg/schedule"
+)
+
+// bulkRollbackTimeout is the time a task stopped by a bulk operation which
+// failed is waited for before it's started again
+var bulkRollbackTimeout = 10 * time.Second
+
+// BulkTaskOperation starts, stops or removes the tasks selected by name
+// pattern and labels.  The operation is applied to none of the tasks when it
+// can't be applied to one of them, and the operation is reverted on the tasks
+// it was applied to when it failed on one of them.  The tasks already in the
+// requested state are left as they are.  A result is returned for each
+// selected task.
+func (s *scheduler) BulkTaskOperation(action string, sel core.TaskSelector) ([]core.TaskBulkResult, serror.SnapError) {
+	logger := schedulerLogger.WithFields(log.Fields{
+		"_block": "bulk-task-operation",
+		"action": action,
+		"name":   sel.Name,
+		"labels": sel.Labels,
+	})
+	switch action {
+	case core.TaskBulkStart, core.TaskBulkStop, core.TaskBulkRemove:
+	default:
+		return nil, serror.New(core.ErrInvalidTaskBulkAction)
+	}
+	if err := sel.Validate(); err != nil {
+		return nil, serror.New(err)
+	}
+	// one bulk operation at a time, so two of them can't see the tasks in
+	// the same state and interleave their changes
+	s.bulkMutex.Lock()
+	defer s.bulkMutex.Unlock()
+
+	tasks := s.selectTasks(sel)
+	results := make([]core.TaskBulkResult, len(tasks))
+	// apply holds the tasks which are not in the requested state yet
+	apply := make([]bool, len(tasks))
+	aborted := false
+	for i, t := range tasks {
+		results[i] = core.TaskBulkResult{ID: t.id, Name: t.name, State: t.State()}
+		var err error
+		apply[i], err = checkBulkAction(action, t)
+		if err != nil {
+			results[i].Error = serror.New(err)
+			aborted = true
+		}
+	}
+	if aborted {
+		logger.Error(core.ErrTaskBulkAborted)
+		return results, serror.New(core.ErrTaskBulkAborted)
+	}
+
+	var applied []int
+	for i, t := range tasks {
+		if !apply[i] {
+			continue
+		}
+		var errs []serror.SnapError
+		switch action {
+		case core.TaskBulkStart:
+			errs = s.startTask(t.id, "user")
+		case core.TaskBulkStop:
+			errs = s.stopTask(t.id, "user")
+		case core.TaskBulkRemove:
+			// the tasks are only taken out of the collection, so they can
+			// be put back, until all of them were
+			if err := s.tasks.remove(t); err != nil {
+				errs = []serror.SnapError{serror.New(err)}
+			}
+		}
+		if len(errs) > 0 {
+			results[i].Error = errs[0]
+			s.rollback(action, tasks, applied, results)
+			logger.WithField("task-id", t.id).Error(core.ErrTaskBulkAborted)
+			return results, serror.New(core.ErrTaskBulkAborted)
+		}
+		applied = append(applied, i)
+		results[i].State = t.State()
+	}
+	if action == core.TaskBulkRemove {
+		for _, i := range applied {
+			s.taskRemoved(tasks[i], "user")
+		}
+	}
+	logger.WithField("tasks", len(tasks)).Info("bulk task operation applied")
+	return results, nil
+}
+
+// selectTasks returns the selected tasks in the order they were created in,
+// so the tasks are started after the tasks they depend on
+func (s *scheduler) selectTasks(sel core.TaskSelector) []*task {
+	var tasks []*task
+	for _, t := range s.tasks.Table() {
+		if sel.Matches(t) {
+			tasks = append(tasks, t)
+		}
+	}
+	sort.Sort(tasksByCreation(tasks))
+	return tasks
+}
+
+// rollback reverts the action on the tasks a bulk operation which failed
+// applied it to: the started tasks are stopped, the stopped tasks are started
+// again and the removed tasks are put back in the task collection
+func (s *scheduler) rollback(action string, tasks []*task, applied []int, results []core.TaskBulkResult) {
+	for _, i := range applied {
+		t := tasks[i]
+		var errs []serror.SnapError
+		switch action {
+		case core.TaskBulkStart:
+			errs = s.stopTask(t.id, "user")
+		case core.TaskBulkStop:
+			// the plugins of the task are unsubscribed once its spin loop
+			// returned, it's started again after
+			if !t.waitSpinDone(bulkRollbackTimeout) {
+				errs = []serror.SnapError{serror.New(ErrTaskNotStopped)}
+				break
+			}
+			errs = s.startTask(t.id, "user")
+		case core.TaskBulkRemove:
+			if err := s.tasks.add(t); err != nil {
+				errs = []serror.SnapError{serror.New(err)}
+			}
+		}
+		for _, err := range errs {
+			schedulerLogger.WithFields(log.Fields{
+				"_block":  "bulk-task-rollback",
+				"action":  action,
+				"task-id": t.id,
+			}).Error(err)
+		}
+		results[i].State = t.State()
+		results[i].Error = serror.New(core.ErrTaskBulkRolledBack)
+	}
+}
+
+// checkBulkAction returns whether the action has to be applied to the task,
+// and an error when the action can't be applied to the task in its state
+func checkBulkAction(action string, t *task) (bool, error) {
+	state := t.State()
+	switch action {
+	case core.TaskBulkStart:
+		switch state {
+		case core.TaskSpinning, core.TaskFiring:
+			return false, nil
+		case core.TaskDisabled:
+			return false, ErrTaskDisabledNotRunnable
+		case core.TaskPaused:
+			return false, ErrTaskPausedNotRunnable
+		case core.TaskStopping:
+			return false, ErrTaskNotStopped
+		}
+		if err := validateSchedule(t.schedule); err != nil {
+			return false, err
+		}
+	case core.TaskBulkStop:
+		switch state {
+		case core.TaskStopped, core.TaskStopping, core.TaskEnded:
+			return false, nil
+		case core.TaskDisabled:
+			return false, ErrTaskDisabledNotStoppable
+		}
+	case core.TaskBulkRemove:
+		switch state {
+		case core.TaskStopped, core.TaskDisabled, core.TaskEnded:
+		default:
+			return false, ErrTaskNotStopped
+		}
+	}
+	return true, nil
+}
+
+// validateSchedule validates a copy of the schedule, validating a windowed
+// schedule activates it
+func validateSchedule(sch schedule.Schedule) error {
+	if w, ok := sch.(*schedule.WindowedSchedule); ok {
+		c := *w
+		return c.Validate()
+	}
+	return sch.Validate()
+}
+
+type tasksByCreation []*task
+
+func (t tasksByCreation) Len() int {
+	return len(t)
+}
+
+func (t tasksByCreation) Less(i, j int) bool {
+	return t[i].creationTime.Before(t[j].creationTime)
+}
+
+func (t tasksByCreation) Swap(i, j int) {
+	t[i], t[j] = t[j], t[i]
+}
@@ -0,0 +1,131 @@
+// +build legacy
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/pkg/schedule"
+	"github.com/intelsdi-x/snap/scheduler/wmap"
+)
+
+func TestBulkTaskOperation(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+	Convey("A scheduler with labelled tasks", t, func() {
+		w := wmap.NewWorkflowMap()
+		w.Collect.AddMetric("/foo/bar", 1)
+		w.Collect.Add(wmap.NewPublishNode("file", 1))
+		s := New(GetDefaultConfig())
+		s.SetMetricManager(&mockRunMetricManager{})
+		So(s.Start(), ShouldBeNil)
+		Reset(s.Stop)
+
+		create := func(name string, labels map[string]string) core.Task {
+			task, errs := s.CreateTask(schedule.NewWindowedSchedule(time.Hour, nil, nil, 0), w, false,
+				core.SetTaskName(name), core.SetLabels(labels))
+			So(errs.Errors(), ShouldBeEmpty)
+			return task
+		}
+		web1 := create("web-1", map[string]string{"team": "web"})
+		web2 := create("web-2", map[string]string{"team": "web", "env": "prod"})
+		db := create("db-1", map[string]string{"team": "db"})
+
+		Convey("starts the tasks selected by name", func() {
+			results, err := s.BulkTaskOperation(core.TaskBulkStart, core.TaskSelector{Name: "web-*"})
+			So(err, ShouldBeNil)
+			So(results, ShouldHaveLength, 2)
+			So(results[0].ID, ShouldEqual, web1.ID())
+			So(results[1].ID, ShouldEqual, web2.ID())
+			So(web1.State(), ShouldBeIn, core.TaskSpinning, core.TaskFiring)
+			So(web2.State(), ShouldBeIn, core.TaskSpinning, core.TaskFiring)
+			So(db.State(), ShouldEqual, core.TaskStopped)
+
+			Convey("and stops the tasks selected by labels", func() {
+				results, err := s.BulkTaskOperation(core.TaskBulkStop, core.TaskSelector{Labels: map[string]string{"env": "prod"}})
+				So(err, ShouldBeNil)
+				So(results, ShouldHaveLength, 1)
+				So(results[0].ID, ShouldEqual, web2.ID())
+				So(web2.State(), ShouldEqual, core.TaskStopped)
+				So(web1.State(), ShouldBeIn, core.TaskSpinning, core.TaskFiring)
+			})
+			Convey("and leaves the running tasks alone when started again", func() {
+				results, err := s.BulkTaskOperation(core.TaskBulkStart, core.TaskSelector{Labels: map[string]string{"team": "web"}})
+				So(err, ShouldBeNil)
+				So(results, ShouldHaveLength, 2)
+				So(results[0].Error, ShouldBeNil)
+			})
+			Convey("and removes none of the tasks when one of them is running", func() {
+				results, err := s.BulkTaskOperation(core.TaskBulkRemove, core.TaskSelector{Name: "*"})
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldEqual, core.ErrTaskBulkAborted.Error())
+				So(results, ShouldHaveLength, 3)
+				So(results[0].Error, ShouldNotBeNil)
+				So(results[2].Error, ShouldBeNil)
+				So(s.GetTasks(), ShouldHaveLength, 3)
+			})
+			Convey("and starts the stopped tasks again when the stop is rolled back", func() {
+				tasks := s.selectTasks(core.TaskSelector{Name: "web-*"})
+				for _, t := range tasks {
+					So(s.stopTask(t.id, "user"), ShouldBeEmpty)
+				}
+				results := make([]core.TaskBulkResult, len(tasks))
+				s.rollback(core.TaskBulkStop, tasks, []int{0, 1}, results)
+				So(web1.State(), ShouldBeIn, core.TaskSpinning, core.TaskFiring)
+				So(web2.State(), ShouldBeIn, core.TaskSpinning, core.TaskFiring)
+				So(results[0].Error.Error(), ShouldEqual, core.ErrTaskBulkRolledBack.Error())
+			})
+		})
+		Convey("puts the removed tasks back when the removal is rolled back", func() {
+			tasks := s.selectTasks(core.TaskSelector{Name: "web-*"})
+			for _, t := range tasks {
+				So(s.tasks.remove(t), ShouldBeNil)
+			}
+			So(s.GetTasks(), ShouldHaveLength, 1)
+			results := make([]core.TaskBulkResult, len(tasks))
+			s.rollback(core.TaskBulkRemove, tasks, []int{0, 1}, results)
+			So(s.GetTasks(), ShouldHaveLength, 3)
+			So(results[1].Error.Error(), ShouldEqual, core.ErrTaskBulkRolledBack.Error())
+		})
+		Convey("removes the stopped tasks selected by name and labels", func() {
+			results, err := s.BulkTaskOperation(core.TaskBulkRemove, core.TaskSelector{Name: "web-*", Labels: map[string]string{"env": "prod"}})
+			So(err, ShouldBeNil)
+			So(results, ShouldHaveLength, 1)
+			So(s.GetTasks(), ShouldHaveLength, 2)
+			_, gerr := s.GetTask(web2.ID())
+			So(gerr, ShouldNotBeNil)
+		})
+		Convey("returns an error without a selector", func() {
+			_, err := s.BulkTaskOperation(core.TaskBulkStart, core.TaskSelector{})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, core.ErrEmptyTaskSelector.Error())
+		})
+		Convey("returns an error for an unknown action", func() {
+			_, err := s.BulkTaskOperation("pause", core.TaskSelector{Name: "*"})
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
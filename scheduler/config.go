@@ -22,12 +22,31 @@ package scheduler
 import (
 	"encoding/json"
 	"fmt"
+	"time"
+
+	"github.com/vrischmann/jsonutil"
 )
 
 // default configuration values
 const (
-	defaultWorkManagerQueueSize uint = 25
-	defaultWorkManagerPoolSize  uint = 4
+	defaultWorkManagerQueueSize       uint = 25
+	defaultWorkManagerPoolSize        uint = 4
+	defaultWorkManagerMaxPoolSize     uint = 16
+	defaultWorkManagerMaxQueueLatency      = 100 * time.Millisecond
+	defaultMinIntervalPolicy               = MinIntervalPolicyDownsample
+	defaultPersistTasks                    = true
+	defaultTaskStorePath                   = "/var/lib/snap/tasks.json"
+	defaultDeadLetterMaxBatches            = 1000
+	defaultBufferPath                      = "/var/lib/snap/buffers"
+)
+
+// The policies applied to the tasks collecting metrics more often than their
+// minimum collection interval allows
+const (
+	// MinIntervalPolicyReject refuses to create the task
+	MinIntervalPolicyReject = "reject"
+	// MinIntervalPolicyDownsample collects the metric only once its minimum interval elapsed
+	MinIntervalPolicyDownsample = "downsample"
 )
 
 // holds the configuration passed in through the SNAP config file
@@ -35,8 +54,20 @@ const (
 //         UnmarshalJSON method in this same file needs to be modified to
 //         match the field mapping that is defined here
 type Config struct {
-	WorkManagerQueueSize uint `json:"work_manager_queue_size"yaml:"work_manager_queue_size"`
-	WorkManagerPoolSize  uint `json:"work_manager_pool_size"yaml:"work_manager_pool_size"`
+	WorkManagerQueueSize       uint              `json:"work_manager_queue_size"yaml:"work_manager_queue_size"`
+	WorkManagerPoolSize        uint              `json:"work_manager_pool_size"yaml:"work_manager_pool_size"`
+	WorkManagerCollectPoolSize uint              `json:"work_manager_collect_pool_size"yaml:"work_manager_collect_pool_size"`
+	WorkManagerProcessPoolSize uint              `json:"work_manager_process_pool_size"yaml:"work_manager_process_pool_size"`
+	WorkManagerPublishPoolSize uint              `json:"work_manager_publish_pool_size"yaml:"work_manager_publish_pool_size"`
+	WorkManagerAutoscale       bool              `json:"work_manager_autoscale"yaml:"work_manager_autoscale"`
+	WorkManagerMaxPoolSize     uint              `json:"work_manager_max_pool_size"yaml:"work_manager_max_pool_size"`
+	WorkManagerMaxQueueLatency jsonutil.Duration `json:"work_manager_max_queue_latency"yaml:"work_manager_max_queue_latency"`
+	MinIntervalPolicy          string            `json:"min_interval_policy"yaml:"min_interval_policy"`
+	PersistTasks               bool              `json:"persist_tasks"yaml:"persist_tasks"`
+	TaskStorePath              string            `json:"task_store_path"yaml:"task_store_path"`
+	DeadLetterPath             string            `json:"dead_letter_path"yaml:"dead_letter_path"`
+	DeadLetterMaxBatches       int               `json:"dead_letter_max_batches"yaml:"dead_letter_max_batches"`
+	BufferPath                 string            `json:"buffer_path"yaml:"buffer_path"`
 }
 
 const (
@@ -51,6 +82,48 @@ const (
 					"work_manager_pool_size" : {
 						"type": "integer",
 						"minimum": 1
+					},
+					"work_manager_collect_pool_size" : {
+						"type": "integer",
+						"minimum": 1
+					},
+					"work_manager_process_pool_size" : {
+						"type": "integer",
+						"minimum": 1
+					},
+					"work_manager_publish_pool_size" : {
+						"type": "integer",
+						"minimum": 1
+					},
+					"work_manager_autoscale" : {
+						"type": "boolean"
+					},
+					"work_manager_max_pool_size" : {
+						"type": "integer",
+						"minimum": 1
+					},
+					"work_manager_max_queue_latency" : {
+						"type": "string"
+					},
+					"min_interval_policy" : {
+						"type": "string",
+						"enum": ["reject", "downsample"]
+					},
+					"persist_tasks" : {
+						"type": "boolean"
+					},
+					"task_store_path" : {
+						"type": "string"
+					},
+					"dead_letter_path" : {
+						"type": "string"
+					},
+					"dead_letter_max_batches" : {
+						"type": "integer",
+						"minimum": 1
+					},
+					"buffer_path" : {
+						"type": "string"
 					}
 				},
 				"additionalProperties": false
@@ -61,11 +134,27 @@ const (
 // get the default snapteld configuration
 func GetDefaultConfig() *Config {
 	return &Config{
-		WorkManagerQueueSize: defaultWorkManagerQueueSize,
-		WorkManagerPoolSize:  defaultWorkManagerPoolSize,
+		WorkManagerQueueSize:       defaultWorkManagerQueueSize,
+		WorkManagerPoolSize:        defaultWorkManagerPoolSize,
+		WorkManagerMaxPoolSize:     defaultWorkManagerMaxPoolSize,
+		WorkManagerMaxQueueLatency: jsonutil.Duration{defaultWorkManagerMaxQueueLatency},
+		MinIntervalPolicy:          defaultMinIntervalPolicy,
+		PersistTasks:               defaultPersistTasks,
+		TaskStorePath:              defaultTaskStorePath,
+		DeadLetterMaxBatches:       defaultDeadLetterMaxBatches,
+		BufferPath:                 defaultBufferPath,
 	}
 }
 
+// poolSize returns the size of a worker pool, the size of all the pools when
+// it's not set
+func (c *Config) poolSize(size uint) uint {
+	if size == 0 {
+		return c.WorkManagerPoolSize
+	}
+	return size
+}
+
 // UnmarshalJSON unmarshals valid json into a Config.  An example Config can be found
 // at github.com/intelsdi-x/snap/blob/master/examples/configs/snap-config-sample.json
 func (c *Config) UnmarshalJSON(data []byte) error {
@@ -88,6 +177,54 @@ func (c *Config) UnmarshalJSON(data []byte) error {
 			if err := json.Unmarshal(v, &(c.WorkManagerPoolSize)); err != nil {
 				return fmt.Errorf("%v (while parsing 'scheduler::work_manager_pool_size')", err)
 			}
+		case "work_manager_collect_pool_size":
+			if err := json.Unmarshal(v, &(c.WorkManagerCollectPoolSize)); err != nil {
+				return fmt.Errorf("%v (while parsing 'scheduler::work_manager_collect_pool_size')", err)
+			}
+		case "work_manager_process_pool_size":
+			if err := json.Unmarshal(v, &(c.WorkManagerProcessPoolSize)); err != nil {
+				return fmt.Errorf("%v (while parsing 'scheduler::work_manager_process_pool_size')", err)
+			}
+		case "work_manager_publish_pool_size":
+			if err := json.Unmarshal(v, &(c.WorkManagerPublishPoolSize)); err != nil {
+				return fmt.Errorf("%v (while parsing 'scheduler::work_manager_publish_pool_size')", err)
+			}
+		case "work_manager_autoscale":
+			if err := json.Unmarshal(v, &(c.WorkManagerAutoscale)); err != nil {
+				return fmt.Errorf("%v (while parsing 'scheduler::work_manager_autoscale')", err)
+			}
+		case "work_manager_max_pool_size":
+			if err := json.Unmarshal(v, &(c.WorkManagerMaxPoolSize)); err != nil {
+				return fmt.Errorf("%v (while parsing 'scheduler::work_manager_max_pool_size')", err)
+			}
+		case "work_manager_max_queue_latency":
+			if err := json.Unmarshal(v, &(c.WorkManagerMaxQueueLatency)); err != nil {
+				return fmt.Errorf("%v (while parsing 'scheduler::work_manager_max_queue_latency')", err)
+			}
+		case "min_interval_policy":
+			if err := json.Unmarshal(v, &(c.MinIntervalPolicy)); err != nil {
+				return fmt.Errorf("%v (while parsing 'scheduler::min_interval_policy')", err)
+			}
+		case "persist_tasks":
+			if err := json.Unmarshal(v, &(c.PersistTasks)); err != nil {
+				return fmt.Errorf("%v (while parsing 'scheduler::persist_tasks')", err)
+			}
+		case "task_store_path":
+			if err := json.Unmarshal(v, &(c.TaskStorePath)); err != nil {
+				return fmt.Errorf("%v (while parsing 'scheduler::task_store_path')", err)
+			}
+		case "dead_letter_path":
+			if err := json.Unmarshal(v, &(c.DeadLetterPath)); err != nil {
+				return fmt.Errorf("%v (while parsing 'scheduler::dead_letter_path')", err)
+			}
+		case "dead_letter_max_batches":
+			if err := json.Unmarshal(v, &(c.DeadLetterMaxBatches)); err != nil {
+				return fmt.Errorf("%v (while parsing 'scheduler::dead_letter_max_batches')", err)
+			}
+		case "buffer_path":
+			if err := json.Unmarshal(v, &(c.BufferPath)); err != nil {
+				return fmt.Errorf("%v (while parsing 'scheduler::buffer_path')", err)
+			}
 		default:
 			return fmt.Errorf("Unrecognized key '%v' in global config file while parsing 'scheduler'", k)
 		}
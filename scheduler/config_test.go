@@ -107,5 +107,13 @@ func TestSchedulerDefaultConfig(t *testing.T) {
 		Convey("WorkManagerPoolSize should equal 4", func() {
 			So(cfg.WorkManagerPoolSize, ShouldEqual, 4)
 		})
+		Convey("The pools should not be autoscaled", func() {
+			So(cfg.WorkManagerAutoscale, ShouldBeFalse)
+			So(cfg.WorkManagerMaxPoolSize, ShouldEqual, 16)
+		})
+		Convey("The size of a pool should default to WorkManagerPoolSize", func() {
+			So(cfg.poolSize(cfg.WorkManagerCollectPoolSize), ShouldEqual, 4)
+			So(cfg.poolSize(2), ShouldEqual, 2)
+		})
 	})
 }
@@ -0,0 +1,403 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/pborman/uuid"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/serror"
+)
+
+var (
+	deadLetterLogger = schedulerLogger.WithField("_module", "scheduler-dead-letter")
+
+	// ErrDeadLetterQueueDisabled - The error message for when the dead letter queue isn't enabled
+	ErrDeadLetterQueueDisabled = errors.New("Dead letter queue is not enabled")
+	// ErrDeadLetterNotFound - The error message for when a batch of the dead letter queue doesn't exist
+	ErrDeadLetterNotFound = errors.New("Dead letter not found")
+	// ErrDeadLetterPublisherNotFound - The error message for when the publisher of a batch isn't in the workflow of its task anymore
+	ErrDeadLetterPublisherNotFound = errors.New("Publisher of the dead letter not found in the workflow of its task")
+)
+
+// deadLetterExt is the extension of the files of the dead letter queue, the
+// batches are gob encoded so the data of the metrics keeps its type
+const deadLetterExt = ".gob"
+
+// deadLetterQueue spools the batches of metrics the publishers failed to
+// publish in a directory, one file per batch.  Once the queue holds its
+// maximum number of batches the oldest ones are dropped.
+type deadLetterQueue struct {
+	dir   string
+	max   int
+	mutex *sync.Mutex
+}
+
+// storedDeadLetter is the persisted form of a batch of the dead letter queue
+type storedDeadLetter struct {
+	ID            string
+	TaskID        string
+	TaskName      string
+	PluginName    string
+	PluginVersion int
+	Errors        []string
+	Timestamp     time.Time
+	Metrics       []plugin.MetricType
+}
+
+func newDeadLetterQueue(dir string, max int) *deadLetterQueue {
+	return &deadLetterQueue{
+		dir:   dir,
+		max:   max,
+		mutex: &sync.Mutex{},
+	}
+}
+
+// newDeadLetterID returns an ID sorting the batches by the time they were
+// spooled at
+func newDeadLetterID(ts time.Time) string {
+	return fmt.Sprintf("%020d-%s", ts.UnixNano(), uuid.New())
+}
+
+// push spools a batch, dropping the oldest batches when the queue is full
+func (q *deadLetterQueue) push(dl storedDeadLetter) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(dl); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(q.dir, 0755); err != nil {
+		return err
+	}
+	ids, err := q.list()
+	if err != nil {
+		return err
+	}
+	for len(ids) >= q.max && len(ids) > 0 {
+		deadLetterLogger.WithFields(log.Fields{
+			"_block":         "push",
+			"dead-letter-id": ids[0],
+			"max-batches":    q.max,
+		}).Warn("Dead letter queue full, dropping the oldest batch")
+		if err := os.Remove(q.path(ids[0])); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		ids = ids[1:]
+	}
+	// write to a temporary file first so a batch is never left half-written
+	tmp := filepath.Join(q.dir, "."+dl.ID+".tmp")
+	if err := ioutil.WriteFile(tmp, buf.Bytes(), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, q.path(dl.ID))
+}
+
+// list returns the IDs of the batches, oldest first
+func (q *deadLetterQueue) list() ([]string, error) {
+	files, err := ioutil.ReadDir(q.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var ids []string
+	for _, f := range files {
+		if f.IsDir() || strings.HasPrefix(f.Name(), ".") || filepath.Ext(f.Name()) != deadLetterExt {
+			continue
+		}
+		ids = append(ids, strings.TrimSuffix(f.Name(), deadLetterExt))
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// ids returns the IDs of the batches, oldest first
+func (q *deadLetterQueue) ids() ([]string, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.list()
+}
+
+func (q *deadLetterQueue) path(id string) string {
+	return filepath.Join(q.dir, id+deadLetterExt)
+}
+
+// load reads a batch, ErrDeadLetterNotFound is returned when it doesn't exist
+func (q *deadLetterQueue) load(id string) (storedDeadLetter, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	var dl storedDeadLetter
+	if id == "" || filepath.Base(id) != id {
+		return dl, ErrDeadLetterNotFound
+	}
+	b, err := ioutil.ReadFile(q.path(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return dl, ErrDeadLetterNotFound
+		}
+		return dl, err
+	}
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&dl); err != nil {
+		return dl, fmt.Errorf("Unable to parse dead letter %s: %v", id, err)
+	}
+	return dl, nil
+}
+
+// remove removes a batch, ErrDeadLetterNotFound is returned when it doesn't exist
+func (q *deadLetterQueue) remove(id string) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if id == "" || filepath.Base(id) != id {
+		return ErrDeadLetterNotFound
+	}
+	if err := os.Remove(q.path(id)); err != nil {
+		if os.IsNotExist(err) {
+			return ErrDeadLetterNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (dl storedDeadLetter) toCore(withMetrics bool) core.DeadLetter {
+	c := core.DeadLetter{
+		ID:            dl.ID,
+		TaskID:        dl.TaskID,
+		TaskName:      dl.TaskName,
+		PluginName:    dl.PluginName,
+		PluginVersion: dl.PluginVersion,
+		Errors:        dl.Errors,
+		Timestamp:     dl.Timestamp,
+		MetricCount:   len(dl.Metrics),
+	}
+	if withMetrics {
+		c.Metrics = dl.metrics()
+	}
+	return c
+}
+
+func (dl storedDeadLetter) metrics() []core.Metric {
+	return coreMetrics(dl.Metrics)
+}
+
+// storedMetrics returns the metrics in their persisted form
+func storedMetrics(metrics []core.Metric) []plugin.MetricType {
+	mts := make([]plugin.MetricType, len(metrics))
+	for i, m := range metrics {
+		mts[i] = plugin.MetricType{
+			Namespace_:          m.Namespace(),
+			LastAdvertisedTime_: m.LastAdvertisedTime(),
+			Version_:            m.Version(),
+			Data_:               m.Data(),
+			Tags_:               m.Tags(),
+			Unit_:               m.Unit(),
+			Description_:        m.Description(),
+			DataType_:           m.DataType(),
+			Timestamp_:          m.Timestamp(),
+		}
+	}
+	return mts
+}
+
+func coreMetrics(mts []plugin.MetricType) []core.Metric {
+	metrics := make([]core.Metric, len(mts))
+	for i, m := range mts {
+		metrics[i] = m
+	}
+	return metrics
+}
+
+// spoolDeadLetter spools the metrics the publish node failed to publish when
+// the dead letter queue is enabled
+func (t *task) spoolDeadLetter(pu *publishNode, metrics []core.Metric, errs []error) {
+	if t.deadLetters == nil || len(metrics) == 0 {
+		return
+	}
+	now := time.Now()
+	dl := storedDeadLetter{
+		ID:            newDeadLetterID(now),
+		TaskID:        t.id,
+		TaskName:      t.name,
+		PluginName:    pu.name,
+		PluginVersion: pu.version,
+		Timestamp:     now,
+		Metrics:       storedMetrics(metrics),
+	}
+	for _, err := range errs {
+		dl.Errors = append(dl.Errors, err.Error())
+	}
+	logger := deadLetterLogger.WithFields(log.Fields{
+		"_block":          "spool",
+		"task-id":         t.id,
+		"task-name":       t.name,
+		"publish-name":    pu.name,
+		"publish-version": pu.version,
+		"metric-count":    len(metrics),
+	})
+	if err := t.deadLetters.push(dl); err != nil {
+		logger.WithField("error", err).Error("Unable to spool the failed publish")
+		return
+	}
+	logger.WithField("dead-letter-id", dl.ID).Warn("Failed publish spooled to the dead letter queue")
+}
+
+// SetDeadLetterQueue spools the batches of metrics the publishers failed to
+// publish in the given directory, keeping up to max batches
+func (s *scheduler) SetDeadLetterQueue(dir string, max int) {
+	s.deadLetters = newDeadLetterQueue(dir, max)
+	schedulerLogger.WithFields(log.Fields{
+		"_block":      "set-dead-letter-queue",
+		"path":        dir,
+		"max-batches": max,
+	}).Debug("dead letter queue set")
+}
+
+// DeadLetters returns the batches of the dead letter queue, oldest first,
+// without their metrics
+func (s *scheduler) DeadLetters() ([]core.DeadLetter, serror.SnapError) {
+	if s.deadLetters == nil {
+		return nil, serror.New(ErrDeadLetterQueueDisabled)
+	}
+	ids, err := s.deadLetters.ids()
+	if err != nil {
+		return nil, serror.New(err)
+	}
+	dls := []core.DeadLetter{}
+	for _, id := range ids {
+		dl, err := s.deadLetters.load(id)
+		if err == ErrDeadLetterNotFound {
+			// replayed or purged meanwhile
+			continue
+		}
+		if err != nil {
+			return nil, serror.New(err, map[string]interface{}{"dead-letter-id": id})
+		}
+		dls = append(dls, dl.toCore(false))
+	}
+	return dls, nil
+}
+
+// GetDeadLetter returns a batch of the dead letter queue with its metrics
+func (s *scheduler) GetDeadLetter(id string) (core.DeadLetter, serror.SnapError) {
+	if s.deadLetters == nil {
+		return core.DeadLetter{}, serror.New(ErrDeadLetterQueueDisabled)
+	}
+	dl, err := s.deadLetters.load(id)
+	if err != nil {
+		return core.DeadLetter{}, serror.New(err, map[string]interface{}{"dead-letter-id": id})
+	}
+	return dl.toCore(true), nil
+}
+
+// ReplayDeadLetters publishes the given batches again, all of them when no
+// ID is given, with the current config of their publisher.  The published
+// batches are removed from the queue.
+func (s *scheduler) ReplayDeadLetters(ids []string) ([]core.DeadLetterResult, serror.SnapError) {
+	return s.deadLetterOperation(ids, s.replayDeadLetter)
+}
+
+// PurgeDeadLetters removes the given batches, all of them when no ID is given
+func (s *scheduler) PurgeDeadLetters(ids []string) ([]core.DeadLetterResult, serror.SnapError) {
+	return s.deadLetterOperation(ids, s.deadLetters.remove)
+}
+
+func (s *scheduler) deadLetterOperation(ids []string, op func(string) error) ([]core.DeadLetterResult, serror.SnapError) {
+	if s.deadLetters == nil {
+		return nil, serror.New(ErrDeadLetterQueueDisabled)
+	}
+	if len(ids) == 0 {
+		var err error
+		if ids, err = s.deadLetters.ids(); err != nil {
+			return nil, serror.New(err)
+		}
+	}
+	results := make([]core.DeadLetterResult, len(ids))
+	for i, id := range ids {
+		results[i].ID = id
+		if err := op(id); err != nil {
+			results[i].Error = serror.New(err, map[string]interface{}{"dead-letter-id": id})
+		}
+	}
+	return results, nil
+}
+
+func (s *scheduler) replayDeadLetter(id string) error {
+	dl, err := s.deadLetters.load(id)
+	if err != nil {
+		return err
+	}
+	t, err := s.getTask(dl.TaskID)
+	if err != nil {
+		return err
+	}
+	pu := findPublishNode(t.workflow.processNodes, t.workflow.publishNodes, dl.PluginName, dl.PluginVersion)
+	if pu == nil {
+		return ErrDeadLetterPublisherNotFound
+	}
+	mgr, err := t.RemoteManagers.Get(pu.Target)
+	if err != nil {
+		return err
+	}
+	if errs := mgr.PublishMetrics(dl.metrics(), pu.config.Table(), t.id, pu.name, pu.version); len(errs) > 0 {
+		return errs[0]
+	}
+	deadLetterLogger.WithFields(log.Fields{
+		"_block":         "replay",
+		"dead-letter-id": id,
+		"task-id":        t.id,
+		"metric-count":   len(dl.Metrics),
+	}).Info("Dead letter replayed")
+	return s.deadLetters.remove(id)
+}
+
+// findPublishNode returns the first publish node of the workflow with the
+// given plugin
+func findPublishNode(prs []*processNode, pus []*publishNode, name string, version int) *publishNode {
+	for _, pu := range pus {
+		if pu.name == name && pu.version == version {
+			return pu
+		}
+	}
+	for _, pr := range prs {
+		if pu := findPublishNode(pr.ProcessNodes, pr.PublishNodes, name, version); pu != nil {
+			return pu
+		}
+	}
+	return nil
+}
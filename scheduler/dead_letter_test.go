@@ -0,0 +1,122 @@
+// +build legacy
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+)
+
+func TestDeadLetterQueue(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+	Convey("deadLetterQueue", t, func() {
+		dir, err := ioutil.TempDir("", "snap-dead-letters")
+		So(err, ShouldBeNil)
+		Reset(func() { os.RemoveAll(dir) })
+		q := newDeadLetterQueue(dir, 2)
+		batch := func(ts time.Time) storedDeadLetter {
+			return storedDeadLetter{
+				ID:         newDeadLetterID(ts),
+				TaskID:     "1234",
+				PluginName: "influxdb",
+				Errors:     []string{"connection refused"},
+				Timestamp:  ts,
+				Metrics: []plugin.MetricType{
+					{Namespace_: core.NewNamespace("foo", "bar"), Data_: 1},
+				},
+			}
+		}
+
+		Convey("returns no batches without a directory", func() {
+			q.dir = dir + "/missing"
+			ids, err := q.ids()
+			So(err, ShouldBeNil)
+			So(ids, ShouldBeEmpty)
+		})
+		Convey("loads the spooled batches", func() {
+			dl := batch(time.Now())
+			So(q.push(dl), ShouldBeNil)
+			loaded, err := q.load(dl.ID)
+			So(err, ShouldBeNil)
+			So(loaded.TaskID, ShouldEqual, "1234")
+			So(loaded.Errors, ShouldResemble, []string{"connection refused"})
+			c := loaded.toCore(true)
+			So(c.MetricCount, ShouldEqual, 1)
+			So(c.Metrics[0].Namespace().String(), ShouldEqual, "/foo/bar")
+			So(loaded.toCore(false).Metrics, ShouldBeNil)
+		})
+		Convey("keeps the type of the data of the metrics", func() {
+			dl := batch(time.Now())
+			dl.Metrics = []plugin.MetricType{
+				{Namespace_: core.NewNamespace("foo", "int64"), Data_: int64(1) << 60},
+				{Namespace_: core.NewNamespace("foo", "uint32"), Data_: uint32(7)},
+				{Namespace_: core.NewNamespace("foo", "float32"), Data_: float32(1.5)},
+				{Namespace_: core.NewNamespace("foo", "bytes"), Data_: []byte("raw")},
+			}
+			So(q.push(dl), ShouldBeNil)
+			loaded, err := q.load(dl.ID)
+			So(err, ShouldBeNil)
+			So(loaded.Metrics[0].Data(), ShouldEqual, int64(1)<<60)
+			So(loaded.Metrics[1].Data(), ShouldEqual, uint32(7))
+			So(loaded.Metrics[2].Data(), ShouldEqual, float32(1.5))
+			So(loaded.Metrics[3].Data(), ShouldResemble, []byte("raw"))
+		})
+		Convey("drops the oldest batches once full", func() {
+			now := time.Now()
+			first, second, third := batch(now), batch(now.Add(time.Second)), batch(now.Add(2*time.Second))
+			So(q.push(first), ShouldBeNil)
+			So(q.push(second), ShouldBeNil)
+			So(q.push(third), ShouldBeNil)
+			ids, err := q.ids()
+			So(err, ShouldBeNil)
+			So(ids, ShouldResemble, []string{second.ID, third.ID})
+		})
+		Convey("removes a batch", func() {
+			dl := batch(time.Now())
+			So(q.push(dl), ShouldBeNil)
+			So(q.remove(dl.ID), ShouldBeNil)
+			_, err := q.load(dl.ID)
+			So(err, ShouldEqual, ErrDeadLetterNotFound)
+			So(q.remove(dl.ID), ShouldEqual, ErrDeadLetterNotFound)
+		})
+		Convey("refuses the IDs outside of its directory", func() {
+			_, err := q.load("../tasks")
+			So(err, ShouldEqual, ErrDeadLetterNotFound)
+		})
+	})
+	Convey("The scheduler without a dead letter queue", t, func() {
+		s := New(GetDefaultConfig())
+		_, err := s.DeadLetters()
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldEqual, ErrDeadLetterQueueDisabled.Error())
+		_, err = s.PurgeDeadLetters(nil)
+		So(err, ShouldNotBeNil)
+	})
+}
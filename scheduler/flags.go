@@ -38,6 +38,24 @@ var (
 		EnvVar: "WORK_MANAGER_POOL_SIZE",
 	}
 
+	flTaskPersistenceDisabled = cli.BoolFlag{
+		Name:   "disable-task-persistence",
+		Usage:  "Disable persisting the tasks across restarts",
+		EnvVar: "SNAP_DISABLE_TASK_PERSISTENCE",
+	}
+
+	flTaskStorePath = cli.StringFlag{
+		Name:   "task-store-path",
+		Usage:  fmt.Sprintf("A path to the file where the tasks are persisted across restarts (default: %v)", defaultTaskStorePath),
+		EnvVar: "SNAP_TASK_STORE_PATH",
+	}
+
+	flDeadLetterPath = cli.StringFlag{
+		Name:   "dead-letter-path",
+		Usage:  "A path to the directory where the failed publishes are spooled (disabled when not set)",
+		EnvVar: "SNAP_DEAD_LETTER_PATH",
+	}
+
 	// Flags consumed by snapteld
-	Flags = []cli.Flag{flSchedulerQueueSize, flSchedulerPoolSize}
+	Flags = []cli.Flag{flSchedulerQueueSize, flSchedulerPoolSize, flTaskPersistenceDisabled, flTaskStorePath, flDeadLetterPath}
 )
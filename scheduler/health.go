@@ -0,0 +1,61 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"fmt"
+
+	"github.com/intelsdi-x/snap/core"
+)
+
+// Health reports the scheduler as ready once it's started, and degraded
+// while tasks are disabled after failing repeatedly
+func (s *scheduler) Health() core.SubsystemHealth {
+	started := s.state == schedulerStarted
+	h := core.SubsystemHealth{
+		Name:    "scheduler",
+		Healthy: started,
+		Ready:   started,
+		Details: map[string]interface{}{},
+	}
+	if !started {
+		h.Message = "scheduler isn't started"
+		return h
+	}
+	states := map[string]int{}
+	disabled := 0
+	for _, t := range s.tasks.Table() {
+		states[t.State().String()]++
+		if t.State() == core.TaskDisabled {
+			disabled++
+		}
+	}
+	queued := uint(0)
+	for _, ps := range s.WorkerPoolStats() {
+		queued += ps.QueueDepth
+	}
+	h.Details["tasks"] = states
+	h.Details["queued_jobs"] = queued
+	if disabled > 0 {
+		h.Degraded = true
+		h.Message = fmt.Sprintf("%d tasks are disabled", disabled)
+	}
+	return h
+}
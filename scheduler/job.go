@@ -29,6 +29,7 @@ import (
 	"github.com/intelsdi-x/snap/core/cdata"
 	"github.com/intelsdi-x/snap/core/ctypes"
 	. "github.com/intelsdi-x/snap/pkg/promise"
+	"github.com/intelsdi-x/snap/pkg/tracing"
 )
 
 const (
@@ -41,6 +42,19 @@ const (
 	defaultDeadline = time.Duration(5 * time.Second)
 )
 
+// The priority levels of the jobs, from the priorities of their tasks
+const (
+	lowPriority = iota
+	normalPriority
+	highPriority
+)
+
+var priorityLevels = map[string]int{
+	core.TaskPriorityLow:    lowPriority,
+	core.TaskPriorityNormal: normalPriority,
+	core.TaskPriorityHigh:   highPriority,
+}
+
 // Represents a queued job, together with a synchronization
 // barrier to signal job completion (successful or otherwise).
 //
@@ -92,6 +106,24 @@ type job interface {
 
 type jobType int
 
+// prioritizedJob is implemented by the jobs dispatched according to the
+// priority of their task
+type prioritizedJob interface {
+	Priority() int
+}
+
+// recordedJob is implemented by the jobs whose results are recorded in the
+// record of their run
+type recordedJob interface {
+	recorder() *runRecorder
+}
+
+// tracedJob is implemented by the jobs whose step is traced, the spans of the
+// steps following them are the children of their span
+type tracedJob interface {
+	span() *tracing.Span
+}
+
 type coreJob struct {
 	sync.Mutex
 	name      string
@@ -101,6 +133,11 @@ type coreJob struct {
 	deadline  time.Time
 	starttime time.Time
 	errors    []error
+	priority  int
+	// run records the results of the scheduled run the job is part of
+	run *runRecorder
+	// stepSpan traces the step the job is part of, nil when it isn't traced
+	stepSpan *tracing.Span
 }
 
 func newCoreJob(t jobType, deadline time.Time, taskID string, name string, version int) *coreJob {
@@ -112,6 +149,7 @@ func newCoreJob(t jobType, deadline time.Time, taskID string, name string, versi
 		taskID:    taskID,
 		errors:    make([]error, 0),
 		starttime: time.Now(),
+		priority:  normalPriority,
 	}
 }
 
@@ -163,6 +201,46 @@ func (c *coreJob) TaskID() string {
 	return c.taskID
 }
 
+// Priority returns the priority level the job is dispatched with
+func (c *coreJob) Priority() int {
+	if c == nil {
+		return normalPriority
+	}
+	return c.priority
+}
+
+// setPriority sets the priority level of the job from the priority of its task
+func (c *coreJob) setPriority(p string) {
+	if level, ok := priorityLevels[p]; ok {
+		c.priority = level
+	}
+}
+
+func (c *coreJob) recorder() *runRecorder {
+	if c == nil {
+		return nil
+	}
+	return c.run
+}
+
+func (c *coreJob) span() *tracing.Span {
+	if c == nil {
+		return nil
+	}
+	return c.stepSpan
+}
+
+// inherit gives the job the priority level and the run of the job it follows
+func (c *coreJob) inherit(parent job) *coreJob {
+	if p, ok := parent.(prioritizedJob); ok {
+		c.priority = p.Priority()
+	}
+	if r, ok := parent.(recordedJob); ok {
+		c.run = r.recorder()
+	}
+	return c
+}
+
 type collectorJob struct {
 	*coreJob
 	collector      collectsMetrics
@@ -211,6 +289,7 @@ func (m *metric) Version() int {
 func (m *metric) Data() interface{}             { return nil }
 func (m *metric) Description() string           { return "" }
 func (m *metric) Unit() string                  { return "" }
+func (m *metric) DataType() string              { return "" }
 func (m *metric) Tags() map[string]string       { return nil }
 func (m *metric) LastAdvertisedTime() time.Time { return time.Unix(0, 0) }
 func (m *metric) Timestamp() time.Time          { return time.Unix(0, 0) }
@@ -226,6 +305,9 @@ func (c *collectorJob) Run() {
 		"job-type":     "collector",
 		"metric-count": len(c.metricTypes),
 	}).Debug("starting collector job")
+	// the span of the collect step ends with the job, not once it's awaited
+	c.stepSpan.SetTag("queue_wait", time.Since(c.starttime))
+	defer c.stepSpan.Finish()
 
 	for ns, tags := range c.tags {
 		for k, v := range tags {
@@ -250,6 +332,8 @@ func (c *collectorJob) Run() {
 	}).Debug("collector run completed")
 
 	c.metrics = ret
+	c.stepSpan.SetTag("metrics", len(ret))
+	c.stepSpan.SetError(errs)
 	if errs != nil {
 		for _, e := range errs {
 			log.WithFields(log.Fields{
@@ -279,7 +363,7 @@ func newProcessJob(parentJob job, pluginName string, pluginVersion int, contentT
 	return &processJob{
 		parentJob: parentJob,
 		metrics:   []core.Metric{},
-		coreJob:   newCoreJob(processJobType, parentJob.Deadline(), taskID, pluginName, pluginVersion),
+		coreJob:   newCoreJob(processJobType, parentJob.Deadline(), taskID, pluginName, pluginVersion).inherit(parentJob),
 		config:    config,
 		processor: processor,
 	}
@@ -328,7 +412,7 @@ func newPublishJob(parentJob job, pluginName string, pluginVersion int, contentT
 	return &publisherJob{
 		parentJob: parentJob,
 		publisher: publisher,
-		coreJob:   newCoreJob(publishJobType, parentJob.Deadline(), taskID, pluginName, pluginVersion),
+		coreJob:   newCoreJob(publishJobType, parentJob.Deadline(), taskID, pluginName, pluginVersion).inherit(parentJob),
 		config:    config,
 	}
 }
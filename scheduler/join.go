@@ -0,0 +1,150 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/cdata"
+	"github.com/intelsdi-x/snap/scheduler/wmap"
+)
+
+// collectStep is a step of a workflow collecting metrics, the metrics of all
+// the steps of a workflow are merged before being processed and published
+type collectStep struct {
+	metrics    []core.RequestedMetric
+	configTree *cdata.ConfigDataTree
+	tags       map[string]map[string]string
+}
+
+// convertCollectSteps returns the collect step of the collect node followed by
+// the steps joined to it
+func convertCollectSteps(cnode *wmap.CollectWorkflowMapNode) ([]*collectStep, error) {
+	nodes := []*wmap.CollectWorkflowMapNode{cnode}
+	for i := range cnode.Join {
+		jnode := &cnode.Join[i]
+		if len(jnode.Process) > 0 || len(jnode.Publish) > 0 || len(jnode.Join) > 0 {
+			return nil, ErrJoinedCollectNodeChildren
+		}
+		if len(jnode.Metrics) < 1 {
+			return nil, ErrNoMetricsInCollectNode
+		}
+		nodes = append(nodes, jnode)
+	}
+	steps := make([]*collectStep, len(nodes))
+	for i, n := range nodes {
+		// Get core.RequestedMetric metrics
+		mts := n.GetMetrics()
+		metrics := make([]core.RequestedMetric, len(mts))
+		for j, m := range mts {
+			metrics[j] = &metric{namespace: core.NewNamespace(m.Namespace()...), version: m.Version()}
+		}
+		// Get our config data tree
+		cdt, err := n.GetConfigTree()
+		if err != nil {
+			return nil, err
+		}
+		steps[i] = &collectStep{
+			metrics:    metrics,
+			configTree: cdt,
+			tags:       n.GetTags(),
+		}
+	}
+	return steps, nil
+}
+
+// joinCollectNodes returns a collect node holding the configs and the tags of
+// the collect node and of the nodes joined to it
+func joinCollectNodes(cnode *wmap.CollectWorkflowMapNode) *wmap.CollectWorkflowMapNode {
+	joined := &wmap.CollectWorkflowMapNode{
+		Config: map[string]map[string]interface{}{},
+		Tags:   map[string]map[string]string{},
+	}
+	for _, n := range append([]wmap.CollectWorkflowMapNode{*cnode}, cnode.Join...) {
+		for ns, cfg := range n.Config {
+			if joined.Config[ns] == nil {
+				joined.Config[ns] = map[string]interface{}{}
+			}
+			for k, v := range cfg {
+				joined.Config[ns][k] = v
+			}
+		}
+		for ns, tags := range n.Tags {
+			if joined.Tags[ns] == nil {
+				joined.Tags[ns] = map[string]string{}
+			}
+			for k, v := range tags {
+				joined.Tags[ns][k] = v
+			}
+		}
+	}
+	return joined
+}
+
+// joinStep returns the name of a joined collect step in the records of the runs
+func joinStep(i int) string {
+	return fmt.Sprintf("%s:join:%d", collectorStep, i)
+}
+
+// collect runs the collect steps of the workflow concurrently and returns the
+// collector job of the first step holding the metrics of all the steps.  The
+// errors of all the steps are returned.
+func (s *schedulerWorkflow) collect(t *task, deadline time.Duration, rec *runRecorder) (*collectorJob, []error) {
+	steps := s.collectSteps
+	if len(steps) == 0 {
+		steps = []*collectStep{{metrics: s.metrics, configTree: s.configTree, tags: s.tags}}
+	}
+	jobs := make([]*collectorJob, len(steps))
+	queued := make([]queuedJob, len(steps))
+	for i, step := range steps {
+		jobs[i] = newCollectorJob(step.metrics, deadline, t.metricsManager, step.configTree, t.id, step.tags).(*collectorJob)
+		jobs[i].setPriority(t.priority)
+		jobs[i].run = rec
+		jobs[i].stepSpan = rec.runSpan().StartChild("collect")
+		jobs[i].stepSpan.SetTag("task_id", t.id)
+		jobs[i].stepSpan.SetTag("requested_metrics", len(step.metrics))
+		queued[i] = t.manager.Work(jobs[i])
+	}
+	var errs []error
+	for i, qj := range queued {
+		stepErrs := qj.Promise().Await()
+		// the span of a job which wasn't run ends once it's awaited
+		jobs[i].stepSpan.SetError(stepErrs)
+		jobs[i].stepSpan.Finish()
+		step := collectorStep
+		if i > 0 {
+			step = joinStep(i)
+		}
+		rec.add(step, len(jobs[i].Metrics()), stepErrs)
+		errs = append(errs, stepErrs...)
+	}
+	j := jobs[0]
+	for _, jj := range jobs[1:] {
+		j.metrics = append(j.metrics, jj.metrics...)
+	}
+	// the spans of the steps following joined steps are the children of the
+	// span of the run
+	if len(jobs) > 1 {
+		j.stepSpan = nil
+	}
+	return j, errs
+}
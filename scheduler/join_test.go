@@ -0,0 +1,84 @@
+// +build legacy
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/scheduler/wmap"
+)
+
+func TestJoinCollectSteps(t *testing.T) {
+	newWmap := func() *wmap.WorkflowMap {
+		w := wmap.NewWorkflowMap()
+		w.Collect.AddMetric("/intel/mock/foo", 1)
+		w.Collect.AddConfigItem("/intel/mock", "password", "secret")
+		w.Collect.Tags = map[string]map[string]string{"/intel/mock": {"env": "prod"}}
+		join := wmap.NewCollectWorkflowMapNode()
+		join.AddMetric("/intel/other/bar", 2)
+		join.AddConfigItem("/intel/other", "user", "root")
+		w.Collect.Join = append(w.Collect.Join, *join)
+		w.Collect.Add(wmap.NewPublishNode("file", 1))
+		return w
+	}
+	Convey("A workflow joining collect steps", t, func() {
+		Convey("has a collect step per collect node", func() {
+			wf, err := wmapToWorkflow(newWmap())
+			So(err, ShouldBeNil)
+			So(wf.collectSteps, ShouldHaveLength, 2)
+			So(wf.collectSteps[0].metrics[0].Namespace(), ShouldResemble, core.NewNamespace("intel", "mock", "foo"))
+			So(wf.collectSteps[1].metrics[0].Namespace(), ShouldResemble, core.NewNamespace("intel", "other", "bar"))
+			So(wf.collectSteps[1].configTree.Get([]string{"intel", "mock"}), ShouldBeNil)
+		})
+		Convey("subscribes to the metrics of all the steps with their configs", func() {
+			wf, err := wmapToWorkflow(newWmap())
+			So(err, ShouldBeNil)
+			So(wf.metrics, ShouldHaveLength, 2)
+			So(wf.configTree.Get([]string{"intel", "mock"}), ShouldNotBeNil)
+			So(wf.configTree.Get([]string{"intel", "other"}), ShouldNotBeNil)
+			So(wf.tags, ShouldResemble, map[string]map[string]string{"/intel/mock": {"env": "prod"}})
+		})
+		Convey("returns an error when a joined step has process or publish nodes", func() {
+			w := newWmap()
+			w.Collect.Join[0].Add(wmap.NewPublishNode("file", 1))
+			_, err := wmapToWorkflow(w)
+			So(err, ShouldEqual, ErrJoinedCollectNodeChildren)
+		})
+		Convey("returns an error when a joined step has no metrics", func() {
+			w := newWmap()
+			w.Collect.Join = append(w.Collect.Join, *wmap.NewCollectWorkflowMapNode())
+			_, err := wmapToWorkflow(w)
+			So(err, ShouldEqual, ErrNoMetricsInCollectNode)
+		})
+	})
+	Convey("A workflow without joined collect steps", t, func() {
+		w := newWmap()
+		w.Collect.Join = nil
+		wf, err := wmapToWorkflow(w)
+		So(err, ShouldBeNil)
+		So(wf.collectSteps, ShouldHaveLength, 1)
+		So(wf.configTree, ShouldEqual, wf.collectSteps[0].configTree)
+	})
+}
@@ -22,11 +22,17 @@ package scheduler
 import (
 	"errors"
 	"sync"
+	"time"
 )
 
 var (
 	errQueueEmpty    = errors.New("queue empty")
 	errLimitExceeded = errors.New("limit exceeded")
+
+	// priorityAgingInterval is the time after which a queued job is raised by
+	// one priority level, so the jobs of the tasks with a low priority aren't
+	// starved by the jobs of the tasks with a higher priority
+	priorityAgingInterval = time.Second
 )
 
 type jobHandler func(queuedJob)
@@ -38,9 +44,26 @@ type queue struct {
 	handler jobHandler
 	limit   uint
 	kill    chan struct{}
-	items   []queuedJob
+	items   []queueItem
 	mutex   *sync.Mutex
 	status  queueStatus
+
+	// the time waited by the jobs dispatched since the latency was last taken
+	waited     time.Duration
+	dispatched int64
+}
+
+// queueItem is a job waiting in the queue
+type queueItem struct {
+	job      queuedJob
+	priority int
+	queued   time.Time
+}
+
+// level returns the priority level of the job raised by the time it's been
+// waiting for
+func (i queueItem) level(now time.Time) int {
+	return i.priority + int(now.Sub(i.queued)/priorityAgingInterval)
 }
 
 type queueStatus int
@@ -68,7 +91,7 @@ func newQueue(limit uint, handler jobHandler) *queue {
 		handler: handler,
 		limit:   limit,
 		kill:    make(chan struct{}),
-		items:   []queuedJob{},
+		items:   []queueItem{},
 		mutex:   &sync.Mutex{},
 		status:  queueStopped,
 	}
@@ -152,7 +175,12 @@ func (q *queue) handle() {
 			q.mutex.Unlock()
 			return
 		}
-		q.handler(item)
+		// the handler returns once a worker took the job
+		q.handler(item.job)
+		q.mutex.Lock()
+		q.waited += time.Since(item.queued)
+		q.dispatched++
+		q.mutex.Unlock()
 	}
 }
 
@@ -160,31 +188,64 @@ func (q *queue) length() int {
 	return len(q.items)
 }
 
+// depth returns the number of queued jobs, it's safe to call outside the queue
+func (q *queue) depth() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.length()
+}
+
+// takeLatency returns the average time the jobs dispatched since the previous
+// call waited for a worker, it's safe to call outside the queue
+func (q *queue) takeLatency() time.Duration {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	var latency time.Duration
+	if q.dispatched > 0 {
+		latency = q.waited / time.Duration(q.dispatched)
+	}
+	q.waited, q.dispatched = 0, 0
+	return latency
+}
+
 func (q *queue) push(j queuedJob) error {
 
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
 
 	if q.limit == 0 || uint(q.length())+1 <= q.limit {
-		q.items = append(q.items, j)
+		item := queueItem{job: j, priority: normalPriority, queued: time.Now()}
+		if p, ok := j.Job().(prioritizedJob); ok {
+			item.priority = p.Priority()
+		}
+		q.items = append(q.items, item)
 		return nil
 	}
 	return errLimitExceeded
 }
 
-func (q *queue) pop() (queuedJob, error) {
+func (q *queue) pop() (queueItem, error) {
 
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
 
-	var j queuedJob
+	var j queueItem
 
 	if q.length() == 0 {
 		return j, errQueueEmpty
 	}
 
-	j = q.items[0]
-	q.items = q.items[1:]
+	// the jobs are popped by priority level, in the order they were queued
+	// for the same level
+	next := 0
+	now := time.Now()
+	for i := 1; i < len(q.items); i++ {
+		if q.items[i].level(now) > q.items[next].level(now) {
+			next = i
+		}
+	}
+	j = q.items[next]
+	q.items = append(q.items[:next], q.items[next+1:]...)
 
 	return j, nil
 }
@@ -27,6 +27,7 @@ import (
 	"time"
 
 	log "github.com/Sirupsen/logrus"
+	"github.com/intelsdi-x/snap/core"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
@@ -80,6 +81,66 @@ func TestQueue(t *testing.T) {
 		q.Stop()
 	})
 
+	Convey("it works the jobs of the tasks with a higher priority first", t, func() {
+		x := []int{}
+		started := make(chan struct{})
+		release := make(chan struct{})
+		q := newQueue(5, func(j queuedJob) {
+			if len(x) == 0 {
+				// keep the following jobs queued
+				close(started)
+				<-release
+			}
+			x = append(x, j.Job().(prioritizedJob).Priority())
+			j.Promise().Complete([]error{})
+		})
+		q.Start()
+
+		var wg sync.WaitGroup
+		priorities := []string{core.TaskPriorityNormal, core.TaskPriorityLow, core.TaskPriorityNormal, core.TaskPriorityHigh}
+		wg.Add(len(priorities))
+		for i, p := range priorities {
+			j := &collectorJob{coreJob: &coreJob{}}
+			j.setPriority(p)
+			qj := newQueuedJob(j)
+			qj.Promise().AndThen(func(errors []error) { wg.Done() })
+			q.Event <- qj
+			if i == 0 {
+				<-started
+			}
+		}
+		// wait for the jobs to be queued
+		for queued := 0; queued < len(priorities)-1; time.Sleep(time.Millisecond) {
+			q.mutex.Lock()
+			queued = q.length()
+			q.mutex.Unlock()
+		}
+		close(release)
+		wg.Wait()
+
+		So(x, ShouldResemble, []int{normalPriority, highPriority, normalPriority, lowPriority})
+		q.Stop()
+	})
+
+	Convey("it raises the priority of the jobs waiting in the queue", t, func() {
+		interval := priorityAgingInterval
+		priorityAgingInterval = 50 * time.Millisecond
+		defer func() { priorityAgingInterval = interval }()
+
+		q := newQueue(5, func(queuedJob) {})
+		for _, p := range []string{core.TaskPriorityLow, core.TaskPriorityHigh} {
+			j := &collectorJob{coreJob: &coreJob{}}
+			j.setPriority(p)
+			So(q.push(newQueuedJob(j)), ShouldBeNil)
+			if p == core.TaskPriorityLow {
+				time.Sleep(3 * priorityAgingInterval)
+			}
+		}
+		j, err := q.pop()
+		So(err, ShouldBeNil)
+		So(j.job.Job().(prioritizedJob).Priority(), ShouldEqual, lowPriority)
+	})
+
 	Convey("it sends an error if the queue bound is exceeded", t, func() {
 		q := newQueue(3, func(queuedJob) { time.Sleep(1 * time.Second) })
 		q.Start()
@@ -0,0 +1,197 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/scheduler/wmap"
+)
+
+// The overflow policies of the rate limit of a workflow step
+const (
+	// RateLimitBlock - the job waits until the metrics are within the limit
+	RateLimitBlock = "block"
+	// RateLimitDropNewest - the newest metrics over the limit are dropped
+	RateLimitDropNewest = "drop-newest"
+	// RateLimitDropOldest - the oldest metrics over the limit are dropped
+	RateLimitDropOldest = "drop-oldest"
+)
+
+var (
+	// ErrInvalidRateLimit - The error message for when the rate limit of a workflow step has neither a metrics nor a bytes limit
+	ErrInvalidRateLimit = errors.New("Rate limit of a workflow step needs metrics or bytes per second.")
+	// ErrInvalidRateLimitRate - The error message for when a limit of the rate limit of a workflow step is negative
+	ErrInvalidRateLimitRate = errors.New("Rate limit of a workflow step can't be negative.")
+	// ErrInvalidRateLimitOverflow - The error message for when the overflow policy of a rate limit is unknown
+	ErrInvalidRateLimitOverflow = errors.New("Rate limit overflow of a workflow step must be one of 'block', 'drop-newest' or 'drop-oldest'.")
+)
+
+// rateLimit caps the metrics and the bytes of metrics published by a workflow
+// step per second.  Each limit is a bucket holding a second worth of tokens,
+// refilled as time passes.  The metrics over the limit are dropped, newest or
+// oldest first, or the job waits until the bucket is refilled.
+type rateLimit struct {
+	sync.Mutex
+	metricsRate float64
+	bytesRate   float64
+	overflow    string
+
+	metricTokens float64
+	byteTokens   float64
+	last         time.Time
+}
+
+// newRateLimit returns the rate limit of a workflow step, nil when the step
+// has none
+func newRateLimit(r *wmap.RateLimitWorkflowMapNode) (*rateLimit, error) {
+	if r == nil {
+		return nil, nil
+	}
+	if r.MetricsPerSecond < 0 || r.BytesPerSecond < 0 {
+		return nil, ErrInvalidRateLimitRate
+	}
+	if r.MetricsPerSecond == 0 && r.BytesPerSecond == 0 {
+		return nil, ErrInvalidRateLimit
+	}
+	l := &rateLimit{
+		metricsRate: float64(r.MetricsPerSecond),
+		bytesRate:   float64(r.BytesPerSecond),
+		overflow:    RateLimitBlock,
+	}
+	switch r.Overflow {
+	case "":
+	case RateLimitBlock, RateLimitDropNewest, RateLimitDropOldest:
+		l.overflow = r.Overflow
+	default:
+		return nil, ErrInvalidRateLimitOverflow
+	}
+	l.metricTokens = l.metricsRate
+	l.byteTokens = l.bytesRate
+	return l, nil
+}
+
+// take takes the tokens of the metrics of a job, it returns the metrics to
+// publish and the time to wait before publishing them
+func (l *rateLimit) take(metrics []core.Metric, now time.Time) ([]core.Metric, time.Duration) {
+	l.Lock()
+	defer l.Unlock()
+	l.refill(now)
+	sizes := make([]float64, len(metrics))
+	if l.bytesRate > 0 {
+		for i, m := range metrics {
+			sizes[i] = metricSize(m)
+		}
+	}
+	if l.overflow == RateLimitBlock {
+		// the tokens are borrowed, the job waits until they're paid back
+		for _, size := range sizes {
+			l.metricTokens--
+			l.byteTokens -= size
+		}
+		var wait time.Duration
+		if l.metricsRate > 0 && l.metricTokens < 0 {
+			wait = time.Duration(-l.metricTokens / l.metricsRate * float64(time.Second))
+		}
+		if l.bytesRate > 0 && l.byteTokens < 0 {
+			if w := time.Duration(-l.byteTokens / l.bytesRate * float64(time.Second)); w > wait {
+				wait = w
+			}
+		}
+		return metrics, wait
+	}
+	// the metrics are kept in the order of their timestamps, newest first
+	// when the oldest are dropped
+	order := make([]int, len(metrics))
+	for i := range order {
+		order[i] = i
+	}
+	if l.overflow == RateLimitDropOldest {
+		sort.Stable(newestFirst{order: order, metrics: metrics})
+	}
+	kept := make([]bool, len(metrics))
+	n := 0
+	for _, i := range order {
+		if (l.metricsRate > 0 && l.metricTokens < 1) || (l.bytesRate > 0 && l.byteTokens < sizes[i]) {
+			break
+		}
+		l.metricTokens--
+		l.byteTokens -= sizes[i]
+		kept[i] = true
+		n++
+	}
+	if n == len(metrics) {
+		return metrics, 0
+	}
+	mts := make([]core.Metric, 0, n)
+	for i, m := range metrics {
+		if kept[i] {
+			mts = append(mts, m)
+		}
+	}
+	return mts, 0
+}
+
+// refill adds the tokens of the time passed since the last refill, the
+// buckets hold at most a second worth of tokens
+func (l *rateLimit) refill(now time.Time) {
+	if !l.last.IsZero() {
+		elapsed := now.Sub(l.last).Seconds()
+		if elapsed > 0 {
+			l.metricTokens += elapsed * l.metricsRate
+			if l.metricTokens > l.metricsRate {
+				l.metricTokens = l.metricsRate
+			}
+			l.byteTokens += elapsed * l.bytesRate
+			if l.byteTokens > l.bytesRate {
+				l.byteTokens = l.bytesRate
+			}
+		}
+	}
+	if now.After(l.last) {
+		l.last = now
+	}
+}
+
+// newestFirst sorts the indexes of metrics, newest first
+type newestFirst struct {
+	order   []int
+	metrics []core.Metric
+}
+
+func (n newestFirst) Len() int      { return len(n.order) }
+func (n newestFirst) Swap(i, j int) { n.order[i], n.order[j] = n.order[j], n.order[i] }
+func (n newestFirst) Less(i, j int) bool {
+	return n.metrics[n.order[i]].Timestamp().After(n.metrics[n.order[j]].Timestamp())
+}
+
+// metricSize returns the size of the metric in its persisted form
+func metricSize(m core.Metric) float64 {
+	b, err := json.Marshal(storedMetrics([]core.Metric{m}))
+	if err != nil {
+		return 0
+	}
+	return float64(len(b))
+}
@@ -0,0 +1,98 @@
+// +build legacy
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/scheduler/wmap"
+)
+
+func TestRateLimit(t *testing.T) {
+	Convey("newRateLimit", t, func() {
+		Convey("returns no rate limit without a rate limit node", func() {
+			l, err := newRateLimit(nil)
+			So(err, ShouldBeNil)
+			So(l, ShouldBeNil)
+		})
+		Convey("defaults the overflow to block", func() {
+			l, err := newRateLimit(&wmap.RateLimitWorkflowMapNode{MetricsPerSecond: 10})
+			So(err, ShouldBeNil)
+			So(l.overflow, ShouldEqual, RateLimitBlock)
+		})
+		Convey("returns an error for invalid limits or overflow", func() {
+			_, err := newRateLimit(&wmap.RateLimitWorkflowMapNode{})
+			So(err, ShouldEqual, ErrInvalidRateLimit)
+			_, err = newRateLimit(&wmap.RateLimitWorkflowMapNode{BytesPerSecond: -1})
+			So(err, ShouldEqual, ErrInvalidRateLimitRate)
+			_, err = newRateLimit(&wmap.RateLimitWorkflowMapNode{MetricsPerSecond: 1, Overflow: "drop-all"})
+			So(err, ShouldEqual, ErrInvalidRateLimitOverflow)
+		})
+	})
+	Convey("A rate limit", t, func() {
+		now := time.Now()
+		mts := []core.Metric{
+			plugin.MetricType{Namespace_: core.NewNamespace("foo", "a"), Data_: 1, Timestamp_: now.Add(-2 * time.Second)},
+			plugin.MetricType{Namespace_: core.NewNamespace("foo", "b"), Data_: 2, Timestamp_: now},
+			plugin.MetricType{Namespace_: core.NewNamespace("foo", "c"), Data_: 3, Timestamp_: now.Add(-time.Second)},
+		}
+		Convey("drops the newest metrics over the limit", func() {
+			l, err := newRateLimit(&wmap.RateLimitWorkflowMapNode{MetricsPerSecond: 2, Overflow: RateLimitDropNewest})
+			So(err, ShouldBeNil)
+			kept, wait := l.take(mts, now)
+			So(wait, ShouldEqual, 0)
+			So(kept, ShouldResemble, mts[:2])
+			kept, _ = l.take(mts, now)
+			So(kept, ShouldBeEmpty)
+			Convey("until the limit is refilled", func() {
+				kept, _ = l.take(mts, now.Add(500*time.Millisecond))
+				So(kept, ShouldResemble, mts[:1])
+			})
+		})
+		Convey("drops the oldest metrics over the limit", func() {
+			l, err := newRateLimit(&wmap.RateLimitWorkflowMapNode{MetricsPerSecond: 2, Overflow: RateLimitDropOldest})
+			So(err, ShouldBeNil)
+			kept, _ := l.take(mts, now)
+			So(kept, ShouldResemble, mts[1:])
+		})
+		Convey("limits the bytes of the metrics", func() {
+			l, err := newRateLimit(&wmap.RateLimitWorkflowMapNode{BytesPerSecond: int(metricSize(mts[0])), Overflow: RateLimitDropNewest})
+			So(err, ShouldBeNil)
+			kept, _ := l.take(mts, now)
+			So(kept, ShouldHaveLength, 1)
+		})
+		Convey("blocks until the metrics are within the limit", func() {
+			l, err := newRateLimit(&wmap.RateLimitWorkflowMapNode{MetricsPerSecond: 2})
+			So(err, ShouldBeNil)
+			kept, wait := l.take(mts, now)
+			So(kept, ShouldResemble, mts)
+			So(wait, ShouldEqual, 500*time.Millisecond)
+			_, wait = l.take(mts[:1], now.Add(500*time.Millisecond))
+			So(wait, ShouldEqual, 500*time.Millisecond)
+		})
+	})
+}
@@ -0,0 +1,199 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/scheduler/wmap"
+)
+
+var (
+	// DefaultRetryBackoff - The default time waited before the first retry of a job
+	DefaultRetryBackoff = time.Millisecond * 100
+	// DefaultRetryMaxBackoff - The default longest time waited between two retries of a job
+	DefaultRetryMaxBackoff = time.Second * 5
+
+	// ErrInvalidRetryCount - The error message for when the retry count of a workflow step is negative
+	ErrInvalidRetryCount = errors.New("Retry count of a workflow step can't be negative.")
+	// ErrInvalidRetryBackoff - The error message for when the backoff of a workflow step isn't a positive duration
+	ErrInvalidRetryBackoff = errors.New("Retry backoff of a workflow step must be a positive duration.")
+	// ErrInvalidStepTimeout - The error message for when the timeout of a workflow step isn't a positive duration
+	ErrInvalidStepTimeout = errors.New("Timeout of a workflow step must be a positive duration.")
+	// ErrStepTimeout - The error message for when a job of a workflow step isn't done within the timeout of the step
+	ErrStepTimeout = errors.New("Workflow step timed out.")
+)
+
+// retryPolicy holds the retries of a failed job of a workflow step and the
+// retry stats of the step
+type retryPolicy struct {
+	count      int
+	backoff    time.Duration
+	maxBackoff time.Duration
+
+	// updated atomically, the steps of a workflow are run concurrently
+	retries   uint64
+	recovered uint64
+	exhausted uint64
+}
+
+// newRetryPolicy returns the retry policy of a workflow step, nil when the
+// step isn't retried
+func newRetryPolicy(r *wmap.RetryWorkflowMapNode) (*retryPolicy, error) {
+	if r == nil || r.Count == 0 {
+		return nil, nil
+	}
+	if r.Count < 0 {
+		return nil, ErrInvalidRetryCount
+	}
+	p := &retryPolicy{
+		count:      r.Count,
+		backoff:    DefaultRetryBackoff,
+		maxBackoff: DefaultRetryMaxBackoff,
+	}
+	var err error
+	if r.Backoff != "" {
+		if p.backoff, err = time.ParseDuration(r.Backoff); err != nil || p.backoff <= 0 {
+			return nil, ErrInvalidRetryBackoff
+		}
+	}
+	if r.MaxBackoff != "" {
+		if p.maxBackoff, err = time.ParseDuration(r.MaxBackoff); err != nil || p.maxBackoff <= 0 {
+			return nil, ErrInvalidRetryBackoff
+		}
+	}
+	if p.maxBackoff < p.backoff {
+		p.maxBackoff = p.backoff
+	}
+	return p, nil
+}
+
+// newStepTimeout returns the timeout of a workflow step, zero when the jobs
+// of the step are waited for until they're done
+func newStepTimeout(timeout string) (time.Duration, error) {
+	if timeout == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(timeout)
+	if err != nil || d <= 0 {
+		return 0, ErrInvalidStepTimeout
+	}
+	return d, nil
+}
+
+// awaitJob blocks until the queued job is done or the timeout passed, the job
+// left running past its timeout is failed with ErrStepTimeout
+func awaitJob(qj queuedJob, timeout time.Duration) []error {
+	p := qj.Promise()
+	if timeout <= 0 {
+		return p.Await()
+	}
+	p.AwaitUntil(timeout)
+	if !p.IsComplete() {
+		return []error{ErrStepTimeout}
+	}
+	return p.Await()
+}
+
+// wait returns the time waited before the given retry (starting at 1)
+func (p *retryPolicy) wait(retry int) time.Duration {
+	d := p.backoff
+	for i := 1; i < retry && d < p.maxBackoff; i++ {
+		d *= 2
+	}
+	if d > p.maxBackoff {
+		d = p.maxBackoff
+	}
+	return d
+}
+
+// work submits the job returned by newJob until it succeeds or its retries are
+// exhausted, the returned errors are the ones of the last attempt.  A job is
+// not retried past the deadline of the run.  Each attempt is waited for up to
+// the timeout of the step.
+func (p *retryPolicy) work(t *task, timeout time.Duration, newJob func() job) (job, []error) {
+	j := newJob()
+	errs := awaitJob(t.manager.Work(j), timeout)
+	if p == nil || len(errs) == 0 {
+		return j, errs
+	}
+	for retry := 1; retry <= p.count; retry++ {
+		wait := p.wait(retry)
+		if time.Now().Add(wait).After(j.Deadline()) {
+			break
+		}
+		workflowLogger.WithFields(log.Fields{
+			"_block":      "retry-job",
+			"task-id":     t.id,
+			"task-name":   t.name,
+			"plugin-name": j.Name(),
+			"job-type":    j.TypeString(),
+			"retry":       retry,
+			"backoff":     wait,
+			"error":       errs[len(errs)-1],
+		}).Debug("Retrying failed job")
+		time.Sleep(wait)
+		atomic.AddUint64(&p.retries, 1)
+		j = newJob()
+		if errs = awaitJob(t.manager.Work(j), timeout); len(errs) == 0 {
+			atomic.AddUint64(&p.recovered, 1)
+			return j, nil
+		}
+	}
+	atomic.AddUint64(&p.exhausted, 1)
+	return j, errs
+}
+
+func (p *retryPolicy) stats(typ core.PluginType, name string, version int) core.TaskStepRetryStats {
+	return core.TaskStepRetryStats{
+		Step:      fmt.Sprintf("%s:%s:%d", typ, name, version),
+		Retries:   uint(atomic.LoadUint64(&p.retries)),
+		Recovered: uint(atomic.LoadUint64(&p.recovered)),
+		Exhausted: uint(atomic.LoadUint64(&p.exhausted)),
+	}
+}
+
+// RetryStats returns the retry stats of the workflow steps of the task which
+// are retried, in the order of the workflow
+func (t *task) RetryStats() []core.TaskStepRetryStats {
+	var stats []core.TaskStepRetryStats
+	var walk func(prs []*processNode, pus []*publishNode)
+	walk = func(prs []*processNode, pus []*publishNode) {
+		for _, pr := range prs {
+			if pr.retry != nil {
+				stats = append(stats, pr.retry.stats(core.ProcessorPluginType, pr.name, pr.version))
+			}
+			walk(pr.ProcessNodes, pr.PublishNodes)
+		}
+		for _, pu := range pus {
+			if pu.retry != nil {
+				stats = append(stats, pu.retry.stats(core.PublisherPluginType, pu.name, pu.version))
+			}
+		}
+	}
+	walk(t.workflow.processNodes, t.workflow.publishNodes)
+	return stats
+}
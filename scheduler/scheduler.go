@@ -24,9 +24,10 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
-	"path"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	log "github.com/Sirupsen/logrus"
@@ -38,10 +39,12 @@ import (
 	"github.com/intelsdi-x/snap/control/plugin"
 	"github.com/intelsdi-x/snap/core"
 	"github.com/intelsdi-x/snap/core/cdata"
+	"github.com/intelsdi-x/snap/core/control_event"
 	"github.com/intelsdi-x/snap/core/ctypes"
 	"github.com/intelsdi-x/snap/core/scheduler_event"
 	"github.com/intelsdi-x/snap/core/serror"
 	"github.com/intelsdi-x/snap/pkg/schedule"
+	"github.com/intelsdi-x/snap/pkg/tracing"
 	"github.com/intelsdi-x/snap/scheduler/wmap"
 )
 
@@ -68,10 +71,28 @@ var (
 	ErrTaskDisabledNotStoppable = errors.New("Task is disabled. Only running tasks can be stopped.")
 	// ErrTaskEndedNotStoppable - The error message for when a task is ended and cannot be stopped
 	ErrTaskEndedNotStoppable = errors.New("Task is ended. Only running tasks can be stopped.")
+	// ErrTaskPausedNotRunnable - The error message for when a task is paused and cannot be started
+	ErrTaskPausedNotRunnable = errors.New("Task is paused. It must be resumed or stopped.")
+	// ErrTaskNotPausable - The error message for when a task which isn't running is paused
+	ErrTaskNotPausable = errors.New("Task is not running. It can't be paused.")
+	// ErrTaskNotPaused - The error message for when a task which isn't paused is resumed
+	ErrTaskNotPaused = errors.New("Task is not paused. Only paused tasks can be resumed.")
+	// ErrStreamingTaskNotPausable - The error message for when a streaming task is paused
+	ErrStreamingTaskNotPausable = errors.New("Streaming tasks can't be paused.")
 	// ErrPluginIncompatibleWithScheduleType - The error message for when a streaming schedule type references a non streaming plugin or vice versa.
 	ErrPluginIncompatibleWithScheduleType = errors.New("Plugin is incompatible with the tasks schedule type.")
 	// ErrMultipleStreamingPlugins - The error message when a task with a streaming schedule refers to multiple streaming plugins.
 	ErrMultipleStreamingPlugins = errors.New("Multiple streaming plugins within the same task is not supported.")
+	// ErrIntervalBelowMinimum - The error message when a task collects a metric more often than its minimum collection interval allows.
+	ErrIntervalBelowMinimum = errors.New("Task interval is shorter than the minimum collection interval of a metric.")
+	// ErrTaskDependencyNotFound - The error message when a task depends on a task which doesn't exist.
+	ErrTaskDependencyNotFound = errors.New("Task depends on a task which doesn't exist.")
+	// ErrTaskDependencyCycle - The error message when the dependencies of a task form a cycle.
+	ErrTaskDependencyCycle = errors.New("Task dependencies form a cycle.")
+	// ErrTaskDependencyStreaming - The error message when a streaming task depends on a task or a task depends on a streaming task.
+	ErrTaskDependencyStreaming = errors.New("Streaming tasks can't depend on tasks or be depended on.")
+	// ErrStreamingTaskJoin - The error message when a task with a streaming schedule joins collect steps.
+	ErrStreamingTaskJoin = errors.New("Joining collect steps within a streaming task is not supported.")
 )
 
 type schedulerState int
@@ -103,6 +124,18 @@ type managesMetrics interface {
 	UnsubscribeDeps(string) []serror.SnapError
 }
 
+// intervalsMetrics is implemented by the metric managers exposing the minimum
+// collection interval of the metrics
+type intervalsMetrics interface {
+	MinCollectionIntervals([]core.RequestedMetric) map[string]time.Duration
+}
+
+// sealsConfig is implemented by the metric managers encrypting the sensitive
+// values of the config of the tasks
+type sealsConfig interface {
+	SealConfig(map[string]interface{}) error
+}
+
 type collectsMetrics interface {
 	CollectMetrics(string, map[string]map[string]string) ([]core.Metric, []error)
 }
@@ -126,6 +159,22 @@ type scheduler struct {
 	state           schedulerState
 	eventManager    *gomit.EventController
 	taskWatcherColl *taskWatcherCollection
+	// the policy applied to tasks collecting metrics more often than allowed
+	minIntervalPolicy string
+	// persists the tasks when a task store is set
+	taskStore *taskStore
+	// the persisted tasks waiting for their plugins to be restored
+	pendingTasks []storedTask
+	pendingMutex sync.Mutex
+	restoreMutex sync.Mutex
+	// spools the failed publishes when a dead letter queue is set
+	deadLetters *deadLetterQueue
+	// the directory the buffers of the tasks are stored in
+	bufferPath string
+	// serializes the bulk task operations
+	bulkMutex sync.Mutex
+	// traces the runs of the workflows when it's set
+	tracer *tracing.Tracer
 }
 
 type managesWork interface {
@@ -143,7 +192,7 @@ func autoDiscoverTasks(taskFiles []os.FileInfo, fullPath string,
 	// Note that the list of files is sorted by name due to ioutil.ReadDir
 	// default behaviour. See go doc ioutil.ReadDir
 	for _, file := range taskFiles {
-		f, err := os.Open(path.Join(fullPath, file.Name()))
+		f, err := os.Open(filepath.Join(fullPath, file.Name()))
 		if err != nil {
 			log.WithFields(log.Fields{
 				"_block":           "autoDiscoverTasks",
@@ -246,25 +295,36 @@ func New(cfg *Config) *scheduler {
 		"value":  cfg.WorkManagerQueueSize,
 	}).Info("Setting work manager queue size")
 	schedulerLogger.WithFields(log.Fields{
-		"_block": "New",
-		"value":  cfg.WorkManagerPoolSize,
+		"_block":  "New",
+		"collect": cfg.poolSize(cfg.WorkManagerCollectPoolSize),
+		"process": cfg.poolSize(cfg.WorkManagerProcessPoolSize),
+		"publish": cfg.poolSize(cfg.WorkManagerPublishPoolSize),
 	}).Info("Setting work manager pool size")
 	opts := []workManagerOption{
 		CollectQSizeOption(cfg.WorkManagerQueueSize),
-		CollectWkrSizeOption(cfg.WorkManagerPoolSize),
+		CollectWkrSizeOption(cfg.poolSize(cfg.WorkManagerCollectPoolSize)),
 		PublishQSizeOption(cfg.WorkManagerQueueSize),
-		PublishWkrSizeOption(cfg.WorkManagerPoolSize),
+		PublishWkrSizeOption(cfg.poolSize(cfg.WorkManagerPublishPoolSize)),
 		ProcessQSizeOption(cfg.WorkManagerQueueSize),
-		ProcessWkrSizeOption(cfg.WorkManagerPoolSize),
+		ProcessWkrSizeOption(cfg.poolSize(cfg.WorkManagerProcessPoolSize)),
+	}
+	if cfg.WorkManagerAutoscale {
+		schedulerLogger.WithFields(log.Fields{
+			"_block":            "New",
+			"max-pool-size":     cfg.WorkManagerMaxPoolSize,
+			"max-queue-latency": cfg.WorkManagerMaxQueueLatency.Duration.String(),
+		}).Info("Autoscaling work manager pools")
+		opts = append(opts, AutoscaleOption(true, cfg.WorkManagerMaxPoolSize, cfg.WorkManagerMaxQueueLatency.Duration))
 	}
 	s := &scheduler{
-		tasks:           newTaskCollection(),
-		eventManager:    gomit.NewEventController(),
-		taskWatcherColl: newTaskWatcherCollection(),
+		tasks:             newTaskCollection(),
+		eventManager:      gomit.NewEventController(),
+		taskWatcherColl:   newTaskWatcherCollection(),
+		minIntervalPolicy: cfg.MinIntervalPolicy,
+		bufferPath:        cfg.BufferPath,
 	}
 
-	// we are setting the size of the queue and number of workers for
-	// collect, process and publish consistently for now
+	// the size of the queue is the same for collect, process and publish
 	s.workManager = newWorkManager(opts...)
 	s.workManager.Start()
 	s.eventManager.RegisterHandler(HandlerRegistrationName, s)
@@ -293,6 +353,11 @@ func (s *scheduler) CreateTask(sch schedule.Schedule, wfMap *wmap.WorkflowMap, s
 	return s.createTask(sch, wfMap, startOnCreate, "user", opts...)
 }
 
+// createAutodiscoveredTask creates a task loaded from the auto discover path
+func (s *scheduler) createAutodiscoveredTask(sch schedule.Schedule, wfMap *wmap.WorkflowMap, startOnCreate bool, opts ...core.TaskOption) (core.Task, core.TaskErrors) {
+	return s.createTask(sch, wfMap, startOnCreate, "autodiscover", opts...)
+}
+
 func (s *scheduler) CreateTaskTribe(sch schedule.Schedule, wfMap *wmap.WorkflowMap, startOnCreate bool, opts ...core.TaskOption) (core.Task, core.TaskErrors) {
 	return s.createTask(sch, wfMap, startOnCreate, "tribe", opts...)
 }
@@ -324,6 +389,15 @@ func (s *scheduler) createTask(sch schedule.Schedule, wfMap *wmap.WorkflowMap, s
 		return nil, te
 	}
 
+	// Encrypt the sensitive values of the config before the workflow map is
+	// kept, the values are decrypted when they are given to the plugins
+	if err := sealWorkflowMap(s.metricManager, wfMap); err != nil {
+		te.errs = append(te.errs, serror.New(err))
+		f := buildErrorsLog(te.Errors(), logger)
+		f.Error("Unable to encrypt the sensitive values of the workflow map")
+		return nil, te
+	}
+
 	// Generate a workflow from the workflow map
 	wf, err := wmapToWorkflow(wfMap)
 	if err != nil {
@@ -332,6 +406,14 @@ func (s *scheduler) createTask(sch schedule.Schedule, wfMap *wmap.WorkflowMap, s
 		f.Error("Unable to generate workflow from workflow map")
 		return nil, te
 	}
+	if _, ok := sch.(*schedule.StreamingSchedule); ok && len(wf.collectSteps) > 1 {
+		te.errs = append(te.errs, serror.New(ErrStreamingTaskJoin))
+		f := buildErrorsLog(te.Errors(), logger)
+		f.Error(ErrStreamingTaskJoin.Error())
+		return nil, te
+	}
+
+	wf.tracer = s.tracer
 
 	// Create the task object
 	task, err := newTask(sch, wf, s.workManager, s.metricManager, s.eventManager, opts...)
@@ -385,21 +467,7 @@ func (s *scheduler) createTask(sch schedule.Schedule, wfMap *wmap.WorkflowMap, s
 			})
 		default:
 			// assert no streaming plugins
-			subscribedPluginAsserts = append(subscribedPluginAsserts, func(plugins []core.SubscribedPlugin) serror.SnapError {
-				for _, plg := range plugins {
-					if plg.TypeName() == plugin.StreamCollectorPluginType.String() {
-						return serror.New(
-							ErrPluginIncompatibleWithScheduleType,
-							map[string]interface{}{
-								"schedule_type": fmt.Sprintf("%T", sch),
-								"plugin_name":   plg.Name(),
-								"plugin_type":   plg.TypeName(),
-							},
-						)
-					}
-				}
-				return nil
-			})
+			subscribedPluginAsserts = append(subscribedPluginAsserts, noStreamingPluginsAssert(sch))
 		}
 
 		manager, err := task.RemoteManagers.Get(k)
@@ -414,6 +482,31 @@ func (s *scheduler) createTask(sch schedule.Schedule, wfMap *wmap.WorkflowMap, s
 			te.errs = append(te.errs, errs...)
 			return nil, te
 		}
+
+		if s.minIntervalPolicy == MinIntervalPolicyReject {
+			if errs := validateMinIntervals(sch, manager, group.requestedMetrics); len(errs) > 0 {
+				te.errs = append(te.errs, errs...)
+				return nil, te
+			}
+		}
+	}
+
+	if err := s.validateDependencies(task); err != nil {
+		te.errs = append(te.errs, err)
+		f := buildErrorsLog(te.Errors(), logger)
+		f.Error("invalid task dependencies")
+		return nil, te
+	}
+	task.tasks = s.tasks
+	task.source = source
+	task.deadLetters = s.deadLetters
+	if task.bufferConfig != nil {
+		if err := task.openBuffer(filepath.Join(s.bufferPath, task.id)); err != nil {
+			te.errs = append(te.errs, serror.New(err, map[string]interface{}{"buffer-path": s.bufferPath}))
+			f := buildErrorsLog(te.Errors(), logger)
+			f.Error("Unable to open the buffer of the task")
+			return nil, te
+		}
 	}
 
 	// Add task to taskCollection
@@ -433,6 +526,7 @@ func (s *scheduler) createTask(sch schedule.Schedule, wfMap *wmap.WorkflowMap, s
 		TaskID:        task.id,
 		StartOnCreate: startOnCreate,
 		Source:        source,
+		Labels:        task.Labels(),
 	}
 	defer s.eventManager.Emit(event)
 
@@ -447,10 +541,147 @@ func (s *scheduler) createTask(sch schedule.Schedule, wfMap *wmap.WorkflowMap, s
 			te.errs = append(te.errs, errs...)
 		}
 	}
+	s.persistTasks()
 
 	return task, te
 }
 
+// validateDependencies returns an error when a task the task depends on
+// doesn't exist or when the dependencies of the tasks form a cycle
+func (s *scheduler) validateDependencies(t *task) serror.SnapError {
+	if len(t.dependencies) > 0 && t.isStream {
+		return serror.New(ErrTaskDependencyStreaming, map[string]interface{}{"task-id": t.id})
+	}
+	for _, id := range t.dependencies {
+		if id == t.id {
+			return serror.New(ErrTaskDependencyCycle, map[string]interface{}{"dependency": id})
+		}
+		upstream := s.tasks.Get(id)
+		if upstream == nil {
+			return serror.New(ErrTaskDependencyNotFound, map[string]interface{}{"dependency": id})
+		}
+		if upstream.isStream {
+			return serror.New(ErrTaskDependencyStreaming, map[string]interface{}{"dependency": id})
+		}
+	}
+	// walk the graph of the dependencies, a task given the id of the new task
+	// (e.g. through tribe) may already be depended on
+	visited := map[string]bool{}
+	var walk func(ids []string) bool
+	walk = func(ids []string) bool {
+		for _, id := range ids {
+			if id == t.id {
+				return true
+			}
+			if visited[id] {
+				continue
+			}
+			visited[id] = true
+			if upstream := s.tasks.Get(id); upstream != nil && walk(upstream.dependencies) {
+				return true
+			}
+		}
+		return false
+	}
+	if walk(t.dependencies) {
+		return serror.New(ErrTaskDependencyCycle, map[string]interface{}{"task-id": t.id})
+	}
+	return nil
+}
+
+// noStreamingPluginsAssert returns an assert failing when the subscribed
+// plugins include a streaming collector, which the schedule can't run
+func noStreamingPluginsAssert(sch schedule.Schedule) core.SubscribedPluginAssert {
+	return func(plugins []core.SubscribedPlugin) serror.SnapError {
+		for _, plg := range plugins {
+			if plg.TypeName() == plugin.StreamCollectorPluginType.String() {
+				return serror.New(
+					ErrPluginIncompatibleWithScheduleType,
+					map[string]interface{}{
+						"schedule_type": fmt.Sprintf("%T", sch),
+						"plugin_name":   plg.Name(),
+						"plugin_type":   plg.TypeName(),
+					},
+				)
+			}
+		}
+		return nil
+	}
+}
+
+// sealWorkflowMap encrypts the sensitive values of the config of the nodes of
+// the workflow map when the metric manager encrypts them
+func sealWorkflowMap(manager managesMetrics, wfMap *wmap.WorkflowMap) error {
+	sc, ok := manager.(sealsConfig)
+	if !ok || wfMap.Collect == nil {
+		return nil
+	}
+	return sealCollectNode(sc, wfMap.Collect)
+}
+
+func sealCollectNode(sc sealsConfig, node *wmap.CollectWorkflowMapNode) error {
+	for _, config := range node.Config {
+		if err := sc.SealConfig(config); err != nil {
+			return err
+		}
+	}
+	for i := range node.Join {
+		if err := sealCollectNode(sc, &node.Join[i]); err != nil {
+			return err
+		}
+	}
+	return sealChildNodes(sc, node.Process, node.Publish)
+}
+
+func sealChildNodes(sc sealsConfig, process []wmap.ProcessWorkflowMapNode, publish []wmap.PublishWorkflowMapNode) error {
+	for _, pr := range process {
+		if err := sc.SealConfig(pr.Config); err != nil {
+			return err
+		}
+		if err := sealChildNodes(sc, pr.Process, pr.Publish); err != nil {
+			return err
+		}
+	}
+	for _, pu := range publish {
+		if err := sc.SealConfig(pu.Config); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateMinIntervals returns an error for each requested metric the schedule
+// collects more often than its minimum collection interval allows.  Only the
+// schedules firing at a fixed interval are validated, the collections of the
+// other schedules are down-sampled.
+func validateMinIntervals(sch schedule.Schedule, manager managesMetrics, requested []core.RequestedMetric) []serror.SnapError {
+	ws, ok := sch.(*schedule.WindowedSchedule)
+	if !ok {
+		return nil
+	}
+	im, ok := manager.(intervalsMetrics)
+	if !ok {
+		return nil
+	}
+	var errs []serror.SnapError
+	intervals := im.MinCollectionIntervals(requested)
+	namespaces := make([]string, 0, len(intervals))
+	for ns := range intervals {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+	for _, ns := range namespaces {
+		if ws.Interval < intervals[ns] {
+			errs = append(errs, serror.New(ErrIntervalBelowMinimum, map[string]interface{}{
+				"metric":                  ns,
+				"task-interval":           ws.Interval.String(),
+				"min-collection-interval": intervals[ns].String(),
+			}))
+		}
+	}
+	return errs
+}
+
 // RemoveTask given a tasks id.  The task must be stopped.
 // Can return errors ErrTaskNotFound and ErrTaskNotStopped.
 func (s *scheduler) RemoveTask(id string) error {
@@ -473,13 +704,60 @@ func (s *scheduler) removeTask(id, source string) error {
 		}).Error(ErrTaskNotFound)
 		return err
 	}
+	if err := s.tasks.remove(t); err != nil {
+		s.eventManager.Emit(&scheduler_event.TaskDeletedEvent{
+			TaskID: t.id,
+			Source: source,
+		})
+		return err
+	}
+	s.taskRemoved(t, source)
+	return nil
+}
+
+// taskRemoved drops the buffer of a task removed from the task collection
+// and persists the removal
+func (s *scheduler) taskRemoved(t *task, source string) {
+	logger := schedulerLogger.WithFields(log.Fields{
+		"_block": "remove-task",
+		"source": source,
+	})
 	event := &scheduler_event.TaskDeletedEvent{
 		TaskID: t.id,
 		Source: source,
 	}
-
 	defer s.eventManager.Emit(event)
-	return s.tasks.remove(t)
+	if t.buffer != nil {
+		if batches, _ := t.buffer.stats(); batches > 0 {
+			logger.WithFields(log.Fields{
+				"task-id":          t.id,
+				"buffered-batches": batches,
+			}).Warn("Dropping the buffered metrics of the removed task")
+		}
+		if err := t.buffer.destroy(); err != nil {
+			logger.WithFields(log.Fields{
+				"task-id": t.id,
+				"error":   err,
+			}).Error("Unable to remove the buffer of the task")
+		}
+	}
+	s.persistTasks()
+}
+
+// WorkerPoolStats returns the state of the collect, process and publish
+// worker pools
+func (s *scheduler) WorkerPoolStats() []core.WorkerPoolStats {
+	return s.workManager.stats()
+}
+
+// ResizeWorkerPools applies the sizes of the worker pools and their
+// autoscaling set in the configuration without stopping the tasks
+func (s *scheduler) ResizeWorkerPools(cfg *Config) {
+	s.workManager.resize(map[jobType]uint{
+		collectJobType: cfg.poolSize(cfg.WorkManagerCollectPoolSize),
+		processJobType: cfg.poolSize(cfg.WorkManagerProcessPoolSize),
+		publishJobType: cfg.poolSize(cfg.WorkManagerPublishPoolSize),
+	}, cfg.WorkManagerAutoscale, cfg.WorkManagerMaxPoolSize, cfg.WorkManagerMaxQueueLatency.Duration)
 }
 
 // GetTasks returns a copy of the tasks in a map where the task id is the key
@@ -551,6 +829,15 @@ func (s *scheduler) startTask(id, source string) []serror.SnapError {
 		}
 	}
 
+	if t.state == core.TaskPaused {
+		logger.WithFields(log.Fields{
+			"task-id": t.ID(),
+		}).Error("Task is paused and must be resumed or stopped before starting")
+		return []serror.SnapError{
+			serror.New(ErrTaskPausedNotRunnable),
+		}
+	}
+
 	// Ensure the schedule is valid at this point and time.
 	if err := t.schedule.Validate(); err != nil {
 		errs := []serror.SnapError{
@@ -576,6 +863,7 @@ func (s *scheduler) startTask(id, source string) []serror.SnapError {
 		"task-id":    t.ID(),
 		"task-state": t.State(),
 	}).Info("task started")
+	s.persistTasks()
 	return nil
 }
 
@@ -640,6 +928,67 @@ func (s *scheduler) stopTask(id, source string) []serror.SnapError {
 	return nil
 }
 
+// PauseTask provided a task id a task is paused, it keeps its subscriptions,
+// counters and the position of its schedule until it's resumed
+func (s *scheduler) PauseTask(id string) []serror.SnapError {
+	logger := schedulerLogger.WithFields(log.Fields{
+		"_block":  "pause-task",
+		"task-id": id,
+	})
+	t, err := s.getTask(id)
+	if err != nil {
+		logger.WithField("_error", err.Error()).Error("error pausing task")
+		return []serror.SnapError{
+			serror.New(err),
+		}
+	}
+	if t.isStream {
+		logger.Error(ErrStreamingTaskNotPausable)
+		return []serror.SnapError{
+			serror.New(ErrStreamingTaskNotPausable),
+		}
+	}
+	if t.state != core.TaskFiring && t.state != core.TaskSpinning {
+		logger.WithField("task-state", t.State()).Error(ErrTaskNotPausable)
+		return []serror.SnapError{
+			serror.New(ErrTaskNotPausable),
+		}
+	}
+	t.Pause()
+	logger.WithField("task-state", t.State()).Info("task paused")
+	return nil
+}
+
+// ResumeTask provided a task id a paused task is resumed
+func (s *scheduler) ResumeTask(id string) []serror.SnapError {
+	logger := schedulerLogger.WithFields(log.Fields{
+		"_block":  "resume-task",
+		"task-id": id,
+	})
+	t, err := s.getTask(id)
+	if err != nil {
+		logger.WithField("_error", err.Error()).Error("error resuming task")
+		return []serror.SnapError{
+			serror.New(err),
+		}
+	}
+	if t.state != core.TaskPaused {
+		logger.WithField("task-state", t.State()).Error(ErrTaskNotPaused)
+		return []serror.SnapError{
+			serror.New(ErrTaskNotPaused),
+		}
+	}
+	t.Resume()
+	event := &scheduler_event.TaskResumedEvent{
+		TaskID: t.ID(),
+		Source: "user",
+	}
+	defer s.eventManager.Emit(event)
+	logger.WithField("task-state", t.State()).Info("task resumed")
+	s.persistTasks()
+	return nil
+}
+
 //EnableTask changes state from disabled to stopped
 func (s *scheduler) EnableTask(id string) (core.Task, error) {
 	t, e := s.getTask(id)
@@ -666,6 +1015,7 @@ func (s *scheduler) EnableTask(id string) (core.Task, error) {
 		"task-id":    t.ID(),
 		"task-state": t.State(),
 	}).Info("task enabled")
+	s.persistTasks()
 	return t, nil
 }
 
@@ -683,6 +1033,10 @@ func (s *scheduler) Start() error {
 		"_block": "start-scheduler",
 	}).Info("scheduler started")
 
+	// Restore the persisted tasks before the tasks of the auto discover path
+	// are loaded
+	s.restoreTasks()
+
 	//Autodiscover
 	autoDiscoverPaths := s.metricManager.GetAutodiscoverPaths()
 	if autoDiscoverPaths != nil && len(autoDiscoverPaths) != 0 {
@@ -723,7 +1077,7 @@ func (s *scheduler) Start() error {
 				}
 				taskFiles = append(taskFiles, file)
 			}
-			autoDiscoverTasks(taskFiles, fullPath, s.CreateTask)
+			autoDiscoverTasks(taskFiles, fullPath, s.createAutodiscoveredTask)
 		}
 	} else {
 		schedulerLogger.WithFields(log.Fields{
@@ -735,6 +1089,8 @@ func (s *scheduler) Start() error {
 }
 
 func (s *scheduler) Stop() {
+	// persist the tasks in the state they are in before they are killed
+	s.persistTasks()
 	s.state = schedulerStopped
 	// stop all tasks that are not already stopped
 	for _, t := range s.tasks.table {
@@ -746,6 +1102,12 @@ func (s *scheduler) Stop() {
 	}).Info("scheduler stopped")
 }
 
+// SetTracer sets the tracer of the runs of the workflows of the tasks created
+// from then on
+func (s *scheduler) SetTracer(t *tracing.Tracer) {
+	s.tracer = t
+}
+
 // Set metricManager for scheduler
 func (s *scheduler) SetMetricManager(mm managesMetrics) {
 	s.metricManager = mm
@@ -808,6 +1170,51 @@ func (s *scheduler) HandleGomitEvent(e gomit.Event) {
 		task, _ := s.getTask(v.TaskID)
 		task.UnsubscribePlugins()
 		s.taskWatcherColl.handleTaskStopped(v.TaskID)
+		s.persistTasks()
+	case *scheduler_event.TaskPausedEvent:
+		log.WithFields(log.Fields{
+			"_module":         "scheduler-events",
+			"_block":          "handle-events",
+			"event-namespace": e.Namespace(),
+			"task-id":         v.TaskID,
+		}).Debug("event received")
+		s.persistTasks()
+	case *scheduler_event.TaskResumedEvent:
+		log.WithFields(log.Fields{
+			"_module":         "scheduler-events",
+			"_block":          "handle-events",
+			"event-namespace": e.Namespace(),
+			"task-id":         v.TaskID,
+		}).Debug("event received")
+	case *scheduler_event.TaskOverrunEvent:
+		log.WithFields(log.Fields{
+			"_module":         "scheduler-events",
+			"_block":          "handle-events",
+			"event-namespace": e.Namespace(),
+			"task-id":         v.TaskID,
+			"overrun-policy":  v.Policy,
+			"overrun-count":   v.OverrunCount,
+		}).Debug("event received")
+		s.taskWatcherColl.handleTaskOverrun(v.TaskID, v.OverrunCount)
+	case *scheduler_event.TaskRunEvent:
+		log.WithFields(log.Fields{
+			"_module":         "scheduler-events",
+			"_block":          "handle-events",
+			"event-namespace": e.Namespace(),
+			"task-id":         v.TaskID,
+			"run-duration":    v.Record.Duration.String(),
+		}).Debug("event received")
+		s.taskWatcherColl.handleTaskRun(v.TaskID, v.Record)
+	case *scheduler_event.CircuitBreakerEvent:
+		log.WithFields(log.Fields{
+			"_module":         "scheduler-events",
+			"_block":          "handle-events",
+			"event-namespace": e.Namespace(),
+			"task-id":         v.TaskID,
+			"step":            v.Breaker.Step,
+			"state":           v.Breaker.State,
+		}).Debug("event received")
+		s.taskWatcherColl.handleCircuitBreaker(v.TaskID, v.Breaker)
 	case *scheduler_event.TaskEndedEvent:
 		log.WithFields(log.Fields{
 			"_module":         "scheduler-events",
@@ -819,6 +1226,7 @@ func (s *scheduler) HandleGomitEvent(e gomit.Event) {
 		task, _ := s.getTask(v.TaskID)
 		task.UnsubscribePlugins()
 		s.taskWatcherColl.handleTaskEnded(v.TaskID)
+		s.persistTasks()
 	case *scheduler_event.TaskDisabledEvent:
 		log.WithFields(log.Fields{
 			"_module":         "scheduler-events",
@@ -831,6 +1239,19 @@ func (s *scheduler) HandleGomitEvent(e gomit.Event) {
 		task, _ := s.getTask(v.TaskID)
 		task.UnsubscribePlugins()
 		s.taskWatcherColl.handleTaskDisabled(v.TaskID, v.Why)
+		s.persistTasks()
+	case *control_event.LoadPluginEvent:
+		log.WithFields(log.Fields{
+			"_module":         "scheduler-events",
+			"_block":          "handle-events",
+			"event-namespace": e.Namespace(),
+			"plugin-name":     v.Name,
+			"plugin-version":  v.Version,
+		}).Debug("event received")
+		// the persisted tasks may be waiting for the plugin
+		if s.hasPendingTasks() {
+			go s.restorePendingTasks()
+		}
 	case *scheduler_event.PluginsUnsubscribedEvent:
 		log.WithFields(log.Fields{
 			"_module":         "scheduler-events",
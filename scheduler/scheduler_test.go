@@ -31,6 +31,7 @@ import (
 	. "github.com/smartystreets/goconvey/convey"
 
 	"github.com/intelsdi-x/gomit"
+	"github.com/intelsdi-x/snap/control/plugin"
 	"github.com/intelsdi-x/snap/core"
 	"github.com/intelsdi-x/snap/core/cdata"
 	"github.com/intelsdi-x/snap/core/control_event"
@@ -45,6 +46,7 @@ type mockMetricManager struct {
 	failValidatingMetricsAfter int
 	failuredSoFar              int
 	autodiscoverPaths          []string
+	unsubscribed               int
 }
 
 func (m *mockMetricManager) StreamMetrics(string, map[string]map[string]string, time.Duration, int64) (chan []core.Metric, chan error, []error) {
@@ -77,6 +79,7 @@ func (m *mockMetricManager) SubscribeDeps(taskID string, req []core.RequestedMet
 }
 
 func (m *mockMetricManager) UnsubscribeDeps(taskID string) []serror.SnapError {
+	m.unsubscribed++
 	return nil
 }
 
@@ -305,3 +308,106 @@ func TestScheduler(t *testing.T) {
 	})
 
 }
+
+type mockIntervalsMetricManager struct {
+	mockMetricManager
+	intervals map[string]time.Duration
+}
+
+func (m *mockIntervalsMetricManager) MinCollectionIntervals([]core.RequestedMetric) map[string]time.Duration {
+	return m.intervals
+}
+
+func TestValidateMinIntervals(t *testing.T) {
+	Convey("validateMinIntervals()", t, func() {
+		manager := &mockIntervalsMetricManager{intervals: map[string]time.Duration{
+			"/intel/cloud/quota": time.Minute,
+			"/intel/cloud/usage": time.Second,
+		}}
+		Convey("Should reject metrics collected more often than allowed", func() {
+			errs := validateMinIntervals(schedule.NewWindowedSchedule(time.Second*10, nil, nil, 0), manager, nil)
+			So(errs, ShouldHaveLength, 1)
+			So(errs[0].Error(), ShouldEqual, ErrIntervalBelowMinimum.Error())
+			So(errs[0].Fields()["metric"], ShouldEqual, "/intel/cloud/quota")
+		})
+		Convey("Should accept intervals at least as long as the minimum", func() {
+			errs := validateMinIntervals(schedule.NewWindowedSchedule(time.Minute, nil, nil, 0), manager, nil)
+			So(errs, ShouldBeEmpty)
+		})
+		Convey("Should skip the schedules without a fixed interval", func() {
+			errs := validateMinIntervals(schedule.NewCronSchedule("* * * * * *"), manager, nil)
+			So(errs, ShouldBeEmpty)
+		})
+		Convey("Should skip the managers which don't expose intervals", func() {
+			errs := validateMinIntervals(schedule.NewWindowedSchedule(time.Second, nil, nil, 0), &mockMetricManager{}, nil)
+			So(errs, ShouldBeEmpty)
+		})
+	})
+}
+
+// mockRunMetricManager subscribes the tasks and collects a single metric,
+// which its processors pass through
+type mockRunMetricManager struct {
+	mockMetricManager
+	published int
+}
+
+func (m *mockRunMetricManager) SubscribeDeps(string, []core.RequestedMetric, []core.SubscribedPlugin, *cdata.ConfigDataTree) []serror.SnapError {
+	return nil
+}
+
+func (m *mockRunMetricManager) CollectMetrics(string, map[string]map[string]string) ([]core.Metric, []error) {
+	return []core.Metric{plugin.MetricType{Namespace_: core.NewNamespace("foo", "bar"), Data_: 1}}, nil
+}
+
+func (m *mockRunMetricManager) ProcessMetrics(mts []core.Metric, _ map[string]ctypes.ConfigValue, _ string, _ string, _ int) ([]core.Metric, []error) {
+	return mts, nil
+}
+
+func (m *mockRunMetricManager) PublishMetrics([]core.Metric, map[string]ctypes.ConfigValue, string, string, int) []error {
+	m.published++
+	return nil
+}
+
+func TestRunTask(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+	Convey("RunTask()", t, func() {
+		w := wmap.NewWorkflowMap()
+		w.Collect.AddMetric("/foo/bar", 1)
+		pr := wmap.NewProcessNode("passthru", 1)
+		pr.Add(wmap.NewPublishNode("file", 1))
+		w.Collect.Add(pr)
+		c := &mockRunMetricManager{}
+		s := New(GetDefaultConfig())
+		s.SetMetricManager(c)
+		Convey("Should return an error when the scheduler is not started", func() {
+			run, errs := s.RunTask(w, time.Second, false)
+			So(run, ShouldBeNil)
+			So(errs, ShouldHaveLength, 1)
+			So(errs[0].Error(), ShouldEqual, ErrSchedulerNotStarted.Error())
+		})
+		So(s.Start(), ShouldBeNil)
+		Convey("Should return the metrics passed to the publishers without publishing them", func() {
+			run, errs := s.RunTask(w, time.Second, false)
+			So(errs, ShouldBeEmpty)
+			So(run, ShouldNotBeNil)
+			So(run.Collected, ShouldHaveLength, 1)
+			So(run.Published, ShouldHaveLength, 1)
+			So(run.Published[0].Name, ShouldEqual, "file")
+			So(run.Published[0].Metrics, ShouldHaveLength, 1)
+			So(c.published, ShouldEqual, 0)
+			So(s.GetTasks(), ShouldBeEmpty)
+		})
+		Convey("Should publish the metrics when requested", func() {
+			_, errs := s.RunTask(w, time.Second, true)
+			So(errs, ShouldBeEmpty)
+			So(c.published, ShouldEqual, 1)
+		})
+		Convey("Should return the errors of the subscription", func() {
+			s.SetMetricManager(&mockMetricManager{})
+			run, errs := s.RunTask(w, time.Second, false)
+			So(run, ShouldBeNil)
+			So(errs, ShouldNotBeEmpty)
+		})
+	})
+}
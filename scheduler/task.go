@@ -44,6 +44,11 @@ const (
 	DefaultDeadlineDuration = time.Second * 5
 	// DefaultStopOnFailure is used to set the number of failures before a task is disabled
 	DefaultStopOnFailure = 10
+	// FailureBackoffCount is the number of consecutive failures after which a
+	// task which is never disabled (stopOnFailure < 0) backs off
+	FailureBackoffCount = 10
+	// FailureBackoff is the time a task which is never disabled backs off for
+	FailureBackoff = time.Second
 )
 
 var (
@@ -59,15 +64,20 @@ var (
 	ErrTaskDisabledOnFailures = errors.New("Task disabled due to consecutive failures")
 	// ErrTaskNotDisabled - The error message for task must be disabled
 	ErrTaskNotDisabled = errors.New("Task must be disabled")
+	// ErrTaskRunDeadlineExceeded - The error message for when a task run lasts longer than its run deadline
+	ErrTaskRunDeadlineExceeded = errors.New("Task run exceeded its deadline")
 )
 
 type task struct {
 	sync.Mutex //protects state
 
-	id                 string
-	name               string
-	schResponseChan    chan schedule.Response
-	killChan           chan struct{}
+	id              string
+	name            string
+	schResponseChan chan schedule.Response
+	killChan        chan struct{}
+	// spinDone is closed once the spin loop returned, the task stopped event
+	// has been handled then
+	spinDone           chan struct{}
 	schedule           schedule.Schedule
 	workflow           *schedulerWorkflow
 	state              core.TaskState
@@ -89,6 +99,43 @@ type task struct {
 
 	maxCollectDuration time.Duration
 	maxMetricsBuffer   int64
+
+	// the ids of the tasks the task depends on and the tasks they are looked up in
+	dependencies []string
+	tasks        *taskCollection
+	// the last time the schedule fired without running the workflow, while the
+	// dependencies weren't satisfied or the task was paused
+	lastSkipTime time.Time
+	// pausing is true while a task is being paused rather than stopped
+	pausing bool
+	// the end of the most recent completed run and whether it failed,
+	// protected by failureMutex
+	lastRunEnd    time.Time
+	lastRunFailed bool
+
+	// the time a run may take before the overrun policy is applied to it
+	runDeadline   time.Duration
+	overrunPolicy string
+	overrunCount  uint
+	// inFlight is closed once the run the task stopped waiting for, under the
+	// skip policy, has ended
+	inFlight chan struct{}
+
+	// the priority the jobs of the task are dispatched with
+	priority string
+	// the labels the tasks are selected by in bulk operations
+	labels map[string]string
+	// source is what created the task ("user", "tribe", "autodiscover" or
+	// "restore"), the tasks created by tribe or autodiscovery aren't persisted
+	source string
+	// the queue the failed publishes are spooled to, nil when disabled
+	deadLetters *deadLetterQueue
+	// the caps of the buffer of the task, nil when the task isn't buffered,
+	// and the buffer storing the metrics its publishers failed to publish
+	bufferConfig *core.TaskBuffer
+	buffer       *metricBuffer
+	// the publish nodes of the workflow, indexed by the buffered batches
+	bufferNodes []*publishNode
 }
 
 //NewTask creates a Task
@@ -119,6 +166,8 @@ func newTask(s schedule.Schedule, wf *schedulerWorkflow, m *workManager, mm mana
 		metricsManager:   mm,
 		deadlineDuration: DefaultDeadlineDuration,
 		stopOnFailure:    DefaultStopOnFailure,
+		overrunPolicy:    core.TaskOverrunQueue,
+		priority:         core.TaskPriorityNormal,
 		eventEmitter:     emitter,
 		RemoteManagers:   mgrs,
 		isStream:         stream,
@@ -156,6 +205,55 @@ func (t *task) SetMaxMetricsBuffer(i int64) {
 	t.maxMetricsBuffer = i
 }
 
+// Dependencies returns the ids of the tasks the task depends on
+func (t *task) Dependencies() []string {
+	return t.dependencies
+}
+
+// SetDependencies sets the ids of the tasks the task depends on
+func (t *task) SetDependencies(ids []string) {
+	t.dependencies = ids
+}
+
+// DependencyStates returns the state of each task the task depends on
+func (t *task) DependencyStates() map[string]core.TaskDependencyState {
+	states := make(map[string]core.TaskDependencyState, len(t.dependencies))
+	for _, id := range t.dependencies {
+		states[id] = t.dependencyState(id)
+	}
+	return states
+}
+
+func (t *task) dependencyState(id string) core.TaskDependencyState {
+	var upstream *task
+	if t.tasks != nil {
+		upstream = t.tasks.Get(id)
+	}
+	if upstream == nil {
+		return core.TaskDependencyMissing
+	}
+	upstream.failureMutex.Lock()
+	defer upstream.failureMutex.Unlock()
+	switch {
+	case upstream.lastRunEnd.IsZero():
+		return core.TaskDependencyWaiting
+	case upstream.lastRunFailed:
+		return core.TaskDependencyFailed
+	}
+	return core.TaskDependencySatisfied
+}
+
+// dependenciesSatisfied returns true when the most recent run of each task
+// the task depends on succeeded
+func (t *task) dependenciesSatisfied() bool {
+	for _, id := range t.dependencies {
+		if t.dependencyState(id) != core.TaskDependencySatisfied {
+			return false
+		}
+	}
+	return true
+}
+
 //Returns the name of the task
 func (t *task) GetName() string {
 	return t.name
@@ -178,6 +276,47 @@ func (t *task) SetDeadlineDuration(d time.Duration) {
 	t.deadlineDuration = d
 }
 
+// RunDeadline returns the time a run of the task may take before its overrun
+// policy is applied to it, zero means no deadline.
+func (t *task) RunDeadline() time.Duration {
+	return t.runDeadline
+}
+
+func (t *task) SetRunDeadline(d time.Duration) {
+	t.runDeadline = d
+}
+
+// OverrunPolicy returns the policy applied to the runs exceeding the run deadline.
+func (t *task) OverrunPolicy() string {
+	return t.overrunPolicy
+}
+
+func (t *task) SetOverrunPolicy(p string) {
+	t.overrunPolicy = p
+}
+
+// Priority returns the priority the jobs of the task are dispatched with.
+func (t *task) Priority() string {
+	return t.priority
+}
+
+func (t *task) SetPriority(p string) {
+	t.priority = p
+}
+
+// OverrunCount returns the number of runs which exceeded the run deadline.
+func (t *task) Labels() map[string]string {
+	return t.labels
+}
+
+func (t *task) SetLabels(labels map[string]string) {
+	t.labels = labels
+}
+
+func (t *task) OverrunCount() uint {
+	return t.overrunCount
+}
+
 func (t *task) SetTaskID(id string) {
 	t.id = id
 }
@@ -244,7 +383,8 @@ func (t *task) Spin() {
 	if t.isStream {
 		t.state = core.TaskSpinning
 		t.killChan = make(chan struct{})
-		go t.stream()
+		t.spinDone = make(chan struct{})
+		go t.stream(t.spinDone)
 		return
 	}
 
@@ -254,17 +394,20 @@ func (t *task) Spin() {
 	// waiting a period of time, and starting the task won't show
 	// misses for the interval while stopped.
 	t.lastFireTime = time.Time{}
+	t.lastSkipTime = time.Time{}
 
 	if t.state == core.TaskStopped || t.state == core.TaskEnded {
 		t.state = core.TaskSpinning
 		t.killChan = make(chan struct{})
+		t.spinDone = make(chan struct{})
 		// spin in a goroutine
-		go t.spin()
+		go t.spin(t.spinDone)
 	}
 }
 
 // Fork stream stuff here
-func (t *task) stream() {
+func (t *task) stream(done chan struct{}) {
+	defer close(done)
 	var consecutiveFailures int
 	resetTime := time.Second * 3
 	for {
@@ -292,7 +435,9 @@ func (t *task) stream() {
 			// wait for a second and then try again until either
 			// the connection is successful or we pass the
 			// acceptable number of consecutive failures
-			time.Sleep(resetTime)
+			if !t.waitStream(resetTime) {
+				return
+			}
 			continue
 		} else {
 			consecutiveFailures = 0
@@ -304,13 +449,7 @@ func (t *task) stream() {
 			}
 			select {
 			case <-t.killChan:
-				t.Lock()
-				t.state = core.TaskStopped
-				t.Unlock()
-				done = true
-				event := new(scheduler_event.TaskStoppedEvent)
-				event.TaskID = t.id
-				defer t.eventEmitter.Emit(event)
+				t.streamStopped()
 				return
 			case mts, ok := <-metricsChan:
 				if !ok {
@@ -333,7 +472,9 @@ func (t *task) stream() {
 				if err.Error() == "connection broken" {
 					// Wait here before trying to reconnect to allow time
 					// for plugin restarts.
-					time.Sleep(resetTime)
+					if !t.waitStream(resetTime) {
+						return
+					}
 					done = true
 				}
 				// check task failures
@@ -354,8 +495,41 @@ func (t *task) stream() {
 	}
 }
 
+// waitStream waits before the stream is set up again, it returns false when
+// the task was stopped in the meantime
+func (t *task) waitStream(d time.Duration) bool {
+	select {
+	case <-t.killChan:
+		t.streamStopped()
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+// streamStopped marks a streaming task as stopped once its stream is closed
+func (t *task) streamStopped() {
+	t.Lock()
+	t.state = core.TaskStopped
+	t.Unlock()
+	event := new(scheduler_event.TaskStoppedEvent)
+	event.TaskID = t.id
+	t.eventEmitter.Emit(event)
+}
+
 func (t *task) Stop() {
 	t.Lock()
+	if t.state == core.TaskPaused {
+		// a paused task isn't spinning, it's stopped right away
+		t.state = core.TaskStopped
+		t.lastFireTime = time.Time{}
+		t.lastSkipTime = time.Time{}
+		t.Unlock()
+		event := new(scheduler_event.TaskStoppedEvent)
+		event.TaskID = t.id
+		t.eventEmitter.Emit(event)
+		return
+	}
 	defer t.Unlock()
 	if t.state == core.TaskFiring || t.state == core.TaskSpinning {
 		t.state = core.TaskStopping
@@ -363,6 +537,62 @@ func (t *task) Stop() {
 	}
 }
 
+// waitSpinDone waits for the spin loop of a stopping task to return, it
+// returns false when it didn't return within the timeout
+func (t *task) waitSpinDone(timeout time.Duration) bool {
+	t.Lock()
+	done := t.spinDone
+	t.Unlock()
+	if done == nil {
+		return true
+	}
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// Pause stops a task spinning while keeping its subscriptions, its counters
+// and the position of its schedule until it's resumed
+func (t *task) Pause() {
+	t.Lock()
+	defer t.Unlock()
+	if t.state == core.TaskFiring || t.state == core.TaskSpinning {
+		t.state = core.TaskStopping
+		t.pausing = true
+		close(t.killChan)
+	}
+}
+
+// Resume starts a paused task spinning again from the position its schedule
+// was paused at
+func (t *task) Resume() {
+	t.Lock()
+	defer t.Unlock()
+	if t.state != core.TaskPaused {
+		return
+	}
+	if p, ok := t.schedule.(schedule.Pausable); ok {
+		p.Resume()
+	}
+	// the intervals elapsed while the task was paused are skipped, not missed
+	if w, ok := t.schedule.(*schedule.WindowedSchedule); ok && w.Interval > 0 {
+		last := t.lastFireTime
+		if t.lastSkipTime.After(last) {
+			last = t.lastSkipTime
+		}
+		if !last.IsZero() {
+			t.lastSkipTime = last.Add(time.Since(last) / w.Interval * w.Interval)
+		}
+	}
+	t.state = core.TaskSpinning
+	t.killChan = make(chan struct{})
+	t.spinDone = make(chan struct{})
+	go t.spin(t.spinDone)
+}
+
 // UnsubscribePlugins groups task dependencies by the node they live in workflow and unsubscribe them
 func (t *task) UnsubscribePlugins() []serror.SnapError {
 	depGroups := getWorkflowPlugins(t.workflow.processNodes, t.workflow.publishNodes, t.workflow.metrics)
@@ -445,11 +675,20 @@ func (t *task) Enable() error {
 
 func (t *task) Kill() {
 	t.Lock()
-	defer t.Unlock()
 	if t.state == core.TaskFiring || t.state == core.TaskSpinning {
+		// the plugins are unsubscribed once the spin loop stopped
 		close(t.killChan)
 		t.state = core.TaskDisabled
 	}
+	paused := t.state == core.TaskPaused
+	if paused {
+		t.state = core.TaskDisabled
+	}
+	t.Unlock()
+	if paused {
+		// a paused task isn't spinning, its plugins are unsubscribed right away
+		t.UnsubscribePlugins()
+	}
 }
 
 func (t *task) WMap() *wmap.WorkflowMap {
@@ -460,7 +699,9 @@ func (t *task) Schedule() schedule.Schedule {
 	return t.schedule
 }
 
-func (t *task) spin() {
+func (t *task) spin(done chan struct{}) {
+	// closed once the events emitted when the loop returns are handled
+	defer close(done)
 	var consecutiveFailures int
 	for {
 		taskLogger.Debug("task spin loop")
@@ -475,6 +716,25 @@ func (t *task) spin() {
 			// If response show this schedule is still active we fire
 			case schedule.Active:
 				t.missedIntervals += sr.Missed()
+				if !t.dependenciesSatisfied() {
+					t.lastSkipTime = time.Now()
+					taskLogger.WithFields(log.Fields{
+						"_block":       "spin",
+						"task-id":      t.id,
+						"task-name":    t.name,
+						"dependencies": t.DependencyStates(),
+					}).Debug("Task not fired, waiting for its dependencies")
+					continue
+				}
+				if t.runInFlight() {
+					t.lastSkipTime = time.Now()
+					taskLogger.WithFields(log.Fields{
+						"_block":    "spin",
+						"task-id":   t.id,
+						"task-name": t.name,
+					}).Debug("Task not fired, its previous run is still in flight")
+					continue
+				}
 				t.fire()
 				if t.lastFailureTime == t.lastFireTime {
 					consecutiveFailures++
@@ -502,6 +762,20 @@ func (t *task) spin() {
 					t.disable(t.lastFailureMessage)
 					return
 				}
+				if t.stopOnFailure < 0 && consecutiveFailures > 0 && consecutiveFailures%FailureBackoffCount == 0 {
+					taskLogger.WithFields(log.Fields{
+						"_block":               "spin",
+						"task-id":              t.id,
+						"task-name":            t.name,
+						"consecutive failures": consecutiveFailures,
+						"backoff":              FailureBackoff,
+					}).Warn("Task keeps failing, backing off")
+					// a stopped task is handled by the next loop
+					select {
+					case <-t.killChan:
+					case <-time.After(FailureBackoff):
+					}
+				}
 
 			// Schedule has ended
 			case schedule.Ended:
@@ -524,10 +798,24 @@ func (t *task) spin() {
 
 			}
 		case <-t.killChan:
-			// Only here can it truly be stopped
 			t.Lock()
+			if t.pausing {
+				// the subscriptions and the position of the schedule are kept
+				t.pausing = false
+				t.state = core.TaskPaused
+				t.Unlock()
+				if p, ok := t.schedule.(schedule.Pausable); ok {
+					p.Pause()
+				}
+				event := new(scheduler_event.TaskPausedEvent)
+				event.TaskID = t.id
+				defer t.eventEmitter.Emit(event)
+				return
+			}
+			// Only here can it truly be stopped
 			t.state = core.TaskStopped
 			t.lastFireTime = time.Time{}
+			t.lastSkipTime = time.Time{}
 			t.Unlock()
 			event := new(scheduler_event.TaskStoppedEvent)
 			event.TaskID = t.id
@@ -543,9 +831,78 @@ func (t *task) fire() {
 
 	t.state = core.TaskFiring
 	t.lastFireTime = time.Now()
-	t.workflow.Start(t)
+	done := make(chan struct{})
+	abandoned := make(chan struct{})
+	go func() {
+		t.workflow.Start(t, abandoned)
+		close(done)
+	}()
+	t.waitRun(done, abandoned)
 	t.hitCount++
 	t.state = core.TaskSpinning
+
+	t.failureMutex.Lock()
+	t.lastRunEnd = time.Now()
+	t.lastRunFailed = t.lastFailureTime == t.lastFireTime
+	t.failureMutex.Unlock()
+}
+
+// waitRun waits for the end of a run, the overrun policy of the task is
+// applied to a run lasting longer than the run deadline.  A run abandoned with
+// the kill policy is signaled through the abandoned channel so it doesn't
+// dispatch the jobs it hasn't started yet.
+func (t *task) waitRun(done, abandoned chan struct{}) {
+	if t.runDeadline <= 0 {
+		<-done
+		return
+	}
+	select {
+	case <-done:
+		return
+	case <-time.After(t.runDeadline):
+	}
+	t.overrunCount++
+	taskLogger.WithFields(log.Fields{
+		"_block":         "wait-run",
+		"task-id":        t.id,
+		"task-name":      t.name,
+		"run-deadline":   t.runDeadline,
+		"overrun-policy": t.overrunPolicy,
+		"overrun-count":  t.overrunCount,
+	}).Warn(ErrTaskRunDeadlineExceeded)
+	event := &scheduler_event.TaskOverrunEvent{
+		TaskID:       t.id,
+		Policy:       t.overrunPolicy,
+		OverrunCount: t.overrunCount,
+	}
+	defer t.eventEmitter.Emit(event)
+	switch t.overrunPolicy {
+	case core.TaskOverrunKill:
+		close(abandoned)
+		t.RecordFailure([]error{ErrTaskRunDeadlineExceeded})
+		// the jobs the run already started can't be interrupted, the task
+		// doesn't fire again until they ended
+		t.inFlight = done
+	case core.TaskOverrunSkip:
+		t.inFlight = done
+	default:
+		<-done
+	}
+}
+
+// runInFlight returns true while the run the task stopped waiting for is
+// still in flight
+func (t *task) runInFlight() bool {
+	if t.inFlight == nil {
+		return false
+	}
+	select {
+	case <-t.inFlight:
+		t.inFlight = nil
+		return false
+	default:
+		return true
+	}
 }
 
 // disable proceeds disabling a task which consists of changing task state to disabled and emitting an appropriate event
@@ -562,10 +919,14 @@ func (t *task) disable(failureMsg string) {
 }
 
 func (t *task) waitForSchedule() {
+	last := t.lastFireTime
+	if t.lastSkipTime.After(last) {
+		last = t.lastSkipTime
+	}
 	select {
 	case <-t.killChan:
 		return
-	case t.schResponseChan <- t.schedule.Wait(t.lastFireTime):
+	case t.schResponseChan <- t.schedule.Wait(last):
 	}
 }
 
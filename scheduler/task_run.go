@@ -0,0 +1,238 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/serror"
+	"github.com/intelsdi-x/snap/pkg/schedule"
+	"github.com/intelsdi-x/snap/scheduler/wmap"
+)
+
+var (
+	// ErrTaskRunTimeout - The error message for when a task run doesn't complete within its timeout
+	ErrTaskRunTimeout = errors.New("Task run timed out.")
+)
+
+// RunTask runs the workflow of the workflow map a single time, without
+// creating a task, and returns the metrics it collected and the metrics passed
+// to each of its publishers.  The publishers only publish the metrics when
+// publish is true.  The run is abandoned once the timeout elapses.
+func (s *scheduler) RunTask(wfMap *wmap.WorkflowMap, timeout time.Duration, publish bool, opts ...core.TaskOption) (*core.TaskRun, []serror.SnapError) {
+	runner, errs := s.NewTaskRunner(wfMap, timeout, publish, opts...)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	defer runner.Close()
+	return runner.Run()
+}
+
+// NewTaskRunner subscribes the plugins of the workflow of the workflow map
+// and returns a runner running it without creating a task, as RunTask does,
+// until the runner is closed.  Each run is abandoned once the timeout elapses.
+func (s *scheduler) NewTaskRunner(wfMap *wmap.WorkflowMap, timeout time.Duration, publish bool, opts ...core.TaskOption) (core.TaskRunner, []serror.SnapError) {
+	logger := schedulerLogger.WithFields(log.Fields{
+		"_block":  "new-task-runner",
+		"timeout": timeout,
+		"publish": publish,
+	})
+	if s.state != schedulerStarted {
+		logger.Error(ErrSchedulerNotStarted.Error())
+		return nil, []serror.SnapError{serror.New(ErrSchedulerNotStarted)}
+	}
+	wf, err := wmapToWorkflow(wfMap)
+	if err != nil {
+		logger.WithField("_error", err.Error()).Error("Unable to generate workflow from workflow map")
+		return nil, []serror.SnapError{serror.New(err)}
+	}
+	sch := schedule.NewWindowedSchedule(timeout, nil, nil, 1)
+	// the jobs of the run can't outlive it
+	opts = append(opts, core.TaskDeadlineDuration(timeout))
+	t, err := newTask(sch, wf, s.workManager, s.metricManager, s.eventManager, opts...)
+	if err != nil {
+		logger.WithField("_error", err.Error()).Error("Unable to create task")
+		return nil, []serror.SnapError{serror.New(err)}
+	}
+	depGroups := getWorkflowPlugins(wf.processNodes, wf.publishNodes, wf.metrics)
+	for k, group := range depGroups {
+		manager, err := t.RemoteManagers.Get(k)
+		if err != nil {
+			return nil, []serror.SnapError{serror.New(err)}
+		}
+		if errs := manager.ValidateDeps(group.requestedMetrics, group.subscribedPlugins, wf.configTree, noStreamingPluginsAssert(sch)); len(errs) > 0 {
+			return nil, errs
+		}
+	}
+	if _, errs := t.SubscribePlugins(); len(errs) > 0 {
+		return nil, errs
+	}
+	return &taskRunner{task: t, timeout: timeout, publish: publish}, nil
+}
+
+// taskRunner runs the workflow of a task which isn't scheduled, its plugins
+// are subscribed as long as it isn't closed
+type taskRunner struct {
+	task    *task
+	timeout time.Duration
+	publish bool
+	// runs holds the runs in flight, including the timed out ones
+	runs      sync.WaitGroup
+	closeOnce sync.Once
+}
+
+func (tr *taskRunner) Run() (*core.TaskRun, []serror.SnapError) {
+	t := tr.task
+	logger := schedulerLogger.WithFields(log.Fields{
+		"_block":  "run-task",
+		"task-id": t.ID(),
+		"timeout": tr.timeout,
+		"publish": tr.publish,
+	})
+	r := &taskRun{task: t, publish: tr.publish, result: &core.TaskRun{}}
+	done := make(chan struct{})
+	tr.runs.Add(1)
+	go func() {
+		defer tr.runs.Done()
+		defer close(done)
+		r.run()
+	}()
+	select {
+	case <-done:
+	case <-time.After(tr.timeout):
+		logger.Warn(ErrTaskRunTimeout.Error())
+		return nil, []serror.SnapError{serror.New(ErrTaskRunTimeout)}
+	}
+	logger.WithFields(log.Fields{
+		"metrics-count":   len(r.result.Collected),
+		"count-errors":    len(r.errs),
+		"count-published": len(r.result.Published),
+	}).Info("task run completed")
+	if r.result.Collected == nil {
+		return nil, r.errs
+	}
+	return r.result, r.errs
+}
+
+// Close unsubscribes the plugins of the workflow once the runs in flight
+// complete, even the timed out ones, without waiting for them
+func (tr *taskRunner) Close() {
+	tr.closeOnce.Do(func() {
+		go func() {
+			tr.runs.Wait()
+			tr.task.UnsubscribePlugins()
+		}()
+	})
+}
+
+// taskRun runs the workflow of a task a single time, walking the workflow
+// sequentially to capture the metrics passed to the publishers
+type taskRun struct {
+	task    *task
+	publish bool
+	result  *core.TaskRun
+	errs    []serror.SnapError
+}
+
+func (r *taskRun) run() {
+	t := r.task
+	j, errs := t.workflow.collect(t, t.deadlineDuration, nil)
+	if len(errs) > 0 {
+		r.addErrors(errs, nil)
+		return
+	}
+	r.result.Collected = j.Metrics()
+	r.walk(j, t.workflow.processNodes, t.workflow.publishNodes)
+}
+
+func (r *taskRun) walk(parent job, prs []*processNode, pus []*publishNode) {
+	t := r.task
+	for _, pr := range prs {
+		pj, ok := branch(parent, pr.when)
+		if !ok {
+			continue
+		}
+		fields := map[string]interface{}{
+			"plugin-name":    pr.Name(),
+			"plugin-version": pr.Version(),
+		}
+		mgr, err := t.RemoteManagers.Get(pr.Target)
+		if err != nil {
+			r.addErrors([]error{err}, fields)
+			continue
+		}
+		j := newProcessJob(pj, pr.Name(), pr.Version(), pr.InboundContentType, pr.jobConfig(), mgr, t.id)
+		if errs := awaitJob(t.manager.Work(j), pr.timeout); len(errs) > 0 {
+			r.addErrors(errs, fields)
+			continue
+		}
+		r.walk(j, pr.ProcessNodes, pr.PublishNodes)
+	}
+	for _, pu := range pus {
+		pj, ok := branch(parent, pu.when)
+		if !ok {
+			continue
+		}
+		r.result.Published = append(r.result.Published, core.TaskRunPublisher{
+			Name:    pu.Name(),
+			Version: pu.Version(),
+			Metrics: pj.Metrics(),
+		})
+		if !r.publish {
+			continue
+		}
+		fields := map[string]interface{}{
+			"plugin-name":    pu.Name(),
+			"plugin-version": pu.Version(),
+		}
+		mgr, err := t.RemoteManagers.Get(pu.Target)
+		if err != nil {
+			r.addErrors([]error{err}, fields)
+			continue
+		}
+		j := newPublishJob(pj, pu.Name(), pu.Version(), pu.InboundContentType, pu.config.Table(), mgr, t.id)
+		if errs := awaitJob(t.manager.Work(j), pu.timeout); len(errs) > 0 {
+			r.addErrors(errs, fields)
+		}
+	}
+}
+
+func (r *taskRun) addErrors(errs []error, fields map[string]interface{}) {
+	for _, err := range errs {
+		if serr, ok := err.(serror.SnapError); ok {
+			merged := map[string]interface{}{}
+			for k, v := range serr.Fields() {
+				merged[k] = v
+			}
+			for k, v := range fields {
+				merged[k] = v
+			}
+			serr.SetFields(merged)
+			r.errs = append(r.errs, serr)
+			continue
+		}
+		r.errs = append(r.errs, serror.New(err, fields))
+	}
+}
@@ -0,0 +1,479 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/pkg/schedule"
+	"github.com/intelsdi-x/snap/scheduler/wmap"
+)
+
+var (
+	taskStoreLogger = schedulerLogger.WithField("_module", "scheduler-task-store")
+
+	// ErrTaskStoreMissingSchedule - The error message for a persisted task without a schedule
+	ErrTaskStoreMissingSchedule = errors.New("Persisted task has no schedule")
+)
+
+// taskStore persists the tasks in a file, so after snapteld restart the tasks
+// are created again in the state they were in
+type taskStore struct {
+	path  string
+	mutex *sync.Mutex
+}
+
+// storedTask is the persisted form of a task, its definition along with its
+// state and counters
+type storedTask struct {
+	ID                 string            `json:"id"`
+	Name               string            `json:"name"`
+	State              string            `json:"state"`
+	Schedule           *core.Schedule    `json:"schedule"`
+	Workflow           *wmap.WorkflowMap `json:"workflow"`
+	Deadline           string            `json:"deadline"`
+	MaxFailures        int               `json:"max-failures"`
+	MaxCollectDuration string            `json:"max-collect-duration,omitempty"`
+	MaxMetricsBuffer   int64             `json:"max-metrics-buffer,omitempty"`
+	DependsOn          []string          `json:"depends-on,omitempty"`
+	RunDeadline        string            `json:"run-deadline,omitempty"`
+	OverrunPolicy      string            `json:"overrun-policy,omitempty"`
+	Priority           string            `json:"priority,omitempty"`
+	Labels             map[string]string `json:"labels,omitempty"`
+	CreationTime       time.Time         `json:"creation_timestamp"`
+	LastRunTime        time.Time         `json:"last_run_timestamp"`
+	HitCount           uint              `json:"hit_count"`
+	MissCount          uint              `json:"miss_count"`
+	FailedCount        uint              `json:"failed_count"`
+	LastFailureMessage string            `json:"last_failure_message,omitempty"`
+	OverrunCount       uint              `json:"overrun_count,omitempty"`
+	// WindowStop is the end of a window determined by the count of runs
+	WindowStop *time.Time `json:"window_stop_timestamp,omitempty"`
+	// Buffer holds the caps of the buffer of the task, its batches stay in
+	// the directory of the buffer
+	Buffer *core.TaskBufferRequest `json:"buffer,omitempty"`
+}
+
+func newTaskStore(path string) *taskStore {
+	return &taskStore{
+		path:  path,
+		mutex: &sync.Mutex{},
+	}
+}
+
+// save writes the tasks to the task file
+func (s *taskStore) save(sts []storedTask) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	b, err := json.Marshal(sts)
+	if err != nil {
+		return err
+	}
+	// write to a temporary file first so the task file is never left half-written
+	tmp := s.path + ".tmp"
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// load reads the task file and returns the tasks it contains.  A missing task
+// file results in no tasks.
+func (s *taskStore) load() ([]storedTask, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	b, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var sts []storedTask
+	if err := json.Unmarshal(b, &sts); err != nil {
+		return nil, fmt.Errorf("Unable to parse task file %s: %v", s.path, err)
+	}
+	return sts, nil
+}
+
+// persisted returns true when the task is persisted, the tasks created by
+// tribe or loaded from the auto discover path are created again by them
+func (t *task) persisted() bool {
+	return t.source != "tribe" && t.source != "autodiscover"
+}
+
+func toStoredTask(t *task) (storedTask, bool) {
+	sch := toCoreSchedule(t.schedule)
+	if sch == nil {
+		return storedTask{}, false
+	}
+	state := t.State()
+	if state == core.TaskStopping {
+		state = core.TaskStopped
+	}
+	t.failureMutex.Lock()
+	st := storedTask{
+		ID:                 t.id,
+		Name:               t.name,
+		State:              state.String(),
+		Schedule:           sch,
+		Workflow:           t.WMap(),
+		Deadline:           t.deadlineDuration.String(),
+		MaxFailures:        t.stopOnFailure,
+		MaxMetricsBuffer:   t.maxMetricsBuffer,
+		DependsOn:          t.dependencies,
+		OverrunPolicy:      t.overrunPolicy,
+		Priority:           t.priority,
+		Labels:             t.labels,
+		CreationTime:       t.creationTime,
+		LastRunTime:        t.lastFireTime,
+		HitCount:           t.hitCount,
+		MissCount:          t.missedIntervals,
+		FailedCount:        t.failedRuns,
+		LastFailureMessage: t.lastFailureMessage,
+		OverrunCount:       t.overrunCount,
+	}
+	t.failureMutex.Unlock()
+	if t.maxCollectDuration > 0 {
+		st.MaxCollectDuration = t.maxCollectDuration.String()
+	}
+	if b := t.Buffer(); b != nil {
+		st.Buffer = &core.TaskBufferRequest{
+			MaxSize: b.MaxSize / (1024 * 1024),
+			MaxAge:  b.MaxAge.String(),
+		}
+	}
+	if t.runDeadline > 0 {
+		st.RunDeadline = t.runDeadline.String()
+	}
+	if w, ok := t.schedule.(*schedule.WindowedSchedule); ok {
+		st.WindowStop = w.StopOnTime()
+	}
+	return st, true
+}
+
+// toCoreSchedule returns the definition of a schedule, nil for the schedules
+// which can't be defined in a task manifest
+func toCoreSchedule(s schedule.Schedule) *core.Schedule {
+	switch v := s.(type) {
+	case *schedule.WindowedSchedule:
+		sch := &core.Schedule{
+			Type:           "windowed",
+			Interval:       v.Interval.String(),
+			StartTimestamp: v.StartTime,
+			StopTimestamp:  v.StopTime,
+			Count:          v.Count,
+			Jitter:         v.Jitter,
+		}
+		if r := v.Recurrence; r != nil {
+			sch.Timezone = r.Timezone
+			sch.Recurrence = &core.ScheduleRecurrence{
+				Start: r.Start,
+				Stop:  r.Stop,
+				Days:  r.Days,
+			}
+		}
+		return sch
+	case *schedule.CronSchedule:
+		return &core.Schedule{
+			Type:     "cron",
+			Interval: v.Entry(),
+			Timezone: v.Timezone(),
+		}
+	case *schedule.StreamingSchedule:
+		return &core.Schedule{
+			Type: "streaming",
+		}
+	}
+	return nil
+}
+
+// options returns the options the stored task is created with
+func (st storedTask) options() ([]core.TaskOption, error) {
+	opts := []core.TaskOption{
+		core.SetTaskID(st.ID),
+		core.SetTaskName(st.Name),
+		core.OptionStopOnFailure(st.MaxFailures),
+		core.SetMaxMetricsBuffer(st.MaxMetricsBuffer),
+		core.SetDependencies(st.DependsOn),
+	}
+	durations := []struct {
+		value string
+		opt   func(time.Duration) core.TaskOption
+	}{
+		{st.Deadline, core.TaskDeadlineDuration},
+		{st.MaxCollectDuration, core.SetMaxCollectDuration},
+		{st.RunDeadline, core.SetRunDeadline},
+	}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+		v, err := time.ParseDuration(d.value)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, d.opt(v))
+	}
+	if st.OverrunPolicy != "" {
+		opts = append(opts, core.SetOverrunPolicy(st.OverrunPolicy))
+	}
+	if st.Priority != "" {
+		opts = append(opts, core.SetPriority(st.Priority))
+	}
+	if len(st.Labels) > 0 {
+		opts = append(opts, core.SetLabels(st.Labels))
+	}
+	if st.Buffer != nil {
+		b, err := core.NewTaskBuffer(st.Buffer.MaxSize, st.Buffer.MaxAge)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, core.SetBuffer(b))
+	}
+	return opts, nil
+}
+
+// SetTaskStore persists the tasks in the file at the given path, the tasks
+// are restored from it when the scheduler is started
+func (s *scheduler) SetTaskStore(path string) {
+	s.taskStore = newTaskStore(path)
+	schedulerLogger.WithFields(log.Fields{
+		"_block": "set-task-store",
+		"path":   path,
+	}).Debug("task store set")
+}
+
+// restoreTasks creates the tasks found in the task file.  The tasks whose
+// plugins aren't loaded yet are restored once they are.
+func (s *scheduler) restoreTasks() {
+	if s.taskStore == nil {
+		return
+	}
+	sts, err := s.taskStore.load()
+	if err != nil {
+		taskStoreLogger.WithFields(log.Fields{
+			"_block": "restore-tasks",
+			"path":   s.taskStore.path,
+			"error":  err,
+		}).Error("unable to restore tasks")
+		return
+	}
+	s.pendingMutex.Lock()
+	s.pendingTasks = sts
+	s.pendingMutex.Unlock()
+	s.restorePendingTasks()
+}
+
+// hasPendingTasks returns true while some persisted tasks are not restored
+func (s *scheduler) hasPendingTasks() bool {
+	s.pendingMutex.Lock()
+	defer s.pendingMutex.Unlock()
+	return len(s.pendingTasks) > 0
+}
+
+// restorePendingTasks creates the persisted tasks which are not restored yet,
+// until none of them can be.  A task may depend on a task restored after it.
+func (s *scheduler) restorePendingTasks() {
+	s.restoreMutex.Lock()
+	defer s.restoreMutex.Unlock()
+
+	s.pendingMutex.Lock()
+	pending := s.pendingTasks
+	s.pendingMutex.Unlock()
+	restored := 0
+	errs := map[string]error{}
+	for progress := true; progress; {
+		progress = false
+		var left []storedTask
+		for _, st := range pending {
+			if err := s.restoreTask(st); err != nil {
+				errs[st.ID] = err
+				left = append(left, st)
+				continue
+			}
+			delete(errs, st.ID)
+			restored++
+			progress = true
+		}
+		pending = left
+		s.pendingMutex.Lock()
+		s.pendingTasks = left
+		s.pendingMutex.Unlock()
+	}
+	for _, st := range pending {
+		taskStoreLogger.WithFields(log.Fields{
+			"_block":  "restore-tasks",
+			"task-id": st.ID,
+			"error":   errs[st.ID],
+		}).Warning("task not restored, waiting for its plugins to be loaded")
+	}
+	if restored > 0 {
+		taskStoreLogger.WithFields(log.Fields{
+			"_block": "restore-tasks",
+			"path":   s.taskStore.path,
+			"tasks":  restored,
+		}).Info("tasks restored")
+		s.persistTasks()
+	}
+}
+
+// restoreTask creates a persisted task and brings it back to its state
+func (s *scheduler) restoreTask(st storedTask) error {
+	if st.Schedule == nil {
+		return ErrTaskStoreMissingSchedule
+	}
+	if s.tasks.Get(st.ID) != nil {
+		return ErrTaskHasAlreadyBeenAdded
+	}
+	sch, err := core.MakeSchedule(*st.Schedule)
+	if err != nil {
+		return err
+	}
+	opts, err := st.options()
+	if err != nil {
+		return err
+	}
+	if _, te := s.createTask(sch, st.Workflow, false, "restore", opts...); te != nil && len(te.Errors()) > 0 {
+		return te.Errors()[0]
+	}
+	t := s.tasks.Get(st.ID)
+	if t == nil {
+		return ErrTaskNotFound
+	}
+	t.failureMutex.Lock()
+	t.creationTime = st.CreationTime
+	t.lastFireTime = st.LastRunTime
+	t.hitCount = st.HitCount
+	t.missedIntervals = st.MissCount
+	t.failedRuns = st.FailedCount
+	t.lastFailureMessage = st.LastFailureMessage
+	t.overrunCount = st.OverrunCount
+	t.failureMutex.Unlock()
+	if w, ok := sch.(*schedule.WindowedSchedule); ok && st.WindowStop != nil {
+		w.RestoreStopOnTime(*st.WindowStop)
+	}
+
+	switch st.State {
+	case core.TaskStateLookup[core.TaskSpinning]:
+		if errs := s.startTask(t.id, "restore"); len(errs) > 0 {
+			taskStoreLogger.WithFields(log.Fields{
+				"_block":  "restore-task",
+				"task-id": t.id,
+				"error":   errs[0],
+			}).Error("unable to start restored task")
+		}
+	case core.TaskStateLookup[core.TaskPaused]:
+		if _, errs := t.SubscribePlugins(); len(errs) > 0 {
+			taskStoreLogger.WithFields(log.Fields{
+				"_block":  "restore-task",
+				"task-id": t.id,
+				"error":   errs[0],
+			}).Error("unable to subscribe the plugins of restored task")
+			break
+		}
+		if p, ok := t.schedule.(schedule.Pausable); ok {
+			p.Pause()
+		}
+		t.Lock()
+		t.state = core.TaskPaused
+		t.Unlock()
+	case core.TaskStateLookup[core.TaskDisabled]:
+		t.Lock()
+		t.state = core.TaskDisabled
+		t.Unlock()
+	case core.TaskStateLookup[core.TaskEnded]:
+		t.Lock()
+		t.state = core.TaskEnded
+		t.Unlock()
+	}
+	taskStoreLogger.WithFields(log.Fields{
+		"_block":     "restore-task",
+		"task-id":    t.id,
+		"task-state": t.State(),
+	}).Debug("task restored")
+	return nil
+}
+
+// persistTasks writes the current state of the tasks to the task file, along
+// with the persisted tasks which are not restored yet
+func (s *scheduler) persistTasks() {
+	if s.taskStore == nil || s.state != schedulerStarted {
+		return
+	}
+	sts := []storedTask{}
+	ids := map[string]bool{}
+	for _, t := range s.tasks.Table() {
+		if !t.persisted() {
+			continue
+		}
+		if st, ok := toStoredTask(t); ok {
+			sts = append(sts, st)
+			ids[st.ID] = true
+		}
+	}
+	s.pendingMutex.Lock()
+	for _, st := range s.pendingTasks {
+		if !ids[st.ID] {
+			sts = append(sts, st)
+		}
+	}
+	s.pendingMutex.Unlock()
+	// restore the tasks in the order they were created in, before the tasks
+	// depending on them
+	sort.Sort(storedTasksByCreation(sts))
+	if err := s.taskStore.save(sts); err != nil {
+		taskStoreLogger.WithFields(log.Fields{
+			"_block": "persist-tasks",
+			"path":   s.taskStore.path,
+			"error":  err,
+		}).Error("unable to persist tasks")
+	}
+}
+
+type storedTasksByCreation []storedTask
+
+func (s storedTasksByCreation) Len() int {
+	return len(s)
+}
+
+func (s storedTasksByCreation) Less(i, j int) bool {
+	return s[i].CreationTime.Before(s[j].CreationTime)
+}
+
+func (s storedTasksByCreation) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
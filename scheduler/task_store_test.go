@@ -0,0 +1,174 @@
+// +build legacy
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2017 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/intelsdi-x/gomit"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/control_event"
+	"github.com/intelsdi-x/snap/pkg/schedule"
+	"github.com/intelsdi-x/snap/scheduler/wmap"
+)
+
+func TestTaskStore(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+	Convey("taskStore", t, func() {
+		dir, err := ioutil.TempDir("", "snap-task-store")
+		So(err, ShouldBeNil)
+		Reset(func() { os.RemoveAll(dir) })
+		store := newTaskStore(filepath.Join(dir, "tasks", "tasks.json"))
+
+		Convey("returns no tasks without a task file", func() {
+			sts, err := store.load()
+			So(err, ShouldBeNil)
+			So(sts, ShouldBeEmpty)
+		})
+		Convey("loads the saved tasks", func() {
+			sts := []storedTask{{
+				ID:       "1234",
+				Name:     "foo",
+				State:    "Running",
+				Schedule: &core.Schedule{Type: "simple", Interval: "1s"},
+				Workflow: wmap.NewWorkflowMap(),
+				HitCount: 3,
+			}}
+			So(store.save(sts), ShouldBeNil)
+			loaded, err := store.load()
+			So(err, ShouldBeNil)
+			So(loaded, ShouldHaveLength, 1)
+			So(loaded[0].ID, ShouldEqual, "1234")
+			So(loaded[0].Schedule.Interval, ShouldEqual, "1s")
+			So(loaded[0].HitCount, ShouldEqual, 3)
+		})
+		Convey("returns an error for a corrupted task file", func() {
+			So(os.MkdirAll(filepath.Dir(store.path), 0755), ShouldBeNil)
+			So(ioutil.WriteFile(store.path, []byte("{"), 0600), ShouldBeNil)
+			_, err := store.load()
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestRestoreTasks(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+	Convey("A scheduler with a task store", t, func() {
+		dir, err := ioutil.TempDir("", "snap-task-store")
+		So(err, ShouldBeNil)
+		Reset(func() { os.RemoveAll(dir) })
+		path := filepath.Join(dir, "tasks.json")
+
+		w := wmap.NewWorkflowMap()
+		w.Collect.AddMetric("/foo/bar", 1)
+		w.Collect.Add(wmap.NewPublishNode("file", 1))
+		c := &mockRunMetricManager{}
+		s := New(GetDefaultConfig())
+		s.SetMetricManager(c)
+		s.SetTaskStore(path)
+		So(s.Start(), ShouldBeNil)
+
+		running, errs := s.CreateTask(schedule.NewWindowedSchedule(time.Hour, nil, nil, 0), w, true,
+			core.SetTaskName("running"), core.SetPriority(core.TaskPriorityHigh))
+		So(errs.Errors(), ShouldBeEmpty)
+		stopped, errs := s.CreateTask(schedule.NewCronSchedule("0 0 * * * *"), w, false, core.OptionStopOnFailure(3))
+		So(errs.Errors(), ShouldBeEmpty)
+		_, errs = s.CreateTaskTribe(schedule.NewWindowedSchedule(time.Hour, nil, nil, 0), w, false)
+		So(errs.Errors(), ShouldBeEmpty)
+		running.(*task).hitCount = 5
+		s.Stop()
+
+		Convey("persists the tasks not created by tribe", func() {
+			sts, err := newTaskStore(path).load()
+			So(err, ShouldBeNil)
+			So(sts, ShouldHaveLength, 2)
+			So(sts[0].ID, ShouldEqual, running.ID())
+			So(sts[0].State, ShouldEqual, "Running")
+			So(sts[1].ID, ShouldEqual, stopped.ID())
+			So(sts[1].State, ShouldEqual, "Stopped")
+		})
+		Convey("restores the tasks in their state on start", func() {
+			s2 := New(GetDefaultConfig())
+			s2.SetMetricManager(c)
+			s2.SetTaskStore(path)
+			So(s2.Start(), ShouldBeNil)
+			Reset(s2.Stop)
+			So(s2.GetTasks(), ShouldHaveLength, 2)
+
+			rt, err := s2.GetTask(running.ID())
+			So(err, ShouldBeNil)
+			So(rt.GetName(), ShouldEqual, "running")
+			So(rt.Priority(), ShouldEqual, core.TaskPriorityHigh)
+			So(rt.HitCount(), ShouldEqual, 5)
+			So(rt.State(), ShouldBeIn, core.TaskSpinning, core.TaskFiring)
+			So(rt.CreationTime().Equal(*running.CreationTime()), ShouldBeTrue)
+
+			st, err := s2.GetTask(stopped.ID())
+			So(err, ShouldBeNil)
+			So(st.State(), ShouldEqual, core.TaskStopped)
+			So(st.GetStopOnFailure(), ShouldEqual, 3)
+			So(st.Schedule().(*schedule.CronSchedule).Entry(), ShouldEqual, "0 0 * * * *")
+		})
+		Convey("restores the tasks waiting for their plugins once they are loaded", func() {
+			m := &mockRunMetricManager{}
+			m.failValidatingMetrics = true
+			s2 := New(GetDefaultConfig())
+			s2.SetMetricManager(m)
+			s2.SetTaskStore(path)
+			So(s2.Start(), ShouldBeNil)
+			Reset(s2.Stop)
+			So(s2.GetTasks(), ShouldBeEmpty)
+			So(s2.hasPendingTasks(), ShouldBeTrue)
+
+			Convey("and keeps persisting them meanwhile", func() {
+				s2.persistTasks()
+				sts, err := newTaskStore(path).load()
+				So(err, ShouldBeNil)
+				So(sts, ShouldHaveLength, 2)
+			})
+
+			m.failValidatingMetrics = false
+			s2.HandleGomitEvent(gomit.Event{Body: &control_event.LoadPluginEvent{Name: "file", Version: 1}})
+			for i := 0; i < 100 && s2.hasPendingTasks(); i++ {
+				time.Sleep(10 * time.Millisecond)
+			}
+			s2.restoreMutex.Lock()
+			defer s2.restoreMutex.Unlock()
+			So(s2.hasPendingTasks(), ShouldBeFalse)
+			So(s2.GetTasks(), ShouldHaveLength, 2)
+		})
+		Convey("doesn't persist the tasks when it's disabled", func() {
+			s2 := New(GetDefaultConfig())
+			s2.SetMetricManager(c)
+			So(s2.Start(), ShouldBeNil)
+			Reset(s2.Stop)
+			So(s2.GetTasks(), ShouldBeEmpty)
+		})
+	})
+}
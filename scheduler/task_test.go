@@ -22,6 +22,7 @@ limitations under the License.
 package scheduler
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -199,3 +200,194 @@ func TestTask(t *testing.T) {
 
 	})
 }
+
+func TestTaskDependencies(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+	Convey("Task dependencies", t, func() {
+		wf, errs := wmapToWorkflow(wmap.Sample())
+		So(errs, ShouldBeEmpty)
+		c := &mockMetricManager{}
+		sch := schedule.NewWindowedSchedule(time.Second, nil, nil, 0)
+		s := New(GetDefaultConfig())
+		upstream, err := newTask(sch, wf, newWorkManager(), c, emitter)
+		So(err, ShouldBeNil)
+		So(s.tasks.add(upstream), ShouldBeNil)
+		downstream, err := newTask(sch, wf, newWorkManager(), c, emitter, core.SetDependencies([]string{upstream.ID()}))
+		So(err, ShouldBeNil)
+		So(s.validateDependencies(downstream), ShouldBeNil)
+		downstream.tasks = s.tasks
+
+		Convey("Should wait for the first run of the dependencies", func() {
+			So(downstream.DependencyStates()[upstream.ID()], ShouldEqual, core.TaskDependencyWaiting)
+			So(downstream.dependenciesSatisfied(), ShouldBeFalse)
+		})
+		Convey("Should be satisfied once the most recent run of the dependencies succeeded", func() {
+			upstream.lastRunEnd = time.Now()
+			So(downstream.DependencyStates()[upstream.ID()], ShouldEqual, core.TaskDependencySatisfied)
+			So(downstream.dependenciesSatisfied(), ShouldBeTrue)
+			upstream.lastRunFailed = true
+			So(downstream.DependencyStates()[upstream.ID()], ShouldEqual, core.TaskDependencyFailed)
+			So(downstream.dependenciesSatisfied(), ShouldBeFalse)
+		})
+		Convey("Should report the removed dependencies", func() {
+			So(s.tasks.remove(upstream), ShouldBeNil)
+			So(downstream.DependencyStates()[upstream.ID()], ShouldEqual, core.TaskDependencyMissing)
+		})
+		Convey("Should reject the dependencies on unknown tasks", func() {
+			downstream.SetDependencies([]string{"unknown"})
+			err := s.validateDependencies(downstream)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, ErrTaskDependencyNotFound.Error())
+		})
+		Convey("Should reject the dependencies forming a cycle", func() {
+			downstream.SetDependencies([]string{downstream.ID()})
+			So(s.validateDependencies(downstream).Error(), ShouldEqual, ErrTaskDependencyCycle.Error())
+
+			// a task added again with the id of a task depended on
+			So(s.tasks.add(downstream), ShouldBeNil)
+			downstream.SetDependencies([]string{upstream.ID()})
+			So(s.tasks.remove(upstream), ShouldBeNil)
+			upstream.SetDependencies([]string{downstream.ID()})
+			So(s.validateDependencies(upstream).Error(), ShouldEqual, ErrTaskDependencyCycle.Error())
+		})
+		Convey("Should reject the dependencies of streaming tasks", func() {
+			stream, err := newTask(schedule.NewStreamingSchedule(), wf, newWorkManager(), c, emitter, core.SetDependencies([]string{upstream.ID()}))
+			So(err, ShouldBeNil)
+			So(s.validateDependencies(stream).Error(), ShouldEqual, ErrTaskDependencyStreaming.Error())
+		})
+	})
+}
+
+func TestTaskPauseResume(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+	Convey("Task pause and resume", t, func() {
+		wf, errs := wmapToWorkflow(wmap.Sample())
+		So(errs, ShouldBeEmpty)
+		c := &mockMetricManager{}
+		sch := schedule.NewWindowedSchedule(time.Millisecond*50, nil, nil, 0)
+		task, err := newTask(sch, wf, newWorkManager(), c, emitter)
+		So(err, ShouldBeNil)
+		task.Spin()
+		time.Sleep(time.Millisecond * 120)
+
+		task.Pause()
+		time.Sleep(time.Millisecond * 20)
+		So(task.State(), ShouldEqual, core.TaskPaused)
+		hits := task.HitCount()
+		lastFire := task.LastRunTime()
+		So(hits, ShouldBeGreaterThan, 0)
+
+		Convey("Should not fire while paused", func() {
+			time.Sleep(time.Millisecond * 120)
+			So(task.HitCount(), ShouldEqual, hits)
+			So(*task.LastRunTime(), ShouldResemble, *lastFire)
+		})
+		Convey("Should keep its counters once resumed", func() {
+			time.Sleep(time.Millisecond * 120)
+			task.Resume()
+			So(task.State(), ShouldNotEqual, core.TaskPaused)
+			So(task.HitCount(), ShouldBeGreaterThanOrEqualTo, hits)
+			So(task.MissedCount(), ShouldEqual, 0)
+		})
+		Convey("Should be stopped from the paused state", func() {
+			task.Stop()
+			So(task.State(), ShouldEqual, core.TaskStopped)
+		})
+		Convey("Should unsubscribe its plugins when killed from the paused state", func() {
+			unsubscribed := c.unsubscribed
+			task.Kill()
+			So(task.State(), ShouldEqual, core.TaskDisabled)
+			So(c.unsubscribed, ShouldBeGreaterThan, unsubscribed)
+		})
+		task.Stop()
+	})
+}
+
+type slowMetricManager struct {
+	mockMetricManager
+	delay time.Duration
+}
+
+func (m *slowMetricManager) CollectMetrics(string, map[string]map[string]string) ([]core.Metric, []error) {
+	time.Sleep(m.delay)
+	return nil, nil
+}
+
+func TestTaskOverrun(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+	Convey("Task runs exceeding the run deadline", t, func() {
+		c := &slowMetricManager{delay: time.Millisecond * 60}
+		newOverrunTask := func(policy string) *task {
+			wf, errs := wmapToWorkflow(wmap.Sample())
+			So(errs, ShouldBeEmpty)
+			sch := schedule.NewWindowedSchedule(time.Millisecond*20, nil, nil, 0)
+			task, err := newTask(sch, wf, newWorkManager(), c, emitter,
+				core.SetRunDeadline(time.Millisecond*10),
+				core.SetOverrunPolicy(policy),
+				core.OptionStopOnFailure(-1))
+			So(err, ShouldBeNil)
+			task.Spin()
+			time.Sleep(time.Millisecond * 200)
+			task.Stop()
+			time.Sleep(time.Millisecond * 100)
+			return task
+		}
+		Convey("Should be waited for with the queue policy", func() {
+			task := newOverrunTask(core.TaskOverrunQueue)
+			So(task.OverrunCount(), ShouldBeGreaterThan, 0)
+			So(task.FailedCount(), ShouldEqual, 0)
+		})
+		Convey("Should skip the next runs with the skip policy", func() {
+			task := newOverrunTask(core.TaskOverrunSkip)
+			So(task.OverrunCount(), ShouldBeGreaterThan, 0)
+			So(task.FailedCount(), ShouldEqual, 0)
+			// a run is only started once the previous one ended
+			So(task.HitCount(), ShouldBeLessThanOrEqualTo, 4)
+		})
+		Convey("Should be recorded as failed with the kill policy", func() {
+			task := newOverrunTask(core.TaskOverrunKill)
+			So(task.OverrunCount(), ShouldBeGreaterThan, 0)
+			// the jobs of the abandoned runs which weren't started fail too
+			So(task.FailedCount(), ShouldBeGreaterThanOrEqualTo, task.OverrunCount())
+			// a run isn't started while the jobs of the abandoned one are in flight
+			So(task.HitCount(), ShouldBeLessThanOrEqualTo, 4)
+		})
+	})
+}
+
+type failingMetricManager struct {
+	mockMetricManager
+}
+
+func (m *failingMetricManager) CollectMetrics(string, map[string]map[string]string) ([]core.Metric, []error) {
+	return nil, []error{errors.New("collection failed")}
+}
+
+func TestTaskConsecutiveFailures(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+	Convey("Task failing consecutively", t, func() {
+		wf, errs := wmapToWorkflow(wmap.Sample())
+		So(errs, ShouldBeEmpty)
+		c := &failingMetricManager{}
+		sch := schedule.NewWindowedSchedule(time.Millisecond, nil, nil, 0)
+		Convey("Should be disabled once it reaches its max failures", func() {
+			task, err := newTask(sch, wf, newWorkManager(), c, emitter, core.OptionStopOnFailure(3))
+			So(err, ShouldBeNil)
+			task.Spin()
+			time.Sleep(time.Millisecond * 100)
+			So(task.State(), ShouldEqual, core.TaskDisabled)
+			So(task.FailedCount(), ShouldEqual, 3)
+			So(task.Enable(), ShouldBeNil)
+			So(task.State(), ShouldEqual, core.TaskStopped)
+		})
+		Convey("Should back off when it's never disabled", func() {
+			task, err := newTask(sch, wf, newWorkManager(), c, emitter, core.OptionStopOnFailure(-1))
+			So(err, ShouldBeNil)
+			task.Spin()
+			time.Sleep(time.Millisecond * 200)
+			So(task.State(), ShouldNotEqual, core.TaskDisabled)
+			So(task.FailedCount(), ShouldEqual, FailureBackoffCount)
+			task.Stop()
+		})
+	})
+}
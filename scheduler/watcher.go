@@ -224,3 +224,69 @@ func (t *taskWatcherCollection) handleTaskDisabled(taskID string, why string) {
 		v.handler.CatchTaskDisabled(why)
 	}
 }
+
+func (t *taskWatcherCollection) handleTaskOverrun(taskID string, count uint) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	// no taskID means no watches, early exit
+	if t.coll[taskID] == nil || len(t.coll[taskID]) == 0 {
+		return
+	}
+	// Walk all watchers for a task ID
+	for _, v := range t.coll[taskID] {
+		// Only the handlers catching overruns are notified
+		h, ok := v.handler.(core.TaskOverrunWatcherHandler)
+		if !ok {
+			continue
+		}
+		watcherLog.WithFields(log.Fields{
+			"task-id":         taskID,
+			"task-watcher-id": v.id,
+		}).Debug("calling taskwatcher task overrun func")
+		h.CatchTaskOverrun(count)
+	}
+}
+
+func (t *taskWatcherCollection) handleTaskRun(taskID string, record core.TaskRunRecord) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	// no taskID means no watches, early exit
+	if t.coll[taskID] == nil || len(t.coll[taskID]) == 0 {
+		return
+	}
+	// Walk all watchers for a task ID
+	for _, v := range t.coll[taskID] {
+		// Only the handlers catching the records of the runs are notified
+		h, ok := v.handler.(core.TaskRunWatcherHandler)
+		if !ok {
+			continue
+		}
+		watcherLog.WithFields(log.Fields{
+			"task-id":         taskID,
+			"task-watcher-id": v.id,
+		}).Debug("calling taskwatcher task run func")
+		h.CatchTaskRun(record)
+	}
+}
+
+func (t *taskWatcherCollection) handleCircuitBreaker(taskID string, breaker core.TaskStepCircuitBreaker) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	// no taskID means no watches, early exit
+	if t.coll[taskID] == nil || len(t.coll[taskID]) == 0 {
+		return
+	}
+	// Walk all watchers for a task ID
+	for _, v := range t.coll[taskID] {
+		// Only the handlers catching the circuit breaker changes are notified
+		h, ok := v.handler.(core.TaskCircuitBreakerWatcherHandler)
+		if !ok {
+			continue
+		}
+		watcherLog.WithFields(log.Fields{
+			"task-id":         taskID,
+			"task-watcher-id": v.id,
+		}).Debug("calling taskwatcher circuit breaker func")
+		h.CatchCircuitBreaker(breaker)
+	}
+}
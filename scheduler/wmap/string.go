@@ -83,6 +83,10 @@ func (p *ProcessWorkflowMapNode) String(pad string) string {
 		out += pad + "      " + fmt.Sprintf("%s=%+v\n", k, v)
 	}
 	out += pad + "   Target:" + p.Target + "\n"
+	out += p.Retry.String(pad)
+	if p.Timeout != "" {
+		out += pad + "   Timeout: " + p.Timeout + "\n"
+	}
 
 	out += pad + "   Process Nodes:\n"
 	for _, pr := range p.Process {
@@ -104,5 +108,40 @@ func (p *PublishWorkflowMapNode) String(pad string) string {
 	for k, v := range p.Config {
 		out += pad + "      " + fmt.Sprintf("%s=%+v\n", k, v)
 	}
+	out += p.Retry.String(pad)
+	if p.Timeout != "" {
+		out += pad + "   Timeout: " + p.Timeout + "\n"
+	}
+	out += p.CircuitBreaker.String(pad)
+	out += p.Batch.String(pad)
+	out += p.RateLimit.String(pad)
 	return out
 }
+
+func (r *RetryWorkflowMapNode) String(pad string) string {
+	if r == nil {
+		return ""
+	}
+	return pad + fmt.Sprintf("   Retry: count=%d backoff=%s max-backoff=%s\n", r.Count, r.Backoff, r.MaxBackoff)
+}
+
+func (c *CircuitBreakerWorkflowMapNode) String(pad string) string {
+	if c == nil {
+		return ""
+	}
+	return pad + fmt.Sprintf("   Circuit Breaker: failures=%d cooldown=%s\n", c.Failures, c.Cooldown)
+}
+
+func (b *BatchWorkflowMapNode) String(pad string) string {
+	if b == nil {
+		return ""
+	}
+	return pad + fmt.Sprintf("   Batch: max-size=%d max-age=%s\n", b.MaxSize, b.MaxAge)
+}
+
+func (r *RateLimitWorkflowMapNode) String(pad string) string {
+	if r == nil {
+		return ""
+	}
+	return pad + fmt.Sprintf("   Rate Limit: metrics-per-second=%d bytes-per-second=%d overflow=%s\n", r.MetricsPerSecond, r.BytesPerSecond, r.Overflow)
+}
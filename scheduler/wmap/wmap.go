@@ -178,6 +178,9 @@ type CollectWorkflowMapNode struct {
 	Tags    map[string]map[string]string      `json:"tags,omitempty"yaml:"tags"`
 	Process []ProcessWorkflowMapNode          `json:"process,omitempty"yaml:"process"`
 	Publish []PublishWorkflowMapNode          `json:"publish,omitempty"yaml:"publish"`
+	// Join the collect steps run with this one, their metrics are merged with
+	// the metrics of this step before being processed and published.
+	Join []CollectWorkflowMapNode `json:"join,omitempty"yaml:"join"`
 }
 
 func (cw *CollectWorkflowMapNode) UnmarshalJSON(data []byte) error {
@@ -207,6 +210,10 @@ func (cw *CollectWorkflowMapNode) UnmarshalJSON(data []byte) error {
 			if err := json.Unmarshal(v, &cw.Publish); err != nil {
 				return err
 			}
+		case "join":
+			if err := json.Unmarshal(v, &cw.Join); err != nil {
+				return err
+			}
 		default:
 			return fmt.Errorf("Unrecognized key '%v' in collect workflow of task.", k)
 		}
@@ -293,6 +300,12 @@ type ProcessWorkflowMapNode struct {
 	// Config the configuration of a processor.
 	Config map[string]interface{} `json:"config,omitempty"yaml:"config"`
 	Target string                 `json:"target"yaml:"target"`
+	// Retry the retries of a failed process job within a task run.
+	Retry *RetryWorkflowMapNode `json:"retry,omitempty"yaml:"retry"`
+	// When the condition of the metrics passed to the processor and its children.
+	When *ConditionWorkflowMapNode `json:"when,omitempty"yaml:"when"`
+	// Timeout the longest time a process job is waited for (e.g. 2s).
+	Timeout string `json:"timeout,omitempty"yaml:"timeout"`
 }
 
 func (pw *ProcessWorkflowMapNode) UnmarshalJSON(data []byte) error {
@@ -326,6 +339,18 @@ func (pw *ProcessWorkflowMapNode) UnmarshalJSON(data []byte) error {
 			if err := json.Unmarshal(v, &pw.Target); err != nil {
 				return fmt.Errorf("%v (while parsing 'target')", err)
 			}
+		case "retry":
+			if err := json.Unmarshal(v, &pw.Retry); err != nil {
+				return err
+			}
+		case "when":
+			if err := json.Unmarshal(v, &pw.When); err != nil {
+				return err
+			}
+		case "timeout":
+			if err := json.Unmarshal(v, &pw.Timeout); err != nil {
+				return fmt.Errorf("%v (while parsing 'timeout')", err)
+			}
 		default:
 			return fmt.Errorf("Unrecognized key '%v' in process workflow of task.", k)
 		}
@@ -376,6 +401,18 @@ type PublishWorkflowMapNode struct {
 	// Config the config of a publisher
 	Config map[string]interface{} `json:"config,omitempty"yaml:"config"`
 	Target string                 `json:"target"yaml:"target"`
+	// Retry the retries of a failed publish job within a task run.
+	Retry *RetryWorkflowMapNode `json:"retry,omitempty"yaml:"retry"`
+	// When the condition of the metrics passed to the publisher.
+	When *ConditionWorkflowMapNode `json:"when,omitempty"yaml:"when"`
+	// Timeout the longest time a publish job is waited for (e.g. 2s).
+	Timeout string `json:"timeout,omitempty"yaml:"timeout"`
+	// CircuitBreaker skips the publisher for a while once its jobs keep failing.
+	CircuitBreaker *CircuitBreakerWorkflowMapNode `json:"circuit-breaker,omitempty"yaml:"circuit-breaker"`
+	// Batch coalesces the metrics of several runs into a single publish job.
+	Batch *BatchWorkflowMapNode `json:"batch,omitempty"yaml:"batch"`
+	// RateLimit caps the metrics or bytes published per second.
+	RateLimit *RateLimitWorkflowMapNode `json:"rate-limit,omitempty"yaml:"rate-limit"`
 }
 
 func (pw *PublishWorkflowMapNode) UnmarshalJSON(data []byte) error {
@@ -401,6 +438,30 @@ func (pw *PublishWorkflowMapNode) UnmarshalJSON(data []byte) error {
 			if err := json.Unmarshal(v, &pw.Target); err != nil {
 				return fmt.Errorf("%v (while parsing 'target')", err)
 			}
+		case "retry":
+			if err := json.Unmarshal(v, &pw.Retry); err != nil {
+				return err
+			}
+		case "when":
+			if err := json.Unmarshal(v, &pw.When); err != nil {
+				return err
+			}
+		case "timeout":
+			if err := json.Unmarshal(v, &pw.Timeout); err != nil {
+				return fmt.Errorf("%v (while parsing 'timeout')", err)
+			}
+		case "circuit-breaker":
+			if err := json.Unmarshal(v, &pw.CircuitBreaker); err != nil {
+				return err
+			}
+		case "batch":
+			if err := json.Unmarshal(v, &pw.Batch); err != nil {
+				return err
+			}
+		case "rate-limit":
+			if err := json.Unmarshal(v, &pw.RateLimit); err != nil {
+				return err
+			}
 		default:
 			return fmt.Errorf("Unrecognized key '%v' in publish workflow of task.", k)
 		}
@@ -430,6 +491,179 @@ func (p *PublishWorkflowMapNode) GetConfigNode() (*cdata.ConfigDataNode, error)
 	return configtoConfigDataNode(p.Config, "")
 }
 
+// RetryWorkflowMapNode holds the retries of a failed process or publish job.
+// The job is retried up to Count times, waiting Backoff before the first
+// retry and twice as long before each next one, up to MaxBackoff.
+type RetryWorkflowMapNode struct {
+	Count      int    `json:"count"yaml:"count"`
+	Backoff    string `json:"backoff,omitempty"yaml:"backoff"`
+	MaxBackoff string `json:"max-backoff,omitempty"yaml:"max-backoff"`
+}
+
+func (r *RetryWorkflowMapNode) UnmarshalJSON(data []byte) error {
+	t := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &t); err != nil {
+		return err
+	}
+	for k, v := range t {
+		switch k {
+		case "count":
+			if err := json.Unmarshal(v, &r.Count); err != nil {
+				return fmt.Errorf("%v (while parsing 'count')", err)
+			}
+		case "backoff":
+			if err := json.Unmarshal(v, &r.Backoff); err != nil {
+				return fmt.Errorf("%v (while parsing 'backoff')", err)
+			}
+		case "max-backoff":
+			if err := json.Unmarshal(v, &r.MaxBackoff); err != nil {
+				return fmt.Errorf("%v (while parsing 'max-backoff')", err)
+			}
+		default:
+			return fmt.Errorf("Unrecognized key '%v' in retry of workflow of task.", k)
+		}
+	}
+	return nil
+}
+
+// CircuitBreakerWorkflowMapNode holds the circuit breaker of a publisher.  The
+// breaker opens after Failures consecutive failed publish jobs, the publisher
+// is then skipped until Cooldown passed and a single job is tried again.
+type CircuitBreakerWorkflowMapNode struct {
+	Failures int    `json:"failures"yaml:"failures"`
+	Cooldown string `json:"cooldown,omitempty"yaml:"cooldown"`
+}
+
+func (c *CircuitBreakerWorkflowMapNode) UnmarshalJSON(data []byte) error {
+	t := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &t); err != nil {
+		return err
+	}
+	for k, v := range t {
+		switch k {
+		case "failures":
+			if err := json.Unmarshal(v, &c.Failures); err != nil {
+				return fmt.Errorf("%v (while parsing 'failures')", err)
+			}
+		case "cooldown":
+			if err := json.Unmarshal(v, &c.Cooldown); err != nil {
+				return fmt.Errorf("%v (while parsing 'cooldown')", err)
+			}
+		default:
+			return fmt.Errorf("Unrecognized key '%v' in circuit breaker of workflow of task.", k)
+		}
+	}
+	return nil
+}
+
+// BatchWorkflowMapNode holds the batching of a publisher.  The metrics of the
+// runs are published once MaxSize metrics are batched or the oldest of them
+// was batched MaxAge ago.
+type BatchWorkflowMapNode struct {
+	MaxSize int    `json:"max-size,omitempty"yaml:"max-size"`
+	MaxAge  string `json:"max-age,omitempty"yaml:"max-age"`
+}
+
+func (b *BatchWorkflowMapNode) UnmarshalJSON(data []byte) error {
+	t := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &t); err != nil {
+		return err
+	}
+	for k, v := range t {
+		switch k {
+		case "max-size":
+			if err := json.Unmarshal(v, &b.MaxSize); err != nil {
+				return fmt.Errorf("%v (while parsing 'max-size')", err)
+			}
+		case "max-age":
+			if err := json.Unmarshal(v, &b.MaxAge); err != nil {
+				return fmt.Errorf("%v (while parsing 'max-age')", err)
+			}
+		default:
+			return fmt.Errorf("Unrecognized key '%v' in batch of workflow of task.", k)
+		}
+	}
+	return nil
+}
+
+// RateLimitWorkflowMapNode holds the rate limit of a publisher.  At most
+// MetricsPerSecond metrics and BytesPerSecond bytes are published per second,
+// the metrics over the limit are handled according to Overflow: "drop-oldest",
+// "drop-newest" or "block".
+type RateLimitWorkflowMapNode struct {
+	MetricsPerSecond int    `json:"metrics-per-second,omitempty"yaml:"metrics-per-second"`
+	BytesPerSecond   int    `json:"bytes-per-second,omitempty"yaml:"bytes-per-second"`
+	Overflow         string `json:"overflow,omitempty"yaml:"overflow"`
+}
+
+func (r *RateLimitWorkflowMapNode) UnmarshalJSON(data []byte) error {
+	t := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &t); err != nil {
+		return err
+	}
+	for k, v := range t {
+		switch k {
+		case "metrics-per-second":
+			if err := json.Unmarshal(v, &r.MetricsPerSecond); err != nil {
+				return fmt.Errorf("%v (while parsing 'metrics-per-second')", err)
+			}
+		case "bytes-per-second":
+			if err := json.Unmarshal(v, &r.BytesPerSecond); err != nil {
+				return fmt.Errorf("%v (while parsing 'bytes-per-second')", err)
+			}
+		case "overflow":
+			if err := json.Unmarshal(v, &r.Overflow); err != nil {
+				return fmt.Errorf("%v (while parsing 'overflow')", err)
+			}
+		default:
+			return fmt.Errorf("Unrecognized key '%v' in rate limit of workflow of task.", k)
+		}
+	}
+	return nil
+}
+
+// ConditionWorkflowMapNode holds the condition of the metrics passed to a
+// branch of a workflow: only the metrics whose namespace matches Namespace,
+// which have all the Tags and whose value is within ValueAbove and ValueBelow
+// are passed to the node.
+type ConditionWorkflowMapNode struct {
+	// Namespace a pattern the namespace matches, '*' matches an element (e.g. /intel/mock/*)
+	Namespace  string            `json:"namespace,omitempty"yaml:"namespace"`
+	Tags       map[string]string `json:"tags,omitempty"yaml:"tags"`
+	ValueAbove *float64          `json:"value_above,omitempty"yaml:"value_above"`
+	ValueBelow *float64          `json:"value_below,omitempty"yaml:"value_below"`
+}
+
+func (c *ConditionWorkflowMapNode) UnmarshalJSON(data []byte) error {
+	t := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &t); err != nil {
+		return err
+	}
+	for k, v := range t {
+		switch k {
+		case "namespace":
+			if err := json.Unmarshal(v, &c.Namespace); err != nil {
+				return fmt.Errorf("%v (while parsing 'namespace')", err)
+			}
+		case "tags":
+			if err := json.Unmarshal(v, &c.Tags); err != nil {
+				return fmt.Errorf("%v (while parsing 'tags')", err)
+			}
+		case "value_above":
+			if err := json.Unmarshal(v, &c.ValueAbove); err != nil {
+				return fmt.Errorf("%v (while parsing 'value_above')", err)
+			}
+		case "value_below":
+			if err := json.Unmarshal(v, &c.ValueBelow); err != nil {
+				return fmt.Errorf("%v (while parsing 'value_below')", err)
+			}
+		default:
+			return fmt.Errorf("Unrecognized key '%v' in condition of workflow of task.", k)
+		}
+	}
+	return nil
+}
+
 type metricInfo struct {
 	Version_ int `json:"version"yaml:"version"`
 }
@@ -19,7 +19,10 @@ limitations under the License.
 
 package scheduler
 
-import "sync"
+import (
+	"sync"
+	"time"
+)
 
 /*
 
@@ -61,6 +64,13 @@ type workManager struct {
 	processchan    chan queuedJob
 	kill           chan struct{}
 	mutex          *sync.Mutex
+
+	// autoscale grows and shrinks the worker pools, between their initial
+	// size and maxWkrSize, depending on the latency of their queue
+	autoscale   bool
+	maxWkrSize  uint
+	maxQLatency time.Duration
+	scales      map[jobType]*poolScale
 }
 
 type workManagerState int
@@ -180,6 +190,11 @@ func newWorkManager(opts ...workManagerOption) *workManager {
 		wm.processWkrs[i] = newWorker(wm.processchan)
 		go wm.processWkrs[i].start()
 	}
+	wm.scales = map[jobType]*poolScale{}
+	for _, t := range poolJobTypes {
+		_, _, size, _ := wm.pool(t)
+		wm.scales[t] = &poolScale{min: *size}
+	}
 	return wm
 }
 
@@ -192,8 +207,12 @@ func (w *workManager) Start() {
 	if w.state == workManagerStopped {
 		w.state = workManagerRunning
 		go func() {
+			ticker := time.NewTicker(poolSampleInterval)
+			defer ticker.Stop()
 			for {
 				select {
+				case <-ticker.C:
+					w.sample()
 				case <-w.collectq.Err:
 					//TODO: log error
 				case <-w.processq.Err:
@@ -235,28 +254,59 @@ func (w *workManager) Work(j job) queuedJob {
 // AddCollectWorker adds a new worker to
 // the collector worker pool
 func (w *workManager) AddCollectWorker() {
-	nw := newWorker(w.collectchan)
-	go nw.start()
-	w.collectWkrs = append(w.collectWkrs, nw)
-	w.collectWkrSize++
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.addWorker(collectJobType)
 }
 
 // AddPublishWorker adds a new worker to
 // the publisher worker pool
 func (w *workManager) AddPublishWorker() {
-	nw := newWorker(w.publishchan)
-	go nw.start()
-	w.publishWkrs = append(w.publishWkrs, nw)
-	w.publishWkrSize++
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.addWorker(publishJobType)
 }
 
 // AddProcessWorker adds a new worker to
 // the processor worker pool
 func (w *workManager) AddProcessWorker() {
-	nw := newWorker(w.processchan)
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.addWorker(processJobType)
+}
+
+// pool returns the queue, the workers, the size and the channel of the worker
+// pool running the jobs of the given type
+func (w *workManager) pool(t jobType) (*queue, *[]*worker, *uint, chan queuedJob) {
+	switch t {
+	case publishJobType:
+		return w.publishq, &w.publishWkrs, &w.publishWkrSize, w.publishchan
+	case processJobType:
+		return w.processq, &w.processWkrs, &w.processWkrSize, w.processchan
+	}
+	return w.collectq, &w.collectWkrs, &w.collectWkrSize, w.collectchan
+}
+
+// addWorker adds a worker to a pool, the caller must hold the mutex
+func (w *workManager) addWorker(t jobType) {
+	_, wkrs, size, rcv := w.pool(t)
+	nw := newWorker(rcv)
 	go nw.start()
-	w.processWkrs = append(w.processWkrs, nw)
-	w.processWkrSize++
+	*wkrs = append(*wkrs, nw)
+	*size++
+}
+
+// removeWorker removes a worker from a pool once it finished its current
+// job, the caller must hold the mutex
+func (w *workManager) removeWorker(t jobType) {
+	_, wkrs, size, _ := w.pool(t)
+	if len(*wkrs) == 0 {
+		return
+	}
+	last := (*wkrs)[len(*wkrs)-1]
+	close(last.kamikaze)
+	*wkrs = (*wkrs)[:len(*wkrs)-1]
+	*size--
 }
 
 // sendToWorker is the handler given to the queue.
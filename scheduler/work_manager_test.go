@@ -235,6 +235,61 @@ func TestWorkerManager(t *testing.T) {
 		})
 	})
 
+	Convey("sample()", t, func() {
+		Convey("it grows and shrinks the autoscaled pools", func() {
+			mgr := newWorkManager(CollectWkrSizeOption(1), AutoscaleOption(true, 3, 10*time.Millisecond))
+			for i := 0; i < 3; i++ {
+				// the jobs waited longer than the max latency
+				mgr.collectq.waited, mgr.collectq.dispatched = 50*time.Millisecond, 1
+				mgr.sample()
+			}
+			So(mgr.collectWkrSize, ShouldEqual, 3)
+			So(mgr.collectWkrSize, ShouldEqual, len(mgr.collectWkrs))
+
+			stats := mgr.stats()
+			So(stats, ShouldHaveLength, 3)
+			So(stats[0].Pool, ShouldEqual, "collect")
+			So(stats[0].Workers, ShouldEqual, 3)
+			So(stats[0].MinWorkers, ShouldEqual, 1)
+			So(stats[0].MaxWorkers, ShouldEqual, 3)
+			So(stats[0].QueueLatency, ShouldEqual, 50*time.Millisecond)
+
+			for i := 0; i < poolIdleSamples; i++ {
+				mgr.sample()
+			}
+			So(mgr.collectWkrSize, ShouldEqual, 2)
+			So(mgr.collectWkrSize, ShouldEqual, len(mgr.collectWkrs))
+		})
+		Convey("it doesn't resize the pools which aren't autoscaled", func() {
+			mgr := newWorkManager(CollectWkrSizeOption(1))
+			mgr.collectq.waited, mgr.collectq.dispatched = 50*time.Millisecond, 1
+			mgr.sample()
+			So(mgr.collectWkrSize, ShouldEqual, 1)
+			So(mgr.stats()[0].MaxWorkers, ShouldEqual, 1)
+		})
+	})
+
+	Convey("resize()", t, func() {
+		Convey("it grows and shrinks the pools to their new size", func() {
+			mgr := newWorkManager(CollectWkrSizeOption(1), PublishWkrSizeOption(4))
+			mgr.resize(map[jobType]uint{collectJobType: 3, publishJobType: 2}, false, 0, 0)
+			So(mgr.collectWkrSize, ShouldEqual, 3)
+			So(mgr.collectWkrSize, ShouldEqual, len(mgr.collectWkrs))
+			So(mgr.publishWkrSize, ShouldEqual, 2)
+			So(mgr.publishWkrSize, ShouldEqual, len(mgr.publishWkrs))
+			So(mgr.stats()[0].MinWorkers, ShouldEqual, 3)
+		})
+		Convey("it keeps the workers of an autoscaled pool up to the max size", func() {
+			mgr := newWorkManager(CollectWkrSizeOption(4))
+			mgr.resize(map[jobType]uint{collectJobType: 2}, true, 6, 10*time.Millisecond)
+			So(mgr.collectWkrSize, ShouldEqual, 4)
+			So(mgr.stats()[0].MinWorkers, ShouldEqual, 2)
+			So(mgr.stats()[0].MaxWorkers, ShouldEqual, 6)
+			mgr.resize(map[jobType]uint{collectJobType: 2}, false, 6, 10*time.Millisecond)
+			So(mgr.collectWkrSize, ShouldEqual, 2)
+		})
+	})
+
 	Convey("Stop()", t, func() {
 		Convey("Stops the queue and the workers", func() {
 			mgr := newWorkManager()
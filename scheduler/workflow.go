@@ -21,6 +21,7 @@ package scheduler
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"sync"
 	"time"
@@ -30,7 +31,9 @@ import (
 
 	"github.com/intelsdi-x/snap/core"
 	"github.com/intelsdi-x/snap/core/cdata"
+	"github.com/intelsdi-x/snap/core/ctypes"
 	"github.com/intelsdi-x/snap/core/scheduler_event"
+	"github.com/intelsdi-x/snap/pkg/tracing"
 	"github.com/intelsdi-x/snap/scheduler/wmap"
 )
 
@@ -54,6 +57,8 @@ var (
 
 	ErrNullCollectNode        = errors.New("Missing collection node in workflow map")
 	ErrNoMetricsInCollectNode = errors.New("Collection node has not metrics defined to collect")
+	// ErrJoinedCollectNodeChildren - The error message for when a joined collect step has process, publish or join nodes
+	ErrJoinedCollectNodeChildren = errors.New("Joined collection node can't have process, publish or join nodes")
 )
 
 // WmapToWorkflow attempts to convert a wmap.WorkflowMap to a schedulerWorkflow instance.
@@ -83,23 +88,28 @@ func convertCollectionNode(cnode *wmap.CollectWorkflowMapNode, wf *schedulerWork
 	if len(cnode.Metrics) < 1 {
 		return ErrNoMetricsInCollectNode
 	}
-	// Get core.RequestedMetric metrics
-	mts := cnode.GetMetrics()
-	wf.metrics = make([]core.RequestedMetric, len(mts))
-	for i, m := range mts {
-		wf.metrics[i] = &metric{namespace: core.NewNamespace(m.Namespace()...), version: m.Version()}
-	}
-	// get tags defined
-	wf.tags = cnode.GetTags()
-
-	// Get our config data tree
-	cdt, err := cnode.GetConfigTree()
+	steps, err := convertCollectSteps(cnode)
 	if err != nil {
 		return err
 	}
-	wf.configTree = cdt
+	wf.collectSteps = steps
+	// The metrics of all the steps are subscribed to with the merged config
+	// and tags of the steps
+	for _, step := range steps {
+		wf.metrics = append(wf.metrics, step.metrics...)
+	}
+	if len(steps) == 1 {
+		wf.tags = steps[0].tags
+		wf.configTree = steps[0].configTree
+	} else {
+		joined := joinCollectNodes(cnode)
+		wf.tags = joined.Tags
+		if wf.configTree, err = joined.GetConfigTree(); err != nil {
+			return err
+		}
+	}
 	// Iterate over first level process nodes
-	pr, err := convertProcessNode(cnode.Process)
+	pr, err := convertProcessNode(cnode.Process, "")
 	if err != nil {
 		return err
 	}
@@ -113,14 +123,18 @@ func convertCollectionNode(cnode *wmap.CollectWorkflowMapNode, wf *schedulerWork
 	return nil
 }
 
-func convertProcessNode(pr []wmap.ProcessWorkflowMapNode) ([]*processNode, error) {
+// convertProcessNode converts the process nodes under the node at the parent
+// position, the position of a node is the one of its parent followed by its
+// index, e.g. "/0/1"
+func convertProcessNode(pr []wmap.ProcessWorkflowMapNode, parent string) ([]*processNode, error) {
 	prNodes := make([]*processNode, len(pr))
 	for i, p := range pr {
 		cdn, err := p.GetConfigNode()
 		if err != nil {
 			return nil, err
 		}
-		prC, err := convertProcessNode(p.Process)
+		position := fmt.Sprintf("%s/%d", parent, i)
+		prC, err := convertProcessNode(p.Process, position)
 		if err != nil {
 			return nil, err
 		}
@@ -128,6 +142,18 @@ func convertProcessNode(pr []wmap.ProcessWorkflowMapNode) ([]*processNode, error
 		if err != nil {
 			return nil, err
 		}
+		retry, err := newRetryPolicy(p.Retry)
+		if err != nil {
+			return nil, err
+		}
+		when, err := newCondition(p.When)
+		if err != nil {
+			return nil, err
+		}
+		timeout, err := newStepTimeout(p.Timeout)
+		if err != nil {
+			return nil, err
+		}
 
 		// If version is not 1+ we use -1 to indicate we want
 		// the plugin manager to select the highest version
@@ -140,9 +166,13 @@ func convertProcessNode(pr []wmap.ProcessWorkflowMapNode) ([]*processNode, error
 			name:         p.PluginName,
 			version:      p.PluginVersion,
 			config:       cdn,
+			position:     position,
 			Target:       p.Target,
 			ProcessNodes: prC,
 			PublishNodes: puC,
+			retry:        retry,
+			when:         when,
+			timeout:      timeout,
 		}
 	}
 	return prNodes, nil
@@ -156,6 +186,30 @@ func convertPublishNode(pu []wmap.PublishWorkflowMapNode) ([]*publishNode, error
 		if err != nil {
 			return nil, err
 		}
+		retry, err := newRetryPolicy(p.Retry)
+		if err != nil {
+			return nil, err
+		}
+		when, err := newCondition(p.When)
+		if err != nil {
+			return nil, err
+		}
+		timeout, err := newStepTimeout(p.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		breaker, err := newCircuitBreaker(p.CircuitBreaker)
+		if err != nil {
+			return nil, err
+		}
+		batch, err := newPublishBatch(p.Batch)
+		if err != nil {
+			return nil, err
+		}
+		limit, err := newRateLimit(p.RateLimit)
+		if err != nil {
+			return nil, err
+		}
 		// If version is not 1+ we use -1 to indicate we want
 		// the plugin manager to select the highest version
 		// available on plugin calls
@@ -168,6 +222,12 @@ func convertPublishNode(pu []wmap.PublishWorkflowMapNode) ([]*publishNode, error
 			version: p.PluginVersion,
 			config:  cdn,
 			Target:  p.Target,
+			retry:   retry,
+			when:    when,
+			timeout: timeout,
+			breaker: breaker,
+			batch:   batch,
+			limit:   limit,
 		}
 	}
 	return puNodes, nil
@@ -185,16 +245,29 @@ type schedulerWorkflow struct {
 	workflowMap  *wmap.WorkflowMap
 	eventEmitter gomit.Emitter
 	tags         map[string]map[string]string
+	// collectSteps are the steps collecting the metrics, the metrics of the
+	// steps joined to the first one are merged into a single batch
+	collectSteps []*collectStep
+	// tracer traces the runs of the workflow, nil when they aren't traced
+	tracer *tracing.Tracer
 }
 
 type processNode struct {
-	name               string
-	version            int
-	config             *cdata.ConfigDataNode
+	name    string
+	version int
+	config  *cdata.ConfigDataNode
+	// position identifies the node in the workflow of the task
+	position           string
 	Target             string
 	ProcessNodes       []*processNode
 	PublishNodes       []*publishNode
 	InboundContentType string
+	// retry is nil when the failed jobs of the node aren't retried
+	retry *retryPolicy
+	// when is nil when all the metrics are passed to the node
+	when *condition
+	// timeout is zero when the jobs of the node are waited for until done
+	timeout time.Duration
 }
 
 func (p *processNode) Name() string {
@@ -209,6 +282,18 @@ func (p *processNode) Config() *cdata.ConfigDataNode {
 	return p.config
 }
 
+// jobConfig returns the config of the process jobs of the node, it tells
+// control the position of the node in the workflow
+func (p *processNode) jobConfig() map[string]ctypes.ConfigValue {
+	table := p.config.Table()
+	config := make(map[string]ctypes.ConfigValue, len(table)+1)
+	for k, v := range table {
+		config[k] = v
+	}
+	config[core.WorkflowNodeConfigKey] = ctypes.ConfigValueStr{Value: p.position}
+	return config
+}
+
 func (p *processNode) TypeName() string {
 	return "processor"
 }
@@ -219,6 +304,18 @@ type publishNode struct {
 	config             *cdata.ConfigDataNode
 	Target             string
 	InboundContentType string
+	// retry is nil when the failed jobs of the node aren't retried
+	retry *retryPolicy
+	// when is nil when all the metrics are passed to the node
+	when *condition
+	// timeout is zero when the jobs of the node are waited for until done
+	timeout time.Duration
+	// breaker is nil when the node is run on every run of the task
+	breaker *circuitBreaker
+	// batch is nil when the metrics of every run are published
+	batch *publishBatch
+	// limit is nil when the metrics are published as fast as they come
+	limit *rateLimit
 }
 
 func (p *publishNode) Name() string {
@@ -239,22 +336,34 @@ func (p *publishNode) TypeName() string {
 
 type wfContentTypes map[string]map[string][]string
 
-// Start starts a workflow
-func (s *schedulerWorkflow) Start(t *task) {
+// Start starts a workflow, the process and publish jobs aren't dispatched once
+// the run is abandoned
+func (s *schedulerWorkflow) Start(t *task, abandoned <-chan struct{}) {
 	workflowLogger.WithFields(log.Fields{
 		"_block":    "workflow-start",
 		"task-id":   t.id,
 		"task-name": t.name,
 	}).Debug("Starting workflow")
 	s.state = WorkflowStarted
-	j := newCollectorJob(s.metrics, t.deadlineDuration, t.metricsManager, t.workflow.configTree, t.id, s.tags)
-
-	// dispatch 'collect' job to be worked
-	// Block until the job has been either run or skipped.
-	errors := t.manager.Work(j).Promise().Await()
+	// the record of the run is sent once all its steps ended
+	rec := newRunRecorder()
+	rec.span = s.startRunSpan(t)
+	defer func() {
+		rec.span.Finish()
+		s.eventEmitter.Emit(&scheduler_event.TaskRunEvent{TaskID: t.id, Record: rec.end()})
+	}()
+	deadline := t.deadlineDuration
+	// the jobs of a run abandoned once past its deadline aren't started
+	if t.overrunPolicy == core.TaskOverrunKill && t.runDeadline > 0 && t.runDeadline < deadline {
+		deadline = t.runDeadline
+	}
+	// dispatch 'collect' jobs to be worked
+	// Block until the jobs have been either run or skipped.
+	j, errors := s.collect(t, deadline, rec)
 
 	if len(errors) > 0 {
 		t.RecordFailure(errors)
+		rec.span.SetError(errors)
 		event := new(scheduler_event.MetricCollectionFailedEvent)
 		event.TaskID = t.id
 		event.Errors = errors
@@ -262,16 +371,119 @@ func (s *schedulerWorkflow) Start(t *task) {
 		return
 	}
 
+	select {
+	case <-abandoned:
+		workflowLogger.WithFields(log.Fields{
+			"_block":    "workflow-start",
+			"task-id":   t.id,
+			"task-name": t.name,
+		}).Debug("Run abandoned, not dispatching its process and publish jobs")
+		return
+	default:
+	}
+
 	// Send event
 	event := new(scheduler_event.MetricCollectedEvent)
 	event.TaskID = t.id
-	event.Metrics = j.(*collectorJob).metrics
+	event.Metrics = j.metrics
 	defer s.eventEmitter.Emit(event)
 
 	// walk through the tree and dispatch work
 	workJobs(s.processNodes, s.publishNodes, t, j)
 }
 
+// collectorStep is the name of the collect step in the records of the runs
+const collectorStep = "collector"
+
+// runRecorder records the results of the steps of a scheduled run of a task
+// workflow, the process and publish steps end concurrently
+type runRecorder struct {
+	sync.Mutex
+	record core.TaskRunRecord
+	// span traces the run, nil when the run isn't traced
+	span *tracing.Span
+}
+
+func newRunRecorder() *runRecorder {
+	return &runRecorder{record: core.TaskRunRecord{Start: time.Now()}}
+}
+
+// add records the result of a step of the run
+func (r *runRecorder) add(step string, metrics int, errs []error) {
+	if r == nil {
+		return
+	}
+	s := core.TaskRunStep{Step: step, Metrics: metrics}
+	for _, err := range errs {
+		s.Errors = append(s.Errors, err.Error())
+	}
+	r.Lock()
+	defer r.Unlock()
+	r.record.Steps = append(r.record.Steps, s)
+}
+
+// runSpan returns the span of the run, nil when the run isn't traced
+func (r *runRecorder) runSpan() *tracing.Span {
+	if r == nil {
+		return nil
+	}
+	return r.span
+}
+
+// end returns the record of the run once all its steps ended
+func (r *runRecorder) end() core.TaskRunRecord {
+	r.Lock()
+	defer r.Unlock()
+	r.record.Duration = time.Since(r.record.Start)
+	return r.record
+}
+
+type workflowNode interface {
+	Name() string
+	Version() int
+	TypeName() string
+}
+
+// recordStep records the result of the step of a process or publish node in
+// the record of the run of the job the step follows
+func recordStep(pj job, n workflowNode, metrics int, errs []error) {
+	if rj, ok := pj.(recordedJob); ok {
+		step := fmt.Sprintf("%s:%s:%d", n.TypeName(), n.Name(), n.Version())
+		rj.recorder().add(step, metrics, errs)
+	}
+}
+
+// startRunSpan starts the span of a run of the workflow, the root of the spans
+// of its steps
+func (s *schedulerWorkflow) startRunSpan(t *task) *tracing.Span {
+	span := s.tracer.StartSpan("workflow")
+	span.SetTag("task_id", t.id)
+	span.SetTag("task_name", t.name)
+	return span
+}
+
+// startStepSpan starts the span of the step of a process or publish node, the
+// child of the span of the step the metrics come from, or of the span of the
+// run
+func startStepSpan(pj job, n workflowNode, t *task) *tracing.Span {
+	var parent *tracing.Span
+	if tj, ok := pj.(tracedJob); ok {
+		parent = tj.span()
+	}
+	if parent == nil {
+		if rj, ok := pj.(recordedJob); ok {
+			parent = rj.recorder().runSpan()
+		}
+	}
+	span := parent.StartChild(fmt.Sprintf("%s:%s", n.TypeName(), n.Name()))
+	span.SetTag("task_id", t.id)
+	span.SetTag("plugin_type", n.TypeName())
+	span.SetTag("plugin_name", n.Name())
+	span.SetTag("plugin_version", n.Version())
+	span.SetTag("batch_size", len(pj.Metrics()))
+	return span
+}
+
 func (s *schedulerWorkflow) State() WorkflowState {
 	return s.state
 }
@@ -289,6 +501,15 @@ func (s *schedulerWorkflow) StreamStart(t *task, metrics []core.Metric) {
 		configDataTree: t.workflow.configTree,
 		tags:           t.workflow.tags,
 	}
+	j.setPriority(t.priority)
+	rec := newRunRecorder()
+	rec.span = s.startRunSpan(t)
+	j.run = rec
+	rec.add(collectorStep, len(metrics), nil)
+	defer func() {
+		rec.span.Finish()
+		s.eventEmitter.Emit(&scheduler_event.TaskRunEvent{TaskID: t.id, Record: rec.end()})
+	}()
 	// Send event
 	event := new(scheduler_event.MetricCollectedEvent)
 	event.TaskID = t.id
@@ -343,10 +564,19 @@ func workJobs(prs []*processNode, pus []*publishNode, t *task, pj job) {
 func submitProcessJob(pj job, t *task, wg *sync.WaitGroup, pr *processNode) {
 	// Decrement the waitgroup
 	defer wg.Done()
+	// Skip the branch when none of the metrics meets its condition
+	pj, ok := branch(pj, pr.when)
+	if !ok {
+		return
+	}
+	span := startStepSpan(pj, pr, t)
+	defer span.Finish()
 	// Create a new process job
 	mgr, err := t.RemoteManagers.Get(pr.Target)
 	if err != nil {
 		t.RecordFailure([]error{err})
+		recordStep(pj, pr, 0, []error{err})
+		span.SetError([]error{err})
 		workflowLogger.WithFields(log.Fields{
 			"_block":           "submit-prblish-job",
 			"task-id":          t.id,
@@ -357,7 +587,9 @@ func submitProcessJob(pj job, t *task, wg *sync.WaitGroup, pr *processNode) {
 		}).Warn("Error getting control instance")
 		return
 	}
-	j := newProcessJob(pj, pr.Name(), pr.Version(), pr.InboundContentType, pr.config.Table(), mgr, t.id)
+	newJob := func() job {
+		return newProcessJob(pj, pr.Name(), pr.Version(), pr.InboundContentType, pr.jobConfig(), mgr, t.id)
+	}
 	workflowLogger.WithFields(log.Fields{
 		"_block":           "submit-process-job",
 		"task-id":          t.id,
@@ -366,8 +598,18 @@ func submitProcessJob(pj job, t *task, wg *sync.WaitGroup, pr *processNode) {
 		"process-version":  pr.Version(),
 		"parent-node-type": pj.TypeString(),
 	}).Debug("Submitting process job")
-	// Submit the job against the task.managesWork
-	errors := t.manager.Work(j).Promise().Await()
+	// Submit the job against the task.managesWork, retrying it on failures
+	j, errors := pr.retry.work(t, pr.timeout, newJob)
+	// a job which timed out may still be running, its metrics aren't read
+	metrics := 0
+	if len(errors) == 0 {
+		metrics = len(j.Metrics())
+	}
+	recordStep(pj, pr, metrics, errors)
+	span.SetTag("metrics", metrics)
+	span.SetError(errors)
+	// the span of the step ends before the steps of the child nodes start
+	span.Finish()
 	// Check for errors and update the task
 	if len(errors) != 0 {
 		// Record the failures in the task
@@ -391,6 +633,10 @@ func submitProcessJob(pj job, t *task, wg *sync.WaitGroup, pr *processNode) {
 		"process-version":  pr.Version(),
 		"parent-node-type": pj.TypeString(),
 	}).Debug("Process job completed")
+	// the spans of the child nodes are the children of the span of the step
+	if j, ok := j.(*processJob); ok {
+		j.stepSpan = span
+	}
 	// Iterate into any child process or publish nodes
 	workJobs(pr.ProcessNodes, pr.PublishNodes, t, j)
 }
@@ -398,10 +644,69 @@ func submitProcessJob(pj job, t *task, wg *sync.WaitGroup, pr *processNode) {
 func submitPublishJob(pj job, t *task, wg *sync.WaitGroup, pu *publishNode) {
 	// Decrement the waitgroup
 	defer wg.Done()
+	// Skip the branch when none of the metrics meets its condition
+	pj, ok := branch(pj, pu.when)
+	if !ok {
+		return
+	}
+	// Add the metrics to the batch of a batched publisher, which is only
+	// published once full or old enough
+	if pu.batch != nil {
+		metrics := pu.batch.add(pj.Metrics(), time.Now())
+		if metrics == nil {
+			workflowLogger.WithFields(log.Fields{
+				"_block":           "submit-publish-job",
+				"task-id":          t.id,
+				"task-name":        t.name,
+				"publish-name":     pu.Name(),
+				"publish-version":  pu.Version(),
+				"parent-node-type": pj.TypeString(),
+			}).Debug("Metrics added to the batch of the publisher")
+			return
+		}
+		pj = &branchJob{job: pj, metrics: metrics}
+	}
+	// the span of the step includes the time held back by the rate limit
+	span := startStepSpan(pj, pu, t)
+	defer span.Finish()
+	// Hold back or drop the metrics over the rate limit of the publisher
+	if pu.limit != nil {
+		metrics, wait := pu.limit.take(pj.Metrics(), time.Now())
+		if dropped := len(pj.Metrics()) - len(metrics); dropped > 0 {
+			workflowLogger.WithFields(log.Fields{
+				"_block":           "submit-publish-job",
+				"task-id":          t.id,
+				"task-name":        t.name,
+				"publish-name":     pu.Name(),
+				"publish-version":  pu.Version(),
+				"parent-node-type": pj.TypeString(),
+				"dropped":          dropped,
+			}).Warn("Metrics dropped, over the rate limit of the publisher")
+			span.SetTag("dropped", dropped)
+			if len(metrics) == 0 {
+				return
+			}
+			pj = &branchJob{job: pj, metrics: metrics}
+		}
+		if wait > 0 {
+			workflowLogger.WithFields(log.Fields{
+				"_block":           "submit-publish-job",
+				"task-id":          t.id,
+				"task-name":        t.name,
+				"publish-name":     pu.Name(),
+				"publish-version":  pu.Version(),
+				"parent-node-type": pj.TypeString(),
+				"wait":             wait,
+			}).Debug("Publish job blocked by the rate limit of the publisher")
+			time.Sleep(wait)
+		}
+	}
 	// Create a new process job
 	mgr, err := t.RemoteManagers.Get(pu.Target)
 	if err != nil {
 		t.RecordFailure([]error{err})
+		recordStep(pj, pu, 0, []error{err})
+		span.SetError([]error{err})
 		workflowLogger.WithFields(log.Fields{
 			"_block":           "submit-publish-job",
 			"task-id":          t.id,
@@ -412,7 +717,9 @@ func submitPublishJob(pj job, t *task, wg *sync.WaitGroup, pu *publishNode) {
 		}).Warn("Error getting control instance")
 		return
 	}
-	j := newPublishJob(pj, pu.Name(), pu.Version(), pu.InboundContentType, pu.config.Table(), mgr, t.id)
+	newJob := func() job {
+		return newPublishJob(pj, pu.Name(), pu.Version(), pu.InboundContentType, pu.config.Table(), mgr, t.id)
+	}
 	workflowLogger.WithFields(log.Fields{
 		"_block":           "submit-publish-job",
 		"task-id":          t.id,
@@ -421,13 +728,49 @@ func submitPublishJob(pj job, t *task, wg *sync.WaitGroup, pu *publishNode) {
 		"publish-version":  pu.Version(),
 		"parent-node-type": pj.TypeString(),
 	}).Debug("Submitting publish job")
-	// Submit the job against the task.managesWork
-	errors := t.manager.Work(j).Promise().Await()
+	// Queue the metrics behind the backlog of a buffered publisher, which is
+	// forwarded oldest first
+	if t.buffer != nil && t.buffer.pending(t.bufferNode(pu)) && t.bufferMetrics(pu, pj.Metrics()) {
+		span.SetTag("buffered", true)
+		t.forward(pj, pu, mgr)
+		return
+	}
+	// Submit the job against the task.managesWork, retrying it on failures,
+	// unless the circuit breaker of the node is open
+	errors, skipped := pu.guard(t, func() []error {
+		_, errs := pu.retry.work(t, pu.timeout, newJob)
+		return errs
+	})
+	if skipped {
+		recordStep(pj, pu, 0, []error{ErrCircuitBreakerOpen})
+		span.SetError([]error{ErrCircuitBreakerOpen})
+		pu.batch.record(len(pj.Metrics()), false)
+		if !t.bufferMetrics(pu, pj.Metrics()) {
+			t.spoolDeadLetter(pu, pj.Metrics(), []error{ErrCircuitBreakerOpen})
+		}
+		workflowLogger.WithFields(log.Fields{
+			"_block":           "submit-publish-job",
+			"task-id":          t.id,
+			"task-name":        t.name,
+			"publish-name":     pu.Name(),
+			"publish-version":  pu.Version(),
+			"parent-node-type": pj.TypeString(),
+		}).Debug("Publish job skipped, circuit breaker open")
+		return
+	}
+	recordStep(pj, pu, len(pj.Metrics()), errors)
+	pu.batch.record(len(pj.Metrics()), len(errors) == 0)
+	span.SetError(errors)
 	// Check for errors and update the task
 	if len(errors) != 0 {
-		// Record the failures in the task
-		// note: this function is thread safe against t
-		t.RecordFailure(errors)
+		// The metrics of a buffered task are forwarded once the publisher
+		// recovers, the failure doesn't count against the task meanwhile
+		if !t.bufferMetrics(pu, pj.Metrics()) {
+			// Record the failures in the task
+			// note: this function is thread safe against t
+			t.RecordFailure(errors)
+			t.spoolDeadLetter(pu, pj.Metrics(), errors)
+		}
 		workflowLogger.WithFields(log.Fields{
 			"_block":           "submit-publish-job",
 			"task-id":          t.id,
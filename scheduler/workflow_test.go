@@ -348,6 +348,29 @@ func TestWorkJobs(t *testing.T) {
 			So(m1.queue["processor"], ShouldEqual, 3)
 			So(m1.queue["publisher"], ShouldEqual, 3)
 		})
+		Convey("record the steps of the run", func() {
+			m := &Mock1{queue: make(map[string]int)}
+			pj := newCollectorJob(nil, time.Second*1, m, nil, "", nil)
+			rec := newRunRecorder()
+			pj.(*collectorJob).run = rec
+			n := cdata.NewNode()
+			pr := &processNode{config: n, name: "prjob", version: 1}
+			pr.PublishNodes = []*publishNode{{config: n, name: "pujobchild", version: 2}}
+			pus := []*publishNode{{config: n, name: "pujob", version: 3}}
+			t := &task{manager: m, id: "1", name: "mock"}
+			workJobs([]*processNode{pr}, pus, t, pj)
+			record := rec.end()
+			So(record.Duration, ShouldBeGreaterThan, 0)
+			steps := []string{}
+			for _, s := range record.Steps {
+				steps = append(steps, s.Step)
+				So(s.Errors, ShouldBeEmpty)
+			}
+			So(steps, ShouldHaveLength, 3)
+			So(steps, ShouldContain, "processor:prjob:1")
+			So(steps, ShouldContain, "publisher:pujobchild:2")
+			So(steps, ShouldContain, "publisher:pujob:3")
+		})
 		Convey("submit multiple jobs with nesting", func() {
 			m2 := &Mock1{queue: make(map[string]int)}
 			pj := newCollectorJob(nil, time.Second*1, m2, nil, "", nil)
@@ -428,6 +451,47 @@ func TestWorkJobs(t *testing.T) {
 			// (3*3)
 			So(m3.queue["publisher"], ShouldEqual, 12)
 		})
+		Convey("submit a failing job which is retried", func() {
+			m4 := &Mock1{queue: make(map[string]int)}
+			// make the first attempt fail
+			m4.errorIndex = 1
+			pj := newCollectorJob(nil, time.Second*1, m4, nil, "", nil)
+			t := &task{manager: m4, id: "1", name: "mock"}
+			retry, err := newRetryPolicy(&wmap.RetryWorkflowMapNode{Count: 2, Backoff: "1ms"})
+			So(err, ShouldBeNil)
+			pu := &publishNode{config: cdata.NewNode(), name: "pujob", retry: retry}
+			workJobs(nil, []*publishNode{pu}, t, pj)
+			So(t.failedRuns, ShouldEqual, 0)
+			So(m4.queue["publisher"], ShouldEqual, 2)
+			stats := retry.stats(core.PublisherPluginType, pu.name, pu.version)
+			So(stats.Retries, ShouldEqual, 1)
+			So(stats.Recovered, ShouldEqual, 1)
+			So(stats.Exhausted, ShouldEqual, 0)
+		})
 
 	})
 }
+
+func TestRetryPolicy(t *testing.T) {
+	Convey("Retry policy of a workflow step", t, func() {
+		Convey("is nil without retries", func() {
+			p, err := newRetryPolicy(nil)
+			So(err, ShouldBeNil)
+			So(p, ShouldBeNil)
+		})
+		Convey("doubles the backoff up to the max backoff", func() {
+			p, err := newRetryPolicy(&wmap.RetryWorkflowMapNode{Count: 5, Backoff: "10ms", MaxBackoff: "50ms"})
+			So(err, ShouldBeNil)
+			So(p.wait(1), ShouldEqual, time.Millisecond*10)
+			So(p.wait(2), ShouldEqual, time.Millisecond*20)
+			So(p.wait(3), ShouldEqual, time.Millisecond*40)
+			So(p.wait(4), ShouldEqual, time.Millisecond*50)
+		})
+		Convey("rejects invalid retries", func() {
+			_, err := newRetryPolicy(&wmap.RetryWorkflowMapNode{Count: -1})
+			So(err, ShouldEqual, ErrInvalidRetryCount)
+			_, err = newRetryPolicy(&wmap.RetryWorkflowMapNode{Count: 1, Backoff: "soon"})
+			So(err, ShouldEqual, ErrInvalidRetryBackoff)
+		})
+	})
+}
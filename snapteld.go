@@ -35,16 +35,25 @@ import (
 	"time"
 
 	log "github.com/Sirupsen/logrus"
+	"github.com/intelsdi-x/gomit"
 	"github.com/urfave/cli"
 	"github.com/vrischmann/jsonutil"
 	"golang.org/x/crypto/ssh/terminal"
 
 	"github.com/intelsdi-x/snap/control"
+	"github.com/intelsdi-x/snap/core"
 	"github.com/intelsdi-x/snap/core/serror"
+	"github.com/intelsdi-x/snap/mgmt/notify"
+	"github.com/intelsdi-x/snap/mgmt/prometheus"
 	"github.com/intelsdi-x/snap/mgmt/rest"
 	"github.com/intelsdi-x/snap/mgmt/tribe"
 	"github.com/intelsdi-x/snap/mgmt/tribe/agreement"
+	"github.com/intelsdi-x/snap/pkg/cfgcrypt"
 	"github.com/intelsdi-x/snap/pkg/cfgfile"
+	"github.com/intelsdi-x/snap/pkg/cfgremote"
+	"github.com/intelsdi-x/snap/pkg/logging"
+	"github.com/intelsdi-x/snap/pkg/secrets"
+	"github.com/intelsdi-x/snap/pkg/tracing"
 	"github.com/intelsdi-x/snap/scheduler"
 	"google.golang.org/grpc/grpclog"
 )
@@ -122,15 +131,21 @@ const (
 //         UnmarshalJSON method in this same file needs to be modified to
 //         match the field mapping that is defined here
 type Config struct {
-	LogLevel    int               `json:"log_level,omitempty"yaml:"log_level,omitempty"`
-	GoMaxProcs  int               `json:"gomaxprocs,omitempty"yaml:"gomaxprocs,omitempty"`
-	LogPath     string            `json:"log_path,omitempty"yaml:"log_path,omitempty"`
-	LogTruncate bool              `json:"log_truncate,omitempty"yaml:"log_truncate,omitempty"`
-	LogColors   bool              `json:"log_colors,omitempty"yaml:"log_colors,omitempty"`
-	Control     *control.Config   `json:"control,omitempty"yaml:"control,omitempty"`
-	Scheduler   *scheduler.Config `json:"scheduler,omitempty"yaml:"scheduler,omitempty"`
-	RestAPI     *rest.Config      `json:"restapi,omitempty"yaml:"restapi,omitempty"`
-	Tribe       *tribe.Config     `json:"tribe,omitempty"yaml:"tribe,omitempty"`
+	LogLevel     int               `json:"log_level,omitempty"yaml:"log_level,omitempty"`
+	GoMaxProcs   int               `json:"gomaxprocs,omitempty"yaml:"gomaxprocs,omitempty"`
+	LogPath      string            `json:"log_path,omitempty"yaml:"log_path,omitempty"`
+	LogTruncate  bool              `json:"log_truncate,omitempty"yaml:"log_truncate,omitempty"`
+	LogColors    bool              `json:"log_colors,omitempty"yaml:"log_colors,omitempty"`
+	Control      *control.Config   `json:"control,omitempty"yaml:"control,omitempty"`
+	Scheduler    *scheduler.Config `json:"scheduler,omitempty"yaml:"scheduler,omitempty"`
+	RestAPI      *rest.Config      `json:"restapi,omitempty"yaml:"restapi,omitempty"`
+	Tribe        *tribe.Config     `json:"tribe,omitempty"yaml:"tribe,omitempty"`
+	Notify       *notify.Config    `json:"notify,omitempty"yaml:"notify,omitempty"`
+	Logging      *logging.Config   `json:"logging,omitempty"yaml:"logging,omitempty"`
+	Tracing      *tracing.Config   `json:"tracing,omitempty"yaml:"tracing,omitempty"`
+	RemoteConfig *cfgremote.Config `json:"remote_config,omitempty"yaml:"remote_config,omitempty"`
+	Secrets      *secrets.Config   `json:"secrets,omitempty"yaml:"secrets,omitempty"`
+	Encryption   *cfgcrypt.Config  `json:"encryption,omitempty"yaml:"encryption,omitempty"`
 }
 
 const (
@@ -165,14 +180,26 @@ const (
 			"control": { "$ref": "#/definitions/control" },
 			"scheduler": { "$ref": "#/definitions/scheduler"},
 			"restapi" : { "$ref": "#/definitions/restapi"},
-			"tribe": { "$ref": "#/definitions/tribe"}
+			"tribe": { "$ref": "#/definitions/tribe"},
+			"notify": { "$ref": "#/definitions/notify"},
+			"logging": { "$ref": "#/definitions/logging"},
+			"tracing": { "$ref": "#/definitions/tracing"},
+			"remote_config": { "$ref": "#/definitions/remote_config"},
+			"secrets": { "$ref": "#/definitions/secrets"},
+			"encryption": { "$ref": "#/definitions/encryption"}
 		},
 		"additionalProperties": false,
 		"definitions": { ` +
 		control.CONFIG_CONSTRAINTS + `,` +
 		scheduler.CONFIG_CONSTRAINTS + `,` +
 		rest.CONFIG_CONSTRAINTS + `,` +
-		tribe.CONFIG_CONSTRAINTS +
+		tribe.CONFIG_CONSTRAINTS + `,` +
+		notify.CONFIG_CONSTRAINTS + `,` +
+		logging.CONFIG_CONSTRAINTS + `,` +
+		tracing.CONFIG_CONSTRAINTS + `,` +
+		cfgremote.CONFIG_CONSTRAINTS + `,` +
+		secrets.CONFIG_CONSTRAINTS + `,` +
+		cfgcrypt.CONFIG_CONSTRAINTS +
 		`}` +
 		`}`
 	logModule = "snapteld"
@@ -187,12 +214,52 @@ type coreModule interface {
 type managesTribe interface {
 	GetAgreement(name string) (*agreement.Agreement, serror.SnapError)
 	GetAgreements() map[string]*agreement.Agreement
-	AddAgreement(name string) serror.SnapError
+	AddAgreement(name string, taskLabels map[string]string) serror.SnapError
+	SetTaskAssignment(agreementName, strategy string, replicas int) serror.SnapError
 	RemoveAgreement(name string) serror.SnapError
 	JoinAgreement(agreementName, memberName string) serror.SnapError
 	LeaveAgreement(agreementName, memberName string) serror.SnapError
 	GetMembers() []string
 	GetMember(name string) *agreement.Member
+	ExportAgreement(name string) (*agreement.Snapshot, serror.SnapError)
+	RestoreAgreement(s *agreement.Snapshot) (*agreement.RestoreReport, serror.SnapError)
+	SetTaskOverrides(agreementName, taskID string, overrides map[string]agreement.TaskOverride) serror.SnapError
+	MetricCatalog(agreementName string) (*agreement.Catalog, serror.SnapError)
+	Sync(taskID string, timeout time.Duration) serror.SnapError
+	ListKeys() ([]string, serror.SnapError)
+	InstallKey(key string) serror.SnapError
+	UseKey(key string) serror.SnapError
+	RemoveKey(key string) serror.SnapError
+	Health() core.SubsystemHealth
+	RegisterEventHandler(name string, h gomit.Handler) error
+}
+
+// configHealth reports the configuration of snapteld to /healthz and /readyz,
+// it's loaded once snapteld is running since errors in it are fatal
+type configHealth struct {
+	file string
+}
+
+func (c configHealth) Health() core.SubsystemHealth {
+	file := c.file
+	if file == "" {
+		file = "defaults"
+	}
+	return core.SubsystemHealth{
+		Name:    "config",
+		Healthy: true,
+		Ready:   true,
+		Details: map[string]interface{}{"file": file},
+	}
+}
+
+// configFile returns the path of the config file read by loadConfig, empty
+// when the default configuration is used
+func configFile(fpath string) string {
+	if fpath == "" && defaultConfigFile() {
+		return defaultConfigPath
+	}
+	return fpath
 }
 
 type runtimeFlagsContext interface {
@@ -225,6 +292,7 @@ func main() {
 	cliApp.Flags = append(cliApp.Flags, scheduler.Flags...)
 	cliApp.Flags = append(cliApp.Flags, rest.Flags...)
 	cliApp.Flags = append(cliApp.Flags, tribe.Flags...)
+	cliApp.Flags = append(cliApp.Flags, cfgremote.Flags...)
 
 	cliApp.Action = action
 
@@ -234,20 +302,19 @@ func main() {
 }
 
 func action(ctx *cli.Context) error {
-	// get default configuration
-	cfg := getDefaultConfig()
-
-	// read config file
-	readConfig(cfg, ctx.String("config"))
-
-	// apply values that may have been passed from the command line
-	// to the configuration that we have built so far, overriding the
-	// values that may have already been set (if any) for the
-	// same variables in that configuration
-	applyCmdLineFlags(cfg, ctx)
+	// build the configuration from its layers: the defaults, the config
+	// file, the environment variables, the command line flags and the
+	// settings of the key-value store, each one overriding the values that
+	// may have already been set (if any) by the previous ones
+	flags := newConfigFlags(ctx, cliApp.Flags, os.Args[1:])
+	cfg, sources, err := layeredConfig(ctx.String("config"), flags)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// test the resulting configuration to ensure the values it contains still pass the
-	// constraints after applying the environment variables and command-line parameters;
+	// constraints after applying the environment variables, command-line parameters and
+	// remote settings;
 	// if errors are found, report them and exit with a fatal error
 	jb, _ := json.Marshal(cfg)
 	serrs := cfgfile.ValidateSchema(CONFIG_CONSTRAINTS, string(jb))
@@ -255,30 +322,30 @@ func action(ctx *cli.Context) error {
 		for _, serr := range serrs {
 			log.WithFields(serr.Fields()).Error(serr.Error())
 		}
-		log.Fatal("Errors found after applying command-line flags")
+		log.Fatal("Errors found after applying command-line flags and remote settings")
 	}
 
 	// If logPath is set, we verify the logPath and set it so that all logging
-	// goes to the log file instead of stdout.
+	// goes to the log file instead of stderr, unless the sinks of the logging
+	// are configured.
 	logPath := cfg.LogPath
-	if logPath != "" {
-		f, err := os.Stat(logPath)
-		if err != nil {
-			log.Fatal(err)
-		}
-		if !f.IsDir() {
-			log.Fatal("log path provided must be a directory")
-		}
-		aMode := os.O_APPEND
-		if cfg.LogTruncate {
-			aMode = os.O_TRUNC
-		}
-		file, err := os.OpenFile(fmt.Sprintf("%s/snapteld.log", logPath), os.O_RDWR|os.O_CREATE|aMode, 0666)
-		if err != nil {
-			log.Fatal(err)
+	if len(cfg.Logging.Sinks) == 0 {
+		sink := logging.SinkConfig{Type: logging.SinkStderr}
+		if logPath != "" {
+			f, err := os.Stat(logPath)
+			if err != nil {
+				log.Fatal(err)
+			}
+			if !f.IsDir() {
+				log.Fatal("log path provided must be a directory")
+			}
+			sink = logging.SinkConfig{
+				Type:     logging.SinkFile,
+				Path:     fmt.Sprintf("%s/snapteld.log", logPath),
+				Truncate: cfg.LogTruncate,
+			}
 		}
-		defer file.Close()
-		log.SetOutput(file)
+		cfg.Logging.Sinks = []logging.SinkConfig{sink}
 	}
 
 	// verify the temDirPath points to existing directory
@@ -299,17 +366,26 @@ func action(ctx *cli.Context) error {
 	// We could also restrict this command line parameter to only apply when no logpath is given
 	// and forcing the coloring to off when using a file but this might not please users who like to use
 	// redirect mechanisms like # snapteld -t 0 -l 1 2>&1 | tee my.log
-	if !cfg.LogColors {
+	switch {
+	case cfg.Logging.Format == logging.FormatJSON:
+		log.SetFormatter(&log.JSONFormatter{})
+	case !cfg.LogColors:
 		log.SetFormatter(&log.TextFormatter{FullTimestamp: true, DisableColors: true})
-	} else {
+	default:
 		log.SetFormatter(&log.TextFormatter{FullTimestamp: true})
 	}
 
 	// Validate log level and trust level settings for snapteld
 	validateLevelSettings(cfg.LogLevel, cfg.Control.PluginTrust)
 
-	// Switch log level to user defined
-	log.SetLevel(getLevel(cfg.LogLevel))
+	// Switch log level to user defined, the entries are written to the sinks
+	// of the logging from then on
+	lg, err := logging.New(cfg.Logging, getLevel(cfg.LogLevel))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer lg.Close()
+	lg.Install()
 
 	//Set standard logger as logger for grpc
 	grpclog.SetLogger(log.StandardLogger())
@@ -327,16 +403,61 @@ func action(ctx *cli.Context) error {
 	if c.Config.AutoDiscoverPath != "" && c.Config.IsTLSEnabled() {
 		log.Fatal("TLS security is not supported in autodiscovery mode")
 	}
+	// the references to secrets of the config of the plugins and of the
+	// tasks are resolved when the config is given to the plugins
+	c.SetSecretResolver(secrets.New(cfg.Secrets))
+	// the sensitive values of the config of the tasks are encrypted when the
+	// tasks are created and decrypted when they are given to the plugins
+	cipher, err := cfgcrypt.New(cfg.Encryption)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if cipher != nil {
+		c.SetConfigCipher(cipher)
+	}
 
 	coreModules = []coreModule{}
 
 	coreModules = append(coreModules, c)
 	s := scheduler.New(cfg.Scheduler)
 	s.SetMetricManager(c)
+	// the worker pools and tasks of the scheduler are collected by the
+	// built-in collector of the statistics of snapteld
+	c.SetTaskManager(s)
+	if cfg.Scheduler.PersistTasks && cfg.Scheduler.TaskStorePath != "" {
+		log.Info("Persisting tasks in ", cfg.Scheduler.TaskStorePath)
+		s.SetTaskStore(cfg.Scheduler.TaskStorePath)
+		// the persisted tasks waiting for a plugin are restored once it's loaded
+		c.RegisterEventHandler("scheduler", s)
+	}
+	if cfg.Scheduler.DeadLetterPath != "" {
+		log.Info("Spooling failed publishes in ", cfg.Scheduler.DeadLetterPath)
+		s.SetDeadLetterQueue(cfg.Scheduler.DeadLetterPath, cfg.Scheduler.DeadLetterMaxBatches)
+	}
+	if cfg.Tracing.Enable {
+		// the runs of the workflows are traced and their spans exported
+		// before the tasks are started
+		log.Info("Exporting the spans of the workflows to ", cfg.Tracing.Endpoint)
+		tc := tracing.New(cfg.Tracing)
+		s.SetTracer(tc)
+		coreModules = append(coreModules, tc)
+	}
 	coreModules = append(coreModules, s)
 
-	// Auth requested and not provided as part of config
-	if cfg.RestAPI.Enable && cfg.RestAPI.RestAuth && cfg.RestAPI.RestAuthPassword == "" {
+	// the events of control, the scheduler and tribe are POSTed to the
+	// webhooks
+	n, err := notify.New(cfg.Notify)
+	if err != nil {
+		log.Fatal(err)
+	}
+	c.RegisterEventHandler("notify", n)
+	s.RegisterEventHandler("notify", n)
+	coreModules = append(coreModules, n)
+
+	// Auth requested and not provided as part of config, the password isn't
+	// needed when the clients authenticate with tokens or certificates
+	restAuthCredentials := len(cfg.RestAPI.RestAuthTokens) > 0 || cfg.RestAPI.RestAuthTokenFile != "" || cfg.RestAPI.RestClientCA != ""
+	if cfg.RestAPI.Enable && cfg.RestAPI.RestAuth && cfg.RestAPI.RestAuthPassword == "" && !restAuthCredentials {
 		fmt.Println("What password do you want to use for authentication?")
 		fmt.Print("Password:")
 		password, err := terminal.ReadPassword(0)
@@ -346,6 +467,16 @@ func action(ctx *cli.Context) error {
 		}
 		cfg.RestAPI.RestAuthPassword = string(password)
 	}
+
+	// the settings changed in the config file are applied on a SIGHUP, or
+	// given to PUT /v1/config, without restarting snapteld
+	rl, err := newReloader(jb, sources, cfg.RestAPI.RestAuthPassword, ctx.String("config"), flags)
+	if err != nil {
+		log.Fatal(err)
+	}
+	rl.control = c
+	rl.scheduler = s
+	rl.logging = lg
 	if cfg.Tribe.Enable && c.Config.IsTLSEnabled() {
 		log.Fatal("TLS security is not supported in tribe mode")
 	}
@@ -364,6 +495,7 @@ func action(ctx *cli.Context) error {
 		t.SetPluginCatalog(c)
 		s.RegisterEventHandler("tribe", t)
 		t.SetTaskManager(s)
+		t.RegisterEventHandler("notify", n)
 		coreModules = append(coreModules, t)
 		tr = t
 	}
@@ -377,13 +509,27 @@ func action(ctx *cli.Context) error {
 		r.BindMetricManager(c)
 		r.BindConfigManager(c.Config)
 		r.BindTaskManager(s)
+		r.BindNotifier(n)
+		r.BindLogging(lg)
+		r.BindConfigReloader(rl)
+		rl.rest = r
+		if cfg.RestAPI.Prometheus {
+			// the values last collected by the tasks are kept from the
+			// events of the scheduler
+			e := prometheus.New(c, s, cfg.RestAPI.PrometheusCollected)
+			s.RegisterEventHandler("prometheus", e)
+			r.BindExporter(e)
+			log.Info("Prometheus metrics are served on /metrics")
+		}
 
 		//Rest Authentication
-		if cfg.RestAPI.RestAuth {
+		if cfg.RestAPI.RestAuth || restAuthCredentials {
 			log.Info("REST API authentication is enabled")
-			r.SetAPIAuth(cfg.RestAPI.RestAuth)
-			log.Info("REST API authentication password is set")
-			r.SetAPIAuthPwd(cfg.RestAPI.RestAuthPassword)
+			r.SetAPIAuth(true)
+			if cfg.RestAPI.RestAuthPassword != "" {
+				log.Info("REST API authentication password is set")
+				r.SetAPIAuthPwd(cfg.RestAPI.RestAuthPassword)
+			}
 			if !cfg.RestAPI.HTTPS {
 				log.Warning("Using REST API authentication without HTTPS enabled.")
 			}
@@ -391,6 +537,12 @@ func action(ctx *cli.Context) error {
 
 		if tr != nil {
 			r.BindTribeManager(tr)
+			// the intents of the tribe are recorded to the audit log
+			tr.RegisterEventHandler("audit", r)
+		}
+		r.BindHealthReporters(c, s, configHealth{file: configFile(ctx.String("config"))})
+		if tr != nil {
+			r.BindHealthReporters(tr)
 		}
 		go monitorErrors(r.Err())
 		coreModules = append(coreModules, r)
@@ -401,7 +553,7 @@ func action(ctx *cli.Context) error {
 
 	// Set interrupt handling so we can either restart the app on a SIGHUP or
 	// die gracefully when an interrupt, kill, etc. are received
-	startInterruptHandling(coreModules...)
+	startInterruptHandling(rl, coreModules...)
 
 	// Start our modules
 	var started []coreModule
@@ -420,87 +572,15 @@ func action(ctx *cli.Context) error {
 	log.Info("setting plugin trust level to: ", t[cfg.Control.PluginTrust])
 	// Keyring checking for trust levels 1 and 2
 	if cfg.Control.PluginTrust > 0 {
-		keyrings := filepath.SplitList(cfg.Control.KeyringPaths)
-		if len(keyrings) == 0 {
+		keyrings, err := keyringFiles(cfg.Control.KeyringPaths)
+		if err != nil {
 			log.WithFields(
 				log.Fields{
 					"block":   "main",
 					"_module": logModule,
-				}).Fatal("need keyring file when trust is on (--keyring-file or -k)")
-		}
-		for _, k := range keyrings {
-			keyringPath, err := filepath.Abs(k)
-			if err != nil {
-				log.WithFields(
-					log.Fields{
-						"block":       "main",
-						"_module":     logModule,
-						"error":       err.Error(),
-						"keyringPath": keyringPath,
-					}).Fatal("Unable to determine absolute path to keyring file")
-			}
-			f, err := os.Stat(keyringPath)
-			if err != nil {
-				log.WithFields(
-					log.Fields{
-						"block":       "main",
-						"_module":     logModule,
-						"error":       err.Error(),
-						"keyringPath": keyringPath,
-					}).Fatal("bad keyring file")
-			}
-			if f.IsDir() {
-				log.Info("Adding keyrings from: ", keyringPath)
-				files, err := ioutil.ReadDir(keyringPath)
-				if err != nil {
-					log.WithFields(
-						log.Fields{
-							"_block":      "main",
-							"_module":     logModule,
-							"error":       err.Error(),
-							"keyringPath": keyringPath,
-						}).Fatal(err)
-				}
-				if len(files) == 0 {
-					log.Fatal(fmt.Sprintf("given keyring path [%s] is an empty directory!", keyringPath))
-				}
-				for _, keyringFile := range files {
-					if keyringFile.IsDir() {
-						continue
-					}
-					if strings.HasSuffix(keyringFile.Name(), ".gpg") || (strings.HasSuffix(keyringFile.Name(), ".pub")) || (strings.HasSuffix(keyringFile.Name(), ".pubring")) {
-						f, err := os.Open(keyringPath)
-						if err != nil {
-							log.WithFields(
-								log.Fields{
-									"block":       "main",
-									"_module":     logModule,
-									"error":       err.Error(),
-									"keyringPath": keyringPath,
-								}).Warning("unable to open keyring file. not adding to keyring path")
-							continue
-						}
-						f.Close()
-						log.Info("adding keyring file: ", keyringPath+"/"+keyringFile.Name())
-						c.SetKeyringFile(keyringPath + "/" + keyringFile.Name())
-					}
-				}
-			} else {
-				f, err := os.Open(keyringPath)
-				if err != nil {
-					log.WithFields(
-						log.Fields{
-							"block":       "main",
-							"_module":     logModule,
-							"error":       err.Error(),
-							"keyringPath": keyringPath,
-						}).Fatal("unable to open keyring file.")
-				}
-				f.Close()
-				log.Info("adding keyring file ", keyringPath)
-				c.SetKeyringFile(keyringPath)
-			}
+				}).Fatal(err)
 		}
+		c.SetKeyringFiles(keyrings)
 	}
 
 	log.WithFields(
@@ -549,23 +629,30 @@ func action(ctx *cli.Context) error {
 // get the default snapteld configuration
 func getDefaultConfig() *Config {
 	return &Config{
-		LogLevel:    defaultLogLevel,
-		GoMaxProcs:  defaultGoMaxProcs,
-		LogPath:     defaultLogPath,
-		LogTruncate: defaultLogTruncate,
-		LogColors:   defaultLogColors,
-		Control:     control.GetDefaultConfig(),
-		Scheduler:   scheduler.GetDefaultConfig(),
-		RestAPI:     rest.GetDefaultConfig(),
-		Tribe:       tribe.GetDefaultConfig(),
+		LogLevel:     defaultLogLevel,
+		GoMaxProcs:   defaultGoMaxProcs,
+		LogPath:      defaultLogPath,
+		LogTruncate:  defaultLogTruncate,
+		LogColors:    defaultLogColors,
+		Control:      control.GetDefaultConfig(),
+		Scheduler:    scheduler.GetDefaultConfig(),
+		RestAPI:      rest.GetDefaultConfig(),
+		Tribe:        tribe.GetDefaultConfig(),
+		Notify:       notify.GetDefaultConfig(),
+		Logging:      logging.GetDefaultConfig(),
+		Tracing:      tracing.GetDefaultConfig(),
+		RemoteConfig: cfgremote.GetDefaultConfig(),
+		Secrets:      secrets.GetDefaultConfig(),
+		Encryption:   cfgcrypt.GetDefaultConfig(),
 	}
 }
 
-// Read the snapteld configuration from a configuration file
-func readConfig(cfg *Config, fpath string) {
+// loadConfig reads the configuration file, or the default one when no path
+// is given, into the configuration
+func loadConfig(cfg *Config, fpath string) error {
 	var path string
 	if !defaultConfigFile() && fpath == "" {
-		return
+		return nil
 	}
 	if defaultConfigFile() && fpath == "" {
 		path = defaultConfigPath
@@ -573,10 +660,10 @@ func readConfig(cfg *Config, fpath string) {
 	if fpath != "" {
 		f, err := os.Stat(fpath)
 		if err != nil {
-			log.Fatal(err)
+			return err
 		}
 		if f.IsDir() {
-			log.Fatal("configuration path provided must be a file")
+			return errors.New("configuration path provided must be a file")
 		}
 		path = fpath
 	}
@@ -586,8 +673,9 @@ func readConfig(cfg *Config, fpath string) {
 		for _, serr := range serrs {
 			log.WithFields(serr.Fields()).Error(serr.Error())
 		}
-		log.Fatal("Errors found while parsing global configuration file")
+		return errors.New("Errors found while parsing global configuration file")
 	}
+	return nil
 }
 
 func defaultConfigFile() bool {
@@ -857,6 +945,14 @@ func applyCmdLineFlags(cfg *Config, ctx runtimeFlagsContext) {
 	cfg.Control.TLSCertPath = setStringVal(cfg.Control.TLSCertPath, ctx, "tls-cert")
 	cfg.Control.TLSKeyPath = setStringVal(cfg.Control.TLSKeyPath, ctx, "tls-key")
 	cfg.Control.CACertPaths = setStringVal(cfg.Control.CACertPaths, ctx, "ca-cert-paths")
+	cfg.Control.CatalogPath = setStringVal(cfg.Control.CatalogPath, ctx, "catalog-path")
+	cfg.Control.PluginWatchPath = setStringVal(cfg.Control.PluginWatchPath, ctx, "plugin-watch-path")
+	cfg.Control.PluginCachePath = setStringVal(cfg.Control.PluginCachePath, ctx, "plugin-cache-path")
+	cfg.Control.PluginConfigDir = setStringVal(cfg.Control.PluginConfigDir, ctx, "plugin-config-dir")
+	cfg.Control.PluginAutoTLS = setBoolVal(cfg.Control.PluginAutoTLS, ctx, "plugin-auto-tls")
+	cfg.Control.PluginResourceLimits = setBoolVal(cfg.Control.PluginResourceLimits, ctx, "plugin-resource-limits")
+	cfg.Control.PluginExecutor = setStringVal(cfg.Control.PluginExecutor, ctx, "plugin-executor")
+	cfg.Control.PluginContainerImage = setStringVal(cfg.Control.PluginContainerImage, ctx, "plugin-container-image")
 	// next for the RESTful server related flags
 	cfg.RestAPI.Enable = setBoolVal(cfg.RestAPI.Enable, ctx, "disable-api", invertBoolean)
 	cfg.RestAPI.Port = setIntVal(cfg.RestAPI.Port, ctx, "api-port")
@@ -866,18 +962,32 @@ func applyCmdLineFlags(cfg *Config, ctx runtimeFlagsContext) {
 	cfg.RestAPI.RestKey = setStringVal(cfg.RestAPI.RestKey, ctx, "rest-key")
 	cfg.RestAPI.RestAuth = setBoolVal(cfg.RestAPI.RestAuth, ctx, "rest-auth")
 	cfg.RestAPI.RestAuthPassword = setStringVal(cfg.RestAPI.RestAuthPassword, ctx, "rest-auth-pwd")
+	cfg.RestAPI.RestAuthTokenFile = setStringVal(cfg.RestAPI.RestAuthTokenFile, ctx, "rest-auth-token-file")
+	cfg.RestAPI.RestClientCA = setStringVal(cfg.RestAPI.RestClientCA, ctx, "rest-client-ca")
+	cfg.RestAPI.UnixSocket = setStringVal(cfg.RestAPI.UnixSocket, ctx, "rest-unix-socket")
+	cfg.RestAPI.UnixSocketMode = setStringVal(cfg.RestAPI.UnixSocketMode, ctx, "rest-unix-socket-mode")
+	cfg.RestAPI.UnixSocketOnly = setBoolVal(cfg.RestAPI.UnixSocketOnly, ctx, "rest-unix-socket-only")
+	cfg.RestAPI.AuditLog = setStringVal(cfg.RestAPI.AuditLog, ctx, "rest-audit-log")
 	cfg.RestAPI.Pprof = setBoolVal(cfg.RestAPI.Pprof, ctx, "pprof")
 	cfg.RestAPI.Corsd = setStringVal(cfg.RestAPI.Corsd, ctx, "allowed_origins")
 
 	// next for the scheduler related flags
 	cfg.Scheduler.WorkManagerQueueSize = setUIntVal(cfg.Scheduler.WorkManagerQueueSize, ctx, "work-manager-queue-size")
 	cfg.Scheduler.WorkManagerPoolSize = setUIntVal(cfg.Scheduler.WorkManagerPoolSize, ctx, "work-manager-pool-size")
+	cfg.Scheduler.PersistTasks = setBoolVal(cfg.Scheduler.PersistTasks, ctx, "disable-task-persistence", invertBoolean)
+	cfg.Scheduler.TaskStorePath = setStringVal(cfg.Scheduler.TaskStorePath, ctx, "task-store-path")
+	cfg.Scheduler.DeadLetterPath = setStringVal(cfg.Scheduler.DeadLetterPath, ctx, "dead-letter-path")
 	// and finally for the tribe-related flags
 	cfg.Tribe.Name = setStringVal(cfg.Tribe.Name, ctx, "tribe-node-name")
 	cfg.Tribe.Enable = setBoolVal(cfg.Tribe.Enable, ctx, "tribe")
 	cfg.Tribe.BindAddr = setStringVal(cfg.Tribe.BindAddr, ctx, "tribe-addr")
 	cfg.Tribe.BindPort = setIntVal(cfg.Tribe.BindPort, ctx, "tribe-port")
 	cfg.Tribe.Seed = setStringVal(cfg.Tribe.Seed, ctx, "tribe-seed")
+	// the flags of the key-value store the settings overriding the others
+	// are read from
+	cfg.RemoteConfig.Type = setStringVal(cfg.RemoteConfig.Type, ctx, "remote-config-type")
+	cfg.RemoteConfig.Endpoint = setStringVal(cfg.RemoteConfig.Endpoint, ctx, "remote-config-endpoint")
+	cfg.RemoteConfig.Prefix = setStringVal(cfg.RemoteConfig.Prefix, ctx, "remote-config-prefix")
 	// check to see if we have duplicate port definitions (check the various
 	// combinations of the config file and command-line parameter values that
 	// could be used to define the port and make sure we only have one)
@@ -1020,6 +1130,30 @@ func (c *Config) UnmarshalJSON(data []byte) error {
 			if err := json.Unmarshal(v, c.Tribe); err != nil {
 				return err
 			}
+		case "notify":
+			if err := json.Unmarshal(v, c.Notify); err != nil {
+				return err
+			}
+		case "logging":
+			if err := json.Unmarshal(v, c.Logging); err != nil {
+				return err
+			}
+		case "tracing":
+			if err := json.Unmarshal(v, c.Tracing); err != nil {
+				return err
+			}
+		case "remote_config":
+			if err := json.Unmarshal(v, c.RemoteConfig); err != nil {
+				return err
+			}
+		case "secrets":
+			if err := json.Unmarshal(v, c.Secrets); err != nil {
+				return err
+			}
+		case "encryption":
+			if err := json.Unmarshal(v, c.Encryption); err != nil {
+				return err
+			}
 		default:
 			return fmt.Errorf("Unrecognized key '%v' in global config file", k)
 		}
@@ -1050,13 +1184,37 @@ func printErrorAndExit(name string, err error) {
 		}).Fatal("error starting module")
 }
 
-func startInterruptHandling(modules ...coreModule) {
+func startInterruptHandling(rl *reloader, modules ...coreModule) {
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, os.Kill, syscall.SIGTERM, syscall.SIGHUP)
 
 	//Let's block until someone tells us to quit
 	go func() {
 		sig := <-c
+		// the configuration is reloaded on a SIGHUP, snapteld is only
+		// restarted when the settings changed can't be applied while it's
+		// running
+		for sig == syscall.SIGHUP {
+			reloaded, changed, err := rl.ReloadFile()
+			if err != nil {
+				log.WithFields(
+					log.Fields{
+						"block":   "main",
+						"_module": logModule,
+						"error":   err.Error(),
+					}).Error("reloading the configuration failed")
+			} else if reloaded {
+				log.WithFields(
+					log.Fields{
+						"block":   "main",
+						"_module": logModule,
+						"changed": strings.Join(changed, ","),
+					}).Info("configuration reloaded")
+			} else {
+				break
+			}
+			sig = <-c
+		}
 		log.WithFields(
 			log.Fields{
 				"block":   "main",
@@ -1132,3 +1290,63 @@ func validateLevelSettings(logLevel, pluginTrust int) {
 			}).Fatal("Plugin trust was invalid (needs: 0-2)")
 	}
 }
+
+// keyringFiles returns the keyring files of the keyring paths, the files of
+// a directory ending in .gpg, .pub or .pubring are keyrings
+func keyringFiles(paths string) ([]string, error) {
+	keyrings := filepath.SplitList(paths)
+	if len(keyrings) == 0 {
+		return nil, errors.New("need keyring file when trust is on (--keyring-file or -k)")
+	}
+	var files []string
+	for _, k := range keyrings {
+		keyringPath, err := filepath.Abs(k)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to determine absolute path to keyring file %s: %v", k, err)
+		}
+		f, err := os.Stat(keyringPath)
+		if err != nil {
+			return nil, fmt.Errorf("bad keyring file %s: %v", keyringPath, err)
+		}
+		if !f.IsDir() {
+			f, err := os.Open(keyringPath)
+			if err != nil {
+				return nil, fmt.Errorf("unable to open keyring file %s: %v", keyringPath, err)
+			}
+			f.Close()
+			log.Info("adding keyring file ", keyringPath)
+			files = append(files, keyringPath)
+			continue
+		}
+		log.Info("Adding keyrings from: ", keyringPath)
+		dirFiles, err := ioutil.ReadDir(keyringPath)
+		if err != nil {
+			return nil, err
+		}
+		if len(dirFiles) == 0 {
+			return nil, fmt.Errorf("given keyring path [%s] is an empty directory!", keyringPath)
+		}
+		for _, keyringFile := range dirFiles {
+			if keyringFile.IsDir() {
+				continue
+			}
+			if strings.HasSuffix(keyringFile.Name(), ".gpg") || (strings.HasSuffix(keyringFile.Name(), ".pub")) || (strings.HasSuffix(keyringFile.Name(), ".pubring")) {
+				f, err := os.Open(keyringPath)
+				if err != nil {
+					log.WithFields(
+						log.Fields{
+							"block":       "main",
+							"_module":     logModule,
+							"error":       err.Error(),
+							"keyringPath": keyringPath,
+						}).Warning("unable to open keyring file. not adding to keyring path")
+					continue
+				}
+				f.Close()
+				log.Info("adding keyring file: ", keyringPath+"/"+keyringFile.Name())
+				files = append(files, keyringPath+"/"+keyringFile.Name())
+			}
+		}
+	}
+	return files, nil
+}
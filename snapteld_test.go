@@ -34,73 +34,108 @@ import (
 	"github.com/intelsdi-x/snap/mgmt/rest"
 	"github.com/intelsdi-x/snap/mgmt/tribe"
 	"github.com/intelsdi-x/snap/pkg/cfgfile"
+	"github.com/intelsdi-x/snap/pkg/cfgremote"
 	"github.com/intelsdi-x/snap/scheduler"
 )
 
 var validCmdlineFlags_input = mockFlags{
-	"max-procs":               "11",
-	"log-level":               "1",
-	"log-path":                "/no/logs/allowed",
-	"log-truncate":            "true",
-	"log-colors":              "true",
-	"max-running-plugins":     "12",
-	"plugin-load-timeout":     "20",
-	"plugin-trust":            "1",
-	"auto-discover":           "/no/plugins/here",
-	"keyring-paths":           "/no/keyrings/here",
-	"cache-expiration":        "30ms",
-	"control-listen-addr":     "100.101.102.103",
-	"control-listen-port":     "10400",
-	"pprof":                   "true",
-	"temp_dir_path":           "/no/temp/files",
-	"tls-cert":                "/no/cert/here",
-	"tls-key":                 "/no/key/here",
-	"ca-cert-paths":           "/no/root/certs",
-	"disable-api":             "false",
-	"api-port":                "12400",
-	"api-addr":                "120.121.122.123",
-	"rest-https":              "true",
-	"rest-cert":               "/no/rest/cert",
-	"rest-key":                "/no/rest/key",
-	"rest-auth":               "true",
-	"rest-auth-pwd":           "noway",
-	"allowed_origins":         "140.141.142.143",
-	"work-manager-queue-size": "70",
-	"work-manager-pool-size":  "71",
-	"tribe-node-name":         "bonk",
-	"tribe":                   "true",
-	"tribe-addr":              "160.161.162.163",
-	"tribe-port":              "16400",
-	"tribe-seed":              "180.181.182.183",
+	"max-procs":                "11",
+	"log-level":                "1",
+	"log-path":                 "/no/logs/allowed",
+	"log-truncate":             "true",
+	"log-colors":               "true",
+	"max-running-plugins":      "12",
+	"plugin-load-timeout":      "20",
+	"plugin-trust":             "1",
+	"auto-discover":            "/no/plugins/here",
+	"keyring-paths":            "/no/keyrings/here",
+	"cache-expiration":         "30ms",
+	"control-listen-addr":      "100.101.102.103",
+	"control-listen-port":      "10400",
+	"pprof":                    "true",
+	"temp_dir_path":            "/no/temp/files",
+	"tls-cert":                 "/no/cert/here",
+	"tls-key":                  "/no/key/here",
+	"ca-cert-paths":            "/no/root/certs",
+	"catalog-path":             "/no/catalog/here",
+	"plugin-watch-path":        "/no/plugins/here",
+	"plugin-cache-path":        "/no/cache/here",
+	"plugin-config-dir":        "/no/profiles/here",
+	"plugin-auto-tls":          "true",
+	"plugin-resource-limits":   "true",
+	"plugin-executor":          "container",
+	"plugin-container-image":   "snap/plugin-runtime",
+	"disable-api":              "false",
+	"api-port":                 "12400",
+	"api-addr":                 "120.121.122.123",
+	"rest-https":               "true",
+	"rest-cert":                "/no/rest/cert",
+	"rest-key":                 "/no/rest/key",
+	"rest-auth":                "true",
+	"rest-auth-pwd":            "noway",
+	"rest-auth-token-file":     "/no/rest/tokens",
+	"rest-client-ca":           "/no/rest/ca",
+	"rest-unix-socket":         "/no/rest/socket",
+	"rest-unix-socket-mode":    "0600",
+	"rest-unix-socket-only":    "true",
+	"rest-audit-log":           "/no/rest/audit",
+	"allowed_origins":          "140.141.142.143",
+	"work-manager-queue-size":  "70",
+	"work-manager-pool-size":   "71",
+	"disable-task-persistence": "false",
+	"task-store-path":          "/no/tasks/here",
+	"dead-letter-path":         "/no/dead/letters/here",
+	"tribe-node-name":          "bonk",
+	"tribe":                    "true",
+	"tribe-addr":               "160.161.162.163",
+	"tribe-port":               "16400",
+	"tribe-seed":               "180.181.182.183",
+	"remote-config-type":       "etcd",
+	"remote-config-endpoint":   "http://190.191.192.193:2379",
+	"remote-config-prefix":     "no/config/",
 }
 
 var validCmdlineFlags_expected = &Config{
 	Control: &control.Config{
-		MaxRunningPlugins: 12,
-		PluginLoadTimeout: 20,
-		PluginTrust:       1,
-		AutoDiscoverPath:  "/no/plugins/here",
-		KeyringPaths:      "/no/keyrings/here",
-		CacheExpiration:   jsonutil.Duration{30 * time.Millisecond},
-		ListenAddr:        "100.101.102.103",
-		ListenPort:        10400,
-		Pprof:             true,
-		TempDirPath:       "/no/temp/files",
-		TLSCertPath:       "/no/cert/here",
-		TLSKeyPath:        "/no/key/here",
-		CACertPaths:       "/no/root/certs",
+		MaxRunningPlugins:    12,
+		PluginLoadTimeout:    20,
+		PluginTrust:          1,
+		AutoDiscoverPath:     "/no/plugins/here",
+		KeyringPaths:         "/no/keyrings/here",
+		CacheExpiration:      jsonutil.Duration{30 * time.Millisecond},
+		ListenAddr:           "100.101.102.103",
+		ListenPort:           10400,
+		Pprof:                true,
+		TempDirPath:          "/no/temp/files",
+		TLSCertPath:          "/no/cert/here",
+		TLSKeyPath:           "/no/key/here",
+		CACertPaths:          "/no/root/certs",
+		CatalogPath:          "/no/catalog/here",
+		PluginWatchPath:      "/no/plugins/here",
+		PluginCachePath:      "/no/cache/here",
+		PluginConfigDir:      "/no/profiles/here",
+		PluginAutoTLS:        true,
+		PluginResourceLimits: true,
+		PluginExecutor:       "container",
+		PluginContainerImage: "snap/plugin-runtime",
 	},
 	RestAPI: &rest.Config{
-		Enable:           true,
-		Port:             12400,
-		Address:          "120.121.122.123:12400",
-		HTTPS:            true,
-		RestCertificate:  "/no/rest/cert",
-		RestKey:          "/no/rest/key",
-		RestAuth:         true,
-		RestAuthPassword: "noway",
-		Pprof:            true,
-		Corsd:            "140.141.142.143",
+		Enable:            true,
+		Port:              12400,
+		Address:           "120.121.122.123:12400",
+		HTTPS:             true,
+		RestCertificate:   "/no/rest/cert",
+		RestKey:           "/no/rest/key",
+		RestAuth:          true,
+		RestAuthPassword:  "noway",
+		RestAuthTokenFile: "/no/rest/tokens",
+		RestClientCA:      "/no/rest/ca",
+		Pprof:             true,
+		Corsd:             "140.141.142.143",
+		UnixSocket:        "/no/rest/socket",
+		UnixSocketMode:    "0600",
+		UnixSocketOnly:    true,
+		AuditLog:          "/no/rest/audit",
 	},
 	Tribe: &tribe.Config{
 		Name:     "bonk",
@@ -112,6 +147,14 @@ var validCmdlineFlags_expected = &Config{
 	Scheduler: &scheduler.Config{
 		WorkManagerQueueSize: 70,
 		WorkManagerPoolSize:  71,
+		PersistTasks:         true,
+		TaskStorePath:        "/no/tasks/here",
+		DeadLetterPath:       "/no/dead/letters/here",
+	},
+	RemoteConfig: &cfgremote.Config{
+		Type:     "etcd",
+		Endpoint: "http://190.191.192.193:2379",
+		Prefix:   "no/config/",
 	},
 	GoMaxProcs:  11,
 	LogLevel:    1,
@@ -380,10 +423,11 @@ func Test_checkCfgSettings(t *testing.T) {
 func Test_applyCmdLineFlags(t *testing.T) {
 	Convey("Having arguments given on command line", t, func() {
 		gotConfig := Config{
-			Control:   &control.Config{},
-			RestAPI:   &rest.Config{},
-			Tribe:     &tribe.Config{},
-			Scheduler: &scheduler.Config{},
+			Control:      &control.Config{},
+			RestAPI:      &rest.Config{},
+			Tribe:        &tribe.Config{},
+			Scheduler:    &scheduler.Config{},
+			RemoteConfig: &cfgremote.Config{},
 		}
 		applyCmdLineFlags(&gotConfig, validCmdlineFlags_input)
 		Convey("config should be filled with correct values", func() {
@@ -391,6 +435,7 @@ func Test_applyCmdLineFlags(t *testing.T) {
 			So(*gotConfig.RestAPI, ShouldResemble, *validCmdlineFlags_expected.RestAPI)
 			So(*gotConfig.Tribe, ShouldResemble, *validCmdlineFlags_expected.Tribe)
 			So(*gotConfig.Scheduler, ShouldResemble, *validCmdlineFlags_expected.Scheduler)
+			So(*gotConfig.RemoteConfig, ShouldResemble, *validCmdlineFlags_expected.RemoteConfig)
 			So(gotConfig, ShouldResemble, *validCmdlineFlags_expected)
 		})
 	})